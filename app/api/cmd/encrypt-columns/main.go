@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+
+	"github.com/clockwise/clockwise/backend/internal/config"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/clockwise/clockwise/backend/migrations"
+)
+
+// encrypt-columns backfills the encrypted user columns. Run it after enabling encryption to seal existing
+// rows, after adding a new active key to re-encrypt rows sealed with the old one, and with -decrypt
+// before turning encryption off.
+func main() {
+	decrypt := flag.Bool("decrypt", false, "write every sensitive column back in plaintext")
+	flag.Parse()
+
+	Logger := slog.Default()
+
+	cfg, err := config.Load(Logger)
+	if err != nil {
+		panic(fmt.Sprintf("failed to load configuration: %s", err))
+	}
+
+	dbService := database.NewWithPasswordSource(cfg.SecretSource("POSTGRES_PASSWORD"), cfg.Database, Logger)
+	defer dbService.Close()
+
+	if err := database.MigrateFS(dbService.GetDB(), ".", migrations.FS); err != nil {
+		panic(fmt.Sprintf("failed to run database migrations: %s", err))
+	}
+
+	cryptoService, err := service.NewCryptoServiceFromEnv(Logger)
+	if err != nil {
+		panic(fmt.Sprintf("failed to load encryption keys: %s", err))
+	}
+	userStore := database.NewPostgresUserStore(dbService.GetDB(), Logger, cryptoService)
+
+	if *decrypt {
+		count, err := userStore.DecryptSensitiveColumns()
+		if err != nil {
+			panic(fmt.Sprintf("failed to decrypt user columns: %s", err))
+		}
+		Logger.Info("decrypted user columns", "users", count)
+		return
+	}
+
+	count, err := userStore.EncryptSensitiveColumns()
+	if err != nil {
+		panic(fmt.Sprintf("failed to encrypt user columns: %s", err))
+	}
+	Logger.Info("encrypted user columns", "users", count)
+}
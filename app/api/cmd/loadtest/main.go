@@ -0,0 +1,228 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+// loadtest drives the schedule generation, order listing and insights endpoints concurrently and reports
+// their P50/P95 latencies. By default it seeds a synthetic organization into in-memory stores behind the
+// real handlers, so the cost of the Go side can be measured without a database. With -target it drives a
+// running API instead, for an organization seeded beforehand through the uploads.
+func main() {
+	employees := flag.Int("employees", 200, "employees of the synthetic organization")
+	managers := flag.Int("managers", 10, "managers of the synthetic organization, on top of the employees")
+	orders := flag.Int("orders", 10000, "orders of the synthetic organization")
+	items := flag.Int("items", 40, "menu items of the synthetic organization")
+	days := flag.Int("days", 90, "days of order history")
+	seed := flag.Int64("seed", 1, "seed of the synthetic data")
+	concurrency := flag.Int("concurrency", 8, "concurrent clients per endpoint")
+	requests := flag.Int("requests", 200, "requests per read endpoint")
+	scheduleRequests := flag.Int("schedule-requests", 20, "schedule generations")
+	mlURL := flag.String("ml-url", "", "ML service for schedule generation, the fallback scheduler is used when empty")
+	target := flag.String("target", "", "base URL of a running API, e.g. http://localhost:8080/api/v1")
+	org := flag.String("org", "", "organization ID, with -target")
+	token := flag.String("token", "", "access token of an admin of the organization, with -target")
+	logLevel := flag.String("log-level", "warn", "level of the handler logs: debug, info, warn or error")
+	flag.Parse()
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(*logLevel)); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid -log-level: %s\n", err)
+		os.Exit(2)
+	}
+	Logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+
+	baseURL := strings.TrimRight(*target, "/")
+	orgID := *org
+	if baseURL == "" {
+		started := time.Now()
+		m := seedOrganization(seedOptions{
+			Employees: *employees,
+			Managers:  *managers,
+			Orders:    *orders,
+			Items:     *items,
+			Days:      *days,
+			Seed:      *seed,
+		})
+		fmt.Printf("seeded %d employees, %d orders and %d demand hours in %s\n",
+			len(m.users)-1, len(m.orders), demandHours(m.demand), time.Since(started).Round(time.Millisecond))
+
+		if *mlURL == "" {
+			// answering 503 makes the schedule handler fall back to the heuristic scheduler
+			ml := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, `{"detail":"load test"}`, http.StatusServiceUnavailable)
+			}))
+			defer ml.Close()
+			*mlURL = ml.URL
+		}
+		os.Setenv("ML_URL", *mlURL)
+
+		server := httptest.NewServer(memoryRouter(m, Logger))
+		defer server.Close()
+		baseURL = server.URL + "/api"
+		orgID = m.org.ID.String()
+	} else if orgID == "" || *token == "" {
+		fmt.Fprintln(os.Stderr, "-org and -token are required with -target")
+		os.Exit(2)
+	}
+
+	endpoints := []endpoint{
+		{Name: "PredictSchedule", Method: http.MethodPost, Path: "/dashboard/schedule/predict", Requests: *scheduleRequests},
+		{Name: "GetAllOrders", Method: http.MethodGet, Path: "/orders/all", Requests: *requests},
+		{Name: "GetInsights", Method: http.MethodGet, Path: "/insights", Requests: *requests},
+		{Name: "GetOrdersInsights", Method: http.MethodGet, Path: "/orders", Requests: *requests},
+		{Name: "GetDeliveryInsights", Method: http.MethodGet, Path: "/deliveries", Requests: *requests},
+		{Name: "GetItemsInsights", Method: http.MethodGet, Path: "/items", Requests: *requests},
+	}
+
+	client := &http.Client{Timeout: 2 * time.Minute, Transport: &http.Transport{MaxIdleConnsPerHost: *concurrency * len(endpoints)}}
+	results := make([]endpointResult, len(endpoints))
+	var wg sync.WaitGroup
+	started := time.Now()
+	for i, e := range endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = e.drive(client, baseURL+"/"+orgID, *token, *concurrency)
+		}()
+	}
+	wg.Wait()
+
+	fmt.Printf("drove %d endpoints with %d clients each in %s\n\n", len(endpoints), *concurrency, time.Since(started).Round(time.Millisecond))
+	writeReport(os.Stdout, results)
+}
+
+// endpoint is one of the endpoints driven by the load test
+type endpoint struct {
+	Name     string
+	Method   string
+	Path     string
+	Requests int
+}
+
+// drive sends the requests of the endpoint from concurrency clients at once and records their latencies,
+// body included. Responses with a 4xx or 5xx status count as errors.
+func (e endpoint) drive(client *http.Client, orgURL, token string, concurrency int) endpointResult {
+	result := endpointResult{Name: e.Name}
+	var mu sync.Mutex
+	var sent atomic.Int64
+	var wg sync.WaitGroup
+
+	started := time.Now()
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for sent.Add(1) <= int64(e.Requests) {
+				latency, err := e.send(client, orgURL, token)
+				mu.Lock()
+				result.Latencies = append(result.Latencies, latency)
+				if err != nil {
+					result.Errors++
+					result.LastError = err.Error()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	result.Elapsed = time.Since(started)
+	return result
+}
+
+func (e endpoint) send(client *http.Client, orgURL, token string) (time.Duration, error) {
+	req, err := http.NewRequest(e.Method, orgURL+e.Path, nil)
+	if err != nil {
+		return 0, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	started := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return time.Since(started), err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	latency := time.Since(started)
+	if err != nil {
+		return latency, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return latency, fmt.Errorf("status %d: %.200s", resp.StatusCode, body)
+	}
+	return latency, nil
+}
+
+// memoryRouter serves the driven endpoints on the same paths as the API, every request authenticated as
+// the admin of the synthetic organization
+func memoryRouter(m *memoryOrg, Logger *slog.Logger) http.Handler {
+	gin.SetMode(gin.ReleaseMode)
+
+	userStore := memoryUserStore{memoryOrg: m}
+	orderStore := memoryOrderStore{memoryOrg: m}
+	statusStore := memoryStatusStore{memoryOrg: m}
+
+	orderHandler := api.NewOrderHandler(orderStore, nil, nil, nil, statusStore, memoryCustomFieldStore{}, nil, Logger)
+	insightHandler := api.NewInsightHandler(memoryInsightStore{memoryOrg: m}, Logger)
+	scheduleHandler := api.NewScheduleHandler(
+		userStore,
+		memoryScheduleStore{memoryOrg: m},
+		Logger,
+		memoryOrgStore{memoryOrg: m},
+		memoryRulesStore{memoryOrg: m},
+		memoryUserRolesStore{memoryOrg: m},
+		memoryOperatingHoursStore{memoryOrg: m},
+		orderStore,
+		nil,
+		memoryDemandStore{memoryOrg: m},
+		memoryRolesStore{memoryOrg: m},
+		memoryPreferencesStore{memoryOrg: m},
+		nil,
+		nil,
+		memoryExceptionStore{},
+		statusStore,
+		memoryComplianceStore{},
+		memoryVersionStore{memoryOrg: m},
+	)
+
+	admin := m.users[0]
+	r := gin.New()
+	r.Use(gin.Recovery())
+	organization := r.Group("/api/:org")
+	organization.Use(func(c *gin.Context) {
+		c.Set("user", admin)
+		c.Next()
+	})
+	organization.POST("/dashboard/schedule/predict", scheduleHandler.PredictScheduleHandler)
+	organization.GET("/orders/all", orderHandler.GetAllOrders)
+	organization.GET("/insights", insightHandler.GetInsightsHandler)
+	organization.GET("/orders", orderHandler.GetOrdersInsights)
+	organization.GET("/deliveries", orderHandler.GetDeliveryInsights)
+	organization.GET("/items", orderHandler.GetItemsInsights)
+	return r
+}
+
+func demandHours(demand database.DemandPredictResponse) int {
+	hours := 0
+	for _, day := range demand.Days {
+		hours += len(day.Hours)
+	}
+	return hours
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// The in-memory stores only implement the methods the driven endpoints call. The embedded interfaces are
+// nil, so an endpoint reaching for anything else panics and the request shows up as an error in the report.
+
+// memoryOrg is the synthetic organization shared by the in-memory stores
+type memoryOrg struct {
+	mu sync.RWMutex
+
+	org        database.Organization
+	rules      database.OrganizationRules
+	hours      []database.OperatingHours
+	roles      []database.OrganizationRole
+	users      []*database.User
+	usersByID  map[uuid.UUID]*database.User
+	userRoles  map[uuid.UUID][]string
+	prefs      map[uuid.UUID][]database.EmployeePreference
+	demand     database.DemandPredictResponse
+	items      []database.Item
+	orders     []database.Order // newest first, like the orders of the Postgres store
+	schedules  map[uuid.UUID][]database.Schedule
+	versions   []database.ScheduleVersion
+	statusLogs int
+}
+
+type memoryUserStore struct {
+	database.UserStore
+	*memoryOrg
+}
+
+func (s memoryUserStore) GetUserByID(id uuid.UUID) (*database.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	user, ok := s.usersByID[id]
+	if !ok {
+		return nil, fmt.Errorf("user %s not found", id)
+	}
+	return user, nil
+}
+
+func (s memoryUserStore) GetUsersByOrganization(orgID uuid.UUID) ([]*database.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]*database.User(nil), s.users...), nil
+}
+
+type memoryOrgStore struct {
+	database.OrgStore
+	*memoryOrg
+}
+
+func (s memoryOrgStore) GetOrganizationByID(id uuid.UUID) (*database.Organization, error) {
+	org := s.org
+	return &org, nil
+}
+
+type memoryRulesStore struct {
+	database.RulesStore
+	*memoryOrg
+}
+
+func (s memoryRulesStore) GetRulesByOrganizationID(orgID uuid.UUID) (*database.OrganizationRules, error) {
+	rules := s.rules
+	return &rules, nil
+}
+
+type memoryOperatingHoursStore struct {
+	database.OperatingHoursStore
+	*memoryOrg
+}
+
+func (s memoryOperatingHoursStore) GetOperatingHours(orgID uuid.UUID) ([]database.OperatingHours, error) {
+	return append([]database.OperatingHours(nil), s.hours...), nil
+}
+
+type memoryExceptionStore struct {
+	database.OperatingHoursExceptionStore
+}
+
+func (memoryExceptionStore) GetExceptionsBetween(orgID uuid.UUID, from, to time.Time) ([]database.OperatingHoursException, error) {
+	return nil, nil
+}
+
+type memoryRolesStore struct {
+	database.RolesStore
+	*memoryOrg
+}
+
+func (s memoryRolesStore) GetRolesByOrganizationID(orgID uuid.UUID) ([]database.OrganizationRole, error) {
+	return append([]database.OrganizationRole(nil), s.roles...), nil
+}
+
+type memoryUserRolesStore struct {
+	database.UserRolesStore
+	*memoryOrg
+}
+
+func (s memoryUserRolesStore) GetUserRoles(userID uuid.UUID, orgID uuid.UUID) ([]string, error) {
+	return s.userRoles[userID], nil
+}
+
+type memoryPreferencesStore struct {
+	database.PreferencesStore
+	*memoryOrg
+}
+
+func (s memoryPreferencesStore) GetPreferencesByEmployeeID(employeeID uuid.UUID) ([]database.EmployeePreference, error) {
+	return s.prefs[employeeID], nil
+}
+
+func (s memoryPreferencesStore) GetUnavailability(employeeID uuid.UUID, from, to time.Time) ([]database.EmployeeUnavailability, error) {
+	return nil, nil
+}
+
+type memoryComplianceStore struct {
+	database.EmployeeComplianceStore
+}
+
+func (memoryComplianceStore) GetWorkPermitExpiries(orgID uuid.UUID) (map[uuid.UUID]time.Time, error) {
+	return map[uuid.UUID]time.Time{}, nil
+}
+
+type memoryDemandStore struct {
+	database.DemandStore
+	*memoryOrg
+}
+
+func (s memoryDemandStore) GetLatestDemandHeatMap(orgID uuid.UUID) (*database.DemandPredictResponse, error) {
+	demand := s.demand
+	return &demand, nil
+}
+
+type memoryScheduleStore struct {
+	database.ScheduleStore
+	*memoryOrg
+}
+
+func (s memoryScheduleStore) StoreScheduleForUser(orgID uuid.UUID, userID uuid.UUID, schedule *database.Schedule, actorID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schedules[userID] = append(s.schedules[userID], *schedule)
+	return nil
+}
+
+type memoryVersionStore struct {
+	database.ScheduleVersionStore
+	*memoryOrg
+}
+
+func (s memoryVersionStore) StoreScheduleVersion(orgID uuid.UUID, version *database.ScheduleVersion) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	version.ID = uuid.New()
+	version.CreatedAt = time.Now()
+	s.versions = append(s.versions, *version)
+	return nil
+}
+
+type memoryStatusStore struct {
+	database.StatusStore
+	*memoryOrg
+}
+
+func (s memoryStatusStore) RecordEvent(orgID uuid.UUID, event *database.StatusEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statusLogs++
+	return nil
+}
+
+type memoryCustomFieldStore struct {
+	database.CustomFieldStore
+}
+
+func (memoryCustomFieldStore) ListFieldDefinitions(orgID uuid.UUID, entityType string) ([]database.CustomFieldDefinition, error) {
+	return nil, nil
+}
+
+type memoryOrderStore struct {
+	database.OrderStore
+	*memoryOrg
+}
+
+func (s memoryOrderStore) GetOrders(orgID uuid.UUID, filter database.OrderFilter) ([]database.Order, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	orders := make([]database.Order, 0, len(s.orders))
+	for _, order := range s.orders {
+		if filter.From != nil && order.CreateTime.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && !order.CreateTime.Before(*filter.To) {
+			continue
+		}
+		if !matchesAny(order.OrderStatus, filter.Statuses) || !matchesAny(order.OrderType, filter.Types) || !matchesAny(order.Channel, filter.Channels) {
+			continue
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+func (s memoryOrderStore) GetOrdersInsights(orgID uuid.UUID) ([]database.Insight, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	now := time.Now()
+	today := now.Format(time.DateOnly)
+	var weekly, daily int
+	perDay := make(map[string]int)
+	perHour := make(map[int]int)
+	for _, order := range s.orders {
+		if now.Sub(order.CreateTime) <= 7*24*time.Hour {
+			weekly++
+		}
+		if order.CreateTime.Format(time.DateOnly) == today {
+			daily++
+		}
+		perDay[order.CreateTime.Weekday().String()]++
+		perHour[order.CreateTime.Hour()]++
+	}
+	return []database.Insight{
+		{Title: "Total Orders (All Time)", Statistic: fmt.Sprintf("%d", len(s.orders))},
+		{Title: "Orders (Last 7 Days)", Statistic: fmt.Sprintf("%d", weekly)},
+		{Title: "Orders (Today)", Statistic: fmt.Sprintf("%d", daily)},
+		{Title: "Busiest Day (Orders)", Statistic: busiest(perDay)},
+		{Title: "Busiest Hour (Orders)", Statistic: busiest(perHour)},
+	}, nil
+}
+
+func (s memoryOrderStore) GetDeliveryInsights(orgID uuid.UUID) ([]database.Insight, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var deliveries int
+	var minutes float64
+	for _, order := range s.orders {
+		if order.DeliveryStatus == nil {
+			continue
+		}
+		deliveries++
+		minutes += order.DeliveryStatus.DeliveredTime.Sub(order.DeliveryStatus.OutForDeliveryTime).Minutes()
+	}
+	average := 0.0
+	if deliveries > 0 {
+		average = minutes / float64(deliveries)
+	}
+	return []database.Insight{
+		{Title: "Total Deliveries (All Time)", Statistic: fmt.Sprintf("%d", deliveries)},
+		{Title: "Average Delivery Time", Statistic: fmt.Sprintf("%.1f minutes", average)},
+	}, nil
+}
+
+func (s memoryOrderStore) GetDeliveryVolume(orgID uuid.UUID, from, to time.Time) ([]database.DeliveryVolume, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	counts := make(map[[2]int]int)
+	for _, order := range s.orders {
+		if order.OrderType != "delivery" || order.CreateTime.Before(from) || !order.CreateTime.Before(to) {
+			continue
+		}
+		counts[[2]int{int(order.CreateTime.Weekday()), order.CreateTime.Hour()}]++
+	}
+	keys := make([][2]int, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	volume := make([]database.DeliveryVolume, 0, len(keys))
+	for _, key := range keys {
+		volume = append(volume, database.DeliveryVolume{Weekday: database.ValidDays[key[0]], Hour: key[1], Orders: counts[key]})
+	}
+	return volume, nil
+}
+
+func (s memoryOrderStore) GetItemsInsights(orgID uuid.UUID) ([]database.Insight, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sold := make(map[uuid.UUID]int)
+	for _, order := range s.orders {
+		for _, item := range order.OrderItems {
+			sold[item.ItemID] += *item.Quantity
+		}
+	}
+	names := make(map[string]int, len(sold))
+	for _, item := range s.items {
+		names[item.Name] = sold[item.ItemID]
+	}
+	return []database.Insight{
+		{Title: "Total Items", Statistic: fmt.Sprintf("%d", len(s.items))},
+		{Title: "Most Selling Item", Statistic: busiest(names)},
+	}, nil
+}
+
+type memoryInsightStore struct {
+	database.InsightStore
+	*memoryOrg
+}
+
+func (s memoryInsightStore) GetInsightsForAdmin(orgID uuid.UUID) ([]database.Insight, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var employees, managers int
+	var revenue float64
+	for _, user := range s.users {
+		switch user.UserRole {
+		case "employee":
+			employees++
+		case "manager":
+			managers++
+		}
+	}
+	for _, order := range s.orders {
+		revenue += *order.TotalAmount
+	}
+	return []database.Insight{
+		{Title: "Number of Employees", Statistic: fmt.Sprintf("%d", employees)},
+		{Title: "Number of Managers", Statistic: fmt.Sprintf("%d", managers)},
+		{Title: "Total Revenue", Statistic: fmt.Sprintf("%.2f", revenue)},
+		{Title: "Number of Orders", Statistic: fmt.Sprintf("%d", len(s.orders))},
+	}, nil
+}
+
+// matchesAny reports whether value is one of values, case insensitively. No values match everything.
+func matchesAny(value string, values []string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// busiest returns the key with the highest count, the smallest key on ties so reports are stable
+func busiest[K string | int](counts map[K]int) string {
+	if len(counts) == 0 {
+		return "N/A"
+	}
+	keys := make([]K, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return fmt.Sprint(keys[0])
+}
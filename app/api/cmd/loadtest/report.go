@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// endpointResult holds the latencies of the requests sent to one endpoint
+type endpointResult struct {
+	Name      string
+	Latencies []time.Duration
+	Errors    int
+	LastError string
+	Elapsed   time.Duration
+}
+
+// percentile returns the nearest-rank percentile p (0-100) of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(float64(len(sorted))*p/100+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// writeReport prints one line per endpoint with its P50, P95 and P99 latencies and throughput
+func writeReport(w io.Writer, results []endpointResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "endpoint\trequests\terrors\tp50\tp95\tp99\tmax\treq/s\t")
+	for _, result := range results {
+		sorted := append([]time.Duration(nil), result.Latencies...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		throughput := 0.0
+		if result.Elapsed > 0 {
+			throughput = float64(len(sorted)) / result.Elapsed.Seconds()
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t%.1f\t\n",
+			result.Name, len(sorted), result.Errors,
+			roundLatency(percentile(sorted, 50)), roundLatency(percentile(sorted, 95)),
+			roundLatency(percentile(sorted, 99)), roundLatency(percentile(sorted, 100)), throughput)
+	}
+	tw.Flush()
+
+	for _, result := range results {
+		if result.Errors > 0 {
+			fmt.Fprintf(w, "%s: %d failed, last error: %s\n", result.Name, result.Errors, result.LastError)
+		}
+	}
+}
+
+func roundLatency(d time.Duration) time.Duration {
+	if d >= time.Second {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(10 * time.Microsecond)
+}
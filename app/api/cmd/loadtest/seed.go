@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// seedOptions sizes the synthetic organization
+type seedOptions struct {
+	Employees int
+	Managers  int
+	Orders    int
+	Items     int
+	Days      int // history the orders are spread over
+	Seed      int64
+}
+
+// scheduling roles of the synthetic organization, employees get one or two of them
+var seedRoles = []string{"cook", "cashier", "waiter", "driver"}
+
+// seedOrganization builds an organization with its rules, opening hours, roles, staff with weekly
+// availability, menu, order history and a demand heatmap for every hour the organization is open in
+// the coming week. The same seed builds the same data, only the IDs differ between runs.
+func seedOrganization(opts seedOptions) *memoryOrg {
+	rng := rand.New(rand.NewSource(opts.Seed))
+	now := time.Now()
+	orgID := uuid.New()
+
+	latitude, longitude, rating := 30.0444, 31.2357, 4.3
+	shiftsPerDay := 2
+	budget := 60000.0
+	m := &memoryOrg{
+		org: database.Organization{
+			ID:        orgID,
+			Name:      "Load Test Bistro",
+			Address:   "1 Synthetic Street",
+			Email:     "loadtest@clockwise.local",
+			Type:      "restaurant",
+			Location:  database.Location{Latitude: &latitude, Longitude: &longitude},
+			Rating:    &rating,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		rules: database.OrganizationRules{
+			OrganizationID:       orgID,
+			ShiftMaxHours:        8,
+			ShiftMinHours:        4,
+			MaxWeeklyHours:       40,
+			MinWeeklyHours:       10,
+			NumberOfShiftsPerDay: &shiftsPerDay,
+			MinRestSlots:         8,
+			SlotLenHour:          1,
+			MinShiftLengthSlots:  4,
+			Delivery:             true,
+			WaitingTime:          15,
+			AcceptingOrders:      true,
+			WeeklyLaborBudget:    &budget,
+		},
+		usersByID: make(map[uuid.UUID]*database.User),
+		userRoles: make(map[uuid.UUID][]string),
+		prefs:     make(map[uuid.UUID][]database.EmployeePreference),
+		schedules: make(map[uuid.UUID][]database.Schedule),
+	}
+
+	for _, day := range database.ValidDays {
+		m.hours = append(m.hours, database.OperatingHours{OrganizationID: orgID, Weekday: day, OpeningTime: "09:00:00", ClosingTime: "23:00:00"})
+	}
+
+	for _, name := range seedRoles {
+		itemsPerHour := 12
+		independent := name == "driver"
+		m.roles = append(m.roles, database.OrganizationRole{
+			OrganizationID:      orgID,
+			Role:                name,
+			MinNeededPerShift:   1,
+			ItemsPerRolePerHour: &itemsPerHour,
+			NeedForDemand:       name == "cook" || name == "cashier",
+			Independent:         &independent,
+		})
+	}
+
+	admin := &database.User{ID: uuid.New(), FullName: "Load Test Admin", Email: "admin@loadtest.local", UserRole: "admin", OrganizationID: orgID}
+	m.addUser(admin, []string{"admin"})
+
+	for i := 0; i < opts.Managers+opts.Employees; i++ {
+		role := "employee"
+		if i < opts.Managers {
+			role = "manager"
+		}
+		wage := 12 + float64(rng.Intn(900))/100
+		maxHours := 24 + rng.Intn(17)
+		preferredHours := maxHours - rng.Intn(8)
+		maxConsec := 8
+		user := &database.User{
+			ID:                    uuid.New(),
+			FullName:              fmt.Sprintf("Employee %03d", i+1),
+			Email:                 fmt.Sprintf("employee%03d@loadtest.local", i+1),
+			UserRole:              role,
+			SalaryPerHour:         &wage,
+			OrganizationID:        orgID,
+			MaxHoursPerWeek:       &maxHours,
+			PreferredHoursPerWeek: &preferredHours,
+			MaxConsecSlots:        &maxConsec,
+			CreatedAt:             now,
+			UpdatedAt:             now,
+		}
+		roles := []string{seedRoles[i%len(seedRoles)]}
+		if rng.Intn(3) == 0 {
+			roles = append(roles, seedRoles[rng.Intn(len(seedRoles))])
+		}
+		m.addUser(user, roles)
+		m.prefs[user.ID] = seedPreferences(rng, user.ID)
+	}
+
+	for i := 0; i < opts.Items; i++ {
+		price := 3 + float64(rng.Intn(2200))/100
+		needed := 1 + rng.Intn(2)
+		m.items = append(m.items, database.Item{ItemID: uuid.New(), Name: fmt.Sprintf("Item %02d", i+1), Price: &price, NeededNumEmployeesToPrepare: &needed})
+	}
+
+	m.orders = seedOrders(rng, m, opts, now)
+	m.demand = seedDemand(rng, m.org.Name, now)
+	return m
+}
+
+func (m *memoryOrg) addUser(user *database.User, roles []string) {
+	m.users = append(m.users, user)
+	m.usersByID[user.ID] = user
+	m.userRoles[user.ID] = roles
+}
+
+// seedPreferences makes the employee available on five or six days, preferring a morning or an
+// evening shift inside the opening hours
+func seedPreferences(rng *rand.Rand, employeeID uuid.UUID) []database.EmployeePreference {
+	offDays := 1 + rng.Intn(2)
+	days := rng.Perm(len(database.ValidDays))[offDays:]
+	morning := rng.Intn(2) == 0
+
+	prefs := make([]database.EmployeePreference, 0, len(days))
+	for _, i := range days {
+		availableFrom, availableTo := "09:00:00", "23:00:00"
+		preferredFrom, preferredTo := "09:00:00", "16:00:00"
+		if !morning {
+			preferredFrom, preferredTo = "16:00:00", "23:00:00"
+		}
+		prefs = append(prefs, database.EmployeePreference{
+			EmployeeID:         employeeID,
+			Day:                database.ValidDays[i],
+			AvailableStartTime: &availableFrom,
+			AvailableEndTime:   &availableTo,
+			PreferredStartTime: &preferredFrom,
+			PreferredEndTime:   &preferredTo,
+		})
+	}
+	return prefs
+}
+
+// seedOrders spreads the orders over the last opts.Days days, busier at lunch and dinner, a fifth of
+// them delivered by one of the drivers
+func seedOrders(rng *rand.Rand, m *memoryOrg, opts seedOptions, now time.Time) []database.Order {
+	var drivers []uuid.UUID
+	for _, user := range m.users {
+		for _, role := range m.userRoles[user.ID] {
+			if role == "driver" {
+				drivers = append(drivers, user.ID)
+			}
+		}
+	}
+	peakHours := []int{9, 10, 11, 12, 12, 13, 13, 13, 14, 15, 16, 17, 18, 19, 19, 19, 20, 20, 21, 22}
+
+	orders := make([]database.Order, opts.Orders)
+	for i := range orders {
+		day := now.AddDate(0, 0, -rng.Intn(opts.Days))
+		created := time.Date(day.Year(), day.Month(), day.Day(), peakHours[rng.Intn(len(peakHours))], rng.Intn(60), rng.Intn(60), 0, time.Local)
+
+		var total float64
+		items := make([]database.OrderItem, 1+rng.Intn(4))
+		for j := range items {
+			item := m.items[rng.Intn(len(m.items))]
+			quantity := 1 + rng.Intn(3)
+			price := *item.Price * float64(quantity)
+			total += price
+			items[j] = database.OrderItem{ItemID: item.ItemID, Quantity: &quantity, TotalPrice: &price}
+		}
+		discount := 0.0
+		if rng.Intn(10) == 0 {
+			discount = total * 0.1
+		}
+		rating := float64(3 + rng.Intn(3))
+
+		order := database.Order{
+			OrderID:        uuid.New(),
+			OrganizationID: m.org.ID,
+			CreateTime:     created,
+			OrderType:      "dine in",
+			OrderStatus:    "closed",
+			TotalAmount:    &total,
+			DiscountAmount: &discount,
+			Rating:         &rating,
+			Channel:        database.OrderChannels[rng.Intn(len(database.OrderChannels))],
+			OrderItems:     items,
+			OrderCount:     len(items),
+		}
+		if rng.Intn(5) == 0 && len(drivers) > 0 {
+			latitude := *m.org.Location.Latitude + (rng.Float64()-0.5)/10
+			longitude := *m.org.Location.Longitude + (rng.Float64()-0.5)/10
+			out := created.Add(time.Duration(10+rng.Intn(20)) * time.Minute)
+			order.OrderType = "delivery"
+			order.DeliveryStatus = &database.OrderDelivery{
+				OrderID:            order.OrderID,
+				DriverID:           drivers[rng.Intn(len(drivers))],
+				DeliveryLocation:   database.Location{Latitude: &latitude, Longitude: &longitude},
+				OutForDeliveryTime: out,
+				DeliveredTime:      out.Add(time.Duration(15+rng.Intn(35)) * time.Minute),
+				DeliveryStatus:     "delivered",
+			}
+		}
+		orders[i] = order
+	}
+
+	sort.Slice(orders, func(i, j int) bool { return orders[i].CreateTime.After(orders[j].CreateTime) })
+	return orders
+}
+
+// seedDemand predicts orders and items for every opening hour of the next seven days
+func seedDemand(rng *rand.Rand, name string, now time.Time) database.DemandPredictResponse {
+	demand := database.DemandPredictResponse{
+		RestaurantName:   name,
+		PredictionPerion: fmt.Sprintf("%s to %s", now.Format(time.DateOnly), now.AddDate(0, 0, 6).Format(time.DateOnly)),
+	}
+	for i := 0; i < 7; i++ {
+		date := now.AddDate(0, 0, i)
+		day := database.PredictionDay{
+			Day:  strings.ToLower(date.Weekday().String()),
+			Date: time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC),
+		}
+		for hour := 9; hour < 23; hour++ {
+			orders := 5 + rng.Intn(10)
+			if (hour >= 12 && hour <= 14) || (hour >= 18 && hour <= 21) {
+				orders += 15 + rng.Intn(15)
+			}
+			day.Hours = append(day.Hours, database.PredictionHour{HourNo: hour, OrderCount: orders, ItemCount: orders * (2 + rng.Intn(2))})
+		}
+		demand.Days = append(demand.Days, day)
+	}
+	return demand
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/clockwise/clockwise/backend/internal/mlcontract"
+)
+
+// mlcontract checks the ML contract fixtures against the Go types the handlers send and read, and exits
+// non-zero on drift so CI can run it next to the ML service's pytest check of the same fixtures.
+func main() {
+	verbose := flag.Bool("v", false, "list the checked contracts")
+	flag.Parse()
+
+	drifts, err := mlcontract.Verify()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load the contracts: %s\n", err)
+		os.Exit(2)
+	}
+
+	if *verbose {
+		contracts, _ := mlcontract.Load(mlcontract.Fixtures)
+		for _, contract := range contracts {
+			fmt.Printf("%s: %s, %s\n", contract.Endpoint, contract.Request, contract.Response)
+		}
+	}
+
+	if len(drifts) > 0 {
+		for _, drift := range drifts {
+			fmt.Fprintln(os.Stderr, drift)
+		}
+		fmt.Fprintf(os.Stderr, "%d differences between the ML contract and the Go types\n", len(drifts))
+		os.Exit(1)
+	}
+	fmt.Println("ML contract OK")
+}
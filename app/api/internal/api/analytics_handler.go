@@ -0,0 +1,124 @@
+package api
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// Slots of the staffing charts when the rules have no slot length dividing the day
+const defaultAnalyticsSlotMinutes = 60
+
+// Quarters of the turnover report by default and at most
+const (
+	defaultTurnoverQuarters = 4
+	maxTurnoverQuarters     = 20
+)
+
+type AnalyticsHandler struct {
+	StaffingAnalyticsStore database.StaffingAnalyticsStore
+	RulesStore             database.RulesStore
+	Logger                 *slog.Logger
+}
+
+func NewAnalyticsHandler(staffingAnalyticsStore database.StaffingAnalyticsStore, rulesStore database.RulesStore, logger *slog.Logger) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		StaffingAnalyticsStore: staffingAnalyticsStore,
+		RulesStore:             rulesStore,
+		Logger:                 logger,
+	}
+}
+
+// GetStaffingVsDemandHandler returns the predicted demand, the actual orders and the scheduled headcount
+// of every slot of a day (?date=, today by default) as aligned arrays for charting. Slots are as long as
+// the slot length of the rules.
+func (ah *AnalyticsHandler) GetStaffingVsDemandHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access staffing analytics"})
+		return
+	}
+
+	now := time.Now()
+	date := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if value := c.Query("date"); value != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+			return
+		}
+		date = parsed
+	}
+
+	slotMinutes := defaultAnalyticsSlotMinutes
+	rules, err := ah.RulesStore.GetRulesByOrganizationID(user.OrganizationID)
+	if err != nil {
+		ah.Logger.Warn("failed to get rules, using hourly slots", "error", err, "org_id", user.OrganizationID)
+	} else if rules != nil {
+		if minutes := int(math.Round(rules.SlotLenHour * 60)); minutes > 0 && 1440%minutes == 0 {
+			slotMinutes = minutes
+		}
+	}
+
+	result, err := ah.StaffingAnalyticsStore.GetStaffingVsDemand(user.OrganizationID, date, slotMinutes)
+	if err != nil {
+		ah.Logger.Error("failed to get staffing vs demand", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve staffing vs demand"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Staffing vs demand retrieved successfully",
+		"data":    result,
+	})
+}
+
+// GetTurnoverHandler returns the headcount changes, voluntary and involuntary exits, average tenure of the
+// leavers and 90-day new-hire retention of the last ?quarters= quarters (4 by default, at most 20), oldest
+// first and ending with the current quarter
+func (ah *AnalyticsHandler) GetTurnoverHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access turnover analytics"})
+		return
+	}
+
+	quarters := defaultTurnoverQuarters
+	if value := c.Query("quarters"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxTurnoverQuarters {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "quarters must be a number between 1 and 20"})
+			return
+		}
+		quarters = parsed
+	}
+
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, -3*(quarters-1), 0)
+
+	result, err := ah.StaffingAnalyticsStore.GetTurnover(user.OrganizationID, from, quarters)
+	if err != nil {
+		ah.Logger.Error("failed to get turnover", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve turnover"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Turnover retrieved successfully",
+		"data":    result,
+	})
+}
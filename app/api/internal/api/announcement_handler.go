@@ -0,0 +1,361 @@
+package api
+
+import (
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AnnouncementHandler struct {
+	AnnouncementStore database.AnnouncementStore
+	UserStore         database.UserStore
+	UserRolesStore    database.UserRolesStore
+	RolesStore        database.RolesStore
+	EmailService      service.EmailService
+	Logger            *slog.Logger
+}
+
+func NewAnnouncementHandler(announcementStore database.AnnouncementStore, userStore database.UserStore, userRolesStore database.UserRolesStore, rolesStore database.RolesStore, emailService service.EmailService, logger *slog.Logger) *AnnouncementHandler {
+	return &AnnouncementHandler{
+		AnnouncementStore: announcementStore,
+		UserStore:         userStore,
+		UserRolesStore:    userRolesStore,
+		RolesStore:        rolesStore,
+		EmailService:      emailService,
+		Logger:            logger,
+	}
+}
+
+type CreateAnnouncementRequest struct {
+	Title       string     `json:"title" binding:"required,max=255"`
+	Message     string     `json:"message" binding:"required"`
+	Pinned      bool       `json:"pinned"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	TargetRoles []string   `json:"target_roles" binding:"omitempty,dive,required"`
+	Notify      []string   `json:"notify" binding:"omitempty,dive,oneof=email"`
+}
+
+// CreateAnnouncementHandler posts an announcement and optionally emails the targeted staff
+func (ah *AnnouncementHandler) CreateAnnouncementHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		ah.Logger.Warn("forbidden announcement creation", "user_id", user.ID, "role", user.UserRole)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can post announcements"})
+		return
+	}
+
+	var req CreateAnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ah.Logger.Warn("invalid announcement request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+		return
+	}
+
+	for _, role := range req.TargetRoles {
+		if role == "admin" || role == "manager" || role == "employee" {
+			continue
+		}
+		existingRole, err := ah.RolesStore.GetRoleByName(user.OrganizationID, role)
+		if err != nil {
+			ah.Logger.Error("failed to check target role", "error", err, "role", role)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate target roles"})
+			return
+		}
+		if existingRole == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown target role: " + role})
+			return
+		}
+	}
+
+	authorID := user.ID
+	announcement := &database.Announcement{
+		OrganizationID: user.OrganizationID,
+		AuthorID:       &authorID,
+		AuthorName:     user.FullName,
+		Title:          req.Title,
+		Message:        req.Message,
+		Pinned:         req.Pinned,
+		TargetRoles:    req.TargetRoles,
+		ExpiresAt:      req.ExpiresAt,
+	}
+
+	if err := ah.AnnouncementStore.CreateAnnouncement(announcement); err != nil {
+		ah.Logger.Error("failed to create announcement", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create announcement"})
+		return
+	}
+
+	notified := 0
+	if slices.Contains(req.Notify, "email") {
+		notified = ah.emailAnnouncement(user, announcement)
+	}
+
+	ah.Logger.Info("announcement posted", "id", announcement.ID, "org_id", user.OrganizationID, "notified", notified)
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Announcement posted successfully",
+		"data":     announcement,
+		"notified": notified,
+	})
+}
+
+// emailAnnouncement sends the announcement to every targeted user except the author and returns
+// how many were emailed. Failures are logged, the announcement is already posted.
+func (ah *AnnouncementHandler) emailAnnouncement(author *database.User, announcement *database.Announcement) int {
+	users, err := ah.UserStore.GetUsersByOrganization(author.OrganizationID)
+	if err != nil {
+		ah.Logger.Error("failed to get announcement recipients", "error", err, "org_id", author.OrganizationID)
+		return 0
+	}
+
+	var recipients []string
+	for _, u := range users {
+		if u.ID == author.ID || u.Email == "" {
+			continue
+		}
+		roles, err := ah.userRoles(u)
+		if err != nil {
+			ah.Logger.Error("failed to get recipient roles", "error", err, "user_id", u.ID)
+			continue
+		}
+		if announcementTargets(announcement, roles) {
+			recipients = append(recipients, u.Email)
+		}
+	}
+
+	if len(recipients) == 0 {
+		return 0
+	}
+	if err := ah.EmailService.SendAnnouncementEmail(recipients, author.FullName, announcement.Title, announcement.Message); err != nil {
+		ah.Logger.Error("failed to send announcement email", "error", err, "id", announcement.ID)
+		return 0
+	}
+	return len(recipients)
+}
+
+// GetAnnouncementsHandler lists every announcement of the organization with read counts
+func (ah *AnnouncementHandler) GetAnnouncementsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can manage announcements"})
+		return
+	}
+
+	announcements, err := ah.AnnouncementStore.GetAnnouncementsForOrganization(user.OrganizationID)
+	if err != nil {
+		ah.Logger.Error("failed to get announcements", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve announcements"})
+		return
+	}
+	if announcements == nil {
+		announcements = []database.Announcement{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Announcements retrieved successfully",
+		"data":    announcements,
+	})
+}
+
+// DeleteAnnouncementHandler removes an announcement
+func (ah *AnnouncementHandler) DeleteAnnouncementHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can manage announcements"})
+		return
+	}
+
+	announcementID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid announcement ID"})
+		return
+	}
+
+	if err := ah.AnnouncementStore.DeleteAnnouncement(user.OrganizationID, announcementID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Announcement not found"})
+			return
+		}
+		ah.Logger.Error("failed to delete announcement", "error", err, "id", announcementID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete announcement"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Announcement deleted successfully"})
+}
+
+// GetAnnouncementReadsHandler returns the read receipts of an announcement
+func (ah *AnnouncementHandler) GetAnnouncementReadsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can view read receipts"})
+		return
+	}
+
+	announcementID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid announcement ID"})
+		return
+	}
+
+	if _, err := ah.AnnouncementStore.GetAnnouncementByID(user.OrganizationID, announcementID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Announcement not found"})
+			return
+		}
+		ah.Logger.Error("failed to get announcement", "error", err, "id", announcementID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve announcement"})
+		return
+	}
+
+	reads, err := ah.AnnouncementStore.GetAnnouncementReads(announcementID)
+	if err != nil {
+		ah.Logger.Error("failed to get announcement reads", "error", err, "id", announcementID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve read receipts"})
+		return
+	}
+	if reads == nil {
+		reads = []database.AnnouncementRead{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Read receipts retrieved successfully",
+		"data":    reads,
+		"total":   len(reads),
+	})
+}
+
+// GetMyAnnouncementsHandler lists the active announcements addressed to the current user
+func (ah *AnnouncementHandler) GetMyAnnouncementsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	roles, err := ah.userRoles(user)
+	if err != nil {
+		ah.Logger.Error("failed to get user roles", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve announcements"})
+		return
+	}
+
+	announcements, err := ah.AnnouncementStore.GetActiveAnnouncementsForUser(user.OrganizationID, user.ID, roles)
+	if err != nil {
+		ah.Logger.Error("failed to get announcements for user", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve announcements"})
+		return
+	}
+	if announcements == nil {
+		announcements = []database.Announcement{}
+	}
+
+	unread := 0
+	for _, announcement := range announcements {
+		if announcement.ReadAt == nil {
+			unread++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Announcements retrieved successfully",
+		"data":    announcements,
+		"unread":  unread,
+	})
+}
+
+// MarkAnnouncementReadHandler records that the current user has read an announcement
+func (ah *AnnouncementHandler) MarkAnnouncementReadHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	announcementID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid announcement ID"})
+		return
+	}
+
+	announcement, err := ah.AnnouncementStore.GetAnnouncementByID(user.OrganizationID, announcementID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Announcement not found"})
+			return
+		}
+		ah.Logger.Error("failed to get announcement", "error", err, "id", announcementID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve announcement"})
+		return
+	}
+
+	roles, err := ah.userRoles(user)
+	if err != nil {
+		ah.Logger.Error("failed to get user roles", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark announcement as read"})
+		return
+	}
+	// announcements for other roles are hidden from the user, so they are reported as missing
+	if !announcementTargets(announcement, roles) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Announcement not found"})
+		return
+	}
+
+	if err := ah.AnnouncementStore.MarkAnnouncementRead(announcementID, user.ID); err != nil {
+		ah.Logger.Error("failed to mark announcement read", "error", err, "id", announcementID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark announcement as read"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Announcement marked as read"})
+}
+
+// userRoles returns the base role of the user together with their organization roles
+func (ah *AnnouncementHandler) userRoles(user *database.User) ([]string, error) {
+	roles, err := ah.UserRolesStore.GetUserRoles(user.ID, user.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(roles, user.UserRole) {
+		roles = append(roles, user.UserRole)
+	}
+	return roles, nil
+}
+
+func announcementTargets(announcement *database.Announcement, roles []string) bool {
+	if len(announcement.TargetRoles) == 0 {
+		return true
+	}
+	for _, role := range roles {
+		if slices.Contains(announcement.TargetRoles, role) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,312 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Longest an interview or trial shift can run
+const maxApplicantSessionHours = 12
+
+type ApplicantSessionHandler struct {
+	JobPostingStore       database.JobPostingStore
+	ApplicantSessionStore database.ApplicantSessionStore
+	UserStore             database.UserStore
+	EmailService          service.EmailService
+	Logger                *slog.Logger
+}
+
+func NewApplicantSessionHandler(jobPostingStore database.JobPostingStore, applicantSessionStore database.ApplicantSessionStore,
+	userStore database.UserStore, emailService service.EmailService, logger *slog.Logger) *ApplicantSessionHandler {
+	return &ApplicantSessionHandler{
+		JobPostingStore:       jobPostingStore,
+		ApplicantSessionStore: applicantSessionStore,
+		UserStore:             userStore,
+		EmailService:          emailService,
+		Logger:                logger,
+	}
+}
+
+// ScheduleApplicantSessionRequest books an interview or trial shift. The manager defaults to the
+// manager making the request.
+type ScheduleApplicantSessionRequest struct {
+	Kind      string     `json:"kind" binding:"required,oneof=interview trial"`
+	ManagerID *uuid.UUID `json:"manager_id"`
+	Date      string     `json:"date" binding:"required"`
+	StartTime string     `json:"start_time" binding:"required"`
+	EndTime   string     `json:"end_time" binding:"required"`
+	Location  *string    `json:"location" binding:"omitempty,max=255"`
+	Notes     *string    `json:"notes" binding:"omitempty,max=2000"`
+}
+
+// sessionSlot parses the day and HH:MM times of a session request, returning the start and end of the slot
+func sessionSlot(request ScheduleApplicantSessionRequest, now time.Time) (time.Time, time.Time, error) {
+	date, err := time.Parse(time.DateOnly, request.Date)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("date must be in YYYY-MM-DD format")
+	}
+	startClock, err := time.Parse("15:04", request.StartTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("start_time must be in HH:MM format")
+	}
+	endClock, err := time.Parse("15:04", request.EndTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("end_time must be in HH:MM format")
+	}
+
+	start := date.Add(time.Duration(startClock.Hour())*time.Hour + time.Duration(startClock.Minute())*time.Minute)
+	end := date.Add(time.Duration(endClock.Hour())*time.Hour + time.Duration(endClock.Minute())*time.Minute)
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end_time must be after start_time")
+	}
+	if end.Sub(start) > maxApplicantSessionHours*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("a session cannot be longer than %d hours", maxApplicantSessionHours)
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if date.Before(today) {
+		return time.Time{}, time.Time{}, fmt.Errorf("date cannot be in the past")
+	}
+	return start, end, nil
+}
+
+// stageIndex is the position of a stage in the applicant pipeline, -1 if it is unknown
+func stageIndex(stage string) int {
+	for i, s := range applicantStages {
+		if s == stage {
+			return i
+		}
+	}
+	return -1
+}
+
+// ScheduleApplicantSessionHandler books an interview or trial shift for an applicant with a manager who is
+// not on shift or in another session at that time, and sends both of them a calendar invite.
+// Applicants who have not reached the stage of the session yet are moved to it.
+func (ah *ApplicantSessionHandler) ScheduleApplicantSessionHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can schedule interviews"})
+		return
+	}
+
+	applicantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid applicant ID"})
+		return
+	}
+
+	var request ScheduleApplicantSessionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	start, end, err := sessionSlot(request, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	managerID := user.ID
+	if request.ManagerID != nil {
+		managerID = *request.ManagerID
+	} else if user.UserRole != "manager" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "manager_id is required"})
+		return
+	}
+
+	manager, err := ah.UserStore.GetUserByID(managerID)
+	if err != nil || manager == nil || manager.OrganizationID != user.OrganizationID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Manager not found"})
+		return
+	}
+	if manager.UserRole != "manager" && manager.UserRole != "admin" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Sessions can only be held by managers or admins"})
+		return
+	}
+
+	applicant, err := ah.JobPostingStore.GetApplicantByID(user.OrganizationID, applicantID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Applicant not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule session"})
+		return
+	}
+	if applicant.Stage == database.ApplicantStageHired || applicant.Stage == database.ApplicantStageRejected {
+		c.JSON(http.StatusConflict, gin.H{"error": "Applicant is no longer in the hiring pipeline"})
+		return
+	}
+
+	posting, err := ah.JobPostingStore.GetPostingByID(user.OrganizationID, applicant.PostingID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule session"})
+		return
+	}
+
+	date := start.Truncate(24 * time.Hour)
+	conflicts, err := ah.ApplicantSessionStore.GetManagerConflicts(user.OrganizationID, managerID, date, request.StartTime, request.EndTime)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check manager availability"})
+		return
+	}
+	if len(conflicts) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Manager is not available at that time", "conflicts": conflicts})
+		return
+	}
+
+	createdBy := user.ID
+	session := &database.ApplicantSession{
+		ApplicantID:    applicant.ID,
+		ApplicantName:  applicant.FullName,
+		ApplicantEmail: applicant.Email,
+		Kind:           request.Kind,
+		ManagerID:      managerID,
+		ManagerName:    manager.FullName,
+		Date:           date,
+		StartTime:      request.StartTime,
+		EndTime:        request.EndTime,
+		Location:       request.Location,
+		Notes:          request.Notes,
+		CreatedBy:      &createdBy,
+	}
+	if err := ah.ApplicantSessionStore.CreateSession(user.OrganizationID, session); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule session"})
+		return
+	}
+
+	// interview and trial sessions share their name with the pipeline stage they belong to
+	if stageIndex(applicant.Stage) < stageIndex(request.Kind) {
+		if err := ah.JobPostingStore.SetApplicantStage(user.OrganizationID, applicant.ID, request.Kind); err != nil {
+			ah.Logger.Error("failed to move applicant to session stage", "error", err, "applicant_id", applicant.ID)
+		} else {
+			applicant.Stage = request.Kind
+		}
+	}
+
+	invited := ah.sendInvites(session, manager.Email, posting.Title, start, end)
+
+	ah.Logger.Info("applicant session scheduled", "id", session.ID, "applicant_id", applicant.ID, "manager_id", managerID, "by", user.ID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message":         "Session scheduled successfully",
+		"data":            session,
+		"applicant_stage": applicant.Stage,
+		"invites_sent":    invited,
+	})
+}
+
+// sendInvites emails a calendar invite to the applicant and the manager, returning how many were sent
+func (ah *ApplicantSessionHandler) sendInvites(session *database.ApplicantSession, managerEmail, postingTitle string, start, end time.Time) int {
+	label := "Interview"
+	if session.Kind == database.ApplicantSessionTrial {
+		label = "Trial shift"
+	}
+	location := ""
+	if session.Location != nil {
+		location = *session.Location
+	}
+
+	invites := []struct{ email, name, title string }{
+		{session.ApplicantEmail, session.ApplicantName, fmt.Sprintf("%s for %s", label, postingTitle)},
+		{managerEmail, session.ManagerName, fmt.Sprintf("%s with %s (%s)", label, session.ApplicantName, postingTitle)},
+	}
+
+	sent := 0
+	for _, invite := range invites {
+		err := ah.EmailService.SendCalendarInviteEmail(invite.email, invite.name, invite.title, location, start, end, session.ID.String())
+		if err != nil {
+			ah.Logger.Error("failed to send session invite", "error", err, "session_id", session.ID)
+			continue
+		}
+		sent++
+	}
+	return sent
+}
+
+// GetApplicantSessionsHandler lists the interviews and trial shifts of an applicant
+func (ah *ApplicantSessionHandler) GetApplicantSessionsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can schedule interviews"})
+		return
+	}
+
+	applicantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid applicant ID"})
+		return
+	}
+
+	sessions, err := ah.ApplicantSessionStore.GetSessionsForApplicant(user.OrganizationID, applicantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sessions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Sessions retrieved successfully", "data": sessions})
+}
+
+// withApplicantSessions adds the upcoming interviews and trial shifts to a 7 day schedule as non-productive
+// entries. When managerID is set only the sessions of that manager are added, listed by ID like the
+// employee schedule; otherwise the manager is listed by name like the organization schedule.
+func (sh *ScheduleHandler) withApplicantSessions(orgID uuid.UUID, schedules []database.Schedule, managerID *uuid.UUID) []database.Schedule {
+	if sh.ApplicantSessionStore == nil {
+		return schedules
+	}
+	sessions, err := sh.ApplicantSessionStore.GetSessionsForSevenDays(orgID)
+	if err != nil {
+		// the schedule is still useful without the sessions
+		sh.Logger.Error("failed to get applicant sessions for schedule", "error", err, "org_id", orgID)
+		return schedules
+	}
+
+	added := false
+	for _, session := range sessions {
+		employee := session.ManagerName
+		if managerID != nil {
+			if session.ManagerID != *managerID {
+				continue
+			}
+			employee = session.ManagerID.String()
+		}
+		schedules = append(schedules, database.Schedule{
+			Date:          session.Date,
+			Day:           session.Date.Weekday().String(),
+			StartTime:     session.StartTime,
+			EndTime:       session.EndTime,
+			Employees:     []string{employee},
+			Kind:          session.Kind,
+			Applicant:     session.ApplicantName,
+			NonProductive: true,
+		})
+		added = true
+	}
+
+	if added {
+		sort.SliceStable(schedules, func(i, j int) bool {
+			if !schedules[i].Date.Equal(schedules[j].Date) {
+				return schedules[i].Date.Before(schedules[j].Date)
+			}
+			return schedules[i].StartTime < schedules[j].StartTime
+		})
+	}
+	return schedules
+}
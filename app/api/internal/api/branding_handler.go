@@ -0,0 +1,293 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// Largest logo accepted, in bytes and in pixels on either side
+	maxLogoBytes     = 1 << 20
+	maxLogoDimension = 1024
+)
+
+// Logos are served as uploaded, so only formats browsers, mail clients and the PDF exports all read
+var logoContentTypes = map[string]bool{"image/png": true, "image/jpeg": true}
+
+var brandColorPattern = regexp.MustCompile(`^[0-9a-fA-F]{6}$`)
+
+type BrandingHandler struct {
+	OrgStore      database.OrgStore
+	BrandingStore database.BrandingStore
+	Logger        *slog.Logger
+}
+
+func NewBrandingHandler(orgStore database.OrgStore, brandingStore database.BrandingStore, logger *slog.Logger) *BrandingHandler {
+	return &BrandingHandler{
+		OrgStore:      orgStore,
+		BrandingStore: brandingStore,
+		Logger:        logger,
+	}
+}
+
+// Branding is the look of an organization. hex1 is its primary color, hex2 the secondary one and hex3
+// the accent.
+type Branding struct {
+	Name    string  `json:"name"`
+	Hex1    string  `json:"hex1"`
+	Hex2    string  `json:"hex2"`
+	Hex3    string  `json:"hex3"`
+	LogoURL *string `json:"logo_url"`
+}
+
+// brandingLogoURL is the public URL of the logo, versioned so clients refetch it once it changes
+func brandingLogoURL(orgID uuid.UUID, updatedAt time.Time) string {
+	return fmt.Sprintf("%s/api/branding/%s/logo?v=%d", service.PublicAPIURL(), orgID, updatedAt.Unix())
+}
+
+func getBranding(orgStore database.OrgStore, brandingStore database.BrandingStore, orgID uuid.UUID) (*Branding, error) {
+	org, err := orgStore.GetOrganizationByID(orgID)
+	if err != nil {
+		return nil, err
+	}
+	logoUpdatedAt, err := brandingStore.GetLogoUpdatedAt(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	branding := &Branding{Name: org.Name, Hex1: org.HexCode1, Hex2: org.HexCode2, Hex3: org.HexCode3}
+	if logoUpdatedAt != nil {
+		logoURL := brandingLogoURL(orgID, *logoUpdatedAt)
+		branding.LogoURL = &logoURL
+	}
+	return branding, nil
+}
+
+// EmailBrandingFor returns the hook giving the emails sent to a user the branding of their organization.
+// Recipients who are not users, and organizations whose colors are not valid hex codes, keep the default look.
+func EmailBrandingFor(userStore database.UserStore, orgStore database.OrgStore, brandingStore database.BrandingStore, logger *slog.Logger) func(email string) *service.EmailBranding {
+	return func(email string) *service.EmailBranding {
+		user, err := userStore.GetUserByEmail(email)
+		if err != nil {
+			return nil
+		}
+		branding, err := getBranding(orgStore, brandingStore, user.OrganizationID)
+		if err != nil {
+			logger.Error("failed to get email branding", "error", err, "organization_id", user.OrganizationID)
+			return nil
+		}
+		if !brandColorPattern.MatchString(branding.Hex1) || !brandColorPattern.MatchString(branding.Hex2) {
+			return nil
+		}
+
+		emailBranding := &service.EmailBranding{Name: branding.Name, Hex1: branding.Hex1, Hex2: branding.Hex2}
+		if branding.LogoURL != nil {
+			emailBranding.LogoURL = *branding.LogoURL
+		}
+		return emailBranding
+	}
+}
+
+// pdfBranding returns the branding of the organization for its PDF exports, nil (a plain document)
+// when it cannot be read
+func pdfBranding(orgStore database.OrgStore, brandingStore database.BrandingStore, logger *slog.Logger, orgID uuid.UUID) *service.PDFBranding {
+	org, err := orgStore.GetOrganizationByID(orgID)
+	if err != nil {
+		logger.Error("failed to get organization for PDF branding", "error", err, "organization_id", orgID)
+		return nil
+	}
+
+	branding := &service.PDFBranding{Name: org.Name, Color: org.HexCode1}
+	logo, err := brandingStore.GetLogo(orgID)
+	if err != nil && err != sql.ErrNoRows {
+		logger.Error("failed to get logo for PDF branding", "error", err, "organization_id", orgID)
+	}
+	if logo != nil {
+		branding.Logo = logo.Data
+	}
+	return branding
+}
+
+// GetBrandingHandler returns the name, colors and logo URL of the organization for the frontend
+func (bh *BrandingHandler) GetBrandingHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	branding, err := getBranding(bh.OrgStore, bh.BrandingStore, user.OrganizationID)
+	if err != nil {
+		bh.Logger.Error("failed to get branding", "error", err, "organization_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve branding"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Branding retrieved successfully", "data": branding})
+}
+
+// UpdateBrandingHandler uploads the logo and sets the brand colors of the organization from a multipart
+// form. Fields left out keep their current value.
+func (bh *BrandingHandler) UpdateBrandingHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change the branding"})
+		return
+	}
+
+	mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Branding must be sent as multipart/form-data"})
+		return
+	}
+
+	// room for the colors on top of the logo
+	maxBytes := int64(maxLogoBytes + 64<<10)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+	if err := c.Request.ParseMultipartForm(maxBytes); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Logos are limited to %d KB", maxLogoBytes>>10)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid multipart form"})
+		return
+	}
+
+	colors := map[string]string{}
+	for _, key := range []string{"hex1", "hex2", "hex3"} {
+		value := strings.TrimPrefix(strings.TrimSpace(c.PostForm(key)), "#")
+		if value == "" {
+			continue
+		}
+		if !brandColorPattern.MatchString(value) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": key + " must be a hex color such as 1A2B3C"})
+			return
+		}
+		colors[key] = strings.ToUpper(value)
+	}
+
+	var logo []byte
+	var logoType string
+	if header, err := c.FormFile("logo"); err == nil {
+		file, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read logo"})
+			return
+		}
+		logo, err = io.ReadAll(io.LimitReader(file, maxLogoBytes+1))
+		file.Close()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read logo"})
+			return
+		}
+		if len(logo) > maxLogoBytes {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Logos are limited to %d KB", maxLogoBytes>>10)})
+			return
+		}
+
+		logoType = http.DetectContentType(logo)
+		if !logoContentTypes[logoType] {
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Logos must be PNG or JPEG images"})
+			return
+		}
+		config, _, err := image.DecodeConfig(bytes.NewReader(logo))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Logo is not a readable image"})
+			return
+		}
+		if config.Width > maxLogoDimension || config.Height > maxLogoDimension {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Logos are limited to %dx%d pixels", maxLogoDimension, maxLogoDimension)})
+			return
+		}
+	}
+
+	if len(colors) == 0 && logo == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Upload a logo or set at least one of hex1, hex2 and hex3"})
+		return
+	}
+
+	if len(colors) > 0 {
+		org, err := bh.OrgStore.GetOrganizationByID(user.OrganizationID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update branding"})
+			return
+		}
+		hex1, hex2, hex3 := org.HexCode1, org.HexCode2, org.HexCode3
+		if value, ok := colors["hex1"]; ok {
+			hex1 = value
+		}
+		if value, ok := colors["hex2"]; ok {
+			hex2 = value
+		}
+		if value, ok := colors["hex3"]; ok {
+			hex3 = value
+		}
+		if err := bh.OrgStore.SetBrandColors(user.OrganizationID, hex1, hex2, hex3); err != nil {
+			bh.Logger.Error("failed to set brand colors", "error", err, "organization_id", user.OrganizationID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update branding"})
+			return
+		}
+	}
+
+	if logo != nil {
+		if err := bh.BrandingStore.SetLogo(user.OrganizationID, logoType, logo); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update branding"})
+			return
+		}
+	}
+
+	branding, err := getBranding(bh.OrgStore, bh.BrandingStore, user.OrganizationID)
+	if err != nil {
+		bh.Logger.Error("failed to get branding", "error", err, "organization_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve branding"})
+		return
+	}
+
+	bh.Logger.Info("branding updated", "organization_id", user.OrganizationID, "by", user.ID, "logo", logo != nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Branding updated successfully", "data": branding})
+}
+
+// GetLogoHandler serves the logo of an organization. It is public, as emails link to it.
+func (bh *BrandingHandler) GetLogoHandler(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("org"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	logo, err := bh.BrandingStore.GetLogo(orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Logo not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve logo"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, logo.ContentType, logo.Data)
+}
@@ -0,0 +1,388 @@
+package api
+
+import (
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// departmentField is the key of the employee custom field broadcasts are filtered on by department
+const departmentField = "department"
+
+type BroadcastHandler struct {
+	BroadcastStore   database.BroadcastStore
+	UserStore        database.UserStore
+	UserRolesStore   database.UserRolesStore
+	RolesStore       database.RolesStore
+	ScheduleStore    database.ScheduleStore
+	CustomFieldStore database.CustomFieldStore
+	EmailService     service.EmailService
+	SMSService       service.SMSService
+	Logger           *slog.Logger
+}
+
+func NewBroadcastHandler(broadcastStore database.BroadcastStore, userStore database.UserStore, userRolesStore database.UserRolesStore, rolesStore database.RolesStore,
+	scheduleStore database.ScheduleStore, customFieldStore database.CustomFieldStore, emailService service.EmailService, smsService service.SMSService, logger *slog.Logger) *BroadcastHandler {
+	return &BroadcastHandler{
+		BroadcastStore:   broadcastStore,
+		UserStore:        userStore,
+		UserRolesStore:   userRolesStore,
+		RolesStore:       rolesStore,
+		ScheduleStore:    scheduleStore,
+		CustomFieldStore: customFieldStore,
+		EmailService:     emailService,
+		SMSService:       smsService,
+		Logger:           logger,
+	}
+}
+
+// BroadcastPreviewRequest targets a broadcast without its message, to count its recipients
+type BroadcastPreviewRequest struct {
+	Channels []string                  `json:"channels" binding:"required,min=1,dive,oneof=email sms"`
+	Filters  database.BroadcastFilters `json:"filters"`
+}
+
+type BroadcastRequest struct {
+	Title    string                    `json:"title" binding:"required,max=255"`
+	Message  string                    `json:"message" binding:"required,max=2000"`
+	Channels []string                  `json:"channels" binding:"required,min=1,dive,oneof=email sms"`
+	Filters  database.BroadcastFilters `json:"filters"`
+}
+
+// broadcastRecipient is a delivery of a broadcast with the address it is sent to
+type broadcastRecipient struct {
+	delivery database.BroadcastDelivery
+	address  string
+}
+
+// BroadcastPreview counts the recipients a broadcast would reach. SMSFallback are the members without a
+// phone number emailed instead of texted.
+type BroadcastPreview struct {
+	Recipients  int            `json:"recipients"`
+	ByChannel   map[string]int `json:"by_channel"`
+	SMSFallback int            `json:"sms_fallback"`
+}
+
+// broadcastChannels picks the channels the broadcast reaches a member on. Members without a phone number
+// are emailed instead of texted, unless they are emailed anyway.
+func broadcastChannels(member *database.User, channels []string) (picked []string, fallback bool) {
+	hasPhone := member.Phone != nil && *member.Phone != ""
+	for _, channel := range channels {
+		switch {
+		case channel == database.RoutingChannelSMS && hasPhone:
+			picked = append(picked, channel)
+		case channel == database.RoutingChannelSMS:
+			if !slices.Contains(channels, database.RoutingChannelEmail) && member.Email != "" {
+				picked = append(picked, database.RoutingChannelEmail)
+				fallback = true
+			}
+		case member.Email != "":
+			picked = append(picked, channel)
+		}
+	}
+	return picked, fallback
+}
+
+// validateBroadcastFilters checks the role filters name roles of the organization. It returns false once
+// it has written an error response.
+func (bh *BroadcastHandler) validateBroadcastFilters(c *gin.Context, orgID uuid.UUID, filters database.BroadcastFilters) bool {
+	for _, role := range filters.Roles {
+		if role == "admin" || role == "manager" || role == "employee" {
+			continue
+		}
+		existingRole, err := bh.RolesStore.GetRoleByName(orgID, role)
+		if err != nil {
+			bh.Logger.Error("failed to check broadcast role", "error", err, "role", role)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate filters"})
+			return false
+		}
+		if existingRole == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown role: " + role})
+			return false
+		}
+	}
+	return true
+}
+
+// broadcastMembers returns the members of the organization matching the filters, the sender excluded.
+// Scheduled tomorrow is the day after now in the server time zone, like the shifts.
+func (bh *BroadcastHandler) broadcastMembers(sender *database.User, filters database.BroadcastFilters, now time.Time) ([]*database.User, error) {
+	users, err := bh.UserStore.GetUsersByOrganization(sender.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var scheduled map[uuid.UUID]bool
+	if filters.ScheduledTomorrow {
+		tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.Local)
+		shifts, err := bh.ScheduleStore.GetShiftsFrom(sender.OrganizationID, tomorrow)
+		if err != nil {
+			return nil, err
+		}
+		scheduled = map[uuid.UUID]bool{}
+		for _, shift := range shifts {
+			if shift.Date.Format(time.DateOnly) == tomorrow.Format(time.DateOnly) {
+				scheduled[shift.EmployeeID] = true
+			}
+		}
+	}
+
+	var departments map[uuid.UUID]map[string]any
+	if len(filters.Departments) > 0 {
+		ids := make([]uuid.UUID, len(users))
+		for i, u := range users {
+			ids[i] = u.ID
+		}
+		departments, err = bh.CustomFieldStore.GetCustomFieldValues(sender.OrganizationID, database.CustomFieldEmployee, ids)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	members := []*database.User{}
+	for _, u := range users {
+		if u.ID == sender.ID {
+			continue
+		}
+		if scheduled != nil && !scheduled[u.ID] {
+			continue
+		}
+		if departments != nil {
+			department, _ := departments[u.ID][departmentField].(string)
+			if !slices.ContainsFunc(filters.Departments, func(d string) bool { return strings.EqualFold(d, department) }) {
+				continue
+			}
+		}
+		if len(filters.Roles) > 0 && !slices.Contains(filters.Roles, u.UserRole) {
+			roles, err := bh.UserRolesStore.GetUserRoles(u.ID, sender.OrganizationID)
+			if err != nil {
+				return nil, err
+			}
+			if !slices.ContainsFunc(roles, func(role string) bool { return slices.Contains(filters.Roles, role) }) {
+				continue
+			}
+		}
+		members = append(members, u)
+	}
+	return members, nil
+}
+
+// PreviewBroadcastHandler counts the recipients of a broadcast per channel without sending anything
+func (bh *BroadcastHandler) PreviewBroadcastHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can broadcast notifications"})
+		return
+	}
+
+	var req BroadcastPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !bh.validateBroadcastFilters(c, user.OrganizationID, req.Filters) {
+		return
+	}
+
+	members, err := bh.broadcastMembers(user, req.Filters, time.Now())
+	if err != nil {
+		bh.Logger.Error("failed to get broadcast recipients", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to preview broadcast"})
+		return
+	}
+
+	preview := BroadcastPreview{ByChannel: map[string]int{}}
+	for _, channel := range req.Channels {
+		preview.ByChannel[channel] = 0
+	}
+	for _, member := range members {
+		channels, fallback := broadcastChannels(member, req.Channels)
+		if len(channels) == 0 {
+			continue
+		}
+		preview.Recipients++
+		for _, channel := range channels {
+			preview.ByChannel[channel]++
+		}
+		if fallback {
+			preview.SMSFallback++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Broadcast previewed successfully", "data": preview})
+}
+
+// SendBroadcastHandler stores a broadcast to the members matching the filters and sends it in the
+// background. Its deliveries are pending until sent, and can be followed from the broadcast.
+func (bh *BroadcastHandler) SendBroadcastHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can broadcast notifications"})
+		return
+	}
+
+	var req BroadcastRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !bh.validateBroadcastFilters(c, user.OrganizationID, req.Filters) {
+		return
+	}
+
+	members, err := bh.broadcastMembers(user, req.Filters, time.Now())
+	if err != nil {
+		bh.Logger.Error("failed to get broadcast recipients", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send broadcast"})
+		return
+	}
+
+	recipients := []broadcastRecipient{}
+	for _, member := range members {
+		channels, _ := broadcastChannels(member, req.Channels)
+		for _, channel := range channels {
+			address := member.Email
+			if channel == database.RoutingChannelSMS {
+				address = *member.Phone
+			}
+			recipients = append(recipients, broadcastRecipient{
+				delivery: database.BroadcastDelivery{UserID: member.ID, FullName: member.FullName, Channel: channel, Status: database.BroadcastDeliveryPending},
+				address:  address,
+			})
+		}
+	}
+	if len(recipients) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No member matches the filters"})
+		return
+	}
+
+	deliveries := make([]database.BroadcastDelivery, len(recipients))
+	for i, recipient := range recipients {
+		deliveries[i] = recipient.delivery
+	}
+	sentBy := user.ID
+	broadcast := &database.Broadcast{
+		OrganizationID: user.OrganizationID,
+		SentBy:         &sentBy,
+		SentByName:     user.FullName,
+		Title:          req.Title,
+		Message:        req.Message,
+		Channels:       req.Channels,
+		Filters:        req.Filters,
+	}
+	if err := bh.BroadcastStore.CreateBroadcast(broadcast, deliveries); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send broadcast"})
+		return
+	}
+
+	go bh.deliver(*broadcast, recipients)
+
+	bh.Logger.Info("broadcast queued", "id", broadcast.ID, "org_id", user.OrganizationID, "deliveries", len(recipients))
+	c.JSON(http.StatusAccepted, gin.H{"message": "Broadcast is being sent", "data": broadcast})
+}
+
+// deliver sends the broadcast to each recipient and records the outcome of every delivery
+func (bh *BroadcastHandler) deliver(broadcast database.Broadcast, recipients []broadcastRecipient) {
+	failed := 0
+	for _, recipient := range recipients {
+		var err error
+		if recipient.delivery.Channel == database.RoutingChannelSMS {
+			err = bh.SMSService.SendSMS(recipient.address, broadcast.Title+": "+broadcast.Message)
+		} else {
+			err = bh.EmailService.SendBroadcastEmail(recipient.address, broadcast.SentByName, broadcast.Title, broadcast.Message)
+		}
+
+		delivery := recipient.delivery
+		delivery.Status = database.BroadcastDeliverySent
+		if err != nil {
+			bh.Logger.Error("failed to send broadcast", "error", err, "broadcast_id", broadcast.ID, "user_id", delivery.UserID, "channel", delivery.Channel)
+			message := err.Error()
+			delivery.Status = database.BroadcastDeliveryFailed
+			delivery.Error = &message
+			failed++
+		}
+		if err := bh.BroadcastStore.SetBroadcastDeliveryStatus(broadcast.ID, delivery); err != nil {
+			bh.Logger.Error("failed to record broadcast delivery", "error", err, "broadcast_id", broadcast.ID, "user_id", delivery.UserID)
+		}
+	}
+	bh.Logger.Info("broadcast sent", "id", broadcast.ID, "deliveries", len(recipients), "failed", failed)
+}
+
+// GetBroadcastsHandler lists the broadcasts of the organization with their delivery counts
+func (bh *BroadcastHandler) GetBroadcastsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can access broadcasts"})
+		return
+	}
+
+	broadcasts, err := bh.BroadcastStore.GetBroadcasts(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve broadcasts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Broadcasts retrieved successfully", "data": broadcasts})
+}
+
+// GetBroadcastHandler returns a broadcast with the status of its delivery to each recipient
+func (bh *BroadcastHandler) GetBroadcastHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can access broadcasts"})
+		return
+	}
+
+	broadcastID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid broadcast ID"})
+		return
+	}
+
+	broadcast, err := bh.BroadcastStore.GetBroadcast(user.OrganizationID, broadcastID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Broadcast not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve broadcast"})
+		return
+	}
+
+	deliveries, err := bh.BroadcastStore.GetBroadcastDeliveries(broadcastID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve broadcast"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Broadcast retrieved successfully",
+		"data":       broadcast,
+		"deliveries": deliveries,
+	})
+}
@@ -0,0 +1,215 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CampaignConflict is an active campaign discounting some of the same items during an overlapping period
+type CampaignConflict struct {
+	CampaignID  uuid.UUID   `json:"campaign_id"`
+	Name        string      `json:"name"`
+	StartTime   string      `json:"start_time"`
+	EndTime     string      `json:"end_time"`
+	SharedItems []uuid.UUID `json:"shared_items"`
+}
+
+// AcceptCampaignRecommendationRequest is a recommended campaign to run, AllowStacking stores it even when
+// it overlaps campaigns discounting the same items
+type AcceptCampaignRecommendationRequest struct {
+	CampaignID         string   `json:"campaign_id"`
+	Name               string   `json:"name"`
+	Items              []string `json:"items" binding:"required"`
+	DiscountPercentage float64  `json:"discount_percentage"`
+	StartDate          string   `json:"start_date" binding:"required"`
+	EndDate            string   `json:"end_date" binding:"required"`
+	Channel            string   `json:"channel"`
+	AllowStacking      bool     `json:"allow_stacking"`
+}
+
+// campaignPeriod parses the period of a campaign, a date without a time covers the whole day
+func campaignPeriod(campaign database.Campaign) (time.Time, time.Time, bool) {
+	parse := func(value string, end bool) (time.Time, bool) {
+		for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05"} {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, true
+			}
+		}
+		if t, err := time.Parse(time.DateOnly, value); err == nil {
+			if end {
+				t = t.AddDate(0, 0, 1)
+			}
+			return t, true
+		}
+		return time.Time{}, false
+	}
+
+	start, ok := parse(campaign.StartTime, false)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	end, ok := parse(campaign.EndTime, true)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	return start, end, true
+}
+
+// CampaignConflicts returns the active campaigns other than the candidate whose period overlaps it and that
+// discount some of its items, in the order of existing. Campaigns with an unreadable period are skipped.
+func CampaignConflicts(candidate database.Campaign, existing []database.Campaign) []CampaignConflict {
+	conflicts := []CampaignConflict{}
+	start, end, ok := campaignPeriod(candidate)
+	if !ok || len(candidate.ItemsIncluded) == 0 {
+		return conflicts
+	}
+	items := make(map[uuid.UUID]bool, len(candidate.ItemsIncluded))
+	for _, item := range candidate.ItemsIncluded {
+		items[item.ItemID] = true
+	}
+
+	for _, other := range existing {
+		if other.ID == candidate.ID || other.Status == "inactive" {
+			continue
+		}
+		otherStart, otherEnd, ok := campaignPeriod(other)
+		if !ok || !otherStart.Before(end) || !start.Before(otherEnd) {
+			continue
+		}
+
+		var shared []uuid.UUID
+		seen := make(map[uuid.UUID]bool)
+		for _, item := range other.ItemsIncluded {
+			if items[item.ItemID] && !seen[item.ItemID] {
+				seen[item.ItemID] = true
+				shared = append(shared, item.ItemID)
+			}
+		}
+		if len(shared) == 0 {
+			continue
+		}
+		conflicts = append(conflicts, CampaignConflict{
+			CampaignID:  other.ID,
+			Name:        other.Name,
+			StartTime:   other.StartTime,
+			EndTime:     other.EndTime,
+			SharedItems: shared,
+		})
+	}
+	return conflicts
+}
+
+// AcceptCampaignRecommendationHandler stores a recommended campaign with its items. It is refused with the
+// conflicting campaigns when it overlaps active campaigns discounting the same items, unless allow_stacking is set.
+func (ch *CampaignHandler) AcceptCampaignRecommendationHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can accept campaign recommendations"})
+		return
+	}
+
+	var request AcceptCampaignRecommendationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	startDate, err := time.Parse(time.DateOnly, request.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start_date must be in YYYY-MM-DD format"})
+		return
+	}
+	endDate, err := time.Parse(time.DateOnly, request.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_date must be in YYYY-MM-DD format"})
+		return
+	}
+	if endDate.Before(startDate) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_date cannot be before start_date"})
+		return
+	}
+	if request.DiscountPercentage < 0 || request.DiscountPercentage > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "discount_percentage must be between 0 and 100"})
+		return
+	}
+	if request.Channel == "" {
+		request.Channel = database.CampaignChannelInStore
+	}
+	if !validCampaignChannels[request.Channel] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid channel"})
+		return
+	}
+	if len(request.Items) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "items cannot be empty"})
+		return
+	}
+	items := make([]database.Item, 0, len(request.Items))
+	for _, value := range request.Items {
+		itemID, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID: " + value})
+			return
+		}
+		items = append(items, database.Item{ItemID: itemID})
+	}
+
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		name = "Recommended campaign " + request.StartDate
+	}
+	discount := request.DiscountPercentage
+	campaign := database.Campaign{
+		ID:     uuid.New(),
+		Name:   name,
+		Status: "active",
+		// The end date is the last day of the campaign
+		StartTime:       startDate.Format(time.RFC3339),
+		EndTime:         endDate.AddDate(0, 0, 1).Add(-time.Second).Format(time.RFC3339),
+		ItemsIncluded:   items,
+		DiscountPercent: &discount,
+		Channel:         request.Channel,
+	}
+
+	existing, err := ch.CampaignStore.GetAllCampaigns(user.OrganizationID)
+	if err != nil {
+		ch.Logger.Error("failed to get campaigns", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check campaign conflicts"})
+		return
+	}
+	conflicts := CampaignConflicts(campaign, existing)
+	if len(conflicts) > 0 && !request.AllowStacking {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     "Campaign overlaps campaigns discounting the same items, set allow_stacking to run it anyway",
+			"conflicts": conflicts,
+		})
+		return
+	}
+
+	if err := ch.CampaignStore.StoreCampaign(user.OrganizationID, campaign); err != nil {
+		ch.Logger.Error("failed to store accepted campaign", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store campaign"})
+		return
+	}
+	if err := ch.CampaignStore.StoreCampaignItems(user.OrganizationID, campaign.ID, items); err != nil {
+		ch.Logger.Error("failed to store accepted campaign items", "error", err, "campaign_id", campaign.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store campaign items"})
+		return
+	}
+
+	ch.Logger.Info("campaign recommendation accepted", "campaign_id", campaign.ID, "recommendation_id", request.CampaignID, "stacked", len(conflicts) > 0)
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   "Campaign recommendation accepted successfully",
+		"data":      campaign,
+		"conflicts": conflicts,
+	})
+}
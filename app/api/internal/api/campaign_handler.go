@@ -2,42 +2,52 @@ package api
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/clockwise/clockwise/backend/internal/database"
 	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/mlproto"
 	"github.com/clockwise/clockwise/backend/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type CampaignHandler struct {
-	CampaignStore       database.CampaignStore
-	UploadCSVService    service.UploadService
-	OrderStore          database.OrderStore
-	OrgStore            database.OrgStore
-	OperatingHoursStore database.OperatingHoursStore
-	RulesStore          database.RulesStore
-	Logger              *slog.Logger
-	MLServiceURL        string
+	CampaignStore         database.CampaignStore
+	UploadCSVService      service.UploadService
+	OrderStore            database.OrderStore
+	OrgStore              database.OrgStore
+	OperatingHoursStore   database.OperatingHoursStore
+	RulesStore            database.RulesStore
+	ItemAvailabilityStore database.ItemAvailabilityStore
+	ExternalEventStore    database.ExternalEventStore
+	StatusStore           database.StatusStore
+	Logger                *slog.Logger
+	MLServiceURL          string
 }
 
-func NewCampaignHandler(campaignStore database.CampaignStore, uploadservice service.UploadService, orderStore database.OrderStore, orgStore database.OrgStore, operatingHoursStore database.OperatingHoursStore, rulesStore database.RulesStore, Logger *slog.Logger) *CampaignHandler {
+func NewCampaignHandler(campaignStore database.CampaignStore, uploadservice service.UploadService, orderStore database.OrderStore, orgStore database.OrgStore, operatingHoursStore database.OperatingHoursStore, rulesStore database.RulesStore, itemAvailabilityStore database.ItemAvailabilityStore, externalEventStore database.ExternalEventStore, statusStore database.StatusStore, Logger *slog.Logger) *CampaignHandler {
 	return &CampaignHandler{
-		CampaignStore:       campaignStore,
-		UploadCSVService:    uploadservice,
-		OrderStore:          orderStore,
-		OrgStore:            orgStore,
-		OperatingHoursStore: operatingHoursStore,
-		RulesStore:          rulesStore,
-		Logger:              Logger,
-		MLServiceURL:        "http://cw-ml-service:8000",
+		CampaignStore:         campaignStore,
+		UploadCSVService:      uploadservice,
+		OrderStore:            orderStore,
+		OrgStore:              orgStore,
+		OperatingHoursStore:   operatingHoursStore,
+		RulesStore:            rulesStore,
+		ItemAvailabilityStore: itemAvailabilityStore,
+		ExternalEventStore:    externalEventStore,
+		StatusStore:           statusStore,
+		Logger:                Logger,
+		MLServiceURL:          "http://cw-ml-service:8000",
 	}
 }
 
@@ -52,31 +62,6 @@ type RecommendCampaignRequest struct {
 	AvailableItems          []string `json:"available_items"`
 }
 
-type RecommendedCampaignItem struct {
-	CampaignID            string         `json:"campaign_id"`
-	Items                 []string       `json:"items"`
-	DiscountPercentage    float64        `json:"discount_percentage"`
-	StartDate             string         `json:"start_date"`
-	EndDate               string         `json:"end_date"`
-	DurationDays          int            `json:"duration_days"`
-	ExpectedUplift        float64        `json:"expected_uplift"`
-	ExpectedROI           float64        `json:"expected_roi"`
-	ExpectedRevenue       float64        `json:"expected_revenue"`
-	ConfidenceScore       float64        `json:"confidence_score"`
-	Reasoning             string         `json:"reasoning"`
-	PriorityScore         float64        `json:"priority_score"`
-	RecommendedForContext map[string]any `json:"recommended_for_context"`
-}
-
-type CampaignRecommendationResponse struct {
-	RestaurantName     string                    `json:"restaurant_name"`
-	RecommendationDate string                    `json:"recommendation_date"`
-	Recommendations    []RecommendedCampaignItem `json:"recommendations"`
-	AnalysisSummary    map[string]any            `json:"analysis_summary"`
-	Insights           map[string]any            `json:"insights"`
-	ConfidenceLevel    string                    `json:"confidence_level"`
-}
-
 type CampaignFeedbackRequest struct {
 	CampaignID    string   `json:"campaign_id" binding:"required"`
 	ActualUplift  *float64 `json:"actual_uplift"`
@@ -84,12 +69,27 @@ type CampaignFeedbackRequest struct {
 	ActualRevenue *float64 `json:"actual_revenue"`
 	Success       bool     `json:"success"`
 	Notes         *string  `json:"notes"`
+	// Channel is set when the ROI is computed from redemptions
+	Channel string `json:"channel,omitempty"`
+}
+
+// Longest redemption code, matches the column size
+const maxRedemptionCodeLength = 32
+
+var validCampaignChannels = map[string]bool{
+	database.CampaignChannelInStore:          true,
+	database.CampaignChannelApp:              true,
+	database.CampaignChannelDeliveryPlatform: true,
 }
 
-type CampaignFeedbackResponse struct {
-	Status            string         `json:"status"`
-	Message           string         `json:"message"`
-	UpdatedParameters map[string]any `json:"updated_parameters,omitempty"`
+// CampaignROI is the return on the discount given: the net revenue of the redeeming orders minus the discount,
+// divided by the discount. Returns nil when no discount was given.
+func CampaignROI(performance database.CampaignPerformance) *float64 {
+	if performance.Discount <= 0 {
+		return nil
+	}
+	roi := math.Round((performance.Revenue-performance.Discount)/performance.Discount*100) / 100
+	return &roi
 }
 
 func (ch *CampaignHandler) UploadCampaignsCSVHandler(c *gin.Context) {
@@ -122,11 +122,15 @@ func (ch *CampaignHandler) UploadCampaignsCSVHandler(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "CSV file is empty"})
 			return
 		}
+		if err == service.ErrTooManyRows {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "CSV file has too many rows", "hint": middleware.UploadSplitHint})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid CSV format"})
 		return
 	}
 
-	// Expected columns: id, name, status, start_time, end_time, discount_percent
+	// Expected columns: id, name, status, start_time, end_time, discount_percent, channel, redemption_code
 	requiredColumns := []string{"id", "name", "status", "start_time", "end_time"}
 	for _, col := range requiredColumns {
 		found := false
@@ -187,6 +191,26 @@ func (ch *CampaignHandler) UploadCampaignsCSVHandler(c *gin.Context) {
 			}
 		}
 
+		// Parse channel and redemption_code (optional)
+		channel := row["channel"]
+		if channel == "" {
+			channel = database.CampaignChannelInStore
+		}
+		if !validCampaignChannels[channel] {
+			ch.Logger.Warn("invalid channel in row", "row", i, "channel", channel)
+			errorCount++
+			continue
+		}
+		var redemptionCode *string
+		if code := strings.TrimSpace(row["redemption_code"]); code != "" {
+			if len(code) > maxRedemptionCodeLength {
+				ch.Logger.Warn("redemption_code too long in row", "row", i)
+				errorCount++
+				continue
+			}
+			redemptionCode = &code
+		}
+
 		campaign := database.Campaign{
 			ID:              campaignID,
 			Name:            row["name"],
@@ -194,6 +218,8 @@ func (ch *CampaignHandler) UploadCampaignsCSVHandler(c *gin.Context) {
 			StartTime:       startTime.Format(time.RFC3339),
 			EndTime:         endTime.Format(time.RFC3339),
 			DiscountPercent: discountPercent,
+			Channel:         channel,
+			RedemptionCode:  redemptionCode,
 		}
 
 		err = ch.CampaignStore.StoreCampaign(user.OrganizationID, campaign)
@@ -254,6 +280,10 @@ func (ch *CampaignHandler) UploadCampaignsItemsCSVHandlers(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "CSV file is empty"})
 			return
 		}
+		if err == service.ErrTooManyRows {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "CSV file has too many rows", "hint": middleware.UploadSplitHint})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid CSV format"})
 		return
 	}
@@ -275,7 +305,8 @@ func (ch *CampaignHandler) UploadCampaignsItemsCSVHandlers(c *gin.Context) {
 		}
 	}
 
-	// Group items by campaign_id
+	// Group items by campaign_id, in the order of the file
+	var campaignOrder []uuid.UUID
 	campaignItemsMap := make(map[uuid.UUID][]database.Item)
 	for i, row := range csvData.Rows {
 		// Parse campaign_id
@@ -293,14 +324,39 @@ func (ch *CampaignHandler) UploadCampaignsItemsCSVHandlers(c *gin.Context) {
 		}
 
 		// Add item to the campaign's item list
+		if _, ok := campaignItemsMap[campaignID]; !ok {
+			campaignOrder = append(campaignOrder, campaignID)
+		}
 		campaignItemsMap[campaignID] = append(campaignItemsMap[campaignID], database.Item{
 			ItemID: itemID,
 		})
 	}
 
+	// Campaigns stacking their discount on the same items during the same period are skipped unless intended
+	allowStacking := c.Query("allow_stacking") == "true"
+	campaignIndex := make(map[uuid.UUID]int, len(existingCampaigns))
+	for i, campaign := range existingCampaigns {
+		campaignIndex[campaign.ID] = i
+	}
+
 	// Store items for each campaign
 	var successCount, errorCount int
-	for campaignID, items := range campaignItemsMap {
+	conflicts := []gin.H{}
+	for _, campaignID := range campaignOrder {
+		items := campaignItemsMap[campaignID]
+		if i, ok := campaignIndex[campaignID]; ok {
+			candidate := existingCampaigns[i]
+			candidate.ItemsIncluded = append(append([]database.Item{}, candidate.ItemsIncluded...), items...)
+			if overlapping := CampaignConflicts(candidate, existingCampaigns); len(overlapping) > 0 {
+				conflicts = append(conflicts, gin.H{"campaign_id": campaignID, "conflicts": overlapping})
+				if !allowStacking {
+					ch.Logger.Warn("skipping campaign items conflicting with other campaigns", "campaign_id", campaignID)
+					errorCount += len(items)
+					continue
+				}
+			}
+		}
+
 		err := ch.CampaignStore.StoreCampaignItems(user.OrganizationID, campaignID, items)
 		if err != nil {
 			ch.Logger.Error("failed to store campaign items", "campaign_id", campaignID, "error", err)
@@ -308,6 +364,10 @@ func (ch *CampaignHandler) UploadCampaignsItemsCSVHandlers(c *gin.Context) {
 			continue
 		}
 		successCount += len(items)
+		// Later campaigns of the file are checked against the items just added
+		if i, ok := campaignIndex[campaignID]; ok {
+			existingCampaigns[i].ItemsIncluded = append(existingCampaigns[i].ItemsIncluded, items...)
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -315,6 +375,7 @@ func (ch *CampaignHandler) UploadCampaignsItemsCSVHandlers(c *gin.Context) {
 		"total_rows":    csvData.Total,
 		"success_count": successCount,
 		"error_count":   errorCount,
+		"conflicts":     conflicts,
 	})
 }
 
@@ -338,9 +399,17 @@ func (ch *CampaignHandler) GetCampaignsInsightsHandler(c *gin.Context) {
 		return
 	}
 
+	channels, err := ch.CampaignStore.GetChannelPerformance(user.OrganizationID)
+	if err != nil {
+		ch.Logger.Error("failed to get channel performance", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve campaign insights"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Campaign insights retrieved successfully",
-		"data":    insights,
+		"message":  "Campaign insights retrieved successfully",
+		"data":     insights,
+		"channels": channels,
 	})
 }
 
@@ -433,7 +502,12 @@ func (ch *CampaignHandler) RecommendCampaignsHandler(c *gin.Context) {
 		request.MaxCampaignDurationDays = 14
 	}
 
-	// Fetch organization data
+	if err := ch.validateRecommendationRequest(&request); err != nil {
+		ch.Logger.Warn("recommendation request failed validation", "error", err)
+		c.JSON(http.StatusUnprocessableEntity, mlPayloadErrorResponse("recommendation request is invalid", err))
+		return
+	}
+
 	// Fetch organization data
 	org, err := ch.OrgStore.GetOrganizationByID(user.OrganizationID)
 	if err != nil {
@@ -442,6 +516,11 @@ func (ch *CampaignHandler) RecommendCampaignsHandler(c *gin.Context) {
 		return
 	}
 
+	if org == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+		return
+	}
+
 	// Fetch organization rules for delivery and phone settings
 	rules, err := ch.RulesStore.GetRulesByOrganizationID(user.OrganizationID)
 	if err != nil {
@@ -455,6 +534,12 @@ func (ch *CampaignHandler) RecommendCampaignsHandler(c *gin.Context) {
 		operatingHours = []database.OperatingHours{} // Use empty if not found
 	}
 
+	if err := validateRecommendationPlace(org, operatingHours); err != nil {
+		ch.Logger.Warn("recommendation payload failed validation", "org_id", user.OrganizationID, "error", err)
+		c.JSON(http.StatusUnprocessableEntity, mlPayloadErrorResponse("organization data is incomplete or invalid", err))
+		return
+	}
+
 	// Fetch historical orders
 	orders, err := ch.OrderStore.GetAllOrders(user.OrganizationID)
 	if err != nil {
@@ -471,6 +556,35 @@ func (ch *CampaignHandler) RecommendCampaignsHandler(c *gin.Context) {
 		return
 	}
 
+	// Leave out discontinued items, and seasonal items that are off the menu when the campaigns start
+	windows, err := ch.ItemAvailabilityStore.GetAvailabilityWindows(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve item availability"})
+		return
+	}
+	items, err := ch.OrderStore.GetAllItems(user.OrganizationID)
+	if err != nil {
+		ch.Logger.Error("failed to get items", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve items"})
+		return
+	}
+	startDate, _ := time.Parse("2006-01-02", request.RecommendationStartDate)
+	unavailable := UnavailableItemsOn(windows, startDate)
+	for _, item := range items {
+		if !item.IsActive {
+			unavailable[item.ItemID] = true
+		}
+	}
+	request.AvailableItems = FilterAvailableItems(request.AvailableItems, items, unavailable)
+
+	// Events that could overlap the longest recommended campaign
+	eventsFrom, _ := time.ParseInLocation(time.DateOnly, request.RecommendationStartDate, time.Local)
+	events, err := ch.ExternalEventStore.GetEventsBetween(user.OrganizationID, eventsFrom, eventsFrom.AddDate(0, 0, request.MaxCampaignDurationDays))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve upcoming events"})
+		return
+	}
+
 	// NOW add the rules handling and ML request building code...
 	// If rules is nil, use safe defaults for all fields
 	var (
@@ -503,51 +617,45 @@ func (ch *CampaignHandler) RecommendCampaignsHandler(c *gin.Context) {
 		waitingTime = rules.WaitingTime
 	}
 
-	// Build ML service request
-	// Handle nil latitude/longitude with defaults
-	latitude := 0.0
-	if org.Location.Latitude != nil {
-		latitude = *org.Location.Latitude
-	}
-	longitude := 0.0
-	if org.Location.Longitude != nil {
-		longitude = *org.Location.Longitude
-	}
+	// Build ML service request, location was checked by validateRecommendationPlace
+	latitude := *org.Location.Latitude
+	longitude := *org.Location.Longitude
 
-	// Convert operating hours to map format for ML service
-	operatingHoursMap := make(map[string]any)
+	// Opening hours are keyed by weekday for the recommender
+	openingHours := make(map[string]mlproto.CampaignOpeningHours, len(operatingHours))
 	for _, hours := range operatingHours {
-		operatingHoursMap[hours.Weekday] = map[string]any{
-			"weekday":      hours.Weekday,
-			"opening_time": hours.OpeningTime,
-			"closing_time": hours.ClosingTime,
-		}
-	}
-
-	mlRequest := map[string]any{
-		"place": map[string]any{
-			"place_id":                 user.OrganizationID.String(),
-			"place_name":               org.Name,
-			"latitude":                 latitude,
-			"longitude":                longitude,
-			"type":                     org.Type,
-			"delivery":                 delivery,
-			"receiving_phone":          receivingPhone,
-			"opening_hours":            operatingHoursMap,
-			"fixed_shifts":             fixedShifts,
-			"number_of_shifts_per_day": numberOfShiftsPerDay,
-			"waiting_time":             waitingTime,
+		openingHours[hours.Weekday] = mlproto.CampaignOpeningHours{
+			Weekday:     hours.Weekday,
+			OpeningTime: hours.OpeningTime,
+			ClosingTime: hours.ClosingTime,
+		}
+	}
+
+	mlRequest := mlproto.CampaignRecommendationRequest{
+		Place: mlproto.CampaignPlace{
+			PlaceID:              user.OrganizationID.String(),
+			PlaceName:            org.Name,
+			Latitude:             latitude,
+			Longitude:            longitude,
+			Type:                 org.Type,
+			Delivery:             delivery,
+			ReceivingPhone:       receivingPhone,
+			OpeningHours:         openingHours,
+			FixedShifts:          fixedShifts,
+			NumberOfShiftsPerDay: numberOfShiftsPerDay,
+			WaitingTime:          waitingTime,
 		},
-		"orders":                     ch.convertOrdersForML(orders),
-		"campaigns":                  ch.convertCampaignsForML(campaigns),
-		"order_items":                ch.convertOrderItemsForML(orders),
-		"recommendation_start_date":  request.RecommendationStartDate,
-		"num_recommendations":        request.NumRecommendations,
-		"optimize_for":               request.OptimizeFor,
-		"max_discount":               request.MaxDiscount,
-		"min_campaign_duration_days": request.MinCampaignDurationDays,
-		"max_campaign_duration_days": request.MaxCampaignDurationDays,
-		"available_items":            request.AvailableItems,
+		Orders:                  ch.convertOrdersForML(orders),
+		Campaigns:               ch.convertCampaignsForML(campaigns),
+		OrderItems:              ch.convertOrderItemsForML(orders),
+		RecommendationStartDate: request.RecommendationStartDate,
+		NumRecommendations:      request.NumRecommendations,
+		OptimizeFor:             request.OptimizeFor,
+		MaxDiscount:             request.MaxDiscount,
+		MinCampaignDurationDays: request.MinCampaignDurationDays,
+		MaxCampaignDurationDays: request.MaxCampaignDurationDays,
+		AvailableItems:          request.AvailableItems,
+		Events:                  events,
 	}
 
 	// Continue with jsonData marshaling and ML service call...
@@ -560,11 +668,13 @@ func (ch *CampaignHandler) RecommendCampaignsHandler(c *gin.Context) {
 
 	// Call ML service
 	client := &http.Client{Timeout: 60 * time.Second}
+	started := time.Now()
 	resp, err := client.Post(
 		fmt.Sprintf("%s/recommend/campaigns", ch.MLServiceURL),
 		"application/json",
 		bytes.NewBuffer(jsonData),
 	)
+	recordMLRequest(ch.StatusStore, ch.Logger, user.OrganizationID, "/recommend/campaigns", started, err != nil || resp.StatusCode != http.StatusOK)
 	if err != nil {
 		ch.Logger.Error("failed to call ML service", "error", err)
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Campaign recommendation service unavailable"})
@@ -616,6 +726,27 @@ func (ch *CampaignHandler) SubmitCampaignFeedbackHandler(c *gin.Context) {
 		return
 	}
 
+	// Fill in what was not measured by hand from the orders that redeemed the campaign
+	if feedback.ActualROI == nil || feedback.ActualRevenue == nil {
+		if campaignID, err := uuid.Parse(feedback.CampaignID); err == nil {
+			performance, err := ch.CampaignStore.GetCampaignPerformance(user.OrganizationID, campaignID)
+			if err != nil && err != sql.ErrNoRows {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute campaign performance"})
+				return
+			}
+			if performance != nil && performance.Redemptions > 0 {
+				if feedback.ActualRevenue == nil {
+					revenue := performance.Revenue
+					feedback.ActualRevenue = &revenue
+				}
+				if feedback.ActualROI == nil {
+					feedback.ActualROI = CampaignROI(*performance)
+				}
+				feedback.Channel = performance.Channel
+			}
+		}
+	}
+
 	jsonData, err := json.Marshal(feedback)
 	if err != nil {
 		ch.Logger.Error("failed to marshal feedback", "error", err)
@@ -662,8 +793,8 @@ func (ch *CampaignHandler) SubmitCampaignFeedbackHandler(c *gin.Context) {
 }
 
 // Helper functions to convert data formats for ML service
-func (ch *CampaignHandler) convertOrdersForML(orders []database.Order) []map[string]any {
-	mlOrders := make([]map[string]any, 0, len(orders))
+func (ch *CampaignHandler) convertOrdersForML(orders []database.Order) []mlproto.CampaignOrder {
+	mlOrders := make([]mlproto.CampaignOrder, 0, len(orders))
 	for _, order := range orders {
 		// Skip invalid orders
 		if order.CreateTime.IsZero() {
@@ -684,19 +815,19 @@ func (ch *CampaignHandler) convertOrdersForML(orders []database.Order) []map[str
 		// OrderCount is int, not *int - use it directly
 		orderCount := order.OrderCount
 
-		mlOrders = append(mlOrders, map[string]any{
-			"time":            order.CreateTime.Format(time.RFC3339),
-			"total_amount":    totalAmount,
-			"items":           orderCount,
-			"status":          order.OrderStatus,
-			"discount_amount": discountAmount,
+		mlOrders = append(mlOrders, mlproto.CampaignOrder{
+			Time:           order.CreateTime.Format(time.RFC3339),
+			TotalAmount:    totalAmount,
+			Items:          orderCount,
+			Status:         order.OrderStatus,
+			DiscountAmount: discountAmount,
 		})
 	}
 	return mlOrders
 }
 
-func (ch *CampaignHandler) convertCampaignsForML(campaigns []database.Campaign) []map[string]any {
-	mlCampaigns := make([]map[string]any, 0, len(campaigns))
+func (ch *CampaignHandler) convertCampaignsForML(campaigns []database.Campaign) []mlproto.PastCampaign {
+	mlCampaigns := make([]mlproto.PastCampaign, 0, len(campaigns))
 	for _, campaign := range campaigns {
 		// Skip campaigns with invalid times
 		if campaign.StartTime == "" || campaign.EndTime == "" {
@@ -716,18 +847,18 @@ func (ch *CampaignHandler) convertCampaignsForML(campaigns []database.Campaign)
 			discount = *campaign.DiscountPercent
 		}
 
-		mlCampaigns = append(mlCampaigns, map[string]any{
-			"start_time":     campaign.StartTime,
-			"end_time":       campaign.EndTime,
-			"items_included": itemNames,
-			"discount":       discount,
+		mlCampaigns = append(mlCampaigns, mlproto.PastCampaign{
+			StartTime:     campaign.StartTime,
+			EndTime:       campaign.EndTime,
+			ItemsIncluded: itemNames,
+			Discount:      discount,
 		})
 	}
 	return mlCampaigns
 }
 
-func (ch *CampaignHandler) convertOrderItemsForML(orders []database.Order) []map[string]any {
-	mlOrderItems := make([]map[string]any, 0)
+func (ch *CampaignHandler) convertOrderItemsForML(orders []database.Order) []mlproto.CampaignOrderItem {
+	mlOrderItems := make([]mlproto.CampaignOrderItem, 0)
 	for _, order := range orders {
 		for _, item := range order.OrderItems {
 			quantity := 1
@@ -740,15 +871,38 @@ func (ch *CampaignHandler) convertOrderItemsForML(orders []database.Order) []map
 				continue
 			}
 
-			mlOrderItems = append(mlOrderItems, map[string]any{
-				"order_id": order.OrderID.String(),
-				"item_id":  item.ItemID.String(),
-				"quantity": quantity,
+			mlOrderItems = append(mlOrderItems, mlproto.CampaignOrderItem{
+				OrderID:  order.OrderID.String(),
+				ItemID:   item.ItemID.String(),
+				Quantity: quantity,
 			})
 		}
 	}
 	return mlOrderItems
 }
+
+// FilterAvailableItems drops the unavailable items from the requested ones. Without requested items every
+// known item that is available is returned, so the recommendations are limited to the current menu.
+func FilterAvailableItems(requested []string, items []database.Item, unavailable map[uuid.UUID]bool) []string {
+	if len(requested) == 0 {
+		if len(unavailable) == 0 {
+			return requested
+		}
+		for _, item := range items {
+			requested = append(requested, item.ItemID.String())
+		}
+	}
+
+	available := []string{}
+	for _, itemID := range requested {
+		if id, err := uuid.Parse(itemID); err == nil && unavailable[id] {
+			continue
+		}
+		available = append(available, itemID)
+	}
+	return available
+}
+
 func (ch *CampaignHandler) validateRecommendationRequest(request *RecommendCampaignRequest) error {
 	// Validate date format
 	_, err := time.Parse("2006-01-02", request.RecommendationStartDate)
@@ -0,0 +1,251 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// consolidationDays is the period of the consolidated report when no from date is given
+const consolidationDays = 30
+
+type ConsolidationHandler struct {
+	MembershipStore database.MembershipStore
+	OrgStore        database.OrgStore
+	OrderStore      database.OrderStore
+	ScheduleStore   database.ScheduleStore
+	UserStore       database.UserStore
+	ExchangeRates   service.ExchangeRateProvider
+	Logger          *slog.Logger
+	// KPIs adds how each organization did against its KPI targets, when set
+	KPIs *service.KPITracker
+}
+
+func NewConsolidationHandler(membershipStore database.MembershipStore, orgStore database.OrgStore, orderStore database.OrderStore,
+	scheduleStore database.ScheduleStore, userStore database.UserStore, exchangeRates service.ExchangeRateProvider, logger *slog.Logger) *ConsolidationHandler {
+	return &ConsolidationHandler{
+		MembershipStore: membershipStore,
+		OrgStore:        orgStore,
+		OrderStore:      orderStore,
+		ScheduleStore:   scheduleStore,
+		UserStore:       userStore,
+		ExchangeRates:   exchangeRates,
+		Logger:          logger,
+	}
+}
+
+// OrganizationFigures is the revenue and labor cost of an organization over a period, in its own currency.
+// Employees without an hourly salary are left out of the labor cost. Targets compares the period with the
+// KPI targets of the organization, left out when it set none.
+type OrganizationFigures struct {
+	OrganizationID         uuid.UUID           `json:"organization_id"`
+	Name                   string              `json:"name"`
+	Currency               string              `json:"currency"`
+	Revenue                float64             `json:"revenue"`
+	LaborCost              float64             `json:"labor_cost"`
+	EmployeesWithoutSalary int                 `json:"employees_without_salary"`
+	Targets                []service.KPIResult `json:"targets,omitempty"`
+}
+
+// ConsolidatedOrganization is the figures of an organization converted into the reporting currency.
+// ExchangeRate is the units of the organization's currency per unit of the reporting currency.
+type ConsolidatedOrganization struct {
+	OrganizationFigures
+	ExchangeRate       float64 `json:"exchange_rate"`
+	ConvertedRevenue   float64 `json:"converted_revenue"`
+	ConvertedLaborCost float64 `json:"converted_labor_cost"`
+}
+
+// ConsolidatedReport sums the converted figures of the organizations of an account
+type ConsolidatedReport struct {
+	Currency         string                     `json:"currency"`
+	Organizations    []ConsolidatedOrganization `json:"organizations"`
+	Revenue          float64                    `json:"revenue"`
+	LaborCost        float64                    `json:"labor_cost"`
+	LaborCostPercent *float64                   `json:"labor_cost_percent"`
+}
+
+func roundAmount(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// ConsolidateFigures converts the figures of each organization into currency with rates, the units of each
+// currency per unit of currency, and sums them. It fails when the rate of a currency is missing.
+func ConsolidateFigures(currency string, figures []OrganizationFigures, rates map[string]float64) (ConsolidatedReport, error) {
+	report := ConsolidatedReport{Currency: currency, Organizations: make([]ConsolidatedOrganization, 0, len(figures))}
+	for _, org := range figures {
+		rate := 1.0
+		if org.Currency != currency {
+			var ok bool
+			if rate, ok = rates[org.Currency]; !ok || rate <= 0 {
+				return ConsolidatedReport{}, fmt.Errorf("no exchange rate from %s to %s", org.Currency, currency)
+			}
+		}
+		consolidated := ConsolidatedOrganization{
+			OrganizationFigures: org,
+			ExchangeRate:        rate,
+			ConvertedRevenue:    roundAmount(org.Revenue / rate),
+			ConvertedLaborCost:  roundAmount(org.LaborCost / rate),
+		}
+		report.Revenue += consolidated.ConvertedRevenue
+		report.LaborCost += consolidated.ConvertedLaborCost
+		report.Organizations = append(report.Organizations, consolidated)
+	}
+	report.Revenue = roundAmount(report.Revenue)
+	report.LaborCost = roundAmount(report.LaborCost)
+	if report.Revenue > 0 {
+		percent := roundAmount(report.LaborCost / report.Revenue * 100)
+		report.LaborCostPercent = &percent
+	}
+	return report, nil
+}
+
+// GetConsolidatedReportHandler converts the revenue and labor cost of every organization the user is an
+// admin of into one reporting currency, ?currency= or that of the current organization. Amounts are
+// converted at the rates of the last day of the period, at the latest yesterday's. from and to
+// (YYYY-MM-DD, inclusive) default to the last 30 days.
+func (ch *ConsolidationHandler) GetConsolidatedReportHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	memberships, err := ch.MembershipStore.GetMembershipsForUser(user.ID)
+	if err != nil {
+		ch.Logger.Error("failed to get memberships", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to consolidate reports"})
+		return
+	}
+	var owned []database.OrganizationMembership
+	for _, membership := range memberships {
+		if membership.UserRole == "admin" {
+			owned = append(owned, membership)
+		}
+	}
+	if len(owned) == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can consolidate the reports of their organizations"})
+		return
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	to := today.AddDate(0, 0, 1)
+	from := to.AddDate(0, 0, -consolidationDays)
+	queryFrom, queryTo, ok := queryDateRange(c)
+	if !ok {
+		return
+	}
+	if queryTo != nil {
+		to = *queryTo
+		from = to.AddDate(0, 0, -consolidationDays)
+	}
+	if queryFrom != nil {
+		from = *queryFrom
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(c.Query("currency")))
+	if currency == "" {
+		if currency, err = ch.OrgStore.GetCurrency(user.OrganizationID); err != nil {
+			ch.Logger.Error("failed to get currency", "error", err, "org_id", user.OrganizationID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to consolidate reports"})
+			return
+		}
+	}
+	if !currencyPattern.MatchString(currency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "currency must be an ISO 4217 code such as USD or EUR"})
+		return
+	}
+
+	figures := make([]OrganizationFigures, 0, len(owned))
+	converted := false
+	for _, membership := range owned {
+		org, err := ch.organizationFigures(membership, from, to)
+		if err != nil {
+			ch.Logger.Error("failed to get organization figures", "error", err, "org_id", membership.OrganizationID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to consolidate reports"})
+			return
+		}
+		converted = converted || org.Currency != currency
+		figures = append(figures, *org)
+	}
+
+	// the rates of today may not be published yet
+	rateDay := to.AddDate(0, 0, -1)
+	if !rateDay.Before(today) {
+		rateDay = today.AddDate(0, 0, -1)
+	}
+	rates := map[string]float64{}
+	if converted {
+		if rates, err = ch.ExchangeRates.Rates(c.Request.Context(), currency, rateDay); err != nil {
+			ch.Logger.Error("failed to get exchange rates", "error", err, "currency", currency)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Exchange rates are unavailable"})
+			return
+		}
+	}
+	report, err := ConsolidateFigures(currency, figures, rates)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Consolidated report retrieved successfully",
+		"data": gin.H{
+			"from":      from.Format(time.DateOnly),
+			"to":        to.AddDate(0, 0, -1).Format(time.DateOnly),
+			"rate_date": rateDay.Format(time.DateOnly),
+			"report":    report,
+		},
+	})
+}
+
+func (ch *ConsolidationHandler) organizationFigures(membership database.OrganizationMembership, from, to time.Time) (*OrganizationFigures, error) {
+	orgID := membership.OrganizationID
+	currency, err := ch.OrgStore.GetCurrency(orgID)
+	if err != nil {
+		return nil, err
+	}
+	channels, err := ch.OrderStore.GetChannelBreakdown(orgID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	employees, err := ch.UserStore.GetUsersByOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+	hours, err := ch.ScheduleStore.GetScheduledHours(orgID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	figures := &OrganizationFigures{OrganizationID: orgID, Name: membership.OrganizationName, Currency: currency}
+	for _, channel := range channels {
+		figures.Revenue += channel.Revenue
+	}
+	figures.Revenue = roundAmount(figures.Revenue)
+	figures.LaborCost, figures.EmployeesWithoutSalary = service.LaborCost(employees, hours)
+
+	if ch.KPIs != nil {
+		progress, err := ch.KPIs.Progress(orgID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			figures.Targets = progress.Targets
+		}
+	}
+	return figures, nil
+}
@@ -0,0 +1,407 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Longest value accepted by a text custom field
+const maxCustomFieldText = 500
+
+// customFieldFilterPrefix prefixes the query parameters filtering list endpoints on custom fields,
+// e.g. ?cf.uniform_size=M
+const customFieldFilterPrefix = "cf."
+
+var customFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+var customFieldEntities = []string{database.CustomFieldEmployee, database.CustomFieldItem, database.CustomFieldOrder}
+
+type CustomFieldHandler struct {
+	CustomFieldStore database.CustomFieldStore
+	Logger           *slog.Logger
+}
+
+func NewCustomFieldHandler(customFieldStore database.CustomFieldStore, logger *slog.Logger) *CustomFieldHandler {
+	return &CustomFieldHandler{
+		CustomFieldStore: customFieldStore,
+		Logger:           logger,
+	}
+}
+
+type CreateCustomFieldRequest struct {
+	EntityType string   `json:"entity_type" binding:"required,oneof=employee item order"`
+	Key        string   `json:"key" binding:"required"`
+	Label      string   `json:"label" binding:"required,max=100"`
+	FieldType  string   `json:"field_type" binding:"required,oneof=text number boolean date select"`
+	Options    []string `json:"options"`
+	Required   bool     `json:"required"`
+}
+
+// SetCustomFieldValuesRequest updates the given custom fields of an entity, a null value clears the field
+type SetCustomFieldValuesRequest struct {
+	Values map[string]any `json:"values" binding:"required"`
+}
+
+// validateCustomFieldValue checks that the value suits the type of the field
+func validateCustomFieldValue(definition database.CustomFieldDefinition, value any) error {
+	switch definition.FieldType {
+	case database.CustomFieldText:
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s must be a string", definition.Key)
+		}
+		if utf8.RuneCountInString(text) > maxCustomFieldText {
+			return fmt.Errorf("%s must be at most %d characters", definition.Key, maxCustomFieldText)
+		}
+	case database.CustomFieldNumber:
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s must be a number", definition.Key)
+		}
+	case database.CustomFieldBoolean:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s must be true or false", definition.Key)
+		}
+	case database.CustomFieldDate:
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s must be a date in the YYYY-MM-DD format", definition.Key)
+		}
+		if _, err := time.Parse(time.DateOnly, text); err != nil {
+			return fmt.Errorf("%s must be a date in the YYYY-MM-DD format", definition.Key)
+		}
+	case database.CustomFieldSelect:
+		text, ok := value.(string)
+		if !ok || !slices.Contains(definition.Options, text) {
+			return fmt.Errorf("%s must be one of %s", definition.Key, strings.Join(definition.Options, ", "))
+		}
+	}
+	return nil
+}
+
+// ValidateCustomFieldValues checks the values of an entity against the custom fields of its type:
+// every key must be a field, every value must suit its field type and required fields must be set
+func ValidateCustomFieldValues(definitions []database.CustomFieldDefinition, values map[string]any) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		index := slices.IndexFunc(definitions, func(d database.CustomFieldDefinition) bool { return d.Key == key })
+		if index < 0 {
+			return fmt.Errorf("unknown custom field %s", key)
+		}
+		if err := validateCustomFieldValue(definitions[index], values[key]); err != nil {
+			return err
+		}
+	}
+	for _, definition := range definitions {
+		if _, ok := values[definition.Key]; definition.Required && !ok {
+			return fmt.Errorf("%s is required", definition.Key)
+		}
+	}
+	return nil
+}
+
+// ParseCustomFieldFilters reads the cf.<key>=<value> query parameters, converting each value to the
+// type of its field
+func ParseCustomFieldFilters(query url.Values, definitions []database.CustomFieldDefinition) (map[string]any, error) {
+	filters := map[string]any{}
+	for param, values := range query {
+		key, ok := strings.CutPrefix(param, customFieldFilterPrefix)
+		if !ok {
+			continue
+		}
+		index := slices.IndexFunc(definitions, func(d database.CustomFieldDefinition) bool { return d.Key == key })
+		if index < 0 {
+			return nil, fmt.Errorf("unknown custom field %s", key)
+		}
+
+		var value any = values[0]
+		switch definitions[index].FieldType {
+		case database.CustomFieldNumber:
+			number, err := strconv.ParseFloat(values[0], 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be a number", key)
+			}
+			value = number
+		case database.CustomFieldBoolean:
+			boolean, err := strconv.ParseBool(values[0])
+			if err != nil {
+				return nil, fmt.Errorf("%s must be true or false", key)
+			}
+			value = boolean
+		}
+		if err := validateCustomFieldValue(definitions[index], value); err != nil {
+			return nil, err
+		}
+		filters[key] = value
+	}
+	return filters, nil
+}
+
+// MatchesCustomFields reports whether the values equal every filter
+func MatchesCustomFields(values map[string]any, filters map[string]any) bool {
+	for key, filter := range filters {
+		if values[key] != filter {
+			return false
+		}
+	}
+	return true
+}
+
+// withCustomFields attaches their custom field values to the entities of a list endpoint and keeps
+// those matching the cf.<key> filters of the request. It returns false once it has written an error
+// response.
+func withCustomFields[T any](c *gin.Context, store database.CustomFieldStore, logger *slog.Logger, orgID uuid.UUID, entityType string,
+	entities []T, id func(T) uuid.UUID, attach func(T, map[string]any) T) ([]T, bool) {
+	definitions, err := store.ListFieldDefinitions(orgID, entityType)
+	if err != nil {
+		logger.Error("failed to get custom fields", "error", err, "org_id", orgID, "entity_type", entityType)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve custom fields"})
+		return nil, false
+	}
+	filters, err := ParseCustomFieldFilters(c.Request.URL.Query(), definitions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if len(definitions) == 0 || len(entities) == 0 {
+		return entities, true
+	}
+
+	ids := make([]uuid.UUID, len(entities))
+	for i, entity := range entities {
+		ids[i] = id(entity)
+	}
+	values, err := store.GetCustomFieldValues(orgID, entityType, ids)
+	if err != nil {
+		logger.Error("failed to get custom field values", "error", err, "org_id", orgID, "entity_type", entityType)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve custom fields"})
+		return nil, false
+	}
+
+	matching := make([]T, 0, len(entities))
+	for _, entity := range entities {
+		entityValues := values[id(entity)]
+		if !MatchesCustomFields(entityValues, filters) {
+			continue
+		}
+		if len(entityValues) > 0 {
+			entity = attach(entity, entityValues)
+		}
+		matching = append(matching, entity)
+	}
+	return matching, true
+}
+
+// ListCustomFieldsHandler lists the custom fields of the organization, of one entity type with ?entity_type=
+func (ch *CustomFieldHandler) ListCustomFieldsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can view custom fields"})
+		return
+	}
+
+	entityType := c.Query("entity_type")
+	if entityType != "" && !slices.Contains(customFieldEntities, entityType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entity_type, expected employee, item or order"})
+		return
+	}
+
+	definitions, err := ch.CustomFieldStore.ListFieldDefinitions(user.OrganizationID, entityType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve custom fields"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Custom fields retrieved successfully", "data": definitions})
+}
+
+// CreateCustomFieldHandler adds a custom field to the employees, items or orders of the organization.
+// Required fields are enforced the next time the values of an entity are set.
+func (ch *CustomFieldHandler) CreateCustomFieldHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can manage custom fields"})
+		return
+	}
+
+	var req CreateCustomFieldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !customFieldKeyPattern.MatchString(req.Key) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "key must start with a lowercase letter and contain at most 50 lowercase letters, digits or underscores"})
+		return
+	}
+
+	label := strings.TrimSpace(req.Label)
+	if label == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "label is required"})
+		return
+	}
+
+	options := []string{}
+	if req.FieldType == database.CustomFieldSelect {
+		for _, option := range req.Options {
+			option = strings.TrimSpace(option)
+			if option == "" || len(option) > 100 || slices.Contains(options, option) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "options must be distinct, non-empty and at most 100 characters"})
+				return
+			}
+			options = append(options, option)
+		}
+		if len(options) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "select fields need at least one option"})
+			return
+		}
+	} else if len(req.Options) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "options are only allowed on select fields"})
+		return
+	}
+
+	definition := &database.CustomFieldDefinition{
+		EntityType: req.EntityType,
+		Key:        req.Key,
+		Label:      label,
+		FieldType:  req.FieldType,
+		Options:    options,
+		Required:   req.Required,
+	}
+	if err := ch.CustomFieldStore.CreateFieldDefinition(user.OrganizationID, definition); err != nil {
+		if errors.Is(err, database.ErrCustomFieldExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("A %s custom field with the key %s already exists", req.EntityType, req.Key)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create custom field"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Custom field created successfully", "data": definition})
+}
+
+// DeleteCustomFieldHandler removes a custom field along with its values
+func (ch *CustomFieldHandler) DeleteCustomFieldHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can manage custom fields"})
+		return
+	}
+
+	definitionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid custom field ID"})
+		return
+	}
+
+	if err := ch.CustomFieldStore.DeleteFieldDefinition(user.OrganizationID, definitionID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Custom field not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete custom field"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Custom field deleted successfully"})
+}
+
+// SetCustomFieldValuesHandler updates the custom field values of an employee, item or order. The
+// given fields are merged into the current values and the result is validated as a whole.
+func (ch *CustomFieldHandler) SetCustomFieldValuesHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can set custom fields"})
+		return
+	}
+
+	entityType := c.Param("entity")
+	if !slices.Contains(customFieldEntities, entityType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entity type, expected employee, item or order"})
+		return
+	}
+	entityID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entity ID"})
+		return
+	}
+
+	var req SetCustomFieldValuesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	definitions, err := ch.CustomFieldStore.ListFieldDefinitions(user.OrganizationID, entityType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve custom fields"})
+		return
+	}
+	current, err := ch.CustomFieldStore.GetCustomFieldValues(user.OrganizationID, entityType, []uuid.UUID{entityID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve custom field values"})
+		return
+	}
+
+	values := current[entityID]
+	if values == nil {
+		values = map[string]any{}
+	}
+	for key, value := range req.Values {
+		if value == nil {
+			delete(values, key)
+		} else {
+			values[key] = value
+		}
+	}
+	if err := ValidateCustomFieldValues(definitions, values); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ch.CustomFieldStore.SetCustomFieldValues(user.OrganizationID, entityType, entityID, values); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("The %s was not found", entityType)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save custom field values"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Custom field values saved successfully", "data": values})
+}
@@ -3,28 +3,37 @@ package api
 // TODO Demand is auto generated every day and store in the database -> Background Tasks
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"net/http"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/clockwise/clockwise/backend/internal/database"
 	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type DashboardHandler struct {
-	OrgStore            database.OrgStore
-	RulesStore          database.RulesStore
-	OperatingHoursStore database.OperatingHoursStore
-	OrderStore          database.OrderStore
-	CampaignStore       database.CampaignStore
-	DemandStore         database.DemandStore
-	Logger              *slog.Logger
+	OrgStore                     database.OrgStore
+	RulesStore                   database.RulesStore
+	OperatingHoursStore          database.OperatingHoursStore
+	OrderStore                   database.OrderStore
+	CampaignStore                database.CampaignStore
+	DemandStore                  database.DemandStore
+	ExternalEventStore           database.ExternalEventStore
+	OperatingHoursExceptionStore database.OperatingHoursExceptionStore
+	StatusStore                  database.StatusStore
+	Logger                       *slog.Logger
 }
 
 func NewDashboardHandler(
@@ -34,42 +43,88 @@ func NewDashboardHandler(
 	orderStore database.OrderStore,
 	campaignStore database.CampaignStore,
 	demandStore database.DemandStore,
+	externalEventStore database.ExternalEventStore,
+	operatingHoursExceptionStore database.OperatingHoursExceptionStore,
+	statusStore database.StatusStore,
 	logger *slog.Logger,
 ) *DashboardHandler {
 	return &DashboardHandler{
-		OrgStore:            orgStore,
-		RulesStore:          rulesStore,
-		OperatingHoursStore: operatingHoursStore,
-		OrderStore:          orderStore,
-		CampaignStore:       campaignStore,
-		DemandStore:         demandStore,
-		Logger:              logger,
+		OrgStore:                     orgStore,
+		RulesStore:                   rulesStore,
+		OperatingHoursStore:          operatingHoursStore,
+		OrderStore:                   orderStore,
+		CampaignStore:                campaignStore,
+		DemandStore:                  demandStore,
+		ExternalEventStore:           externalEventStore,
+		OperatingHoursExceptionStore: operatingHoursExceptionStore,
+		StatusStore:                  statusStore,
+		Logger:                       logger,
 	}
 }
 
-type DemandPredictionRequest struct {
-	Place                Place               `json:"place"`
-	Orders               []database.Order    `json:"orders"`
-	Campaigns            []database.Campaign `json:"campaigns"`
-	PredicationStartDate string              `json:"prediction_start_date"`
-	PredictionDays       *int                `json:"prediction_days,omitempty"`
+// ChannelMixPerSlot computes the channel mix of every weekday and hour the orders were placed in, ordered
+// by weekday and hour. Orders without a channel count as direct.
+func ChannelMixPerSlot(orders []database.Order) []ChannelSlotMix {
+	type slot struct{ weekday, hour int }
+	type counts struct {
+		orders, delivery, platform int
+		channels                   map[string]int
+	}
+
+	slots := map[slot]*counts{}
+	for _, order := range orders {
+		key := slot{int(order.CreateTime.Weekday()), order.CreateTime.Hour()}
+		slotCounts, ok := slots[key]
+		if !ok {
+			slotCounts = &counts{channels: map[string]int{}}
+			slots[key] = slotCounts
+		}
+
+		channel := order.Channel
+		if channel == "" {
+			channel = database.OrderChannelDirect
+		}
+		slotCounts.orders++
+		slotCounts.channels[channel]++
+		if order.OrderType == "delivery" {
+			slotCounts.delivery++
+		}
+		if database.IsPlatformChannel(channel) {
+			slotCounts.platform++
+		}
+	}
+
+	mix := make([]ChannelSlotMix, 0, len(slots))
+	for key, slotCounts := range slots {
+		share := func(count int) float64 {
+			return math.Round(float64(count)/float64(slotCounts.orders)*10000) / 100
+		}
+		shares := make(map[string]float64, len(slotCounts.channels))
+		for channel, count := range slotCounts.channels {
+			shares[channel] = share(count)
+		}
+		mix = append(mix, ChannelSlotMix{
+			Weekday:       key.weekday,
+			Hour:          key.hour,
+			Orders:        slotCounts.orders,
+			Channels:      shares,
+			DeliveryShare: share(slotCounts.delivery),
+			PlatformShare: share(slotCounts.platform),
+		})
+	}
+	sort.Slice(mix, func(i, j int) bool {
+		if mix[i].Weekday != mix[j].Weekday {
+			return mix[i].Weekday < mix[j].Weekday
+		}
+		return mix[i].Hour < mix[j].Hour
+	})
+	return mix
 }
 
-type Place struct {
-	ID                 uuid.UUID                 `json:"place_id"`
-	Name               string                    `json:"name"`
-	Type               string                    `json:"type"`
-	Latitude           *float64                  `json:"latitude,omitempty"`
-	Longitude          *float64                  `json:"longitude,omitempty"`
-	WaitingTime        int                       `json:"waiting_time"`
-	ReceivingPhone     bool                      `json:"receiving_phone"`
-	Delivery           bool                      `json:"delivery"`
-	OpeningHours       []database.OperatingHours `json:"opening_hours"`
-	FixedShifts        bool                      `json:"fixed_shifts"`
-	NumberShiftsPerDay *int                      `json:"number_of_shifts_per_day,omitempty"`
-	ShiftTimes         []database.ShiftTime      `json:"shift_time"`
-	Rating             *float64                  `json:"rating,omitempty"`
-	AcceptingOrders    bool                      `json:"accepting_orders"`
+// DemandHeatMapResponse is the stored demand with markers for the events of each day, keyed by date
+type DemandHeatMapResponse struct {
+	*database.DemandPredictResponse
+	Events map[string][]EventMarker `json:"events"`
 }
 
 func (dh *DashboardHandler) GetDemandHeatMapHandler(c *gin.Context) {
@@ -99,8 +154,116 @@ func (dh *DashboardHandler) GetDemandHeatMapHandler(c *gin.Context) {
 		return
 	}
 
+	events, err := dh.demandEvents(user.OrganizationID, demandResponse)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events"})
+		return
+	}
+
+	markers := make(map[string][]EventMarker, len(demandResponse.Days))
+	for _, day := range demandResponse.Days {
+		dayStart := time.Date(day.Date.Year(), day.Date.Month(), day.Date.Day(), 0, 0, 0, 0, time.Local)
+		markers[dayStart.Format(time.DateOnly)] = EventMarkersBetween(events, dayStart, dayStart.AddDate(0, 0, 1))
+	}
+
 	// Return the demand heatmap
-	c.JSON(http.StatusOK, demandResponse)
+	c.JSON(http.StatusOK, DemandHeatMapResponse{DemandPredictResponse: demandResponse, Events: markers})
+}
+
+// demandEvents returns the external events overlapping the days of the demand
+func (dh *DashboardHandler) demandEvents(orgID uuid.UUID, demand *database.DemandPredictResponse) ([]database.ExternalEvent, error) {
+	if len(demand.Days) == 0 {
+		return []database.ExternalEvent{}, nil
+	}
+	first, last := demand.Days[0].Date, demand.Days[len(demand.Days)-1].Date
+	from := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, time.Local)
+	to := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+	return dh.ExternalEventStore.GetEventsBetween(orgID, from, to)
+}
+
+// ExportDemandHeatMapHandler flattens the latest demand heatmap into one row per day and hour with the
+// predicted orders and items and the events overlapping the hour, as CSV or as an XLSX workbook
+func (dh *DashboardHandler) ExportDemandHeatMapHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access demand data"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or xlsx"})
+		return
+	}
+
+	demandResponse, err := dh.DemandStore.GetLatestDemandHeatMap(user.OrganizationID)
+	if err != nil {
+		dh.Logger.Error("failed to retrieve demand heatmap", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve demand data"})
+		return
+	}
+	if demandResponse == nil || len(demandResponse.Days) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No demand data found for this organization"})
+		return
+	}
+
+	events, err := dh.demandEvents(user.OrganizationID, demandResponse)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events"})
+		return
+	}
+
+	headers := []string{"date", "day", "hour", "predicted_orders", "predicted_items", "events"}
+	rows := DemandExportRows(demandResponse, events)
+	filename := "demand-" + demandResponse.Days[0].Date.Format(time.DateOnly)
+
+	switch format {
+	case "xlsx":
+		workbook, err := service.RenderTableXLSX("Demand", headers, rows)
+		if err != nil {
+			dh.Logger.Error("failed to render demand workbook", "error", err, "org_id", user.OrganizationID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export demand data"})
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.xlsx"`, filename))
+		c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", workbook)
+	default:
+		var buf bytes.Buffer
+		writer := csv.NewWriter(&buf)
+		writer.Write(headers)
+		writer.WriteAll(rows)
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, filename))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+	}
+}
+
+// DemandExportRows returns a row per day and hour of the demand, ordered as predicted, with the names of
+// the events overlapping the hour joined by "; "
+func DemandExportRows(demand *database.DemandPredictResponse, events []database.ExternalEvent) [][]string {
+	var rows [][]string
+	for _, day := range demand.Days {
+		dayStart := time.Date(day.Date.Year(), day.Date.Month(), day.Date.Day(), 0, 0, 0, 0, time.Local)
+		for _, hour := range day.Hours {
+			slotStart := dayStart.Add(time.Duration(hour.HourNo) * time.Hour)
+			var names []string
+			for _, marker := range EventMarkersBetween(events, slotStart, slotStart.Add(time.Hour)) {
+				names = append(names, marker.Name)
+			}
+			rows = append(rows, []string{
+				dayStart.Format(time.DateOnly),
+				day.Day,
+				fmt.Sprintf("%02d:00", hour.HourNo),
+				strconv.Itoa(hour.OrderCount),
+				strconv.Itoa(hour.ItemCount),
+				strings.Join(names, "; "),
+			})
+		}
+	}
+	return rows
 }
 
 func (dh *DashboardHandler) PredictDemandHeatMapHandler(c *gin.Context) {
@@ -194,13 +357,42 @@ func (dh *DashboardHandler) PredictDemandHeatMapHandler(c *gin.Context) {
 	}
 	days := 7
 
-	date := time.Now().Format(time.DateOnly)
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	events, err := dh.ExternalEventStore.GetEventsBetween(user.OrganizationID, today, today.AddDate(0, 0, days))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get organization upcoming events"})
+		return
+	}
+
+	closed, err := closedDays(dh.OperatingHoursExceptionStore, user.OrganizationID, today, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get organization closed days"})
+		return
+	}
+	closedDates := make([]string, 0, len(closed))
+	for i := 0; i < days; i++ {
+		if day := today.AddDate(0, 0, i).Format(time.DateOnly); closed[day] {
+			closedDates = append(closedDates, day)
+		}
+	}
+
+	date := today.Format(time.DateOnly)
 	request := DemandPredictionRequest{
 		Place:                Place,
 		Orders:               orders,
 		Campaigns:            campaigns,
+		Events:               events,
 		PredicationStartDate: date,
 		PredictionDays:       &days,
+		ClosedDates:          closedDates,
+		ChannelMix:           ChannelMixPerSlot(orders),
+	}
+
+	if err := validateDemandPredictionRequest(request); err != nil {
+		dh.Logger.Warn("demand payload failed validation", "org_id", user.OrganizationID, "error", err)
+		c.JSON(http.StatusUnprocessableEntity, mlPayloadErrorResponse("demand input is incomplete or invalid", err))
+		return
 	}
 
 	// Call external ML API
@@ -229,7 +421,9 @@ func (dh *DashboardHandler) PredictDemandHeatMapHandler(c *gin.Context) {
 
 	req.Header.Add("Content-Type", "application/json")
 
+	started := time.Now()
 	resp, err := client.Do(req)
+	recordMLRequest(dh.StatusStore, dh.Logger, user.OrganizationID, "/predict/demand", started, err != nil || resp.StatusCode != http.StatusOK)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -254,6 +448,17 @@ func (dh *DashboardHandler) PredictDemandHeatMapHandler(c *gin.Context) {
 		return
 	}
 
+	// Closed days have no demand, whatever the model predicted for them
+	if len(closed) > 0 {
+		openDays := make([]database.PredictionDay, 0, len(demandResponse.Days))
+		for _, day := range demandResponse.Days {
+			if !closed[day.Date.Format(time.DateOnly)] {
+				openDays = append(openDays, day)
+			}
+		}
+		demandResponse.Days = openDays
+	}
+
 	// Store in Demand Store (handles deletion + insertion atomically in a single transaction)
 	err = dh.DemandStore.StoreDemandHeatMap(user.OrganizationID, demandResponse)
 
@@ -0,0 +1,305 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultDeadLetters = 50
+	maxDeadLetters     = 200
+	// Failure rates cover this many days unless ?days= is given, at most maxFailureRateDays
+	defaultFailureRateDays = 7
+	maxFailureRateDays     = 90
+)
+
+// DeadLetterHandler lets admins review the delivery platform payloads that could not be read, fix them and
+// replay them through the integration they came from
+type DeadLetterHandler struct {
+	DeadLetterStore database.DeadLetterStore
+	PlatformStore   database.DeliveryPlatformStore
+	Logger          *slog.Logger
+
+	// Apply stores the events of a replayed payload, as the webhooks do
+	Apply func(integration database.DeliveryPlatformIntegration, events []service.PlatformEvent) service.PlatformSyncResult
+}
+
+func NewDeadLetterHandler(deadLetterStore database.DeadLetterStore, platformStore database.DeliveryPlatformStore, apply func(database.DeliveryPlatformIntegration, []service.PlatformEvent) service.PlatformSyncResult, logger *slog.Logger) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		DeadLetterStore: deadLetterStore,
+		PlatformStore:   platformStore,
+		Logger:          logger,
+		Apply:           apply,
+	}
+}
+
+type UpdateDeadLetterRequest struct {
+	Payload string `json:"payload" binding:"required"`
+}
+
+// DeadLetterReplay is a replayed dead letter with what its events did
+type DeadLetterReplay struct {
+	DeadLetter *database.DeadLetter       `json:"dead_letter"`
+	Result     service.PlatformSyncResult `json:"result"`
+}
+
+// deadLetterSources are the sources payloads are dead-lettered from, the delivery platforms
+func deadLetterSources() []string {
+	sources := []string{}
+	for _, channel := range database.OrderChannels {
+		if _, ok := service.DeliveryConnectors[channel]; ok {
+			sources = append(sources, channel)
+		}
+	}
+	return sources
+}
+
+// deadLetterID reads the :id parameter, answering 400 when it is not a UUID
+func deadLetterID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dead letter ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// GetDeadLettersHandler lists the dead letters of the organization without their payloads, latest first.
+// ?source= and ?status= narrow the list, ?limit= caps it (50 by default, at most 200).
+func (dh *DeadLetterHandler) GetDeadLettersHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can view dead letters"})
+		return
+	}
+
+	filter := database.DeadLetterFilter{Source: c.Query("source"), Status: c.Query("status"), Limit: defaultDeadLetters}
+	if _, ok := service.DeliveryConnectors[filter.Source]; filter.Source != "" && !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown source"})
+		return
+	}
+	if filter.Status != "" && filter.Status != database.DeadLetterPending && filter.Status != database.DeadLetterReplayed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending or replayed"})
+		return
+	}
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxDeadLetters {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxDeadLetters)})
+			return
+		}
+		filter.Limit = parsed
+	}
+
+	letters, err := dh.DeadLetterStore.ListDeadLetters(user.OrganizationID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve dead letters"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Dead letters retrieved successfully", "data": letters})
+}
+
+// GetDeadLetterHandler returns a dead letter with its payload
+func (dh *DeadLetterHandler) GetDeadLetterHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can view dead letters"})
+		return
+	}
+
+	id, ok := deadLetterID(c)
+	if !ok {
+		return
+	}
+	letter, err := dh.DeadLetterStore.GetDeadLetter(user.OrganizationID, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Dead letter not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve dead letter"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Dead letter retrieved successfully", "data": letter})
+}
+
+// UpdateDeadLetterHandler replaces the payload of a pending dead letter, to fix it before replaying it
+func (dh *DeadLetterHandler) UpdateDeadLetterHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can edit dead letters"})
+		return
+	}
+
+	id, ok := deadLetterID(c)
+	if !ok {
+		return
+	}
+	var request UpdateDeadLetterRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(request.Payload) > maxWebhookBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Payload too large"})
+		return
+	}
+
+	letter, err := dh.DeadLetterStore.UpdatePayload(user.OrganizationID, id, request.Payload, user.ID, time.Now())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Pending dead letter not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update dead letter"})
+		return
+	}
+
+	dh.Logger.Info("dead letter edited", "organization_id", user.OrganizationID, "dead_letter_id", id, "by", user.ID)
+	c.JSON(http.StatusOK, gin.H{"message": "Dead letter updated successfully", "data": letter})
+}
+
+// ReplayDeadLetterHandler reads the payload of a pending dead letter again and stores its events through
+// the integration of its platform. A payload still unreadable, or whose events could not all be stored,
+// stays pending with the reason.
+func (dh *DeadLetterHandler) ReplayDeadLetterHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can replay dead letters"})
+		return
+	}
+
+	id, ok := deadLetterID(c)
+	if !ok {
+		return
+	}
+	letter, err := dh.DeadLetterStore.GetDeadLetter(user.OrganizationID, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Dead letter not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replay dead letter"})
+		return
+	}
+	if letter.Status != database.DeadLetterPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Dead letter already replayed"})
+		return
+	}
+
+	connector, ok := service.DeliveryConnectors[letter.Source]
+	if !ok {
+		c.JSON(http.StatusConflict, gin.H{"error": "Unknown source"})
+		return
+	}
+	integration, err := dh.PlatformStore.GetIntegration(user.OrganizationID, letter.Source)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusConflict, gin.H{"error": "Delivery platform no longer connected"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replay dead letter"})
+		return
+	}
+	if !integration.Enabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Delivery platform disabled"})
+		return
+	}
+
+	events, err := connector.Parse([]byte(letter.Payload))
+	if err != nil {
+		updated, recordErr := dh.DeadLetterStore.RecordFailedReplay(user.OrganizationID, id, err.Error())
+		if recordErr != nil {
+			updated = letter
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "data": updated})
+		return
+	}
+
+	result := dh.Apply(*integration, events)
+	if result.Failed > 0 {
+		_, _ = dh.DeadLetterStore.RecordFailedReplay(user.OrganizationID, id, fmt.Sprintf("%d events could not be stored", result.Failed))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store some orders", "data": result})
+		return
+	}
+
+	replayed, err := dh.DeadLetterStore.MarkReplayed(user.OrganizationID, id, user.ID, time.Now())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusConflict, gin.H{"error": "Dead letter already replayed"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replay dead letter"})
+		return
+	}
+
+	dh.Logger.Info("dead letter replayed", "organization_id", user.OrganizationID, "dead_letter_id", id, "by", user.ID, "orders", result.Orders, "updates", result.Updates)
+	c.JSON(http.StatusOK, gin.H{"message": "Dead letter replayed successfully", "data": DeadLetterReplay{DeadLetter: replayed, Result: result}})
+}
+
+// GetDeadLetterMetricsHandler reports, per delivery platform, the payloads received over the last ?days=
+// (7 by default, at most 90) and the share that was dead-lettered
+func (dh *DeadLetterHandler) GetDeadLetterMetricsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can view dead letters"})
+		return
+	}
+
+	days := defaultFailureRateDays
+	if value := c.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxFailureRateDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and " + strconv.Itoa(maxFailureRateDays)})
+			return
+		}
+		days = parsed
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	rates, err := dh.DeadLetterStore.GetFailureRates(user.OrganizationID, deadLetterSources(), since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve ingestion failure rates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Ingestion failure rates retrieved successfully",
+		"data": gin.H{
+			"days":    days,
+			"sources": rates,
+		},
+	})
+}
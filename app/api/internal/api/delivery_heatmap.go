@@ -0,0 +1,82 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultHeatmapCellSize is the side of a heatmap cell in degrees, about 1 km
+	defaultHeatmapCellSize = 0.01
+	minHeatmapCellSize     = 0.0001
+	maxHeatmapCellSize     = 1.0
+)
+
+// GetDeliveryHeatmap counts the deliveries dropped off in each cell of a grid of cell_size degrees (default
+// 0.01, about 1 km), to see where the delivery demand concentrates. The deliveries can be narrowed like the
+// delivery list: from and to (YYYY-MM-DD, inclusive), status and driver_id.
+func (oh *OrderHandler) GetDeliveryHeatmap(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access deliveries"})
+		return
+	}
+
+	cellSize := defaultHeatmapCellSize
+	if value := c.Query("cell_size"); value != "" {
+		size, err := strconv.ParseFloat(value, 64)
+		if err != nil || size < minHeatmapCellSize || size > maxHeatmapCellSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "cell_size must be between 0.0001 and 1 degree"})
+			return
+		}
+		cellSize = size
+	}
+	from, to, ok := queryDateRange(c)
+	if !ok {
+		return
+	}
+	driverIDs, ok := queryIDs(c, "driver_id")
+	if !ok {
+		return
+	}
+	filter := database.DeliveryFilter{From: from, To: to, Statuses: queryList(c, "status"), DriverIDs: driverIDs}
+
+	cells, err := oh.OrderStore.GetDeliveryHeatmap(user.OrganizationID, cellSize, filter)
+	if err != nil {
+		oh.Logger.Error("failed to get delivery heatmap", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve delivery heatmap"})
+		return
+	}
+
+	deliveries := 0
+	for _, cell := range cells {
+		deliveries += cell.Deliveries
+	}
+	data := gin.H{
+		"cell_size":  cellSize,
+		"from":       nil,
+		"to":         nil,
+		"deliveries": deliveries,
+		"cells":      cells,
+	}
+	if from != nil {
+		data["from"] = from.Format(time.DateOnly)
+	}
+	if to != nil {
+		data["to"] = to.AddDate(0, 0, -1).Format(time.DateOnly)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Delivery heatmap retrieved successfully",
+		"data":    data,
+	})
+}
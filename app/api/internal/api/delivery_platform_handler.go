@@ -0,0 +1,361 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Largest webhook accepted from a delivery platform, in bytes
+const maxWebhookBytes = 1 << 20
+
+type DeliveryPlatformHandler struct {
+	PlatformStore   database.DeliveryPlatformStore
+	OrderStore      database.OrderStore
+	StatusStore     database.StatusStore
+	DeadLetterStore database.DeadLetterStore
+	Logger          *slog.Logger
+}
+
+func NewDeliveryPlatformHandler(platformStore database.DeliveryPlatformStore, orderStore database.OrderStore, statusStore database.StatusStore, deadLetterStore database.DeadLetterStore, logger *slog.Logger) *DeliveryPlatformHandler {
+	return &DeliveryPlatformHandler{
+		PlatformStore:   platformStore,
+		OrderStore:      orderStore,
+		StatusStore:     statusStore,
+		DeadLetterStore: deadLetterStore,
+		Logger:          logger,
+	}
+}
+
+// SaveDeliveryPlatformRequest connects a platform. The webhook secret and API token can be left out to
+// keep the current ones.
+type SaveDeliveryPlatformRequest struct {
+	StoreID       string  `json:"store_id" binding:"required,max=100"`
+	WebhookSecret *string `json:"webhook_secret" binding:"omitempty,min=16"`
+	APIURL        *string `json:"api_url" binding:"omitempty,url"`
+	APIToken      *string `json:"api_token"`
+	Enabled       *bool   `json:"enabled"`
+}
+
+// DeliveryPlatformResponse is an integration with the URL its platform should send webhooks to
+type DeliveryPlatformResponse struct {
+	database.DeliveryPlatformIntegration
+	WebhookURL  string `json:"webhook_url"`
+	HasAPIToken bool   `json:"has_api_token"`
+}
+
+func deliveryPlatformResponse(integration database.DeliveryPlatformIntegration) DeliveryPlatformResponse {
+	return DeliveryPlatformResponse{
+		DeliveryPlatformIntegration: integration,
+		WebhookURL:                  fmt.Sprintf("%s/api/webhooks/%s/%s", service.PublicAPIURL(), integration.Platform, integration.OrganizationID),
+		HasAPIToken:                 integration.APIToken != nil,
+	}
+}
+
+// GetDeliveryPlatformsHandler lists the delivery platforms the organization is connected to
+func (dh *DeliveryPlatformHandler) GetDeliveryPlatformsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can view delivery platforms"})
+		return
+	}
+
+	integrations, err := dh.PlatformStore.GetIntegrations(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve delivery platforms"})
+		return
+	}
+
+	response := make([]DeliveryPlatformResponse, 0, len(integrations))
+	for _, integration := range integrations {
+		response = append(response, deliveryPlatformResponse(integration))
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Delivery platforms retrieved successfully", "data": response})
+}
+
+// SaveDeliveryPlatformHandler connects the organization to a delivery platform or updates its connection
+func (dh *DeliveryPlatformHandler) SaveDeliveryPlatformHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can connect delivery platforms"})
+		return
+	}
+
+	platform := c.Param("platform")
+	if _, ok := service.DeliveryConnectors[platform]; !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown delivery platform"})
+		return
+	}
+
+	var request SaveDeliveryPlatformRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if request.APIURL != nil {
+		// the poller calls the URL from the server, like a webhook it must be public and https
+		if err := ValidateWebhookURL(*request.APIURL); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "api_url is invalid: " + err.Error()})
+			return
+		}
+	}
+
+	existing, err := dh.PlatformStore.GetIntegration(user.OrganizationID, platform)
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save delivery platform"})
+		return
+	}
+
+	integration := &database.DeliveryPlatformIntegration{Platform: platform, StoreID: request.StoreID, APIURL: request.APIURL, Enabled: true}
+	if existing != nil {
+		integration.WebhookSecret = existing.WebhookSecret
+		integration.APIToken = existing.APIToken
+	}
+	if request.WebhookSecret != nil {
+		integration.WebhookSecret = *request.WebhookSecret
+	}
+	if request.APIToken != nil {
+		integration.APIToken = request.APIToken
+	}
+	if request.Enabled != nil {
+		integration.Enabled = *request.Enabled
+	}
+	if integration.WebhookSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "webhook_secret is required to connect a delivery platform"})
+		return
+	}
+	if integration.APIURL != nil && integration.APIToken == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "api_token is required to poll the platform"})
+		return
+	}
+
+	if err := dh.PlatformStore.SaveIntegration(user.OrganizationID, integration); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save delivery platform"})
+		return
+	}
+
+	dh.Logger.Info("delivery platform saved", "organization_id", user.OrganizationID, "platform", platform, "by", user.ID, "polled", integration.APIURL != nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Delivery platform saved successfully", "data": deliveryPlatformResponse(*integration)})
+}
+
+// DeleteDeliveryPlatformHandler disconnects the organization from a delivery platform, its orders are kept
+func (dh *DeliveryPlatformHandler) DeleteDeliveryPlatformHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can disconnect delivery platforms"})
+		return
+	}
+
+	if err := dh.PlatformStore.DeleteIntegration(user.OrganizationID, c.Param("platform")); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Delivery platform not connected"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disconnect delivery platform"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Delivery platform disconnected successfully"})
+}
+
+// ReceiveWebhookHandler stores the orders and delivery status updates a platform sends. It is public and
+// only trusts webhooks signed with the secret of the integration. Platforms retry failed webhooks, which is
+// safe since orders are stored once.
+func (dh *DeliveryPlatformHandler) ReceiveWebhookHandler(c *gin.Context) {
+	platform := c.Param("platform")
+	connector, ok := service.DeliveryConnectors[platform]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown delivery platform"})
+		return
+	}
+	orgID, err := uuid.Parse(c.Param("org"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read webhook"})
+		return
+	}
+	if len(body) > maxWebhookBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Webhook too large"})
+		return
+	}
+
+	integration, err := dh.PlatformStore.GetIntegration(orgID, platform)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Delivery platform not connected"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process webhook"})
+		return
+	}
+	if !connector.Verify(c.Request.Header, body, integration.WebhookSecret) {
+		dh.Logger.Warn("delivery platform webhook with invalid signature", "organization_id", orgID, "platform", platform)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid webhook signature"})
+		return
+	}
+	// acknowledged so the platform stops retrying while the integration is paused
+	if !integration.Enabled {
+		c.JSON(http.StatusOK, gin.H{"message": "Delivery platform disabled, webhook ignored"})
+		return
+	}
+
+	events, err := connector.Parse(body)
+	if err != nil {
+		// kept for review, the platform will not send it any better
+		letter := &database.DeadLetter{Source: platform, Via: database.DeadLetterViaWebhook, Payload: string(body), Error: err.Error()}
+		if dh.DeadLetterStore.CreateDeadLetter(orgID, letter) == nil {
+			dh.Logger.Warn("delivery platform webhook dead-lettered", "organization_id", orgID, "platform", platform, "dead_letter_id", letter.ID, "error", err)
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := dh.ApplyPlatformEvents(*integration, events)
+	if result.Failed > 0 {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store some orders", "data": result})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook processed", "data": result})
+}
+
+// ApplyPlatformEvents stores the new orders of a platform, attributed to its channel, and moves the
+// orders and deliveries the status updates are about. Items are matched on the merchant's reference
+// being a ClockWise item ID, then on their name; unmatched items are left out of the order.
+func (dh *DeliveryPlatformHandler) ApplyPlatformEvents(integration database.DeliveryPlatformIntegration, events []service.PlatformEvent) service.PlatformSyncResult {
+	orgID := integration.OrganizationID
+	result := service.PlatformSyncResult{}
+	if len(events) == 0 {
+		return result
+	}
+
+	var itemsByID map[uuid.UUID]bool
+	var itemsByName map[string]uuid.UUID
+	var newest time.Time
+	for _, event := range events {
+		orderID := service.PlatformOrderID(orgID, integration.Platform, event.ExternalOrderID)
+		var delivery *database.OrderDelivery
+		if event.Delivery != nil {
+			delivery = &database.OrderDelivery{
+				OrderID:            orderID,
+				DeliveryLocation:   database.Location{Latitude: event.Delivery.Latitude, Longitude: event.Delivery.Longitude},
+				OutForDeliveryTime: event.Delivery.OutForDeliveryTime,
+				DeliveredTime:      event.Delivery.DeliveredTime,
+				DeliveryStatus:     event.Delivery.Status,
+			}
+		}
+		if event.OccurredAt.After(newest) {
+			newest = event.OccurredAt
+		}
+
+		if event.Order != nil {
+			if itemsByID == nil {
+				items, err := dh.OrderStore.GetAllItems(orgID)
+				if err != nil {
+					dh.Logger.Error("failed to get items for delivery platform orders", "error", err, "organization_id", orgID)
+					result.Failed++
+					continue
+				}
+				itemsByID = make(map[uuid.UUID]bool, len(items))
+				itemsByName = make(map[string]uuid.UUID, len(items))
+				for _, item := range items {
+					itemsByID[item.ItemID] = true
+					itemsByName[strings.ToLower(strings.TrimSpace(item.Name))] = item.ItemID
+				}
+			}
+
+			total := event.Order.TotalAmount
+			discount := math.Min(event.Order.DiscountAmount, total)
+			order := &database.Order{
+				OrderID:        orderID,
+				CreateTime:     event.Order.CreateTime,
+				OrderType:      event.Order.OrderType,
+				OrderStatus:    event.OrderStatus,
+				TotalAmount:    &total,
+				DiscountAmount: &discount,
+				Channel:        integration.Platform,
+			}
+			if order.CreateTime.IsZero() {
+				order.CreateTime = event.OccurredAt
+			}
+			for _, item := range event.Order.Items {
+				itemID, err := uuid.Parse(item.Reference)
+				if err != nil || !itemsByID[itemID] {
+					var ok bool
+					if itemID, ok = itemsByName[strings.ToLower(strings.TrimSpace(item.Name))]; !ok {
+						result.UnmatchedItems++
+						continue
+					}
+				}
+				quantity := max(item.Quantity, 1)
+				totalPrice := item.TotalPrice
+				order.OrderItems = append(order.OrderItems, database.OrderItem{ItemID: itemID, Quantity: &quantity, TotalPrice: &totalPrice})
+			}
+			if order.OrderType == "delivery" {
+				order.DeliveryStatus = delivery
+			}
+
+			err := dh.OrderStore.StoreNestedOrder(orgID, order)
+			if err == nil {
+				result.Orders++
+				continue
+			}
+			if !errors.Is(err, database.ErrDuplicateOrder) {
+				dh.Logger.Error("failed to store delivery platform order", "error", err, "organization_id", orgID, "platform", integration.Platform, "external_id", event.ExternalOrderID)
+				result.Failed++
+				continue
+			}
+			// sent again, the statuses may have moved since
+		}
+
+		if err := dh.OrderStore.UpdateOrderProgress(orgID, orderID, event.OrderStatus, delivery); err != nil {
+			if err == sql.ErrNoRows {
+				// updates of orders placed before the platform was connected
+				result.UnknownOrders++
+				continue
+			}
+			dh.Logger.Error("failed to update delivery platform order", "error", err, "organization_id", orgID, "platform", integration.Platform, "external_id", event.ExternalOrderID)
+			result.Failed++
+			continue
+		}
+		result.Updates++
+	}
+
+	now := time.Now()
+	_ = dh.PlatformStore.MarkEventReceived(orgID, integration.Platform, now)
+	recordIngestion(dh.StatusStore, dh.Logger, orgID, integration.Platform, newest, result.Orders+result.Updates)
+
+	if result.UnmatchedItems > 0 {
+		dh.Logger.Warn("delivery platform items not on the menu", "organization_id", orgID, "platform", integration.Platform, "items", result.UnmatchedItems)
+	}
+	return result
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// Thresholds of the discount audit
+const (
+	// discountAuditDays is the period of the audit when no from date is given
+	discountAuditDays = 30
+	// A customer is listed from this many discounted orders
+	discountAuditMinRepeats = 3
+	// Employees need this many orders during their shifts before their discounts are flagged
+	discountAuditMinShiftOrders = 20
+	// A customer is flagged when their discount rate is this many times that of the organization
+	customerDiscountLiftThreshold = 2.0
+	// An employee is flagged when the discount rate during their shifts is this many times that of the organization
+	shiftDiscountLiftThreshold = 1.5
+)
+
+// DiscountRates compares discounts with those of the whole organization. The discount rate is the share
+// of the sales given away and the lift is the discount rate over that of the organization.
+type DiscountRates struct {
+	DiscountedShare float64 `json:"discounted_share_percent"`
+	DiscountRate    float64 `json:"discount_rate_percent"`
+	Lift            float64 `json:"lift"`
+	Flagged         bool    `json:"flagged"`
+}
+
+// CustomerDiscountReview is a repeatedly discounted customer
+type CustomerDiscountReview struct {
+	database.CustomerDiscounts
+	DiscountRates
+}
+
+// EmployeeDiscountReview is the discounts given while an employee was on shift
+type EmployeeDiscountReview struct {
+	database.ShiftDiscounts
+	DiscountRates
+}
+
+// DiscountAudit lists the customers and the employees whose discounts stand out from the organization
+type DiscountAudit struct {
+	Organization struct {
+		database.DiscountStats
+		DiscountedShare float64 `json:"discounted_share_percent"`
+		DiscountRate    float64 `json:"discount_rate_percent"`
+	} `json:"organization"`
+	Customers []CustomerDiscountReview `json:"customers"`
+	Employees []EmployeeDiscountReview `json:"employees"`
+}
+
+func discountRates(stats database.DiscountStats, orgRate float64) DiscountRates {
+	rates := DiscountRates{}
+	if stats.Orders > 0 {
+		rates.DiscountedShare = math.Round(float64(stats.DiscountedOrders)*10000/float64(stats.Orders)) / 100
+	}
+	if stats.Sales > 0 {
+		rates.DiscountRate = math.Round(stats.Discount*10000/stats.Sales) / 100
+	}
+	if orgRate > 0 {
+		rates.Lift = math.Round(rates.DiscountRate/orgRate*100) / 100
+	}
+	return rates
+}
+
+// AuditDiscounts compares the discounts of the customers and of the shifts of the employees with those of
+// the organization. Customers discounted 3 times or more are flagged at twice the discount rate of the
+// organization, employees with 20 orders or more during their shifts at 1.5 times its rate.
+func AuditDiscounts(org database.DiscountStats, customers []database.CustomerDiscounts, employees []database.ShiftDiscounts) DiscountAudit {
+	audit := DiscountAudit{
+		Customers: make([]CustomerDiscountReview, 0, len(customers)),
+		Employees: make([]EmployeeDiscountReview, 0, len(employees)),
+	}
+	orgRates := discountRates(org, 0)
+	audit.Organization.DiscountStats = org
+	audit.Organization.DiscountedShare = orgRates.DiscountedShare
+	audit.Organization.DiscountRate = orgRates.DiscountRate
+
+	for _, customer := range customers {
+		review := CustomerDiscountReview{CustomerDiscounts: customer, DiscountRates: discountRates(customer.DiscountStats, orgRates.DiscountRate)}
+		review.Flagged = customer.DiscountedOrders >= discountAuditMinRepeats && review.Lift >= customerDiscountLiftThreshold
+		audit.Customers = append(audit.Customers, review)
+	}
+	for _, employee := range employees {
+		review := EmployeeDiscountReview{ShiftDiscounts: employee, DiscountRates: discountRates(employee.DiscountStats, orgRates.DiscountRate)}
+		review.Flagged = employee.Orders >= discountAuditMinShiftOrders && review.Lift >= shiftDiscountLiftThreshold
+		audit.Employees = append(audit.Employees, review)
+	}
+	return audit
+}
+
+// GetDiscountAudit cross-references the discounts of a period with the customers who got them and the
+// employees on shift when they were given, for loss-prevention reviews. from and to (YYYY-MM-DD, inclusive)
+// default to the last 30 days.
+func (oh *OrderHandler) GetDiscountAudit(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can audit discounts"})
+		return
+	}
+
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+	from := to.AddDate(0, 0, -discountAuditDays)
+	queryFrom, queryTo, ok := queryDateRange(c)
+	if !ok {
+		return
+	}
+	if queryTo != nil {
+		to = *queryTo
+		from = to.AddDate(0, 0, -discountAuditDays)
+	}
+	if queryFrom != nil {
+		from = *queryFrom
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	org, err := oh.OrderStore.GetDiscountStats(user.OrganizationID, from, to)
+	if err != nil {
+		oh.Logger.Error("failed to get discount stats", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to audit discounts"})
+		return
+	}
+	customers, err := oh.OrderStore.GetCustomerDiscounts(user.OrganizationID, from, to, discountAuditMinRepeats)
+	if err != nil {
+		oh.Logger.Error("failed to get customer discounts", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to audit discounts"})
+		return
+	}
+	employees, err := oh.OrderStore.GetShiftDiscounts(user.OrganizationID, from, to)
+	if err != nil {
+		oh.Logger.Error("failed to get shift discounts", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to audit discounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Discount audit retrieved successfully",
+		"data": gin.H{
+			"from":  from.Format(time.DateOnly),
+			"to":    to.AddDate(0, 0, -1).Format(time.DateOnly),
+			"audit": AuditDiscounts(*org, customers, employees),
+		},
+	})
+}
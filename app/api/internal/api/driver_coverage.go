@@ -0,0 +1,270 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// driverRole is the organization role delivering the orders, staffed from the delivery demand
+	driverRole = "driver"
+
+	// deliveryDemandWeeks is how many past weeks the expected deliveries are averaged over
+	deliveryDemandWeeks    = 4
+	maxDeliveryDemandWeeks = 12
+)
+
+// Statuses of a driver coverage slot
+const (
+	DriverCoverageShort   = "short"
+	DriverCoverageOver    = "over"
+	DriverCoverageCovered = "covered"
+)
+
+// DriverCoverage compares the deliveries expected in the hours of the next seven days with the drivers
+// scheduled in them. Only hours with expected deliveries or scheduled drivers are listed.
+type DriverCoverage struct {
+	Role                    string               `json:"role"`
+	HistoryWeeks            int                  `json:"history_weeks"`
+	DeliveriesPerDriverHour *int                 `json:"deliveries_per_driver_hour"`
+	ExpectedDeliveries      int                  `json:"expected_deliveries"`
+	DriverHours             int                  `json:"driver_hours"`
+	ShortSlots              int                  `json:"short_slots"`
+	OverSlots               int                  `json:"over_slots"`
+	Slots                   []DriverCoverageSlot `json:"slots"`
+}
+
+// DriverCoverageSlot is an hour of a day with the deliveries expected in it and the drivers it needs
+// and has
+type DriverCoverageSlot struct {
+	Date               string `json:"date"`
+	Day                string `json:"day"`
+	Time               string `json:"time"`
+	ExpectedDeliveries int    `json:"expected_deliveries"`
+	DriversNeeded      int    `json:"drivers_needed"`
+	DriversScheduled   int    `json:"drivers_scheduled"`
+	Status             string `json:"status"`
+}
+
+// GetDriverCoverageHandler reports whether the drivers of the published schedule cover the deliveries
+// expected from the last weeks of delivery orders
+func (sh *ScheduleHandler) GetDriverCoverageHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access the driver coverage"})
+		return
+	}
+
+	weeks := deliveryDemandWeeks
+	if value := c.Query("weeks"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxDeliveryDemandWeeks {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid weeks, expected a number between 1 and 12"})
+			return
+		}
+		weeks = parsed
+	}
+
+	roles, err := sh.RoleStore.GetRolesByOrganizationID(user.OrganizationID)
+	if err != nil {
+		sh.Logger.Error("failed to get roles for driver coverage", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve the driver coverage"})
+		return
+	}
+	role := findDriverRole(roles)
+	if role == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "The organization has no driver role"})
+		return
+	}
+
+	today := startOfDay(time.Now())
+	expected, err := sh.expectedDeliveries(user.OrganizationID, today, weeks)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve the driver coverage"})
+		return
+	}
+
+	shifts, err := sh.ScheduleStore.GetShiftAcknowledgments(user.OrganizationID, database.ShiftFilter{Roles: []string{role.Role}})
+	if err != nil {
+		sh.Logger.Error("failed to get driver shifts", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve the driver coverage"})
+		return
+	}
+
+	coverage := assessDriverCoverage(*role, expected, shifts, today)
+	coverage.HistoryWeeks = weeks
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Driver coverage retrieved successfully",
+		"data":    coverage,
+	})
+}
+
+// buildDeliveryDemand is the delivery demand of the scheduled days for the driver role, nil when the
+// organization does not deliver or has no driver role
+func (sh *ScheduleHandler) buildDeliveryDemand(orgID uuid.UUID, rules *database.OrganizationRules, roles []database.OrganizationRole, days []database.PredictionDay) (*DeliveryDemand, error) {
+	role := findDriverRole(roles)
+	if !rules.Delivery || role == nil {
+		return nil, nil
+	}
+
+	expected, err := sh.expectedDeliveries(orgID, startOfDay(time.Now()), deliveryDemandWeeks)
+	if err != nil {
+		return nil, err
+	}
+
+	demand := &DeliveryDemand{Role: role.Role, HistoryWeeks: deliveryDemandWeeks, Days: make([]DeliveryDemandDay, 0, len(days))}
+	for _, day := range days {
+		name := strings.ToLower(day.Day)
+		demandDay := DeliveryDemandDay{Day: name, Date: day.Date.Format(time.DateOnly), Hours: []DeliveryDemandHour{}}
+		for hour := 0; hour < 24; hour++ {
+			if orders := expected[name][hour]; orders > 0 {
+				demandDay.Hours = append(demandDay.Hours, DeliveryDemandHour{HourNo: hour, DeliveryOrders: orders})
+			}
+		}
+		demand.Days = append(demand.Days, demandDay)
+	}
+	return demand, nil
+}
+
+// expectedDeliveries averages the delivery orders of the weeks before today per weekday and hour
+func (sh *ScheduleHandler) expectedDeliveries(orgID uuid.UUID, today time.Time, weeks int) (map[string]map[int]int, error) {
+	volume, err := sh.OrderStore.GetDeliveryVolume(orgID, today.AddDate(0, 0, -7*weeks), today)
+	if err != nil {
+		sh.Logger.Error("failed to get delivery volume", "error", err, "org_id", orgID)
+		return nil, err
+	}
+	return averageDeliveries(volume, weeks), nil
+}
+
+// averageDeliveries turns the delivery orders counted over weeks weeks into the deliveries of an average
+// week, rounded to whole orders
+func averageDeliveries(volume []database.DeliveryVolume, weeks int) map[string]map[int]int {
+	expected := make(map[string]map[int]int)
+	for _, v := range volume {
+		orders := (v.Orders + weeks/2) / weeks
+		if orders == 0 {
+			continue
+		}
+		if expected[v.Weekday] == nil {
+			expected[v.Weekday] = make(map[int]int)
+		}
+		expected[v.Weekday][v.Hour] = orders
+	}
+	return expected
+}
+
+// findDriverRole returns the role delivering the orders, nil when the organization has none
+func findDriverRole(roles []database.OrganizationRole) *database.OrganizationRole {
+	for i := range roles {
+		if strings.EqualFold(roles[i].Role, driverRole) {
+			return &roles[i]
+		}
+	}
+	return nil
+}
+
+// deliveriesByHour returns the expected deliveries of the day by hour, nil without a delivery demand
+func deliveriesByHour(demand *DeliveryDemand, day string) map[int]int {
+	if demand == nil {
+		return nil
+	}
+	for _, demandDay := range demand.Days {
+		if strings.EqualFold(demandDay.Day, day) {
+			hours := make(map[int]int, len(demandDay.Hours))
+			for _, hour := range demandDay.Hours {
+				hours[hour.HourNo] = hour.DeliveryOrders
+			}
+			return hours
+		}
+	}
+	return map[int]int{}
+}
+
+// roleDemand is what the headcount of the role follows in the hour: the expected deliveries for the
+// driver role when there is a delivery demand, the predicted items otherwise
+func roleDemand(demand *DeliveryDemand, role string, items, deliveries map[int]int, hour int) int {
+	if demand != nil && role == demand.Role {
+		return deliveries[hour]
+	}
+	return items[hour]
+}
+
+// assessDriverCoverage compares the expected deliveries of the seven days from today with the driver
+// shifts, a shift covering every hour it overlaps
+func assessDriverCoverage(role database.OrganizationRole, expected map[string]map[int]int, shifts []database.ShiftAcknowledgment, today time.Time) DriverCoverage {
+	coverage := DriverCoverage{Role: role.Role, Slots: []DriverCoverageSlot{}}
+	if role.NeedForDemand {
+		coverage.DeliveriesPerDriverHour = role.ItemsPerRolePerHour
+	}
+
+	drivers := make(map[string]*[24]int)
+	for _, shift := range shifts {
+		window, ok := clockWindow(shift.StartTime, shift.EndTime)
+		if !ok {
+			continue
+		}
+		date := shift.Date.Format(time.DateOnly)
+		if drivers[date] == nil {
+			drivers[date] = &[24]int{}
+		}
+		for hour := 0; hour < 24; hour++ {
+			if window[0] < (hour+1)*60 && window[1] > hour*60 {
+				drivers[date][hour]++
+			}
+		}
+	}
+
+	for i := 0; i < 7; i++ {
+		date := today.AddDate(0, 0, i)
+		day := database.ValidDays[date.Weekday()]
+		scheduled := drivers[date.Format(time.DateOnly)]
+		if scheduled == nil {
+			scheduled = &[24]int{}
+		}
+		for hour := 0; hour < 24; hour++ {
+			deliveries := expected[day][hour]
+			if deliveries == 0 && scheduled[hour] == 0 {
+				continue
+			}
+			slot := DriverCoverageSlot{
+				Date:               date.Format(time.DateOnly),
+				Day:                day,
+				Time:               formatClockMinutes(hour*60) + "-" + formatClockMinutes((hour+1)*60),
+				ExpectedDeliveries: deliveries,
+				DriversScheduled:   scheduled[hour],
+				Status:             DriverCoverageCovered,
+			}
+			if deliveries > 0 {
+				slot.DriversNeeded = roleHeadcount(role, deliveries)
+			}
+			switch {
+			case slot.DriversScheduled < slot.DriversNeeded:
+				slot.Status = DriverCoverageShort
+				coverage.ShortSlots++
+			case slot.DriversScheduled > slot.DriversNeeded:
+				slot.Status = DriverCoverageOver
+				coverage.OverSlots++
+			}
+			coverage.ExpectedDeliveries += deliveries
+			coverage.DriverHours += slot.DriversScheduled
+			coverage.Slots = append(coverage.Slots, slot)
+		}
+	}
+	return coverage
+}
+
+// startOfDay is midnight of the day of t
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
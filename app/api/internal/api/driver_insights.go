@@ -0,0 +1,125 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// driverInsightsDays is the period of the driver insights when no from date is given
+	driverInsightsDays = 30
+	// Delivery SLA defaults, a delivery is on time when delivered within the SLA of leaving
+	defaultDeliverySLAMinutes    = 45
+	defaultSLABreachAlertPercent = 20
+)
+
+// deliverySLAMinutes returns the delivery SLA of the rules, the default one for an organization without rules
+func deliverySLAMinutes(rules *database.OrganizationRules) int {
+	if rules == nil || rules.DeliverySLAMinutes <= 0 {
+		return defaultDeliverySLAMinutes
+	}
+	return rules.DeliverySLAMinutes
+}
+
+// DriverPerformance rates the deliveries of a driver. Deliveries per day are per day the driver went out,
+// and the average delivery time and the on-time rate are nil until a delivery was delivered.
+type DriverPerformance struct {
+	database.DriverDeliveryStats
+	AverageDeliveryMinutes *float64 `json:"average_delivery_minutes"`
+	DeliveriesPerDay       float64  `json:"deliveries_per_day"`
+	OnTimeRate             *float64 `json:"on_time_rate_percent"`
+}
+
+// RateDrivers computes the averages and rates of the deliveries of every driver, rounded to 2 decimals
+func RateDrivers(drivers []database.DriverDeliveryStats) []DriverPerformance {
+	rated := make([]DriverPerformance, 0, len(drivers))
+	for _, driver := range drivers {
+		driver.DistanceKm = math.Round(driver.DistanceKm*100) / 100
+		performance := DriverPerformance{DriverDeliveryStats: driver}
+		if driver.ActiveDays > 0 {
+			performance.DeliveriesPerDay = math.Round(float64(driver.Deliveries)*100/float64(driver.ActiveDays)) / 100
+		}
+		if driver.Delivered > 0 {
+			average := math.Round(driver.DeliveryMinutes*100/float64(driver.Delivered)) / 100
+			onTime := math.Round(float64(driver.OnTime)*10000/float64(driver.Delivered)) / 100
+			performance.AverageDeliveryMinutes = &average
+			performance.OnTimeRate = &onTime
+		}
+		rated = append(rated, performance)
+	}
+	return rated
+}
+
+// GetDriverDeliveryInsights rates the delivery staff on the deliveries they went out for: average delivery
+// time, deliveries per day, on-time rate and distance covered. from and to (YYYY-MM-DD, inclusive) default
+// to the last 30 days, and on_time_minutes to the delivery SLA of the organization.
+func (oh *OrderHandler) GetDriverDeliveryInsights(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access deliveries"})
+		return
+	}
+
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+	from := to.AddDate(0, 0, -driverInsightsDays)
+	queryFrom, queryTo, ok := queryDateRange(c)
+	if !ok {
+		return
+	}
+	if queryTo != nil {
+		to = *queryTo
+		from = to.AddDate(0, 0, -driverInsightsDays)
+	}
+	if queryFrom != nil {
+		from = *queryFrom
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	var onTimeMinutes int
+	if value := c.Query("on_time_minutes"); value != "" {
+		minutes, err := strconv.Atoi(value)
+		if err != nil || minutes <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "on_time_minutes must be a positive number of minutes"})
+			return
+		}
+		onTimeMinutes = minutes
+	} else {
+		rules, err := oh.RulesStore.GetRulesByOrganizationID(user.OrganizationID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve driver insights"})
+			return
+		}
+		onTimeMinutes = deliverySLAMinutes(rules)
+	}
+
+	drivers, err := oh.OrderStore.GetDriverDeliveryStats(user.OrganizationID, from, to, onTimeMinutes)
+	if err != nil {
+		oh.Logger.Error("failed to get driver delivery stats", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve driver insights"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Driver insights retrieved successfully",
+		"data": gin.H{
+			"from":            from.Format(time.DateOnly),
+			"to":              to.AddDate(0, 0, -1).Format(time.DateOnly),
+			"on_time_minutes": onTimeMinutes,
+			"drivers":         RateDrivers(drivers),
+		},
+	})
+}
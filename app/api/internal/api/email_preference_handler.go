@@ -0,0 +1,100 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type EmailPreferenceHandler struct {
+	EmailPreferenceStore database.EmailPreferenceStore
+	Unsubscribe          *service.UnsubscribeSigner
+	Logger               *slog.Logger
+}
+
+func NewEmailPreferenceHandler(emailPreferenceStore database.EmailPreferenceStore, unsubscribe *service.UnsubscribeSigner, logger *slog.Logger) *EmailPreferenceHandler {
+	return &EmailPreferenceHandler{
+		EmailPreferenceStore: emailPreferenceStore,
+		Unsubscribe:          unsubscribe,
+		Logger:               logger,
+	}
+}
+
+type UpdateEmailPreferencesRequest struct {
+	OptOuts []string `json:"opt_outs" binding:"dive,oneof=reminders digests marketing"`
+}
+
+// GetEmailPreferencesHandler returns the categories of non-critical emails the current user opted out of
+func (eh *EmailPreferenceHandler) GetEmailPreferencesHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	optOuts, err := eh.EmailPreferenceStore.GetOptOuts(user.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve email preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Email preferences retrieved successfully",
+		"data":    gin.H{"opt_outs": optOuts, "categories": database.EmailCategories},
+	})
+}
+
+// UpdateEmailPreferencesHandler replaces the categories the current user opted out of, an empty list
+// subscribes them to every category again
+func (eh *EmailPreferenceHandler) UpdateEmailPreferencesHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	var request UpdateEmailPreferencesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	optOuts := []string{}
+	for _, category := range database.EmailCategories {
+		if slices.Contains(request.OptOuts, category) {
+			optOuts = append(optOuts, category)
+		}
+	}
+
+	if err := eh.EmailPreferenceStore.SetOptOuts(user.Email, optOuts); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update email preferences"})
+		return
+	}
+
+	eh.Logger.Info("email preferences updated", "user_id", user.ID, "opt_outs", optOuts)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Email preferences updated successfully",
+		"data":    gin.H{"opt_outs": optOuts, "categories": database.EmailCategories},
+	})
+}
+
+// UnsubscribeHandler opts an address out of a category from the signed link of an email. It is public,
+// and answers both the link itself and one-click unsubscribes (RFC 8058) posted by mail clients.
+func (eh *EmailPreferenceHandler) UnsubscribeHandler(c *gin.Context) {
+	email, category, err := eh.Unsubscribe.Parse(c.Query("token"))
+	if err != nil || !slices.Contains(database.EmailCategories, category) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid unsubscribe link"})
+		return
+	}
+
+	if err := eh.EmailPreferenceStore.OptOut(email, category); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unsubscribe"})
+		return
+	}
+
+	eh.Logger.Info("email unsubscribed from link", "category", category)
+	c.JSON(http.StatusOK, gin.H{"message": "You have been unsubscribed from " + category + " emails", "data": gin.H{"category": category}})
+}
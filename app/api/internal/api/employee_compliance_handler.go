@@ -0,0 +1,238 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var (
+	phonePattern    = regexp.MustCompile(`^\+?[0-9][0-9 ()-]{5,18}[0-9]$`)
+	documentPattern = regexp.MustCompile(`^[A-Za-z0-9-]{4,30}$`)
+	ibanPattern     = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+)
+
+type EmployeeComplianceHandler struct {
+	UserStore       database.UserStore
+	ComplianceStore database.EmployeeComplianceStore
+	Logger          *slog.Logger
+}
+
+func NewEmployeeComplianceHandler(userStore database.UserStore, complianceStore database.EmployeeComplianceStore, logger *slog.Logger) *EmployeeComplianceHandler {
+	return &EmployeeComplianceHandler{
+		UserStore:       userStore,
+		ComplianceStore: complianceStore,
+		Logger:          logger,
+	}
+}
+
+type EmergencyContactRequest struct {
+	Name         string  `json:"name" binding:"required,max=100"`
+	Relationship *string `json:"relationship" binding:"omitempty,max=50"`
+	Phone        string  `json:"phone" binding:"required"`
+}
+
+type BankDetailsRequest struct {
+	AccountHolder string  `json:"account_holder" binding:"required,max=100"`
+	BankName      *string `json:"bank_name" binding:"omitempty,max=100"`
+	IBAN          string  `json:"iban" binding:"required"`
+}
+
+// ComplianceRequest replaces the compliance details of an employee, omitted sections are cleared
+type ComplianceRequest struct {
+	EmergencyContact *EmergencyContactRequest `json:"emergency_contact"`
+	NationalID       *string                  `json:"national_id"`
+	NationalIDExpiry *string                  `json:"national_id_expiry"`
+	WorkPermitNumber *string                  `json:"work_permit_number"`
+	WorkPermitExpiry *string                  `json:"work_permit_expiry"`
+	BankDetails      *BankDetailsRequest      `json:"bank_details"`
+}
+
+// ValidIBAN reports whether the IBAN, without spaces, passes the ISO 13616 mod-97 checksum
+func ValidIBAN(iban string) bool {
+	if !ibanPattern.MatchString(iban) {
+		return false
+	}
+	var digits strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		if r >= 'A' && r <= 'Z' {
+			fmt.Fprintf(&digits, "%d", r-'A'+10)
+		} else {
+			digits.WriteRune(r)
+		}
+	}
+	number, ok := new(big.Int).SetString(digits.String(), 10)
+	return ok && new(big.Int).Mod(number, big.NewInt(97)).Int64() == 1
+}
+
+// parseDocument validates a document number and its YYYY-MM-DD expiry, which must be given together
+func parseDocument(numberField, expiryField string, number, expiry *string) (*string, *time.Time, error) {
+	if number == nil && expiry == nil {
+		return nil, nil, nil
+	}
+	if number == nil || expiry == nil {
+		return nil, nil, fmt.Errorf("%s and %s must be given together", numberField, expiryField)
+	}
+	value := strings.ToUpper(strings.TrimSpace(*number))
+	if !documentPattern.MatchString(value) {
+		return nil, nil, fmt.Errorf("%s must be 4 to 30 letters, digits or dashes", numberField)
+	}
+	day, err := time.Parse(time.DateOnly, *expiry)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s must use the YYYY-MM-DD format", expiryField)
+	}
+	return &value, &day, nil
+}
+
+// ComplianceFromRequest validates the request and builds the compliance details of the employee
+func ComplianceFromRequest(employeeID uuid.UUID, req ComplianceRequest) (*database.EmployeeCompliance, error) {
+	compliance := &database.EmployeeCompliance{UserID: employeeID}
+
+	if contact := req.EmergencyContact; contact != nil {
+		name := strings.TrimSpace(contact.Name)
+		if name == "" {
+			return nil, errors.New("emergency_contact.name is required")
+		}
+		phone := strings.TrimSpace(contact.Phone)
+		if !phonePattern.MatchString(phone) {
+			return nil, errors.New("emergency_contact.phone must be a phone number of 7 to 20 digits, spaces, dashes or parentheses")
+		}
+		compliance.EmergencyContact = database.EmergencyContact{Name: &name, Relationship: contact.Relationship, Phone: &phone}
+	}
+
+	var err error
+	if compliance.NationalID, compliance.NationalIDExpiry, err = parseDocument("national_id", "national_id_expiry", req.NationalID, req.NationalIDExpiry); err != nil {
+		return nil, err
+	}
+	if compliance.WorkPermitNumber, compliance.WorkPermitExpiry, err = parseDocument("work_permit_number", "work_permit_expiry", req.WorkPermitNumber, req.WorkPermitExpiry); err != nil {
+		return nil, err
+	}
+
+	if bank := req.BankDetails; bank != nil {
+		holder := strings.TrimSpace(bank.AccountHolder)
+		if holder == "" {
+			return nil, errors.New("bank_details.account_holder is required")
+		}
+		iban := strings.ToUpper(strings.ReplaceAll(bank.IBAN, " ", ""))
+		if !ValidIBAN(iban) {
+			return nil, errors.New("bank_details.iban is not a valid IBAN")
+		}
+		compliance.BankDetails = database.BankDetails{AccountHolder: &holder, BankName: bank.BankName, IBAN: &iban}
+	}
+	return compliance, nil
+}
+
+// maskNumber hides all but the last 4 characters of a document or account number
+func maskNumber(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	visible := *value
+	if len(visible) > 4 {
+		visible = visible[len(visible)-4:]
+	}
+	masked := "****" + visible
+	return &masked
+}
+
+// complianceEmployee returns the employee of the :id parameter, or the user on self-service routes,
+// writing the error response when it is not in the organization or the user may not access it
+func (ch *EmployeeComplianceHandler) complianceEmployee(c *gin.Context, user *database.User, roles ...string) *database.User {
+	if c.Param("id") == "" {
+		return user
+	}
+	employeeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid employee ID"})
+		return nil
+	}
+	if employeeID == user.ID {
+		return user
+	}
+	if !slices.Contains(roles, user.UserRole) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot access the compliance details of this employee"})
+		return nil
+	}
+	employee, err := ch.UserStore.GetUserByID(employeeID)
+	if err != nil || employee == nil || employee.OrganizationID != user.OrganizationID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		return nil
+	}
+	return employee
+}
+
+// GetComplianceHandler returns the emergency contact, documents and bank details of an employee.
+// Managers see the contact and expiry dates, with the document and account numbers masked.
+func (ch *EmployeeComplianceHandler) GetComplianceHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	employee := ch.complianceEmployee(c, user, "admin", "manager")
+	if employee == nil {
+		return
+	}
+
+	compliance, err := ch.ComplianceStore.GetCompliance(employee.ID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve compliance details"})
+			return
+		}
+		compliance = &database.EmployeeCompliance{UserID: employee.ID}
+	}
+
+	if user.UserRole != "admin" && employee.ID != user.ID {
+		compliance.NationalID = maskNumber(compliance.NationalID)
+		compliance.WorkPermitNumber = maskNumber(compliance.WorkPermitNumber)
+		compliance.BankDetails.AccountHolder = nil
+		compliance.BankDetails.IBAN = maskNumber(compliance.BankDetails.IBAN)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Compliance details retrieved successfully", "data": compliance})
+}
+
+// UpdateComplianceHandler replaces the compliance details of an employee. Only the employee and
+// admins can change them.
+func (ch *EmployeeComplianceHandler) UpdateComplianceHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	employee := ch.complianceEmployee(c, user, "admin")
+	if employee == nil {
+		return
+	}
+
+	var req ComplianceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	compliance, err := ComplianceFromRequest(employee.ID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ch.ComplianceStore.SaveCompliance(compliance); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save compliance details"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Compliance details saved successfully", "data": compliance})
+}
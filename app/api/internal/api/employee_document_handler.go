@@ -0,0 +1,383 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// EmployeeDocumentHandler generates offer and termination letters and records their electronic signature
+type EmployeeDocumentHandler struct {
+	Documents     *service.DocumentService
+	UserStore     database.UserStore
+	OrgStore      database.OrgStore
+	BrandingStore database.BrandingStore
+	Logger        *slog.Logger
+}
+
+func NewEmployeeDocumentHandler(documents *service.DocumentService, userStore database.UserStore, orgStore database.OrgStore, brandingStore database.BrandingStore, logger *slog.Logger) *EmployeeDocumentHandler {
+	return &EmployeeDocumentHandler{
+		Documents:     documents,
+		UserStore:     userStore,
+		OrgStore:      orgStore,
+		BrandingStore: brandingStore,
+		Logger:        logger,
+	}
+}
+
+type DocumentTemplateRequest struct {
+	Title string `json:"title" binding:"required"`
+	Body  string `json:"body" binding:"required"`
+}
+
+// CreateEmployeeDocumentRequest generates a letter, StartDate is used by offer letters and LastDay and
+// Reason by termination letters
+type CreateEmployeeDocumentRequest struct {
+	Kind      string `json:"kind" binding:"required"`
+	StartDate string `json:"start_date"`
+	LastDay   string `json:"last_day"`
+	Reason    string `json:"reason"`
+}
+
+type SignDocumentRequest struct {
+	SignerName string `json:"signer_name" binding:"required,max=255"`
+	// Agree confirms the signer means their typed name as their signature
+	Agree bool `json:"agree"`
+}
+
+// documentDate checks an optional YYYY-MM-DD date of a document, empty stays empty
+func documentDate(field, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if _, err := time.Parse(time.DateOnly, value); err != nil {
+		return "", fmt.Errorf("invalid %s, expected YYYY-MM-DD", field)
+	}
+	return value, nil
+}
+
+// issueDocument generates a letter of the kind for an employee of the organization, signed by them with the
+// link they are emailed
+func issueDocument(documents *service.DocumentService, orgStore database.OrgStore, orgID uuid.UUID, employee *database.User, kind string, fields service.DocumentFields, createdBy uuid.UUID) (*database.EmployeeDocument, error) {
+	org, err := orgStore.GetOrganizationByID(orgID)
+	if err != nil {
+		return nil, err
+	}
+	fields.Organization = org.Name
+	return documents.Issue(orgID, employee, kind, fields, createdBy)
+}
+
+// documentManager returns the user when they may generate documents and read those of the organization
+func documentManager(c *gin.Context) *database.User {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return nil
+	}
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can manage employee documents"})
+		return nil
+	}
+	return user
+}
+
+// GetDocumentTemplateHandler returns the organization's template of a kind of letter, the built-in one
+// (without updated_at) when it has none
+func (dh *EmployeeDocumentHandler) GetDocumentTemplateHandler(c *gin.Context) {
+	user := documentManager(c)
+	if user == nil {
+		return
+	}
+	kind := c.Param("kind")
+	if !database.IsDocumentKind(kind) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown document kind, expected offer_letter or termination_letter"})
+		return
+	}
+
+	template, err := dh.Documents.Template(user.OrganizationID, kind)
+	if err != nil {
+		dh.Logger.Error("failed to get document template", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve document template"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Document template retrieved successfully",
+		"data":    template,
+	})
+}
+
+// UpdateDocumentTemplateHandler replaces the organization's template of a kind of letter, admins only
+func (dh *EmployeeDocumentHandler) UpdateDocumentTemplateHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change document templates"})
+		return
+	}
+	kind := c.Param("kind")
+	if !database.IsDocumentKind(kind) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown document kind, expected offer_letter or termination_letter"})
+		return
+	}
+
+	var request DocumentTemplateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := service.ValidateDocumentTemplate(request.Title, request.Body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template := &database.DocumentTemplate{Kind: kind, Title: request.Title, Body: request.Body, UpdatedBy: &user.ID}
+	if err := dh.Documents.Store.SaveTemplate(user.OrganizationID, template); err != nil {
+		dh.Logger.Error("failed to save document template", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save document template"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Document template saved successfully",
+		"data":    template,
+	})
+}
+
+// CreateEmployeeDocumentHandler generates a letter for an employee from the organization's template and
+// emails them the link to sign it
+func (dh *EmployeeDocumentHandler) CreateEmployeeDocumentHandler(c *gin.Context) {
+	user := documentManager(c)
+	if user == nil {
+		return
+	}
+
+	employeeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid employee ID"})
+		return
+	}
+	var request CreateEmployeeDocumentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !database.IsDocumentKind(request.Kind) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid kind, expected offer_letter or termination_letter"})
+		return
+	}
+	fields := service.DocumentFields{Reason: strings.TrimSpace(request.Reason)}
+	if fields.StartDate, err = documentDate("start_date", request.StartDate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if fields.LastDay, err = documentDate("last_day", request.LastDay); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	employee, err := dh.UserStore.GetUserByID(employeeID)
+	if err != nil || employee == nil || employee.OrganizationID != user.OrganizationID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		return
+	}
+
+	doc, err := issueDocument(dh.Documents, dh.OrgStore, user.OrganizationID, employee, request.Kind, fields, user.ID)
+	if err != nil {
+		dh.Logger.Error("failed to generate employee document", "error", err, "employee_id", employeeID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate document"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Document generated and sent for signature",
+		"data":    doc,
+	})
+}
+
+// GetEmployeeDocumentsHandler lists the documents of the organization, of one employee with ?employee_id=.
+// Documents of laid off employees are kept with their name and email.
+func (dh *EmployeeDocumentHandler) GetEmployeeDocumentsHandler(c *gin.Context) {
+	user := documentManager(c)
+	if user == nil {
+		return
+	}
+
+	var employeeID *uuid.UUID
+	if value := c.Query("employee_id"); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid employee ID"})
+			return
+		}
+		employeeID = &parsed
+	}
+
+	documents, err := dh.Documents.Store.GetDocuments(user.OrganizationID, employeeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve documents"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Documents retrieved successfully",
+		"data":    documents,
+	})
+}
+
+// GetMyDocumentsHandler lists the documents of the current user
+func (dh *EmployeeDocumentHandler) GetMyDocumentsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	documents, err := dh.Documents.Store.GetDocuments(user.OrganizationID, &user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve documents"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Documents retrieved successfully",
+		"data":    documents,
+	})
+}
+
+// GetDocumentPDFHandler downloads a document, the stored signed PDF once it is signed. Admins, managers and
+// the employee of the document can download it.
+func (dh *EmployeeDocumentHandler) GetDocumentPDFHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+	documentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return
+	}
+
+	doc, err := dh.Documents.Store.GetDocument(user.OrganizationID, documentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve document"})
+		return
+	}
+	ownDocument := doc.EmployeeID != nil && *doc.EmployeeID == user.ID
+	if user.UserRole != "admin" && user.UserRole != "manager" && !ownDocument {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	dh.writeDocumentPDF(c, doc)
+}
+
+// writeDocumentPDF answers with the signed PDF of a signed document, a rendering of the letter otherwise
+func (dh *EmployeeDocumentHandler) writeDocumentPDF(c *gin.Context, doc *database.EmployeeDocument) {
+	var pdf []byte
+	if doc.SignedAt != nil {
+		signed, err := dh.Documents.Store.GetSignedPDF(doc.OrganizationID, doc.ID)
+		if err != nil {
+			dh.Logger.Error("failed to get signed document", "error", err, "document_id", doc.ID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve document"})
+			return
+		}
+		pdf = signed
+	} else {
+		pdf = service.RenderDocumentPDF(pdfBranding(dh.OrgStore, dh.BrandingStore, dh.Logger, doc.OrganizationID), doc)
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s-%s.pdf"`, strings.ReplaceAll(doc.Kind, "_", "-"), doc.ID))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// signableDocument returns the document of the :token parameter, writing a 404 for unknown and expired links
+func (dh *EmployeeDocumentHandler) signableDocument(c *gin.Context) *database.EmployeeDocument {
+	doc, err := dh.Documents.Store.GetDocumentBySignToken(c.Param("token"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "This signing link is invalid or has expired"})
+			return nil
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve document"})
+		return nil
+	}
+	return doc
+}
+
+// GetSignDocumentHandler shows the document of a signing link to the employee, who needs no account.
+// ?format=pdf downloads it.
+func (dh *EmployeeDocumentHandler) GetSignDocumentHandler(c *gin.Context) {
+	doc := dh.signableDocument(c)
+	if doc == nil {
+		return
+	}
+	if c.Query("format") == "pdf" {
+		dh.writeDocumentPDF(c, doc)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Document retrieved successfully",
+		"data": gin.H{
+			"id":              doc.ID,
+			"kind":            doc.Kind,
+			"title":           doc.Title,
+			"body":            doc.Body,
+			"employee_name":   doc.EmployeeName,
+			"sign_expires_at": doc.SignExpiresAt,
+			"signed_at":       doc.SignedAt,
+			"signer_name":     doc.SignerName,
+		},
+	})
+}
+
+// SignDocumentHandler records the electronic signature of the document of a signing link: the typed name
+// of the signer, the time and their IP address, and stores the signed PDF
+func (dh *EmployeeDocumentHandler) SignDocumentHandler(c *gin.Context) {
+	doc := dh.signableDocument(c)
+	if doc == nil {
+		return
+	}
+
+	var request SignDocumentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.TrimSpace(request.SignerName) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "signer_name is required"})
+		return
+	}
+	if !request.Agree {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You must agree to sign the document electronically"})
+		return
+	}
+
+	branding := pdfBranding(dh.OrgStore, dh.BrandingStore, dh.Logger, doc.OrganizationID)
+	if _, err := dh.Documents.Sign(doc, request.SignerName, c.ClientIP(), branding); err != nil {
+		if errors.Is(err, database.ErrDocumentSigned) {
+			c.JSON(http.StatusConflict, gin.H{"error": "This document is already signed"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign document"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Document signed successfully",
+		"data": gin.H{
+			"id":            doc.ID,
+			"signed_at":     doc.SignedAt,
+			"signer_name":   doc.SignerName,
+			"signer_ip":     doc.SignerIP,
+			"signed_sha256": doc.SignedSHA256,
+		},
+	})
+}
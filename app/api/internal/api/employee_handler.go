@@ -1,8 +1,11 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/clockwise/clockwise/backend/internal/database"
 	"github.com/clockwise/clockwise/backend/internal/middleware"
@@ -12,29 +15,48 @@ import (
 )
 
 type EmployeeHandler struct {
-	userStore    database.UserStore
-	requestStore database.RequestStore
-	orgStore     database.OrgStore
-	EmailService service.EmailService
-	Logger       *slog.Logger
+	userStore         database.UserStore
+	requestStore      database.RequestStore
+	orgStore          database.OrgStore
+	notificationStore database.NotificationStore
+	blackoutStore     database.BlackoutStore
+	approvalStore     database.ApprovalStore
+	EmailService      service.EmailService
+	Logger            *slog.Logger
+
+	// Webhooks receives the approved requests, nil sends none
+	Webhooks *service.WebhookService
+	// Documents generates the termination letter of a layoff, nil generates none
+	Documents *service.DocumentService
+	// Router routes the request notifications along the rules of the organization, nil emails managers and admins
+	Router *NotificationRouter
 }
 
-func NewEmployeeHandler(userStore database.UserStore, emailService service.EmailService, requestStore database.RequestStore, orgStore database.OrgStore, logger *slog.Logger) *EmployeeHandler {
+func NewEmployeeHandler(userStore database.UserStore, emailService service.EmailService, requestStore database.RequestStore, orgStore database.OrgStore, notificationStore database.NotificationStore, blackoutStore database.BlackoutStore, approvalStore database.ApprovalStore, logger *slog.Logger) *EmployeeHandler {
 	return &EmployeeHandler{
-		userStore:    userStore,
-		requestStore: requestStore,
-		orgStore:     orgStore,
-		EmailService: emailService,
-		Logger:       logger,
+		userStore:         userStore,
+		requestStore:      requestStore,
+		orgStore:          orgStore,
+		notificationStore: notificationStore,
+		blackoutStore:     blackoutStore,
+		approvalStore:     approvalStore,
+		EmailService:      emailService,
+		Logger:            logger,
 	}
 }
 
 type LayoffRequest struct {
 	Reason string `json:"reason"`
+	// TerminationLetter generates a termination letter the employee is emailed to sign
+	TerminationLetter bool `json:"termination_letter"`
+	// LastDay is the last day of work the letter states, YYYY-MM-DD, immediately when empty
+	LastDay string `json:"last_day"`
 }
 
 type RequestActionBody struct {
 	RequestID string `json:"request_id" binding:"required"`
+	// Override lets an admin approve a holiday request overlapping a blackout
+	Override bool `json:"override"`
 }
 
 // GetEmployeeDetails godoc
@@ -134,6 +156,30 @@ func (h *EmployeeHandler) LayoffEmployee(c *gin.Context) {
 		req.Reason = "No reason provided"
 	}
 
+	// the letter is generated first, a layoff without the letter that was asked for is not done
+	var document *database.EmployeeDocument
+	if req.TerminationLetter {
+		if h.Documents == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Document generation is not available"})
+			return
+		}
+		lastDay, err := documentDate("last_day", req.LastDay)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		fields := service.DocumentFields{LastDay: lastDay}
+		if req.Reason != "No reason provided" {
+			fields.Reason = req.Reason
+		}
+		document, err = issueDocument(h.Documents, h.orgStore, user.OrganizationID, employee, database.DocumentTerminationLetter, fields, user.ID)
+		if err != nil {
+			h.Logger.Error("failed to generate termination letter", "error", err, "employee_id", employeeID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate termination letter"})
+			return
+		}
+	}
+
 	if err := h.userStore.LayoffUser(employeeID, req.Reason); err != nil {
 		h.Logger.Error("failed to layoff employee", "error", err, "employee_id", employeeID)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to layoff employee"})
@@ -147,10 +193,14 @@ func (h *EmployeeHandler) LayoffEmployee(c *gin.Context) {
 	}()
 
 	h.Logger.Info("employee laid off successfully", "employee_id", employeeID, "by", user.ID)
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"message":     "Employee laid off successfully",
 		"employee_id": employeeID,
-	})
+	}
+	if document != nil {
+		response["document_id"] = document.ID
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // GetEmployeeRequests godoc
@@ -198,6 +248,20 @@ func (h *EmployeeHandler) GetEmployeeRequests(c *gin.Context) {
 		return
 	}
 
+	requestIDs := make([]uuid.UUID, len(requests))
+	for i, request := range requests {
+		requestIDs[i] = request.ID
+	}
+	approvals, err := h.approvalStore.GetRequestApprovals(requestIDs)
+	if err != nil {
+		h.Logger.Error("failed to get request approvals", "error", err, "employee_id", employeeID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve requests"})
+		return
+	}
+	for _, request := range requests {
+		request.Approvals = approvals[request.ID]
+	}
+
 	h.Logger.Info("employee requests retrieved", "employee_id", employeeID, "count", len(requests))
 	c.JSON(http.StatusOK, gin.H{
 		"message":  "Employee requests retrieved successfully",
@@ -251,11 +315,87 @@ func (h *EmployeeHandler) ApproveRequest(c *gin.Context) {
 		return
 	}
 
-	if err := h.requestStore.UpdateRequestStatus(requestID, "accepted"); err != nil {
+	// Holidays overlapping a blackout, including one set after the request was submitted, need an
+	// explicit admin override
+	if request.Type == "holiday" && request.StartDate != nil && request.EndDate != nil {
+		blackouts, err := h.blackoutStore.GetOverlappingBlackouts(user.OrganizationID, *request.StartDate, *request.EndDate)
+		if err != nil {
+			h.Logger.Error("failed to check blackouts", "error", err, "request_id", requestID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve request"})
+			return
+		}
+		if len(blackouts) > 0 {
+			if user.UserRole != "admin" {
+				c.JSON(http.StatusForbidden, gin.H{"error": blackoutMessage(blackouts[0]) + ". Only an admin can approve this request"})
+				return
+			}
+			if !req.Override {
+				c.JSON(http.StatusConflict, gin.H{"error": blackoutMessage(blackouts[0]) + ". Set override to approve it anyway"})
+				return
+			}
+			h.Logger.Info("blackout overridden", "request_id", requestID, "blackout_id", blackouts[0].ID, "by", user.ID)
+		}
+	}
+
+	// The request is only accepted once every step of its approval chain approved it
+	if request.Status == "accepted" || request.Status == "declined" {
+		c.JSON(http.StatusConflict, gin.H{"error": "The request has already been " + request.Status})
+		return
+	}
+	approvals, err := h.requestApprovals(user.OrganizationID, request)
+	if err != nil {
+		h.Logger.Error("failed to get request approvals", "error", err, "request_id", requestID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve request"})
+		return
+	}
+	pending := pendingApprovals(approvals)
+	steps := stepsDecidedBy(user.UserRole, pending, database.ApprovalApproved)
+	if steps == nil {
+		if len(pending) == 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "The request has already been decided"})
+			return
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "This request is awaiting the sign-off of an " + pending[0].ApproverRole})
+		return
+	}
+	if err := h.approvalStore.DecideApprovalSteps(requestID, steps, database.ApprovalApproved, user.ID); err != nil {
+		if errors.Is(err, database.ErrApprovalStepDecided) {
+			c.JSON(http.StatusConflict, gin.H{"error": "The request was decided by someone else in the meantime"})
+			return
+		}
+		h.Logger.Error("failed to record approval", "error", err, "request_id", requestID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve request"})
+		return
+	}
+	if len(steps) < len(pending) {
+		next := pending[len(steps)]
+		go h.notifyNextApprovers(user.OrganizationID, employee, request, user.FullName, next.ApproverRole)
+
+		h.Logger.Info("request approval step recorded", "request_id", requestID, "step", steps[0], "by", user.ID)
+		c.JSON(http.StatusOK, gin.H{
+			"message":            "Request approved, awaiting the sign-off of an " + next.ApproverRole,
+			"request_id":         requestID,
+			"next_approver_role": next.ApproverRole,
+		})
+		return
+	}
+
+	// Approved holidays and call-offs make the employee unavailable for the schedules generated from now on
+	unavailability, err := h.requestStore.AcceptRequest(requestID)
+	if err != nil {
 		h.Logger.Error("failed to approve request", "error", err, "request_id", requestID)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve request"})
 		return
 	}
+	h.Webhooks.Publish(user.OrganizationID, database.WebhookRequestApproved, gin.H{
+		"request_id":     requestID,
+		"employee_id":    employee.ID,
+		"type":           request.Type,
+		"start_date":     request.StartDate,
+		"end_date":       request.EndDate,
+		"approved_by":    user.ID,
+		"unavailability": unavailability,
+	})
 
 	go func() {
 		if err := h.EmailService.SendRequestApprovedEmail(employee.Email, employee.FullName, request.Type); err != nil {
@@ -263,22 +403,19 @@ func (h *EmployeeHandler) ApproveRequest(c *gin.Context) {
 		}
 	}()
 
-	//TODO: Handle If type = resign mark the employee as not working, else if holiday cancel for the whole day if call off cancel for the next shift
-	switch request.Type {
-		case "resign":
-
-		case "holiday":
-		
-		case "calloff":
-	}
+	//TODO: Handle If type = resign mark the employee as not working
 
 	//TODO: Send update schedule and redirect to the schedule to remove request
 
 	h.Logger.Info("request approved", "request_id", requestID, "by", user.ID)
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"message":    "Request approved successfully",
 		"request_id": requestID,
-	})
+	}
+	if unavailability != nil {
+		response["unavailability"] = unavailability
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // DeclineRequest godoc
@@ -326,6 +463,37 @@ func (h *EmployeeHandler) DeclineRequest(c *gin.Context) {
 		return
 	}
 
+	// Any step of the approval chain can decline the request
+	if request.Status == "accepted" || request.Status == "declined" {
+		c.JSON(http.StatusConflict, gin.H{"error": "The request has already been " + request.Status})
+		return
+	}
+	approvals, err := h.requestApprovals(user.OrganizationID, request)
+	if err != nil {
+		h.Logger.Error("failed to get request approvals", "error", err, "request_id", requestID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decline request"})
+		return
+	}
+	pending := pendingApprovals(approvals)
+	steps := stepsDecidedBy(user.UserRole, pending, database.ApprovalDeclined)
+	if steps == nil {
+		if len(pending) == 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "The request has already been decided"})
+			return
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "This request is awaiting the sign-off of an " + pending[0].ApproverRole})
+		return
+	}
+	if err := h.approvalStore.DecideApprovalSteps(requestID, steps, database.ApprovalDeclined, user.ID); err != nil {
+		if errors.Is(err, database.ErrApprovalStepDecided) {
+			c.JSON(http.StatusConflict, gin.H{"error": "The request was decided by someone else in the meantime"})
+			return
+		}
+		h.Logger.Error("failed to record decline", "error", err, "request_id", requestID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decline request"})
+		return
+	}
+
 	if err := h.requestStore.UpdateRequestStatus(requestID, "declined"); err != nil {
 		h.Logger.Error("failed to decline request", "error", err, "request_id", requestID)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decline request"})
@@ -348,6 +516,9 @@ func (h *EmployeeHandler) DeclineRequest(c *gin.Context) {
 type CalloffRequest struct {
 	Type    string `json:"type" binding:"required,oneof=calloff holiday resign"`
 	Message string `json:"message" binding:"required"`
+	// StartDate and EndDate (default StartDate) are required for holiday requests
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
 }
 
 // RequestCalloffHandlerForEmployee godoc
@@ -378,37 +549,124 @@ func (h *EmployeeHandler) RequestHandlerForEmployee(c *gin.Context) {
 		Message:    req.Message,
 	}
 
+	// Holidays overlapping a reject blackout are refused, those overlapping a flag blackout are
+	// submitted for an admin to decide
+	var flagged *database.TimeOffBlackout
+	if req.Type == "holiday" {
+		if req.StartDate == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "start_date is required for holiday requests"})
+			return
+		}
+		startDate, err := time.Parse(time.DateOnly, req.StartDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "start_date must use the YYYY-MM-DD format"})
+			return
+		}
+		endDate := startDate
+		if req.EndDate != "" {
+			if endDate, err = time.Parse(time.DateOnly, req.EndDate); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "end_date must use the YYYY-MM-DD format"})
+				return
+			}
+		}
+		if endDate.Before(startDate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "end_date must not be before start_date"})
+			return
+		}
+		now := time.Now()
+		if startDate.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "start_date cannot be in the past"})
+			return
+		}
+
+		blackouts, err := h.blackoutStore.GetOverlappingBlackouts(user.OrganizationID, startDate, endDate)
+		if err != nil {
+			h.Logger.Error("failed to check blackouts", "error", err, "user_id", user.ID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit request"})
+			return
+		}
+		// rejecting blackouts come first
+		if len(blackouts) > 0 {
+			if blackouts[0].Action == database.BlackoutReject {
+				h.Logger.Info("holiday request refused by blackout", "user_id", user.ID, "blackout_id", blackouts[0].ID)
+				c.JSON(http.StatusConflict, gin.H{"error": blackoutMessage(blackouts[0]), "blackout": blackouts[0]})
+				return
+			}
+			flagged = &blackouts[0]
+			request.BlackoutID = &flagged.ID
+		}
+		request.StartDate = &startDate
+		request.EndDate = &endDate
+	}
+
 	if err := h.requestStore.CreateRequest(request); err != nil {
 		h.Logger.Error("failed to create request", "error", err, "user_id", user.ID)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit request"})
 		return
 	}
 
+	// The chain is snapshotted so that later changes only apply to new requests. When it cannot be
+	// started it is started with the current chain on the first decision.
+	chain, err := h.approvalStore.GetApprovalChain(user.OrganizationID, req.Type)
+	if err == nil {
+		err = h.approvalStore.StartApprovals(request.ID, chain)
+	}
+	if err != nil {
+		h.Logger.Error("failed to start approval chain", "error", err, "request_id", request.ID)
+	}
+
+	notifyMessage := req.Message
+	if flagged != nil {
+		notifyMessage += " (" + blackoutMessage(*flagged) + ", only an admin can approve it)"
+	}
+
 	go func() {
 		if err := h.EmailService.SendRequestSubmittedEmail(user.Email, user.FullName, req.Type, req.Message); err != nil {
 			h.Logger.Error("failed to send request submitted email", "error", err, "email", user.Email)
 		}
 
-		// Notify managers and admins
-		managerEmails, err := h.orgStore.GetManagerEmailsByOrgID(user.OrganizationID)
-		if err != nil {
-			h.Logger.Error("failed to get manager emails", "error", err)
+		summary := fmt.Sprintf("%s submitted a %s request: %s", user.FullName, req.Type, notifyMessage)
+
+		// Notify managers and admins, or whoever the routing rules of the organization send the request to.
+		// Texted recipients are done, the others are emailed.
+		var notifyEmails []string
+		routed := false
+		if h.Router != nil {
+			route, err := h.Router.Route(user.OrganizationID, RoutingEvent{Event: req.Type, EmployeeID: user.ID, At: time.Now()})
+			if err != nil {
+				h.Logger.Error("failed to route request notification", "error", err, "request_id", request.ID)
+			} else {
+				notifyEmails = h.Router.Dispatch(route, summary)
+				routed = true
+			}
 		}
-		adminEmails, err := h.orgStore.GetAdminEmailsByOrgID(user.OrganizationID)
-		if err != nil {
-			h.Logger.Error("failed to get admin emails", "error", err)
+		if !routed {
+			managerEmails, err := h.orgStore.GetManagerEmailsByOrgID(user.OrganizationID)
+			if err != nil {
+				h.Logger.Error("failed to get manager emails", "error", err)
+			}
+			adminEmails, err := h.orgStore.GetAdminEmailsByOrgID(user.OrganizationID)
+			if err != nil {
+				h.Logger.Error("failed to get admin emails", "error", err)
+			}
+			notifyEmails = append(managerEmails, adminEmails...)
 		}
-		notifyEmails := append(managerEmails, adminEmails...)
+		// Recipients on hourly or daily digests get the request in their next digest instead
+		notifyEmails = digestOrNotify(h.notificationStore, h.Logger, notifyEmails, "request:"+user.ID.String()+":"+req.Type, summary)
 		if len(notifyEmails) > 0 {
-			if err := h.EmailService.SendRequestNotifyEmail(notifyEmails, user.FullName, req.Type, req.Message); err != nil {
+			if err := h.EmailService.SendRequestNotifyEmail(notifyEmails, user.FullName, req.Type, notifyMessage); err != nil {
 				h.Logger.Error("failed to send request notification to managers/admins", "error", err)
 			}
 		}
 	}()
 
 	h.Logger.Info("request submitted successfully", "request_id", request.ID, "user_id", user.ID, "type", req.Type)
-	c.JSON(http.StatusCreated, gin.H{
+	response := gin.H{
 		"message":    "Request submitted successfully",
 		"request_id": request.ID,
-	})
+	}
+	if flagged != nil {
+		response["warning"] = blackoutMessage(*flagged) + ". Only an admin can approve this request"
+	}
+	c.JSON(http.StatusCreated, response)
 }
@@ -0,0 +1,225 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Longest a record can be kept
+const maxRecordRetentionYears = 7
+
+// How long each kind of record is kept when no retain_until is given
+var defaultRecordRetention = map[string]time.Duration{
+	database.EmployeeRecordNote:         365 * 24 * time.Hour,
+	database.EmployeeRecordWarning:      365 * 24 * time.Hour,
+	database.EmployeeRecordWriteUp:      2 * 365 * 24 * time.Hour,
+	database.EmployeeRecordCommendation: 2 * 365 * 24 * time.Hour,
+}
+
+type EmployeeRecordHandler struct {
+	UserStore           database.UserStore
+	EmployeeRecordStore database.EmployeeRecordStore
+	Logger              *slog.Logger
+}
+
+func NewEmployeeRecordHandler(userStore database.UserStore, employeeRecordStore database.EmployeeRecordStore, logger *slog.Logger) *EmployeeRecordHandler {
+	return &EmployeeRecordHandler{
+		UserStore:           userStore,
+		EmployeeRecordStore: employeeRecordStore,
+		Logger:              logger,
+	}
+}
+
+type CreateEmployeeRecordRequest struct {
+	Kind        string  `json:"kind" binding:"required,oneof=note warning write_up commendation"`
+	Title       string  `json:"title" binding:"required,max=255"`
+	Details     *string `json:"details" binding:"omitempty,max=5000"`
+	RetainUntil string  `json:"retain_until"`
+}
+
+// RecordRetainUntil is the day until which a record is kept: the requested YYYY-MM-DD day, or the default
+// retention of its kind. The day must be in the future and at most 7 years away.
+func RecordRetainUntil(kind, requested string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if requested == "" {
+		return today.Add(defaultRecordRetention[kind]), nil
+	}
+
+	day, err := time.Parse(time.DateOnly, requested)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("retain_until must be in YYYY-MM-DD format")
+	}
+	if !day.After(today) {
+		return time.Time{}, fmt.Errorf("retain_until must be in the future")
+	}
+	if day.After(today.AddDate(maxRecordRetentionYears, 0, 0)) {
+		return time.Time{}, fmt.Errorf("records cannot be kept longer than %d years", maxRecordRetentionYears)
+	}
+	return day, nil
+}
+
+// employeeOfOrg looks up the employee of the :id parameter, writing the error response when it is not in the organization
+func (eh *EmployeeRecordHandler) employeeOfOrg(c *gin.Context, user *database.User) *database.User {
+	employeeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid employee ID"})
+		return nil
+	}
+	employee, err := eh.UserStore.GetUserByID(employeeID)
+	if err != nil || employee == nil || employee.OrganizationID != user.OrganizationID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		return nil
+	}
+	return employee
+}
+
+// CreateEmployeeRecordHandler adds a confidential note, warning, write-up or commendation to an employee's record
+func (eh *EmployeeRecordHandler) CreateEmployeeRecordHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can manage employee records"})
+		return
+	}
+
+	employee := eh.employeeOfOrg(c, user)
+	if employee == nil {
+		return
+	}
+	if employee.ID == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot add records about yourself"})
+		return
+	}
+
+	var request CreateEmployeeRecordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	title := strings.TrimSpace(request.Title)
+	if title == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title is required"})
+		return
+	}
+
+	retainUntil, err := RecordRetainUntil(request.Kind, request.RetainUntil, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authorID := user.ID
+	authorName := user.FullName
+	record := &database.EmployeeRecord{
+		EmployeeID:  employee.ID,
+		AuthorID:    &authorID,
+		AuthorName:  &authorName,
+		Kind:        request.Kind,
+		Title:       title,
+		Details:     request.Details,
+		RetainUntil: retainUntil,
+	}
+	if err := eh.EmployeeRecordStore.CreateRecord(user.OrganizationID, record); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create employee record"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Employee record created successfully", "data": record})
+}
+
+// GetEmployeeRecordsHandler lists the records about an employee. Admins see every record, managers only
+// the ones they wrote.
+func (eh *EmployeeRecordHandler) GetEmployeeRecordsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can manage employee records"})
+		return
+	}
+
+	employee := eh.employeeOfOrg(c, user)
+	if employee == nil {
+		return
+	}
+
+	records, err := eh.EmployeeRecordStore.GetRecordsForEmployee(user.OrganizationID, employee.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve employee records"})
+		return
+	}
+
+	if user.UserRole != "admin" {
+		own := []database.EmployeeRecord{}
+		for _, record := range records {
+			if record.AuthorID != nil && *record.AuthorID == user.ID {
+				own = append(own, record)
+			}
+		}
+		records = own
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Employee records retrieved successfully", "data": records})
+}
+
+// DeleteEmployeeRecordHandler removes a record before its retention ends. Admins can remove any record,
+// managers only the ones they wrote.
+func (eh *EmployeeRecordHandler) DeleteEmployeeRecordHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can manage employee records"})
+		return
+	}
+
+	employeeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid employee ID"})
+		return
+	}
+	recordID, err := uuid.Parse(c.Param("record"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid record ID"})
+		return
+	}
+
+	record, err := eh.EmployeeRecordStore.GetRecordByID(user.OrganizationID, recordID)
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete employee record"})
+		return
+	}
+	// Managers cannot tell the records of others apart from missing ones
+	if record == nil || record.EmployeeID != employeeID ||
+		(user.UserRole != "admin" && (record.AuthorID == nil || *record.AuthorID != user.ID)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee record not found"})
+		return
+	}
+
+	if err := eh.EmployeeRecordStore.DeleteRecord(user.OrganizationID, recordID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Employee record not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete employee record"})
+		return
+	}
+
+	eh.Logger.Info("employee record deleted", "id", recordID, "employee_id", employeeID, "by", user.ID)
+	c.JSON(http.StatusOK, gin.H{"message": "Employee record deleted successfully"})
+}
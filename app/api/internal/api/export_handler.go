@@ -0,0 +1,282 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ExportHandler queues exports of the history of an organization, produced in the background by the export
+// service, and hands out links to download the finished files. Admins choose how the files are encrypted.
+type ExportHandler struct {
+	ExportJobStore  database.ExportJobStore
+	EncryptionStore database.ExportEncryptionStore
+	Exports         *service.ExportService
+	// Downloads serves the files kept on disk, nil when exports are kept in S3
+	Downloads *service.LocalExportStorage
+	Logger    *slog.Logger
+}
+
+func NewExportHandler(exportJobStore database.ExportJobStore, encryptionStore database.ExportEncryptionStore, exports *service.ExportService, logger *slog.Logger) *ExportHandler {
+	downloads, _ := exports.Storage.(*service.LocalExportStorage)
+	return &ExportHandler{
+		ExportJobStore:  exportJobStore,
+		EncryptionStore: encryptionStore,
+		Exports:         exports,
+		Downloads:       downloads,
+		Logger:          logger,
+	}
+}
+
+type CreateExportRequest struct {
+	Entity string `json:"entity" binding:"required,oneof=orders deliveries"`
+	From   string `json:"from" binding:"required"`
+	To     string `json:"to" binding:"required"`
+	Format string `json:"format" binding:"omitempty,oneof=csv xlsx"`
+}
+
+// exportManager returns the user when they may export the history of the organization
+func exportManager(c *gin.Context) *database.User {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return nil
+	}
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can export data"})
+		return nil
+	}
+	return user
+}
+
+// CreateExportHandler queues an export of the orders or deliveries created from one day to another, both
+// included. The requester is emailed a link to the file once it is ready.
+func (h *ExportHandler) CreateExportHandler(c *gin.Context) {
+	user := exportManager(c)
+	if user == nil {
+		return
+	}
+
+	var req CreateExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	from, err := time.Parse(time.DateOnly, req.From)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+		return
+	}
+	to, err := time.Parse(time.DateOnly, req.To)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD"})
+		return
+	}
+	if from.After(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+	if req.Format == "" {
+		req.Format = database.ExportFormatCSV
+	}
+
+	job := &database.ExportJob{
+		OrganizationID: user.OrganizationID,
+		RequestedBy:    &user.ID,
+		Entity:         req.Entity,
+		From:           from,
+		To:             to,
+		Format:         req.Format,
+	}
+	if err := h.ExportJobStore.CreateExportJob(job); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue export"})
+		return
+	}
+
+	h.Logger.Info("export queued", "org_id", user.OrganizationID, "job_id", job.ID, "entity", job.Entity)
+	c.JSON(http.StatusAccepted, gin.H{"message": "Export queued, you will be emailed a download link once it is ready", "data": job})
+}
+
+// GetExportsHandler lists the exports of the organization
+func (h *ExportHandler) GetExportsHandler(c *gin.Context) {
+	user := exportManager(c)
+	if user == nil {
+		return
+	}
+
+	jobs, err := h.ExportJobStore.GetExportJobs(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve exports"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Exports retrieved successfully", "data": jobs})
+}
+
+// GetExportHandler returns an export, with a fresh download link once it is completed
+func (h *ExportHandler) GetExportHandler(c *gin.Context) {
+	user := exportManager(c)
+	if user == nil {
+		return
+	}
+
+	jobID, err := uuid.Parse(c.Param("job"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export ID"})
+		return
+	}
+
+	job, err := h.ExportJobStore.GetExportJob(user.OrganizationID, jobID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Export not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve export"})
+		return
+	}
+
+	response := gin.H{"message": "Export retrieved successfully", "data": job}
+	if job.Status == database.ExportJobCompleted {
+		link, expires, err := h.Exports.DownloadURL(job)
+		if err != nil {
+			h.Logger.Error("failed to sign export link", "error", err, "job_id", job.ID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign download link"})
+			return
+		}
+		response["download_url"] = link
+		response["download_expires_at"] = expires
+		response["encrypted"] = job.Encrypted()
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// DownloadExportHandler serves an export kept on disk to the holder of a signed link, without logging in
+func (h *ExportHandler) DownloadExportHandler(c *gin.Context) {
+	if h.Downloads == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Export not found"})
+		return
+	}
+
+	path, fileName, err := h.Downloads.Open(c.Query("token"), time.Now())
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid or expired download link"})
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Export not found"})
+		return
+	}
+
+	c.FileAttachment(path, fileName)
+}
+
+// ExportEncryptionRequest encrypts the files of the organization to public_key, an ASCII-armored OpenPGP
+// public key, or with passphrase
+type ExportEncryptionRequest struct {
+	Mode       string `json:"mode" binding:"required,oneof=pgp passphrase"`
+	PublicKey  string `json:"public_key"`
+	Passphrase string `json:"passphrase" binding:"max=200"`
+}
+
+// exportAdmin returns the user when they may change how the files of the organization are encrypted
+func exportAdmin(c *gin.Context) *database.User {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return nil
+	}
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can manage export encryption"})
+		return nil
+	}
+	return user
+}
+
+// GetExportEncryptionHandler returns how the exports and emailed reports of the organization are
+// encrypted, data is null when they are not. The passphrase is never returned.
+func (h *ExportHandler) GetExportEncryptionHandler(c *gin.Context) {
+	user := exportAdmin(c)
+	if user == nil {
+		return
+	}
+
+	encryption, err := h.EncryptionStore.GetExportEncryption(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve export encryption"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Export encryption retrieved successfully", "data": encryption})
+}
+
+// SetExportEncryptionHandler encrypts the exports produced from now on and the emailed reports of the
+// organization. Files already produced are left as they are.
+func (h *ExportHandler) SetExportEncryptionHandler(c *gin.Context) {
+	user := exportAdmin(c)
+	if user == nil {
+		return
+	}
+
+	var req ExportEncryptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	encryption := &database.ExportEncryption{OrganizationID: user.OrganizationID, Mode: req.Mode, UpdatedBy: &user.ID}
+	switch req.Mode {
+	case database.ExportEncryptionPGP:
+		publicKey := strings.TrimSpace(req.PublicKey)
+		fingerprint, err := service.ParsePGPPublicKey(publicKey)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		encryption.PublicKey, encryption.KeyFingerprint = &publicKey, &fingerprint
+	case database.ExportEncryptionPassphrase:
+		if utf8.RuneCountInString(req.Passphrase) < service.MinExportPassphraseLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("passphrase must be at least %d characters", service.MinExportPassphraseLength)})
+			return
+		}
+		encryption.Passphrase = &req.Passphrase
+	}
+
+	if err := h.EncryptionStore.SetExportEncryption(encryption); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save export encryption"})
+		return
+	}
+
+	h.Logger.Info("export encryption set", "org_id", user.OrganizationID, "mode", encryption.Mode, "by", user.ID)
+	c.JSON(http.StatusOK, gin.H{"message": "Export encryption saved successfully", "data": encryption})
+}
+
+// DeleteExportEncryptionHandler stops encrypting the exports and emailed reports of the organization
+func (h *ExportHandler) DeleteExportEncryptionHandler(c *gin.Context) {
+	user := exportAdmin(c)
+	if user == nil {
+		return
+	}
+
+	if err := h.EncryptionStore.DeleteExportEncryption(user.OrganizationID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Exports are not encrypted"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove export encryption"})
+		return
+	}
+
+	h.Logger.Info("export encryption removed", "org_id", user.OrganizationID, "by", user.ID)
+	c.JSON(http.StatusOK, gin.H{"message": "Export encryption removed successfully"})
+}
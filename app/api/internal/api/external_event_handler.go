@@ -0,0 +1,256 @@
+package api
+
+import (
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// How far ahead upcoming events are looked up when none of the window is given
+const upcomingEventsDays = 30
+
+type ExternalEventHandler struct {
+	ExternalEventStore database.ExternalEventStore
+	Logger             *slog.Logger
+}
+
+func NewExternalEventHandler(externalEventStore database.ExternalEventStore, logger *slog.Logger) *ExternalEventHandler {
+	return &ExternalEventHandler{
+		ExternalEventStore: externalEventStore,
+		Logger:             logger,
+	}
+}
+
+type ExternalEventRequest struct {
+	Name               string    `json:"name" binding:"required,max=255"`
+	Category           string    `json:"category" binding:"omitempty,oneof=concert sports festival road_closure competitor other"`
+	Description        *string   `json:"description"`
+	Location           *string   `json:"location" binding:"omitempty,max=255"`
+	StartTime          time.Time `json:"start_time" binding:"required"`
+	EndTime            time.Time `json:"end_time" binding:"required"`
+	ExpectedAttendance *int      `json:"expected_attendance" binding:"omitempty,min=0"`
+}
+
+// EventMarker flags an event on a time series point, insights only need to know what and when
+type EventMarker struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Category  string    `json:"category"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// EventMarkersBetween returns markers for the events overlapping the [from, to) window
+func EventMarkersBetween(events []database.ExternalEvent, from, to time.Time) []EventMarker {
+	markers := []EventMarker{}
+	for _, event := range events {
+		if !event.StartTime.Before(to) || !event.EndTime.After(from) {
+			continue
+		}
+		markers = append(markers, EventMarker{
+			ID:        event.ID,
+			Name:      event.Name,
+			Category:  event.Category,
+			StartTime: event.StartTime,
+			EndTime:   event.EndTime,
+		})
+	}
+	return markers
+}
+
+func (eh *ExternalEventHandler) eventFromRequest(c *gin.Context) (*database.ExternalEvent, bool) {
+	var req ExternalEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		eh.Logger.Warn("invalid external event request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if !req.StartTime.Before(req.EndTime) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start_time must be before end_time"})
+		return nil, false
+	}
+	if req.Category == "" {
+		req.Category = database.ExternalEventOther
+	}
+
+	return &database.ExternalEvent{
+		Name:               req.Name,
+		Category:           req.Category,
+		Description:        req.Description,
+		Location:           req.Location,
+		StartTime:          req.StartTime,
+		EndTime:            req.EndTime,
+		ExpectedAttendance: req.ExpectedAttendance,
+	}, true
+}
+
+// GetExternalEventsHandler lists the events of the organization. With from and/or to (YYYY-MM-DD) only
+// the events overlapping that window are listed, to defaults to 30 days after from.
+func (eh *ExternalEventHandler) GetExternalEventsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	fromValue, toValue := c.Query("from"), c.Query("to")
+	if fromValue == "" && toValue == "" {
+		events, err := eh.ExternalEventStore.GetEvents(user.OrganizationID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Events retrieved successfully", "data": events})
+		return
+	}
+
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if fromValue != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, fromValue, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+		from = parsed
+	}
+	to := from.AddDate(0, 0, upcomingEventsDays)
+	if toValue != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, toValue, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+		// to is inclusive, the whole day counts
+		to = parsed.AddDate(0, 0, 1)
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	events, err := eh.ExternalEventStore.GetEventsBetween(user.OrganizationID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Events retrieved successfully", "data": events})
+}
+
+// GetExternalEventHandler returns one event of the organization
+func (eh *ExternalEventHandler) GetExternalEventHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	event, err := eh.ExternalEventStore.GetEventByID(user.OrganizationID, eventID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Event retrieved successfully", "data": event})
+}
+
+// CreateExternalEventHandler adds an event to the calendar of the organization
+func (eh *ExternalEventHandler) CreateExternalEventHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can manage events"})
+		return
+	}
+
+	event, ok := eh.eventFromRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := eh.ExternalEventStore.CreateEvent(user.OrganizationID, event); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create event"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Event created successfully", "data": event})
+}
+
+// UpdateExternalEventHandler replaces the details of an event
+func (eh *ExternalEventHandler) UpdateExternalEventHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can manage events"})
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	event, ok := eh.eventFromRequest(c)
+	if !ok {
+		return
+	}
+	event.ID = eventID
+
+	if err := eh.ExternalEventStore.UpdateEvent(user.OrganizationID, event); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Event updated successfully", "data": event})
+}
+
+// DeleteExternalEventHandler removes an event from the calendar
+func (eh *ExternalEventHandler) DeleteExternalEventHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can manage events"})
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	if err := eh.ExternalEventStore.DeleteEvent(user.OrganizationID, eventID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Event deleted successfully"})
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// Days of alerts listed when no period is given
+const varianceAlertDays = 30
+
+type ForecastVarianceHandler struct {
+	ForecastVarianceStore database.ForecastVarianceStore
+	Logger                *slog.Logger
+}
+
+func NewForecastVarianceHandler(forecastVarianceStore database.ForecastVarianceStore, logger *slog.Logger) *ForecastVarianceHandler {
+	return &ForecastVarianceHandler{
+		ForecastVarianceStore: forecastVarianceStore,
+		Logger:                logger,
+	}
+}
+
+// VarianceAlertSummary is how often the alerts of a period were right. An alert is settled once its day
+// is over, and confirmed when the whole day ended on the same side of the forecast.
+type VarianceAlertSummary struct {
+	Alerts           int      `json:"alerts"`
+	Settled          int      `json:"settled"`
+	Confirmed        int      `json:"confirmed"`
+	ConfirmedPercent *float64 `json:"confirmed_percent"`
+}
+
+// SummarizeVarianceAlerts counts the settled and confirmed alerts of the days before today
+func SummarizeVarianceAlerts(alerts []database.ForecastVarianceAlert, today time.Time) VarianceAlertSummary {
+	summary := VarianceAlertSummary{Alerts: len(alerts)}
+	for _, alert := range alerts {
+		if !alert.Date.Before(today) || alert.DayForecastOrders == nil || alert.DayActualOrders == nil {
+			continue
+		}
+		summary.Settled++
+		dayVariance := *alert.DayActualOrders - *alert.DayForecastOrders
+		if (alert.Direction == database.VarianceAbove && dayVariance > 0) || (alert.Direction == database.VarianceBelow && dayVariance < 0) {
+			summary.Confirmed++
+		}
+	}
+	if summary.Settled > 0 {
+		percent := math.Round(float64(summary.Confirmed)/float64(summary.Settled)*1000) / 10
+		summary.ConfirmedPercent = &percent
+	}
+	return summary
+}
+
+// GetVarianceAlertsHandler lists the forecast variance alerts of a period (the last 30 days by default)
+// with how often they were confirmed by the end of their day
+func (fh *ForecastVarianceHandler) GetVarianceAlertsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access forecast variance alerts"})
+		return
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	to := today.AddDate(0, 0, 1)
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+		// to is inclusive, the whole day counts
+		to = parsed.AddDate(0, 0, 1)
+	}
+	from := to.AddDate(0, 0, -varianceAlertDays)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+		from = parsed
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	alerts, err := fh.ForecastVarianceStore.ListAlerts(user.OrganizationID, from, to)
+	if err != nil {
+		fh.Logger.Error("failed to list forecast variance alerts", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve forecast variance alerts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Forecast variance alerts retrieved successfully",
+		"data": gin.H{
+			"from":    from.Format(time.DateOnly),
+			"to":      to.AddDate(0, 0, -1).Format(time.DateOnly),
+			"summary": SummarizeVarianceAlerts(alerts, today),
+			"alerts":  alerts,
+		},
+	})
+}
@@ -0,0 +1,282 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// handoverShiftLayout is the start of the incoming shift of ?shift=
+const handoverShiftLayout = "2006-01-02T15:04"
+
+const maxShiftNoteBody = 2000
+
+// requestInQueue is the status of requests awaiting a decision
+const requestInQueue = "in queue"
+
+// HandoverHandler compiles what the incoming manager needs at the change of shift, and the notes
+// left for the next shift
+type HandoverHandler struct {
+	OrderStore     database.OrderStore
+	ScheduleStore  database.ScheduleStore
+	RequestStore   database.RequestStore
+	ShiftNoteStore database.ShiftNoteStore
+	Logger         *slog.Logger
+}
+
+func NewHandoverHandler(orderStore database.OrderStore, scheduleStore database.ScheduleStore, requestStore database.RequestStore, shiftNoteStore database.ShiftNoteStore, logger *slog.Logger) *HandoverHandler {
+	return &HandoverHandler{
+		OrderStore:     orderStore,
+		ScheduleStore:  scheduleStore,
+		RequestStore:   requestStore,
+		ShiftNoteStore: shiftNoteStore,
+		Logger:         logger,
+	}
+}
+
+// HandoverShift is the incoming shift and the staff scheduled to start it
+type HandoverShift struct {
+	Start time.Time        `json:"start"`
+	Staff []database.Shift `json:"staff"`
+}
+
+// HandoverToday is the business of the day so far, in total and per channel
+type HandoverToday struct {
+	Orders            int                         `json:"orders"`
+	DeliveryOrders    int                         `json:"delivery_orders"`
+	Revenue           float64                     `json:"revenue"`
+	Discount          float64                     `json:"discount"`
+	AverageOrderValue float64                     `json:"average_order_value"`
+	Channels          []database.ChannelBreakdown `json:"channels"`
+}
+
+// HandoverReport is everything the incoming manager needs at the change of shift
+type HandoverReport struct {
+	GeneratedAt     time.Time                       `json:"generated_at"`
+	NextShift       *HandoverShift                  `json:"next_shift"`
+	OpenOrders      []database.Order                `json:"open_orders"`
+	PendingRequests []*database.RequestWithEmployee `json:"pending_requests"`
+	ShiftNotes      []database.ShiftNote            `json:"shift_notes"`
+	LowStockAlerts  []database.ShiftNote            `json:"low_stock_alerts"`
+	Today           HandoverToday                   `json:"today"`
+}
+
+type ShiftNoteRequest struct {
+	Kind   string     `json:"kind"`
+	Body   string     `json:"body" binding:"required"`
+	ItemID *uuid.UUID `json:"item_id"`
+}
+
+// nextShift picks the shifts starting at start, or when start is nil at the earliest start after now
+func nextShift(shifts []database.Shift, start *time.Time, now time.Time) *HandoverShift {
+	var next *HandoverShift
+	for _, shift := range shifts {
+		period, ok := periodOfShift(shift)
+		if !ok {
+			continue
+		}
+		switch {
+		case start != nil && !period.start.Equal(*start):
+			continue
+		case start == nil && period.start.Before(now):
+			continue
+		case next == nil || period.start.Before(next.Start):
+			next = &HandoverShift{Start: period.start, Staff: []database.Shift{shift}}
+		case period.start.Equal(next.Start):
+			next.Staff = append(next.Staff, shift)
+		}
+	}
+	return next
+}
+
+// summarizeToday sums the channels of the day
+func summarizeToday(channels []database.ChannelBreakdown) HandoverToday {
+	today := HandoverToday{Channels: channels}
+	for _, channel := range channels {
+		today.Orders += channel.Orders
+		today.DeliveryOrders += channel.DeliveryOrders
+		today.Revenue += channel.Revenue
+		today.Discount += channel.Discount
+	}
+	if today.Orders > 0 {
+		today.AverageOrderValue = today.Revenue / float64(today.Orders)
+	}
+	return today
+}
+
+// GetHandoverReportHandler compiles the shift handover report: the staff of the incoming shift, the open
+// orders and the numbers of the day so far, the requests awaiting a decision and the unresolved shift notes
+// and low stock alerts. The shift is the one starting at ?shift=YYYY-MM-DDTHH:MM, the next one by default.
+// The sections are read from their stores concurrently.
+func (hh *HandoverHandler) GetHandoverReportHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can read the handover report"})
+		return
+	}
+
+	now := time.Now()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	var shiftStart *time.Time
+	shiftsFrom := dayStart
+	if value := c.Query("shift"); value != "" {
+		start, err := time.ParseInLocation(handoverShiftLayout, value, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid shift, expected the start of the shift as YYYY-MM-DDTHH:MM"})
+			return
+		}
+		shiftStart = &start
+		shiftsFrom = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+	}
+
+	report := HandoverReport{GeneratedAt: now}
+	var shifts []database.Shift
+	var channels []database.ChannelBreakdown
+	var notes []database.ShiftNote
+	sections := []struct {
+		name string
+		load func() error
+	}{
+		{"shifts", func() (err error) {
+			shifts, err = hh.ScheduleStore.GetShiftsFrom(user.OrganizationID, shiftsFrom)
+			return err
+		}},
+		{"open orders", func() (err error) {
+			report.OpenOrders, err = hh.OrderStore.GetOrders(user.OrganizationID, database.OrderFilter{From: &dayStart, Statuses: []string{"incompleted"}})
+			return err
+		}},
+		{"channel breakdown", func() (err error) {
+			channels, err = hh.OrderStore.GetChannelBreakdown(user.OrganizationID, dayStart, now)
+			return err
+		}},
+		{"requests", func() error {
+			requests, err := hh.RequestStore.GetRequestsByOrganization(user.OrganizationID)
+			report.PendingRequests = []*database.RequestWithEmployee{}
+			for _, request := range requests {
+				if request.Status == requestInQueue {
+					report.PendingRequests = append(report.PendingRequests, request)
+				}
+			}
+			return err
+		}},
+		{"shift notes", func() (err error) {
+			notes, err = hh.ShiftNoteStore.GetUnresolvedShiftNotes(user.OrganizationID)
+			return err
+		}},
+	}
+
+	errs := make([]error, len(sections))
+	var wg sync.WaitGroup
+	for i, section := range sections {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = section.load()
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			hh.Logger.Error("failed to compile handover report", "section", sections[i].name, "error", err, "org_id", user.OrganizationID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compile handover report"})
+			return
+		}
+	}
+
+	report.NextShift = nextShift(shifts, shiftStart, now)
+	report.Today = summarizeToday(channels)
+	report.ShiftNotes = []database.ShiftNote{}
+	report.LowStockAlerts = []database.ShiftNote{}
+	for _, note := range notes {
+		if note.Kind == database.ShiftNoteLowStock {
+			report.LowStockAlerts = append(report.LowStockAlerts, note)
+		} else {
+			report.ShiftNotes = append(report.ShiftNotes, note)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Handover report compiled successfully",
+		"data":    report,
+	})
+}
+
+// CreateShiftNoteHandler leaves a note for the next shift. Any member of the organization can leave one,
+// low stock notes optionally name the item running out.
+func (hh *HandoverHandler) CreateShiftNoteHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	var request ShiftNoteRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.Kind == "" {
+		request.Kind = database.ShiftNoteGeneral
+	}
+	if !database.IsShiftNoteKind(request.Kind) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid kind, expected note or low_stock"})
+		return
+	}
+	body := strings.TrimSpace(request.Body)
+	if body == "" || len(body) > maxShiftNoteBody {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "body must be between 1 and 2000 characters"})
+		return
+	}
+
+	note := &database.ShiftNote{Kind: request.Kind, ItemID: request.ItemID, Body: body, CreatedBy: &user.ID, CreatedByName: &user.FullName}
+	if err := hh.ShiftNoteStore.CreateShiftNote(user.OrganizationID, note); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create shift note"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Shift note created successfully",
+		"data":    note,
+	})
+}
+
+// ResolveShiftNoteHandler closes a shift note once dealt with, which leaves the handover report
+func (hh *HandoverHandler) ResolveShiftNoteHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can resolve shift notes"})
+		return
+	}
+	noteID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid note ID"})
+		return
+	}
+
+	if err := hh.ShiftNoteStore.ResolveShiftNote(user.OrganizationID, noteID, user.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Shift note not found or already resolved"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve shift note"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Shift note resolved successfully"})
+}
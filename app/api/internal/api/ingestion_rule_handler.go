@@ -0,0 +1,266 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Most violations listed in an upload result, the rejected rows are all counted
+const maxReportedViolations = 100
+
+// Columns of each imported dataset that rules can check
+var ingestionColumns = map[string][]string{
+	"orders":      {"order_id", "user_id", "create_time", "order_type", "order_status", "total_amount", "discount_amount", "rating", "redemption_code"},
+	"order_items": {"order_id", "item_id", "quantity", "total_price"},
+	"deliveries":  {"order_id", "driver_id", "out_for_delivery_time", "delivered_time", "status", "delivery_latitude", "delivery_longitude"},
+	"items":       {"item_id", "name", "needed_employees", "price"},
+}
+
+type IngestionRuleHandler struct {
+	IngestionRuleStore database.IngestionRuleStore
+	Logger             *slog.Logger
+}
+
+func NewIngestionRuleHandler(ingestionRuleStore database.IngestionRuleStore, logger *slog.Logger) *IngestionRuleHandler {
+	return &IngestionRuleHandler{
+		IngestionRuleStore: ingestionRuleStore,
+		Logger:             logger,
+	}
+}
+
+type CreateIngestionRuleRequest struct {
+	Dataset  string  `json:"dataset" binding:"required,oneof=orders order_items deliveries items"`
+	Field    string  `json:"field" binding:"required"`
+	Operator string  `json:"operator" binding:"required,oneof=required min max one_of"`
+	Value    string  `json:"value" binding:"max=255"`
+	Message  *string `json:"message" binding:"omitempty,max=255"`
+}
+
+// IngestionViolation is a row rejected by an ingestion rule. Line is the line of the row in the CSV
+// file, the header being line 1.
+type IngestionViolation struct {
+	Line    int       `json:"line"`
+	RuleID  uuid.UUID `json:"rule_id"`
+	Field   string    `json:"field"`
+	Message string    `json:"message"`
+}
+
+// ruleMessage is the message reported for a row breaking the rule
+func ruleMessage(rule database.IngestionRule) string {
+	if rule.Message != nil {
+		return *rule.Message
+	}
+	switch rule.Operator {
+	case "required":
+		return fmt.Sprintf("%s is required", rule.Field)
+	case "min":
+		return fmt.Sprintf("%s must be at least %s", rule.Field, rule.Value)
+	case "max":
+		return fmt.Sprintf("%s must be at most %s", rule.Field, rule.Value)
+	default:
+		return fmt.Sprintf("%s must be one of %s", rule.Field, rule.Value)
+	}
+}
+
+// ruleHolds reports whether the value of a row complies with the rule. Empty values only break
+// required rules, the other rules apply to the values given.
+func ruleHolds(rule database.IngestionRule, value string) bool {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return rule.Operator != "required"
+	}
+
+	switch rule.Operator {
+	case "min", "max":
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		limit, _ := strconv.ParseFloat(rule.Value, 64)
+		if rule.Operator == "min" {
+			return number >= limit
+		}
+		return number <= limit
+	case "one_of":
+		for _, allowed := range strings.Split(rule.Value, ",") {
+			if strings.TrimSpace(allowed) == value {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// ingestionValidator rejects the imported rows breaking the rules of the organization for a dataset
+// and keeps the violations for the upload result
+type ingestionValidator struct {
+	rules      []database.IngestionRule
+	Rejected   int
+	Violations []IngestionViolation
+}
+
+func newIngestionValidator(store database.IngestionRuleStore, orgID uuid.UUID, dataset string) (*ingestionValidator, error) {
+	rules, err := store.GetRulesForDataset(orgID, dataset)
+	if err != nil {
+		return nil, err
+	}
+	return &ingestionValidator{rules: rules, Violations: []IngestionViolation{}}, nil
+}
+
+// Check reports whether the i-th row of the file complies with every rule
+func (v *ingestionValidator) Check(i int, row map[string]string) bool {
+	broken := v.BrokenRules(row)
+	for _, rule := range broken {
+		if len(v.Violations) < maxReportedViolations {
+			v.Violations = append(v.Violations, IngestionViolation{Line: i + 2, RuleID: rule.ID, Field: rule.Field, Message: ruleMessage(rule)})
+		}
+	}
+	if len(broken) > 0 {
+		v.Rejected++
+	}
+	return len(broken) == 0
+}
+
+// BrokenRules returns the rules a row breaks, without counting it as rejected
+func (v *ingestionValidator) BrokenRules(row map[string]string) []database.IngestionRule {
+	var broken []database.IngestionRule
+	for _, rule := range v.rules {
+		if !ruleHolds(rule, row[rule.Field]) {
+			broken = append(broken, rule)
+		}
+	}
+	return broken
+}
+
+// GetIngestionRulesHandler lists the validation rules applied to the imports of the organization
+func (ih *IngestionRuleHandler) GetIngestionRulesHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access rules"})
+		return
+	}
+
+	rules, err := ih.IngestionRuleStore.GetRules(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve validation rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Validation rules retrieved successfully", "data": rules})
+}
+
+// CreateIngestionRuleHandler adds a validation rule to the imports of a dataset
+func (ih *IngestionRuleHandler) CreateIngestionRuleHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change validation rules"})
+		return
+	}
+
+	var request CreateIngestionRuleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	field := strings.TrimSpace(request.Field)
+	known := false
+	for _, column := range ingestionColumns[request.Dataset] {
+		if column == field {
+			known = true
+			break
+		}
+	}
+	if !known {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s imports have no %q column", request.Dataset, field)})
+		return
+	}
+
+	value := strings.TrimSpace(request.Value)
+	switch request.Operator {
+	case "required":
+		value = ""
+	case "min", "max":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": request.Operator + " rules need a numeric value"})
+			return
+		}
+	case "one_of":
+		if strings.Trim(value, ", ") == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "one_of rules need a comma separated list of values"})
+			return
+		}
+	}
+
+	if request.Message != nil {
+		message := strings.TrimSpace(*request.Message)
+		request.Message = &message
+		if message == "" {
+			request.Message = nil
+		}
+	}
+
+	createdBy := user.ID
+	rule := &database.IngestionRule{
+		Dataset:   request.Dataset,
+		Field:     field,
+		Operator:  request.Operator,
+		Value:     value,
+		Message:   request.Message,
+		CreatedBy: &createdBy,
+	}
+	if err := ih.IngestionRuleStore.CreateRule(user.OrganizationID, rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create validation rule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Validation rule created successfully", "data": rule})
+}
+
+// DeleteIngestionRuleHandler removes a validation rule, later imports are no longer checked against it
+func (ih *IngestionRuleHandler) DeleteIngestionRuleHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change validation rules"})
+		return
+	}
+
+	ruleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	if err := ih.IngestionRuleStore.DeleteRule(user.OrganizationID, ruleID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Validation rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete validation rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Validation rule deleted successfully"})
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultDrillDownItems = 20
+	maxDrillDownItems     = 100
+)
+
+// deliveryPending is the status of delivery orders that have not left yet
+const deliveryPending = "pending"
+
+// drillDownUser returns the user when they may open the drill-downs, which show the order rows of the
+// organization to admins and managers
+func drillDownUser(c *gin.Context) *database.User {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return nil
+	}
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can open insight details"})
+		return nil
+	}
+	return user
+}
+
+// dateRangeData echoes the inclusive period of a drill-down, null when unbounded
+func dateRangeData(from, to *time.Time) gin.H {
+	data := gin.H{"from": nil, "to": nil}
+	if from != nil {
+		data["from"] = from.Format(time.DateOnly)
+	}
+	if to != nil {
+		data["to"] = to.AddDate(0, 0, -1).Format(time.DateOnly)
+	}
+	return data
+}
+
+// GetBusiestHourDrillDownHandler returns the orders of every hour of the day behind the busiest hour
+// insight, over all orders unless from and to (YYYY-MM-DD, inclusive) are given
+func (ih *InsightHandler) GetBusiestHourDrillDownHandler(c *gin.Context) {
+	user := drillDownUser(c)
+	if user == nil {
+		return
+	}
+	from, to, ok := queryDateRange(c)
+	if !ok {
+		return
+	}
+
+	hours, err := ih.InsightsStore.GetHourlyOrders(user.OrganizationID, from, to)
+	if err != nil {
+		ih.Logger.Error("failed to get hourly orders", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve insight details"})
+		return
+	}
+
+	// ties go to the earliest hour, null without orders
+	var busiest *int
+	for i, hour := range hours {
+		if hour.Orders > 0 && (busiest == nil || hour.Orders > hours[*busiest].Orders) {
+			busiest = &hours[i].Hour
+		}
+	}
+
+	data := dateRangeData(from, to)
+	data["busiest_hour"] = busiest
+	data["hours"] = hours
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Busiest hour details retrieved successfully",
+		"data":    data,
+	})
+}
+
+// GetMostSellingItemsDrillDownHandler returns the limit (default 20) most ordered items behind the most
+// selling items insight, over all orders unless from and to (YYYY-MM-DD, inclusive) are given
+func (ih *InsightHandler) GetMostSellingItemsDrillDownHandler(c *gin.Context) {
+	user := drillDownUser(c)
+	if user == nil {
+		return
+	}
+	from, to, ok := queryDateRange(c)
+	if !ok {
+		return
+	}
+	limit := defaultDrillDownItems
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxDrillDownItems {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxDrillDownItems)})
+			return
+		}
+		limit = parsed
+	}
+
+	items, err := ih.InsightsStore.GetTopItems(user.OrganizationID, from, to, limit)
+	if err != nil {
+		ih.Logger.Error("failed to get top items", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve insight details"})
+		return
+	}
+
+	data := dateRangeData(from, to)
+	data["items"] = items
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Most selling items details retrieved successfully",
+		"data":    data,
+	})
+}
+
+// GetDeliveriesTodayDrillDownHandler returns the delivery orders of today behind the deliveries today
+// insight and how many are pending, out for delivery, delivered or not delivered
+func (ih *InsightHandler) GetDeliveriesTodayDrillDownHandler(c *gin.Context) {
+	user := drillDownUser(c)
+	if user == nil {
+		return
+	}
+
+	orders, err := ih.InsightsStore.GetDeliveryOrdersToday(user.OrganizationID)
+	if err != nil {
+		ih.Logger.Error("failed to get deliveries today", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve insight details"})
+		return
+	}
+
+	byStatus := map[string]int{deliveryPending: 0, "out for delivery": 0, "delivered": 0, "not delivered": 0}
+	for _, order := range orders {
+		status := deliveryPending
+		if order.DeliveryStatus != nil {
+			status = *order.DeliveryStatus
+		}
+		byStatus[status]++
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Deliveries today details retrieved successfully",
+		"data": gin.H{
+			"total":     len(orders),
+			"by_status": byStatus,
+			"orders":    orders,
+		},
+	})
+}
@@ -3,15 +3,19 @@ package api
 import (
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/clockwise/clockwise/backend/internal/database"
 	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
 type InsightHandler struct {
 	InsightsStore database.InsightStore
 	Logger        *slog.Logger
+	// KPIs adds the last 7 days against the KPI targets to the insights of admins and managers, when set
+	KPIs *service.KPITracker
 }
 
 
@@ -53,8 +57,40 @@ func (ih *InsightHandler) GetInsightsHandler(c *gin.Context) {
 		return
 	}
 
+	if user.UserRole == "admin" || user.UserRole == "manager" {
+		insights = append(insights, ih.kpiInsights(user)...)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Insights retrieved successfully",
 		"data":    insights,
 	})
 }
+
+// kpiInsights compares the last 7 days with each KPI target, none when the organization set no targets or
+// they could not be measured
+func (ih *InsightHandler) kpiInsights(user *database.User) []database.Insight {
+	if ih.KPIs == nil {
+		return nil
+	}
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.Local)
+	progress, err := ih.KPIs.Progress(user.OrganizationID, to.AddDate(0, 0, -kpiProgressDays), to)
+	if err != nil {
+		ih.Logger.Error("failed to measure KPI progress for insights", "error", err, "org_id", user.OrganizationID)
+		return nil
+	}
+	if progress == nil {
+		return nil
+	}
+
+	insights := make([]database.Insight, 0, len(progress.Targets))
+	for _, result := range progress.Targets {
+		insights = append(insights, database.Insight{
+			Title:     service.KPILabel(result.KPI) + " vs Target (Last 7 Days)",
+			Statistic: service.FormatKPIResult(result),
+			DrillDown: database.DrillDownKPIProgress,
+		})
+	}
+	return insights
+}
@@ -0,0 +1,292 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Most windows an item can have, enough for a season split around holidays plus daily hours
+const maxAvailabilityWindows = 20
+
+type ItemAvailabilityHandler struct {
+	ItemAvailabilityStore database.ItemAvailabilityStore
+	OrderStore            database.OrderStore
+	Logger                *slog.Logger
+}
+
+func NewItemAvailabilityHandler(itemAvailabilityStore database.ItemAvailabilityStore, orderStore database.OrderStore, logger *slog.Logger) *ItemAvailabilityHandler {
+	return &ItemAvailabilityHandler{
+		ItemAvailabilityStore: itemAvailabilityStore,
+		OrderStore:            orderStore,
+		Logger:                logger,
+	}
+}
+
+type AvailabilityWindowRequest struct {
+	StartDate *string `json:"start_date"`
+	EndDate   *string `json:"end_date"`
+	StartTime *string `json:"start_time"`
+	EndTime   *string `json:"end_time"`
+}
+
+type SetItemAvailabilityRequest struct {
+	Windows []AvailabilityWindowRequest `json:"windows"`
+}
+
+type OrderValidationItem struct {
+	ItemID   uuid.UUID `json:"item_id" binding:"required"`
+	Quantity int       `json:"quantity" binding:"required,min=1"`
+}
+
+type ValidateOrderRequest struct {
+	Items []OrderValidationItem `json:"items" binding:"required,min=1,dive"`
+	// At is when the order is placed, now unless set
+	At *time.Time `json:"at"`
+}
+
+func windowCoversDate(window database.ItemAvailabilityWindow, date string) bool {
+	if window.StartDate != nil && date < *window.StartDate {
+		return false
+	}
+	if window.EndDate != nil && date > *window.EndDate {
+		return false
+	}
+	return true
+}
+
+func windowCoversTime(window database.ItemAvailabilityWindow, at time.Time) bool {
+	if !windowCoversDate(window, at.Format(time.DateOnly)) {
+		return false
+	}
+	clock := at.Format("15:04")
+	if window.StartTime != nil && clock < *window.StartTime {
+		return false
+	}
+	if window.EndTime != nil && clock >= *window.EndTime {
+		return false
+	}
+	return true
+}
+
+func unavailableItemsWhere(windows []database.ItemAvailabilityWindow, covers func(database.ItemAvailabilityWindow) bool) map[uuid.UUID]bool {
+	available := map[uuid.UUID]bool{}
+	for _, window := range windows {
+		available[window.ItemID] = available[window.ItemID] || covers(window)
+	}
+	unavailable := map[uuid.UUID]bool{}
+	for itemID, ok := range available {
+		if !ok {
+			unavailable[itemID] = true
+		}
+	}
+	return unavailable
+}
+
+// UnavailableItemsOn returns the items that cannot be ordered at any time of the given day.
+// Items without windows are always available.
+func UnavailableItemsOn(windows []database.ItemAvailabilityWindow, date time.Time) map[uuid.UUID]bool {
+	day := date.Format(time.DateOnly)
+	return unavailableItemsWhere(windows, func(window database.ItemAvailabilityWindow) bool {
+		return windowCoversDate(window, day)
+	})
+}
+
+// UnavailableItemsAt returns the items that cannot be ordered at the given time.
+// Items without windows are always available.
+func UnavailableItemsAt(windows []database.ItemAvailabilityWindow, at time.Time) map[uuid.UUID]bool {
+	return unavailableItemsWhere(windows, func(window database.ItemAvailabilityWindow) bool {
+		return windowCoversTime(window, at)
+	})
+}
+
+func validateAvailabilityWindow(window AvailabilityWindowRequest) error {
+	for _, date := range []*string{window.StartDate, window.EndDate} {
+		if date == nil {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, *date); err != nil {
+			return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", *date)
+		}
+	}
+	if window.StartDate != nil && window.EndDate != nil && *window.StartDate > *window.EndDate {
+		return fmt.Errorf("start_date must not be after end_date")
+	}
+
+	if (window.StartTime == nil) != (window.EndTime == nil) {
+		return fmt.Errorf("start_time and end_time must be set together")
+	}
+	if window.StartTime == nil {
+		if window.StartDate == nil && window.EndDate == nil {
+			return fmt.Errorf("a window needs a date or a time bound")
+		}
+		return nil
+	}
+	for _, clock := range []string{*window.StartTime, *window.EndTime} {
+		if _, err := time.Parse("15:04", clock); err != nil {
+			return fmt.Errorf("invalid time %q, expected HH:MM", clock)
+		}
+	}
+	if *window.StartTime >= *window.EndTime {
+		return fmt.Errorf("start_time must be before end_time")
+	}
+	return nil
+}
+
+// GetItemAvailabilityHandler lists the availability windows of the menu and the items that cannot be ordered now
+func (ih *ItemAvailabilityHandler) GetItemAvailabilityHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	windows, err := ih.ItemAvailabilityStore.GetAvailabilityWindows(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve item availability"})
+		return
+	}
+
+	unavailableNow := []uuid.UUID{}
+	for itemID := range UnavailableItemsAt(windows, time.Now()) {
+		unavailableNow = append(unavailableNow, itemID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Item availability retrieved successfully",
+		"data": gin.H{
+			"windows":         windows,
+			"unavailable_now": unavailableNow,
+		},
+	})
+}
+
+// SetItemAvailabilityHandler replaces the availability windows of an item, an empty list makes it always available
+func (ih *ItemAvailabilityHandler) SetItemAvailabilityHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can manage the menu"})
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("item"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+		return
+	}
+
+	var request SetItemAvailabilityRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if len(request.Windows) > maxAvailabilityWindows {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("An item can have at most %d windows", maxAvailabilityWindows)})
+		return
+	}
+
+	windows := make([]database.ItemAvailabilityWindow, 0, len(request.Windows))
+	for _, window := range request.Windows {
+		if err := validateAvailabilityWindow(window); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		windows = append(windows, database.ItemAvailabilityWindow{
+			ItemID:    itemID,
+			StartDate: window.StartDate,
+			EndDate:   window.EndDate,
+			StartTime: window.StartTime,
+			EndTime:   window.EndTime,
+		})
+	}
+
+	if err := ih.ItemAvailabilityStore.SetItemAvailability(user.OrganizationID, itemID, windows); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update item availability"})
+		return
+	}
+
+	ih.Logger.Info("item availability updated", "org_id", user.OrganizationID, "item_id", itemID, "windows", len(windows))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Item availability updated successfully",
+		"data":    windows,
+	})
+}
+
+// ValidateOrderHandler checks that every item of an order being placed exists and can be ordered at that time,
+// discontinued items never can
+func (ih *ItemAvailabilityHandler) ValidateOrderHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	var request ValidateOrderRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	at := time.Now()
+	if request.At != nil {
+		at = request.At.In(time.Local)
+	}
+
+	items, err := ih.OrderStore.GetAllItems(user.OrganizationID)
+	if err != nil {
+		ih.Logger.Error("failed to get items", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate order"})
+		return
+	}
+	windows, err := ih.ItemAvailabilityStore.GetAvailabilityWindows(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate order"})
+		return
+	}
+
+	known := make(map[uuid.UUID]bool, len(items))
+	for _, item := range items {
+		known[item.ItemID] = true
+	}
+	unavailable := UnavailableItemsAt(windows, at)
+	for _, item := range items {
+		if !item.IsActive {
+			unavailable[item.ItemID] = true
+		}
+	}
+
+	unknownItems := []uuid.UUID{}
+	unavailableItems := []uuid.UUID{}
+	for _, item := range request.Items {
+		switch {
+		case !known[item.ItemID]:
+			unknownItems = append(unknownItems, item.ItemID)
+		case unavailable[item.ItemID]:
+			unavailableItems = append(unavailableItems, item.ItemID)
+		}
+	}
+
+	if len(unknownItems) > 0 || len(unavailableItems) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":             "Order contains items that cannot be ordered",
+			"unknown_items":     unknownItems,
+			"unavailable_items": unavailableItems,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Order is valid"})
+}
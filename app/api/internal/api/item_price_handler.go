@@ -0,0 +1,206 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Price adjustments of a bulk price update
+const (
+	PriceAdjustPercentage = "percentage" // value is a percent of the current price, e.g. 5 for +5%
+	PriceAdjustFixed      = "fixed"      // value is an amount added to the current price
+)
+
+type ItemPriceHandler struct {
+	ItemPriceStore database.ItemPriceStore
+	OrderStore     database.OrderStore
+	Logger         *slog.Logger
+}
+
+func NewItemPriceHandler(itemPriceStore database.ItemPriceStore, orderStore database.OrderStore, logger *slog.Logger) *ItemPriceHandler {
+	return &ItemPriceHandler{
+		ItemPriceStore: itemPriceStore,
+		OrderStore:     orderStore,
+		Logger:         logger,
+	}
+}
+
+// BulkPriceUpdateRequest adjusts the price of every item, or of ItemIDs only. New prices are rounded
+// to the nearest multiple of RoundTo, cents by default.
+type BulkPriceUpdateRequest struct {
+	AdjustmentType string      `json:"adjustment_type" binding:"required,oneof=percentage fixed"`
+	Value          float64     `json:"value"`
+	ItemIDs        []uuid.UUID `json:"item_ids"`
+	RoundTo        *float64    `json:"round_to"`
+	Reason         *string     `json:"reason" binding:"omitempty,max=200"`
+}
+
+// ComputePriceChanges returns the price changes of the request for the items, leaving out the items
+// whose price does not change
+func ComputePriceChanges(items []database.Item, req BulkPriceUpdateRequest) ([]database.ItemPriceChange, error) {
+	if req.Value == 0 {
+		return nil, errors.New("value must not be 0")
+	}
+	if req.AdjustmentType == PriceAdjustPercentage && req.Value <= -100 {
+		return nil, errors.New("a percentage adjustment must be above -100")
+	}
+	roundTo := 0.01
+	if req.RoundTo != nil {
+		if *req.RoundTo < 0.01 || *req.RoundTo > 100 {
+			return nil, errors.New("round_to must be between 0.01 and 100")
+		}
+		roundTo = *req.RoundTo
+	}
+
+	selected := items
+	if len(req.ItemIDs) > 0 {
+		selected = []database.Item{}
+		for _, id := range req.ItemIDs {
+			index := slices.IndexFunc(items, func(item database.Item) bool { return item.ItemID == id })
+			if index < 0 {
+				return nil, fmt.Errorf("item %s not found", id)
+			}
+			selected = append(selected, items[index])
+		}
+	}
+
+	changes := []database.ItemPriceChange{}
+	for _, item := range selected {
+		if item.Price == nil {
+			continue
+		}
+		price := *item.Price + req.Value
+		if req.AdjustmentType == PriceAdjustPercentage {
+			price = *item.Price * (1 + req.Value/100)
+		}
+		price = math.Round(math.Round(price/roundTo)*roundTo*100) / 100
+		if price < 0 {
+			return nil, fmt.Errorf("%s would get a negative price", item.Name)
+		}
+		if price == *item.Price {
+			continue
+		}
+		changes = append(changes, database.ItemPriceChange{ItemID: item.ItemID, ItemName: item.Name, OldPrice: *item.Price, NewPrice: price, Reason: req.Reason})
+	}
+	return changes, nil
+}
+
+// BulkPriceUpdateHandler changes the prices of the menu by a percentage or a fixed amount. It is a dry
+// run unless dry_run=false is given: the response then only previews the new prices. Admins and
+// managers can preview, only admins can apply.
+func (ph *ItemPriceHandler) BulkPriceUpdateHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access items"})
+		return
+	}
+
+	dryRun := true
+	if value := c.Query("dry_run"); value != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be true or false"})
+			return
+		}
+	}
+	if !dryRun && user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change prices"})
+		return
+	}
+
+	var req BulkPriceUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Reason != nil {
+		reason := strings.TrimSpace(*req.Reason)
+		req.Reason = &reason
+		if reason == "" {
+			req.Reason = nil
+		}
+	}
+
+	items, err := ph.OrderStore.GetAllItems(user.OrganizationID)
+	if err != nil {
+		ph.Logger.Error("failed to get all items", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve items"})
+		return
+	}
+	changes, err := ComputePriceChanges(items, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if dryRun {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Dry run, no price was changed. Repeat with dry_run=false to apply the new prices",
+			"data":    gin.H{"dry_run": true, "count": len(changes), "changes": changes},
+		})
+		return
+	}
+
+	for i := range changes {
+		changes[i].ChangedBy = &user.ID
+	}
+	if len(changes) > 0 {
+		if err := ph.ItemPriceStore.ApplyPriceChanges(user.OrganizationID, changes); err != nil {
+			if errors.Is(err, database.ErrItemPriceChanged) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Prices changed while updating, nothing was changed. Please try again"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update prices"})
+			return
+		}
+	}
+
+	ph.Logger.Info("bulk price update applied", "org_id", user.OrganizationID, "user_id", user.ID, "count", len(changes))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Prices updated successfully",
+		"data":    gin.H{"dry_run": false, "count": len(changes), "changes": changes},
+	})
+}
+
+// GetPriceHistoryHandler lists the price changes of an item, newest first
+func (ph *ItemPriceHandler) GetPriceHistoryHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access items"})
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("item"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+		return
+	}
+
+	history, err := ph.ItemPriceStore.GetPriceHistory(user.OrganizationID, itemID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve price history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Price history retrieved successfully", "data": history})
+}
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateItemRequest adds one item to the menu, as a row of the items CSV would
+type CreateItemRequest struct {
+	Name            string   `json:"name" binding:"required,max=50"`
+	NeededEmployees *int     `json:"needed_employees" binding:"required,min=0"`
+	Price           *float64 `json:"price" binding:"required,gte=0"`
+}
+
+// UpdateItemRequest holds the fields of an item to change, the others are left as they are. Setting
+// is_active to true puts a discontinued item back on the menu.
+type UpdateItemRequest struct {
+	Name            *string  `json:"name" binding:"omitempty,min=1,max=50"`
+	NeededEmployees *int     `json:"needed_employees" binding:"omitempty,min=0"`
+	Price           *float64 `json:"price" binding:"omitempty,gte=0"`
+	IsActive        *bool    `json:"is_active"`
+}
+
+// CreateItemHandler adds an item to the menu, checked against the validation rules of the items imports
+func (oh *OrderHandler) CreateItemHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can create items"})
+		return
+	}
+
+	var req CreateItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item := &database.Item{
+		ItemID:                      uuid.New(),
+		Name:                        strings.TrimSpace(req.Name),
+		NeededNumEmployeesToPrepare: req.NeededEmployees,
+		Price:                       req.Price,
+		IsActive:                    true,
+	}
+	if item.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must not be blank"})
+		return
+	}
+	if !oh.checkItemRules(c, user.OrganizationID, item) {
+		return
+	}
+
+	if err := oh.OrderStore.StoreItems(user.OrganizationID, item); err != nil {
+		if errors.Is(err, database.ErrItemExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": "An item with this name already exists"})
+			return
+		}
+		oh.Logger.Error("failed to create item", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create item"})
+		return
+	}
+
+	oh.Logger.Info("item created", "org_id", user.OrganizationID, "item_id", item.ItemID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Item created successfully",
+		"data":    item,
+	})
+}
+
+// UpdateItemHandler changes the name, preparation staff, price or active flag of an item. A new price is
+// recorded in the price history of the item like a bulk price update.
+func (oh *OrderHandler) UpdateItemHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can update items"})
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("item"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+		return
+	}
+
+	var req UpdateItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item, err := oh.OrderStore.GetItem(user.OrganizationID, itemID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve item"})
+		return
+	}
+
+	if req.Name != nil {
+		item.Name = strings.TrimSpace(*req.Name)
+		if item.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "name must not be blank"})
+			return
+		}
+	}
+	if req.NeededEmployees != nil {
+		item.NeededNumEmployeesToPrepare = req.NeededEmployees
+	}
+	if req.Price != nil {
+		item.Price = req.Price
+	}
+	if req.IsActive != nil {
+		item.IsActive = *req.IsActive
+	}
+	if !oh.checkItemRules(c, user.OrganizationID, item) {
+		return
+	}
+
+	if err := oh.OrderStore.UpdateItem(user.OrganizationID, item, &user.ID); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		case errors.Is(err, database.ErrItemExists):
+			c.JSON(http.StatusConflict, gin.H{"error": "An item with this name already exists"})
+		default:
+			oh.Logger.Error("failed to update item", "error", err, "item_id", itemID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update item"})
+		}
+		return
+	}
+
+	oh.Logger.Info("item updated", "org_id", user.OrganizationID, "item_id", itemID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Item updated successfully",
+		"data":    item,
+	})
+}
+
+// DeleteItemHandler discontinues an item. It is kept with is_active false so the orders it was sold in
+// still report it, and PATCH can put it back on the menu.
+func (oh *OrderHandler) DeleteItemHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can delete items"})
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("item"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+		return
+	}
+
+	if err := oh.OrderStore.DeactivateItem(user.OrganizationID, itemID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+			return
+		}
+		oh.Logger.Error("failed to deactivate item", "error", err, "item_id", itemID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete item"})
+		return
+	}
+
+	oh.Logger.Info("item deactivated", "org_id", user.OrganizationID, "item_id", itemID)
+	c.JSON(http.StatusOK, gin.H{"message": "Item deleted successfully"})
+}
+
+// checkItemRules checks the item as a row of the items import, responding with the broken rules
+func (oh *OrderHandler) checkItemRules(c *gin.Context, orgID uuid.UUID, item *database.Item) bool {
+	validator, err := newIngestionValidator(oh.IngestionRuleStore, orgID, "items")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve validation rules"})
+		return false
+	}
+
+	neededEmployees := ""
+	if item.NeededNumEmployeesToPrepare != nil {
+		neededEmployees = strconv.Itoa(*item.NeededNumEmployeesToPrepare)
+	}
+	broken := validator.BrokenRules(map[string]string{
+		"item_id":          item.ItemID.String(),
+		"name":             item.Name,
+		"needed_employees": neededEmployees,
+		"price":            formatOptional(item.Price),
+	})
+	if len(broken) > 0 {
+		messages := make([]string, len(broken))
+		for i, rule := range broken {
+			messages[i] = ruleMessage(rule)
+		}
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "The item breaks the validation rules of the organization", "details": messages})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,419 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Most openings a single posting can have
+const maxPostingOpenings = 50
+
+// Order of the applicant pipeline, hired and rejected end it
+var applicantStages = []string{
+	database.ApplicantStageApplied,
+	database.ApplicantStageScreening,
+	database.ApplicantStageInterview,
+	database.ApplicantStageTrial,
+	database.ApplicantStageOffer,
+	database.ApplicantStageHired,
+	database.ApplicantStageRejected,
+}
+
+type JobPostingHandler struct {
+	JobPostingStore database.JobPostingStore
+	RolesStore      database.RolesStore
+	Logger          *slog.Logger
+}
+
+func NewJobPostingHandler(jobPostingStore database.JobPostingStore, rolesStore database.RolesStore, logger *slog.Logger) *JobPostingHandler {
+	return &JobPostingHandler{
+		JobPostingStore: jobPostingStore,
+		RolesStore:      rolesStore,
+		Logger:          logger,
+	}
+}
+
+// HiringRecommendation is one entry of hiring_recommendations in the schedule management insights
+type HiringRecommendation struct {
+	Role             string `json:"role"`
+	RecommendedHires int    `json:"recommended_hires"`
+	Reason           string `json:"reason"`
+	Priority         string `json:"priority"`
+}
+
+// CreateJobPostingRequest creates a posting by hand, or from a hiring recommendation which fills in
+// the role, openings and reason that are not given
+type CreateJobPostingRequest struct {
+	Role           string                `json:"role" binding:"omitempty,max=50"`
+	Title          string                `json:"title" binding:"omitempty,max=255"`
+	Description    *string               `json:"description"`
+	Openings       int                   `json:"openings" binding:"omitempty,min=1"`
+	Recommendation *HiringRecommendation `json:"recommendation"`
+}
+
+type SetPostingStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=open closed"`
+}
+
+type SetApplicantStageRequest struct {
+	Stage string `json:"stage" binding:"required"`
+}
+
+type JobApplicationRequest struct {
+	FullName string  `json:"full_name" binding:"required,max=255"`
+	Email    string  `json:"email" binding:"required,email,max=255"`
+	Phone    *string `json:"phone" binding:"omitempty,max=50"`
+	Message  *string `json:"message" binding:"omitempty,max=5000"`
+}
+
+// PublicJobPosting is what applicants see of a posting
+type PublicJobPosting struct {
+	ID          uuid.UUID `json:"id"`
+	Role        string    `json:"role"`
+	Title       string    `json:"title"`
+	Description *string   `json:"description,omitempty"`
+	Openings    int       `json:"openings"`
+	PostedAt    time.Time `json:"posted_at"`
+}
+
+// PostingFromRequest builds the posting described by a request, taking what is missing from its
+// hiring recommendation. The title defaults to the role.
+func PostingFromRequest(request CreateJobPostingRequest) (database.JobPosting, error) {
+	posting := database.JobPosting{
+		Role:        strings.TrimSpace(request.Role),
+		Title:       strings.TrimSpace(request.Title),
+		Description: request.Description,
+		Openings:    request.Openings,
+		Status:      database.JobPostingOpen,
+		Source:      database.JobPostingSourceManual,
+	}
+
+	if rec := request.Recommendation; rec != nil {
+		posting.Source = database.JobPostingSourceRecommendation
+		if posting.Role == "" {
+			posting.Role = strings.TrimSpace(rec.Role)
+		}
+		if posting.Openings == 0 {
+			posting.Openings = rec.RecommendedHires
+		}
+		if rec.Reason != "" {
+			reason := rec.Reason
+			posting.RecommendationReason = &reason
+		}
+	}
+
+	if posting.Role == "" {
+		return posting, fmt.Errorf("role is required")
+	}
+	if posting.Openings == 0 {
+		posting.Openings = 1
+	}
+	if posting.Openings < 1 || posting.Openings > maxPostingOpenings {
+		return posting, fmt.Errorf("openings must be between 1 and %d", maxPostingOpenings)
+	}
+	if posting.Title == "" {
+		posting.Title = strings.ToUpper(posting.Role[:1]) + strings.ReplaceAll(posting.Role[1:], "_", " ")
+	}
+	return posting, nil
+}
+
+// CanMoveApplicant reports whether an applicant can go from one pipeline stage to another.
+// Any open stage can move to any other stage, hired and rejected applicants stay where they are.
+func CanMoveApplicant(from, to string) bool {
+	if from == to || from == database.ApplicantStageHired || from == database.ApplicantStageRejected {
+		return false
+	}
+	for _, stage := range applicantStages {
+		if stage == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (jh *JobPostingHandler) roleExists(orgID uuid.UUID, role string) (bool, error) {
+	if role == "admin" || role == "manager" || role == "employee" {
+		return true, nil
+	}
+	existingRole, err := jh.RolesStore.GetRoleByName(orgID, role)
+	if err != nil {
+		return false, err
+	}
+	return existingRole != nil, nil
+}
+
+// CreateJobPostingHandler opens a posting, optionally from a hiring recommendation of the schedule insights
+func (jh *JobPostingHandler) CreateJobPostingHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can manage job postings"})
+		return
+	}
+
+	var request CreateJobPostingRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	posting, err := PostingFromRequest(request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	exists, err := jh.roleExists(user.OrganizationID, posting.Role)
+	if err != nil {
+		jh.Logger.Error("failed to check posting role", "error", err, "role", posting.Role)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate role"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown role: " + posting.Role})
+		return
+	}
+
+	createdBy := user.ID
+	posting.OrganizationID = user.OrganizationID
+	posting.CreatedBy = &createdBy
+	if err := jh.JobPostingStore.CreatePosting(&posting); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create job posting"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Job posting created successfully", "data": posting})
+}
+
+// GetJobPostingsHandler lists the postings of the organization with their number of applicants
+func (jh *JobPostingHandler) GetJobPostingsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can manage job postings"})
+		return
+	}
+
+	postings, err := jh.JobPostingStore.GetPostings(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve job postings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Job postings retrieved successfully", "data": postings})
+}
+
+// SetJobPostingStatusHandler opens or closes a posting, closed postings stop taking applications
+func (jh *JobPostingHandler) SetJobPostingStatusHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can manage job postings"})
+		return
+	}
+
+	postingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid posting ID"})
+		return
+	}
+
+	var request SetPostingStatusRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := jh.JobPostingStore.SetPostingStatus(user.OrganizationID, postingID, request.Status); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job posting not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update job posting"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Job posting updated successfully"})
+}
+
+// GetApplicantsHandler lists the applicants of a posting
+func (jh *JobPostingHandler) GetApplicantsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can manage job postings"})
+		return
+	}
+
+	postingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid posting ID"})
+		return
+	}
+
+	if _, err := jh.JobPostingStore.GetPostingByID(user.OrganizationID, postingID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job posting not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve applicants"})
+		return
+	}
+
+	applicants, err := jh.JobPostingStore.GetApplicants(user.OrganizationID, postingID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve applicants"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Applicants retrieved successfully", "data": applicants})
+}
+
+// SetApplicantStageHandler moves an applicant through the hiring pipeline
+func (jh *JobPostingHandler) SetApplicantStageHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can manage job postings"})
+		return
+	}
+
+	applicantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid applicant ID"})
+		return
+	}
+
+	var request SetApplicantStageRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	applicant, err := jh.JobPostingStore.GetApplicantByID(user.OrganizationID, applicantID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Applicant not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update applicant"})
+		return
+	}
+
+	if !CanMoveApplicant(applicant.Stage, request.Stage) {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Applicant cannot move from %s to %s", applicant.Stage, request.Stage)})
+		return
+	}
+
+	if err := jh.JobPostingStore.SetApplicantStage(user.OrganizationID, applicantID, request.Stage); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Applicant not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update applicant"})
+		return
+	}
+
+	jh.Logger.Info("applicant moved", "id", applicantID, "from", applicant.Stage, "to", request.Stage, "by", user.ID)
+	applicant.Stage = request.Stage
+	c.JSON(http.StatusOK, gin.H{"message": "Applicant updated successfully", "data": applicant})
+}
+
+// GetPublicJobPostingsHandler lists the open postings of an organization, no login needed
+func (jh *JobPostingHandler) GetPublicJobPostingsHandler(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("org"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	postings, err := jh.JobPostingStore.GetOpenPostings(orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve job postings"})
+		return
+	}
+
+	public := make([]PublicJobPosting, 0, len(postings))
+	for _, posting := range postings {
+		public = append(public, PublicJobPosting{
+			ID:          posting.ID,
+			Role:        posting.Role,
+			Title:       posting.Title,
+			Description: posting.Description,
+			Openings:    posting.Openings,
+			PostedAt:    posting.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Job postings retrieved successfully", "data": public})
+}
+
+// ApplyToJobPostingHandler takes an application to an open posting, no login needed
+func (jh *JobPostingHandler) ApplyToJobPostingHandler(c *gin.Context) {
+	orgID, err := uuid.Parse(c.Param("org"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+	postingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid posting ID"})
+		return
+	}
+
+	var request JobApplicationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	posting, err := jh.JobPostingStore.GetPostingByID(orgID, postingID)
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit application"})
+		return
+	}
+	// Closed postings look the same as missing ones to the public
+	if posting == nil || posting.Status != database.JobPostingOpen {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job posting not found"})
+		return
+	}
+
+	applicant := &database.JobApplicant{
+		PostingID: postingID,
+		FullName:  strings.TrimSpace(request.FullName),
+		Email:     strings.TrimSpace(request.Email),
+		Phone:     request.Phone,
+		Message:   request.Message,
+	}
+	if err := jh.JobPostingStore.CreateApplicant(orgID, applicant); err != nil {
+		if errors.Is(err, database.ErrDuplicateApplication) {
+			c.JSON(http.StatusConflict, gin.H{"error": "You already applied to this posting"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit application"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Application received", "data": gin.H{"id": applicant.ID}})
+}
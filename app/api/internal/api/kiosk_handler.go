@@ -0,0 +1,400 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	maxKioskDevicesPerOrganization = 20
+	defaultKioskEntriesDays        = 7
+)
+
+var kioskPinPattern = regexp.MustCompile(`^[0-9]{4,6}$`)
+
+// KioskHandler lets employees clock in and out on a shared tablet with a personal PIN instead of the app.
+// Wrong PINs lock the PIN of the employee after KIOSK_PIN_MAX_ATTEMPTS consecutive failures for
+// KIOSK_PIN_LOCKOUT_DURATION.
+type KioskHandler struct {
+	KioskStore     database.KioskStore
+	MaxPinAttempts int
+	PinLockout     time.Duration
+	Logger         *slog.Logger
+}
+
+func NewKioskHandler(kioskStore database.KioskStore, logger *slog.Logger) *KioskHandler {
+	maxAttempts := 5
+	if value := os.Getenv("KIOSK_PIN_MAX_ATTEMPTS"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			maxAttempts = parsed
+		}
+	}
+	lockout := 15 * time.Minute
+	if value := os.Getenv("KIOSK_PIN_LOCKOUT_DURATION"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			lockout = parsed
+		}
+	}
+
+	return &KioskHandler{
+		KioskStore:     kioskStore,
+		MaxPinAttempts: maxAttempts,
+		PinLockout:     lockout,
+		Logger:         logger,
+	}
+}
+
+type KioskDeviceRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// KioskDeviceWithToken is a device with its token, only sent when the device is registered
+type KioskDeviceWithToken struct {
+	database.KioskDevice
+	Token string `json:"token"`
+}
+
+type KioskPinRequest struct {
+	Pin string `json:"pin" binding:"required"`
+}
+
+// KioskClockRequest identifies the employee clocking in or out on a kiosk
+type KioskClockRequest struct {
+	EmployeeID uuid.UUID `json:"employee_id" binding:"required"`
+	Pin        string    `json:"pin" binding:"required"`
+}
+
+func newKioskToken() (string, error) {
+	token, err := utils.GenerateRandomPassword(32)
+	if err != nil {
+		return "", err
+	}
+	return "kiosk_" + token, nil
+}
+
+// adminOnly lets admins through, kiosk devices clock in every employee of the organization
+func (kh *KioskHandler) adminOnly(c *gin.Context) *database.User {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return nil
+	}
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can manage kiosk devices"})
+		return nil
+	}
+	return user
+}
+
+// GetKioskDevicesHandler lists the kiosk devices of the organization, revoked ones included
+func (kh *KioskHandler) GetKioskDevicesHandler(c *gin.Context) {
+	user := kh.adminOnly(c)
+	if user == nil {
+		return
+	}
+
+	devices, err := kh.KioskStore.ListDevices(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve kiosk devices"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Kiosk devices retrieved successfully",
+		"data":    devices,
+	})
+}
+
+// CreateKioskDeviceHandler registers a kiosk device and returns its token, which is not shown again
+func (kh *KioskHandler) CreateKioskDeviceHandler(c *gin.Context) {
+	user := kh.adminOnly(c)
+	if user == nil {
+		return
+	}
+
+	var req KioskDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	existing, err := kh.KioskStore.ListDevices(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register kiosk device"})
+		return
+	}
+	active := 0
+	for _, device := range existing {
+		if device.RevokedAt == nil {
+			active++
+		}
+	}
+	if active >= maxKioskDevicesPerOrganization {
+		c.JSON(http.StatusConflict, gin.H{"error": "An organization can have at most " + strconv.Itoa(maxKioskDevicesPerOrganization) + " kiosk devices"})
+		return
+	}
+
+	token, err := newKioskToken()
+	if err != nil {
+		kh.Logger.Error("failed to generate kiosk token", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register kiosk device"})
+		return
+	}
+	device := &database.KioskDevice{Name: req.Name, CreatedBy: &user.ID}
+	if err := kh.KioskStore.CreateDevice(user.OrganizationID, device, token); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register kiosk device"})
+		return
+	}
+
+	kh.Logger.Info("kiosk device registered", "org_id", user.OrganizationID, "device_id", device.ID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Kiosk device registered successfully. Store the token now, it will not be shown again",
+		"data":    KioskDeviceWithToken{KioskDevice: *device, Token: token},
+	})
+}
+
+// RevokeKioskDeviceHandler stops a device from clocking employees in, for lost or replaced tablets
+func (kh *KioskHandler) RevokeKioskDeviceHandler(c *gin.Context) {
+	user := kh.adminOnly(c)
+	if user == nil {
+		return
+	}
+
+	deviceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid kiosk device ID"})
+		return
+	}
+	if err := kh.KioskStore.RevokeDevice(user.OrganizationID, deviceID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Kiosk device not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke kiosk device"})
+		return
+	}
+
+	kh.Logger.Info("kiosk device revoked", "org_id", user.OrganizationID, "device_id", deviceID)
+	c.JSON(http.StatusOK, gin.H{"message": "Kiosk device revoked successfully"})
+}
+
+// GetTimeClockEntriesHandler lists the clock ins of the organization between ?from= and ?to=, inclusive,
+// the last 7 days by default
+func (kh *KioskHandler) GetTimeClockEntriesHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access the time clock"})
+		return
+	}
+
+	to := startOfDay(time.Now()).AddDate(0, 0, 1)
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+		// to is inclusive, the whole day counts
+		to = parsed.AddDate(0, 0, 1)
+	}
+	from := to.AddDate(0, 0, -defaultKioskEntriesDays)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+		from = parsed
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	entries, err := kh.KioskStore.ListEntries(user.OrganizationID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve time clock entries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Time clock entries retrieved successfully",
+		"data": gin.H{
+			"from":    from.Format(time.DateOnly),
+			"to":      to.AddDate(0, 0, -1).Format(time.DateOnly),
+			"entries": entries,
+		},
+	})
+}
+
+// SetKioskPinHandler sets the 4 to 6 digit PIN the current user clocks in with on the kiosks of the
+// organization, replacing the previous one and lifting a lockout
+func (kh *KioskHandler) SetKioskPinHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	var req KioskPinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if !kioskPinPattern.MatchString(req.Pin) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The PIN must be 4 to 6 digits"})
+		return
+	}
+
+	hash, err := database.Hash(req.Pin)
+	if err != nil {
+		kh.Logger.Error("failed to hash kiosk pin", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set kiosk PIN"})
+		return
+	}
+	if err := kh.KioskStore.SetPin(user.OrganizationID, user.ID, hash); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set kiosk PIN"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Kiosk PIN set successfully"})
+}
+
+// GetKioskEmployeesHandler lists the employees who can clock in on the kiosk and whether they are
+// clocked in, for the kiosk to show its employee picker
+func (kh *KioskHandler) GetKioskEmployeesHandler(c *gin.Context) {
+	device := middleware.KioskDeviceFromContext(c)
+	if device == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	employees, err := kh.KioskStore.ListKioskEmployees(device.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve kiosk employees"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Kiosk employees retrieved successfully",
+		"data":    employees,
+	})
+}
+
+// verifyPin checks the PIN of the employee clocking in or out, writing the error response when it does
+// not match. Unknown employees and employees without a PIN get the same answer as a wrong PIN.
+func (kh *KioskHandler) verifyPin(c *gin.Context, device *database.KioskDevice) *KioskClockRequest {
+	var req KioskClockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return nil
+	}
+
+	pin, err := kh.KioskStore.GetPin(device.OrganizationID, req.EmployeeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid employee or PIN"})
+			return nil
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify kiosk PIN"})
+		return nil
+	}
+	if pin.State.IsLocked(time.Now()) {
+		c.JSON(http.StatusLocked, gin.H{"error": "PIN temporarily locked after too many wrong attempts, try again later"})
+		return nil
+	}
+
+	match, err := pin.Pin.Matches(req.Pin)
+	if err != nil {
+		kh.Logger.Error("failed to check kiosk pin", "error", err, "user_id", req.EmployeeID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify kiosk PIN"})
+		return nil
+	}
+	if !match {
+		state, err := kh.KioskStore.RecordFailedPin(device.OrganizationID, req.EmployeeID, kh.MaxPinAttempts, kh.PinLockout)
+		if err == nil && state.IsLocked(time.Now()) {
+			kh.Logger.Warn("kiosk pin locked after wrong attempts", "user_id", req.EmployeeID, "device_id", device.ID)
+			c.JSON(http.StatusLocked, gin.H{"error": "PIN temporarily locked after too many wrong attempts, try again later"})
+			return nil
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid employee or PIN"})
+		return nil
+	}
+
+	if pin.State.FailedAttempts > 0 {
+		kh.KioskStore.ResetFailedPins(device.OrganizationID, req.EmployeeID)
+	}
+	return &req
+}
+
+// KioskClockInHandler clocks an employee in on the kiosk after checking their PIN
+func (kh *KioskHandler) KioskClockInHandler(c *gin.Context) {
+	device := middleware.KioskDeviceFromContext(c)
+	if device == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	req := kh.verifyPin(c, device)
+	if req == nil {
+		return
+	}
+
+	entry := &database.TimeClockEntry{EmployeeID: req.EmployeeID, DeviceID: &device.ID, ClockInAt: time.Now()}
+	if err := kh.KioskStore.ClockIn(device.OrganizationID, entry); err != nil {
+		if errors.Is(err, database.ErrAlreadyClockedIn) {
+			c.JSON(http.StatusConflict, gin.H{"error": "You are already clocked in"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clock in"})
+		return
+	}
+
+	kh.Logger.Info("employee clocked in", "org_id", device.OrganizationID, "user_id", req.EmployeeID, "device_id", device.ID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Clocked in successfully",
+		"data":    entry,
+	})
+}
+
+// KioskClockOutHandler clocks an employee out on the kiosk after checking their PIN
+func (kh *KioskHandler) KioskClockOutHandler(c *gin.Context) {
+	device := middleware.KioskDeviceFromContext(c)
+	if device == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	req := kh.verifyPin(c, device)
+	if req == nil {
+		return
+	}
+
+	entry, err := kh.KioskStore.ClockOut(device.OrganizationID, req.EmployeeID, time.Now())
+	if err != nil {
+		if errors.Is(err, database.ErrNotClockedIn) {
+			c.JSON(http.StatusConflict, gin.H{"error": "You are not clocked in"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clock out"})
+		return
+	}
+
+	kh.Logger.Info("employee clocked out", "org_id", device.OrganizationID, "user_id", req.EmployeeID, "device_id", device.ID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Clocked out successfully",
+		"data":    entry,
+	})
+}
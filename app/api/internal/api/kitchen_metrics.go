@@ -0,0 +1,221 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// Period of the prep time statistics when no days are given
+	defaultPrepTimeDays = 28
+	// Clock difference allowed between the kitchen display or POS and the server
+	preparedAtClockSkew = time.Minute
+
+	kitchenLevelIdle       = "idle"
+	kitchenLevelNormal     = "normal"
+	kitchenLevelBusy       = "busy"
+	kitchenLevelOverloaded = "overloaded"
+	kitchenLevelUnstaffed  = "unstaffed"
+)
+
+// MarkPreparedRequest bumps an order, or some of its items, off the kitchen display
+type MarkPreparedRequest struct {
+	// Items prepared, every item of the order when empty
+	Items []uuid.UUID `json:"items"`
+	// PreparedAt is when the kitchen finished them, now when empty. POS events send their own time.
+	PreparedAt *time.Time `json:"prepared_at"`
+}
+
+// KitchenLoadMetric is how much of the kitchen the open orders take. RequiredEmployees is how many of the
+// staff on shift are needed to clear the queued prep units within the target kitchen time, and LoadPercent
+// compares the queue with what the staff on shift clear in that time.
+type KitchenLoadMetric struct {
+	database.KitchenLoad
+	StaffOnShift      int      `json:"staff_on_shift"`
+	RequiredEmployees int      `json:"required_employees"`
+	LoadPercent       *float64 `json:"load_percent"`
+	BacklogMinutes    *float64 `json:"backlog_minutes"`
+	TargetMinutes     int      `json:"target_minutes"`
+	Level             string   `json:"level"`
+}
+
+// MeasureKitchenLoad rates the open orders against the capacity of the staff on shift. The level is idle
+// without open orders, unstaffed when nobody who prepares items is on shift, then normal up to 75% of the
+// capacity, busy up to 100% and overloaded above.
+func MeasureKitchenLoad(load database.KitchenLoad, capacity database.KitchenCapacity, targetMinutes int) KitchenLoadMetric {
+	if targetMinutes <= 0 {
+		targetMinutes = defaultWaitingTime
+	}
+	metric := KitchenLoadMetric{KitchenLoad: load, StaffOnShift: capacity.StaffOnShift, TargetMinutes: targetMinutes}
+
+	if capacity.ItemsPerHour <= 0 || capacity.StaffOnShift == 0 {
+		metric.Level = kitchenLevelUnstaffed
+		if load.OpenOrders == 0 {
+			metric.Level = kitchenLevelIdle
+		}
+		return metric
+	}
+
+	perMinute := capacity.ItemsPerHour / 60
+	backlog := roundMinutes(load.PrepUnits / perMinute)
+	percent := math.Round(load.PrepUnits/(perMinute*float64(targetMinutes))*1000) / 10
+	metric.BacklogMinutes = &backlog
+	metric.LoadPercent = &percent
+
+	perEmployee := perMinute / float64(capacity.StaffOnShift) * float64(targetMinutes)
+	metric.RequiredEmployees = int(math.Ceil(load.PrepUnits / perEmployee))
+
+	switch {
+	case load.OpenOrders == 0:
+		metric.Level = kitchenLevelIdle
+	case percent <= 75:
+		metric.Level = kitchenLevelNormal
+	case percent <= 100:
+		metric.Level = kitchenLevelBusy
+	default:
+		metric.Level = kitchenLevelOverloaded
+	}
+	return metric
+}
+
+// MarkOrderPreparedHandler records an order, or the items listed, as prepared. Kitchen displays call it
+// when an order is bumped and POS systems when they report it ready.
+func (wh *WaitTimeHandler) MarkOrderPreparedHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("order"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	// the body is optional, an empty request bumps the whole order now
+	var req MarkPreparedRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	now := time.Now()
+	preparedAt := now
+	if req.PreparedAt != nil {
+		if req.PreparedAt.After(now.Add(preparedAtClockSkew)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "prepared_at cannot be in the future"})
+			return
+		}
+		preparedAt = *req.PreparedAt
+	}
+
+	order, err := wh.WaitTimeStore.MarkPrepared(user.OrganizationID, orderID, req.Items, preparedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		case errors.Is(err, database.ErrUnknownOrderItem):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "An item is not on the order"})
+		case errors.Is(err, database.ErrPreparedBeforeCreated):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "prepared_at is before the order was placed"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark order prepared"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Order marked prepared", "data": order})
+}
+
+// GetKitchenLoadHandler reports the live load of the kitchen against the staff on shift
+func (wh *WaitTimeHandler) GetKitchenLoadHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	rules, err := wh.RulesStore.GetRulesByOrganizationID(user.OrganizationID)
+	if err != nil {
+		wh.Logger.Error("failed to get rules", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to measure kitchen load"})
+		return
+	}
+
+	now := time.Now()
+	load, err := wh.WaitTimeStore.GetOpenKitchenLoad(user.OrganizationID, now.Add(-openOrderWindow))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to measure kitchen load"})
+		return
+	}
+	capacity, err := wh.WaitTimeStore.GetKitchenCapacity(user.OrganizationID, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to measure kitchen load"})
+		return
+	}
+	recent, err := wh.WaitTimeStore.GetRecentPrepTime(user.OrganizationID, now.Add(-recentPrepWindow))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to measure kitchen load"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Kitchen load measured successfully",
+		"data": gin.H{
+			"load":        MeasureKitchenLoad(*load, *capacity, waitTimeTuning(rules).FallbackMinutes),
+			"recent_prep": recent,
+		},
+	})
+}
+
+// GetPrepTimesHandler averages the prep time per item and per hour of the day over the last days
+// (28 by default)
+func (wh *WaitTimeHandler) GetPrepTimesHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access prep times"})
+		return
+	}
+
+	days := defaultPrepTimeDays
+	if value := c.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 365 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid days, expected a number between 1 and 365"})
+			return
+		}
+		days = parsed
+	}
+	since := time.Now().AddDate(0, 0, -days)
+
+	items, err := wh.WaitTimeStore.GetItemPrepTimes(user.OrganizationID, since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve prep times"})
+		return
+	}
+	hours, err := wh.WaitTimeStore.GetHourlyPrepTimes(user.OrganizationID, since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve prep times"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Prep times retrieved successfully",
+		"data":    gin.H{"days": days, "items": items, "hours": hours},
+	})
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// kpiProgressDays is the period the progress against the KPI targets is measured over when no from date
+// is given
+const kpiProgressDays = 7
+
+// KPITargetHandler manages the weekly revenue, labor cost and rating targets of an organization and how
+// its periods did against them
+type KPITargetHandler struct {
+	Store   database.KPITargetStore
+	Tracker *service.KPITracker
+	Logger  *slog.Logger
+}
+
+func NewKPITargetHandler(store database.KPITargetStore, tracker *service.KPITracker, logger *slog.Logger) *KPITargetHandler {
+	return &KPITargetHandler{
+		Store:   store,
+		Tracker: tracker,
+		Logger:  logger,
+	}
+}
+
+// SetKPITargetsRequest replaces the targets, a target left out is not tracked
+type SetKPITargetsRequest struct {
+	WeeklyRevenue    *float64 `json:"weekly_revenue" binding:"omitempty,gt=0"`
+	LaborCostPercent *float64 `json:"labor_cost_percent" binding:"omitempty,gt=0,lte=100"`
+	AverageRating    *float64 `json:"average_rating" binding:"omitempty,gte=1,lte=5"`
+}
+
+// GetKPITargetsHandler returns the targets of the organization, null when it set none
+func (kh *KPITargetHandler) GetKPITargetsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can view KPI targets"})
+		return
+	}
+
+	targets, err := kh.Store.GetTargets(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve KPI targets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "KPI targets retrieved successfully", "data": targets})
+}
+
+// SetKPITargetsHandler replaces the targets of the organization, at least one target must be set
+func (kh *KPITargetHandler) SetKPITargetsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can set KPI targets"})
+		return
+	}
+
+	var req SetKPITargetsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.WeeklyRevenue == nil && req.LaborCostPercent == nil && req.AverageRating == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Set at least one of weekly_revenue, labor_cost_percent and average_rating"})
+		return
+	}
+
+	targets := &database.KPITargets{
+		OrganizationID:   user.OrganizationID,
+		WeeklyRevenue:    req.WeeklyRevenue,
+		LaborCostPercent: req.LaborCostPercent,
+		AverageRating:    req.AverageRating,
+		UpdatedBy:        &user.ID,
+		UpdatedAt:        time.Now(),
+	}
+	if err := kh.Store.SetTargets(targets); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set KPI targets"})
+		return
+	}
+
+	kh.Logger.Info("KPI targets set", "org_id", user.OrganizationID, "user_id", user.ID)
+	c.JSON(http.StatusOK, gin.H{"message": "KPI targets set successfully", "data": targets})
+}
+
+// DeleteKPITargetsHandler stops tracking the targets of the organization
+func (kh *KPITargetHandler) DeleteKPITargetsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can remove KPI targets"})
+		return
+	}
+
+	if err := kh.Store.DeleteTargets(user.OrganizationID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No KPI targets are set"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove KPI targets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "KPI targets removed successfully"})
+}
+
+// GetKPIProgressHandler measures the revenue, labor cost and rating of a period against the targets, the
+// weekly revenue target scaled to its days. from and to (YYYY-MM-DD, inclusive) default to the last 7 days.
+func (kh *KPITargetHandler) GetKPIProgressHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can view KPI progress"})
+		return
+	}
+
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.Local)
+	from := to.AddDate(0, 0, -kpiProgressDays)
+	queryFrom, queryTo, ok := queryDateRange(c)
+	if !ok {
+		return
+	}
+	if queryTo != nil {
+		to = *queryTo
+		from = to.AddDate(0, 0, -kpiProgressDays)
+	}
+	if queryFrom != nil {
+		from = *queryFrom
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	progress, err := kh.Tracker.Progress(user.OrganizationID, from, to)
+	if err != nil {
+		kh.Logger.Error("failed to measure KPI progress", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to measure KPI progress"})
+		return
+	}
+	if progress == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No KPI targets are set"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "KPI progress retrieved successfully", "data": progress})
+}
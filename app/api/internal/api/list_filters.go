@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// queryList returns the comma separated values of a list filter, e.g. ?status=delivered,not delivered.
+// The parameter can also be repeated.
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, param := range c.QueryArray(key) {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// queryIDs parses a list filter of IDs, writing a 400 response when one is invalid
+func queryIDs(c *gin.Context, key string) ([]uuid.UUID, bool) {
+	var ids []uuid.UUID
+	for _, value := range queryList(c, key) {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + key + ", expected IDs"})
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	return ids, true
+}
+
+// queryDateRange parses the optional ?from= and ?to= dates of a list, to inclusive. It returns the
+// start of from and the start of the day after to, writing a 400 response when they are invalid.
+func queryDateRange(c *gin.Context) (from *time.Time, to *time.Time, ok bool) {
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+			return nil, nil, false
+		}
+		from = &parsed
+	}
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD"})
+			return nil, nil, false
+		}
+		parsed = parsed.AddDate(0, 0, 1)
+		to = &parsed
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return nil, nil, false
+	}
+	return from, to, true
+}
@@ -0,0 +1,279 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/clockwise/clockwise/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// magicLinkWindow is the window the login links of an account and the requests of an IP address are counted over
+const magicLinkWindow = time.Hour
+
+// magicLinkSent is the answer to every accepted request, so it does not tell which emails have an account
+const magicLinkSent = "If this email belongs to an account allowed to log in with a link, a login link has been sent"
+
+// MagicLinkHandler logs members of the organizations that enabled it in with single-use links emailed to
+// them. Links are valid for MAGIC_LINK_TTL (15m), an account gets at most MAGIC_LINK_MAX_PER_HOUR (3) links
+// an hour and an IP address makes at most MAGIC_LINK_MAX_PER_IP (10) requests an hour. The link opens
+// MAGIC_LINK_URL (PUBLIC_APP_URL/login, http://localhost:3000/login by default) with the token, which the
+// login page exchanges for a session.
+type MagicLinkHandler struct {
+	MagicLinkStore database.MagicLinkStore
+	UserStore      database.UserStore
+	OrgStore       database.OrgStore
+	EmailService   service.EmailService
+	Logger         *slog.Logger
+	// LoginGuard refuses locked accounts and warns about new devices, nil does neither
+	LoginGuard *middleware.LoginGuard
+
+	TTL        time.Duration
+	MaxPerUser int
+	MaxPerIP   int
+	LinkURL    string
+}
+
+func NewMagicLinkHandler(magicLinkStore database.MagicLinkStore, userStore database.UserStore, orgStore database.OrgStore, emailService service.EmailService, logger *slog.Logger) *MagicLinkHandler {
+	ttl := 15 * time.Minute
+	if value := os.Getenv("MAGIC_LINK_TTL"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			ttl = parsed
+		}
+	}
+	maxPerUser := 3
+	if value := os.Getenv("MAGIC_LINK_MAX_PER_HOUR"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			maxPerUser = parsed
+		}
+	}
+	maxPerIP := 10
+	if value := os.Getenv("MAGIC_LINK_MAX_PER_IP"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			maxPerIP = parsed
+		}
+	}
+	linkURL := os.Getenv("MAGIC_LINK_URL")
+	if linkURL == "" {
+		appURL := strings.TrimSuffix(os.Getenv("PUBLIC_APP_URL"), "/")
+		if appURL == "" {
+			appURL = "http://localhost:3000"
+		}
+		linkURL = appURL + "/login"
+	}
+
+	return &MagicLinkHandler{
+		MagicLinkStore: magicLinkStore,
+		UserStore:      userStore,
+		OrgStore:       orgStore,
+		EmailService:   emailService,
+		Logger:         logger,
+		TTL:            ttl,
+		MaxPerUser:     maxPerUser,
+		MaxPerIP:       maxPerIP,
+		LinkURL:        linkURL,
+	}
+}
+
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type MagicLinkExchangeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type MagicLinkSettingsRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// RequestMagicLinkHandler emails a login link to the account of the email. The answer is the same whether
+// or not a link was sent, except when the IP address made too many requests.
+func (mh *MagicLinkHandler) RequestMagicLinkHandler(c *gin.Context) {
+	var request MagicLinkRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// every request counts against the IP address before the email is looked up, so the limit does not
+	// tell which emails have an account
+	ipAddress := c.ClientIP()
+	fromIP, err := mh.MagicLinkStore.RecordMagicLinkRequest(ipAddress, time.Now().Add(-magicLinkWindow))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send login link"})
+		return
+	}
+	if fromIP > mh.MaxPerIP {
+		mh.Logger.Warn("magic link requests rate limited", "ip_address", ipAddress)
+		c.Header("Retry-After", strconv.Itoa(int(magicLinkWindow.Seconds())))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many login links requested, try again later"})
+		return
+	}
+
+	user, err := mh.UserStore.GetUserByEmail(strings.TrimSpace(request.Email))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			mh.Logger.Error("failed to get user for magic link", "error", err)
+		}
+		c.JSON(http.StatusAccepted, gin.H{"message": magicLinkSent})
+		return
+	}
+
+	enabled, err := mh.OrgStore.GetMagicLinkEnabled(user.OrganizationID)
+	if err != nil || !enabled {
+		if err != nil {
+			mh.Logger.Error("failed to check magic link login", "error", err, "org_id", user.OrganizationID)
+		}
+		c.JSON(http.StatusAccepted, gin.H{"message": magicLinkSent})
+		return
+	}
+
+	forUser, err := mh.MagicLinkStore.CountRecentMagicLinks(user.ID, time.Now().Add(-magicLinkWindow))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send login link"})
+		return
+	}
+	// the account limit is not told apart, it would confirm the account exists
+	if forUser >= mh.MaxPerUser {
+		mh.Logger.Warn("magic link requests of account rate limited", "user_id", user.ID)
+		c.JSON(http.StatusAccepted, gin.H{"message": magicLinkSent})
+		return
+	}
+
+	token, err := utils.GenerateRandomPassword(32)
+	if err != nil {
+		mh.Logger.Error("failed to generate magic link token", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send login link"})
+		return
+	}
+	if err := mh.MagicLinkStore.CreateMagicLink(user.ID, token, ipAddress, time.Now().Add(mh.TTL)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send login link"})
+		return
+	}
+
+	link := mh.LinkURL + "?magic_token=" + url.QueryEscape(token)
+	go func() {
+		if err := mh.EmailService.SendMagicLinkEmail(user.Email, user.FullName, link, mh.TTL); err != nil {
+			mh.Logger.Error("failed to send magic link email", "error", err, "user_id", user.ID)
+		}
+	}()
+
+	mh.Logger.Info("magic link sent", "user_id", user.ID)
+	c.JSON(http.StatusAccepted, gin.H{"message": magicLinkSent})
+}
+
+// ExchangeMagicLinkHandler exchanges the token of a login link for a session, once
+func (mh *MagicLinkHandler) ExchangeMagicLinkHandler(tokens TokenGenerator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request MagicLinkExchangeRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		userID, err := mh.MagicLinkStore.ConsumeMagicLink(request.Token)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Login link is invalid, expired or already used"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+			return
+		}
+
+		user, err := mh.UserStore.GetUserByID(userID)
+		if err != nil {
+			mh.Logger.Error("failed to get user of magic link", "error", err, "user_id", userID)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Login link is invalid, expired or already used"})
+			return
+		}
+		// the organization may have turned the links off since the link was sent
+		if enabled, err := mh.OrgStore.GetMagicLinkEnabled(user.OrganizationID); err != nil || !enabled {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Login links are disabled for your organization"})
+			return
+		}
+		if mh.LoginGuard != nil {
+			if err := mh.LoginGuard.CheckLocked(user); err != nil {
+				c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		token, err := tokens.TokenGenerator(c.Request.Context(), user)
+		if err != nil {
+			mh.Logger.Error("failed to generate token", "error", err, "user_id", user.ID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+			return
+		}
+		if mh.LoginGuard != nil {
+			mh.LoginGuard.LoginSucceeded(c, user)
+		}
+
+		mh.Logger.Info("logged in with magic link", "user_id", user.ID)
+		c.JSON(http.StatusOK, gin.H{
+			"access_token":  token.AccessToken,
+			"refresh_token": token.RefreshToken,
+			"expires_at":    token.ExpiresAt,
+		})
+	}
+}
+
+// GetMagicLinkSettingsHandler tells whether the members of the organization can log in with emailed links
+func (mh *MagicLinkHandler) GetMagicLinkSettingsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	enabled, err := mh.OrgStore.GetMagicLinkEnabled(user.OrganizationID)
+	if err != nil {
+		mh.Logger.Error("failed to get magic link login", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve login link settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Login link settings retrieved successfully", "data": gin.H{"enabled": enabled}})
+}
+
+// UpdateMagicLinkSettingsHandler turns login links on or off for the organization. Links already sent stop
+// working when they are turned off.
+func (mh *MagicLinkHandler) UpdateMagicLinkSettingsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change login link settings"})
+		return
+	}
+
+	var request MagicLinkSettingsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := mh.OrgStore.SetMagicLinkEnabled(user.OrganizationID, *request.Enabled); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+			return
+		}
+		mh.Logger.Error("failed to set magic link login", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update login link settings"})
+		return
+	}
+
+	mh.Logger.Info("magic link login updated", "org_id", user.OrganizationID, "enabled", *request.Enabled)
+	c.JSON(http.StatusOK, gin.H{"message": "Login link settings updated successfully", "data": gin.H{"enabled": *request.Enabled}})
+}
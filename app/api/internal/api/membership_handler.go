@@ -0,0 +1,208 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+
+	"github.com/appleboy/gin-jwt/v3/core"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TokenGenerator issues access and refresh tokens for a user, implemented by the JWT middleware
+type TokenGenerator interface {
+	TokenGenerator(ctx context.Context, data any) (*core.Token, error)
+}
+
+type MembershipHandler struct {
+	MembershipStore database.MembershipStore
+	UserStore       database.UserStore
+	Logger          *slog.Logger
+}
+
+func NewMembershipHandler(membershipStore database.MembershipStore, userStore database.UserStore, logger *slog.Logger) *MembershipHandler {
+	return &MembershipHandler{
+		MembershipStore: membershipStore,
+		UserStore:       userStore,
+		Logger:          logger,
+	}
+}
+
+type SwitchOrganizationRequest struct {
+	OrganizationID uuid.UUID `json:"organization_id" binding:"required"`
+}
+
+type AddMemberRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	UserRole string `json:"user_role" binding:"required,oneof=manager employee"`
+}
+
+// GetMyOrganizationsHandler lists the organizations the current user can switch to
+func (mh *MembershipHandler) GetMyOrganizationsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	memberships, err := mh.MembershipStore.GetMembershipsForUser(user.ID)
+	if err != nil {
+		mh.Logger.Error("failed to get memberships", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organizations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Organizations retrieved successfully",
+		"data": gin.H{
+			"current_organization_id": user.OrganizationID,
+			"organizations":           memberships,
+		},
+	})
+}
+
+// SwitchOrganizationHandler issues a token scoped to another organization the user is a member of,
+// carrying the user's role in that organization
+func (mh *MembershipHandler) SwitchOrganizationHandler(tokens TokenGenerator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := middleware.ValidateOrgAccess(c)
+		if user == nil {
+			return
+		}
+
+		var req SwitchOrganizationRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			mh.Logger.Warn("invalid switch organization request", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		membership, err := mh.MembershipStore.GetMembership(user.ID, req.OrganizationID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				mh.Logger.Warn("switch to organization without membership", "user_id", user.ID, "org_id", req.OrganizationID)
+				c.JSON(http.StatusForbidden, gin.H{"error": "You are not a member of this organization"})
+				return
+			}
+			mh.Logger.Error("failed to get membership", "error", err, "user_id", user.ID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to switch organization"})
+			return
+		}
+
+		scoped := *user
+		scoped.OrganizationID = membership.OrganizationID
+		scoped.UserRole = membership.UserRole
+
+		token, err := tokens.TokenGenerator(c.Request.Context(), &scoped)
+		if err != nil {
+			mh.Logger.Error("failed to generate token", "error", err, "user_id", user.ID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to switch organization"})
+			return
+		}
+
+		mh.Logger.Info("organization switched", "user_id", user.ID, "org_id", membership.OrganizationID)
+		c.JSON(http.StatusOK, gin.H{
+			"access_token":    token.AccessToken,
+			"refresh_token":   token.RefreshToken,
+			"expires_at":      token.ExpiresAt,
+			"organization_id": membership.OrganizationID,
+			"user_role":       membership.UserRole,
+		})
+	}
+}
+
+// AddMemberHandler gives an existing user of another organization access to this one
+func (mh *MembershipHandler) AddMemberHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		mh.Logger.Warn("forbidden member addition", "user_id", user.ID, "role", user.UserRole)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can add members"})
+		return
+	}
+
+	var req AddMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		mh.Logger.Warn("invalid add member request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := mh.UserStore.GetUserByEmail(req.Email)
+	if err != nil || member == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if member.OrganizationID == user.OrganizationID {
+		c.JSON(http.StatusConflict, gin.H{"error": "User already belongs to this organization"})
+		return
+	}
+
+	membership := &database.OrganizationMembership{
+		UserID:         member.ID,
+		OrganizationID: user.OrganizationID,
+		UserRole:       req.UserRole,
+	}
+	if err := mh.MembershipStore.AddMembership(membership); err != nil {
+		mh.Logger.Error("failed to add member", "error", err, "member_id", member.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add member"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Member added successfully",
+		"data":    membership,
+	})
+}
+
+// RemoveMemberHandler revokes the access of a member whose home organization is another one
+func (mh *MembershipHandler) RemoveMemberHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		mh.Logger.Warn("forbidden member removal", "user_id", user.ID, "role", user.UserRole)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can remove members"})
+		return
+	}
+
+	memberID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	member, err := mh.UserStore.GetUserByID(memberID)
+	if err != nil || member == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if member.OrganizationID == user.OrganizationID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Employees of this organization are removed with a layoff"})
+		return
+	}
+
+	if err := mh.MembershipStore.RemoveMembership(memberID, user.OrganizationID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User is not a member of this organization"})
+			return
+		}
+		mh.Logger.Error("failed to remove member", "error", err, "member_id", memberID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Member removed successfully",
+	})
+}
@@ -0,0 +1,24 @@
+package api
+
+import "github.com/clockwise/clockwise/backend/internal/mlproto"
+
+// The ML protocol types live in package mlproto, shared with the contract checks. The handlers refer to
+// them by these names.
+type (
+	Place                          = mlproto.Place
+	SchedulePredictRequest         = mlproto.SchedulePredictRequest
+	ScheduleInput                  = mlproto.ScheduleInput
+	Employee                       = mlproto.Employee
+	SchedulerConfig                = mlproto.SchedulerConfig
+	EmployeeHours                  = mlproto.EmployeeHours
+	DeliveryDemand                 = mlproto.DeliveryDemand
+	DeliveryDemandDay              = mlproto.DeliveryDemandDay
+	DeliveryDemandHour             = mlproto.DeliveryDemandHour
+	GenerateScheduleResponse       = mlproto.GenerateScheduleResponse
+	ManagementInsights             = mlproto.ManagementInsights
+	DemandPredictionRequest        = mlproto.DemandPredictionRequest
+	ChannelSlotMix                 = mlproto.ChannelSlotMix
+	RecommendedCampaignItem        = mlproto.RecommendedCampaignItem
+	CampaignRecommendationResponse = mlproto.CampaignRecommendationResponse
+	CampaignFeedbackResponse       = mlproto.CampaignFeedbackResponse
+)
@@ -0,0 +1,271 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+// MLPayloadError lists every problem found in a payload before it is sent to
+// the ML service, so the caller can fix them all in one go.
+type MLPayloadError struct {
+	Problems []string
+}
+
+func (e *MLPayloadError) Error() string {
+	return "invalid ML payload: " + strings.Join(e.Problems, "; ")
+}
+
+type mlPayloadValidator struct {
+	problems []string
+}
+
+func (v *mlPayloadValidator) addf(format string, args ...any) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *mlPayloadValidator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &MLPayloadError{Problems: v.problems}
+}
+
+// mlPayloadErrorResponse is the 422 body returned when a payload fails validation
+func mlPayloadErrorResponse(message string, err error) gin.H {
+	details := []string{err.Error()}
+	if payloadErr, ok := err.(*MLPayloadError); ok {
+		details = payloadErr.Problems
+	}
+	return gin.H{"error": message, "details": details}
+}
+
+// parseClockTime accepts both "15:04" and the "15:04:05" format returned for TIME columns
+func parseClockTime(value string) (time.Time, error) {
+	if t, err := time.Parse("15:04:05", value); err == nil {
+		return t, nil
+	}
+	return time.Parse("15:04", value)
+}
+
+func validatePlaceIdentity(v *mlPayloadValidator, name, placeType string, latitude, longitude *float64) {
+	if strings.TrimSpace(name) == "" {
+		v.addf("organization name is required")
+	}
+	if strings.TrimSpace(placeType) == "" {
+		v.addf("organization type is required")
+	}
+	if latitude == nil {
+		v.addf("organization latitude is required, please update the organization location")
+	} else if *latitude < -90 || *latitude > 90 {
+		v.addf("organization latitude %v must be between -90 and 90", *latitude)
+	}
+	if longitude == nil {
+		v.addf("organization longitude is required, please update the organization location")
+	} else if *longitude < -180 || *longitude > 180 {
+		v.addf("organization longitude %v must be between -180 and 180", *longitude)
+	}
+}
+
+func validateOpeningHours(v *mlPayloadValidator, hours []database.OperatingHours) {
+	seen := make(map[string]bool)
+	for _, oh := range hours {
+		if !database.IsValidDay(oh.Weekday) {
+			v.addf("opening hours contain invalid weekday %q", oh.Weekday)
+			continue
+		}
+		if seen[oh.Weekday] {
+			v.addf("opening hours contain duplicate weekday %q", oh.Weekday)
+		}
+		seen[oh.Weekday] = true
+
+		if oh.Closed != nil && *oh.Closed {
+			continue
+		}
+		if _, err := parseClockTime(oh.OpeningTime); err != nil {
+			v.addf("opening time %q for %s is not a valid time", oh.OpeningTime, oh.Weekday)
+		}
+		if _, err := parseClockTime(oh.ClosingTime); err != nil {
+			v.addf("closing time %q for %s is not a valid time", oh.ClosingTime, oh.Weekday)
+		}
+	}
+}
+
+func validatePlace(v *mlPayloadValidator, place Place) {
+	validatePlaceIdentity(v, place.Name, place.Type, place.Latitude, place.Longitude)
+
+	if len(place.OpeningHours) == 0 {
+		v.addf("operating hours are required, please set them in the organization rules")
+	}
+	validateOpeningHours(v, place.OpeningHours)
+
+	if place.WaitingTime < 0 {
+		v.addf("waiting_time must not be negative")
+	}
+	if place.Rating != nil && (*place.Rating < 0 || *place.Rating > 5) {
+		v.addf("organization rating %v must be between 0 and 5", *place.Rating)
+	}
+	if place.FixedShifts {
+		if place.NumberShiftsPerDay == nil || *place.NumberShiftsPerDay < 1 {
+			v.addf("number_of_shifts_per_day must be at least 1 when fixed shifts are enabled")
+		}
+		if len(place.ShiftTimes) == 0 {
+			v.addf("shift times are required when fixed shifts are enabled")
+		}
+		for _, st := range place.ShiftTimes {
+			if _, err := parseClockTime(st.From); err != nil {
+				v.addf("shift start %q is not a valid time", st.From)
+			}
+			if _, err := parseClockTime(st.To); err != nil {
+				v.addf("shift end %q is not a valid time", st.To)
+			}
+		}
+	}
+}
+
+func validateDemandDays(v *mlPayloadValidator, days []database.PredictionDay) {
+	if len(days) == 0 {
+		v.addf("demand predictions are required, please generate demand first")
+	}
+	for _, day := range days {
+		if !database.IsValidDay(strings.ToLower(day.Day)) {
+			v.addf("demand prediction contains invalid day %q", day.Day)
+		}
+		for _, hour := range day.Hours {
+			if hour.HourNo < 0 || hour.HourNo > 23 {
+				v.addf("demand prediction for %s has hour %d outside 0-23", day.Day, hour.HourNo)
+			}
+			if hour.OrderCount < 0 || hour.ItemCount < 0 {
+				v.addf("demand prediction for %s hour %d has negative counts", day.Day, hour.HourNo)
+			}
+		}
+	}
+}
+
+func validateEmployeeHours(v *mlPayloadValidator, emp Employee, kind string, hours map[string]EmployeeHours) {
+	for day, h := range hours {
+		if !database.IsValidDay(day) {
+			v.addf("employee %s has %s hours for invalid day %q", emp.EmployeeID, kind, day)
+			continue
+		}
+		if _, err := parseClockTime(h.From); err != nil {
+			v.addf("employee %s has invalid %s start %q on %s", emp.EmployeeID, kind, h.From, day)
+		}
+		if _, err := parseClockTime(h.To); err != nil {
+			v.addf("employee %s has invalid %s end %q on %s", emp.EmployeeID, kind, h.To, day)
+		}
+	}
+}
+
+// validateSchedulePredictRequest checks the payload built for /predict/schedule
+func validateSchedulePredictRequest(request SchedulePredictRequest) error {
+	v := &mlPayloadValidator{}
+	validatePlace(v, request.Place)
+
+	input := request.ScheduleInput
+	cfg := input.SchedulerConfig
+	if cfg.SlotLenHour == nil || *cfg.SlotLenHour <= 0 {
+		v.addf("slot_len_hour must be greater than 0")
+	}
+	if cfg.MinRestSlots == nil || *cfg.MinRestSlots < 0 {
+		v.addf("min_rest_slots must not be negative")
+	}
+	if cfg.MinShiftLengthSlots == nil || *cfg.MinShiftLengthSlots < 1 {
+		v.addf("min_shift_length_slots must be at least 1")
+	}
+	if cfg.WeeklyLaborBudget != nil && *cfg.WeeklyLaborBudget <= 0 {
+		v.addf("weekly_labor_budget must be greater than 0")
+	}
+
+	if len(input.Roles) == 0 {
+		v.addf("at least one organization role is required, please create roles first")
+	}
+	knownRoles := make(map[string]bool)
+	for _, role := range input.Roles {
+		if strings.TrimSpace(role.Role) == "" {
+			v.addf("organization roles must have a name")
+			continue
+		}
+		knownRoles[role.Role] = true
+		if role.MinNeededPerShift < 0 {
+			v.addf("role %s has negative min_present", role.Role)
+		}
+		if role.ItemsPerRolePerHour != nil && *role.ItemsPerRolePerHour < 0 {
+			v.addf("role %s has negative items_per_role_per_hour", role.Role)
+		}
+	}
+
+	validateDemandDays(v, input.DemandPredictions)
+
+	if len(input.Employees) == 0 {
+		v.addf("at least one employee is required to generate a schedule")
+	}
+	for _, emp := range input.Employees {
+		for _, roleName := range emp.RoleNames {
+			// base user roles are not part of the scheduling roles sent to the model
+			if roleName == "employee" || roleName == "admin" {
+				continue
+			}
+			if !knownRoles[roleName] {
+				v.addf("employee %s references unknown role %q", emp.EmployeeID, roleName)
+			}
+		}
+		if emp.HourlyWage != nil && *emp.HourlyWage < 0 {
+			v.addf("employee %s has negative hourly_wage", emp.EmployeeID)
+		}
+		if emp.MaxHoursPerWeek != nil && *emp.MaxHoursPerWeek <= 0 {
+			v.addf("employee %s must have max_hours_per_week greater than 0", emp.EmployeeID)
+		}
+		if emp.MaxConsecSlots != nil && *emp.MaxConsecSlots < 1 {
+			v.addf("employee %s must have max_consec_slots of at least 1", emp.EmployeeID)
+		}
+		validateEmployeeHours(v, emp, "available", emp.AvailableHours)
+		validateEmployeeHours(v, emp, "preferred", emp.PreferredHours)
+	}
+
+	return v.err()
+}
+
+// validateDemandPredictionRequest checks the payload built for /predict/demand
+func validateDemandPredictionRequest(request DemandPredictionRequest) error {
+	v := &mlPayloadValidator{}
+	validatePlace(v, request.Place)
+
+	if _, err := time.Parse(time.DateOnly, request.PredicationStartDate); err != nil {
+		v.addf("prediction_start_date %q must use the YYYY-MM-DD format", request.PredicationStartDate)
+	}
+	if request.PredictionDays != nil && (*request.PredictionDays < 1 || *request.PredictionDays > 31) {
+		v.addf("prediction_days must be between 1 and 31")
+	}
+
+	if len(request.Orders) == 0 {
+		v.addf("historical orders are required, please upload them first")
+	}
+	for _, order := range request.Orders {
+		if order.CreateTime.IsZero() {
+			v.addf("order %s is missing its create_time", order.OrderID)
+		}
+		if order.TotalAmount != nil && *order.TotalAmount < 0 {
+			v.addf("order %s has a negative total_amount", order.OrderID)
+		}
+	}
+
+	for _, campaign := range request.Campaigns {
+		if campaign.DiscountPercent != nil && (*campaign.DiscountPercent < 0 || *campaign.DiscountPercent > 100) {
+			v.addf("campaign %s discount must be between 0 and 100", campaign.Name)
+		}
+	}
+
+	return v.err()
+}
+
+// validateRecommendationPlace checks the organization data sent to /recommend/campaigns
+func validateRecommendationPlace(org *database.Organization, hours []database.OperatingHours) error {
+	v := &mlPayloadValidator{}
+	validatePlaceIdentity(v, org.Name, org.Type, org.Location.Latitude, org.Location.Longitude)
+	validateOpeningHours(v, hours)
+	return v.err()
+}
@@ -0,0 +1,225 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// Alerts of the day sent to phones, the newest ones
+const mobileSummaryAlertLimit = 20
+
+// mobileSummaryFields are the sections of the mobile summary and the fields of their entries
+var mobileSummaryFields = map[string][]string{
+	"schedule": {"name", "start", "end", "type", "on_now"},
+	"alerts":   {"kind", "at", "detail", "value"},
+	"pending":  {"requests", "skills", "total"},
+}
+
+// MobileHandler serves the manager app, which runs on phone networks, so its payloads are kept to a few KB
+// and can be trimmed further with ?fields=
+type MobileHandler struct {
+	MobileSummaryStore database.MobileSummaryStore
+	ScheduleStore      database.ScheduleStore
+	Logger             *slog.Logger
+}
+
+func NewMobileHandler(mobileSummaryStore database.MobileSummaryStore, scheduleStore database.ScheduleStore, logger *slog.Logger) *MobileHandler {
+	return &MobileHandler{
+		MobileSummaryStore: mobileSummaryStore,
+		ScheduleStore:      scheduleStore,
+		Logger:             logger,
+	}
+}
+
+// MobileShift is a shift of the day, the employee by first name and initial
+type MobileShift struct {
+	Name  string `json:"name"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Type  string `json:"type"`
+	OnNow bool   `json:"on_now"`
+}
+
+// ParseMobileFields reads ?fields=, a comma separated list of sections ("schedule") and fields of their
+// entries ("schedule.name"). It returns the selected sections with their fields, nil fields keeping all of
+// them. An empty list selects everything.
+func ParseMobileFields(raw string) (map[string][]string, error) {
+	selected := map[string][]string{}
+	if strings.TrimSpace(raw) == "" {
+		for section := range mobileSummaryFields {
+			selected[section] = nil
+		}
+		return selected, nil
+	}
+
+	whole := map[string]bool{}
+	for _, token := range strings.Split(raw, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		section, field, nested := strings.Cut(token, ".")
+		fields, ok := mobileSummaryFields[section]
+		if !ok {
+			return nil, fmt.Errorf("unknown section %q", section)
+		}
+		if !nested {
+			whole[section] = true
+			selected[section] = nil
+			continue
+		}
+		if !slices.Contains(fields, field) {
+			return nil, fmt.Errorf("unknown field %q of %s", field, section)
+		}
+		if !whole[section] && !slices.Contains(selected[section], field) {
+			selected[section] = append(selected[section], field)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no fields selected")
+	}
+	return selected, nil
+}
+
+// trimFields keeps only the given fields of an object, or of every object of a list, nil keeping them all
+func trimFields(value any, fields []string) (any, error) {
+	if fields == nil {
+		return value, nil
+	}
+	content, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	pick := func(entry map[string]any) map[string]any {
+		trimmed := make(map[string]any, len(fields))
+		for _, field := range fields {
+			if v, ok := entry[field]; ok {
+				trimmed[field] = v
+			}
+		}
+		return trimmed
+	}
+	if strings.HasPrefix(string(content), "[") {
+		var entries []map[string]any
+		if err := json.Unmarshal(content, &entries); err != nil {
+			return nil, err
+		}
+		result := make([]map[string]any, len(entries))
+		for i, entry := range entries {
+			result[i] = pick(entry)
+		}
+		return result, nil
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(content, &entry); err != nil {
+		return nil, err
+	}
+	return pick(entry), nil
+}
+
+// GetMobileSummaryHandler returns the schedule of the day, the alerts of the day and the counts of what
+// waits for approval. Only the sections asked for in ?fields= are read. The summary carries an ETag so
+// the app is answered with 304 when nothing changed.
+func (mh *MobileHandler) GetMobileSummaryHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access the mobile summary"})
+		return
+	}
+
+	selected, err := ParseMobileFields(c.Query("fields"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid fields: " + err.Error()})
+		return
+	}
+
+	now := time.Now()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	sections := map[string]any{}
+
+	if _, ok := selected["schedule"]; ok {
+		// shifts of the day before may run past midnight
+		shifts, err := mh.ScheduleStore.GetShiftsFrom(user.OrganizationID, dayStart.AddDate(0, 0, -1))
+		if err != nil {
+			mh.Logger.Error("failed to get shifts for mobile summary", "error", err, "org_id", user.OrganizationID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve mobile summary"})
+			return
+		}
+		schedule := []MobileShift{}
+		for _, shift := range boardShifts(shifts, dayStart, now) {
+			schedule = append(schedule, MobileShift{
+				Name:  shift.EmployeeName,
+				Start: shift.StartTime,
+				End:   shift.EndTime,
+				Type:  shift.ShiftType,
+				OnNow: shift.OnNow,
+			})
+		}
+		sections["schedule"] = schedule
+	}
+
+	if _, ok := selected["alerts"]; ok {
+		alerts, err := mh.MobileSummaryStore.GetAlertsSince(user.OrganizationID, dayStart, mobileSummaryAlertLimit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve mobile summary"})
+			return
+		}
+		sections["alerts"] = alerts
+	}
+
+	if _, ok := selected["pending"]; ok {
+		pending, err := mh.MobileSummaryStore.GetPendingApprovals(user.OrganizationID, user.UserRole)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve mobile summary"})
+			return
+		}
+		sections["pending"] = pending
+	}
+
+	for section, value := range sections {
+		trimmed, err := trimFields(value, selected[section])
+		if err != nil {
+			mh.Logger.Error("failed to trim mobile summary", "error", err, "section", section)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve mobile summary"})
+			return
+		}
+		sections[section] = trimmed
+	}
+	sections["date"] = dayStart.Format(time.DateOnly)
+
+	// map keys are marshalled in order, so the same summary always has the same ETag
+	content, err := json.Marshal(sections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve mobile summary"})
+		return
+	}
+	sum := sha256.Sum256(content)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("Cache-Control", "private, no-cache")
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Mobile summary retrieved successfully",
+		"data":    sections,
+	})
+}
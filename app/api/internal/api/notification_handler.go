@@ -0,0 +1,93 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationSettingsHandler struct {
+	NotificationStore database.NotificationStore
+	Logger            *slog.Logger
+}
+
+func NewNotificationSettingsHandler(notificationStore database.NotificationStore, logger *slog.Logger) *NotificationSettingsHandler {
+	return &NotificationSettingsHandler{
+		NotificationStore: notificationStore,
+		Logger:            logger,
+	}
+}
+
+type UpdateNotificationSettingsRequest struct {
+	DigestMode string `json:"digest_mode" binding:"required,oneof=immediate hourly daily"`
+}
+
+// digestOrNotify queues the notification for the recipients receiving digests and returns the emails
+// that should be notified right away. When the settings cannot be read everyone is notified right away.
+func digestOrNotify(store database.NotificationStore, logger *slog.Logger, emails []string, dedupKey, summary string) []string {
+	if len(emails) == 0 {
+		return emails
+	}
+
+	recipients, err := store.GetDigestRecipients(emails)
+	if err != nil {
+		logger.Error("failed to get digest recipients", "error", err)
+		return emails
+	}
+
+	immediate := make([]string, 0, len(emails))
+	for _, email := range emails {
+		userID, ok := recipients[email]
+		if !ok {
+			immediate = append(immediate, email)
+			continue
+		}
+		if err := store.QueueNotification(userID, dedupKey, summary); err != nil {
+			logger.Error("failed to queue notification", "error", err, "user_id", userID)
+			immediate = append(immediate, email)
+		}
+	}
+	return immediate
+}
+
+// GetNotificationSettingsHandler returns how the current user receives notification emails
+func (nh *NotificationSettingsHandler) GetNotificationSettingsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	settings, err := nh.NotificationStore.GetSettings(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve notification settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification settings retrieved successfully", "data": settings})
+}
+
+// UpdateNotificationSettingsHandler switches the current user between an email per notification and
+// hourly or daily digests
+func (nh *NotificationSettingsHandler) UpdateNotificationSettingsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	var request UpdateNotificationSettingsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := nh.NotificationStore.SetDigestMode(user.ID, request.DigestMode); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification settings"})
+		return
+	}
+
+	nh.Logger.Info("notification settings updated", "user_id", user.ID, "digest_mode", request.DigestMode)
+	c.JSON(http.StatusOK, gin.H{"message": "Notification settings updated successfully", "data": database.NotificationSettings{DigestMode: request.DigestMode}})
+}
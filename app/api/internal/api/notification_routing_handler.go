@@ -0,0 +1,366 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// defaultRoutingRule applies when no rule of the organization matches the event
+var defaultRoutingRule = database.NotificationRoutingRule{
+	Channel:    database.RoutingChannelEmail,
+	Recipients: database.RoutingManagersAndAdmins,
+}
+
+// RoutingEvent is a notification about an employee to route. HoursBeforeShift, when nil, is the time
+// left at At before the next shift of the employee.
+type RoutingEvent struct {
+	Event            string
+	EmployeeID       uuid.UUID
+	At               time.Time
+	HoursBeforeShift *float64
+}
+
+// RoutingRecipient is a member the notification is sent to. Channel is the channel of the rule, except
+// for SMS recipients without a phone who are emailed.
+type RoutingRecipient struct {
+	UserID   uuid.UUID `json:"user_id"`
+	FullName string    `json:"full_name"`
+	Email    string    `json:"email"`
+	Phone    *string   `json:"phone,omitempty"`
+	Channel  string    `json:"channel"`
+}
+
+// NotificationRoute is where a notification goes. Rule is nil when no rule matched and the default
+// route applies.
+type NotificationRoute struct {
+	Rule             *database.NotificationRoutingRule `json:"rule"`
+	Channel          string                            `json:"channel"`
+	Recipients       string                            `json:"recipients"`
+	HoursBeforeShift *float64                          `json:"hours_before_shift"`
+	SendTo           []RoutingRecipient                `json:"send_to"`
+}
+
+// routingRuleMatches reports whether the rule applies to the event, hoursBeforeShift being nil when
+// the employee has no upcoming shift
+func routingRuleMatches(rule database.NotificationRoutingRule, event string, hoursBeforeShift *float64) bool {
+	if rule.Event != event {
+		return false
+	}
+	if rule.WithinHours == nil {
+		return true
+	}
+	return hoursBeforeShift != nil && *hoursBeforeShift < float64(*rule.WithinHours)
+}
+
+// matchRoutingRule returns the first of the ordered rules matching the event, nil when none does
+func matchRoutingRule(rules []database.NotificationRoutingRule, event string, hoursBeforeShift *float64) *database.NotificationRoutingRule {
+	for i := range rules {
+		if routingRuleMatches(rules[i], event, hoursBeforeShift) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// hoursBeforeNextShift is the time between at and the start of the next shift of the employee, nil
+// when the employee has no shift starting after at
+func hoursBeforeNextShift(shifts []database.Shift, employeeID uuid.UUID, at time.Time) *float64 {
+	var next *time.Time
+	for _, shift := range shifts {
+		if shift.EmployeeID != employeeID {
+			continue
+		}
+		period, ok := periodOfShift(shift)
+		if !ok || period.start.Before(at) {
+			continue
+		}
+		if next == nil || period.start.Before(*next) {
+			next = &period.start
+		}
+	}
+	if next == nil {
+		return nil
+	}
+	hours := next.Sub(at).Hours()
+	return &hours
+}
+
+// routeRecipients picks the members a rule sends to. When no manager has a shift covering at, the
+// notifications for the manager on duty go to every manager.
+func routeRecipients(users []*database.User, shifts []database.Shift, recipients string, at time.Time) []*database.User {
+	onDuty := map[uuid.UUID]bool{}
+	for _, shift := range shifts {
+		period, ok := periodOfShift(shift)
+		if ok && !period.start.After(at) && period.end.After(at) {
+			onDuty[shift.EmployeeID] = true
+		}
+	}
+
+	var managers, onDutyManagers, admins []*database.User
+	for _, user := range users {
+		switch user.UserRole {
+		case "manager":
+			managers = append(managers, user)
+			if onDuty[user.ID] {
+				onDutyManagers = append(onDutyManagers, user)
+			}
+		case "admin":
+			admins = append(admins, user)
+		}
+	}
+
+	switch recipients {
+	case database.RoutingOnDutyManager:
+		if len(onDutyManagers) > 0 {
+			return onDutyManagers
+		}
+		return managers
+	case database.RoutingManagers:
+		return managers
+	case database.RoutingAdmins:
+		return admins
+	default:
+		return append(managers, admins...)
+	}
+}
+
+// NotificationRouter dispatches the notifications of an organization along its routing rules
+type NotificationRouter struct {
+	RoutingStore  database.NotificationRoutingStore
+	UserStore     database.UserStore
+	ScheduleStore database.ScheduleStore
+	SMSService    service.SMSService
+	Logger        *slog.Logger
+}
+
+func NewNotificationRouter(routingStore database.NotificationRoutingStore, userStore database.UserStore, scheduleStore database.ScheduleStore, smsService service.SMSService, logger *slog.Logger) *NotificationRouter {
+	return &NotificationRouter{
+		RoutingStore:  routingStore,
+		UserStore:     userStore,
+		ScheduleStore: scheduleStore,
+		SMSService:    smsService,
+		Logger:        logger,
+	}
+}
+
+// Route evaluates the rules of the organization for the event and resolves who is notified and how,
+// without sending anything
+func (r *NotificationRouter) Route(orgID uuid.UUID, event RoutingEvent) (*NotificationRoute, error) {
+	rules, err := r.RoutingStore.GetRoutingRules(orgID)
+	if err != nil {
+		return nil, err
+	}
+	users, err := r.UserStore.GetUsersByOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+	// overnight shifts of the day before can still be on duty
+	from := time.Date(event.At.Year(), event.At.Month(), event.At.Day()-1, 0, 0, 0, 0, time.Local)
+	shifts, err := r.ScheduleStore.GetShiftsFrom(orgID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	route := &NotificationRoute{HoursBeforeShift: event.HoursBeforeShift, SendTo: []RoutingRecipient{}}
+	if route.HoursBeforeShift == nil {
+		route.HoursBeforeShift = hoursBeforeNextShift(shifts, event.EmployeeID, event.At)
+	}
+	rule := defaultRoutingRule
+	if matched := matchRoutingRule(rules, event.Event, route.HoursBeforeShift); matched != nil {
+		route.Rule = matched
+		rule = *matched
+	}
+	route.Channel = rule.Channel
+	route.Recipients = rule.Recipients
+
+	for _, user := range routeRecipients(users, shifts, rule.Recipients, event.At) {
+		recipient := RoutingRecipient{UserID: user.ID, FullName: user.FullName, Email: user.Email, Phone: user.Phone, Channel: rule.Channel}
+		if rule.Channel == database.RoutingChannelSMS && (user.Phone == nil || *user.Phone == "") {
+			recipient.Channel = database.RoutingChannelEmail
+		}
+		route.SendTo = append(route.SendTo, recipient)
+	}
+	return route, nil
+}
+
+// Dispatch texts the SMS recipients of the route and returns the emails of the recipients left to
+// email, those whose text could not be sent included
+func (r *NotificationRouter) Dispatch(route *NotificationRoute, message string) []string {
+	emails := []string{}
+	for _, recipient := range route.SendTo {
+		if recipient.Channel == database.RoutingChannelSMS {
+			err := r.SMSService.SendSMS(*recipient.Phone, message)
+			if err == nil {
+				continue
+			}
+			r.Logger.Error("failed to send notification SMS, emailing instead", "error", err, "user_id", recipient.UserID)
+		}
+		emails = append(emails, recipient.Email)
+	}
+	return emails
+}
+
+type NotificationRoutingHandler struct {
+	Router *NotificationRouter
+	Logger *slog.Logger
+}
+
+func NewNotificationRoutingHandler(router *NotificationRouter, logger *slog.Logger) *NotificationRoutingHandler {
+	return &NotificationRoutingHandler{
+		Router: router,
+		Logger: logger,
+	}
+}
+
+type CreateRoutingRuleRequest struct {
+	Event       string `json:"event" binding:"required,oneof=calloff holiday resign"`
+	WithinHours *int   `json:"within_hours" binding:"omitempty,min=1"`
+	Channel     string `json:"channel" binding:"required,oneof=email sms"`
+	Recipients  string `json:"recipients" binding:"required,oneof=on_duty_manager managers admins managers_and_admins"`
+	Position    int    `json:"position"`
+}
+
+// SimulateRoutingRequest is a sample event. The time before the shift is hours_before_shift when given,
+// otherwise the time from at (now by default) to the next shift of employee_id.
+type SimulateRoutingRequest struct {
+	Event            string     `json:"event" binding:"required,oneof=calloff holiday resign"`
+	EmployeeID       *uuid.UUID `json:"employee_id"`
+	At               *time.Time `json:"at"`
+	HoursBeforeShift *float64   `json:"hours_before_shift"`
+}
+
+// GetRoutingRulesHandler lists the notification routing rules of the organization in the order they are tried
+func (nh *NotificationRoutingHandler) GetRoutingRulesHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access routing rules"})
+		return
+	}
+
+	rules, err := nh.Router.RoutingStore.GetRoutingRules(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve routing rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Routing rules retrieved successfully", "data": rules})
+}
+
+// CreateRoutingRuleHandler adds a notification routing rule
+func (nh *NotificationRoutingHandler) CreateRoutingRuleHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change routing rules"})
+		return
+	}
+
+	var request CreateRoutingRuleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	createdBy := user.ID
+	rule := &database.NotificationRoutingRule{
+		Event:       request.Event,
+		WithinHours: request.WithinHours,
+		Channel:     request.Channel,
+		Recipients:  request.Recipients,
+		Position:    request.Position,
+		CreatedBy:   &createdBy,
+	}
+	if err := nh.Router.RoutingStore.CreateRoutingRule(user.OrganizationID, rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create routing rule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Routing rule created successfully", "data": rule})
+}
+
+// DeleteRoutingRuleHandler removes a notification routing rule
+func (nh *NotificationRoutingHandler) DeleteRoutingRuleHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change routing rules"})
+		return
+	}
+
+	ruleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	if err := nh.Router.RoutingStore.DeleteRoutingRule(user.OrganizationID, ruleID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Routing rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete routing rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Routing rule deleted successfully"})
+}
+
+// SimulateRoutingHandler routes a sample event through the rules of the organization and returns the
+// matched rule and who would be notified on which channel, without sending anything
+func (nh *NotificationRoutingHandler) SimulateRoutingHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can simulate routing"})
+		return
+	}
+
+	var request SimulateRoutingRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if request.EmployeeID == nil && request.HoursBeforeShift == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "employee_id or hours_before_shift is required"})
+		return
+	}
+
+	event := RoutingEvent{Event: request.Event, At: time.Now(), HoursBeforeShift: request.HoursBeforeShift}
+	if request.EmployeeID != nil {
+		event.EmployeeID = *request.EmployeeID
+	}
+	if request.At != nil {
+		event.At = request.At.Local()
+	}
+
+	route, err := nh.Router.Route(user.OrganizationID, event)
+	if err != nil {
+		nh.Logger.Error("failed to simulate notification routing", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to simulate routing"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Routing simulated successfully", "data": route})
+}
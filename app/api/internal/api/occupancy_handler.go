@@ -0,0 +1,197 @@
+package api
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type OccupancyHandler struct {
+	OccupancyStore     database.OccupancyStore
+	ExternalEventStore database.ExternalEventStore
+	Logger             *slog.Logger
+}
+
+func NewOccupancyHandler(occupancyStore database.OccupancyStore, externalEventStore database.ExternalEventStore, logger *slog.Logger) *OccupancyHandler {
+	return &OccupancyHandler{
+		OccupancyStore:     occupancyStore,
+		ExternalEventStore: externalEventStore,
+		Logger:             logger,
+	}
+}
+
+// TableOccupancy is how many guests sit at a table at one moment
+type TableOccupancy struct {
+	TableNo     int         `json:"table_no"`
+	Capacity    int         `json:"capacity"`
+	Seated      int         `json:"seated"`
+	Occupied    bool        `json:"occupied"`
+	OrderIDs    []uuid.UUID `json:"order_ids"`
+	SeatedSince *time.Time  `json:"seated_since"`
+	ExpectedEnd *time.Time  `json:"expected_end"`
+}
+
+// OccupancySnapshot is the occupancy of the whole floor at one moment
+type OccupancySnapshot struct {
+	Time               time.Time        `json:"time"`
+	SeatedGuests       int              `json:"seated_guests"`
+	TotalCapacity      int              `json:"total_capacity"`
+	OccupiedTables     int              `json:"occupied_tables"`
+	TotalTables        int              `json:"total_tables"`
+	UtilizationPercent float64          `json:"utilization_percent"`
+	Tables             []TableOccupancy `json:"tables"`
+}
+
+// occupancyAt computes the occupancy of every table at a moment from the seatings around it
+func occupancyAt(at time.Time, tables []database.DiningTable, seatings []database.TableSeating) OccupancySnapshot {
+	snapshot := OccupancySnapshot{Time: at, TotalTables: len(tables), Tables: make([]TableOccupancy, 0, len(tables))}
+
+	index := make(map[int]int, len(tables))
+	for _, table := range tables {
+		index[table.TableNo] = len(snapshot.Tables)
+		snapshot.TotalCapacity += table.Capacity
+		snapshot.Tables = append(snapshot.Tables, TableOccupancy{TableNo: table.TableNo, Capacity: table.Capacity, OrderIDs: []uuid.UUID{}})
+	}
+
+	for _, seating := range seatings {
+		// A party leaving at 12:00 no longer occupies the table at 12:00
+		if seating.StartTime.After(at) || !seating.EndTime.After(at) {
+			continue
+		}
+		i, ok := index[seating.TableNo]
+		if !ok {
+			continue
+		}
+		table := &snapshot.Tables[i]
+		table.Seated += seating.NumberOfPeople
+		table.OrderIDs = append(table.OrderIDs, seating.OrderID)
+		if table.SeatedSince == nil || seating.StartTime.Before(*table.SeatedSince) {
+			start := seating.StartTime
+			table.SeatedSince = &start
+		}
+		if table.ExpectedEnd == nil || seating.EndTime.After(*table.ExpectedEnd) {
+			end := seating.EndTime
+			table.ExpectedEnd = &end
+		}
+		snapshot.SeatedGuests += seating.NumberOfPeople
+	}
+
+	for i := range snapshot.Tables {
+		if snapshot.Tables[i].Seated > 0 {
+			snapshot.Tables[i].Occupied = true
+			snapshot.OccupiedTables++
+		}
+	}
+	if snapshot.TotalCapacity > 0 {
+		snapshot.UtilizationPercent = math.Round(float64(snapshot.SeatedGuests)/float64(snapshot.TotalCapacity)*10000) / 100
+	}
+	return snapshot
+}
+
+// GetCurrentOccupancyHandler returns the guests seated right now, per table
+func (oh *OccupancyHandler) GetCurrentOccupancyHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	now := time.Now()
+	tables, err := oh.OccupancyStore.GetTables(user.OrganizationID)
+	if err != nil {
+		oh.Logger.Error("failed to get tables", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve occupancy"})
+		return
+	}
+	seatings, err := oh.OccupancyStore.GetSeatingsBetween(user.OrganizationID, now, now)
+	if err != nil {
+		oh.Logger.Error("failed to get table seatings", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve occupancy"})
+		return
+	}
+
+	// Hosts poll this endpoint, intermediaries must not serve stale floors
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Current occupancy retrieved successfully",
+		"data":    occupancyAt(now, tables, seatings),
+	})
+}
+
+// GetOccupancyTimelineHandler returns the occupancy of a day at a fixed interval, per table, with markers
+// for the external events of that day. date defaults to today and interval (minutes) to 15.
+func (oh *OccupancyHandler) GetOccupancyTimelineHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	day := time.Now()
+	if value := c.Query("date"); value != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+			return
+		}
+		day = parsed
+	}
+	dayStart := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	interval := 15
+	if value := c.Query("interval"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 5 || parsed > 240 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid interval, expected minutes between 5 and 240"})
+			return
+		}
+		interval = parsed
+	}
+
+	tables, err := oh.OccupancyStore.GetTables(user.OrganizationID)
+	if err != nil {
+		oh.Logger.Error("failed to get tables", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve occupancy timeline"})
+		return
+	}
+	seatings, err := oh.OccupancyStore.GetSeatingsBetween(user.OrganizationID, dayStart, dayEnd)
+	if err != nil {
+		oh.Logger.Error("failed to get table seatings", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve occupancy timeline"})
+		return
+	}
+
+	events, err := oh.ExternalEventStore.GetEventsBetween(user.OrganizationID, dayStart, dayEnd)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve occupancy timeline"})
+		return
+	}
+
+	timeline := []OccupancySnapshot{}
+	peak := OccupancySnapshot{Time: dayStart}
+	for at := dayStart; at.Before(dayEnd); at = at.Add(time.Duration(interval) * time.Minute) {
+		snapshot := occupancyAt(at, tables, seatings)
+		if snapshot.SeatedGuests > peak.SeatedGuests {
+			peak = snapshot
+		}
+		timeline = append(timeline, snapshot)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Occupancy timeline retrieved successfully",
+		"data": gin.H{
+			"date":             dayStart.Format(time.DateOnly),
+			"interval_minutes": interval,
+			"peak_time":        peak.Time,
+			"peak_guests":      peak.SeatedGuests,
+			"timeline":         timeline,
+			"events":           EventMarkersBetween(events, dayStart, dayEnd),
+		},
+	})
+}
@@ -17,6 +17,9 @@ type OfferHandler struct {
 	UserStore    database.UserStore
 	OfferStore   database.OfferStore
 	OrgStore     database.OrgStore
+
+	// Webhooks receives the accepted offers as shift swaps, nil sends none
+	Webhooks *service.WebhookService
 }
 
 func NewOfferHandler(userStore database.UserStore, orgStore database.OrgStore, offerStore database.OfferStore, emailSvc service.EmailService, logger *slog.Logger) *OfferHandler {
@@ -127,6 +130,12 @@ func (oh *OfferHandler) AcceptOfferHandler(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept offer"})
 		return
 	}
+	oh.Webhooks.Publish(user.OrganizationID, database.WebhookShiftSwapped, gin.H{
+		"offer_id":     offer.ID,
+		"employee_id":  user.ID,
+		"start_time":   offer.StartTime,
+		"shift_length": offer.ShiftLength,
+	})
 	
 	//TODO: Send update schedule swap in start_time 
 
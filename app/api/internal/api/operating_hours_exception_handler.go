@@ -0,0 +1,211 @@
+package api
+
+import (
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// How far ahead closed days are listed when no to is given
+const defaultExceptionWindowDays = 365
+
+type OperatingHoursExceptionHandler struct {
+	OperatingHoursExceptionStore database.OperatingHoursExceptionStore
+	ScheduleStore                database.ScheduleStore
+	EmailService                 service.EmailService
+	Logger                       *slog.Logger
+}
+
+func NewOperatingHoursExceptionHandler(operatingHoursExceptionStore database.OperatingHoursExceptionStore, scheduleStore database.ScheduleStore,
+	emailService service.EmailService, logger *slog.Logger) *OperatingHoursExceptionHandler {
+	return &OperatingHoursExceptionHandler{
+		OperatingHoursExceptionStore: operatingHoursExceptionStore,
+		ScheduleStore:                scheduleStore,
+		EmailService:                 emailService,
+		Logger:                       logger,
+	}
+}
+
+type CloseDayRequest struct {
+	Reason *string `json:"reason" binding:"omitempty,max=255"`
+}
+
+// closedDays returns the closed days of the organization among the given number of days from the
+// start day, keyed by their YYYY-MM-DD date
+func closedDays(store database.OperatingHoursExceptionStore, orgID uuid.UUID, from time.Time, days int) (map[string]bool, error) {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	exceptions, err := store.GetExceptionsBetween(orgID, from, from.AddDate(0, 0, days-1))
+	if err != nil {
+		return nil, err
+	}
+
+	closed := make(map[string]bool, len(exceptions))
+	for _, exception := range exceptions {
+		closed[exception.Date.Format(time.DateOnly)] = true
+	}
+	return closed, nil
+}
+
+// GetOperatingHoursExceptionsHandler lists the closed days between from (default today) and to
+// (default a year later)
+func (eh *OperatingHoursExceptionHandler) GetOperatingHoursExceptionsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access rules"})
+		return
+	}
+
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must use the YYYY-MM-DD format"})
+			return
+		}
+		from = parsed
+	}
+	to := from.AddDate(0, 0, defaultExceptionWindowDays)
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must use the YYYY-MM-DD format"})
+			return
+		}
+		to = parsed
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return
+	}
+
+	exceptions, err := eh.OperatingHoursExceptionStore.GetExceptionsBetween(user.OrganizationID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve closed days"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Closed days retrieved successfully", "data": exceptions})
+}
+
+// CloseDayHandler closes the organization on the :date day. The shifts already scheduled that day are
+// cancelled and their employees notified, and later schedule and demand generations skip the day.
+func (eh *OperatingHoursExceptionHandler) CloseDayHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change operating hours"})
+		return
+	}
+
+	date, err := time.Parse(time.DateOnly, c.Param("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date must use the YYYY-MM-DD format"})
+		return
+	}
+	now := time.Now()
+	if date.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Past days cannot be closed"})
+		return
+	}
+
+	// The body is optional
+	var request CloseDayRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if request.Reason != nil {
+		reason := strings.TrimSpace(*request.Reason)
+		request.Reason = &reason
+		if reason == "" {
+			request.Reason = nil
+		}
+	}
+
+	createdBy := user.ID
+	exception := &database.OperatingHoursException{Date: date, Reason: request.Reason, CreatedBy: &createdBy}
+	if err := eh.OperatingHoursExceptionStore.UpsertException(user.OrganizationID, exception); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to close the day"})
+		return
+	}
+
+	summary, err := eh.ScheduleStore.ClearSchedule(user.OrganizationID, date, date, user.ID)
+	if err != nil {
+		eh.Logger.Error("failed to cancel shifts of closed day", "error", err, "org_id", user.OrganizationID, "date", date)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "The day was closed but its shifts could not be cancelled, please try again"})
+		return
+	}
+
+	// Notification failures do not reopen the day
+	reason := ""
+	if exception.Reason != nil {
+		reason = *exception.Reason
+	}
+	notified := 0
+	for _, employee := range summary.Employees {
+		if err := eh.EmailService.SendClosureEmail(employee.Email, employee.EmployeeName, date.Format(time.DateOnly), reason); err != nil {
+			eh.Logger.Error("failed to send closure email", "error", err, "employee_id", employee.EmployeeID)
+			continue
+		}
+		notified++
+	}
+
+	eh.Logger.Info("day closed", "org_id", user.OrganizationID, "user_id", user.ID, "date", date, "cancelled_shifts", summary.Shifts, "notified", notified)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Day closed successfully",
+		"data": gin.H{
+			"exception":          exception,
+			"cancelled":          summary,
+			"notified_employees": notified,
+		},
+	})
+}
+
+// ReopenDayHandler removes the closure of the :date day. Cancelled shifts are not restored, the
+// schedule has to be generated again.
+func (eh *OperatingHoursExceptionHandler) ReopenDayHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change operating hours"})
+		return
+	}
+
+	date, err := time.Parse(time.DateOnly, c.Param("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date must use the YYYY-MM-DD format"})
+		return
+	}
+
+	if err := eh.OperatingHoursExceptionStore.DeleteException(user.OrganizationID, date); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "The day is not closed"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reopen the day"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Day reopened successfully"})
+}
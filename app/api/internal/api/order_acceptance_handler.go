@@ -0,0 +1,97 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// Order auto-close defaults, see service.EvaluateOrderLoad
+const (
+	defaultOrdersPerStaffHour    = 10
+	defaultAutoCloseLoadPercent  = 150
+	defaultAutoReopenLoadPercent = 100
+)
+
+// Days of order acceptance changes listed when no period is given
+const orderAcceptanceHistoryDays = 7
+
+type OrderAcceptanceHandler struct {
+	OrderAcceptanceStore database.OrderAcceptanceStore
+	Logger               *slog.Logger
+}
+
+func NewOrderAcceptanceHandler(orderAcceptanceStore database.OrderAcceptanceStore, logger *slog.Logger) *OrderAcceptanceHandler {
+	return &OrderAcceptanceHandler{
+		OrderAcceptanceStore: orderAcceptanceStore,
+		Logger:               logger,
+	}
+}
+
+// GetAutoCloseHistoryHandler lists the changes the auto-close controller made to accepting_orders in a
+// period (the last 7 days by default), newest first, with the load each one was made on
+func (oh *OrderAcceptanceHandler) GetAutoCloseHistoryHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access the order auto-close history"})
+		return
+	}
+
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+		// to is inclusive, the whole day counts
+		to = parsed.AddDate(0, 0, 1)
+	}
+	from := to.AddDate(0, 0, -orderAcceptanceHistoryDays)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+		from = parsed
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	changes, err := oh.OrderAcceptanceStore.ListChanges(user.OrganizationID, from, to)
+	if err != nil {
+		oh.Logger.Error("failed to list order acceptance changes", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve order auto-close history"})
+		return
+	}
+
+	closures := 0
+	for _, change := range changes {
+		if !change.AcceptingOrders {
+			closures++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Order auto-close history retrieved successfully",
+		"data": gin.H{
+			"from":       from.Format(time.DateOnly),
+			"to":         to.AddDate(0, 0, -1).Format(time.DateOnly),
+			"closures":   closures,
+			"reopenings": len(changes) - closures,
+			"changes":    changes,
+		},
+	})
+}
@@ -0,0 +1,223 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// orderImportBatch is the rows of an orders import stored at once
+const orderImportBatch = 1000
+
+// orderImportColumns are the columns an orders import must have, rating, channel and redemption_code
+// are optional
+var orderImportColumns = []string{"order_id", "user_id", "create_time", "order_type", "order_status", "total_amount", "discount_amount"}
+
+// orderImportOptionalColumns are read when the import has them
+var orderImportOptionalColumns = []string{"rating", "channel", "redemption_code"}
+
+// orderImport stores the rows of an orders import, from a CSV upload or a sheet sync, orderImportBatch at
+// a time. Rows breaking a validation rule or failing to parse are skipped and counted. Orders already
+// imported are handled along OnConflict, skipped by default. With database.OrderConflictFail the import
+// stops at the batch holding the first of them, and Conflict is set to its ID.
+type orderImport struct {
+	OrderStore    database.OrderStore
+	CampaignStore database.CampaignStore
+	Logger        *slog.Logger
+	OrgID         uuid.UUID
+	Validator     *ingestionValidator
+	OnConflict    string
+
+	Success, Updated, Errors, Duplicates, Redemptions, UnknownCodes int
+	Newest                                                          time.Time
+	Conflict                                                        *uuid.UUID
+
+	batch []database.Order
+	codes map[uuid.UUID]string // redemption codes of the batch
+}
+
+// Add parses a row, i counting the rows of the import from 0, and stores the batch once it is full
+func (imp *orderImport) Add(i int, row map[string]string) {
+	if imp.Conflict != nil || !imp.Validator.Check(i, row) {
+		return
+	}
+
+	orderID, err := uuid.Parse(row["order_id"])
+	if err != nil {
+		imp.Logger.Warn("invalid order_id in row", "row", i, "error", err)
+		imp.Errors++
+		return
+	}
+
+	// Parse user_id
+	userID, err := uuid.Parse(row["user_id"])
+	if err != nil {
+		imp.Logger.Warn("invalid user_id in row", "row", i, "error", err)
+		imp.Errors++
+		return
+	}
+
+	// Parse create_time
+	createTime, err := time.Parse(time.RFC3339, row["create_time"])
+	if err != nil {
+		// Try alternative format
+		createTime, err = time.Parse("2006-01-02 15:04:05", row["create_time"])
+		if err != nil {
+			imp.Logger.Warn("invalid create_time in row", "row", i, "error", err)
+			imp.Errors++
+			return
+		}
+	}
+
+	// Parse total_amount
+	totalAmount, err := strconv.ParseFloat(row["total_amount"], 64)
+	if err != nil {
+		imp.Logger.Warn("invalid total_amount in row", "row", i, "error", err)
+		imp.Errors++
+		return
+	}
+
+	// Parse discount_amount
+	discountAmount, err := strconv.ParseFloat(row["discount_amount"], 64)
+	if err != nil {
+		imp.Logger.Warn("invalid discount_amount in row", "row", i, "error", err)
+		imp.Errors++
+		return
+	}
+
+	// Parse rating (optional)
+	var rating *float64
+	if row["rating"] != "" {
+		r, err := strconv.ParseFloat(row["rating"], 64)
+		if err == nil {
+			rating = &r
+		}
+	}
+
+	// Parse channel (optional), direct when missing
+	channel := strings.TrimSpace(row["channel"])
+	if channel != "" && !database.IsOrderChannel(channel) {
+		imp.Logger.Warn("invalid channel in row", "row", i, "channel", channel)
+		imp.Errors++
+		return
+	}
+
+	imp.batch = append(imp.batch, database.Order{
+		OrderID:        orderID,
+		UserID:         userID,
+		OrganizationID: imp.OrgID,
+		CreateTime:     createTime,
+		OrderType:      row["order_type"],
+		OrderStatus:    row["order_status"],
+		TotalAmount:    &totalAmount,
+		DiscountAmount: &discountAmount,
+		Rating:         rating,
+		Channel:        channel,
+	})
+	// Link the order to the campaign whose code it used (optional column)
+	if code := strings.TrimSpace(row["redemption_code"]); code != "" {
+		if imp.codes == nil {
+			imp.codes = make(map[uuid.UUID]string)
+		}
+		imp.codes[orderID] = code
+	}
+	if len(imp.batch) == orderImportBatch {
+		imp.Flush()
+	}
+}
+
+// Flush stores the batch and records the redemption codes of the orders stored. Orders already imported
+// are counted as duplicates when skipped and as updated when overwritten. When the batch fails its orders
+// are stored one at a time, so a bad row does not take the rest of the batch with it.
+func (imp *orderImport) Flush() {
+	batch, codes := imp.batch, imp.codes
+	imp.batch, imp.codes = nil, nil
+	if len(batch) == 0 || imp.Conflict != nil {
+		return
+	}
+
+	onConflict := imp.OnConflict
+	if onConflict == "" {
+		onConflict = database.OrderConflictSkip
+	}
+
+	var conflict *database.OrderConflictError
+	inserted, updated, err := imp.OrderStore.StoreOrdersBulk(imp.OrgID, batch, onConflict)
+	switch {
+	case errors.As(err, &conflict):
+		imp.Logger.Warn("order already imported, stopping the import", "order_id", conflict.OrderID)
+		imp.Conflict = &conflict.OrderID
+		return
+	case err != nil:
+		imp.Logger.Warn("failed to store order batch, storing orders one at a time", "error", err, "count", len(batch))
+		inserted, updated = inserted[:0], updated[:0]
+		for i := range batch {
+			orderInserted, orderUpdated, err := imp.OrderStore.StoreOrdersBulk(imp.OrgID, batch[i:i+1], onConflict)
+			if errors.As(err, &conflict) {
+				imp.Logger.Warn("order already imported, stopping the import", "order_id", conflict.OrderID)
+				imp.Conflict = &conflict.OrderID
+				break
+			}
+			if err != nil {
+				imp.Logger.Error("failed to store order", "order_id", batch[i].OrderID, "error", err)
+				imp.Errors++
+				continue
+			}
+			if len(orderInserted)+len(orderUpdated) == 0 {
+				imp.Duplicates++
+			}
+			inserted = append(inserted, orderInserted...)
+			updated = append(updated, orderUpdated...)
+		}
+	default:
+		imp.Duplicates += len(batch) - len(inserted) - len(updated)
+	}
+
+	stored := make(map[uuid.UUID]bool, len(inserted)+len(updated))
+	for _, id := range updated {
+		stored[id] = false
+	}
+	for _, id := range inserted {
+		stored[id] = true
+	}
+	for i, order := range batch {
+		wasInserted, ok := stored[order.OrderID]
+		if !ok {
+			continue
+		}
+		// an order repeated in the batch was stored once, overwritten with its last row
+		if onConflict == database.OrderConflictOverwrite && slices.ContainsFunc(batch[i+1:], func(o database.Order) bool { return o.OrderID == order.OrderID }) {
+			continue
+		}
+		delete(stored, order.OrderID)
+		if wasInserted {
+			imp.Success++
+		} else {
+			imp.Updated++
+		}
+		if order.CreateTime.After(imp.Newest) {
+			imp.Newest = order.CreateTime
+		}
+
+		code, ok := codes[order.OrderID]
+		if !ok {
+			continue
+		}
+		err := imp.CampaignStore.RecordRedemption(imp.OrgID, code, order.OrderID, order.CreateTime)
+		switch {
+		case err == nil:
+			imp.Redemptions++
+		case errors.Is(err, sql.ErrNoRows):
+			imp.UnknownCodes++
+		default:
+			imp.Logger.Error("failed to record redemption", "order_id", order.OrderID, "error", err)
+		}
+	}
+}
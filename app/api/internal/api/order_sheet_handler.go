@@ -0,0 +1,379 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultOrderSheetRuns = 20
+	maxOrderSheetRuns     = 100
+)
+
+// OrderSheetHandler connects the Google Sheets organizations keep their order logs in. Their new rows are
+// imported every ORDER_SHEET_SYNC_INTERVAL (15m) like an orders CSV upload, each sync is logged.
+type OrderSheetHandler struct {
+	SheetStore         database.OrderSheetStore
+	OrderStore         database.OrderStore
+	CampaignStore      database.CampaignStore
+	IngestionRuleStore database.IngestionRuleStore
+	StatusStore        database.StatusStore
+	Logger             *slog.Logger
+	// Syncer reads the sheets, set once it is created as it imports through ApplySheetRows
+	Syncer *service.OrderSheetSyncer
+}
+
+func NewOrderSheetHandler(sheetStore database.OrderSheetStore, orderStore database.OrderStore, campaignStore database.CampaignStore, ingestionRuleStore database.IngestionRuleStore, statusStore database.StatusStore, logger *slog.Logger) *OrderSheetHandler {
+	return &OrderSheetHandler{
+		SheetStore:         sheetStore,
+		OrderStore:         orderStore,
+		CampaignStore:      campaignStore,
+		IngestionRuleStore: ingestionRuleStore,
+		StatusStore:        statusStore,
+		Logger:             logger,
+	}
+}
+
+// OrderSheetRequest connects a sheet. column_mapping maps the columns of an orders CSV to the headers of
+// the sheet. credentials is the JSON key of a service account the sheet is shared with, left out for sheets
+// shared by link; on update it is kept when left out and removed when empty.
+type OrderSheetRequest struct {
+	Name          string            `json:"name" binding:"required,max=100"`
+	SheetURL      string            `json:"sheet_url" binding:"required"`
+	Credentials   *string           `json:"credentials"`
+	ColumnMapping map[string]string `json:"column_mapping"`
+	Enabled       *bool             `json:"enabled"`
+}
+
+// OrderSheetResponse is a sheet with the service account it must be shared with, if it has one
+type OrderSheetResponse struct {
+	database.OrderSheetSync
+	ServiceAccount string `json:"service_account,omitempty"`
+}
+
+func orderSheetResponse(sheet database.OrderSheetSync) OrderSheetResponse {
+	response := OrderSheetResponse{OrderSheetSync: sheet}
+	if sheet.Credentials != nil {
+		response.ServiceAccount, _ = service.ParseSheetCredentials(*sheet.Credentials)
+	}
+	return response
+}
+
+// bindOrderSheet reads and checks a sheet request into sheet, writing a 400 response when it is invalid
+func bindOrderSheet(c *gin.Context, sheet *database.OrderSheetSync) bool {
+	var req OrderSheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	req.SheetURL = strings.TrimSpace(req.SheetURL)
+	if _, err := service.ParseSheetURL(req.SheetURL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	mapping := make(map[string]string, len(req.ColumnMapping))
+	for column, header := range req.ColumnMapping {
+		if !isOrderImportColumn(column) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown column in column_mapping: " + column})
+			return false
+		}
+		if header = strings.TrimSpace(header); header != "" {
+			mapping[column] = header
+		}
+	}
+
+	if req.Credentials != nil {
+		sheet.Credentials = nil
+		if credentials := strings.TrimSpace(*req.Credentials); credentials != "" {
+			if _, err := service.ParseSheetCredentials(credentials); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return false
+			}
+			sheet.Credentials = &credentials
+		}
+	}
+	sheet.Name = strings.TrimSpace(req.Name)
+	sheet.SheetURL = req.SheetURL
+	sheet.ColumnMapping = mapping
+	if req.Enabled != nil {
+		sheet.Enabled = *req.Enabled
+	}
+	return true
+}
+
+func isOrderImportColumn(column string) bool {
+	for _, c := range orderImportColumns {
+		if c == column {
+			return true
+		}
+	}
+	for _, c := range orderImportOptionalColumns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// orderSheet returns the sheet of the :id parameter, writing the error response when there is none
+func (h *OrderSheetHandler) orderSheet(c *gin.Context, orgID uuid.UUID) *database.OrderSheetSync {
+	sheetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order sheet ID"})
+		return nil
+	}
+	sheet, err := h.SheetStore.GetSync(orgID, sheetID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order sheet not found"})
+			return nil
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve order sheet"})
+		return nil
+	}
+	return sheet
+}
+
+// GetOrderSheetsHandler lists the order sheets of the organization
+func (h *OrderSheetHandler) GetOrderSheetsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can view order sheets"})
+		return
+	}
+
+	sheets, err := h.SheetStore.ListSyncs(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve order sheets"})
+		return
+	}
+
+	response := make([]OrderSheetResponse, 0, len(sheets))
+	for _, sheet := range sheets {
+		response = append(response, orderSheetResponse(sheet))
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Order sheets retrieved successfully", "data": response})
+}
+
+// CreateOrderSheetHandler connects a sheet, its rows are imported from the first at the next sync
+func (h *OrderSheetHandler) CreateOrderSheetHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can connect order sheets"})
+		return
+	}
+
+	sheet := &database.OrderSheetSync{Enabled: true}
+	if !bindOrderSheet(c, sheet) {
+		return
+	}
+	if err := h.SheetStore.CreateSync(user.OrganizationID, sheet); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to connect order sheet"})
+		return
+	}
+
+	h.Logger.Info("order sheet connected", "organization_id", user.OrganizationID, "sync_id", sheet.ID, "by", user.ID, "service_account", sheet.Credentials != nil)
+	c.JSON(http.StatusCreated, gin.H{"message": "Order sheet connected successfully", "data": orderSheetResponse(*sheet)})
+}
+
+// UpdateOrderSheetHandler changes the settings of a sheet. A sheet pointed at another URL is imported
+// again from its first row.
+func (h *OrderSheetHandler) UpdateOrderSheetHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change order sheets"})
+		return
+	}
+
+	sheet := h.orderSheet(c, user.OrganizationID)
+	if sheet == nil {
+		return
+	}
+	if !bindOrderSheet(c, sheet) {
+		return
+	}
+	if err := h.SheetStore.UpdateSync(user.OrganizationID, sheet); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order sheet not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update order sheet"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Order sheet updated successfully", "data": orderSheetResponse(*sheet)})
+}
+
+// DeleteOrderSheetHandler disconnects a sheet with its sync logs, the orders imported from it are kept
+func (h *OrderSheetHandler) DeleteOrderSheetHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can disconnect order sheets"})
+		return
+	}
+
+	sheetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order sheet ID"})
+		return
+	}
+	if err := h.SheetStore.DeleteSync(user.OrganizationID, sheetID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order sheet not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disconnect order sheet"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Order sheet disconnected successfully"})
+}
+
+// SyncOrderSheetHandler imports the new rows of a sheet now rather than at the next sync
+func (h *OrderSheetHandler) SyncOrderSheetHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can sync order sheets"})
+		return
+	}
+
+	sheet := h.orderSheet(c, user.OrganizationID)
+	if sheet == nil {
+		return
+	}
+
+	run := h.Syncer.Sync(c.Request.Context(), *sheet)
+	if run.Status != database.OrderSheetRunSuccess {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Order sheet sync failed: " + *run.Error, "data": run})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Order sheet synced successfully", "data": run})
+}
+
+// GetOrderSheetRunsHandler lists the latest syncs of a sheet with their counts and errors, ?limit= of
+// them (20 by default, at most 100)
+func (h *OrderSheetHandler) GetOrderSheetRunsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can view order sheets"})
+		return
+	}
+
+	limit := defaultOrderSheetRuns
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxOrderSheetRuns {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxOrderSheetRuns)})
+			return
+		}
+		limit = parsed
+	}
+
+	sheet := h.orderSheet(c, user.OrganizationID)
+	if sheet == nil {
+		return
+	}
+	runs, err := h.SheetStore.ListRuns(user.OrganizationID, sheet.ID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve order sheet syncs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Order sheet syncs retrieved successfully", "data": runs})
+}
+
+// ApplySheetRows imports the rows read from a sheet as the orders CSV upload does, the columns named by
+// the column mapping of the sheet. Headers are matched ignoring case and surrounding spaces, blank rows
+// are skipped. Violations of the validation rules are numbered by their row in the sheet.
+func (h *OrderSheetHandler) ApplySheetRows(sheet database.OrderSheetSync, headers []string, rows [][]string, firstRow int) database.OrderSheetRun {
+	run := database.OrderSheetRun{TotalRows: len(rows)}
+	fail := func(message string) database.OrderSheetRun {
+		run.Error = &message
+		return run
+	}
+
+	positions := make(map[string]int, len(headers))
+	for i, header := range headers {
+		positions[strings.ToLower(strings.TrimSpace(header))] = i
+	}
+	columns := make(map[string]int)
+	find := func(column string) (string, bool) {
+		header := column
+		if mapped, ok := sheet.ColumnMapping[column]; ok {
+			header = mapped
+		}
+		position, ok := positions[strings.ToLower(header)]
+		if ok {
+			columns[column] = position
+		}
+		return header, ok
+	}
+	for _, column := range orderImportColumns {
+		if header, ok := find(column); !ok {
+			return fail("Missing required column: " + header + " (" + column + ")")
+		}
+	}
+	for _, column := range orderImportOptionalColumns {
+		find(column)
+	}
+
+	validator, err := newIngestionValidator(h.IngestionRuleStore, sheet.OrganizationID, "orders")
+	if err != nil {
+		return fail("Failed to retrieve validation rules")
+	}
+
+	imp := &orderImport{OrderStore: h.OrderStore, CampaignStore: h.CampaignStore, Logger: h.Logger, OrgID: sheet.OrganizationID, Validator: validator}
+	for i, values := range rows {
+		row := make(map[string]string, len(columns))
+		blank := true
+		for column, position := range columns {
+			if position < len(values) {
+				row[column] = strings.TrimSpace(values[position])
+			}
+			if row[column] != "" {
+				blank = false
+			}
+		}
+		if blank {
+			continue
+		}
+		imp.Add(firstRow+i, row)
+	}
+	imp.Flush()
+	recordIngestion(h.StatusStore, h.Logger, sheet.OrganizationID, "order_sheet", imp.Newest, imp.Success)
+
+	run.SuccessCount = imp.Success
+	run.ErrorCount = imp.Errors
+	run.DuplicateCount = imp.Duplicates
+	run.RejectedCount = validator.Rejected
+	return run
+}
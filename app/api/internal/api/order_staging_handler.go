@@ -0,0 +1,339 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Most invalid and duplicate rows listed with a staged import, they are all counted
+const maxStagedImportProblems = 100
+
+// OrderImportHandler imports large order histories in two phases. The file is first loaded into the
+// staging area, where its rows are checked and deduplicated in bulk, then promoted into the orders table
+// in one transaction once reviewed, or discarded.
+type OrderImportHandler struct {
+	OrderImportStore   database.OrderImportStore
+	UploadCSVService   service.UploadService
+	IngestionRuleStore database.IngestionRuleStore
+	Logger             *slog.Logger
+}
+
+func NewOrderImportHandler(orderImportStore database.OrderImportStore, uploadService service.UploadService, ingestionRuleStore database.IngestionRuleStore, logger *slog.Logger) *OrderImportHandler {
+	return &OrderImportHandler{
+		OrderImportStore:   orderImportStore,
+		UploadCSVService:   uploadService,
+		IngestionRuleStore: ingestionRuleStore,
+		Logger:             logger,
+	}
+}
+
+// orderImportManager returns the user when they may import orders
+func orderImportManager(c *gin.Context) *database.User {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return nil
+	}
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can import orders"})
+		return nil
+	}
+	return user
+}
+
+// stagedOrderRow parses the i-th row of a file, counting from 0. A value that does not parse leaves the
+// row invalid, the other checks run on the whole file once it is staged.
+func stagedOrderRow(i int, row map[string]string) database.OrderImportRow {
+	staged := database.OrderImportRow{
+		Line:           i + 2,
+		OrderType:      row["order_type"],
+		OrderStatus:    row["order_status"],
+		Channel:        strings.TrimSpace(row["channel"]),
+		RedemptionCode: strings.TrimSpace(row["redemption_code"]),
+	}
+
+	var problems []string
+	if orderID, err := uuid.Parse(row["order_id"]); err == nil {
+		staged.OrderID = &orderID
+	} else {
+		problems = append(problems, "order_id must be a UUID")
+	}
+	if userID, err := uuid.Parse(row["user_id"]); err == nil {
+		staged.UserID = &userID
+	} else {
+		problems = append(problems, "user_id must be a UUID")
+	}
+	createTime, err := time.Parse(time.RFC3339, row["create_time"])
+	if err != nil {
+		createTime, err = time.Parse("2006-01-02 15:04:05", row["create_time"])
+	}
+	if err == nil {
+		staged.CreateTime = &createTime
+	} else {
+		problems = append(problems, "create_time must be RFC3339 or YYYY-MM-DD HH:MM:SS")
+	}
+	for _, field := range []struct {
+		name     string
+		value    **float64
+		required bool
+	}{
+		{"total_amount", &staged.TotalAmount, true},
+		{"discount_amount", &staged.DiscountAmount, true},
+		{"rating", &staged.Rating, false},
+	} {
+		text := strings.TrimSpace(row[field.name])
+		if text == "" && !field.required {
+			continue
+		}
+		number, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			problems = append(problems, field.name+" must be a number")
+			continue
+		}
+		*field.value = &number
+	}
+
+	if len(problems) > 0 {
+		message := strings.Join(problems, "; ")
+		staged.Status = database.OrderImportRowInvalid
+		staged.Error = &message
+	}
+	return staged
+}
+
+// StageOrderImportHandler loads an orders CSV into the staging area and checks its rows: values that do
+// not parse and rows breaking a validation rule of the organization as the file is read, then the
+// constraints of the orders table and duplicates in bulk. Nothing reaches the orders table until the
+// import is promoted. A file failing to read is discarded as a whole.
+func (h *OrderImportHandler) StageOrderImportHandler(c *gin.Context) {
+	user := orderImportManager(c)
+	if user == nil {
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		h.Logger.Error("failed to get file from request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to get file from request"})
+		return
+	}
+	defer file.Close()
+
+	stream, err := h.UploadCSVService.StreamCSV(file)
+	if err != nil {
+		h.Logger.Error("failed to read CSV", "error", err)
+		if err == service.ErrEmptyFile {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "CSV file is empty"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid CSV format"})
+		return
+	}
+
+	for _, col := range orderImportColumns {
+		found := false
+		for _, header := range stream.Headers {
+			if header == col {
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing required column: " + col})
+			return
+		}
+	}
+
+	validator, err := newIngestionValidator(h.IngestionRuleStore, user.OrganizationID, "orders")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve validation rules"})
+		return
+	}
+
+	orderImport := &database.OrderImport{OrganizationID: user.OrganizationID, UploadedBy: &user.ID, FileName: header.Filename}
+	if err := h.OrderImportStore.CreateOrderImport(orderImport); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create import"})
+		return
+	}
+	h.Logger.Info("staging orders import", "org_id", user.OrganizationID, "import_id", orderImport.ID)
+
+	// discard drops what was staged of a file that failed to load
+	discard := func() {
+		if err := h.OrderImportStore.DiscardOrderImport(user.OrganizationID, orderImport.ID); err != nil {
+			h.Logger.Error("failed to discard order import", "error", err, "import_id", orderImport.ID)
+		}
+	}
+
+	batch := make([]database.OrderImportRow, 0, orderImportBatch)
+	for i := 0; ; i++ {
+		row, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			discard()
+			status, response := http.StatusBadRequest, gin.H{"error": "Invalid CSV format at line " + strconv.Itoa(i+2)}
+			if err == service.ErrTooManyRows {
+				status, response = http.StatusRequestEntityTooLarge, gin.H{"error": "CSV file has too many rows", "hint": middleware.UploadSplitHint}
+			}
+			c.JSON(status, response)
+			return
+		}
+
+		staged := stagedOrderRow(i, row)
+		if staged.Error == nil {
+			if broken := validator.BrokenRules(row); len(broken) > 0 {
+				message := ruleMessage(broken[0])
+				staged.Status = database.OrderImportRowInvalid
+				staged.Error = &message
+			}
+		}
+		batch = append(batch, staged)
+		if len(batch) == orderImportBatch {
+			if err := h.OrderImportStore.StageOrderImportRows(orderImport.ID, batch); err != nil {
+				discard()
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to stage orders"})
+				return
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := h.OrderImportStore.StageOrderImportRows(orderImport.ID, batch); err != nil {
+		discard()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to stage orders"})
+		return
+	}
+
+	validated, err := h.OrderImportStore.ValidateOrderImport(user.OrganizationID, orderImport.ID)
+	if err != nil {
+		discard()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate orders"})
+		return
+	}
+	problems, err := h.OrderImportStore.GetOrderImportProblems(orderImport.ID, maxStagedImportProblems)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve import problems"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Orders staged successfully",
+		"data":     validated,
+		"problems": problems,
+	})
+}
+
+// GetOrderImportsHandler lists the staged, promoted and discarded imports of the organization
+func (h *OrderImportHandler) GetOrderImportsHandler(c *gin.Context) {
+	user := orderImportManager(c)
+	if user == nil {
+		return
+	}
+
+	imports, err := h.OrderImportStore.GetOrderImports(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve imports"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Imports retrieved successfully", "data": imports})
+}
+
+// GetOrderImportHandler returns an import with its first invalid and duplicate rows
+func (h *OrderImportHandler) GetOrderImportHandler(c *gin.Context) {
+	user := orderImportManager(c)
+	if user == nil {
+		return
+	}
+
+	importID, err := uuid.Parse(c.Param("import"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import ID"})
+		return
+	}
+
+	orderImport, err := h.OrderImportStore.GetOrderImport(user.OrganizationID, importID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Import not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve import"})
+		return
+	}
+	problems, err := h.OrderImportStore.GetOrderImportProblems(importID, maxStagedImportProblems)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve import problems"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Import retrieved successfully", "data": orderImport, "problems": problems})
+}
+
+// PromoteOrderImportHandler stores the valid rows of a staged import as orders, all in one transaction
+func (h *OrderImportHandler) PromoteOrderImportHandler(c *gin.Context) {
+	user := orderImportManager(c)
+	if user == nil {
+		return
+	}
+
+	importID, err := uuid.Parse(c.Param("import"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import ID"})
+		return
+	}
+
+	orderImport, err := h.OrderImportStore.PromoteOrderImport(user.OrganizationID, importID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Import not found"})
+		case errors.Is(err, database.ErrOrderImportNotStaged):
+			c.JSON(http.StatusConflict, gin.H{"error": "Import was already promoted or discarded"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to promote import"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Import promoted successfully", "data": orderImport})
+}
+
+// DiscardOrderImportHandler drops a staged import without storing any of its orders
+func (h *OrderImportHandler) DiscardOrderImportHandler(c *gin.Context) {
+	user := orderImportManager(c)
+	if user == nil {
+		return
+	}
+
+	importID, err := uuid.Parse(c.Param("import"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import ID"})
+		return
+	}
+
+	if err := h.OrderImportStore.DiscardOrderImport(user.OrganizationID, importID); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Import not found"})
+		case errors.Is(err, database.ErrOrderImportNotStaged):
+			c.JSON(http.StatusConflict, gin.H{"error": "Import was already promoted or discarded"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to discard import"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Import discarded successfully"})
+}
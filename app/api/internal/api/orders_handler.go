@@ -1,9 +1,17 @@
 package api
 
 import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/clockwise/clockwise/backend/internal/database"
@@ -14,20 +22,31 @@ import (
 )
 
 type OrderHandler struct {
-	OrderStore       database.OrderStore
-	UploadCSVService service.UploadService
-	Logger           *slog.Logger
+	OrderStore         database.OrderStore
+	CampaignStore      database.CampaignStore
+	UploadCSVService   service.UploadService
+	IngestionRuleStore database.IngestionRuleStore
+	StatusStore        database.StatusStore
+	CustomFieldStore   database.CustomFieldStore
+	RulesStore         database.RulesStore
+	Logger             *slog.Logger
 }
 
-func NewOrderHandler(orderStore database.OrderStore, uploadservice service.UploadService, Logger *slog.Logger) *OrderHandler {
+func NewOrderHandler(orderStore database.OrderStore, campaignStore database.CampaignStore, uploadservice service.UploadService, ingestionRuleStore database.IngestionRuleStore, statusStore database.StatusStore, customFieldStore database.CustomFieldStore, rulesStore database.RulesStore, Logger *slog.Logger) *OrderHandler {
 	return &OrderHandler{
-		OrderStore:       orderStore,
-		UploadCSVService: uploadservice,
-		Logger:           Logger,
+		OrderStore:         orderStore,
+		CampaignStore:      campaignStore,
+		UploadCSVService:   uploadservice,
+		IngestionRuleStore: ingestionRuleStore,
+		StatusStore:        statusStore,
+		CustomFieldStore:   customFieldStore,
+		RulesStore:         rulesStore,
+		Logger:             Logger,
 	}
 }
 
 // GetAllOrders godoc
+// Optional filters: ?from=&to= (dates, to inclusive), ?status=, ?type= and ?channel= (comma separated)
 func (oh *OrderHandler) GetAllOrders(c *gin.Context) {
 	user := middleware.ValidateOrgAccess(c)
 	if user == nil {
@@ -39,15 +58,37 @@ func (oh *OrderHandler) GetAllOrders(c *gin.Context) {
 		return
 	}
 
+	from, to, ok := queryDateRange(c)
+	if !ok {
+		return
+	}
+	filter := database.OrderFilter{
+		From:     from,
+		To:       to,
+		Statuses: queryList(c, "status"),
+		Types:    queryList(c, "type"),
+		Channels: queryList(c, "channel"),
+	}
+
 	oh.Logger.Info("getting all orders", "org_id", user.OrganizationID)
 
-	orders, err := oh.OrderStore.GetAllOrders(user.OrganizationID)
+	orders, err := oh.OrderStore.GetOrders(user.OrganizationID, filter)
 	if err != nil {
 		oh.Logger.Error("failed to get all orders", "error", err, "org_id", user.OrganizationID)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve orders"})
 		return
 	}
 
+	orders, ok = withCustomFields(c, oh.CustomFieldStore, oh.Logger, user.OrganizationID, database.CustomFieldOrder, orders,
+		func(o database.Order) uuid.UUID { return o.OrderID },
+		func(o database.Order, values map[string]any) database.Order {
+			o.CustomFields = values
+			return o
+		})
+	if !ok {
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Orders retrieved successfully",
 		"data":    orders,
@@ -120,6 +161,12 @@ func (oh *OrderHandler) GetOrdersInsights(c *gin.Context) {
 		return
 	}
 
+	// with a period the orders of the period are listed instead of the insights
+	if c.Query("from") != "" || c.Query("to") != "" {
+		oh.getOrdersInRange(c, user.OrganizationID)
+		return
+	}
+
 	oh.Logger.Info("getting orders insights", "org_id", user.OrganizationID)
 
 	insights, err := oh.OrderStore.GetOrdersInsights(user.OrganizationID)
@@ -135,6 +182,113 @@ func (oh *OrderHandler) GetOrdersInsights(c *gin.Context) {
 	})
 }
 
+// getOrdersInRange lists the orders from the start of from to the end of to (YYYY-MM-DD, both required),
+// so managers can look at any period such as a month-end or a holiday
+func (oh *OrderHandler) getOrdersInRange(c *gin.Context, orgID uuid.UUID) {
+	from, to, ok := queryDateRange(c)
+	if !ok {
+		return
+	}
+	if from == nil || to == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Both from and to are required, expected YYYY-MM-DD"})
+		return
+	}
+
+	orders, err := oh.OrderStore.GetOrdersInRange(orgID, *from, *to)
+	if err != nil {
+		oh.Logger.Error("failed to get orders in range", "error", err, "org_id", orgID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve orders"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Orders retrieved successfully",
+		"data":    orders,
+	})
+}
+
+// channelReportDays is the period of a channel report when no from date is given
+const channelReportDays = 30
+
+// GetChannelReport breaks the orders and net revenue of a period down per channel, as JSON or as a CSV
+// download with format=csv. from and to (YYYY-MM-DD, inclusive) default to the last 30 days.
+func (oh *OrderHandler) GetChannelReport(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access orders"})
+		return
+	}
+
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+		// to is inclusive, the whole day counts
+		to = parsed.AddDate(0, 0, 1)
+	}
+	from := to.AddDate(0, 0, -channelReportDays)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+		from = parsed
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format, expected json or csv"})
+		return
+	}
+
+	breakdown, err := oh.OrderStore.GetChannelBreakdown(user.OrganizationID, from, to)
+	if err != nil {
+		oh.Logger.Error("failed to get channel breakdown", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve channel report"})
+		return
+	}
+
+	fromDate, toDate := from.Format(time.DateOnly), to.AddDate(0, 0, -1).Format(time.DateOnly)
+	if format == "csv" {
+		var buf bytes.Buffer
+		writer := csv.NewWriter(&buf)
+		writer.Write([]string{"channel", "orders", "delivery_orders", "share_percent", "revenue", "discount", "average_order_value"})
+		for _, b := range breakdown {
+			writer.Write([]string{
+				b.Channel,
+				strconv.Itoa(b.Orders),
+				strconv.Itoa(b.DeliveryOrders),
+				strconv.FormatFloat(b.Share, 'f', 2, 64),
+				strconv.FormatFloat(b.Revenue, 'f', 2, 64),
+				strconv.FormatFloat(b.Discount, 'f', 2, 64),
+				strconv.FormatFloat(b.AverageOrderValue, 'f', 2, 64),
+			})
+		}
+		writer.Flush()
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="channels-%s-%s.csv"`, fromDate, toDate))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Channel report retrieved successfully",
+		"data":    gin.H{"from": fromDate, "to": toDate, "channels": breakdown},
+	})
+}
+
 // UploadAllPastOrdersCSV godoc
 func (oh *OrderHandler) UploadAllPastOrdersCSV(c *gin.Context) {
 	user := middleware.ValidateOrgAccess(c)
@@ -147,7 +301,14 @@ func (oh *OrderHandler) UploadAllPastOrdersCSV(c *gin.Context) {
 		return
 	}
 
-	oh.Logger.Info("uploading past orders CSV", "org_id", user.OrganizationID)
+	// Orders already imported are skipped unless asked otherwise, so re-uploading a file is safe
+	onConflict := c.DefaultQuery("on_conflict", database.OrderConflictSkip)
+	if onConflict != database.OrderConflictSkip && onConflict != database.OrderConflictOverwrite && onConflict != database.OrderConflictFail {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "on_conflict must be one of skip, overwrite or fail"})
+		return
+	}
+
+	oh.Logger.Info("uploading past orders CSV", "org_id", user.OrganizationID, "on_conflict", onConflict)
 
 	// Get the file from the request
 	file, _, err := c.Request.FormFile("file")
@@ -158,10 +319,10 @@ func (oh *OrderHandler) UploadAllPastOrdersCSV(c *gin.Context) {
 	}
 	defer file.Close()
 
-	// Parse the CSV file
-	csvData, err := oh.UploadCSVService.ParseCSV(file)
+	// Rows are read one at a time, history files are too large to hold in memory
+	stream, err := oh.UploadCSVService.StreamCSV(file)
 	if err != nil {
-		oh.Logger.Error("failed to parse CSV", "error", err)
+		oh.Logger.Error("failed to read CSV", "error", err)
 		if err == service.ErrEmptyFile {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "CSV file is empty"})
 			return
@@ -170,11 +331,10 @@ func (oh *OrderHandler) UploadAllPastOrdersCSV(c *gin.Context) {
 		return
 	}
 
-	// Expected columns: user_id, create_time, order_type, order_status, total_amount, discount_amount, rating
-	requiredColumns := []string{"order_id", "user_id", "create_time", "order_type", "order_status", "total_amount", "discount_amount"}
-	for _, col := range requiredColumns {
+	// Expected columns: user_id, create_time, order_type, order_status, total_amount, discount_amount, rating, channel
+	for _, col := range orderImportColumns {
 		found := false
-		for _, header := range csvData.Headers {
+		for _, header := range stream.Headers {
 			if header == col {
 				found = true
 				break
@@ -187,87 +347,66 @@ func (oh *OrderHandler) UploadAllPastOrdersCSV(c *gin.Context) {
 		}
 	}
 
-	// Store each order from CSV
-	var successCount, errorCount int
-	for i, row := range csvData.Rows {
-		orderID, err := uuid.Parse(row["order_id"])
-		if err != nil {
-			oh.Logger.Warn("invalid order_id in row", "row", i, "error", err)
-			errorCount++
-			continue
-		}
-
-		// Parse user_id
-		userID, err := uuid.Parse(row["user_id"])
-		if err != nil {
-			oh.Logger.Warn("invalid user_id in row", "row", i, "error", err)
-			errorCount++
-			continue
-		}
-
-		// Parse create_time
-		createTime, err := time.Parse(time.RFC3339, row["create_time"])
-		if err != nil {
-			// Try alternative format
-			createTime, err = time.Parse("2006-01-02 15:04:05", row["create_time"])
-			if err != nil {
-				oh.Logger.Warn("invalid create_time in row", "row", i, "error", err)
-				errorCount++
-				continue
-			}
-		}
+	// Rows breaking the organization's validation rules are rejected
+	validator, err := newIngestionValidator(oh.IngestionRuleStore, user.OrganizationID, "orders")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve validation rules"})
+		return
+	}
 
-		// Parse total_amount
-		totalAmount, err := strconv.ParseFloat(row["total_amount"], 64)
-		if err != nil {
-			oh.Logger.Warn("invalid total_amount in row", "row", i, "error", err)
-			errorCount++
-			continue
+	imp := &orderImport{OrderStore: oh.OrderStore, CampaignStore: oh.CampaignStore, Logger: oh.Logger, OrgID: user.OrganizationID,
+		Validator: validator, OnConflict: onConflict}
+	for i := 0; imp.Conflict == nil; i++ {
+		row, err := stream.Next()
+		if err == io.EOF {
+			break
 		}
-
-		// Parse discount_amount
-		discountAmount, err := strconv.ParseFloat(row["discount_amount"], 64)
 		if err != nil {
-			oh.Logger.Warn("invalid discount_amount in row", "row", i, "error", err)
-			errorCount++
-			continue
-		}
-
-		// Parse rating (optional)
-		var rating *float64
-		if row["rating"] != "" {
-			r, err := strconv.ParseFloat(row["rating"], 64)
-			if err == nil {
-				rating = &r
+			// The rows before the bad one are kept, the response tells how far the import got
+			imp.Flush()
+			recordIngestion(oh.StatusStore, oh.Logger, user.OrganizationID, "orders_csv", imp.Newest, imp.Success+imp.Updated)
+			status, message := http.StatusBadRequest, "Invalid CSV format at line "+strconv.Itoa(i+2)
+			response := gin.H{}
+			if err == service.ErrTooManyRows {
+				status, message = http.StatusRequestEntityTooLarge, "CSV file has too many rows"
+				response["hint"] = middleware.UploadSplitHint
 			}
+			response["error"] = message
+			response["success_count"] = imp.Success
+			response["updated_count"] = imp.Updated
+			response["error_count"] = imp.Errors
+			response["duplicate_count"] = imp.Duplicates
+			c.JSON(status, response)
+			return
 		}
-
-		order := &database.Order{
-			OrderID:        orderID,
-			UserID:         userID,
-			OrganizationID: user.OrganizationID,
-			CreateTime:     createTime,
-			OrderType:      row["order_type"],
-			OrderStatus:    row["order_status"],
-			TotalAmount:    &totalAmount,
-			DiscountAmount: &discountAmount,
-			Rating:         rating,
-		}
-
-		err = oh.OrderStore.StoreOrder(user.OrganizationID, order)
-		if err != nil {
-			oh.Logger.Error("failed to store order", "row", i, "error", err)
-			errorCount++
-			continue
-		}
-		successCount++
+		imp.Add(i, row)
+	}
+	imp.Flush()
+	recordIngestion(oh.StatusStore, oh.Logger, user.OrganizationID, "orders_csv", imp.Newest, imp.Success+imp.Updated)
+
+	if imp.Conflict != nil {
+		// The batches stored before the conflicting order are kept
+		c.JSON(http.StatusConflict, gin.H{
+			"error":           "Order " + imp.Conflict.String() + " was already imported",
+			"order_id":        imp.Conflict,
+			"success_count":   imp.Success,
+			"error_count":     imp.Errors,
+			"duplicate_count": imp.Duplicates,
+		})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":       "Orders CSV uploaded successfully",
-		"total_rows":    csvData.Total,
-		"success_count": successCount,
-		"error_count":   errorCount,
+		"message":                  "Orders CSV uploaded successfully",
+		"total_rows":               stream.Rows,
+		"success_count":            imp.Success,
+		"updated_count":            imp.Updated,
+		"error_count":              imp.Errors,
+		"duplicate_count":          imp.Duplicates,
+		"redemption_count":         imp.Redemptions,
+		"unknown_redemption_codes": imp.UnknownCodes,
+		"rejected_count":           validator.Rejected,
+		"violations":               validator.Violations,
 	})
 }
 
@@ -325,6 +464,10 @@ func (oh *OrderHandler) UploadOrderItemsCSV(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "CSV file is empty"})
 			return
 		}
+		if err == service.ErrTooManyRows {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "CSV file has too many rows", "hint": middleware.UploadSplitHint})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid CSV format"})
 		return
 	}
@@ -346,9 +489,22 @@ func (oh *OrderHandler) UploadOrderItemsCSV(c *gin.Context) {
 		}
 	}
 
-	// Store each order item link from CSV
-	var successCount, errorCount int
+	// Rows breaking the organization's validation rules are rejected
+	validator, err := newIngestionValidator(oh.IngestionRuleStore, user.OrganizationID, "order_items")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve validation rules"})
+		return
+	}
+
+	// Parse each order item link from CSV, the valid ones are stored together
+	var errorCount int
+	var links []database.OrderItemLink
+	var linkRows []int
 	for i, row := range csvData.Rows {
+		if !validator.Check(i, row) {
+			continue
+		}
+
 		// Parse order_id
 		orderID, err := uuid.Parse(row["order_id"])
 		if err != nil {
@@ -381,30 +537,41 @@ func (oh *OrderHandler) UploadOrderItemsCSV(c *gin.Context) {
 			continue
 		}
 
-		orderItem := &database.OrderItem{
-			ItemID:     itemID,
-			Quantity:   &quantity,
-			TotalPrice: &totalPrice,
-		}
+		links = append(links, database.OrderItemLink{
+			OrderID: orderID,
+			OrderItem: database.OrderItem{
+				ItemID:     itemID,
+				Quantity:   &quantity,
+				TotalPrice: &totalPrice,
+			},
+		})
+		linkRows = append(linkRows, i)
+	}
 
-		err = oh.OrderStore.StoreOrderItems(user.OrganizationID, orderID, orderItem)
-		if err != nil {
-			oh.Logger.Error("failed to store order item", "row", i, "error", err)
-			errorCount++
-			continue
-		}
-		successCount++
+	unknown, err := oh.OrderStore.StoreOrderItemsBulk(user.OrganizationID, links)
+	if err != nil {
+		oh.Logger.Error("failed to store order items", "error", err, "count", len(links))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store order items"})
+		return
 	}
+	for _, index := range unknown {
+		oh.Logger.Warn("order or item not found in row", "row", linkRows[index])
+	}
+	errorCount += len(unknown)
+	successCount := len(links) - len(unknown)
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":       "Order items CSV uploaded successfully",
-		"total_rows":    csvData.Total,
-		"success_count": successCount,
-		"error_count":   errorCount,
+		"message":        "Order items CSV uploaded successfully",
+		"total_rows":     csvData.Total,
+		"success_count":  successCount,
+		"error_count":    errorCount,
+		"rejected_count": validator.Rejected,
+		"violations":     validator.Violations,
 	})
 }
 
 // GetAllDeliveries godoc
+// Optional filters: ?from=&to= (dates, to inclusive), ?status= and ?driver_id= (comma separated)
 func (oh *OrderHandler) GetAllDeliveries(c *gin.Context) {
 	user := middleware.ValidateOrgAccess(c)
 	if user == nil {
@@ -416,9 +583,19 @@ func (oh *OrderHandler) GetAllDeliveries(c *gin.Context) {
 		return
 	}
 
+	from, to, ok := queryDateRange(c)
+	if !ok {
+		return
+	}
+	driverIDs, ok := queryIDs(c, "driver_id")
+	if !ok {
+		return
+	}
+	filter := database.DeliveryFilter{From: from, To: to, Statuses: queryList(c, "status"), DriverIDs: driverIDs}
+
 	oh.Logger.Info("getting all deliveries", "org_id", user.OrganizationID)
 
-	deliveries, err := oh.OrderStore.GetAllDeliveries(user.OrganizationID)
+	deliveries, err := oh.OrderStore.GetDeliveries(user.OrganizationID, filter)
 	if err != nil {
 		oh.Logger.Error("failed to get all deliveries", "error", err, "org_id", user.OrganizationID)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve deliveries"})
@@ -555,6 +732,10 @@ func (oh *OrderHandler) UploadAllPastDeliveriesCSV(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "CSV file is empty"})
 			return
 		}
+		if err == service.ErrTooManyRows {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "CSV file has too many rows", "hint": middleware.UploadSplitHint})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid CSV format"})
 		return
 	}
@@ -576,9 +757,21 @@ func (oh *OrderHandler) UploadAllPastDeliveriesCSV(c *gin.Context) {
 		}
 	}
 
+	// Rows breaking the organization's validation rules are rejected
+	validator, err := newIngestionValidator(oh.IngestionRuleStore, user.OrganizationID, "deliveries")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve validation rules"})
+		return
+	}
+
 	// Store each delivery from CSV
 	var successCount, errorCount int
+	var newest time.Time
 	for i, row := range csvData.Rows {
+		if !validator.Check(i, row) {
+			continue
+		}
+
 		// Parse order_id
 		orderID, err := uuid.Parse(row["order_id"])
 		if err != nil {
@@ -650,13 +843,19 @@ func (oh *OrderHandler) UploadAllPastDeliveriesCSV(c *gin.Context) {
 			continue
 		}
 		successCount++
+		if outForDeliveryTime.After(newest) {
+			newest = outForDeliveryTime
+		}
 	}
+	recordIngestion(oh.StatusStore, oh.Logger, user.OrganizationID, "deliveries_csv", newest, successCount)
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":       "Deliveries CSV uploaded successfully",
-		"total_rows":    csvData.Total,
-		"success_count": successCount,
-		"error_count":   errorCount,
+		"message":        "Deliveries CSV uploaded successfully",
+		"total_rows":     csvData.Total,
+		"success_count":  successCount,
+		"error_count":    errorCount,
+		"rejected_count": validator.Rejected,
+		"violations":     validator.Violations,
 	})
 }
 
@@ -718,6 +917,10 @@ func (oh *OrderHandler) UploadItemsCSV(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "CSV file is empty"})
 			return
 		}
+		if err == service.ErrTooManyRows {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "CSV file has too many rows", "hint": middleware.UploadSplitHint})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid CSV format"})
 		return
 	}
@@ -739,9 +942,20 @@ func (oh *OrderHandler) UploadItemsCSV(c *gin.Context) {
 		}
 	}
 
+	// Rows breaking the organization's validation rules are rejected
+	validator, err := newIngestionValidator(oh.IngestionRuleStore, user.OrganizationID, "items")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve validation rules"})
+		return
+	}
+
 	// Store each item from CSV
 	var successCount, errorCount int
 	for i, row := range csvData.Rows {
+		if !validator.Check(i, row) {
+			continue
+		}
+
 		itemID, err := uuid.Parse(row["item_id"])
 		if err != nil {
 			oh.Logger.Warn("invalid item_id in row", "row", i, "error", err)
@@ -782,14 +996,17 @@ func (oh *OrderHandler) UploadItemsCSV(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":       "Items CSV uploaded successfully",
-		"total_rows":    csvData.Total,
-		"success_count": successCount,
-		"error_count":   errorCount,
+		"message":        "Items CSV uploaded successfully",
+		"total_rows":     csvData.Total,
+		"success_count":  successCount,
+		"error_count":    errorCount,
+		"rejected_count": validator.Rejected,
+		"violations":     validator.Violations,
 	})
 }
 
-// GetAllItems godoc
+// GetAllItems lists the items of the organization, the discontinued ones included unless ?active=true.
+// ?active=false lists only the discontinued items.
 func (oh *OrderHandler) GetAllItems(c *gin.Context) {
 	user := middleware.ValidateOrgAccess(c)
 	if user == nil {
@@ -801,6 +1018,16 @@ func (oh *OrderHandler) GetAllItems(c *gin.Context) {
 		return
 	}
 
+	var active *bool
+	if raw := c.Query("active"); raw != "" {
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "active must be true or false"})
+			return
+		}
+		active = &value
+	}
+
 	oh.Logger.Info("getting all items", "org_id", user.OrganizationID)
 
 	items, err := oh.OrderStore.GetAllItems(user.OrganizationID)
@@ -809,9 +1036,712 @@ func (oh *OrderHandler) GetAllItems(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve items"})
 		return
 	}
+	if active != nil {
+		filtered := make([]database.Item, 0, len(items))
+		for _, item := range items {
+			if item.IsActive == *active {
+				filtered = append(filtered, item)
+			}
+		}
+		items = filtered
+	}
+
+	items, ok := withCustomFields(c, oh.CustomFieldStore, oh.Logger, user.OrganizationID, database.CustomFieldItem, items,
+		func(i database.Item) uuid.UUID { return i.ItemID },
+		func(i database.Item, values map[string]any) database.Item {
+			i.CustomFields = values
+			return i
+		})
+	if !ok {
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Items retrieved successfully",
 		"data":    items,
 	})
 }
+
+// Most orders accepted by one batch request
+const maxBatchOrders = 500
+
+// Outcome of each order of a batch
+const (
+	BatchOrderStored    = "stored"
+	BatchOrderInvalid   = "invalid"
+	BatchOrderRejected  = "rejected"
+	BatchOrderDuplicate = "duplicate"
+	BatchOrderFailed    = "failed"
+)
+
+type BatchOrdersRequest struct {
+	// Orders are decoded one by one, so a malformed order does not fail the others
+	Orders []json.RawMessage `json:"orders" binding:"required"`
+}
+
+// BatchOrder is an order sent with its items and delivery in one record of a batch
+type BatchOrder struct {
+	OrderID        uuid.UUID           `json:"order_id"`
+	UserID         uuid.UUID           `json:"user_id"`
+	CreateTime     time.Time           `json:"create_time"`
+	OrderType      string              `json:"order_type"`
+	OrderStatus    string              `json:"order_status"`
+	TotalAmount    *float64            `json:"total_amount"`
+	DiscountAmount *float64            `json:"discount_amount"`
+	Rating         *float64            `json:"rating"`
+	RedemptionCode string              `json:"redemption_code"`
+	Channel        string              `json:"channel"`
+	Items          []BatchOrderItem    `json:"items"`
+	Delivery       *BatchOrderDelivery `json:"delivery"`
+}
+
+type BatchOrderItem struct {
+	ItemID     uuid.UUID `json:"item_id"`
+	Quantity   *int      `json:"quantity"`
+	TotalPrice *float64  `json:"total_price"`
+}
+
+type BatchOrderDelivery struct {
+	DriverID           uuid.UUID `json:"driver_id"`
+	Latitude           *float64  `json:"latitude"`
+	Longitude          *float64  `json:"longitude"`
+	OutForDeliveryTime time.Time `json:"out_for_delivery_time"`
+	DeliveredTime      time.Time `json:"delivered_time"`
+	Status             string    `json:"status"`
+}
+
+// BatchOrderResult is the outcome of the order at Index in the batch
+type BatchOrderResult struct {
+	Index   int        `json:"index"`
+	OrderID *uuid.UUID `json:"order_id,omitempty"`
+	Status  string     `json:"status"`
+	Errors  []string   `json:"errors,omitempty"`
+}
+
+// validate returns what is wrong with the order, the checks of the database constraints
+func (o *BatchOrder) validate() []string {
+	var problems []string
+	if o.OrderID == uuid.Nil {
+		problems = append(problems, "order_id is required")
+	}
+	if o.CreateTime.IsZero() {
+		problems = append(problems, "create_time is required")
+	}
+	if o.OrderType != "delivery" && o.OrderType != "takeaway" && o.OrderType != "dine in" {
+		problems = append(problems, "order_type must be one of delivery, takeaway, dine in")
+	}
+	if !database.IsOrderStatus(o.OrderStatus) {
+		problems = append(problems, "order_status must be one of "+strings.Join(database.OrderStatuses, ", "))
+	}
+	if o.TotalAmount == nil || *o.TotalAmount < 0 {
+		problems = append(problems, "total_amount must be a positive number")
+	}
+	if o.DiscountAmount == nil || *o.DiscountAmount < 0 {
+		problems = append(problems, "discount_amount must be a positive number")
+	} else if o.TotalAmount != nil && *o.DiscountAmount > *o.TotalAmount {
+		problems = append(problems, "discount_amount cannot exceed total_amount")
+	}
+	if o.Channel != "" && !database.IsOrderChannel(o.Channel) {
+		problems = append(problems, "channel must be one of "+strings.Join(database.OrderChannels, ", "))
+	}
+
+	for i, item := range o.Items {
+		if item.ItemID == uuid.Nil {
+			problems = append(problems, fmt.Sprintf("items[%d].item_id is required", i))
+		}
+		if item.Quantity != nil && *item.Quantity < 1 {
+			problems = append(problems, fmt.Sprintf("items[%d].quantity must be at least 1", i))
+		}
+		if item.TotalPrice == nil || *item.TotalPrice < 0 {
+			problems = append(problems, fmt.Sprintf("items[%d].total_price must be a positive number", i))
+		}
+	}
+
+	if o.Delivery != nil {
+		if o.OrderType != "delivery" {
+			problems = append(problems, "only delivery orders can have a delivery")
+		}
+		if o.Delivery.DriverID == uuid.Nil {
+			problems = append(problems, "delivery.driver_id is required")
+		}
+		if o.Delivery.OutForDeliveryTime.IsZero() {
+			problems = append(problems, "delivery.out_for_delivery_time is required")
+		}
+		if o.Delivery.Status != "delivered" && o.Delivery.Status != "out for delivery" && o.Delivery.Status != "not delivered" {
+			problems = append(problems, "delivery.status must be one of delivered, out for delivery, not delivered")
+		}
+	}
+	return problems
+}
+
+// formatOptional formats a number for the validation rules, empty when it is missing
+func formatOptional(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+// brokenRules returns the messages of the validation rules of the organization the order, its items and
+// its delivery break, checked as rows of the orders, order_items and deliveries imports
+func (o *BatchOrder) brokenRules(orders, orderItems, deliveries *ingestionValidator) []string {
+	var broken []database.IngestionRule
+	broken = append(broken, orders.BrokenRules(map[string]string{
+		"order_id":        o.OrderID.String(),
+		"user_id":         o.UserID.String(),
+		"create_time":     o.CreateTime.Format(time.RFC3339),
+		"order_type":      o.OrderType,
+		"order_status":    o.OrderStatus,
+		"total_amount":    formatOptional(o.TotalAmount),
+		"discount_amount": formatOptional(o.DiscountAmount),
+		"rating":          formatOptional(o.Rating),
+		"redemption_code": o.RedemptionCode,
+		"channel":         o.Channel,
+	})...)
+
+	for _, item := range o.Items {
+		quantity := ""
+		if item.Quantity != nil {
+			quantity = strconv.Itoa(*item.Quantity)
+		}
+		broken = append(broken, orderItems.BrokenRules(map[string]string{
+			"order_id":    o.OrderID.String(),
+			"item_id":     item.ItemID.String(),
+			"quantity":    quantity,
+			"total_price": formatOptional(item.TotalPrice),
+		})...)
+	}
+
+	if o.Delivery != nil {
+		deliveredTime := ""
+		if !o.Delivery.DeliveredTime.IsZero() {
+			deliveredTime = o.Delivery.DeliveredTime.Format(time.RFC3339)
+		}
+		broken = append(broken, deliveries.BrokenRules(map[string]string{
+			"order_id":              o.OrderID.String(),
+			"driver_id":             o.Delivery.DriverID.String(),
+			"out_for_delivery_time": o.Delivery.OutForDeliveryTime.Format(time.RFC3339),
+			"delivered_time":        deliveredTime,
+			"status":                o.Delivery.Status,
+			"delivery_latitude":     formatOptional(o.Delivery.Latitude),
+			"delivery_longitude":    formatOptional(o.Delivery.Longitude),
+		})...)
+	}
+
+	messages := make([]string, len(broken))
+	for i, rule := range broken {
+		messages[i] = ruleMessage(rule)
+	}
+	return messages
+}
+
+// toOrder converts the record into the order stored
+func (o *BatchOrder) toOrder(orgID uuid.UUID) *database.Order {
+	order := &database.Order{
+		OrderID:        o.OrderID,
+		UserID:         o.UserID,
+		OrganizationID: orgID,
+		CreateTime:     o.CreateTime,
+		OrderType:      o.OrderType,
+		OrderStatus:    o.OrderStatus,
+		TotalAmount:    o.TotalAmount,
+		DiscountAmount: o.DiscountAmount,
+		Rating:         o.Rating,
+		Channel:        o.Channel,
+		OrderItems:     make([]database.OrderItem, len(o.Items)),
+	}
+	for i, item := range o.Items {
+		order.OrderItems[i] = database.OrderItem{ItemID: item.ItemID, Quantity: item.Quantity, TotalPrice: item.TotalPrice}
+	}
+	if o.Delivery != nil {
+		order.DeliveryStatus = &database.OrderDelivery{
+			OrderID:            o.OrderID,
+			DriverID:           o.Delivery.DriverID,
+			DeliveryLocation:   database.Location{Latitude: o.Delivery.Latitude, Longitude: o.Delivery.Longitude},
+			OutForDeliveryTime: o.Delivery.OutForDeliveryTime,
+			DeliveredTime:      o.Delivery.DeliveredTime,
+			DeliveryStatus:     o.Delivery.Status,
+		}
+	}
+	return order
+}
+
+// orderValidators returns the validators of the orders, order_items and deliveries datasets, keyed by
+// dataset
+func (oh *OrderHandler) orderValidators(orgID uuid.UUID) (map[string]*ingestionValidator, error) {
+	validators := make(map[string]*ingestionValidator)
+	for _, dataset := range []string{"orders", "order_items", "deliveries"} {
+		validator, err := newIngestionValidator(oh.IngestionRuleStore, orgID, dataset)
+		if err != nil {
+			return nil, err
+		}
+		validators[dataset] = validator
+	}
+	return validators, nil
+}
+
+// UploadOrdersBatch stores orders sent as JSON with their items and delivery, for integrations pushing
+// orders as they happen instead of uploading three CSV files. Each order is validated and stored in its
+// own transaction, and the response reports the outcome of every order.
+func (oh *OrderHandler) UploadOrdersBatch(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can upload orders"})
+		return
+	}
+
+	var request BatchOrdersRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(request.Orders) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The batch has no orders"})
+		return
+	}
+	if len(request.Orders) > maxBatchOrders {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": fmt.Sprintf("A batch is limited to %d orders", maxBatchOrders),
+			"hint":  fmt.Sprintf("Split the orders into batches of at most %d and send them one after another", maxBatchOrders),
+		})
+		return
+	}
+
+	// Orders breaking the organization's validation rules are rejected, like rows of the CSV imports
+	validators, err := oh.orderValidators(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve validation rules"})
+		return
+	}
+
+	results := make([]BatchOrderResult, len(request.Orders))
+	var successCount, errorCount, rejectedCount, redemptionCount, unknownCodeCount int
+	var newest time.Time
+	for i, raw := range request.Orders {
+		results[i] = BatchOrderResult{Index: i}
+
+		var record BatchOrder
+		if err := json.Unmarshal(raw, &record); err != nil {
+			results[i].Status = BatchOrderInvalid
+			results[i].Errors = []string{"Invalid order: " + err.Error()}
+			errorCount++
+			continue
+		}
+		if record.OrderID != uuid.Nil {
+			orderID := record.OrderID
+			results[i].OrderID = &orderID
+		}
+
+		if problems := record.validate(); len(problems) > 0 {
+			results[i].Status = BatchOrderInvalid
+			results[i].Errors = problems
+			errorCount++
+			continue
+		}
+		if broken := record.brokenRules(validators["orders"], validators["order_items"], validators["deliveries"]); len(broken) > 0 {
+			results[i].Status = BatchOrderRejected
+			results[i].Errors = broken
+			rejectedCount++
+			continue
+		}
+
+		err := oh.OrderStore.StoreNestedOrder(user.OrganizationID, record.toOrder(user.OrganizationID))
+		switch {
+		case err == nil:
+		case errors.Is(err, database.ErrDuplicateOrder):
+			results[i].Status = BatchOrderDuplicate
+			results[i].Errors = []string{"An order with this order_id already exists"}
+			errorCount++
+			continue
+		case errors.Is(err, database.ErrUnknownOrderItem):
+			results[i].Status = BatchOrderInvalid
+			results[i].Errors = []string{"An item of the order is not on the menu of the organization"}
+			errorCount++
+			continue
+		default:
+			oh.Logger.Error("failed to store batch order", "index", i, "order_id", record.OrderID, "error", err)
+			results[i].Status = BatchOrderFailed
+			results[i].Errors = []string{"Failed to store order"}
+			errorCount++
+			continue
+		}
+
+		results[i].Status = BatchOrderStored
+		successCount++
+		if record.CreateTime.After(newest) {
+			newest = record.CreateTime
+		}
+
+		// Link the order to the campaign whose code it used
+		if code := strings.TrimSpace(record.RedemptionCode); code != "" {
+			err = oh.CampaignStore.RecordRedemption(user.OrganizationID, code, record.OrderID, record.CreateTime)
+			switch {
+			case err == nil:
+				redemptionCount++
+			case errors.Is(err, sql.ErrNoRows):
+				unknownCodeCount++
+			default:
+				oh.Logger.Error("failed to record redemption", "index", i, "error", err)
+			}
+		}
+	}
+	recordIngestion(oh.StatusStore, oh.Logger, user.OrganizationID, "orders_batch", newest, successCount)
+
+	oh.Logger.Info("orders batch processed", "org_id", user.OrganizationID, "total", len(request.Orders), "stored", successCount)
+	c.JSON(http.StatusOK, gin.H{
+		"message":                  "Orders batch processed",
+		"total_orders":             len(request.Orders),
+		"success_count":            successCount,
+		"error_count":              errorCount,
+		"rejected_count":           rejectedCount,
+		"redemption_count":         redemptionCount,
+		"unknown_redemption_codes": unknownCodeCount,
+		"results":                  results,
+	})
+}
+
+// UpdateOrderRequest holds the fields of an order to change, the others are left as they are
+type UpdateOrderRequest struct {
+	UserID         *uuid.UUID `json:"user_id"`
+	CreateTime     *time.Time `json:"create_time"`
+	OrderType      *string    `json:"order_type"`
+	OrderStatus    *string    `json:"order_status"`
+	TotalAmount    *float64   `json:"total_amount"`
+	DiscountAmount *float64   `json:"discount_amount"`
+	Rating         *float64   `json:"rating"`
+	Channel        *string    `json:"channel"`
+}
+
+// CreateOrderHandler stores one order sent as JSON with its items and delivery, checked like an order of
+// a batch
+func (oh *OrderHandler) CreateOrderHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can create orders"})
+		return
+	}
+
+	var record BatchOrder
+	if err := c.ShouldBindJSON(&record); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if problems := record.validate(); len(problems) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order", "details": problems})
+		return
+	}
+
+	validators, err := oh.orderValidators(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve validation rules"})
+		return
+	}
+	if broken := record.brokenRules(validators["orders"], validators["order_items"], validators["deliveries"]); len(broken) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "The order breaks the validation rules of the organization", "details": broken})
+		return
+	}
+
+	order := record.toOrder(user.OrganizationID)
+	err = oh.OrderStore.StoreNestedOrder(user.OrganizationID, order)
+	switch {
+	case err == nil:
+	case errors.Is(err, database.ErrDuplicateOrder):
+		c.JSON(http.StatusConflict, gin.H{"error": "An order with this order_id already exists"})
+		return
+	case errors.Is(err, database.ErrUnknownOrderItem):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "An item of the order is not on the menu of the organization"})
+		return
+	default:
+		oh.Logger.Error("failed to create order", "error", err, "order_id", record.OrderID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order"})
+		return
+	}
+
+	// Link the order to the campaign whose code it used
+	if code := strings.TrimSpace(record.RedemptionCode); code != "" {
+		err = oh.CampaignStore.RecordRedemption(user.OrganizationID, code, record.OrderID, record.CreateTime)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			oh.Logger.Error("failed to record redemption", "order_id", record.OrderID, "error", err)
+		}
+	}
+
+	oh.Logger.Info("order created", "org_id", user.OrganizationID, "order_id", order.OrderID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Order created successfully",
+		"data":    order,
+	})
+}
+
+// GetOrderHandler returns an order with its items, delivery and custom fields
+func (oh *OrderHandler) GetOrderHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access orders"})
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	order, err := oh.OrderStore.GetOrder(user.OrganizationID, orderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+			return
+		}
+		oh.Logger.Error("failed to get order", "error", err, "order_id", orderID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve order"})
+		return
+	}
+
+	orders, ok := withCustomFields(c, oh.CustomFieldStore, oh.Logger, user.OrganizationID, database.CustomFieldOrder, []database.Order{*order},
+		func(o database.Order) uuid.UUID { return o.OrderID },
+		func(o database.Order, values map[string]any) database.Order {
+			o.CustomFields = values
+			return o
+		})
+	if !ok {
+		return
+	}
+	if len(orders) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Order retrieved successfully",
+		"data":    orders[0],
+	})
+}
+
+// UpdateOrderHandler changes the fields of an order sent in the request. Its items and delivery are left
+// as they are, and the order is checked again as a whole against the constraints and validation rules.
+func (oh *OrderHandler) UpdateOrderHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can update orders"})
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	var req UpdateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order, err := oh.OrderStore.GetOrder(user.OrganizationID, orderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+			return
+		}
+		oh.Logger.Error("failed to get order", "error", err, "order_id", orderID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve order"})
+		return
+	}
+
+	if req.UserID != nil {
+		order.UserID = *req.UserID
+	}
+	if req.CreateTime != nil {
+		order.CreateTime = *req.CreateTime
+	}
+	if req.OrderType != nil {
+		order.OrderType = *req.OrderType
+	}
+	if req.OrderStatus != nil {
+		order.OrderStatus = *req.OrderStatus
+	}
+	if req.TotalAmount != nil {
+		order.TotalAmount = req.TotalAmount
+	}
+	if req.DiscountAmount != nil {
+		order.DiscountAmount = req.DiscountAmount
+	}
+	if req.Rating != nil {
+		order.Rating = req.Rating
+	}
+	if req.Channel != nil {
+		order.Channel = *req.Channel
+	}
+
+	// The items and delivery are not sent again, only the fields of the order are checked
+	record := BatchOrder{OrderID: order.OrderID, UserID: order.UserID, CreateTime: order.CreateTime, OrderType: order.OrderType,
+		OrderStatus: order.OrderStatus, TotalAmount: order.TotalAmount, DiscountAmount: order.DiscountAmount, Rating: order.Rating,
+		Channel: order.Channel}
+	problems := record.validate()
+	if order.DeliveryStatus != nil && order.OrderType != "delivery" {
+		problems = append(problems, "only delivery orders can have a delivery")
+	}
+	if len(problems) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order", "details": problems})
+		return
+	}
+
+	validator, err := newIngestionValidator(oh.IngestionRuleStore, user.OrganizationID, "orders")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve validation rules"})
+		return
+	}
+	if broken := record.brokenRules(validator, nil, nil); len(broken) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "The order breaks the validation rules of the organization", "details": broken})
+		return
+	}
+
+	if err := oh.OrderStore.UpdateOrder(user.OrganizationID, order); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+			return
+		}
+		oh.Logger.Error("failed to update order", "error", err, "order_id", orderID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update order"})
+		return
+	}
+
+	oh.Logger.Info("order updated", "org_id", user.OrganizationID, "order_id", orderID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Order updated successfully",
+		"data":    order,
+	})
+}
+
+// DeleteOrderHandler deletes an order with its items and delivery
+func (oh *OrderHandler) DeleteOrderHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can delete orders"})
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	if err := oh.OrderStore.DeleteOrder(user.OrganizationID, orderID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+			return
+		}
+		oh.Logger.Error("failed to delete order", "error", err, "order_id", orderID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete order"})
+		return
+	}
+
+	oh.Logger.Info("order deleted", "org_id", user.OrganizationID, "order_id", orderID)
+	c.JSON(http.StatusOK, gin.H{"message": "Order deleted successfully"})
+}
+
+type TransitionOrderStatusRequest struct {
+	Status string  `json:"status" binding:"required"`
+	Note   *string `json:"note" binding:"omitempty,max=500"`
+}
+
+// TransitionOrderStatusHandler moves an order along its lifecycle, pending to preparing to ready to
+// completed, and records the change. Any member advances orders, only admins and managers cancel them.
+func (oh *OrderHandler) TransitionOrderStatusHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	var req TransitionOrderStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !database.IsOrderStatus(req.Status) || req.Status == database.OrderStatusIncompleted {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of preparing, ready, completed, cancelled"})
+		return
+	}
+	if req.Status == database.OrderStatusCancelled && user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can cancel orders"})
+		return
+	}
+
+	change, err := oh.OrderStore.TransitionOrderStatus(user.OrganizationID, orderID, req.Status, &user.ID, req.Note)
+	if err != nil {
+		var transitionErr *database.OrderTransitionError
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		case errors.As(err, &transitionErr):
+			allowed := database.OrderStatusTransitions[transitionErr.From]
+			if allowed == nil {
+				allowed = []string{}
+			}
+			c.JSON(http.StatusConflict, gin.H{"error": "Order is " + transitionErr.From + " and cannot become " + transitionErr.To, "allowed": allowed})
+		default:
+			oh.Logger.Error("failed to change order status", "error", err, "order_id", orderID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change order status"})
+		}
+		return
+	}
+
+	oh.Logger.Info("order status changed", "org_id", user.OrganizationID, "order_id", orderID, "from", change.FromStatus, "to", change.ToStatus)
+	c.JSON(http.StatusOK, gin.H{"message": "Order status changed successfully", "data": change})
+}
+
+// GetOrderStatusHistoryHandler lists the status changes of an order, oldest first
+func (oh *OrderHandler) GetOrderStatusHistoryHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can view the status history of orders"})
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	history, err := oh.OrderStore.GetOrderStatusHistory(user.OrganizationID, orderID)
+	if err != nil {
+		oh.Logger.Error("failed to get order status history", "error", err, "order_id", orderID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve order status history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Order status history retrieved successfully", "data": history})
+}
@@ -1,11 +1,19 @@
 package api
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"log/slog"
+	"net"
 	"net/http"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
 	"github.com/clockwise/clockwise/backend/internal/service"
 	"github.com/clockwise/clockwise/backend/internal/utils"
 
@@ -19,6 +27,13 @@ type OrgHandler struct {
 	rolesStore     database.RolesStore
 	emailService   service.EmailService
 	Logger         *slog.Logger
+
+	// Documents generates the offer letter of a new employee, nil generates none
+	Documents *service.DocumentService
+	// Trainings holds the training periods of new employees, nil puts nobody in training
+	Trainings database.TrainingStore
+	// LookupTXT resolves the TXT records a custom domain is verified with
+	LookupTXT func(ctx context.Context, name string) ([]string, error)
 }
 
 func NewOrgHandler(orgStore database.OrgStore, userStore database.UserStore, userRolesStore database.UserRolesStore, rolesStore database.RolesStore, emailService service.EmailService, logger *slog.Logger) *OrgHandler {
@@ -29,6 +44,7 @@ func NewOrgHandler(orgStore database.OrgStore, userStore database.UserStore, use
 		rolesStore:     rolesStore,
 		emailService:   emailService,
 		Logger:         logger,
+		LookupTXT:      net.DefaultResolver.LookupTXT,
 	}
 }
 
@@ -47,15 +63,56 @@ type RegisterOrgRequest struct {
 	Hex3          string   `json:"hex3" binding:"required,len=6"`
 }
 
+// hostnamePattern accepts host names such as shifts.example.com, optionally with a port
+var hostnamePattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}(:[0-9]{1,5})?$`)
+
+type CustomDomainRequest struct {
+	// Empty removes the custom domain
+	Domain string `json:"domain"`
+}
+
+// customDomainChallenge is prepended to a custom domain to get the name of the TXT record holding its token
+const customDomainChallenge = "_clockwise-challenge."
+
+// customDomainLookupTimeout bounds the DNS lookup of the TXT record of a custom domain
+const customDomainLookupTimeout = 5 * time.Second
+
+// currencyPattern matches ISO 4217 currency codes such as USD and EUR
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type CurrencyRequest struct {
+	Currency string `json:"currency" binding:"required"`
+}
+
+// Configuration an organization clone can copy, all of it when the request lists none
+var orgCloneIncludes = []string{"rules", "roles", "operating_hours", "items", "campaigns"}
+
+type CloneOrgRequest struct {
+	Name      string   `json:"name" binding:"required"`
+	Email     string   `json:"email" binding:"required,email"`
+	Phone     string   `json:"phone" binding:"required"`
+	Address   string   `json:"address"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	// Include lists the configuration copied among orgCloneIncludes
+	Include []string `json:"include"`
+}
+
 type DelegateUserRequest struct {
 	FullName              string   `json:"full_name" binding:"required"`
 	Email                 string   `json:"email" binding:"required"`
-	Role                  string   `json:"role" binding:"required"`
+	Role                  string   `json:"role" binding:"required,oneof=manager employee"`
 	SalaryPerHour         *float64 `json:"hourly_salary" binding:"required"`
 	MaxHoursPerWeek       *int     `json:"max_hours_per_week"`
 	PreferredHoursPerWeek *int     `json:"preferred_hours_per_week"`
 	MaxConsecSlots        *int     `json:"max_consec_slots"`
 	OnCall                *bool    `json:"on_call"`
+	// OfferLetter generates an offer letter the new employee is emailed to sign
+	OfferLetter bool `json:"offer_letter"`
+	// StartDate is the first day of work the offer letter states, YYYY-MM-DD
+	StartDate string `json:"start_date"`
+	// TrainingDays puts the new employee in training for that many days from today
+	TrainingDays *int `json:"training_days" binding:"omitempty,min=1,max=365"`
 }
 
 // RegisterOrganization godoc
@@ -128,6 +185,16 @@ func (h *OrgHandler) DelegateUser(c *gin.Context) {
 		return
 	}
 
+	startDate, err := documentDate("start_date", req.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.OfferLetter && h.Documents == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Document generation is not available"})
+		return
+	}
+
 	h.Logger.Debug("delegating user", "email", req.Email, "role", req.Role, "delegated_by", currentUser.ID)
 
 	org, err := h.orgStore.GetOrganizationByID(currentUser.OrganizationID)
@@ -181,11 +248,32 @@ func (h *OrgHandler) DelegateUser(c *gin.Context) {
 		}
 	}()
 
-	h.Logger.Info("user delegated successfully", "user_id", newUser.ID, "email", newUser.Email, "role", newUser.UserRole, "org_id", currentUser.OrganizationID)
-	c.JSON(http.StatusCreated, gin.H{
+	response := gin.H{
 		"message": "User delegated successfully. Email sent.",
 		"user_id": newUser.ID,
-	})
+	}
+	// the user exists by now, a training that fails to start can be started again on the employee
+	if req.TrainingDays != nil && h.Trainings != nil {
+		training := NewTraining(newUser, *req.TrainingDays, currentUser.ID, time.Now())
+		if err := h.Trainings.StartTraining(training); err != nil {
+			h.Logger.Error("failed to start employee training", "error", err, "user_id", newUser.ID)
+		} else {
+			response["training_ends_on"] = training.EndsOn.Format(time.DateOnly)
+		}
+	}
+	// the user exists by now, a letter that fails to generate is generated again from their documents
+	if req.OfferLetter {
+		fields := service.DocumentFields{Organization: org.Name, StartDate: startDate}
+		document, err := h.Documents.Issue(currentUser.OrganizationID, newUser, database.DocumentOfferLetter, fields, currentUser.ID)
+		if err != nil {
+			h.Logger.Error("failed to generate offer letter", "error", err, "user_id", newUser.ID)
+		} else {
+			response["document_id"] = document.ID
+		}
+	}
+
+	h.Logger.Info("user delegated successfully", "user_id", newUser.ID, "email", newUser.Email, "role", newUser.UserRole, "org_id", currentUser.OrganizationID)
+	c.JSON(http.StatusCreated, response)
 }
 
 // GetOrganizationProfile godoc
@@ -213,3 +301,296 @@ func (oh *OrgHandler) GetOrganizationProfile(c *gin.Context) {
 		"data":    profile,
 	})
 }
+
+// SetCustomDomainHandler sets the host name the organization serves its dashboard from. The domain is
+// pending until VerifyCustomDomainHandler finds its token in a TXT record, only then the API allows https
+// requests from that host as a CORS origin.
+func (oh *OrgHandler) SetCustomDomainHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change the custom domain"})
+		return
+	}
+
+	var req CustomDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	host := strings.ToLower(strings.TrimSpace(req.Domain))
+	if host == "" {
+		if err := oh.orgStore.SetCustomDomain(user.OrganizationID, nil, nil); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+				return
+			}
+			oh.Logger.Error("failed to remove custom domain", "error", err, "org_id", user.OrganizationID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update custom domain"})
+			return
+		}
+		oh.Logger.Info("custom domain removed", "org_id", user.OrganizationID)
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Custom domain removed successfully",
+			"data":    gin.H{"domain": nil},
+		})
+		return
+	}
+	if !hostnamePattern.MatchString(host) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Domain must be a host name such as shifts.example.com, without scheme or path"})
+		return
+	}
+
+	current, err := oh.orgStore.GetCustomDomain(user.OrganizationID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		oh.Logger.Error("failed to get custom domain", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update custom domain"})
+		return
+	}
+	if current != nil && current.Domain == host {
+		// setting the same domain again keeps its token and verification
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Custom domain updated successfully",
+			"data":    customDomainView(current),
+		})
+		return
+	}
+
+	ownerID, err := oh.orgStore.GetOrgIDByCustomDomain(host)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		oh.Logger.Error("failed to check custom domain", "error", err, "domain", host)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update custom domain"})
+		return
+	}
+	if err == nil && ownerID != user.OrganizationID {
+		c.JSON(http.StatusConflict, gin.H{"error": "Domain is already verified by another organization"})
+		return
+	}
+
+	token, err := utils.GenerateRandomPassword(16)
+	if err != nil {
+		oh.Logger.Error("failed to generate custom domain token", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update custom domain"})
+		return
+	}
+	token = "clockwise-verify=" + token
+	if err := oh.orgStore.SetCustomDomain(user.OrganizationID, &host, &token); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+			return
+		}
+		oh.Logger.Error("failed to set custom domain", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update custom domain"})
+		return
+	}
+
+	oh.Logger.Info("custom domain set, pending verification", "org_id", user.OrganizationID, "domain", host)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Custom domain updated successfully, publish the TXT record and verify it",
+		"data":    customDomainView(&database.CustomDomain{Domain: host, Token: token}),
+	})
+}
+
+// VerifyCustomDomainHandler looks for the token of the pending custom domain in its TXT record, and
+// verifies the domain when it is there
+func (oh *OrgHandler) VerifyCustomDomainHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change the custom domain"})
+		return
+	}
+
+	domain, err := oh.orgStore.GetCustomDomain(user.OrganizationID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No custom domain is set"})
+			return
+		}
+		oh.Logger.Error("failed to get custom domain", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify custom domain"})
+		return
+	}
+	if domain.VerifiedAt != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Custom domain is verified",
+			"data":    customDomainView(domain),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), customDomainLookupTimeout)
+	defer cancel()
+	records, err := oh.LookupTXT(ctx, customDomainRecord(domain.Domain))
+	if err != nil || !slices.Contains(records, domain.Token) {
+		oh.Logger.Info("custom domain TXT record not found", "org_id", user.OrganizationID, "domain", domain.Domain, "error", err)
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "The TXT record with the verification token was not found, DNS changes can take a while to show",
+			"data":  customDomainView(domain),
+		})
+		return
+	}
+
+	if err := oh.orgStore.VerifyCustomDomain(user.OrganizationID); err != nil {
+		switch {
+		case errors.Is(err, database.ErrCustomDomainTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": "Domain is already verified by another organization"})
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{"error": "No custom domain is set"})
+		default:
+			oh.Logger.Error("failed to verify custom domain", "error", err, "org_id", user.OrganizationID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify custom domain"})
+		}
+		return
+	}
+
+	now := time.Now()
+	domain.VerifiedAt = &now
+	oh.Logger.Info("custom domain verified", "org_id", user.OrganizationID, "domain", domain.Domain)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Custom domain verified successfully",
+		"data":    customDomainView(domain),
+	})
+}
+
+// customDomainRecord is the name of the TXT record verifying the domain, without its port
+func customDomainRecord(domain string) string {
+	host, _, _ := strings.Cut(domain, ":")
+	return customDomainChallenge + host
+}
+
+// customDomainView is the custom domain with the TXT record the admin publishes to verify it
+func customDomainView(domain *database.CustomDomain) gin.H {
+	return gin.H{
+		"domain":      domain.Domain,
+		"verified":    domain.VerifiedAt != nil,
+		"verified_at": domain.VerifiedAt,
+		"txt_record": gin.H{
+			"name":  customDomainRecord(domain.Domain),
+			"value": domain.Token,
+		},
+	}
+}
+
+// SetCurrencyHandler sets the currency the organization's prices, revenue and salaries are in. Existing
+// amounts are not converted.
+func (oh *OrgHandler) SetCurrencyHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change the currency"})
+		return
+	}
+
+	var req CurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if !currencyPattern.MatchString(currency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Currency must be an ISO 4217 code such as USD or EUR"})
+		return
+	}
+
+	if err := oh.orgStore.SetCurrency(user.OrganizationID, currency); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+			return
+		}
+		oh.Logger.Error("failed to set currency", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update currency"})
+		return
+	}
+
+	oh.Logger.Info("currency updated", "org_id", user.OrganizationID, "currency", currency)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Currency updated successfully",
+		"data":    gin.H{"currency": currency},
+	})
+}
+
+// CloneOrganizationHandler opens a new organization, such as a new branch, with the configuration of the
+// organization: its rules, roles, operating hours, items and campaigns as templates, or those listed in
+// include. Orders, employees and schedules are not copied. The admin becomes an admin of the new
+// organization and switches to it with /auth/switch-org.
+func (oh *OrgHandler) CloneOrganizationHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can clone the organization"})
+		return
+	}
+
+	var req CloneOrgRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "latitude and longitude must be given together"})
+		return
+	}
+
+	include := req.Include
+	if len(include) == 0 {
+		include = orgCloneIncludes
+	}
+	var options database.OrgCloneOptions
+	for _, what := range include {
+		switch what {
+		case "rules":
+			options.Rules = true
+		case "roles":
+			options.Roles = true
+		case "operating_hours":
+			options.OperatingHours = true
+		case "items":
+			options.Items = true
+		case "campaigns":
+			options.Campaigns = true
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid include " + what + ", expected one of " + strings.Join(orgCloneIncludes, ", ")})
+			return
+		}
+	}
+
+	org := &database.Organization{
+		Name:    strings.TrimSpace(req.Name),
+		Email:   strings.TrimSpace(req.Email),
+		Phone:   strings.TrimSpace(req.Phone),
+		Address: req.Address,
+		Location: database.Location{
+			Latitude:  req.Latitude,
+			Longitude: req.Longitude,
+		},
+	}
+	clone, err := oh.orgStore.CloneOrganization(user.OrganizationID, org, user.ID, options)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrOrganizationExists):
+			c.JSON(http.StatusConflict, gin.H{"error": "An organization already uses this name, email or phone"})
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+		default:
+			oh.Logger.Error("failed to clone organization", "error", err, "org_id", user.OrganizationID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clone organization"})
+		}
+		return
+	}
+
+	oh.Logger.Info("organization cloned", "org_id", user.OrganizationID, "clone_id", org.ID, "user_id", user.ID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Organization cloned successfully",
+		"data":    clone,
+	})
+}
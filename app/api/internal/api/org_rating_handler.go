@@ -0,0 +1,128 @@
+package api
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// Days of rating history listed when no period is given
+const ratingTrendDays = 90
+
+// Smallest change of the rating over a period that counts as a trend
+const ratingTrendThreshold = 0.05
+
+// Directions of the rating over a period
+const (
+	RatingTrendUp   = "up"
+	RatingTrendDown = "down"
+	RatingTrendFlat = "flat"
+)
+
+type OrgRatingHandler struct {
+	OrgRatingStore database.OrgRatingStore
+	Logger         *slog.Logger
+}
+
+func NewOrgRatingHandler(orgRatingStore database.OrgRatingStore, logger *slog.Logger) *OrgRatingHandler {
+	return &OrgRatingHandler{
+		OrgRatingStore: orgRatingStore,
+		Logger:         logger,
+	}
+}
+
+// RatingTrend is how the rating of an organization moved over a period, from its first to its latest
+// recomputation. Current and Change are nil when the rating was not recomputed in the period.
+type RatingTrend struct {
+	Current   *float64 `json:"current"`
+	Change    *float64 `json:"change"`
+	Direction string   `json:"direction"`
+	Lowest    *float64 `json:"lowest"`
+	Highest   *float64 `json:"highest"`
+}
+
+// SummarizeRatingTrend compares the latest rating of the period with the first, ratings oldest first
+func SummarizeRatingTrend(ratings []database.OrganizationRating) RatingTrend {
+	trend := RatingTrend{Direction: RatingTrendFlat}
+	if len(ratings) == 0 {
+		return trend
+	}
+
+	current := ratings[len(ratings)-1].Rating
+	change := math.Round((current-ratings[0].Rating)*100) / 100
+	lowest, highest := current, current
+	for _, rating := range ratings {
+		lowest = min(lowest, rating.Rating)
+		highest = max(highest, rating.Rating)
+	}
+	trend.Current, trend.Change, trend.Lowest, trend.Highest = &current, &change, &lowest, &highest
+
+	switch {
+	case change >= ratingTrendThreshold:
+		trend.Direction = RatingTrendUp
+	case change <= -ratingTrendThreshold:
+		trend.Direction = RatingTrendDown
+	}
+	return trend
+}
+
+// GetRatingTrendHandler lists the ratings recomputed from the order ratings over a period (the last 90
+// days by default) with the direction the rating moved in
+func (rh *OrgRatingHandler) GetRatingTrendHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access the rating trend"})
+		return
+	}
+
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+		// to is inclusive, the whole day counts
+		to = parsed.AddDate(0, 0, 1)
+	}
+	from := to.AddDate(0, 0, -ratingTrendDays)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+		from = parsed
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	ratings, err := rh.OrgRatingStore.ListRatings(user.OrganizationID, from, to)
+	if err != nil {
+		rh.Logger.Error("failed to list organization ratings", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve the rating trend"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Rating trend retrieved successfully",
+		"data": gin.H{
+			"from":    from.Format(time.DateOnly),
+			"to":      to.AddDate(0, 0, -1).Format(time.DateOnly),
+			"trend":   SummarizeRatingTrend(ratings),
+			"ratings": ratings,
+		},
+	})
+}
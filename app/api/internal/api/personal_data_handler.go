@@ -0,0 +1,137 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PersonalDataHandler exports everything stored about a user, for GDPR access requests
+type PersonalDataHandler struct {
+	UserStore           database.UserStore
+	RequestStore        database.RequestStore
+	PreferencesStore    database.PreferencesStore
+	EmployeeRecordStore database.EmployeeRecordStore
+	CustomFieldStore    database.CustomFieldStore
+	Logger              *slog.Logger
+}
+
+func NewPersonalDataHandler(userStore database.UserStore, requestStore database.RequestStore, preferencesStore database.PreferencesStore,
+	employeeRecordStore database.EmployeeRecordStore, customFieldStore database.CustomFieldStore, logger *slog.Logger) *PersonalDataHandler {
+	return &PersonalDataHandler{
+		UserStore:           userStore,
+		RequestStore:        requestStore,
+		PreferencesStore:    preferencesStore,
+		EmployeeRecordStore: employeeRecordStore,
+		CustomFieldStore:    customFieldStore,
+		Logger:              logger,
+	}
+}
+
+// PersonalDataExport is the personal data of a user. Records include the confidential notes and
+// disciplinary actions about them that have not reached the end of their retention, and CustomFields
+// the values of the employee custom fields of the organization.
+type PersonalDataExport struct {
+	ExportedAt   time.Time                     `json:"exported_at"`
+	User         *database.User                `json:"user"`
+	Preferences  []database.EmployeePreference `json:"preferences"`
+	Requests     []*database.Request           `json:"requests"`
+	Records      []database.EmployeeRecord     `json:"records"`
+	CustomFields map[string]any                `json:"custom_fields"`
+}
+
+func (ph *PersonalDataHandler) buildExport(orgID uuid.UUID, user *database.User) (*PersonalDataExport, error) {
+	preferences, err := ph.PreferencesStore.GetPreferencesByEmployeeID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := ph.RequestStore.GetRequestsByEmployee(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	records, err := ph.EmployeeRecordStore.GetRecordsForEmployee(orgID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	customFields, err := ph.CustomFieldStore.GetCustomFieldValues(orgID, database.CustomFieldEmployee, []uuid.UUID{user.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	if preferences == nil {
+		preferences = []database.EmployeePreference{}
+	}
+	if requests == nil {
+		requests = []*database.Request{}
+	}
+	values := customFields[user.ID]
+	if values == nil {
+		values = map[string]any{}
+	}
+	return &PersonalDataExport{
+		ExportedAt:   time.Now(),
+		User:         user,
+		Preferences:  preferences,
+		Requests:     requests,
+		Records:      records,
+		CustomFields: values,
+	}, nil
+}
+
+func (ph *PersonalDataHandler) sendExport(c *gin.Context, orgID uuid.UUID, user *database.User, requestedBy uuid.UUID) {
+	export, err := ph.buildExport(orgID, user)
+	if err != nil {
+		ph.Logger.Error("failed to build personal data export", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export personal data"})
+		return
+	}
+
+	ph.Logger.Info("personal data exported", "user_id", user.ID, "by", requestedBy)
+	c.Header("Content-Disposition", "attachment; filename=personal-data-"+user.ID.String()+".json")
+	c.JSON(http.StatusOK, gin.H{"message": "Personal data exported successfully", "data": export})
+}
+
+// ExportMyDataHandler exports the personal data of the current user
+func (ph *PersonalDataHandler) ExportMyDataHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	current, err := ph.UserStore.GetUserByID(user.ID)
+	if err != nil || current == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	ph.sendExport(c, user.OrganizationID, current, user.ID)
+}
+
+// ExportEmployeeDataHandler lets admins answer an access request on behalf of an employee
+func (ph *PersonalDataHandler) ExportEmployeeDataHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can export employee data"})
+		return
+	}
+
+	employeeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid employee ID"})
+		return
+	}
+	employee, err := ph.UserStore.GetUserByID(employeeID)
+	if err != nil || employee == nil || employee.OrganizationID != user.OrganizationID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		return
+	}
+	ph.sendExport(c, user.OrganizationID, employee, user.ID)
+}
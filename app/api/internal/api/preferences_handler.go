@@ -7,6 +7,7 @@ import (
 
 	"github.com/clockwise/clockwise/backend/internal/database"
 	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,6 +18,8 @@ type PreferencesHandler struct {
 	userStore        database.UserStore
 	rolesStore       database.RolesStore
 	Logger           *slog.Logger
+	// UploadService parses the availability imports
+	UploadService service.UploadService
 }
 
 // NewPreferencesHandler creates a new PreferencesHandler
@@ -0,0 +1,270 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxPreferenceCopyTargets caps the employees one template is copied to at once
+const maxPreferenceCopyTargets = 500
+
+// preferenceCSVHeaders are the columns of an availability import, the time columns may be left empty
+var preferenceCSVHeaders = []string{"employee_email", "day", "available_from", "available_to", "preferred_from", "preferred_to"}
+
+// CopyPreferencesRequest copies the weekly availability of one employee to others
+type CopyPreferencesRequest struct {
+	FromEmployeeID uuid.UUID   `json:"from_employee_id" binding:"required"`
+	ToEmployeeIDs  []uuid.UUID `json:"to_employee_ids" binding:"required,min=1"`
+}
+
+// PreferenceImportFailure is a row of an availability import that was not imported, rows count from 2
+// as the first line holds the headers
+type PreferenceImportFailure struct {
+	Row   int    `json:"row"`
+	Email string `json:"email"`
+	Error string `json:"error"`
+}
+
+// clockRange validates an optional from-to pair of a CSV row, both are set or neither is
+func clockRange(from, to, name string) (*string, *string, string) {
+	if from == "" && to == "" {
+		return nil, nil, ""
+	}
+	if from == "" || to == "" {
+		return nil, nil, name + "_from and " + name + "_to must be set together"
+	}
+	start, err := parseClockTime(from)
+	if err != nil {
+		return nil, nil, "Invalid " + name + "_from, expected HH:MM"
+	}
+	end, err := parseClockTime(to)
+	if err != nil {
+		return nil, nil, "Invalid " + name + "_to, expected HH:MM"
+	}
+	if !start.Before(end) {
+		return nil, nil, name + "_from must be before " + name + "_to"
+	}
+	return &from, &to, ""
+}
+
+// managesPreferences refuses employees, only admins and managers set the availability of others
+func managesPreferences(c *gin.Context, user *database.User) bool {
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can set the availability of employees"})
+		return false
+	}
+	return true
+}
+
+// CopyPreferencesHandler uses the weekly availability of one employee as the template of others, whose
+// day preferences are replaced by a copy of it
+func (h *PreferencesHandler) CopyPreferencesHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+	if !managesPreferences(c, user) {
+		return
+	}
+
+	var req CopyPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if len(req.ToEmployeeIDs) > maxPreferenceCopyTargets {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Availability can be copied to at most " + strconv.Itoa(maxPreferenceCopyTargets) + " employees at once"})
+		return
+	}
+
+	employees, err := h.userStore.GetUsersByOrganization(user.OrganizationID)
+	if err != nil {
+		h.Logger.Error("failed to get employees", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve employees"})
+		return
+	}
+	members := make(map[uuid.UUID]bool, len(employees))
+	for _, employee := range employees {
+		members[employee.ID] = true
+	}
+
+	if !members[req.FromEmployeeID] {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found: " + req.FromEmployeeID.String()})
+		return
+	}
+	seen := make(map[uuid.UUID]bool, len(req.ToEmployeeIDs))
+	toIDs := make([]uuid.UUID, 0, len(req.ToEmployeeIDs))
+	for _, id := range req.ToEmployeeIDs {
+		if !members[id] {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found: " + id.String()})
+			return
+		}
+		if id == req.FromEmployeeID || seen[id] {
+			continue
+		}
+		seen[id] = true
+		toIDs = append(toIDs, id)
+	}
+	if len(toIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to_employee_ids must name employees other than from_employee_id"})
+		return
+	}
+
+	template, err := h.preferencesStore.GetPreferencesByEmployeeID(req.FromEmployeeID)
+	if err != nil {
+		h.Logger.Error("failed to get preferences", "error", err, "employee_id", req.FromEmployeeID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve preferences"})
+		return
+	}
+	if len(template) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The employee has no availability to copy"})
+		return
+	}
+
+	if err := h.preferencesStore.CopyPreferences(req.FromEmployeeID, toIDs); err != nil {
+		h.Logger.Error("failed to copy preferences", "error", err, "from_id", req.FromEmployeeID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to copy preferences"})
+		return
+	}
+
+	h.Logger.Info("preferences copied", "org_id", user.OrganizationID, "from_id", req.FromEmployeeID, "count", len(toIDs))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Preferences copied successfully",
+		"data": gin.H{
+			"from_employee_id": req.FromEmployeeID,
+			"copied_to":        toIDs,
+			"day_count":        len(template),
+		},
+	})
+}
+
+// UploadPreferencesCSV imports the weekly availability of employees from a CSV with a row per employee
+// and day. The days of the file are set, the other days of an employee are left as they were. Rows that
+// do not validate are reported and skipped, the others are imported.
+func (h *PreferencesHandler) UploadPreferencesCSV(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+	if !managesPreferences(c, user) {
+		return
+	}
+
+	file, _, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+		return
+	}
+	defer file.Close()
+
+	csvData, err := h.UploadService.ParseCSV(file)
+	if err != nil {
+		h.Logger.Error("failed to parse CSV", "error", err)
+		if err == service.ErrTooManyRows {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "CSV file has too many rows", "hint": middleware.UploadSplitHint})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	headers := make(map[string]bool, len(csvData.Headers))
+	for _, header := range csvData.Headers {
+		headers[strings.TrimSpace(header)] = true
+	}
+	for _, header := range preferenceCSVHeaders {
+		if !headers[header] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing required header: " + header})
+			return
+		}
+	}
+
+	employees, err := h.userStore.GetUsersByOrganization(user.OrganizationID)
+	if err != nil {
+		h.Logger.Error("failed to get employees", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve employees"})
+		return
+	}
+	byEmail := make(map[string]uuid.UUID, len(employees))
+	for _, employee := range employees {
+		byEmail[strings.ToLower(employee.Email)] = employee.ID
+	}
+
+	failed := []PreferenceImportFailure{}
+	prefs := make(map[uuid.UUID][]database.EmployeePreference)
+	var order []uuid.UUID
+	seen := make(map[string]bool)
+	for i, row := range csvData.Rows {
+		email := strings.TrimSpace(row["employee_email"])
+		fail := func(message string) {
+			failed = append(failed, PreferenceImportFailure{Row: i + 2, Email: email, Error: message})
+		}
+
+		employeeID, ok := byEmail[strings.ToLower(email)]
+		if !ok {
+			fail("No employee with this email in the organization")
+			continue
+		}
+		day := strings.ToLower(strings.TrimSpace(row["day"]))
+		if !database.IsValidDay(day) {
+			fail("Invalid day: " + row["day"])
+			continue
+		}
+		key := employeeID.String() + ":" + day
+		if seen[key] {
+			fail("Duplicate day for the employee: " + day)
+			continue
+		}
+		availableFrom, availableTo, problem := clockRange(strings.TrimSpace(row["available_from"]), strings.TrimSpace(row["available_to"]), "available")
+		if problem != "" {
+			fail(problem)
+			continue
+		}
+		preferredFrom, preferredTo, problem := clockRange(strings.TrimSpace(row["preferred_from"]), strings.TrimSpace(row["preferred_to"]), "preferred")
+		if problem != "" {
+			fail(problem)
+			continue
+		}
+		seen[key] = true
+
+		if _, ok := prefs[employeeID]; !ok {
+			order = append(order, employeeID)
+		}
+		prefs[employeeID] = append(prefs[employeeID], database.EmployeePreference{
+			EmployeeID:         employeeID,
+			Day:                day,
+			PreferredStartTime: preferredFrom,
+			PreferredEndTime:   preferredTo,
+			AvailableStartTime: availableFrom,
+			AvailableEndTime:   availableTo,
+		})
+	}
+
+	imported := 0
+	for _, employeeID := range order {
+		if err := h.preferencesStore.UpsertPreferences(employeeID, prefs[employeeID]); err != nil {
+			h.Logger.Error("failed to import preferences", "error", err, "employee_id", employeeID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save day preferences"})
+			return
+		}
+		imported += len(prefs[employeeID])
+	}
+
+	h.Logger.Info("preferences imported", "org_id", user.OrganizationID, "employees", len(order), "rows", imported, "failed", len(failed))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Preferences import completed",
+		"data": gin.H{
+			"employee_count": len(order),
+			"imported_count": imported,
+			"failed_count":   len(failed),
+			"failed":         failed,
+		},
+	})
+}
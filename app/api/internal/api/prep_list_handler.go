@@ -0,0 +1,225 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// Weeks of sales the item mix is taken from
+	prepHistoryWeeks = 8
+	// Extra percent prepared on top of the expected units unless the request sets buffer
+	defaultPrepBufferPercent = 10
+
+	itemMixWeekday = "weekday"
+	itemMixAllDays = "all_days"
+)
+
+type PrepListHandler struct {
+	PrepListStore         database.PrepListStore
+	ItemAvailabilityStore database.ItemAvailabilityStore
+	OrgStore              database.OrgStore
+	BrandingStore         database.BrandingStore
+	Logger                *slog.Logger
+}
+
+func NewPrepListHandler(prepListStore database.PrepListStore, itemAvailabilityStore database.ItemAvailabilityStore, orgStore database.OrgStore, brandingStore database.BrandingStore, logger *slog.Logger) *PrepListHandler {
+	return &PrepListHandler{
+		PrepListStore:         prepListStore,
+		ItemAvailabilityStore: itemAvailabilityStore,
+		OrgStore:              orgStore,
+		BrandingStore:         brandingStore,
+		Logger:                logger,
+	}
+}
+
+// PrepListItem is the suggested quantity of an item to prepare
+type PrepListItem struct {
+	ItemID            uuid.UUID `json:"item_id"`
+	Name              string    `json:"name"`
+	SharePercent      float64   `json:"share_percent"`
+	ExpectedUnits     float64   `json:"expected_units"`
+	SuggestedQuantity int       `json:"suggested_quantity"`
+}
+
+// PrepList is the prep list of a day
+type PrepList struct {
+	Date           string         `json:"date"`
+	ForecastItems  int            `json:"forecast_items"`
+	ForecastOrders int            `json:"forecast_orders"`
+	ItemMix        string         `json:"item_mix"`
+	BufferPercent  int            `json:"buffer_percent"`
+	Items          []PrepListItem `json:"items"`
+}
+
+// BuildPrepList splits the forecast items of the day by each item's share of past sales. The mix of the same
+// weekday is used when there are sales on that weekday, otherwise the mix of all days. Quantities are rounded up
+// after adding the buffer.
+func BuildPrepList(forecast database.DayForecast, sales []database.ItemSales, bufferPercent int) PrepList {
+	list := PrepList{
+		Date:           forecast.Date.Format(time.DateOnly),
+		ForecastItems:  forecast.ItemCount,
+		ForecastOrders: forecast.OrderCount,
+		ItemMix:        itemMixWeekday,
+		BufferPercent:  bufferPercent,
+		Items:          []PrepListItem{},
+	}
+
+	units := func(item database.ItemSales) int { return item.UnitsOnWeekday }
+	total := 0
+	for _, item := range sales {
+		total += item.UnitsOnWeekday
+	}
+	if total == 0 {
+		list.ItemMix = itemMixAllDays
+		units = func(item database.ItemSales) int { return item.UnitsAllDays }
+		for _, item := range sales {
+			total += item.UnitsAllDays
+		}
+	}
+	if total == 0 {
+		return list
+	}
+
+	for _, item := range sales {
+		if units(item) == 0 {
+			continue
+		}
+		share := float64(units(item)) / float64(total)
+		expected := float64(forecast.ItemCount) * share
+		list.Items = append(list.Items, PrepListItem{
+			ItemID:            item.ItemID,
+			Name:              item.Name,
+			SharePercent:      math.Round(share*10000) / 100,
+			ExpectedUnits:     math.Round(expected*10) / 10,
+			SuggestedQuantity: int(math.Ceil(expected * float64(100+bufferPercent) / 100)),
+		})
+	}
+	sort.SliceStable(list.Items, func(i, j int) bool {
+		return list.Items[i].SuggestedQuantity > list.Items[j].SuggestedQuantity
+	})
+	return list
+}
+
+// GetPrepListHandler suggests prep quantities per item for a day from the demand forecast and the item mix
+// of past sales, leaving out items that are not available that day. format=csv or format=pdf downloads
+// the list for the kitchen.
+func (ph *PrepListHandler) GetPrepListHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	date := time.Now()
+	if value := c.Query("date"); value != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+			return
+		}
+		date = parsed
+	}
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+
+	bufferPercent := defaultPrepBufferPercent
+	if value := c.Query("buffer"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 || parsed > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid buffer, expected a percent between 0 and 100"})
+			return
+		}
+		bufferPercent = parsed
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" && format != "pdf" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format, expected json, csv or pdf"})
+		return
+	}
+
+	forecast, err := ph.PrepListStore.GetDayForecast(user.OrganizationID, date)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No demand forecast for this date, predict the demand first"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate prep list"})
+		return
+	}
+
+	sales, err := ph.PrepListStore.GetItemSales(user.OrganizationID, date.Weekday(), date.AddDate(0, 0, -7*prepHistoryWeeks))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate prep list"})
+		return
+	}
+
+	// Items off the menu that day are left out, their share goes to the items still served
+	windows, err := ph.ItemAvailabilityStore.GetAvailabilityWindows(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate prep list"})
+		return
+	}
+	unavailable := UnavailableItemsOn(windows, date)
+	available := make([]database.ItemSales, 0, len(sales))
+	for _, item := range sales {
+		if !unavailable[item.ItemID] {
+			available = append(available, item)
+		}
+	}
+
+	list := BuildPrepList(*forecast, available, bufferPercent)
+
+	switch format {
+	case "csv":
+		var buf bytes.Buffer
+		writer := csv.NewWriter(&buf)
+		writer.Write([]string{"item_id", "item", "share_percent", "expected_units", "suggested_quantity"})
+		for _, item := range list.Items {
+			writer.Write([]string{
+				item.ItemID.String(),
+				item.Name,
+				strconv.FormatFloat(item.SharePercent, 'f', 2, 64),
+				strconv.FormatFloat(item.ExpectedUnits, 'f', 1, 64),
+				strconv.Itoa(item.SuggestedQuantity),
+			})
+		}
+		writer.Flush()
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="prep-list-%s.csv"`, list.Date))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+	case "pdf":
+		rows := make([][]string, 0, len(list.Items))
+		for _, item := range list.Items {
+			rows = append(rows, []string{
+				item.Name,
+				strconv.Itoa(item.SuggestedQuantity),
+				strconv.FormatFloat(item.ExpectedUnits, 'f', 1, 64),
+				strconv.FormatFloat(item.SharePercent, 'f', 1, 64) + "%",
+			})
+		}
+		title := fmt.Sprintf("Prep list %s - %d items forecast, %d%% buffer", list.Date, list.ForecastItems, list.BufferPercent)
+		branding := pdfBranding(ph.OrgStore, ph.BrandingStore, ph.Logger, user.OrganizationID)
+		pdf := service.RenderBrandedTablePDF(branding, title, []string{"Item", "Prepare", "Expected", "Share"}, rows)
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="prep-list-%s.pdf"`, list.Date))
+		c.Data(http.StatusOK, "application/pdf", pdf)
+	default:
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Prep list generated successfully",
+			"data":    list,
+		})
+	}
+}
@@ -0,0 +1,344 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// defaultMaxWeeklyHours is the weekly hours above which an employee works overtime when neither the
+	// employee nor the organization has set a maximum
+	defaultMaxWeeklyHours = 40
+	// overtimePayRate is the multiple of the hourly salary paid for overtime hours
+	overtimePayRate = 1.5
+)
+
+// StaffMember is an employee of the organization with their organization roles
+type StaffMember struct {
+	User  *database.User
+	Roles []string
+}
+
+// RemovedShift is a future working shift of the removed employee, with the employee taking it over when
+// it could be covered
+type RemovedShift struct {
+	ShiftID       uuid.UUID  `json:"shift_id"`
+	Date          string     `json:"date"`
+	Day           string     `json:"day"`
+	StartTime     string     `json:"start_time"`
+	EndTime       string     `json:"end_time"`
+	Hours         float64    `json:"hours"`
+	CoveredBy     *uuid.UUID `json:"covered_by,omitempty"`
+	CoveredByName string     `json:"covered_by_name,omitempty"`
+}
+
+// ExtraHours is the hours an employee takes over from the removed employee and the overtime they cause.
+// OvertimeCost is nil when the employee has no hourly salary.
+type ExtraHours struct {
+	EmployeeID    uuid.UUID `json:"employee_id"`
+	FullName      string    `json:"full_name"`
+	Shifts        int       `json:"shifts"`
+	ExtraHours    float64   `json:"extra_hours"`
+	OvertimeHours float64   `json:"overtime_hours"`
+	OvertimeCost  *float64  `json:"overtime_cost"`
+}
+
+// RemovalImpact is what removing an employee from the future schedules costs the others. Shifts nobody
+// is free to take are listed as uncovered slots. The overtime cost leaves out the employees without an
+// hourly salary, OvertimeCostComplete tells whether there were any.
+type RemovalImpact struct {
+	EmployeeID           uuid.UUID      `json:"employee_id"`
+	FullName             string         `json:"full_name"`
+	RemovedShifts        int            `json:"removed_shifts"`
+	RemovedHours         float64        `json:"removed_hours"`
+	Reassigned           []RemovedShift `json:"reassigned"`
+	UncoveredSlots       []RemovedShift `json:"uncovered_slots"`
+	UncoveredHours       float64        `json:"uncovered_hours"`
+	ExtraHours           []ExtraHours   `json:"extra_hours"`
+	OvertimeHours        float64        `json:"overtime_hours"`
+	OvertimeCost         float64        `json:"overtime_cost"`
+	OvertimeCostComplete bool           `json:"overtime_cost_complete"`
+}
+
+type shiftPeriod struct {
+	start time.Time
+	end   time.Time
+}
+
+// periodOfShift is when a shift takes place, a shift ending at or before its start ends the next day
+func periodOfShift(shift database.Shift) (shiftPeriod, bool) {
+	at := func(clock string) (time.Time, bool) {
+		t, err := time.Parse(time.TimeOnly, clock)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Date(shift.Date.Year(), shift.Date.Month(), shift.Date.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.Local), true
+	}
+	start, ok := at(shift.StartTime)
+	if !ok {
+		return shiftPeriod{}, false
+	}
+	end, ok := at(shift.EndTime)
+	if !ok {
+		return shiftPeriod{}, false
+	}
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return shiftPeriod{start: start, end: end}, true
+}
+
+func (p shiftPeriod) overlaps(other shiftPeriod) bool {
+	return p.start.Before(other.end) && other.start.Before(p.end)
+}
+
+func (p shiftPeriod) hours() float64 {
+	return p.end.Sub(p.start).Hours()
+}
+
+// isoWeek identifies the week of a shift for the weekly hours
+func isoWeek(t time.Time) [2]int {
+	year, week := t.ISOWeek()
+	return [2]int{year, week}
+}
+
+func roundHours(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// SimulateEmployeeRemoval takes the removed employee off their working shifts starting after now and hands
+// each shift to the employee free at that time with the fewest hours that week. When the removed employee
+// has organization roles, only employees sharing one of them can take over. Overtime is counted on the
+// weekly hours above the employee's maximum, or maxWeeklyHours, and paid at 1.5 times the hourly salary.
+// shifts holds the shifts of the whole organization from the start of the current week.
+func SimulateEmployeeRemoval(removed StaffMember, staff []StaffMember, shifts []database.Shift, now time.Time, maxWeeklyHours int) RemovalImpact {
+	impact := RemovalImpact{
+		EmployeeID:           removed.User.ID,
+		FullName:             removed.User.FullName,
+		Reassigned:           []RemovedShift{},
+		UncoveredSlots:       []RemovedShift{},
+		ExtraHours:           []ExtraHours{},
+		OvertimeCostComplete: true,
+	}
+	if maxWeeklyHours <= 0 {
+		maxWeeklyHours = defaultMaxWeeklyHours
+	}
+
+	removedRoles := make(map[string]bool, len(removed.Roles))
+	for _, role := range removed.Roles {
+		removedRoles[role] = true
+	}
+	var candidates []StaffMember
+	for _, member := range staff {
+		if member.User.ID == removed.User.ID || member.User.UserRole != "employee" {
+			continue
+		}
+		shares := len(removedRoles) == 0
+		for _, role := range member.Roles {
+			shares = shares || removedRoles[role]
+		}
+		if shares {
+			candidates = append(candidates, member)
+		}
+	}
+	// ties go to the employee first by name
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].User.FullName < candidates[j].User.FullName
+	})
+
+	busy := make(map[uuid.UUID][]shiftPeriod)
+	weeklyHours := make(map[uuid.UUID]map[[2]int]float64)
+	addHours := func(employeeID uuid.UUID, week [2]int, hours float64) {
+		if weeklyHours[employeeID] == nil {
+			weeklyHours[employeeID] = make(map[[2]int]float64)
+		}
+		weeklyHours[employeeID][week] += hours
+	}
+	var toCover []database.Shift
+	for _, shift := range shifts {
+		period, ok := periodOfShift(shift)
+		if !ok {
+			continue
+		}
+		if shift.EmployeeID == removed.User.ID {
+			if shift.ShiftType == database.ShiftWorking && period.start.After(now) {
+				toCover = append(toCover, shift)
+			}
+			continue
+		}
+		busy[shift.EmployeeID] = append(busy[shift.EmployeeID], period)
+		if shift.ShiftType == database.ShiftWorking {
+			addHours(shift.EmployeeID, isoWeek(period.start), period.hours())
+		}
+	}
+	baseHours := make(map[uuid.UUID]map[[2]int]float64, len(weeklyHours))
+	for employeeID, weeks := range weeklyHours {
+		baseHours[employeeID] = make(map[[2]int]float64, len(weeks))
+		for week, hours := range weeks {
+			baseHours[employeeID][week] = hours
+		}
+	}
+
+	extra := make(map[uuid.UUID]*ExtraHours)
+	var extraOrder []uuid.UUID
+	for _, shift := range toCover {
+		period, _ := periodOfShift(shift)
+		week := isoWeek(period.start)
+		slot := RemovedShift{
+			ShiftID:   shift.ID,
+			Date:      shift.Date.Format(time.DateOnly),
+			Day:       shift.Day,
+			StartTime: shift.StartTime,
+			EndTime:   shift.EndTime,
+			Hours:     roundHours(period.hours()),
+		}
+		impact.RemovedShifts++
+		impact.RemovedHours += period.hours()
+
+		var substitute *database.User
+		for _, candidate := range candidates {
+			free := true
+			for _, other := range busy[candidate.User.ID] {
+				if other.overlaps(period) {
+					free = false
+					break
+				}
+			}
+			if free && (substitute == nil || weeklyHours[candidate.User.ID][week] < weeklyHours[substitute.ID][week]) {
+				substitute = candidate.User
+			}
+		}
+		if substitute == nil {
+			impact.UncoveredSlots = append(impact.UncoveredSlots, slot)
+			impact.UncoveredHours += period.hours()
+			continue
+		}
+
+		busy[substitute.ID] = append(busy[substitute.ID], period)
+		addHours(substitute.ID, week, period.hours())
+		slot.CoveredBy = &substitute.ID
+		slot.CoveredByName = substitute.FullName
+		impact.Reassigned = append(impact.Reassigned, slot)
+		if extra[substitute.ID] == nil {
+			extra[substitute.ID] = &ExtraHours{EmployeeID: substitute.ID, FullName: substitute.FullName}
+			extraOrder = append(extraOrder, substitute.ID)
+		}
+		extra[substitute.ID].Shifts++
+		extra[substitute.ID].ExtraHours += period.hours()
+	}
+
+	users := make(map[uuid.UUID]*database.User, len(candidates))
+	for _, candidate := range candidates {
+		users[candidate.User.ID] = candidate.User
+	}
+	for _, employeeID := range extraOrder {
+		hours := extra[employeeID]
+		limit := float64(maxWeeklyHours)
+		if userMax := users[employeeID].MaxHoursPerWeek; userMax != nil && *userMax > 0 {
+			limit = float64(*userMax)
+		}
+		// only the overtime caused by the extra hours, not the overtime already scheduled
+		for week, total := range weeklyHours[employeeID] {
+			before := baseHours[employeeID][week]
+			hours.OvertimeHours += math.Max(total-limit, 0) - math.Max(before-limit, 0)
+		}
+		hours.ExtraHours = roundHours(hours.ExtraHours)
+		hours.OvertimeHours = roundHours(hours.OvertimeHours)
+		impact.OvertimeHours += hours.OvertimeHours
+		if salary := users[employeeID].SalaryPerHour; salary != nil {
+			cost := math.Round(hours.OvertimeHours**salary*overtimePayRate*100) / 100
+			hours.OvertimeCost = &cost
+			impact.OvertimeCost += cost
+		} else if hours.OvertimeHours > 0 {
+			impact.OvertimeCostComplete = false
+		}
+		impact.ExtraHours = append(impact.ExtraHours, *hours)
+	}
+
+	impact.RemovedHours = roundHours(impact.RemovedHours)
+	impact.UncoveredHours = roundHours(impact.UncoveredHours)
+	impact.OvertimeHours = roundHours(impact.OvertimeHours)
+	impact.OvertimeCost = math.Round(impact.OvertimeCost*100) / 100
+	return impact
+}
+
+// EmployeeImpactAnalysisHandler simulates removing the employee from the future schedules, without
+// changing them, and reports the shifts nobody could cover and the extra hours and overtime of the others
+func (sh *ScheduleHandler) EmployeeImpactAnalysisHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can analyze the impact of removing an employee"})
+		return
+	}
+
+	employeeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid employee ID"})
+		return
+	}
+
+	users, err := sh.UserStore.GetUsersByOrganization(user.OrganizationID)
+	if err != nil {
+		sh.Logger.Error("failed to get employees", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get employees"})
+		return
+	}
+	removed := -1
+	staff := make([]StaffMember, 0, len(users))
+	for _, member := range users {
+		if member.ID != employeeID && member.UserRole != "employee" {
+			continue
+		}
+		roles, err := sh.UserRolesStore.GetUserRoles(member.ID, user.OrganizationID)
+		if err != nil {
+			sh.Logger.Error("failed to get employee roles", "error", err, "user_id", member.ID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get employee roles"})
+			return
+		}
+		staff = append(staff, StaffMember{User: member, Roles: roles})
+		if member.ID == employeeID {
+			removed = len(staff) - 1
+		}
+	}
+	if removed < 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		return
+	}
+
+	rules, err := sh.RulesStore.GetRulesByOrganizationID(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get the organization rules"})
+		return
+	}
+	maxWeeklyHours := defaultMaxWeeklyHours
+	if rules != nil && rules.MaxWeeklyHours > 0 {
+		maxWeeklyHours = rules.MaxWeeklyHours
+	}
+
+	// the hours already worked this week count towards the overtime
+	now := time.Now()
+	weekStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).
+		AddDate(0, 0, -(int(now.Weekday())+6)%7)
+	shifts, err := sh.ScheduleStore.GetShiftsFrom(user.OrganizationID, weekStart)
+	if err != nil {
+		sh.Logger.Error("failed to get shifts", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get shifts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Impact analysis completed successfully",
+		"data":    SimulateEmployeeRemoval(staff[removed], staff, shifts, now, maxWeeklyHours),
+	})
+}
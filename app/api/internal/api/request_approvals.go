@@ -0,0 +1,205 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// requestTypes are the types of requests employees can submit, in the order chains are listed
+var requestTypes = []string{"calloff", "holiday", "resign"}
+
+// ApprovalChainRequest sets the roles that must approve a request type, in order
+type ApprovalChainRequest struct {
+	Steps []string `json:"steps" binding:"required,min=1,max=5,dive,oneof=manager admin"`
+}
+
+// requestApprovals returns the steps of the approval chain of the request. Requests submitted before
+// chains existed, or whose chain could not be started, get the current chain of their type.
+func (h *EmployeeHandler) requestApprovals(orgID uuid.UUID, request *database.Request) ([]database.RequestApproval, error) {
+	approvals, err := h.approvalStore.GetRequestApprovals([]uuid.UUID{request.ID})
+	if err != nil {
+		return nil, err
+	}
+	if steps := approvals[request.ID]; len(steps) > 0 {
+		return steps, nil
+	}
+
+	chain, err := h.approvalStore.GetApprovalChain(orgID, request.Type)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.approvalStore.StartApprovals(request.ID, chain); err != nil {
+		return nil, err
+	}
+	steps := make([]database.RequestApproval, len(chain))
+	for i, role := range chain {
+		steps[i] = database.RequestApproval{Step: i + 1, ApproverRole: role}
+	}
+	return steps, nil
+}
+
+// pendingApprovals returns the steps of the chain that are not decided yet
+func pendingApprovals(steps []database.RequestApproval) []database.RequestApproval {
+	pending := []database.RequestApproval{}
+	for _, step := range steps {
+		if step.Decision == nil {
+			pending = append(pending, step)
+		}
+	}
+	return pending
+}
+
+// stepsDecidedBy returns the pending steps a user with the role decides, nil when they cannot decide
+// the current step. Admins can decide any step and their approval signs off the rest of the chain,
+// managers only decide manager steps.
+func stepsDecidedBy(role string, pending []database.RequestApproval, decision string) []int {
+	if len(pending) == 0 {
+		return nil
+	}
+	if role == "admin" && decision == database.ApprovalApproved {
+		steps := make([]int, len(pending))
+		for i, step := range pending {
+			steps[i] = step.Step
+		}
+		return steps
+	}
+	if role == "admin" || role == pending[0].ApproverRole {
+		return []int{pending[0].Step}
+	}
+	return nil
+}
+
+// notifyNextApprovers tells the approvers of the next step and the employee that the request moved on
+func (h *EmployeeHandler) notifyNextApprovers(orgID uuid.UUID, employee *database.User, request *database.Request, approvedBy, nextRole string) {
+	var approverEmails []string
+	var err error
+	if nextRole == "admin" {
+		approverEmails, err = h.orgStore.GetAdminEmailsByOrgID(orgID)
+	} else {
+		approverEmails, err = h.orgStore.GetManagerEmailsByOrgID(orgID)
+	}
+	if err != nil {
+		h.Logger.Error("failed to get next approver emails", "error", err, "org_id", orgID)
+	}
+	if len(approverEmails) > 0 {
+		if err := h.EmailService.SendRequestAwaitingApprovalEmail(approverEmails, employee.FullName, request.Type, approvedBy); err != nil {
+			h.Logger.Error("failed to send request awaiting approval email", "error", err, "request_id", request.ID)
+		}
+	}
+	if err := h.EmailService.SendRequestStepApprovedEmail(employee.Email, employee.FullName, request.Type, nextRole); err != nil {
+		h.Logger.Error("failed to send request step approved email", "error", err, "email", employee.Email)
+	}
+}
+
+// GetApprovalChainsHandler lists the approval chain of every request type, configured or default
+func (h *EmployeeHandler) GetApprovalChainsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access rules"})
+		return
+	}
+
+	configured, err := h.approvalStore.GetApprovalChains(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve approval chains"})
+		return
+	}
+
+	chains := make([]database.ApprovalChain, 0, len(requestTypes))
+	for _, requestType := range requestTypes {
+		chain := database.ApprovalChain{RequestType: requestType, Steps: database.DefaultApprovalChain(requestType), Default: true}
+		for _, custom := range configured {
+			if custom.RequestType == requestType {
+				chain = custom
+			}
+		}
+		chains = append(chains, chain)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Approval chains retrieved successfully", "data": chains})
+}
+
+// SetApprovalChainHandler replaces the approval chain of the :type request type. Requests already
+// submitted keep their chain.
+func (h *EmployeeHandler) SetApprovalChainHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change approval chains"})
+		return
+	}
+
+	requestType := c.Param("type")
+	if !slices.Contains(requestTypes, requestType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be one of calloff, holiday, resign"})
+		return
+	}
+
+	var req ApprovalChainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// an admin approval signs off the rest of the chain, so manager steps after it would be skipped
+	if i := slices.Index(req.Steps, "admin"); i >= 0 && slices.Contains(req.Steps[i:], "manager") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "manager steps must come before admin steps"})
+		return
+	}
+
+	if err := h.approvalStore.SetApprovalChain(user.OrganizationID, requestType, req.Steps, user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save approval chain"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Approval chain saved successfully",
+		"data":    database.ApprovalChain{RequestType: requestType, Steps: req.Steps},
+	})
+}
+
+// ResetApprovalChainHandler restores the default approval chain of the :type request type
+func (h *EmployeeHandler) ResetApprovalChainHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change approval chains"})
+		return
+	}
+
+	requestType := c.Param("type")
+	if !slices.Contains(requestTypes, requestType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be one of calloff, holiday, resign"})
+		return
+	}
+
+	if err := h.approvalStore.DeleteApprovalChain(user.OrganizationID, requestType); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "The request type already uses the default chain"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset approval chain"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Approval chain reset successfully",
+		"data":    database.ApprovalChain{RequestType: requestType, Steps: database.DefaultApprovalChain(requestType), Default: true},
+	})
+}
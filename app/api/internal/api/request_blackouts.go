@@ -0,0 +1,139 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// BlackoutRequest blocks holiday requests between two days (inclusive)
+type BlackoutRequest struct {
+	StartDate string `json:"start_date" binding:"required"`
+	EndDate   string `json:"end_date" binding:"required"`
+	Reason    string `json:"reason" binding:"required,max=255"`
+	Action    string `json:"action" binding:"omitempty,oneof=reject flag"`
+}
+
+// blackoutMessage tells the employee why their holiday request overlaps the blackout
+func blackoutMessage(blackout database.TimeOffBlackout) string {
+	return fmt.Sprintf("Holidays are blocked from %s to %s: %s",
+		blackout.StartDate.Format(time.DateOnly), blackout.EndDate.Format(time.DateOnly), blackout.Reason)
+}
+
+// GetBlackoutsHandler lists the blackouts of the organization that have not ended yet, so that
+// employees can plan their holidays around them
+func (h *EmployeeHandler) GetBlackoutsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	blackouts, err := h.blackoutStore.ListBlackouts(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve blackouts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Blackouts retrieved successfully", "data": blackouts})
+}
+
+// CreateBlackoutHandler blocks holiday requests during a peak period. Requests overlapping a reject
+// blackout are refused when submitted, those overlapping a flag blackout can only be approved by an
+// admin.
+func (h *EmployeeHandler) CreateBlackoutHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		h.Logger.Warn("forbidden blackout creation", "user_id", user.ID, "role", user.UserRole)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can set blackouts"})
+		return
+	}
+
+	var req BlackoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	startDate, err := time.Parse(time.DateOnly, req.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start_date must use the YYYY-MM-DD format"})
+		return
+	}
+	endDate, err := time.Parse(time.DateOnly, req.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_date must use the YYYY-MM-DD format"})
+		return
+	}
+	if endDate.Before(startDate) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_date must not be before start_date"})
+		return
+	}
+	now := time.Now()
+	if endDate.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Past periods cannot be blocked"})
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+
+	createdBy := user.ID
+	blackout := &database.TimeOffBlackout{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Reason:    reason,
+		Action:    req.Action,
+		CreatedBy: &createdBy,
+	}
+	if err := h.blackoutStore.CreateBlackout(user.OrganizationID, blackout); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create blackout"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Blackout created successfully", "data": blackout})
+}
+
+// DeleteBlackoutHandler lifts a blackout. Requests already refused stay refused.
+func (h *EmployeeHandler) DeleteBlackoutHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		h.Logger.Warn("forbidden blackout deletion", "user_id", user.ID, "role", user.UserRole)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can lift blackouts"})
+		return
+	}
+
+	blackoutID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid blackout ID"})
+		return
+	}
+
+	if err := h.blackoutStore.DeleteBlackout(user.OrganizationID, blackoutID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Blackout not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete blackout"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Blackout deleted successfully"})
+}
@@ -30,6 +30,7 @@ type CreateRoleRequest struct {
 	ItemsPerRolePerHour *int   `json:"items_per_role_per_hour"`
 	NeedForDemand       bool   `json:"need_for_demand"`
 	Independent         *bool  `json:"independent"`
+	SoloCoverage        bool   `json:"solo_coverage"`
 }
 
 // UpdateRoleRequest represents the request body for updating a role
@@ -38,6 +39,7 @@ type UpdateRoleRequest struct {
 	ItemsPerRolePerHour *int  `json:"items_per_role_per_hour"`
 	NeedForDemand       bool  `json:"need_for_demand"`
 	Independent         *bool `json:"independent"`
+	SoloCoverage        bool  `json:"solo_coverage"`
 }
 
 // GetAllRoles godoc
@@ -134,6 +136,7 @@ func (h *RolesHandler) CreateRole(c *gin.Context) {
 		ItemsPerRolePerHour: req.ItemsPerRolePerHour,
 		NeedForDemand:       req.NeedForDemand,
 		Independent:         req.Independent,
+		SoloCoverage:        req.SoloCoverage,
 	}
 
 	if err := h.rolesStore.CreateRole(role); err != nil {
@@ -259,6 +262,7 @@ func (h *RolesHandler) UpdateRole(c *gin.Context) {
 		ItemsPerRolePerHour: req.ItemsPerRolePerHour,
 		NeedForDemand:       req.NeedForDemand,
 		Independent:         req.Independent,
+		SoloCoverage:        req.SoloCoverage,
 	}
 
 	if err := h.rolesStore.UpdateRole(role); err != nil {
@@ -1,12 +1,14 @@
 package api
 
 import (
+	"encoding/json"
 	"log/slog"
 	"net/http"
 
 	"github.com/clockwise/clockwise/backend/internal/database"
 	"github.com/clockwise/clockwise/backend/internal/middleware"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 // RulesHandler handles organization rules-related HTTP requests
@@ -35,22 +37,73 @@ type OperatingHoursRequest struct {
 
 // RulesRequest represents the request body for creating/updating organization rules
 type RulesRequest struct {
-	ShiftMaxHours        int                     `json:"shift_max_hours" binding:"required,min=1"`
-	ShiftMinHours        int                     `json:"shift_min_hours" binding:"required,min=1"`
-	MaxWeeklyHours       int                     `json:"max_weekly_hours" binding:"required,min=1"`
-	MinWeeklyHours       int                     `json:"min_weekly_hours" binding:"required,min=1"`
-	FixedShifts          bool                    `json:"fixed_shifts"`
-	NumberOfShiftsPerDay *int                    `json:"number_of_shifts_per_day"`
-	MeetAllDemand        bool                    `json:"meet_all_demand"`
-	MinRestSlots         int                     `json:"min_rest_slots"`
-	SlotLenHour          float64                 `json:"slot_len_hour" binding:"required,gt=0"`
-	MinShiftLengthSlots  int                     `json:"min_shift_length_slots" binding:"required,min=1"`
-	ReceivingPhone       *bool                   `json:"receiving_phone"`
-	Delivery             *bool                   `json:"delivery"`
-	WaitingTime          int                     `json:"waiting_time" binding:"required,min=0"`
-	AcceptingOrders      *bool                   `json:"accepting_orders"`
-	OperatingHours       []OperatingHoursRequest `json:"operating_hours" binding:"max=7,dive"`
-	ShiftTimes           []database.ShiftTime    `json:"shift_times,omitempty"` // Only if fixed
+	ShiftMaxHours           int      `json:"shift_max_hours" binding:"required,min=1"`
+	ShiftMinHours           int      `json:"shift_min_hours" binding:"required,min=1"`
+	MaxWeeklyHours          int      `json:"max_weekly_hours" binding:"required,min=1"`
+	MinWeeklyHours          int      `json:"min_weekly_hours" binding:"required,min=1"`
+	FixedShifts             bool     `json:"fixed_shifts"`
+	NumberOfShiftsPerDay    *int     `json:"number_of_shifts_per_day"`
+	MeetAllDemand           bool     `json:"meet_all_demand"`
+	MinRestSlots            int      `json:"min_rest_slots"`
+	SlotLenHour             float64  `json:"slot_len_hour" binding:"required,gt=0"`
+	MinShiftLengthSlots     int      `json:"min_shift_length_slots" binding:"required,min=1"`
+	ReceivingPhone          *bool    `json:"receiving_phone"`
+	Delivery                *bool    `json:"delivery"`
+	WaitingTime             int      `json:"waiting_time" binding:"required,min=0"`
+	AcceptingOrders         *bool    `json:"accepting_orders"`
+	WeeklyLaborBudget       *float64 `json:"weekly_labor_budget" binding:"omitempty,gt=0"`
+	PrepBufferMinutes       *int     `json:"prep_buffer_minutes" binding:"omitempty,min=0"`
+	DeliveryMinutes         *int     `json:"delivery_minutes" binding:"omitempty,min=0"`
+	WaitTimeFactor          *float64 `json:"wait_time_factor" binding:"omitempty,gt=0,lte=10"`
+	StandbyPayPercent       *int     `json:"standby_pay_percent" binding:"omitempty,min=0,max=100"`
+	CancellationNoticeHours *int     `json:"cancellation_notice_hours" binding:"omitempty,min=0,max=720"`
+	AutoCloseOrders         *bool    `json:"auto_close_orders"`
+	OrdersPerStaffHour      *int     `json:"orders_per_staff_hour" binding:"omitempty,min=1"`
+	AutoCloseLoadPercent    *int     `json:"auto_close_load_percent" binding:"omitempty,min=1"`
+	AutoReopenLoadPercent   *int     `json:"auto_reopen_load_percent" binding:"omitempty,min=1"`
+	DeliverySLAMinutes      *int     `json:"delivery_sla_minutes" binding:"omitempty,min=1"`
+	SLABreachAlertPercent   *int     `json:"sla_breach_alert_percent" binding:"omitempty,min=1,max=100"`
+	// Predictable scheduling, predictability_notice_days is left out when no such law applies
+	PredictabilityNoticeDays       *int                    `json:"predictability_notice_days" binding:"omitempty,min=1,max=60"`
+	PredictabilityPayHours         *float64                `json:"predictability_pay_hours" binding:"omitempty,min=0,max=24"`
+	PredictabilityLostHoursPercent *int                    `json:"predictability_lost_hours_percent" binding:"omitempty,min=0,max=100"`
+	OperatingHours                 []OperatingHoursRequest `json:"operating_hours" binding:"max=7,dive"`
+	ShiftTimes                     []database.ShiftTime    `json:"shift_times,omitempty"` // Only if fixed
+}
+
+// defaultOrganizationRules are the settings of an organization that never saved its rules
+func defaultOrganizationRules() *database.OrganizationRules {
+	return &database.OrganizationRules{
+		ReceivingPhone:                 true,
+		Delivery:                       true,
+		AcceptingOrders:                true,
+		PrepBufferMinutes:              defaultPrepBufferMinutes,
+		DeliveryMinutes:                defaultDeliveryMinutes,
+		WaitTimeFactor:                 defaultWaitTimeFactor,
+		StandbyPayPercent:              defaultStandbyPayPercent,
+		CancellationNoticeHours:        defaultCancellationNoticeHours,
+		PredictabilityPayHours:         defaultPredictabilityPayHours,
+		PredictabilityLostHoursPercent: defaultPredictabilityLostHoursPercent,
+		OrdersPerStaffHour:             defaultOrdersPerStaffHour,
+		AutoCloseLoadPercent:           defaultAutoCloseLoadPercent,
+		AutoReopenLoadPercent:          defaultAutoReopenLoadPercent,
+		DeliverySLAMinutes:             defaultDeliverySLAMinutes,
+		SLABreachAlertPercent:          defaultSLABreachAlertPercent,
+	}
+}
+
+// sentNull reports whether the JSON body of the request sets key to null
+func sentNull(c *gin.Context, key string) bool {
+	body, ok := c.Get(gin.BodyBytesKey)
+	if !ok {
+		return false
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body.([]byte), &fields); err != nil {
+		return false
+	}
+	value, ok := fields[key]
+	return ok && string(value) == "null"
 }
 
 // RulesResponse represents the response for rules GET
@@ -134,7 +187,8 @@ func (h *RulesHandler) UpdateOrganizationRules(c *gin.Context) {
 	}
 
 	var req RulesRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	// the body is kept to tell settings sent as null from settings left out
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
 		h.Logger.Warn("invalid request body", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
 		return
@@ -185,41 +239,103 @@ func (h *RulesHandler) UpdateOrganizationRules(c *gin.Context) {
 		req.ShiftTimes = nil
 	}
 
-	// Default receiving_phone, delivery, and accepting_orders to true if not provided
-	receivingPhone := true
+	// Settings left out of the request keep their stored value, or their default for new rules
+	current, err := h.rulesStore.GetRulesByOrganizationID(user.OrganizationID)
+	if err != nil {
+		h.Logger.Error("failed to get rules", "error", err, "organization_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve rules"})
+		return
+	}
+	if current == nil {
+		current = defaultOrganizationRules()
+	}
+	rules := *current
+	rules.OrganizationID = user.OrganizationID
+	rules.ShiftMaxHours = req.ShiftMaxHours
+	rules.ShiftMinHours = req.ShiftMinHours
+	rules.MaxWeeklyHours = req.MaxWeeklyHours
+	rules.MinWeeklyHours = req.MinWeeklyHours
+	rules.FixedShifts = req.FixedShifts
+	rules.NumberOfShiftsPerDay = req.NumberOfShiftsPerDay
+	rules.MeetAllDemand = req.MeetAllDemand
+	rules.MinRestSlots = req.MinRestSlots
+	rules.SlotLenHour = req.SlotLenHour
+	rules.MinShiftLengthSlots = req.MinShiftLengthSlots
+	rules.WaitingTime = req.WaitingTime
+	rules.ShiftTimes = req.ShiftTimes
+
 	if req.ReceivingPhone != nil {
-		receivingPhone = *req.ReceivingPhone
+		rules.ReceivingPhone = *req.ReceivingPhone
 	}
-	delivery := true
 	if req.Delivery != nil {
-		delivery = *req.Delivery
+		rules.Delivery = *req.Delivery
 	}
-	acceptingOrders := true
 	if req.AcceptingOrders != nil {
-		acceptingOrders = *req.AcceptingOrders
-	}
-
-	rules := &database.OrganizationRules{
-		OrganizationID:       user.OrganizationID,
-		ShiftMaxHours:        req.ShiftMaxHours,
-		ShiftMinHours:        req.ShiftMinHours,
-		MaxWeeklyHours:       req.MaxWeeklyHours,
-		MinWeeklyHours:       req.MinWeeklyHours,
-		FixedShifts:          req.FixedShifts,
-		NumberOfShiftsPerDay: req.NumberOfShiftsPerDay,
-		MeetAllDemand:        req.MeetAllDemand,
-		MinRestSlots:         req.MinRestSlots,
-		SlotLenHour:          req.SlotLenHour,
-		MinShiftLengthSlots:  req.MinShiftLengthSlots,
-		ReceivingPhone:       receivingPhone,
-		Delivery:             delivery,
-		WaitingTime:          req.WaitingTime,
-		AcceptingOrders:      acceptingOrders,
-		ShiftTimes:           req.ShiftTimes,
+		rules.AcceptingOrders = *req.AcceptingOrders
+	}
+	// The budget and the predictability notice are turned off with an explicit null
+	if req.WeeklyLaborBudget != nil || sentNull(c, "weekly_labor_budget") {
+		rules.WeeklyLaborBudget = req.WeeklyLaborBudget
+	}
+	// Wait time estimate tuning, see EstimateWaitTime
+	if req.PrepBufferMinutes != nil {
+		rules.PrepBufferMinutes = *req.PrepBufferMinutes
+	}
+	if req.DeliveryMinutes != nil {
+		rules.DeliveryMinutes = *req.DeliveryMinutes
+	}
+	if req.WaitTimeFactor != nil {
+		rules.WaitTimeFactor = *req.WaitTimeFactor
+	}
+	// Share of the hourly salary paid for standby shifts that are not activated
+	if req.StandbyPayPercent != nil {
+		rules.StandbyPayPercent = *req.StandbyPayPercent
+	}
+	// Shifts cancelled with less notice are late cancellations
+	if req.CancellationNoticeHours != nil {
+		rules.CancellationNoticeHours = *req.CancellationNoticeHours
+	}
+	// Predictability pay owed for late schedule changes, see service.AssessPredictabilityPay
+	if req.PredictabilityNoticeDays != nil || sentNull(c, "predictability_notice_days") {
+		rules.PredictabilityNoticeDays = req.PredictabilityNoticeDays
+	}
+	if req.PredictabilityPayHours != nil {
+		rules.PredictabilityPayHours = *req.PredictabilityPayHours
+	}
+	if req.PredictabilityLostHoursPercent != nil {
+		rules.PredictabilityLostHoursPercent = *req.PredictabilityLostHoursPercent
+	}
+	// Order auto-close, see service.EvaluateOrderLoad
+	if req.AutoCloseOrders != nil {
+		rules.AutoCloseOrders = *req.AutoCloseOrders
+	}
+	if req.OrdersPerStaffHour != nil {
+		rules.OrdersPerStaffHour = *req.OrdersPerStaffHour
+	}
+	if req.AutoCloseLoadPercent != nil {
+		rules.AutoCloseLoadPercent = *req.AutoCloseLoadPercent
+	}
+	if req.AutoReopenLoadPercent != nil {
+		rules.AutoReopenLoadPercent = *req.AutoReopenLoadPercent
+	}
+	// Reopening below the closing load keeps orders from flapping between the two
+	if rules.AutoReopenLoadPercent >= rules.AutoCloseLoadPercent {
+		h.Logger.Warn("auto-reopen load not below auto-close load",
+			"reopen", rules.AutoReopenLoadPercent,
+			"close", rules.AutoCloseLoadPercent)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "auto_reopen_load_percent must be lower than auto_close_load_percent"})
+		return
+	}
+	// Delivery SLA, see service.EvaluateSLABreaches
+	if req.DeliverySLAMinutes != nil {
+		rules.DeliverySLAMinutes = *req.DeliverySLAMinutes
+	}
+	if req.SLABreachAlertPercent != nil {
+		rules.SLABreachAlertPercent = *req.SLABreachAlertPercent
 	}
 
 	// Use upsert to handle both create and update scenarios
-	if err := h.rulesStore.UpsertRules(rules); err != nil {
+	if err := h.rulesStore.UpsertRules(&rules); err != nil {
 		h.Logger.Error("failed to save rules", "error", err, "organization_id", user.OrganizationID)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save rules"})
 		return
@@ -273,7 +389,7 @@ func (h *RulesHandler) UpdateOrganizationRules(c *gin.Context) {
 	}
 
 	response := RulesResponse{
-		Rules:          rules,
+		Rules:          &rules,
 		OperatingHours: currentOperatingHours,
 	}
 
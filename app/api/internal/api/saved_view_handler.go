@@ -0,0 +1,289 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Most filters a view can hold, and longest value of a filter
+const (
+	maxViewFilters     = 20
+	maxViewFilterValue = 200
+)
+
+// viewResources are the list endpoints views can be saved for, relative to the organization
+var viewResources = map[string]string{
+	"orders":       "/orders/all",
+	"deliveries":   "/deliveries/all",
+	"items":        "/items/all",
+	"employees":    "/staffing/employees",
+	"campaigns":    "/campaigns/all",
+	"events":       "/events",
+	"job-postings": "/job-postings",
+	"audit-log":    "/audit-log",
+}
+
+var viewFilterPattern = regexp.MustCompile(`^[a-z][a-z0-9_.]{0,49}$`)
+
+type SavedViewHandler struct {
+	SavedViewStore database.SavedViewStore
+	Logger         *slog.Logger
+}
+
+func NewSavedViewHandler(savedViewStore database.SavedViewStore, logger *slog.Logger) *SavedViewHandler {
+	return &SavedViewHandler{
+		SavedViewStore: savedViewStore,
+		Logger:         logger,
+	}
+}
+
+// SavedViewRequest creates or replaces a view. Resource cannot change once the view is saved.
+type SavedViewRequest struct {
+	Name     string            `json:"name" binding:"required,max=100"`
+	Resource string            `json:"resource"`
+	Filters  map[string]string `json:"filters"`
+	Shared   bool              `json:"shared"`
+}
+
+// SavedViewResponse is a view with the path of its list endpoint, filters included
+type SavedViewResponse struct {
+	database.SavedView
+	Path string `json:"path"`
+}
+
+// ViewFromRequest validates the name and filters of a view
+func ViewFromRequest(req SavedViewRequest) (*database.SavedView, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if len(req.Filters) > maxViewFilters {
+		return nil, fmt.Errorf("a view can have at most %d filters", maxViewFilters)
+	}
+
+	filters := make(map[string]string, len(req.Filters))
+	for key, value := range req.Filters {
+		if !viewFilterPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid filter %q, expected a query parameter name", key)
+		}
+		if len(value) > maxViewFilterValue {
+			return nil, fmt.Errorf("filter %s must be at most %d characters", key, maxViewFilterValue)
+		}
+		filters[key] = value
+	}
+	return &database.SavedView{Name: name, Resource: req.Resource, Filters: filters, Shared: req.Shared}, nil
+}
+
+// viewResponse resolves the path of the view under the API version the request was made on
+func viewResponse(c *gin.Context, orgID uuid.UUID, view database.SavedView) SavedViewResponse {
+	prefix, _, _ := strings.Cut(c.FullPath(), "/:org")
+	path := prefix + "/" + orgID.String() + viewResources[view.Resource]
+
+	query := url.Values{}
+	for key, value := range view.Filters {
+		query.Set(key, value)
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return SavedViewResponse{SavedView: view, Path: path}
+}
+
+// visibleView returns the view of the :id parameter, writing a 404 response when it is private to
+// another user
+func (vh *SavedViewHandler) visibleView(c *gin.Context, user *database.User) *database.SavedView {
+	viewID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid view ID"})
+		return nil
+	}
+
+	view, err := vh.SavedViewStore.GetView(user.OrganizationID, viewID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "View not found"})
+			return nil
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve view"})
+		return nil
+	}
+	if !view.Shared && view.CreatedBy != user.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "View not found"})
+		return nil
+	}
+	return view
+}
+
+// GetViewsHandler lists the shared views of the organization and the user's own views, of one
+// resource with ?resource=
+func (vh *SavedViewHandler) GetViewsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	resource := c.Query("resource")
+	if _, ok := viewResources[resource]; resource != "" && !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown resource"})
+		return
+	}
+
+	views, err := vh.SavedViewStore.ListViews(user.OrganizationID, user.ID, resource)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve views"})
+		return
+	}
+
+	data := make([]SavedViewResponse, len(views))
+	for i, view := range views {
+		data[i] = viewResponse(c, user.OrganizationID, view)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Views retrieved successfully", "data": data})
+}
+
+// GetViewHandler returns a view with the path to apply it
+func (vh *SavedViewHandler) GetViewHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	view := vh.visibleView(c, user)
+	if view == nil {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "View retrieved successfully", "data": viewResponse(c, user.OrganizationID, *view)})
+}
+
+// CreateViewHandler saves a view for the user. Only admins and managers can share views with the
+// organization.
+func (vh *SavedViewHandler) CreateViewHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	var req SavedViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, ok := viewResources[req.Resource]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown resource"})
+		return
+	}
+	view, err := ViewFromRequest(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if view.Shared && user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can share views"})
+		return
+	}
+
+	view.CreatedBy = user.ID
+	if err := vh.SavedViewStore.CreateView(user.OrganizationID, view); err != nil {
+		if errors.Is(err, database.ErrSavedViewExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": "You already have a view with this name"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save view"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "View saved successfully", "data": viewResponse(c, user.OrganizationID, *view)})
+}
+
+// UpdateViewHandler replaces the name, filters and sharing of a view. Only its creator and admins can
+// change it.
+func (vh *SavedViewHandler) UpdateViewHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	current := vh.visibleView(c, user)
+	if current == nil {
+		return
+	}
+	if current.CreatedBy != user.ID && user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot change this view"})
+		return
+	}
+
+	var req SavedViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Resource != "" && req.Resource != current.Resource {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The resource of a view cannot change"})
+		return
+	}
+	view, err := ViewFromRequest(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if view.Shared && !current.Shared && user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can share views"})
+		return
+	}
+
+	view.ID, view.CreatedBy, view.Resource, view.CreatedAt = current.ID, current.CreatedBy, current.Resource, current.CreatedAt
+	if err := vh.SavedViewStore.UpdateView(user.OrganizationID, view); err != nil {
+		if errors.Is(err, database.ErrSavedViewExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": "The creator already has a view with this name"})
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "View not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save view"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "View saved successfully", "data": viewResponse(c, user.OrganizationID, *view)})
+}
+
+// DeleteViewHandler removes a view. Only its creator and admins can remove it.
+func (vh *SavedViewHandler) DeleteViewHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	view := vh.visibleView(c, user)
+	if view == nil {
+		return
+	}
+	if view.CreatedBy != user.ID && user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot delete this view"})
+		return
+	}
+
+	if err := vh.SavedViewStore.DeleteView(user.OrganizationID, view.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "View not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete view"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "View deleted successfully"})
+}
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AcknowledgeShiftRequest struct {
+	ScheduleDate string `json:"schedule_date" binding:"required"`
+	StartTime    string `json:"start_time" binding:"required"`
+	EndTime      string `json:"end_time" binding:"required"`
+}
+
+// AcknowledgeScheduleRequest lists the shifts to acknowledge
+type AcknowledgeScheduleRequest struct {
+	Shifts []AcknowledgeShiftRequest `json:"shifts" binding:"omitempty,dive"`
+}
+
+type EmployeeAcknowledgmentSummary struct {
+	EmployeeID         uuid.UUID  `json:"employee_id"`
+	EmployeeName       string     `json:"employee_name"`
+	TotalShifts        int        `json:"total_shifts"`
+	AcknowledgedShifts int        `json:"acknowledged_shifts"`
+	PendingShifts      int        `json:"pending_shifts"`
+	FullyAcknowledged  bool       `json:"fully_acknowledged"`
+	LastRemindedAt     *time.Time `json:"last_reminded_at"`
+}
+
+type ScheduleAcknowledgmentDashboard struct {
+	TotalShifts           int                             `json:"total_shifts"`
+	AcknowledgedShifts    int                             `json:"acknowledged_shifts"`
+	PendingShifts         int                             `json:"pending_shifts"`
+	AcknowledgmentPercent float64                         `json:"acknowledgment_percent"`
+	Employees             []EmployeeAcknowledgmentSummary `json:"employees"`
+	Shifts                []database.ShiftAcknowledgment  `json:"shifts"`
+}
+
+// AcknowledgeScheduleHandler lets the current employee confirm they have seen their published shifts
+func (sh *ScheduleHandler) AcknowledgeScheduleHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole == "admin" {
+		sh.Logger.Warn("forbidden schedule acknowledgment", "user_id", user.ID, "role", user.UserRole)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied. Admin don't have schedules"})
+		return
+	}
+
+	// the body is optional, an empty request acknowledges every upcoming shift
+	var req AcknowledgeScheduleRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			sh.Logger.Warn("invalid schedule acknowledgment request", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	shifts := make([]database.ShiftKey, 0, len(req.Shifts))
+	for _, shift := range req.Shifts {
+		date, err := time.Parse(time.DateOnly, shift.ScheduleDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "schedule_date must use the YYYY-MM-DD format"})
+			return
+		}
+		if _, err := parseClockTime(shift.StartTime); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "start_time must use the HH:MM or HH:MM:SS format"})
+			return
+		}
+		if _, err := parseClockTime(shift.EndTime); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "end_time must use the HH:MM or HH:MM:SS format"})
+			return
+		}
+		shifts = append(shifts, database.ShiftKey{Date: date, StartTime: shift.StartTime, EndTime: shift.EndTime})
+	}
+
+	acknowledged, err := sh.ScheduleStore.AcknowledgeShifts(user.OrganizationID, user.ID, shifts)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found in organization"})
+			return
+		}
+		sh.Logger.Error("failed to acknowledge schedule", "error", err, "user_id", user.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to acknowledge schedule"})
+		return
+	}
+
+	sh.Logger.Info("schedule acknowledged", "user_id", user.ID, "count", acknowledged)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Schedule acknowledged successfully",
+		"data":    gin.H{"acknowledged_shifts": acknowledged},
+	})
+}
+
+// GetScheduleAcknowledgmentsHandler shows managers which employees have confirmed their upcoming shifts,
+// optionally only for ?employee_id= or ?role= (comma separated) and ?acknowledged=true|false
+func (sh *ScheduleHandler) GetScheduleAcknowledgmentsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		sh.Logger.Warn("forbidden schedule acknowledgment access", "user_id", user.ID, "role", user.UserRole)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied. Only admins and managers can view schedule acknowledgments"})
+		return
+	}
+
+	employeeIDs, ok := queryIDs(c, "employee_id")
+	if !ok {
+		return
+	}
+	filter := database.ShiftFilter{EmployeeIDs: employeeIDs, Roles: queryList(c, "role")}
+	if value := c.Query("acknowledged"); value != "" {
+		acknowledged, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "acknowledged must be true or false"})
+			return
+		}
+		filter.Acknowledged = &acknowledged
+	}
+
+	shifts, err := sh.ScheduleStore.GetShiftAcknowledgments(user.OrganizationID, filter)
+	if err != nil {
+		sh.Logger.Error("failed to get schedule acknowledgments", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve schedule acknowledgments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Schedule acknowledgments retrieved successfully",
+		"data":    summarizeAcknowledgments(shifts),
+	})
+}
+
+func summarizeAcknowledgments(shifts []database.ShiftAcknowledgment) ScheduleAcknowledgmentDashboard {
+	dashboard := ScheduleAcknowledgmentDashboard{
+		Employees: []EmployeeAcknowledgmentSummary{},
+		Shifts:    shifts,
+	}
+	if dashboard.Shifts == nil {
+		dashboard.Shifts = []database.ShiftAcknowledgment{}
+	}
+
+	byEmployee := make(map[uuid.UUID]int)
+	for _, shift := range shifts {
+		i, ok := byEmployee[shift.EmployeeID]
+		if !ok {
+			i = len(dashboard.Employees)
+			byEmployee[shift.EmployeeID] = i
+			dashboard.Employees = append(dashboard.Employees, EmployeeAcknowledgmentSummary{
+				EmployeeID:   shift.EmployeeID,
+				EmployeeName: shift.EmployeeName,
+			})
+		}
+		summary := &dashboard.Employees[i]
+
+		summary.TotalShifts++
+		dashboard.TotalShifts++
+		if shift.AcknowledgedAt != nil {
+			summary.AcknowledgedShifts++
+			dashboard.AcknowledgedShifts++
+		} else {
+			summary.PendingShifts++
+			dashboard.PendingShifts++
+		}
+		if shift.LastRemindedAt != nil && (summary.LastRemindedAt == nil || shift.LastRemindedAt.After(*summary.LastRemindedAt)) {
+			summary.LastRemindedAt = shift.LastRemindedAt
+		}
+	}
+
+	for i := range dashboard.Employees {
+		dashboard.Employees[i].FullyAcknowledged = dashboard.Employees[i].PendingShifts == 0
+	}
+	if dashboard.TotalShifts > 0 {
+		dashboard.AcknowledgmentPercent = math.Round(float64(dashboard.AcknowledgedShifts)/float64(dashboard.TotalShifts)*10000) / 100
+	}
+	return dashboard
+}
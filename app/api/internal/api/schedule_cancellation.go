@@ -0,0 +1,158 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// defaultCancellationNoticeHours is how long before a shift starts it can be cancelled without being a
+// late cancellation when the organization has not set it
+const defaultCancellationNoticeHours = 24
+
+// CancelShiftRequest cancels a published shift. Within the notice period the shift is only cancelled
+// with override_notice, and the cancellation is tagged late.
+type CancelShiftRequest struct {
+	Reason             string `json:"reason" binding:"required,max=500"`
+	OfferToMarketplace bool   `json:"offer_to_marketplace"`
+	OverrideNotice     bool   `json:"override_notice"`
+}
+
+// CancelShiftHandler cancels a published shift, records who cancelled it and why, and emails the
+// employee. The shift can be offered as overtime to the employees free at that time.
+func (sh *ScheduleHandler) CancelShiftHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		sh.Logger.Warn("forbidden shift cancellation", "user_id", user.ID, "role", user.UserRole)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can cancel shifts"})
+		return
+	}
+
+	shiftID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid shift ID"})
+		return
+	}
+
+	var req CancelShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rules, err := sh.RulesStore.GetRulesByOrganizationID(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get the cancellation notice period"})
+		return
+	}
+	noticeHours := defaultCancellationNoticeHours
+	if rules != nil {
+		noticeHours = rules.CancellationNoticeHours
+	}
+
+	cancellation, err := sh.ScheduleStore.CancelShift(user.OrganizationID, shiftID, database.ShiftCancellationRequest{
+		Reason:              req.Reason,
+		At:                  time.Now(),
+		RequiredNoticeHours: noticeHours,
+		AllowLate:           req.OverrideNotice,
+		OfferShift:          req.OfferToMarketplace,
+	}, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Shift not found"})
+		case errors.Is(err, database.ErrShiftStarted):
+			c.JSON(http.StatusConflict, gin.H{"error": "The shift has already started"})
+		case errors.Is(err, database.ErrCancellationNotice):
+			c.JSON(http.StatusConflict, gin.H{
+				"error": fmt.Sprintf("Shifts must be cancelled at least %d hours before they start, set override_notice to cancel it as a late cancellation", noticeHours),
+			})
+		default:
+			sh.Logger.Error("failed to cancel shift", "error", err, "shift_id", shiftID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel shift"})
+		}
+		return
+	}
+	if cancellation.Late {
+		sh.Logger.Warn("late shift cancellation", "org_id", user.OrganizationID, "shift_id", shiftID,
+			"notice_hours", cancellation.NoticeHours, "required_notice_hours", noticeHours)
+	}
+	sh.Webhooks.Publish(user.OrganizationID, database.WebhookShiftUpdated, gin.H{"change": "cancelled", "cancellation": cancellation})
+
+	// the shift is cancelled even when the employee could not be emailed
+	notified := true
+	if err := sh.EmailService.SendShiftCancelledEmail(cancellation.EmployeeEmail, cancellation.EmployeeName,
+		cancellation.Date.Format(time.DateOnly), cancellation.StartTime, cancellation.EndTime, cancellation.Reason); err != nil {
+		sh.Logger.Error("failed to send shift cancelled email", "error", err, "shift_id", shiftID)
+		notified = false
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Shift cancelled successfully",
+		"data": gin.H{
+			"cancellation": cancellation,
+			"notified":     notified,
+		},
+	})
+}
+
+// GetShiftCancellationsHandler lists the shift cancellations of shifts between ?from= and ?to=, with
+// ?late=true for the late ones only, and counts the late cancellations for compliance reporting
+func (sh *ScheduleHandler) GetShiftCancellationsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can view shift cancellations"})
+		return
+	}
+
+	from, to, ok := queryDateRange(c)
+	if !ok {
+		return
+	}
+	filter := database.CancellationFilter{From: from, To: to}
+	if value := c.Query("late"); value != "" {
+		late, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "late must be true or false"})
+			return
+		}
+		filter.Late = &late
+	}
+
+	cancellations, err := sh.ScheduleStore.GetShiftCancellations(user.OrganizationID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve shift cancellations"})
+		return
+	}
+
+	late := 0
+	for _, cancellation := range cancellations {
+		if cancellation.Late {
+			late++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Shift cancellations retrieved successfully",
+		"data": gin.H{
+			"cancellations": cancellations,
+			"total":         len(cancellations),
+			"late":          late,
+		},
+	})
+}
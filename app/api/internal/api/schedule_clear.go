@@ -0,0 +1,102 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// maxClearScheduleDays limits how many days a single request can clear
+const maxClearScheduleDays = 31
+
+// ClearScheduleHandler removes the shifts of a date range so a bad generated schedule can be regenerated.
+// It is a dry run unless dry_run=false is given: the response then only reports what would be removed.
+func (sh *ScheduleHandler) ClearScheduleHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		sh.Logger.Warn("forbidden schedule clear", "user_id", user.ID, "role", user.UserRole)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied. Only admins and managers can clear schedules"})
+		return
+	}
+
+	from, err := time.Parse(time.DateOnly, c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must use the YYYY-MM-DD format"})
+		return
+	}
+	to, err := time.Parse(time.DateOnly, c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must use the YYYY-MM-DD format"})
+		return
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > maxClearScheduleDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A schedule can be cleared for at most " + strconv.Itoa(maxClearScheduleDays) + " days at a time"})
+		return
+	}
+
+	dryRun := true
+	if value := c.Query("dry_run"); value != "" {
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be true or false"})
+			return
+		}
+	}
+
+	if dryRun {
+		summary, err := sh.ScheduleStore.GetScheduleClearSummary(user.OrganizationID, from, to)
+		if err != nil {
+			sh.Logger.Error("failed to get schedule clear summary", "error", err, "org_id", user.OrganizationID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check the schedule"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Dry run, nothing was deleted. Repeat with dry_run=false to clear the schedule",
+			"data": gin.H{
+				"dry_run": true,
+				"summary": summary,
+			},
+		})
+		return
+	}
+
+	summary, err := sh.ScheduleStore.ClearSchedule(user.OrganizationID, from, to, user.ID)
+	if err != nil {
+		sh.Logger.Error("failed to clear schedule", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear schedule"})
+		return
+	}
+
+	// Notification failures do not undo the clear
+	notified := 0
+	for _, employee := range summary.Employees {
+		err := sh.EmailService.SendScheduleClearedEmail(employee.Email, employee.EmployeeName, from.Format(time.DateOnly), to.Format(time.DateOnly))
+		if err != nil {
+			sh.Logger.Error("failed to send schedule cleared email", "error", err, "employee_id", employee.EmployeeID)
+			continue
+		}
+		notified++
+	}
+
+	sh.Logger.Info("schedule cleared", "org_id", user.OrganizationID, "user_id", user.ID, "shifts", summary.Shifts, "notified", notified)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Schedule cleared successfully",
+		"data": gin.H{
+			"dry_run":            false,
+			"summary":            summary,
+			"notified_employees": notified,
+		},
+	})
+}
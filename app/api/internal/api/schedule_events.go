@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetShiftEventsHandler returns the history of a shift, oldest first: who generated, changed or cancelled
+// it and when. Employees can only see the history of their own shifts.
+func (sh *ScheduleHandler) GetShiftEventsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	shiftID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid shift ID"})
+		return
+	}
+
+	events, err := sh.ScheduleStore.GetShiftEvents(user.OrganizationID, shiftID)
+	if err != nil {
+		sh.Logger.Error("failed to get shift events", "error", err, "shift_id", shiftID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve shift history"})
+		return
+	}
+
+	// a shift swapped away from the employee stays visible to them
+	ownShift := slices.ContainsFunc(events, func(event database.ScheduleEvent) bool { return event.EmployeeID == user.ID })
+	if len(events) == 0 || (user.UserRole != "admin" && user.UserRole != "manager" && !ownShift) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shift not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Shift history retrieved successfully",
+		"data":    events,
+	})
+}
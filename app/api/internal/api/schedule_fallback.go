@@ -0,0 +1,445 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+)
+
+const (
+	// heuristicScheduleStatus labels schedules produced without the ML service
+	heuristicScheduleStatus = "HEURISTIC"
+
+	scheduleSourceML        = "ml"
+	scheduleSourceHeuristic = "heuristic_fallback"
+
+	minutesPerDay = 24 * 60
+)
+
+type heuristicEmployee struct {
+	id               string
+	roles            map[string]bool
+	wage             float64
+	available        map[string][2]int
+	alwaysAvailable  bool
+	preferred        map[string][2]int
+	maxMinutes       int
+	maxShiftMinutes  int
+	assignedMinutes  int
+	lastShiftEnd     int
+	workingDays      map[int]bool
+	preferredMinutes int
+	// supernumerary employees are in training: they shadow the shifts of their roles instead of filling
+	// the slots
+	supernumerary bool
+}
+
+type heuristicSlot struct {
+	start int
+	end   int
+	need  map[string]int
+	have  map[string]int
+}
+
+type heuristicShift struct {
+	start int
+	end   int
+	ids   []string
+	roles map[string]bool
+}
+
+// generateHeuristicSchedule builds a schedule greedily when the ML service is unavailable.
+// Each demand slot is filled with available employees holding the needed role, respecting
+// max weekly hours, max consecutive slots, minimum shift length and minimum rest between shifts.
+// The weekly labor budget caps the shifts unless all demand must be met.
+// Employees in training then join a shift of one of their roles, on top of the staff it needs.
+func generateHeuristicSchedule(request SchedulePredictRequest) GenerateScheduleResponse {
+	input := request.ScheduleInput
+	cfg := input.SchedulerConfig
+
+	slotMinutes := schedulerSlotMinutes(cfg)
+	minShiftMinutes := slotMinutes
+	if cfg.MinShiftLengthSlots != nil && *cfg.MinShiftLengthSlots > 0 {
+		minShiftMinutes = *cfg.MinShiftLengthSlots * slotMinutes
+	}
+	minRestMinutes := 0
+	if cfg.MinRestSlots != nil && *cfg.MinRestSlots > 0 {
+		minRestMinutes = *cfg.MinRestSlots * slotMinutes
+	}
+	remainingBudget := math.Inf(1)
+	if cfg.WeeklyLaborBudget != nil && (cfg.MeetAllDemands == nil || !*cfg.MeetAllDemands) {
+		remainingBudget = *cfg.WeeklyLaborBudget
+	}
+
+	employees := make([]*heuristicEmployee, 0, len(input.Employees))
+	for _, emp := range input.Employees {
+		employees = append(employees, newHeuristicEmployee(emp, slotMinutes))
+	}
+
+	roleNames := make([]string, 0, len(input.Roles))
+	for _, role := range input.Roles {
+		roleNames = append(roleNames, role.Role)
+	}
+	sort.Strings(roleNames)
+
+	openingHours := openingWindows(request.Place)
+
+	output := make(map[string][]map[string][]string)
+	var coverageGaps []map[string]any
+	totalCost := 0.0
+
+	for dayIndex, demandDay := range input.DemandPredictions {
+		day := strings.ToLower(demandDay.Day)
+		window, open := openingHours[day]
+		if !open {
+			continue
+		}
+
+		itemsPerHour := make(map[int]int)
+		for _, hour := range demandDay.Hours {
+			itemsPerHour[hour.HourNo] = hour.ItemCount
+		}
+		deliveries := deliveriesByHour(input.DeliveryDemand, day)
+
+		var slots []*heuristicSlot
+		for start := window[0]; start+slotMinutes <= window[1]; start += slotMinutes {
+			slot := &heuristicSlot{start: start, end: start + slotMinutes, need: map[string]int{}, have: map[string]int{}}
+			for _, role := range input.Roles {
+				slot.need[role.Role] = roleHeadcount(role, roleDemand(input.DeliveryDemand, role.Role, itemsPerHour, deliveries, start/60))
+			}
+			slots = append(slots, slot)
+		}
+
+		shifts := make(map[[2]int]*heuristicShift)
+		assign := func(emp *heuristicEmployee, role string, start, end int) {
+			emp.assignedMinutes += end - start
+			emp.lastShiftEnd = dayIndex*minutesPerDay + end
+			emp.workingDays[dayIndex] = true
+			key := [2]int{start, end}
+			if shifts[key] == nil {
+				shifts[key] = &heuristicShift{start: start, end: end, roles: map[string]bool{}}
+			}
+			shifts[key].ids = append(shifts[key].ids, emp.id)
+			if !emp.supernumerary {
+				for _, slot := range slots {
+					if slot.start >= start && slot.end <= end {
+						slot.have[role]++
+					}
+				}
+				shifts[key].roles[role] = true
+			}
+			shiftCost := float64(end-start) / 60 * emp.wage
+			totalCost += shiftCost
+			remainingBudget -= shiftCost
+		}
+
+		if request.Place.FixedShifts && len(request.Place.ShiftTimes) > 0 {
+			for _, shiftTime := range request.Place.ShiftTimes {
+				block, ok := clockWindow(shiftTime.From, shiftTime.To)
+				if !ok {
+					continue
+				}
+				start, end := max(block[0], window[0]), min(block[1], window[1])
+				if end <= start {
+					continue
+				}
+				for _, role := range roleNames {
+					needed, have := 0, minutesPerDay
+					for _, slot := range slots {
+						if slot.start >= start && slot.end <= end {
+							needed = max(needed, slot.need[role])
+							have = min(have, slot.have[role])
+						}
+					}
+					for ; have < needed; have++ {
+						emp := pickHeuristicEmployee(employees, role, day, dayIndex, start, end, minRestMinutes, remainingBudget)
+						if emp == nil {
+							break
+						}
+						assign(emp, role, start, end)
+					}
+				}
+			}
+		} else {
+			for _, slot := range slots {
+				for _, role := range roleNames {
+					for slot.have[role] < slot.need[role] {
+						// Shifts near closing start earlier rather than breaking the minimum length
+						start := slot.start
+						if start+minShiftMinutes > window[1] {
+							start = max(window[0], window[1]-minShiftMinutes)
+						}
+						end := min(start+minShiftMinutes, window[1])
+
+						emp := pickHeuristicEmployee(employees, role, day, dayIndex, start, end, minRestMinutes, remainingBudget)
+						if emp == nil {
+							break
+						}
+
+						// Extend the shift while the following slots still lack this role
+						limit := min(window[1], emp.windowEnd(day, window[1]), start+emp.maxShiftMinutes)
+						if emp.maxMinutes >= 0 {
+							limit = min(limit, start+emp.maxMinutes-emp.assignedMinutes)
+						}
+						if emp.wage > 0 && !math.IsInf(remainingBudget, 1) {
+							limit = min(limit, start+int(remainingBudget/emp.wage*60))
+						}
+						for j := (end - window[0]) / slotMinutes; j < len(slots); j++ {
+							next := slots[j]
+							if next.end > limit || next.have[role] >= next.need[role] {
+								break
+							}
+							end = next.end
+						}
+						assign(emp, role, start, end)
+					}
+				}
+			}
+		}
+
+		dayShifts := make([]*heuristicShift, 0, len(shifts))
+		for _, shift := range shifts {
+			dayShifts = append(dayShifts, shift)
+		}
+		sort.Slice(dayShifts, func(a, b int) bool {
+			if dayShifts[a].start != dayShifts[b].start {
+				return dayShifts[a].start < dayShifts[b].start
+			}
+			return dayShifts[a].end < dayShifts[b].end
+		})
+		for _, emp := range employees {
+			if !emp.supernumerary {
+				continue
+			}
+		shadow:
+			for _, shift := range dayShifts {
+				for _, role := range roleNames {
+					if shift.roles[role] && emp.canWork(role, day, dayIndex, shift.start, shift.end, minRestMinutes, remainingBudget) {
+						assign(emp, role, shift.start, shift.end)
+						break shadow
+					}
+				}
+			}
+		}
+		for _, shift := range dayShifts {
+			timeRange := formatClockMinutes(shift.start) + "-" + formatClockMinutes(shift.end)
+			output[day] = append(output[day], map[string][]string{timeRange: shift.ids})
+		}
+
+		for _, slot := range slots {
+			for _, role := range roleNames {
+				if slot.have[role] < slot.need[role] {
+					coverageGaps = append(coverageGaps, map[string]any{
+						"day":      day,
+						"time":     formatClockMinutes(slot.start) + "-" + formatClockMinutes(slot.end),
+						"role":     role,
+						"required": slot.need[role],
+						"assigned": slot.have[role],
+					})
+				}
+			}
+		}
+	}
+
+	var utilization []map[string]any
+	for _, emp := range employees {
+		utilization = append(utilization, map[string]any{
+			"employee_id":     emp.id,
+			"scheduled_hours": float64(emp.assignedMinutes) / 60,
+			"preferred_hours": float64(emp.preferredMinutes) / 60,
+		})
+	}
+
+	message := "ML service unavailable, schedule generated by the built-in heuristic scheduler"
+	if len(coverageGaps) > 0 {
+		message = fmt.Sprintf("%s with %d uncovered slot(s)", message, len(coverageGaps))
+	}
+
+	costAnalysis := map[string]any{"total_labor_cost": math.Round(totalCost*100) / 100}
+	if cfg.WeeklyLaborBudget != nil {
+		costAnalysis["weekly_labor_budget"] = *cfg.WeeklyLaborBudget
+	}
+
+	return GenerateScheduleResponse{
+		ScheduleOutput:  output,
+		ScheduleStatus:  heuristicScheduleStatus,
+		ScheduleMessage: message,
+		ManagementInsights: ManagementInsights{
+			HasSolution:         len(coverageGaps) == 0,
+			PeakPeriods:         []map[string]any{},
+			CoverageGaps:        coverageGaps,
+			EmployeeUtilization: utilization,
+			CostAnalysis:        costAnalysis,
+		},
+	}
+}
+
+func newHeuristicEmployee(emp Employee, slotMinutes int) *heuristicEmployee {
+	he := &heuristicEmployee{
+		id:              emp.EmployeeID.String(),
+		roles:           make(map[string]bool),
+		available:       make(map[string][2]int),
+		preferred:       make(map[string][2]int),
+		maxMinutes:      -1,
+		maxShiftMinutes: minutesPerDay,
+		lastShiftEnd:    -minutesPerDay,
+		workingDays:     make(map[int]bool),
+		supernumerary:   emp.Supernumerary,
+	}
+	for _, role := range emp.RoleNames {
+		he.roles[role] = true
+	}
+	if emp.HourlyWage != nil {
+		he.wage = *emp.HourlyWage
+	}
+	if emp.MaxHoursPerWeek != nil {
+		he.maxMinutes = int(*emp.MaxHoursPerWeek * 60)
+	}
+	if emp.PreferredHoursPerWeek != nil {
+		he.preferredMinutes = int(*emp.PreferredHoursPerWeek * 60)
+	}
+	if emp.MaxConsecSlots != nil && *emp.MaxConsecSlots > 0 {
+		he.maxShiftMinutes = *emp.MaxConsecSlots * slotMinutes
+	}
+	for day, hours := range emp.AvailableHours {
+		if window, ok := clockWindow(hours.From, hours.To); ok {
+			he.available[strings.ToLower(day)] = window
+		}
+	}
+	for day, hours := range emp.PreferredHours {
+		if window, ok := clockWindow(hours.From, hours.To); ok {
+			he.preferred[strings.ToLower(day)] = window
+		}
+	}
+	// Employees who never submitted availability are treated as available whenever the place is open
+	he.alwaysAvailable = len(emp.AvailableHours) == 0
+	return he
+}
+
+// windowEnd returns the end of the employee's availability on the given day
+func (he *heuristicEmployee) windowEnd(day string, fallback int) int {
+	if he.alwaysAvailable {
+		return fallback
+	}
+	return he.available[day][1]
+}
+
+func (he *heuristicEmployee) canWork(role, day string, dayIndex, start, end, minRest int, budget float64) bool {
+	if !he.roles[role] || he.workingDays[dayIndex] {
+		return false
+	}
+	if float64(end-start)/60*he.wage > budget {
+		return false
+	}
+	if end-start > he.maxShiftMinutes {
+		return false
+	}
+	if he.maxMinutes >= 0 && he.assignedMinutes+end-start > he.maxMinutes {
+		return false
+	}
+	if dayIndex*minutesPerDay+start-he.lastShiftEnd < minRest {
+		return false
+	}
+	return he.availableFor(day, start, end)
+}
+
+// availableFor reports whether the employee's availability on the day covers the block
+func (he *heuristicEmployee) availableFor(day string, start, end int) bool {
+	if he.alwaysAvailable {
+		return true
+	}
+	window, ok := he.available[day]
+	return ok && window[0] <= start && end <= window[1]
+}
+
+func (he *heuristicEmployee) prefers(day string, start, end int) bool {
+	window, ok := he.preferred[day]
+	return ok && window[0] <= start && end <= window[1]
+}
+
+// pickHeuristicEmployee returns the best eligible employee not in training for the block whose cost
+// fits in the remaining budget, favouring those who prefer the hours, then those with the fewest
+// scheduled hours so far, then the cheapest
+func pickHeuristicEmployee(employees []*heuristicEmployee, role, day string, dayIndex, start, end, minRest int, budget float64) *heuristicEmployee {
+	var best *heuristicEmployee
+	for _, emp := range employees {
+		if emp.supernumerary || !emp.canWork(role, day, dayIndex, start, end, minRest, budget) {
+			continue
+		}
+		if best == nil {
+			best = emp
+			continue
+		}
+		empPrefers, bestPrefers := emp.prefers(day, start, end), best.prefers(day, start, end)
+		switch {
+		case empPrefers != bestPrefers:
+			if empPrefers {
+				best = emp
+			}
+		case emp.assignedMinutes != best.assignedMinutes:
+			if emp.assignedMinutes < best.assignedMinutes {
+				best = emp
+			}
+		case emp.wage < best.wage:
+			best = emp
+		}
+	}
+	return best
+}
+
+// schedulerSlotMinutes is the length of a scheduling slot, an hour unless configured
+func schedulerSlotMinutes(cfg SchedulerConfig) int {
+	if cfg.SlotLenHour != nil && *cfg.SlotLenHour > 0 {
+		return int(math.Round(*cfg.SlotLenHour * 60))
+	}
+	return 60
+}
+
+// openingWindows returns the opening hours of the place by lowercase weekday, leaving out closed days
+func openingWindows(place Place) map[string][2]int {
+	openingHours := make(map[string][2]int)
+	for _, oh := range place.OpeningHours {
+		if oh.Closed != nil && *oh.Closed {
+			continue
+		}
+		if window, ok := clockWindow(oh.OpeningTime, oh.ClosingTime); ok {
+			openingHours[strings.ToLower(oh.Weekday)] = window
+		}
+	}
+	return openingHours
+}
+
+// roleHeadcount is the number of people a role needs in a slot given the predicted item count for that hour
+func roleHeadcount(role database.OrganizationRole, items int) int {
+	needed := role.MinNeededPerShift
+	if role.NeedForDemand && role.ItemsPerRolePerHour != nil && *role.ItemsPerRolePerHour > 0 {
+		needed = max(needed, int(math.Ceil(float64(items)/float64(*role.ItemsPerRolePerHour))))
+	}
+	return needed
+}
+
+// clockWindow converts a from/to pair into minutes since midnight, a "to" at or before
+// "from" (e.g. closing at 00:00) is treated as the end of the day
+func clockWindow(from, to string) ([2]int, bool) {
+	start, err := parseClockTime(from)
+	if err != nil {
+		return [2]int{}, false
+	}
+	end, err := parseClockTime(to)
+	if err != nil {
+		return [2]int{}, false
+	}
+	startMinutes := start.Hour()*60 + start.Minute()
+	endMinutes := end.Hour()*60 + end.Minute()
+	if endMinutes <= startMinutes {
+		endMinutes = minutesPerDay
+	}
+	return [2]int{startMinutes, endMinutes}, true
+}
+
+func formatClockMinutes(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
@@ -3,92 +3,49 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/clockwise/clockwise/backend/internal/database"
 	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type ScheduleHandler struct {
-	UserStore           database.UserStore
-	ScheduleStore       database.ScheduleStore
-	OrgStore            database.OrgStore
-	RulesStore          database.RulesStore
-	UserRolesStore      database.UserRolesStore
-	OperatingHoursStore database.OperatingHoursStore
-	OrderStore          database.OrderStore
-	CampaignStore       database.CampaignStore
-	DemandStore         database.DemandStore
-	RoleStore           database.RolesStore
-	PreferenceStore     database.PreferencesStore
-	Logger              *slog.Logger
-}
-
-type SchedulePredictRequest struct {
-	Place         Place         `json:"place"`
-	ScheduleInput ScheduleInput `json:"schedule_input"`
-}
-
-type ScheduleInput struct {
-	Roles               []database.OrganizationRole `json:"roles"`
-	Employees           []Employee                  `json:"employees"`
-	SchedulerConfig     SchedulerConfig             `json:"scheduler_config"`
-	DemandPredictions   []database.PredictionDay    `json:"demand_predictions"`
-	PredictionStartDate time.Time                   `json:"prediction_start_date"`
-}
-
-type Employee struct {
-	EmployeeID            uuid.UUID                `json:"employee_id"`
-	RoleNames             []string                 `json:"role_ids"`
-	AvailableDays         []string                 `json:"available_days"`
-	Preferred_Days        []string                 `json:"preferred_days"`
-	AvailableHours        map[string]EmployeeHours `json:"available_hours"`
-	PreferredHours        map[string]EmployeeHours `json:"preferred_hours"`
-	HourlyWage            *float64                 `json:"hourly_wage"`
-	MaxHoursPerWeek       *float64                 `json:"max_hours_per_week"`
-	MaxConsecSlots        *int                     `json:"max_consec_slots"`
-	PreferredHoursPerWeek *float64                 `json:"pref_hours"`
-}
-
-type SchedulerConfig struct {
-	SlotLenHour         *float64 `json:"slot_len_hour"`
-	MinRestSlots        *int     `json:"min_rest_slots"`
-	MinShiftLengthSlots *int     `json:"min_shift_length_slots"`
-	MeetAllDemands      *bool    `json:"meet_all_demand"`
-}
-
-type EmployeeHours struct {
-	From string `json:"from"`
-	To   string `json:"to"`
-}
-
-type GenerateScheduleResponse struct {
-	ScheduleOutput     map[string][]map[string][]string `json:"schedule_output"`
-	ScheduleStatus     string                           `json:"schedule_status"`
-	ScheduleMessage    string                           `json:"schedule_message"`
-	ObjectiveValue     *float64                         `json:"objective_value"`
-	ManagementInsights ManagementInsights               `json:"management_insights"`
-}
-
-type ManagementInsights struct {
-	HasSolution           bool             `json:"has_solution"`
-	PeakPeriods           []map[string]any `json:"peak_periods"`
-	CapacityAnalysis      map[string]any   `json:"capacity_analysis,omitempty"`
-	EmployeeUtilization   []map[string]any `json:"employee_utilization,omitempty"`
-	RoleDemand            map[string]any   `json:"role_demand,omitempty"`
-	HiringRecommendations []map[string]any `json:"hiring_recommendations,omitempty"`
-	CoverageGaps          []map[string]any `json:"coverage_gaps,omitempty"`
-	CostAnalysis          map[string]any   `json:"cost_analysis,omitempty"`
-	WorkloadDistribution  map[string]any   `json:"workload_distribution,omitempty"`
-	FeasibilityAnalysis   []map[string]any `json:"feasibility_analysis,omitempty"`
+	UserStore                    database.UserStore
+	ScheduleStore                database.ScheduleStore
+	OrgStore                     database.OrgStore
+	RulesStore                   database.RulesStore
+	UserRolesStore               database.UserRolesStore
+	OperatingHoursStore          database.OperatingHoursStore
+	OrderStore                   database.OrderStore
+	CampaignStore                database.CampaignStore
+	DemandStore                  database.DemandStore
+	RoleStore                    database.RolesStore
+	PreferenceStore              database.PreferencesStore
+	EmailService                 service.EmailService
+	ApplicantSessionStore        database.ApplicantSessionStore
+	OperatingHoursExceptionStore database.OperatingHoursExceptionStore
+	StatusStore                  database.StatusStore
+	ComplianceStore              database.EmployeeComplianceStore
+	VersionStore                 database.ScheduleVersionStore
+	Logger                       *slog.Logger
+
+	// Webhooks receives the schedule and shift events of organizations, nil sends none
+	Webhooks *service.WebhookService
+	// SkillStore holds the skills roles require, nil schedules employees in all their roles
+	SkillStore database.SkillStore
+	// TrainingStore holds the training periods of new hires, nil schedules nobody as in training
+	TrainingStore database.TrainingStore
 }
 
 func NewScheduleHandler(userStore database.UserStore, scheduleStore database.ScheduleStore, logger *slog.Logger,
@@ -101,20 +58,32 @@ func NewScheduleHandler(userStore database.UserStore, scheduleStore database.Sch
 	demandStore database.DemandStore,
 	roleStore database.RolesStore,
 	preferenceStore database.PreferencesStore,
+	emailService service.EmailService,
+	applicantSessionStore database.ApplicantSessionStore,
+	operatingHoursExceptionStore database.OperatingHoursExceptionStore,
+	statusStore database.StatusStore,
+	complianceStore database.EmployeeComplianceStore,
+	versionStore database.ScheduleVersionStore,
 ) *ScheduleHandler {
 	return &ScheduleHandler{
-		UserStore:           userStore,
-		ScheduleStore:       scheduleStore,
-		OrgStore:            orgStore,
-		RulesStore:          rulesStore,
-		UserRolesStore:      userRolesStore,
-		OperatingHoursStore: operatingHoursStore,
-		OrderStore:          orderStore,
-		CampaignStore:       campaignStore,
-		DemandStore:         demandStore,
-		RoleStore:           roleStore,
-		PreferenceStore:     preferenceStore,
-		Logger:              logger,
+		UserStore:                    userStore,
+		ScheduleStore:                scheduleStore,
+		OrgStore:                     orgStore,
+		RulesStore:                   rulesStore,
+		UserRolesStore:               userRolesStore,
+		OperatingHoursStore:          operatingHoursStore,
+		OrderStore:                   orderStore,
+		CampaignStore:                campaignStore,
+		DemandStore:                  demandStore,
+		RoleStore:                    roleStore,
+		PreferenceStore:              preferenceStore,
+		EmailService:                 emailService,
+		ApplicantSessionStore:        applicantSessionStore,
+		OperatingHoursExceptionStore: operatingHoursExceptionStore,
+		StatusStore:                  statusStore,
+		ComplianceStore:              complianceStore,
+		VersionStore:                 versionStore,
+		Logger:                       logger,
 	}
 }
 
@@ -131,230 +100,72 @@ func (sh *ScheduleHandler) PredictScheduleHandler(c *gin.Context) {
 
 	sh.Logger.Info("requesting schedule from external api", "org_id", user.OrganizationID)
 
-	organization, err := sh.OrgStore.GetOrganizationByID(user.OrganizationID)
-
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get organization details"})
-		return
-	}
-
-	organization_rules, err := sh.RulesStore.GetRulesByOrganizationID(user.OrganizationID)
-
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get organization rules details"})
-		return
-	}
-
-	operating_hours, err := sh.OperatingHoursStore.GetOperatingHours(user.OrganizationID)
-
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get organization operating hours details"})
-		return
-	}
-
-	Place := Place{
-		ID:                 organization.ID,
-		Name:               organization.Name,
-		Type:               organization.Type,
-		Latitude:           organization.Location.Latitude,
-		Longitude:          organization.Location.Longitude,
-		WaitingTime:        organization_rules.WaitingTime,
-		ReceivingPhone:     organization_rules.ReceivingPhone,
-		Delivery:           organization_rules.Delivery,
-		OpeningHours:       operating_hours,
-		FixedShifts:        organization_rules.FixedShifts,
-		NumberShiftsPerDay: organization_rules.NumberOfShiftsPerDay,
-		ShiftTimes:         organization_rules.ShiftTimes,
-		Rating:             organization.Rating,
-		AcceptingOrders:    organization_rules.AcceptingOrders,
-	}
-
-	schedulerConfig := SchedulerConfig{
-		MinRestSlots:        &organization_rules.MinRestSlots,
-		SlotLenHour:         &organization_rules.SlotLenHour,
-		MinShiftLengthSlots: &organization_rules.MinShiftLengthSlots,
-		MeetAllDemands:      &organization_rules.MeetAllDemand,
-	}
-
-	demands, err := sh.DemandStore.GetLatestDemandHeatMap(organization.ID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get organization latest demands, please generate demand first"})
+	request, inputErr := sh.buildSchedulePredictRequest(user.OrganizationID)
+	if inputErr != nil {
+		c.JSON(inputErr.Status, gin.H{"error": inputErr.Message})
 		return
 	}
 
-	roles, err := sh.RoleStore.GetRolesByOrganizationID(user.OrganizationID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get organization latest demands, please get roles from organization"})
+	if err := validateSchedulePredictRequest(*request); err != nil {
+		sh.Logger.Warn("schedule payload failed validation", "org_id", user.OrganizationID, "error", err)
+		c.JSON(http.StatusUnprocessableEntity, mlPayloadErrorResponse("schedule input is incomplete or invalid", err))
 		return
 	}
 
-	employees, err := sh.UserStore.GetUsersByOrganization(user.OrganizationID)
-
+	scheduleResponse, scheduleSource, err := sh.generateSchedule(*request)
 	if err != nil {
-		sh.Logger.Debug("failed to retrieve employees for organization", "err", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get employees from organization"})
-		return
-	}
-
-	var Employees []Employee
-
-	for _, employee := range employees {
-		// Exclude Admin
-		if employee.UserRole == "admin" {
-			continue
-		}
-
-		// Get preferences for this employee
-		prefs, err := sh.PreferenceStore.GetPreferencesByEmployeeID(employee.ID)
-		sh.Logger.Info("got prefs for employee", "employee_id", employee.ID)
-		if err != nil {
-			sh.Logger.Warn("failed to get preferences for employee", "employee_id", employee.ID, "error", err)
-			// Continue without preferences for this employee
-			prefs = []database.EmployeePreference{}
-		}
-
-		// User Roles
-		userRoles, err := sh.UserRolesStore.GetUserRoles(employee.ID, user.OrganizationID)
-		if err != nil {
-			sh.Logger.Info("failed to get user roles for employees", "employee_id", employee.ID, "error", err)
-			continue
-		}
-
-		if len(userRoles) == 0 {
-			sh.Logger.Error("no user roles found", "user", employee.ID)
+		var serviceErr *mlServiceError
+		if errors.As(err, &serviceErr) {
+			c.JSON(serviceErr.StatusCode, gin.H{"error": "ML service returned an error", "details": serviceErr.Body})
+			return
 		}
-
-		// Build available/preferred days and hours maps
-		availableDays := []string{}
-		preferredDays := []string{}
-		availableHours := make(map[string]EmployeeHours)
-		preferredHours := make(map[string]EmployeeHours)
-
-		for _, pref := range prefs {
-			dayLower := pref.Day
-
-			// Available hours
-			if pref.AvailableStartTime != nil && pref.AvailableEndTime != nil {
-				availableDays = append(availableDays, dayLower)
-				availableHours[dayLower] = EmployeeHours{
-					From: *pref.AvailableStartTime,
-					To:   *pref.AvailableEndTime,
-				}
-			}
-
-			// Preferred hours
-			if pref.PreferredStartTime != nil && pref.PreferredEndTime != nil {
-				preferredDays = append(preferredDays, dayLower)
-				preferredHours[dayLower] = EmployeeHours{
-					From: *pref.PreferredStartTime,
-					To:   *pref.PreferredEndTime,
-				}
-			}
-		}
-
-		// Convert hours per week from int to float64 if needed
-		var maxHoursPerWeek *float64
-		if employee.MaxHoursPerWeek != nil {
-			val := float64(*employee.MaxHoursPerWeek)
-			maxHoursPerWeek = &val
-		}
-
-		var preferredHoursPerWeek *float64
-		if employee.PreferredHoursPerWeek != nil {
-			val := float64(*employee.PreferredHoursPerWeek)
-			preferredHoursPerWeek = &val
-		}
-
-		// Build Employee struct
-		emp := Employee{
-			EmployeeID:            employee.ID,
-			RoleNames:             userRoles,
-			AvailableDays:         availableDays,
-			Preferred_Days:        preferredDays,
-			AvailableHours:        availableHours,
-			PreferredHours:        preferredHours,
-			HourlyWage:            employee.SalaryPerHour,
-			MaxHoursPerWeek:       maxHoursPerWeek,
-			MaxConsecSlots:        employee.MaxConsecSlots,
-			PreferredHoursPerWeek: preferredHoursPerWeek,
-		}
-
-		Employees = append(Employees, emp)
-	}
-	scheduleInput := ScheduleInput{
-		SchedulerConfig:     schedulerConfig,
-		DemandPredictions:   demands.Days,
-		PredictionStartDate: time.Now(),
-		Roles:               roles,
-		Employees:           Employees,
-	}
-
-	request := SchedulePredictRequest{
-		Place:         Place,
-		ScheduleInput: scheduleInput,
-	}
-
-	// Call external ML API
-	mlURL := os.Getenv("ML_URL")
-	if mlURL == "" {
-		mlURL = "http://cw-ml-service:8000"
-	}
-
-	// Make an api call to the demand model http://cw-ml-service:8000/predict/schedule
-	client := &http.Client{}
-
-	// JSON
-	jsonPayload, err := json.Marshal(request)
-	if err != nil {
-		sh.Logger.Error("failed to marshal request", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare request payload"})
-		return
-	}
-
-	sh.Logger.Debug("json body in request", "json", string(jsonPayload))
-
-	req, err := http.NewRequest("POST", fmt.Sprintf("%v%v", os.Getenv("ML_URL"), "/predict/schedule"), bytes.NewBuffer(jsonPayload))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
-		return
-	}
-
-	req.Header.Add("Content-Type", "application/json")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	defer resp.Body.Close()
-
-	sh.Logger.Info("request to ML API", "req", jsonPayload)
-	// Validate response status code first
-	if resp.StatusCode != http.StatusOK {
-		sh.Logger.Error("ML API returned error", "status_code", resp.StatusCode)
-		body, _ := io.ReadAll(resp.Body)
-		c.JSON(resp.StatusCode, gin.H{"error": "ML service returned an error", "details": string(body)})
+		sh.Logger.Error("failed to get schedule from ML service", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode ML response"})
 		return
 	}
 
-	// Process Response with custom UnmarshalJSON for date parsing
-	var scheduleResponse GenerateScheduleResponse
-	decoder := json.NewDecoder(resp.Body)
-
-	if err := decoder.Decode(&scheduleResponse); err != nil {
-		sh.Logger.Error("failed to decode ML response", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode ML response"})
-		return
+	metrics := evaluateSchedule(*request, scheduleResponse.ScheduleOutput)
+	budgetUtilization := newBudgetUtilization(request.ScheduleInput.SchedulerConfig.WeeklyLaborBudget, metrics.LaborCost)
+	if budgetUtilization.WithinBudget != nil && !*budgetUtilization.WithinBudget {
+		sh.Logger.Warn("generated schedule exceeds weekly labor budget", "org_id", user.OrganizationID,
+			"labor_cost", metrics.LaborCost, "budget", *budgetUtilization.WeeklyLaborBudget)
 	}
 
 	// Store in Schedule Store
-	err = sh.storeScheduleOutput(user.OrganizationID, scheduleResponse.ScheduleOutput)
+	err = sh.storeScheduleOutput(user.OrganizationID, scheduleResponse.ScheduleOutput, user.ID)
 	if err != nil {
 		sh.Logger.Error("failed to store schedule", "error", err)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "error storing schedule"})
 		return
 	}
-
+	recordStatusEvent(sh.StatusStore, sh.Logger, user.OrganizationID, &database.StatusEvent{
+		Kind:   database.StatusEventScheduleGeneration,
+		Source: scheduleSource,
+	})
+	// generated schedules are stored published, so both events go out together
+	event := scheduleWebhookEvent(scheduleSource, scheduleResponse.ScheduleOutput)
+	sh.Webhooks.Publish(user.OrganizationID, database.WebhookScheduleGenerated, event)
+	sh.Webhooks.Publish(user.OrganizationID, database.WebhookSchedulePublished, event)
+
+	// the schedule is already stored, so a version that fails to store is only logged
+	version := scheduleVersion(*request, scheduleResponse.ScheduleOutput)
+	version.Source = scheduleSource
+	version.GeneratedBy = &user.ID
+	var versionID *uuid.UUID
+	if err := sh.VersionStore.StoreScheduleVersion(user.OrganizationID, version); err != nil {
+		sh.Logger.Error("failed to store schedule version", "error", err, "org_id", user.OrganizationID)
+	} else {
+		versionID = &version.ID
+	}
+	annotateUtilization(&scheduleResponse.ManagementInsights, version)
+
+	trainees := make(map[string]bool)
+	for _, emp := range request.ScheduleInput.Employees {
+		if emp.Supernumerary {
+			trainees[emp.EmployeeID.String()] = true
+		}
+	}
+	inTraining := []string{}
 	for day, timeSlots := range scheduleResponse.ScheduleOutput {
 		for i, slotMap := range timeSlots {
 			for timeRange := range slotMap {
@@ -367,19 +178,39 @@ func (sh *ScheduleHandler) PredictScheduleHandler(c *gin.Context) {
 						continue
 					}
 					names = append(names, emp.FullName)
+					if trainees[empID] && !slices.Contains(inTraining, emp.FullName) {
+						inTraining = append(inTraining, emp.FullName)
+					}
 				}
 				scheduleResponse.ScheduleOutput[day][i][timeRange] = names
 			}
 		}
 	}
+	slices.Sort(inTraining)
+	message := "schedule prediction retrieved successfully from API"
+	if scheduleSource == scheduleSourceHeuristic {
+		message = "ML service unavailable, schedule generated by the fallback heuristic scheduler"
+	}
+
 	// Return the successfully decoded response
 	c.JSON(http.StatusOK, gin.H{
-		"message":             "schedule prediction retrieved successfully from API",
+		"message":             message,
 		"schedule_status":     scheduleResponse.ScheduleStatus,
 		"schedule_message":    scheduleResponse.ScheduleMessage,
 		"management_insights": scheduleResponse.ManagementInsights,
 		"objective_value":     scheduleResponse.ObjectiveValue,
 		"schedule_output":     scheduleResponse.ScheduleOutput,
+		"schedule_source":     scheduleSource,
+		"coverage_percent":    metrics.CoveragePercent,
+		"budget_utilization":  budgetUtilization,
+		"preference_satisfaction": gin.H{
+			"percent":          version.PreferenceSatisfactionPercent,
+			"shifts":           version.Shifts,
+			"preferred_shifts": version.PreferredShifts,
+			"employees":        version.Employees,
+		},
+		"schedule_version_id": versionID,
+		"in_training":         inTraining,
 	})
 
 }
@@ -404,6 +235,10 @@ func (sh *ScheduleHandler) GetCurrentUserScheduleHandler(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve schedule"})
 		return
 	}
+	if user.UserRole == "manager" {
+		schedules = sh.withApplicantSessions(user.OrganizationID, schedules, &user.ID)
+	}
+	schedules = sh.withTrainees(user.OrganizationID, schedules)
 
 	sh.Logger.Info("current user schedule retrieved", "user_id", user.ID, "count", len(schedules))
 	c.JSON(http.StatusOK, gin.H{
@@ -433,6 +268,8 @@ func (sh *ScheduleHandler) GetScheduleHandler(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve schedule"})
 		return
 	}
+	schedules = sh.withApplicantSessions(user.OrganizationID, schedules, nil)
+	schedules = sh.withTrainees(user.OrganizationID, schedules)
 
 	sh.Logger.Info("organization schedule retrieved", "org_id", user.OrganizationID, "count", len(schedules))
 	c.JSON(http.StatusOK, gin.H{
@@ -477,6 +314,7 @@ func (sh *ScheduleHandler) GetEmployeeScheduleHandler(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve employee schedule"})
 		return
 	}
+	schedules = sh.withTrainees(user.OrganizationID, schedules)
 
 	sh.Logger.Info("employee schedule retrieved", "employee_id", employeeID, "count", len(schedules))
 	c.JSON(http.StatusOK, gin.H{
@@ -485,12 +323,30 @@ func (sh *ScheduleHandler) GetEmployeeScheduleHandler(c *gin.Context) {
 	})
 }
 
-// storeScheduleOutput parses the ML model schedule output and stores each entry in the database
+// scheduleWebhookEvent is the data of the schedule webhook events: the week the schedule covers and its
+// shifts by day, with employee IDs
+func scheduleWebhookEvent(source string, scheduleOutput map[string][]map[string][]string) gin.H {
+	today := time.Now()
+	return gin.H{
+		"from":     today.Format(time.DateOnly),
+		"to":       today.AddDate(0, 0, 6).Format(time.DateOnly),
+		"source":   source,
+		"schedule": scheduleOutput,
+	}
+}
+
+// storeScheduleOutput parses the ML model schedule output and stores each entry in the database, as
+// generated by actorID
 // schedule_output format: { "monday": [{"10:00-14:00": ["emp_001", "emp_002"]}, ...], ... }
-func (sh *ScheduleHandler) storeScheduleOutput(orgID uuid.UUID, scheduleOutput map[string][]map[string][]string) error {
+func (sh *ScheduleHandler) storeScheduleOutput(orgID uuid.UUID, scheduleOutput map[string][]map[string][]string, actorID uuid.UUID) error {
 	// Map day names to their next occurrence date
 	dayToDate := sh.getNextSevenDayDates()
 
+	closed, err := closedDays(sh.OperatingHoursExceptionStore, orgID, time.Now(), 7)
+	if err != nil {
+		return err
+	}
+
 	for dayName, timeSlots := range scheduleOutput {
 		dayLower := strings.ToLower(dayName)
 		scheduleDate, ok := dayToDate[dayLower]
@@ -498,6 +354,10 @@ func (sh *ScheduleHandler) storeScheduleOutput(orgID uuid.UUID, scheduleOutput m
 			sh.Logger.Warn("unknown day name in schedule output", "day", dayName)
 			continue
 		}
+		if closed[scheduleDate.Format(time.DateOnly)] {
+			sh.Logger.Warn("skipping shifts of closed day in schedule output", "day", dayName, "date", scheduleDate)
+			continue
+		}
 
 		for _, slotMap := range timeSlots {
 			for timeRange, employeeIDs := range slotMap {
@@ -523,7 +383,7 @@ func (sh *ScheduleHandler) storeScheduleOutput(orgID uuid.UUID, scheduleOutput m
 						EndTime:   endTime,
 					}
 
-					err = sh.ScheduleStore.StoreScheduleForUser(orgID, empID, schedule)
+					err = sh.ScheduleStore.StoreScheduleForUser(orgID, empID, schedule, actorID)
 					if err != nil {
 						sh.Logger.Error("failed to store schedule entry",
 							"error", err,
@@ -566,3 +426,395 @@ func (sh *ScheduleHandler) parseTimeRange(timeRange string, baseDate time.Time)
 
 	return startStr, endStr, nil
 }
+
+// scheduleInputError carries the status and message when the data needed for a schedule cannot be gathered
+type scheduleInputError struct {
+	Status  int
+	Message string
+}
+
+// buildSchedulePredictRequest gathers everything the scheduler needs for the organization
+func (sh *ScheduleHandler) buildSchedulePredictRequest(orgID uuid.UUID) (*SchedulePredictRequest, *scheduleInputError) {
+	organization, err := sh.OrgStore.GetOrganizationByID(orgID)
+
+	if err != nil {
+		return nil, &scheduleInputError{Status: http.StatusInternalServerError, Message: "failed to get organization details"}
+	}
+
+	organization_rules, err := sh.RulesStore.GetRulesByOrganizationID(orgID)
+
+	if err != nil {
+		return nil, &scheduleInputError{Status: http.StatusInternalServerError, Message: "failed to get organization rules details"}
+	}
+
+	if organization_rules == nil {
+		return nil, &scheduleInputError{Status: http.StatusNotFound, Message: "organization rules not found"}
+	}
+
+	operating_hours, err := sh.OperatingHoursStore.GetOperatingHours(orgID)
+
+	if err != nil {
+		return nil, &scheduleInputError{Status: http.StatusInternalServerError, Message: "failed to get organization operating hours details"}
+	}
+
+	Place := Place{
+		ID:                 organization.ID,
+		Name:               organization.Name,
+		Type:               organization.Type,
+		Latitude:           organization.Location.Latitude,
+		Longitude:          organization.Location.Longitude,
+		WaitingTime:        organization_rules.WaitingTime,
+		ReceivingPhone:     organization_rules.ReceivingPhone,
+		Delivery:           organization_rules.Delivery,
+		OpeningHours:       operating_hours,
+		FixedShifts:        organization_rules.FixedShifts,
+		NumberShiftsPerDay: organization_rules.NumberOfShiftsPerDay,
+		ShiftTimes:         organization_rules.ShiftTimes,
+		Rating:             organization.Rating,
+		AcceptingOrders:    organization_rules.AcceptingOrders,
+	}
+
+	schedulerConfig := SchedulerConfig{
+		MinRestSlots:        &organization_rules.MinRestSlots,
+		SlotLenHour:         &organization_rules.SlotLenHour,
+		MinShiftLengthSlots: &organization_rules.MinShiftLengthSlots,
+		MeetAllDemands:      &organization_rules.MeetAllDemand,
+		WeeklyLaborBudget:   organization_rules.WeeklyLaborBudget,
+	}
+
+	demands, err := sh.DemandStore.GetLatestDemandHeatMap(organization.ID)
+	if err != nil {
+		return nil, &scheduleInputError{Status: http.StatusInternalServerError, Message: "failed to get organization latest demands, please generate demand first"}
+	}
+
+	if demands == nil {
+		return nil, &scheduleInputError{Status: http.StatusNotFound, Message: "no demand predictions found, please generate demand first"}
+	}
+
+	// Shifts are stored on the next occurrence of each weekday, so the demand of a weekday is left
+	// out when that occurrence is a closed day
+	closed, err := closedDays(sh.OperatingHoursExceptionStore, orgID, time.Now(), 7)
+	if err != nil {
+		return nil, &scheduleInputError{Status: http.StatusInternalServerError, Message: "failed to get organization closed days"}
+	}
+	closedWeekdays := make(map[string]bool, len(closed))
+	for day, date := range sh.getNextSevenDayDates() {
+		if closed[date.Format(time.DateOnly)] {
+			closedWeekdays[day] = true
+		}
+	}
+	demandDays := make([]database.PredictionDay, 0, len(demands.Days))
+	for _, day := range demands.Days {
+		if closedWeekdays[strings.ToLower(day.Day)] {
+			continue
+		}
+		demandDays = append(demandDays, day)
+	}
+
+	roles, err := sh.RoleStore.GetRolesByOrganizationID(orgID)
+	if err != nil {
+		return nil, &scheduleInputError{Status: http.StatusInternalServerError, Message: "failed to get organization latest demands, please get roles from organization"}
+	}
+
+	// Drivers are staffed from the delivery orders of the last weeks rather than the predicted items
+	deliveryDemand, err := sh.buildDeliveryDemand(orgID, organization_rules, roles, demandDays)
+	if err != nil {
+		return nil, &scheduleInputError{Status: http.StatusInternalServerError, Message: "failed to get organization delivery demand"}
+	}
+
+	employees, err := sh.UserStore.GetUsersByOrganization(orgID)
+
+	if err != nil {
+		sh.Logger.Debug("failed to retrieve employees for organization", "err", err.Error())
+		return nil, &scheduleInputError{Status: http.StatusInternalServerError, Message: "failed to get employees from organization"}
+	}
+
+	// Employees are not scheduled past the expiry of their work permit
+	permitExpiries, err := sh.ComplianceStore.GetWorkPermitExpiries(orgID)
+	if err != nil {
+		return nil, &scheduleInputError{Status: http.StatusInternalServerError, Message: "failed to get employee work permits"}
+	}
+	weekDates := sh.getNextSevenDayDates()
+	today := time.Now().Format(time.DateOnly)
+	weekStart := weekDates[strings.ToLower(time.Now().Weekday().String())]
+
+	// Employees are only scheduled in the roles whose required skills they have verified and valid
+	// until the end of the week
+	var skillRequirements []database.RoleSkillRequirement
+	var verifiedSkills map[uuid.UUID][]string
+	if sh.SkillStore != nil {
+		skillRequirements, err = sh.SkillStore.GetRoleSkillRequirements(orgID)
+		if err != nil {
+			return nil, &scheduleInputError{Status: http.StatusInternalServerError, Message: "failed to get role skill requirements"}
+		}
+		if len(skillRequirements) > 0 {
+			verifiedSkills, err = sh.SkillStore.GetVerifiedSkills(orgID, weekStart.AddDate(0, 0, 6))
+			if err != nil {
+				return nil, &scheduleInputError{Status: http.StatusInternalServerError, Message: "failed to get employee skills"}
+			}
+		}
+	}
+
+	// Employees in training during the week are scheduled on top of the staff the demand needs, and never in
+	// the roles worked alone
+	trainees := make(map[uuid.UUID]bool)
+	if sh.TrainingStore != nil {
+		trainings, err := sh.TrainingStore.GetTrainings(orgID)
+		if err != nil {
+			return nil, &scheduleInputError{Status: http.StatusInternalServerError, Message: "failed to get employee trainings"}
+		}
+		for _, training := range trainings {
+			if training.InTrainingOn(weekStart) {
+				trainees[training.UserID] = true
+			}
+		}
+	}
+	soloRoles := make(map[string]bool)
+	for _, role := range roles {
+		if role.SoloCoverage {
+			soloRoles[role.Role] = true
+		}
+	}
+
+	var Employees []Employee
+
+	for _, employee := range employees {
+		// Exclude Admin
+		if employee.UserRole == "admin" {
+			continue
+		}
+
+		permitExpiry, hasPermit := permitExpiries[employee.ID]
+		if hasPermit && permitExpiry.Format(time.DateOnly) < today {
+			sh.Logger.Info("work permit expired, employee not scheduled", "employee_id", employee.ID, "expired_on", permitExpiry.Format(time.DateOnly))
+			continue
+		}
+
+		// Get preferences for this employee
+		prefs, err := sh.PreferenceStore.GetPreferencesByEmployeeID(employee.ID)
+		sh.Logger.Info("got prefs for employee", "employee_id", employee.ID)
+		if err != nil {
+			sh.Logger.Warn("failed to get preferences for employee", "employee_id", employee.ID, "error", err)
+			// Continue without preferences for this employee
+			prefs = []database.EmployeePreference{}
+		}
+
+		// Days of approved holidays and call-offs are left out of the availability
+		unavailability, err := sh.PreferenceStore.GetUnavailability(employee.ID, weekStart, weekStart.AddDate(0, 0, 6))
+		if err != nil {
+			return nil, &scheduleInputError{Status: http.StatusInternalServerError, Message: "failed to get employee unavailability"}
+		}
+		unavailableDays := make(map[string]bool)
+		for day, date := range weekDates {
+			date := date.Format(time.DateOnly)
+			for _, period := range unavailability {
+				if period.StartDate.Format(time.DateOnly) <= date && date <= period.EndDate.Format(time.DateOnly) {
+					unavailableDays[day] = true
+				}
+			}
+		}
+
+		// User Roles
+		userRoles, err := sh.UserRolesStore.GetUserRoles(employee.ID, orgID)
+		if err != nil {
+			sh.Logger.Info("failed to get user roles for employees", "employee_id", employee.ID, "error", err)
+			continue
+		}
+
+		if len(userRoles) == 0 {
+			sh.Logger.Error("no user roles found", "user", employee.ID)
+		}
+
+		if len(skillRequirements) > 0 {
+			eligibleRoles, missing := rolesWithVerifiedSkills(userRoles, skillRequirements, verifiedSkills[employee.ID])
+			if len(missing) > 0 {
+				sh.Logger.Info("required skills not verified, employee not scheduled in roles", "employee_id", employee.ID, "roles", missing)
+				if !slices.ContainsFunc(eligibleRoles, func(role string) bool { return role != "employee" && role != "admin" }) {
+					continue
+				}
+			}
+			userRoles = eligibleRoles
+		}
+
+		if trainees[employee.ID] {
+			userRoles = slices.DeleteFunc(slices.Clone(userRoles), func(role string) bool { return soloRoles[role] })
+			if !slices.ContainsFunc(userRoles, func(role string) bool { return role != "employee" && role != "admin" }) {
+				sh.Logger.Info("employee in training only holds solo coverage roles, not scheduled", "employee_id", employee.ID)
+				continue
+			}
+		}
+
+		// Build available/preferred days and hours maps
+		availableDays := []string{}
+		preferredDays := []string{}
+		availableHours := make(map[string]EmployeeHours)
+		preferredHours := make(map[string]EmployeeHours)
+
+		hasAvailability := false
+		for _, pref := range prefs {
+			dayLower := pref.Day
+			if pref.AvailableStartTime != nil && pref.AvailableEndTime != nil {
+				hasAvailability = true
+			}
+			if date, ok := weekDates[strings.ToLower(dayLower)]; ok && hasPermit && date.Format(time.DateOnly) > permitExpiry.Format(time.DateOnly) {
+				continue
+			}
+			if unavailableDays[strings.ToLower(dayLower)] {
+				continue
+			}
+
+			// Available hours
+			if pref.AvailableStartTime != nil && pref.AvailableEndTime != nil {
+				availableDays = append(availableDays, dayLower)
+				availableHours[dayLower] = EmployeeHours{
+					From: *pref.AvailableStartTime,
+					To:   *pref.AvailableEndTime,
+				}
+			}
+
+			// Preferred hours
+			if pref.PreferredStartTime != nil && pref.PreferredEndTime != nil {
+				preferredDays = append(preferredDays, dayLower)
+				preferredHours[dayLower] = EmployeeHours{
+					From: *pref.PreferredStartTime,
+					To:   *pref.PreferredEndTime,
+				}
+			}
+		}
+
+		// Employees who never submitted availability are available every day, so their unavailable days
+		// are left out by making them available all day on the others
+		if len(unavailableDays) > 0 && !hasAvailability {
+			for _, day := range database.ValidDays {
+				if !unavailableDays[day] {
+					availableDays = append(availableDays, day)
+					availableHours[day] = EmployeeHours{From: "00:00:00", To: "23:59:59"}
+				}
+			}
+		}
+		if len(unavailableDays) > 0 && len(availableHours) == 0 {
+			sh.Logger.Info("employee unavailable all week, not scheduled", "employee_id", employee.ID)
+			continue
+		}
+
+		// Convert hours per week from int to float64 if needed
+		var maxHoursPerWeek *float64
+		if employee.MaxHoursPerWeek != nil {
+			val := float64(*employee.MaxHoursPerWeek)
+			maxHoursPerWeek = &val
+		}
+
+		var preferredHoursPerWeek *float64
+		if employee.PreferredHoursPerWeek != nil {
+			val := float64(*employee.PreferredHoursPerWeek)
+			preferredHoursPerWeek = &val
+		}
+
+		// Build Employee struct
+		emp := Employee{
+			EmployeeID:            employee.ID,
+			RoleNames:             userRoles,
+			AvailableDays:         availableDays,
+			Preferred_Days:        preferredDays,
+			AvailableHours:        availableHours,
+			PreferredHours:        preferredHours,
+			HourlyWage:            employee.SalaryPerHour,
+			MaxHoursPerWeek:       maxHoursPerWeek,
+			MaxConsecSlots:        employee.MaxConsecSlots,
+			PreferredHoursPerWeek: preferredHoursPerWeek,
+			Supernumerary:         trainees[employee.ID],
+		}
+
+		Employees = append(Employees, emp)
+	}
+	scheduleInput := ScheduleInput{
+		SchedulerConfig:     schedulerConfig,
+		DemandPredictions:   demandDays,
+		PredictionStartDate: time.Now(),
+		Roles:               roles,
+		Employees:           Employees,
+		DeliveryDemand:      deliveryDemand,
+	}
+
+	request := SchedulePredictRequest{
+		Place:         Place,
+		ScheduleInput: scheduleInput,
+	}
+
+	return &request, nil
+}
+
+// generateSchedule asks the ML service for a schedule and falls back to the heuristic
+// scheduler when the service is unreachable or failing. Errors are only returned when
+// falling back is not appropriate (e.g. the ML service rejected the payload).
+func (sh *ScheduleHandler) generateSchedule(request SchedulePredictRequest) (*GenerateScheduleResponse, string, error) {
+	started := time.Now()
+	scheduleResponse, err := sh.requestScheduleFromML(request)
+	recordMLRequest(sh.StatusStore, sh.Logger, request.Place.ID, "/predict/schedule", started, err != nil)
+	if err == nil {
+		return scheduleResponse, scheduleSourceML, nil
+	}
+
+	var serviceErr *mlServiceError
+	if !errors.As(err, &serviceErr) || !serviceErr.unavailable() {
+		return nil, "", err
+	}
+
+	sh.Logger.Warn("ML service unavailable, falling back to heuristic scheduler", "place_id", request.Place.ID, "error", err)
+	fallback := generateHeuristicSchedule(request)
+	return &fallback, scheduleSourceHeuristic, nil
+}
+
+// mlServiceError is returned when the ML service could not be reached or answered with a non-200 status
+type mlServiceError struct {
+	StatusCode int
+	Body       string
+	Err        error
+}
+
+func (e *mlServiceError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("ML service unreachable: %v", e.Err)
+	}
+	return fmt.Sprintf("ML service returned status %d", e.StatusCode)
+}
+
+// unavailable reports whether the failure is on the ML side, in which case a fallback is appropriate
+func (e *mlServiceError) unavailable() bool {
+	return e.StatusCode == 0 || e.StatusCode >= http.StatusInternalServerError
+}
+
+// requestScheduleFromML posts the schedule input to the ML service and decodes its answer
+func (sh *ScheduleHandler) requestScheduleFromML(request SchedulePredictRequest) (*GenerateScheduleResponse, error) {
+	mlURL := os.Getenv("ML_URL")
+	if mlURL == "" {
+		mlURL = "http://cw-ml-service:8000"
+	}
+
+	jsonPayload, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	sh.Logger.Debug("json body in request", "json", string(jsonPayload))
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Post(mlURL+"/predict/schedule", "application/json", bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return nil, &mlServiceError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		sh.Logger.Error("ML API returned error", "status_code", resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &mlServiceError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var scheduleResponse GenerateScheduleResponse
+	if err := json.NewDecoder(resp.Body).Decode(&scheduleResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode ML response: %w", err)
+	}
+
+	return &scheduleResponse, nil
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Predictability pay of organizations subject to predictable scheduling that did not set it: an hour of
+// pay for shifts added or moved within the notice period, half of the hours cut otherwise
+const (
+	defaultPredictabilityPayHours         = 1.0
+	defaultPredictabilityLostHoursPercent = 50
+)
+
+// defaultPredictabilityPayWeeks is how many weeks of past shifts are reported without ?from=
+const defaultPredictabilityPayWeeks = 4
+
+// GetPredictabilityPayHandler flags the changes made by the organization to the shifts between ?from= and
+// ?to= within the notice period of its predictable scheduling law, and computes the predictability pay
+// they owe per employee. By default it covers the last four weeks and the notice period ahead.
+func (sh *ScheduleHandler) GetPredictabilityPayHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can view predictability pay"})
+		return
+	}
+
+	from, to, ok := queryDateRange(c)
+	if !ok {
+		return
+	}
+
+	rules, err := sh.RulesStore.GetRulesByOrganizationID(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get the predictable scheduling rules"})
+		return
+	}
+	policy, ok := service.PredictabilityPolicyFromRules(rules)
+	if !ok {
+		c.JSON(http.StatusConflict, gin.H{"error": "The organization is not subject to predictable scheduling, set predictability_notice_days in its rules"})
+		return
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if from == nil {
+		start := today.AddDate(0, 0, -7*defaultPredictabilityPayWeeks)
+		if to != nil && !start.Before(*to) {
+			start = to.AddDate(0, 0, -7*defaultPredictabilityPayWeeks)
+		}
+		from = &start
+	}
+	if to == nil {
+		end := today.AddDate(0, 0, policy.NoticeDays+1)
+		if !from.Before(end) {
+			end = from.AddDate(0, 0, 1)
+		}
+		to = &end
+	}
+
+	report, err := service.PredictabilityPay(sh.ScheduleStore, sh.UserStore, user.OrganizationID, policy, *from, *to)
+	if err != nil {
+		sh.Logger.Error("failed to compute predictability pay", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute predictability pay"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Predictability pay retrieved successfully",
+		"data":    report,
+	})
+}
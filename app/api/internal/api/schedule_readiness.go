@@ -0,0 +1,198 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// ScheduleReadiness tells whether the available employees can plausibly cover the demand before a
+// schedule is generated. Ready is false when a slot lacks available employees of a role, when a role
+// needs more hours than its employees can work, or when the scheduler input is incomplete (Problems).
+type ScheduleReadiness struct {
+	Ready        bool            `json:"ready"`
+	SlotsChecked int             `json:"slots_checked"`
+	ShortSlots   int             `json:"short_slots"`
+	Roles        []RoleReadiness `json:"roles"`
+	Shortfalls   []SlotShortfall `json:"shortfalls"`
+	Problems     []string        `json:"problems,omitempty"`
+}
+
+// RoleReadiness compares the hours the demand needs of a role with the most its employees could work.
+// AvailableHours counts every employee of the role in full, so a role can still be short when its
+// employees hold other roles too.
+type RoleReadiness struct {
+	Role           string  `json:"role"`
+	Employees      int     `json:"employees"`
+	NeededHours    float64 `json:"needed_hours"`
+	AvailableHours float64 `json:"available_hours"`
+	ShortSlots     int     `json:"short_slots"`
+}
+
+// SlotShortfall is a slot where fewer employees of a role are available than the demand needs
+type SlotShortfall struct {
+	Day       string `json:"day"`
+	Time      string `json:"time"`
+	Role      string `json:"role"`
+	Required  int    `json:"required"`
+	Available int    `json:"available"`
+	Shortfall int    `json:"shortfall"`
+}
+
+// GetScheduleReadinessHandler checks the latest demand against the availability of the employees
+// before the ML service is called, so managers can fix availability instead of getting an infeasible
+// schedule
+func (sh *ScheduleHandler) GetScheduleReadinessHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can check schedule readiness"})
+		return
+	}
+
+	request, inputErr := sh.buildSchedulePredictRequest(user.OrganizationID)
+	if inputErr != nil {
+		c.JSON(inputErr.Status, gin.H{"error": inputErr.Message})
+		return
+	}
+
+	readiness := assessScheduleReadiness(*request)
+	if err := validateSchedulePredictRequest(*request); err != nil {
+		readiness.Ready = false
+		readiness.Problems = []string{err.Error()}
+		if payloadErr, ok := err.(*MLPayloadError); ok {
+			readiness.Problems = payloadErr.Problems
+		}
+	}
+
+	sh.Logger.Info("schedule readiness checked", "org_id", user.OrganizationID, "ready", readiness.Ready,
+		"short_slots", readiness.ShortSlots, "problems", len(readiness.Problems))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Schedule readiness checked successfully",
+		"data":    readiness,
+	})
+}
+
+// assessScheduleReadiness counts, for every open slot of the demand and every role, the employees of
+// the role whose availability covers the slot, the same way the fallback scheduler picks them
+func assessScheduleReadiness(request SchedulePredictRequest) ScheduleReadiness {
+	input := request.ScheduleInput
+	slotMinutes := schedulerSlotMinutes(input.SchedulerConfig)
+	openingHours := openingWindows(request.Place)
+
+	// employees in training are scheduled on top of the demand, so they cover none of it
+	employees := make([]*heuristicEmployee, 0, len(input.Employees))
+	for _, emp := range input.Employees {
+		if emp.Supernumerary {
+			continue
+		}
+		employees = append(employees, newHeuristicEmployee(emp, slotMinutes))
+	}
+
+	roles := append(input.Roles[:0:0], input.Roles...)
+	sort.Slice(roles, func(a, b int) bool { return roles[a].Role < roles[b].Role })
+
+	summaries := make(map[string]*RoleReadiness, len(roles))
+	for _, role := range roles {
+		summaries[role.Role] = &RoleReadiness{Role: role.Role}
+	}
+	availableMinutes := make(map[*heuristicEmployee]int, len(employees))
+
+	readiness := ScheduleReadiness{Shortfalls: []SlotShortfall{}}
+	for _, demandDay := range input.DemandPredictions {
+		day := strings.ToLower(demandDay.Day)
+		window, open := openingHours[day]
+		if !open {
+			continue
+		}
+
+		itemsPerHour := make(map[int]int)
+		for _, hour := range demandDay.Hours {
+			itemsPerHour[hour.HourNo] = hour.ItemCount
+		}
+		deliveries := deliveriesByHour(input.DeliveryDemand, day)
+
+		for _, emp := range employees {
+			availableMinutes[emp] += emp.availableMinutes(day, window)
+		}
+
+		for start := window[0]; start+slotMinutes <= window[1]; start += slotMinutes {
+			end := start + slotMinutes
+			readiness.SlotsChecked++
+			short := false
+			for _, role := range roles {
+				required := roleHeadcount(role, roleDemand(input.DeliveryDemand, role.Role, itemsPerHour, deliveries, start/60))
+				if required == 0 {
+					continue
+				}
+				summary := summaries[role.Role]
+				summary.NeededHours += float64(required*slotMinutes) / 60
+
+				available := 0
+				for _, emp := range employees {
+					if emp.roles[role.Role] && emp.availableFor(day, start, end) {
+						available++
+					}
+				}
+				if available < required {
+					short = true
+					summary.ShortSlots++
+					readiness.Shortfalls = append(readiness.Shortfalls, SlotShortfall{
+						Day:       day,
+						Time:      formatClockMinutes(start) + "-" + formatClockMinutes(end),
+						Role:      role.Role,
+						Required:  required,
+						Available: available,
+						Shortfall: required - available,
+					})
+				}
+			}
+			if short {
+				readiness.ShortSlots++
+			}
+		}
+	}
+
+	readiness.Ready = len(readiness.Shortfalls) == 0
+	readiness.Roles = make([]RoleReadiness, 0, len(roles))
+	for _, role := range roles {
+		summary := summaries[role.Role]
+		for _, emp := range employees {
+			if !emp.roles[role.Role] {
+				continue
+			}
+			summary.Employees++
+			minutes := availableMinutes[emp]
+			if emp.maxMinutes >= 0 {
+				minutes = min(minutes, emp.maxMinutes)
+			}
+			summary.AvailableHours += float64(minutes) / 60
+		}
+		summary.NeededHours = math.Round(summary.NeededHours*100) / 100
+		summary.AvailableHours = math.Round(summary.AvailableHours*100) / 100
+		if summary.NeededHours > summary.AvailableHours {
+			readiness.Ready = false
+		}
+		readiness.Roles = append(readiness.Roles, *summary)
+	}
+	return readiness
+}
+
+// availableMinutes is how long the employee is available while the place is open on the day
+func (he *heuristicEmployee) availableMinutes(day string, open [2]int) int {
+	if he.alwaysAvailable {
+		return open[1] - open[0]
+	}
+	window, ok := he.available[day]
+	if !ok {
+		return 0
+	}
+	return max(0, min(window[1], open[1])-max(window[0], open[0]))
+}
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ScheduleReplayRules are the rules a past week is replayed with, a rule left out keeps the value of the
+// organization rules
+type ScheduleReplayRules struct {
+	Name                string   `json:"name" binding:"required"`
+	SlotLenHour         *float64 `json:"slot_len_hour" binding:"omitempty,gt=0,lte=4"`
+	MinShiftLengthSlots *int     `json:"min_shift_length_slots" binding:"omitempty,min=1"`
+	MinRestSlots        *int     `json:"min_rest_slots" binding:"omitempty,min=0"`
+	MeetAllDemand       *bool    `json:"meet_all_demand"`
+	MaxLaborCost        *float64 `json:"max_labor_cost" binding:"omitempty,gt=0"`
+}
+
+type ScheduleReplayRequest struct {
+	// WeekStart is the first day of the week replayed (YYYY-MM-DD), which must be over
+	WeekStart string                `json:"week_start" binding:"required"`
+	Scenarios []ScheduleReplayRules `json:"scenarios" binding:"required,min=1,max=5,dive"`
+}
+
+// ScheduleReplayResult is the schedule the fallback scheduler builds for the demand of the week under a
+// set of rules. The differences are against the replay under the current rules.
+type ScheduleReplayResult struct {
+	Name                      string   `json:"name"`
+	LaborCost                 float64  `json:"labor_cost"`
+	ScheduledHours            float64  `json:"scheduled_hours"`
+	EmployeesScheduled        int      `json:"employees_scheduled"`
+	CoveragePercent           float64  `json:"coverage_percent"`
+	UncoveredSlots            int      `json:"uncovered_slots"`
+	LaborCostDifference       *float64 `json:"labor_cost_difference,omitempty"`
+	CoveragePercentDifference *float64 `json:"coverage_percent_difference,omitempty"`
+	Error                     string   `json:"error,omitempty"`
+	Details                   []string `json:"details,omitempty"`
+}
+
+// ScheduleReplayWeek is the demand of the replayed week and the schedule the employees actually had
+type ScheduleReplayWeek struct {
+	From                   string  `json:"from"`
+	To                     string  `json:"to"`
+	Orders                 int     `json:"orders"`
+	Items                  int     `json:"items"`
+	DeliveryOrders         int     `json:"delivery_orders"`
+	ScheduledLaborCost     float64 `json:"scheduled_labor_cost"`
+	ScheduledHours         float64 `json:"scheduled_hours"`
+	EmployeesWithoutSalary int     `json:"employees_without_salary"`
+}
+
+// ReplayScheduleHandler replays the orders of a past week against the current rules and alternative
+// ones with the fallback scheduler, and reports how cost and coverage would have differed. The current
+// employees, roles and availability are scheduled, the ML service is not called and nothing is stored.
+func (sh *ScheduleHandler) ReplayScheduleHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can replay schedules"})
+		return
+	}
+
+	var req ScheduleReplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	weekStart, err := time.ParseInLocation(time.DateOnly, req.WeekStart, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "week_start must be a date (YYYY-MM-DD)"})
+		return
+	}
+	weekEnd := weekStart.AddDate(0, 0, 7)
+	if weekEnd.After(startOfDay(time.Now())) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only weeks that are over can be replayed"})
+		return
+	}
+
+	base, inputErr := sh.buildSchedulePredictRequest(user.OrganizationID)
+	if inputErr != nil {
+		c.JSON(inputErr.Status, gin.H{"error": inputErr.Message})
+		return
+	}
+	week, err := sh.replayWeekDemand(user.OrganizationID, base, weekStart, weekEnd)
+	if err != nil {
+		sh.Logger.Error("failed to get the demand of the replayed week", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get the orders of the week"})
+		return
+	}
+
+	employees, err := sh.UserStore.GetUsersByOrganization(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get the schedule of the week"})
+		return
+	}
+	hours, err := sh.ScheduleStore.GetScheduledHours(user.OrganizationID, weekStart, weekEnd)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get the schedule of the week"})
+		return
+	}
+	week.ScheduledLaborCost, week.EmployeesWithoutSalary = service.LaborCost(employees, hours)
+	for _, h := range hours {
+		week.ScheduledHours += h.WorkingHours
+	}
+
+	current := replaySchedule(*base, ScheduleReplayRules{Name: "current rules"})
+	scenarios := make([]ScheduleReplayResult, 0, len(req.Scenarios))
+	for _, rules := range req.Scenarios {
+		result := replaySchedule(*base, rules)
+		if result.Error == "" && current.Error == "" {
+			costDifference := math.Round((result.LaborCost-current.LaborCost)*100) / 100
+			coverageDifference := math.Round((result.CoveragePercent-current.CoveragePercent)*100) / 100
+			result.LaborCostDifference, result.CoveragePercentDifference = &costDifference, &coverageDifference
+		}
+		scenarios = append(scenarios, result)
+	}
+
+	sh.Logger.Info("schedule replayed", "org_id", user.OrganizationID, "week_start", req.WeekStart, "scenarios", len(scenarios))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Schedule replayed successfully",
+		"data": gin.H{
+			"week":      week,
+			"current":   current,
+			"scenarios": scenarios,
+		},
+	})
+}
+
+// replayWeekDemand replaces the predicted demand of the request with the orders and items of the week, and
+// the expected deliveries with those of the week. Days closed that week are left out.
+func (sh *ScheduleHandler) replayWeekDemand(orgID uuid.UUID, request *SchedulePredictRequest, weekStart, weekEnd time.Time) (*ScheduleReplayWeek, error) {
+	demand, err := sh.OrderStore.GetHourlyDemand(orgID, weekStart, weekEnd)
+	if err != nil {
+		return nil, err
+	}
+	closed, err := closedDays(sh.OperatingHoursExceptionStore, orgID, weekStart, 7)
+	if err != nil {
+		return nil, err
+	}
+
+	week := &ScheduleReplayWeek{From: weekStart.Format(time.DateOnly), To: weekEnd.AddDate(0, 0, -1).Format(time.DateOnly)}
+	hoursByDate := make(map[string][]database.PredictionHour)
+	for _, d := range demand {
+		date := d.Date.Format(time.DateOnly)
+		hoursByDate[date] = append(hoursByDate[date], database.PredictionHour{HourNo: d.Hour, OrderCount: d.Orders, ItemCount: d.Items})
+		week.Orders += d.Orders
+		week.Items += d.Items
+	}
+	days := make([]database.PredictionDay, 0, 7)
+	for date := weekStart; date.Before(weekEnd); date = date.AddDate(0, 0, 1) {
+		if closed[date.Format(time.DateOnly)] {
+			continue
+		}
+		hours := hoursByDate[date.Format(time.DateOnly)]
+		if hours == nil {
+			hours = []database.PredictionHour{}
+		}
+		days = append(days, database.PredictionDay{Day: strings.ToLower(date.Weekday().String()), Date: date, Hours: hours})
+	}
+	request.ScheduleInput.DemandPredictions = days
+	request.ScheduleInput.PredictionStartDate = weekStart
+
+	if request.ScheduleInput.DeliveryDemand != nil {
+		volume, err := sh.OrderStore.GetDeliveryVolume(orgID, weekStart, weekEnd)
+		if err != nil {
+			return nil, err
+		}
+		deliveries := averageDeliveries(volume, 1)
+		delivery := &DeliveryDemand{Role: request.ScheduleInput.DeliveryDemand.Role, HistoryWeeks: 1, Days: make([]DeliveryDemandDay, 0, len(days))}
+		for _, day := range days {
+			demandDay := DeliveryDemandDay{Day: day.Day, Date: day.Date.Format(time.DateOnly), Hours: []DeliveryDemandHour{}}
+			for hour := 0; hour < 24; hour++ {
+				if orders := deliveries[day.Day][hour]; orders > 0 {
+					demandDay.Hours = append(demandDay.Hours, DeliveryDemandHour{HourNo: hour, DeliveryOrders: orders})
+					week.DeliveryOrders += orders
+				}
+			}
+			delivery.Days = append(delivery.Days, demandDay)
+		}
+		request.ScheduleInput.DeliveryDemand = delivery
+	}
+	return week, nil
+}
+
+// replaySchedule schedules the request under the rules with the fallback scheduler and measures the
+// schedule against the demand
+func replaySchedule(base SchedulePredictRequest, rules ScheduleReplayRules) ScheduleReplayResult {
+	result := ScheduleReplayResult{Name: rules.Name}
+	request := base
+	cfg := base.ScheduleInput.SchedulerConfig
+	if rules.SlotLenHour != nil {
+		cfg.SlotLenHour = rules.SlotLenHour
+	}
+	if rules.MinShiftLengthSlots != nil {
+		cfg.MinShiftLengthSlots = rules.MinShiftLengthSlots
+	}
+	if rules.MinRestSlots != nil {
+		cfg.MinRestSlots = rules.MinRestSlots
+	}
+	if rules.MeetAllDemand != nil {
+		cfg.MeetAllDemands = rules.MeetAllDemand
+	}
+	if rules.MaxLaborCost != nil {
+		cfg.WeeklyLaborBudget = rules.MaxLaborCost
+	}
+	request.ScheduleInput.SchedulerConfig = cfg
+
+	if err := validateSchedulePredictRequest(request); err != nil {
+		result.Error = "schedule input is incomplete or invalid"
+		if payloadErr, ok := err.(*MLPayloadError); ok {
+			result.Details = payloadErr.Problems
+		}
+		return result
+	}
+
+	response := generateHeuristicSchedule(request)
+	metrics := evaluateSchedule(request, response.ScheduleOutput)
+	result.LaborCost = metrics.LaborCost
+	result.ScheduledHours = metrics.ScheduledHours
+	result.EmployeesScheduled = metrics.EmployeesScheduled
+	result.CoveragePercent = metrics.CoveragePercent
+	result.UncoveredSlots = len(response.ManagementInsights.CoverageGaps)
+	return result
+}
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultScheduleVersions = 10
+	maxScheduleVersions     = 100
+)
+
+// scheduleVersion scores how well a schedule output keyed by employee IDs matches the preferences of
+// the employees: a shift is preferred when it falls inside the preferred hours of its day. Employees
+// without preferences count in the shifts but not in the satisfaction.
+func scheduleVersion(request SchedulePredictRequest, output map[string][]map[string][]string) *database.ScheduleVersion {
+	slotMinutes := schedulerSlotMinutes(request.ScheduleInput.SchedulerConfig)
+	employees := make(map[string]*heuristicEmployee, len(request.ScheduleInput.Employees))
+	for _, emp := range request.ScheduleInput.Employees {
+		employees[emp.EmployeeID.String()] = newHeuristicEmployee(emp, slotMinutes)
+	}
+
+	satisfaction := make(map[uuid.UUID]*database.EmployeeSatisfaction)
+	for day, shifts := range output {
+		day = strings.ToLower(day)
+		for _, shift := range shifts {
+			for timeRange, ids := range shift {
+				parts := strings.Split(timeRange, "-")
+				if len(parts) != 2 {
+					continue
+				}
+				window, ok := clockWindow(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+				if !ok {
+					continue
+				}
+				for _, id := range ids {
+					employeeID, err := uuid.Parse(id)
+					if err != nil {
+						continue
+					}
+					entry := satisfaction[employeeID]
+					if entry == nil {
+						entry = &database.EmployeeSatisfaction{EmployeeID: employeeID}
+						satisfaction[employeeID] = entry
+					}
+					entry.Shifts++
+					if emp, ok := employees[id]; ok && emp.prefers(day, window[0], window[1]) {
+						entry.PreferredShifts++
+					}
+				}
+			}
+		}
+	}
+
+	now := time.Now()
+	version := &database.ScheduleVersion{
+		WeekStart: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()),
+		Employees: make([]database.EmployeeSatisfaction, 0, len(satisfaction)),
+	}
+	withPreferences, preferred := 0, 0
+	for employeeID, entry := range satisfaction {
+		version.Shifts += entry.Shifts
+		version.PreferredShifts += entry.PreferredShifts
+		if emp, ok := employees[employeeID.String()]; ok && len(emp.preferred) > 0 {
+			entry.SatisfactionPercent = satisfactionPercent(entry.PreferredShifts, entry.Shifts)
+			withPreferences += entry.Shifts
+			preferred += entry.PreferredShifts
+		}
+		version.Employees = append(version.Employees, *entry)
+	}
+	if withPreferences > 0 {
+		version.PreferenceSatisfactionPercent = satisfactionPercent(preferred, withPreferences)
+	}
+	sort.Slice(version.Employees, func(a, b int) bool {
+		return version.Employees[a].EmployeeID.String() < version.Employees[b].EmployeeID.String()
+	})
+	return version
+}
+
+func satisfactionPercent(preferred, total int) *float64 {
+	percent := math.Round(float64(preferred)/float64(total)*10000) / 100
+	return &percent
+}
+
+// annotateUtilization adds the satisfaction of each employee to the employee utilization of the
+// management insights, the fallback scheduler keys them by "employee_id" and the ML service by "employee"
+func annotateUtilization(insights *ManagementInsights, version *database.ScheduleVersion) {
+	byEmployee := make(map[string]*float64, len(version.Employees))
+	for _, employee := range version.Employees {
+		byEmployee[employee.EmployeeID.String()] = employee.SatisfactionPercent
+	}
+	for _, utilization := range insights.EmployeeUtilization {
+		id, ok := utilization["employee_id"].(string)
+		if !ok {
+			id, _ = utilization["employee"].(string)
+		}
+		if percent, ok := byEmployee[id]; ok {
+			utilization["preference_satisfaction_percent"] = percent
+		}
+	}
+}
+
+// GetScheduleVersionsHandler lists the latest generated schedules with their preference satisfaction,
+// ?limit= of them (10 by default, at most 100)
+func (sh *ScheduleHandler) GetScheduleVersionsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can view schedule versions"})
+		return
+	}
+
+	limit := defaultScheduleVersions
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxScheduleVersions {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxScheduleVersions)})
+			return
+		}
+		limit = parsed
+	}
+
+	versions, err := sh.VersionStore.GetScheduleVersions(user.OrganizationID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve schedule versions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Schedule versions retrieved successfully", "data": versions})
+}
+
+// GetScheduleVersionHandler returns a generated schedule with the preference satisfaction of each
+// employee, least satisfied first. The id "latest" is the last generated schedule.
+func (sh *ScheduleHandler) GetScheduleVersionHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can view schedule versions"})
+		return
+	}
+
+	var versionID uuid.UUID
+	if c.Param("id") == "latest" {
+		versions, err := sh.VersionStore.GetScheduleVersions(user.OrganizationID, 1)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve schedule version"})
+			return
+		}
+		if len(versions) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No schedule has been generated yet"})
+			return
+		}
+		versionID = versions[0].ID
+	} else {
+		parsed, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule version ID"})
+			return
+		}
+		versionID = parsed
+	}
+
+	version, err := sh.VersionStore.GetScheduleVersion(user.OrganizationID, versionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Schedule version not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve schedule version"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Schedule version retrieved successfully", "data": version})
+}
@@ -0,0 +1,291 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ScheduleScenario struct {
+	Name             string      `json:"name" binding:"required"`
+	MeetAllDemand    *bool       `json:"meet_all_demand"`
+	ExcludeEmployees []uuid.UUID `json:"exclude_employees"`
+	MaxLaborCost     *float64    `json:"max_labor_cost" binding:"omitempty,gt=0"`
+	BudgetCutPercent *float64    `json:"budget_cut_percent" binding:"omitempty,gte=0,lt=100"`
+}
+
+type ScheduleScenariosRequest struct {
+	Scenarios []ScheduleScenario `json:"scenarios" binding:"required,min=1,max=5,dive"`
+}
+
+type ScheduleScenarioResult struct {
+	Name                          string                           `json:"name"`
+	ScheduleSource                string                           `json:"schedule_source,omitempty"`
+	ScheduleStatus                string                           `json:"schedule_status,omitempty"`
+	LaborCost                     float64                          `json:"labor_cost"`
+	ScheduledHours                float64                          `json:"scheduled_hours"`
+	EmployeesScheduled            int                              `json:"employees_scheduled"`
+	CoveragePercent               float64                          `json:"coverage_percent"`
+	PreferenceSatisfactionPercent *float64                         `json:"preference_satisfaction_percent"`
+	WithinBudget                  *bool                            `json:"within_budget,omitempty"`
+	BudgetUtilization             *BudgetUtilization               `json:"budget_utilization,omitempty"`
+	ScheduleOutput                map[string][]map[string][]string `json:"schedule_output,omitempty"`
+	Error                         string                           `json:"error,omitempty"`
+	Details                       []string                         `json:"details,omitempty"`
+}
+
+// CompareScheduleScenariosHandler runs schedule generation once per scenario without storing
+// anything, and returns cost, coverage and preference satisfaction side by side
+func (sh *ScheduleHandler) CompareScheduleScenariosHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can compare schedule scenarios"})
+		return
+	}
+
+	var req ScheduleScenariosRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		sh.Logger.Warn("invalid schedule scenarios request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sh.Logger.Info("comparing schedule scenarios", "org_id", user.OrganizationID, "count", len(req.Scenarios))
+
+	base, inputErr := sh.buildSchedulePredictRequest(user.OrganizationID)
+	if inputErr != nil {
+		c.JSON(inputErr.Status, gin.H{"error": inputErr.Message})
+		return
+	}
+
+	results := make([]ScheduleScenarioResult, len(req.Scenarios))
+	var wg sync.WaitGroup
+	for i, scenario := range req.Scenarios {
+		wg.Add(1)
+		go func(i int, scenario ScheduleScenario) {
+			defer wg.Done()
+			results[i] = sh.runScheduleScenario(*base, scenario)
+		}(i, scenario)
+	}
+	wg.Wait()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "schedule scenarios compared successfully",
+		"data":    results,
+	})
+}
+
+func (sh *ScheduleHandler) runScheduleScenario(base SchedulePredictRequest, scenario ScheduleScenario) ScheduleScenarioResult {
+	result := ScheduleScenarioResult{Name: scenario.Name}
+	request := applyScheduleScenario(base, scenario)
+
+	if err := validateSchedulePredictRequest(request); err != nil {
+		result.Error = "schedule input is incomplete or invalid"
+		if payloadErr, ok := err.(*MLPayloadError); ok {
+			result.Details = payloadErr.Problems
+		}
+		return result
+	}
+
+	response, source, err := sh.generateSchedule(request)
+	if err != nil {
+		sh.Logger.Error("failed to generate schedule scenario", "scenario", scenario.Name, "error", err)
+		result.Error = "failed to generate the schedule of the scenario"
+		return result
+	}
+
+	metrics := evaluateSchedule(request, response.ScheduleOutput)
+	result.ScheduleSource = source
+	result.ScheduleStatus = response.ScheduleStatus
+	result.LaborCost = metrics.LaborCost
+	result.ScheduledHours = metrics.ScheduledHours
+	result.EmployeesScheduled = metrics.EmployeesScheduled
+	result.CoveragePercent = metrics.CoveragePercent
+	result.PreferenceSatisfactionPercent = metrics.PreferenceSatisfactionPercent
+	result.ScheduleOutput = response.ScheduleOutput
+	if budget := request.ScheduleInput.SchedulerConfig.WeeklyLaborBudget; budget != nil {
+		utilization := newBudgetUtilization(budget, metrics.LaborCost)
+		result.BudgetUtilization = &utilization
+		result.WithinBudget = utilization.WithinBudget
+	}
+	return result
+}
+
+// applyScheduleScenario returns a copy of the base request with the scenario knobs applied
+func applyScheduleScenario(base SchedulePredictRequest, scenario ScheduleScenario) SchedulePredictRequest {
+	request := base
+	if scenario.MaxLaborCost != nil {
+		budget := *scenario.MaxLaborCost
+		request.ScheduleInput.SchedulerConfig.WeeklyLaborBudget = &budget
+	}
+	if scenario.BudgetCutPercent != nil && request.ScheduleInput.SchedulerConfig.WeeklyLaborBudget != nil {
+		budget := *request.ScheduleInput.SchedulerConfig.WeeklyLaborBudget * (1 - *scenario.BudgetCutPercent/100)
+		request.ScheduleInput.SchedulerConfig.WeeklyLaborBudget = &budget
+	}
+	if scenario.MeetAllDemand != nil {
+		meetAll := *scenario.MeetAllDemand
+		request.ScheduleInput.SchedulerConfig.MeetAllDemands = &meetAll
+	}
+
+	if len(scenario.ExcludeEmployees) > 0 {
+		excluded := make(map[uuid.UUID]bool, len(scenario.ExcludeEmployees))
+		for _, id := range scenario.ExcludeEmployees {
+			excluded[id] = true
+		}
+		employees := make([]Employee, 0, len(base.ScheduleInput.Employees))
+		for _, emp := range base.ScheduleInput.Employees {
+			if !excluded[emp.EmployeeID] {
+				employees = append(employees, emp)
+			}
+		}
+		request.ScheduleInput.Employees = employees
+	}
+	return request
+}
+
+// BudgetUtilization compares a schedule's labor cost with the weekly labor budget
+type BudgetUtilization struct {
+	WeeklyLaborBudget  *float64 `json:"weekly_labor_budget"`
+	LaborCost          float64  `json:"labor_cost"`
+	UtilizationPercent *float64 `json:"utilization_percent"`
+	WithinBudget       *bool    `json:"within_budget"`
+}
+
+func newBudgetUtilization(budget *float64, laborCost float64) BudgetUtilization {
+	utilization := BudgetUtilization{WeeklyLaborBudget: budget, LaborCost: laborCost}
+	if budget != nil && *budget > 0 {
+		percent := math.Round(laborCost / *budget * 10000) / 100
+		within := laborCost <= *budget
+		utilization.UtilizationPercent = &percent
+		utilization.WithinBudget = &within
+	}
+	return utilization
+}
+
+type scheduleMetrics struct {
+	LaborCost                     float64
+	ScheduledHours                float64
+	EmployeesScheduled            int
+	CoveragePercent               float64
+	PreferenceSatisfactionPercent *float64
+}
+
+// evaluateSchedule computes cost, demand coverage and preference satisfaction for a schedule
+// output keyed by employee IDs. Coverage compares headcount per slot, employees in training left
+// out, with what the roles need;
+// preference satisfaction is the share of scheduled hours that fall inside preferred hours,
+// counted only for employees who submitted preferences.
+func evaluateSchedule(request SchedulePredictRequest, output map[string][]map[string][]string) scheduleMetrics {
+	input := request.ScheduleInput
+	slotMinutes := 60
+	if input.SchedulerConfig.SlotLenHour != nil && *input.SchedulerConfig.SlotLenHour > 0 {
+		slotMinutes = int(math.Round(*input.SchedulerConfig.SlotLenHour * 60))
+	}
+
+	employees := make(map[string]*heuristicEmployee, len(input.Employees))
+	for _, emp := range input.Employees {
+		employees[emp.EmployeeID.String()] = newHeuristicEmployee(emp, slotMinutes)
+	}
+
+	var metrics scheduleMetrics
+	scheduled := make(map[string]bool)
+	preferredTotal, preferredMet := 0, 0
+	shiftsByDay := make(map[string][]heuristicShift)
+
+	for day, shifts := range output {
+		day = strings.ToLower(day)
+		for _, shift := range shifts {
+			for timeRange, ids := range shift {
+				parts := strings.Split(timeRange, "-")
+				if len(parts) != 2 {
+					continue
+				}
+				window, ok := clockWindow(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+				if !ok {
+					continue
+				}
+				shiftsByDay[day] = append(shiftsByDay[day], heuristicShift{start: window[0], end: window[1], ids: ids})
+
+				minutes := window[1] - window[0]
+				for _, id := range ids {
+					scheduled[id] = true
+					metrics.ScheduledHours += float64(minutes) / 60
+					emp, ok := employees[id]
+					if !ok {
+						continue
+					}
+					metrics.LaborCost += float64(minutes) / 60 * emp.wage
+					if len(emp.preferred) > 0 {
+						preferredTotal += minutes
+						if pref, ok := emp.preferred[day]; ok {
+							preferredMet += max(0, min(window[1], pref[1])-max(window[0], pref[0]))
+						}
+					}
+				}
+			}
+		}
+	}
+
+	openingHours := make(map[string][2]int)
+	for _, oh := range request.Place.OpeningHours {
+		if oh.Closed != nil && *oh.Closed {
+			continue
+		}
+		if window, ok := clockWindow(oh.OpeningTime, oh.ClosingTime); ok {
+			openingHours[strings.ToLower(oh.Weekday)] = window
+		}
+	}
+
+	required, covered := 0, 0
+	for _, demandDay := range input.DemandPredictions {
+		day := strings.ToLower(demandDay.Day)
+		window, open := openingHours[day]
+		if !open {
+			continue
+		}
+		itemsPerHour := make(map[int]int)
+		for _, hour := range demandDay.Hours {
+			itemsPerHour[hour.HourNo] = hour.ItemCount
+		}
+		deliveries := deliveriesByHour(input.DeliveryDemand, day)
+		for start := window[0]; start+slotMinutes <= window[1]; start += slotMinutes {
+			need := 0
+			for _, role := range input.Roles {
+				need += roleHeadcount(role, roleDemand(input.DeliveryDemand, role.Role, itemsPerHour, deliveries, start/60))
+			}
+			have := 0
+			for _, shift := range shiftsByDay[day] {
+				if shift.start <= start && start+slotMinutes <= shift.end {
+					for _, id := range shift.ids {
+						if emp, ok := employees[id]; !ok || !emp.supernumerary {
+							have++
+						}
+					}
+				}
+			}
+			required += need
+			covered += min(have, need)
+		}
+	}
+
+	metrics.EmployeesScheduled = len(scheduled)
+	metrics.LaborCost = math.Round(metrics.LaborCost*100) / 100
+	metrics.CoveragePercent = 100
+	if required > 0 {
+		metrics.CoveragePercent = math.Round(float64(covered)/float64(required)*10000) / 100
+	}
+	if preferredTotal > 0 {
+		satisfaction := math.Round(float64(preferredMet)/float64(preferredTotal)*10000) / 100
+		metrics.PreferenceSatisfactionPercent = &satisfaction
+	}
+	return metrics
+}
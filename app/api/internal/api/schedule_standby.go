@@ -0,0 +1,159 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// defaultStandbyPayPercent is the share of the hourly salary paid for standby shifts when the
+// organization has not set one
+const defaultStandbyPayPercent = 25
+
+// StandbyShiftRequest schedules an on-call shift for an employee
+type StandbyShiftRequest struct {
+	EmployeeID uuid.UUID `json:"employee_id" binding:"required"`
+	Date       string    `json:"date" binding:"required"`
+	StartTime  string    `json:"start_time" binding:"required"`
+	EndTime    string    `json:"end_time" binding:"required"`
+}
+
+// CreateStandbyShiftHandler schedules a standby shift. The employee is paid the standby rate for it
+// unless a manager activates it.
+func (sh *ScheduleHandler) CreateStandbyShiftHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		sh.Logger.Warn("forbidden standby shift", "user_id", user.ID, "role", user.UserRole)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can schedule standby shifts"})
+		return
+	}
+
+	var req StandbyShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	date, err := time.Parse(time.DateOnly, req.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date must use the YYYY-MM-DD format"})
+		return
+	}
+	startClock, err := time.Parse("15:04", req.StartTime)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start_time must use the HH:MM format"})
+		return
+	}
+	endClock, err := time.Parse("15:04", req.EndTime)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_time must use the HH:MM format"})
+		return
+	}
+	if !endClock.After(startClock) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_time must be after start_time"})
+		return
+	}
+	now := time.Now()
+	if date.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date cannot be in the past"})
+		return
+	}
+
+	permitExpiries, err := sh.ComplianceStore.GetWorkPermitExpiries(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check the employee's work permit"})
+		return
+	}
+	if expiry, ok := permitExpiries[req.EmployeeID]; ok && date.After(expiry) {
+		c.JSON(http.StatusConflict, gin.H{"error": "The employee's work permit expires on " + expiry.Format(time.DateOnly)})
+		return
+	}
+
+	shift, err := sh.ScheduleStore.StoreStandbyShift(user.OrganizationID, req.EmployeeID, database.ShiftKey{
+		Date:      date,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+	}, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		case errors.Is(err, database.ErrShiftOverlaps):
+			c.JSON(http.StatusConflict, gin.H{"error": "The employee already has a shift at that time"})
+		default:
+			sh.Logger.Error("failed to store standby shift", "error", err, "org_id", user.OrganizationID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule standby shift"})
+		}
+		return
+	}
+	sh.Webhooks.Publish(user.OrganizationID, database.WebhookShiftUpdated, gin.H{"change": "standby_scheduled", "shift": shift})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Standby shift scheduled successfully",
+		"data":    shift,
+	})
+}
+
+// ActivateShiftHandler calls in the employee of a standby shift: the shift becomes a working shift,
+// paid and counted as worked hours, and the employee is notified by email
+func (sh *ScheduleHandler) ActivateShiftHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		sh.Logger.Warn("forbidden shift activation", "user_id", user.ID, "role", user.UserRole)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can activate standby shifts"})
+		return
+	}
+
+	shiftID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid shift ID"})
+		return
+	}
+
+	shift, err := sh.ScheduleStore.ActivateShift(user.OrganizationID, shiftID, time.Now(), user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Shift not found"})
+		case errors.Is(err, database.ErrShiftNotStandby):
+			c.JSON(http.StatusConflict, gin.H{"error": "Only standby shifts that are not activated yet can be activated"})
+		case errors.Is(err, database.ErrShiftEnded):
+			c.JSON(http.StatusConflict, gin.H{"error": "The shift has already ended"})
+		default:
+			sh.Logger.Error("failed to activate shift", "error", err, "shift_id", shiftID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to activate shift"})
+		}
+		return
+	}
+	sh.Webhooks.Publish(user.OrganizationID, database.WebhookShiftUpdated, gin.H{"change": "standby_activated", "shift": shift})
+
+	// the shift is activated even when the employee could not be emailed
+	notified := true
+	if err := sh.EmailService.SendStandbyActivatedEmail(shift.EmployeeEmail, shift.EmployeeName,
+		shift.Date.Format(time.DateOnly), shift.StartTime, shift.EndTime); err != nil {
+		sh.Logger.Error("failed to send standby activated email", "error", err, "shift_id", shiftID)
+		notified = false
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Standby shift activated successfully",
+		"data": gin.H{
+			"shift":    shift,
+			"notified": notified,
+		},
+	})
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// defaultAuditLogLimit is how many audit events are returned when no limit is given
+const defaultAuditLogLimit = 100
+
+type SecurityHandler struct {
+	LoginSecurityStore database.LoginSecurityStore
+	AuditStore         database.AuditStore
+	UserStore          database.UserStore
+	Logger             *slog.Logger
+}
+
+func NewSecurityHandler(loginSecurityStore database.LoginSecurityStore, auditStore database.AuditStore, userStore database.UserStore, logger *slog.Logger) *SecurityHandler {
+	return &SecurityHandler{
+		LoginSecurityStore: loginSecurityStore,
+		AuditStore:         auditStore,
+		UserStore:          userStore,
+		Logger:             logger,
+	}
+}
+
+// UnlockAccountHandler lifts the lockout of an employee's account after too many failed logins
+func (sh *SecurityHandler) UnlockAccountHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		sh.Logger.Warn("forbidden account unlock", "user_id", user.ID, "role", user.UserRole)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can unlock accounts"})
+		return
+	}
+
+	employeeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid employee ID"})
+		return
+	}
+
+	employee, err := sh.UserStore.GetUserByID(employeeID)
+	if err != nil || employee == nil || employee.OrganizationID != user.OrganizationID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		return
+	}
+
+	if err := sh.LoginSecurityStore.UnlockAccount(employeeID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+			return
+		}
+		sh.Logger.Error("failed to unlock account", "error", err, "employee_id", employeeID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock account"})
+		return
+	}
+
+	event := &database.AuditEvent{
+		OrganizationID: user.OrganizationID,
+		ActorID:        &user.ID,
+		TargetUserID:   &employeeID,
+		Action:         database.AuditAccountUnlocked,
+		IPAddress:      c.ClientIP(),
+	}
+	if err := sh.AuditStore.RecordEvent(event); err != nil {
+		sh.Logger.Error("failed to audit account unlock", "error", err, "employee_id", employeeID)
+	}
+
+	sh.Logger.Info("account unlocked", "employee_id", employeeID, "unlocked_by", user.ID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Account unlocked successfully",
+	})
+}
+
+// GetAuditLogHandler lists the latest audit events of the organization, optionally filtered by action
+func (sh *SecurityHandler) GetAuditLogHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		sh.Logger.Warn("forbidden audit log access", "user_id", user.ID, "role", user.UserRole)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can view the audit log"})
+		return
+	}
+
+	limit := defaultAuditLogLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 1000 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+			return
+		}
+		limit = parsed
+	}
+
+	events, err := sh.AuditStore.GetEvents(user.OrganizationID, c.Query("action"), limit)
+	if err != nil {
+		sh.Logger.Error("failed to get audit log", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Audit log retrieved successfully",
+		"data":    events,
+	})
+}
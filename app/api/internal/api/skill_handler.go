@@ -0,0 +1,377 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SkillHandler lets employees declare their skills and certifications and managers verify them. Roles can
+// require skills, which employees need verified to be scheduled in the role.
+type SkillHandler struct {
+	SkillStore   database.SkillStore
+	RoleStore    database.RolesStore
+	OrgStore     database.OrgStore
+	EmailService service.EmailService
+	Logger       *slog.Logger
+}
+
+func NewSkillHandler(skillStore database.SkillStore, roleStore database.RolesStore, orgStore database.OrgStore, emailService service.EmailService, logger *slog.Logger) *SkillHandler {
+	return &SkillHandler{
+		SkillStore:   skillStore,
+		RoleStore:    roleStore,
+		OrgStore:     orgStore,
+		EmailService: emailService,
+		Logger:       logger,
+	}
+}
+
+// ProposeSkillRequest declares a skill, ExpiresOn (YYYY-MM-DD) is the last day a certification is valid
+type ProposeSkillRequest struct {
+	Name      string  `json:"name" binding:"required,max=100"`
+	Kind      string  `json:"kind" binding:"omitempty,oneof=skill certification"`
+	ExpiresOn *string `json:"expires_on"`
+	Note      *string `json:"note" binding:"omitempty,max=500"`
+}
+
+type ReviewSkillRequest struct {
+	Note *string `json:"note" binding:"omitempty,max=500"`
+}
+
+// RoleSkillsRequest replaces the skills a role requires, an empty list removes the requirement
+type RoleSkillsRequest struct {
+	Skills []string `json:"skills" binding:"max=20,dive,max=100"`
+}
+
+var skillStatuses = []string{database.SkillPending, database.SkillVerified, database.SkillRejected}
+
+// rolesWithVerifiedSkills splits the roles of an employee into those they have every required skill of
+// verified and those they are missing a skill for. Skill names are compared regardless of case.
+func rolesWithVerifiedSkills(roles []string, requirements []database.RoleSkillRequirement, verified []string) (eligible, missing []string) {
+	held := make(map[string]bool, len(verified))
+	for _, skill := range verified {
+		held[strings.ToLower(skill)] = true
+	}
+	required := make(map[string][]string, len(requirements))
+	for _, requirement := range requirements {
+		required[requirement.Role] = requirement.Skills
+	}
+
+	for _, role := range roles {
+		ok := true
+		for _, skill := range required[role] {
+			if !held[strings.ToLower(skill)] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			eligible = append(eligible, role)
+		} else {
+			missing = append(missing, role)
+		}
+	}
+	return eligible, missing
+}
+
+// GetMySkillsHandler lists the skills the current user declared and their verification
+func (sh *SkillHandler) GetMySkillsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	skills, err := sh.SkillStore.GetSkills(user.OrganizationID, &user.ID, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve skills"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Skills retrieved successfully", "data": skills})
+}
+
+// ProposeSkillHandler declares a skill or certification of the current user. It stays pending until a
+// manager verifies it, declaring it again sends it back for verification.
+func (sh *SkillHandler) ProposeSkillHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	var req ProposeSkillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	skill := &database.EmployeeSkill{
+		OrganizationID: user.OrganizationID,
+		EmployeeID:     user.ID,
+		EmployeeName:   user.FullName,
+		EmployeeEmail:  user.Email,
+		Name:           name,
+		Kind:           database.SkillKindSkill,
+		Note:           req.Note,
+	}
+	if req.Kind != "" {
+		skill.Kind = req.Kind
+	}
+	if req.ExpiresOn != nil {
+		expiresOn, err := time.Parse(time.DateOnly, *req.ExpiresOn)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid expires_on, expected YYYY-MM-DD"})
+			return
+		}
+		if expiresOn.Format(time.DateOnly) < time.Now().Format(time.DateOnly) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_on must not be in the past"})
+			return
+		}
+		skill.ExpiresOn = &expiresOn
+	}
+
+	if err := sh.SkillStore.ProposeSkill(skill); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to declare skill"})
+		return
+	}
+
+	// managers verify the skills of employees, admins those of managers
+	var reviewerEmails []string
+	var err error
+	if user.UserRole == "employee" {
+		reviewerEmails, err = sh.OrgStore.GetManagerEmailsByOrgID(user.OrganizationID)
+	} else {
+		reviewerEmails, err = sh.OrgStore.GetAdminEmailsByOrgID(user.OrganizationID)
+	}
+	if err != nil {
+		sh.Logger.Error("failed to get skill reviewer emails", "error", err, "org_id", user.OrganizationID)
+	}
+	reviewerEmails = slices.DeleteFunc(reviewerEmails, func(email string) bool { return email == user.Email })
+	if len(reviewerEmails) > 0 {
+		if err := sh.EmailService.SendSkillProposedEmail(reviewerEmails, user.FullName, skill.Name, skill.Kind); err != nil {
+			sh.Logger.Error("failed to send skill proposed email", "error", err, "skill_id", skill.ID)
+		}
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Skill declared, awaiting verification", "data": skill})
+}
+
+// DeleteMySkillHandler removes a skill the current user declared
+func (sh *SkillHandler) DeleteMySkillHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	skillID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid skill ID"})
+		return
+	}
+
+	if err := sh.SkillStore.DeleteSkill(user.ID, skillID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Skill not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete skill"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Skill deleted successfully"})
+}
+
+// GetSkillsHandler lists the skills declared in the organization, filtered with ?status= and ?employee_id=
+func (sh *SkillHandler) GetSkillsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access the skills of employees"})
+		return
+	}
+
+	status := c.Query("status")
+	if status != "" && !slices.Contains(skillStatuses, status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of pending, verified, rejected"})
+		return
+	}
+	var employeeID *uuid.UUID
+	if value := c.Query("employee_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid employee_id"})
+			return
+		}
+		employeeID = &id
+	}
+
+	skills, err := sh.SkillStore.GetSkills(user.OrganizationID, employeeID, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve skills"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Skills retrieved successfully", "data": skills})
+}
+
+// VerifySkillHandler verifies a pending skill, which then counts towards the roles the employee is scheduled in
+func (sh *SkillHandler) VerifySkillHandler(c *gin.Context) {
+	sh.reviewSkill(c, database.SkillVerified)
+}
+
+// RejectSkillHandler rejects a pending skill, the note tells the employee why
+func (sh *SkillHandler) RejectSkillHandler(c *gin.Context) {
+	sh.reviewSkill(c, database.SkillRejected)
+}
+
+func (sh *SkillHandler) reviewSkill(c *gin.Context, status string) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can review skills"})
+		return
+	}
+
+	skillID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid skill ID"})
+		return
+	}
+
+	var req ReviewSkillRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	skill, err := sh.SkillStore.GetSkill(user.OrganizationID, skillID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Skill not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve skill"})
+		return
+	}
+	if skill.EmployeeID == user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot review your own skills"})
+		return
+	}
+	if skill.Status != database.SkillPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "The skill was already " + skill.Status})
+		return
+	}
+	if status == database.SkillVerified && skill.ExpiresOn != nil && skill.ExpiresOn.Format(time.DateOnly) < time.Now().Format(time.DateOnly) {
+		c.JSON(http.StatusConflict, gin.H{"error": "The certification has expired and cannot be verified"})
+		return
+	}
+
+	skill.Status = status
+	skill.ReviewedBy = &user.ID
+	skill.ReviewNote = req.Note
+	if err := sh.SkillStore.ReviewSkill(skill); err != nil {
+		if errors.Is(err, database.ErrSkillReviewed) {
+			c.JSON(http.StatusConflict, gin.H{"error": "The skill was already reviewed"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to review skill"})
+		return
+	}
+
+	if err := sh.EmailService.SendSkillReviewedEmail(skill.EmployeeEmail, skill.EmployeeName, skill.Name, status, req.Note); err != nil {
+		sh.Logger.Error("failed to send skill reviewed email", "error", err, "skill_id", skill.ID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Skill " + status + " successfully", "data": skill})
+}
+
+// GetRoleSkillRequirementsHandler lists the roles that require skills
+func (sh *SkillHandler) GetRoleSkillRequirementsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access role requirements"})
+		return
+	}
+
+	requirements, err := sh.SkillStore.GetRoleSkillRequirements(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve role requirements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role requirements retrieved successfully", "data": requirements})
+}
+
+// SetRoleSkillRequirementHandler replaces the skills the :role requires. Employees without all of them
+// verified are no longer scheduled in the role.
+func (sh *SkillHandler) SetRoleSkillRequirementHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change role requirements"})
+		return
+	}
+
+	var req RoleSkillsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := sh.RoleStore.GetRoleByName(user.OrganizationID, c.Param("role"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve role"})
+		return
+	}
+	if role == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+		return
+	}
+
+	requirement := database.RoleSkillRequirement{Role: role.Role, Skills: []string{}}
+	for _, skill := range req.Skills {
+		skill = strings.TrimSpace(skill)
+		if skill == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "skills must not be empty"})
+			return
+		}
+		if !slices.ContainsFunc(requirement.Skills, func(s string) bool { return strings.EqualFold(s, skill) }) {
+			requirement.Skills = append(requirement.Skills, skill)
+		}
+	}
+
+	if err := sh.SkillStore.SetRoleSkillRequirement(user.OrganizationID, requirement, user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save role requirements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role requirements saved successfully", "data": requirement})
+}
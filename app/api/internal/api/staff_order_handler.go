@@ -0,0 +1,202 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// StaffOrderHandler tags orders as staff meals or employee discounts. Tagged orders are left out of the
+// revenue insights and reported per employee for payroll deductions.
+type StaffOrderHandler struct {
+	StaffOrderStore database.StaffOrderStore
+	UserStore       database.UserStore
+	Logger          *slog.Logger
+}
+
+func NewStaffOrderHandler(staffOrderStore database.StaffOrderStore, userStore database.UserStore, logger *slog.Logger) *StaffOrderHandler {
+	return &StaffOrderHandler{
+		StaffOrderStore: staffOrderStore,
+		UserStore:       userStore,
+		Logger:          logger,
+	}
+}
+
+type TagStaffOrderRequest struct {
+	EmployeeID uuid.UUID `json:"employee_id" binding:"required"`
+	Kind       string    `json:"kind" binding:"required"`
+}
+
+// staffOrderManager returns the user when they may tag orders and read the consumption report
+func staffOrderManager(c *gin.Context) *database.User {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return nil
+	}
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can manage staff orders"})
+		return nil
+	}
+	return user
+}
+
+// TagStaffOrderHandler tags the order as a staff meal or an employee discount of an employee of the
+// organization, replacing an earlier tag
+func (sh *StaffOrderHandler) TagStaffOrderHandler(c *gin.Context) {
+	user := staffOrderManager(c)
+	if user == nil {
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	var request TagStaffOrderRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !database.IsStaffOrderKind(request.Kind) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid kind, expected staff_meal or employee_discount"})
+		return
+	}
+
+	employee, err := sh.UserStore.GetUserByID(request.EmployeeID)
+	if err != nil || employee == nil || employee.OrganizationID != user.OrganizationID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		return
+	}
+
+	tag := &database.StaffOrder{
+		OrderID:    orderID,
+		EmployeeID: employee.ID,
+		Kind:       request.Kind,
+		TaggedBy:   &user.ID,
+	}
+	if err := sh.StaffOrderStore.TagStaffOrder(user.OrganizationID, tag); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+			return
+		}
+		sh.Logger.Error("failed to tag staff order", "error", err, "order_id", orderID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to tag order"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Order tagged successfully",
+		"data":    tag,
+	})
+}
+
+// UntagStaffOrderHandler counts the order as a customer order again
+func (sh *StaffOrderHandler) UntagStaffOrderHandler(c *gin.Context) {
+	user := staffOrderManager(c)
+	if user == nil {
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	if err := sh.StaffOrderStore.UntagStaffOrder(user.OrganizationID, orderID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order is not tagged as a staff order"})
+			return
+		}
+		sh.Logger.Error("failed to untag staff order", "error", err, "order_id", orderID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to untag order"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Order untagged successfully"})
+}
+
+// GetStaffMealReportHandler returns the staff meals and employee discounts of every employee over a month
+// (?month=YYYY-MM, the current month by default), as JSON or as CSV for payroll
+func (sh *StaffOrderHandler) GetStaffMealReportHandler(c *gin.Context) {
+	user := staffOrderManager(c)
+	if user == nil {
+		return
+	}
+
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	if value := c.Query("month"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01", value, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month, expected YYYY-MM"})
+			return
+		}
+		from = parsed
+	}
+	to := from.AddDate(0, 1, 0)
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format, expected json or csv"})
+		return
+	}
+
+	consumption, err := sh.StaffOrderStore.GetStaffMealConsumption(user.OrganizationID, from, to)
+	if err != nil {
+		sh.Logger.Error("failed to get staff meal consumption", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve staff meal report"})
+		return
+	}
+
+	month := from.Format("2006-01")
+	if format == "csv" {
+		var buf bytes.Buffer
+		writer := csv.NewWriter(&buf)
+		writer.Write([]string{"employee_id", "full_name", "staff_meals", "staff_meal_value", "staff_meal_benefit",
+			"discounted_orders", "discounted_value", "discount_benefit", "total_benefit"})
+		for _, e := range consumption {
+			writer.Write([]string{
+				e.EmployeeID.String(),
+				e.FullName,
+				strconv.Itoa(e.StaffMeals),
+				strconv.FormatFloat(e.StaffMealValue, 'f', 2, 64),
+				strconv.FormatFloat(e.StaffMealBenefit, 'f', 2, 64),
+				strconv.Itoa(e.DiscountedOrders),
+				strconv.FormatFloat(e.DiscountedValue, 'f', 2, 64),
+				strconv.FormatFloat(e.DiscountBenefit, 'f', 2, 64),
+				strconv.FormatFloat(e.TotalBenefit, 'f', 2, 64),
+			})
+		}
+		writer.Flush()
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="staff-meals-%s.csv"`, month))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+		return
+	}
+
+	var totalBenefit float64
+	for _, e := range consumption {
+		totalBenefit += e.TotalBenefit
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Staff meal report retrieved successfully",
+		"data": gin.H{
+			"month":         month,
+			"total_benefit": totalBenefit,
+			"employees":     consumption,
+		},
+	})
+}
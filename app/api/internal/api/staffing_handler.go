@@ -14,16 +14,18 @@ import (
 	"github.com/clockwise/clockwise/backend/internal/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type StaffingHandler struct {
-	userStore      database.UserStore
-	orgStore       database.OrgStore
-	userRolesStore database.UserRolesStore
-	rolesStore     database.RolesStore
-	uploadService  service.UploadService
-	emailService   service.EmailService
-	Logger         *slog.Logger
+	userStore        database.UserStore
+	orgStore         database.OrgStore
+	userRolesStore   database.UserRolesStore
+	rolesStore       database.RolesStore
+	uploadService    service.UploadService
+	emailService     service.EmailService
+	customFieldStore database.CustomFieldStore
+	Logger           *slog.Logger
 }
 
 func NewStaffingHandler(
@@ -33,16 +35,18 @@ func NewStaffingHandler(
 	rolesStore database.RolesStore,
 	uploadService service.UploadService,
 	emailService service.EmailService,
+	customFieldStore database.CustomFieldStore,
 	logger *slog.Logger,
 ) *StaffingHandler {
 	return &StaffingHandler{
-		userStore:      userStore,
-		orgStore:       orgStore,
-		userRolesStore: userRolesStore,
-		rolesStore:     rolesStore,
-		uploadService:  uploadService,
-		emailService:   emailService,
-		Logger:         logger,
+		userStore:        userStore,
+		orgStore:         orgStore,
+		userRolesStore:   userRolesStore,
+		rolesStore:       rolesStore,
+		uploadService:    uploadService,
+		emailService:     emailService,
+		customFieldStore: customFieldStore,
+		Logger:           logger,
 	}
 }
 
@@ -114,6 +118,10 @@ func (h *StaffingHandler) UploadEmployeesCSV(c *gin.Context) {
 	csvData, err := h.uploadService.ParseCSV(file)
 	if err != nil {
 		h.Logger.Error("failed to parse CSV", "error", err)
+		if err == service.ErrTooManyRows {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "CSV file has too many rows", "hint": middleware.UploadSplitHint})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -318,6 +326,18 @@ func (h *StaffingHandler) GetAllEmployees(c *gin.Context) {
 		return
 	}
 
+	// Copies carry the custom fields, the users may be shared with the cache
+	employees, ok := withCustomFields(c, h.customFieldStore, h.Logger, user.OrganizationID, database.CustomFieldEmployee, employees,
+		func(u *database.User) uuid.UUID { return u.ID },
+		func(u *database.User, values map[string]any) *database.User {
+			employee := *u
+			employee.CustomFields = values
+			return &employee
+		})
+	if !ok {
+		return
+	}
+
 	h.Logger.Info("employees retrieved", "org_id", user.OrganizationID, "count", len(employees))
 	c.JSON(http.StatusOK, gin.H{
 		"employees": employees,
@@ -0,0 +1,376 @@
+package api
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const maxStatusBoardsPerOrganization = 20
+
+// StatusBoardHandler serves the wall displays of organizations, such as a breakroom TV. A board reads the
+// schedule of the day and the live order counts with its token, without logging in, so the view leaves
+// out wages, revenue, emails and ids. Views are computed at most once per STATUS_BOARD_REFRESH (30s) per
+// organization, however many boards poll them, and carry an ETag so unchanged views are answered with 304.
+type StatusBoardHandler struct {
+	StatusBoardStore database.StatusBoardStore
+	ScheduleStore    database.ScheduleStore
+	OrderStore       database.OrderStore
+	Refresh          time.Duration
+	Logger           *slog.Logger
+
+	mu    sync.Mutex
+	views map[uuid.UUID]*statusBoardCache
+}
+
+// statusBoardCache is the last view computed for an organization
+type statusBoardCache struct {
+	view      StatusBoardView
+	etag      string
+	expiresAt time.Time
+}
+
+func NewStatusBoardHandler(statusBoardStore database.StatusBoardStore, scheduleStore database.ScheduleStore, orderStore database.OrderStore, logger *slog.Logger) *StatusBoardHandler {
+	refresh := 30 * time.Second
+	if value := os.Getenv("STATUS_BOARD_REFRESH"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed >= time.Second {
+			refresh = parsed
+		}
+	}
+
+	return &StatusBoardHandler{
+		StatusBoardStore: statusBoardStore,
+		ScheduleStore:    scheduleStore,
+		OrderStore:       orderStore,
+		Refresh:          refresh,
+		Logger:           logger,
+		views:            make(map[uuid.UUID]*statusBoardCache),
+	}
+}
+
+type StatusBoardRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// StatusBoardWithToken is a board with its token, only sent when the board is registered
+type StatusBoardWithToken struct {
+	database.StatusBoard
+	Token string `json:"token"`
+}
+
+// StatusBoardShift is a shift of the day as shown on a board, the employee by first name and initial
+type StatusBoardShift struct {
+	EmployeeName string `json:"employee_name"`
+	StartTime    string `json:"start_time"`
+	EndTime      string `json:"end_time"`
+	ShiftType    string `json:"shift_type"`
+	OnNow        bool   `json:"on_now"`
+}
+
+// StatusBoardChannel is the number of orders of the day taken on a channel
+type StatusBoardChannel struct {
+	Channel string `json:"channel"`
+	Orders  int    `json:"orders"`
+}
+
+// StatusBoardOrders counts the orders of the day so far
+type StatusBoardOrders struct {
+	Today      int                  `json:"today"`
+	Open       int                  `json:"open"`
+	Deliveries int                  `json:"deliveries"`
+	Channels   []StatusBoardChannel `json:"channels"`
+}
+
+// StatusBoardView is what a board shows
+type StatusBoardView struct {
+	Date           string             `json:"date"`
+	GeneratedAt    time.Time          `json:"generated_at"`
+	RefreshSeconds int                `json:"refresh_seconds"`
+	Shifts         []StatusBoardShift `json:"shifts"`
+	Orders         StatusBoardOrders  `json:"orders"`
+}
+
+func newStatusBoardToken() (string, error) {
+	token, err := utils.GenerateRandomPassword(32)
+	if err != nil {
+		return "", err
+	}
+	return "board_" + token, nil
+}
+
+// boardName shortens a full name to the first name and the initial of the last one
+func boardName(fullName string) string {
+	parts := strings.Fields(fullName)
+	switch len(parts) {
+	case 0:
+		return ""
+	case 1:
+		return parts[0]
+	}
+	last := []rune(parts[len(parts)-1])
+	return parts[0] + " " + strings.ToUpper(string(last[0])) + "."
+}
+
+// boardShifts lists the shifts taking place on the day of dayStart by start, flagging those under way at now
+func boardShifts(shifts []database.Shift, dayStart, now time.Time) []StatusBoardShift {
+	day := shiftPeriod{start: dayStart, end: dayStart.AddDate(0, 0, 1)}
+	type placed struct {
+		period shiftPeriod
+		shift  StatusBoardShift
+	}
+	var today []placed
+	for _, shift := range shifts {
+		period, ok := periodOfShift(shift)
+		if !ok || !period.overlaps(day) {
+			continue
+		}
+		today = append(today, placed{period, StatusBoardShift{
+			EmployeeName: boardName(shift.EmployeeName),
+			StartTime:    shift.StartTime,
+			EndTime:      shift.EndTime,
+			ShiftType:    shift.ShiftType,
+			OnNow:        !now.Before(period.start) && now.Before(period.end),
+		}})
+	}
+	sort.SliceStable(today, func(i, j int) bool {
+		if !today[i].period.start.Equal(today[j].period.start) {
+			return today[i].period.start.Before(today[j].period.start)
+		}
+		return today[i].shift.EmployeeName < today[j].shift.EmployeeName
+	})
+
+	result := make([]StatusBoardShift, len(today))
+	for i, p := range today {
+		result[i] = p.shift
+	}
+	return result
+}
+
+// view returns the view of the organization, computing it when the cached one expired
+func (sh *StatusBoardHandler) view(orgID uuid.UUID) (*statusBoardCache, error) {
+	now := time.Now()
+	sh.mu.Lock()
+	cached, ok := sh.views[orgID]
+	sh.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached, nil
+	}
+
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	// shifts of the day before may run past midnight
+	shifts, err := sh.ScheduleStore.GetShiftsFrom(orgID, dayStart.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+	channels, err := sh.OrderStore.GetChannelBreakdown(orgID, dayStart, now)
+	if err != nil {
+		return nil, err
+	}
+	open, err := sh.OrderStore.GetOrders(orgID, database.OrderFilter{From: &dayStart, Statuses: []string{"incompleted"}})
+	if err != nil {
+		return nil, err
+	}
+
+	view := StatusBoardView{
+		Date:           dayStart.Format(time.DateOnly),
+		GeneratedAt:    now,
+		RefreshSeconds: int(sh.Refresh.Seconds()),
+		Shifts:         boardShifts(shifts, dayStart, now),
+		Orders:         StatusBoardOrders{Open: len(open), Channels: []StatusBoardChannel{}},
+	}
+	for _, channel := range channels {
+		view.Orders.Today += channel.Orders
+		view.Orders.Deliveries += channel.DeliveryOrders
+		view.Orders.Channels = append(view.Orders.Channels, StatusBoardChannel{Channel: channel.Channel, Orders: channel.Orders})
+	}
+
+	// the ETag leaves out when the view was generated, so boards are told when nothing changed
+	content, err := json.Marshal(struct {
+		Date   string
+		Shifts []StatusBoardShift
+		Orders StatusBoardOrders
+	}{view.Date, view.Shifts, view.Orders})
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(content)
+
+	cached = &statusBoardCache{view: view, etag: `"` + hex.EncodeToString(sum[:16]) + `"`, expiresAt: now.Add(sh.Refresh)}
+	sh.mu.Lock()
+	sh.views[orgID] = cached
+	sh.mu.Unlock()
+	return cached, nil
+}
+
+// GetStatusBoardHandler serves the view of a board. The token is sent as "Authorization: Board <token>" or,
+// for displays that only open a URL, as ?token=. The board only reads the :org organization it belongs to.
+func (sh *StatusBoardHandler) GetStatusBoardHandler(c *gin.Context) {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Board ")
+	if !ok {
+		token = c.Query("token")
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing status board token"})
+		return
+	}
+	orgID, err := uuid.Parse(c.Param("org"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	board, err := sh.StatusBoardStore.GetBoardByToken(token)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or revoked status board token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify status board"})
+		return
+	}
+	if board.OrganizationID != orgID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied: This status board belongs to another organization"})
+		return
+	}
+
+	cached, err := sh.view(orgID)
+	if err != nil {
+		sh.Logger.Error("failed to compile status board", "error", err, "org_id", orgID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compile status board"})
+		return
+	}
+
+	// the token may be in the URL, shared caches must not keep the view
+	c.Header("Cache-Control", "private, max-age="+strconv.Itoa(int(sh.Refresh.Seconds())))
+	c.Header("ETag", cached.etag)
+	if c.GetHeader("If-None-Match") == cached.etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Status board retrieved successfully",
+		"data":    cached.view,
+	})
+}
+
+// adminOnly lets admins through, boards show the schedule of the organization to anyone in the room
+func (sh *StatusBoardHandler) adminOnly(c *gin.Context) *database.User {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return nil
+	}
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can manage status boards"})
+		return nil
+	}
+	return user
+}
+
+// GetStatusBoardsHandler lists the status boards of the organization, revoked ones included
+func (sh *StatusBoardHandler) GetStatusBoardsHandler(c *gin.Context) {
+	user := sh.adminOnly(c)
+	if user == nil {
+		return
+	}
+
+	boards, err := sh.StatusBoardStore.ListBoards(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve status boards"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Status boards retrieved successfully",
+		"data":    boards,
+	})
+}
+
+// CreateStatusBoardHandler registers a status board and returns its token, which is not shown again
+func (sh *StatusBoardHandler) CreateStatusBoardHandler(c *gin.Context) {
+	user := sh.adminOnly(c)
+	if user == nil {
+		return
+	}
+
+	var req StatusBoardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	existing, err := sh.StatusBoardStore.ListBoards(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register status board"})
+		return
+	}
+	active := 0
+	for _, board := range existing {
+		if board.RevokedAt == nil {
+			active++
+		}
+	}
+	if active >= maxStatusBoardsPerOrganization {
+		c.JSON(http.StatusConflict, gin.H{"error": "An organization can have at most " + strconv.Itoa(maxStatusBoardsPerOrganization) + " status boards"})
+		return
+	}
+
+	token, err := newStatusBoardToken()
+	if err != nil {
+		sh.Logger.Error("failed to generate status board token", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register status board"})
+		return
+	}
+	board := &database.StatusBoard{Name: req.Name, CreatedBy: &user.ID}
+	if err := sh.StatusBoardStore.CreateBoard(user.OrganizationID, board, token); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register status board"})
+		return
+	}
+
+	sh.Logger.Info("status board registered", "org_id", user.OrganizationID, "board_id", board.ID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Status board registered successfully. Store the token now, it will not be shown again",
+		"data":    StatusBoardWithToken{StatusBoard: *board, Token: token},
+	})
+}
+
+// RevokeStatusBoardHandler stops a board from reading, for removed or replaced displays
+func (sh *StatusBoardHandler) RevokeStatusBoardHandler(c *gin.Context) {
+	user := sh.adminOnly(c)
+	if user == nil {
+		return
+	}
+
+	boardID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status board ID"})
+		return
+	}
+	if err := sh.StatusBoardStore.RevokeBoard(user.OrganizationID, boardID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Status board not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke status board"})
+		return
+	}
+
+	sh.Logger.Info("status board revoked", "org_id", user.OrganizationID, "board_id", boardID)
+	c.JSON(http.StatusOK, gin.H{"message": "Status board revoked successfully"})
+}
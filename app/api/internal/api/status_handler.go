@@ -0,0 +1,129 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Window over which the ML latency of the status is computed
+const mlLatencyWindow = 24 * time.Hour
+
+type StatusHandler struct {
+	StatusStore database.StatusStore
+	Logger      *slog.Logger
+}
+
+func NewStatusHandler(statusStore database.StatusStore, logger *slog.Logger) *StatusHandler {
+	return &StatusHandler{
+		StatusStore: statusStore,
+		Logger:      logger,
+	}
+}
+
+// recordStatusEvent stores a status event. The status is best effort, failing to record it never fails the caller.
+func recordStatusEvent(store database.StatusStore, logger *slog.Logger, orgID uuid.UUID, event *database.StatusEvent) {
+	if err := store.RecordEvent(orgID, event); err != nil {
+		logger.Warn("failed to record status event", "error", err, "org_id", orgID, "kind", event.Kind)
+	}
+}
+
+// recordIngestion records an upload of rows whose newest record was created at newest, the lag being
+// the time the data took to reach ClockWise
+func recordIngestion(store database.StatusStore, logger *slog.Logger, orgID uuid.UUID, source string, newest time.Time, rows int) {
+	if rows == 0 {
+		return
+	}
+	lag := time.Since(newest).Milliseconds()
+	if lag < 0 {
+		lag = 0
+	}
+	detail := fmt.Sprintf("%d rows", rows)
+	recordStatusEvent(store, logger, orgID, &database.StatusEvent{
+		Kind:       database.StatusEventIngestion,
+		Source:     source,
+		DurationMs: &lag,
+		Detail:     &detail,
+	})
+}
+
+// recordMLRequest records the response time of an ML request started at started
+func recordMLRequest(store database.StatusStore, logger *slog.Logger, orgID uuid.UUID, endpoint string, started time.Time, failed bool) {
+	duration := time.Since(started).Milliseconds()
+	recordStatusEvent(store, logger, orgID, &database.StatusEvent{
+		Kind:       database.StatusEventMLRequest,
+		Source:     endpoint,
+		DurationMs: &duration,
+		Failed:     failed,
+	})
+}
+
+// RecordEmailFailures returns a hook for the email service recording a delivery failure on the
+// organization of each recipient. Recipients without an account (e.g. applicants) are skipped.
+func RecordEmailFailures(userStore database.UserStore, statusStore database.StatusStore, logger *slog.Logger) func(to []string, err error) {
+	return func(to []string, sendErr error) {
+		detail := sendErr.Error()
+		for _, email := range to {
+			user, err := userStore.GetUserByEmail(email)
+			if err != nil {
+				continue
+			}
+			recordStatusEvent(statusStore, logger, user.OrganizationID, &database.StatusEvent{
+				Kind:   database.StatusEventEmailFailure,
+				Source: "smtp",
+				Failed: true,
+				Detail: &detail,
+			})
+		}
+	}
+}
+
+// GetStatusHandler summarizes the health of the API for the organization: the latest data ingestion and
+// its lag, the last successful schedule generation, the last email delivery failure and the latency of
+// the ML service over the last 24 hours
+func (sh *StatusHandler) GetStatusHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access the status"})
+		return
+	}
+
+	latest := make(map[string]*database.StatusEvent, 3)
+	for _, kind := range []string{database.StatusEventIngestion, database.StatusEventScheduleGeneration, database.StatusEventEmailFailure} {
+		event, err := sh.StatusStore.GetLatestEvent(user.OrganizationID, kind)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve status"})
+			return
+		}
+		latest[kind] = event
+	}
+
+	now := time.Now()
+	latency, err := sh.StatusStore.GetMLLatency(user.OrganizationID, now.Add(-mlLatencyWindow))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Status retrieved successfully",
+		"data": gin.H{
+			"generated_at":             now,
+			"last_ingestion":           latest[database.StatusEventIngestion],
+			"last_schedule_generation": latest[database.StatusEventScheduleGeneration],
+			"last_email_failure":       latest[database.StatusEventEmailFailure],
+			"ml_latency":               latency,
+			"ml_latency_window_hours":  int(mlLatencyWindow.Hours()),
+		},
+	})
+}
@@ -0,0 +1,265 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// totpIssuer names the accounts in authenticator apps
+const totpIssuer = "ClockWise"
+
+// StepUpHandler lets users re-authenticate with their password or an authenticator app code before the
+// sensitive actions the StepUpGuard protects, and set up the authenticator app
+type StepUpHandler struct {
+	UserStore  database.UserStore
+	TOTPStore  database.TOTPStore
+	AuditStore database.AuditStore
+	Guard      *middleware.StepUpGuard
+	Logger     *slog.Logger
+	// LoginGuard counts wrong passwords and codes towards the lockout, nil does not
+	LoginGuard *middleware.LoginGuard
+}
+
+func NewStepUpHandler(userStore database.UserStore, totpStore database.TOTPStore, auditStore database.AuditStore, guard *middleware.StepUpGuard, logger *slog.Logger) *StepUpHandler {
+	return &StepUpHandler{
+		UserStore:  userStore,
+		TOTPStore:  totpStore,
+		AuditStore: auditStore,
+		Guard:      guard,
+		Logger:     logger,
+	}
+}
+
+// StepUpRequest re-authenticates with the password or a code of the authenticator app
+type StepUpRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+func (h *StepUpHandler) audit(c *gin.Context, user *database.User, action string, details map[string]any) {
+	h.AuditStore.RecordEvent(&database.AuditEvent{
+		OrganizationID: user.OrganizationID,
+		ActorID:        &user.ID,
+		TargetUserID:   &user.ID,
+		Action:         action,
+		Details:        details,
+		IPAddress:      c.ClientIP(),
+	})
+}
+
+// StepUpHandler checks the password or code of the user and issues a token that unlocks the protected
+// routes until step_up_expires_at. The token keeps the organization and role of the current one.
+func (h *StepUpHandler) StepUpHandler(tokens TokenGenerator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := middleware.ValidateOrgAccess(c)
+		if user == nil {
+			return
+		}
+
+		var req StepUpRequest
+		if err := c.ShouldBindJSON(&req); err != nil || (req.Password == "") == (req.Code == "") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Provide either your password or an authenticator code"})
+			return
+		}
+
+		fullUser, err := h.UserStore.GetUserByID(user.ID)
+		if err != nil {
+			h.Logger.Error("failed to get user", "error", err, "user_id", user.ID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify user"})
+			return
+		}
+		if h.LoginGuard != nil {
+			if err := h.LoginGuard.CheckLocked(fullUser); err != nil {
+				c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		method := "password"
+		var verified bool
+		if req.Code != "" {
+			method = "totp"
+			totp, err := h.TOTPStore.GetTOTP(user.ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+				return
+			}
+			if totp == nil || !totp.Enabled() {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "No authenticator app is set up, use your password"})
+				return
+			}
+			if counter, ok := service.VerifyTOTP(totp.Secret, req.Code, time.Now(), totp.LastUsedCounter); ok {
+				if verified, err = h.TOTPStore.UseTOTPCounter(user.ID, counter); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+					return
+				}
+			}
+		} else {
+			match, err := fullUser.PasswordHash.Matches(req.Password)
+			verified = err == nil && match
+		}
+
+		if !verified {
+			h.Logger.Warn("step-up failed", "user_id", user.ID, "method", method)
+			if h.LoginGuard != nil {
+				h.LoginGuard.LoginFailed(c, fullUser)
+			}
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Incorrect password or code"})
+			return
+		}
+
+		now := time.Now()
+		token, err := tokens.TokenGenerator(c.Request.Context(), &middleware.StepUp{User: user, At: now})
+		if err != nil {
+			h.Logger.Error("failed to generate token", "error", err, "user_id", user.ID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm your identity"})
+			return
+		}
+
+		h.audit(c, user, database.AuditStepUp, map[string]any{"method": method})
+		h.Logger.Info("stepped up", "user_id", user.ID, "method", method)
+		c.JSON(http.StatusOK, gin.H{
+			"access_token":       token.AccessToken,
+			"refresh_token":      token.RefreshToken,
+			"expires_at":         token.ExpiresAt,
+			"step_up_expires_at": h.Guard.ExpiresAt(now),
+		})
+	}
+}
+
+// GetTOTPHandler tells whether the user has an authenticator app, data is null when they have none
+func (h *StepUpHandler) GetTOTPHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	totp, err := h.TOTPStore.GetTOTP(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve authenticator app"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Authenticator app retrieved successfully", "data": totp})
+}
+
+// SetupTOTPHandler creates a secret for the authenticator app of the user, confirmed with a first code.
+// Replacing an app that is already enabled needs a recent step-up.
+func (h *StepUpHandler) SetupTOTPHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	existing, err := h.TOTPStore.GetTOTP(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set up authenticator app"})
+		return
+	}
+	if existing != nil && existing.Enabled() && !h.Guard.Recent(c) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":            "Confirm your password or authenticator code with /auth/step-up to replace your authenticator app",
+			"step_up_required": true,
+		})
+		return
+	}
+
+	secret, err := service.GenerateTOTPSecret()
+	if err != nil {
+		h.Logger.Error("failed to generate totp secret", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set up authenticator app"})
+		return
+	}
+	if err := h.TOTPStore.SaveTOTPSecret(user.ID, secret); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set up authenticator app"})
+		return
+	}
+
+	h.Logger.Info("totp setup started", "user_id", user.ID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Scan the code with your authenticator app and confirm it with a first code",
+		"data": gin.H{
+			"secret":      secret,
+			"otpauth_url": service.TOTPURI(secret, totpIssuer, user.Email),
+		},
+	})
+}
+
+// EnableTOTPHandler confirms the pending authenticator app of the user with a first code
+func (h *StepUpHandler) EnableTOTPHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	var req TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	totp, err := h.TOTPStore.GetTOTP(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable authenticator app"})
+		return
+	}
+	if totp == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No authenticator app to confirm, set one up first"})
+		return
+	}
+	if totp.Enabled() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Authenticator app is already enabled"})
+		return
+	}
+
+	counter, ok := service.VerifyTOTP(totp.Secret, req.Code, time.Now(), totp.LastUsedCounter)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Incorrect code"})
+		return
+	}
+	if err := h.TOTPStore.EnableTOTP(user.ID, counter); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Authenticator app is already enabled"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable authenticator app"})
+		return
+	}
+
+	h.audit(c, user, database.AuditTOTPEnabled, nil)
+	h.Logger.Info("totp enabled", "user_id", user.ID)
+	c.JSON(http.StatusOK, gin.H{"message": "Authenticator app enabled successfully"})
+}
+
+// DeleteTOTPHandler removes the authenticator app of the user, protected by the StepUpGuard by default
+func (h *StepUpHandler) DeleteTOTPHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if err := h.TOTPStore.DeleteTOTP(user.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No authenticator app is set up"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove authenticator app"})
+		return
+	}
+
+	h.audit(c, user, database.AuditTOTPDisabled, nil)
+	h.Logger.Info("totp removed", "user_id", user.ID)
+	c.JSON(http.StatusOK, gin.H{"message": "Authenticator app removed successfully"})
+}
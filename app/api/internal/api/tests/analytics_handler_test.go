@@ -0,0 +1,180 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type AnalyticsTestEnv struct {
+	Store      *MockStaffingAnalyticsStore
+	RulesStore *MockRulesStore
+	Handler    *api.AnalyticsHandler
+}
+
+func setupAnalyticsEnv() *AnalyticsTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	store := new(MockStaffingAnalyticsStore)
+	rulesStore := new(MockRulesStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &AnalyticsTestEnv{
+		Store:      store,
+		RulesStore: rulesStore,
+		Handler:    api.NewAnalyticsHandler(store, rulesStore, logger),
+	}
+}
+
+func (env *AnalyticsTestEnv) ResetMocks() {
+	env.Store.ExpectedCalls = nil
+	env.Store.Calls = nil
+	env.RulesStore.ExpectedCalls = nil
+	env.RulesStore.Calls = nil
+}
+
+func TestGetStaffingVsDemandHandler(t *testing.T) {
+	env := setupAnalyticsEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	route := "/:org/analytics/staffing-vs-demand"
+	path := "/" + orgID.String() + "/analytics/staffing-vs-demand"
+	date := time.Date(2026, 10, 14, 0, 0, 0, 0, time.Local)
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(&database.OrganizationRules{SlotLenHour: 0.5}, nil).Once()
+		env.Store.On("GetStaffingVsDemand", orgID, date, 30).Return(&database.StaffingVsDemand{
+			Date:            date,
+			SlotMinutes:     30,
+			Slots:           []string{"12:00", "12:30"},
+			PredictedOrders: []float64{6, 6},
+			ActualOrders:    []int{4, 9},
+			ScheduledStaff:  []int{2, 3},
+		}, nil).Once()
+
+		w := jobRequest("GET", route, path+"?date=2026-10-14", []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetStaffingVsDemandHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data database.StaffingVsDemand `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, []string{"12:00", "12:30"}, resp.Data.Slots)
+		assert.Equal(t, []int{4, 9}, resp.Data.ActualOrders)
+		env.Store.AssertExpectations(t)
+	})
+
+	t.Run("HourlySlotsByDefault", func(t *testing.T) {
+		env.ResetMocks()
+		// 42 minutes do not divide the day
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(&database.OrganizationRules{SlotLenHour: 0.7}, nil).Once()
+		env.Store.On("GetStaffingVsDemand", orgID, mock.Anything, 60).Return(&database.StaffingVsDemand{SlotMinutes: 60}, nil).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetStaffingVsDemandHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		now := time.Now()
+		assert.Equal(t, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local), env.Store.Calls[0].Arguments.Get(1))
+	})
+
+	t.Run("InvalidDate", func(t *testing.T) {
+		env.ResetMocks()
+		w := jobRequest("GET", route, path+"?date=14-10-2026", []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetStaffingVsDemandHandler}, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetStaffingVsDemandHandler}, nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(nil, errors.New("db error")).Once()
+		env.Store.On("GetStaffingVsDemand", orgID, date, 60).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path+"?date=2026-10-14", []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetStaffingVsDemandHandler}, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestGetTurnoverHandler(t *testing.T) {
+	env := setupAnalyticsEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	route := "/:org/staffing/turnover"
+	path := "/" + orgID.String() + "/staffing/turnover"
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		rate := 12.5
+		env.Store.On("GetTurnover", orgID, mock.Anything, 2).Return([]database.TurnoverQuarter{
+			{Quarter: "2026-Q3", HeadcountStart: 8, HeadcountEnd: 8, VoluntaryExits: 1, TurnoverRate: &rate},
+			{Quarter: "2026-Q4", HeadcountStart: 8, HeadcountEnd: 9, Hires: 1},
+		}, nil).Once()
+
+		w := jobRequest("GET", route, path+"?quarters=2", []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetTurnoverHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data []database.TurnoverQuarter `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Len(t, resp.Data, 2)
+		assert.Equal(t, 12.5, *resp.Data[0].TurnoverRate)
+		// The quarters end with the current one
+		now := time.Now()
+		from := env.Store.Calls[0].Arguments.Get(1).(time.Time)
+		assert.Equal(t, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, -3, 0), from)
+	})
+
+	t.Run("FourQuartersByDefault", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("GetTurnover", orgID, mock.Anything, 4).Return([]database.TurnoverQuarter{}, nil).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetTurnoverHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.Store.AssertExpectations(t)
+	})
+
+	t.Run("InvalidQuarters", func(t *testing.T) {
+		env.ResetMocks()
+		for _, quarters := range []string{"0", "21", "two"} {
+			w := jobRequest("GET", route, path+"?quarters="+quarters, []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetTurnoverHandler}, nil)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		}
+		env.Store.AssertNotCalled(t, "GetTurnover", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetTurnoverHandler}, nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("GetTurnover", orgID, mock.Anything, 4).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetTurnoverHandler}, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
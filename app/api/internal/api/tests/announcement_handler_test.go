@@ -0,0 +1,358 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type AnnouncementTestEnv struct {
+	Router            *gin.Engine
+	AnnouncementStore *MockAnnouncementStore
+	UserStore         *MockUserStore
+	UserRolesStore    *MockUserRolesStore
+	RolesStore        *MockRolesStore
+	EmailService      *MockEmailService
+	Handler           *api.AnnouncementHandler
+}
+
+func setupAnnouncementEnv() *AnnouncementTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	announcementStore := new(MockAnnouncementStore)
+	userStore := new(MockUserStore)
+	userRolesStore := new(MockUserRolesStore)
+	rolesStore := new(MockRolesStore)
+	emailService := new(MockEmailService)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	handler := api.NewAnnouncementHandler(announcementStore, userStore, userRolesStore, rolesStore, emailService, logger)
+
+	return &AnnouncementTestEnv{
+		Router:            gin.New(),
+		AnnouncementStore: announcementStore,
+		UserStore:         userStore,
+		UserRolesStore:    userRolesStore,
+		RolesStore:        rolesStore,
+		EmailService:      emailService,
+		Handler:           handler,
+	}
+}
+
+func (env *AnnouncementTestEnv) ResetMocks() {
+	env.AnnouncementStore.ExpectedCalls = nil
+	env.AnnouncementStore.Calls = nil
+	env.UserStore.ExpectedCalls = nil
+	env.UserStore.Calls = nil
+	env.UserRolesStore.ExpectedCalls = nil
+	env.UserRolesStore.Calls = nil
+	env.RolesStore.ExpectedCalls = nil
+	env.RolesStore.Calls = nil
+	env.EmailService.ExpectedCalls = nil
+	env.EmailService.Calls = nil
+}
+
+func TestCreateAnnouncementHandler(t *testing.T) {
+	env := setupAnnouncementEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), FullName: "Maya Manager", OrganizationID: orgID, UserRole: "manager"}
+
+	env.Router.POST("/:org/announcements", authMiddleware(manager), env.Handler.CreateAnnouncementHandler)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/announcements", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.AnnouncementStore.On("CreateAnnouncement", mock.MatchedBy(func(a *database.Announcement) bool {
+			return a.OrganizationID == orgID && *a.AuthorID == manager.ID && a.Title == "Menu change" && a.Pinned
+		})).Return(nil).Once()
+
+		w := post(`{"title":"Menu change","message":"New menu starts Monday","pinned":true}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), "Announcement posted successfully")
+		assert.Contains(t, w.Body.String(), `"notified":0`)
+		env.AnnouncementStore.AssertExpectations(t)
+		env.EmailService.AssertNotCalled(t, "SendAnnouncementEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success_EmailFanOutToTargetedRoles", func(t *testing.T) {
+		env.ResetMocks()
+		cook := &database.User{ID: uuid.New(), Email: "cook@example.com", OrganizationID: orgID, UserRole: "employee"}
+		server := &database.User{ID: uuid.New(), Email: "server@example.com", OrganizationID: orgID, UserRole: "employee"}
+
+		env.RolesStore.On("GetRoleByName", orgID, "cook").Return(&database.OrganizationRole{Role: "cook"}, nil).Once()
+		env.AnnouncementStore.On("CreateAnnouncement", mock.Anything).Return(nil).Once()
+		env.UserStore.On("GetUsersByOrganization", orgID).Return([]*database.User{manager, cook, server}, nil).Once()
+		env.UserRolesStore.On("GetUserRoles", cook.ID, orgID).Return([]string{"employee", "cook"}, nil).Once()
+		env.UserRolesStore.On("GetUserRoles", server.ID, orgID).Return([]string{"employee", "server"}, nil).Once()
+		env.EmailService.On("SendAnnouncementEmail", []string{"cook@example.com"}, "Maya Manager", "Kitchen meeting", "Friday 9am").Return(nil).Once()
+
+		w := post(`{"title":"Kitchen meeting","message":"Friday 9am","target_roles":["cook"],"notify":["email"]}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"notified":1`)
+		env.EmailService.AssertExpectations(t)
+		env.UserRolesStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_UnknownTargetRole", func(t *testing.T) {
+		env.ResetMocks()
+		env.RolesStore.On("GetRoleByName", orgID, "astronaut").Return(nil, nil).Once()
+
+		w := post(`{"title":"Hi","message":"Hello","target_roles":["astronaut"]}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Unknown target role")
+		env.AnnouncementStore.AssertNotCalled(t, "CreateAnnouncement", mock.Anything)
+	})
+
+	t.Run("Failure_ExpiredAlready", func(t *testing.T) {
+		env.ResetMocks()
+		past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+		w := post(`{"title":"Hi","message":"Hello","expires_at":"` + past + `"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "expires_at")
+	})
+
+	t.Run("Failure_UnsupportedChannel", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := post(`{"title":"Hi","message":"Hello","notify":["pigeon"]}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+		router := gin.New()
+		router.POST("/:org/announcements", authMiddleware(employee), env.Handler.CreateAnnouncementHandler)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/announcements", strings.NewReader(`{"title":"Hi","message":"Hello"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Failure_DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.AnnouncementStore.On("CreateAnnouncement", mock.Anything).Return(errors.New("db error")).Once()
+
+		w := post(`{"title":"Hi","message":"Hello"}`)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestGetMyAnnouncementsHandler(t *testing.T) {
+	env := setupAnnouncementEnv()
+	orgID := uuid.New()
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+	env.Router.GET("/:org/me/announcements", authMiddleware(employee), env.Handler.GetMyAnnouncementsHandler)
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		readAt := time.Now()
+		announcements := []database.Announcement{
+			{ID: uuid.New(), Title: "Pinned", Pinned: true, TargetRoles: []string{}},
+			{ID: uuid.New(), Title: "Read already", TargetRoles: []string{"cook"}, ReadAt: &readAt},
+		}
+		env.UserRolesStore.On("GetUserRoles", employee.ID, orgID).Return([]string{"cook"}, nil).Once()
+		env.AnnouncementStore.On("GetActiveAnnouncementsForUser", orgID, employee.ID, []string{"cook", "employee"}).Return(announcements, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/me/announcements", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data   []database.Announcement `json:"data"`
+			Unread int                     `json:"unread"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Len(t, resp.Data, 2)
+		assert.Equal(t, 1, resp.Unread)
+		env.AnnouncementStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserRolesStore.On("GetUserRoles", employee.ID, orgID).Return([]string{}, nil).Once()
+		env.AnnouncementStore.On("GetActiveAnnouncementsForUser", orgID, employee.ID, []string{"employee"}).Return(nil, errors.New("db error")).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/me/announcements", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestMarkAnnouncementReadHandler(t *testing.T) {
+	env := setupAnnouncementEnv()
+	orgID := uuid.New()
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	announcementID := uuid.New()
+
+	env.Router.POST("/:org/me/announcements/:id/read", authMiddleware(employee), env.Handler.MarkAnnouncementReadHandler)
+
+	read := func(id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/me/announcements/"+id+"/read", nil)
+		env.Router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.AnnouncementStore.On("GetAnnouncementByID", orgID, announcementID).Return(&database.Announcement{ID: announcementID}, nil).Once()
+		env.UserRolesStore.On("GetUserRoles", employee.ID, orgID).Return([]string{"employee"}, nil).Once()
+		env.AnnouncementStore.On("MarkAnnouncementRead", announcementID, employee.ID).Return(nil).Once()
+
+		w := read(announcementID.String())
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.AnnouncementStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_NotTargeted", func(t *testing.T) {
+		env.ResetMocks()
+		announcement := &database.Announcement{ID: announcementID, TargetRoles: []string{"manager"}}
+		env.AnnouncementStore.On("GetAnnouncementByID", orgID, announcementID).Return(announcement, nil).Once()
+		env.UserRolesStore.On("GetUserRoles", employee.ID, orgID).Return([]string{"employee"}, nil).Once()
+
+		w := read(announcementID.String())
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		env.AnnouncementStore.AssertNotCalled(t, "MarkAnnouncementRead", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failure_NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.AnnouncementStore.On("GetAnnouncementByID", orgID, announcementID).Return(nil, sql.ErrNoRows).Once()
+
+		w := read(announcementID.String())
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Failure_InvalidID", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := read("not-a-uuid")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestManageAnnouncementsHandlers(t *testing.T) {
+	env := setupAnnouncementEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	announcementID := uuid.New()
+
+	env.Router.GET("/:org/announcements", authMiddleware(admin), env.Handler.GetAnnouncementsHandler)
+	env.Router.DELETE("/:org/announcements/:id", authMiddleware(admin), env.Handler.DeleteAnnouncementHandler)
+	env.Router.GET("/:org/announcements/:id/reads", authMiddleware(admin), env.Handler.GetAnnouncementReadsHandler)
+
+	t.Run("List_Success", func(t *testing.T) {
+		env.ResetMocks()
+		readCount := 3
+		env.AnnouncementStore.On("GetAnnouncementsForOrganization", orgID).
+			Return([]database.Announcement{{ID: announcementID, Title: "Menu change", ReadCount: &readCount}}, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/announcements", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"read_count":3`)
+	})
+
+	t.Run("Reads_Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.AnnouncementStore.On("GetAnnouncementByID", orgID, announcementID).Return(&database.Announcement{ID: announcementID}, nil).Once()
+		env.AnnouncementStore.On("GetAnnouncementReads", announcementID).
+			Return([]database.AnnouncementRead{{UserID: uuid.New(), FullName: "Sam", ReadAt: time.Now()}}, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/announcements/"+announcementID.String()+"/reads", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Sam")
+		assert.Contains(t, w.Body.String(), `"total":1`)
+	})
+
+	t.Run("Reads_NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.AnnouncementStore.On("GetAnnouncementByID", orgID, announcementID).Return(nil, sql.ErrNoRows).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/announcements/"+announcementID.String()+"/reads", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Delete_Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.AnnouncementStore.On("DeleteAnnouncement", orgID, announcementID).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/"+orgID.String()+"/announcements/"+announcementID.String(), nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Delete_NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.AnnouncementStore.On("DeleteAnnouncement", orgID, announcementID).Return(sql.ErrNoRows).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/"+orgID.String()+"/announcements/"+announcementID.String(), nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("List_EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+		router := gin.New()
+		router.GET("/:org/announcements", authMiddleware(employee), env.Handler.GetAnnouncementsHandler)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/announcements", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
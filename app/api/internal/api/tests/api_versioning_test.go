@@ -0,0 +1,131 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// renameShim moves the v2 "items" list back to the v1 "data" key
+func renameShim(body any) any {
+	response := body.(map[string]any)
+	response["data"] = response["items"]
+	delete(response, "items")
+	return response
+}
+
+func setupVersionedRouter(version middleware.APIVersion) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	group := router.Group(version.Prefix, middleware.Versioned(version))
+	group.GET("/:org/things", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "Things retrieved", "items": []string{"a"}, "version": middleware.RequestAPIVersion(c)})
+	})
+	group.GET("/:org/other", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"items": []string{"b"}})
+	})
+	group.GET("/:org/broken", func(c *gin.Context) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid things"})
+	})
+	group.GET("/:org/export", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/csv", []byte("name\na\n"))
+	})
+	return router
+}
+
+func versionedRequest(router *gin.Engine, path string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", path, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestVersioned(t *testing.T) {
+	shims := map[string]middleware.ResponseShim{
+		"GET /:org/things": renameShim,
+		"GET /:org/broken": renameShim,
+		"GET /:org/export": renameShim,
+	}
+
+	t.Run("CurrentVersion", func(t *testing.T) {
+		router := setupVersionedRouter(middleware.APIVersion{Name: "v2", Prefix: "/api/v2"})
+
+		w := versionedRequest(router, "/api/v2/acme/things")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"message":"Things retrieved","items":["a"],"version":"v2"}`, w.Body.String())
+		assert.Empty(t, w.Header().Get("Deprecation"))
+		assert.Empty(t, w.Header().Get("Sunset"))
+		assert.Empty(t, w.Header().Get("Link"))
+	})
+
+	t.Run("DeprecatedVersion", func(t *testing.T) {
+		router := setupVersionedRouter(middleware.APIVersion{
+			Name:       "v1",
+			Prefix:     "/api",
+			Successor:  "/api/v1",
+			Deprecated: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+			Sunset:     time.Date(2027, 4, 16, 0, 0, 0, 0, time.UTC),
+		})
+
+		w := versionedRequest(router, "/api/acme/things?page=2")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "@1792108800", w.Header().Get("Deprecation"))
+		assert.Equal(t, "Fri, 16 Apr 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+		assert.Equal(t, `</api/v1/acme/things>; rel="successor-version"`, w.Header().Get("Link"))
+		assert.Contains(t, w.Body.String(), `"version":"v1"`)
+	})
+
+	t.Run("SunsetWithoutDeprecation", func(t *testing.T) {
+		router := setupVersionedRouter(middleware.APIVersion{Name: "v1", Prefix: "/api/v1", Sunset: time.Date(2027, 4, 16, 0, 0, 0, 0, time.UTC)})
+
+		w := versionedRequest(router, "/api/v1/acme/things")
+
+		assert.Empty(t, w.Header().Get("Deprecation"))
+		assert.Empty(t, w.Header().Get("Link"))
+		assert.NotEmpty(t, w.Header().Get("Sunset"))
+	})
+
+	t.Run("ShimRewritesRoute", func(t *testing.T) {
+		router := setupVersionedRouter(middleware.APIVersion{Name: "v1", Prefix: "/api/v1", Shims: shims})
+
+		w := versionedRequest(router, "/api/v1/acme/things")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"message":"Things retrieved","data":["a"],"version":"v1"}`, w.Body.String())
+	})
+
+	t.Run("OtherRoutesUntouched", func(t *testing.T) {
+		router := setupVersionedRouter(middleware.APIVersion{Name: "v1", Prefix: "/api/v1", Shims: shims})
+
+		w := versionedRequest(router, "/api/v1/acme/other")
+
+		assert.JSONEq(t, `{"items":["b"]}`, w.Body.String())
+	})
+
+	t.Run("ErrorsUntouched", func(t *testing.T) {
+		router := setupVersionedRouter(middleware.APIVersion{Name: "v1", Prefix: "/api/v1", Shims: shims})
+
+		w := versionedRequest(router, "/api/v1/acme/broken")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"Invalid things"}`, w.Body.String())
+	})
+
+	t.Run("NonJSONUntouched", func(t *testing.T) {
+		router := setupVersionedRouter(middleware.APIVersion{Name: "v1", Prefix: "/api/v1", Shims: shims})
+
+		w := versionedRequest(router, "/api/v1/acme/export")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+		assert.Equal(t, "name\na\n", w.Body.String())
+	})
+}
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type ApplicantSessionTestEnv struct {
+	JobPostingStore *MockJobPostingStore
+	SessionStore    *MockApplicantSessionStore
+	UserStore       *MockUserStore
+	EmailService    *MockEmailService
+	Handler         *api.ApplicantSessionHandler
+}
+
+func setupApplicantSessionEnv() *ApplicantSessionTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	jobPostingStore := new(MockJobPostingStore)
+	sessionStore := new(MockApplicantSessionStore)
+	userStore := new(MockUserStore)
+	emailService := new(MockEmailService)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &ApplicantSessionTestEnv{
+		JobPostingStore: jobPostingStore,
+		SessionStore:    sessionStore,
+		UserStore:       userStore,
+		EmailService:    emailService,
+		Handler:         api.NewApplicantSessionHandler(jobPostingStore, sessionStore, userStore, emailService, logger),
+	}
+}
+
+func (env *ApplicantSessionTestEnv) ResetMocks() {
+	env.JobPostingStore.ExpectedCalls = nil
+	env.JobPostingStore.Calls = nil
+	env.SessionStore.ExpectedCalls = nil
+	env.SessionStore.Calls = nil
+	env.UserStore.ExpectedCalls = nil
+	env.UserStore.Calls = nil
+	env.EmailService.ExpectedCalls = nil
+	env.EmailService.Calls = nil
+}
+
+func TestScheduleApplicantSessionHandler(t *testing.T) {
+	env := setupApplicantSessionEnv()
+	orgID := uuid.New()
+	postingID := uuid.New()
+	applicantID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager", FullName: "Alex Manager", Email: "alex@example.com"}
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	route := "/:org/applicants/:id/sessions"
+	path := "/" + orgID.String() + "/applicants/" + applicantID.String() + "/sessions"
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	day := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 0, 0, 0, 0, time.UTC)
+	body := map[string]any{"kind": "interview", "date": day.Format(time.DateOnly), "start_time": "10:00", "end_time": "10:45", "location": "Back office"}
+
+	newApplicant := func(stage string) *database.JobApplicant {
+		return &database.JobApplicant{ID: applicantID, PostingID: postingID, FullName: "Jamie Doe", Email: "jamie@example.com", Stage: stage}
+	}
+	posting := &database.JobPosting{ID: postingID, OrganizationID: orgID, Role: "server", Title: "Server"}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByID", manager.ID).Return(manager, nil).Once()
+		env.JobPostingStore.On("GetApplicantByID", orgID, applicantID).Return(newApplicant("screening"), nil).Once()
+		env.JobPostingStore.On("GetPostingByID", orgID, postingID).Return(posting, nil).Once()
+		env.SessionStore.On("GetManagerConflicts", orgID, manager.ID, day, "10:00", "10:45").Return([]database.ManagerConflict{}, nil).Once()
+		env.SessionStore.On("CreateSession", orgID, mock.MatchedBy(func(s *database.ApplicantSession) bool {
+			return s.ApplicantID == applicantID && s.ManagerID == manager.ID && s.Kind == "interview" && s.Date.Equal(day)
+		})).Return(nil).Once()
+		env.JobPostingStore.On("SetApplicantStage", orgID, applicantID, "interview").Return(nil).Once()
+		start, end := day.Add(10*time.Hour), day.Add(10*time.Hour+45*time.Minute)
+		env.EmailService.On("SendCalendarInviteEmail", "jamie@example.com", "Jamie Doe", "Interview for Server", "Back office", start, end, mock.Anything).Return(nil).Once()
+		env.EmailService.On("SendCalendarInviteEmail", "alex@example.com", "Alex Manager", "Interview with Jamie Doe (Server)", "Back office", start, end, mock.Anything).Return(nil).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.ScheduleApplicantSessionHandler}, body)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"applicant_stage":"interview"`)
+		assert.Contains(t, w.Body.String(), `"invites_sent":2`)
+		env.SessionStore.AssertExpectations(t)
+		env.JobPostingStore.AssertExpectations(t)
+		env.EmailService.AssertExpectations(t)
+	})
+
+	t.Run("KeepsLaterStage", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByID", manager.ID).Return(manager, nil).Once()
+		env.JobPostingStore.On("GetApplicantByID", orgID, applicantID).Return(newApplicant("trial"), nil).Once()
+		env.JobPostingStore.On("GetPostingByID", orgID, postingID).Return(posting, nil).Once()
+		env.SessionStore.On("GetManagerConflicts", orgID, manager.ID, day, "10:00", "10:45").Return([]database.ManagerConflict{}, nil).Once()
+		env.SessionStore.On("CreateSession", orgID, mock.Anything).Return(nil).Once()
+		env.EmailService.On("SendCalendarInviteEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("smtp down"))
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.ScheduleApplicantSessionHandler}, body)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"applicant_stage":"trial"`)
+		assert.Contains(t, w.Body.String(), `"invites_sent":0`)
+		env.JobPostingStore.AssertNotCalled(t, "SetApplicantStage", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ManagerConflict", func(t *testing.T) {
+		env.ResetMocks()
+		conflicts := []database.ManagerConflict{{Kind: "shift", Date: day, StartTime: "09:00:00", EndTime: "13:00:00"}}
+		env.UserStore.On("GetUserByID", manager.ID).Return(manager, nil).Once()
+		env.JobPostingStore.On("GetApplicantByID", orgID, applicantID).Return(newApplicant("applied"), nil).Once()
+		env.JobPostingStore.On("GetPostingByID", orgID, postingID).Return(posting, nil).Once()
+		env.SessionStore.On("GetManagerConflicts", orgID, manager.ID, day, "10:00", "10:45").Return(conflicts, nil).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.ScheduleApplicantSessionHandler}, body)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "Manager is not available")
+		assert.Contains(t, w.Body.String(), `"kind":"shift"`)
+		env.SessionStore.AssertNotCalled(t, "CreateSession", mock.Anything, mock.Anything)
+	})
+
+	t.Run("AdminMustPickManager", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.ScheduleApplicantSessionHandler}, body)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "manager_id is required")
+	})
+
+	t.Run("HostMustBeManager", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+		env.UserStore.On("GetUserByID", employee.ID).Return(employee, nil).Once()
+		withManager := map[string]any{"kind": "trial", "manager_id": employee.ID, "date": day.Format(time.DateOnly), "start_time": "10:00", "end_time": "14:00"}
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.ScheduleApplicantSessionHandler}, withManager)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "managers or admins")
+	})
+
+	t.Run("InvalidSlot", func(t *testing.T) {
+		env.ResetMocks()
+		cases := []map[string]any{
+			{"kind": "interview", "date": day.Format(time.DateOnly), "start_time": "11:00", "end_time": "10:00"},
+			{"kind": "interview", "date": "2020-01-01", "start_time": "10:00", "end_time": "11:00"},
+			{"kind": "interview", "date": day.Format(time.DateOnly), "start_time": "10am", "end_time": "11:00"},
+			{"kind": "lunch", "date": day.Format(time.DateOnly), "start_time": "10:00", "end_time": "11:00"},
+		}
+		for _, invalid := range cases {
+			w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.ScheduleApplicantSessionHandler}, invalid)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("ApplicantLeftPipeline", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByID", manager.ID).Return(manager, nil).Once()
+		env.JobPostingStore.On("GetApplicantByID", orgID, applicantID).Return(newApplicant("rejected"), nil).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.ScheduleApplicantSessionHandler}, body)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("ApplicantNotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByID", manager.ID).Return(manager, nil).Once()
+		env.JobPostingStore.On("GetApplicantByID", orgID, applicantID).Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.ScheduleApplicantSessionHandler}, body)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.ScheduleApplicantSessionHandler}, body)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestGetApplicantSessionsHandler(t *testing.T) {
+	env := setupApplicantSessionEnv()
+	orgID := uuid.New()
+	applicantID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/applicants/:id/sessions"
+	path := "/" + orgID.String() + "/applicants/" + applicantID.String() + "/sessions"
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		sessions := []database.ApplicantSession{{ID: uuid.New(), ApplicantID: applicantID, Kind: "trial", StartTime: "10:00:00", EndTime: "14:00:00"}}
+		env.SessionStore.On("GetSessionsForApplicant", orgID, applicantID).Return(sessions, nil).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetApplicantSessionsHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"kind":"trial"`)
+		env.SessionStore.AssertExpectations(t)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.SessionStore.On("GetSessionsForApplicant", orgID, applicantID).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetApplicantSessionsHandler}, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
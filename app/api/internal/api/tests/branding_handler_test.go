@@ -0,0 +1,311 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type BrandingTestEnv struct {
+	OrgStore      *MockOrgStore
+	BrandingStore *MockBrandingStore
+	Handler       *api.BrandingHandler
+}
+
+func setupBrandingEnv(t *testing.T) *BrandingTestEnv {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("PUBLIC_API_URL", "https://api.example.com")
+
+	orgStore := new(MockOrgStore)
+	brandingStore := new(MockBrandingStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &BrandingTestEnv{
+		OrgStore:      orgStore,
+		BrandingStore: brandingStore,
+		Handler:       api.NewBrandingHandler(orgStore, brandingStore, logger),
+	}
+}
+
+func (env *BrandingTestEnv) ResetMocks() {
+	env.OrgStore.ExpectedCalls = nil
+	env.OrgStore.Calls = nil
+	env.BrandingStore.ExpectedCalls = nil
+	env.BrandingStore.Calls = nil
+}
+
+// testLogoPNG encodes a plain PNG image of the given size
+func testLogoPNG(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: 191, G: 65, B: 36, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func brandingForm(fields map[string]string, logo []byte) (*bytes.Buffer, string) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	for key, value := range fields {
+		writer.WriteField(key, value)
+	}
+	if logo != nil {
+		part, _ := writer.CreateFormFile("logo", "logo.png")
+		part.Write(logo)
+	}
+	writer.Close()
+	return body, writer.FormDataContentType()
+}
+
+func TestGetBrandingHandler(t *testing.T) {
+	env := setupBrandingEnv(t)
+	orgID := uuid.New()
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	route := "/:org/branding"
+	path := "/" + orgID.String() + "/branding"
+	handlers := []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetBrandingHandler}
+	org := &database.Organization{ID: orgID, Name: "Burger Barn", HexCode1: "BF4124", HexCode2: "010440", HexCode3: "F2DFDF"}
+
+	t.Run("WithLogo", func(t *testing.T) {
+		env.ResetMocks()
+		updatedAt := time.Unix(1760000000, 0)
+		env.OrgStore.On("GetOrganizationByID", orgID).Return(org, nil).Once()
+		env.BrandingStore.On("GetLogoUpdatedAt", orgID).Return(&updatedAt, nil).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"hex1":"BF4124"`)
+		assert.Contains(t, w.Body.String(), `"logo_url":"https://api.example.com/api/branding/`+orgID.String()+`/logo?v=1760000000"`)
+	})
+
+	t.Run("WithoutLogo", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrgStore.On("GetOrganizationByID", orgID).Return(org, nil).Once()
+		env.BrandingStore.On("GetLogoUpdatedAt", orgID).Return(nil, nil).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"logo_url":null`)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrgStore.On("GetOrganizationByID", orgID).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestUpdateBrandingHandler(t *testing.T) {
+	env := setupBrandingEnv(t)
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	org := &database.Organization{ID: orgID, Name: "Burger Barn", HexCode1: "BF4124", HexCode2: "010440", HexCode3: "F2DFDF"}
+
+	post := func(user *database.User, body *bytes.Buffer, contentType string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.POST("/:org/branding", authMiddleware(user), env.Handler.UpdateBrandingHandler)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/branding", body)
+		req.Header.Set("Content-Type", contentType)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("LogoAndColor", func(t *testing.T) {
+		env.ResetMocks()
+		logo := testLogoPNG(t, 64, 64)
+		updatedAt := time.Now()
+		env.OrgStore.On("GetOrganizationByID", orgID).Return(org, nil)
+		env.OrgStore.On("SetBrandColors", orgID, "1A2B3C", "010440", "F2DFDF").Return(nil).Once()
+		env.BrandingStore.On("SetLogo", orgID, "image/png", logo).Return(nil).Once()
+		env.BrandingStore.On("GetLogoUpdatedAt", orgID).Return(&updatedAt, nil).Once()
+
+		body, contentType := brandingForm(map[string]string{"hex1": "#1a2b3c"}, logo)
+		w := post(admin, body, contentType)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.OrgStore.AssertExpectations(t)
+		env.BrandingStore.AssertExpectations(t)
+	})
+
+	t.Run("ColorsOnly", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrgStore.On("GetOrganizationByID", orgID).Return(org, nil)
+		env.OrgStore.On("SetBrandColors", orgID, "BF4124", "000000", "FFFFFF").Return(nil).Once()
+		env.BrandingStore.On("GetLogoUpdatedAt", orgID).Return(nil, nil).Once()
+
+		body, contentType := brandingForm(map[string]string{"hex2": "000000", "hex3": "ffffff"}, nil)
+		w := post(admin, body, contentType)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.BrandingStore.AssertNotCalled(t, "SetLogo", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("InvalidColor", func(t *testing.T) {
+		env.ResetMocks()
+
+		body, contentType := brandingForm(map[string]string{"hex1": "red"}, nil)
+		w := post(admin, body, contentType)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		env.OrgStore.AssertNotCalled(t, "SetBrandColors", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("NotAnImage", func(t *testing.T) {
+		env.ResetMocks()
+
+		body, contentType := brandingForm(nil, []byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"))
+		w := post(admin, body, contentType)
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		env.BrandingStore.AssertNotCalled(t, "SetLogo", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("LogoTooWide", func(t *testing.T) {
+		env.ResetMocks()
+
+		body, contentType := brandingForm(nil, testLogoPNG(t, 2048, 10))
+		w := post(admin, body, contentType)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("LogoTooLarge", func(t *testing.T) {
+		env.ResetMocks()
+
+		body, contentType := brandingForm(nil, append(testLogoPNG(t, 8, 8), make([]byte, 2<<20)...))
+		w := post(admin, body, contentType)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("NothingToUpdate", func(t *testing.T) {
+		env.ResetMocks()
+
+		body, contentType := brandingForm(nil, nil)
+		w := post(admin, body, contentType)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("NotMultipart", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := post(admin, bytes.NewBufferString(`{"hex1":"1A2B3C"}`), "application/json")
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+
+	t.Run("ManagerForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+
+		body, contentType := brandingForm(map[string]string{"hex1": "1A2B3C"}, nil)
+		w := post(manager, body, contentType)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestGetLogoHandler(t *testing.T) {
+	env := setupBrandingEnv(t)
+	orgID := uuid.New()
+	route := "/branding/:org/logo"
+	path := "/branding/" + orgID.String() + "/logo"
+	handlers := []gin.HandlerFunc{env.Handler.GetLogoHandler}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		logo := testLogoPNG(t, 4, 4)
+		env.BrandingStore.On("GetLogo", orgID).Return(&database.OrganizationLogo{ContentType: "image/png", Data: logo}, nil).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+		assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+		assert.Equal(t, logo, w.Body.Bytes())
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.BrandingStore.On("GetLogo", orgID).Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("InvalidOrg", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("GET", route, "/branding/abc/logo", handlers, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestEmailBrandingFor(t *testing.T) {
+	t.Setenv("PUBLIC_API_URL", "https://api.example.com")
+	userStore := new(MockUserStore)
+	orgStore := new(MockOrgStore)
+	brandingStore := new(MockBrandingStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	brandingFor := api.EmailBrandingFor(userStore, orgStore, brandingStore, logger)
+
+	orgID := uuid.New()
+	userStore.On("GetUserByEmail", "sam@example.com").Return(&database.User{ID: uuid.New(), OrganizationID: orgID}, nil)
+	userStore.On("GetUserByEmail", "guest@example.com").Return(nil, sql.ErrNoRows)
+	brandingStore.On("GetLogoUpdatedAt", orgID).Return(nil, nil)
+
+	t.Run("Member", func(t *testing.T) {
+		orgStore.ExpectedCalls = nil
+		orgStore.On("GetOrganizationByID", orgID).Return(&database.Organization{ID: orgID, Name: "Burger Barn", HexCode1: "BF4124", HexCode2: "010440"}, nil).Once()
+
+		branding := brandingFor("sam@example.com")
+		if assert.NotNil(t, branding) {
+			assert.Equal(t, "Burger Barn", branding.Name)
+			assert.Equal(t, "BF4124", branding.Hex1)
+			assert.Empty(t, branding.LogoURL)
+		}
+	})
+
+	t.Run("InvalidColors", func(t *testing.T) {
+		orgStore.ExpectedCalls = nil
+		orgStore.On("GetOrganizationByID", orgID).Return(&database.Organization{ID: orgID, Name: "Burger Barn", HexCode1: "red;}x", HexCode2: "010440"}, nil).Once()
+
+		assert.Nil(t, brandingFor("sam@example.com"))
+	})
+
+	t.Run("NotAUser", func(t *testing.T) {
+		assert.Nil(t, brandingFor("guest@example.com"))
+	})
+}
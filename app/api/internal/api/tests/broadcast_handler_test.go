@@ -0,0 +1,300 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type BroadcastTestEnv struct {
+	BroadcastStore   *MockBroadcastStore
+	UserStore        *MockUserStore
+	UserRolesStore   *MockUserRolesStore
+	RolesStore       *MockRolesStore
+	ScheduleStore    *MockScheduleStore
+	CustomFieldStore *MockCustomFieldStore
+	EmailService     *MockEmailService
+	SMSService       *MockSMSService
+	Handler          *api.BroadcastHandler
+}
+
+func setupBroadcastEnv() *BroadcastTestEnv {
+	gin.SetMode(gin.TestMode)
+	env := &BroadcastTestEnv{
+		BroadcastStore:   new(MockBroadcastStore),
+		UserStore:        new(MockUserStore),
+		UserRolesStore:   new(MockUserRolesStore),
+		RolesStore:       new(MockRolesStore),
+		ScheduleStore:    new(MockScheduleStore),
+		CustomFieldStore: new(MockCustomFieldStore),
+		EmailService:     new(MockEmailService),
+		SMSService:       new(MockSMSService),
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	env.Handler = api.NewBroadcastHandler(env.BroadcastStore, env.UserStore, env.UserRolesStore, env.RolesStore, env.ScheduleStore,
+		env.CustomFieldStore, env.EmailService, env.SMSService, logger)
+	return env
+}
+
+type broadcastMembers struct {
+	orgID uuid.UUID
+	admin *database.User
+	sam   *database.User
+	alex  *database.User
+	lee   *database.User
+}
+
+func newBroadcastMembers() broadcastMembers {
+	orgID := uuid.New()
+	phone := "+201000000000"
+	return broadcastMembers{
+		orgID: orgID,
+		admin: &database.User{ID: uuid.New(), OrganizationID: orgID, FullName: "Admin", Email: "admin@test.com", UserRole: "admin"},
+		sam:   &database.User{ID: uuid.New(), OrganizationID: orgID, FullName: "Sam", Email: "sam@test.com", UserRole: "employee", Phone: &phone},
+		alex:  &database.User{ID: uuid.New(), OrganizationID: orgID, FullName: "Alex", Email: "alex@test.com", UserRole: "manager"},
+		lee:   &database.User{ID: uuid.New(), OrganizationID: orgID, FullName: "Lee", Email: "lee@test.com", UserRole: "employee"},
+	}
+}
+
+func (m broadcastMembers) all() []*database.User {
+	return []*database.User{m.admin, m.sam, m.alex, m.lee}
+}
+
+func TestPreviewBroadcastHandler(t *testing.T) {
+	members := newBroadcastMembers()
+	orgID := members.orgID
+	route := "/:org/notifications/broadcast/preview"
+	path := "/" + orgID.String() + "/notifications/broadcast/preview"
+
+	previewOf := func(t *testing.T, body []byte) api.BroadcastPreview {
+		var response struct {
+			Data api.BroadcastPreview `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(body, &response))
+		return response.Data
+	}
+
+	t.Run("Success_RoleAndDepartment", func(t *testing.T) {
+		env := setupBroadcastEnv()
+		ids := []uuid.UUID{members.admin.ID, members.sam.ID, members.alex.ID, members.lee.ID}
+		env.RolesStore.On("GetRoleByName", orgID, "bartender").Return(&database.OrganizationRole{Role: "bartender"}, nil).Once()
+		env.UserStore.On("GetUsersByOrganization", orgID).Return(members.all(), nil).Once()
+		env.CustomFieldStore.On("GetCustomFieldValues", orgID, database.CustomFieldEmployee, ids).Return(map[uuid.UUID]map[string]any{
+			members.sam.ID:  {"department": "Bar"},
+			members.alex.ID: {"department": "Kitchen"},
+			members.lee.ID:  {"department": "bar"},
+		}, nil).Once()
+		env.UserRolesStore.On("GetUserRoles", members.sam.ID, orgID).Return([]string{"bartender"}, nil).Once()
+		env.UserRolesStore.On("GetUserRoles", members.lee.ID, orgID).Return([]string{"bartender", "server"}, nil).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(members.admin), env.Handler.PreviewBroadcastHandler},
+			map[string]any{"channels": []string{"sms"}, "filters": map[string]any{"roles": []string{"bartender"}, "departments": []string{"Bar"}}})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		preview := previewOf(t, w.Body.Bytes())
+		assert.Equal(t, 2, preview.Recipients)
+		assert.Equal(t, map[string]int{"sms": 1, "email": 1}, preview.ByChannel)
+		assert.Equal(t, 1, preview.SMSFallback)
+		env.UserRolesStore.AssertNotCalled(t, "GetUserRoles", members.alex.ID, orgID)
+		env.ScheduleStore.AssertNotCalled(t, "GetShiftsFrom", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Success_ScheduledTomorrow", func(t *testing.T) {
+		env := setupBroadcastEnv()
+		now := time.Now()
+		tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.Local)
+		env.UserStore.On("GetUsersByOrganization", orgID).Return(members.all(), nil).Once()
+		env.ScheduleStore.On("GetShiftsFrom", orgID, tomorrow).Return([]database.Shift{
+			{EmployeeID: members.sam.ID, Date: time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 0, 0, 0, 0, time.UTC), StartTime: "10:00:00", EndTime: "18:00:00"},
+			{EmployeeID: members.lee.ID, Date: time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day()+1, 0, 0, 0, 0, time.UTC), StartTime: "10:00:00", EndTime: "18:00:00"},
+		}, nil).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(members.admin), env.Handler.PreviewBroadcastHandler},
+			map[string]any{"channels": []string{"email", "sms"}, "filters": map[string]any{"scheduled_tomorrow": true}})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		preview := previewOf(t, w.Body.Bytes())
+		assert.Equal(t, 1, preview.Recipients)
+		assert.Equal(t, map[string]int{"email": 1, "sms": 1}, preview.ByChannel)
+		assert.Equal(t, 0, preview.SMSFallback)
+	})
+
+	t.Run("Failure_UnknownRole", func(t *testing.T) {
+		env := setupBroadcastEnv()
+		env.RolesStore.On("GetRoleByName", orgID, "sommelier").Return(nil, nil).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(members.admin), env.Handler.PreviewBroadcastHandler},
+			map[string]any{"channels": []string{"email"}, "filters": map[string]any{"roles": []string{"sommelier"}}})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Unknown role: sommelier")
+		env.UserStore.AssertNotCalled(t, "GetUsersByOrganization", mock.Anything)
+	})
+
+	t.Run("Failure_InvalidChannel", func(t *testing.T) {
+		env := setupBroadcastEnv()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(members.admin), env.Handler.PreviewBroadcastHandler},
+			map[string]any{"channels": []string{"pager"}})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Failure_ManagerForbidden", func(t *testing.T) {
+		env := setupBroadcastEnv()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(members.alex), env.Handler.PreviewBroadcastHandler},
+			map[string]any{"channels": []string{"email"}})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestSendBroadcastHandler(t *testing.T) {
+	members := newBroadcastMembers()
+	orgID := members.orgID
+	route := "/:org/notifications/broadcast"
+	path := "/" + orgID.String() + "/notifications/broadcast"
+
+	t.Run("Success_DeliveredInBackground", func(t *testing.T) {
+		env := setupBroadcastEnv()
+		broadcastID := uuid.New()
+		env.UserStore.On("GetUsersByOrganization", orgID).Return(members.all(), nil).Once()
+		env.BroadcastStore.On("CreateBroadcast", mock.MatchedBy(func(b *database.Broadcast) bool {
+			return b.OrganizationID == orgID && *b.SentBy == members.admin.ID && b.Title == "Closed tomorrow"
+		}), mock.MatchedBy(func(deliveries []database.BroadcastDelivery) bool {
+			return len(deliveries) == 4 &&
+				deliveries[0].UserID == members.sam.ID && deliveries[0].Channel == "email" &&
+				deliveries[1].UserID == members.sam.ID && deliveries[1].Channel == "sms" &&
+				deliveries[2].UserID == members.alex.ID && deliveries[2].Channel == "email" &&
+				deliveries[3].UserID == members.lee.ID && deliveries[3].Channel == "email"
+		})).Run(func(args mock.Arguments) {
+			args.Get(0).(*database.Broadcast).ID = broadcastID
+		}).Return(nil).Once()
+
+		env.EmailService.On("SendBroadcastEmail", "sam@test.com", "Admin", "Closed tomorrow", "Water outage").Return(nil).Once()
+		env.EmailService.On("SendBroadcastEmail", "alex@test.com", "Admin", "Closed tomorrow", "Water outage").Return(nil).Once()
+		env.EmailService.On("SendBroadcastEmail", "lee@test.com", "Admin", "Closed tomorrow", "Water outage").Return(errors.New("mailbox full")).Once()
+		env.SMSService.On("SendSMS", "+201000000000", "Closed tomorrow: Water outage").Return(nil).Once()
+
+		recorded := make(chan database.BroadcastDelivery, 4)
+		env.BroadcastStore.On("SetBroadcastDeliveryStatus", broadcastID, mock.Anything).Run(func(args mock.Arguments) {
+			recorded <- args.Get(1).(database.BroadcastDelivery)
+		}).Return(nil).Times(4)
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(members.admin), env.Handler.SendBroadcastHandler},
+			map[string]any{"title": "Closed tomorrow", "message": "Water outage", "channels": []string{"email", "sms"}})
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Contains(t, w.Body.String(), broadcastID.String())
+
+		statuses := map[string]database.BroadcastDelivery{}
+		for range 4 {
+			select {
+			case delivery := <-recorded:
+				statuses[delivery.FullName+" "+delivery.Channel] = delivery
+			case <-time.After(time.Second):
+				t.Fatal("broadcast was not delivered")
+			}
+		}
+		assert.Equal(t, database.BroadcastDeliverySent, statuses["Sam email"].Status)
+		assert.Equal(t, database.BroadcastDeliverySent, statuses["Sam sms"].Status)
+		assert.Equal(t, database.BroadcastDeliverySent, statuses["Alex email"].Status)
+		assert.Equal(t, database.BroadcastDeliveryFailed, statuses["Lee email"].Status)
+		if assert.NotNil(t, statuses["Lee email"].Error) {
+			assert.Equal(t, "mailbox full", *statuses["Lee email"].Error)
+		}
+		env.EmailService.AssertNotCalled(t, "SendBroadcastEmail", "admin@test.com", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failure_NoRecipients", func(t *testing.T) {
+		env := setupBroadcastEnv()
+		env.UserStore.On("GetUsersByOrganization", orgID).Return([]*database.User{members.admin, members.sam}, nil).Once()
+		env.UserRolesStore.On("GetUserRoles", members.sam.ID, orgID).Return([]string{}, nil).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(members.admin), env.Handler.SendBroadcastHandler},
+			map[string]any{"title": "Meeting", "message": "Managers meeting at 9", "channels": []string{"email"}, "filters": map[string]any{"roles": []string{"manager"}}})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		env.BroadcastStore.AssertNotCalled(t, "CreateBroadcast", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failure_MissingMessage", func(t *testing.T) {
+		env := setupBroadcastEnv()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(members.admin), env.Handler.SendBroadcastHandler},
+			map[string]any{"title": "Meeting", "channels": []string{"email"}})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Failure_DBError", func(t *testing.T) {
+		env := setupBroadcastEnv()
+		env.UserStore.On("GetUsersByOrganization", orgID).Return(members.all(), nil).Once()
+		env.BroadcastStore.On("CreateBroadcast", mock.Anything, mock.Anything).Return(errors.New("db error")).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(members.admin), env.Handler.SendBroadcastHandler},
+			map[string]any{"title": "Meeting", "message": "Staff meeting at 9", "channels": []string{"email"}})
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		env.EmailService.AssertNotCalled(t, "SendBroadcastEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetBroadcastHandler(t *testing.T) {
+	members := newBroadcastMembers()
+	orgID := members.orgID
+	broadcastID := uuid.New()
+	route := "/:org/notifications/broadcasts/:id"
+	path := "/" + orgID.String() + "/notifications/broadcasts/" + broadcastID.String()
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupBroadcastEnv()
+		failure := "mailbox full"
+		env.BroadcastStore.On("GetBroadcast", orgID, broadcastID).Return(&database.Broadcast{ID: broadcastID, Recipients: 2, Sent: 1, Failed: 1}, nil).Once()
+		env.BroadcastStore.On("GetBroadcastDeliveries", broadcastID).Return([]database.BroadcastDelivery{
+			{UserID: members.lee.ID, FullName: "Lee", Channel: "email", Status: database.BroadcastDeliveryFailed, Error: &failure},
+			{UserID: members.sam.ID, FullName: "Sam", Channel: "sms", Status: database.BroadcastDeliverySent},
+		}, nil).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(members.admin), env.Handler.GetBroadcastHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data       database.Broadcast           `json:"data"`
+			Deliveries []database.BroadcastDelivery `json:"deliveries"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 1, response.Data.Failed)
+		assert.Len(t, response.Deliveries, 2)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env := setupBroadcastEnv()
+		env.BroadcastStore.On("GetBroadcast", orgID, broadcastID).Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(members.admin), env.Handler.GetBroadcastHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		env := setupBroadcastEnv()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(members.sam), env.Handler.GetBroadcastHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
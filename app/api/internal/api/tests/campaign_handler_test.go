@@ -1,18 +1,25 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/clockwise/clockwise/backend/internal/api"
 	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 type CampaignTestEnv struct {
@@ -23,6 +30,7 @@ type CampaignTestEnv struct {
 	OrgStore            *MockOrgStore
 	OperatingHoursStore *MockOperatingHoursStore
 	RulesStore          *MockRulesStore
+	StatusStore         *MockStatusStore
 	Handler             *api.CampaignHandler
 }
 
@@ -35,9 +43,11 @@ func setupCampaignEnv() *CampaignTestEnv {
 	orgStore := new(MockOrgStore)
 	opHoursStore := new(MockOperatingHoursStore)
 	rulesStore := new(MockRulesStore)
+	statusStore := new(MockStatusStore)
+	statusStore.AllowEvents()
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
-	handler := api.NewCampaignHandler(campaignStore, uploadService, orderStore, orgStore, opHoursStore, rulesStore, logger)
+	handler := api.NewCampaignHandler(campaignStore, uploadService, orderStore, orgStore, opHoursStore, rulesStore, new(MockItemAvailabilityStore), new(MockExternalEventStore), statusStore, logger)
 
 	return &CampaignTestEnv{
 		Router:              gin.New(),
@@ -47,6 +57,7 @@ func setupCampaignEnv() *CampaignTestEnv {
 		OrgStore:            orgStore,
 		OperatingHoursStore: opHoursStore,
 		RulesStore:          rulesStore,
+		StatusStore:         statusStore,
 		Handler:             handler,
 	}
 }
@@ -64,6 +75,9 @@ func (env *CampaignTestEnv) ResetMocks() {
 	env.OperatingHoursStore.Calls = nil
 	env.RulesStore.ExpectedCalls = nil
 	env.RulesStore.Calls = nil
+	env.StatusStore.ExpectedCalls = nil
+	env.StatusStore.Calls = nil
+	env.StatusStore.AllowEvents()
 }
 
 // --- GetAllCampaigns ---
@@ -186,6 +200,9 @@ func TestGetCampaignsInsightsHandler(t *testing.T) {
 			{Title: "Total Campaigns", Statistic: "12"},
 		}
 		env.CampaignStore.On("GetCampaignInsights", orgID).Return(insights, nil).Once()
+		env.CampaignStore.On("GetChannelPerformance", orgID).Return([]database.ChannelPerformance{
+			{Channel: "app", Campaigns: 2, Redemptions: 30, Revenue: 540, Discount: 60},
+		}, nil).Once()
 
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/campaigns/insights", nil)
@@ -193,6 +210,7 @@ func TestGetCampaignsInsightsHandler(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, w.Code)
 		assert.Contains(t, w.Body.String(), "Total Campaigns")
+		assert.Contains(t, w.Body.String(), `"channel":"app"`)
 		assert.Contains(t, w.Body.String(), "Campaign insights retrieved successfully")
 		env.CampaignStore.AssertExpectations(t)
 	})
@@ -257,6 +275,38 @@ func TestRecommendCampaignsHandler(t *testing.T) {
 
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
+
+	t.Run("Failure_InvalidOptions", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := httptest.NewRecorder()
+		body := `{"recommendation_start_date": "2024-01-01", "optimize_for": "clicks"}`
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/campaigns/recommend", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "optimize_for must be one of")
+		env.OrgStore.AssertNotCalled(t, "GetOrganizationByID", orgID)
+	})
+
+	t.Run("Failure_MissingLocation", func(t *testing.T) {
+		env.ResetMocks()
+		org := &database.Organization{ID: orgID, Name: "Test Org", Type: "restaurant"}
+		env.OrgStore.On("GetOrganizationByID", orgID).Return(org, nil).Once()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(nil, nil).Once()
+		env.OperatingHoursStore.On("GetOperatingHours", orgID).Return([]database.OperatingHours{}, nil).Once()
+
+		w := httptest.NewRecorder()
+		body := `{"recommendation_start_date": "2024-01-01"}`
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/campaigns/recommend", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "organization latitude is required")
+		env.OrderStore.AssertNotCalled(t, "GetAllOrders", orgID)
+	})
 }
 
 // --- SubmitCampaignFeedbackHandler (validation tests only) ---
@@ -293,4 +343,270 @@ func TestSubmitCampaignFeedbackHandler(t *testing.T) {
 
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
+
+	t.Run("ComputesROIFromRedemptions", func(t *testing.T) {
+		env.ResetMocks()
+		campaignID := uuid.New()
+		var sent map[string]any
+		ml := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewDecoder(r.Body).Decode(&sent)
+			w.Write([]byte(`{"status":"ok","message":"Feedback recorded"}`))
+		}))
+		defer ml.Close()
+		env.Handler.MLServiceURL = ml.URL
+		env.CampaignStore.On("GetCampaignPerformance", orgID, campaignID).Return(&database.CampaignPerformance{
+			CampaignID: campaignID, Channel: "delivery_platform", Redemptions: 20, Revenue: 400, Discount: 50,
+		}, nil).Once()
+
+		w := httptest.NewRecorder()
+		body := strings.NewReader(`{"campaign_id":"` + campaignID.String() + `","success":true}`)
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/campaigns/feedback", body)
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 400.0, sent["actual_revenue"])
+		assert.Equal(t, 7.0, sent["actual_roi"])
+		assert.Equal(t, "delivery_platform", sent["channel"])
+		env.CampaignStore.AssertExpectations(t)
+	})
+}
+
+// --- CampaignROI ---
+
+func TestCampaignROI(t *testing.T) {
+	t.Run("ReturnOnDiscount", func(t *testing.T) {
+		roi := api.CampaignROI(database.CampaignPerformance{Redemptions: 10, Revenue: 250, Discount: 100})
+		assert.NotNil(t, roi)
+		assert.Equal(t, 1.5, *roi)
+	})
+
+	t.Run("NoDiscount", func(t *testing.T) {
+		assert.Nil(t, api.CampaignROI(database.CampaignPerformance{Redemptions: 10, Revenue: 250}))
+	})
+}
+
+// --- FilterAvailableItems ---
+
+func TestFilterAvailableItems(t *testing.T) {
+	burger := uuid.New()
+	pumpkinSoup := uuid.New()
+	items := []database.Item{{ItemID: burger, Name: "Burger"}, {ItemID: pumpkinSoup, Name: "Pumpkin soup"}}
+	unavailable := map[uuid.UUID]bool{pumpkinSoup: true}
+
+	t.Run("DropsUnavailableRequestedItems", func(t *testing.T) {
+		available := api.FilterAvailableItems([]string{burger.String(), pumpkinSoup.String(), "drink_cola"}, nil, unavailable)
+		assert.Equal(t, []string{burger.String(), "drink_cola"}, available)
+	})
+
+	t.Run("FillsAvailableItemsWhenNoneRequested", func(t *testing.T) {
+		available := api.FilterAvailableItems(nil, items, unavailable)
+		assert.Equal(t, []string{burger.String()}, available)
+	})
+
+	t.Run("NothingUnavailable", func(t *testing.T) {
+		assert.Empty(t, api.FilterAvailableItems(nil, items, map[uuid.UUID]bool{}))
+	})
+}
+
+// --- Campaign conflicts ---
+
+func TestCampaignConflicts(t *testing.T) {
+	burger, fries, soda := uuid.New(), uuid.New(), uuid.New()
+	candidate := database.Campaign{
+		ID: uuid.New(), Status: "active", StartTime: "2026-11-02T00:00:00Z", EndTime: "2026-11-08T23:59:59Z",
+		ItemsIncluded: []database.Item{{ItemID: burger}, {ItemID: fries}},
+	}
+	lunch := database.Campaign{
+		ID: uuid.New(), Name: "Lunch deal", Status: "active", StartTime: "2026-11-05", EndTime: "2026-11-12",
+		ItemsIncluded: []database.Item{{ItemID: fries}, {ItemID: soda}},
+	}
+
+	t.Run("OverlappingSharedItems", func(t *testing.T) {
+		conflicts := api.CampaignConflicts(candidate, []database.Campaign{candidate, lunch})
+		assert.Len(t, conflicts, 1)
+		assert.Equal(t, lunch.ID, conflicts[0].CampaignID)
+		assert.Equal(t, []uuid.UUID{fries}, conflicts[0].SharedItems)
+	})
+
+	t.Run("NoSharedItems", func(t *testing.T) {
+		drinks := lunch
+		drinks.ItemsIncluded = []database.Item{{ItemID: soda}}
+		assert.Empty(t, api.CampaignConflicts(candidate, []database.Campaign{drinks}))
+	})
+
+	t.Run("EndDateIsInclusive", func(t *testing.T) {
+		// a campaign ending on November 1 still runs that day, one ending the day before does not overlap
+		before := lunch
+		before.StartTime, before.EndTime = "2026-10-25", "2026-11-01"
+		assert.Empty(t, api.CampaignConflicts(candidate, []database.Campaign{before}))
+		before.EndTime = "2026-11-02"
+		assert.Len(t, api.CampaignConflicts(candidate, []database.Campaign{before}), 1)
+	})
+
+	t.Run("InactiveIgnored", func(t *testing.T) {
+		inactive := lunch
+		inactive.Status = "inactive"
+		assert.Empty(t, api.CampaignConflicts(candidate, []database.Campaign{inactive}))
+	})
+}
+
+func TestAcceptCampaignRecommendationHandler(t *testing.T) {
+	env := setupCampaignEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+
+	env.Router.POST("/:org/campaigns/recommendations/accept", authMiddleware(admin), env.Handler.AcceptCampaignRecommendationHandler)
+
+	burger := uuid.New()
+	lunch := database.Campaign{
+		ID: uuid.New(), Name: "Lunch deal", Status: "active", StartTime: "2026-11-05T00:00:00Z", EndTime: "2026-11-12T00:00:00Z",
+		ItemsIncluded: []database.Item{{ItemID: burger}},
+	}
+	accept := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/campaigns/recommendations/accept", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+		return w
+	}
+	body := `{"campaign_id": "rec_1", "items": ["` + burger.String() + `"], "discount_percentage": 15, "start_date": "2026-11-02", "end_date": "2026-11-08"%s}`
+
+	t.Run("Success_NoConflict", func(t *testing.T) {
+		env.ResetMocks()
+		env.CampaignStore.On("GetAllCampaigns", orgID).Return([]database.Campaign{}, nil).Once()
+		var stored database.Campaign
+		env.CampaignStore.On("StoreCampaign", orgID, mock.AnythingOfType("database.Campaign")).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(database.Campaign)
+		}).Return(nil).Once()
+		env.CampaignStore.On("StoreCampaignItems", orgID, mock.Anything, []database.Item{{ItemID: burger}}).Return(nil).Once()
+
+		w := accept(fmt.Sprintf(body, ""))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "2026-11-02T00:00:00Z", stored.StartTime)
+		assert.Equal(t, "2026-11-08T23:59:59Z", stored.EndTime)
+		assert.Equal(t, 15.0, *stored.DiscountPercent)
+		assert.Equal(t, database.CampaignChannelInStore, stored.Channel)
+		env.CampaignStore.AssertExpectations(t)
+	})
+
+	t.Run("Conflict_Refused", func(t *testing.T) {
+		env.ResetMocks()
+		env.CampaignStore.On("GetAllCampaigns", orgID).Return([]database.Campaign{lunch}, nil).Once()
+
+		w := accept(fmt.Sprintf(body, ""))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "Lunch deal")
+		assert.Contains(t, w.Body.String(), burger.String())
+		env.CampaignStore.AssertNotCalled(t, "StoreCampaign", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Conflict_AllowStacking", func(t *testing.T) {
+		env.ResetMocks()
+		env.CampaignStore.On("GetAllCampaigns", orgID).Return([]database.Campaign{lunch}, nil).Once()
+		env.CampaignStore.On("StoreCampaign", orgID, mock.Anything).Return(nil).Once()
+		env.CampaignStore.On("StoreCampaignItems", orgID, mock.Anything, mock.Anything).Return(nil).Once()
+
+		w := accept(fmt.Sprintf(body, `, "allow_stacking": true`))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		// the stacking is still reported
+		assert.Contains(t, w.Body.String(), "Lunch deal")
+		env.CampaignStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_InvalidPeriod", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := accept(`{"items": ["` + burger.String() + `"], "start_date": "2026-11-08", "end_date": "2026-11-02"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		env.CampaignStore.AssertNotCalled(t, "GetAllCampaigns", orgID)
+	})
+
+	t.Run("Failure_InvalidItem", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := accept(`{"items": ["burger"], "start_date": "2026-11-02", "end_date": "2026-11-08"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+		router := gin.New()
+		router.POST("/:org/campaigns/recommendations/accept", authMiddleware(employee), env.Handler.AcceptCampaignRecommendationHandler)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/campaigns/recommendations/accept", strings.NewReader(fmt.Sprintf(body, "")))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestUploadCampaignsItemsCSVConflicts(t *testing.T) {
+	env := setupCampaignEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+
+	env.Router.POST("/:org/campaigns/upload/items", authMiddleware(admin), env.Handler.UploadCampaignsItemsCSVHandlers)
+
+	burger := uuid.New()
+	lunch := database.Campaign{
+		ID: uuid.New(), Name: "Lunch deal", Status: "active", StartTime: "2026-11-05T00:00:00Z", EndTime: "2026-11-12T00:00:00Z",
+		ItemsIncluded: []database.Item{{ItemID: burger}},
+	}
+	weekend := database.Campaign{ID: uuid.New(), Name: "Weekend", Status: "active", StartTime: "2026-11-07T00:00:00Z", EndTime: "2026-11-09T00:00:00Z"}
+	csvData := &service.CSVData{
+		Headers: []string{"campaign_id", "item_id"},
+		Rows:    []map[string]string{{"campaign_id": weekend.ID.String(), "item_id": burger.String()}},
+		Total:   1,
+	}
+	upload := func(query string) *httptest.ResponseRecorder {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("file", "items.csv")
+		part.Write([]byte("dummy content"))
+		writer.Close()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/campaigns/upload/items"+query, body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		env.Router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("ConflictSkipped", func(t *testing.T) {
+		env.ResetMocks()
+		env.CampaignStore.On("GetAllCampaigns", orgID).Return([]database.Campaign{lunch, weekend}, nil).Once()
+		env.UploadService.On("ParseCSV", mock.Anything).Return(csvData, nil).Once()
+
+		w := upload("")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]any
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, float64(0), response["success_count"])
+		assert.Equal(t, float64(1), response["error_count"])
+		assert.Contains(t, w.Body.String(), lunch.ID.String())
+		env.CampaignStore.AssertNotCalled(t, "StoreCampaignItems", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("AllowStacking", func(t *testing.T) {
+		env.ResetMocks()
+		env.CampaignStore.On("GetAllCampaigns", orgID).Return([]database.Campaign{lunch, weekend}, nil).Once()
+		env.UploadService.On("ParseCSV", mock.Anything).Return(csvData, nil).Once()
+		env.CampaignStore.On("StoreCampaignItems", orgID, weekend.ID, []database.Item{{ItemID: burger}}).Return(nil).Once()
+
+		w := upload("?allow_stacking=true")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"success_count":1`)
+		env.CampaignStore.AssertExpectations(t)
+	})
 }
@@ -0,0 +1,303 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestConsolidateFigures(t *testing.T) {
+	paris, london := uuid.New(), uuid.New()
+	figures := []api.OrganizationFigures{
+		{OrganizationID: paris, Name: "Paris", Currency: "EUR", Revenue: 9200, LaborCost: 2300},
+		{OrganizationID: london, Name: "London", Currency: "GBP", Revenue: 1580, LaborCost: 790},
+	}
+
+	t.Run("ConvertsIntoReportingCurrency", func(t *testing.T) {
+		report, err := api.ConsolidateFigures("USD", figures, map[string]float64{"EUR": 0.92, "GBP": 0.79})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "USD", report.Currency)
+		if assert.Len(t, report.Organizations, 2) {
+			assert.Equal(t, 0.92, report.Organizations[0].ExchangeRate)
+			assert.Equal(t, 10000.0, report.Organizations[0].ConvertedRevenue)
+			assert.Equal(t, 2500.0, report.Organizations[0].ConvertedLaborCost)
+			assert.Equal(t, 2000.0, report.Organizations[1].ConvertedRevenue)
+		}
+		assert.Equal(t, 12000.0, report.Revenue)
+		assert.Equal(t, 3500.0, report.LaborCost)
+		assert.Equal(t, 29.17, *report.LaborCostPercent)
+	})
+
+	t.Run("SameCurrencyNeedsNoRate", func(t *testing.T) {
+		report, err := api.ConsolidateFigures("EUR", figures[:1], nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1.0, report.Organizations[0].ExchangeRate)
+		assert.Equal(t, 9200.0, report.Revenue)
+	})
+
+	t.Run("MissingRate", func(t *testing.T) {
+		_, err := api.ConsolidateFigures("USD", figures, map[string]float64{"EUR": 0.92})
+		assert.EqualError(t, err, "no exchange rate from GBP to USD")
+	})
+
+	t.Run("NoRevenue", func(t *testing.T) {
+		report, err := api.ConsolidateFigures("USD", []api.OrganizationFigures{{Currency: "USD", LaborCost: 100}}, nil)
+		assert.NoError(t, err)
+		assert.Nil(t, report.LaborCostPercent)
+	})
+}
+
+func TestCachedExchangeRates(t *testing.T) {
+	store := new(MockExchangeRateStore)
+	provider := new(MockExchangeRateProvider)
+	rates := &service.CachedExchangeRates{Store: store, Provider: provider, Logger: slog.New(slog.NewTextHandler(os.Stdout, nil))}
+	day := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	reset := func() {
+		for _, m := range []*mock.Mock{&store.Mock, &provider.Mock} {
+			m.ExpectedCalls = nil
+			m.Calls = nil
+		}
+	}
+
+	t.Run("Cached", func(t *testing.T) {
+		reset()
+		store.On("GetExchangeRates", "USD", day).Return(map[string]float64{"EUR": 0.92}, nil).Once()
+
+		result, err := rates.Rates(t.Context(), "USD", day.Add(15*time.Hour))
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]float64{"EUR": 0.92}, result)
+		provider.AssertNotCalled(t, "Rates", mock.Anything, mock.Anything)
+	})
+
+	t.Run("FetchedAndCached", func(t *testing.T) {
+		reset()
+		store.On("GetExchangeRates", "USD", day).Return(map[string]float64{}, nil).Once()
+		provider.On("Rates", "USD", day).Return(map[string]float64{"GBP": 0.79}, nil).Once()
+		// a failure to cache still answers
+		store.On("StoreExchangeRates", "USD", day, map[string]float64{"GBP": 0.79}).Return(errors.New("db error")).Once()
+
+		result, err := rates.Rates(t.Context(), "USD", day)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0.79, result["GBP"])
+		store.AssertExpectations(t)
+	})
+
+	t.Run("ProviderError", func(t *testing.T) {
+		reset()
+		store.On("GetExchangeRates", "USD", day).Return(map[string]float64{}, nil).Once()
+		provider.On("Rates", "USD", day).Return(nil, service.ErrExchangeRatesUnavailable).Once()
+
+		_, err := rates.Rates(t.Context(), "USD", day)
+
+		assert.ErrorIs(t, err, service.ErrExchangeRatesUnavailable)
+		store.AssertNotCalled(t, "StoreExchangeRates", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestHTTPExchangeRates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2026-10-15" || r.URL.Query().Get("from") != "USD" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"amount": 1.0, "base": "USD", "date": "2026-10-15", "rates": {"EUR": 0.92}}`))
+	}))
+	defer server.Close()
+	provider := &service.HTTPExchangeRates{URL: server.URL, Client: server.Client(), Logger: slog.New(slog.NewTextHandler(os.Stdout, nil))}
+
+	rates, err := provider.Rates(t.Context(), "USD", time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"EUR": 0.92}, rates)
+
+	_, err = provider.Rates(t.Context(), "XXX", time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, service.ErrExchangeRatesUnavailable)
+}
+
+func TestGetConsolidatedReportHandler(t *testing.T) {
+	membershipStore := new(MockMembershipStore)
+	orgStore := new(MockOrgStore)
+	orderStore := new(MockOrderStore)
+	scheduleStore := new(MockScheduleStore)
+	userStore := new(MockUserStore)
+	rates := new(MockExchangeRateProvider)
+	handler := api.NewConsolidationHandler(membershipStore, orgStore, orderStore, scheduleStore, userStore, rates,
+		slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	reset := func() {
+		for _, m := range []*mock.Mock{&membershipStore.Mock, &orgStore.Mock, &orderStore.Mock, &scheduleStore.Mock, &userStore.Mock, &rates.Mock} {
+			m.ExpectedCalls = nil
+			m.Calls = nil
+		}
+	}
+
+	newYork, paris, berlin := uuid.New(), uuid.New(), uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: newYork, UserRole: "admin"}
+	memberships := []database.OrganizationMembership{
+		{UserID: admin.ID, OrganizationID: newYork, OrganizationName: "New York", UserRole: "admin"},
+		{UserID: admin.ID, OrganizationID: paris, OrganizationName: "Paris", UserRole: "admin"},
+		// only managing Berlin, it is not part of the account
+		{UserID: admin.ID, OrganizationID: berlin, OrganizationName: "Berlin", UserRole: "manager"},
+	}
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+	path := "/auth/organizations/consolidated?from=2026-09-01&to=2026-09-30"
+	request := func(user *database.User, path string) *httptest.ResponseRecorder {
+		return jobRequest("GET", "/auth/organizations/consolidated", path, []gin.HandlerFunc{authMiddleware(user), handler.GetConsolidatedReportHandler}, nil)
+	}
+	expectFigures := func(orgID uuid.UUID, currency string, revenue, salary float64) {
+		employeeID := uuid.New()
+		orgStore.On("GetCurrency", orgID).Return(currency, nil).Once()
+		orderStore.On("GetChannelBreakdown", orgID, from, to).Return([]database.ChannelBreakdown{{Channel: "in_store", Revenue: revenue}}, nil).Once()
+		userStore.On("GetUsersByOrganization", orgID).Return([]*database.User{{ID: employeeID, SalaryPerHour: &salary}}, nil).Once()
+		scheduleStore.On("GetScheduledHours", orgID, from, to).Return([]database.EmployeeHours{
+			{EmployeeID: employeeID, WorkingHours: 100, StandbyHours: 20, StandbyPayPercent: 50},
+			{EmployeeID: uuid.New(), WorkingHours: 10},
+		}, nil).Once()
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		reset()
+		membershipStore.On("GetMembershipsForUser", admin.ID).Return(memberships, nil).Once()
+		orgStore.On("GetCurrency", newYork).Return("USD", nil).Once()
+		expectFigures(newYork, "USD", 5000, 20)
+		expectFigures(paris, "EUR", 4600, 18.4)
+		rates.On("Rates", "USD", time.Date(2026, 9, 30, 0, 0, 0, 0, time.Local)).Return(map[string]float64{"EUR": 0.92}, nil).Once()
+
+		w := request(admin, path)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data struct {
+				RateDate string                 `json:"rate_date"`
+				Report   api.ConsolidatedReport `json:"report"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "2026-09-30", resp.Data.RateDate)
+		report := resp.Data.Report
+		assert.Equal(t, "USD", report.Currency)
+		if assert.Len(t, report.Organizations, 2) {
+			// 100 working hours and 10 paid standby hours, the employee without a salary left out
+			assert.Equal(t, 2200.0, report.Organizations[0].LaborCost)
+			assert.Equal(t, 1, report.Organizations[0].EmployeesWithoutSalary)
+			assert.Equal(t, "EUR", report.Organizations[1].Currency)
+			assert.Equal(t, 5000.0, report.Organizations[1].ConvertedRevenue)
+			assert.Equal(t, 2200.0, report.Organizations[1].ConvertedLaborCost)
+		}
+		assert.Equal(t, 10000.0, report.Revenue)
+		assert.Equal(t, 4400.0, report.LaborCost)
+		orgStore.AssertNotCalled(t, "GetCurrency", berlin)
+	})
+
+	t.Run("SingleCurrency_NoRates", func(t *testing.T) {
+		reset()
+		membershipStore.On("GetMembershipsForUser", admin.ID).Return(memberships[:1], nil).Once()
+		expectFigures(newYork, "USD", 5000, 20)
+
+		w := request(admin, path+"&currency=usd")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		rates.AssertNotCalled(t, "Rates", mock.Anything, mock.Anything)
+	})
+
+	t.Run("RatesUnavailable", func(t *testing.T) {
+		reset()
+		membershipStore.On("GetMembershipsForUser", admin.ID).Return(memberships, nil).Once()
+		expectFigures(newYork, "USD", 5000, 20)
+		expectFigures(paris, "EUR", 4600, 18.4)
+		rates.On("Rates", "GBP", mock.Anything).Return(nil, service.ErrExchangeRatesUnavailable).Once()
+
+		w := request(admin, path+"&currency=GBP")
+
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+	})
+
+	t.Run("MissingRate", func(t *testing.T) {
+		reset()
+		membershipStore.On("GetMembershipsForUser", admin.ID).Return(memberships, nil).Once()
+		expectFigures(newYork, "USD", 5000, 20)
+		expectFigures(paris, "EUR", 4600, 18.4)
+		rates.On("Rates", "GBP", mock.Anything).Return(map[string]float64{"USD": 1.27}, nil).Once()
+
+		w := request(admin, path+"&currency=GBP")
+
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+		assert.Contains(t, w.Body.String(), "no exchange rate from EUR to GBP")
+	})
+
+	t.Run("InvalidCurrency", func(t *testing.T) {
+		reset()
+		membershipStore.On("GetMembershipsForUser", admin.ID).Return(memberships, nil).Once()
+
+		w := request(admin, path+"&currency=dollars")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("NotAnAdmin", func(t *testing.T) {
+		reset()
+		manager := &database.User{ID: uuid.New(), OrganizationID: berlin, UserRole: "manager"}
+		membershipStore.On("GetMembershipsForUser", manager.ID).Return(memberships[2:], nil).Once()
+
+		w := request(manager, path)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("WithKPITargets", func(t *testing.T) {
+		reset()
+		kpiStore := new(MockKPITargetStore)
+		handler.KPIs = service.NewKPITracker(kpiStore, scheduleStore, userStore)
+		defer func() { handler.KPIs = nil }()
+		membershipStore.On("GetMembershipsForUser", admin.ID).Return(memberships[:1], nil).Once()
+		expectFigures(newYork, "USD", 5000, 20)
+		revenueTarget := 1000.0
+		kpiStore.On("GetTargets", newYork).Return(&database.KPITargets{OrganizationID: newYork, WeeklyRevenue: &revenueTarget}, nil).Once()
+		kpiStore.On("GetSales", newYork, from, to).Return(&database.KPISales{Revenue: 5000}, nil).Once()
+		userStore.On("GetUsersByOrganization", newYork).Return([]*database.User{}, nil).Once()
+		scheduleStore.On("GetScheduledHours", newYork, from, to).Return([]database.EmployeeHours{}, nil).Once()
+
+		w := request(admin, path+"&currency=USD")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data struct {
+				Report api.ConsolidatedReport `json:"report"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		if assert.Len(t, resp.Data.Report.Organizations, 1) && assert.Len(t, resp.Data.Report.Organizations[0].Targets, 1) {
+			// the weekly target over the 30 days of September
+			result := resp.Data.Report.Organizations[0].Targets[0]
+			assert.Equal(t, 4285.71, result.Target)
+			assert.True(t, *result.Met)
+		}
+	})
+
+	t.Run("StoreError", func(t *testing.T) {
+		reset()
+		membershipStore.On("GetMembershipsForUser", admin.ID).Return(memberships[:1], nil).Once()
+		orgStore.On("GetCurrency", newYork).Return("USD", nil).Once()
+		orgStore.On("GetCurrency", newYork).Return("", errors.New("db error")).Once()
+
+		w := request(admin, path)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
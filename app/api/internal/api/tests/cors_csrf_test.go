@@ -0,0 +1,180 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/config"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupCORSRouter(orgStore *MockOrgStore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	cfg := config.CORSConfig{
+		AllowedOrigins:     []string{"http://localhost:3000", "https://*.clockwise.app"},
+		AllowCustomDomains: true,
+		MaxAge:             time.Hour,
+	}
+	router := gin.New()
+	router.Use(middleware.CORS(cfg, orgStore, slog.New(slog.NewTextHandler(os.Stdout, nil))))
+	router.GET("/api/ping", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"message": "pong"}) })
+	return router
+}
+
+func corsRequest(router *gin.Engine, origin string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/api/ping", nil)
+	req.Header.Set("Origin", origin)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORS(t *testing.T) {
+	t.Run("ConfiguredOrigin", func(t *testing.T) {
+		orgStore := new(MockOrgStore)
+		w := corsRequest(setupCORSRouter(orgStore), "http://localhost:3000")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "http://localhost:3000", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), "Deprecation")
+		orgStore.AssertNotCalled(t, "GetOrgIDByCustomDomain")
+	})
+
+	t.Run("WildcardOrigin", func(t *testing.T) {
+		w := corsRequest(setupCORSRouter(new(MockOrgStore)), "https://acme.clockwise.app")
+
+		assert.Equal(t, "https://acme.clockwise.app", w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("OrganizationCustomDomainIsCached", func(t *testing.T) {
+		orgStore := new(MockOrgStore)
+		orgStore.On("GetOrgIDByCustomDomain", "shifts.example.com").Return(uuid.New(), nil).Once()
+		router := setupCORSRouter(orgStore)
+
+		corsRequest(router, "https://shifts.example.com")
+		w := corsRequest(router, "https://shifts.example.com")
+
+		assert.Equal(t, "https://shifts.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		orgStore.AssertExpectations(t)
+	})
+
+	t.Run("UnknownOrigin", func(t *testing.T) {
+		orgStore := new(MockOrgStore)
+		orgStore.On("GetOrgIDByCustomDomain", "evil.example.com").Return(uuid.Nil, sql.ErrNoRows).Once()
+
+		w := corsRequest(setupCORSRouter(orgStore), "https://evil.example.com")
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("UnknownOriginIsNotCached", func(t *testing.T) {
+		orgStore := new(MockOrgStore)
+		orgStore.On("GetOrgIDByCustomDomain", "evil.example.com").Return(uuid.Nil, sql.ErrNoRows).Twice()
+		router := setupCORSRouter(orgStore)
+
+		corsRequest(router, "https://evil.example.com")
+		w := corsRequest(router, "https://evil.example.com")
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		orgStore.AssertExpectations(t)
+	})
+
+	t.Run("CustomDomainOverHTTP", func(t *testing.T) {
+		orgStore := new(MockOrgStore)
+
+		w := corsRequest(setupCORSRouter(orgStore), "http://shifts.example.com")
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		orgStore.AssertNotCalled(t, "GetOrgIDByCustomDomain", "shifts.example.com")
+	})
+
+	t.Run("StoreError", func(t *testing.T) {
+		orgStore := new(MockOrgStore)
+		orgStore.On("GetOrgIDByCustomDomain", "shifts.example.com").Return(uuid.Nil, errors.New("db down")).Once()
+
+		w := corsRequest(setupCORSRouter(orgStore), "https://shifts.example.com")
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func setupCSRFRouter(enabled bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	cfg := config.CSRFConfig{Enabled: enabled, CookieName: "csrf_token", HeaderName: "X-CSRF-Token", SecureCookie: true}
+	router := gin.New()
+	router.Use(middleware.CSRFProtect(cfg))
+	router.GET("/api/csrf-token", middleware.IssueCSRFToken(cfg))
+	router.POST("/api/action", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"message": "done"}) })
+	return router
+}
+
+func csrfRequest(router *gin.Engine, cookies map[string]string, headers map[string]string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/api/action", nil)
+	for name, value := range cookies {
+		req.AddCookie(&http.Cookie{Name: name, Value: value})
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCSRFProtect(t *testing.T) {
+	t.Run("CookieSessionWithoutToken", func(t *testing.T) {
+		w := csrfRequest(setupCSRFRouter(true), map[string]string{"access_token": "jwt"}, nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("CookieSessionWithMismatchedToken", func(t *testing.T) {
+		w := csrfRequest(setupCSRFRouter(true),
+			map[string]string{"access_token": "jwt", "csrf_token": "abc"},
+			map[string]string{"X-CSRF-Token": "xyz"})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("CookieSessionWithToken", func(t *testing.T) {
+		w := csrfRequest(setupCSRFRouter(true),
+			map[string]string{"access_token": "jwt", "csrf_token": "abc"},
+			map[string]string{"X-CSRF-Token": "abc"})
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("BearerTokenNotAffected", func(t *testing.T) {
+		w := csrfRequest(setupCSRFRouter(true),
+			map[string]string{"access_token": "jwt"},
+			map[string]string{"Authorization": "Bearer jwt"})
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		w := csrfRequest(setupCSRFRouter(false), map[string]string{"access_token": "jwt"}, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("IssueToken", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/csrf-token", nil)
+		w := httptest.NewRecorder()
+		setupCSRFRouter(true).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		cookies := w.Result().Cookies()
+		if assert.Len(t, cookies, 1) {
+			assert.Equal(t, "csrf_token", cookies[0].Name)
+			assert.NotEmpty(t, cookies[0].Value)
+			assert.True(t, cookies[0].Secure)
+			assert.Contains(t, w.Body.String(), cookies[0].Value)
+		}
+	})
+}
@@ -0,0 +1,293 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type CustomFieldTestEnv struct {
+	FieldStore *MockCustomFieldStore
+	Handler    *api.CustomFieldHandler
+}
+
+func setupCustomFieldEnv() *CustomFieldTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	fieldStore := new(MockCustomFieldStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &CustomFieldTestEnv{
+		FieldStore: fieldStore,
+		Handler:    api.NewCustomFieldHandler(fieldStore, logger),
+	}
+}
+
+func (env *CustomFieldTestEnv) ResetMocks() {
+	env.FieldStore.ExpectedCalls = nil
+	env.FieldStore.Calls = nil
+}
+
+var employeeFields = []database.CustomFieldDefinition{
+	{Key: "uniform_size", FieldType: database.CustomFieldSelect, Options: []string{"S", "M", "L"}, Required: true},
+	{Key: "locker", FieldType: database.CustomFieldNumber},
+	{Key: "forklift_certified", FieldType: database.CustomFieldBoolean},
+	{Key: "certified_on", FieldType: database.CustomFieldDate},
+	{Key: "nickname", FieldType: database.CustomFieldText},
+}
+
+func TestValidateCustomFieldValues(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		err := api.ValidateCustomFieldValues(employeeFields, map[string]any{
+			"uniform_size": "M", "locker": float64(12), "forklift_certified": true, "certified_on": "2026-03-01", "nickname": "Sam",
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for name, values := range map[string]map[string]any{
+			"UnknownField":   {"uniform_size": "M", "shoe_size": "42"},
+			"NotAnOption":    {"uniform_size": "XXL"},
+			"NotANumber":     {"uniform_size": "M", "locker": "12"},
+			"NotABoolean":    {"uniform_size": "M", "forklift_certified": "yes"},
+			"NotADate":       {"uniform_size": "M", "certified_on": "01/03/2026"},
+			"RequiredMissed": {"locker": float64(12)},
+		} {
+			assert.Error(t, api.ValidateCustomFieldValues(employeeFields, values), name)
+		}
+	})
+}
+
+func TestParseCustomFieldFilters(t *testing.T) {
+	t.Run("ConvertsByType", func(t *testing.T) {
+		query := url.Values{"cf.locker": {"12"}, "cf.forklift_certified": {"true"}, "cf.uniform_size": {"M"}, "page": {"2"}}
+
+		filters, err := api.ParseCustomFieldFilters(query, employeeFields)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"locker": float64(12), "forklift_certified": true, "uniform_size": "M"}, filters)
+		assert.True(t, api.MatchesCustomFields(map[string]any{"locker": float64(12), "forklift_certified": true, "uniform_size": "M", "nickname": "Sam"}, filters))
+		assert.False(t, api.MatchesCustomFields(map[string]any{"locker": float64(13), "forklift_certified": true, "uniform_size": "M"}, filters))
+		assert.False(t, api.MatchesCustomFields(nil, filters))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, query := range []url.Values{{"cf.shoe_size": {"42"}}, {"cf.locker": {"twelve"}}, {"cf.uniform_size": {"XXL"}}} {
+			_, err := api.ParseCustomFieldFilters(query, employeeFields)
+			assert.Error(t, err, query.Encode())
+		}
+	})
+}
+
+func TestListCustomFieldsHandler(t *testing.T) {
+	env := setupCustomFieldEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	route := "/:org/custom-fields"
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.FieldStore.On("ListFieldDefinitions", orgID, "item").Return(employeeFields[:1], nil).Once()
+
+		w := jobRequest("GET", route, "/"+orgID.String()+"/custom-fields?entity_type=item", []gin.HandlerFunc{authMiddleware(manager), env.Handler.ListCustomFieldsHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "uniform_size")
+		env.FieldStore.AssertExpectations(t)
+	})
+
+	t.Run("InvalidEntityType", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("GET", route, "/"+orgID.String()+"/custom-fields?entity_type=shift", []gin.HandlerFunc{authMiddleware(manager), env.Handler.ListCustomFieldsHandler}, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Forbidden_Employee", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("GET", route, "/"+orgID.String()+"/custom-fields", []gin.HandlerFunc{authMiddleware(employee), env.Handler.ListCustomFieldsHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestCreateCustomFieldHandler(t *testing.T) {
+	env := setupCustomFieldEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route, path := "/:org/custom-fields", "/"+orgID.String()+"/custom-fields"
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.FieldStore.On("CreateFieldDefinition", orgID, mock.MatchedBy(func(d *database.CustomFieldDefinition) bool {
+			return d.EntityType == "employee" && d.Key == "uniform_size" && d.Label == "Uniform size" && len(d.Options) == 3 && d.Required
+		})).Return(nil).Once()
+
+		body := gin.H{"entity_type": "employee", "key": "uniform_size", "label": " Uniform size ", "field_type": "select", "options": []string{"S", "M", "L"}, "required": true}
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.CreateCustomFieldHandler}, body)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		env.FieldStore.AssertExpectations(t)
+	})
+
+	t.Run("Conflict_Exists", func(t *testing.T) {
+		env.ResetMocks()
+		env.FieldStore.On("CreateFieldDefinition", orgID, mock.Anything).Return(database.ErrCustomFieldExists).Once()
+
+		body := gin.H{"entity_type": "item", "key": "allergens", "label": "Allergens", "field_type": "text"}
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.CreateCustomFieldHandler}, body)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for name, body := range map[string]gin.H{
+			"Key":             {"entity_type": "item", "key": "Allergens!", "label": "Allergens", "field_type": "text"},
+			"EntityType":      {"entity_type": "shift", "key": "allergens", "label": "Allergens", "field_type": "text"},
+			"FieldType":       {"entity_type": "item", "key": "allergens", "label": "Allergens", "field_type": "list"},
+			"SelectNoOptions": {"entity_type": "order", "key": "table", "label": "Table", "field_type": "select"},
+			"DuplicateOption": {"entity_type": "order", "key": "table", "label": "Table", "field_type": "select", "options": []string{"A", "A"}},
+			"OptionsOnText":   {"entity_type": "item", "key": "allergens", "label": "Allergens", "field_type": "text", "options": []string{"nuts"}},
+		} {
+			env.ResetMocks()
+			w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.CreateCustomFieldHandler}, body)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, name)
+			env.FieldStore.AssertNotCalled(t, "CreateFieldDefinition", mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("Forbidden_Manager", func(t *testing.T) {
+		env.ResetMocks()
+
+		body := gin.H{"entity_type": "item", "key": "allergens", "label": "Allergens", "field_type": "text"}
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.CreateCustomFieldHandler}, body)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestDeleteCustomFieldHandler(t *testing.T) {
+	env := setupCustomFieldEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	fieldID := uuid.New()
+	route, path := "/:org/custom-fields/:id", "/"+orgID.String()+"/custom-fields/"+fieldID.String()
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.FieldStore.On("DeleteFieldDefinition", orgID, fieldID).Return(nil).Once()
+
+		w := jobRequest("DELETE", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.DeleteCustomFieldHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.FieldStore.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.FieldStore.On("DeleteFieldDefinition", orgID, fieldID).Return(sql.ErrNoRows).Once()
+
+		w := jobRequest("DELETE", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.DeleteCustomFieldHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestSetCustomFieldValuesHandler(t *testing.T) {
+	env := setupCustomFieldEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	employeeID := uuid.New()
+	route, path := "/:org/custom-fields/:entity/:id", "/"+orgID.String()+"/custom-fields/employee/"+employeeID.String()
+
+	t.Run("MergesValues", func(t *testing.T) {
+		env.ResetMocks()
+		env.FieldStore.On("ListFieldDefinitions", orgID, "employee").Return(employeeFields, nil).Once()
+		env.FieldStore.On("GetCustomFieldValues", orgID, "employee", []uuid.UUID{employeeID}).
+			Return(map[uuid.UUID]map[string]any{employeeID: {"uniform_size": "S", "nickname": "Sam"}}, nil).Once()
+		// the size changes, the nickname is cleared and the locker added
+		merged := map[string]any{"uniform_size": "M", "locker": float64(12)}
+		env.FieldStore.On("SetCustomFieldValues", orgID, "employee", employeeID, merged).Return(nil).Once()
+
+		body := gin.H{"values": gin.H{"uniform_size": "M", "nickname": nil, "locker": 12}}
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.SetCustomFieldValuesHandler}, body)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data map[string]any `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, merged, response.Data)
+		env.FieldStore.AssertExpectations(t)
+	})
+
+	t.Run("Invalid_RequiredMissing", func(t *testing.T) {
+		env.ResetMocks()
+		env.FieldStore.On("ListFieldDefinitions", orgID, "employee").Return(employeeFields, nil).Once()
+		env.FieldStore.On("GetCustomFieldValues", orgID, "employee", []uuid.UUID{employeeID}).Return(map[uuid.UUID]map[string]any{}, nil).Once()
+
+		body := gin.H{"values": gin.H{"locker": 12}}
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.SetCustomFieldValuesHandler}, body)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "uniform_size is required")
+		env.FieldStore.AssertNotCalled(t, "SetCustomFieldValues", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("NotFound_OtherOrganization", func(t *testing.T) {
+		env.ResetMocks()
+		env.FieldStore.On("ListFieldDefinitions", orgID, "employee").Return(employeeFields, nil).Once()
+		env.FieldStore.On("GetCustomFieldValues", orgID, "employee", []uuid.UUID{employeeID}).Return(map[uuid.UUID]map[string]any{}, nil).Once()
+		env.FieldStore.On("SetCustomFieldValues", orgID, "employee", employeeID, mock.Anything).Return(sql.ErrNoRows).Once()
+
+		body := gin.H{"values": gin.H{"uniform_size": "L"}}
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.SetCustomFieldValuesHandler}, body)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("InvalidEntity", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("PUT", route, "/"+orgID.String()+"/custom-fields/shift/"+employeeID.String(),
+			[]gin.HandlerFunc{authMiddleware(manager), env.Handler.SetCustomFieldValuesHandler}, gin.H{"values": gin.H{}})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Failure_DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.FieldStore.On("ListFieldDefinitions", orgID, "employee").Return(nil, errors.New("db error")).Once()
+
+		body := gin.H{"values": gin.H{"uniform_size": "L"}}
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.SetCustomFieldValuesHandler}, body)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("Forbidden_Employee", func(t *testing.T) {
+		env.ResetMocks()
+
+		body := gin.H{"values": gin.H{"uniform_size": "L"}}
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.SetCustomFieldValuesHandler}, body)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
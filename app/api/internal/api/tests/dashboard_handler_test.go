@@ -1,7 +1,11 @@
 package api
 
 import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
 	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -14,6 +18,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 type DashboardTestEnv struct {
@@ -24,6 +29,9 @@ type DashboardTestEnv struct {
 	OrderStore          *MockOrderStore
 	CampaignStore       *MockCampaignStore
 	DemandStore         *MockDemandStore
+	ExternalEventStore  *MockExternalEventStore
+	ExceptionStore      *MockOperatingHoursExceptionStore
+	StatusStore         *MockStatusStore
 	Handler             *api.DashboardHandler
 }
 
@@ -36,9 +44,13 @@ func setupDashboardEnv() *DashboardTestEnv {
 	orderStore := new(MockOrderStore)
 	campaignStore := new(MockCampaignStore)
 	demandStore := new(MockDemandStore)
+	externalEventStore := new(MockExternalEventStore)
+	exceptionStore := new(MockOperatingHoursExceptionStore)
+	statusStore := new(MockStatusStore)
+	statusStore.AllowEvents()
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
-	handler := api.NewDashboardHandler(orgStore, rulesStore, opHoursStore, orderStore, campaignStore, demandStore, logger)
+	handler := api.NewDashboardHandler(orgStore, rulesStore, opHoursStore, orderStore, campaignStore, demandStore, externalEventStore, exceptionStore, statusStore, logger)
 
 	return &DashboardTestEnv{
 		Router:              gin.New(),
@@ -48,6 +60,9 @@ func setupDashboardEnv() *DashboardTestEnv {
 		OrderStore:          orderStore,
 		CampaignStore:       campaignStore,
 		DemandStore:         demandStore,
+		ExternalEventStore:  externalEventStore,
+		ExceptionStore:      exceptionStore,
+		StatusStore:         statusStore,
 		Handler:             handler,
 	}
 }
@@ -65,6 +80,13 @@ func (env *DashboardTestEnv) ResetMocks() {
 	env.CampaignStore.Calls = nil
 	env.DemandStore.ExpectedCalls = nil
 	env.DemandStore.Calls = nil
+	env.ExternalEventStore.ExpectedCalls = nil
+	env.ExternalEventStore.Calls = nil
+	env.ExceptionStore.ExpectedCalls = nil
+	env.ExceptionStore.Calls = nil
+	env.StatusStore.ExpectedCalls = nil
+	env.StatusStore.Calls = nil
+	env.StatusStore.AllowEvents()
 }
 
 // --- GetDemandHeatMap ---
@@ -86,6 +108,8 @@ func TestGetDemandHeatMapHandler(t *testing.T) {
 			},
 		}
 		env.DemandStore.On("GetLatestDemandHeatMap", orgID).Return(demandResp, nil).Once()
+		concert := database.ExternalEvent{ID: uuid.New(), Name: "Stadium Concert", Category: database.ExternalEventConcert, StartTime: time.Now(), EndTime: time.Now().Add(time.Hour)}
+		env.ExternalEventStore.On("GetEventsBetween", orgID, mock.Anything, mock.Anything).Return([]database.ExternalEvent{concert}, nil).Once()
 
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/dashboard/demand", nil)
@@ -93,7 +117,9 @@ func TestGetDemandHeatMapHandler(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, w.Code)
 		assert.Contains(t, w.Body.String(), "Test Restaurant")
+		assert.Contains(t, w.Body.String(), "Stadium Concert")
 		env.DemandStore.AssertExpectations(t)
+		env.ExternalEventStore.AssertExpectations(t)
 	})
 
 	t.Run("Success_Manager", func(t *testing.T) {
@@ -169,6 +195,109 @@ func TestGetDemandHeatMapHandler(t *testing.T) {
 	})
 }
 
+// --- ExportDemandHeatMap ---
+
+func TestExportDemandHeatMapHandler(t *testing.T) {
+	env := setupDashboardEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+
+	env.Router.GET("/:org/dashboard/demand/latest/export", authMiddleware(admin), env.Handler.ExportDemandHeatMapHandler)
+
+	monday := time.Date(2026, 10, 19, 0, 0, 0, 0, time.Local)
+	demandResp := &database.DemandPredictResponse{
+		RestaurantName: "Test Restaurant",
+		Days: []database.PredictionDay{
+			{Day: "monday", Date: monday, Hours: []database.PredictionHour{
+				{HourNo: 18, OrderCount: 12, ItemCount: 30},
+				{HourNo: 19, OrderCount: 20, ItemCount: 45},
+			}},
+		},
+	}
+	concert := database.ExternalEvent{ID: uuid.New(), Name: "Stadium Concert", StartTime: monday.Add(19 * time.Hour), EndTime: monday.Add(22 * time.Hour)}
+
+	t.Run("Success_CSV", func(t *testing.T) {
+		env.ResetMocks()
+		env.DemandStore.On("GetLatestDemandHeatMap", orgID).Return(demandResp, nil).Once()
+		env.ExternalEventStore.On("GetEventsBetween", orgID, monday, monday.AddDate(0, 0, 1)).Return([]database.ExternalEvent{concert}, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/dashboard/demand/latest/export?format=csv", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "demand-2026-10-19.csv")
+		assert.Equal(t, "date,day,hour,predicted_orders,predicted_items,events\n"+
+			"2026-10-19,monday,18:00,12,30,\n"+
+			"2026-10-19,monday,19:00,20,45,Stadium Concert\n", w.Body.String())
+	})
+
+	t.Run("Success_XLSX", func(t *testing.T) {
+		env.ResetMocks()
+		env.DemandStore.On("GetLatestDemandHeatMap", orgID).Return(demandResp, nil).Once()
+		env.ExternalEventStore.On("GetEventsBetween", orgID, mock.Anything, mock.Anything).Return([]database.ExternalEvent{concert}, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/dashboard/demand/latest/export?format=xlsx", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "demand-2026-10-19.xlsx")
+
+		workbook, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+		assert.NoError(t, err)
+		var sheet string
+		for _, file := range workbook.File {
+			if file.Name == "xl/worksheets/sheet1.xml" {
+				f, _ := file.Open()
+				content, _ := io.ReadAll(f)
+				f.Close()
+				sheet = string(content)
+			}
+		}
+		assert.Contains(t, sheet, "<is><t xml:space=\"preserve\">predicted_items</t></is>")
+		// counts are numeric cells so they can be summed
+		assert.Contains(t, sheet, `<c r="E3"><v>45</v></c>`)
+		assert.Contains(t, sheet, "Stadium Concert")
+	})
+
+	t.Run("Failure_InvalidFormat", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/dashboard/demand/latest/export?format=pdf", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		env.DemandStore.AssertNotCalled(t, "GetLatestDemandHeatMap", mock.Anything)
+	})
+
+	t.Run("Failure_NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.DemandStore.On("GetLatestDemandHeatMap", orgID).Return(nil, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/dashboard/demand/latest/export", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+		router := gin.New()
+		router.GET("/:org/dashboard/demand/latest/export", authMiddleware(employee), env.Handler.ExportDemandHeatMapHandler)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/dashboard/demand/latest/export", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
 // --- PredictDemandHeatMap (partial - validation & data-fetch tests, skipping ML call) ---
 
 func TestPredictDemandHeatMapHandler(t *testing.T) {
@@ -284,4 +413,116 @@ func TestPredictDemandHeatMapHandler(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, w.Code)
 		assert.Contains(t, w.Body.String(), "no campaigns found")
 	})
+
+	t.Run("Failure_InvalidPayload", func(t *testing.T) {
+		env.ResetMocks()
+		// Missing location and an unknown weekday should be rejected before calling ML
+		org := &database.Organization{ID: orgID, Name: "Test Org", Type: "restaurant"}
+		rules := &database.OrganizationRules{OrganizationID: orgID}
+		opHours := []database.OperatingHours{{Weekday: "funday", OpeningTime: "09:00", ClosingTime: "17:00"}}
+		orders := []database.Order{{OrderID: uuid.New(), OrderType: "dine-in", OrderStatus: "completed", CreateTime: time.Now()}}
+		campaigns := []database.Campaign{}
+		env.OrgStore.On("GetOrganizationByID", orgID).Return(org, nil).Once()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(rules, nil).Once()
+		env.OperatingHoursStore.On("GetOperatingHours", orgID).Return(opHours, nil).Once()
+		env.OrderStore.On("GetAllOrders", orgID).Return(orders, nil).Once()
+		env.CampaignStore.On("GetAllCampaigns", orgID).Return(campaigns, nil).Once()
+		env.ExternalEventStore.On("GetEventsBetween", orgID, mock.Anything, mock.Anything).Return([]database.ExternalEvent{}, nil).Once()
+		env.ExceptionStore.On("GetExceptionsBetween", orgID, mock.Anything, mock.Anything).Return([]database.OperatingHoursException{}, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/dashboard/demand/predict", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		body := w.Body.String()
+		assert.Contains(t, body, "demand input is incomplete or invalid")
+		assert.Contains(t, body, "organization longitude is required")
+		assert.Contains(t, body, "invalid weekday")
+	})
+
+	t.Run("Success_ClosedDaysExcluded", func(t *testing.T) {
+		env.ResetMocks()
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		tomorrow := today.AddDate(0, 0, 1)
+
+		var sent api.DemandPredictionRequest
+		mlServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewDecoder(r.Body).Decode(&sent)
+			w.Write([]byte(`{"restaurant_name": "Test Org", "prediction_period": "7 days", "days": [
+				{"day_name": "today", "date": "` + today.Format(time.DateOnly) + `", "hours": [{"hour": 10, "order_count": 4, "item_count": 9}]},
+				{"day_name": "tomorrow", "date": "` + tomorrow.Format(time.DateOnly) + `", "hours": [{"hour": 10, "order_count": 5, "item_count": 11}]}
+			]}`))
+		}))
+		defer mlServer.Close()
+		t.Setenv("ML_URL", mlServer.URL)
+
+		lat, long := 30.0, 31.0
+		org := &database.Organization{ID: orgID, Name: "Test Org", Type: "restaurant", Location: database.Location{Latitude: &lat, Longitude: &long}}
+		rules := &database.OrganizationRules{OrganizationID: orgID}
+		opHours := []database.OperatingHours{{Weekday: "monday", OpeningTime: "09:00", ClosingTime: "17:00"}}
+		orders := []database.Order{{OrderID: uuid.New(), OrderType: "dine-in", OrderStatus: "completed", CreateTime: time.Now()}}
+		env.OrgStore.On("GetOrganizationByID", orgID).Return(org, nil).Once()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(rules, nil).Once()
+		env.OperatingHoursStore.On("GetOperatingHours", orgID).Return(opHours, nil).Once()
+		env.OrderStore.On("GetAllOrders", orgID).Return(orders, nil).Once()
+		env.CampaignStore.On("GetAllCampaigns", orgID).Return([]database.Campaign{}, nil).Once()
+		env.ExternalEventStore.On("GetEventsBetween", orgID, mock.Anything, mock.Anything).Return([]database.ExternalEvent{}, nil).Once()
+		env.ExceptionStore.On("GetExceptionsBetween", orgID, today, today.AddDate(0, 0, 6)).
+			Return([]database.OperatingHoursException{{Date: tomorrow}}, nil).Once()
+		env.DemandStore.On("StoreDemandHeatMap", orgID, mock.MatchedBy(func(demand database.DemandPredictResponse) bool {
+			return len(demand.Days) == 1 && demand.Days[0].Day == "today"
+		})).Return(nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/dashboard/demand/predict", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{tomorrow.Format(time.DateOnly)}, sent.ClosedDates)
+		if assert.Len(t, sent.ChannelMix, 1) {
+			assert.Equal(t, map[string]float64{database.OrderChannelDirect: 100}, sent.ChannelMix[0].Channels)
+		}
+		assert.NotContains(t, w.Body.String(), `"tomorrow"`)
+		env.DemandStore.AssertExpectations(t)
+	})
+}
+
+func TestChannelMixPerSlot(t *testing.T) {
+	friday := time.Date(2025, 6, 6, 19, 15, 0, 0, time.UTC)
+	sunday := time.Date(2025, 6, 8, 12, 5, 0, 0, time.UTC)
+	order := func(at time.Time, orderType, channel string) database.Order {
+		return database.Order{OrderID: uuid.New(), CreateTime: at, OrderType: orderType, Channel: channel}
+	}
+
+	t.Run("SharesPerSlot", func(t *testing.T) {
+		mix := api.ChannelMixPerSlot([]database.Order{
+			order(friday, "delivery", database.OrderChannelUberEats),
+			order(friday.Add(20*time.Minute), "delivery", database.OrderChannelTalabat),
+			order(friday.Add(30*time.Minute), "delivery", database.OrderChannelApp),
+			order(friday.AddDate(0, 0, -7), "dine in", ""),
+			order(sunday, "takeaway", database.OrderChannelPhone),
+		})
+
+		// Sunday comes first, Friday's orders of two weeks share a slot
+		if assert.Len(t, mix, 2) {
+			assert.Equal(t, 0, mix[0].Weekday)
+			assert.Equal(t, 12, mix[0].Hour)
+			assert.Equal(t, map[string]float64{database.OrderChannelPhone: 100}, mix[0].Channels)
+			assert.Equal(t, 0.0, mix[0].DeliveryShare)
+
+			assert.Equal(t, 5, mix[1].Weekday)
+			assert.Equal(t, 19, mix[1].Hour)
+			assert.Equal(t, 4, mix[1].Orders)
+			assert.Equal(t, 25.0, mix[1].Channels[database.OrderChannelDirect])
+			assert.Equal(t, 25.0, mix[1].Channels[database.OrderChannelUberEats])
+			assert.Equal(t, 75.0, mix[1].DeliveryShare)
+			assert.Equal(t, 50.0, mix[1].PlatformShare)
+		}
+	})
+
+	t.Run("NoOrders", func(t *testing.T) {
+		assert.Empty(t, api.ChannelMixPerSlot(nil))
+	})
 }
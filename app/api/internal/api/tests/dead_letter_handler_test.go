@@ -0,0 +1,312 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type DeadLetterTestEnv struct {
+	Store         *MockDeadLetterStore
+	PlatformStore *MockDeliveryPlatformStore
+	Handler       *api.DeadLetterHandler
+	// Applied holds the events of every replay
+	Applied [][]service.PlatformEvent
+	Result  service.PlatformSyncResult
+}
+
+func setupDeadLetterEnv() *DeadLetterTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	env := &DeadLetterTestEnv{
+		Store:         new(MockDeadLetterStore),
+		PlatformStore: new(MockDeliveryPlatformStore),
+	}
+	apply := func(integration database.DeliveryPlatformIntegration, events []service.PlatformEvent) service.PlatformSyncResult {
+		env.Applied = append(env.Applied, events)
+		return env.Result
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	env.Handler = api.NewDeadLetterHandler(env.Store, env.PlatformStore, apply, logger)
+	return env
+}
+
+func TestGetDeadLettersHandler(t *testing.T) {
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/integrations/dead-letters"
+	path := "/" + orgID.String() + "/integrations/dead-letters"
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupDeadLetterEnv()
+		env.Store.On("ListDeadLetters", orgID, database.DeadLetterFilter{Source: "talabat", Status: "pending", Limit: 10}).
+			Return([]database.DeadLetter{{ID: uuid.New(), Source: "talabat", Via: database.DeadLetterViaPoll, Error: "invalid delivery platform payload", Status: "pending"}}, nil).Once()
+
+		w := jobRequest("GET", route, path+"?source=talabat&status=pending&limit=10", []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetDeadLettersHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"via":"poll"`)
+		assert.NotContains(t, w.Body.String(), `"payload"`)
+		env.Store.AssertExpectations(t)
+	})
+
+	t.Run("InvalidFilters", func(t *testing.T) {
+		env := setupDeadLetterEnv()
+		handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetDeadLettersHandler}
+
+		for _, query := range []string{"?source=just_eat", "?status=discarded", "?limit=500"} {
+			w := jobRequest("GET", route, path+query, handlers, nil)
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+		env.Store.AssertNotCalled(t, "ListDeadLetters", mock.Anything, mock.Anything)
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		env := setupDeadLetterEnv()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetDeadLettersHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env := setupDeadLetterEnv()
+		env.Store.On("ListDeadLetters", orgID, mock.Anything).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetDeadLettersHandler}, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestGetDeadLetterHandler(t *testing.T) {
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	id := uuid.New()
+	route := "/:org/integrations/dead-letters/:id"
+	path := "/" + orgID.String() + "/integrations/dead-letters/"
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupDeadLetterEnv()
+		env.Store.On("GetDeadLetter", orgID, id).Return(&database.DeadLetter{ID: id, Source: "talabat", Payload: `{"token": "tb-4"}`, Status: "pending"}, nil).Once()
+
+		w := jobRequest("GET", route, path+id.String(), []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetDeadLetterHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"payload":"{\"token\": \"tb-4\"}"`)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env := setupDeadLetterEnv()
+		env.Store.On("GetDeadLetter", orgID, id).Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("GET", route, path+id.String(), []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetDeadLetterHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		env := setupDeadLetterEnv()
+
+		w := jobRequest("GET", route, path+"not-a-uuid", []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetDeadLetterHandler}, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestUpdateDeadLetterHandler(t *testing.T) {
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	id := uuid.New()
+	route := "/:org/integrations/dead-letters/:id"
+	path := "/" + orgID.String() + "/integrations/dead-letters/" + id.String()
+	payload := `{"token": "tb-4", "price": {"grandTotal": "12.00"}}`
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupDeadLetterEnv()
+		env.Store.On("UpdatePayload", orgID, id, payload, admin.ID, mock.AnythingOfType("time.Time")).
+			Return(&database.DeadLetter{ID: id, Payload: payload, Status: "pending", EditedBy: &admin.ID}, nil).Once()
+
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.UpdateDeadLetterHandler}, map[string]any{"payload": payload})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.Store.AssertExpectations(t)
+	})
+
+	t.Run("NotPending", func(t *testing.T) {
+		env := setupDeadLetterEnv()
+		env.Store.On("UpdatePayload", orgID, id, payload, admin.ID, mock.Anything).Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.UpdateDeadLetterHandler}, map[string]any{"payload": payload})
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("MissingPayload", func(t *testing.T) {
+		env := setupDeadLetterEnv()
+
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.UpdateDeadLetterHandler}, map[string]any{})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("ManagerForbidden", func(t *testing.T) {
+		env := setupDeadLetterEnv()
+		manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.UpdateDeadLetterHandler}, map[string]any{"payload": payload})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestReplayDeadLetterHandler(t *testing.T) {
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	id := uuid.New()
+	route := "/:org/integrations/dead-letters/:id/replay"
+	path := "/" + orgID.String() + "/integrations/dead-letters/" + id.String() + "/replay"
+	integration := &database.DeliveryPlatformIntegration{OrganizationID: orgID, Platform: "talabat", Enabled: true}
+	fixed := `{"token": "tb-3", "status": "PICKED_UP", "expeditionType": "delivery", "updatedAt": "2026-10-16T12:10:00Z"}`
+	pending := func(payload string) *database.DeadLetter {
+		return &database.DeadLetter{ID: id, Source: "talabat", Via: database.DeadLetterViaWebhook, Payload: payload, Status: database.DeadLetterPending}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupDeadLetterEnv()
+		env.Result = service.PlatformSyncResult{Updates: 1}
+		env.Store.On("GetDeadLetter", orgID, id).Return(pending(fixed), nil).Once()
+		env.PlatformStore.On("GetIntegration", orgID, "talabat").Return(integration, nil).Once()
+		env.Store.On("MarkReplayed", orgID, id, admin.ID, mock.AnythingOfType("time.Time")).
+			Return(&database.DeadLetter{ID: id, Status: database.DeadLetterReplayed, Replays: 1}, nil).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.ReplayDeadLetterHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"replayed"`)
+		assert.Contains(t, w.Body.String(), `"updates":1`)
+		if assert.Len(t, env.Applied, 1) {
+			assert.Equal(t, "tb-3", env.Applied[0][0].ExternalOrderID)
+		}
+		env.Store.AssertExpectations(t)
+	})
+
+	t.Run("StillInvalid", func(t *testing.T) {
+		env := setupDeadLetterEnv()
+		env.Store.On("GetDeadLetter", orgID, id).Return(pending(`[`), nil).Once()
+		env.PlatformStore.On("GetIntegration", orgID, "talabat").Return(integration, nil).Once()
+		env.Store.On("RecordFailedReplay", orgID, id, mock.AnythingOfType("string")).Return(pending(`[`), nil).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.ReplayDeadLetterHandler}, nil)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Empty(t, env.Applied)
+		env.Store.AssertNotCalled(t, "MarkReplayed", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("StoreErrorStaysPending", func(t *testing.T) {
+		env := setupDeadLetterEnv()
+		env.Result = service.PlatformSyncResult{Failed: 1}
+		env.Store.On("GetDeadLetter", orgID, id).Return(pending(fixed), nil).Once()
+		env.PlatformStore.On("GetIntegration", orgID, "talabat").Return(integration, nil).Once()
+		env.Store.On("RecordFailedReplay", orgID, id, "1 events could not be stored").Return(pending(fixed), nil).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.ReplayDeadLetterHandler}, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		env.Store.AssertExpectations(t)
+	})
+
+	t.Run("AlreadyReplayed", func(t *testing.T) {
+		env := setupDeadLetterEnv()
+		replayed := pending(fixed)
+		replayed.Status = database.DeadLetterReplayed
+		env.Store.On("GetDeadLetter", orgID, id).Return(replayed, nil).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.ReplayDeadLetterHandler}, nil)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Empty(t, env.Applied)
+	})
+
+	t.Run("PlatformDisconnected", func(t *testing.T) {
+		env := setupDeadLetterEnv()
+		env.Store.On("GetDeadLetter", orgID, id).Return(pending(fixed), nil).Once()
+		env.PlatformStore.On("GetIntegration", orgID, "talabat").Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.ReplayDeadLetterHandler}, nil)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Empty(t, env.Applied)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env := setupDeadLetterEnv()
+		env.Store.On("GetDeadLetter", orgID, id).Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.ReplayDeadLetterHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("ManagerForbidden", func(t *testing.T) {
+		env := setupDeadLetterEnv()
+		manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.ReplayDeadLetterHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestGetDeadLetterMetricsHandler(t *testing.T) {
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/integrations/dead-letters/metrics"
+	path := "/" + orgID.String() + "/integrations/dead-letters/metrics"
+	platforms := []string{"uber_eats", "deliveroo", "talabat"}
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupDeadLetterEnv()
+		env.Store.On("GetFailureRates", orgID, platforms, mock.MatchedBy(func(since time.Time) bool {
+			return time.Since(since) > 29*24*time.Hour && time.Since(since) < 31*24*time.Hour
+		})).Return([]database.IngestionFailureRate{{Source: "talabat", Received: 40, Failed: 2, FailureRate: 5}}, nil).Once()
+
+		w := jobRequest("GET", route, path+"?days=30", []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetDeadLetterMetricsHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"days":30`)
+		assert.Contains(t, w.Body.String(), `"failure_rate_percent":5`)
+		env.Store.AssertExpectations(t)
+	})
+
+	t.Run("InvalidDays", func(t *testing.T) {
+		env := setupDeadLetterEnv()
+
+		w := jobRequest("GET", route, path+"?days=365", []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetDeadLetterMetricsHandler}, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env := setupDeadLetterEnv()
+		env.Store.On("GetFailureRates", orgID, platforms, mock.Anything).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetDeadLetterMetricsHandler}, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,428 @@
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testWebhookSecret = "whsec_0123456789abcdef"
+
+type DeliveryPlatformTestEnv struct {
+	PlatformStore   *MockDeliveryPlatformStore
+	OrderStore      *MockOrderStore
+	StatusStore     *MockStatusStore
+	DeadLetterStore *MockDeadLetterStore
+	Handler         *api.DeliveryPlatformHandler
+}
+
+func setupDeliveryPlatformEnv(t *testing.T) *DeliveryPlatformTestEnv {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("PUBLIC_API_URL", "https://api.example.com")
+
+	platformStore := new(MockDeliveryPlatformStore)
+	orderStore := new(MockOrderStore)
+	statusStore := new(MockStatusStore)
+	deadLetterStore := new(MockDeadLetterStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &DeliveryPlatformTestEnv{
+		PlatformStore:   platformStore,
+		OrderStore:      orderStore,
+		StatusStore:     statusStore,
+		DeadLetterStore: deadLetterStore,
+		Handler:         api.NewDeliveryPlatformHandler(platformStore, orderStore, statusStore, deadLetterStore, logger),
+	}
+}
+
+func (env *DeliveryPlatformTestEnv) ResetMocks() {
+	env.PlatformStore.ExpectedCalls = nil
+	env.PlatformStore.Calls = nil
+	env.OrderStore.ExpectedCalls = nil
+	env.OrderStore.Calls = nil
+	env.StatusStore.ExpectedCalls = nil
+	env.StatusStore.Calls = nil
+	env.DeadLetterStore.ExpectedCalls = nil
+	env.DeadLetterStore.Calls = nil
+}
+
+// webhookRequest sends a raw webhook body with the headers a platform would set
+func webhookRequest(handler gin.HandlerFunc, path string, body string, headers map[string]string) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.POST("/webhooks/:platform/:org", handler)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func signWebhook(message string) string {
+	mac := hmac.New(sha256.New, []byte(testWebhookSecret))
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestReceiveDeliveryWebhook(t *testing.T) {
+	env := setupDeliveryPlatformEnv(t)
+	orgID := uuid.New()
+	burgerID, friesID := uuid.New(), uuid.New()
+	items := []database.Item{{ItemID: burgerID, Name: "Burger"}, {ItemID: friesID, Name: "Fries"}}
+	integration := func(platform string) *database.DeliveryPlatformIntegration {
+		return &database.DeliveryPlatformIntegration{OrganizationID: orgID, Platform: platform, StoreID: "store-1", WebhookSecret: testWebhookSecret, Enabled: true}
+	}
+	path := func(platform string) string { return "/webhooks/" + platform + "/" + orgID.String() }
+
+	uberOrder := `{
+		"event_type": "orders.notification",
+		"event_time": "2026-10-16T12:00:00Z",
+		"order": {
+			"id": "uber-1",
+			"type": "DELIVERY_BY_UBER",
+			"state": "ACCEPTED",
+			"placed_at": "2026-10-16T11:58:00Z",
+			"payment": {"total": 2450, "discount": 300},
+			"items": [
+				{"external_id": "` + burgerID.String() + `", "title": "Classic", "quantity": 2, "price": 2000},
+				{"external_id": "", "title": " fries ", "quantity": 1, "price": 450},
+				{"external_id": "", "title": "Milkshake", "quantity": 1, "price": 500}
+			]
+		}
+	}`
+
+	t.Run("UberEatsNewOrder", func(t *testing.T) {
+		env.ResetMocks()
+		env.StatusStore.AllowEvents()
+		env.PlatformStore.On("GetIntegration", orgID, "uber_eats").Return(integration("uber_eats"), nil).Once()
+		env.PlatformStore.On("MarkEventReceived", orgID, "uber_eats", mock.Anything).Return(nil).Once()
+		env.OrderStore.On("GetAllItems", orgID).Return(items, nil).Once()
+		env.OrderStore.On("StoreNestedOrder", orgID, mock.MatchedBy(func(order *database.Order) bool {
+			return order.OrderID == service.PlatformOrderID(orgID, "uber_eats", "uber-1") &&
+				order.Channel == database.OrderChannelUberEats &&
+				order.OrderType == "delivery" && order.OrderStatus == "incompleted" &&
+				*order.TotalAmount == 24.5 && *order.DiscountAmount == 3 &&
+				// matched on the reference, then on the name
+				len(order.OrderItems) == 2 && order.OrderItems[0].ItemID == burgerID && *order.OrderItems[0].Quantity == 2 &&
+				order.OrderItems[1].ItemID == friesID && *order.OrderItems[1].TotalPrice == 4.5 &&
+				order.DeliveryStatus == nil
+		})).Return(nil).Once()
+
+		w := webhookRequest(env.Handler.ReceiveWebhookHandler, path("uber_eats"), uberOrder, map[string]string{"X-Uber-Signature": signWebhook(uberOrder)})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"orders":1`)
+		assert.Contains(t, w.Body.String(), `"unmatched_items":1`)
+		env.OrderStore.AssertExpectations(t)
+		env.PlatformStore.AssertExpectations(t)
+		assert.Len(t, env.StatusStore.RecordedEvents(database.StatusEventIngestion), 1)
+	})
+
+	t.Run("InvalidSignature", func(t *testing.T) {
+		env.ResetMocks()
+		env.PlatformStore.On("GetIntegration", orgID, "uber_eats").Return(integration("uber_eats"), nil)
+
+		for _, signature := range []string{"", "not-hex", signWebhook(uberOrder + " ")} {
+			w := webhookRequest(env.Handler.ReceiveWebhookHandler, path("uber_eats"), uberOrder, map[string]string{"X-Uber-Signature": signature})
+			assert.Equal(t, http.StatusUnauthorized, w.Code, signature)
+		}
+		env.OrderStore.AssertNotCalled(t, "StoreNestedOrder", mock.Anything, mock.Anything)
+	})
+
+	t.Run("DeliverooRiderDelivered", func(t *testing.T) {
+		env.ResetMocks()
+		env.StatusStore.AllowEvents()
+		body := `{"event": "rider.status_update", "body": {"order": {"id": "roo-9", "status": "accepted"}, "rider": {"status": "rider_delivered", "at": "2026-10-16T12:30:00Z", "latitude": 51.5, "longitude": -0.12}}}`
+		deliveredAt := time.Date(2026, 10, 16, 12, 30, 0, 0, time.UTC)
+		env.PlatformStore.On("GetIntegration", orgID, "deliveroo").Return(integration("deliveroo"), nil).Once()
+		env.PlatformStore.On("MarkEventReceived", orgID, "deliveroo", mock.Anything).Return(nil).Once()
+		env.OrderStore.On("UpdateOrderProgress", orgID, service.PlatformOrderID(orgID, "deliveroo", "roo-9"), "completed", mock.MatchedBy(func(delivery *database.OrderDelivery) bool {
+			return delivery.DeliveryStatus == "delivered" && delivery.DeliveredTime.Equal(deliveredAt) && *delivery.DeliveryLocation.Latitude == 51.5
+		})).Return(nil).Once()
+
+		w := webhookRequest(env.Handler.ReceiveWebhookHandler, path("deliveroo"), body, map[string]string{
+			"X-Deliveroo-Sequence-Guid": "guid-1",
+			"X-Deliveroo-Hmac-Sha256":   signWebhook("guid-1 " + body),
+		})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"updates":1`)
+		env.OrderStore.AssertExpectations(t)
+		env.OrderStore.AssertNotCalled(t, "GetAllItems", mock.Anything)
+	})
+
+	t.Run("DuplicateOrderUpdatesStatus", func(t *testing.T) {
+		env.ResetMocks()
+		env.StatusStore.AllowEvents()
+		env.PlatformStore.On("GetIntegration", orgID, "uber_eats").Return(integration("uber_eats"), nil).Once()
+		env.PlatformStore.On("MarkEventReceived", orgID, "uber_eats", mock.Anything).Return(nil).Once()
+		env.OrderStore.On("GetAllItems", orgID).Return(items, nil).Once()
+		env.OrderStore.On("StoreNestedOrder", orgID, mock.Anything).Return(database.ErrDuplicateOrder).Once()
+		env.OrderStore.On("UpdateOrderProgress", orgID, service.PlatformOrderID(orgID, "uber_eats", "uber-1"), "incompleted", (*database.OrderDelivery)(nil)).Return(nil).Once()
+
+		w := webhookRequest(env.Handler.ReceiveWebhookHandler, path("uber_eats"), uberOrder, map[string]string{"X-Uber-Signature": signWebhook(uberOrder)})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"orders":0,"updates":1`)
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("TalabatUnknownOrder", func(t *testing.T) {
+		env.ResetMocks()
+		env.StatusStore.AllowEvents()
+		body := `{"token": "tb-3", "status": "PICKED_UP", "expeditionType": "delivery", "updatedAt": "2026-10-16T12:10:00Z"}`
+		env.PlatformStore.On("GetIntegration", orgID, "talabat").Return(integration("talabat"), nil).Once()
+		env.PlatformStore.On("MarkEventReceived", orgID, "talabat", mock.Anything).Return(nil).Once()
+		env.OrderStore.On("UpdateOrderProgress", orgID, service.PlatformOrderID(orgID, "talabat", "tb-3"), "incompleted", mock.MatchedBy(func(delivery *database.OrderDelivery) bool {
+			return delivery.DeliveryStatus == "out for delivery" && delivery.DeliveredTime.IsZero()
+		})).Return(sql.ErrNoRows).Once()
+
+		w := webhookRequest(env.Handler.ReceiveWebhookHandler, path("talabat"), body, map[string]string{"Authorization": "Bearer " + testWebhookSecret})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"unknown_orders":1`)
+		assert.Empty(t, env.StatusStore.RecordedEvents(database.StatusEventIngestion))
+	})
+
+	t.Run("StoreErrorAsksForRetry", func(t *testing.T) {
+		env.ResetMocks()
+		env.StatusStore.AllowEvents()
+		env.PlatformStore.On("GetIntegration", orgID, "uber_eats").Return(integration("uber_eats"), nil).Once()
+		env.PlatformStore.On("MarkEventReceived", orgID, "uber_eats", mock.Anything).Return(nil).Once()
+		env.OrderStore.On("GetAllItems", orgID).Return(items, nil).Once()
+		env.OrderStore.On("StoreNestedOrder", orgID, mock.Anything).Return(errors.New("db error")).Once()
+
+		w := webhookRequest(env.Handler.ReceiveWebhookHandler, path("uber_eats"), uberOrder, map[string]string{"X-Uber-Signature": signWebhook(uberOrder)})
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Body.String(), `"failed":1`)
+	})
+
+	t.Run("DisabledIsAcknowledged", func(t *testing.T) {
+		env.ResetMocks()
+		disabled := integration("uber_eats")
+		disabled.Enabled = false
+		env.PlatformStore.On("GetIntegration", orgID, "uber_eats").Return(disabled, nil).Once()
+
+		w := webhookRequest(env.Handler.ReceiveWebhookHandler, path("uber_eats"), uberOrder, map[string]string{"X-Uber-Signature": signWebhook(uberOrder)})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.OrderStore.AssertNotCalled(t, "StoreNestedOrder", mock.Anything, mock.Anything)
+	})
+
+	t.Run("InvalidPayload", func(t *testing.T) {
+		env.ResetMocks()
+		body := `{"token": "tb-4", "price": {"grandTotal": "twelve"}, "products": [{"name": "Burger", "quantity": "1", "paidPrice": "12.00"}]}`
+		env.PlatformStore.On("GetIntegration", orgID, "talabat").Return(integration("talabat"), nil).Once()
+		env.DeadLetterStore.On("CreateDeadLetter", orgID, mock.MatchedBy(func(letter *database.DeadLetter) bool {
+			return letter.Source == "talabat" && letter.Via == database.DeadLetterViaWebhook && letter.Payload == body && letter.Error != ""
+		})).Return(nil).Once()
+
+		w := webhookRequest(env.Handler.ReceiveWebhookHandler, path("talabat"), body, map[string]string{"Authorization": "Bearer " + testWebhookSecret})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		env.DeadLetterStore.AssertExpectations(t)
+	})
+
+	t.Run("InvalidPayloadDeadLetterFails", func(t *testing.T) {
+		env.ResetMocks()
+		env.PlatformStore.On("GetIntegration", orgID, "talabat").Return(integration("talabat"), nil).Once()
+		env.DeadLetterStore.On("CreateDeadLetter", orgID, mock.Anything).Return(errors.New("db error")).Once()
+
+		w := webhookRequest(env.Handler.ReceiveWebhookHandler, path("talabat"), `[`, map[string]string{"Authorization": "Bearer " + testWebhookSecret})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("NotConnected", func(t *testing.T) {
+		env.ResetMocks()
+		env.PlatformStore.On("GetIntegration", orgID, "deliveroo").Return(nil, sql.ErrNoRows).Once()
+
+		w := webhookRequest(env.Handler.ReceiveWebhookHandler, path("deliveroo"), `{}`, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("UnknownPlatform", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := webhookRequest(env.Handler.ReceiveWebhookHandler, path("just_eat"), `{}`, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		env.PlatformStore.AssertNotCalled(t, "GetIntegration", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetDeliveryPlatformsHandler(t *testing.T) {
+	env := setupDeliveryPlatformEnv(t)
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	route := "/:org/integrations/delivery-platforms"
+	path := "/" + orgID.String() + "/integrations/delivery-platforms"
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		token := "token-123"
+		env.PlatformStore.On("GetIntegrations", orgID).Return([]database.DeliveryPlatformIntegration{
+			{OrganizationID: orgID, Platform: "talabat", StoreID: "vendor-7", WebhookSecret: testWebhookSecret, APIToken: &token, Enabled: true},
+		}, nil).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetDeliveryPlatformsHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"webhook_url":"https://api.example.com/api/webhooks/talabat/`+orgID.String()+`"`)
+		assert.Contains(t, w.Body.String(), `"has_api_token":true`)
+		assert.NotContains(t, w.Body.String(), testWebhookSecret)
+		assert.NotContains(t, w.Body.String(), token)
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetDeliveryPlatformsHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestSaveDeliveryPlatformHandler(t *testing.T) {
+	env := setupDeliveryPlatformEnv(t)
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/integrations/delivery-platforms/:platform"
+	path := func(platform string) string {
+		return "/" + orgID.String() + "/integrations/delivery-platforms/" + platform
+	}
+	handlers := []gin.HandlerFunc{authMiddleware(admin), env.Handler.SaveDeliveryPlatformHandler}
+
+	t.Run("Connect", func(t *testing.T) {
+		env.ResetMocks()
+		env.PlatformStore.On("GetIntegration", orgID, "deliveroo").Return(nil, sql.ErrNoRows).Once()
+		env.PlatformStore.On("SaveIntegration", orgID, mock.MatchedBy(func(integration *database.DeliveryPlatformIntegration) bool {
+			return integration.Platform == "deliveroo" && integration.StoreID == "store-42" && integration.WebhookSecret == testWebhookSecret && integration.Enabled
+		})).Return(nil).Once()
+
+		w := jobRequest("PUT", route, path("deliveroo"), handlers, map[string]any{"store_id": "store-42", "webhook_secret": testWebhookSecret})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "/api/webhooks/deliveroo/")
+		assert.NotContains(t, w.Body.String(), testWebhookSecret)
+		env.PlatformStore.AssertExpectations(t)
+	})
+
+	t.Run("UpdateKeepsSecrets", func(t *testing.T) {
+		env.ResetMocks()
+		token := "token-123"
+		env.PlatformStore.On("GetIntegration", orgID, "talabat").Return(&database.DeliveryPlatformIntegration{Platform: "talabat", WebhookSecret: testWebhookSecret, APIToken: &token, Enabled: true}, nil).Once()
+		env.PlatformStore.On("SaveIntegration", orgID, mock.MatchedBy(func(integration *database.DeliveryPlatformIntegration) bool {
+			return integration.WebhookSecret == testWebhookSecret && *integration.APIToken == token && *integration.APIURL == "https://platform.example.com/orders" && !integration.Enabled
+		})).Return(nil).Once()
+
+		w := jobRequest("PUT", route, path("talabat"), handlers, map[string]any{"store_id": "vendor-7", "api_url": "https://platform.example.com/orders", "enabled": false})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.PlatformStore.AssertExpectations(t)
+	})
+
+	t.Run("SecretRequiredToConnect", func(t *testing.T) {
+		env.ResetMocks()
+		env.PlatformStore.On("GetIntegration", orgID, "uber_eats").Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("PUT", route, path("uber_eats"), handlers, map[string]any{"store_id": "store-1"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		env.PlatformStore.AssertNotCalled(t, "SaveIntegration", mock.Anything, mock.Anything)
+	})
+
+	t.Run("PollingNeedsToken", func(t *testing.T) {
+		env.ResetMocks()
+		env.PlatformStore.On("GetIntegration", orgID, "uber_eats").Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("PUT", route, path("uber_eats"), handlers, map[string]any{"store_id": "store-1", "webhook_secret": testWebhookSecret, "api_url": "https://platform.example.com/orders"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("PrivateOrPlainHTTPAPIURL", func(t *testing.T) {
+		for _, apiURL := range []string{"http://platform.example.com/orders", "https://169.254.169.254/latest/meta-data", "https://localhost/orders"} {
+			env.ResetMocks()
+
+			w := jobRequest("PUT", route, path("uber_eats"), handlers, map[string]any{"store_id": "store-1", "webhook_secret": testWebhookSecret, "api_url": apiURL, "api_token": "token-123"})
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, apiURL)
+			env.PlatformStore.AssertNotCalled(t, "SaveIntegration", mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("ShortSecret", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("PUT", route, path("uber_eats"), handlers, map[string]any{"store_id": "store-1", "webhook_secret": "short"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("UnknownPlatform", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("PUT", route, path("just_eat"), handlers, map[string]any{"store_id": "store-1", "webhook_secret": testWebhookSecret})
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("ManagerForbidden", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("PUT", route, path("uber_eats"), []gin.HandlerFunc{authMiddleware(manager), env.Handler.SaveDeliveryPlatformHandler}, map[string]any{"store_id": "store-1", "webhook_secret": testWebhookSecret})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestDeleteDeliveryPlatformHandler(t *testing.T) {
+	env := setupDeliveryPlatformEnv(t)
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	route := "/:org/integrations/delivery-platforms/:platform"
+	path := "/" + orgID.String() + "/integrations/delivery-platforms/talabat"
+	handlers := []gin.HandlerFunc{authMiddleware(admin), env.Handler.DeleteDeliveryPlatformHandler}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.PlatformStore.On("DeleteIntegration", orgID, "talabat").Return(nil).Once()
+
+		w := jobRequest("DELETE", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("NotConnected", func(t *testing.T) {
+		env.ResetMocks()
+		env.PlatformStore.On("DeleteIntegration", orgID, "talabat").Return(sql.ErrNoRows).Once()
+
+		w := jobRequest("DELETE", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type DeliverySLATestEnv struct {
+	Store    *MockDeliverySLAStore
+	OrgStore *MockOrgStore
+	Email    *MockEmailService
+	Service  *service.DeliverySLAService
+}
+
+func setupDeliverySLAEnv() *DeliverySLATestEnv {
+	store := new(MockDeliverySLAStore)
+	orgStore := new(MockOrgStore)
+	email := new(MockEmailService)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &DeliverySLATestEnv{
+		Store:    store,
+		OrgStore: orgStore,
+		Email:    email,
+		Service:  &service.DeliverySLAService{Store: store, OrgStore: orgStore, EmailService: email, Logger: logger, Interval: time.Minute, MinDeliveries: 10},
+	}
+}
+
+func (env *DeliverySLATestEnv) ResetMocks() {
+	env.Store.ExpectedCalls = nil
+	env.Store.Calls = nil
+	env.OrgStore.ExpectedCalls = nil
+	env.OrgStore.Calls = nil
+	env.Email.ExpectedCalls = nil
+	env.Email.Calls = nil
+}
+
+func TestEvaluateSLABreaches(t *testing.T) {
+	orgID := uuid.New()
+
+	t.Run("AboveThreshold", func(t *testing.T) {
+		alert := service.EvaluateSLABreaches(database.DeliverySLADay{OrganizationID: orgID, SLAMinutes: 45, AlertPercent: 20, Delivered: 40, Breaches: 12}, 10)
+
+		if assert.NotNil(t, alert) {
+			assert.Equal(t, orgID, alert.OrganizationID)
+			assert.Equal(t, 30.0, alert.BreachRate)
+			assert.Equal(t, 20, alert.ThresholdPercent)
+		}
+	})
+
+	t.Run("AtThreshold", func(t *testing.T) {
+		alert := service.EvaluateSLABreaches(database.DeliverySLADay{OrganizationID: orgID, SLAMinutes: 45, AlertPercent: 20, Delivered: 40, Breaches: 8}, 10)
+
+		assert.Nil(t, alert)
+	})
+
+	t.Run("TooFewDeliveries", func(t *testing.T) {
+		alert := service.EvaluateSLABreaches(database.DeliverySLADay{OrganizationID: orgID, SLAMinutes: 45, AlertPercent: 20, Delivered: 4, Breaches: 4}, 10)
+
+		assert.Nil(t, alert)
+	})
+}
+
+func TestCheckDeliverySLABreaches(t *testing.T) {
+	env := setupDeliverySLAEnv()
+	orgID := uuid.New()
+	now := time.Date(2026, 10, 16, 20, 15, 0, 0, time.Local)
+	today := time.Date(2026, 10, 16, 0, 0, 0, 0, time.Local)
+	tomorrow := today.AddDate(0, 0, 1)
+	emails := []string{"manager@example.com", "admin@example.com"}
+	late := database.DeliverySLADay{OrganizationID: orgID, SLAMinutes: 30, AlertPercent: 20, Delivered: 20, Breaches: 5}
+
+	t.Run("AlertAndNotify", func(t *testing.T) {
+		env.ResetMocks()
+		onTime := database.DeliverySLADay{OrganizationID: uuid.New(), SLAMinutes: 45, AlertPercent: 20, Delivered: 30, Breaches: 1}
+		env.Store.On("GetDayBreaches", today, tomorrow).Return([]database.DeliverySLADay{late, onTime}, nil).Once()
+		env.Store.On("StoreAlert", mock.MatchedBy(func(alert *database.DeliverySLAAlert) bool {
+			return alert.OrganizationID == orgID && alert.Date.Equal(today) && alert.BreachRate == 25 && alert.CreatedAt.Equal(now)
+		})).Return(nil).Once()
+		env.OrgStore.On("GetManagerEmailsByOrgID", orgID).Return([]string{"manager@example.com"}, nil).Once()
+		env.OrgStore.On("GetAdminEmailsByOrgID", orgID).Return([]string{"admin@example.com"}, nil).Once()
+		env.Email.On("SendDeliverySLAEmail", emails,
+			"5 of the 20 deliveries today (25%) took longer than the 30 minute delivery SLA, above the 20% alert threshold.").Return(nil).Once()
+
+		alerted, err := env.Service.CheckBreaches(now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, alerted)
+		env.Store.AssertExpectations(t)
+		env.OrgStore.AssertExpectations(t)
+		env.Email.AssertExpectations(t)
+	})
+
+	t.Run("AlreadyAlertedToday", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("GetDayBreaches", today, tomorrow).Return([]database.DeliverySLADay{late}, nil).Once()
+		env.Store.On("StoreAlert", mock.Anything).Return(sql.ErrNoRows).Once()
+
+		alerted, err := env.Service.CheckBreaches(now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, alerted)
+		env.Email.AssertNotCalled(t, "SendDeliverySLAEmail", mock.Anything, mock.Anything)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("GetDayBreaches", today, tomorrow).Return(nil, errors.New("db error")).Once()
+
+		alerted, err := env.Service.CheckBreaches(now)
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, alerted)
+	})
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetDriverCoverageHandler(t *testing.T) {
+	env := setupScheduleEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/schedule/drivers/coverage"
+	path := "/" + orgID.String() + "/schedule/drivers/coverage"
+	handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetDriverCoverageHandler}
+
+	perDriver := 1
+	roles := []database.OrganizationRole{
+		{OrganizationID: orgID, Role: "cook", MinNeededPerShift: 1},
+		{OrganizationID: orgID, Role: "driver", NeedForDemand: true, ItemsPerRolePerHour: &perDriver},
+	}
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	weekday := database.ValidDays[today.Weekday()]
+	driverShift := database.ShiftFilter{Roles: []string{"driver"}}
+
+	coverage := func(w *httptest.ResponseRecorder) api.DriverCoverage {
+		var resp struct {
+			Data api.DriverCoverage `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Data
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.RoleStore.On("GetRolesByOrganizationID", orgID).Return(roles, nil).Once()
+		// 8 deliveries at noon over 4 weeks are 2 a week
+		env.OrderStore.On("GetDeliveryVolume", orgID, today.AddDate(0, 0, -28), today).
+			Return([]database.DeliveryVolume{{Weekday: weekday, Hour: 12, Orders: 8}}, nil).Once()
+		env.ScheduleStore.On("GetShiftAcknowledgments", orgID, driverShift).Return([]database.ShiftAcknowledgment{
+			{EmployeeID: uuid.New(), Date: today, StartTime: "11:00:00", EndTime: "13:00:00"},
+		}, nil).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		data := coverage(w)
+		assert.Equal(t, "driver", data.Role)
+		assert.Equal(t, 4, data.HistoryWeeks)
+		assert.Equal(t, &perDriver, data.DeliveriesPerDriverHour)
+		assert.Equal(t, 2, data.ExpectedDeliveries)
+		assert.Equal(t, 2, data.DriverHours)
+		assert.Equal(t, 1, data.ShortSlots)
+		assert.Equal(t, 1, data.OverSlots)
+		date := today.Format(time.DateOnly)
+		assert.Equal(t, []api.DriverCoverageSlot{
+			{Date: date, Day: weekday, Time: "11:00-12:00", DriversScheduled: 1, Status: api.DriverCoverageOver},
+			{Date: date, Day: weekday, Time: "12:00-13:00", ExpectedDeliveries: 2, DriversNeeded: 2, DriversScheduled: 1, Status: api.DriverCoverageShort},
+		}, data.Slots)
+	})
+
+	t.Run("CustomWeeks", func(t *testing.T) {
+		env.ResetMocks()
+		env.RoleStore.On("GetRolesByOrganizationID", orgID).Return(roles, nil).Once()
+		env.OrderStore.On("GetDeliveryVolume", orgID, today.AddDate(0, 0, -14), today).Return([]database.DeliveryVolume{}, nil).Once()
+		env.ScheduleStore.On("GetShiftAcknowledgments", orgID, driverShift).Return([]database.ShiftAcknowledgment{}, nil).Once()
+
+		w := jobRequest("GET", route, path+"?weeks=2", handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		data := coverage(w)
+		assert.Equal(t, 2, data.HistoryWeeks)
+		assert.NotNil(t, data.Slots)
+		assert.Empty(t, data.Slots)
+	})
+
+	t.Run("InvalidWeeks", func(t *testing.T) {
+		env.ResetMocks()
+		for _, weeks := range []string{"0", "13", "many"} {
+			w := jobRequest("GET", route, path+"?weeks="+weeks, handlers, nil)
+			assert.Equal(t, http.StatusBadRequest, w.Code, weeks)
+		}
+		env.RoleStore.AssertNotCalled(t, "GetRolesByOrganizationID", orgID)
+	})
+
+	t.Run("NoDriverRole", func(t *testing.T) {
+		env.ResetMocks()
+		env.RoleStore.On("GetRolesByOrganizationID", orgID).Return(roles[:1], nil).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "no driver role")
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.RoleStore.On("GetRolesByOrganizationID", orgID).Return(roles, nil).Once()
+		env.OrderStore.On("GetDeliveryVolume", orgID, mock.Anything, mock.Anything).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Body.String(), "Failed to retrieve the driver coverage")
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetDriverCoverageHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		env.RoleStore.AssertNotCalled(t, "GetRolesByOrganizationID", orgID)
+	})
+}
+
+// The driver role is staffed from the delivery orders of the last weeks instead of the predicted items
+func TestScheduleDeliveryDemand(t *testing.T) {
+	env := setupScheduleEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/schedule/readiness"
+	path := "/" + orgID.String() + "/schedule/readiness"
+	handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetScheduleReadinessHandler}
+
+	perDriver := 3
+	roles := []database.OrganizationRole{
+		{OrganizationID: orgID, Role: "server", MinNeededPerShift: 1},
+		{OrganizationID: orgID, Role: "driver", NeedForDemand: true, ItemsPerRolePerHour: &perDriver},
+	}
+	// registered before the helper's expectations, so they are used instead
+	mockDelivery := func(delivery bool) {
+		rules := &database.OrganizationRules{OrganizationID: orgID, SlotLenHour: 1, MinShiftLengthSlots: 2, Delivery: delivery}
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(rules, nil).Once()
+		env.RoleStore.On("GetRolesByOrganizationID", orgID).Return(roles, nil).Once()
+	}
+	readiness := func(w *httptest.ResponseRecorder) api.ScheduleReadiness {
+		var resp struct {
+			Data api.ScheduleReadiness `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Data
+	}
+
+	t.Run("DriversFollowDeliveries", func(t *testing.T) {
+		env.ResetMocks()
+		mockDelivery(true)
+		mockValidSchedulePrediction(env, orgID)
+		// 20 deliveries at 9 over 4 weeks are 5 a week, 2 drivers at 3 deliveries each
+		env.OrderStore.On("GetDeliveryVolume", orgID, mock.Anything, mock.Anything).
+			Return([]database.DeliveryVolume{{Weekday: "monday", Hour: 9, Orders: 20}, {Weekday: "monday", Hour: 10, Orders: 1}}, nil).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		data := readiness(w)
+		assert.Equal(t, []api.SlotShortfall{
+			{Day: "monday", Time: "09:00-10:00", Role: "driver", Required: 2, Available: 0, Shortfall: 2},
+		}, data.Shortfalls)
+		call := env.OrderStore.Calls[0]
+		from, to := call.Arguments.Get(1).(time.Time), call.Arguments.Get(2).(time.Time)
+		assert.Equal(t, to.AddDate(0, 0, -28), from)
+	})
+
+	t.Run("NoDelivery_StaffedFromItems", func(t *testing.T) {
+		env.ResetMocks()
+		mockDelivery(false)
+		mockValidSchedulePrediction(env, orgID)
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		// without deliveries the driver role is staffed from the predicted items like any other role
+		assert.Equal(t, []api.SlotShortfall{
+			{Day: "monday", Time: "09:00-10:00", Role: "driver", Required: 2, Available: 0, Shortfall: 2},
+			{Day: "monday", Time: "10:00-11:00", Role: "driver", Required: 3, Available: 0, Shortfall: 3},
+		}, readiness(w).Shortfalls)
+		env.OrderStore.AssertNotCalled(t, "GetDeliveryVolume", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("VolumeError", func(t *testing.T) {
+		env.ResetMocks()
+		mockDelivery(true)
+		mockValidSchedulePrediction(env, orgID)
+		env.OrderStore.On("GetDeliveryVolume", orgID, mock.Anything, mock.Anything).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Body.String(), "failed to get organization delivery demand")
+	})
+}
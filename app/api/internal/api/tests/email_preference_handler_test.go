@@ -0,0 +1,191 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type EmailPreferenceTestEnv struct {
+	PreferenceStore *MockEmailPreferenceStore
+	Signer          *service.UnsubscribeSigner
+	Handler         *api.EmailPreferenceHandler
+}
+
+func setupEmailPreferenceEnv(t *testing.T) *EmailPreferenceTestEnv {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("UNSUBSCRIBE_SECRET", "unsubscribe-test-secret")
+	t.Setenv("PUBLIC_API_URL", "https://api.example.com/")
+
+	preferenceStore := new(MockEmailPreferenceStore)
+	signer := service.NewUnsubscribeSigner()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &EmailPreferenceTestEnv{
+		PreferenceStore: preferenceStore,
+		Signer:          signer,
+		Handler:         api.NewEmailPreferenceHandler(preferenceStore, signer, logger),
+	}
+}
+
+func (env *EmailPreferenceTestEnv) ResetMocks() {
+	env.PreferenceStore.ExpectedCalls = nil
+	env.PreferenceStore.Calls = nil
+}
+
+func TestGetEmailPreferencesHandler(t *testing.T) {
+	env := setupEmailPreferenceEnv(t)
+	orgID := uuid.New()
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, Email: "sam@example.com", UserRole: "employee"}
+	route := "/:org/me/email-preferences"
+	path := "/" + orgID.String() + "/me/email-preferences"
+	handlers := []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetEmailPreferencesHandler}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.PreferenceStore.On("GetOptOuts", employee.Email).Return([]string{"marketing"}, nil).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"opt_outs":["marketing"]`)
+		assert.Contains(t, w.Body.String(), `"categories":["reminders","digests","marketing","weekly_summary"]`)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.PreferenceStore.On("GetOptOuts", employee.Email).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestUpdateEmailPreferencesHandler(t *testing.T) {
+	env := setupEmailPreferenceEnv(t)
+	orgID := uuid.New()
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, Email: "sam@example.com", UserRole: "employee"}
+	route := "/:org/me/email-preferences"
+	path := "/" + orgID.String() + "/me/email-preferences"
+	handlers := []gin.HandlerFunc{authMiddleware(employee), env.Handler.UpdateEmailPreferencesHandler}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.PreferenceStore.On("SetOptOuts", employee.Email, []string{"reminders", "marketing"}).Return(nil).Once()
+
+		w := jobRequest("PUT", route, path, handlers, map[string]any{"opt_outs": []string{"marketing", "reminders", "marketing"}})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.PreferenceStore.AssertExpectations(t)
+	})
+
+	t.Run("SubscribeToEverything", func(t *testing.T) {
+		env.ResetMocks()
+		env.PreferenceStore.On("SetOptOuts", employee.Email, []string{}).Return(nil).Once()
+
+		w := jobRequest("PUT", route, path, handlers, map[string]any{"opt_outs": []string{}})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.PreferenceStore.AssertExpectations(t)
+	})
+
+	t.Run("UnknownCategory", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("PUT", route, path, handlers, map[string]any{"opt_outs": []string{"security"}})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		env.PreferenceStore.AssertNotCalled(t, "SetOptOuts", mock.Anything, mock.Anything)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.PreferenceStore.On("SetOptOuts", employee.Email, mock.Anything).Return(errors.New("db error")).Once()
+
+		w := jobRequest("PUT", route, path, handlers, map[string]any{"opt_outs": []string{"digests"}})
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestUnsubscribeHandler(t *testing.T) {
+	env := setupEmailPreferenceEnv(t)
+	route := "/unsubscribe"
+	handlers := []gin.HandlerFunc{env.Handler.UnsubscribeHandler}
+
+	t.Run("Link", func(t *testing.T) {
+		env.ResetMocks()
+		env.PreferenceStore.On("OptOut", "sam@example.com", "reminders").Return(nil).Once()
+
+		link := env.Signer.Link("Sam@Example.com", "reminders")
+		assert.True(t, strings.HasPrefix(link, "https://api.example.com/api/unsubscribe?token="))
+		w := jobRequest("GET", route, strings.TrimPrefix(link, "https://api.example.com/api"), handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "unsubscribed from reminders emails")
+		env.PreferenceStore.AssertExpectations(t)
+	})
+
+	t.Run("OneClickPost", func(t *testing.T) {
+		env.ResetMocks()
+		env.PreferenceStore.On("OptOut", "sam@example.com", "marketing").Return(nil).Once()
+
+		w := jobRequest("POST", route, "/unsubscribe?token="+url.QueryEscape(env.Signer.Token("sam@example.com", "marketing")), handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.PreferenceStore.AssertExpectations(t)
+	})
+
+	t.Run("TamperedToken", func(t *testing.T) {
+		env.ResetMocks()
+		token := env.Signer.Token("sam@example.com", "marketing")
+		_, signature, _ := strings.Cut(token, ".")
+		payload, _, _ := strings.Cut(env.Signer.Token("boss@example.com", "marketing"), ".")
+
+		for _, token := range []string{"", "not-a-token", payload + "." + signature} {
+			w := jobRequest("GET", route, "/unsubscribe?token="+url.QueryEscape(token), handlers, nil)
+			assert.Equal(t, http.StatusBadRequest, w.Code, token)
+		}
+		env.PreferenceStore.AssertNotCalled(t, "OptOut", mock.Anything, mock.Anything)
+	})
+
+	t.Run("OtherSecret", func(t *testing.T) {
+		env.ResetMocks()
+		t.Setenv("UNSUBSCRIBE_SECRET", "another-secret")
+		token := service.NewUnsubscribeSigner().Token("sam@example.com", "digests")
+
+		w := jobRequest("GET", route, "/unsubscribe?token="+url.QueryEscape(token), handlers, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("UnknownCategory", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("GET", route, "/unsubscribe?token="+url.QueryEscape(env.Signer.Token("sam@example.com", "security")), handlers, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.PreferenceStore.On("OptOut", "sam@example.com", "digests").Return(errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, "/unsubscribe?token="+url.QueryEscape(env.Signer.Token("sam@example.com", "digests")), handlers, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,261 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type ComplianceTestEnv struct {
+	UserStore       *MockUserStore
+	ComplianceStore *MockEmployeeComplianceStore
+	Handler         *api.EmployeeComplianceHandler
+}
+
+func setupComplianceEnv() *ComplianceTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	userStore := new(MockUserStore)
+	complianceStore := new(MockEmployeeComplianceStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &ComplianceTestEnv{
+		UserStore:       userStore,
+		ComplianceStore: complianceStore,
+		Handler:         api.NewEmployeeComplianceHandler(userStore, complianceStore, logger),
+	}
+}
+
+func (env *ComplianceTestEnv) ResetMocks() {
+	env.UserStore.ExpectedCalls = nil
+	env.UserStore.Calls = nil
+	env.ComplianceStore.ExpectedCalls = nil
+	env.ComplianceStore.Calls = nil
+}
+
+func TestValidIBAN(t *testing.T) {
+	for _, iban := range []string{"GB82WEST12345698765432", "DE89370400440532013000", "EG380019000500000000263180002"} {
+		assert.True(t, api.ValidIBAN(iban), iban)
+	}
+	for _, iban := range []string{"GB82WEST12345698765433", "DE8937040044053201300", "gb82west12345698765432", "1234567890123456", ""} {
+		assert.False(t, api.ValidIBAN(iban), iban)
+	}
+}
+
+func TestComplianceFromRequest(t *testing.T) {
+	employeeID := uuid.New()
+	text := func(value string) *string { return &value }
+
+	t.Run("Normalizes", func(t *testing.T) {
+		compliance, err := api.ComplianceFromRequest(employeeID, api.ComplianceRequest{
+			EmergencyContact: &api.EmergencyContactRequest{Name: " Mona Said ", Relationship: text("sister"), Phone: "+20 100 123 4567"},
+			WorkPermitNumber: text("wp-2291"),
+			WorkPermitExpiry: text("2027-03-31"),
+			BankDetails:      &api.BankDetailsRequest{AccountHolder: "Sam Server", IBAN: "gb82 west 1234 5698 7654 32"},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Mona Said", *compliance.EmergencyContact.Name)
+		assert.Equal(t, "WP-2291", *compliance.WorkPermitNumber)
+		assert.Equal(t, time.Date(2027, 3, 31, 0, 0, 0, 0, time.UTC), *compliance.WorkPermitExpiry)
+		assert.Equal(t, "GB82WEST12345698765432", *compliance.BankDetails.IBAN)
+		assert.Nil(t, compliance.NationalID)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		invalid := map[string]api.ComplianceRequest{
+			"emergency_contact.phone": {EmergencyContact: &api.EmergencyContactRequest{Name: "Mona", Phone: "call me"}},
+			"emergency_contact.name":  {EmergencyContact: &api.EmergencyContactRequest{Name: "  ", Phone: "0100 123 4567"}},
+			"national_id_expiry":      {NationalID: text("29001011234567")},
+			"national_id must":        {NationalID: text("12"), NationalIDExpiry: text("2030-01-01")},
+			"work_permit_expiry must": {WorkPermitNumber: text("WP-2291"), WorkPermitExpiry: text("31/03/2027")},
+			"bank_details.iban":       {BankDetails: &api.BankDetailsRequest{AccountHolder: "Sam", IBAN: "GB82WEST12345698765433"}},
+		}
+		for field, request := range invalid {
+			_, err := api.ComplianceFromRequest(employeeID, request)
+			if assert.Error(t, err, field) {
+				assert.Contains(t, err.Error(), field)
+			}
+		}
+	})
+}
+
+func TestGetComplianceHandler(t *testing.T) {
+	env := setupComplianceEnv()
+	orgID := uuid.New()
+	employeeID := uuid.New()
+	employee := &database.User{ID: employeeID, OrganizationID: orgID, UserRole: "employee"}
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/staffing/employees/:id/compliance"
+	path := "/" + orgID.String() + "/staffing/employees/" + employeeID.String() + "/compliance"
+	expiry := time.Date(2027, 3, 31, 0, 0, 0, 0, time.UTC)
+	stored := func() *database.EmployeeCompliance {
+		name, phone, permit, holder, iban := "Mona Said", "+20 100 123 4567", "WP-2291", "Sam Server", "GB82WEST12345698765432"
+		return &database.EmployeeCompliance{
+			UserID:           employeeID,
+			EmergencyContact: database.EmergencyContact{Name: &name, Phone: &phone},
+			WorkPermitNumber: &permit,
+			WorkPermitExpiry: &expiry,
+			BankDetails:      database.BankDetails{AccountHolder: &holder, IBAN: &iban},
+		}
+	}
+
+	t.Run("Admin", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByID", employeeID).Return(employee, nil).Once()
+		env.ComplianceStore.On("GetCompliance", employeeID).Return(stored(), nil).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.GetComplianceHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"work_permit_number":"WP-2291"`)
+		assert.Contains(t, w.Body.String(), `"iban":"GB82WEST12345698765432"`)
+	})
+
+	t.Run("Manager_Masked", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByID", employeeID).Return(employee, nil).Once()
+		env.ComplianceStore.On("GetCompliance", employeeID).Return(stored(), nil).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetComplianceHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		body := w.Body.String()
+		assert.Contains(t, body, `"phone":"+20 100 123 4567"`)
+		assert.Contains(t, body, `"work_permit_number":"****2291"`)
+		assert.Contains(t, body, `"work_permit_expiry":"2027-03-31T00:00:00Z"`)
+		assert.Contains(t, body, `"iban":"****5432"`)
+		assert.NotContains(t, body, "Sam Server")
+	})
+
+	t.Run("Self_NotSavedYet", func(t *testing.T) {
+		env.ResetMocks()
+		env.ComplianceStore.On("GetCompliance", employeeID).Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("GET", "/:org/me/compliance", "/"+orgID.String()+"/me/compliance",
+			[]gin.HandlerFunc{authMiddleware(employee), env.Handler.GetComplianceHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"national_id":null`)
+		env.UserStore.AssertNotCalled(t, "GetUserByID", mock.Anything)
+	})
+
+	t.Run("Forbidden_OtherEmployee", func(t *testing.T) {
+		env.ResetMocks()
+		colleague := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(colleague), env.Handler.GetComplianceHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		env.ComplianceStore.AssertNotCalled(t, "GetCompliance", mock.Anything)
+	})
+
+	t.Run("NotFound_OtherOrganization", func(t *testing.T) {
+		env.ResetMocks()
+		outsider := &database.User{ID: employeeID, OrganizationID: uuid.New(), UserRole: "employee"}
+		env.UserStore.On("GetUserByID", employeeID).Return(outsider, nil).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.GetComplianceHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("StoreError", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByID", employeeID).Return(employee, nil).Once()
+		env.ComplianceStore.On("GetCompliance", employeeID).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.GetComplianceHandler}, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestUpdateComplianceHandler(t *testing.T) {
+	env := setupComplianceEnv()
+	orgID := uuid.New()
+	employeeID := uuid.New()
+	employee := &database.User{ID: employeeID, OrganizationID: orgID, UserRole: "employee"}
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	route := "/:org/staffing/employees/:id/compliance"
+	path := "/" + orgID.String() + "/staffing/employees/" + employeeID.String() + "/compliance"
+	body := map[string]any{
+		"emergency_contact":  map[string]any{"name": "Mona Said", "relationship": "sister", "phone": "+20 100 123 4567"},
+		"work_permit_number": "WP-2291",
+		"work_permit_expiry": "2027-03-31",
+		"bank_details":       map[string]any{"account_holder": "Sam Server", "iban": "GB82 WEST 1234 5698 7654 32"},
+	}
+
+	t.Run("Admin", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByID", employeeID).Return(employee, nil).Once()
+		env.ComplianceStore.On("SaveCompliance", mock.MatchedBy(func(c *database.EmployeeCompliance) bool {
+			return c.UserID == employeeID && *c.WorkPermitNumber == "WP-2291" && *c.BankDetails.IBAN == "GB82WEST12345698765432"
+		})).Return(nil).Once()
+
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.UpdateComplianceHandler}, body)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.ComplianceStore.AssertExpectations(t)
+	})
+
+	t.Run("Self", func(t *testing.T) {
+		env.ResetMocks()
+		env.ComplianceStore.On("SaveCompliance", mock.AnythingOfType("*database.EmployeeCompliance")).Return(nil).Once()
+
+		w := jobRequest("PUT", "/:org/me/compliance", "/"+orgID.String()+"/me/compliance",
+			[]gin.HandlerFunc{authMiddleware(employee), env.Handler.UpdateComplianceHandler}, body)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.ComplianceStore.AssertExpectations(t)
+	})
+
+	t.Run("Forbidden_Manager", func(t *testing.T) {
+		env.ResetMocks()
+		manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.UpdateComplianceHandler}, body)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		env.ComplianceStore.AssertNotCalled(t, "SaveCompliance", mock.Anything)
+	})
+
+	t.Run("InvalidRequest", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByID", employeeID).Return(employee, nil)
+		invalid := []map[string]any{
+			{"emergency_contact": map[string]any{"name": "Mona Said"}},
+			{"emergency_contact": map[string]any{"name": "Mona Said", "phone": "12"}},
+			{"work_permit_number": "WP-2291"},
+			{"bank_details": map[string]any{"account_holder": "Sam Server", "iban": "GB00WEST12345698765432"}},
+		}
+		for _, request := range invalid {
+			w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.UpdateComplianceHandler}, request)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, request)
+		}
+		env.ComplianceStore.AssertNotCalled(t, "SaveCompliance", mock.Anything)
+	})
+
+	t.Run("StoreError", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByID", employeeID).Return(employee, nil).Once()
+		env.ComplianceStore.On("SaveCompliance", mock.Anything).Return(errors.New("db error")).Once()
+
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.UpdateComplianceHandler}, body)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
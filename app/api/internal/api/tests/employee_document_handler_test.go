@@ -0,0 +1,294 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type DocumentTestEnv struct {
+	Store         *MockEmployeeDocumentStore
+	UserStore     *MockUserStore
+	OrgStore      *MockOrgStore
+	BrandingStore *MockBrandingStore
+	EmailService  *MockEmailService
+	Handler       *api.EmployeeDocumentHandler
+}
+
+func setupDocumentEnv(t *testing.T) *DocumentTestEnv {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("PUBLIC_API_URL", "https://api.example.com")
+
+	env := &DocumentTestEnv{
+		Store:         new(MockEmployeeDocumentStore),
+		UserStore:     new(MockUserStore),
+		OrgStore:      new(MockOrgStore),
+		BrandingStore: new(MockBrandingStore),
+		EmailService:  new(MockEmailService),
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	documents := service.NewDocumentService(env.Store, env.EmailService, logger)
+	env.Handler = api.NewEmployeeDocumentHandler(documents, env.UserStore, env.OrgStore, env.BrandingStore, logger)
+	return env
+}
+
+func TestGetDocumentTemplateHandler(t *testing.T) {
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/documents/templates/:kind"
+	path := "/" + orgID.String() + "/documents/templates/"
+
+	t.Run("BuiltInWithoutTemplate", func(t *testing.T) {
+		env := setupDocumentEnv(t)
+		env.Store.On("GetTemplate", orgID, database.DocumentOfferLetter).Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("GET", route, path+"offer_letter", []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetDocumentTemplateHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Offer of Employment")
+		assert.Contains(t, w.Body.String(), `"updated_at":null`)
+	})
+
+	t.Run("UnknownKind", func(t *testing.T) {
+		env := setupDocumentEnv(t)
+
+		w := jobRequest("GET", route, path+"warning_letter", []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetDocumentTemplateHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Forbidden_Employee", func(t *testing.T) {
+		env := setupDocumentEnv(t)
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("GET", route, path+"offer_letter", []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetDocumentTemplateHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		env.Store.AssertNotCalled(t, "GetTemplate", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUpdateDocumentTemplateHandler(t *testing.T) {
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	route := "/:org/documents/templates/:kind"
+	path := "/" + orgID.String() + "/documents/templates/termination_letter"
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupDocumentEnv(t)
+		env.Store.On("SaveTemplate", orgID, mock.MatchedBy(func(template *database.DocumentTemplate) bool {
+			return template.Kind == database.DocumentTerminationLetter && *template.UpdatedBy == admin.ID
+		})).Return(nil).Once()
+
+		body := map[string]string{"title": "Termination - {{.Organization}}", "body": "Dear {{.EmployeeName}}, your last day is {{.LastDay}}."}
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.UpdateDocumentTemplateHandler}, body)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.Store.AssertExpectations(t)
+	})
+
+	t.Run("UnknownField", func(t *testing.T) {
+		env := setupDocumentEnv(t)
+
+		body := map[string]string{"title": "Termination", "body": "Dear {{.Nickname}}"}
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.UpdateDocumentTemplateHandler}, body)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid body template")
+		env.Store.AssertNotCalled(t, "SaveTemplate", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Forbidden_Manager", func(t *testing.T) {
+		env := setupDocumentEnv(t)
+		manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+
+		body := map[string]string{"title": "Termination", "body": "Dear {{.EmployeeName}}"}
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.UpdateDocumentTemplateHandler}, body)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestCreateEmployeeDocumentHandler(t *testing.T) {
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	salary := 15.5
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, FullName: "Sam Carter", Email: "sam@example.com", UserRole: "employee", SalaryPerHour: &salary}
+	route := "/:org/staffing/employees/:id/documents"
+	path := "/" + orgID.String() + "/staffing/employees/" + employee.ID.String() + "/documents"
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupDocumentEnv(t)
+		env.UserStore.On("GetUserByID", employee.ID).Return(employee, nil).Once()
+		env.OrgStore.On("GetOrganizationByID", orgID).Return(&database.Organization{ID: orgID, Name: "Bistro"}, nil).Once()
+		env.Store.On("GetTemplate", orgID, database.DocumentOfferLetter).Return(nil, sql.ErrNoRows).Once()
+		env.Store.On("CreateDocument", mock.MatchedBy(func(doc *database.EmployeeDocument) bool {
+			return *doc.EmployeeID == employee.ID && doc.Title == "Offer of Employment - Bistro" &&
+				strings.Contains(doc.Body, "starting on 2026-11-02") && strings.Contains(doc.Body, "15.50 per hour")
+		}), mock.AnythingOfType("string")).Return(nil).Once()
+		sent := make(chan string, 1)
+		env.EmailService.On("SendDocumentSignatureEmail", employee.Email, employee.FullName, "Offer of Employment - Bistro", mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+			Run(func(args mock.Arguments) { sent <- args.String(3) }).Return(nil).Once()
+
+		body := map[string]string{"kind": "offer_letter", "start_date": "2026-11-02"}
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.CreateEmployeeDocumentHandler}, body)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		select {
+		case link := <-sent:
+			assert.True(t, strings.HasPrefix(link, "https://api.example.com/api/documents/sign/"))
+		case <-time.After(time.Second):
+			t.Fatal("signature email was not sent")
+		}
+		env.Store.AssertExpectations(t)
+	})
+
+	t.Run("EmployeeOfOtherOrganization", func(t *testing.T) {
+		env := setupDocumentEnv(t)
+		other := &database.User{ID: employee.ID, OrganizationID: uuid.New(), UserRole: "employee"}
+		env.UserStore.On("GetUserByID", employee.ID).Return(other, nil).Once()
+
+		body := map[string]string{"kind": "offer_letter"}
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.CreateEmployeeDocumentHandler}, body)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		env.Store.AssertNotCalled(t, "CreateDocument", mock.Anything, mock.Anything)
+	})
+
+	t.Run("InvalidDate", func(t *testing.T) {
+		env := setupDocumentEnv(t)
+
+		body := map[string]string{"kind": "termination_letter", "last_day": "31/12/2026"}
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.CreateEmployeeDocumentHandler}, body)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "last_day")
+	})
+}
+
+func TestGetDocumentPDFHandler(t *testing.T) {
+	orgID := uuid.New()
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	route := "/:org/documents/:id/pdf"
+
+	t.Run("Unsigned_OwnDocument", func(t *testing.T) {
+		env := setupDocumentEnv(t)
+		doc := &database.EmployeeDocument{ID: uuid.New(), OrganizationID: orgID, EmployeeID: &employee.ID, Kind: database.DocumentOfferLetter,
+			Title: "Offer", Body: "Dear Sam", EmployeeName: "Sam", EmployeeEmail: "sam@example.com"}
+		env.Store.On("GetDocument", orgID, doc.ID).Return(doc, nil).Once()
+		env.OrgStore.On("GetOrganizationByID", orgID).Return(&database.Organization{ID: orgID, Name: "Bistro", HexCode1: "BF4124"}, nil).Once()
+		env.BrandingStore.On("GetLogo", orgID).Return(nil, sql.ErrNoRows).Once()
+
+		path := "/" + orgID.String() + "/documents/" + doc.ID.String() + "/pdf"
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetDocumentPDFHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+		assert.True(t, strings.HasPrefix(w.Body.String(), "%PDF-"))
+		assert.Contains(t, w.Body.String(), "Awaiting the signature of Sam")
+	})
+
+	t.Run("Signed_StoredPDF", func(t *testing.T) {
+		env := setupDocumentEnv(t)
+		signedAt := time.Now()
+		doc := &database.EmployeeDocument{ID: uuid.New(), OrganizationID: orgID, EmployeeID: &employee.ID, Kind: database.DocumentOfferLetter, SignedAt: &signedAt}
+		env.Store.On("GetDocument", orgID, doc.ID).Return(doc, nil).Once()
+		env.Store.On("GetSignedPDF", orgID, doc.ID).Return([]byte("%PDF-signed"), nil).Once()
+
+		path := "/" + orgID.String() + "/documents/" + doc.ID.String() + "/pdf"
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetDocumentPDFHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "%PDF-signed", w.Body.String())
+	})
+
+	t.Run("DocumentOfAnotherEmployee", func(t *testing.T) {
+		env := setupDocumentEnv(t)
+		otherID := uuid.New()
+		doc := &database.EmployeeDocument{ID: uuid.New(), OrganizationID: orgID, EmployeeID: &otherID}
+		env.Store.On("GetDocument", orgID, doc.ID).Return(doc, nil).Once()
+
+		path := "/" + orgID.String() + "/documents/" + doc.ID.String() + "/pdf"
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetDocumentPDFHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestSignDocumentHandler(t *testing.T) {
+	orgID := uuid.New()
+	route := "/documents/sign/:token"
+	path := "/documents/sign/token-123"
+	body := map[string]any{"signer_name": " Sam Carter ", "agree": true}
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupDocumentEnv(t)
+		doc := &database.EmployeeDocument{ID: uuid.New(), OrganizationID: orgID, Kind: database.DocumentTerminationLetter,
+			Title: "Termination", Body: "Dear Sam", EmployeeName: "Sam Carter", EmployeeEmail: "sam@example.com"}
+		env.Store.On("GetDocumentBySignToken", "token-123").Return(doc, nil).Once()
+		env.OrgStore.On("GetOrganizationByID", orgID).Return(&database.Organization{ID: orgID, Name: "Bistro"}, nil).Once()
+		env.BrandingStore.On("GetLogo", orgID).Return(nil, sql.ErrNoRows).Once()
+		env.Store.On("SignDocument", doc, mock.MatchedBy(func(pdf []byte) bool {
+			return strings.Contains(string(pdf), "Electronically signed by Sam Carter")
+		})).Return(nil).Once()
+
+		r := gin.New()
+		r.POST(route, env.Handler.SignDocumentHandler)
+		payload, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", path, bytes.NewBuffer(payload)))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"signer_name":"Sam Carter"`)
+		assert.Contains(t, w.Body.String(), `"signer_ip":"192.0.2.1"`)
+		env.Store.AssertExpectations(t)
+	})
+
+	t.Run("NotAgreed", func(t *testing.T) {
+		env := setupDocumentEnv(t)
+		doc := &database.EmployeeDocument{ID: uuid.New(), OrganizationID: orgID}
+		env.Store.On("GetDocumentBySignToken", "token-123").Return(doc, nil).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{env.Handler.SignDocumentHandler}, map[string]any{"signer_name": "Sam Carter"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		env.Store.AssertNotCalled(t, "SignDocument", mock.Anything, mock.Anything)
+	})
+
+	t.Run("AlreadySigned", func(t *testing.T) {
+		env := setupDocumentEnv(t)
+		signedAt := time.Now()
+		doc := &database.EmployeeDocument{ID: uuid.New(), OrganizationID: orgID, SignedAt: &signedAt}
+		env.Store.On("GetDocumentBySignToken", "token-123").Return(doc, nil).Once()
+		env.OrgStore.On("GetOrganizationByID", orgID).Return(nil, errors.New("not found")).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{env.Handler.SignDocumentHandler}, body)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("ExpiredLink", func(t *testing.T) {
+		env := setupDocumentEnv(t)
+		env.Store.On("GetDocumentBySignToken", "token-123").Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{env.Handler.SignDocumentHandler}, body)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
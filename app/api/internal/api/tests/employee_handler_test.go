@@ -2,17 +2,20 @@ package api
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/clockwise/clockwise/backend/internal/api"
 	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -20,12 +23,15 @@ import (
 )
 
 type EmployeeTestEnv struct {
-	Router       *gin.Engine
-	UserStore    *MockUserStore
-	RequestStore *MockRequestStore
-	OrgStore     *MockOrgStore
-	EmailService *MockEmailService
-	Handler      *api.EmployeeHandler
+	Router            *gin.Engine
+	UserStore         *MockUserStore
+	RequestStore      *MockRequestStore
+	OrgStore          *MockOrgStore
+	NotificationStore *MockNotificationStore
+	BlackoutStore     *MockBlackoutStore
+	ApprovalStore     *MockApprovalStore
+	EmailService      *MockEmailService
+	Handler           *api.EmployeeHandler
 }
 
 func setupEmployeeEnv() *EmployeeTestEnv {
@@ -34,18 +40,24 @@ func setupEmployeeEnv() *EmployeeTestEnv {
 	userStore := new(MockUserStore)
 	requestStore := new(MockRequestStore)
 	orgStore := new(MockOrgStore)
+	notificationStore := new(MockNotificationStore)
+	blackoutStore := new(MockBlackoutStore)
+	approvalStore := new(MockApprovalStore)
 	emailService := new(MockEmailService)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
-	handler := api.NewEmployeeHandler(userStore, emailService, requestStore, orgStore, logger)
+	handler := api.NewEmployeeHandler(userStore, emailService, requestStore, orgStore, notificationStore, blackoutStore, approvalStore, logger)
 
 	return &EmployeeTestEnv{
-		Router:       gin.New(),
-		UserStore:    userStore,
-		RequestStore: requestStore,
-		OrgStore:     orgStore,
-		EmailService: emailService,
-		Handler:      handler,
+		Router:            gin.New(),
+		UserStore:         userStore,
+		RequestStore:      requestStore,
+		OrgStore:          orgStore,
+		NotificationStore: notificationStore,
+		BlackoutStore:     blackoutStore,
+		ApprovalStore:     approvalStore,
+		EmailService:      emailService,
+		Handler:           handler,
 	}
 }
 
@@ -163,6 +175,57 @@ func TestLayoffEmployee(t *testing.T) {
 	})
 }
 
+func TestLayoffEmployeeTerminationLetter(t *testing.T) {
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	target := &database.User{ID: uuid.New(), OrganizationID: orgID, FullName: "Target", Email: "target@test.com", UserRole: "employee"}
+	route := "/:org/staffing/employees/:id/layoff"
+	path := "/" + orgID.String() + "/staffing/employees/" + target.ID.String() + "/layoff"
+	body := map[string]any{"reason": "Budget cuts", "termination_letter": true, "last_day": "2026-11-30"}
+
+	setup := func(t *testing.T) (*EmployeeTestEnv, *MockEmployeeDocumentStore) {
+		t.Setenv("PUBLIC_API_URL", "https://api.example.com")
+		env := setupEmployeeEnv()
+		documentStore := new(MockEmployeeDocumentStore)
+		env.Handler.Documents = service.NewDocumentService(documentStore, env.EmailService, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+		env.UserStore.On("GetUserByID", target.ID).Return(target, nil).Once()
+		env.OrgStore.On("GetOrganizationByID", orgID).Return(&database.Organization{ID: orgID, Name: "Bistro"}, nil).Once()
+		documentStore.On("GetTemplate", orgID, database.DocumentTerminationLetter).Return(nil, sql.ErrNoRows).Once()
+		return env, documentStore
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env, documentStore := setup(t)
+		documentID := uuid.New()
+		documentStore.On("CreateDocument", mock.MatchedBy(func(doc *database.EmployeeDocument) bool {
+			return doc.Kind == database.DocumentTerminationLetter && strings.Contains(doc.Body, "ends on 2026-11-30") &&
+				strings.Contains(doc.Body, "Reason: Budget cuts")
+		}), mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+			args.Get(0).(*database.EmployeeDocument).ID = documentID
+		}).Return(nil).Once()
+		env.UserStore.On("LayoffUser", target.ID, "Budget cuts").Return(nil).Once()
+		env.EmailService.On("SendLayoffEmail", target.Email, target.FullName, "Budget cuts").Return(nil).Maybe()
+		env.EmailService.On("SendDocumentSignatureEmail", target.Email, target.FullName, "Termination of Employment - Bistro", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+		w := jobRequest("DELETE", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.LayoffEmployee}, body)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), documentID.String())
+		env.UserStore.AssertExpectations(t)
+		documentStore.AssertExpectations(t)
+	})
+
+	t.Run("LetterFails_NoLayoff", func(t *testing.T) {
+		env, documentStore := setup(t)
+		documentStore.On("CreateDocument", mock.Anything, mock.Anything).Return(errors.New("db error")).Once()
+
+		w := jobRequest("DELETE", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.LayoffEmployee}, body)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		env.UserStore.AssertNotCalled(t, "LayoffUser", mock.Anything, mock.Anything)
+	})
+}
+
 func TestGetEmployeeRequests(t *testing.T) {
 	env := setupEmployeeEnv()
 	orgID := uuid.New()
@@ -180,6 +243,10 @@ func TestGetEmployeeRequests(t *testing.T) {
 
 		env.UserStore.On("GetUserByID", employeeID).Return(employee, nil).Once()
 		env.RequestStore.On("GetRequestsByEmployee", employeeID).Return(requests, nil).Once()
+		decision := database.ApprovalApproved
+		env.ApprovalStore.On("GetRequestApprovals", []uuid.UUID{requests[0].ID}).Return(map[uuid.UUID][]database.RequestApproval{
+			requests[0].ID: {{Step: 1, ApproverRole: "manager", Decision: &decision}, {Step: 2, ApproverRole: "admin"}},
+		}, nil).Once()
 
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/staffing/employees/"+employeeID.String()+"/requests", nil)
@@ -187,6 +254,7 @@ func TestGetEmployeeRequests(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, w.Code)
 		assert.Contains(t, w.Body.String(), "time-off")
+		assert.Contains(t, w.Body.String(), `"approver_role":"admin"`)
 	})
 }
 
@@ -206,7 +274,12 @@ func TestApproveRequest(t *testing.T) {
 
 		env.RequestStore.On("GetRequestByID", reqID).Return(request, nil).Once()
 		env.UserStore.On("GetUserByID", employeeID).Return(employee, nil).Once()
-		env.RequestStore.On("UpdateRequestStatus", reqID, "accepted").Return(nil).Once()
+		env.ApprovalStore.On("GetRequestApprovals", []uuid.UUID{reqID}).Return(map[uuid.UUID][]database.RequestApproval{
+			reqID: {{Step: 1, ApproverRole: "manager"}},
+		}, nil).Once()
+		env.ApprovalStore.On("DecideApprovalSteps", reqID, []int{1}, database.ApprovalApproved, manager.ID).Return(nil).Once()
+		unavailability := &database.EmployeeUnavailability{ID: uuid.New(), EmployeeID: employeeID, RequestID: reqID, Reason: "holiday"}
+		env.RequestStore.On("AcceptRequest", reqID).Return(unavailability, nil).Once()
 
 		env.EmailService.On("SendRequestApprovedEmail", employee.Email, employee.FullName, "holiday").Return(nil).Once()
 
@@ -220,10 +293,193 @@ func TestApproveRequest(t *testing.T) {
 		time.Sleep(10 * time.Millisecond)
 
 		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"unavailability":{"id":"`+unavailability.ID.String())
 		env.RequestStore.AssertExpectations(t)
 		env.EmailService.AssertExpectations(t)
 	})
 
+	t.Run("AcceptError", func(t *testing.T) {
+		reqID := uuid.New()
+		employeeID := uuid.New()
+		employee := &database.User{ID: employeeID, OrganizationID: orgID, FullName: "John", Email: "john@test.com"}
+
+		env.RequestStore.On("GetRequestByID", reqID).Return(&database.Request{ID: reqID, EmployeeID: employeeID, Type: "calloff"}, nil).Once()
+		env.UserStore.On("GetUserByID", employeeID).Return(employee, nil).Once()
+		env.ApprovalStore.On("GetRequestApprovals", []uuid.UUID{reqID}).Return(map[uuid.UUID][]database.RequestApproval{
+			reqID: {{Step: 1, ApproverRole: "manager"}},
+		}, nil).Once()
+		env.ApprovalStore.On("DecideApprovalSteps", reqID, []int{1}, database.ApprovalApproved, manager.ID).Return(nil).Once()
+		env.RequestStore.On("AcceptRequest", reqID).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest(http.MethodPost, "/:org/staffing/employees/:id/requests/approve",
+			"/"+orgID.String()+"/staffing/employees/"+employeeID.String()+"/requests/approve",
+			[]gin.HandlerFunc{authMiddleware(manager), env.Handler.ApproveRequest}, api.RequestActionBody{RequestID: reqID.String()})
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		env.EmailService.AssertNotCalled(t, "SendRequestApprovedEmail", employee.Email, employee.FullName, "calloff")
+	})
+
+	t.Run("Blackout", func(t *testing.T) {
+		reqID := uuid.New()
+		employeeID := uuid.New()
+		employee := &database.User{ID: employeeID, OrganizationID: orgID, FullName: "John", Email: "john@test.com"}
+		start := time.Now().AddDate(0, 1, 0).Truncate(24 * time.Hour)
+		end := start.AddDate(0, 0, 2)
+		request := &database.Request{ID: reqID, EmployeeID: employeeID, Type: "holiday", StartDate: &start, EndDate: &end}
+		blackout := database.TimeOffBlackout{ID: uuid.New(), StartDate: start, EndDate: start, Reason: "Ramadan nights", Action: database.BlackoutFlag}
+		admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+
+		approve := func(approver *database.User, override bool) *httptest.ResponseRecorder {
+			r := gin.New()
+			r.POST("/:org/staffing/employees/:id/requests/approve", authMiddleware(approver), env.Handler.ApproveRequest)
+			jsonBody, _ := json.Marshal(api.RequestActionBody{RequestID: reqID.String(), Override: override})
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/"+orgID.String()+"/staffing/employees/"+employeeID.String()+"/requests/approve", bytes.NewBuffer(jsonBody))
+			r.ServeHTTP(w, req)
+			return w
+		}
+
+		t.Run("Forbidden_Manager", func(t *testing.T) {
+			env.RequestStore.On("GetRequestByID", reqID).Return(request, nil).Once()
+			env.UserStore.On("GetUserByID", employeeID).Return(employee, nil).Once()
+			env.BlackoutStore.On("GetOverlappingBlackouts", orgID, start, end).Return([]database.TimeOffBlackout{blackout}, nil).Once()
+
+			w := approve(manager, true)
+
+			assert.Equal(t, http.StatusForbidden, w.Code)
+			assert.Contains(t, w.Body.String(), "Ramadan nights")
+			env.RequestStore.AssertNotCalled(t, "AcceptRequest", reqID)
+		})
+
+		t.Run("Conflict_AdminWithoutOverride", func(t *testing.T) {
+			env.RequestStore.On("GetRequestByID", reqID).Return(request, nil).Once()
+			env.UserStore.On("GetUserByID", employeeID).Return(employee, nil).Once()
+			env.BlackoutStore.On("GetOverlappingBlackouts", orgID, start, end).Return([]database.TimeOffBlackout{blackout}, nil).Once()
+
+			w := approve(admin, false)
+
+			assert.Equal(t, http.StatusConflict, w.Code)
+			assert.Contains(t, w.Body.String(), "override")
+			env.RequestStore.AssertNotCalled(t, "AcceptRequest", reqID)
+		})
+
+		t.Run("Success_AdminOverride", func(t *testing.T) {
+			env.RequestStore.On("GetRequestByID", reqID).Return(request, nil).Once()
+			env.UserStore.On("GetUserByID", employeeID).Return(employee, nil).Once()
+			env.BlackoutStore.On("GetOverlappingBlackouts", orgID, start, end).Return([]database.TimeOffBlackout{blackout}, nil).Once()
+			env.ApprovalStore.On("GetRequestApprovals", []uuid.UUID{reqID}).Return(map[uuid.UUID][]database.RequestApproval{
+				reqID: {{Step: 1, ApproverRole: "manager"}},
+			}, nil).Once()
+			env.ApprovalStore.On("DecideApprovalSteps", reqID, []int{1}, database.ApprovalApproved, admin.ID).Return(nil).Once()
+			env.RequestStore.On("AcceptRequest", reqID).Return(&database.EmployeeUnavailability{RequestID: reqID, Reason: "holiday", StartDate: start, EndDate: end}, nil).Once()
+			env.EmailService.On("SendRequestApprovedEmail", employee.Email, employee.FullName, "holiday").Return(nil).Once()
+
+			w := approve(admin, true)
+
+			time.Sleep(10 * time.Millisecond)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			env.RequestStore.AssertExpectations(t)
+			env.BlackoutStore.AssertExpectations(t)
+		})
+	})
+
+	t.Run("ApprovalChain", func(t *testing.T) {
+		employeeID := uuid.New()
+		employee := &database.User{ID: employeeID, OrganizationID: orgID, FullName: "John", Email: "john@test.com"}
+		admin := &database.User{ID: uuid.New(), OrganizationID: orgID, FullName: "Ada Admin", UserRole: "admin"}
+		manager.FullName = "Max Manager"
+		approved := database.ApprovalApproved
+
+		approve := func(approver *database.User, reqID uuid.UUID) *httptest.ResponseRecorder {
+			return jobRequest(http.MethodPost, "/:org/staffing/employees/:id/requests/approve",
+				"/"+orgID.String()+"/staffing/employees/"+employeeID.String()+"/requests/approve",
+				[]gin.HandlerFunc{authMiddleware(approver), env.Handler.ApproveRequest}, api.RequestActionBody{RequestID: reqID.String()})
+		}
+
+		t.Run("ManagerStep_AwaitsAdmin", func(t *testing.T) {
+			reqID := uuid.New()
+			env.RequestStore.On("GetRequestByID", reqID).Return(&database.Request{ID: reqID, EmployeeID: employeeID, Type: "resign", Status: "in queue"}, nil).Once()
+			env.UserStore.On("GetUserByID", employeeID).Return(employee, nil).Once()
+			env.ApprovalStore.On("GetRequestApprovals", []uuid.UUID{reqID}).Return(map[uuid.UUID][]database.RequestApproval{
+				reqID: {{Step: 1, ApproverRole: "manager"}, {Step: 2, ApproverRole: "admin"}},
+			}, nil).Once()
+			env.ApprovalStore.On("DecideApprovalSteps", reqID, []int{1}, database.ApprovalApproved, manager.ID).Return(nil).Once()
+			env.OrgStore.On("GetAdminEmailsByOrgID", orgID).Return([]string{"admin@test.com"}, nil).Once()
+			env.EmailService.On("SendRequestAwaitingApprovalEmail", []string{"admin@test.com"}, "John", "resign", "Max Manager").Return(nil).Once()
+			env.EmailService.On("SendRequestStepApprovedEmail", "john@test.com", "John", "resign", "admin").Return(nil).Once()
+
+			w := approve(manager, reqID)
+
+			time.Sleep(10 * time.Millisecond)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Body.String(), `"next_approver_role":"admin"`)
+			env.RequestStore.AssertNotCalled(t, "AcceptRequest", reqID)
+			env.ApprovalStore.AssertExpectations(t)
+			env.EmailService.AssertExpectations(t)
+		})
+
+		t.Run("Forbidden_ManagerOnAdminStep", func(t *testing.T) {
+			reqID := uuid.New()
+			env.RequestStore.On("GetRequestByID", reqID).Return(&database.Request{ID: reqID, EmployeeID: employeeID, Type: "resign", Status: "in queue"}, nil).Once()
+			env.UserStore.On("GetUserByID", employeeID).Return(employee, nil).Once()
+			env.ApprovalStore.On("GetRequestApprovals", []uuid.UUID{reqID}).Return(map[uuid.UUID][]database.RequestApproval{
+				reqID: {{Step: 1, ApproverRole: "manager", Decision: &approved}, {Step: 2, ApproverRole: "admin"}},
+			}, nil).Once()
+
+			w := approve(manager, reqID)
+
+			assert.Equal(t, http.StatusForbidden, w.Code)
+			assert.Contains(t, w.Body.String(), "sign-off of an admin")
+		})
+
+		t.Run("AdminSignsOffRemainingSteps", func(t *testing.T) {
+			reqID := uuid.New()
+			env.RequestStore.On("GetRequestByID", reqID).Return(&database.Request{ID: reqID, EmployeeID: employeeID, Type: "resign", Status: "in queue"}, nil).Once()
+			env.UserStore.On("GetUserByID", employeeID).Return(employee, nil).Once()
+			env.ApprovalStore.On("GetRequestApprovals", []uuid.UUID{reqID}).Return(map[uuid.UUID][]database.RequestApproval{
+				reqID: {{Step: 1, ApproverRole: "manager"}, {Step: 2, ApproverRole: "admin"}},
+			}, nil).Once()
+			env.ApprovalStore.On("DecideApprovalSteps", reqID, []int{1, 2}, database.ApprovalApproved, admin.ID).Return(nil).Once()
+			env.RequestStore.On("AcceptRequest", reqID).Return(nil, nil).Once()
+			env.EmailService.On("SendRequestApprovedEmail", employee.Email, employee.FullName, "resign").Return(nil).Once()
+
+			w := approve(admin, reqID)
+
+			time.Sleep(10 * time.Millisecond)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			env.RequestStore.AssertExpectations(t)
+			env.ApprovalStore.AssertExpectations(t)
+		})
+
+		t.Run("Conflict_AlreadyAccepted", func(t *testing.T) {
+			reqID := uuid.New()
+			env.RequestStore.On("GetRequestByID", reqID).Return(&database.Request{ID: reqID, EmployeeID: employeeID, Type: "resign", Status: "accepted"}, nil).Once()
+			env.UserStore.On("GetUserByID", employeeID).Return(employee, nil).Once()
+
+			w := approve(admin, reqID)
+
+			assert.Equal(t, http.StatusConflict, w.Code)
+		})
+
+		t.Run("Conflict_DecidedConcurrently", func(t *testing.T) {
+			reqID := uuid.New()
+			env.RequestStore.On("GetRequestByID", reqID).Return(&database.Request{ID: reqID, EmployeeID: employeeID, Type: "calloff", Status: "in queue"}, nil).Once()
+			env.UserStore.On("GetUserByID", employeeID).Return(employee, nil).Once()
+			env.ApprovalStore.On("GetRequestApprovals", []uuid.UUID{reqID}).Return(map[uuid.UUID][]database.RequestApproval{
+				reqID: {{Step: 1, ApproverRole: "manager"}},
+			}, nil).Once()
+			env.ApprovalStore.On("DecideApprovalSteps", reqID, []int{1}, database.ApprovalApproved, manager.ID).Return(database.ErrApprovalStepDecided).Once()
+
+			w := approve(manager, reqID)
+
+			assert.Equal(t, http.StatusConflict, w.Code)
+			env.RequestStore.AssertNotCalled(t, "AcceptRequest", reqID)
+		})
+	})
+
 	t.Run("Forbidden_EmployeeApproves", func(t *testing.T) {
 		emp := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
 		r := gin.New()
@@ -253,6 +509,10 @@ func TestDeclineRequest(t *testing.T) {
 
 		env.RequestStore.On("GetRequestByID", reqID).Return(request, nil).Once()
 		env.UserStore.On("GetUserByID", employeeID).Return(employee, nil).Once()
+		env.ApprovalStore.On("GetRequestApprovals", []uuid.UUID{reqID}).Return(map[uuid.UUID][]database.RequestApproval{}, nil).Once()
+		env.ApprovalStore.On("GetApprovalChain", orgID, "calloff").Return([]string{"manager"}, nil).Once()
+		env.ApprovalStore.On("StartApprovals", reqID, []string{"manager"}).Return(nil).Once()
+		env.ApprovalStore.On("DecideApprovalSteps", reqID, []int{1}, database.ApprovalDeclined, admin.ID).Return(nil).Once()
 		env.RequestStore.On("UpdateRequestStatus", reqID, "declined").Return(nil).Once()
 
 		env.EmailService.On("SendRequestDeclinedEmail", employee.Email, employee.FullName, "calloff").Return(nil).Once()
@@ -268,6 +528,28 @@ func TestDeclineRequest(t *testing.T) {
 
 		assert.Equal(t, http.StatusOK, w.Code)
 		env.RequestStore.AssertExpectations(t)
+		env.ApprovalStore.AssertExpectations(t)
+	})
+
+	t.Run("Forbidden_ManagerOnAdminStep", func(t *testing.T) {
+		reqID := uuid.New()
+		employeeID := uuid.New()
+		manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+		employee := &database.User{ID: employeeID, OrganizationID: orgID}
+		approved := database.ApprovalApproved
+
+		env.RequestStore.On("GetRequestByID", reqID).Return(&database.Request{ID: reqID, EmployeeID: employeeID, Type: "resign", Status: "in queue"}, nil).Once()
+		env.UserStore.On("GetUserByID", employeeID).Return(employee, nil).Once()
+		env.ApprovalStore.On("GetRequestApprovals", []uuid.UUID{reqID}).Return(map[uuid.UUID][]database.RequestApproval{
+			reqID: {{Step: 1, ApproverRole: "manager", Decision: &approved}, {Step: 2, ApproverRole: "admin"}},
+		}, nil).Once()
+
+		w := jobRequest(http.MethodPost, "/:org/staffing/employees/:id/requests/decline",
+			"/"+orgID.String()+"/staffing/employees/"+employeeID.String()+"/requests/decline",
+			[]gin.HandlerFunc{authMiddleware(manager), env.Handler.DeclineRequest}, api.RequestActionBody{RequestID: reqID.String()})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		env.RequestStore.AssertNotCalled(t, "UpdateRequestStatus", reqID, "declined")
 	})
 }
 
@@ -281,6 +563,8 @@ func TestRequestHandlerForEmployee(t *testing.T) {
 		r := gin.New()
 		r.POST("/:org/request", authMiddleware(user), env.Handler.RequestHandlerForEmployee)
 
+		env.ApprovalStore.On("GetApprovalChain", orgID, "calloff").Return([]string{"manager"}, nil).Once()
+		env.ApprovalStore.On("StartApprovals", mock.Anything, []string{"manager"}).Return(nil).Once()
 		env.RequestStore.On("CreateRequest", mock.MatchedBy(func(r *database.Request) bool {
 			return r.EmployeeID == user.ID && r.Type == "calloff" && r.Message == "Sick"
 		})).Return(nil).Once()
@@ -293,6 +577,7 @@ func TestRequestHandlerForEmployee(t *testing.T) {
 		env.OrgStore.On("GetAdminEmailsByOrgID", orgID).Return(admins, nil).Once()
 
 		allEmails := append(managers, admins...)
+		env.NotificationStore.On("GetDigestRecipients", allEmails).Return(map[string]uuid.UUID{}, nil).Once()
 		env.EmailService.On("SendRequestNotifyEmail", allEmails, user.FullName, "calloff", "Sick").Return(nil).Once()
 
 		body := api.CalloffRequest{Type: "calloff", Message: "Sick"}
@@ -309,6 +594,105 @@ func TestRequestHandlerForEmployee(t *testing.T) {
 		env.EmailService.AssertExpectations(t)
 	})
 
+	t.Run("Success_DigestRecipientQueued", func(t *testing.T) {
+		user := &database.User{ID: uuid.New(), OrganizationID: orgID, FullName: "Test User", Email: "test@test.com", UserRole: "employee"}
+		managerID := uuid.New()
+
+		r := gin.New()
+		r.POST("/:org/request", authMiddleware(user), env.Handler.RequestHandlerForEmployee)
+
+		env.ApprovalStore.On("GetApprovalChain", orgID, "holiday").Return([]string{"manager"}, nil).Once()
+		env.ApprovalStore.On("StartApprovals", mock.Anything, []string{"manager"}).Return(nil).Once()
+		env.RequestStore.On("CreateRequest", mock.Anything).Return(nil).Once()
+		env.EmailService.On("SendRequestSubmittedEmail", user.Email, user.FullName, "holiday", "Trip").Return(nil).Once()
+		env.OrgStore.On("GetManagerEmailsByOrgID", orgID).Return([]string{"mgr@test.com"}, nil).Once()
+		env.OrgStore.On("GetAdminEmailsByOrgID", orgID).Return([]string{"admin@test.com"}, nil).Once()
+		env.NotificationStore.On("GetDigestRecipients", []string{"mgr@test.com", "admin@test.com"}).
+			Return(map[string]uuid.UUID{"mgr@test.com": managerID}, nil).Once()
+		env.NotificationStore.On("QueueNotification", managerID, "request:"+user.ID.String()+":holiday", "Test User submitted a holiday request: Trip").
+			Return(nil).Once()
+		env.EmailService.On("SendRequestNotifyEmail", []string{"admin@test.com"}, user.FullName, "holiday", "Trip").Return(nil).Once()
+		env.BlackoutStore.On("GetOverlappingBlackouts", orgID, mock.Anything, mock.Anything).Return([]database.TimeOffBlackout{}, nil).Once()
+
+		start := time.Now().AddDate(0, 0, 7).Format(time.DateOnly)
+		jsonBody, _ := json.Marshal(api.CalloffRequest{Type: "holiday", Message: "Trip", StartDate: start})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/request", bytes.NewBuffer(jsonBody))
+		r.ServeHTTP(w, req)
+
+		time.Sleep(20 * time.Millisecond)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		env.NotificationStore.AssertExpectations(t)
+		env.EmailService.AssertExpectations(t)
+	})
+
+	t.Run("Blackout", func(t *testing.T) {
+		user := &database.User{ID: uuid.New(), OrganizationID: orgID, FullName: "Test User", Email: "test@test.com", UserRole: "employee"}
+		start := time.Now().AddDate(0, 1, 0).Format(time.DateOnly)
+		end := time.Now().AddDate(0, 1, 3).Format(time.DateOnly)
+		startDate, _ := time.Parse(time.DateOnly, start)
+		endDate, _ := time.Parse(time.DateOnly, end)
+
+		submit := func(body api.CalloffRequest) *httptest.ResponseRecorder {
+			r := gin.New()
+			r.POST("/:org/request", authMiddleware(user), env.Handler.RequestHandlerForEmployee)
+			jsonBody, _ := json.Marshal(body)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/"+orgID.String()+"/request", bytes.NewBuffer(jsonBody))
+			r.ServeHTTP(w, req)
+			return w
+		}
+
+		t.Run("BadRequest_MissingStartDate", func(t *testing.T) {
+			w := submit(api.CalloffRequest{Type: "holiday", Message: "Trip"})
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), "start_date is required")
+		})
+
+		t.Run("BadRequest_EndBeforeStart", func(t *testing.T) {
+			w := submit(api.CalloffRequest{Type: "holiday", Message: "Trip", StartDate: end, EndDate: start})
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+
+		t.Run("Conflict_Rejected", func(t *testing.T) {
+			blackout := database.TimeOffBlackout{ID: uuid.New(), StartDate: startDate, EndDate: startDate, Reason: "December peak", Action: database.BlackoutReject}
+			env.BlackoutStore.On("GetOverlappingBlackouts", orgID, startDate, endDate).Return([]database.TimeOffBlackout{blackout}, nil).Once()
+
+			w := submit(api.CalloffRequest{Type: "holiday", Message: "Trip", StartDate: start, EndDate: end})
+
+			assert.Equal(t, http.StatusConflict, w.Code)
+			assert.Contains(t, w.Body.String(), "Holidays are blocked from "+start+" to "+start+": December peak")
+			env.BlackoutStore.AssertExpectations(t)
+		})
+
+		t.Run("Success_Flagged", func(t *testing.T) {
+			blackout := database.TimeOffBlackout{ID: uuid.New(), StartDate: startDate, EndDate: endDate, Reason: "Ramadan nights", Action: database.BlackoutFlag}
+			env.BlackoutStore.On("GetOverlappingBlackouts", orgID, startDate, endDate).Return([]database.TimeOffBlackout{blackout}, nil).Once()
+			env.ApprovalStore.On("GetApprovalChain", orgID, "holiday").Return([]string{"manager"}, nil).Once()
+			env.ApprovalStore.On("StartApprovals", mock.Anything, []string{"manager"}).Return(nil).Once()
+			env.RequestStore.On("CreateRequest", mock.MatchedBy(func(r *database.Request) bool {
+				return r.BlackoutID != nil && *r.BlackoutID == blackout.ID && r.StartDate.Equal(startDate) && r.EndDate.Equal(endDate)
+			})).Return(nil).Once()
+			env.EmailService.On("SendRequestSubmittedEmail", user.Email, user.FullName, "holiday", "Trip").Return(nil).Once()
+			env.OrgStore.On("GetManagerEmailsByOrgID", orgID).Return([]string{}, nil).Once()
+			env.OrgStore.On("GetAdminEmailsByOrgID", orgID).Return([]string{"admin@test.com"}, nil).Once()
+			env.NotificationStore.On("GetDigestRecipients", []string{"admin@test.com"}).Return(map[string]uuid.UUID{}, nil).Once()
+			env.EmailService.On("SendRequestNotifyEmail", []string{"admin@test.com"}, user.FullName, "holiday",
+				mock.MatchedBy(func(message string) bool { return strings.Contains(message, "Ramadan nights") })).Return(nil).Once()
+
+			w := submit(api.CalloffRequest{Type: "holiday", Message: "Trip", StartDate: start, EndDate: end})
+
+			time.Sleep(20 * time.Millisecond)
+
+			assert.Equal(t, http.StatusCreated, w.Code)
+			assert.Contains(t, w.Body.String(), "warning")
+			env.RequestStore.AssertExpectations(t)
+			env.EmailService.AssertExpectations(t)
+		})
+	})
+
 	t.Run("Forbidden_AdminCannotSubmit", func(t *testing.T) {
 		admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
 		r := gin.New()
@@ -336,3 +720,222 @@ func TestRequestHandlerForEmployee(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
 }
+
+func TestGetBlackoutsHandler(t *testing.T) {
+	env := setupEmployeeEnv()
+	orgID := uuid.New()
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	route := "/:org/rules/blackouts"
+	path := "/" + orgID.String() + "/rules/blackouts"
+
+	t.Run("Success_EmployeeCanList", func(t *testing.T) {
+		blackouts := []database.TimeOffBlackout{{ID: uuid.New(), Reason: "December peak", Action: database.BlackoutReject}}
+		env.BlackoutStore.On("ListBlackouts", orgID).Return(blackouts, nil).Once()
+
+		w := jobRequest(http.MethodGet, route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetBlackoutsHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "December peak")
+		env.BlackoutStore.AssertExpectations(t)
+	})
+
+	t.Run("StoreError", func(t *testing.T) {
+		env.BlackoutStore.On("ListBlackouts", orgID).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest(http.MethodGet, route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetBlackoutsHandler}, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestCreateBlackoutHandler(t *testing.T) {
+	env := setupEmployeeEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	route := "/:org/rules/blackouts"
+	path := "/" + orgID.String() + "/rules/blackouts"
+	start := time.Now().AddDate(0, 2, 0).Format(time.DateOnly)
+	end := time.Now().AddDate(0, 2, 20).Format(time.DateOnly)
+
+	t.Run("Success", func(t *testing.T) {
+		env.BlackoutStore.On("CreateBlackout", orgID, mock.MatchedBy(func(b *database.TimeOffBlackout) bool {
+			return b.StartDate.Format(time.DateOnly) == start && b.EndDate.Format(time.DateOnly) == end &&
+				b.Reason == "Ramadan nights" && b.Action == database.BlackoutFlag && *b.CreatedBy == manager.ID
+		})).Return(nil).Once()
+
+		body := api.BlackoutRequest{StartDate: start, EndDate: end, Reason: " Ramadan nights ", Action: "flag"}
+		w := jobRequest(http.MethodPost, route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.CreateBlackoutHandler}, body)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		env.BlackoutStore.AssertExpectations(t)
+	})
+
+	t.Run("Forbidden_Employee", func(t *testing.T) {
+		body := api.BlackoutRequest{StartDate: start, EndDate: end, Reason: "Ramadan nights"}
+		w := jobRequest(http.MethodPost, route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.CreateBlackoutHandler}, body)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("BadRequest_InvalidAction", func(t *testing.T) {
+		body := api.BlackoutRequest{StartDate: start, EndDate: end, Reason: "Ramadan nights", Action: "warn"}
+		w := jobRequest(http.MethodPost, route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.CreateBlackoutHandler}, body)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("BadRequest_EndBeforeStart", func(t *testing.T) {
+		body := api.BlackoutRequest{StartDate: end, EndDate: start, Reason: "Ramadan nights"}
+		w := jobRequest(http.MethodPost, route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.CreateBlackoutHandler}, body)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("BadRequest_PastPeriod", func(t *testing.T) {
+		body := api.BlackoutRequest{StartDate: "2020-12-01", EndDate: "2020-12-31", Reason: "December peak"}
+		w := jobRequest(http.MethodPost, route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.CreateBlackoutHandler}, body)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestDeleteBlackoutHandler(t *testing.T) {
+	env := setupEmployeeEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	blackoutID := uuid.New()
+	route := "/:org/rules/blackouts/:id"
+	path := "/" + orgID.String() + "/rules/blackouts/" + blackoutID.String()
+
+	t.Run("Success", func(t *testing.T) {
+		env.BlackoutStore.On("DeleteBlackout", orgID, blackoutID).Return(nil).Once()
+
+		w := jobRequest(http.MethodDelete, route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.DeleteBlackoutHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.BlackoutStore.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env.BlackoutStore.On("DeleteBlackout", orgID, blackoutID).Return(sql.ErrNoRows).Once()
+
+		w := jobRequest(http.MethodDelete, route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.DeleteBlackoutHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		w := jobRequest(http.MethodDelete, route, "/"+orgID.String()+"/rules/blackouts/nope",
+			[]gin.HandlerFunc{authMiddleware(admin), env.Handler.DeleteBlackoutHandler}, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestGetApprovalChainsHandler(t *testing.T) {
+	env := setupEmployeeEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	route := "/:org/rules/approval-chains"
+	path := "/" + orgID.String() + "/rules/approval-chains"
+
+	t.Run("Success_MergesDefaults", func(t *testing.T) {
+		env.ApprovalStore.On("GetApprovalChains", orgID).Return([]database.ApprovalChain{
+			{RequestType: "holiday", Steps: []string{"manager", "admin"}},
+		}, nil).Once()
+
+		w := jobRequest(http.MethodGet, route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetApprovalChainsHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data []database.ApprovalChain `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, []database.ApprovalChain{
+			{RequestType: "calloff", Steps: []string{"manager"}, Default: true},
+			{RequestType: "holiday", Steps: []string{"manager", "admin"}},
+			{RequestType: "resign", Steps: []string{"manager", "admin"}, Default: true},
+		}, response.Data)
+	})
+
+	t.Run("Forbidden_Employee", func(t *testing.T) {
+		w := jobRequest(http.MethodGet, route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetApprovalChainsHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestSetApprovalChainHandler(t *testing.T) {
+	env := setupEmployeeEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/rules/approval-chains/:type"
+	path := "/" + orgID.String() + "/rules/approval-chains/"
+
+	t.Run("Success", func(t *testing.T) {
+		env.ApprovalStore.On("SetApprovalChain", orgID, "holiday", []string{"manager", "admin"}, admin.ID).Return(nil).Once()
+
+		w := jobRequest(http.MethodPut, route, path+"holiday", []gin.HandlerFunc{authMiddleware(admin), env.Handler.SetApprovalChainHandler},
+			api.ApprovalChainRequest{Steps: []string{"manager", "admin"}})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.ApprovalStore.AssertExpectations(t)
+	})
+
+	t.Run("Forbidden_Manager", func(t *testing.T) {
+		w := jobRequest(http.MethodPut, route, path+"holiday", []gin.HandlerFunc{authMiddleware(manager), env.Handler.SetApprovalChainHandler},
+			api.ApprovalChainRequest{Steps: []string{"manager"}})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("BadRequest_UnknownType", func(t *testing.T) {
+		w := jobRequest(http.MethodPut, route, path+"sabbatical", []gin.HandlerFunc{authMiddleware(admin), env.Handler.SetApprovalChainHandler},
+			api.ApprovalChainRequest{Steps: []string{"manager"}})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("BadRequest_UnknownRole", func(t *testing.T) {
+		w := jobRequest(http.MethodPut, route, path+"resign", []gin.HandlerFunc{authMiddleware(admin), env.Handler.SetApprovalChainHandler},
+			api.ApprovalChainRequest{Steps: []string{"manager", "owner"}})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("BadRequest_ManagerAfterAdmin", func(t *testing.T) {
+		w := jobRequest(http.MethodPut, route, path+"resign", []gin.HandlerFunc{authMiddleware(admin), env.Handler.SetApprovalChainHandler},
+			api.ApprovalChainRequest{Steps: []string{"admin", "manager"}})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "manager steps must come before admin steps")
+	})
+}
+
+func TestResetApprovalChainHandler(t *testing.T) {
+	env := setupEmployeeEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	route := "/:org/rules/approval-chains/:type"
+	path := "/" + orgID.String() + "/rules/approval-chains/resign"
+
+	t.Run("Success", func(t *testing.T) {
+		env.ApprovalStore.On("DeleteApprovalChain", orgID, "resign").Return(nil).Once()
+
+		w := jobRequest(http.MethodDelete, route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.ResetApprovalChainHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"steps":["manager","admin"]`)
+	})
+
+	t.Run("NotFound_AlreadyDefault", func(t *testing.T) {
+		env.ApprovalStore.On("DeleteApprovalChain", orgID, "resign").Return(sql.ErrNoRows).Once()
+
+		w := jobRequest(http.MethodDelete, route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.ResetApprovalChainHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
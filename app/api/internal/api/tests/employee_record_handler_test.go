@@ -0,0 +1,355 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type EmployeeRecordTestEnv struct {
+	UserStore        *MockUserStore
+	RecordStore      *MockEmployeeRecordStore
+	RequestStore     *MockRequestStore
+	PreferencesStore *MockPreferencesStore
+	FieldStore       *MockCustomFieldStore
+	Handler          *api.EmployeeRecordHandler
+	ExportHandler    *api.PersonalDataHandler
+}
+
+func setupEmployeeRecordEnv() *EmployeeRecordTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	userStore := new(MockUserStore)
+	recordStore := new(MockEmployeeRecordStore)
+	requestStore := new(MockRequestStore)
+	preferencesStore := new(MockPreferencesStore)
+	fieldStore := new(MockCustomFieldStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &EmployeeRecordTestEnv{
+		UserStore:        userStore,
+		RecordStore:      recordStore,
+		RequestStore:     requestStore,
+		PreferencesStore: preferencesStore,
+		FieldStore:       fieldStore,
+		Handler:          api.NewEmployeeRecordHandler(userStore, recordStore, logger),
+		ExportHandler:    api.NewPersonalDataHandler(userStore, requestStore, preferencesStore, recordStore, fieldStore, logger),
+	}
+}
+
+func (env *EmployeeRecordTestEnv) ResetMocks() {
+	env.UserStore.ExpectedCalls = nil
+	env.UserStore.Calls = nil
+	env.RecordStore.ExpectedCalls = nil
+	env.RecordStore.Calls = nil
+	env.RequestStore.ExpectedCalls = nil
+	env.RequestStore.Calls = nil
+	env.PreferencesStore.ExpectedCalls = nil
+	env.PreferencesStore.Calls = nil
+	env.FieldStore.ExpectedCalls = nil
+	env.FieldStore.Calls = nil
+}
+
+func TestRecordRetainUntil(t *testing.T) {
+	now := time.Date(2026, 10, 15, 14, 30, 0, 0, time.UTC)
+
+	t.Run("DefaultsByKind", func(t *testing.T) {
+		warning, err := api.RecordRetainUntil(database.EmployeeRecordWarning, "", now)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2027, 10, 15, 0, 0, 0, 0, time.UTC), warning)
+
+		writeUp, err := api.RecordRetainUntil(database.EmployeeRecordWriteUp, "", now)
+		assert.NoError(t, err)
+		assert.True(t, writeUp.After(warning))
+	})
+
+	t.Run("Requested", func(t *testing.T) {
+		day, err := api.RecordRetainUntil(database.EmployeeRecordNote, "2027-01-31", now)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2027, 1, 31, 0, 0, 0, 0, time.UTC), day)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, requested := range []string{"2026-10-15", "2020-01-01", "2040-01-01", "next year"} {
+			_, err := api.RecordRetainUntil(database.EmployeeRecordNote, requested, now)
+			assert.Error(t, err, requested)
+		}
+	})
+}
+
+func TestCreateEmployeeRecordHandler(t *testing.T) {
+	env := setupEmployeeRecordEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager", FullName: "Alex Manager"}
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	route := "/:org/staffing/employees/:id/records"
+	path := "/" + orgID.String() + "/staffing/employees/" + employee.ID.String() + "/records"
+	body := map[string]any{"kind": "warning", "title": "Late three times this month", "details": "Discussed on the 12th"}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByID", employee.ID).Return(employee, nil).Once()
+		env.RecordStore.On("CreateRecord", orgID, mock.MatchedBy(func(r *database.EmployeeRecord) bool {
+			return r.EmployeeID == employee.ID && *r.AuthorID == manager.ID && r.Kind == "warning" && r.RetainUntil.After(time.Now())
+		})).Return(nil).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.CreateEmployeeRecordHandler}, body)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"author_name":"Alex Manager"`)
+		env.RecordStore.AssertExpectations(t)
+	})
+
+	t.Run("AboutYourself", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByID", manager.ID).Return(manager, nil).Once()
+		self := "/" + orgID.String() + "/staffing/employees/" + manager.ID.String() + "/records"
+
+		w := jobRequest("POST", route, self, []gin.HandlerFunc{authMiddleware(manager), env.Handler.CreateEmployeeRecordHandler}, body)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		env.RecordStore.AssertNotCalled(t, "CreateRecord", mock.Anything, mock.Anything)
+	})
+
+	t.Run("InvalidBody", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByID", employee.ID).Return(employee, nil)
+		cases := []map[string]any{
+			{"kind": "demotion", "title": "Demoted"},
+			{"kind": "note", "title": "   "},
+			{"kind": "note", "title": "Note", "retain_until": "2020-01-01"},
+		}
+		for _, invalid := range cases {
+			w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.CreateEmployeeRecordHandler}, invalid)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("OtherOrganization", func(t *testing.T) {
+		env.ResetMocks()
+		outsider := &database.User{ID: employee.ID, OrganizationID: uuid.New(), UserRole: "employee"}
+		env.UserStore.On("GetUserByID", employee.ID).Return(outsider, nil).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.CreateEmployeeRecordHandler}, body)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.CreateEmployeeRecordHandler}, body)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestGetEmployeeRecordsHandler(t *testing.T) {
+	env := setupEmployeeRecordEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	otherManagerID := uuid.New()
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	route := "/:org/staffing/employees/:id/records"
+	path := "/" + orgID.String() + "/staffing/employees/" + employee.ID.String() + "/records"
+
+	records := []database.EmployeeRecord{
+		{ID: uuid.New(), EmployeeID: employee.ID, AuthorID: &manager.ID, Kind: "commendation", Title: "Covered a double shift"},
+		{ID: uuid.New(), EmployeeID: employee.ID, AuthorID: &otherManagerID, Kind: "warning", Title: "Late three times"},
+		{ID: uuid.New(), EmployeeID: employee.ID, Kind: "note", Title: "Author left the company"},
+	}
+
+	t.Run("AdminSeesAll", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByID", employee.ID).Return(employee, nil).Once()
+		env.RecordStore.On("GetRecordsForEmployee", orgID, employee.ID).Return(records, nil).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.GetEmployeeRecordsHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data []database.EmployeeRecord `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response.Data, 3)
+	})
+
+	t.Run("ManagerSeesOwn", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByID", employee.ID).Return(employee, nil).Once()
+		env.RecordStore.On("GetRecordsForEmployee", orgID, employee.ID).Return(records, nil).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetEmployeeRecordsHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Covered a double shift")
+		assert.NotContains(t, w.Body.String(), "Late three times")
+		assert.NotContains(t, w.Body.String(), "Author left the company")
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetEmployeeRecordsHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		env.RecordStore.AssertNotCalled(t, "GetRecordsForEmployee", mock.Anything, mock.Anything)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByID", employee.ID).Return(employee, nil).Once()
+		env.RecordStore.On("GetRecordsForEmployee", orgID, employee.ID).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.GetEmployeeRecordsHandler}, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestDeleteEmployeeRecordHandler(t *testing.T) {
+	env := setupEmployeeRecordEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	employeeID := uuid.New()
+	otherManagerID := uuid.New()
+	recordID := uuid.New()
+	route := "/:org/staffing/employees/:id/records/:record"
+	path := "/" + orgID.String() + "/staffing/employees/" + employeeID.String() + "/records/" + recordID.String()
+
+	t.Run("Author", func(t *testing.T) {
+		env.ResetMocks()
+		record := &database.EmployeeRecord{ID: recordID, EmployeeID: employeeID, AuthorID: &manager.ID}
+		env.RecordStore.On("GetRecordByID", orgID, recordID).Return(record, nil).Once()
+		env.RecordStore.On("DeleteRecord", orgID, recordID).Return(nil).Once()
+
+		w := jobRequest("DELETE", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.DeleteEmployeeRecordHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.RecordStore.AssertExpectations(t)
+	})
+
+	t.Run("OtherManagersRecord", func(t *testing.T) {
+		env.ResetMocks()
+		record := &database.EmployeeRecord{ID: recordID, EmployeeID: employeeID, AuthorID: &otherManagerID}
+		env.RecordStore.On("GetRecordByID", orgID, recordID).Return(record, nil).Once()
+
+		w := jobRequest("DELETE", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.DeleteEmployeeRecordHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		env.RecordStore.AssertNotCalled(t, "DeleteRecord", mock.Anything, mock.Anything)
+	})
+
+	t.Run("AdminAnyRecord", func(t *testing.T) {
+		env.ResetMocks()
+		record := &database.EmployeeRecord{ID: recordID, EmployeeID: employeeID, AuthorID: &otherManagerID}
+		env.RecordStore.On("GetRecordByID", orgID, recordID).Return(record, nil).Once()
+		env.RecordStore.On("DeleteRecord", orgID, recordID).Return(nil).Once()
+
+		w := jobRequest("DELETE", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.DeleteEmployeeRecordHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("WrongEmployee", func(t *testing.T) {
+		env.ResetMocks()
+		record := &database.EmployeeRecord{ID: recordID, EmployeeID: uuid.New(), AuthorID: &manager.ID}
+		env.RecordStore.On("GetRecordByID", orgID, recordID).Return(record, nil).Once()
+
+		w := jobRequest("DELETE", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.DeleteEmployeeRecordHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.RecordStore.On("GetRecordByID", orgID, recordID).Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("DELETE", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.DeleteEmployeeRecordHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestPersonalDataExportHandlers(t *testing.T) {
+	env := setupEmployeeRecordEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee", FullName: "Sam Cook"}
+	records := []database.EmployeeRecord{{ID: uuid.New(), EmployeeID: employee.ID, Kind: "warning", Title: "Late three times"}}
+
+	expectExport := func() {
+		env.PreferencesStore.On("GetPreferencesByEmployeeID", employee.ID).Return(nil, nil).Once()
+		env.RequestStore.On("GetRequestsByEmployee", employee.ID).Return([]*database.Request{{ID: uuid.New(), EmployeeID: employee.ID, Type: "holiday"}}, nil).Once()
+		env.RecordStore.On("GetRecordsForEmployee", orgID, employee.ID).Return(records, nil).Once()
+		env.FieldStore.On("GetCustomFieldValues", orgID, database.CustomFieldEmployee, []uuid.UUID{employee.ID}).
+			Return(map[uuid.UUID]map[string]any{employee.ID: {"uniform_size": "M"}}, nil).Once()
+	}
+
+	t.Run("Self", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByID", employee.ID).Return(employee, nil).Once()
+		expectExport()
+
+		w := jobRequest("GET", "/:org/me/export", "/"+orgID.String()+"/me/export", []gin.HandlerFunc{authMiddleware(employee), env.ExportHandler.ExportMyDataHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+		var response struct {
+			Data api.PersonalDataExport `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Sam Cook", response.Data.User.FullName)
+		assert.Empty(t, response.Data.Preferences)
+		assert.Len(t, response.Data.Requests, 1)
+		// disciplinary records are part of the subject's personal data
+		assert.Len(t, response.Data.Records, 1)
+		assert.Equal(t, map[string]any{"uniform_size": "M"}, response.Data.CustomFields)
+	})
+
+	t.Run("AdminForEmployee", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByID", employee.ID).Return(employee, nil).Once()
+		expectExport()
+		path := "/" + orgID.String() + "/staffing/employees/" + employee.ID.String() + "/export"
+
+		w := jobRequest("GET", "/:org/staffing/employees/:id/export", path, []gin.HandlerFunc{authMiddleware(admin), env.ExportHandler.ExportEmployeeDataHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Late three times")
+	})
+
+	t.Run("ManagerForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+		path := "/" + orgID.String() + "/staffing/employees/" + employee.ID.String() + "/export"
+
+		w := jobRequest("GET", "/:org/staffing/employees/:id/export", path, []gin.HandlerFunc{authMiddleware(manager), env.ExportHandler.ExportEmployeeDataHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByID", employee.ID).Return(employee, nil).Once()
+		env.PreferencesStore.On("GetPreferencesByEmployeeID", employee.ID).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", "/:org/me/export", "/"+orgID.String()+"/me/export", []gin.HandlerFunc{authMiddleware(employee), env.ExportHandler.ExportMyDataHandler}, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
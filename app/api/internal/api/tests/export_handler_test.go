@@ -0,0 +1,374 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+type ExportTestEnv struct {
+	JobStore     *MockExportJobStore
+	OrderStore   *MockOrderStore
+	Encryption   *MockExportEncryptionStore
+	EmailService *MockEmailService
+	Exports      *service.ExportService
+	Handler      *api.ExportHandler
+}
+
+func setupExportEnv(t *testing.T) *ExportTestEnv {
+	gin.SetMode(gin.TestMode)
+	env := &ExportTestEnv{
+		JobStore:     new(MockExportJobStore),
+		OrderStore:   new(MockOrderStore),
+		Encryption:   new(MockExportEncryptionStore),
+		EmailService: new(MockEmailService),
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	env.Exports = service.NewExportService(env.JobStore, env.OrderStore, env.Encryption, service.NewLocalExportStorage(t.TempDir()), env.EmailService, logger)
+	env.Handler = api.NewExportHandler(env.JobStore, env.Encryption, env.Exports, logger)
+	return env
+}
+
+func TestCreateExportHandler(t *testing.T) {
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	route, path := "/:org/exports", "/"+orgID.String()+"/exports"
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupExportEnv(t)
+		env.JobStore.On("CreateExportJob", mock.MatchedBy(func(job *database.ExportJob) bool {
+			return job.OrganizationID == orgID && *job.RequestedBy == manager.ID && job.Entity == "orders" &&
+				job.From.Format(time.DateOnly) == "2020-01-01" && job.To.Format(time.DateOnly) == "2025-12-31" && job.Format == "csv"
+		})).Run(func(args mock.Arguments) {
+			job := args.Get(0).(*database.ExportJob)
+			job.ID, job.Status = uuid.New(), database.ExportJobQueued
+		}).Return(nil).Once()
+
+		w := jobRequest(http.MethodPost, route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.CreateExportHandler},
+			map[string]any{"entity": "orders", "from": "2020-01-01", "to": "2025-12-31"})
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"queued"`)
+		env.JobStore.AssertExpectations(t)
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		env := setupExportEnv(t)
+		w := jobRequest(http.MethodPost, route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.CreateExportHandler},
+			map[string]any{"entity": "orders", "from": "2020-01-01", "to": "2025-12-31"})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		env.JobStore.AssertNotCalled(t, "CreateExportJob", mock.Anything)
+	})
+
+	t.Run("InvalidRequest", func(t *testing.T) {
+		env := setupExportEnv(t)
+		for _, body := range []map[string]any{
+			{"entity": "employees", "from": "2020-01-01", "to": "2025-12-31"},
+			{"entity": "orders", "from": "2020-01-01", "to": "2025-12-31", "format": "pdf"},
+			{"entity": "deliveries", "from": "01/01/2020", "to": "2025-12-31"},
+			{"entity": "deliveries", "from": "2025-12-31", "to": "2020-01-01"},
+		} {
+			w := jobRequest(http.MethodPost, route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.CreateExportHandler}, body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+		env.JobStore.AssertNotCalled(t, "CreateExportJob", mock.Anything)
+	})
+}
+
+func TestGetExportHandler(t *testing.T) {
+	orgID, jobID := uuid.New(), uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route, path := "/:org/exports/:job", "/"+orgID.String()+"/exports/"+jobID.String()
+	day := func(value string) time.Time {
+		parsed, _ := time.Parse(time.DateOnly, value)
+		return parsed
+	}
+
+	t.Run("CompletedHasDownloadLink", func(t *testing.T) {
+		env := setupExportEnv(t)
+		key, expires := orgID.String()+"/"+jobID.String()+".csv", time.Now().Add(time.Hour)
+		env.JobStore.On("GetExportJob", orgID, jobID).Return(&database.ExportJob{ID: jobID, OrganizationID: orgID, Entity: "orders",
+			From: day("2020-01-01"), To: day("2025-12-31"), Format: "csv", Status: database.ExportJobCompleted, FileKey: &key, ExpiresAt: &expires}, nil).Once()
+
+		w := jobRequest(http.MethodGet, route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetExportHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			DownloadURL       string    `json:"download_url"`
+			DownloadExpiresAt time.Time `json:"download_expires_at"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Contains(t, response.DownloadURL, "/api/exports/download?token=")
+		// the link does not outlive the file
+		assert.WithinDuration(t, expires, response.DownloadExpiresAt, time.Second)
+	})
+
+	t.Run("QueuedHasNoLink", func(t *testing.T) {
+		env := setupExportEnv(t)
+		env.JobStore.On("GetExportJob", orgID, jobID).Return(&database.ExportJob{ID: jobID, OrganizationID: orgID, Entity: "orders",
+			Format: "csv", Status: database.ExportJobQueued}, nil).Once()
+
+		w := jobRequest(http.MethodGet, route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetExportHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "download_url")
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env := setupExportEnv(t)
+		env.JobStore.On("GetExportJob", orgID, jobID).Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest(http.MethodGet, route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetExportHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestProduceAndDownloadExport(t *testing.T) {
+	env := setupExportEnv(t)
+	orgID, jobID := uuid.New(), uuid.New()
+	from, _ := time.Parse(time.DateOnly, "2025-01-01")
+	to, _ := time.Parse(time.DateOnly, "2025-01-31")
+	total, discount := 42.5, 0.0
+	job := &database.ExportJob{ID: jobID, OrganizationID: orgID, Entity: "orders", From: from, To: to, Format: "csv",
+		Status: database.ExportJobRunning, Attempts: 1, RequesterEmail: "sam@test.com", RequesterName: "Sam"}
+
+	env.OrderStore.On("GetOrders", orgID, mock.MatchedBy(func(filter database.OrderFilter) bool {
+		// to is inclusive, the orders of the whole last day count
+		return filter.From.Day() == 1 && filter.To.Month() == time.February && filter.To.Day() == 1
+	})).Return([]database.Order{{OrderID: uuid.New(), CreateTime: from.Add(12 * time.Hour), OrderType: "takeaway",
+		OrderStatus: "completed", Channel: "direct", TotalAmount: &total, DiscountAmount: &discount}}, nil).Once()
+	env.Encryption.On("GetExportEncryption", orgID).Return(nil, nil).Once()
+	env.JobStore.On("CompleteExportJob", jobID, orgID.String()+"/"+jobID.String()+".csv", 1, mock.AnythingOfType("int64"), mock.AnythingOfType("time.Time")).Return(nil).Once()
+	var link string
+	env.EmailService.On("SendExportReadyEmail", "sam@test.com", "Sam", "orders-2025-01-01-2025-01-31.csv", mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { link = args.String(3) }).Return(nil).Once()
+
+	env.Exports.Produce(context.Background(), job)
+
+	env.JobStore.AssertExpectations(t)
+	env.EmailService.AssertExpectations(t)
+
+	router := gin.New()
+	router.GET("/api/exports/download", env.Handler.DownloadExportHandler)
+	parsed, err := url.Parse(link)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	t.Run("Download", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, parsed.RequestURI(), nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "orders-2025-01-01-2025-01-31.csv")
+		assert.Contains(t, w.Body.String(), "order_id,user_id,create_time")
+		assert.Contains(t, w.Body.String(), ",takeaway,completed,direct,42.50,0.00,,0")
+	})
+
+	t.Run("TamperedToken", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, parsed.Path+"?token="+url.QueryEscape(parsed.Query().Get("token")+"x"), nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+// pgpTestKey returns a new OpenPGP key and its armored public key, which states no algorithm preferences
+func pgpTestKey(t *testing.T) (openpgp.EntityList, string) {
+	entity, err := openpgp.NewEntity("Ops", "", "ops@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var armored bytes.Buffer
+	writer, _ := armor.Encode(&armored, openpgp.PublicKeyType, nil)
+	entity.Serialize(writer)
+	writer.Close()
+	return openpgp.EntityList{entity}, armored.String()
+}
+
+func TestProduceEncryptedExport(t *testing.T) {
+	keyring, publicKey := pgpTestKey(t)
+	passphrase := "correct horse battery"
+	from, _ := time.Parse(time.DateOnly, "2025-01-01")
+	total := 42.5
+
+	for _, tc := range []struct {
+		name       string
+		encryption *database.ExportEncryption
+		decrypt    func(data []byte) (*openpgp.MessageDetails, error)
+	}{
+		{
+			name:       "PublicKey",
+			encryption: &database.ExportEncryption{Mode: database.ExportEncryptionPGP, PublicKey: &publicKey},
+			decrypt: func(data []byte) (*openpgp.MessageDetails, error) {
+				return openpgp.ReadMessage(bytes.NewReader(data), keyring, nil, nil)
+			},
+		},
+		{
+			name:       "Passphrase",
+			encryption: &database.ExportEncryption{Mode: database.ExportEncryptionPassphrase, Passphrase: &passphrase},
+			decrypt: func(data []byte) (*openpgp.MessageDetails, error) {
+				prompt := func([]openpgp.Key, bool) ([]byte, error) { return []byte(passphrase), nil }
+				return openpgp.ReadMessage(bytes.NewReader(data), nil, prompt, nil)
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			env := setupExportEnv(t)
+			orgID, jobID := uuid.New(), uuid.New()
+			job := &database.ExportJob{ID: jobID, OrganizationID: orgID, Entity: "orders", From: from, To: from, Format: "csv",
+				Status: database.ExportJobRunning, Attempts: 1}
+			tc.encryption.OrganizationID = orgID
+
+			env.OrderStore.On("GetOrders", orgID, mock.Anything).Return([]database.Order{{OrderID: uuid.New(), CreateTime: from,
+				OrderType: "takeaway", OrderStatus: "completed", Channel: "direct", TotalAmount: &total}}, nil).Once()
+			env.Encryption.On("GetExportEncryption", orgID).Return(tc.encryption, nil).Once()
+			key := orgID.String() + "/" + jobID.String() + ".csv.gpg"
+			env.JobStore.On("CompleteExportJob", jobID, key, 1, mock.AnythingOfType("int64"), mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+			env.Exports.Produce(context.Background(), job)
+
+			env.JobStore.AssertExpectations(t)
+			assert.True(t, job.Encrypted())
+			assert.Equal(t, "orders-2025-01-01-2025-01-01.csv.gpg", job.FileName())
+
+			link, _, err := env.Exports.DownloadURL(job)
+			if !assert.NoError(t, err) {
+				return
+			}
+			router := gin.New()
+			router.GET("/api/exports/download", env.Handler.DownloadExportHandler)
+			parsed, _ := url.Parse(link)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, parsed.RequestURI(), nil)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.NotContains(t, w.Body.String(), "takeaway")
+
+			message, err := tc.decrypt(w.Body.Bytes())
+			if !assert.NoError(t, err) {
+				return
+			}
+			plaintext, err := io.ReadAll(message.UnverifiedBody)
+			assert.NoError(t, err)
+			assert.Equal(t, "orders-2025-01-01-2025-01-01.csv", message.LiteralData.FileName)
+			assert.Contains(t, string(plaintext), ",takeaway,completed,direct,42.50,")
+		})
+	}
+
+	t.Run("SettingsUnreadable_Fails", func(t *testing.T) {
+		env := setupExportEnv(t)
+		orgID, jobID := uuid.New(), uuid.New()
+		job := &database.ExportJob{ID: jobID, OrganizationID: orgID, Entity: "orders", From: from, To: from, Format: "csv", Attempts: 1}
+		env.OrderStore.On("GetOrders", orgID, mock.Anything).Return([]database.Order{}, nil).Once()
+		env.Encryption.On("GetExportEncryption", orgID).Return(nil, errors.New("db error")).Once()
+		env.JobStore.On("FailExportJob", jobID, "Failed to read the encryption settings").Return(nil).Once()
+
+		env.Exports.Produce(context.Background(), job)
+
+		env.JobStore.AssertExpectations(t)
+		env.JobStore.AssertNotCalled(t, "CompleteExportJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestExportEncryptionHandler(t *testing.T) {
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route, path := "/:org/exports/encryption", "/"+orgID.String()+"/exports/encryption"
+	_, publicKey := pgpTestKey(t)
+
+	t.Run("SetPublicKey", func(t *testing.T) {
+		env := setupExportEnv(t)
+		env.Encryption.On("SetExportEncryption", mock.MatchedBy(func(encryption *database.ExportEncryption) bool {
+			return encryption.OrganizationID == orgID && encryption.Mode == database.ExportEncryptionPGP && *encryption.UpdatedBy == admin.ID &&
+				encryption.KeyFingerprint != nil && len(*encryption.KeyFingerprint) == 40 && encryption.Passphrase == nil
+		})).Return(nil).Once()
+
+		w := jobRequest(http.MethodPut, route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.SetExportEncryptionHandler},
+			map[string]any{"mode": "pgp", "public_key": publicKey})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"key_fingerprint"`)
+		env.Encryption.AssertExpectations(t)
+	})
+
+	t.Run("SetPassphrase_NotReturned", func(t *testing.T) {
+		env := setupExportEnv(t)
+		env.Encryption.On("SetExportEncryption", mock.MatchedBy(func(encryption *database.ExportEncryption) bool {
+			return encryption.Mode == database.ExportEncryptionPassphrase && *encryption.Passphrase == "correct horse battery"
+		})).Return(nil).Once()
+
+		w := jobRequest(http.MethodPut, route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.SetExportEncryptionHandler},
+			map[string]any{"mode": "passphrase", "passphrase": "correct horse battery"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "correct horse battery")
+	})
+
+	t.Run("InvalidRequest", func(t *testing.T) {
+		env := setupExportEnv(t)
+		for _, body := range []map[string]any{
+			{"mode": "zip", "passphrase": "correct horse battery"},
+			{"mode": "pgp", "public_key": "not a key"},
+			{"mode": "pgp"},
+			{"mode": "passphrase", "passphrase": "short"},
+		} {
+			w := jobRequest(http.MethodPut, route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.SetExportEncryptionHandler}, body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+		env.Encryption.AssertNotCalled(t, "SetExportEncryption", mock.Anything)
+	})
+
+	t.Run("ManagerForbidden", func(t *testing.T) {
+		env := setupExportEnv(t)
+		w := jobRequest(http.MethodPut, route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.SetExportEncryptionHandler},
+			map[string]any{"mode": "passphrase", "passphrase": "correct horse battery"})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("GetNotEncrypted", func(t *testing.T) {
+		env := setupExportEnv(t)
+		env.Encryption.On("GetExportEncryption", orgID).Return(nil, nil).Once()
+
+		w := jobRequest(http.MethodGet, route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.GetExportEncryptionHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"data":null`)
+	})
+
+	t.Run("DeleteNotEncrypted", func(t *testing.T) {
+		env := setupExportEnv(t)
+		env.Encryption.On("DeleteExportEncryption", orgID).Return(sql.ErrNoRows).Once()
+
+		w := jobRequest(http.MethodDelete, route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.DeleteExportEncryptionHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type ExternalEventTestEnv struct {
+	ExternalEventStore *MockExternalEventStore
+	Handler            *api.ExternalEventHandler
+}
+
+func setupExternalEventEnv() *ExternalEventTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	externalEventStore := new(MockExternalEventStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &ExternalEventTestEnv{
+		ExternalEventStore: externalEventStore,
+		Handler:            api.NewExternalEventHandler(externalEventStore, logger),
+	}
+}
+
+func (env *ExternalEventTestEnv) ResetMocks() {
+	env.ExternalEventStore.ExpectedCalls = nil
+	env.ExternalEventStore.Calls = nil
+}
+
+func eventRequest(method, path string, user *database.User, handler gin.HandlerFunc, route string, body any) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.Handle(method, route, authMiddleware(user), handler)
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestEventMarkersBetween(t *testing.T) {
+	day := time.Date(2025, 6, 7, 0, 0, 0, 0, time.Local)
+	events := []database.ExternalEvent{
+		{ID: uuid.New(), Name: "Night Concert", Category: database.ExternalEventConcert, StartTime: day.Add(-4 * time.Hour), EndTime: day.Add(time.Hour)},
+		{ID: uuid.New(), Name: "Cup Final", Category: database.ExternalEventSports, StartTime: day.Add(18 * time.Hour), EndTime: day.Add(20 * time.Hour)},
+		{ID: uuid.New(), Name: "Marathon", Category: database.ExternalEventRoadClosure, StartTime: day.AddDate(0, 0, 1), EndTime: day.AddDate(0, 0, 1).Add(6 * time.Hour)},
+	}
+
+	markers := api.EventMarkersBetween(events, day, day.AddDate(0, 0, 1))
+
+	if assert.Len(t, markers, 2) {
+		assert.Equal(t, "Night Concert", markers[0].Name)
+		assert.Equal(t, "Cup Final", markers[1].Name)
+		assert.Equal(t, database.ExternalEventSports, markers[1].Category)
+	}
+	assert.Empty(t, api.EventMarkersBetween(nil, day, day.AddDate(0, 0, 1)))
+}
+
+func TestGetExternalEventsHandler(t *testing.T) {
+	env := setupExternalEventEnv()
+	orgID := uuid.New()
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	get := func(query string) *httptest.ResponseRecorder {
+		return eventRequest("GET", "/"+orgID.String()+"/events"+query, employee, env.Handler.GetExternalEventsHandler, "/:org/events", nil)
+	}
+
+	t.Run("All", func(t *testing.T) {
+		env.ResetMocks()
+		env.ExternalEventStore.On("GetEvents", orgID).Return([]database.ExternalEvent{{ID: uuid.New(), Name: "Street Fair"}}, nil).Once()
+
+		w := get("")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Street Fair")
+		env.ExternalEventStore.AssertExpectations(t)
+	})
+
+	t.Run("Window", func(t *testing.T) {
+		env.ResetMocks()
+		from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.Local)
+		env.ExternalEventStore.On("GetEventsBetween", orgID, from, from.AddDate(0, 0, 7)).Return([]database.ExternalEvent{}, nil).Once()
+
+		w := get("?from=2025-06-01&to=2025-06-07")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.ExternalEventStore.AssertExpectations(t)
+	})
+
+	t.Run("InvalidWindow", func(t *testing.T) {
+		env.ResetMocks()
+		assert.Equal(t, http.StatusBadRequest, get("?from=06-01-2025").Code)
+		assert.Equal(t, http.StatusBadRequest, get("?from=2025-06-07&to=2025-06-01").Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.ExternalEventStore.On("GetEvents", orgID).Return(nil, errors.New("db error")).Once()
+
+		w := get("")
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestCreateExternalEventHandler(t *testing.T) {
+	env := setupExternalEventEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	post := func(user *database.User, body any) *httptest.ResponseRecorder {
+		return eventRequest("POST", "/"+orgID.String()+"/events", user, env.Handler.CreateExternalEventHandler, "/:org/events", body)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.ExternalEventStore.On("CreateEvent", orgID, mock.MatchedBy(func(event *database.ExternalEvent) bool {
+			return event.Name == "Cup Final" && event.Category == database.ExternalEventSports
+		})).Return(nil).Once()
+
+		w := post(manager, gin.H{
+			"name":       "Cup Final",
+			"category":   "sports",
+			"start_time": "2025-06-07T18:00:00Z",
+			"end_time":   "2025-06-07T20:00:00Z",
+		})
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		env.ExternalEventStore.AssertExpectations(t)
+	})
+
+	t.Run("DefaultsToOther", func(t *testing.T) {
+		env.ResetMocks()
+		env.ExternalEventStore.On("CreateEvent", orgID, mock.MatchedBy(func(event *database.ExternalEvent) bool {
+			return event.Category == database.ExternalEventOther
+		})).Return(nil).Once()
+
+		w := post(manager, gin.H{"name": "Parade", "start_time": "2025-06-07T10:00:00Z", "end_time": "2025-06-07T12:00:00Z"})
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		env.ExternalEventStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := post(employee, gin.H{"name": "Parade"})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Failure_UnknownCategory", func(t *testing.T) {
+		env.ResetMocks()
+		w := post(manager, gin.H{"name": "Parade", "category": "weather", "start_time": "2025-06-07T10:00:00Z", "end_time": "2025-06-07T12:00:00Z"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Failure_EndsBeforeStart", func(t *testing.T) {
+		env.ResetMocks()
+		w := post(manager, gin.H{"name": "Parade", "start_time": "2025-06-07T12:00:00Z", "end_time": "2025-06-07T10:00:00Z"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "start_time must be before end_time")
+	})
+}
+
+func TestUpdateExternalEventHandler(t *testing.T) {
+	env := setupExternalEventEnv()
+	orgID := uuid.New()
+	eventID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	body := gin.H{"name": "Road Works", "category": "road_closure", "start_time": "2025-06-07T06:00:00Z", "end_time": "2025-06-09T18:00:00Z"}
+	put := func(id string) *httptest.ResponseRecorder {
+		return eventRequest("PUT", "/"+orgID.String()+"/events/"+id, admin, env.Handler.UpdateExternalEventHandler, "/:org/events/:id", body)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.ExternalEventStore.On("UpdateEvent", orgID, mock.MatchedBy(func(event *database.ExternalEvent) bool {
+			return event.ID == eventID && event.Category == database.ExternalEventRoadClosure
+		})).Return(nil).Once()
+
+		w := put(eventID.String())
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.ExternalEventStore.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.ExternalEventStore.On("UpdateEvent", orgID, mock.Anything).Return(sql.ErrNoRows).Once()
+
+		w := put(eventID.String())
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		env.ResetMocks()
+		assert.Equal(t, http.StatusBadRequest, put("not-a-uuid").Code)
+	})
+}
+
+func TestDeleteExternalEventHandler(t *testing.T) {
+	env := setupExternalEventEnv()
+	orgID := uuid.New()
+	eventID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	del := func(user *database.User) *httptest.ResponseRecorder {
+		return eventRequest("DELETE", "/"+orgID.String()+"/events/"+eventID.String(), user, env.Handler.DeleteExternalEventHandler, "/:org/events/:id", nil)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.ExternalEventStore.On("DeleteEvent", orgID, eventID).Return(nil).Once()
+
+		w := del(manager)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.ExternalEventStore.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.ExternalEventStore.On("DeleteEvent", orgID, eventID).Return(sql.ErrNoRows).Once()
+
+		w := del(manager)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := del(employee)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
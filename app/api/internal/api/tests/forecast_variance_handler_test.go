@@ -0,0 +1,156 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type ForecastVarianceTestEnv struct {
+	Store   *MockForecastVarianceStore
+	Handler *api.ForecastVarianceHandler
+}
+
+func setupForecastVarianceEnv() *ForecastVarianceTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	store := new(MockForecastVarianceStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &ForecastVarianceTestEnv{
+		Store:   store,
+		Handler: api.NewForecastVarianceHandler(store, logger),
+	}
+}
+
+func (env *ForecastVarianceTestEnv) ResetMocks() {
+	env.Store.ExpectedCalls = nil
+	env.Store.Calls = nil
+}
+
+func varianceAlert(date time.Time, direction string, dayForecast, dayActual int) database.ForecastVarianceAlert {
+	return database.ForecastVarianceAlert{
+		ID:                uuid.New(),
+		Date:              date,
+		ThroughHour:       14,
+		ForecastOrders:    50,
+		ActualOrders:      66,
+		VariancePercent:   32,
+		Direction:         direction,
+		DayForecastOrders: &dayForecast,
+		DayActualOrders:   &dayActual,
+	}
+}
+
+func TestSummarizeVarianceAlerts(t *testing.T) {
+	today := time.Date(2026, 10, 15, 0, 0, 0, 0, time.Local)
+	yesterday := today.AddDate(0, 0, -1)
+
+	t.Run("ConfirmedAlerts", func(t *testing.T) {
+		summary := api.SummarizeVarianceAlerts([]database.ForecastVarianceAlert{
+			varianceAlert(today, database.VarianceAbove, 100, 80),      // not settled yet
+			varianceAlert(yesterday, database.VarianceAbove, 100, 130), // held
+			varianceAlert(yesterday, database.VarianceBelow, 100, 90),  // held
+			varianceAlert(yesterday, database.VarianceAbove, 100, 95),  // turned around
+		}, today)
+
+		assert.Equal(t, 4, summary.Alerts)
+		assert.Equal(t, 3, summary.Settled)
+		assert.Equal(t, 2, summary.Confirmed)
+		assert.Equal(t, 66.7, *summary.ConfirmedPercent)
+	})
+
+	t.Run("NothingSettled", func(t *testing.T) {
+		summary := api.SummarizeVarianceAlerts([]database.ForecastVarianceAlert{varianceAlert(today, database.VarianceBelow, 100, 60)}, today)
+
+		assert.Equal(t, 1, summary.Alerts)
+		assert.Nil(t, summary.ConfirmedPercent)
+	})
+}
+
+func TestGetVarianceAlertsHandler(t *testing.T) {
+	env := setupForecastVarianceEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/dashboard/demand/variance-alerts"
+	path := "/" + orgID.String() + "/dashboard/demand/variance-alerts"
+	handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetVarianceAlertsHandler}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.Local)
+		to := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+		alerts := []database.ForecastVarianceAlert{varianceAlert(from, database.VarianceAbove, 100, 120)}
+		env.Store.On("ListAlerts", orgID, from, to).Return(alerts, nil).Once()
+
+		w := jobRequest("GET", route, path+"?from=2026-09-01&to=2026-09-30", handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data struct {
+				From    string                           `json:"from"`
+				To      string                           `json:"to"`
+				Summary api.VarianceAlertSummary         `json:"summary"`
+				Alerts  []database.ForecastVarianceAlert `json:"alerts"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "2026-09-30", resp.Data.To)
+		assert.Equal(t, 1, resp.Data.Summary.Confirmed)
+		assert.Equal(t, 120, *resp.Data.Alerts[0].DayActualOrders)
+		env.Store.AssertExpectations(t)
+	})
+
+	t.Run("DefaultPeriod", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("ListAlerts", orgID, mock.Anything, mock.Anything).Return([]database.ForecastVarianceAlert{}, nil).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		from := env.Store.Calls[0].Arguments.Get(1).(time.Time)
+		to := env.Store.Calls[0].Arguments.Get(2).(time.Time)
+		assert.Equal(t, 30, int(to.Sub(from).Round(time.Hour).Hours()/24))
+		assert.True(t, to.After(time.Now()))
+	})
+
+	t.Run("InvalidDates", func(t *testing.T) {
+		env.ResetMocks()
+
+		badFrom := jobRequest("GET", route, path+"?from=yesterday", handlers, nil)
+		reversed := jobRequest("GET", route, path+"?from=2026-09-10&to=2026-09-01", handlers, nil)
+
+		assert.Equal(t, http.StatusBadRequest, badFrom.Code)
+		assert.Equal(t, http.StatusBadRequest, reversed.Code)
+		env.Store.AssertNotCalled(t, "ListAlerts", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetVarianceAlertsHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("ListAlerts", orgID, mock.Anything, mock.Anything).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
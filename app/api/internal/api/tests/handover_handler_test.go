@@ -0,0 +1,193 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type HandoverTestEnv struct {
+	OrderStore     *MockOrderStore
+	ScheduleStore  *MockScheduleStore
+	RequestStore   *MockRequestStore
+	ShiftNoteStore *MockShiftNoteStore
+	Handler        *api.HandoverHandler
+}
+
+func setupHandoverEnv() *HandoverTestEnv {
+	gin.SetMode(gin.TestMode)
+	env := &HandoverTestEnv{
+		OrderStore:     new(MockOrderStore),
+		ScheduleStore:  new(MockScheduleStore),
+		RequestStore:   new(MockRequestStore),
+		ShiftNoteStore: new(MockShiftNoteStore),
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	env.Handler = api.NewHandoverHandler(env.OrderStore, env.ScheduleStore, env.RequestStore, env.ShiftNoteStore, logger)
+	return env
+}
+
+func TestGetHandoverReportHandler(t *testing.T) {
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/handover"
+	path := "/" + orgID.String() + "/handover"
+	day := time.Date(2026, 10, 20, 0, 0, 0, 0, time.Local)
+	shifts := []database.Shift{
+		{EmployeeID: uuid.New(), EmployeeName: "Morning", Date: day, StartTime: "08:00:00", EndTime: "16:00:00"},
+		{EmployeeID: uuid.New(), EmployeeName: "Evening A", Date: day, StartTime: "16:00:00", EndTime: "23:00:00"},
+		{EmployeeID: uuid.New(), EmployeeName: "Evening B", Date: day, StartTime: "16:00:00", EndTime: "20:00:00"},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupHandoverEnv()
+		env.ScheduleStore.On("GetShiftsFrom", orgID, day).Return(shifts, nil).Once()
+		env.OrderStore.On("GetOrders", orgID, mock.MatchedBy(func(filter database.OrderFilter) bool {
+			return filter.From != nil && len(filter.Statuses) == 1 && filter.Statuses[0] == "incompleted"
+		})).Return([]database.Order{{OrderID: uuid.New(), OrderStatus: "incompleted"}}, nil).Once()
+		env.OrderStore.On("GetChannelBreakdown", orgID, mock.Anything, mock.Anything).Return([]database.ChannelBreakdown{
+			{Channel: "pos", Orders: 3, DeliveryOrders: 1, Revenue: 60},
+			{Channel: "web", Orders: 1, Revenue: 20},
+		}, nil).Once()
+		env.RequestStore.On("GetRequestsByOrganization", orgID).Return([]*database.RequestWithEmployee{
+			{Request: database.Request{ID: uuid.New(), Status: "in queue"}, EmployeeName: "Sam"},
+			{Request: database.Request{ID: uuid.New(), Status: "accepted"}, EmployeeName: "Alex"},
+		}, nil).Once()
+		env.ShiftNoteStore.On("GetUnresolvedShiftNotes", orgID).Return([]database.ShiftNote{
+			{ID: uuid.New(), Kind: database.ShiftNoteGeneral, Body: "Fridge 2 is noisy"},
+			{ID: uuid.New(), Kind: database.ShiftNoteLowStock, Body: "Two bags of oat milk left"},
+		}, nil).Once()
+
+		w := jobRequest("GET", route, path+"?shift=2026-10-20T16:00", []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetHandoverReportHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		body := w.Body.String()
+		assert.Contains(t, body, `"employee_name":"Evening A"`)
+		assert.Contains(t, body, `"employee_name":"Evening B"`)
+		assert.NotContains(t, body, `"employee_name":"Morning"`)
+		assert.Contains(t, body, `"employee_name":"Sam"`)
+		assert.NotContains(t, body, `"employee_name":"Alex"`)
+		assert.Contains(t, body, `"shift_notes":[{`)
+		assert.Contains(t, body, `"low_stock_alerts":[{`)
+		assert.Contains(t, body, `"orders":4`)
+		assert.Contains(t, body, `"revenue":80`)
+		assert.Contains(t, body, `"average_order_value":20`)
+	})
+
+	t.Run("SectionFails", func(t *testing.T) {
+		env := setupHandoverEnv()
+		env.ScheduleStore.On("GetShiftsFrom", orgID, day).Return([]database.Shift{}, nil).Once()
+		env.OrderStore.On("GetOrders", orgID, mock.Anything).Return([]database.Order{}, nil).Once()
+		env.OrderStore.On("GetChannelBreakdown", orgID, mock.Anything, mock.Anything).Return(nil, errors.New("db error")).Once()
+		env.RequestStore.On("GetRequestsByOrganization", orgID).Return([]*database.RequestWithEmployee{}, nil).Once()
+		env.ShiftNoteStore.On("GetUnresolvedShiftNotes", orgID).Return([]database.ShiftNote{}, nil).Once()
+
+		w := jobRequest("GET", route, path+"?shift=2026-10-20T16:00", []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetHandoverReportHandler}, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("InvalidShift", func(t *testing.T) {
+		env := setupHandoverEnv()
+
+		w := jobRequest("GET", route, path+"?shift=tonight", []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetHandoverReportHandler}, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Forbidden_Employee", func(t *testing.T) {
+		env := setupHandoverEnv()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetHandoverReportHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestCreateShiftNoteHandler(t *testing.T) {
+	orgID := uuid.New()
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee", FullName: "Sam"}
+	route := "/:org/handover/notes"
+	path := "/" + orgID.String() + "/handover/notes"
+	itemID := uuid.New()
+
+	t.Run("LowStock", func(t *testing.T) {
+		env := setupHandoverEnv()
+		env.ShiftNoteStore.On("CreateShiftNote", orgID, mock.MatchedBy(func(note *database.ShiftNote) bool {
+			return note.Kind == database.ShiftNoteLowStock && *note.ItemID == itemID && note.Body == "Two bags left" && *note.CreatedBy == employee.ID
+		})).Return(nil).Once()
+
+		body := map[string]any{"kind": "low_stock", "item_id": itemID, "body": " Two bags left "}
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.CreateShiftNoteHandler}, body)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		env.ShiftNoteStore.AssertExpectations(t)
+	})
+
+	t.Run("ItemNotFound", func(t *testing.T) {
+		env := setupHandoverEnv()
+		env.ShiftNoteStore.On("CreateShiftNote", orgID, mock.Anything).Return(sql.ErrNoRows).Once()
+
+		body := map[string]any{"kind": "low_stock", "item_id": itemID, "body": "Two bags left"}
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.CreateShiftNoteHandler}, body)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("InvalidKind", func(t *testing.T) {
+		env := setupHandoverEnv()
+
+		body := map[string]any{"kind": "urgent", "body": "Call the plumber"}
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.CreateShiftNoteHandler}, body)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		env.ShiftNoteStore.AssertNotCalled(t, "CreateShiftNote", mock.Anything, mock.Anything)
+	})
+}
+
+func TestResolveShiftNoteHandler(t *testing.T) {
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	noteID := uuid.New()
+	route := "/:org/handover/notes/:id/resolve"
+	path := "/" + orgID.String() + "/handover/notes/" + noteID.String() + "/resolve"
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupHandoverEnv()
+		env.ShiftNoteStore.On("ResolveShiftNote", orgID, noteID, manager.ID).Return(nil).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.ResolveShiftNoteHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("AlreadyResolved", func(t *testing.T) {
+		env := setupHandoverEnv()
+		env.ShiftNoteStore.On("ResolveShiftNote", orgID, noteID, manager.ID).Return(sql.ErrNoRows).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.ResolveShiftNoteHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Forbidden_Employee", func(t *testing.T) {
+		env := setupHandoverEnv()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.ResolveShiftNoteHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type IngestionRuleTestEnv struct {
+	RuleStore *MockIngestionRuleStore
+	Handler   *api.IngestionRuleHandler
+}
+
+func setupIngestionRuleEnv() *IngestionRuleTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	ruleStore := new(MockIngestionRuleStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &IngestionRuleTestEnv{
+		RuleStore: ruleStore,
+		Handler:   api.NewIngestionRuleHandler(ruleStore, logger),
+	}
+}
+
+func (env *IngestionRuleTestEnv) ResetMocks() {
+	env.RuleStore.ExpectedCalls = nil
+	env.RuleStore.Calls = nil
+}
+
+func TestGetIngestionRulesHandler(t *testing.T) {
+	env := setupIngestionRuleEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/rules/validation"
+	path := "/" + orgID.String() + "/rules/validation"
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		rules := []database.IngestionRule{{ID: uuid.New(), Dataset: "orders", Field: "total_amount", Operator: "max", Value: "2000"}}
+		env.RuleStore.On("GetRules", orgID).Return(rules, nil).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetIngestionRulesHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "total_amount")
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetIngestionRulesHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestCreateIngestionRuleHandler(t *testing.T) {
+	env := setupIngestionRuleEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	route := "/:org/rules/validation"
+	path := "/" + orgID.String() + "/rules/validation"
+	handlers := []gin.HandlerFunc{authMiddleware(admin), env.Handler.CreateIngestionRuleHandler}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.RuleStore.On("CreateRule", orgID, mock.MatchedBy(func(rule *database.IngestionRule) bool {
+			return rule.Dataset == "orders" && rule.Field == "total_amount" && rule.Operator == "max" &&
+				rule.Value == "2000" && *rule.Message == "Orders over $2,000 are typos" && *rule.CreatedBy == admin.ID
+		})).Return(nil).Once()
+
+		w := jobRequest("POST", route, path, handlers, map[string]any{
+			"dataset": "orders", "field": " total_amount ", "operator": "max", "value": "2000", "message": "Orders over $2,000 are typos",
+		})
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		env.RuleStore.AssertExpectations(t)
+	})
+
+	t.Run("RequiredIgnoresValue", func(t *testing.T) {
+		env.ResetMocks()
+		env.RuleStore.On("CreateRule", orgID, mock.MatchedBy(func(rule *database.IngestionRule) bool {
+			return rule.Operator == "required" && rule.Value == "" && rule.Message == nil
+		})).Return(nil).Once()
+
+		w := jobRequest("POST", route, path, handlers, map[string]any{"dataset": "deliveries", "field": "delivered_time", "operator": "required", "value": "yes", "message": " "})
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("InvalidRule", func(t *testing.T) {
+		env.ResetMocks()
+		for _, body := range []map[string]any{
+			{"dataset": "payroll", "field": "total_amount", "operator": "max", "value": "2000"},
+			{"dataset": "orders", "field": "tip", "operator": "max", "value": "20"},
+			{"dataset": "orders", "field": "total_amount", "operator": "below", "value": "2000"},
+			{"dataset": "orders", "field": "total_amount", "operator": "max", "value": "two thousand"},
+			{"dataset": "orders", "field": "order_type", "operator": "one_of", "value": " , "},
+		} {
+			w := jobRequest("POST", route, path, handlers, body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+		env.RuleStore.AssertNotCalled(t, "CreateRule", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ManagerForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.CreateIngestionRuleHandler},
+			map[string]any{"dataset": "orders", "field": "rating", "operator": "min", "value": "1"})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.RuleStore.On("CreateRule", orgID, mock.Anything).Return(errors.New("db error")).Once()
+
+		w := jobRequest("POST", route, path, handlers, map[string]any{"dataset": "orders", "field": "order_type", "operator": "one_of", "value": "takeaway,delivery"})
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestDeleteIngestionRuleHandler(t *testing.T) {
+	env := setupIngestionRuleEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	ruleID := uuid.New()
+	route := "/:org/rules/validation/:id"
+	path := "/" + orgID.String() + "/rules/validation/" + ruleID.String()
+	handlers := []gin.HandlerFunc{authMiddleware(admin), env.Handler.DeleteIngestionRuleHandler}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.RuleStore.On("DeleteRule", orgID, ruleID).Return(nil).Once()
+
+		w := jobRequest("DELETE", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.RuleStore.On("DeleteRule", orgID, ruleID).Return(sql.ErrNoRows).Once()
+
+		w := jobRequest("DELETE", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("DELETE", route, "/"+orgID.String()+"/rules/validation/abc", handlers, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -1,18 +1,22 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/clockwise/clockwise/backend/internal/api"
 	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 type InsightTestEnv struct {
@@ -131,4 +135,172 @@ func TestGetInsightsHandler(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, w.Code)
 		assert.Contains(t, w.Body.String(), "invalid user in context")
 	})
+
+	t.Run("Success_Admin_WithKPITargets", func(t *testing.T) {
+		adminUser := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+		kpiStore := new(MockKPITargetStore)
+		scheduleStore := new(MockScheduleStore)
+		userStore := new(MockUserStore)
+		handler := api.NewInsightHandler(env.InsightStore, env.Handler.Logger)
+		handler.KPIs = service.NewKPITracker(kpiStore, scheduleStore, userStore)
+
+		env.InsightStore.On("GetInsightsForAdmin", orgID).Return(dummyInsights, nil).Once()
+		kpiStore.On("GetTargets", orgID).Return(&database.KPITargets{OrganizationID: orgID, WeeklyRevenue: kpiFloat(7000)}, nil).Once()
+		kpiStore.On("GetSales", orgID, mock.Anything, mock.Anything).Return(&database.KPISales{Revenue: 7700}, nil).Once()
+		userStore.On("GetUsersByOrganization", orgID).Return([]*database.User{}, nil).Once()
+		scheduleStore.On("GetScheduledHours", orgID, mock.Anything, mock.Anything).Return([]database.EmployeeHours{}, nil).Once()
+
+		r := gin.New()
+		r.GET("/:org/insights", authMiddleware(adminUser), handler.GetInsightsHandler)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/insights", nil)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data []database.Insight `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		if assert.Len(t, resp.Data, 2) {
+			assert.Equal(t, "Revenue vs Target (Last 7 Days)", resp.Data[1].Title)
+			assert.Equal(t, "7700.00 against 7000.00 (+10.00%, met)", resp.Data[1].Statistic)
+			assert.Equal(t, database.DrillDownKPIProgress, resp.Data[1].DrillDown)
+		}
+	})
+
+	t.Run("Success_Employee_NoKPITargets", func(t *testing.T) {
+		employeeUser := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+		kpiStore := new(MockKPITargetStore)
+		handler := api.NewInsightHandler(env.InsightStore, env.Handler.Logger)
+		handler.KPIs = service.NewKPITracker(kpiStore, new(MockScheduleStore), new(MockUserStore))
+
+		env.InsightStore.On("GetInsightsForEmployee", orgID, employeeUser.ID).Return(dummyInsights, nil).Once()
+
+		r := gin.New()
+		r.GET("/:org/insights", authMiddleware(employeeUser), handler.GetInsightsHandler)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/insights", nil)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		kpiStore.AssertNotCalled(t, "GetTargets", mock.Anything)
+	})
+}
+
+func TestInsightDrillDownHandlers(t *testing.T) {
+	env := setupInsightEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	base := "/" + orgID.String() + "/insights"
+
+	request := func(user *database.User, route string, handler gin.HandlerFunc, query string) *httptest.ResponseRecorder {
+		r := gin.New()
+		r.GET("/:org/insights"+route, authMiddleware(user), handler)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", base+route+query, nil)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("BusiestHour", func(t *testing.T) {
+		hours := make([]database.HourlyOrders, 24)
+		for hour := range hours {
+			hours[hour].Hour = hour
+		}
+		hours[12].Orders, hours[19].Orders, hours[20].Orders = 5, 9, 9
+		from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+		to := time.Date(2026, 10, 8, 0, 0, 0, 0, time.Local)
+		env.InsightStore.On("GetHourlyOrders", orgID, &from, &to).Return(hours, nil).Once()
+
+		w := request(manager, "/busiest-hour", env.Handler.GetBusiestHourDrillDownHandler, "?from=2026-10-01&to=2026-10-07")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data struct {
+				From        string                  `json:"from"`
+				To          string                  `json:"to"`
+				BusiestHour *int                    `json:"busiest_hour"`
+				Hours       []database.HourlyOrders `json:"hours"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "2026-10-01", resp.Data.From)
+		assert.Equal(t, "2026-10-07", resp.Data.To)
+		// ties go to the earliest hour
+		if assert.NotNil(t, resp.Data.BusiestHour) {
+			assert.Equal(t, 19, *resp.Data.BusiestHour)
+		}
+		assert.Len(t, resp.Data.Hours, 24)
+		env.InsightStore.AssertExpectations(t)
+	})
+
+	t.Run("BusiestHour_NoOrders", func(t *testing.T) {
+		env.InsightStore.On("GetHourlyOrders", orgID, (*time.Time)(nil), (*time.Time)(nil)).Return(make([]database.HourlyOrders, 24), nil).Once()
+
+		w := request(manager, "/busiest-hour", env.Handler.GetBusiestHourDrillDownHandler, "")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"busiest_hour":null`)
+		assert.Contains(t, w.Body.String(), `"from":null`)
+	})
+
+	t.Run("MostSellingItems", func(t *testing.T) {
+		items := []database.TopItem{{ItemID: uuid.New(), Name: "Burger", SoldCount: 100, Quantity: 130, Revenue: 1300}}
+		env.InsightStore.On("GetTopItems", orgID, (*time.Time)(nil), (*time.Time)(nil), 5).Return(items, nil).Once()
+
+		w := request(manager, "/most-selling-items", env.Handler.GetMostSellingItemsDrillDownHandler, "?limit=5")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"quantity":130`)
+		env.InsightStore.AssertExpectations(t)
+	})
+
+	t.Run("MostSellingItems_InvalidLimit", func(t *testing.T) {
+		w := request(manager, "/most-selling-items", env.Handler.GetMostSellingItemsDrillDownHandler, "?limit=500")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = request(manager, "/most-selling-items", env.Handler.GetMostSellingItemsDrillDownHandler, "?from=yesterday")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("DeliveriesToday", func(t *testing.T) {
+		delivered, out := "delivered", "out for delivery"
+		env.InsightStore.On("GetDeliveryOrdersToday", orgID).Return([]database.DeliveryOrder{
+			{OrderID: uuid.New()},
+			{OrderID: uuid.New(), DeliveryStatus: &out},
+			{OrderID: uuid.New(), DeliveryStatus: &delivered},
+			{OrderID: uuid.New(), DeliveryStatus: &delivered},
+		}, nil).Once()
+
+		w := request(manager, "/deliveries-today", env.Handler.GetDeliveriesTodayDrillDownHandler, "")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data struct {
+				Total    int                      `json:"total"`
+				ByStatus map[string]int           `json:"by_status"`
+				Orders   []database.DeliveryOrder `json:"orders"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, 4, resp.Data.Total)
+		assert.Equal(t, map[string]int{"pending": 1, "out for delivery": 1, "delivered": 2, "not delivered": 0}, resp.Data.ByStatus)
+		assert.Len(t, resp.Data.Orders, 4)
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		w := request(employee, "/deliveries-today", env.Handler.GetDeliveriesTodayDrillDownHandler, "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("StoreError", func(t *testing.T) {
+		env.InsightStore.On("GetDeliveryOrdersToday", orgID).Return(nil, errors.New("db error")).Once()
+
+		w := request(manager, "/deliveries-today", env.Handler.GetDeliveriesTodayDrillDownHandler, "")
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
 }
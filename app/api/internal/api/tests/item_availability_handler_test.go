@@ -0,0 +1,297 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type ItemAvailabilityTestEnv struct {
+	ItemAvailabilityStore *MockItemAvailabilityStore
+	OrderStore            *MockOrderStore
+	Handler               *api.ItemAvailabilityHandler
+}
+
+func setupItemAvailabilityEnv() *ItemAvailabilityTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	itemAvailabilityStore := new(MockItemAvailabilityStore)
+	orderStore := new(MockOrderStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &ItemAvailabilityTestEnv{
+		ItemAvailabilityStore: itemAvailabilityStore,
+		OrderStore:            orderStore,
+		Handler:               api.NewItemAvailabilityHandler(itemAvailabilityStore, orderStore, logger),
+	}
+}
+
+func (env *ItemAvailabilityTestEnv) ResetMocks() {
+	env.ItemAvailabilityStore.ExpectedCalls = nil
+	env.ItemAvailabilityStore.Calls = nil
+	env.OrderStore.ExpectedCalls = nil
+	env.OrderStore.Calls = nil
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestUnavailableItems(t *testing.T) {
+	pumpkinSoup := uuid.New()
+	lunchMenu := uuid.New()
+	windows := []database.ItemAvailabilityWindow{
+		{ItemID: pumpkinSoup, StartDate: strPtr("2025-09-15"), EndDate: strPtr("2025-11-30")},
+		{ItemID: lunchMenu, StartTime: strPtr("11:30"), EndTime: strPtr("14:30")},
+		{ItemID: lunchMenu, StartDate: strPtr("2025-12-24"), EndDate: strPtr("2025-12-24"), StartTime: strPtr("18:00"), EndTime: strPtr("22:00")},
+	}
+
+	t.Run("InSeasonAtLunch", func(t *testing.T) {
+		unavailable := api.UnavailableItemsAt(windows, time.Date(2025, 10, 1, 12, 0, 0, 0, time.Local))
+		assert.Empty(t, unavailable)
+	})
+
+	t.Run("OutOfSeasonInTheEvening", func(t *testing.T) {
+		unavailable := api.UnavailableItemsAt(windows, time.Date(2025, 12, 1, 14, 30, 0, 0, time.Local))
+		assert.True(t, unavailable[pumpkinSoup])
+		assert.True(t, unavailable[lunchMenu])
+	})
+
+	t.Run("AnyWindowMakesItAvailable", func(t *testing.T) {
+		unavailable := api.UnavailableItemsAt(windows, time.Date(2025, 12, 24, 19, 0, 0, 0, time.Local))
+		assert.False(t, unavailable[lunchMenu])
+	})
+
+	t.Run("WholeDay", func(t *testing.T) {
+		unavailable := api.UnavailableItemsOn(windows, time.Date(2025, 12, 1, 0, 0, 0, 0, time.Local))
+		assert.True(t, unavailable[pumpkinSoup])
+		assert.False(t, unavailable[lunchMenu])
+	})
+}
+
+func TestSetItemAvailabilityHandler(t *testing.T) {
+	env := setupItemAvailabilityEnv()
+	orgID := uuid.New()
+	itemID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+
+	put := func(user *database.User, item string, body any) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.PUT("/:org/items/:item/availability", authMiddleware(user), env.Handler.SetItemAvailabilityHandler)
+		payload, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/"+orgID.String()+"/items/"+item+"/availability", bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.ItemAvailabilityStore.On("SetItemAvailability", orgID, itemID, mock.MatchedBy(func(windows []database.ItemAvailabilityWindow) bool {
+			return len(windows) == 1 && *windows[0].StartTime == "11:30" && windows[0].StartDate == nil
+		})).Return(nil).Once()
+
+		w := put(manager, itemID.String(), gin.H{"windows": []gin.H{{"start_time": "11:30", "end_time": "14:30"}}})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Item availability updated successfully")
+		env.ItemAvailabilityStore.AssertExpectations(t)
+	})
+
+	t.Run("ClearWindows", func(t *testing.T) {
+		env.ResetMocks()
+		env.ItemAvailabilityStore.On("SetItemAvailability", orgID, itemID, []database.ItemAvailabilityWindow{}).Return(nil).Once()
+
+		w := put(manager, itemID.String(), gin.H{"windows": []gin.H{}})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.ItemAvailabilityStore.AssertExpectations(t)
+	})
+
+	t.Run("InvalidWindows", func(t *testing.T) {
+		env.ResetMocks()
+		for _, window := range []gin.H{
+			{},
+			{"start_date": "2025-11-30", "end_date": "2025-09-15"},
+			{"start_date": "15/09/2025"},
+			{"start_time": "11:30"},
+			{"start_time": "14:30", "end_time": "11:30"},
+			{"start_time": "25:00", "end_time": "26:00"},
+		} {
+			w := put(manager, itemID.String(), gin.H{"windows": []gin.H{window}})
+			assert.Equal(t, http.StatusBadRequest, w.Code, window)
+		}
+		assert.Equal(t, http.StatusBadRequest, put(manager, "not-a-uuid", gin.H{"windows": []gin.H{}}).Code)
+		env.ItemAvailabilityStore.AssertNotCalled(t, "SetItemAvailability", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ItemNotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.ItemAvailabilityStore.On("SetItemAvailability", orgID, itemID, mock.Anything).Return(sql.ErrNoRows).Once()
+
+		w := put(manager, itemID.String(), gin.H{"windows": []gin.H{{"start_date": "2025-09-15"}}})
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := put(employee, itemID.String(), gin.H{"windows": []gin.H{}})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.ItemAvailabilityStore.On("SetItemAvailability", orgID, itemID, mock.Anything).Return(errors.New("db error")).Once()
+
+		w := put(manager, itemID.String(), gin.H{"windows": []gin.H{}})
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestGetItemAvailabilityHandler(t *testing.T) {
+	env := setupItemAvailabilityEnv()
+	orgID := uuid.New()
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+	get := func() *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/:org/items/availability", authMiddleware(employee), env.Handler.GetItemAvailabilityHandler)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/items/availability", nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		pastSeason := uuid.New()
+		env.ItemAvailabilityStore.On("GetAvailabilityWindows", orgID).Return([]database.ItemAvailabilityWindow{
+			{ItemID: pastSeason, StartDate: strPtr("2000-01-01"), EndDate: strPtr("2000-03-31")},
+		}, nil).Once()
+
+		w := get()
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data struct {
+				Windows        []database.ItemAvailabilityWindow `json:"windows"`
+				UnavailableNow []uuid.UUID                       `json:"unavailable_now"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Len(t, resp.Data.Windows, 1)
+		assert.Equal(t, []uuid.UUID{pastSeason}, resp.Data.UnavailableNow)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.ItemAvailabilityStore.On("GetAvailabilityWindows", orgID).Return(nil, errors.New("db error")).Once()
+
+		assert.Equal(t, http.StatusInternalServerError, get().Code)
+	})
+}
+
+func TestValidateOrderHandler(t *testing.T) {
+	env := setupItemAvailabilityEnv()
+	orgID := uuid.New()
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	burger := uuid.New()
+	lunchMenu := uuid.New()
+	discontinued := uuid.New()
+	items := []database.Item{{ItemID: burger, Name: "Burger", IsActive: true}, {ItemID: lunchMenu, Name: "Lunch menu", IsActive: true},
+		{ItemID: discontinued, Name: "Pumpkin soup"}}
+	windows := []database.ItemAvailabilityWindow{
+		{ItemID: lunchMenu, StartTime: strPtr("11:30"), EndTime: strPtr("14:30")},
+	}
+
+	post := func(body any) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.POST("/:org/orders/validate", authMiddleware(employee), env.Handler.ValidateOrderHandler)
+		payload, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/orders/validate", bytes.NewBuffer(payload))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+	at := func(hour, minute int) time.Time {
+		return time.Date(2025, 10, 1, hour, minute, 0, 0, time.Local)
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetAllItems", orgID).Return(items, nil).Once()
+		env.ItemAvailabilityStore.On("GetAvailabilityWindows", orgID).Return(windows, nil).Once()
+
+		w := post(gin.H{"at": at(12, 0), "items": []gin.H{{"item_id": burger, "quantity": 2}, {"item_id": lunchMenu, "quantity": 1}}})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Order is valid")
+	})
+
+	t.Run("UnavailableAndUnknownItems", func(t *testing.T) {
+		env.ResetMocks()
+		unknown := uuid.New()
+		env.OrderStore.On("GetAllItems", orgID).Return(items, nil).Once()
+		env.ItemAvailabilityStore.On("GetAvailabilityWindows", orgID).Return(windows, nil).Once()
+
+		w := post(gin.H{"at": at(19, 0), "items": []gin.H{{"item_id": lunchMenu, "quantity": 1}, {"item_id": unknown, "quantity": 1}}})
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var resp struct {
+			UnknownItems     []uuid.UUID `json:"unknown_items"`
+			UnavailableItems []uuid.UUID `json:"unavailable_items"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, []uuid.UUID{unknown}, resp.UnknownItems)
+		assert.Equal(t, []uuid.UUID{lunchMenu}, resp.UnavailableItems)
+	})
+
+	t.Run("DiscontinuedItemUnavailable", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetAllItems", orgID).Return(items, nil).Once()
+		env.ItemAvailabilityStore.On("GetAvailabilityWindows", orgID).Return(windows, nil).Once()
+
+		w := post(gin.H{"at": at(12, 0), "items": []gin.H{{"item_id": burger, "quantity": 1}, {"item_id": discontinued, "quantity": 1}}})
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var resp struct {
+			UnavailableItems []uuid.UUID `json:"unavailable_items"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, []uuid.UUID{discontinued}, resp.UnavailableItems)
+	})
+
+	t.Run("InvalidBody", func(t *testing.T) {
+		env.ResetMocks()
+		assert.Equal(t, http.StatusBadRequest, post(gin.H{"items": []gin.H{}}).Code)
+		assert.Equal(t, http.StatusBadRequest, post(gin.H{"items": []gin.H{{"item_id": burger, "quantity": 0}}}).Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetAllItems", orgID).Return(nil, errors.New("db error")).Once()
+
+		w := post(gin.H{"items": []gin.H{{"item_id": burger, "quantity": 1}}})
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
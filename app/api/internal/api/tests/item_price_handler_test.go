@@ -0,0 +1,202 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type ItemPriceTestEnv struct {
+	ItemPriceStore *MockItemPriceStore
+	OrderStore     *MockOrderStore
+	Handler        *api.ItemPriceHandler
+}
+
+func setupItemPriceEnv() *ItemPriceTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	itemPriceStore := new(MockItemPriceStore)
+	orderStore := new(MockOrderStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &ItemPriceTestEnv{
+		ItemPriceStore: itemPriceStore,
+		OrderStore:     orderStore,
+		Handler:        api.NewItemPriceHandler(itemPriceStore, orderStore, logger),
+	}
+}
+
+func (env *ItemPriceTestEnv) ResetMocks() {
+	env.ItemPriceStore.ExpectedCalls = nil
+	env.ItemPriceStore.Calls = nil
+	env.OrderStore.ExpectedCalls = nil
+	env.OrderStore.Calls = nil
+}
+
+func pricePtr(price float64) *float64 { return &price }
+
+func TestComputePriceChanges(t *testing.T) {
+	burger, fries, water := uuid.New(), uuid.New(), uuid.New()
+	items := []database.Item{
+		{ItemID: burger, Name: "Burger", Price: pricePtr(12.50)},
+		{ItemID: fries, Name: "Fries", Price: pricePtr(3.99)},
+		{ItemID: water, Name: "Water"},
+	}
+
+	t.Run("Percentage", func(t *testing.T) {
+		changes, err := api.ComputePriceChanges(items, api.BulkPriceUpdateRequest{AdjustmentType: "percentage", Value: 10})
+		assert.NoError(t, err)
+		if assert.Len(t, changes, 2) {
+			assert.Equal(t, 13.75, changes[0].NewPrice)
+			assert.Equal(t, 4.39, changes[1].NewPrice)
+			assert.Equal(t, 3.99, changes[1].OldPrice)
+		}
+	})
+
+	t.Run("FixedOnSelectedItemsWithRounding", func(t *testing.T) {
+		changes, err := api.ComputePriceChanges(items, api.BulkPriceUpdateRequest{
+			AdjustmentType: "fixed", Value: 0.4, ItemIDs: []uuid.UUID{fries}, RoundTo: pricePtr(0.5),
+		})
+		assert.NoError(t, err)
+		if assert.Len(t, changes, 1) {
+			assert.Equal(t, fries, changes[0].ItemID)
+			assert.Equal(t, 4.5, changes[0].NewPrice)
+		}
+	})
+
+	t.Run("UnchangedPricesAreSkipped", func(t *testing.T) {
+		changes, err := api.ComputePriceChanges(items, api.BulkPriceUpdateRequest{
+			AdjustmentType: "fixed", Value: 0.1, ItemIDs: []uuid.UUID{burger}, RoundTo: pricePtr(0.5),
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, req := range []api.BulkPriceUpdateRequest{
+			{AdjustmentType: "fixed", Value: 0},
+			{AdjustmentType: "percentage", Value: -100},
+			{AdjustmentType: "fixed", Value: -5},
+			{AdjustmentType: "fixed", Value: 1, ItemIDs: []uuid.UUID{uuid.New()}},
+			{AdjustmentType: "fixed", Value: 1, RoundTo: pricePtr(0)},
+		} {
+			_, err := api.ComputePriceChanges(items, req)
+			assert.Error(t, err, req)
+		}
+	})
+}
+
+func TestBulkPriceUpdateHandler(t *testing.T) {
+	env := setupItemPriceEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	burger := uuid.New()
+	items := []database.Item{{ItemID: burger, Name: "Burger", Price: pricePtr(10)}}
+
+	update := func(user *database.User, query string, body any) int {
+		w := jobRequest("POST", "/:org/items/bulk-price-update", "/"+orgID.String()+"/items/bulk-price-update"+query,
+			[]gin.HandlerFunc{authMiddleware(user), env.Handler.BulkPriceUpdateHandler}, body)
+		return w.Code
+	}
+	body := gin.H{"adjustment_type": "percentage", "value": 5, "reason": "Supplier costs"}
+
+	t.Run("DryRunByDefault", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetAllItems", orgID).Return(items, nil).Once()
+
+		w := jobRequest("POST", "/:org/items/bulk-price-update", "/"+orgID.String()+"/items/bulk-price-update",
+			[]gin.HandlerFunc{authMiddleware(manager), env.Handler.BulkPriceUpdateHandler}, body)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"dry_run":true`)
+		assert.Contains(t, w.Body.String(), `"new_price":10.5`)
+		env.ItemPriceStore.AssertNotCalled(t, "ApplyPriceChanges", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Apply", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetAllItems", orgID).Return(items, nil).Once()
+		env.ItemPriceStore.On("ApplyPriceChanges", orgID, mock.MatchedBy(func(changes []database.ItemPriceChange) bool {
+			return len(changes) == 1 && changes[0].NewPrice == 10.5 && *changes[0].ChangedBy == admin.ID &&
+				*changes[0].Reason == "Supplier costs"
+		})).Return(nil).Once()
+
+		assert.Equal(t, http.StatusOK, update(admin, "?dry_run=false", body))
+		env.ItemPriceStore.AssertExpectations(t)
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetAllItems", orgID).Return(items, nil).Once()
+		env.ItemPriceStore.On("ApplyPriceChanges", orgID, mock.Anything).Return(database.ErrItemPriceChanged).Once()
+
+		assert.Equal(t, http.StatusConflict, update(admin, "?dry_run=false", body))
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		env.ResetMocks()
+		assert.Equal(t, http.StatusForbidden, update(employee, "", body))
+		assert.Equal(t, http.StatusForbidden, update(manager, "?dry_run=false", body))
+		env.OrderStore.AssertNotCalled(t, "GetAllItems", mock.Anything)
+	})
+
+	t.Run("BadRequest", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetAllItems", orgID).Return(items, nil)
+
+		assert.Equal(t, http.StatusBadRequest, update(admin, "?dry_run=maybe", body))
+		assert.Equal(t, http.StatusBadRequest, update(admin, "", gin.H{"adjustment_type": "double", "value": 2}))
+		assert.Equal(t, http.StatusBadRequest, update(admin, "", gin.H{"adjustment_type": "fixed", "value": -20}))
+		env.ItemPriceStore.AssertNotCalled(t, "ApplyPriceChanges", mock.Anything, mock.Anything)
+	})
+
+	t.Run("StoreError", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetAllItems", orgID).Return(nil, errors.New("db error")).Once()
+
+		assert.Equal(t, http.StatusInternalServerError, update(admin, "", body))
+	})
+}
+
+func TestGetPriceHistoryHandler(t *testing.T) {
+	env := setupItemPriceEnv()
+	orgID, itemID := uuid.New(), uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+
+	get := func(user *database.User, item string) int {
+		w := jobRequest("GET", "/:org/items/:item/price-history", "/"+orgID.String()+"/items/"+item+"/price-history",
+			[]gin.HandlerFunc{authMiddleware(user), env.Handler.GetPriceHistoryHandler}, nil)
+		return w.Code
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.ItemPriceStore.On("GetPriceHistory", orgID, itemID).
+			Return([]database.ItemPriceChange{{ItemID: itemID, OldPrice: 10, NewPrice: 10.5}}, nil).Once()
+
+		assert.Equal(t, http.StatusOK, get(manager, itemID.String()))
+		env.ItemPriceStore.AssertExpectations(t)
+	})
+
+	t.Run("InvalidItem", func(t *testing.T) {
+		env.ResetMocks()
+		assert.Equal(t, http.StatusBadRequest, get(manager, "not-a-uuid"))
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+		assert.Equal(t, http.StatusForbidden, get(employee, itemID.String()))
+	})
+}
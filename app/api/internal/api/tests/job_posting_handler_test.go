@@ -0,0 +1,304 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type JobPostingTestEnv struct {
+	JobPostingStore *MockJobPostingStore
+	RolesStore      *MockRolesStore
+	Handler         *api.JobPostingHandler
+}
+
+func setupJobPostingEnv() *JobPostingTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	jobPostingStore := new(MockJobPostingStore)
+	rolesStore := new(MockRolesStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &JobPostingTestEnv{
+		JobPostingStore: jobPostingStore,
+		RolesStore:      rolesStore,
+		Handler:         api.NewJobPostingHandler(jobPostingStore, rolesStore, logger),
+	}
+}
+
+func (env *JobPostingTestEnv) ResetMocks() {
+	env.JobPostingStore.ExpectedCalls = nil
+	env.JobPostingStore.Calls = nil
+	env.RolesStore.ExpectedCalls = nil
+	env.RolesStore.Calls = nil
+}
+
+func jobRequest(method, route, path string, handlers []gin.HandlerFunc, body any) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.Handle(method, route, handlers...)
+	payload, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestPostingFromRequest(t *testing.T) {
+	t.Run("FromRecommendation", func(t *testing.T) {
+		posting, err := api.PostingFromRequest(api.CreateJobPostingRequest{
+			Recommendation: &api.HiringRecommendation{Role: "line_cook", RecommendedHires: 2, Reason: "Unmet demand: 120.0 items total", Priority: "high"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "line_cook", posting.Role)
+		assert.Equal(t, "Line cook", posting.Title)
+		assert.Equal(t, 2, posting.Openings)
+		assert.Equal(t, database.JobPostingSourceRecommendation, posting.Source)
+		assert.Equal(t, "Unmet demand: 120.0 items total", *posting.RecommendationReason)
+	})
+
+	t.Run("RequestOverridesRecommendation", func(t *testing.T) {
+		posting, err := api.PostingFromRequest(api.CreateJobPostingRequest{
+			Title:          "Weekend Server",
+			Openings:       1,
+			Recommendation: &api.HiringRecommendation{Role: "server", RecommendedHires: 3},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "Weekend Server", posting.Title)
+		assert.Equal(t, 1, posting.Openings)
+		assert.Nil(t, posting.RecommendationReason)
+	})
+
+	t.Run("Manual", func(t *testing.T) {
+		posting, err := api.PostingFromRequest(api.CreateJobPostingRequest{Role: "server"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, posting.Openings)
+		assert.Equal(t, database.JobPostingSourceManual, posting.Source)
+		assert.Equal(t, database.JobPostingOpen, posting.Status)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := api.PostingFromRequest(api.CreateJobPostingRequest{})
+		assert.EqualError(t, err, "role is required")
+		_, err = api.PostingFromRequest(api.CreateJobPostingRequest{Role: "server", Openings: 51})
+		assert.Error(t, err)
+	})
+}
+
+func TestCanMoveApplicant(t *testing.T) {
+	assert.True(t, api.CanMoveApplicant("applied", "interview"))
+	assert.True(t, api.CanMoveApplicant("offer", "screening"))
+	assert.True(t, api.CanMoveApplicant("trial", "rejected"))
+	assert.False(t, api.CanMoveApplicant("applied", "applied"))
+	assert.False(t, api.CanMoveApplicant("hired", "rejected"))
+	assert.False(t, api.CanMoveApplicant("rejected", "offer"))
+	assert.False(t, api.CanMoveApplicant("applied", "ghosted"))
+}
+
+func TestCreateJobPostingHandler(t *testing.T) {
+	env := setupJobPostingEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	post := func(user *database.User, body any) *httptest.ResponseRecorder {
+		return jobRequest("POST", "/:org/job-postings", "/"+orgID.String()+"/job-postings",
+			[]gin.HandlerFunc{authMiddleware(user), env.Handler.CreateJobPostingHandler}, body)
+	}
+
+	t.Run("FromRecommendation", func(t *testing.T) {
+		env.ResetMocks()
+		env.RolesStore.On("GetRoleByName", orgID, "server").Return(&database.OrganizationRole{Role: "server"}, nil).Once()
+		env.JobPostingStore.On("CreatePosting", mock.MatchedBy(func(posting *database.JobPosting) bool {
+			return posting.OrganizationID == orgID && posting.Openings == 2 && *posting.CreatedBy == manager.ID &&
+				posting.Source == database.JobPostingSourceRecommendation
+		})).Return(nil).Once()
+
+		w := post(manager, gin.H{"recommendation": gin.H{"role": "server", "recommended_hires": 2, "reason": "Weekend shortfall"}})
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		env.RolesStore.AssertExpectations(t)
+		env.JobPostingStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_UnknownRole", func(t *testing.T) {
+		env.ResetMocks()
+		env.RolesStore.On("GetRoleByName", orgID, "astronaut").Return(nil, nil).Once()
+
+		w := post(manager, gin.H{"role": "astronaut"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Unknown role: astronaut")
+	})
+
+	t.Run("Failure_MissingRole", func(t *testing.T) {
+		env.ResetMocks()
+		w := post(manager, gin.H{"title": "Helper"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+		w := post(employee, gin.H{"role": "server"})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestGetApplicantsHandler(t *testing.T) {
+	env := setupJobPostingEnv()
+	orgID := uuid.New()
+	postingID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	get := func() *httptest.ResponseRecorder {
+		return jobRequest("GET", "/:org/job-postings/:id/applicants", "/"+orgID.String()+"/job-postings/"+postingID.String()+"/applicants",
+			[]gin.HandlerFunc{authMiddleware(admin), env.Handler.GetApplicantsHandler}, nil)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.JobPostingStore.On("GetPostingByID", orgID, postingID).Return(&database.JobPosting{ID: postingID}, nil).Once()
+		env.JobPostingStore.On("GetApplicants", orgID, postingID).Return([]database.JobApplicant{{FullName: "Jamie Doe", Stage: "applied"}}, nil).Once()
+
+		w := get()
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Jamie Doe")
+		env.JobPostingStore.AssertExpectations(t)
+	})
+
+	t.Run("PostingNotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.JobPostingStore.On("GetPostingByID", orgID, postingID).Return(nil, sql.ErrNoRows).Once()
+
+		w := get()
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestSetApplicantStageHandler(t *testing.T) {
+	env := setupJobPostingEnv()
+	orgID := uuid.New()
+	applicantID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	put := func(stage string) *httptest.ResponseRecorder {
+		return jobRequest("PUT", "/:org/applicants/:id/stage", "/"+orgID.String()+"/applicants/"+applicantID.String()+"/stage",
+			[]gin.HandlerFunc{authMiddleware(manager), env.Handler.SetApplicantStageHandler}, gin.H{"stage": stage})
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.JobPostingStore.On("GetApplicantByID", orgID, applicantID).Return(&database.JobApplicant{ID: applicantID, Stage: "applied"}, nil).Once()
+		env.JobPostingStore.On("SetApplicantStage", orgID, applicantID, "interview").Return(nil).Once()
+
+		w := put("interview")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"stage":"interview"`)
+		env.JobPostingStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_FinalStage", func(t *testing.T) {
+		env.ResetMocks()
+		env.JobPostingStore.On("GetApplicantByID", orgID, applicantID).Return(&database.JobApplicant{ID: applicantID, Stage: "hired"}, nil).Once()
+
+		w := put("rejected")
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		env.JobPostingStore.AssertNotCalled(t, "SetApplicantStage", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failure_NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.JobPostingStore.On("GetApplicantByID", orgID, applicantID).Return(nil, sql.ErrNoRows).Once()
+
+		w := put("interview")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestApplyToJobPostingHandler(t *testing.T) {
+	env := setupJobPostingEnv()
+	orgID := uuid.New()
+	postingID := uuid.New()
+	application := gin.H{"full_name": "Jamie Doe", "email": "jamie@example.com", "message": "Two years as a barista"}
+	apply := func(body any) *httptest.ResponseRecorder {
+		return jobRequest("POST", "/careers/:org/postings/:id/apply", "/careers/"+orgID.String()+"/postings/"+postingID.String()+"/apply",
+			[]gin.HandlerFunc{env.Handler.ApplyToJobPostingHandler}, body)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.JobPostingStore.On("GetPostingByID", orgID, postingID).Return(&database.JobPosting{ID: postingID, Status: "open"}, nil).Once()
+		env.JobPostingStore.On("CreateApplicant", orgID, mock.MatchedBy(func(applicant *database.JobApplicant) bool {
+			return applicant.PostingID == postingID && applicant.Email == "jamie@example.com"
+		})).Return(nil).Once()
+
+		w := apply(application)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		env.JobPostingStore.AssertExpectations(t)
+	})
+
+	t.Run("ClosedPosting", func(t *testing.T) {
+		env.ResetMocks()
+		env.JobPostingStore.On("GetPostingByID", orgID, postingID).Return(&database.JobPosting{ID: postingID, Status: "closed"}, nil).Once()
+
+		w := apply(application)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Duplicate", func(t *testing.T) {
+		env.ResetMocks()
+		env.JobPostingStore.On("GetPostingByID", orgID, postingID).Return(&database.JobPosting{ID: postingID, Status: "open"}, nil).Once()
+		env.JobPostingStore.On("CreateApplicant", orgID, mock.Anything).Return(database.ErrDuplicateApplication).Once()
+
+		w := apply(application)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("InvalidEmail", func(t *testing.T) {
+		env.ResetMocks()
+		w := apply(gin.H{"full_name": "Jamie Doe", "email": "not-an-email"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.JobPostingStore.On("GetPostingByID", orgID, postingID).Return(nil, errors.New("db error")).Once()
+
+		w := apply(application)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestGetPublicJobPostingsHandler(t *testing.T) {
+	env := setupJobPostingEnv()
+	orgID := uuid.New()
+	reason := "Unmet demand"
+	env.JobPostingStore.On("GetOpenPostings", orgID).Return([]database.JobPosting{
+		{ID: uuid.New(), Role: "server", Title: "Server", Openings: 2, Status: "open", RecommendationReason: &reason},
+	}, nil).Once()
+
+	w := jobRequest("GET", "/careers/:org", "/careers/"+orgID.String(), []gin.HandlerFunc{env.Handler.GetPublicJobPostingsHandler}, nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"title":"Server"`)
+	assert.NotContains(t, w.Body.String(), "Unmet demand")
+}
@@ -0,0 +1,350 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type KioskTestEnv struct {
+	KioskStore *MockKioskStore
+	Handler    *api.KioskHandler
+}
+
+func setupKioskEnv() *KioskTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	kioskStore := new(MockKioskStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &KioskTestEnv{
+		KioskStore: kioskStore,
+		Handler:    api.NewKioskHandler(kioskStore, logger),
+	}
+}
+
+func (env *KioskTestEnv) ResetMocks() {
+	env.KioskStore.ExpectedCalls = nil
+	env.KioskStore.Calls = nil
+}
+
+// kioskRequest sends a request from a kiosk device through the KioskDevice middleware
+func kioskRequest(env *KioskTestEnv, method, route, path, token string, handler gin.HandlerFunc, body any) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.Handle(method, route, middleware.KioskDevice(env.KioskStore), handler)
+	payload, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Kiosk "+token)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestKioskDeviceMiddleware(t *testing.T) {
+	env := setupKioskEnv()
+	orgID := uuid.New()
+	device := &database.KioskDevice{ID: uuid.New(), OrganizationID: orgID, Name: "Front counter"}
+	route := "/kiosk/:org/employees"
+	path := "/kiosk/" + orgID.String() + "/employees"
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.KioskStore.On("GetDeviceByToken", "kiosk_abc").Return(device, nil).Once()
+		env.KioskStore.On("ListKioskEmployees", orgID).Return([]database.KioskEmployee{{ID: uuid.New(), FullName: "Ada"}}, nil).Once()
+
+		w := kioskRequest(env, "GET", route, path, "kiosk_abc", env.Handler.GetKioskEmployeesHandler, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Ada")
+	})
+
+	t.Run("MissingToken", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := kioskRequest(env, "GET", route, path, "", env.Handler.GetKioskEmployeesHandler, nil)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		env.KioskStore.AssertNotCalled(t, "GetDeviceByToken", mock.Anything)
+	})
+
+	t.Run("RevokedToken", func(t *testing.T) {
+		env.ResetMocks()
+		env.KioskStore.On("GetDeviceByToken", "kiosk_old").Return(nil, sql.ErrNoRows).Once()
+
+		w := kioskRequest(env, "GET", route, path, "kiosk_old", env.Handler.GetKioskEmployeesHandler, nil)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid or revoked")
+	})
+
+	t.Run("OtherOrganization", func(t *testing.T) {
+		env.ResetMocks()
+		env.KioskStore.On("GetDeviceByToken", "kiosk_abc").Return(device, nil).Once()
+		otherPath := "/kiosk/" + uuid.New().String() + "/employees"
+
+		w := kioskRequest(env, "GET", route, otherPath, "kiosk_abc", env.Handler.GetKioskEmployeesHandler, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		env.KioskStore.AssertNotCalled(t, "ListKioskEmployees", mock.Anything)
+	})
+}
+
+func TestKioskClockHandlers(t *testing.T) {
+	env := setupKioskEnv()
+	orgID := uuid.New()
+	employeeID := uuid.New()
+	device := &database.KioskDevice{ID: uuid.New(), OrganizationID: orgID, Name: "Front counter"}
+	hash, err := database.Hash("1234")
+	assert.NoError(t, err)
+	pin := func(state database.LoginState) *database.KioskPin {
+		return &database.KioskPin{UserID: employeeID, OrganizationID: orgID, Pin: database.NewPasswordFromHash(hash), State: state}
+	}
+	clockIn := func(body any) *httptest.ResponseRecorder {
+		return kioskRequest(env, "POST", "/kiosk/:org/clock-in", "/kiosk/"+orgID.String()+"/clock-in", "kiosk_abc", env.Handler.KioskClockInHandler, body)
+	}
+	clockOut := func(body any) *httptest.ResponseRecorder {
+		return kioskRequest(env, "POST", "/kiosk/:org/clock-out", "/kiosk/"+orgID.String()+"/clock-out", "kiosk_abc", env.Handler.KioskClockOutHandler, body)
+	}
+	correct := map[string]any{"employee_id": employeeID, "pin": "1234"}
+	wrong := map[string]any{"employee_id": employeeID, "pin": "9999"}
+
+	t.Run("ClockIn", func(t *testing.T) {
+		env.ResetMocks()
+		env.KioskStore.On("GetDeviceByToken", "kiosk_abc").Return(device, nil).Once()
+		env.KioskStore.On("GetPin", orgID, employeeID).Return(pin(database.LoginState{}), nil).Once()
+		env.KioskStore.On("ClockIn", orgID, mock.MatchedBy(func(entry *database.TimeClockEntry) bool {
+			return entry.EmployeeID == employeeID && *entry.DeviceID == device.ID
+		})).Return(nil).Once()
+
+		w := clockIn(correct)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		env.KioskStore.AssertNotCalled(t, "ResetFailedPins", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ClockIn_ResetsFailures", func(t *testing.T) {
+		env.ResetMocks()
+		env.KioskStore.On("GetDeviceByToken", "kiosk_abc").Return(device, nil).Once()
+		env.KioskStore.On("GetPin", orgID, employeeID).Return(pin(database.LoginState{FailedAttempts: 2}), nil).Once()
+		env.KioskStore.On("ResetFailedPins", orgID, employeeID).Return(nil).Once()
+		env.KioskStore.On("ClockIn", orgID, mock.Anything).Return(nil).Once()
+
+		w := clockIn(correct)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		env.KioskStore.AssertExpectations(t)
+	})
+
+	t.Run("AlreadyClockedIn", func(t *testing.T) {
+		env.ResetMocks()
+		env.KioskStore.On("GetDeviceByToken", "kiosk_abc").Return(device, nil).Once()
+		env.KioskStore.On("GetPin", orgID, employeeID).Return(pin(database.LoginState{}), nil).Once()
+		env.KioskStore.On("ClockIn", orgID, mock.Anything).Return(database.ErrAlreadyClockedIn).Once()
+
+		w := clockIn(correct)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("WrongPin", func(t *testing.T) {
+		env.ResetMocks()
+		env.KioskStore.On("GetDeviceByToken", "kiosk_abc").Return(device, nil).Once()
+		env.KioskStore.On("GetPin", orgID, employeeID).Return(pin(database.LoginState{}), nil).Once()
+		env.KioskStore.On("RecordFailedPin", orgID, employeeID, 5, 15*time.Minute).Return(&database.LoginState{FailedAttempts: 1}, nil).Once()
+
+		w := clockIn(wrong)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid employee or PIN")
+		env.KioskStore.AssertNotCalled(t, "ClockIn", mock.Anything, mock.Anything)
+	})
+
+	t.Run("WrongPin_Locks", func(t *testing.T) {
+		env.ResetMocks()
+		lockedUntil := time.Now().Add(15 * time.Minute)
+		env.KioskStore.On("GetDeviceByToken", "kiosk_abc").Return(device, nil).Once()
+		env.KioskStore.On("GetPin", orgID, employeeID).Return(pin(database.LoginState{FailedAttempts: 4}), nil).Once()
+		env.KioskStore.On("RecordFailedPin", orgID, employeeID, 5, 15*time.Minute).Return(&database.LoginState{LockedUntil: &lockedUntil}, nil).Once()
+
+		w := clockIn(wrong)
+
+		assert.Equal(t, http.StatusLocked, w.Code)
+	})
+
+	t.Run("Locked", func(t *testing.T) {
+		env.ResetMocks()
+		lockedUntil := time.Now().Add(time.Minute)
+		env.KioskStore.On("GetDeviceByToken", "kiosk_abc").Return(device, nil).Once()
+		env.KioskStore.On("GetPin", orgID, employeeID).Return(pin(database.LoginState{LockedUntil: &lockedUntil}), nil).Once()
+
+		// even the correct PIN is refused while locked
+		w := clockIn(correct)
+
+		assert.Equal(t, http.StatusLocked, w.Code)
+		env.KioskStore.AssertNotCalled(t, "ClockIn", mock.Anything, mock.Anything)
+	})
+
+	t.Run("NoPin", func(t *testing.T) {
+		env.ResetMocks()
+		env.KioskStore.On("GetDeviceByToken", "kiosk_abc").Return(device, nil).Once()
+		env.KioskStore.On("GetPin", orgID, employeeID).Return(nil, sql.ErrNoRows).Once()
+
+		w := clockIn(correct)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid employee or PIN")
+	})
+
+	t.Run("ClockOut", func(t *testing.T) {
+		env.ResetMocks()
+		clockedIn := time.Now().Add(-8 * time.Hour)
+		env.KioskStore.On("GetDeviceByToken", "kiosk_abc").Return(device, nil).Once()
+		env.KioskStore.On("GetPin", orgID, employeeID).Return(pin(database.LoginState{}), nil).Once()
+		env.KioskStore.On("ClockOut", orgID, employeeID, mock.Anything).
+			Return(&database.TimeClockEntry{ID: uuid.New(), EmployeeID: employeeID, ClockInAt: clockedIn}, nil).Once()
+
+		w := clockOut(correct)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("NotClockedIn", func(t *testing.T) {
+		env.ResetMocks()
+		env.KioskStore.On("GetDeviceByToken", "kiosk_abc").Return(device, nil).Once()
+		env.KioskStore.On("GetPin", orgID, employeeID).Return(pin(database.LoginState{}), nil).Once()
+		env.KioskStore.On("ClockOut", orgID, employeeID, mock.Anything).Return(nil, database.ErrNotClockedIn).Once()
+
+		w := clockOut(correct)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestKioskDeviceHandlers(t *testing.T) {
+	env := setupKioskEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	devicesPath := "/" + orgID.String() + "/kiosk/devices"
+
+	t.Run("Create", func(t *testing.T) {
+		env.ResetMocks()
+		revokedAt := time.Now()
+		env.KioskStore.On("ListDevices", orgID).Return([]database.KioskDevice{{ID: uuid.New(), RevokedAt: &revokedAt}}, nil).Once()
+		env.KioskStore.On("CreateDevice", orgID, mock.Anything, mock.Anything).Return(nil).Once()
+
+		w := jobRequest("POST", "/:org/kiosk/devices", devicesPath, []gin.HandlerFunc{authMiddleware(admin), env.Handler.CreateKioskDeviceHandler}, map[string]any{"name": "Front counter"})
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var resp struct {
+			Data api.KioskDeviceWithToken `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.True(t, strings.HasPrefix(resp.Data.Token, "kiosk_"))
+		assert.Equal(t, "Front counter", resp.Data.Name)
+		call := env.KioskStore.Calls[1]
+		assert.Equal(t, resp.Data.Token, call.Arguments.Get(2))
+		assert.Equal(t, &admin.ID, call.Arguments.Get(1).(*database.KioskDevice).CreatedBy)
+	})
+
+	t.Run("Create_TooMany", func(t *testing.T) {
+		env.ResetMocks()
+		devices := make([]database.KioskDevice, 20)
+		env.KioskStore.On("ListDevices", orgID).Return(devices, nil).Once()
+
+		w := jobRequest("POST", "/:org/kiosk/devices", devicesPath, []gin.HandlerFunc{authMiddleware(admin), env.Handler.CreateKioskDeviceHandler}, map[string]any{"name": "Back office"})
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		env.KioskStore.AssertNotCalled(t, "CreateDevice", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ManagerForbidden", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("GET", "/:org/kiosk/devices", devicesPath, []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetKioskDevicesHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Revoke_NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		deviceID := uuid.New()
+		env.KioskStore.On("RevokeDevice", orgID, deviceID).Return(sql.ErrNoRows).Once()
+
+		w := jobRequest("DELETE", "/:org/kiosk/devices/:id", devicesPath+"/"+deviceID.String(), []gin.HandlerFunc{authMiddleware(admin), env.Handler.RevokeKioskDeviceHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Entries", func(t *testing.T) {
+		env.ResetMocks()
+		from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.Local)
+		to := time.Date(2025, 3, 8, 0, 0, 0, 0, time.Local)
+		env.KioskStore.On("ListEntries", orgID, from, to).Return([]database.TimeClockEntry{}, nil).Once()
+
+		w := jobRequest("GET", "/:org/kiosk/entries", "/"+orgID.String()+"/kiosk/entries?from=2025-03-01&to=2025-03-07",
+			[]gin.HandlerFunc{authMiddleware(manager), env.Handler.GetTimeClockEntriesHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Entries_DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.KioskStore.On("ListEntries", orgID, mock.Anything, mock.Anything).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", "/:org/kiosk/entries", "/"+orgID.String()+"/kiosk/entries",
+			[]gin.HandlerFunc{authMiddleware(manager), env.Handler.GetTimeClockEntriesHandler}, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestSetKioskPinHandler(t *testing.T) {
+	env := setupKioskEnv()
+	orgID := uuid.New()
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	handlers := []gin.HandlerFunc{authMiddleware(employee), env.Handler.SetKioskPinHandler}
+	path := "/" + orgID.String() + "/me/kiosk-pin"
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.KioskStore.On("SetPin", orgID, employee.ID, mock.Anything).Return(nil).Once()
+
+		w := jobRequest("PUT", "/:org/me/kiosk-pin", path, handlers, map[string]any{"pin": "482913"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		// the PIN is stored hashed
+		stored := database.NewPasswordFromHash(env.KioskStore.Calls[0].Arguments.Get(2).([]byte))
+		match, err := stored.Matches("482913")
+		assert.NoError(t, err)
+		assert.True(t, match)
+	})
+
+	t.Run("InvalidPin", func(t *testing.T) {
+		env.ResetMocks()
+		for _, pin := range []string{"123", "1234567", "12a4", " 1234"} {
+			w := jobRequest("PUT", "/:org/me/kiosk-pin", path, handlers, map[string]any{"pin": pin})
+			assert.Equal(t, http.StatusBadRequest, w.Code, pin)
+		}
+		env.KioskStore.AssertNotCalled(t, "SetPin", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
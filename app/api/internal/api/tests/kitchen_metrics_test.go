@@ -0,0 +1,229 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMeasureKitchenLoad(t *testing.T) {
+	// 3 cooks making 90 items per hour together, one and a half items per minute
+	capacity := database.KitchenCapacity{StaffOnShift: 3, ItemsPerHour: 90}
+
+	t.Run("Levels", func(t *testing.T) {
+		cases := []struct {
+			prepUnits float64
+			level     string
+			percent   float64
+			required  int
+		}{
+			{prepUnits: 9, level: "normal", percent: 40, required: 2},
+			{prepUnits: 20, level: "busy", percent: 88.9, required: 3},
+			{prepUnits: 30, level: "overloaded", percent: 133.3, required: 4},
+		}
+		for _, tc := range cases {
+			metric := api.MeasureKitchenLoad(database.KitchenLoad{OpenOrders: 3, PrepUnits: tc.prepUnits}, capacity, 15)
+
+			assert.Equal(t, tc.level, metric.Level)
+			assert.Equal(t, tc.percent, *metric.LoadPercent)
+			assert.Equal(t, tc.required, metric.RequiredEmployees)
+		}
+	})
+
+	t.Run("Backlog", func(t *testing.T) {
+		metric := api.MeasureKitchenLoad(database.KitchenLoad{OpenOrders: 2, OpenItems: 5, PrepUnits: 6}, capacity, 0)
+
+		assert.Equal(t, 4.0, *metric.BacklogMinutes)
+		assert.Equal(t, 15, metric.TargetMinutes) // the default waiting time
+		assert.Equal(t, 5, metric.OpenItems)
+	})
+
+	t.Run("Idle", func(t *testing.T) {
+		metric := api.MeasureKitchenLoad(database.KitchenLoad{}, capacity, 15)
+
+		assert.Equal(t, "idle", metric.Level)
+		assert.Equal(t, 0, metric.RequiredEmployees)
+	})
+
+	t.Run("Unstaffed", func(t *testing.T) {
+		metric := api.MeasureKitchenLoad(database.KitchenLoad{OpenOrders: 1, PrepUnits: 2}, database.KitchenCapacity{}, 15)
+
+		assert.Equal(t, "unstaffed", metric.Level)
+		assert.Nil(t, metric.LoadPercent)
+		assert.Nil(t, metric.BacklogMinutes)
+	})
+}
+
+func TestMarkOrderPreparedHandler(t *testing.T) {
+	env := setupWaitTimeEnv()
+	orgID := uuid.New()
+	orderID := uuid.New()
+	cook := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	route := "/:org/kitchen/orders/:order/prepared"
+	path := "/" + orgID.String() + "/kitchen/orders/" + orderID.String() + "/prepared"
+	handlers := []gin.HandlerFunc{authMiddleware(cook), env.Handler.MarkOrderPreparedHandler}
+
+	t.Run("WholeOrderNow", func(t *testing.T) {
+		env.ResetMocks()
+		preparedAt := time.Now()
+		env.WaitTimeStore.On("MarkPrepared", orgID, orderID, []uuid.UUID(nil), mock.MatchedBy(func(at time.Time) bool {
+			return time.Since(at) < time.Minute
+		})).Return(&database.PreparedOrder{OrderID: orderID, PreparedItems: 3, PreparedAt: &preparedAt}, nil).Once()
+
+		w := jobRequest("POST", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"prepared_items":3`)
+		env.WaitTimeStore.AssertExpectations(t)
+	})
+
+	t.Run("ItemsFromPOS", func(t *testing.T) {
+		env.ResetMocks()
+		itemID := uuid.New()
+		at := time.Date(2025, 6, 1, 12, 14, 0, 0, time.UTC)
+		env.WaitTimeStore.On("MarkPrepared", orgID, orderID, []uuid.UUID{itemID}, mock.MatchedBy(at.Equal)).
+			Return(&database.PreparedOrder{OrderID: orderID, PreparedItems: 1, RemainingItems: 2}, nil).Once()
+
+		w := jobRequest("POST", route, path, handlers, map[string]any{"items": []uuid.UUID{itemID}, "prepared_at": at})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"remaining_items":2`)
+		assert.Contains(t, w.Body.String(), `"prepared_at":null`)
+		env.WaitTimeStore.AssertExpectations(t)
+	})
+
+	t.Run("StoreErrors", func(t *testing.T) {
+		cases := map[error]int{
+			sql.ErrNoRows:                     http.StatusNotFound,
+			database.ErrUnknownOrderItem:      http.StatusBadRequest,
+			database.ErrPreparedBeforeCreated: http.StatusBadRequest,
+			errors.New("db error"):            http.StatusInternalServerError,
+		}
+		for storeErr, status := range cases {
+			env.ResetMocks()
+			env.WaitTimeStore.On("MarkPrepared", orgID, orderID, mock.Anything, mock.Anything).Return(nil, storeErr).Once()
+
+			w := jobRequest("POST", route, path, handlers, nil)
+
+			assert.Equal(t, status, w.Code, storeErr.Error())
+		}
+	})
+
+	t.Run("InvalidRequest", func(t *testing.T) {
+		env.ResetMocks()
+
+		future := jobRequest("POST", route, path, handlers, map[string]any{"prepared_at": time.Now().Add(time.Hour)})
+		badOrder := jobRequest("POST", route, "/"+orgID.String()+"/kitchen/orders/not-a-uuid/prepared", handlers, nil)
+
+		assert.Equal(t, http.StatusBadRequest, future.Code)
+		assert.Equal(t, http.StatusBadRequest, badOrder.Code)
+		env.WaitTimeStore.AssertNotCalled(t, "MarkPrepared", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetKitchenLoadHandler(t *testing.T) {
+	env := setupWaitTimeEnv()
+	orgID := uuid.New()
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	route := "/:org/kitchen/load"
+	path := "/" + orgID.String() + "/kitchen/load"
+	handlers := []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetKitchenLoadHandler}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(&database.OrganizationRules{OrganizationID: orgID, WaitingTime: 20}, nil)
+		env.WaitTimeStore.On("GetOpenKitchenLoad", orgID, mock.Anything).Return(&database.KitchenLoad{OpenOrders: 4, OpenItems: 10, PrepUnits: 12}, nil)
+		env.WaitTimeStore.On("GetKitchenCapacity", orgID, mock.Anything).Return(&database.KitchenCapacity{StaffOnShift: 2, ItemsPerHour: 60}, nil)
+		env.WaitTimeStore.On("GetRecentPrepTime", orgID, mock.Anything).Return(&database.PrepTime{Prepared: 5, AvgMinutes: 14.5}, nil)
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data struct {
+				Load       api.KitchenLoadMetric `json:"load"`
+				RecentPrep database.PrepTime     `json:"recent_prep"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, 4, resp.Data.Load.OpenOrders)
+		assert.Equal(t, 20, resp.Data.Load.TargetMinutes)
+		assert.Equal(t, 60.0, *resp.Data.Load.LoadPercent)
+		assert.Equal(t, "normal", resp.Data.Load.Level)
+		assert.Equal(t, 14.5, resp.Data.RecentPrep.AvgMinutes)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(nil, nil)
+		env.WaitTimeStore.On("GetOpenKitchenLoad", orgID, mock.Anything).Return(nil, errors.New("db error"))
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestGetPrepTimesHandler(t *testing.T) {
+	env := setupWaitTimeEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/kitchen/prep-times"
+	path := "/" + orgID.String() + "/kitchen/prep-times"
+	handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetPrepTimesHandler}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		since := mock.MatchedBy(func(at time.Time) bool {
+			return time.Since(at).Round(time.Hour) == 7*24*time.Hour
+		})
+		items := []database.ItemPrepTime{{ItemID: uuid.New(), Name: "Lasagna", PrepTime: database.PrepTime{Prepared: 40, AvgMinutes: 21.5}}}
+		hours := []database.HourPrepTime{{Hour: 19, PrepTime: database.PrepTime{Prepared: 25, AvgMinutes: 17}}}
+		env.WaitTimeStore.On("GetItemPrepTimes", orgID, since).Return(items, nil).Once()
+		env.WaitTimeStore.On("GetHourlyPrepTimes", orgID, since).Return(hours, nil).Once()
+
+		w := jobRequest("GET", route, path+"?days=7", handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		body := w.Body.String()
+		assert.Contains(t, body, `"name":"Lasagna","prepared":40,"avg_prep_minutes":21.5`)
+		assert.Contains(t, body, `"hour":19`)
+		env.WaitTimeStore.AssertExpectations(t)
+	})
+
+	t.Run("InvalidDays", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("GET", route, path+"?days=0", handlers, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetPrepTimesHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.WaitTimeStore.On("GetItemPrepTimes", orgID, mock.Anything).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type KPIReportTestEnv struct {
+	Store         *MockKPITargetStore
+	ScheduleStore *MockScheduleStore
+	UserStore     *MockUserStore
+	OrgStore      *MockOrgStore
+	Email         *MockEmailService
+	Service       *service.KPIReportService
+}
+
+func setupKPIReportEnv() *KPIReportTestEnv {
+	store := new(MockKPITargetStore)
+	scheduleStore := new(MockScheduleStore)
+	userStore := new(MockUserStore)
+	orgStore := new(MockOrgStore)
+	email := new(MockEmailService)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &KPIReportTestEnv{
+		Store:         store,
+		ScheduleStore: scheduleStore,
+		UserStore:     userStore,
+		OrgStore:      orgStore,
+		Email:         email,
+		Service: &service.KPIReportService{
+			Tracker:      service.NewKPITracker(store, scheduleStore, userStore),
+			OrgStore:     orgStore,
+			EmailService: email,
+			Logger:       logger,
+			Interval:     time.Hour,
+			SendHour:     8,
+		},
+	}
+}
+
+func (env *KPIReportTestEnv) ResetMocks() {
+	for _, m := range []*mock.Mock{&env.Store.Mock, &env.ScheduleStore.Mock, &env.UserStore.Mock, &env.OrgStore.Mock, &env.Email.Mock} {
+		m.ExpectedCalls = nil
+		m.Calls = nil
+	}
+}
+
+func TestSendKPIReports(t *testing.T) {
+	env := setupKPIReportEnv()
+	orgID := uuid.New()
+	monday := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	weekStart := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	weekEnd := weekStart.AddDate(0, 0, 7)
+	admins := []string{"admin@example.com"}
+
+	t.Run("SendAndMark", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("GetOrganizationsForKPIReport", weekStart).Return([]uuid.UUID{orgID}, nil).Once()
+		env.Store.On("GetTargets", orgID).Return(&database.KPITargets{OrganizationID: orgID, WeeklyRevenue: kpiFloat(7000), AverageRating: kpiFloat(4.5)}, nil).Once()
+		env.Store.On("GetSales", orgID, weekStart, weekEnd).Return(&database.KPISales{Revenue: 6500, AverageRating: kpiFloat(4.6), RatedOrders: 40}, nil).Once()
+		env.UserStore.On("GetUsersByOrganization", orgID).Return([]*database.User{}, nil).Once()
+		env.ScheduleStore.On("GetScheduledHours", orgID, weekStart, weekEnd).Return([]database.EmployeeHours{}, nil).Once()
+		env.OrgStore.On("GetAdminEmailsByOrgID", orgID).Return(admins, nil).Once()
+		env.Email.On("SendKPIReportEmail", admins, "Oct 5, 2026", []string{
+			"Revenue: 6500.00 against 7000.00 (-7.14%, missed)",
+			"Average rating: 4.60 against 4.50 (+2.22%, met)",
+		}).Return(nil).Once()
+		env.Store.On("MarkKPIReportSent", orgID, weekStart).Return(nil).Once()
+
+		sent, err := env.Service.SendReports(monday)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		env.Store.AssertExpectations(t)
+		env.Email.AssertExpectations(t)
+	})
+
+	t.Run("NotDue", func(t *testing.T) {
+		env.ResetMocks()
+
+		sent, err := env.Service.SendReports(time.Date(2026, 10, 12, 7, 0, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+
+		sent, err = env.Service.SendReports(time.Date(2026, 10, 13, 9, 0, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		env.Store.AssertNotCalled(t, "GetOrganizationsForKPIReport", mock.Anything)
+	})
+
+	t.Run("EmailFailed_NotMarked", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("GetOrganizationsForKPIReport", weekStart).Return([]uuid.UUID{orgID}, nil).Once()
+		env.Store.On("GetTargets", orgID).Return(&database.KPITargets{OrganizationID: orgID, WeeklyRevenue: kpiFloat(7000)}, nil).Once()
+		env.Store.On("GetSales", orgID, weekStart, weekEnd).Return(&database.KPISales{Revenue: 7200}, nil).Once()
+		env.UserStore.On("GetUsersByOrganization", orgID).Return([]*database.User{}, nil).Once()
+		env.ScheduleStore.On("GetScheduledHours", orgID, weekStart, weekEnd).Return([]database.EmployeeHours{}, nil).Once()
+		env.OrgStore.On("GetAdminEmailsByOrgID", orgID).Return(admins, nil).Once()
+		env.Email.On("SendKPIReportEmail", admins, "Oct 5, 2026", mock.Anything).Return(errors.New("smtp error")).Once()
+
+		sent, err := env.Service.SendReports(monday)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		env.Store.AssertNotCalled(t, "MarkKPIReportSent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("GetOrganizationsForKPIReport", weekStart).Return(nil, errors.New("db error")).Once()
+
+		sent, err := env.Service.SendReports(monday)
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, sent)
+	})
+}
@@ -0,0 +1,296 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type KPITargetTestEnv struct {
+	Store         *MockKPITargetStore
+	ScheduleStore *MockScheduleStore
+	UserStore     *MockUserStore
+	Handler       *api.KPITargetHandler
+}
+
+func setupKPITargetEnv() *KPITargetTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	store := new(MockKPITargetStore)
+	scheduleStore := new(MockScheduleStore)
+	userStore := new(MockUserStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &KPITargetTestEnv{
+		Store:         store,
+		ScheduleStore: scheduleStore,
+		UserStore:     userStore,
+		Handler:       api.NewKPITargetHandler(store, service.NewKPITracker(store, scheduleStore, userStore), logger),
+	}
+}
+
+func (env *KPITargetTestEnv) ResetMocks() {
+	for _, m := range []*mock.Mock{&env.Store.Mock, &env.ScheduleStore.Mock, &env.UserStore.Mock} {
+		m.ExpectedCalls = nil
+		m.Calls = nil
+	}
+}
+
+func kpiFloat(value float64) *float64 {
+	return &value
+}
+
+func TestCompareKPIs(t *testing.T) {
+	from := time.Date(2026, 10, 5, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 0, 7)
+	targets := &database.KPITargets{WeeklyRevenue: kpiFloat(7000), LaborCostPercent: kpiFloat(32), AverageRating: kpiFloat(4.5)}
+
+	t.Run("Week", func(t *testing.T) {
+		progress := service.CompareKPIs(targets, from, to, database.KPISales{Revenue: 6500, AverageRating: kpiFloat(4.6), RatedOrders: 40}, 1950)
+
+		assert.Equal(t, "2026-10-05", progress.From)
+		assert.Equal(t, "2026-10-11", progress.To)
+		assert.Equal(t, 30.0, *progress.LaborCostPercent)
+		if assert.Len(t, progress.Targets, 3) {
+			revenue := progress.Targets[0]
+			assert.Equal(t, service.KPIWeeklyRevenue, revenue.KPI)
+			assert.Equal(t, 7000.0, revenue.Target)
+			assert.Equal(t, -500.0, *revenue.Variance)
+			assert.Equal(t, -7.14, *revenue.VariancePercent)
+			assert.False(t, *revenue.Met)
+			assert.Equal(t, "6500.00 against 7000.00 (-7.14%, missed)", service.FormatKPIResult(revenue))
+
+			// the labor cost is met by staying under its target
+			labor := progress.Targets[1]
+			assert.Equal(t, -2.0, *labor.Variance)
+			assert.True(t, *labor.Met)
+
+			rating := progress.Targets[2]
+			assert.Equal(t, 4.6, *rating.Actual)
+			assert.True(t, *rating.Met)
+		}
+	})
+
+	t.Run("RevenueTargetScaledToPeriod", func(t *testing.T) {
+		progress := service.CompareKPIs(&database.KPITargets{WeeklyRevenue: kpiFloat(7000)}, from, from.AddDate(0, 0, 14),
+			database.KPISales{Revenue: 15000}, 0)
+
+		if assert.Len(t, progress.Targets, 1) {
+			assert.Equal(t, 14000.0, progress.Targets[0].Target)
+			assert.True(t, *progress.Targets[0].Met)
+		}
+	})
+
+	t.Run("NotMeasured", func(t *testing.T) {
+		progress := service.CompareKPIs(targets, from, to, database.KPISales{}, 800)
+
+		assert.Nil(t, progress.LaborCostPercent)
+		if assert.Len(t, progress.Targets, 3) {
+			assert.Nil(t, progress.Targets[1].Actual)
+			assert.Nil(t, progress.Targets[1].Met)
+			assert.Nil(t, progress.Targets[2].Actual)
+			assert.Equal(t, "not measured against 4.50", service.FormatKPIResult(progress.Targets[2]))
+		}
+	})
+}
+
+func TestGetKPITargetsHandler(t *testing.T) {
+	env := setupKPITargetEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	request := func(user *database.User) *httptest.ResponseRecorder {
+		return jobRequest("GET", "/:org/targets", "/"+orgID.String()+"/targets",
+			[]gin.HandlerFunc{authMiddleware(user), env.Handler.GetKPITargetsHandler}, nil)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("GetTargets", orgID).Return(&database.KPITargets{OrganizationID: orgID, WeeklyRevenue: kpiFloat(7000)}, nil).Once()
+
+		w := request(manager)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"weekly_revenue":7000`)
+		assert.Contains(t, w.Body.String(), `"labor_cost_percent":null`)
+	})
+
+	t.Run("NoneSet", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("GetTargets", orgID).Return(nil, nil).Once()
+
+		w := request(manager)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"data":null`)
+	})
+
+	t.Run("Forbidden_Employee", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := request(&database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestSetKPITargetsHandler(t *testing.T) {
+	env := setupKPITargetEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	request := func(user *database.User, body any) *httptest.ResponseRecorder {
+		return jobRequest("PUT", "/:org/targets", "/"+orgID.String()+"/targets",
+			[]gin.HandlerFunc{authMiddleware(user), env.Handler.SetKPITargetsHandler}, body)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("SetTargets", mock.MatchedBy(func(targets *database.KPITargets) bool {
+			return targets.OrganizationID == orgID && *targets.LaborCostPercent == 28 && targets.WeeklyRevenue == nil &&
+				*targets.UpdatedBy == admin.ID
+		})).Return(nil).Once()
+
+		w := request(admin, gin.H{"labor_cost_percent": 28})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.Store.AssertExpectations(t)
+	})
+
+	t.Run("NoTarget", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := request(admin, gin.H{})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		env.Store.AssertNotCalled(t, "SetTargets", mock.Anything)
+	})
+
+	t.Run("InvalidTarget", func(t *testing.T) {
+		env.ResetMocks()
+
+		assert.Equal(t, http.StatusBadRequest, request(admin, gin.H{"labor_cost_percent": 120}).Code)
+		assert.Equal(t, http.StatusBadRequest, request(admin, gin.H{"average_rating": 6}).Code)
+		assert.Equal(t, http.StatusBadRequest, request(admin, gin.H{"weekly_revenue": -10}).Code)
+	})
+
+	t.Run("Forbidden_Manager", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := request(&database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}, gin.H{"weekly_revenue": 7000})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestDeleteKPITargetsHandler(t *testing.T) {
+	env := setupKPITargetEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	request := func(user *database.User) *httptest.ResponseRecorder {
+		return jobRequest("DELETE", "/:org/targets", "/"+orgID.String()+"/targets",
+			[]gin.HandlerFunc{authMiddleware(user), env.Handler.DeleteKPITargetsHandler}, nil)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("DeleteTargets", orgID).Return(nil).Once()
+
+		assert.Equal(t, http.StatusOK, request(admin).Code)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("DeleteTargets", orgID).Return(sql.ErrNoRows).Once()
+
+		assert.Equal(t, http.StatusNotFound, request(admin).Code)
+	})
+
+	t.Run("Forbidden_Manager", func(t *testing.T) {
+		env.ResetMocks()
+
+		assert.Equal(t, http.StatusForbidden, request(&database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}).Code)
+	})
+}
+
+func TestGetKPIProgressHandler(t *testing.T) {
+	env := setupKPITargetEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	from := time.Date(2026, 10, 5, 0, 0, 0, 0, time.Local)
+	to := time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)
+	path := "/" + orgID.String() + "/targets/progress?from=2026-10-05&to=2026-10-11"
+	request := func(user *database.User, path string) *httptest.ResponseRecorder {
+		return jobRequest("GET", "/:org/targets/progress", path,
+			[]gin.HandlerFunc{authMiddleware(user), env.Handler.GetKPIProgressHandler}, nil)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		employeeID := uuid.New()
+		env.Store.On("GetTargets", orgID).Return(&database.KPITargets{OrganizationID: orgID, WeeklyRevenue: kpiFloat(7000), LaborCostPercent: kpiFloat(32)}, nil).Once()
+		env.Store.On("GetSales", orgID, from, to).Return(&database.KPISales{Revenue: 7500}, nil).Once()
+		env.UserStore.On("GetUsersByOrganization", orgID).Return([]*database.User{{ID: employeeID, SalaryPerHour: kpiFloat(20)}}, nil).Once()
+		env.ScheduleStore.On("GetScheduledHours", orgID, from, to).Return([]database.EmployeeHours{{EmployeeID: employeeID, WorkingHours: 120}}, nil).Once()
+
+		w := request(admin, path)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data service.KPIProgress `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "2026-10-11", resp.Data.To)
+		assert.Equal(t, 2400.0, resp.Data.LaborCost)
+		assert.Equal(t, 32.0, *resp.Data.LaborCostPercent)
+		if assert.Len(t, resp.Data.Targets, 2) {
+			assert.True(t, *resp.Data.Targets[0].Met)
+			assert.Equal(t, 500.0, *resp.Data.Targets[0].Variance)
+			// exactly on the labor cost target still meets it
+			assert.True(t, *resp.Data.Targets[1].Met)
+		}
+	})
+
+	t.Run("NoTargets", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("GetTargets", orgID).Return(nil, nil).Once()
+
+		assert.Equal(t, http.StatusNotFound, request(admin, path).Code)
+		env.Store.AssertNotCalled(t, "GetSales", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("StoreError", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("GetTargets", orgID).Return(nil, errors.New("db error")).Once()
+
+		assert.Equal(t, http.StatusInternalServerError, request(admin, path).Code)
+	})
+
+	t.Run("InvalidRange", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := request(admin, "/"+orgID.String()+"/targets/progress?from=2026-10-11&to=2026-10-05")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Forbidden_Employee", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := request(&database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}, path)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
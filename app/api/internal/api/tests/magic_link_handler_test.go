@@ -0,0 +1,237 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MagicLinkTestEnv struct {
+	MagicLinkStore *MockMagicLinkStore
+	UserStore      *MockUserStore
+	OrgStore       *MockOrgStore
+	EmailService   *MockEmailService
+	Handler        *api.MagicLinkHandler
+}
+
+func setupMagicLinkEnv() *MagicLinkTestEnv {
+	gin.SetMode(gin.TestMode)
+	env := &MagicLinkTestEnv{
+		MagicLinkStore: new(MockMagicLinkStore),
+		UserStore:      new(MockUserStore),
+		OrgStore:       new(MockOrgStore),
+		EmailService:   new(MockEmailService),
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	env.Handler = api.NewMagicLinkHandler(env.MagicLinkStore, env.UserStore, env.OrgStore, env.EmailService, logger)
+	env.Handler.LinkURL = "https://app.example.com/login"
+	return env
+}
+
+func TestRequestMagicLinkHandler(t *testing.T) {
+	orgID := uuid.New()
+	user := &database.User{ID: uuid.New(), OrganizationID: orgID, FullName: "Sam Cook", Email: "sam@example.com", UserRole: "employee"}
+	route := "/auth/magic-link"
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupMagicLinkEnv()
+		var token string
+		env.MagicLinkStore.On("RecordMagicLinkRequest", mock.Anything, mock.Anything).Return(1, nil).Once()
+		env.UserStore.On("GetUserByEmail", "sam@example.com").Return(user, nil).Once()
+		env.OrgStore.On("GetMagicLinkEnabled", orgID).Return(true, nil).Once()
+		env.MagicLinkStore.On("CountRecentMagicLinks", user.ID, mock.Anything).Return(0, nil).Once()
+		env.MagicLinkStore.On("CreateMagicLink", user.ID, mock.Anything, mock.Anything, mock.MatchedBy(func(expires time.Time) bool {
+			return expires.After(time.Now().Add(14 * time.Minute))
+		})).Run(func(args mock.Arguments) { token = args.String(1) }).Return(nil).Once()
+		env.EmailService.On("SendMagicLinkEmail", user.Email, user.FullName, mock.MatchedBy(func(link string) bool {
+			return strings.HasPrefix(link, "https://app.example.com/login?magic_token=")
+		}), 15*time.Minute).Return(nil).Once()
+
+		w := jobRequest("POST", route, route, []gin.HandlerFunc{env.Handler.RequestMagicLinkHandler}, map[string]string{"email": "sam@example.com"})
+		time.Sleep(20 * time.Millisecond)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Len(t, token, 64)
+		env.MagicLinkStore.AssertExpectations(t)
+		env.EmailService.AssertExpectations(t)
+	})
+
+	t.Run("UnknownEmailSameAnswer", func(t *testing.T) {
+		env := setupMagicLinkEnv()
+		env.MagicLinkStore.On("RecordMagicLinkRequest", mock.Anything, mock.Anything).Return(1, nil).Once()
+		env.UserStore.On("GetUserByEmail", "nobody@example.com").Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("POST", route, route, []gin.HandlerFunc{env.Handler.RequestMagicLinkHandler}, map[string]string{"email": "nobody@example.com"})
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		env.MagicLinkStore.AssertNotCalled(t, "CreateMagicLink", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("DisabledForOrganization", func(t *testing.T) {
+		env := setupMagicLinkEnv()
+		env.MagicLinkStore.On("RecordMagicLinkRequest", mock.Anything, mock.Anything).Return(1, nil).Once()
+		env.UserStore.On("GetUserByEmail", "sam@example.com").Return(user, nil).Once()
+		env.OrgStore.On("GetMagicLinkEnabled", orgID).Return(false, nil).Once()
+
+		w := jobRequest("POST", route, route, []gin.HandlerFunc{env.Handler.RequestMagicLinkHandler}, map[string]string{"email": "sam@example.com"})
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		env.MagicLinkStore.AssertNotCalled(t, "CreateMagicLink", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("AccountLimitSilent", func(t *testing.T) {
+		env := setupMagicLinkEnv()
+		env.MagicLinkStore.On("RecordMagicLinkRequest", mock.Anything, mock.Anything).Return(4, nil).Once()
+		env.UserStore.On("GetUserByEmail", "sam@example.com").Return(user, nil).Once()
+		env.OrgStore.On("GetMagicLinkEnabled", orgID).Return(true, nil).Once()
+		env.MagicLinkStore.On("CountRecentMagicLinks", user.ID, mock.Anything).Return(3, nil).Once()
+
+		w := jobRequest("POST", route, route, []gin.HandlerFunc{env.Handler.RequestMagicLinkHandler}, map[string]string{"email": "sam@example.com"})
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		env.MagicLinkStore.AssertNotCalled(t, "CreateMagicLink", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("IPLimit", func(t *testing.T) {
+		env := setupMagicLinkEnv()
+		env.MagicLinkStore.On("RecordMagicLinkRequest", mock.Anything, mock.Anything).Return(10, nil).Once()
+		env.UserStore.On("GetUserByEmail", "sam@example.com").Return(user, nil).Once()
+		env.OrgStore.On("GetMagicLinkEnabled", orgID).Return(true, nil).Once()
+		env.MagicLinkStore.On("CountRecentMagicLinks", user.ID, mock.Anything).Return(0, nil).Once()
+		env.MagicLinkStore.On("CreateMagicLink", user.ID, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+		env.EmailService.On("SendMagicLinkEmail", user.Email, user.FullName, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+		// the 10th request of the hour is still answered
+		w := jobRequest("POST", route, route, []gin.HandlerFunc{env.Handler.RequestMagicLinkHandler}, map[string]string{"email": "sam@example.com"})
+		assert.Equal(t, http.StatusAccepted, w.Code)
+
+		env.MagicLinkStore.On("RecordMagicLinkRequest", mock.Anything, mock.Anything).Return(11, nil).Once()
+		w = jobRequest("POST", route, route, []gin.HandlerFunc{env.Handler.RequestMagicLinkHandler}, map[string]string{"email": "sam@example.com"})
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "3600", w.Header().Get("Retry-After"))
+	})
+
+	t.Run("IPLimitBeforeLookup", func(t *testing.T) {
+		env := setupMagicLinkEnv()
+		env.MagicLinkStore.On("RecordMagicLinkRequest", mock.Anything, mock.Anything).Return(11, nil).Once()
+
+		// an unknown email is limited like a known one
+		w := jobRequest("POST", route, route, []gin.HandlerFunc{env.Handler.RequestMagicLinkHandler}, map[string]string{"email": "nobody@example.com"})
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		env.UserStore.AssertNotCalled(t, "GetUserByEmail", mock.Anything)
+	})
+
+	t.Run("InvalidEmail", func(t *testing.T) {
+		env := setupMagicLinkEnv()
+
+		w := jobRequest("POST", route, route, []gin.HandlerFunc{env.Handler.RequestMagicLinkHandler}, map[string]string{"email": "sam"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestExchangeMagicLinkHandler(t *testing.T) {
+	orgID := uuid.New()
+	user := &database.User{ID: uuid.New(), OrganizationID: orgID, FullName: "Sam Cook", Email: "sam@example.com", UserRole: "employee"}
+	route := "/auth/magic-link/exchange"
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupMagicLinkEnv()
+		tokens := &fakeTokenGenerator{}
+		env.MagicLinkStore.On("ConsumeMagicLink", "token").Return(user.ID, nil).Once()
+		env.UserStore.On("GetUserByID", user.ID).Return(user, nil).Once()
+		env.OrgStore.On("GetMagicLinkEnabled", orgID).Return(true, nil).Once()
+
+		w := jobRequest("POST", route, route, []gin.HandlerFunc{env.Handler.ExchangeMagicLinkHandler(tokens)}, map[string]string{"token": "token"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]any
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "access", response["access_token"])
+		assert.Equal(t, user.ID, tokens.issuedFor.ID)
+	})
+
+	t.Run("UsedOrExpired", func(t *testing.T) {
+		env := setupMagicLinkEnv()
+		env.MagicLinkStore.On("ConsumeMagicLink", "token").Return(uuid.Nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("POST", route, route, []gin.HandlerFunc{env.Handler.ExchangeMagicLinkHandler(&fakeTokenGenerator{})}, map[string]string{"token": "token"})
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("DisabledSinceSent", func(t *testing.T) {
+		env := setupMagicLinkEnv()
+		tokens := &fakeTokenGenerator{}
+		env.MagicLinkStore.On("ConsumeMagicLink", "token").Return(user.ID, nil).Once()
+		env.UserStore.On("GetUserByID", user.ID).Return(user, nil).Once()
+		env.OrgStore.On("GetMagicLinkEnabled", orgID).Return(false, nil).Once()
+
+		w := jobRequest("POST", route, route, []gin.HandlerFunc{env.Handler.ExchangeMagicLinkHandler(tokens)}, map[string]string{"token": "token"})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Nil(t, tokens.issuedFor)
+	})
+
+	t.Run("TokenError", func(t *testing.T) {
+		env := setupMagicLinkEnv()
+		env.MagicLinkStore.On("ConsumeMagicLink", "token").Return(user.ID, nil).Once()
+		env.UserStore.On("GetUserByID", user.ID).Return(user, nil).Once()
+		env.OrgStore.On("GetMagicLinkEnabled", orgID).Return(true, nil).Once()
+
+		w := jobRequest("POST", route, route, []gin.HandlerFunc{env.Handler.ExchangeMagicLinkHandler(&fakeTokenGenerator{err: errors.New("signing failed")})},
+			map[string]string{"token": "token"})
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestUpdateMagicLinkSettingsHandler(t *testing.T) {
+	orgID := uuid.New()
+	route := "/:org/magic-link"
+	path := "/" + orgID.String() + "/magic-link"
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupMagicLinkEnv()
+		admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+		env.OrgStore.On("SetMagicLinkEnabled", orgID, true).Return(nil).Once()
+
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.UpdateMagicLinkSettingsHandler}, map[string]bool{"enabled": true})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.OrgStore.AssertExpectations(t)
+	})
+
+	t.Run("MissingEnabled", func(t *testing.T) {
+		env := setupMagicLinkEnv()
+		admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.UpdateMagicLinkSettingsHandler}, map[string]any{})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("ManagerForbidden", func(t *testing.T) {
+		env := setupMagicLinkEnv()
+		manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.UpdateMagicLinkSettingsHandler}, map[string]bool{"enabled": false})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
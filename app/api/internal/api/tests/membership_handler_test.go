@@ -0,0 +1,371 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/appleboy/gin-jwt/v3/core"
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MembershipTestEnv struct {
+	Router          *gin.Engine
+	MembershipStore *MockMembershipStore
+	UserStore       *MockUserStore
+	Handler         *api.MembershipHandler
+}
+
+func setupMembershipEnv() *MembershipTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	membershipStore := new(MockMembershipStore)
+	userStore := new(MockUserStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	handler := api.NewMembershipHandler(membershipStore, userStore, logger)
+
+	return &MembershipTestEnv{
+		Router:          gin.New(),
+		MembershipStore: membershipStore,
+		UserStore:       userStore,
+		Handler:         handler,
+	}
+}
+
+func (env *MembershipTestEnv) ResetMocks() {
+	env.MembershipStore.ExpectedCalls = nil
+	env.MembershipStore.Calls = nil
+	env.UserStore.ExpectedCalls = nil
+	env.UserStore.Calls = nil
+}
+
+// fakeTokenGenerator records the user a token was issued for, and when they stepped up for step-up tokens
+type fakeTokenGenerator struct {
+	issuedFor   *database.User
+	steppedUpAt time.Time
+	err         error
+}
+
+func (f *fakeTokenGenerator) TokenGenerator(ctx context.Context, data any) (*core.Token, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if stepUp, ok := data.(*middleware.StepUp); ok {
+		f.issuedFor, f.steppedUpAt = stepUp.User, stepUp.At
+	} else {
+		f.issuedFor = data.(*database.User)
+	}
+	return &core.Token{AccessToken: "access", RefreshToken: "refresh", ExpiresAt: 1700000000}, nil
+}
+
+func TestSwitchOrganizationHandler(t *testing.T) {
+	env := setupMembershipEnv()
+	homeOrg := uuid.New()
+	otherOrg := uuid.New()
+	user := &database.User{ID: uuid.New(), FullName: "Maya", OrganizationID: homeOrg, UserRole: "employee"}
+	tokens := &fakeTokenGenerator{}
+
+	env.Router.POST("/auth/switch-org", authMiddleware(user), env.Handler.SwitchOrganizationHandler(tokens))
+
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/auth/switch-org", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.MembershipStore.On("GetMembership", user.ID, otherOrg).
+			Return(&database.OrganizationMembership{UserID: user.ID, OrganizationID: otherOrg, UserRole: "manager"}, nil)
+
+		w := post(`{"organization_id": "` + otherOrg.String() + `"}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "access", response["access_token"])
+		assert.Equal(t, "manager", response["user_role"])
+		assert.Equal(t, otherOrg, tokens.issuedFor.OrganizationID)
+		assert.Equal(t, "manager", tokens.issuedFor.UserRole)
+		assert.Equal(t, homeOrg, user.OrganizationID, "the current user must not be modified")
+	})
+
+	t.Run("NotAMember", func(t *testing.T) {
+		env.ResetMocks()
+		env.MembershipStore.On("GetMembership", user.ID, otherOrg).Return(nil, sql.ErrNoRows)
+
+		w := post(`{"organization_id": "` + otherOrg.String() + `"}`)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("MissingOrganization", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := post(`{}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("TokenError", func(t *testing.T) {
+		env.ResetMocks()
+		env.MembershipStore.On("GetMembership", user.ID, otherOrg).
+			Return(&database.OrganizationMembership{UserID: user.ID, OrganizationID: otherOrg, UserRole: "employee"}, nil)
+		tokens.err = errors.New("signing failed")
+		defer func() { tokens.err = nil }()
+
+		w := post(`{"organization_id": "` + otherOrg.String() + `"}`)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestGetMyOrganizationsHandler(t *testing.T) {
+	env := setupMembershipEnv()
+	orgID := uuid.New()
+	user := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+	env.Router.GET("/auth/organizations", authMiddleware(user), env.Handler.GetMyOrganizationsHandler)
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.MembershipStore.On("GetMembershipsForUser", user.ID).Return([]database.OrganizationMembership{
+			{UserID: user.ID, OrganizationID: orgID, OrganizationName: "Downtown", UserRole: "employee"},
+			{UserID: user.ID, OrganizationID: uuid.New(), OrganizationName: "Uptown", UserRole: "manager"},
+		}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/auth/organizations", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, orgID.String(), data["current_organization_id"])
+		assert.Len(t, data["organizations"], 2)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.MembershipStore.On("GetMembershipsForUser", user.ID).Return(nil, errors.New("db error"))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/auth/organizations", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestAddMemberHandler(t *testing.T) {
+	env := setupMembershipEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+
+	env.Router.POST("/admin/:org/staffing/members", authMiddleware(admin), env.Handler.AddMemberHandler)
+	env.Router.POST("/manager/:org/staffing/members", authMiddleware(manager), env.Handler.AddMemberHandler)
+
+	post := func(prefix, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", prefix+"/"+orgID.String()+"/staffing/members", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		member := &database.User{ID: uuid.New(), Email: "sam@example.com", OrganizationID: uuid.New(), UserRole: "manager"}
+		env.UserStore.On("GetUserByEmail", "sam@example.com").Return(member, nil)
+		env.MembershipStore.On("AddMembership", mock.MatchedBy(func(m *database.OrganizationMembership) bool {
+			return m.UserID == member.ID && m.OrganizationID == orgID && m.UserRole == "manager"
+		})).Return(nil)
+
+		w := post("/admin", `{"email": "sam@example.com", "user_role": "manager"}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		env.MembershipStore.AssertExpectations(t)
+	})
+
+	t.Run("Forbidden_Manager", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := post("/manager", `{"email": "sam@example.com", "user_role": "manager"}`)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("InvalidRole", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := post("/admin", `{"email": "sam@example.com", "user_role": "admin"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("UserNotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByEmail", "ghost@example.com").Return(nil, sql.ErrNoRows)
+
+		w := post("/admin", `{"email": "ghost@example.com", "user_role": "employee"}`)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("AlreadyInOrganization", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByEmail", "local@example.com").
+			Return(&database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}, nil)
+
+		w := post("/admin", `{"email": "local@example.com", "user_role": "employee"}`)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestRemoveMemberHandler(t *testing.T) {
+	env := setupMembershipEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+
+	env.Router.DELETE("/:org/staffing/members/:id", authMiddleware(admin), env.Handler.RemoveMemberHandler)
+
+	remove := func(id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/"+orgID.String()+"/staffing/members/"+id, nil)
+		env.Router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		memberID := uuid.New()
+		env.UserStore.On("GetUserByID", memberID).Return(&database.User{ID: memberID, OrganizationID: uuid.New()}, nil)
+		env.MembershipStore.On("RemoveMembership", memberID, orgID).Return(nil)
+
+		w := remove(memberID.String())
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("HomeOrganization", func(t *testing.T) {
+		env.ResetMocks()
+		memberID := uuid.New()
+		env.UserStore.On("GetUserByID", memberID).Return(&database.User{ID: memberID, OrganizationID: orgID}, nil)
+
+		w := remove(memberID.String())
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		env.MembershipStore.AssertNotCalled(t, "RemoveMembership", mock.Anything, mock.Anything)
+	})
+
+	t.Run("NotAMember", func(t *testing.T) {
+		env.ResetMocks()
+		memberID := uuid.New()
+		env.UserStore.On("GetUserByID", memberID).Return(&database.User{ID: memberID, OrganizationID: uuid.New()}, nil)
+		env.MembershipStore.On("RemoveMembership", memberID, orgID).Return(sql.ErrNoRows)
+
+		w := remove(memberID.String())
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := remove("not-a-uuid")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestOrgMembershipMiddleware(t *testing.T) {
+	env := setupMembershipEnv()
+	orgID := uuid.New()
+	user := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+
+	env.Router.GET("/:org/whoami", authMiddleware(user), middleware.OrgMembership(env.MembershipStore), func(c *gin.Context) {
+		current := middleware.ValidateOrgAccess(c)
+		if current == nil {
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"user_role": current.UserRole})
+	})
+
+	get := func(org uuid.UUID) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+org.String()+"/whoami", nil)
+		env.Router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Member", func(t *testing.T) {
+		env.ResetMocks()
+		env.MembershipStore.On("GetMembership", user.ID, orgID).
+			Return(&database.OrganizationMembership{UserID: user.ID, OrganizationID: orgID, UserRole: "manager"}, nil)
+
+		w := get(orgID)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"manager"`)
+	})
+
+	t.Run("RoleChangedSinceTokenIssued", func(t *testing.T) {
+		env.ResetMocks()
+		env.MembershipStore.On("GetMembership", user.ID, orgID).
+			Return(&database.OrganizationMembership{UserID: user.ID, OrganizationID: orgID, UserRole: "employee"}, nil)
+
+		w := get(orgID)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"employee"`)
+		assert.Equal(t, "manager", user.UserRole, "the token user must not be modified")
+	})
+
+	t.Run("MembershipRemoved", func(t *testing.T) {
+		env.ResetMocks()
+		env.MembershipStore.On("GetMembership", user.ID, orgID).Return(nil, sql.ErrNoRows)
+
+		w := get(orgID)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.MembershipStore.On("GetMembership", user.ID, orgID).Return(nil, errors.New("db error"))
+
+		w := get(orgID)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("OtherOrganization", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := get(uuid.New())
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "switch-org")
+		env.MembershipStore.AssertNotCalled(t, "GetMembership", mock.Anything, mock.Anything)
+	})
+}
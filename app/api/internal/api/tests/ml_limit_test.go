@@ -0,0 +1,203 @@
+package api
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMLGuard(maxConcurrent, hourlyQuota int, cooldown time.Duration) *middleware.MLGuard {
+	guard := middleware.NewMLGuard(slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	guard.MaxConcurrent = maxConcurrent
+	guard.HourlyQuota = hourlyQuota
+	guard.Cooldown = cooldown
+	return guard
+}
+
+func mlRequest(router *gin.Engine, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", path, nil)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestMLGuard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	path := "/" + orgID.String() + "/predict"
+
+	t.Run("JoinsCallInProgress", func(t *testing.T) {
+		guard := newTestMLGuard(1, 10, time.Hour)
+		started, release := make(chan struct{}), make(chan struct{})
+		calls := 0
+		router := gin.New()
+		router.POST("/:org/predict", authMiddleware(manager), guard.Limit("schedule"), func(c *gin.Context) {
+			calls++
+			close(started)
+			<-release
+			c.JSON(http.StatusOK, gin.H{"message": "generated"})
+		})
+
+		var wg sync.WaitGroup
+		var first, second *httptest.ResponseRecorder
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			first = mlRequest(router, path)
+		}()
+		<-started
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			second = mlRequest(router, path)
+		}()
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Equal(t, "started", first.Header().Get("X-ML-Job"))
+		assert.Equal(t, http.StatusOK, second.Code)
+		assert.Equal(t, "joined", second.Header().Get("X-ML-Job"))
+		assert.JSONEq(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, "application/json; charset=utf-8", second.Header().Get("Content-Type"))
+		assert.Equal(t, "9", second.Header().Get("X-ML-Quota-Remaining"))
+
+		// the schedule was just generated
+		w := mlRequest(router, path)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.NotEmpty(t, w.Header().Get("Retry-After"))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("DifferentBodiesNotJoined", func(t *testing.T) {
+		guard := newTestMLGuard(2, 10, time.Hour)
+		started, release := make(chan struct{}, 2), make(chan struct{})
+		router := gin.New()
+		router.POST("/:org/scenarios", authMiddleware(manager), guard.Limit("scenarios"), func(c *gin.Context) {
+			body, _ := io.ReadAll(c.Request.Body)
+			started <- struct{}{}
+			<-release
+			c.Data(http.StatusOK, "application/json", body)
+		})
+		send := func(body string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/"+orgID.String()+"/scenarios", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		var wg sync.WaitGroup
+		var first, second *httptest.ResponseRecorder
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			first = send(`{"scenarios": [{"name": "lean"}]}`)
+		}()
+		go func() {
+			defer wg.Done()
+			second = send(`{"scenarios": [{"name": "full"}]}`)
+		}()
+		<-started
+		<-started
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, "started", first.Header().Get("X-ML-Job"))
+		assert.Equal(t, "started", second.Header().Get("X-ML-Job"))
+		assert.JSONEq(t, `{"scenarios": [{"name": "lean"}]}`, first.Body.String())
+		assert.JSONEq(t, `{"scenarios": [{"name": "full"}]}`, second.Body.String())
+
+		// the cooldown only holds back the same request, whatever its spacing
+		assert.Equal(t, http.StatusTooManyRequests, send(`{"scenarios":[{"name":"lean"}]}`).Code)
+		assert.Equal(t, http.StatusOK, send(`{"scenarios": [{"name": "other"}]}`).Code)
+	})
+
+	t.Run("ConcurrencyLimit", func(t *testing.T) {
+		guard := newTestMLGuard(1, 10, 0)
+		started, release := make(chan struct{}), make(chan struct{})
+		router := gin.New()
+		router.POST("/:org/predict", authMiddleware(manager), guard.Limit("schedule"), func(c *gin.Context) {
+			close(started)
+			<-release
+			c.JSON(http.StatusOK, gin.H{})
+		})
+		router.POST("/:org/demand", authMiddleware(manager), guard.Limit("demand"), func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{})
+		})
+
+		done := make(chan struct{})
+		go func() {
+			mlRequest(router, path)
+			close(done)
+		}()
+		<-started
+
+		w := mlRequest(router, "/"+orgID.String()+"/demand")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+		// other organizations are not held up
+		otherOrg := uuid.New()
+		other := gin.New()
+		other.POST("/:org/demand", authMiddleware(&database.User{ID: uuid.New(), OrganizationID: otherOrg, UserRole: "admin"}),
+			guard.Limit("demand"), func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) })
+		assert.Equal(t, http.StatusOK, mlRequest(other, "/"+otherOrg.String()+"/demand").Code)
+
+		close(release)
+		<-done
+		assert.Equal(t, http.StatusOK, mlRequest(router, "/"+orgID.String()+"/demand").Code)
+	})
+
+	t.Run("HourlyQuota", func(t *testing.T) {
+		guard := newTestMLGuard(1, 2, 0)
+		status := http.StatusUnprocessableEntity
+		router := gin.New()
+		router.POST("/:org/predict", authMiddleware(manager), guard.Limit("schedule"), func(c *gin.Context) {
+			c.JSON(status, gin.H{})
+		})
+
+		// rejected input does not use up the quota
+		w := mlRequest(router, path)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, "2", w.Header().Get("X-ML-Quota-Limit"))
+
+		status = http.StatusOK
+		assert.Equal(t, "1", mlRequest(router, path).Header().Get("X-ML-Quota-Remaining"))
+		assert.Equal(t, "0", mlRequest(router, path).Header().Get("X-ML-Quota-Remaining"))
+
+		w = mlRequest(router, path)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Contains(t, w.Body.String(), "quota")
+		assert.Equal(t, "0", w.Header().Get("X-ML-Quota-Remaining"))
+	})
+
+	t.Run("EmployeesLeftToHandler", func(t *testing.T) {
+		guard := newTestMLGuard(1, 1, time.Hour)
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+		router := gin.New()
+		router.POST("/:org/predict", authMiddleware(employee), guard.Limit("schedule"), func(c *gin.Context) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can access orders"})
+		})
+
+		for range 2 {
+			w := mlRequest(router, path)
+			assert.Equal(t, http.StatusForbidden, w.Code)
+			assert.Empty(t, w.Header().Get("X-ML-Quota-Remaining"))
+		}
+	})
+}
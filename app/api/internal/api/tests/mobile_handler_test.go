@@ -0,0 +1,182 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MobileTestEnv struct {
+	Store         *MockMobileSummaryStore
+	ScheduleStore *MockScheduleStore
+	Handler       *api.MobileHandler
+}
+
+func setupMobileEnv() *MobileTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	store := new(MockMobileSummaryStore)
+	scheduleStore := new(MockScheduleStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &MobileTestEnv{
+		Store:         store,
+		ScheduleStore: scheduleStore,
+		Handler:       api.NewMobileHandler(store, scheduleStore, logger),
+	}
+}
+
+func TestParseMobileFields(t *testing.T) {
+	t.Run("EverythingByDefault", func(t *testing.T) {
+		selected, err := api.ParseMobileFields("")
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][]string{"schedule": nil, "alerts": nil, "pending": nil}, selected)
+	})
+
+	t.Run("SectionsAndFields", func(t *testing.T) {
+		selected, err := api.ParseMobileFields(" schedule.name, schedule.on_now,pending,schedule.name")
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][]string{"schedule": {"name", "on_now"}, "pending": nil}, selected)
+	})
+
+	t.Run("WholeSectionWins", func(t *testing.T) {
+		selected, err := api.ParseMobileFields("alerts,alerts.kind")
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][]string{"alerts": nil}, selected)
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		_, sectionErr := api.ParseMobileFields("payroll")
+		_, fieldErr := api.ParseMobileFields("schedule.salary")
+		_, emptyErr := api.ParseMobileFields(",")
+
+		assert.Error(t, sectionErr)
+		assert.Error(t, fieldErr)
+		assert.Error(t, emptyErr)
+	})
+}
+
+func TestGetMobileSummaryHandler(t *testing.T) {
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/mobile/summary"
+	path := "/" + orgID.String() + "/mobile/summary"
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	yesterday := today.AddDate(0, 0, -1)
+	shifts := []database.Shift{
+		{EmployeeName: "Yesterday Only", Date: yesterday, StartTime: "08:00:00", EndTime: "16:00:00", ShiftType: "working"},
+		{EmployeeName: "Ada Lovelace", EmployeeEmail: "ada@test.com", Date: today, StartTime: "00:00:00", EndTime: "00:00:00", ShiftType: "working"},
+	}
+	variance := 32.0
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupMobileEnv()
+		handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetMobileSummaryHandler}
+		env.ScheduleStore.On("GetShiftsFrom", orgID, yesterday).Return(shifts, nil).Once()
+		env.Store.On("GetAlertsSince", orgID, today, 20).Return([]database.MobileAlert{
+			{Kind: database.MobileAlertForecastVariance, At: now, Detail: "above forecast through 14:00, call_in", Value: &variance},
+		}, nil).Once()
+		env.Store.On("GetPendingApprovals", orgID, "manager").Return(&database.PendingApprovals{Requests: 2, Skills: 1, Total: 3}, nil).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+		var resp struct {
+			Data struct {
+				Date     string                    `json:"date"`
+				Schedule []api.MobileShift         `json:"schedule"`
+				Alerts   []database.MobileAlert    `json:"alerts"`
+				Pending  database.PendingApprovals `json:"pending"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, today.Format(time.DateOnly), resp.Data.Date)
+		assert.Equal(t, []api.MobileShift{{Name: "Ada L.", Start: "00:00:00", End: "00:00:00", Type: "working", OnNow: true}}, resp.Data.Schedule)
+		assert.Equal(t, 32.0, *resp.Data.Alerts[0].Value)
+		assert.Equal(t, 3, resp.Data.Pending.Total)
+		assert.NotContains(t, w.Body.String(), "ada@test.com")
+		env.Store.AssertExpectations(t)
+	})
+
+	t.Run("TrimmedFields", func(t *testing.T) {
+		env := setupMobileEnv()
+		handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetMobileSummaryHandler}
+		env.ScheduleStore.On("GetShiftsFrom", orgID, yesterday).Return(shifts, nil).Once()
+		env.Store.On("GetPendingApprovals", orgID, "manager").Return(&database.PendingApprovals{Requests: 2, Skills: 1, Total: 3}, nil).Once()
+
+		w := jobRequest("GET", route, path+"?fields=schedule.name,pending.total", handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data map[string]any `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, []any{map[string]any{"name": "Ada L."}}, resp.Data["schedule"])
+		assert.Equal(t, map[string]any{"total": 3.0}, resp.Data["pending"])
+		assert.NotContains(t, resp.Data, "alerts")
+		env.Store.AssertNotCalled(t, "GetAlertsSince", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("NotModified", func(t *testing.T) {
+		env := setupMobileEnv()
+		handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetMobileSummaryHandler}
+		env.Store.On("GetPendingApprovals", orgID, "manager").Return(&database.PendingApprovals{Total: 0}, nil).Twice()
+
+		first := jobRequest("GET", route, path+"?fields=pending", handlers, nil)
+		router := gin.New()
+		router.GET(route, handlers...)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path+"?fields=pending", nil)
+		req.Header.Set("If-None-Match", first.Header().Get("ETag"))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("InvalidFields", func(t *testing.T) {
+		env := setupMobileEnv()
+		handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetMobileSummaryHandler}
+
+		w := jobRequest("GET", route, path+"?fields=schedule.wage", handlers, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		env := setupMobileEnv()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetMobileSummaryHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env := setupMobileEnv()
+		handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetMobileSummaryHandler}
+		env.Store.On("GetAlertsSince", orgID, today, 20).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path+"?fields=alerts", handlers, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type NotificationSettingsTestEnv struct {
+	NotificationStore *MockNotificationStore
+	Handler           *api.NotificationSettingsHandler
+}
+
+func setupNotificationSettingsEnv() *NotificationSettingsTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	notificationStore := new(MockNotificationStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &NotificationSettingsTestEnv{
+		NotificationStore: notificationStore,
+		Handler:           api.NewNotificationSettingsHandler(notificationStore, logger),
+	}
+}
+
+func (env *NotificationSettingsTestEnv) ResetMocks() {
+	env.NotificationStore.ExpectedCalls = nil
+	env.NotificationStore.Calls = nil
+}
+
+func TestGetNotificationSettingsHandler(t *testing.T) {
+	env := setupNotificationSettingsEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/me/notifications"
+	path := "/" + orgID.String() + "/me/notifications"
+	handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetNotificationSettingsHandler}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.NotificationStore.On("GetSettings", manager.ID).Return(&database.NotificationSettings{DigestMode: database.DigestDaily}, nil).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"digest_mode":"daily"`)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.NotificationStore.On("GetSettings", manager.ID).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestUpdateNotificationSettingsHandler(t *testing.T) {
+	env := setupNotificationSettingsEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/me/notifications"
+	path := "/" + orgID.String() + "/me/notifications"
+	handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.UpdateNotificationSettingsHandler}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.NotificationStore.On("SetDigestMode", manager.ID, "hourly").Return(nil).Once()
+
+		w := jobRequest("PUT", route, path, handlers, map[string]any{"digest_mode": "hourly"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.NotificationStore.AssertExpectations(t)
+	})
+
+	t.Run("InvalidMode", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("PUT", route, path, handlers, map[string]any{"digest_mode": "weekly"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		env.NotificationStore.AssertNotCalled(t, "SetDigestMode", mock.Anything, mock.Anything)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.NotificationStore.On("SetDigestMode", manager.ID, "immediate").Return(errors.New("db error")).Once()
+
+		w := jobRequest("PUT", route, path, handlers, map[string]any{"digest_mode": "immediate"})
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,278 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type NotificationRoutingTestEnv struct {
+	RoutingStore  *MockNotificationRoutingStore
+	UserStore     *MockUserStore
+	ScheduleStore *MockScheduleStore
+	SMSService    *MockSMSService
+	Router        *api.NotificationRouter
+	Handler       *api.NotificationRoutingHandler
+}
+
+func setupNotificationRoutingEnv() *NotificationRoutingTestEnv {
+	gin.SetMode(gin.TestMode)
+	env := &NotificationRoutingTestEnv{
+		RoutingStore:  new(MockNotificationRoutingStore),
+		UserStore:     new(MockUserStore),
+		ScheduleStore: new(MockScheduleStore),
+		SMSService:    new(MockSMSService),
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	env.Router = api.NewNotificationRouter(env.RoutingStore, env.UserStore, env.ScheduleStore, env.SMSService, logger)
+	env.Handler = api.NewNotificationRoutingHandler(env.Router, logger)
+	return env
+}
+
+func TestSimulateRoutingHandler(t *testing.T) {
+	orgID := uuid.New()
+	phone := "+201000000000"
+	onDuty := &database.User{ID: uuid.New(), OrganizationID: orgID, FullName: "On Duty", Email: "duty@test.com", UserRole: "manager", Phone: &phone}
+	offDuty := &database.User{ID: uuid.New(), OrganizationID: orgID, FullName: "Off Duty", Email: "off@test.com", UserRole: "manager"}
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, FullName: "Admin", Email: "admin@test.com", UserRole: "admin"}
+	employeeID := uuid.New()
+	route := "/:org/notification-routing/simulate"
+	path := "/" + orgID.String() + "/notification-routing/simulate"
+
+	day := time.Date(2026, 10, 20, 0, 0, 0, 0, time.Local)
+	at := time.Date(2026, 10, 20, 14, 0, 0, 0, time.Local)
+	shifts := []database.Shift{
+		{EmployeeID: onDuty.ID, Date: day, StartTime: "10:00:00", EndTime: "18:00:00"},
+		{EmployeeID: offDuty.ID, Date: day, StartTime: "18:00:00", EndTime: "23:00:00"},
+		{EmployeeID: employeeID, Date: day, StartTime: "16:00:00", EndTime: "22:00:00"},
+	}
+	within := 4
+	rules := []database.NotificationRoutingRule{
+		{ID: uuid.New(), Event: "calloff", WithinHours: &within, Channel: database.RoutingChannelSMS, Recipients: database.RoutingOnDutyManager},
+	}
+
+	expectRoute := func(env *NotificationRoutingTestEnv) {
+		env.RoutingStore.On("GetRoutingRules", orgID).Return(rules, nil).Once()
+		env.UserStore.On("GetUsersByOrganization", orgID).Return([]*database.User{onDuty, offDuty, admin}, nil).Once()
+		env.ScheduleStore.On("GetShiftsFrom", orgID, day.AddDate(0, 0, -1)).Return(shifts, nil).Once()
+	}
+
+	t.Run("CalloffCloseToShiftTextsManagerOnDuty", func(t *testing.T) {
+		env := setupNotificationRoutingEnv()
+		expectRoute(env)
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.SimulateRoutingHandler},
+			map[string]any{"event": "calloff", "employee_id": employeeID, "at": at})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data api.NotificationRoute `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		if assert.NotNil(t, response.Data.Rule) {
+			assert.Equal(t, rules[0].ID, response.Data.Rule.ID)
+		}
+		assert.Equal(t, database.RoutingChannelSMS, response.Data.Channel)
+		if assert.NotNil(t, response.Data.HoursBeforeShift) {
+			assert.Equal(t, 2.0, *response.Data.HoursBeforeShift)
+		}
+		if assert.Len(t, response.Data.SendTo, 1) {
+			assert.Equal(t, onDuty.ID, response.Data.SendTo[0].UserID)
+			assert.Equal(t, database.RoutingChannelSMS, response.Data.SendTo[0].Channel)
+		}
+		env.SMSService.AssertNotCalled(t, "SendSMS", mock.Anything, mock.Anything)
+	})
+
+	t.Run("OtherwiseDefaultEmail", func(t *testing.T) {
+		env := setupNotificationRoutingEnv()
+		expectRoute(env)
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.SimulateRoutingHandler},
+			map[string]any{"event": "calloff", "hours_before_shift": 6, "at": at})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data api.NotificationRoute `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Nil(t, response.Data.Rule)
+		assert.Equal(t, database.RoutingChannelEmail, response.Data.Channel)
+		assert.Len(t, response.Data.SendTo, 3)
+	})
+
+	t.Run("MissingEmployeeAndHours", func(t *testing.T) {
+		env := setupNotificationRoutingEnv()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.SimulateRoutingHandler},
+			map[string]any{"event": "calloff"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		env := setupNotificationRoutingEnv()
+		employee := &database.User{ID: employeeID, OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.SimulateRoutingHandler},
+			map[string]any{"event": "calloff", "hours_before_shift": 1})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestCreateRoutingRuleHandler(t *testing.T) {
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	route := "/:org/notification-routing"
+	path := "/" + orgID.String() + "/notification-routing"
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupNotificationRoutingEnv()
+		env.RoutingStore.On("CreateRoutingRule", orgID, mock.MatchedBy(func(rule *database.NotificationRoutingRule) bool {
+			return rule.Event == "calloff" && rule.WithinHours != nil && *rule.WithinHours == 4 && rule.Channel == "sms" &&
+				rule.Recipients == "on_duty_manager" && *rule.CreatedBy == admin.ID
+		})).Return(nil).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.CreateRoutingRuleHandler},
+			map[string]any{"event": "calloff", "within_hours": 4, "channel": "sms", "recipients": "on_duty_manager"})
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		env.RoutingStore.AssertExpectations(t)
+	})
+
+	t.Run("InvalidChannel", func(t *testing.T) {
+		env := setupNotificationRoutingEnv()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.CreateRoutingRuleHandler},
+			map[string]any{"event": "calloff", "channel": "pager", "recipients": "managers"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("ManagerForbidden", func(t *testing.T) {
+		env := setupNotificationRoutingEnv()
+		manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.CreateRoutingRuleHandler},
+			map[string]any{"event": "calloff", "channel": "sms", "recipients": "managers"})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestDeleteRoutingRuleHandler(t *testing.T) {
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	ruleID := uuid.New()
+	route := "/:org/notification-routing/:id"
+	path := "/" + orgID.String() + "/notification-routing/" + ruleID.String()
+
+	t.Run("NotFound", func(t *testing.T) {
+		env := setupNotificationRoutingEnv()
+		env.RoutingStore.On("DeleteRoutingRule", orgID, ruleID).Return(sql.ErrNoRows).Once()
+
+		w := jobRequest("DELETE", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.DeleteRoutingRuleHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestRequestNotificationRouting(t *testing.T) {
+	orgID := uuid.New()
+	user := &database.User{ID: uuid.New(), OrganizationID: orgID, FullName: "Test User", Email: "test@test.com", UserRole: "employee"}
+	phone := "+201000000000"
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, Email: "mgr@test.com", UserRole: "manager", Phone: &phone}
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, Email: "admin@test.com", UserRole: "admin"}
+	rules := []database.NotificationRoutingRule{
+		{ID: uuid.New(), Event: "calloff", Channel: database.RoutingChannelSMS, Recipients: database.RoutingManagersAndAdmins},
+	}
+
+	send := func(env *EmployeeTestEnv) *httptest.ResponseRecorder {
+		r := gin.New()
+		r.POST("/:org/request", authMiddleware(user), env.Handler.RequestHandlerForEmployee)
+		jsonBody, _ := json.Marshal(api.CalloffRequest{Type: "calloff", Message: "Sick"})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/request", bytes.NewBuffer(jsonBody))
+		r.ServeHTTP(w, req)
+		time.Sleep(20 * time.Millisecond)
+		return w
+	}
+
+	expectRequest := func(env *EmployeeTestEnv) {
+		env.ApprovalStore.On("GetApprovalChain", orgID, "calloff").Return([]string{"manager"}, nil).Once()
+		env.ApprovalStore.On("StartApprovals", mock.Anything, []string{"manager"}).Return(nil).Once()
+		env.RequestStore.On("CreateRequest", mock.Anything).Return(nil).Once()
+		env.EmailService.On("SendRequestSubmittedEmail", user.Email, user.FullName, "calloff", "Sick").Return(nil).Once()
+	}
+
+	t.Run("TextsWithPhoneEmailsOthers", func(t *testing.T) {
+		env := setupEmployeeEnv()
+		routing := setupNotificationRoutingEnv()
+		env.Handler.Router = routing.Router
+		expectRequest(env)
+		routing.RoutingStore.On("GetRoutingRules", orgID).Return(rules, nil).Once()
+		routing.UserStore.On("GetUsersByOrganization", orgID).Return([]*database.User{user, manager, admin}, nil).Once()
+		routing.ScheduleStore.On("GetShiftsFrom", orgID, mock.Anything).Return([]database.Shift{}, nil).Once()
+		routing.SMSService.On("SendSMS", phone, "Test User submitted a calloff request: Sick").Return(nil).Once()
+		env.NotificationStore.On("GetDigestRecipients", []string{"admin@test.com"}).Return(map[string]uuid.UUID{}, nil).Once()
+		env.EmailService.On("SendRequestNotifyEmail", []string{"admin@test.com"}, user.FullName, "calloff", "Sick").Return(nil).Once()
+
+		w := send(env)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		routing.SMSService.AssertExpectations(t)
+		env.EmailService.AssertExpectations(t)
+	})
+
+	t.Run("FailedSMSFallsBackToEmail", func(t *testing.T) {
+		env := setupEmployeeEnv()
+		routing := setupNotificationRoutingEnv()
+		env.Handler.Router = routing.Router
+		expectRequest(env)
+		routing.RoutingStore.On("GetRoutingRules", orgID).Return(rules, nil).Once()
+		routing.UserStore.On("GetUsersByOrganization", orgID).Return([]*database.User{manager, admin}, nil).Once()
+		routing.ScheduleStore.On("GetShiftsFrom", orgID, mock.Anything).Return([]database.Shift{}, nil).Once()
+		routing.SMSService.On("SendSMS", phone, mock.Anything).Return(errors.New("gateway down")).Once()
+		emails := []string{"mgr@test.com", "admin@test.com"}
+		env.NotificationStore.On("GetDigestRecipients", emails).Return(map[string]uuid.UUID{}, nil).Once()
+		env.EmailService.On("SendRequestNotifyEmail", emails, user.FullName, "calloff", "Sick").Return(nil).Once()
+
+		w := send(env)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		env.EmailService.AssertExpectations(t)
+	})
+
+	t.Run("RulesUnavailableEmailsManagersAndAdmins", func(t *testing.T) {
+		env := setupEmployeeEnv()
+		routing := setupNotificationRoutingEnv()
+		env.Handler.Router = routing.Router
+		expectRequest(env)
+		routing.RoutingStore.On("GetRoutingRules", orgID).Return(nil, errors.New("db error")).Once()
+		env.OrgStore.On("GetManagerEmailsByOrgID", orgID).Return([]string{"mgr@test.com"}, nil).Once()
+		env.OrgStore.On("GetAdminEmailsByOrgID", orgID).Return([]string{"admin@test.com"}, nil).Once()
+		emails := []string{"mgr@test.com", "admin@test.com"}
+		env.NotificationStore.On("GetDigestRecipients", emails).Return(map[string]uuid.UUID{}, nil).Once()
+		env.EmailService.On("SendRequestNotifyEmail", emails, user.FullName, "calloff", "Sick").Return(nil).Once()
+
+		w := send(env)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		env.EmailService.AssertExpectations(t)
+		routing.SMSService.AssertNotCalled(t, "SendSMS", mock.Anything, mock.Anything)
+	})
+}
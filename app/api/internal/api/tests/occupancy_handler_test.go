@@ -0,0 +1,195 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type OccupancyTestEnv struct {
+	OccupancyStore     *MockOccupancyStore
+	ExternalEventStore *MockExternalEventStore
+	Handler            *api.OccupancyHandler
+}
+
+func setupOccupancyEnv() *OccupancyTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	occupancyStore := new(MockOccupancyStore)
+	externalEventStore := new(MockExternalEventStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &OccupancyTestEnv{
+		OccupancyStore:     occupancyStore,
+		ExternalEventStore: externalEventStore,
+		Handler:            api.NewOccupancyHandler(occupancyStore, externalEventStore, logger),
+	}
+}
+
+func (env *OccupancyTestEnv) ResetMocks() {
+	env.OccupancyStore.ExpectedCalls = nil
+	env.OccupancyStore.Calls = nil
+	env.ExternalEventStore.ExpectedCalls = nil
+	env.ExternalEventStore.Calls = nil
+}
+
+type occupancyResponse struct {
+	Data api.OccupancySnapshot `json:"data"`
+}
+
+type occupancyTimelineResponse struct {
+	Data struct {
+		Date            string                  `json:"date"`
+		IntervalMinutes int                     `json:"interval_minutes"`
+		PeakTime        time.Time               `json:"peak_time"`
+		PeakGuests      int                     `json:"peak_guests"`
+		Timeline        []api.OccupancySnapshot `json:"timeline"`
+		Events          []api.EventMarker       `json:"events"`
+	} `json:"data"`
+}
+
+func TestGetCurrentOccupancyHandler(t *testing.T) {
+	env := setupOccupancyEnv()
+	orgID := uuid.New()
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	tables := []database.DiningTable{{TableNo: 1, Capacity: 4}, {TableNo: 2, Capacity: 2}, {TableNo: 3, Capacity: 6}}
+
+	get := func() *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/:org/occupancy/current", authMiddleware(employee), env.Handler.GetCurrentOccupancyHandler)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/occupancy/current", nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		now := time.Now()
+		orderID := uuid.New()
+		seatings := []database.TableSeating{
+			{OrderID: orderID, TableNo: 1, NumberOfPeople: 3, StartTime: now.Add(-30 * time.Minute), EndTime: now.Add(30 * time.Minute)},
+			{OrderID: uuid.New(), TableNo: 3, NumberOfPeople: 6, StartTime: now.Add(-time.Hour), EndTime: now.Add(15 * time.Minute)},
+		}
+		env.OccupancyStore.On("GetTables", orgID).Return(tables, nil)
+		env.OccupancyStore.On("GetSeatingsBetween", orgID, mock.Anything, mock.Anything).Return(seatings, nil)
+
+		w := get()
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		var resp occupancyResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, 9, resp.Data.SeatedGuests)
+		assert.Equal(t, 12, resp.Data.TotalCapacity)
+		assert.Equal(t, 2, resp.Data.OccupiedTables)
+		assert.Equal(t, 75.0, resp.Data.UtilizationPercent)
+		assert.Equal(t, []uuid.UUID{orderID}, resp.Data.Tables[0].OrderIDs)
+		assert.False(t, resp.Data.Tables[1].Occupied)
+		assert.Nil(t, resp.Data.Tables[1].SeatedSince)
+	})
+
+	t.Run("NoTables", func(t *testing.T) {
+		env.ResetMocks()
+		env.OccupancyStore.On("GetTables", orgID).Return([]database.DiningTable{}, nil)
+		env.OccupancyStore.On("GetSeatingsBetween", orgID, mock.Anything, mock.Anything).Return([]database.TableSeating{}, nil)
+
+		w := get()
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp occupancyResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, 0.0, resp.Data.UtilizationPercent)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.OccupancyStore.On("GetTables", orgID).Return(nil, errors.New("db error"))
+
+		w := get()
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestGetOccupancyTimelineHandler(t *testing.T) {
+	env := setupOccupancyEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	tables := []database.DiningTable{{TableNo: 1, Capacity: 4}, {TableNo: 2, Capacity: 4}}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/:org/occupancy/timeline", authMiddleware(manager), env.Handler.GetOccupancyTimelineHandler)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/occupancy/timeline"+query, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		day := time.Date(2025, 3, 14, 0, 0, 0, 0, time.Local)
+		seatings := []database.TableSeating{
+			{OrderID: uuid.New(), TableNo: 1, NumberOfPeople: 2, StartTime: day.Add(12 * time.Hour), EndTime: day.Add(13 * time.Hour)},
+			{OrderID: uuid.New(), TableNo: 2, NumberOfPeople: 4, StartTime: day.Add(12*time.Hour + 30*time.Minute), EndTime: day.Add(14 * time.Hour)},
+		}
+		env.OccupancyStore.On("GetTables", orgID).Return(tables, nil)
+		env.OccupancyStore.On("GetSeatingsBetween", orgID, day, day.AddDate(0, 0, 1)).Return(seatings, nil)
+		match := database.ExternalEvent{ID: uuid.New(), Name: "Derby", Category: database.ExternalEventSports, StartTime: day.Add(19 * time.Hour), EndTime: day.Add(21 * time.Hour)}
+		env.ExternalEventStore.On("GetEventsBetween", orgID, day, day.AddDate(0, 0, 1)).Return([]database.ExternalEvent{match}, nil)
+
+		w := get("?date=2025-03-14&interval=30")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp occupancyTimelineResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "2025-03-14", resp.Data.Date)
+		assert.Len(t, resp.Data.Timeline, 48)
+		assert.Equal(t, 2, resp.Data.Timeline[24].SeatedGuests) // 12:00
+		assert.Equal(t, 6, resp.Data.Timeline[25].SeatedGuests) // 12:30
+		assert.Equal(t, 4, resp.Data.Timeline[26].SeatedGuests) // 13:00, table 1 has left
+		assert.Equal(t, 0, resp.Data.Timeline[28].SeatedGuests) // 14:00
+		assert.Equal(t, 6, resp.Data.PeakGuests)
+		assert.True(t, resp.Data.PeakTime.Equal(day.Add(12*time.Hour+30*time.Minute)))
+		if assert.Len(t, resp.Data.Events, 1) {
+			assert.Equal(t, "Derby", resp.Data.Events[0].Name)
+		}
+		env.OccupancyStore.AssertExpectations(t)
+		env.ExternalEventStore.AssertExpectations(t)
+	})
+
+	t.Run("InvalidDate", func(t *testing.T) {
+		env.ResetMocks()
+		w := get("?date=14-03-2025")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("InvalidInterval", func(t *testing.T) {
+		env.ResetMocks()
+		w := get("?interval=1")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.OccupancyStore.On("GetTables", orgID).Return(tables, nil)
+		env.OccupancyStore.On("GetSeatingsBetween", orgID, mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+
+		w := get("")
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
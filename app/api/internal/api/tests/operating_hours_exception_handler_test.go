@@ -0,0 +1,229 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type ClosureTestEnv struct {
+	ExceptionStore *MockOperatingHoursExceptionStore
+	ScheduleStore  *MockScheduleStore
+	EmailService   *MockEmailService
+	Handler        *api.OperatingHoursExceptionHandler
+}
+
+func setupClosureEnv() *ClosureTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	exceptionStore := new(MockOperatingHoursExceptionStore)
+	scheduleStore := new(MockScheduleStore)
+	emailService := new(MockEmailService)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &ClosureTestEnv{
+		ExceptionStore: exceptionStore,
+		ScheduleStore:  scheduleStore,
+		EmailService:   emailService,
+		Handler:        api.NewOperatingHoursExceptionHandler(exceptionStore, scheduleStore, emailService, logger),
+	}
+}
+
+func (env *ClosureTestEnv) ResetMocks() {
+	env.ExceptionStore.ExpectedCalls = nil
+	env.ExceptionStore.Calls = nil
+	env.ScheduleStore.ExpectedCalls = nil
+	env.ScheduleStore.Calls = nil
+	env.EmailService.ExpectedCalls = nil
+	env.EmailService.Calls = nil
+}
+
+func TestGetOperatingHoursExceptionsHandler(t *testing.T) {
+	env := setupClosureEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/rules/exceptions"
+	path := "/" + orgID.String() + "/rules/exceptions"
+	handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetOperatingHoursExceptionsHandler}
+
+	t.Run("DefaultWindow", func(t *testing.T) {
+		env.ResetMocks()
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		reason := "Christmas"
+		closed := []database.OperatingHoursException{{Date: today.AddDate(0, 0, 3), Reason: &reason}}
+		env.ExceptionStore.On("GetExceptionsBetween", orgID, today, today.AddDate(0, 0, 365)).Return(closed, nil).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Christmas")
+	})
+
+	t.Run("Window", func(t *testing.T) {
+		env.ResetMocks()
+		from := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+		env.ExceptionStore.On("GetExceptionsBetween", orgID, from, to).Return([]database.OperatingHoursException{}, nil).Once()
+
+		w := jobRequest("GET", route, path+"?from=2026-12-01&to=2026-12-31", handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.ExceptionStore.AssertExpectations(t)
+	})
+
+	t.Run("InvalidWindow", func(t *testing.T) {
+		env.ResetMocks()
+		for _, query := range []string{"?from=december", "?to=2026/12/31", "?from=2026-12-31&to=2026-12-01"} {
+			w := jobRequest("GET", route, path+query, handlers, nil)
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetOperatingHoursExceptionsHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestCloseDayHandler(t *testing.T) {
+	env := setupClosureEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	day := time.Now().AddDate(0, 0, 2)
+	date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	route := "/:org/rules/exceptions/:date"
+	path := "/" + orgID.String() + "/rules/exceptions/" + date.Format(time.DateOnly)
+	handlers := []gin.HandlerFunc{authMiddleware(admin), env.Handler.CloseDayHandler}
+
+	summary := &database.ScheduleClearSummary{
+		From:   date,
+		To:     date,
+		Shifts: 3,
+		Employees: []database.ScheduleClearedEmployee{
+			{EmployeeID: uuid.New(), EmployeeName: "Sam Cook", Email: "sam@example.com", Shifts: 2},
+			{EmployeeID: uuid.New(), EmployeeName: "Alex Server", Email: "alex@example.com", Shifts: 1},
+		},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.ExceptionStore.On("UpsertException", orgID, mock.MatchedBy(func(e *database.OperatingHoursException) bool {
+			return e.Date.Equal(date) && *e.Reason == "Public holiday" && *e.CreatedBy == admin.ID
+		})).Return(nil).Once()
+		env.ScheduleStore.On("ClearSchedule", orgID, date, date, admin.ID).Return(summary, nil).Once()
+		env.EmailService.On("SendClosureEmail", "sam@example.com", "Sam Cook", date.Format(time.DateOnly), "Public holiday").Return(nil).Once()
+		// A failed notification does not undo the closure
+		env.EmailService.On("SendClosureEmail", "alex@example.com", "Alex Server", date.Format(time.DateOnly), "Public holiday").Return(errors.New("smtp down")).Once()
+
+		w := jobRequest("PUT", route, path, handlers, map[string]any{"reason": "  Public holiday "})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data struct {
+				Cancelled         database.ScheduleClearSummary `json:"cancelled"`
+				NotifiedEmployees int                           `json:"notified_employees"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 3, response.Data.Cancelled.Shifts)
+		assert.Equal(t, 1, response.Data.NotifiedEmployees)
+		env.ExceptionStore.AssertExpectations(t)
+		env.EmailService.AssertExpectations(t)
+	})
+
+	t.Run("NoBody", func(t *testing.T) {
+		env.ResetMocks()
+		env.ExceptionStore.On("UpsertException", orgID, mock.MatchedBy(func(e *database.OperatingHoursException) bool {
+			return e.Reason == nil
+		})).Return(nil).Once()
+		env.ScheduleStore.On("ClearSchedule", orgID, date, date, admin.ID).Return(&database.ScheduleClearSummary{Employees: []database.ScheduleClearedEmployee{}}, nil).Once()
+
+		w := jobRequest("PUT", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.EmailService.AssertNotCalled(t, "SendClosureEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("InvalidDate", func(t *testing.T) {
+		env.ResetMocks()
+		for _, invalid := range []string{"christmas", "2020-12-25"} {
+			w := jobRequest("PUT", route, "/"+orgID.String()+"/rules/exceptions/"+invalid, handlers, nil)
+			assert.Equal(t, http.StatusBadRequest, w.Code, invalid)
+		}
+		env.ExceptionStore.AssertNotCalled(t, "UpsertException", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ClearError", func(t *testing.T) {
+		env.ResetMocks()
+		env.ExceptionStore.On("UpsertException", orgID, mock.Anything).Return(nil).Once()
+		env.ScheduleStore.On("ClearSchedule", orgID, date, date, admin.ID).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("PUT", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("ManagerForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.CloseDayHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		env.ScheduleStore.AssertNotCalled(t, "ClearSchedule", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestReopenDayHandler(t *testing.T) {
+	env := setupClosureEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	date := time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC)
+	route := "/:org/rules/exceptions/:date"
+	path := "/" + orgID.String() + "/rules/exceptions/2026-12-25"
+	handlers := []gin.HandlerFunc{authMiddleware(admin), env.Handler.ReopenDayHandler}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.ExceptionStore.On("DeleteException", orgID, date).Return(nil).Once()
+
+		w := jobRequest("DELETE", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.ExceptionStore.AssertExpectations(t)
+	})
+
+	t.Run("NotClosed", func(t *testing.T) {
+		env.ResetMocks()
+		env.ExceptionStore.On("DeleteException", orgID, date).Return(sql.ErrNoRows).Once()
+
+		w := jobRequest("DELETE", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("InvalidDate", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("DELETE", route, "/"+orgID.String()+"/rules/exceptions/christmas", handlers, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
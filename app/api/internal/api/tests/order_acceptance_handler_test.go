@@ -0,0 +1,267 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type OrderAcceptanceTestEnv struct {
+	Store    *MockOrderAcceptanceStore
+	OrgStore *MockOrgStore
+	Email    *MockEmailService
+	Handler  *api.OrderAcceptanceHandler
+	Service  *service.OrderAcceptanceService
+}
+
+func setupOrderAcceptanceEnv() *OrderAcceptanceTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	store := new(MockOrderAcceptanceStore)
+	orgStore := new(MockOrgStore)
+	email := new(MockEmailService)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &OrderAcceptanceTestEnv{
+		Store:    store,
+		OrgStore: orgStore,
+		Email:    email,
+		Handler:  api.NewOrderAcceptanceHandler(store, logger),
+		Service:  &service.OrderAcceptanceService{Store: store, OrgStore: orgStore, EmailService: email, Logger: logger, Interval: time.Minute},
+	}
+}
+
+func (env *OrderAcceptanceTestEnv) ResetMocks() {
+	env.Store.ExpectedCalls = nil
+	env.Store.Calls = nil
+	env.OrgStore.ExpectedCalls = nil
+	env.OrgStore.Calls = nil
+	env.Email.ExpectedCalls = nil
+	env.Email.Calls = nil
+}
+
+func acceptanceSettings(orgID uuid.UUID, accepting bool, autoClosedAt *time.Time) database.OrderAcceptanceSettings {
+	return database.OrderAcceptanceSettings{
+		OrganizationID:        orgID,
+		AcceptingOrders:       accepting,
+		OrdersPerStaffHour:    10,
+		AutoCloseLoadPercent:  150,
+		AutoReopenLoadPercent: 100,
+		OrdersAutoClosedAt:    autoClosedAt,
+	}
+}
+
+func TestEvaluateOrderLoad(t *testing.T) {
+	orgID := uuid.New()
+	closedAt := time.Date(2026, 10, 15, 12, 0, 0, 0, time.Local)
+
+	t.Run("CloseOverCapacity", func(t *testing.T) {
+		// 40 orders in the last hour beat the forecast of 25, against 2 staff handling 10 each
+		change := service.EvaluateOrderLoad(acceptanceSettings(orgID, true, nil), database.OrderLoad{ForecastOrders: 25, RecentOrders: 40, StaffOnShift: 2})
+
+		assert.NotNil(t, change)
+		assert.False(t, change.AcceptingOrders)
+		assert.Equal(t, 20, change.Capacity)
+		assert.Equal(t, 200.0, *change.LoadPercent)
+	})
+
+	t.Run("StayOpenWithinCapacity", func(t *testing.T) {
+		change := service.EvaluateOrderLoad(acceptanceSettings(orgID, true, nil), database.OrderLoad{ForecastOrders: 30, RecentOrders: 20, StaffOnShift: 2})
+
+		assert.Nil(t, change)
+	})
+
+	t.Run("CloseWithNobodyOnShift", func(t *testing.T) {
+		change := service.EvaluateOrderLoad(acceptanceSettings(orgID, true, nil), database.OrderLoad{ForecastOrders: 5})
+
+		assert.NotNil(t, change)
+		assert.False(t, change.AcceptingOrders)
+		assert.Nil(t, change.LoadPercent)
+	})
+
+	t.Run("StayOpenWhenQuiet", func(t *testing.T) {
+		change := service.EvaluateOrderLoad(acceptanceSettings(orgID, true, nil), database.OrderLoad{})
+
+		assert.Nil(t, change)
+	})
+
+	t.Run("ReopenOnceLoadDrops", func(t *testing.T) {
+		change := service.EvaluateOrderLoad(acceptanceSettings(orgID, false, &closedAt), database.OrderLoad{ForecastOrders: 18, RecentOrders: 12, StaffOnShift: 2})
+
+		assert.NotNil(t, change)
+		assert.True(t, change.AcceptingOrders)
+		assert.Equal(t, 90.0, *change.LoadPercent)
+	})
+
+	t.Run("StayClosedBetweenThresholds", func(t *testing.T) {
+		change := service.EvaluateOrderLoad(acceptanceSettings(orgID, false, &closedAt), database.OrderLoad{ForecastOrders: 25, StaffOnShift: 2})
+
+		assert.Nil(t, change)
+	})
+
+	t.Run("KeepManualClosure", func(t *testing.T) {
+		change := service.EvaluateOrderLoad(acceptanceSettings(orgID, false, nil), database.OrderLoad{StaffOnShift: 3})
+
+		assert.Nil(t, change)
+	})
+}
+
+func TestControlOrders(t *testing.T) {
+	env := setupOrderAcceptanceEnv()
+	orgID := uuid.New()
+	now := time.Date(2026, 10, 15, 19, 30, 0, 0, time.Local)
+	emails := []string{"manager@example.com", "admin@example.com"}
+
+	t.Run("CloseAndNotify", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("GetAutoCloseOrganizations").Return([]database.OrderAcceptanceSettings{acceptanceSettings(orgID, true, nil)}, nil).Once()
+		env.Store.On("GetOrderLoad", orgID, now).Return(&database.OrderLoad{ForecastOrders: 60, RecentOrders: 45, StaffOnShift: 3}, nil).Once()
+		env.Store.On("SetAcceptingOrders", mock.MatchedBy(func(change *database.OrderAcceptanceChange) bool {
+			return change.OrganizationID == orgID && !change.AcceptingOrders && change.Capacity == 30 && *change.LoadPercent == 200 && change.ChangedAt.Equal(now)
+		})).Return(nil).Once()
+		env.OrgStore.On("GetManagerEmailsByOrgID", orgID).Return([]string{"manager@example.com"}, nil).Once()
+		env.OrgStore.On("GetAdminEmailsByOrgID", orgID).Return([]string{"admin@example.com"}, nil).Once()
+		env.Email.On("SendOrderAcceptanceEmail", emails, false, mock.MatchedBy(func(summary string) bool {
+			return summary == "Orders were paused: 60 orders expected this hour (60 forecast, 45 in the last hour) against a capacity of 30 with 3 staff on shift, a load of 200%."
+		})).Return(nil).Once()
+
+		changed, err := env.Service.ControlOrders(now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, changed)
+		env.Store.AssertExpectations(t)
+		env.OrgStore.AssertExpectations(t)
+		env.Email.AssertExpectations(t)
+	})
+
+	t.Run("ChangedByManagerMeanwhile", func(t *testing.T) {
+		env.ResetMocks()
+		closedAt := now.Add(-time.Hour)
+		env.Store.On("GetAutoCloseOrganizations").Return([]database.OrderAcceptanceSettings{acceptanceSettings(orgID, false, &closedAt)}, nil).Once()
+		env.Store.On("GetOrderLoad", orgID, now).Return(&database.OrderLoad{ForecastOrders: 10, StaffOnShift: 3}, nil).Once()
+		env.Store.On("SetAcceptingOrders", mock.Anything).Return(sql.ErrNoRows).Once()
+
+		changed, err := env.Service.ControlOrders(now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, changed)
+		env.Email.AssertNotCalled(t, "SendOrderAcceptanceEmail", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("NothingToChange", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("GetAutoCloseOrganizations").Return([]database.OrderAcceptanceSettings{acceptanceSettings(orgID, true, nil)}, nil).Once()
+		env.Store.On("GetOrderLoad", orgID, now).Return(&database.OrderLoad{ForecastOrders: 20, RecentOrders: 18, StaffOnShift: 3}, nil).Once()
+
+		changed, err := env.Service.ControlOrders(now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, changed)
+		env.Store.AssertNotCalled(t, "SetAcceptingOrders", mock.Anything)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("GetAutoCloseOrganizations").Return(nil, errors.New("db error")).Once()
+
+		_, err := env.Service.ControlOrders(now)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestGetAutoCloseHistoryHandler(t *testing.T) {
+	env := setupOrderAcceptanceEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/rules/auto-close/history"
+	path := "/" + orgID.String() + "/rules/auto-close/history"
+	handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetAutoCloseHistoryHandler}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+		to := time.Date(2026, 10, 8, 0, 0, 0, 0, time.Local)
+		load := 180.0
+		changes := []database.OrderAcceptanceChange{
+			{ID: uuid.New(), OrganizationID: orgID, AcceptingOrders: true, Capacity: 30},
+			{ID: uuid.New(), OrganizationID: orgID, AcceptingOrders: false, Capacity: 30, LoadPercent: &load},
+			{ID: uuid.New(), OrganizationID: orgID, AcceptingOrders: false, StaffOnShift: 0},
+		}
+		env.Store.On("ListChanges", orgID, from, to).Return(changes, nil).Once()
+
+		w := jobRequest("GET", route, path+"?from=2026-10-01&to=2026-10-07", handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data struct {
+				To         string                           `json:"to"`
+				Closures   int                              `json:"closures"`
+				Reopenings int                              `json:"reopenings"`
+				Changes    []database.OrderAcceptanceChange `json:"changes"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "2026-10-07", resp.Data.To)
+		assert.Equal(t, 2, resp.Data.Closures)
+		assert.Equal(t, 1, resp.Data.Reopenings)
+		assert.Equal(t, 180.0, *resp.Data.Changes[1].LoadPercent)
+		assert.Nil(t, resp.Data.Changes[2].LoadPercent)
+		env.Store.AssertExpectations(t)
+	})
+
+	t.Run("DefaultPeriod", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("ListChanges", orgID, mock.Anything, mock.Anything).Return([]database.OrderAcceptanceChange{}, nil).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		from := env.Store.Calls[0].Arguments.Get(1).(time.Time)
+		to := env.Store.Calls[0].Arguments.Get(2).(time.Time)
+		assert.Equal(t, 7, int(to.Sub(from).Round(time.Hour).Hours()/24))
+		assert.True(t, to.After(time.Now()))
+	})
+
+	t.Run("InvalidDates", func(t *testing.T) {
+		env.ResetMocks()
+
+		badTo := jobRequest("GET", route, path+"?to=tomorrow", handlers, nil)
+		reversed := jobRequest("GET", route, path+"?from=2026-10-10&to=2026-10-01", handlers, nil)
+
+		assert.Equal(t, http.StatusBadRequest, badTo.Code)
+		assert.Equal(t, http.StatusBadRequest, reversed.Code)
+		env.Store.AssertNotCalled(t, "ListChanges", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetAutoCloseHistoryHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("ListChanges", orgID, mock.Anything, mock.Anything).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,369 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"database/sql"
+	"encoding/json"
+	"encoding/pem"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testSheetURL = "https://docs.google.com/spreadsheets/d/sheet123/edit#gid=42"
+
+type OrderSheetTestEnv struct {
+	SheetStore  *MockOrderSheetStore
+	OrderStore  *MockOrderStore
+	RuleStore   *MockIngestionRuleStore
+	StatusStore *MockStatusStore
+	Handler     *api.OrderSheetHandler
+}
+
+func setupOrderSheetEnv() *OrderSheetTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	sheetStore := new(MockOrderSheetStore)
+	orderStore := new(MockOrderStore)
+	ruleStore := new(MockIngestionRuleStore)
+	statusStore := new(MockStatusStore)
+	statusStore.AllowEvents()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	handler := api.NewOrderSheetHandler(sheetStore, orderStore, new(MockCampaignStore), ruleStore, statusStore, logger)
+	handler.Syncer = service.NewOrderSheetSyncer(sheetStore, handler.ApplySheetRows, logger)
+
+	return &OrderSheetTestEnv{
+		SheetStore:  sheetStore,
+		OrderStore:  orderStore,
+		RuleStore:   ruleStore,
+		StatusStore: statusStore,
+		Handler:     handler,
+	}
+}
+
+// serviceAccountKey returns the JSON key of a service account with the token_uri given
+func serviceAccountKey(t *testing.T, tokenURI string) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	credentials, _ := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "orders@clockwise-test.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    tokenURI,
+	})
+	return string(credentials)
+}
+
+func TestCreateOrderSheetHandler(t *testing.T) {
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	path := "/" + orgID.String() + "/integrations/order-sheets"
+
+	create := func(env *OrderSheetTestEnv, user *database.User, body any) *httptest.ResponseRecorder {
+		return jobRequest("POST", "/:org/integrations/order-sheets", path, []gin.HandlerFunc{authMiddleware(user), env.Handler.CreateOrderSheetHandler}, body)
+	}
+
+	t.Run("SuccessWithServiceAccount", func(t *testing.T) {
+		env := setupOrderSheetEnv()
+		env.SheetStore.On("CreateSync", orgID, mock.MatchedBy(func(sheet *database.OrderSheetSync) bool {
+			return sheet.Name == "Till log" && sheet.Enabled && sheet.Credentials != nil &&
+				sheet.ColumnMapping["order_id"] == "Order #" && len(sheet.ColumnMapping) == 1
+		})).Return(nil).Once()
+
+		w := create(env, admin, gin.H{
+			"name":           " Till log ",
+			"sheet_url":      testSheetURL,
+			"credentials":    serviceAccountKey(t, "https://oauth2.googleapis.com/token"),
+			"column_mapping": gin.H{"order_id": "Order #", "rating": " "},
+		})
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"service_account":"orders@clockwise-test.iam.gserviceaccount.com"`)
+		assert.NotContains(t, w.Body.String(), "PRIVATE KEY")
+		env.SheetStore.AssertExpectations(t)
+	})
+
+	t.Run("InvalidInput", func(t *testing.T) {
+		env := setupOrderSheetEnv()
+		for name, body := range map[string]gin.H{
+			"NotASheet":      {"name": "Log", "sheet_url": "https://example.com/spreadsheets/d/abc"},
+			"BadCredentials": {"name": "Log", "sheet_url": testSheetURL, "credentials": `{"type":"authorized_user"}`},
+			"UnknownColumn":  {"name": "Log", "sheet_url": testSheetURL, "column_mapping": gin.H{"tip": "Tip"}},
+		} {
+			w := create(env, admin, body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, name)
+		}
+		env.SheetStore.AssertNotCalled(t, "CreateSync", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ManagerForbidden", func(t *testing.T) {
+		env := setupOrderSheetEnv()
+		w := create(env, manager, gin.H{"name": "Log", "sheet_url": testSheetURL})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestUpdateOrderSheetHandler(t *testing.T) {
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	route := "/:org/integrations/order-sheets/:id"
+
+	t.Run("KeepsCredentialsWhenLeftOut", func(t *testing.T) {
+		env := setupOrderSheetEnv()
+		credentials := serviceAccountKey(t, "https://oauth2.googleapis.com/token")
+		sheet := &database.OrderSheetSync{ID: uuid.New(), Name: "Log", SheetURL: testSheetURL, Credentials: &credentials, Enabled: true}
+		env.SheetStore.On("GetSync", orgID, sheet.ID).Return(sheet, nil).Once()
+		env.SheetStore.On("UpdateSync", orgID, mock.MatchedBy(func(s *database.OrderSheetSync) bool {
+			return s.Credentials == &credentials && !s.Enabled && s.ColumnMapping["create_time"] == "Date"
+		})).Return(nil).Once()
+
+		w := jobRequest("PUT", route, "/"+orgID.String()+"/integrations/order-sheets/"+sheet.ID.String(),
+			[]gin.HandlerFunc{authMiddleware(admin), env.Handler.UpdateOrderSheetHandler},
+			gin.H{"name": "Log", "sheet_url": testSheetURL, "enabled": false, "column_mapping": gin.H{"create_time": "Date"}})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.SheetStore.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env := setupOrderSheetEnv()
+		sheetID := uuid.New()
+		env.SheetStore.On("GetSync", orgID, sheetID).Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("PUT", route, "/"+orgID.String()+"/integrations/order-sheets/"+sheetID.String(),
+			[]gin.HandlerFunc{authMiddleware(admin), env.Handler.UpdateOrderSheetHandler},
+			gin.H{"name": "Log", "sheet_url": testSheetURL})
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestApplySheetRows(t *testing.T) {
+	orgID := uuid.New()
+	firstID, secondID := uuid.New(), uuid.New()
+	sheet := database.OrderSheetSync{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		ColumnMapping:  map[string]string{"order_id": "Order #", "create_time": "Date", "total_amount": "Total"},
+	}
+	headers := []string{"Order #", "USER_ID", "Date", "order_type", "order_status", "Total", "discount_amount", "Notes"}
+	row := func(orderID uuid.UUID, total string) []string {
+		return []string{orderID.String(), uuid.New().String(), "2025-06-01 12:00:00", "takeaway", "completed", total, "0", "no onions"}
+	}
+
+	t.Run("MapsColumnsAndSkipsBlankRows", func(t *testing.T) {
+		env := setupOrderSheetEnv()
+		env.RuleStore.On("GetRulesForDataset", orgID, "orders").Return([]database.IngestionRule{}, nil).Once()
+		env.OrderStore.On("StoreOrdersBulk", orgID, mock.MatchedBy(func(orders []database.Order) bool {
+			return len(orders) == 2 && orders[0].OrderID == firstID && *orders[1].TotalAmount == 12.5
+		}), database.OrderConflictSkip).Return([]uuid.UUID{firstID}, nil, nil).Once()
+
+		run := env.Handler.ApplySheetRows(sheet, headers, [][]string{row(firstID, "20"), {"", " "}, row(secondID, "12.5"), row(uuid.New(), "lots")}, 10)
+
+		assert.Nil(t, run.Error)
+		assert.Equal(t, 4, run.TotalRows)
+		assert.Equal(t, 1, run.SuccessCount)
+		assert.Equal(t, 1, run.DuplicateCount)
+		assert.Equal(t, 1, run.ErrorCount)
+		env.OrderStore.AssertExpectations(t)
+		if ingestions := env.StatusStore.RecordedEvents(database.StatusEventIngestion); assert.Len(t, ingestions, 1) {
+			assert.Equal(t, "order_sheet", ingestions[0].Source)
+		}
+	})
+
+	t.Run("ViolationsNumberedBySheetRow", func(t *testing.T) {
+		env := setupOrderSheetEnv()
+		rule := database.IngestionRule{ID: uuid.New(), Dataset: "orders", Field: "total_amount", Operator: "max", Value: "100"}
+		env.RuleStore.On("GetRulesForDataset", orgID, "orders").Return([]database.IngestionRule{rule}, nil).Once()
+
+		run := env.Handler.ApplySheetRows(sheet, headers, [][]string{row(firstID, "500")}, 10)
+
+		assert.Nil(t, run.Error)
+		assert.Equal(t, 1, run.RejectedCount)
+		env.OrderStore.AssertNotCalled(t, "StoreOrdersBulk", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("MissingColumn", func(t *testing.T) {
+		env := setupOrderSheetEnv()
+
+		run := env.Handler.ApplySheetRows(sheet, headers[1:], [][]string{}, 0)
+
+		if assert.NotNil(t, run.Error) {
+			assert.Equal(t, "Missing required column: Order # (order_id)", *run.Error)
+		}
+	})
+}
+
+func TestSyncOrderSheetHandler(t *testing.T) {
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	header := "order_id,user_id,create_time,order_type,order_status,total_amount,discount_amount"
+	imported, added := uuid.New(), uuid.New()
+	line := func(orderID uuid.UUID) string {
+		return orderID.String() + "," + uuid.New().String() + ",2025-06-01 12:00:00,takeaway,completed,20,0"
+	}
+
+	sync := func(env *OrderSheetTestEnv, sheet *database.OrderSheetSync) *httptest.ResponseRecorder {
+		env.SheetStore.On("GetSync", orgID, sheet.ID).Return(sheet, nil).Once()
+		return jobRequest("POST", "/:org/integrations/order-sheets/:id/sync", "/"+orgID.String()+"/integrations/order-sheets/"+sheet.ID.String()+"/sync",
+			[]gin.HandlerFunc{authMiddleware(manager), env.Handler.SyncOrderSheetHandler}, nil)
+	}
+
+	t.Run("SharedByLinkImportsNewRows", func(t *testing.T) {
+		env := setupOrderSheetEnv()
+		google := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/spreadsheets/d/sheet123/export", r.URL.Path)
+			assert.Equal(t, "csv", r.URL.Query().Get("format"))
+			assert.Equal(t, "42", r.URL.Query().Get("gid"))
+			w.Header().Set("Content-Type", "text/csv")
+			w.Write([]byte(header + "\n" + line(imported) + "\n" + line(added) + "\n"))
+		}))
+		defer google.Close()
+		env.Handler.Syncer.Client = google.Client()
+		env.Handler.Syncer.ExportURL = google.URL
+
+		sheet := &database.OrderSheetSync{ID: uuid.New(), OrganizationID: orgID, SheetURL: testSheetURL, RowsImported: 1, ColumnMapping: map[string]string{}}
+		env.RuleStore.On("GetRulesForDataset", orgID, "orders").Return([]database.IngestionRule{}, nil).Once()
+		env.OrderStore.On("StoreOrdersBulk", orgID, mock.MatchedBy(func(orders []database.Order) bool {
+			return len(orders) == 1 && orders[0].OrderID == added
+		}), database.OrderConflictSkip).Return([]uuid.UUID{added}, nil, nil).Once()
+		env.SheetStore.On("MarkSynced", sheet.ID, 2, mock.Anything).Return(nil).Once()
+		env.SheetStore.On("RecordRun", mock.MatchedBy(func(run *database.OrderSheetRun) bool {
+			return run.SyncID == sheet.ID && run.Status == database.OrderSheetRunSuccess && run.TotalRows == 1 && run.SuccessCount == 1
+		})).Return(nil).Once()
+
+		w := sync(env, sheet)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"success_count":1`)
+		env.SheetStore.AssertExpectations(t)
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("ServiceAccountReadsTheTab", func(t *testing.T) {
+		env := setupOrderSheetEnv()
+		google := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/token":
+				assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.FormValue("grant_type"))
+				assert.NotEmpty(t, r.FormValue("assertion"))
+				w.Write([]byte(`{"access_token":"ya29.test","expires_in":3600}`))
+			case "/spreadsheets/sheet123":
+				assert.Equal(t, "Bearer ya29.test", r.Header.Get("Authorization"))
+				w.Write([]byte(`{"sheets":[{"properties":{"sheetId":0,"title":"Summary"}},{"properties":{"sheetId":42,"title":"Till log"}}]}`))
+			case "/spreadsheets/sheet123/values/Till log":
+				assert.Equal(t, "Bearer ya29.test", r.Header.Get("Authorization"))
+				values, _ := json.Marshal(gin.H{"values": [][]string{
+					{"order_id", "user_id", "create_time", "order_type", "order_status", "total_amount", "discount_amount"},
+					{added.String(), uuid.New().String(), "2025-06-01T12:00:00Z", "takeaway", "completed", "20", "0"},
+				}})
+				w.Write(values)
+			default:
+				t.Errorf("unexpected request to %s", r.URL.Path)
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer google.Close()
+		env.Handler.Syncer.Client = google.Client()
+		env.Handler.Syncer.SheetsURL = google.URL
+		env.Handler.Syncer.TokenURL = google.URL + "/token"
+
+		// the token_uri of the key is ignored, the assertion only goes to the token URL of the syncer
+		credentials := serviceAccountKey(t, "https://attacker.example.com/token")
+		sheet := &database.OrderSheetSync{ID: uuid.New(), OrganizationID: orgID, SheetURL: testSheetURL, Credentials: &credentials}
+		env.RuleStore.On("GetRulesForDataset", orgID, "orders").Return([]database.IngestionRule{}, nil).Once()
+		env.OrderStore.On("StoreOrdersBulk", orgID, mock.Anything, database.OrderConflictSkip).Return([]uuid.UUID{added}, nil, nil).Once()
+		env.SheetStore.On("MarkSynced", sheet.ID, 1, mock.Anything).Return(nil).Once()
+		env.SheetStore.On("RecordRun", mock.Anything).Return(nil).Once()
+
+		w := sync(env, sheet)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.SheetStore.AssertExpectations(t)
+	})
+
+	t.Run("PrivateAddressRefused", func(t *testing.T) {
+		env := setupOrderSheetEnv()
+		internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("the syncer reached a loopback address: %s", r.URL.Path)
+		}))
+		defer internal.Close()
+		env.Handler.Syncer.ExportURL = internal.URL
+
+		sheet := &database.OrderSheetSync{ID: uuid.New(), OrganizationID: orgID, SheetURL: testSheetURL}
+		env.SheetStore.On("RecordRun", mock.MatchedBy(func(run *database.OrderSheetRun) bool {
+			return run.Status == database.OrderSheetRunFailed
+		})).Return(nil).Once()
+
+		w := sync(env, sheet)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "loopback or private addresses")
+	})
+
+	t.Run("UnreadableSheetIsLogged", func(t *testing.T) {
+		env := setupOrderSheetEnv()
+		google := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// sheets that are not shared by link redirect to the login page
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html>Sign in</html>"))
+		}))
+		defer google.Close()
+		env.Handler.Syncer.Client = google.Client()
+		env.Handler.Syncer.ExportURL = google.URL
+
+		sheet := &database.OrderSheetSync{ID: uuid.New(), OrganizationID: orgID, SheetURL: testSheetURL, RowsImported: 5}
+		env.SheetStore.On("RecordRun", mock.MatchedBy(func(run *database.OrderSheetRun) bool {
+			return run.Status == database.OrderSheetRunFailed && run.Error != nil
+		})).Return(nil).Once()
+
+		w := sync(env, sheet)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "not shared by link")
+		env.SheetStore.AssertExpectations(t)
+		env.SheetStore.AssertNotCalled(t, "MarkSynced", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetOrderSheetRunsHandler(t *testing.T) {
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	sheetID := uuid.New()
+	route := "/:org/integrations/order-sheets/:id/runs"
+	path := "/" + orgID.String() + "/integrations/order-sheets/" + sheetID.String() + "/runs"
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupOrderSheetEnv()
+		env.SheetStore.On("GetSync", orgID, sheetID).Return(&database.OrderSheetSync{ID: sheetID}, nil).Once()
+		env.SheetStore.On("ListRuns", orgID, sheetID, 5).Return([]database.OrderSheetRun{{SyncID: sheetID, Status: database.OrderSheetRunSuccess, SuccessCount: 12}}, nil).Once()
+
+		w := jobRequest("GET", route, path+"?limit=5", []gin.HandlerFunc{authMiddleware(admin), env.Handler.GetOrderSheetRunsHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"success_count":12`)
+	})
+
+	t.Run("InvalidLimit", func(t *testing.T) {
+		env := setupOrderSheetEnv()
+		w := jobRequest("GET", route, path+"?limit=500", []gin.HandlerFunc{authMiddleware(admin), env.Handler.GetOrderSheetRunsHandler}, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
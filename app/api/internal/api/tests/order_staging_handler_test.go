@@ -0,0 +1,259 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type OrderImportTestEnv struct {
+	ImportStore   *MockOrderImportStore
+	UploadService *MockUploadService
+	RuleStore     *MockIngestionRuleStore
+	Handler       *api.OrderImportHandler
+}
+
+func setupOrderImportEnv() *OrderImportTestEnv {
+	gin.SetMode(gin.TestMode)
+	env := &OrderImportTestEnv{
+		ImportStore:   new(MockOrderImportStore),
+		UploadService: new(MockUploadService),
+		RuleStore:     new(MockIngestionRuleStore),
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	env.Handler = api.NewOrderImportHandler(env.ImportStore, env.UploadService, env.RuleStore, logger)
+	return env
+}
+
+func TestStageOrderImportHandler(t *testing.T) {
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	importID := uuid.New()
+
+	upload := func(env *OrderImportTestEnv, user *database.User) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.POST("/:org/orders/imports", authMiddleware(user), env.Handler.StageOrderImportHandler)
+
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("file", "history.csv")
+		part.Write([]byte("dummy content"))
+		writer.Close()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/orders/imports", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		router.ServeHTTP(w, req)
+		return w
+	}
+	stream := func(lines ...string) *service.CSVStream {
+		s, err := service.NewCSVStream(strings.NewReader(strings.Join(lines, "\n")), 0, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	headers := "order_id,user_id,create_time,order_type,order_status,total_amount,discount_amount"
+	row := func(orderID, total string) string {
+		return orderID + "," + uuid.New().String() + ",2025-06-01 12:00:00,takeaway,completed," + total + ",0"
+	}
+	createImport := func(env *OrderImportTestEnv) {
+		env.ImportStore.On("CreateOrderImport", mock.MatchedBy(func(orderImport *database.OrderImport) bool {
+			return orderImport.OrganizationID == orgID && *orderImport.UploadedBy == admin.ID && orderImport.FileName == "history.csv"
+		})).Run(func(args mock.Arguments) {
+			args.Get(0).(*database.OrderImport).ID = importID
+		}).Return(nil).Once()
+	}
+
+	t.Run("StagesRowsAndListsProblems", func(t *testing.T) {
+		env := setupOrderImportEnv()
+		orderID := uuid.New()
+		message := "Orders over $2,000 are typos"
+		maxTotal := database.IngestionRule{ID: uuid.New(), Dataset: "orders", Field: "total_amount", Operator: "max", Value: "2000", Message: &message}
+		env.UploadService.On("StreamCSV", mock.Anything).Return(stream(headers, row(orderID.String(), "20"), row("not-a-uuid", "abc"), row(uuid.New().String(), "2500")), nil).Once()
+		env.RuleStore.On("GetRulesForDataset", orgID, "orders").Return([]database.IngestionRule{maxTotal}, nil).Once()
+		createImport(env)
+
+		var staged []database.OrderImportRow
+		env.ImportStore.On("StageOrderImportRows", importID, mock.Anything).Run(func(args mock.Arguments) {
+			staged = append(staged, args.Get(1).([]database.OrderImportRow)...)
+		}).Return(nil).Once()
+		validated := &database.OrderImport{ID: importID, OrganizationID: orgID, Status: database.OrderImportStaged, TotalRows: 3, ValidRows: 1, InvalidRows: 2}
+		env.ImportStore.On("ValidateOrderImport", orgID, importID).Return(validated, nil).Once()
+		parseError := "order_id must be a UUID; total_amount must be a number"
+		env.ImportStore.On("GetOrderImportProblems", importID, 100).Return([]database.OrderImportRow{
+			{Line: 3, Status: database.OrderImportRowInvalid, Error: &parseError},
+			{Line: 4, Status: database.OrderImportRowInvalid, Error: &message},
+		}, nil).Once()
+
+		w := upload(env, admin)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var response struct {
+			Data     database.OrderImport      `json:"data"`
+			Problems []database.OrderImportRow `json:"problems"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 1, response.Data.ValidRows)
+		assert.Len(t, response.Problems, 2)
+
+		// Values that do not parse and broken rules are caught as the file is read, the rest in bulk
+		if assert.Len(t, staged, 3) {
+			assert.Equal(t, 2, staged[0].Line)
+			assert.Equal(t, orderID, *staged[0].OrderID)
+			assert.Equal(t, 20.0, *staged[0].TotalAmount)
+			assert.Equal(t, time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC), *staged[0].CreateTime)
+			assert.Nil(t, staged[0].Error)
+			assert.Equal(t, database.OrderImportRowInvalid, staged[1].Status)
+			assert.Equal(t, parseError, *staged[1].Error)
+			assert.Nil(t, staged[1].OrderID)
+			assert.Equal(t, message, *staged[2].Error)
+		}
+		env.ImportStore.AssertExpectations(t)
+	})
+
+	t.Run("BadLineDiscardsImport", func(t *testing.T) {
+		env := setupOrderImportEnv()
+		env.UploadService.On("StreamCSV", mock.Anything).Return(stream(headers, row(uuid.New().String(), "20"), `"unterminated`), nil).Once()
+		env.RuleStore.On("GetRulesForDataset", orgID, "orders").Return([]database.IngestionRule{}, nil).Once()
+		createImport(env)
+		env.ImportStore.On("DiscardOrderImport", orgID, importID).Return(nil).Once()
+
+		w := upload(env, admin)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid CSV format at line")
+		env.ImportStore.AssertExpectations(t)
+		env.ImportStore.AssertNotCalled(t, "ValidateOrderImport", mock.Anything, mock.Anything)
+	})
+
+	t.Run("MissingColumn", func(t *testing.T) {
+		env := setupOrderImportEnv()
+		env.UploadService.On("StreamCSV", mock.Anything).Return(stream("order_id,user_id"), nil).Once()
+
+		w := upload(env, admin)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Missing required column: create_time")
+		env.ImportStore.AssertNotCalled(t, "CreateOrderImport", mock.Anything)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		env := setupOrderImportEnv()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := upload(env, employee)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestGetOrderImportHandler(t *testing.T) {
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	importID := uuid.New()
+	route := "/:org/orders/imports/:import"
+	path := "/" + orgID.String() + "/orders/imports/" + importID.String()
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupOrderImportEnv()
+		env.ImportStore.On("GetOrderImport", orgID, importID).Return(&database.OrderImport{ID: importID, Status: database.OrderImportStaged, DuplicateRows: 1}, nil).Once()
+		duplicate := "order_id repeats line 2"
+		env.ImportStore.On("GetOrderImportProblems", importID, 100).Return([]database.OrderImportRow{{Line: 5, Status: database.OrderImportRowDuplicate, Error: &duplicate}}, nil).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.GetOrderImportHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"duplicate_rows":1`)
+		assert.Contains(t, w.Body.String(), duplicate)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env := setupOrderImportEnv()
+		env.ImportStore.On("GetOrderImport", orgID, importID).Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.GetOrderImportHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestPromoteOrderImportHandler(t *testing.T) {
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	importID := uuid.New()
+	route := "/:org/orders/imports/:import/promote"
+	path := "/" + orgID.String() + "/orders/imports/" + importID.String() + "/promote"
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupOrderImportEnv()
+		env.ImportStore.On("PromoteOrderImport", orgID, importID).Return(&database.OrderImport{ID: importID, Status: database.OrderImportPromoted, PromotedRows: 998}, nil).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.PromoteOrderImportHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"promoted_rows":998`)
+	})
+
+	t.Run("AlreadyPromoted", func(t *testing.T) {
+		env := setupOrderImportEnv()
+		env.ImportStore.On("PromoteOrderImport", orgID, importID).Return(nil, database.ErrOrderImportNotStaged).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.PromoteOrderImportHandler}, nil)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("StoreError", func(t *testing.T) {
+		env := setupOrderImportEnv()
+		env.ImportStore.On("PromoteOrderImport", orgID, importID).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.PromoteOrderImportHandler}, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestDiscardOrderImportHandler(t *testing.T) {
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	importID := uuid.New()
+	route := "/:org/orders/imports/:import"
+	path := "/" + orgID.String() + "/orders/imports/" + importID.String()
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupOrderImportEnv()
+		env.ImportStore.On("DiscardOrderImport", orgID, importID).Return(nil).Once()
+
+		w := jobRequest("DELETE", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.DiscardOrderImportHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.ImportStore.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env := setupOrderImportEnv()
+		env.ImportStore.On("DiscardOrderImport", orgID, importID).Return(sql.ErrNoRows).Once()
+
+		w := jobRequest("DELETE", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.DiscardOrderImportHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
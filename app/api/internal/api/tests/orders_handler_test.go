@@ -1,25 +1,37 @@
 package api
 
 import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
 	"errors"
 	"log/slog"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/clockwise/clockwise/backend/internal/api"
 	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 type OrderTestEnv struct {
 	Router        *gin.Engine
 	OrderStore    *MockOrderStore
+	CampaignStore *MockCampaignStore
 	UploadService *MockUploadService
+	RuleStore     *MockIngestionRuleStore
+	StatusStore   *MockStatusStore
+	FieldStore    *MockCustomFieldStore
+	RulesStore    *MockRulesStore
 	Handler       *api.OrderHandler
 }
 
@@ -27,15 +39,27 @@ func setupOrderEnv() *OrderTestEnv {
 	gin.SetMode(gin.TestMode)
 
 	orderStore := new(MockOrderStore)
+	campaignStore := new(MockCampaignStore)
 	uploadService := new(MockUploadService)
+	ruleStore := new(MockIngestionRuleStore)
+	statusStore := new(MockStatusStore)
+	statusStore.AllowEvents()
+	fieldStore := new(MockCustomFieldStore)
+	fieldStore.NoFields()
+	rulesStore := new(MockRulesStore)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
-	handler := api.NewOrderHandler(orderStore, uploadService, logger)
+	handler := api.NewOrderHandler(orderStore, campaignStore, uploadService, ruleStore, statusStore, fieldStore, rulesStore, logger)
 
 	return &OrderTestEnv{
 		Router:        gin.New(),
 		OrderStore:    orderStore,
+		CampaignStore: campaignStore,
 		UploadService: uploadService,
+		RuleStore:     ruleStore,
+		StatusStore:   statusStore,
+		FieldStore:    fieldStore,
+		RulesStore:    rulesStore,
 		Handler:       handler,
 	}
 }
@@ -43,8 +67,507 @@ func setupOrderEnv() *OrderTestEnv {
 func (env *OrderTestEnv) ResetMocks() {
 	env.OrderStore.ExpectedCalls = nil
 	env.OrderStore.Calls = nil
+	env.CampaignStore.ExpectedCalls = nil
+	env.CampaignStore.Calls = nil
 	env.UploadService.ExpectedCalls = nil
 	env.UploadService.Calls = nil
+	env.RuleStore.ExpectedCalls = nil
+	env.RuleStore.Calls = nil
+	env.StatusStore.ExpectedCalls = nil
+	env.StatusStore.Calls = nil
+	env.StatusStore.AllowEvents()
+	env.FieldStore.ExpectedCalls = nil
+	env.FieldStore.Calls = nil
+	env.FieldStore.NoFields()
+	env.RulesStore.ExpectedCalls = nil
+	env.RulesStore.Calls = nil
+}
+
+// --- UploadAllPastOrdersCSV ---
+
+func TestUploadAllPastOrdersCSV(t *testing.T) {
+	env := setupOrderEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+
+	env.Router.POST("/:org/orders/upload/orders", authMiddleware(admin), env.Handler.UploadAllPastOrdersCSV)
+
+	uploadWith := func(query string) *httptest.ResponseRecorder {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("file", "orders.csv")
+		part.Write([]byte("dummy content"))
+		writer.Close()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/orders/upload/orders"+query, body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		env.Router.ServeHTTP(w, req)
+		return w
+	}
+	upload := func() *httptest.ResponseRecorder { return uploadWith("") }
+	// stream returns a stream of the CSV lines, read at most maxRows rows
+	stream := func(maxRows int, lines ...string) *service.CSVStream {
+		s, err := service.NewCSVStream(strings.NewReader(strings.Join(lines, "\n")), maxRows, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	row := func(orderID uuid.UUID, extra string) string {
+		return orderID.String() + "," + uuid.New().String() + ",2025-06-01 12:00:00,takeaway,completed,20,2" + extra
+	}
+	headers := "order_id,user_id,create_time,order_type,order_status,total_amount,discount_amount"
+
+	t.Run("RecordsRedemptions", func(t *testing.T) {
+		env.ResetMocks()
+		redeemed, unknown, plain := uuid.New(), uuid.New(), uuid.New()
+		at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		env.UploadService.On("StreamCSV", mock.Anything).Return(stream(0, headers+",redemption_code", row(redeemed, ",SUMMER10"), row(unknown, ",NOPE"), row(plain, ",")), nil).Once()
+		env.RuleStore.On("GetRulesForDataset", orgID, "orders").Return([]database.IngestionRule{}, nil).Once()
+		env.OrderStore.On("StoreOrdersBulk", orgID, mock.Anything, database.OrderConflictSkip).Return([]uuid.UUID{redeemed, unknown, plain}, nil, nil).Once()
+		env.CampaignStore.On("RecordRedemption", orgID, "SUMMER10", redeemed, at).Return(nil).Once()
+		env.CampaignStore.On("RecordRedemption", orgID, "NOPE", unknown, at).Return(sql.ErrNoRows).Once()
+
+		w := upload()
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"total_rows":3`)
+		assert.Contains(t, w.Body.String(), `"success_count":3`)
+		assert.Contains(t, w.Body.String(), `"redemption_count":1`)
+		assert.Contains(t, w.Body.String(), `"unknown_redemption_codes":1`)
+		env.CampaignStore.AssertExpectations(t)
+
+		// The ingestion lag runs from the newest order to the upload
+		if ingestions := env.StatusStore.RecordedEvents(database.StatusEventIngestion); assert.Len(t, ingestions, 1) {
+			assert.Equal(t, "orders_csv", ingestions[0].Source)
+			assert.InDelta(t, time.Since(at).Milliseconds(), *ingestions[0].DurationMs, float64(time.Minute.Milliseconds()))
+			assert.Equal(t, "3 rows", *ingestions[0].Detail)
+		}
+	})
+
+	t.Run("RejectsRuleViolations", func(t *testing.T) {
+		env.ResetMocks()
+		line := func(orderID uuid.UUID, total, rating string) string {
+			return orderID.String() + "," + uuid.New().String() + ",2025-06-01 12:00:00,takeaway,completed," + total + ",0," + rating
+		}
+		first, last := uuid.New(), uuid.New()
+		message := "Orders over $2,000 are typos"
+		maxTotal := database.IngestionRule{ID: uuid.New(), Dataset: "orders", Field: "total_amount", Operator: "max", Value: "2000", Message: &message}
+		minRating := database.IngestionRule{ID: uuid.New(), Dataset: "orders", Field: "rating", Operator: "min", Value: "1"}
+		maxRating := database.IngestionRule{ID: uuid.New(), Dataset: "orders", Field: "rating", Operator: "max", Value: "5"}
+		env.UploadService.On("StreamCSV", mock.Anything).Return(stream(0, headers+",rating", line(first, "20", "4"), line(uuid.New(), "2500", "4"), line(uuid.New(), "30", "9"), line(last, "40", "")), nil).Once()
+		env.RuleStore.On("GetRulesForDataset", orgID, "orders").Return([]database.IngestionRule{maxTotal, minRating, maxRating}, nil).Once()
+		env.OrderStore.On("StoreOrdersBulk", orgID, mock.MatchedBy(func(orders []database.Order) bool { return len(orders) == 2 }), database.OrderConflictSkip).Return([]uuid.UUID{first, last}, nil, nil).Once()
+
+		w := upload()
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			SuccessCount  int                      `json:"success_count"`
+			RejectedCount int                      `json:"rejected_count"`
+			Violations    []api.IngestionViolation `json:"violations"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		// The missing rating is optional, only the second and third rows break a rule
+		assert.Equal(t, 2, response.SuccessCount)
+		assert.Equal(t, 2, response.RejectedCount)
+		assert.Equal(t, []api.IngestionViolation{
+			{Line: 3, RuleID: maxTotal.ID, Field: "total_amount", Message: message},
+			{Line: 4, RuleID: maxRating.ID, Field: "rating", Message: "rating must be at most 5"},
+		}, response.Violations)
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("RulesError", func(t *testing.T) {
+		env.ResetMocks()
+		env.UploadService.On("StreamCSV", mock.Anything).Return(stream(0, headers), nil).Once()
+		env.RuleStore.On("GetRulesForDataset", orgID, "orders").Return(nil, errors.New("db error")).Once()
+
+		w := upload()
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		env.OrderStore.AssertNotCalled(t, "StoreOrdersBulk", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("StoresInBatchesAndCountsDuplicates", func(t *testing.T) {
+		env.ResetMocks()
+		lines := []string{headers}
+		ids := make([]uuid.UUID, 1500)
+		for i := range ids {
+			ids[i] = uuid.New()
+			lines = append(lines, row(ids[i], ""))
+		}
+		env.UploadService.On("StreamCSV", mock.Anything).Return(stream(0, lines...), nil).Once()
+		env.RuleStore.On("GetRulesForDataset", orgID, "orders").Return([]database.IngestionRule{}, nil).Once()
+		env.OrderStore.On("StoreOrdersBulk", orgID, mock.MatchedBy(func(orders []database.Order) bool { return len(orders) == 1000 }), database.OrderConflictSkip).Return(ids[:1000], nil, nil).Once()
+		// Ten orders of the second batch were imported before
+		env.OrderStore.On("StoreOrdersBulk", orgID, mock.MatchedBy(func(orders []database.Order) bool { return len(orders) == 500 }), database.OrderConflictSkip).Return(ids[1010:], nil, nil).Once()
+
+		w := upload()
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"total_rows":1500`)
+		assert.Contains(t, w.Body.String(), `"success_count":1490`)
+		assert.Contains(t, w.Body.String(), `"duplicate_count":10`)
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("FailedBatchStoresOrdersOneAtATime", func(t *testing.T) {
+		env.ResetMocks()
+		good, bad := uuid.New(), uuid.New()
+		env.UploadService.On("StreamCSV", mock.Anything).Return(stream(0, headers, row(good, ""), row(bad, "")), nil).Once()
+		env.RuleStore.On("GetRulesForDataset", orgID, "orders").Return([]database.IngestionRule{}, nil).Once()
+		env.OrderStore.On("StoreOrdersBulk", orgID, mock.MatchedBy(func(orders []database.Order) bool { return len(orders) == 2 }), database.OrderConflictSkip).Return(nil, nil, errors.New("db error")).Once()
+		env.OrderStore.On("StoreOrdersBulk", orgID, mock.MatchedBy(func(orders []database.Order) bool { return len(orders) == 1 && orders[0].OrderID == good }), database.OrderConflictSkip).Return([]uuid.UUID{good}, nil, nil).Once()
+		env.OrderStore.On("StoreOrdersBulk", orgID, mock.MatchedBy(func(orders []database.Order) bool { return len(orders) == 1 && orders[0].OrderID == bad }), database.OrderConflictSkip).Return(nil, nil, errors.New("db error")).Once()
+
+		w := upload()
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"success_count":1`)
+		assert.Contains(t, w.Body.String(), `"error_count":1`)
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("OverwriteCountsUpdatedOrders", func(t *testing.T) {
+		env.ResetMocks()
+		fresh, reimported := uuid.New(), uuid.New()
+		env.UploadService.On("StreamCSV", mock.Anything).Return(stream(0, headers, row(fresh, ""), row(reimported, "")), nil).Once()
+		env.RuleStore.On("GetRulesForDataset", orgID, "orders").Return([]database.IngestionRule{}, nil).Once()
+		env.OrderStore.On("StoreOrdersBulk", orgID, mock.Anything, database.OrderConflictOverwrite).Return([]uuid.UUID{fresh}, []uuid.UUID{reimported}, nil).Once()
+
+		w := uploadWith("?on_conflict=overwrite")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"success_count":1`)
+		assert.Contains(t, w.Body.String(), `"updated_count":1`)
+		assert.Contains(t, w.Body.String(), `"duplicate_count":0`)
+		if ingestions := env.StatusStore.RecordedEvents(database.StatusEventIngestion); assert.Len(t, ingestions, 1) {
+			assert.Equal(t, "2 rows", *ingestions[0].Detail)
+		}
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("FailStopsAtAlreadyImportedOrder", func(t *testing.T) {
+		env.ResetMocks()
+		lines := []string{headers}
+		ids := make([]uuid.UUID, 1500)
+		for i := range ids {
+			ids[i] = uuid.New()
+			lines = append(lines, row(ids[i], ""))
+		}
+		env.UploadService.On("StreamCSV", mock.Anything).Return(stream(0, lines...), nil).Once()
+		env.RuleStore.On("GetRulesForDataset", orgID, "orders").Return([]database.IngestionRule{}, nil).Once()
+		env.OrderStore.On("StoreOrdersBulk", orgID, mock.MatchedBy(func(orders []database.Order) bool { return len(orders) == 1000 }), database.OrderConflictFail).
+			Return(nil, nil, &database.OrderConflictError{OrderID: ids[10]}).Once()
+
+		w := uploadWith("?on_conflict=fail")
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "Order "+ids[10].String()+" was already imported")
+		assert.Contains(t, w.Body.String(), `"success_count":0`)
+		// The import stops at the first conflict, the second batch is never stored
+		env.OrderStore.AssertNumberOfCalls(t, "StoreOrdersBulk", 1)
+	})
+
+	t.Run("InvalidConflictStrategy", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := uploadWith("?on_conflict=merge")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "on_conflict must be one of skip, overwrite or fail")
+		env.UploadService.AssertNotCalled(t, "StreamCSV", mock.Anything)
+	})
+
+	t.Run("TooManyRowsKeepsImportedRows", func(t *testing.T) {
+		env.ResetMocks()
+		first, second := uuid.New(), uuid.New()
+		env.UploadService.On("StreamCSV", mock.Anything).Return(stream(2, headers, row(first, ""), row(second, ""), row(uuid.New(), "")), nil).Once()
+		env.RuleStore.On("GetRulesForDataset", orgID, "orders").Return([]database.IngestionRule{}, nil).Once()
+		env.OrderStore.On("StoreOrdersBulk", orgID, mock.MatchedBy(func(orders []database.Order) bool { return len(orders) == 2 }), database.OrderConflictSkip).Return([]uuid.UUID{first, second}, nil, nil).Once()
+
+		w := upload()
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Contains(t, w.Body.String(), `"success_count":2`)
+		assert.Contains(t, w.Body.String(), `"hint"`)
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("EmptyFile", func(t *testing.T) {
+		env.ResetMocks()
+		env.UploadService.On("StreamCSV", mock.Anything).Return(nil, service.ErrEmptyFile).Once()
+
+		w := upload()
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "CSV file is empty")
+	})
+}
+
+func TestUploadOrderItemsCSV(t *testing.T) {
+	env := setupOrderEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+
+	env.Router.POST("/:org/orders/upload/items", authMiddleware(admin), env.Handler.UploadOrderItemsCSV)
+
+	upload := func() *httptest.ResponseRecorder {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("file", "order_items.csv")
+		part.Write([]byte("dummy content"))
+		writer.Close()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/orders/upload/items", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		env.Router.ServeHTTP(w, req)
+		return w
+	}
+	orderID, burger, fries := uuid.New(), uuid.New(), uuid.New()
+	csvData := &service.CSVData{
+		Headers: []string{"order_id", "item_id", "quantity", "total_price"},
+		Rows: []map[string]string{
+			{"order_id": orderID.String(), "item_id": burger.String(), "quantity": "2", "total_price": "20"},
+			{"order_id": "not-a-uuid", "item_id": burger.String(), "quantity": "1", "total_price": "10"},
+			{"order_id": orderID.String(), "item_id": fries.String(), "quantity": "1", "total_price": "4"},
+		},
+		Total: 3,
+	}
+	prerequisites := func() {
+		env.OrderStore.On("GetAllOrders", orgID).Return([]database.Order{{OrderID: orderID}}, nil).Once()
+		env.OrderStore.On("GetAllItems", orgID).Return([]database.Item{{ItemID: burger}}, nil).Once()
+		env.UploadService.On("ParseCSV", mock.Anything).Return(csvData, nil).Once()
+		env.RuleStore.On("GetRulesForDataset", orgID, "order_items").Return([]database.IngestionRule{}, nil).Once()
+	}
+
+	t.Run("StoresValidRowsAtOnce", func(t *testing.T) {
+		env.ResetMocks()
+		prerequisites()
+		env.OrderStore.On("StoreOrderItemsBulk", orgID, mock.MatchedBy(func(links []database.OrderItemLink) bool {
+			return len(links) == 2 && links[0].ItemID == burger && *links[0].Quantity == 2 && links[1].ItemID == fries
+		})).Return([]int{1}, nil).Once()
+
+		w := upload()
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"success_count":1`)
+		assert.Contains(t, w.Body.String(), `"error_count":2`)
+		env.OrderStore.AssertExpectations(t)
+		env.OrderStore.AssertNotCalled(t, "StoreOrderItems", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("StoreError", func(t *testing.T) {
+		env.ResetMocks()
+		prerequisites()
+		env.OrderStore.On("StoreOrderItemsBulk", orgID, mock.Anything).Return(nil, errors.New("db error")).Once()
+
+		w := upload()
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Body.String(), "Failed to store order items")
+	})
+}
+
+// --- UploadOrdersBatch ---
+
+func TestUploadOrdersBatch(t *testing.T) {
+	env := setupOrderEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	route := "/:org/orders/batch"
+	path := "/" + orgID.String() + "/orders/batch"
+	handlers := []gin.HandlerFunc{authMiddleware(admin), env.Handler.UploadOrdersBatch}
+
+	itemID := uuid.New()
+	order := func(orderType string) map[string]any {
+		return map[string]any{
+			"order_id": uuid.New(), "user_id": uuid.New(), "create_time": "2025-06-01T12:00:00Z",
+			"order_type": orderType, "order_status": "completed", "total_amount": 20, "discount_amount": 2,
+			"items": []map[string]any{{"item_id": itemID, "quantity": 2, "total_price": 20}},
+		}
+	}
+	allowRules := func() {
+		for _, dataset := range []string{"orders", "order_items", "deliveries"} {
+			env.RuleStore.On("GetRulesForDataset", orgID, dataset).Return([]database.IngestionRule{}, nil).Once()
+		}
+	}
+	type batchResponse struct {
+		SuccessCount  int                    `json:"success_count"`
+		ErrorCount    int                    `json:"error_count"`
+		RejectedCount int                    `json:"rejected_count"`
+		Results       []api.BatchOrderResult `json:"results"`
+	}
+	decode := func(w *httptest.ResponseRecorder) batchResponse {
+		var response batchResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	t.Run("StoresNestedOrders", func(t *testing.T) {
+		env.ResetMocks()
+		allowRules()
+		delivery := order("delivery")
+		delivery["redemption_code"] = "SUMMER10"
+		delivery["delivery"] = map[string]any{
+			"driver_id": uuid.New(), "latitude": 30.04, "longitude": 31.23,
+			"out_for_delivery_time": "2025-06-01T12:20:00Z", "status": "delivered",
+		}
+		at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		env.OrderStore.On("StoreNestedOrder", orgID, mock.MatchedBy(func(o *database.Order) bool {
+			return o.OrderType == "delivery" && o.DeliveryStatus != nil && o.DeliveryStatus.DeliveryStatus == "delivered" &&
+				*o.DeliveryStatus.DeliveryLocation.Latitude == 30.04 && len(o.OrderItems) == 1 && *o.OrderItems[0].Quantity == 2
+		})).Return(nil).Once()
+		env.OrderStore.On("StoreNestedOrder", orgID, mock.MatchedBy(func(o *database.Order) bool {
+			return o.OrderType == "takeaway" && o.DeliveryStatus == nil
+		})).Return(nil).Once()
+		env.CampaignStore.On("RecordRedemption", orgID, "SUMMER10", mock.Anything, at).Return(nil).Once()
+
+		w := jobRequest("POST", route, path, handlers, map[string]any{"orders": []any{delivery, order("takeaway")}})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		response := decode(w)
+		assert.Equal(t, 2, response.SuccessCount)
+		assert.Equal(t, api.BatchOrderStored, response.Results[0].Status)
+		assert.Equal(t, 1, response.Results[1].Index)
+		assert.Contains(t, w.Body.String(), `"redemption_count":1`)
+		env.OrderStore.AssertExpectations(t)
+		env.CampaignStore.AssertExpectations(t)
+
+		if ingestions := env.StatusStore.RecordedEvents(database.StatusEventIngestion); assert.Len(t, ingestions, 1) {
+			assert.Equal(t, "orders_batch", ingestions[0].Source)
+			assert.Equal(t, "2 rows", *ingestions[0].Detail)
+		}
+	})
+
+	t.Run("PerRecordResults", func(t *testing.T) {
+		env.ResetMocks()
+		allowRules()
+		invalid := order("takeaway")
+		invalid["discount_amount"] = 50
+		invalid["order_status"] = "delivered"
+		deliveryOnTakeaway := order("takeaway")
+		deliveryOnTakeaway["delivery"] = map[string]any{"driver_id": uuid.New(), "out_for_delivery_time": "2025-06-01T12:20:00Z", "status": "delivered"}
+		duplicate, unknownItem, failing := order("dine in"), order("dine in"), order("dine in")
+		matchID := func(o map[string]any) any {
+			id := o["order_id"].(uuid.UUID)
+			return mock.MatchedBy(func(order *database.Order) bool { return order.OrderID == id })
+		}
+		env.OrderStore.On("StoreNestedOrder", orgID, matchID(duplicate)).Return(database.ErrDuplicateOrder).Once()
+		env.OrderStore.On("StoreNestedOrder", orgID, matchID(unknownItem)).Return(database.ErrUnknownOrderItem).Once()
+		env.OrderStore.On("StoreNestedOrder", orgID, matchID(failing)).Return(errors.New("db error")).Once()
+
+		w := jobRequest("POST", route, path, handlers, map[string]any{"orders": []any{
+			invalid, deliveryOnTakeaway, "not an order", duplicate, unknownItem, failing,
+		}})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		response := decode(w)
+		assert.Equal(t, 0, response.SuccessCount)
+		assert.Equal(t, 6, response.ErrorCount)
+		statuses := []string{}
+		for _, result := range response.Results {
+			statuses = append(statuses, result.Status)
+		}
+		assert.Equal(t, []string{
+			api.BatchOrderInvalid, api.BatchOrderInvalid, api.BatchOrderInvalid,
+			api.BatchOrderDuplicate, api.BatchOrderInvalid, api.BatchOrderFailed,
+		}, statuses)
+		assert.Equal(t, []string{"order_status must be one of pending, preparing, ready, completed, cancelled, incompleted", "discount_amount cannot exceed total_amount"}, response.Results[0].Errors)
+		assert.Equal(t, []string{"only delivery orders can have a delivery"}, response.Results[1].Errors)
+		assert.Nil(t, response.Results[2].OrderID)
+		assert.Equal(t, []string{"Failed to store order"}, response.Results[5].Errors)
+		env.StatusStore.AssertNotCalled(t, "RecordEvent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("RejectsRuleViolations", func(t *testing.T) {
+		env.ResetMocks()
+		message := "Orders over $2,000 are typos"
+		maxTotal := database.IngestionRule{ID: uuid.New(), Dataset: "orders", Field: "total_amount", Operator: "max", Value: "2000", Message: &message}
+		maxQuantity := database.IngestionRule{ID: uuid.New(), Dataset: "order_items", Field: "quantity", Operator: "max", Value: "50"}
+		env.RuleStore.On("GetRulesForDataset", orgID, "orders").Return([]database.IngestionRule{maxTotal}, nil).Once()
+		env.RuleStore.On("GetRulesForDataset", orgID, "order_items").Return([]database.IngestionRule{maxQuantity}, nil).Once()
+		env.RuleStore.On("GetRulesForDataset", orgID, "deliveries").Return([]database.IngestionRule{}, nil).Once()
+		env.OrderStore.On("StoreNestedOrder", orgID, mock.Anything).Return(nil).Once()
+
+		huge := order("takeaway")
+		huge["total_amount"] = 2500
+		huge["items"] = []map[string]any{{"item_id": itemID, "quantity": 80, "total_price": 2500}}
+
+		w := jobRequest("POST", route, path, handlers, map[string]any{"orders": []any{huge, order("takeaway")}})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		response := decode(w)
+		assert.Equal(t, 1, response.SuccessCount)
+		assert.Equal(t, 1, response.RejectedCount)
+		assert.Equal(t, api.BatchOrderRejected, response.Results[0].Status)
+		assert.Equal(t, []string{message, "quantity must be at most 50"}, response.Results[0].Errors)
+		env.OrderStore.AssertNumberOfCalls(t, "StoreNestedOrder", 1)
+	})
+
+	t.Run("Channels", func(t *testing.T) {
+		env.ResetMocks()
+		allowRules()
+		phone, unknown := order("takeaway"), order("takeaway")
+		phone["channel"] = database.OrderChannelPhone
+		unknown["channel"] = "fax"
+		env.OrderStore.On("StoreNestedOrder", orgID, mock.MatchedBy(func(o *database.Order) bool {
+			return o.Channel == database.OrderChannelPhone
+		})).Return(nil).Once()
+
+		w := jobRequest("POST", route, path, handlers, map[string]any{"orders": []any{phone, unknown}})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		response := decode(w)
+		assert.Equal(t, 1, response.SuccessCount)
+		assert.Equal(t, api.BatchOrderInvalid, response.Results[1].Status)
+		assert.Equal(t, []string{"channel must be one of direct, pos, phone, app, uber_eats, deliveroo, talabat"}, response.Results[1].Errors)
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("TooManyOrders", func(t *testing.T) {
+		env.ResetMocks()
+		orders := make([]any, 501)
+		for i := range orders {
+			orders[i] = order("takeaway")
+		}
+
+		w := jobRequest("POST", route, path, handlers, map[string]any{"orders": orders})
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Contains(t, w.Body.String(), "hint")
+		env.OrderStore.AssertNotCalled(t, "StoreNestedOrder", mock.Anything, mock.Anything)
+	})
+
+	t.Run("EmptyBatch", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("POST", route, path, handlers, map[string]any{"orders": []any{}})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("RulesError", func(t *testing.T) {
+		env.ResetMocks()
+		env.RuleStore.On("GetRulesForDataset", orgID, "orders").Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("POST", route, path, handlers, map[string]any{"orders": []any{order("takeaway")}})
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		env.OrderStore.AssertNotCalled(t, "StoreNestedOrder", mock.Anything, mock.Anything)
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.UploadOrdersBatch}, map[string]any{"orders": []any{order("takeaway")}})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
 }
 
 // --- GetAllOrders ---
@@ -62,7 +585,7 @@ func TestGetAllOrdersHandler(t *testing.T) {
 		orders := []database.Order{
 			{OrderID: uuid.New(), UserID: uuid.New(), OrganizationID: orgID, CreateTime: time.Now(), OrderType: "dine-in", OrderStatus: "completed", TotalAmount: &total},
 		}
-		env.OrderStore.On("GetAllOrders", orgID).Return(orders, nil).Once()
+		env.OrderStore.On("GetOrders", orgID, database.OrderFilter{}).Return(orders, nil).Once()
 
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/orders", nil)
@@ -74,6 +597,33 @@ func TestGetAllOrdersHandler(t *testing.T) {
 		env.OrderStore.AssertExpectations(t)
 	})
 
+	t.Run("Success_Filtered", func(t *testing.T) {
+		env.ResetMocks()
+		from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+		to := time.Date(2026, 10, 8, 0, 0, 0, 0, time.Local)
+		filter := database.OrderFilter{From: &from, To: &to, Statuses: []string{"completed"}, Channels: []string{"uber_eats", "deliveroo"}}
+		env.OrderStore.On("GetOrders", orgID, filter).Return([]database.Order{}, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/orders?from=2026-10-01&to=2026-10-07&status=completed&channel=uber_eats,deliveroo", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_InvalidDateRange", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/orders?from=2026-10-08&to=2026-10-01", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "from must not be after to")
+		env.OrderStore.AssertNotCalled(t, "GetOrders", mock.Anything, mock.Anything)
+	})
+
 	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
 		env.ResetMocks()
 		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
@@ -90,7 +640,7 @@ func TestGetAllOrdersHandler(t *testing.T) {
 
 	t.Run("Failure_DBError", func(t *testing.T) {
 		env.ResetMocks()
-		env.OrderStore.On("GetAllOrders", orgID).Return(nil, errors.New("db error")).Once()
+		env.OrderStore.On("GetOrders", orgID, database.OrderFilter{}).Return(nil, errors.New("db error")).Once()
 
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/orders", nil)
@@ -245,133 +795,234 @@ func TestGetOrdersInsightsHandler(t *testing.T) {
 	})
 }
 
-// --- GetAllItems ---
-
-func TestGetAllItemsHandler(t *testing.T) {
+func TestGetOrdersInRangeHandler(t *testing.T) {
 	env := setupOrderEnv()
 	orgID := uuid.New()
 	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
-
-	env.Router.GET("/:org/items", authMiddleware(admin), env.Handler.GetAllItems)
+	route := "/:org/orders"
+	path := "/" + orgID.String() + "/orders"
+	handlers := []gin.HandlerFunc{authMiddleware(admin), env.Handler.GetOrdersInsights}
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
 
 	t.Run("Success", func(t *testing.T) {
 		env.ResetMocks()
-		price := 12.99
-		neededEmp := 2
-		items := []database.Item{
-			{ItemID: uuid.New(), Name: "Burger", Price: &price, NeededNumEmployeesToPrepare: &neededEmp},
-		}
-		env.OrderStore.On("GetAllItems", orgID).Return(items, nil).Once()
+		orders := []database.Order{{OrderID: uuid.New(), OrderType: "dine-in"}}
+		env.OrderStore.On("GetOrdersInRange", orgID, from, from.AddDate(0, 0, 7)).Return(orders, nil).Once()
 
-		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/items", nil)
-		env.Router.ServeHTTP(w, req)
+		w := jobRequest("GET", route, path+"?from=2026-10-01&to=2026-10-07", handlers, nil)
 
 		assert.Equal(t, http.StatusOK, w.Code)
-		assert.Contains(t, w.Body.String(), "Items retrieved successfully")
-		assert.Contains(t, w.Body.String(), "Burger")
+		assert.Contains(t, w.Body.String(), "Orders retrieved successfully")
+		assert.Contains(t, w.Body.String(), orders[0].OrderID.String())
+		env.OrderStore.AssertNotCalled(t, "GetOrdersInsights", mock.Anything)
 		env.OrderStore.AssertExpectations(t)
 	})
 
-	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
+	t.Run("MissingBound", func(t *testing.T) {
 		env.ResetMocks()
-		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
-		router := gin.New()
-		router.GET("/:org/items", authMiddleware(employee), env.Handler.GetAllItems)
+		w := jobRequest("GET", route, path+"?from=2026-10-01", handlers, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Both from and to are required")
+	})
 
-		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/items", nil)
-		router.ServeHTTP(w, req)
+	t.Run("InvalidRange", func(t *testing.T) {
+		env.ResetMocks()
+		w := jobRequest("GET", route, path+"?from=2026-10-07&to=2026-10-01", handlers, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
 
-		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = jobRequest("GET", route, path+"?from=october&to=2026-10-07", handlers, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		env.OrderStore.AssertNotCalled(t, "GetOrdersInRange", mock.Anything, mock.Anything, mock.Anything)
 	})
 
-	t.Run("Failure_DBError", func(t *testing.T) {
+	t.Run("DBError", func(t *testing.T) {
 		env.ResetMocks()
-		env.OrderStore.On("GetAllItems", orgID).Return(nil, errors.New("db error")).Once()
+		env.OrderStore.On("GetOrdersInRange", orgID, from, from.AddDate(0, 0, 7)).Return(nil, errors.New("db error")).Once()
 
-		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/items", nil)
-		env.Router.ServeHTTP(w, req)
+		w := jobRequest("GET", route, path+"?from=2026-10-01&to=2026-10-07", handlers, nil)
 
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
-		env.OrderStore.AssertExpectations(t)
+		assert.Contains(t, w.Body.String(), "Failed to retrieve orders")
 	})
 }
 
-// --- GetItemsInsights ---
+// --- GetChannelReport ---
 
-func TestGetItemsInsightsHandler(t *testing.T) {
+func TestGetChannelReportHandler(t *testing.T) {
 	env := setupOrderEnv()
 	orgID := uuid.New()
 	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	route := "/:org/orders/channels"
+	path := "/" + orgID.String() + "/orders/channels"
+	handlers := []gin.HandlerFunc{authMiddleware(admin), env.Handler.GetChannelReport}
 
-	env.Router.GET("/:org/items/insights", authMiddleware(admin), env.Handler.GetItemsInsights)
+	breakdown := []database.ChannelBreakdown{
+		{Channel: database.OrderChannelUberEats, Orders: 30, DeliveryOrders: 30, Share: 60, Revenue: 750, Discount: 50, AverageOrderValue: 25},
+		{Channel: database.OrderChannelDirect, Orders: 20, Share: 40, Revenue: 400, AverageOrderValue: 20},
+	}
+	june := time.Date(2025, 6, 1, 0, 0, 0, 0, time.Local)
 
-	t.Run("Success", func(t *testing.T) {
+	t.Run("Success_Period", func(t *testing.T) {
 		env.ResetMocks()
-		insights := []database.Insight{
-			{Title: "Most Popular Item", Statistic: "Burger"},
-		}
-		env.OrderStore.On("GetItemsInsights", orgID).Return(insights, nil).Once()
+		env.OrderStore.On("GetChannelBreakdown", orgID, june, june.AddDate(0, 0, 30)).Return(breakdown, nil).Once()
 
-		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/items/insights", nil)
-		env.Router.ServeHTTP(w, req)
+		w := jobRequest("GET", route, path+"?from=2025-06-01&to=2025-06-30", handlers, nil)
 
 		assert.Equal(t, http.StatusOK, w.Code)
-		assert.Contains(t, w.Body.String(), "Item insights retrieved successfully")
-		assert.Contains(t, w.Body.String(), "Most Popular Item")
+		body := w.Body.String()
+		assert.Contains(t, body, `"from":"2025-06-01"`)
+		assert.Contains(t, body, `"to":"2025-06-30"`)
+		assert.Contains(t, body, `"channel":"uber_eats"`)
+		assert.Contains(t, body, `"share_percent":60`)
 		env.OrderStore.AssertExpectations(t)
 	})
 
-	t.Run("Failure_DBError", func(t *testing.T) {
+	t.Run("Success_DefaultsToLast30Days", func(t *testing.T) {
 		env.ResetMocks()
-		env.OrderStore.On("GetItemsInsights", orgID).Return(nil, errors.New("db error")).Once()
+		now := time.Now()
+		to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+		env.OrderStore.On("GetChannelBreakdown", orgID, to.AddDate(0, 0, -30), to).Return([]database.ChannelBreakdown{}, nil).Once()
 
-		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/items/insights", nil)
-		env.Router.ServeHTTP(w, req)
+		w := jobRequest("GET", route, path, handlers, nil)
 
-		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, http.StatusOK, w.Code)
 		env.OrderStore.AssertExpectations(t)
 	})
-}
 
-// --- GetAllDeliveries ---
+	t.Run("Success_CSV", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetChannelBreakdown", orgID, june, june.AddDate(0, 0, 30)).Return(breakdown, nil).Once()
 
-func TestGetAllDeliveriesHandler(t *testing.T) {
-	env := setupOrderEnv()
-	orgID := uuid.New()
-	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+		w := jobRequest("GET", route, path+"?from=2025-06-01&to=2025-06-30&format=csv", handlers, nil)
 
-	env.Router.GET("/:org/deliveries", authMiddleware(admin), env.Handler.GetAllDeliveries)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "channels-2025-06-01-2025-06-30.csv")
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		assert.Equal(t, "channel,orders,delivery_orders,share_percent,revenue,discount,average_order_value", lines[0])
+		assert.Equal(t, "uber_eats,30,30,60.00,750.00,50.00,25.00", lines[1])
+	})
 
-	t.Run("Success", func(t *testing.T) {
-		env.ResetMocks()
-		deliveries := []database.OrderDelivery{
-			{OrderID: uuid.New(), DriverID: uuid.New(), DeliveryStatus: "delivered", OutForDeliveryTime: time.Now()},
+	t.Run("Failure_InvalidQuery", func(t *testing.T) {
+		for _, query := range []string{"?from=June", "?to=2025-13-01", "?from=2025-06-10&to=2025-06-01", "?format=pdf"} {
+			env.ResetMocks()
+
+			w := jobRequest("GET", route, path+query, handlers, nil)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+		env.OrderStore.AssertNotCalled(t, "GetChannelBreakdown", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetChannelReport}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Failure_DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetChannelBreakdown", orgID, mock.Anything, mock.Anything).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Body.String(), "Failed to retrieve channel report")
+	})
+}
+
+// --- GetAllItems ---
+
+func TestGetAllItemsHandler(t *testing.T) {
+	env := setupOrderEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+
+	env.Router.GET("/:org/items", authMiddleware(admin), env.Handler.GetAllItems)
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		price := 12.99
+		neededEmp := 2
+		items := []database.Item{
+			{ItemID: uuid.New(), Name: "Burger", Price: &price, NeededNumEmployeesToPrepare: &neededEmp},
 		}
-		env.OrderStore.On("GetAllDeliveries", orgID).Return(deliveries, nil).Once()
+		env.OrderStore.On("GetAllItems", orgID).Return(items, nil).Once()
 
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/deliveries", nil)
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/items", nil)
 		env.Router.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusOK, w.Code)
-		assert.Contains(t, w.Body.String(), "Deliveries retrieved successfully")
-		assert.Contains(t, w.Body.String(), "delivered")
+		assert.Contains(t, w.Body.String(), "Items retrieved successfully")
+		assert.Contains(t, w.Body.String(), "Burger")
 		env.OrderStore.AssertExpectations(t)
 	})
 
+	t.Run("Success_CustomFieldFilter", func(t *testing.T) {
+		env.ResetMocks()
+		burger, salad := database.Item{ItemID: uuid.New(), Name: "Burger"}, database.Item{ItemID: uuid.New(), Name: "Salad"}
+		env.OrderStore.On("GetAllItems", orgID).Return([]database.Item{burger, salad}, nil).Once()
+		env.FieldStore.ExpectedCalls = nil
+		env.FieldStore.On("ListFieldDefinitions", orgID, database.CustomFieldItem).
+			Return([]database.CustomFieldDefinition{{Key: "vegan", FieldType: database.CustomFieldBoolean}}, nil).Once()
+		env.FieldStore.On("GetCustomFieldValues", orgID, database.CustomFieldItem, []uuid.UUID{burger.ItemID, salad.ItemID}).
+			Return(map[uuid.UUID]map[string]any{salad.ItemID: {"vegan": true}}, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/items?cf.vegan=true", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Salad")
+		assert.Contains(t, w.Body.String(), `"custom_fields":{"vegan":true}`)
+		assert.NotContains(t, w.Body.String(), "Burger")
+		env.FieldStore.AssertExpectations(t)
+	})
+
+	t.Run("Success_ActiveFilter", func(t *testing.T) {
+		env.ResetMocks()
+		burger, soup := database.Item{ItemID: uuid.New(), Name: "Burger", IsActive: true}, database.Item{ItemID: uuid.New(), Name: "Pumpkin soup"}
+		env.OrderStore.On("GetAllItems", orgID).Return([]database.Item{burger, soup}, nil).Twice()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/items?active=true", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Burger")
+		assert.NotContains(t, w.Body.String(), "Pumpkin soup")
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/"+orgID.String()+"/items?active=false", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Pumpkin soup")
+		assert.NotContains(t, w.Body.String(), "Burger")
+	})
+
+	t.Run("Failure_InvalidActive", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/items?active=maybe", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
 	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
 		env.ResetMocks()
 		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
 		router := gin.New()
-		router.GET("/:org/deliveries", authMiddleware(employee), env.Handler.GetAllDeliveries)
+		router.GET("/:org/items", authMiddleware(employee), env.Handler.GetAllItems)
 
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/deliveries", nil)
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/items", nil)
 		router.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusForbidden, w.Code)
@@ -379,10 +1030,10 @@ func TestGetAllDeliveriesHandler(t *testing.T) {
 
 	t.Run("Failure_DBError", func(t *testing.T) {
 		env.ResetMocks()
-		env.OrderStore.On("GetAllDeliveries", orgID).Return(nil, errors.New("db error")).Once()
+		env.OrderStore.On("GetAllItems", orgID).Return(nil, errors.New("db error")).Once()
 
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/deliveries", nil)
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/items", nil)
 		env.Router.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
@@ -390,37 +1041,210 @@ func TestGetAllDeliveriesHandler(t *testing.T) {
 	})
 }
 
-// --- GetAllDeliveriesForLastWeek ---
+// --- Item CRUD ---
 
-func TestGetAllDeliveriesForLastWeekHandler(t *testing.T) {
+func TestCreateItemHandler(t *testing.T) {
+	env := setupOrderEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/items"
+	path := "/" + orgID.String() + "/items"
+	handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.CreateItemHandler}
+	body := map[string]any{"name": " Falafel wrap ", "needed_employees": 1, "price": 6.5}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.RuleStore.On("GetRulesForDataset", orgID, "items").Return([]database.IngestionRule{}, nil).Once()
+		env.OrderStore.On("StoreItems", orgID, mock.MatchedBy(func(i *database.Item) bool {
+			return i.Name == "Falafel wrap" && *i.Price == 6.5 && i.IsActive && i.ItemID != uuid.Nil
+		})).Return(nil).Once()
+
+		w := jobRequest("POST", route, path, handlers, body)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"is_active":true`)
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_MissingPrice", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("POST", route, path, handlers, map[string]any{"name": "Falafel wrap", "needed_employees": 1})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		env.OrderStore.AssertNotCalled(t, "StoreItems", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failure_BreaksRule", func(t *testing.T) {
+		env.ResetMocks()
+		env.RuleStore.On("GetRulesForDataset", orgID, "items").Return([]database.IngestionRule{
+			{ID: uuid.New(), Dataset: "items", Field: "price", Operator: "min", Value: "10"},
+		}, nil).Once()
+
+		w := jobRequest("POST", route, path, handlers, body)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "price must be at least 10")
+		env.OrderStore.AssertNotCalled(t, "StoreItems", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failure_NameTaken", func(t *testing.T) {
+		env.ResetMocks()
+		env.RuleStore.On("GetRulesForDataset", orgID, "items").Return([]database.IngestionRule{}, nil).Once()
+		env.OrderStore.On("StoreItems", orgID, mock.Anything).Return(database.ErrItemExists).Once()
+
+		w := jobRequest("POST", route, path, handlers, body)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.CreateItemHandler}, body)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestUpdateItemHandler(t *testing.T) {
 	env := setupOrderEnv()
 	orgID := uuid.New()
 	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	itemID := uuid.New()
+	route := "/:org/items/:item"
+	path := "/" + orgID.String() + "/items/" + itemID.String()
+	handlers := []gin.HandlerFunc{authMiddleware(admin), env.Handler.UpdateItemHandler}
+	stored := func() *database.Item {
+		needed, price := 2, 10.0
+		return &database.Item{ItemID: itemID, Name: "Burger", NeededNumEmployeesToPrepare: &needed, Price: &price}
+	}
 
-	env.Router.GET("/:org/deliveries/week", authMiddleware(admin), env.Handler.GetAllDeliveriesForLastWeek)
+	t.Run("Success_ReactivatesAndReprices", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetItem", orgID, itemID).Return(stored(), nil).Once()
+		env.RuleStore.On("GetRulesForDataset", orgID, "items").Return([]database.IngestionRule{}, nil).Once()
+		env.OrderStore.On("UpdateItem", orgID, mock.MatchedBy(func(i *database.Item) bool {
+			return i.Name == "Burger" && *i.Price == 11.5 && *i.NeededNumEmployeesToPrepare == 2 && i.IsActive
+		}), &admin.ID).Return(nil).Once()
+
+		w := jobRequest("PATCH", route, path, handlers, map[string]any{"price": 11.5, "is_active": true})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetItem", orgID, itemID).Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("PATCH", route, path, handlers, map[string]any{"price": 11.5})
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Failure_BlankName", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetItem", orgID, itemID).Return(stored(), nil).Once()
+
+		w := jobRequest("PATCH", route, path, handlers, map[string]any{"name": "   "})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		env.OrderStore.AssertNotCalled(t, "UpdateItem", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failure_NameTaken", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetItem", orgID, itemID).Return(stored(), nil).Once()
+		env.RuleStore.On("GetRulesForDataset", orgID, "items").Return([]database.IngestionRule{}, nil).Once()
+		env.OrderStore.On("UpdateItem", orgID, mock.Anything, &admin.ID).Return(database.ErrItemExists).Once()
+
+		w := jobRequest("PATCH", route, path, handlers, map[string]any{"name": "Fries"})
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Failure_InvalidID", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("PATCH", route, "/"+orgID.String()+"/items/not-a-uuid", handlers, map[string]any{"price": 11.5})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestDeleteItemHandler(t *testing.T) {
+	env := setupOrderEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	itemID := uuid.New()
+	route := "/:org/items/:item"
+	path := "/" + orgID.String() + "/items/" + itemID.String()
+	handlers := []gin.HandlerFunc{authMiddleware(admin), env.Handler.DeleteItemHandler}
+
+	t.Run("Success_Deactivates", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("DeactivateItem", orgID, itemID).Return(nil).Once()
+
+		w := jobRequest("DELETE", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("DeactivateItem", orgID, itemID).Return(sql.ErrNoRows).Once()
+
+		w := jobRequest("DELETE", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("DELETE", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.DeleteItemHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		env.OrderStore.AssertNotCalled(t, "DeactivateItem", mock.Anything, mock.Anything)
+	})
+}
+
+// --- GetItemsInsights ---
+
+func TestGetItemsInsightsHandler(t *testing.T) {
+	env := setupOrderEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+
+	env.Router.GET("/:org/items/insights", authMiddleware(admin), env.Handler.GetItemsInsights)
 
 	t.Run("Success", func(t *testing.T) {
 		env.ResetMocks()
-		deliveries := []database.OrderDelivery{
-			{OrderID: uuid.New(), DriverID: uuid.New(), DeliveryStatus: "delivered"},
+		insights := []database.Insight{
+			{Title: "Most Popular Item", Statistic: "Burger"},
 		}
-		env.OrderStore.On("GetAllDeliveriesForLastWeek", orgID).Return(deliveries, nil).Once()
+		env.OrderStore.On("GetItemsInsights", orgID).Return(insights, nil).Once()
 
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/deliveries/week", nil)
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/items/insights", nil)
 		env.Router.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusOK, w.Code)
-		assert.Contains(t, w.Body.String(), "Deliveries retrieved successfully")
+		assert.Contains(t, w.Body.String(), "Item insights retrieved successfully")
+		assert.Contains(t, w.Body.String(), "Most Popular Item")
 		env.OrderStore.AssertExpectations(t)
 	})
 
 	t.Run("Failure_DBError", func(t *testing.T) {
 		env.ResetMocks()
-		env.OrderStore.On("GetAllDeliveriesForLastWeek", orgID).Return(nil, errors.New("db error")).Once()
+		env.OrderStore.On("GetItemsInsights", orgID).Return(nil, errors.New("db error")).Once()
 
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/deliveries/week", nil)
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/items/insights", nil)
 		env.Router.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
@@ -428,37 +1252,77 @@ func TestGetAllDeliveriesForLastWeekHandler(t *testing.T) {
 	})
 }
 
-// --- GetAllDeliveriesToday ---
+// --- GetAllDeliveries ---
 
-func TestGetAllDeliveriesTodayHandler(t *testing.T) {
+func TestGetAllDeliveriesHandler(t *testing.T) {
 	env := setupOrderEnv()
 	orgID := uuid.New()
 	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
 
-	env.Router.GET("/:org/deliveries/today", authMiddleware(admin), env.Handler.GetAllDeliveriesToday)
+	env.Router.GET("/:org/deliveries", authMiddleware(admin), env.Handler.GetAllDeliveries)
 
 	t.Run("Success", func(t *testing.T) {
 		env.ResetMocks()
 		deliveries := []database.OrderDelivery{
-			{OrderID: uuid.New(), DriverID: uuid.New(), DeliveryStatus: "in_transit"},
+			{OrderID: uuid.New(), DriverID: uuid.New(), DeliveryStatus: "delivered", OutForDeliveryTime: time.Now()},
 		}
-		env.OrderStore.On("GetTodaysDeliveries", orgID).Return(deliveries, nil).Once()
+		env.OrderStore.On("GetDeliveries", orgID, database.DeliveryFilter{}).Return(deliveries, nil).Once()
 
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/deliveries/today", nil)
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/deliveries", nil)
 		env.Router.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusOK, w.Code)
 		assert.Contains(t, w.Body.String(), "Deliveries retrieved successfully")
+		assert.Contains(t, w.Body.String(), "delivered")
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("Success_Filtered", func(t *testing.T) {
+		env.ResetMocks()
+		driverID := uuid.New()
+		from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+		filter := database.DeliveryFilter{From: &from, Statuses: []string{"out for delivery"}, DriverIDs: []uuid.UUID{driverID}}
+		env.OrderStore.On("GetDeliveries", orgID, filter).Return([]database.OrderDelivery{}, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/deliveries?from=2026-10-01&status=out%20for%20delivery&driver_id="+driverID.String(), nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
 		env.OrderStore.AssertExpectations(t)
 	})
 
+	t.Run("Failure_InvalidDriverID", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/deliveries?driver_id=sam", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid driver_id")
+	})
+
+	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+		router := gin.New()
+		router.GET("/:org/deliveries", authMiddleware(employee), env.Handler.GetAllDeliveries)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/deliveries", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
 	t.Run("Failure_DBError", func(t *testing.T) {
 		env.ResetMocks()
-		env.OrderStore.On("GetTodaysDeliveries", orgID).Return(nil, errors.New("db error")).Once()
+		env.OrderStore.On("GetDeliveries", orgID, database.DeliveryFilter{}).Return(nil, errors.New("db error")).Once()
 
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/deliveries/today", nil)
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/deliveries", nil)
 		env.Router.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
@@ -466,35 +1330,111 @@ func TestGetAllDeliveriesTodayHandler(t *testing.T) {
 	})
 }
 
-// --- GetDeliveryInsights ---
+// --- GetAllDeliveriesForLastWeek ---
 
-func TestGetDeliveryInsightsHandler(t *testing.T) {
+func TestGetAllDeliveriesForLastWeekHandler(t *testing.T) {
 	env := setupOrderEnv()
 	orgID := uuid.New()
 	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
 
-	env.Router.GET("/:org/deliveries/insights", authMiddleware(admin), env.Handler.GetDeliveryInsights)
+	env.Router.GET("/:org/deliveries/week", authMiddleware(admin), env.Handler.GetAllDeliveriesForLastWeek)
 
 	t.Run("Success", func(t *testing.T) {
 		env.ResetMocks()
-		insights := []database.Insight{
-			{Title: "Total Deliveries", Statistic: "78"},
+		deliveries := []database.OrderDelivery{
+			{OrderID: uuid.New(), DriverID: uuid.New(), DeliveryStatus: "delivered"},
 		}
-		env.OrderStore.On("GetDeliveryInsights", orgID).Return(insights, nil).Once()
+		env.OrderStore.On("GetAllDeliveriesForLastWeek", orgID).Return(deliveries, nil).Once()
 
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/deliveries/insights", nil)
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/deliveries/week", nil)
 		env.Router.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusOK, w.Code)
-		assert.Contains(t, w.Body.String(), "Delivery insights retrieved successfully")
-		assert.Contains(t, w.Body.String(), "Total Deliveries")
+		assert.Contains(t, w.Body.String(), "Deliveries retrieved successfully")
 		env.OrderStore.AssertExpectations(t)
 	})
 
 	t.Run("Failure_DBError", func(t *testing.T) {
 		env.ResetMocks()
-		env.OrderStore.On("GetDeliveryInsights", orgID).Return(nil, errors.New("db error")).Once()
+		env.OrderStore.On("GetAllDeliveriesForLastWeek", orgID).Return(nil, errors.New("db error")).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/deliveries/week", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		env.OrderStore.AssertExpectations(t)
+	})
+}
+
+// --- GetAllDeliveriesToday ---
+
+func TestGetAllDeliveriesTodayHandler(t *testing.T) {
+	env := setupOrderEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+
+	env.Router.GET("/:org/deliveries/today", authMiddleware(admin), env.Handler.GetAllDeliveriesToday)
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		deliveries := []database.OrderDelivery{
+			{OrderID: uuid.New(), DriverID: uuid.New(), DeliveryStatus: "in_transit"},
+		}
+		env.OrderStore.On("GetTodaysDeliveries", orgID).Return(deliveries, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/deliveries/today", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Deliveries retrieved successfully")
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetTodaysDeliveries", orgID).Return(nil, errors.New("db error")).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/deliveries/today", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		env.OrderStore.AssertExpectations(t)
+	})
+}
+
+// --- GetDeliveryInsights ---
+
+func TestGetDeliveryInsightsHandler(t *testing.T) {
+	env := setupOrderEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+
+	env.Router.GET("/:org/deliveries/insights", authMiddleware(admin), env.Handler.GetDeliveryInsights)
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		insights := []database.Insight{
+			{Title: "Total Deliveries", Statistic: "78"},
+		}
+		env.OrderStore.On("GetDeliveryInsights", orgID).Return(insights, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/deliveries/insights", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Delivery insights retrieved successfully")
+		assert.Contains(t, w.Body.String(), "Total Deliveries")
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetDeliveryInsights", orgID).Return(nil, errors.New("db error")).Once()
 
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/deliveries/insights", nil)
@@ -504,3 +1444,672 @@ func TestGetDeliveryInsightsHandler(t *testing.T) {
 		env.OrderStore.AssertExpectations(t)
 	})
 }
+
+// --- Driver insights ---
+
+func TestRateDrivers(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		driver := database.DriverDeliveryStats{DriverID: uuid.New(), FullName: "Sam", Deliveries: 25, Delivered: 24, ActiveDays: 4, DeliveryMinutes: 780, OnTime: 18, DistanceKm: 123.456}
+
+		rated := api.RateDrivers([]database.DriverDeliveryStats{driver})
+
+		assert.Equal(t, 6.25, rated[0].DeliveriesPerDay)
+		assert.Equal(t, 32.5, *rated[0].AverageDeliveryMinutes)
+		assert.Equal(t, 75.0, *rated[0].OnTimeRate)
+		assert.Equal(t, 123.46, rated[0].DistanceKm)
+	})
+
+	t.Run("NothingDelivered", func(t *testing.T) {
+		// out once and the customer was not home
+		driver := database.DriverDeliveryStats{DriverID: uuid.New(), Deliveries: 1, ActiveDays: 1}
+
+		rated := api.RateDrivers([]database.DriverDeliveryStats{driver})
+
+		assert.Equal(t, 1.0, rated[0].DeliveriesPerDay)
+		assert.Nil(t, rated[0].AverageDeliveryMinutes)
+		assert.Nil(t, rated[0].OnTimeRate)
+	})
+
+	t.Run("NoDrivers", func(t *testing.T) {
+		rated := api.RateDrivers(nil)
+		assert.NotNil(t, rated)
+		assert.Empty(t, rated)
+	})
+}
+
+func TestGetDriverDeliveryInsightsHandler(t *testing.T) {
+	env := setupOrderEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/deliveries/insights/drivers"
+	path := "/" + orgID.String() + "/deliveries/insights/drivers"
+	handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetDriverDeliveryInsights}
+	june := time.Date(2025, 6, 1, 0, 0, 0, 0, time.Local)
+	july := june.AddDate(0, 0, 30)
+
+	t.Run("Success_Period", func(t *testing.T) {
+		env.ResetMocks()
+		driverID := uuid.New()
+		env.OrderStore.On("GetDriverDeliveryStats", orgID, june, july, 30).Return([]database.DriverDeliveryStats{
+			{DriverID: driverID, FullName: "Sam", Deliveries: 10, Delivered: 10, ActiveDays: 2, DeliveryMinutes: 250, OnTime: 8, DistanceKm: 42},
+		}, nil).Once()
+
+		w := jobRequest("GET", route, path+"?from=2025-06-01&to=2025-06-30&on_time_minutes=30", handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		body := w.Body.String()
+		assert.Contains(t, body, `"from":"2025-06-01"`)
+		assert.Contains(t, body, `"to":"2025-06-30"`)
+		assert.Contains(t, body, `"on_time_minutes":30`)
+		assert.Contains(t, body, `"driver_id":"`+driverID.String()+`"`)
+		assert.Contains(t, body, `"average_delivery_minutes":25`)
+		assert.Contains(t, body, `"deliveries_per_day":5`)
+		assert.Contains(t, body, `"on_time_rate_percent":80`)
+		assert.Contains(t, body, `"distance_km":42`)
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("Success_Defaults", func(t *testing.T) {
+		env.ResetMocks()
+		now := time.Now()
+		to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+		from := to.AddDate(0, 0, -30)
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(nil, nil).Once()
+		env.OrderStore.On("GetDriverDeliveryStats", orgID, from, to, 45).Return([]database.DriverDeliveryStats{}, nil).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"drivers":[]`)
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("Success_OrganizationSLA", func(t *testing.T) {
+		env.ResetMocks()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(&database.OrganizationRules{OrganizationID: orgID, DeliverySLAMinutes: 35}, nil).Once()
+		env.OrderStore.On("GetDriverDeliveryStats", orgID, june, july, 35).Return([]database.DriverDeliveryStats{}, nil).Once()
+
+		w := jobRequest("GET", route, path+"?from=2025-06-01&to=2025-06-30", handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"on_time_minutes":35`)
+		env.OrderStore.AssertExpectations(t)
+		env.RulesStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_InvalidOnTimeMinutes", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("GET", route, path+"?on_time_minutes=0", handlers, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Failure_InvalidDate", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("GET", route, path+"?to=June", handlers, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Failure_DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(nil, nil).Once()
+		env.OrderStore.On("GetDriverDeliveryStats", orgID, june, july, 45).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path+"?from=2025-06-01&to=2025-06-30", handlers, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetDriverDeliveryInsights}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestGetDeliveryHeatmapHandler(t *testing.T) {
+	env := setupOrderEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/deliveries/heatmap"
+	path := "/" + orgID.String() + "/deliveries/heatmap"
+	handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetDeliveryHeatmap}
+
+	t.Run("Success_Defaults", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetDeliveryHeatmap", orgID, 0.01, database.DeliveryFilter{}).Return([]database.DeliveryHeatmapCell{
+			{Latitude: 30.05, Longitude: 31.24, Deliveries: 12},
+			{Latitude: 30.06, Longitude: 31.22, Deliveries: 3},
+		}, nil).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		body := w.Body.String()
+		assert.Contains(t, body, `"cell_size":0.01`)
+		assert.Contains(t, body, `"from":null`)
+		assert.Contains(t, body, `"deliveries":15`)
+		assert.Contains(t, body, `{"latitude":30.05,"longitude":31.24,"deliveries":12}`)
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("Success_Filtered", func(t *testing.T) {
+		env.ResetMocks()
+		june := time.Date(2025, 6, 1, 0, 0, 0, 0, time.Local)
+		july := june.AddDate(0, 0, 30)
+		driverID := uuid.New()
+		filter := database.DeliveryFilter{From: &june, To: &july, Statuses: []string{"delivered"}, DriverIDs: []uuid.UUID{driverID}}
+		env.OrderStore.On("GetDeliveryHeatmap", orgID, 0.005, filter).Return([]database.DeliveryHeatmapCell{}, nil).Once()
+
+		w := jobRequest("GET", route, path+"?cell_size=0.005&from=2025-06-01&to=2025-06-30&status=delivered&driver_id="+driverID.String(), handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		body := w.Body.String()
+		assert.Contains(t, body, `"from":"2025-06-01"`)
+		assert.Contains(t, body, `"to":"2025-06-30"`)
+		assert.Contains(t, body, `"cells":[]`)
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_InvalidCellSize", func(t *testing.T) {
+		env.ResetMocks()
+
+		for _, size := range []string{"0", "2", "small"} {
+			w := jobRequest("GET", route, path+"?cell_size="+size, handlers, nil)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, size)
+		}
+		env.OrderStore.AssertNotCalled(t, "GetDeliveryHeatmap", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failure_InvalidDate", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("GET", route, path+"?from=June", handlers, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Failure_DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetDeliveryHeatmap", orgID, 0.01, database.DeliveryFilter{}).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetDeliveryHeatmap}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+// --- Discount audit ---
+
+func TestAuditDiscounts(t *testing.T) {
+	// 10% of the sales of the organization are discounted
+	org := database.DiscountStats{Orders: 200, DiscountedOrders: 20, Sales: 5000, Discount: 500}
+
+	t.Run("RepeatedlyDiscountedCustomerFlagged", func(t *testing.T) {
+		regular := database.CustomerDiscounts{CustomerID: uuid.New(), DiscountStats: database.DiscountStats{Orders: 5, DiscountedOrders: 4, Sales: 100, Discount: 25}}
+		occasional := database.CustomerDiscounts{CustomerID: uuid.New(), DiscountStats: database.DiscountStats{Orders: 30, DiscountedOrders: 3, Sales: 600, Discount: 60}}
+
+		audit := api.AuditDiscounts(org, []database.CustomerDiscounts{regular, occasional}, nil)
+
+		assert.Equal(t, 10.0, audit.Organization.DiscountRate)
+		assert.Equal(t, 10.0, audit.Organization.DiscountedShare)
+		assert.Equal(t, 25.0, audit.Customers[0].DiscountRate)
+		assert.Equal(t, 80.0, audit.Customers[0].DiscountedShare)
+		assert.Equal(t, 2.5, audit.Customers[0].Lift)
+		assert.True(t, audit.Customers[0].Flagged)
+		// discounted as often as anyone else
+		assert.Equal(t, 1.0, audit.Customers[1].Lift)
+		assert.False(t, audit.Customers[1].Flagged)
+	})
+
+	t.Run("ShiftsWithConcentratedDiscountsFlagged", func(t *testing.T) {
+		late := database.ShiftDiscounts{EmployeeID: uuid.New(), FullName: "Sam", Shifts: 8, DiscountStats: database.DiscountStats{Orders: 40, DiscountedOrders: 12, Sales: 1000, Discount: 160}}
+		early := database.ShiftDiscounts{EmployeeID: uuid.New(), FullName: "Ada", Shifts: 8, DiscountStats: database.DiscountStats{Orders: 60, DiscountedOrders: 4, Sales: 1500, Discount: 90}}
+		// too few orders to tell
+		newcomer := database.ShiftDiscounts{EmployeeID: uuid.New(), FullName: "Lee", Shifts: 1, DiscountStats: database.DiscountStats{Orders: 5, DiscountedOrders: 3, Sales: 100, Discount: 40}}
+
+		audit := api.AuditDiscounts(org, nil, []database.ShiftDiscounts{late, early, newcomer})
+
+		assert.Equal(t, 1.6, audit.Employees[0].Lift)
+		assert.True(t, audit.Employees[0].Flagged)
+		assert.False(t, audit.Employees[1].Flagged)
+		assert.Equal(t, 4.0, audit.Employees[2].Lift)
+		assert.False(t, audit.Employees[2].Flagged)
+	})
+
+	t.Run("NoDiscounts", func(t *testing.T) {
+		audit := api.AuditDiscounts(database.DiscountStats{}, nil, nil)
+		assert.Empty(t, audit.Customers)
+		assert.NotNil(t, audit.Employees)
+		assert.Equal(t, 0.0, audit.Organization.DiscountRate)
+	})
+}
+
+func TestGetDiscountAuditHandler(t *testing.T) {
+	env := setupOrderEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	route := "/:org/orders/discounts/audit"
+	path := "/" + orgID.String() + "/orders/discounts/audit"
+	handlers := []gin.HandlerFunc{authMiddleware(admin), env.Handler.GetDiscountAudit}
+	june := time.Date(2025, 6, 1, 0, 0, 0, 0, time.Local)
+	july := june.AddDate(0, 0, 30)
+
+	t.Run("Success_Period", func(t *testing.T) {
+		env.ResetMocks()
+		customerID := uuid.New()
+		env.OrderStore.On("GetDiscountStats", orgID, june, july).Return(&database.DiscountStats{Orders: 100, DiscountedOrders: 10, Sales: 2000, Discount: 100}, nil).Once()
+		env.OrderStore.On("GetCustomerDiscounts", orgID, june, july, 3).Return([]database.CustomerDiscounts{
+			{CustomerID: customerID, DiscountStats: database.DiscountStats{Orders: 4, DiscountedOrders: 4, Sales: 80, Discount: 20}},
+		}, nil).Once()
+		env.OrderStore.On("GetShiftDiscounts", orgID, june, july).Return([]database.ShiftDiscounts{}, nil).Once()
+
+		w := jobRequest("GET", route, path+"?from=2025-06-01&to=2025-06-30", handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		body := w.Body.String()
+		assert.Contains(t, body, `"from":"2025-06-01"`)
+		assert.Contains(t, body, `"to":"2025-06-30"`)
+		assert.Contains(t, body, `"customer_id":"`+customerID.String()+`"`)
+		assert.Contains(t, body, `"lift":5`)
+		assert.Contains(t, body, `"flagged":true`)
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("Success_DefaultsToLast30Days", func(t *testing.T) {
+		env.ResetMocks()
+		now := time.Now()
+		to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+		from := to.AddDate(0, 0, -30)
+		env.OrderStore.On("GetDiscountStats", orgID, from, to).Return(&database.DiscountStats{}, nil).Once()
+		env.OrderStore.On("GetCustomerDiscounts", orgID, from, to, 3).Return([]database.CustomerDiscounts{}, nil).Once()
+		env.OrderStore.On("GetShiftDiscounts", orgID, from, to).Return([]database.ShiftDiscounts{}, nil).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_InvalidDate", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("GET", route, path+"?from=June", handlers, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Failure_DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetDiscountStats", orgID, june, july).Return(&database.DiscountStats{}, nil).Once()
+		env.OrderStore.On("GetCustomerDiscounts", orgID, june, july, 3).Return([]database.CustomerDiscounts{}, nil).Once()
+		env.OrderStore.On("GetShiftDiscounts", orgID, june, july).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path+"?from=2025-06-01&to=2025-06-30", handlers, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetDiscountAudit}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+// --- Single order CRUD ---
+
+func TestCreateOrderHandler(t *testing.T) {
+	env := setupOrderEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	route := "/:org/orders"
+	path := "/" + orgID.String() + "/orders"
+	handlers := []gin.HandlerFunc{authMiddleware(admin), env.Handler.CreateOrderHandler}
+
+	orderID := uuid.New()
+	order := func() map[string]any {
+		return map[string]any{
+			"order_id": orderID, "user_id": uuid.New(), "create_time": "2025-06-01T12:00:00Z",
+			"order_type": "takeaway", "order_status": "completed", "total_amount": 20, "discount_amount": 2,
+			"items": []map[string]any{{"item_id": uuid.New(), "quantity": 2, "total_price": 20}},
+		}
+	}
+	allowRules := func() {
+		for _, dataset := range []string{"orders", "order_items", "deliveries"} {
+			env.RuleStore.On("GetRulesForDataset", orgID, dataset).Return([]database.IngestionRule{}, nil).Once()
+		}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		allowRules()
+		body := order()
+		body["redemption_code"] = "SUMMER10"
+		env.OrderStore.On("StoreNestedOrder", orgID, mock.MatchedBy(func(o *database.Order) bool {
+			return o.OrderID == orderID && len(o.OrderItems) == 1
+		})).Return(nil).Once()
+		env.CampaignStore.On("RecordRedemption", orgID, "SUMMER10", orderID, time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)).Return(nil).Once()
+
+		w := jobRequest("POST", route, path, handlers, body)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), orderID.String())
+		env.OrderStore.AssertExpectations(t)
+		env.CampaignStore.AssertExpectations(t)
+	})
+
+	t.Run("InvalidOrder", func(t *testing.T) {
+		env.ResetMocks()
+		body := order()
+		body["order_type"] = "drive-through"
+
+		w := jobRequest("POST", route, path, handlers, body)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "order_type must be one of")
+		env.OrderStore.AssertNotCalled(t, "StoreNestedOrder", mock.Anything, mock.Anything)
+	})
+
+	t.Run("BreaksRule", func(t *testing.T) {
+		env.ResetMocks()
+		env.RuleStore.On("GetRulesForDataset", orgID, "orders").Return([]database.IngestionRule{
+			{ID: uuid.New(), Dataset: "orders", Field: "total_amount", Operator: "max", Value: "10"},
+		}, nil).Once()
+		env.RuleStore.On("GetRulesForDataset", orgID, mock.Anything).Return([]database.IngestionRule{}, nil)
+
+		w := jobRequest("POST", route, path, handlers, order())
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "total_amount must be at most 10")
+		env.OrderStore.AssertNotCalled(t, "StoreNestedOrder", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Duplicate", func(t *testing.T) {
+		env.ResetMocks()
+		allowRules()
+		env.OrderStore.On("StoreNestedOrder", orgID, mock.Anything).Return(database.ErrDuplicateOrder).Once()
+
+		w := jobRequest("POST", route, path, handlers, order())
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.CreateOrderHandler}, order())
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestGetOrderHandler(t *testing.T) {
+	env := setupOrderEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	orderID := uuid.New()
+	route := "/:org/orders/:id"
+	handlers := []gin.HandlerFunc{authMiddleware(admin), env.Handler.GetOrderHandler}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetOrder", orgID, orderID).Return(&database.Order{OrderID: orderID, OrderType: "takeaway"}, nil).Once()
+
+		w := jobRequest("GET", route, "/"+orgID.String()+"/orders/"+orderID.String(), handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), orderID.String())
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetOrder", orgID, orderID).Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("GET", route, "/"+orgID.String()+"/orders/"+orderID.String(), handlers, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("GET", route, "/"+orgID.String()+"/orders/not-a-uuid", handlers, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestUpdateOrderHandler(t *testing.T) {
+	env := setupOrderEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	orderID := uuid.New()
+	route := "/:org/orders/:id"
+	path := "/" + orgID.String() + "/orders/" + orderID.String()
+	handlers := []gin.HandlerFunc{authMiddleware(admin), env.Handler.UpdateOrderHandler}
+
+	stored := func() *database.Order {
+		total, discount := 20.0, 2.0
+		return &database.Order{OrderID: orderID, UserID: uuid.New(), CreateTime: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+			OrderType: "delivery", OrderStatus: "completed", TotalAmount: &total, DiscountAmount: &discount, Channel: database.OrderChannelDirect,
+			DeliveryStatus: &database.OrderDelivery{OrderID: orderID, DeliveryStatus: "delivered"}}
+	}
+
+	t.Run("Success_ChangesOnlySentFields", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetOrder", orgID, orderID).Return(stored(), nil).Once()
+		env.RuleStore.On("GetRulesForDataset", orgID, "orders").Return([]database.IngestionRule{}, nil).Once()
+		env.OrderStore.On("UpdateOrder", orgID, mock.MatchedBy(func(o *database.Order) bool {
+			return o.OrderStatus == "incompleted" && *o.TotalAmount == 18 && *o.DiscountAmount == 2 && o.OrderType == "delivery"
+		})).Return(nil).Once()
+
+		w := jobRequest("PATCH", route, path, handlers, map[string]any{"order_status": "incompleted", "total_amount": 18})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("DiscountOverTotal", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetOrder", orgID, orderID).Return(stored(), nil).Once()
+
+		w := jobRequest("PATCH", route, path, handlers, map[string]any{"total_amount": 1})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "discount_amount cannot exceed total_amount")
+		env.OrderStore.AssertNotCalled(t, "UpdateOrder", mock.Anything, mock.Anything)
+	})
+
+	t.Run("DeliveryOrderKeepsItsType", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetOrder", orgID, orderID).Return(stored(), nil).Once()
+
+		w := jobRequest("PATCH", route, path, handlers, map[string]any{"order_type": "takeaway"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "only delivery orders can have a delivery")
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("GetOrder", orgID, orderID).Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("PATCH", route, path, handlers, map[string]any{"order_status": "completed"})
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestDeleteOrderHandler(t *testing.T) {
+	env := setupOrderEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	orderID := uuid.New()
+	route := "/:org/orders/:id"
+	path := "/" + orgID.String() + "/orders/" + orderID.String()
+	handlers := []gin.HandlerFunc{authMiddleware(admin), env.Handler.DeleteOrderHandler}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("DeleteOrder", orgID, orderID).Return(nil).Once()
+
+		w := jobRequest("DELETE", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("DeleteOrder", orgID, orderID).Return(sql.ErrNoRows).Once()
+
+		w := jobRequest("DELETE", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("DELETE", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.DeleteOrderHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		env.OrderStore.AssertNotCalled(t, "DeleteOrder", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTransitionOrderStatusHandler(t *testing.T) {
+	env := setupOrderEnv()
+	orgID := uuid.New()
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	orderID := uuid.New()
+	route := "/:org/orders/:id/status"
+	path := "/" + orgID.String() + "/orders/" + orderID.String() + "/status"
+
+	t.Run("EmployeeAdvancesOrder", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("TransitionOrderStatus", orgID, orderID, "ready", &employee.ID, (*string)(nil)).
+			Return(&database.OrderStatusChange{ID: uuid.New(), OrderID: orderID, FromStatus: "preparing", ToStatus: "ready", ChangedBy: &employee.ID}, nil).Once()
+
+		w := jobRequest("PATCH", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.TransitionOrderStatusHandler}, map[string]any{"status": "ready"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"from_status":"preparing"`)
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("ManagerCancelsWithNote", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("TransitionOrderStatus", orgID, orderID, "cancelled", &manager.ID, mock.MatchedBy(func(note *string) bool {
+			return note != nil && *note == "Customer left"
+		})).Return(&database.OrderStatusChange{ID: uuid.New(), OrderID: orderID, FromStatus: "pending", ToStatus: "cancelled"}, nil).Once()
+
+		w := jobRequest("PATCH", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.TransitionOrderStatusHandler},
+			map[string]any{"status": "cancelled", "note": "Customer left"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.OrderStore.AssertExpectations(t)
+	})
+
+	t.Run("EmployeeCannotCancel", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("PATCH", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.TransitionOrderStatusHandler}, map[string]any{"status": "cancelled"})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		env.OrderStore.AssertNotCalled(t, "TransitionOrderStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("UnknownStatus", func(t *testing.T) {
+		env.ResetMocks()
+		for _, status := range []string{"delivered", "incompleted"} {
+			w := jobRequest("PATCH", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.TransitionOrderStatusHandler}, map[string]any{"status": status})
+			assert.Equal(t, http.StatusBadRequest, w.Code, status)
+		}
+		env.OrderStore.AssertNotCalled(t, "TransitionOrderStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("TransitionNotAllowed", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("TransitionOrderStatus", orgID, orderID, "completed", &manager.ID, (*string)(nil)).
+			Return(nil, &database.OrderTransitionError{From: "pending", To: "completed"}).Once()
+
+		w := jobRequest("PATCH", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.TransitionOrderStatusHandler}, map[string]any{"status": "completed"})
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), `"allowed":["preparing","cancelled"]`)
+	})
+
+	t.Run("FinalStatus", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("TransitionOrderStatus", orgID, orderID, "preparing", &manager.ID, (*string)(nil)).
+			Return(nil, &database.OrderTransitionError{From: "completed", To: "preparing"}).Once()
+
+		w := jobRequest("PATCH", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.TransitionOrderStatusHandler}, map[string]any{"status": "preparing"})
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), `"allowed":[]`)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrderStore.On("TransitionOrderStatus", orgID, orderID, "preparing", &manager.ID, (*string)(nil)).Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("PATCH", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.TransitionOrderStatusHandler}, map[string]any{"status": "preparing"})
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestGetOrderStatusHistoryHandler(t *testing.T) {
+	env := setupOrderEnv()
+	orgID := uuid.New()
+	orderID := uuid.New()
+	route := "/:org/orders/:id/status-history"
+	path := "/" + orgID.String() + "/orders/" + orderID.String() + "/status-history"
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+		env.OrderStore.On("GetOrderStatusHistory", orgID, orderID).Return([]database.OrderStatusChange{
+			{ID: uuid.New(), OrderID: orderID, FromStatus: "pending", ToStatus: "preparing", ChangedByName: "Sam Cook"},
+		}, nil).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.GetOrderStatusHistoryHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"changed_by_name":"Sam Cook"`)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetOrderStatusHistoryHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -2,13 +2,17 @@ package api
 
 import (
 	"bytes"
+	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/clockwise/clockwise/backend/internal/api"
 	"github.com/clockwise/clockwise/backend/internal/database"
@@ -160,6 +164,41 @@ func TestDelegateUser(t *testing.T) {
 		assert.Equal(t, http.StatusCreated, w.Code)
 	})
 
+	t.Run("Success_WithTraining", func(t *testing.T) {
+		trainings := new(MockTrainingStore)
+		env.Handler.Trainings = trainings
+		defer func() { env.Handler.Trainings = nil }()
+		days := 14
+		reqBody := api.DelegateUserRequest{
+			FullName:      "New Hire",
+			Email:         "hire@test.com",
+			Role:          "employee",
+			SalaryPerHour: &salary,
+			TrainingDays:  &days,
+		}
+
+		org := &database.Organization{ID: orgID, Name: "Clockwise"}
+
+		env.OrgStore.On("GetOrganizationByID", orgID).Return(org, nil).Once()
+		env.UserStore.On("CreateUser", mock.Anything).Return(nil).Once()
+		env.EmailService.On("SendWelcomeEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+		trainings.On("StartTraining", mock.MatchedBy(func(training *database.EmployeeTraining) bool {
+			return training.OrganizationID == orgID && training.EndsOn.Equal(training.StartsOn.AddDate(0, 0, 14)) && *training.StartedBy == adminUser.ID
+		})).Return(nil).Once()
+
+		jsonBytes, _ := json.Marshal(reqBody)
+		r := gin.New()
+		r.POST("/:org/staffing", authMiddleware(adminUser), env.Handler.DelegateUser)
+
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/staffing", bytes.NewBuffer(jsonBytes))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), "training_ends_on")
+		trainings.AssertExpectations(t)
+	})
+
 	t.Run("Failure_ForbiddenForStaff", func(t *testing.T) {
 		staffUser := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "staff"}
 
@@ -242,3 +281,283 @@ func TestGetOrganizationProfile(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
 	})
 }
+
+func TestSetCustomDomain(t *testing.T) {
+	env := setupOrgEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+
+	send := func(user *database.User, body string) *httptest.ResponseRecorder {
+		r := gin.New()
+		r.PUT("/:org/custom-domain", authMiddleware(user), env.Handler.SetCustomDomainHandler)
+		req, _ := http.NewRequest("PUT", "/"+orgID.String()+"/custom-domain", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.OrgStore.On("GetCustomDomain", orgID).Return(nil, sql.ErrNoRows).Once()
+		env.OrgStore.On("GetOrgIDByCustomDomain", "shifts.example.com").Return(uuid.Nil, sql.ErrNoRows).Once()
+		env.OrgStore.On("SetCustomDomain", orgID, mock.MatchedBy(func(d *string) bool { return d != nil && *d == "shifts.example.com" }),
+			mock.MatchedBy(func(token *string) bool { return token != nil && len(*token) > len("clockwise-verify=") })).Return(nil).Once()
+
+		w := send(admin, `{"domain": "Shifts.Example.com"}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data struct {
+				Domain    string `json:"domain"`
+				Verified  bool   `json:"verified"`
+				TXTRecord struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"txt_record"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "shifts.example.com", resp.Data.Domain)
+		assert.False(t, resp.Data.Verified, "a new domain is pending until its TXT record is found")
+		assert.Equal(t, "_clockwise-challenge.shifts.example.com", resp.Data.TXTRecord.Name)
+		assert.Contains(t, resp.Data.TXTRecord.Value, "clockwise-verify=")
+	})
+
+	t.Run("SameDomainKeepsVerification", func(t *testing.T) {
+		verifiedAt := time.Now()
+		current := &database.CustomDomain{Domain: "shifts.example.com", Token: "clockwise-verify=abc", VerifiedAt: &verifiedAt}
+		env.OrgStore.On("GetCustomDomain", orgID).Return(current, nil).Once()
+
+		w := send(admin, `{"domain": "shifts.example.com"}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"verified":true`)
+	})
+
+	t.Run("RemoveDomain", func(t *testing.T) {
+		env.OrgStore.On("SetCustomDomain", orgID, (*string)(nil), (*string)(nil)).Return(nil).Once()
+
+		w := send(admin, `{"domain": ""}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		w := send(manager, `{"domain": "shifts.example.com"}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("InvalidDomain", func(t *testing.T) {
+		w := send(admin, `{"domain": "https://shifts.example.com/app"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("DomainVerifiedByAnotherOrganization", func(t *testing.T) {
+		env.OrgStore.On("GetCustomDomain", orgID).Return(nil, sql.ErrNoRows).Once()
+		env.OrgStore.On("GetOrgIDByCustomDomain", "taken.example.com").Return(uuid.New(), nil).Once()
+
+		w := send(admin, `{"domain": "taken.example.com"}`)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("StoreError", func(t *testing.T) {
+		env.OrgStore.On("GetCustomDomain", orgID).Return(nil, sql.ErrNoRows).Once()
+		env.OrgStore.On("GetOrgIDByCustomDomain", "fail.example.com").Return(uuid.Nil, sql.ErrNoRows).Once()
+		env.OrgStore.On("SetCustomDomain", orgID, mock.Anything, mock.Anything).Return(errors.New("db error")).Once()
+
+		w := send(admin, `{"domain": "fail.example.com"}`)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestVerifyCustomDomain(t *testing.T) {
+	env := setupOrgEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	pending := func() *database.CustomDomain {
+		return &database.CustomDomain{Domain: "shifts.example.com:8443", Token: "clockwise-verify=abc"}
+	}
+	records := func(values ...string) func(ctx context.Context, name string) ([]string, error) {
+		return func(ctx context.Context, name string) ([]string, error) {
+			assert.Equal(t, "_clockwise-challenge.shifts.example.com", name)
+			return values, nil
+		}
+	}
+
+	send := func(user *database.User) *httptest.ResponseRecorder {
+		r := gin.New()
+		r.POST("/:org/custom-domain/verify", authMiddleware(user), env.Handler.VerifyCustomDomainHandler)
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/custom-domain/verify", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.Handler.LookupTXT = records("v=spf1 -all", "clockwise-verify=abc")
+		env.OrgStore.On("GetCustomDomain", orgID).Return(pending(), nil).Once()
+		env.OrgStore.On("VerifyCustomDomain", orgID).Return(nil).Once()
+
+		w := send(admin)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"verified":true`)
+	})
+
+	t.Run("RecordMissing", func(t *testing.T) {
+		env.Handler.LookupTXT = records("clockwise-verify=other")
+		env.OrgStore.On("GetCustomDomain", orgID).Return(pending(), nil).Once()
+
+		w := send(admin)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("LookupError", func(t *testing.T) {
+		env.Handler.LookupTXT = func(ctx context.Context, name string) ([]string, error) {
+			return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+		}
+		env.OrgStore.On("GetCustomDomain", orgID).Return(pending(), nil).Once()
+
+		w := send(admin)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("VerifiedByAnotherOrganization", func(t *testing.T) {
+		env.Handler.LookupTXT = records("clockwise-verify=abc")
+		env.OrgStore.On("GetCustomDomain", orgID).Return(pending(), nil).Once()
+		env.OrgStore.On("VerifyCustomDomain", orgID).Return(database.ErrCustomDomainTaken).Once()
+
+		w := send(admin)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("NoDomain", func(t *testing.T) {
+		env.OrgStore.On("GetCustomDomain", orgID).Return(nil, sql.ErrNoRows).Once()
+
+		w := send(admin)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		w := send(manager)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestSetCurrencyHandler(t *testing.T) {
+	env := setupOrgEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+
+	send := func(user *database.User, body string) *httptest.ResponseRecorder {
+		r := gin.New()
+		r.PUT("/:org/currency", authMiddleware(user), env.Handler.SetCurrencyHandler)
+		req, _ := http.NewRequest("PUT", "/"+orgID.String()+"/currency", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.OrgStore.On("SetCurrency", orgID, "EUR").Return(nil).Once()
+
+		w := send(admin, `{"currency": " eur "}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"currency":"EUR"`)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		w := send(manager, `{"currency": "EUR"}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("InvalidCurrency", func(t *testing.T) {
+		for _, body := range []string{`{"currency": "EURO"}`, `{"currency": "€"}`, `{}`} {
+			w := send(admin, body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+
+	t.Run("StoreError", func(t *testing.T) {
+		env.OrgStore.On("SetCurrency", orgID, "GBP").Return(errors.New("db error")).Once()
+
+		w := send(admin, `{"currency": "GBP"}`)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestCloneOrganizationHandler(t *testing.T) {
+	env := setupOrgEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+
+	send := func(user *database.User, body string) *httptest.ResponseRecorder {
+		r := gin.New()
+		r.POST("/:org/clone", authMiddleware(user), env.Handler.CloneOrganizationHandler)
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/clone", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	branch := func(org *database.Organization) bool {
+		return org.Name == "Downtown" && org.Email == "downtown@example.com" && org.Phone == "+15550100"
+	}
+
+	t.Run("CopiesEverythingByDefault", func(t *testing.T) {
+		all := database.OrgCloneOptions{Rules: true, Roles: true, OperatingHours: true, Items: true, Campaigns: true}
+		env.OrgStore.On("CloneOrganization", orgID, mock.MatchedBy(branch), admin.ID, all).
+			Return(&database.OrgClone{Organization: &database.Organization{ID: uuid.New(), Name: "Downtown"}, Rules: true, Roles: 5, Items: 40}, nil).Once()
+
+		w := send(admin, `{"name": " Downtown ", "email": "downtown@example.com", "phone": "+15550100"}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"items":40`)
+	})
+
+	t.Run("SelectedIncludes", func(t *testing.T) {
+		env.OrgStore.On("CloneOrganization", orgID, mock.MatchedBy(branch), admin.ID, database.OrgCloneOptions{Roles: true, Items: true}).
+			Return(&database.OrgClone{Organization: &database.Organization{ID: uuid.New()}}, nil).Once()
+
+		w := send(admin, `{"name": "Downtown", "email": "downtown@example.com", "phone": "+15550100", "include": ["roles", "items"]}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("InvalidRequest", func(t *testing.T) {
+		for _, body := range []string{
+			`{"email": "downtown@example.com", "phone": "+15550100"}`,
+			`{"name": "Downtown", "email": "downtown@example.com", "phone": "+15550100", "include": ["orders"]}`,
+			`{"name": "Downtown", "email": "downtown@example.com", "phone": "+15550100", "latitude": 40.7}`,
+		} {
+			w := send(admin, body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+
+	t.Run("NameTaken", func(t *testing.T) {
+		env.OrgStore.On("CloneOrganization", orgID, mock.Anything, admin.ID, mock.Anything).Return(nil, database.ErrOrganizationExists).Once()
+
+		w := send(admin, `{"name": "Downtown", "email": "downtown@example.com", "phone": "+15550100"}`)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		w := send(manager, `{"name": "Downtown", "email": "downtown@example.com", "phone": "+15550100"}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -0,0 +1,222 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type OrgRatingTestEnv struct {
+	Store   *MockOrgRatingStore
+	Handler *api.OrgRatingHandler
+}
+
+func setupOrgRatingEnv() *OrgRatingTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	store := new(MockOrgRatingStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &OrgRatingTestEnv{
+		Store:   store,
+		Handler: api.NewOrgRatingHandler(store, logger),
+	}
+}
+
+func (env *OrgRatingTestEnv) ResetMocks() {
+	env.Store.ExpectedCalls = nil
+	env.Store.Calls = nil
+}
+
+func orgRating(computedAt time.Time, rating float64) database.OrganizationRating {
+	return database.OrganizationRating{
+		ID:           uuid.New(),
+		Rating:       rating,
+		RatedOrders:  40,
+		WindowDays:   90,
+		HalfLifeDays: 30,
+		ComputedAt:   computedAt,
+	}
+}
+
+func TestWeightedRating(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	halfLife := 30 * 24 * time.Hour
+
+	t.Run("RecentRatingsWeighMore", func(t *testing.T) {
+		rating, ok := service.WeightedRating([]database.OrderRating{
+			{Rating: 2, CreateTime: now.AddDate(0, 0, -60)}, // weighs a quarter
+			{Rating: 5, CreateTime: now},
+		}, now, halfLife)
+
+		assert.True(t, ok)
+		assert.Equal(t, 4.4, rating)
+	})
+
+	t.Run("SameAgeIsPlainAverage", func(t *testing.T) {
+		rating, ok := service.WeightedRating([]database.OrderRating{
+			{Rating: 3, CreateTime: now.AddDate(0, 0, -10)},
+			{Rating: 4, CreateTime: now.AddDate(0, 0, -10)},
+			{Rating: 4, CreateTime: now.AddDate(0, 0, -10)},
+		}, now, halfLife)
+
+		assert.True(t, ok)
+		assert.Equal(t, 3.67, rating)
+	})
+
+	t.Run("NoRatings", func(t *testing.T) {
+		_, ok := service.WeightedRating(nil, now, halfLife)
+		assert.False(t, ok)
+	})
+}
+
+func TestRecomputeRatings(t *testing.T) {
+	store := new(MockOrgRatingStore)
+	ratingService := &service.OrgRatingService{
+		Store:        store,
+		Logger:       slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		WindowDays:   90,
+		HalfLifeDays: 30,
+	}
+	now := time.Date(2026, 10, 15, 3, 0, 0, 0, time.UTC)
+	since := now.AddDate(0, 0, -90)
+	rated, failing, empty := uuid.New(), uuid.New(), uuid.New()
+
+	store.On("GetRatedOrganizations", since).Return([]uuid.UUID{rated, failing, empty}, nil).Once()
+	store.On("GetOrderRatings", rated, since).Return([]database.OrderRating{{Rating: 4.5, CreateTime: now}}, nil).Once()
+	store.On("GetOrderRatings", failing, since).Return(nil, errors.New("db error")).Once()
+	store.On("GetOrderRatings", empty, since).Return([]database.OrderRating{}, nil).Once()
+	store.On("StoreRating", mock.MatchedBy(func(rating *database.OrganizationRating) bool {
+		return rating.OrganizationID == rated && rating.Rating == 4.5 && rating.RatedOrders == 1 &&
+			rating.WindowDays == 90 && rating.HalfLifeDays == 30 && rating.ComputedAt.Equal(now)
+	})).Return(nil).Once()
+
+	count, err := ratingService.RecomputeRatings(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	store.AssertExpectations(t)
+}
+
+func TestSummarizeRatingTrend(t *testing.T) {
+	day := time.Date(2026, 9, 1, 3, 0, 0, 0, time.Local)
+
+	t.Run("Up", func(t *testing.T) {
+		trend := api.SummarizeRatingTrend([]database.OrganizationRating{
+			orgRating(day, 4.1), orgRating(day.AddDate(0, 0, 1), 3.9), orgRating(day.AddDate(0, 0, 2), 4.3),
+		})
+
+		assert.Equal(t, api.RatingTrendUp, trend.Direction)
+		assert.Equal(t, 4.3, *trend.Current)
+		assert.Equal(t, 0.2, *trend.Change)
+		assert.Equal(t, 3.9, *trend.Lowest)
+		assert.Equal(t, 4.3, *trend.Highest)
+	})
+
+	t.Run("Down", func(t *testing.T) {
+		trend := api.SummarizeRatingTrend([]database.OrganizationRating{orgRating(day, 4.5), orgRating(day.AddDate(0, 0, 1), 4.4)})
+		assert.Equal(t, api.RatingTrendDown, trend.Direction)
+	})
+
+	t.Run("FlatWithinThreshold", func(t *testing.T) {
+		trend := api.SummarizeRatingTrend([]database.OrganizationRating{orgRating(day, 4.2), orgRating(day.AddDate(0, 0, 1), 4.22)})
+		assert.Equal(t, api.RatingTrendFlat, trend.Direction)
+	})
+
+	t.Run("NoRatings", func(t *testing.T) {
+		trend := api.SummarizeRatingTrend(nil)
+		assert.Equal(t, api.RatingTrendFlat, trend.Direction)
+		assert.Nil(t, trend.Current)
+		assert.Nil(t, trend.Change)
+	})
+}
+
+func TestGetRatingTrendHandler(t *testing.T) {
+	env := setupOrgRatingEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/rating/trend"
+	path := "/" + orgID.String() + "/rating/trend"
+	handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetRatingTrendHandler}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.Local)
+		to := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+		ratings := []database.OrganizationRating{orgRating(from.Add(3*time.Hour), 4.0), orgRating(from.AddDate(0, 0, 20), 4.25)}
+		env.Store.On("ListRatings", orgID, from, to).Return(ratings, nil).Once()
+
+		w := jobRequest("GET", route, path+"?from=2026-09-01&to=2026-09-30", handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data struct {
+				From    string                        `json:"from"`
+				To      string                        `json:"to"`
+				Trend   api.RatingTrend               `json:"trend"`
+				Ratings []database.OrganizationRating `json:"ratings"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "2026-09-01", resp.Data.From)
+		assert.Equal(t, "2026-09-30", resp.Data.To)
+		assert.Equal(t, api.RatingTrendUp, resp.Data.Trend.Direction)
+		assert.Equal(t, 4.25, *resp.Data.Trend.Current)
+		assert.Len(t, resp.Data.Ratings, 2)
+		env.Store.AssertExpectations(t)
+	})
+
+	t.Run("DefaultPeriod", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("ListRatings", orgID, mock.Anything, mock.Anything).Return([]database.OrganizationRating{}, nil).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		from := env.Store.Calls[0].Arguments.Get(1).(time.Time)
+		to := env.Store.Calls[0].Arguments.Get(2).(time.Time)
+		assert.Equal(t, 90, int(to.Sub(from).Round(time.Hour).Hours()/24))
+		assert.True(t, to.After(time.Now()))
+	})
+
+	t.Run("InvalidDates", func(t *testing.T) {
+		env.ResetMocks()
+
+		badTo := jobRequest("GET", route, path+"?to=today", handlers, nil)
+		reversed := jobRequest("GET", route, path+"?from=2026-09-10&to=2026-09-01", handlers, nil)
+
+		assert.Equal(t, http.StatusBadRequest, badTo.Code)
+		assert.Equal(t, http.StatusBadRequest, reversed.Code)
+		env.Store.AssertNotCalled(t, "ListRatings", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetRatingTrendHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("ListRatings", orgID, mock.Anything, mock.Anything).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,300 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/openpgp"
+)
+
+// scheduleChange is a change to a shift of the employee made notice hours before it starts. The shift
+// runs start-end after the change and previousStart-previousEnd before it, left empty for created shifts.
+func scheduleChange(employeeID uuid.UUID, eventType, start, end, previousStart, previousEnd string, notice float64) database.ScheduleChange {
+	date := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+	hours := func(start, end string) float64 {
+		s, _ := time.Parse(time.TimeOnly, start)
+		e, _ := time.Parse(time.TimeOnly, end)
+		return e.Sub(s).Hours()
+	}
+	change := database.ScheduleChange{
+		ShiftID: uuid.New(), EventType: eventType, EmployeeID: employeeID, EmployeeName: "Ada Lovelace",
+		Date: date, StartTime: start, EndTime: end, ShiftType: database.ShiftWorking, Hours: hours(start, end), NoticeHours: notice,
+	}
+	if previousStart != "" {
+		previousHours := hours(previousStart, previousEnd)
+		change.PreviousDate = &date
+		change.PreviousStartTime = &previousStart
+		change.PreviousEndTime = &previousEnd
+		change.PreviousHours = &previousHours
+	}
+	return change
+}
+
+func TestAssessPredictabilityPay(t *testing.T) {
+	policy := service.PredictabilityPolicy{NoticeDays: 14, PayHours: 1, LostHoursPercent: 50}
+	ada, sam := uuid.New(), uuid.New()
+	salary := 20.0
+	salaries := map[uuid.UUID]*float64{ada: &salary, sam: nil}
+
+	t.Run("OwedPerChange", func(t *testing.T) {
+		report := service.AssessPredictabilityPay([]database.ScheduleChange{
+			scheduleChange(ada, database.ScheduleEventCreated, "09:00:00", "13:00:00", "", "", 48),
+			scheduleChange(ada, database.ScheduleEventEdited, "10:00:00", "18:00:00", "09:00:00", "17:00:00", 100),
+			scheduleChange(ada, database.ScheduleEventEdited, "09:00:00", "13:00:00", "09:00:00", "17:00:00", 30),
+			scheduleChange(sam, database.ScheduleEventCancelled, "09:00:00", "15:00:00", "09:00:00", "15:00:00", 12),
+		}, policy, salaries)
+
+		if assert.Len(t, report.Changes, 4) {
+			assert.Equal(t, service.PredictabilityAdded, report.Changes[0].Change)
+			assert.Equal(t, 1.0, report.Changes[0].OwedHours)
+			assert.Equal(t, 20.0, *report.Changes[0].OwedPay)
+			assert.Equal(t, service.PredictabilityChanged, report.Changes[1].Change)
+			assert.Equal(t, 1.0, report.Changes[1].OwedHours)
+			// 4 of the 8 hours cut, half of them owed
+			assert.Equal(t, service.PredictabilityReduced, report.Changes[2].Change)
+			assert.Equal(t, 4.0, report.Changes[2].HoursLost)
+			assert.Equal(t, 2.0, report.Changes[2].OwedHours)
+			assert.Equal(t, service.PredictabilityCancelled, report.Changes[3].Change)
+			assert.Equal(t, 3.0, report.Changes[3].OwedHours)
+			assert.Nil(t, report.Changes[3].OwedPay)
+		}
+		if assert.Len(t, report.Employees, 2) {
+			assert.Equal(t, 3, report.Employees[0].Changes)
+			assert.Equal(t, 4.0, report.Employees[0].OwedHours)
+			assert.Equal(t, 80.0, *report.Employees[0].OwedPay)
+			assert.Nil(t, report.Employees[1].OwedPay)
+		}
+		assert.Equal(t, 7.0, report.OwedHours)
+		assert.Equal(t, 80.0, report.OwedPay)
+		assert.Equal(t, 1, report.WithoutSalary)
+	})
+
+	t.Run("ChangesOwingNothing", func(t *testing.T) {
+		report := service.AssessPredictabilityPay([]database.ScheduleChange{
+			// made before the notice period
+			scheduleChange(ada, database.ScheduleEventCancelled, "09:00:00", "17:00:00", "09:00:00", "17:00:00", 14*24),
+			// traded with another employee
+			scheduleChange(ada, database.ScheduleEventSwapped, "09:00:00", "17:00:00", "09:00:00", "17:00:00", 5),
+			// a standby shift activated keeps its times
+			scheduleChange(ada, database.ScheduleEventEdited, "09:00:00", "17:00:00", "09:00:00", "17:00:00", 2),
+		}, policy, salaries)
+
+		assert.Empty(t, report.Changes)
+		assert.Empty(t, report.Employees)
+		assert.Equal(t, 0.0, report.OwedPay)
+	})
+}
+
+func TestGetPredictabilityPayHandler(t *testing.T) {
+	env := setupScheduleEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	route := "/:org/schedule/predictability-pay"
+	path := "/" + orgID.String() + "/schedule/predictability-pay"
+	noticeDays := 14
+	rules := &database.OrganizationRules{OrganizationID: orgID, PredictabilityNoticeDays: &noticeDays, PredictabilityPayHours: 1, PredictabilityLostHoursPercent: 50}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		salary := 20.0
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(rules, nil).Once()
+		env.ScheduleStore.On("GetScheduleChanges", orgID, mock.MatchedBy(func(from time.Time) bool {
+			return from.Format(time.DateOnly) == "2026-10-01"
+		}), mock.MatchedBy(func(to time.Time) bool {
+			return to.Format(time.DateOnly) == "2026-11-01"
+		})).Return([]database.ScheduleChange{
+			scheduleChange(admin.ID, database.ScheduleEventCancelled, "09:00:00", "17:00:00", "09:00:00", "17:00:00", 20),
+		}, nil).Once()
+		env.UserStore.On("GetUsersByOrganization", orgID).Return([]*database.User{{ID: admin.ID, SalaryPerHour: &salary}}, nil).Once()
+
+		w := jobRequest("GET", route, path+"?from=2026-10-01&to=2026-10-31", []gin.HandlerFunc{authMiddleware(admin), env.Handler.GetPredictabilityPayHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data service.PredictabilityPayReport `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, "2026-10-31", resp.Data.To.Format(time.DateOnly))
+		assert.Equal(t, 14, resp.Data.Policy.NoticeDays)
+		assert.Equal(t, 4.0, resp.Data.OwedHours)
+		assert.Equal(t, 80.0, resp.Data.OwedPay)
+		env.ScheduleStore.AssertExpectations(t)
+	})
+
+	t.Run("DefaultsToRecentWeeksAndNoticePeriod", func(t *testing.T) {
+		env.ResetMocks()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(rules, nil).Once()
+		env.ScheduleStore.On("GetScheduleChanges", orgID, mock.Anything, mock.Anything).Return([]database.ScheduleChange{}, nil).Once()
+		env.UserStore.On("GetUsersByOrganization", orgID).Return([]*database.User{}, nil).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.GetPredictabilityPayHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		from := env.ScheduleStore.Calls[0].Arguments.Get(1).(time.Time)
+		to := env.ScheduleStore.Calls[0].Arguments.Get(2).(time.Time)
+		assert.Equal(t, 28+14+1, int(to.Sub(from).Hours()/24+0.5))
+	})
+
+	t.Run("NotSubjectToPredictableScheduling", func(t *testing.T) {
+		env.ResetMocks()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(&database.OrganizationRules{OrganizationID: orgID}, nil).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.GetPredictabilityPayHandler}, nil)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		env.ScheduleStore.AssertNotCalled(t, "GetScheduleChanges", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetPredictabilityPayHandler}, nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(rules, nil).Once()
+		env.ScheduleStore.On("GetScheduleChanges", orgID, mock.Anything, mock.Anything).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.GetPredictabilityPayHandler}, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestSendComplianceReports(t *testing.T) {
+	scheduleStore := new(MockScheduleStore)
+	rulesStore := new(MockRulesStore)
+	userStore := new(MockUserStore)
+	orgStore := new(MockOrgStore)
+	emailService := new(MockEmailService)
+	reportService := &service.ComplianceReportService{
+		ScheduleStore: scheduleStore,
+		RulesStore:    rulesStore,
+		UserStore:     userStore,
+		OrgStore:      orgStore,
+		EmailService:  emailService,
+		Logger:        slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		SendHour:      8,
+	}
+	reset := func() {
+		for _, m := range []*mock.Mock{&scheduleStore.Mock, &rulesStore.Mock, &userStore.Mock, &orgStore.Mock, &emailService.Mock} {
+			m.ExpectedCalls = nil
+			m.Calls = nil
+		}
+	}
+
+	// Monday morning, the report covers the week of Monday October 12
+	monday := time.Date(2026, 10, 19, 8, 15, 0, 0, time.UTC)
+	weekStart := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	weekEnd := weekStart.AddDate(0, 0, 7)
+	orgID, ada := uuid.New(), uuid.New()
+	noticeDays := 14
+	rules := &database.OrganizationRules{OrganizationID: orgID, PredictabilityNoticeDays: &noticeDays, PredictabilityPayHours: 1, PredictabilityLostHoursPercent: 50}
+	salary := 20.0
+
+	expectReport := func() {
+		scheduleStore.On("GetOrganizationsForComplianceReport", weekStart).Return([]uuid.UUID{orgID}, nil).Once()
+		rulesStore.On("GetRulesByOrganizationID", orgID).Return(rules, nil).Once()
+		scheduleStore.On("GetScheduleChanges", orgID, weekStart, weekEnd).Return([]database.ScheduleChange{
+			scheduleChange(ada, database.ScheduleEventCancelled, "09:00:00", "17:00:00", "09:00:00", "17:00:00", 20),
+			scheduleChange(ada, database.ScheduleEventCreated, "18:00:00", "22:00:00", "", "", 30),
+		}, nil).Once()
+		userStore.On("GetUsersByOrganization", orgID).Return([]*database.User{{ID: ada, SalaryPerHour: &salary}}, nil).Once()
+		scheduleStore.On("GetShiftCancellations", orgID, mock.MatchedBy(func(filter database.CancellationFilter) bool {
+			return filter.From.Equal(weekStart) && filter.To.Equal(weekEnd) && filter.Late != nil && *filter.Late
+		})).Return([]database.ShiftCancellation{{ID: uuid.New(), Late: true}}, nil).Once()
+		orgStore.On("GetAdminEmailsByOrgID", orgID).Return([]string{"admin@example.com"}, nil).Once()
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		reset()
+		expectReport()
+		emailService.On("SendComplianceReportEmail", []string{"admin@example.com"}, "Oct 12, 2026", 1,
+			[]string{"Ada Lovelace: 2 changes, 5.00 hours ($100.00)"}, 5.0, 100.0).Return(nil).Once()
+		scheduleStore.On("MarkComplianceReportSent", orgID, weekStart).Return(nil).Once()
+
+		sent, err := reportService.SendReports(monday)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		emailService.AssertExpectations(t)
+		scheduleStore.AssertExpectations(t)
+	})
+
+	t.Run("EmailFails_NotMarked", func(t *testing.T) {
+		reset()
+		expectReport()
+		emailService.On("SendComplianceReportEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(errors.New("smtp down")).Once()
+
+		sent, err := reportService.SendReports(monday)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		scheduleStore.AssertNotCalled(t, "MarkComplianceReportSent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("EncryptedAttachment", func(t *testing.T) {
+		reset()
+		expectReport()
+		passphrase := "correct horse battery"
+		encryption := new(MockExportEncryptionStore)
+		encryption.On("GetExportEncryption", orgID).Return(&database.ExportEncryption{OrganizationID: orgID,
+			Mode: database.ExportEncryptionPassphrase, Passphrase: &passphrase}, nil).Once()
+		var attachment service.EmailAttachment
+		emailService.On("SendEncryptedComplianceReportEmail", []string{"admin@example.com"}, "Oct 12, 2026", 1, mock.AnythingOfType("service.EmailAttachment")).
+			Run(func(args mock.Arguments) { attachment = args.Get(3).(service.EmailAttachment) }).Return(nil).Once()
+		scheduleStore.On("MarkComplianceReportSent", orgID, weekStart).Return(nil).Once()
+		encrypted := *reportService
+		encrypted.Encryption = encryption
+
+		sent, err := encrypted.SendReports(monday)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		emailService.AssertNotCalled(t, "SendComplianceReportEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		assert.Equal(t, "compliance-report-2026-10-12.csv.gpg", attachment.FileName)
+		assert.NotContains(t, string(attachment.Data), "Ada Lovelace")
+
+		prompt := func([]openpgp.Key, bool) ([]byte, error) { return []byte(passphrase), nil }
+		message, err := openpgp.ReadMessage(bytes.NewReader(attachment.Data), nil, prompt, nil)
+		if assert.NoError(t, err) {
+			plaintext, _ := io.ReadAll(message.UnverifiedBody)
+			assert.Equal(t, "employee,changes,owed_hours,owed_pay\nAda Lovelace,2,5.00,100.00\ntotal,,5.00,100.00\n", string(plaintext))
+		}
+	})
+
+	t.Run("NotDue", func(t *testing.T) {
+		reset()
+		for _, now := range []time.Time{monday.Add(-time.Hour), monday.AddDate(0, 0, 1)} {
+			sent, err := reportService.SendReports(now)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, sent)
+		}
+		scheduleStore.AssertNotCalled(t, "GetOrganizationsForComplianceReport", mock.Anything)
+	})
+
+	t.Run("StoreError", func(t *testing.T) {
+		reset()
+		scheduleStore.On("GetOrganizationsForComplianceReport", weekStart).Return(nil, errors.New("db error")).Once()
+
+		_, err := reportService.SendReports(monday)
+
+		assert.Error(t, err)
+	})
+}
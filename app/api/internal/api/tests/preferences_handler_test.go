@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"log/slog"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/clockwise/clockwise/backend/internal/api"
 	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -24,6 +26,7 @@ type PreferencesTestEnv struct {
 	UserRolesStore   *MockUserRolesStore
 	UserStore        *MockUserStore
 	RolesStore       *MockRolesStore
+	UploadService    *MockUploadService
 	Handler          *api.PreferencesHandler
 }
 
@@ -34,9 +37,12 @@ func setupPreferencesEnv() *PreferencesTestEnv {
 	userRolesStore := new(MockUserRolesStore)
 	userStore := new(MockUserStore)
 	rolesStore := new(MockRolesStore)
+
+	uploadService := new(MockUploadService)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
 	handler := api.NewPreferencesHandler(prefStore, userRolesStore, userStore, rolesStore, logger)
+	handler.UploadService = uploadService
 
 	return &PreferencesTestEnv{
 		Router:           gin.New(),
@@ -44,6 +50,7 @@ func setupPreferencesEnv() *PreferencesTestEnv {
 		UserRolesStore:   userRolesStore,
 		UserStore:        userStore,
 		RolesStore:       rolesStore,
+		UploadService:    uploadService,
 		Handler:          handler,
 	}
 }
@@ -58,6 +65,8 @@ func (env *PreferencesTestEnv) ResetMocks() {
 	env.UserStore.Calls = nil
 	env.RolesStore.ExpectedCalls = nil
 	env.RolesStore.Calls = nil
+	env.UploadService.ExpectedCalls = nil
+	env.UploadService.Calls = nil
 }
 
 func TestGetCurrentEmployeePreferences(t *testing.T) {
@@ -72,7 +81,7 @@ func TestGetCurrentEmployeePreferences(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		env.ResetMocks()
 
-		prefs := []*database.EmployeePreference{
+		prefs := []database.EmployeePreference{
 			{EmployeeID: userID, Day: "Monday", PreferredStartTime: nil},
 		}
 		roles := []string{"Server"}
@@ -246,3 +255,188 @@ func TestUpdateCurrentEmployeePreferences(t *testing.T) {
 		assert.Contains(t, w.Body.String(), "Role does not exist")
 	})
 }
+
+func TestCopyPreferencesHandler(t *testing.T) {
+	env := setupPreferencesEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	template := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	newHire := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	members := []*database.User{manager, employee, template, newHire}
+
+	route := "/:org/preferences/copy"
+	path := "/" + orgID.String() + "/preferences/copy"
+	handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.CopyPreferencesHandler}
+	start, end := "09:00", "17:00"
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUsersByOrganization", orgID).Return(members, nil).Once()
+		env.PreferencesStore.On("GetPreferencesByEmployeeID", template.ID).Return([]database.EmployeePreference{
+			{EmployeeID: template.ID, Day: "monday", AvailableStartTime: &start, AvailableEndTime: &end},
+		}, nil).Once()
+		env.PreferencesStore.On("CopyPreferences", template.ID, []uuid.UUID{newHire.ID, employee.ID}).Return(nil).Once()
+
+		w := jobRequest(http.MethodPost, route, path, handlers, api.CopyPreferencesRequest{
+			FromEmployeeID: template.ID,
+			ToEmployeeIDs:  []uuid.UUID{newHire.ID, template.ID, employee.ID, newHire.ID},
+		})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"day_count":1`)
+		env.PreferencesStore.AssertExpectations(t)
+	})
+
+	t.Run("OtherOrganization", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUsersByOrganization", orgID).Return(members, nil).Once()
+
+		w := jobRequest(http.MethodPost, route, path, handlers, api.CopyPreferencesRequest{
+			FromEmployeeID: template.ID,
+			ToEmployeeIDs:  []uuid.UUID{uuid.New()},
+		})
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		env.PreferencesStore.AssertNotCalled(t, "CopyPreferences", mock.Anything, mock.Anything)
+	})
+
+	t.Run("EmptyTemplate", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUsersByOrganization", orgID).Return(members, nil).Once()
+		env.PreferencesStore.On("GetPreferencesByEmployeeID", template.ID).Return(nil, nil).Once()
+
+		w := jobRequest(http.MethodPost, route, path, handlers, api.CopyPreferencesRequest{
+			FromEmployeeID: template.ID,
+			ToEmployeeIDs:  []uuid.UUID{newHire.ID},
+		})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "no availability to copy")
+	})
+
+	t.Run("OnlyItself", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUsersByOrganization", orgID).Return(members, nil).Once()
+
+		w := jobRequest(http.MethodPost, route, path, handlers, api.CopyPreferencesRequest{
+			FromEmployeeID: template.ID,
+			ToEmployeeIDs:  []uuid.UUID{template.ID},
+		})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest(http.MethodPost, route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.CopyPreferencesHandler},
+			api.CopyPreferencesRequest{FromEmployeeID: template.ID, ToEmployeeIDs: []uuid.UUID{newHire.ID}})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestUploadPreferencesCSV(t *testing.T) {
+	env := setupPreferencesEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	first := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee", Email: "first@test.com"}
+	second := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee", Email: "second@test.com"}
+
+	env.Router.POST("/:org/preferences/upload", authMiddleware(admin), env.Handler.UploadPreferencesCSV)
+	headers := []string{"employee_email", "day", "available_from", "available_to", "preferred_from", "preferred_to"}
+
+	upload := func() *httptest.ResponseRecorder {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("file", "availability.csv")
+		part.Write([]byte("dummy content"))
+		writer.Close()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/preferences/upload", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		env.Router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success_PartialFailures", func(t *testing.T) {
+		env.ResetMocks()
+		env.UploadService.On("ParseCSV", mock.Anything).Return(&service.CSVData{
+			Headers: headers,
+			Rows: []map[string]string{
+				{"employee_email": "First@test.com", "day": "Monday", "available_from": "08:00", "available_to": "18:00", "preferred_from": "09:00", "preferred_to": "17:00"},
+				{"employee_email": "first@test.com", "day": "tuesday", "available_from": "08:00", "available_to": "18:00"},
+				{"employee_email": "second@test.com", "day": "monday"},
+				{"employee_email": "first@test.com", "day": "monday", "available_from": "08:00", "available_to": "18:00"},
+				{"employee_email": "unknown@test.com", "day": "monday"},
+				{"employee_email": "second@test.com", "day": "funday"},
+				{"employee_email": "second@test.com", "day": "friday", "available_from": "18:00", "available_to": "08:00"},
+				{"employee_email": "second@test.com", "day": "sunday", "preferred_from": "09:00"},
+			},
+		}, nil).Once()
+		env.UserStore.On("GetUsersByOrganization", orgID).Return([]*database.User{admin, first, second}, nil).Once()
+		env.PreferencesStore.On("UpsertPreferences", first.ID, mock.MatchedBy(func(prefs []database.EmployeePreference) bool {
+			return len(prefs) == 2 && prefs[0].Day == "monday" && *prefs[0].PreferredStartTime == "09:00" &&
+				prefs[1].Day == "tuesday" && prefs[1].PreferredStartTime == nil
+		})).Return(nil).Once()
+		env.PreferencesStore.On("UpsertPreferences", second.ID, mock.MatchedBy(func(prefs []database.EmployeePreference) bool {
+			return len(prefs) == 1 && prefs[0].AvailableStartTime == nil
+		})).Return(nil).Once()
+
+		w := upload()
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data struct {
+				EmployeeCount int                           `json:"employee_count"`
+				ImportedCount int                           `json:"imported_count"`
+				Failed        []api.PreferenceImportFailure `json:"failed"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 2, response.Data.EmployeeCount)
+		assert.Equal(t, 3, response.Data.ImportedCount)
+		if assert.Len(t, response.Data.Failed, 5) {
+			assert.Equal(t, 5, response.Data.Failed[0].Row)
+			assert.Contains(t, response.Data.Failed[0].Error, "Duplicate day")
+			assert.Contains(t, response.Data.Failed[1].Error, "No employee")
+			assert.Contains(t, response.Data.Failed[2].Error, "Invalid day")
+			assert.Contains(t, response.Data.Failed[3].Error, "must be before")
+			assert.Contains(t, response.Data.Failed[4].Error, "set together")
+		}
+		env.PreferencesStore.AssertExpectations(t)
+	})
+
+	t.Run("MissingHeader", func(t *testing.T) {
+		env.ResetMocks()
+		env.UploadService.On("ParseCSV", mock.Anything).Return(&service.CSVData{Headers: []string{"employee_email", "day"}}, nil).Once()
+
+		w := upload()
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Missing required header: available_from")
+	})
+
+	t.Run("TooManyRows", func(t *testing.T) {
+		env.ResetMocks()
+		env.UploadService.On("ParseCSV", mock.Anything).Return(nil, service.ErrTooManyRows).Once()
+
+		w := upload()
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		router := gin.New()
+		router.POST("/:org/preferences/upload", authMiddleware(first), env.Handler.UploadPreferencesCSV)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/preferences/upload", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -0,0 +1,272 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type PrepListTestEnv struct {
+	PrepListStore         *MockPrepListStore
+	ItemAvailabilityStore *MockItemAvailabilityStore
+	OrgStore              *MockOrgStore
+	BrandingStore         *MockBrandingStore
+	Handler               *api.PrepListHandler
+}
+
+func setupPrepListEnv() *PrepListTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	prepListStore := new(MockPrepListStore)
+	itemAvailabilityStore := new(MockItemAvailabilityStore)
+	orgStore := new(MockOrgStore)
+	brandingStore := new(MockBrandingStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &PrepListTestEnv{
+		PrepListStore:         prepListStore,
+		ItemAvailabilityStore: itemAvailabilityStore,
+		OrgStore:              orgStore,
+		BrandingStore:         brandingStore,
+		Handler:               api.NewPrepListHandler(prepListStore, itemAvailabilityStore, orgStore, brandingStore, logger),
+	}
+}
+
+func (env *PrepListTestEnv) ResetMocks() {
+	env.PrepListStore.ExpectedCalls = nil
+	env.PrepListStore.Calls = nil
+	env.ItemAvailabilityStore.ExpectedCalls = nil
+	env.ItemAvailabilityStore.Calls = nil
+	env.OrgStore.ExpectedCalls = nil
+	env.OrgStore.Calls = nil
+	env.BrandingStore.ExpectedCalls = nil
+	env.BrandingStore.Calls = nil
+}
+
+func TestBuildPrepList(t *testing.T) {
+	date := time.Date(2025, 3, 14, 0, 0, 0, 0, time.Local)
+	forecast := database.DayForecast{Date: date, OrderCount: 40, ItemCount: 100, Hours: 12}
+	burger := uuid.New()
+	salad := uuid.New()
+
+	t.Run("WeekdayMix", func(t *testing.T) {
+		sales := []database.ItemSales{
+			{ItemID: salad, Name: "Salad", UnitsOnWeekday: 25, UnitsAllDays: 200},
+			{ItemID: burger, Name: "Burger", UnitsOnWeekday: 75, UnitsAllDays: 200},
+		}
+
+		list := api.BuildPrepList(forecast, sales, 10)
+
+		assert.Equal(t, "2025-03-14", list.Date)
+		assert.Equal(t, "weekday", list.ItemMix)
+		assert.Len(t, list.Items, 2)
+		assert.Equal(t, burger, list.Items[0].ItemID) // largest quantity first
+		assert.Equal(t, 75.0, list.Items[0].SharePercent)
+		assert.Equal(t, 75.0, list.Items[0].ExpectedUnits)
+		assert.Equal(t, 83, list.Items[0].SuggestedQuantity) // 82.5 rounded up
+		assert.Equal(t, 28, list.Items[1].SuggestedQuantity) // 27.5 rounded up
+	})
+
+	t.Run("AllDaysMixWithoutWeekdaySales", func(t *testing.T) {
+		sales := []database.ItemSales{
+			{ItemID: salad, Name: "Salad", UnitsAllDays: 10},
+			{ItemID: burger, Name: "Burger", UnitsAllDays: 30},
+		}
+
+		list := api.BuildPrepList(forecast, sales, 0)
+
+		assert.Equal(t, "all_days", list.ItemMix)
+		assert.Equal(t, 75, list.Items[0].SuggestedQuantity)
+		assert.Equal(t, 25, list.Items[1].SuggestedQuantity)
+	})
+
+	t.Run("ItemsNotSoldOnWeekdayAreSkipped", func(t *testing.T) {
+		sales := []database.ItemSales{
+			{ItemID: salad, Name: "Salad", UnitsOnWeekday: 0, UnitsAllDays: 10},
+			{ItemID: burger, Name: "Burger", UnitsOnWeekday: 5, UnitsAllDays: 30},
+		}
+
+		list := api.BuildPrepList(forecast, sales, 0)
+
+		assert.Len(t, list.Items, 1)
+		assert.Equal(t, 100, list.Items[0].SuggestedQuantity)
+	})
+
+	t.Run("NoSales", func(t *testing.T) {
+		list := api.BuildPrepList(forecast, []database.ItemSales{}, 10)
+		assert.Empty(t, list.Items)
+	})
+}
+
+func TestGetPrepListHandler(t *testing.T) {
+	env := setupPrepListEnv()
+	orgID := uuid.New()
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	date := time.Date(2025, 3, 14, 0, 0, 0, 0, time.Local)
+	forecast := &database.DayForecast{Date: date, OrderCount: 40, ItemCount: 100, Hours: 12}
+	burger := uuid.New()
+	sales := []database.ItemSales{
+		{ItemID: burger, Name: "Burger", UnitsOnWeekday: 3, UnitsAllDays: 10},
+		{ItemID: uuid.New(), Name: "Fries (large)", UnitsOnWeekday: 1, UnitsAllDays: 10},
+	}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/:org/prep-list", authMiddleware(employee), env.Handler.GetPrepListHandler)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/prep-list"+query, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		env.ResetMocks()
+		env.PrepListStore.On("GetDayForecast", orgID, date).Return(forecast, nil)
+		env.PrepListStore.On("GetItemSales", orgID, time.Friday, date.AddDate(0, 0, -56)).Return(sales, nil)
+		env.ItemAvailabilityStore.On("GetAvailabilityWindows", orgID).Return([]database.ItemAvailabilityWindow{}, nil)
+
+		w := get("?date=2025-03-14&buffer=20")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data api.PrepList `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, 20, resp.Data.BufferPercent)
+		assert.Equal(t, 90, resp.Data.Items[0].SuggestedQuantity)
+		env.PrepListStore.AssertExpectations(t)
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		env.ResetMocks()
+		env.PrepListStore.On("GetDayForecast", orgID, date).Return(forecast, nil)
+		env.PrepListStore.On("GetItemSales", orgID, time.Friday, mock.Anything).Return(sales, nil)
+		env.ItemAvailabilityStore.On("GetAvailabilityWindows", orgID).Return([]database.ItemAvailabilityWindow{}, nil)
+
+		w := get("?date=2025-03-14&format=csv")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "prep-list-2025-03-14.csv")
+		records, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+		assert.NoError(t, err)
+		assert.Len(t, records, 3)
+		assert.Equal(t, []string{"item_id", "item", "share_percent", "expected_units", "suggested_quantity"}, records[0])
+		assert.Equal(t, "83", records[1][4])
+	})
+
+	t.Run("PDF", func(t *testing.T) {
+		env.ResetMocks()
+		env.PrepListStore.On("GetDayForecast", orgID, date).Return(forecast, nil)
+		env.PrepListStore.On("GetItemSales", orgID, time.Friday, mock.Anything).Return(sales, nil)
+		env.ItemAvailabilityStore.On("GetAvailabilityWindows", orgID).Return([]database.ItemAvailabilityWindow{}, nil)
+		env.OrgStore.On("GetOrganizationByID", orgID).Return(&database.Organization{ID: orgID, Name: "Burger Barn", HexCode1: "BF4124"}, nil)
+		env.BrandingStore.On("GetLogo", orgID).Return(nil, sql.ErrNoRows)
+
+		w := get("?date=2025-03-14&format=pdf")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+		body := w.Body.String()
+		assert.True(t, strings.HasPrefix(body, "%PDF-1.4"))
+		assert.True(t, strings.HasSuffix(body, "%%EOF\n"))
+		assert.Contains(t, body, `Fries \(large\)`)
+		assert.Contains(t, body, "(Burger Barn) Tj")
+		assert.Contains(t, body, "0.749 0.255 0.141 rg")
+		assert.NotContains(t, body, "/Im1")
+	})
+
+	t.Run("PDFWithLogo", func(t *testing.T) {
+		env.ResetMocks()
+		env.PrepListStore.On("GetDayForecast", orgID, date).Return(forecast, nil)
+		env.PrepListStore.On("GetItemSales", orgID, time.Friday, mock.Anything).Return(sales, nil)
+		env.ItemAvailabilityStore.On("GetAvailabilityWindows", orgID).Return([]database.ItemAvailabilityWindow{}, nil)
+		env.OrgStore.On("GetOrganizationByID", orgID).Return(&database.Organization{ID: orgID, Name: "Burger Barn", HexCode1: "BF4124"}, nil)
+		env.BrandingStore.On("GetLogo", orgID).Return(&database.OrganizationLogo{ContentType: "image/png", Data: testLogoPNG(t, 40, 20)}, nil)
+
+		w := get("?date=2025-03-14&format=pdf")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		body := w.Body.String()
+		assert.Contains(t, body, "/Subtype /Image /Width 40 /Height 20")
+		assert.Contains(t, body, "/Im1 Do")
+		assert.True(t, strings.HasSuffix(body, "%%EOF\n"))
+	})
+
+	t.Run("PDFWithoutBranding", func(t *testing.T) {
+		env.ResetMocks()
+		env.PrepListStore.On("GetDayForecast", orgID, date).Return(forecast, nil)
+		env.PrepListStore.On("GetItemSales", orgID, time.Friday, mock.Anything).Return(sales, nil)
+		env.ItemAvailabilityStore.On("GetAvailabilityWindows", orgID).Return([]database.ItemAvailabilityWindow{}, nil)
+		env.OrgStore.On("GetOrganizationByID", orgID).Return(nil, errors.New("db error"))
+
+		w := get("?date=2025-03-14&format=pdf")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `Fries \(large\)`)
+		assert.NotContains(t, w.Body.String(), " re f")
+	})
+
+	t.Run("UnavailableItemsExcluded", func(t *testing.T) {
+		env.ResetMocks()
+		summerStart, summerEnd := "2025-06-01", "2025-08-31"
+		env.PrepListStore.On("GetDayForecast", orgID, date).Return(forecast, nil)
+		env.PrepListStore.On("GetItemSales", orgID, time.Friday, mock.Anything).Return(sales, nil)
+		env.ItemAvailabilityStore.On("GetAvailabilityWindows", orgID).Return([]database.ItemAvailabilityWindow{
+			{ItemID: burger, StartDate: &summerStart, EndDate: &summerEnd},
+		}, nil)
+
+		w := get("?date=2025-03-14&buffer=0")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data api.PrepList `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Len(t, resp.Data.Items, 1)
+		assert.Equal(t, "Fries (large)", resp.Data.Items[0].Name)
+		assert.Equal(t, 100, resp.Data.Items[0].SuggestedQuantity)
+		env.ItemAvailabilityStore.AssertExpectations(t)
+	})
+
+	t.Run("NoForecast", func(t *testing.T) {
+		env.ResetMocks()
+		env.PrepListStore.On("GetDayForecast", orgID, date).Return(nil, sql.ErrNoRows)
+
+		w := get("?date=2025-03-14")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("InvalidParameters", func(t *testing.T) {
+		env.ResetMocks()
+		assert.Equal(t, http.StatusBadRequest, get("?date=14/03/2025").Code)
+		assert.Equal(t, http.StatusBadRequest, get("?buffer=150").Code)
+		assert.Equal(t, http.StatusBadRequest, get("?format=xlsx").Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.PrepListStore.On("GetDayForecast", orgID, date).Return(forecast, nil)
+		env.PrepListStore.On("GetItemSales", orgID, time.Friday, mock.Anything).Return(nil, errors.New("db error"))
+
+		w := get("?date=2025-03-14")
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSimulateEmployeeRemoval(t *testing.T) {
+	// Monday October 19, the shifts before 08:00 have started
+	monday := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.Local)
+	shift := func(employeeID uuid.UUID, day int, start, end, shiftType string) database.Shift {
+		date := monday.AddDate(0, 0, day)
+		return database.Shift{
+			ID: uuid.New(), EmployeeID: employeeID, Date: date, Day: database.ValidDays[date.Weekday()],
+			StartTime: start, EndTime: end, ShiftType: shiftType,
+		}
+	}
+	member := func(name, role string, roles ...string) api.StaffMember {
+		return api.StaffMember{User: &database.User{ID: uuid.New(), FullName: name, UserRole: role}, Roles: roles}
+	}
+
+	t.Run("ReassignsToFreeEmployees", func(t *testing.T) {
+		ada := member("ada", "employee", "cook")
+		ben := member("ben", "employee", "cook")
+		salary, maxHours := 20.0, 38
+		ben.User.SalaryPerHour = &salary
+		ben.User.MaxHoursPerWeek = &maxHours
+		cal := member("cal", "employee", "cook", "cashier")
+		dan := member("dan", "employee", "driver")
+		mia := member("mia", "manager", "cook")
+
+		started := shift(ada.User.ID, 0, "06:00:00", "10:00:00", database.ShiftWorking)
+		mon := shift(ada.User.ID, 0, "09:00:00", "17:00:00", database.ShiftWorking)
+		tue := shift(ada.User.ID, 1, "09:00:00", "17:00:00", database.ShiftWorking)
+		sat := shift(ada.User.ID, 5, "10:00:00", "14:00:00", database.ShiftWorking)
+		shifts := []database.Shift{
+			started, mon, tue, sat,
+			shift(ada.User.ID, 2, "09:00:00", "17:00:00", database.ShiftStandby),
+			// ben works 34 hours and cal 1 hour this week
+			shift(ben.User.ID, 0, "09:00:00", "13:00:00", database.ShiftWorking),
+			shift(ben.User.ID, 2, "08:00:00", "20:00:00", database.ShiftWorking),
+			shift(ben.User.ID, 3, "08:00:00", "20:00:00", database.ShiftWorking),
+			shift(ben.User.ID, 5, "09:00:00", "15:00:00", database.ShiftWorking),
+			shift(cal.User.ID, 1, "10:00:00", "12:00:00", database.ShiftStandby),
+			shift(cal.User.ID, 5, "10:00:00", "11:00:00", database.ShiftWorking),
+		}
+
+		impact := api.SimulateEmployeeRemoval(ada, []api.StaffMember{ada, ben, cal, dan, mia}, shifts, now, 40)
+
+		assert.Equal(t, ada.User.ID, impact.EmployeeID)
+		assert.Equal(t, 3, impact.RemovedShifts)
+		assert.Equal(t, 20.0, impact.RemovedHours)
+		// ben is busy on Monday and cal on standby on Tuesday
+		if assert.Len(t, impact.Reassigned, 2) {
+			assert.Equal(t, mon.ID, impact.Reassigned[0].ShiftID)
+			assert.Equal(t, &cal.User.ID, impact.Reassigned[0].CoveredBy)
+			assert.Equal(t, tue.ID, impact.Reassigned[1].ShiftID)
+			assert.Equal(t, "ben", impact.Reassigned[1].CoveredByName)
+		}
+		if assert.Len(t, impact.UncoveredSlots, 1) {
+			assert.Equal(t, sat.ID, impact.UncoveredSlots[0].ShiftID)
+			assert.Equal(t, "2026-10-24", impact.UncoveredSlots[0].Date)
+			assert.Nil(t, impact.UncoveredSlots[0].CoveredBy)
+		}
+		assert.Equal(t, 4.0, impact.UncoveredHours)
+
+		// ben goes from 34 to 42 hours, 4 above their maximum of 38
+		cost := 120.0
+		assert.Equal(t, []api.ExtraHours{
+			{EmployeeID: cal.User.ID, FullName: "cal", Shifts: 1, ExtraHours: 8},
+			{EmployeeID: ben.User.ID, FullName: "ben", Shifts: 1, ExtraHours: 8, OvertimeHours: 4, OvertimeCost: &cost},
+		}, impact.ExtraHours)
+		assert.Equal(t, 4.0, impact.OvertimeHours)
+		assert.Equal(t, 120.0, impact.OvertimeCost)
+		assert.True(t, impact.OvertimeCostComplete)
+	})
+
+	t.Run("OvertimeWithoutSalary", func(t *testing.T) {
+		ada := member("ada", "employee")
+		dan := member("dan", "employee", "driver")
+		shifts := []database.Shift{shift(ada.User.ID, 1, "09:00:00", "17:00:00", database.ShiftWorking)}
+
+		// without roles anyone can take over
+		impact := api.SimulateEmployeeRemoval(ada, []api.StaffMember{ada, dan}, shifts, now, 4)
+
+		assert.Len(t, impact.Reassigned, 1)
+		assert.Empty(t, impact.UncoveredSlots)
+		assert.Equal(t, []api.ExtraHours{
+			{EmployeeID: dan.User.ID, FullName: "dan", Shifts: 1, ExtraHours: 8, OvertimeHours: 4},
+		}, impact.ExtraHours)
+		assert.Equal(t, 0.0, impact.OvertimeCost)
+		assert.False(t, impact.OvertimeCostComplete)
+	})
+}
+
+func TestEmployeeImpactAnalysisHandler(t *testing.T) {
+	env := setupScheduleEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	ada := &database.User{ID: uuid.New(), OrganizationID: orgID, FullName: "ada", UserRole: "employee"}
+	ben := &database.User{ID: uuid.New(), OrganizationID: orgID, FullName: "ben", UserRole: "employee"}
+	route := "/:org/staffing/employees/:id/impact-analysis"
+	path := "/" + orgID.String() + "/staffing/employees/" + ada.ID.String() + "/impact-analysis"
+	handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.EmployeeImpactAnalysisHandler}
+
+	expectStaff := func() {
+		env.UserStore.On("GetUsersByOrganization", orgID).Return([]*database.User{manager, ada, ben}, nil).Once()
+		env.UserRolesStore.On("GetUserRoles", ada.ID, orgID).Return([]string{"cook"}, nil).Once()
+		env.UserRolesStore.On("GetUserRoles", ben.ID, orgID).Return([]string{"cook"}, nil).Once()
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		expectStaff()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(&database.OrganizationRules{MaxWeeklyHours: 40}, nil).Once()
+		nextWeek := time.Now().AddDate(0, 0, 7)
+		env.ScheduleStore.On("GetShiftsFrom", orgID, mock.AnythingOfType("time.Time")).Return([]database.Shift{
+			{ID: uuid.New(), EmployeeID: ada.ID, Date: nextWeek, StartTime: "09:00:00", EndTime: "17:00:00", ShiftType: database.ShiftWorking},
+		}, nil).Once()
+
+		w := jobRequest("POST", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data api.RemovalImpact `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, 1, resp.Data.RemovedShifts)
+		if assert.Len(t, resp.Data.ExtraHours, 1) {
+			assert.Equal(t, ben.ID, resp.Data.ExtraHours[0].EmployeeID)
+			assert.Equal(t, 8.0, resp.Data.ExtraHours[0].ExtraHours)
+		}
+		// the manager's roles are not needed
+		env.UserRolesStore.AssertNotCalled(t, "GetUserRoles", manager.ID, orgID)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		env.ResetMocks()
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(ben), env.Handler.EmployeeImpactAnalysisHandler}, nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		env.ResetMocks()
+		w := jobRequest("POST", route, "/"+orgID.String()+"/staffing/employees/nope/impact-analysis", handlers, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUsersByOrganization", orgID).Return([]*database.User{manager, ben}, nil).Once()
+		env.UserRolesStore.On("GetUserRoles", ben.ID, orgID).Return([]string{}, nil).Once()
+
+		w := jobRequest("POST", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("StoreError", func(t *testing.T) {
+		env.ResetMocks()
+		expectStaff()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(nil, nil).Once()
+		env.ScheduleStore.On("GetShiftsFrom", orgID, mock.AnythingOfType("time.Time")).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("POST", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -97,13 +97,14 @@ func TestCreateRole(t *testing.T) {
 			NeedForDemand:       true,
 			ItemsPerRolePerHour: intPtr(5),
 			Independent:         boolPtr(false),
+			SoloCoverage:        true,
 		}
 
 		// 1. Check if exists
 		env.RolesStore.On("GetRoleByName", orgID, "Chef").Return(nil, nil).Once()
 		// 2. Create
 		env.RolesStore.On("CreateRole", mock.MatchedBy(func(r *database.OrganizationRole) bool {
-			return r.Role == "Chef" && r.OrganizationID == orgID && *r.ItemsPerRolePerHour == 5
+			return r.Role == "Chef" && r.OrganizationID == orgID && *r.ItemsPerRolePerHour == 5 && r.SoloCoverage
 		})).Return(nil).Once()
 
 		jsonBytes, _ := json.Marshal(reqBody)
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/clockwise/clockwise/backend/internal/api"
@@ -129,6 +130,7 @@ func TestUpdateOrganizationRules(t *testing.T) {
 
 		// 1. Expect Rules Upsert
 		// We use mock.Anything for the struct to avoid brittle matching on specific fields if struct definition changes
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(nil, nil).Once()
 		env.RulesStore.On("UpsertRules", mock.Anything).Return(nil).Once()
 
 		// 2. Expect Operating Hours Set
@@ -179,4 +181,295 @@ func TestUpdateOrganizationRules(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 		assert.Contains(t, w.Body.String(), "Shift minimum hours cannot exceed")
 	})
+
+	t.Run("Success_WeeklyLaborBudget", func(t *testing.T) {
+		env.ResetMocks()
+		budget := 4500.0
+		reqBody := api.RulesRequest{
+			ShiftMaxHours:       8,
+			ShiftMinHours:       4,
+			MaxWeeklyHours:      40,
+			MinWeeklyHours:      20,
+			SlotLenHour:         1.0,
+			MinShiftLengthSlots: 4,
+			WaitingTime:         15,
+			WeeklyLaborBudget:   &budget,
+		}
+
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(nil, nil).Once()
+		env.RulesStore.On("UpsertRules", mock.MatchedBy(func(rules *database.OrganizationRules) bool {
+			return rules.WeeklyLaborBudget != nil && *rules.WeeklyLaborBudget == budget
+		})).Return(nil).Once()
+		env.OperatingHoursStore.On("GetOperatingHours", orgID).Return([]database.OperatingHours{}, nil).Once()
+
+		jsonBytes, _ := json.Marshal(reqBody)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/rules", bytes.NewBuffer(jsonBytes))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.RulesStore.AssertExpectations(t)
+	})
+
+	t.Run("Success_WaitTimeTuning", func(t *testing.T) {
+		env.ResetMocks()
+		buffer := 0
+		factor := 1.5
+		reqBody := api.RulesRequest{
+			ShiftMaxHours:       8,
+			ShiftMinHours:       4,
+			MaxWeeklyHours:      40,
+			MinWeeklyHours:      20,
+			SlotLenHour:         1.0,
+			MinShiftLengthSlots: 4,
+			WaitingTime:         15,
+			PrepBufferMinutes:   &buffer,
+			WaitTimeFactor:      &factor,
+		}
+
+		// Omitted delivery_minutes, standby_pay_percent and cancellation_notice_hours fall back to the defaults
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(nil, nil).Once()
+		env.RulesStore.On("UpsertRules", mock.MatchedBy(func(rules *database.OrganizationRules) bool {
+			return rules.PrepBufferMinutes == 0 && rules.DeliveryMinutes == 25 && rules.WaitTimeFactor == 1.5 && rules.StandbyPayPercent == 25 &&
+				rules.CancellationNoticeHours == 24
+		})).Return(nil).Once()
+		env.OperatingHoursStore.On("GetOperatingHours", orgID).Return([]database.OperatingHours{}, nil).Once()
+
+		jsonBytes, _ := json.Marshal(reqBody)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/rules", bytes.NewBuffer(jsonBytes))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.RulesStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_Validation_NegativeBudget", func(t *testing.T) {
+		env.ResetMocks()
+		budget := -10.0
+		reqBody := api.RulesRequest{
+			ShiftMaxHours:       8,
+			ShiftMinHours:       4,
+			MaxWeeklyHours:      40,
+			MinWeeklyHours:      20,
+			SlotLenHour:         1.0,
+			MinShiftLengthSlots: 4,
+			WaitingTime:         15,
+			WeeklyLaborBudget:   &budget,
+		}
+
+		jsonBytes, _ := json.Marshal(reqBody)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/rules", bytes.NewBuffer(jsonBytes))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "WeeklyLaborBudget")
+	})
+
+	t.Run("Success_AutoCloseOrders", func(t *testing.T) {
+		env.ResetMocks()
+		autoClose := true
+		perStaff := 8
+		reqBody := api.RulesRequest{
+			ShiftMaxHours:       8,
+			ShiftMinHours:       4,
+			MaxWeeklyHours:      40,
+			MinWeeklyHours:      20,
+			SlotLenHour:         1.0,
+			MinShiftLengthSlots: 4,
+			WaitingTime:         15,
+			AutoCloseOrders:     &autoClose,
+			OrdersPerStaffHour:  &perStaff,
+		}
+
+		// Omitted load percents fall back to closing past 150% and reopening at 100%
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(nil, nil).Once()
+		env.RulesStore.On("UpsertRules", mock.MatchedBy(func(rules *database.OrganizationRules) bool {
+			return rules.AutoCloseOrders && rules.OrdersPerStaffHour == 8 && rules.AutoCloseLoadPercent == 150 && rules.AutoReopenLoadPercent == 100
+		})).Return(nil).Once()
+		env.OperatingHoursStore.On("GetOperatingHours", orgID).Return([]database.OperatingHours{}, nil).Once()
+
+		jsonBytes, _ := json.Marshal(reqBody)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/rules", bytes.NewBuffer(jsonBytes))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.RulesStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_Validation_ReopenNotBelowClose", func(t *testing.T) {
+		env.ResetMocks()
+		closeAt := 120
+		reopenAt := 120
+		reqBody := api.RulesRequest{
+			ShiftMaxHours:         8,
+			ShiftMinHours:         4,
+			MaxWeeklyHours:        40,
+			MinWeeklyHours:        20,
+			SlotLenHour:           1.0,
+			MinShiftLengthSlots:   4,
+			WaitingTime:           15,
+			AutoCloseLoadPercent:  &closeAt,
+			AutoReopenLoadPercent: &reopenAt,
+		}
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(nil, nil).Once()
+
+		jsonBytes, _ := json.Marshal(reqBody)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/rules", bytes.NewBuffer(jsonBytes))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "auto_reopen_load_percent")
+		env.RulesStore.AssertNotCalled(t, "UpsertRules", mock.Anything)
+	})
+
+	t.Run("Success_DeliverySLA", func(t *testing.T) {
+		env.ResetMocks()
+		sla := 30
+		reqBody := api.RulesRequest{
+			ShiftMaxHours:       8,
+			ShiftMinHours:       4,
+			MaxWeeklyHours:      40,
+			MinWeeklyHours:      20,
+			SlotLenHour:         1.0,
+			MinShiftLengthSlots: 4,
+			WaitingTime:         15,
+			DeliverySLAMinutes:  &sla,
+		}
+
+		// An omitted alert threshold falls back to 20% of the deliveries of the day
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(nil, nil).Once()
+		env.RulesStore.On("UpsertRules", mock.MatchedBy(func(rules *database.OrganizationRules) bool {
+			return rules.DeliverySLAMinutes == 30 && rules.SLABreachAlertPercent == 20
+		})).Return(nil).Once()
+		env.OperatingHoursStore.On("GetOperatingHours", orgID).Return([]database.OperatingHours{}, nil).Once()
+
+		jsonBytes, _ := json.Marshal(reqBody)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/rules", bytes.NewBuffer(jsonBytes))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.RulesStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_Validation_SLAAlertPercent", func(t *testing.T) {
+		env.ResetMocks()
+		percent := 120
+		reqBody := api.RulesRequest{
+			ShiftMaxHours:         8,
+			ShiftMinHours:         4,
+			MaxWeeklyHours:        40,
+			MinWeeklyHours:        20,
+			SlotLenHour:           1.0,
+			MinShiftLengthSlots:   4,
+			WaitingTime:           15,
+			SLABreachAlertPercent: &percent,
+		}
+
+		jsonBytes, _ := json.Marshal(reqBody)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/rules", bytes.NewBuffer(jsonBytes))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "SLABreachAlertPercent")
+		env.RulesStore.AssertNotCalled(t, "UpsertRules", mock.Anything)
+	})
+	t.Run("Success_PartialBodyKeepsStoredSettings", func(t *testing.T) {
+		env.ResetMocks()
+		noticeDays := 14
+		sla := 30
+		stored := &database.OrganizationRules{
+			OrganizationID:                 orgID,
+			ReceivingPhone:                 false,
+			Delivery:                       true,
+			AcceptingOrders:                true,
+			WeeklyLaborBudget:              pricePtr(4500),
+			PrepBufferMinutes:              8,
+			DeliveryMinutes:                20,
+			WaitTimeFactor:                 1.2,
+			StandbyPayPercent:              30,
+			CancellationNoticeHours:        48,
+			PredictabilityNoticeDays:       &noticeDays,
+			PredictabilityPayHours:         2,
+			PredictabilityLostHoursPercent: 40,
+			AutoCloseOrders:                true,
+			OrdersPerStaffHour:             12,
+			AutoCloseLoadPercent:           140,
+			AutoReopenLoadPercent:          90,
+			DeliverySLAMinutes:             sla,
+			SLABreachAlertPercent:          15,
+		}
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(stored, nil).Once()
+		env.RulesStore.On("UpsertRules", mock.MatchedBy(func(rules *database.OrganizationRules) bool {
+			return rules.ShiftMaxHours == 9 && !rules.ReceivingPhone && *rules.WeeklyLaborBudget == 5000 &&
+				rules.PrepBufferMinutes == 8 && rules.DeliveryMinutes == 20 && rules.WaitTimeFactor == 1.2 &&
+				rules.StandbyPayPercent == 30 && rules.CancellationNoticeHours == 48 &&
+				rules.PredictabilityNoticeDays != nil && *rules.PredictabilityNoticeDays == 14 && rules.PredictabilityPayHours == 2 &&
+				rules.AutoCloseOrders && rules.OrdersPerStaffHour == 12 && rules.AutoCloseLoadPercent == 140 &&
+				rules.DeliverySLAMinutes == 30 && rules.SLABreachAlertPercent == 15
+		})).Return(nil).Once()
+		env.OperatingHoursStore.On("GetOperatingHours", orgID).Return([]database.OperatingHours{}, nil).Once()
+
+		// only the required rules and the budget, as a client that does not know the later settings sends
+		body := `{"shift_max_hours": 9, "shift_min_hours": 4, "max_weekly_hours": 40, "min_weekly_hours": 20,
+			"slot_len_hour": 1, "min_shift_length_slots": 4, "waiting_time": 15, "weekly_labor_budget": 5000}`
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/rules", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.RulesStore.AssertExpectations(t)
+		assert.Equal(t, 0, stored.ShiftMaxHours, "the stored rules are not changed in place")
+	})
+
+	t.Run("Success_NullClearsOptionalSettings", func(t *testing.T) {
+		env.ResetMocks()
+		noticeDays := 14
+		stored := &database.OrganizationRules{OrganizationID: orgID, WeeklyLaborBudget: pricePtr(4500), PredictabilityNoticeDays: &noticeDays,
+			AutoCloseLoadPercent: 150, AutoReopenLoadPercent: 100}
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(stored, nil).Once()
+		env.RulesStore.On("UpsertRules", mock.MatchedBy(func(rules *database.OrganizationRules) bool {
+			return rules.WeeklyLaborBudget == nil && rules.PredictabilityNoticeDays == nil
+		})).Return(nil).Once()
+		env.OperatingHoursStore.On("GetOperatingHours", orgID).Return([]database.OperatingHours{}, nil).Once()
+
+		body := `{"shift_max_hours": 8, "shift_min_hours": 4, "max_weekly_hours": 40, "min_weekly_hours": 20,
+			"slot_len_hour": 1, "min_shift_length_slots": 4, "waiting_time": 15,
+			"weekly_labor_budget": null, "predictability_notice_days": null}`
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/rules", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.RulesStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_RulesDBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(nil, errors.New("db error")).Once()
+
+		body := `{"shift_max_hours": 8, "shift_min_hours": 4, "max_weekly_hours": 40, "min_weekly_hours": 20,
+			"slot_len_hour": 1, "min_shift_length_slots": 4, "waiting_time": 15}`
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/rules", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		env.RulesStore.AssertNotCalled(t, "UpsertRules", mock.Anything)
+	})
 }
\ No newline at end of file
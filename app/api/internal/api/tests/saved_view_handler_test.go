@@ -0,0 +1,298 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type SavedViewTestEnv struct {
+	ViewStore *MockSavedViewStore
+	Handler   *api.SavedViewHandler
+}
+
+func setupSavedViewEnv() *SavedViewTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	viewStore := new(MockSavedViewStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &SavedViewTestEnv{
+		ViewStore: viewStore,
+		Handler:   api.NewSavedViewHandler(viewStore, logger),
+	}
+}
+
+func (env *SavedViewTestEnv) ResetMocks() {
+	env.ViewStore.ExpectedCalls = nil
+	env.ViewStore.Calls = nil
+}
+
+func TestViewFromRequest(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		view, err := api.ViewFromRequest(api.SavedViewRequest{Name: " Vegan items ", Resource: "items", Filters: map[string]string{"cf.vegan": "true"}})
+		assert.NoError(t, err)
+		assert.Equal(t, "Vegan items", view.Name)
+		assert.Equal(t, map[string]string{"cf.vegan": "true"}, view.Filters)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		tooMany := map[string]string{}
+		for i := 0; i < 21; i++ {
+			tooMany[string(rune('a'+i))] = "x"
+		}
+		for name, req := range map[string]api.SavedViewRequest{
+			"BlankName": {Name: "  ", Filters: map[string]string{}},
+			"BadKey":    {Name: "Late", Filters: map[string]string{"Status&x": "late"}},
+			"LongValue": {Name: "Late", Filters: map[string]string{"status": strings.Repeat("x", 201)}},
+			"TooMany":   {Name: "Late", Filters: tooMany},
+		} {
+			_, err := api.ViewFromRequest(req)
+			assert.Error(t, err, name)
+		}
+	})
+}
+
+func TestGetViewsHandler(t *testing.T) {
+	env := setupSavedViewEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/api/v1/:org/views"
+
+	t.Run("Success_WithPaths", func(t *testing.T) {
+		env.ResetMocks()
+		views := []database.SavedView{{ID: uuid.New(), CreatedBy: manager.ID, Name: "Vegan items", Resource: "items", Filters: map[string]string{"cf.vegan": "true"}, Shared: true}}
+		env.ViewStore.On("ListViews", orgID, manager.ID, "items").Return(views, nil).Once()
+
+		w := jobRequest("GET", route, "/api/v1/"+orgID.String()+"/views?resource=items", []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetViewsHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data []api.SavedViewResponse `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		if assert.Len(t, response.Data, 1) {
+			// the path keeps the API version the views were listed on
+			assert.Equal(t, "/api/v1/"+orgID.String()+"/items/all?cf.vegan=true", response.Data[0].Path)
+			assert.Equal(t, "Vegan items", response.Data[0].Name)
+		}
+		env.ViewStore.AssertExpectations(t)
+	})
+
+	t.Run("UnknownResource", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("GET", route, "/api/v1/"+orgID.String()+"/views?resource=payroll", []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetViewsHandler}, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Failure_DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.ViewStore.On("ListViews", orgID, manager.ID, "").Return(nil, sql.ErrConnDone).Once()
+
+		w := jobRequest("GET", route, "/api/v1/"+orgID.String()+"/views", []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetViewsHandler}, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestGetViewHandler(t *testing.T) {
+	env := setupSavedViewEnv()
+	orgID := uuid.New()
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	viewID := uuid.New()
+	route, path := "/:org/views/:id", "/"+orgID.String()+"/views/"+viewID.String()
+
+	t.Run("Shared", func(t *testing.T) {
+		env.ResetMocks()
+		env.ViewStore.On("GetView", orgID, viewID).
+			Return(&database.SavedView{ID: viewID, CreatedBy: uuid.New(), Name: "Late deliveries", Resource: "deliveries", Filters: map[string]string{}, Shared: true}, nil).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetViewHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"path":"/`+orgID.String()+`/deliveries/all"`)
+	})
+
+	t.Run("NotFound_PrivateToAnotherUser", func(t *testing.T) {
+		env.ResetMocks()
+		env.ViewStore.On("GetView", orgID, viewID).
+			Return(&database.SavedView{ID: viewID, CreatedBy: uuid.New(), Name: "Mine", Resource: "orders", Filters: map[string]string{}}, nil).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetViewHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.ViewStore.On("GetView", orgID, viewID).Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetViewHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestCreateViewHandler(t *testing.T) {
+	env := setupSavedViewEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	route, path := "/:org/views", "/"+orgID.String()+"/views"
+
+	t.Run("Success_Shared", func(t *testing.T) {
+		env.ResetMocks()
+		env.ViewStore.On("CreateView", orgID, mock.MatchedBy(func(v *database.SavedView) bool {
+			return v.CreatedBy == manager.ID && v.Name == "Table 12" && v.Resource == "orders" && v.Shared && v.Filters["cf.table"] == "12"
+		})).Return(nil).Once()
+
+		body := gin.H{"name": "Table 12", "resource": "orders", "filters": gin.H{"cf.table": "12"}, "shared": true}
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.CreateViewHandler}, body)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), "/orders/all?cf.table=12")
+		env.ViewStore.AssertExpectations(t)
+	})
+
+	t.Run("Success_EmployeePrivate", func(t *testing.T) {
+		env.ResetMocks()
+		env.ViewStore.On("CreateView", orgID, mock.Anything).Return(nil).Once()
+
+		body := gin.H{"name": "My events", "resource": "events"}
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.CreateViewHandler}, body)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("Forbidden_EmployeeSharing", func(t *testing.T) {
+		env.ResetMocks()
+
+		body := gin.H{"name": "Everyone's events", "resource": "events", "shared": true}
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.CreateViewHandler}, body)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		env.ViewStore.AssertNotCalled(t, "CreateView", mock.Anything, mock.Anything)
+	})
+
+	t.Run("UnknownResource", func(t *testing.T) {
+		env.ResetMocks()
+
+		body := gin.H{"name": "Payroll", "resource": "payroll"}
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.CreateViewHandler}, body)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Conflict_NameTaken", func(t *testing.T) {
+		env.ResetMocks()
+		env.ViewStore.On("CreateView", orgID, mock.Anything).Return(database.ErrSavedViewExists).Once()
+
+		body := gin.H{"name": "Table 12", "resource": "orders"}
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.CreateViewHandler}, body)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestUpdateViewHandler(t *testing.T) {
+	env := setupSavedViewEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	viewID := uuid.New()
+	route, path := "/:org/views/:id", "/"+orgID.String()+"/views/"+viewID.String()
+	sharedView := func() *database.SavedView {
+		return &database.SavedView{ID: viewID, CreatedBy: manager.ID, Name: "Late", Resource: "deliveries", Filters: map[string]string{}, Shared: true}
+	}
+
+	t.Run("Creator", func(t *testing.T) {
+		env.ResetMocks()
+		env.ViewStore.On("GetView", orgID, viewID).Return(sharedView(), nil).Once()
+		env.ViewStore.On("UpdateView", orgID, mock.MatchedBy(func(v *database.SavedView) bool {
+			return v.ID == viewID && v.CreatedBy == manager.ID && v.Resource == "deliveries" && v.Name == "Late this week" && !v.Shared
+		})).Return(nil).Once()
+
+		body := gin.H{"name": "Late this week", "filters": gin.H{"status": "late"}}
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.UpdateViewHandler}, body)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.ViewStore.AssertExpectations(t)
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		env.ResetMocks()
+		env.ViewStore.On("GetView", orgID, viewID).Return(sharedView(), nil).Once()
+		env.ViewStore.On("UpdateView", orgID, mock.Anything).Return(nil).Once()
+
+		body := gin.H{"name": "Late", "shared": true}
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.UpdateViewHandler}, body)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Forbidden_OtherManager", func(t *testing.T) {
+		env.ResetMocks()
+		other := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+		env.ViewStore.On("GetView", orgID, viewID).Return(sharedView(), nil).Once()
+
+		body := gin.H{"name": "Mine now"}
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(other), env.Handler.UpdateViewHandler}, body)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("ResourceChange", func(t *testing.T) {
+		env.ResetMocks()
+		env.ViewStore.On("GetView", orgID, viewID).Return(sharedView(), nil).Once()
+
+		body := gin.H{"name": "Late", "resource": "orders"}
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.UpdateViewHandler}, body)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		env.ViewStore.AssertNotCalled(t, "UpdateView", mock.Anything, mock.Anything)
+	})
+}
+
+func TestDeleteViewHandler(t *testing.T) {
+	env := setupSavedViewEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	viewID := uuid.New()
+	route, path := "/:org/views/:id", "/"+orgID.String()+"/views/"+viewID.String()
+	view := &database.SavedView{ID: viewID, CreatedBy: manager.ID, Name: "Late", Resource: "deliveries", Filters: map[string]string{}, Shared: true}
+
+	t.Run("Creator", func(t *testing.T) {
+		env.ResetMocks()
+		env.ViewStore.On("GetView", orgID, viewID).Return(view, nil).Once()
+		env.ViewStore.On("DeleteView", orgID, viewID).Return(nil).Once()
+
+		w := jobRequest("DELETE", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.DeleteViewHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.ViewStore.AssertExpectations(t)
+	})
+
+	t.Run("Forbidden_SharedWithEmployee", func(t *testing.T) {
+		env.ResetMocks()
+		env.ViewStore.On("GetView", orgID, viewID).Return(view, nil).Once()
+
+		w := jobRequest("DELETE", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.DeleteViewHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		env.ViewStore.AssertNotCalled(t, "DeleteView", mock.Anything, mock.Anything)
+	})
+}
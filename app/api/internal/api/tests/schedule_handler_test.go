@@ -1,11 +1,14 @@
 package api
 
 import (
+	"database/sql"
+	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,6 +17,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 type ScheduleTestEnv struct {
@@ -29,6 +33,12 @@ type ScheduleTestEnv struct {
 	DemandStore         *MockDemandStore
 	RoleStore           *MockRolesStore
 	PreferenceStore     *MockPreferencesStore
+	EmailService        *MockEmailService
+	SessionStore        *MockApplicantSessionStore
+	ExceptionStore      *MockOperatingHoursExceptionStore
+	StatusStore         *MockStatusStore
+	ComplianceStore     *MockEmployeeComplianceStore
+	VersionStore        *MockScheduleVersionStore
 	Handler             *api.ScheduleHandler
 }
 
@@ -46,6 +56,14 @@ func setupScheduleEnv() *ScheduleTestEnv {
 	demandStore := new(MockDemandStore)
 	roleStore := new(MockRolesStore)
 	preferenceStore := new(MockPreferencesStore)
+	emailService := new(MockEmailService)
+	sessionStore := new(MockApplicantSessionStore)
+	exceptionStore := new(MockOperatingHoursExceptionStore)
+	statusStore := new(MockStatusStore)
+	statusStore.AllowEvents()
+	complianceStore := new(MockEmployeeComplianceStore)
+	versionStore := new(MockScheduleVersionStore)
+	versionStore.AllowVersions()
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
 	handler := api.NewScheduleHandler(
@@ -53,6 +71,8 @@ func setupScheduleEnv() *ScheduleTestEnv {
 		orgStore, rulesStore, userRolesStore,
 		opHoursStore, orderStore, campaignStore,
 		demandStore, roleStore, preferenceStore,
+		emailService, sessionStore, exceptionStore,
+		statusStore, complianceStore, versionStore,
 	)
 
 	return &ScheduleTestEnv{
@@ -68,6 +88,12 @@ func setupScheduleEnv() *ScheduleTestEnv {
 		DemandStore:         demandStore,
 		RoleStore:           roleStore,
 		PreferenceStore:     preferenceStore,
+		EmailService:        emailService,
+		SessionStore:        sessionStore,
+		ExceptionStore:      exceptionStore,
+		StatusStore:         statusStore,
+		ComplianceStore:     complianceStore,
+		VersionStore:        versionStore,
 		Handler:             handler,
 	}
 }
@@ -95,6 +121,20 @@ func (env *ScheduleTestEnv) ResetMocks() {
 	env.RoleStore.Calls = nil
 	env.PreferenceStore.ExpectedCalls = nil
 	env.PreferenceStore.Calls = nil
+	env.EmailService.ExpectedCalls = nil
+	env.EmailService.Calls = nil
+	env.SessionStore.ExpectedCalls = nil
+	env.SessionStore.Calls = nil
+	env.ExceptionStore.ExpectedCalls = nil
+	env.ExceptionStore.Calls = nil
+	env.StatusStore.ExpectedCalls = nil
+	env.StatusStore.Calls = nil
+	env.ComplianceStore.ExpectedCalls = nil
+	env.ComplianceStore.Calls = nil
+	env.VersionStore.ExpectedCalls = nil
+	env.VersionStore.Calls = nil
+	env.StatusStore.AllowEvents()
+	env.VersionStore.AllowVersions()
 }
 
 // --- GetScheduleHandler (full organization schedule) ---
@@ -112,12 +152,13 @@ func TestGetScheduleHandler(t *testing.T) {
 			{
 				Date:      time.Now(),
 				Day:       "monday",
-				StartTime: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
-				EndTime:   time.Date(2024, 1, 1, 17, 0, 0, 0, time.UTC),
+				StartTime: "09:00:00",
+				EndTime:   "17:00:00",
 				Employees: []string{uuid.New().String()},
 			},
 		}
 		env.ScheduleStore.On("GetFullScheduleForSevenDays", orgID).Return(schedules, nil).Once()
+		env.SessionStore.On("GetSessionsForSevenDays", orgID).Return([]database.ApplicantSession{}, nil).Once()
 
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule", nil)
@@ -126,9 +167,56 @@ func TestGetScheduleHandler(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Code)
 		assert.Contains(t, w.Body.String(), "Schedule retrieved successfully")
 		assert.Contains(t, w.Body.String(), "monday")
+		assert.NotContains(t, w.Body.String(), "non_productive")
 		env.ScheduleStore.AssertExpectations(t)
 	})
 
+	t.Run("Success_WithApplicantSessions", func(t *testing.T) {
+		env.ResetMocks()
+		day := time.Now().Truncate(24 * time.Hour)
+		schedules := []database.Schedule{
+			{Date: day, Day: day.Weekday().String(), StartTime: "14:00:00", EndTime: "18:00:00", Employees: []string{"Sam Cook"}},
+		}
+		sessions := []database.ApplicantSession{
+			{ApplicantName: "Jamie Doe", Kind: "interview", ManagerID: uuid.New(), ManagerName: "Alex Manager", Date: day, StartTime: "10:00:00", EndTime: "11:00:00"},
+		}
+		env.ScheduleStore.On("GetFullScheduleForSevenDays", orgID).Return(schedules, nil).Once()
+		env.SessionStore.On("GetSessionsForSevenDays", orgID).Return(sessions, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data []database.Schedule `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		if assert.Len(t, response.Data, 2) {
+			// the morning interview is sorted before the afternoon shift
+			assert.Equal(t, "interview", response.Data[0].Kind)
+			assert.True(t, response.Data[0].NonProductive)
+			assert.Equal(t, "Jamie Doe", response.Data[0].Applicant)
+			assert.Equal(t, []string{"Alex Manager"}, response.Data[0].Employees)
+			assert.False(t, response.Data[1].NonProductive)
+		}
+		env.SessionStore.AssertExpectations(t)
+	})
+
+	t.Run("Success_SessionsUnavailable", func(t *testing.T) {
+		env.ResetMocks()
+		schedules := []database.Schedule{{Date: time.Now(), Day: "monday", StartTime: "09:00:00", EndTime: "17:00:00"}}
+		env.ScheduleStore.On("GetFullScheduleForSevenDays", orgID).Return(schedules, nil).Once()
+		env.SessionStore.On("GetSessionsForSevenDays", orgID).Return(nil, errors.New("db error")).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "monday")
+	})
+
 	t.Run("Success_Manager", func(t *testing.T) {
 		env.ResetMocks()
 		manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
@@ -137,6 +225,7 @@ func TestGetScheduleHandler(t *testing.T) {
 
 		schedules := []database.Schedule{}
 		env.ScheduleStore.On("GetFullScheduleForSevenDays", orgID).Return(schedules, nil).Once()
+		env.SessionStore.On("GetSessionsForSevenDays", orgID).Return([]database.ApplicantSession{}, nil).Once()
 
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule", nil)
@@ -202,8 +291,8 @@ func TestGetCurrentUserScheduleHandler(t *testing.T) {
 			{
 				Date:      time.Now(),
 				Day:       "tuesday",
-				StartTime: time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
-				EndTime:   time.Date(2024, 1, 1, 16, 0, 0, 0, time.UTC),
+				StartTime: "08:00:00",
+				EndTime:   "16:00:00",
 				Employees: []string{employeeID.String()},
 			},
 		}
@@ -227,14 +316,24 @@ func TestGetCurrentUserScheduleHandler(t *testing.T) {
 		router.GET("/:org/schedule/me", authMiddleware(manager), env.Handler.GetCurrentUserScheduleHandler)
 
 		schedules := []database.Schedule{}
+		sessions := []database.ApplicantSession{
+			{ApplicantName: "Jamie Doe", Kind: "trial", ManagerID: managerID, Date: time.Now(), StartTime: "10:00:00", EndTime: "14:00:00"},
+			{ApplicantName: "Robin Roe", Kind: "interview", ManagerID: uuid.New(), Date: time.Now(), StartTime: "15:00:00", EndTime: "16:00:00"},
+		}
 		env.ScheduleStore.On("GetScheduleForEmployeeForSevenDays", orgID, managerID).Return(schedules, nil).Once()
+		env.SessionStore.On("GetSessionsForSevenDays", orgID).Return(sessions, nil).Once()
 
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule/me", nil)
 		router.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusOK, w.Code)
+		// only the sessions the manager holds are on their own schedule
+		assert.Contains(t, w.Body.String(), "Jamie Doe")
+		assert.Contains(t, w.Body.String(), managerID.String())
+		assert.NotContains(t, w.Body.String(), "Robin Roe")
 		env.ScheduleStore.AssertExpectations(t)
+		env.SessionStore.AssertExpectations(t)
 	})
 
 	t.Run("Failure_AdminForbidden", func(t *testing.T) {
@@ -284,8 +383,8 @@ func TestGetEmployeeScheduleHandler(t *testing.T) {
 			{
 				Date:      time.Now(),
 				Day:       "wednesday",
-				StartTime: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
-				EndTime:   time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC),
+				StartTime: "10:00:00",
+				EndTime:   "18:00:00",
 				Employees: []string{targetEmployeeID.String()},
 			},
 		}
@@ -451,6 +550,7 @@ func TestPredictScheduleHandler(t *testing.T) {
 		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(rules, nil).Once()
 		env.OperatingHoursStore.On("GetOperatingHours", orgID).Return(opHours, nil).Once()
 		env.DemandStore.On("GetLatestDemandHeatMap", orgID).Return(demand, nil).Once()
+		env.ExceptionStore.On("GetExceptionsBetween", orgID, mock.Anything, mock.Anything).Return([]database.OperatingHoursException{}, nil).Once()
 		env.RoleStore.On("GetRolesByOrganizationID", orgID).Return(nil, errors.New("db error")).Once()
 
 		w := httptest.NewRecorder()
@@ -471,6 +571,7 @@ func TestPredictScheduleHandler(t *testing.T) {
 		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(rules, nil).Once()
 		env.OperatingHoursStore.On("GetOperatingHours", orgID).Return(opHours, nil).Once()
 		env.DemandStore.On("GetLatestDemandHeatMap", orgID).Return(demand, nil).Once()
+		env.ExceptionStore.On("GetExceptionsBetween", orgID, mock.Anything, mock.Anything).Return([]database.OperatingHoursException{}, nil).Once()
 		env.RoleStore.On("GetRolesByOrganizationID", orgID).Return(roles, nil).Once()
 		env.UserStore.On("GetUsersByOrganization", orgID).Return(nil, errors.New("db error")).Once()
 
@@ -481,4 +582,1489 @@ func TestPredictScheduleHandler(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
 		assert.Contains(t, w.Body.String(), "failed to get employees from organization")
 	})
+
+	t.Run("Failure_RulesNotFound", func(t *testing.T) {
+		env.ResetMocks()
+		org := &database.Organization{ID: orgID, Name: "Test Org", Type: "restaurant"}
+		env.OrgStore.On("GetOrganizationByID", orgID).Return(org, nil).Once()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(nil, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/schedule/predict", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "organization rules not found")
+	})
+
+	t.Run("Failure_InvalidPayload", func(t *testing.T) {
+		env.ResetMocks()
+		// No location, no roles, no demand and no employees
+		org := &database.Organization{ID: orgID, Name: "Test Org", Type: "restaurant"}
+		rules := &database.OrganizationRules{OrganizationID: orgID}
+		opHours := []database.OperatingHours{{Weekday: "monday", OpeningTime: "09:00", ClosingTime: "17:00"}}
+		demand := &database.DemandPredictResponse{Days: []database.PredictionDay{}}
+		env.OrgStore.On("GetOrganizationByID", orgID).Return(org, nil).Once()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(rules, nil).Once()
+		env.OperatingHoursStore.On("GetOperatingHours", orgID).Return(opHours, nil).Once()
+		env.DemandStore.On("GetLatestDemandHeatMap", orgID).Return(demand, nil).Once()
+		env.ExceptionStore.On("GetExceptionsBetween", orgID, mock.Anything, mock.Anything).Return([]database.OperatingHoursException{}, nil).Once()
+		env.RoleStore.On("GetRolesByOrganizationID", orgID).Return([]database.OrganizationRole{}, nil).Once()
+		env.UserStore.On("GetUsersByOrganization", orgID).Return([]*database.User{}, nil).Once()
+		env.ComplianceStore.On("GetWorkPermitExpiries", orgID).Return(map[uuid.UUID]time.Time{}, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/schedule/predict", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		body := w.Body.String()
+		assert.Contains(t, body, "schedule input is incomplete or invalid")
+		assert.Contains(t, body, "organization latitude is required")
+		assert.Contains(t, body, "at least one organization role is required")
+		assert.Contains(t, body, "slot_len_hour must be greater than 0")
+		assert.Contains(t, body, "demand predictions are required")
+	})
+
+	t.Run("Success_FallbackWhenMLUnavailable", func(t *testing.T) {
+		env.ResetMocks()
+		mlServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer mlServer.Close()
+		t.Setenv("ML_URL", mlServer.URL)
+
+		employee := mockValidSchedulePrediction(env, orgID)
+		env.ScheduleStore.On("StoreScheduleForUser", orgID, employee.ID, mock.AnythingOfType("*database.Schedule"), admin.ID).Return(nil)
+		env.UserStore.On("GetUserByID", employee.ID).Return(employee, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/schedule/predict", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, "heuristic_fallback", resp["schedule_source"])
+		assert.Equal(t, "HEURISTIC", resp["schedule_status"])
+
+		output := resp["schedule_output"].(map[string]any)
+		assert.Equal(t, []any{map[string]any{"09:00-13:00": []any{"Sam Server"}}}, output["monday"])
+		assert.Equal(t, 100.0, resp["coverage_percent"])
+		budget := resp["budget_utilization"].(map[string]any)
+		assert.Equal(t, 60.0, budget["labor_cost"])
+		assert.Nil(t, budget["weekly_labor_budget"])
+		env.ScheduleStore.AssertNumberOfCalls(t, "StoreScheduleForUser", 1)
+
+		// The failed ML request and the fallback generation show in the status
+		if requests := env.StatusStore.RecordedEvents(database.StatusEventMLRequest); assert.Len(t, requests, 1) {
+			assert.Equal(t, "/predict/schedule", requests[0].Source)
+			assert.True(t, requests[0].Failed)
+		}
+		if generations := env.StatusStore.RecordedEvents(database.StatusEventScheduleGeneration); assert.Len(t, generations, 1) {
+			assert.Equal(t, "heuristic_fallback", generations[0].Source)
+		}
+	})
+
+	t.Run("Success_ScoresPreferenceSatisfaction", func(t *testing.T) {
+		env.ResetMocks()
+		mlServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer mlServer.Close()
+		t.Setenv("ML_URL", mlServer.URL)
+
+		preferredStart, preferredEnd := "09:00:00", "13:00:00"
+		env.PreferenceStore.On("GetPreferencesByEmployeeID", mock.Anything).Return([]database.EmployeePreference{
+			{Day: "monday", PreferredStartTime: &preferredStart, PreferredEndTime: &preferredEnd},
+		}, nil)
+		employee := mockValidSchedulePrediction(env, orgID)
+		env.ScheduleStore.On("StoreScheduleForUser", orgID, employee.ID, mock.AnythingOfType("*database.Schedule"), admin.ID).Return(nil)
+		env.UserStore.On("GetUserByID", employee.ID).Return(employee, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/schedule/predict", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		satisfaction := resp["preference_satisfaction"].(map[string]any)
+		assert.Equal(t, 100.0, satisfaction["percent"])
+		assert.Equal(t, 1.0, satisfaction["shifts"])
+		assert.Equal(t, 1.0, satisfaction["preferred_shifts"])
+		assert.NotNil(t, resp["schedule_version_id"])
+
+		utilization := resp["management_insights"].(map[string]any)["employee_utilization"].([]any)
+		assert.Equal(t, 100.0, utilization[0].(map[string]any)["preference_satisfaction_percent"])
+
+		if versions := env.VersionStore.StoredVersions(); assert.Len(t, versions, 1) {
+			assert.Equal(t, "heuristic_fallback", versions[0].Source)
+			assert.Equal(t, admin.ID, *versions[0].GeneratedBy)
+			if assert.Len(t, versions[0].Employees, 1) {
+				assert.Equal(t, employee.ID, versions[0].Employees[0].EmployeeID)
+				assert.Equal(t, 100.0, *versions[0].Employees[0].SatisfactionPercent)
+			}
+		}
+	})
+
+	t.Run("Success_VersionStoreErrorStillReturnsSchedule", func(t *testing.T) {
+		env.ResetMocks()
+		mlServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer mlServer.Close()
+		t.Setenv("ML_URL", mlServer.URL)
+
+		env.VersionStore.ExpectedCalls = nil
+		env.VersionStore.On("StoreScheduleVersion", orgID, mock.Anything).Return(errors.New("db error"))
+		employee := mockValidSchedulePrediction(env, orgID)
+		env.ScheduleStore.On("StoreScheduleForUser", orgID, employee.ID, mock.AnythingOfType("*database.Schedule"), admin.ID).Return(nil)
+		env.UserStore.On("GetUserByID", employee.ID).Return(employee, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/schedule/predict", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Nil(t, resp["schedule_version_id"])
+		// the employee submitted no preferences, so there is nothing to be satisfied
+		satisfaction := resp["preference_satisfaction"].(map[string]any)
+		assert.Nil(t, satisfaction["percent"])
+		assert.Equal(t, 1.0, satisfaction["shifts"])
+	})
+
+	t.Run("Success_ClosedDayExcluded", func(t *testing.T) {
+		env.ResetMocks()
+		mlServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer mlServer.Close()
+		t.Setenv("ML_URL", mlServer.URL)
+
+		// The only demand is on monday, and the next monday is closed
+		monday := time.Now()
+		for monday.Weekday() != time.Monday {
+			monday = monday.AddDate(0, 0, 1)
+		}
+		closed := database.OperatingHoursException{Date: time.Date(monday.Year(), monday.Month(), monday.Day(), 0, 0, 0, 0, time.UTC)}
+		env.ExceptionStore.On("GetExceptionsBetween", orgID, mock.Anything, mock.Anything).Return([]database.OperatingHoursException{closed}, nil)
+		mockValidSchedulePrediction(env, orgID)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/schedule/predict", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "demand predictions are required")
+		env.ScheduleStore.AssertNotCalled(t, "StoreScheduleForUser")
+	})
+
+	t.Run("Failure_ClosedDaysError", func(t *testing.T) {
+		env.ResetMocks()
+		env.ExceptionStore.On("GetExceptionsBetween", orgID, mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+		mockValidSchedulePrediction(env, orgID)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/schedule/predict", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Body.String(), "failed to get organization closed days")
+	})
+
+	t.Run("Success_ExpiredWorkPermitExcluded", func(t *testing.T) {
+		env.ResetMocks()
+		employee := mockValidSchedulePrediction(env, orgID)
+		yesterday := time.Now().UTC().AddDate(0, 0, -1).Truncate(24 * time.Hour)
+		env.ComplianceStore.ExpectedCalls = nil
+		env.ComplianceStore.On("GetWorkPermitExpiries", orgID).Return(map[uuid.UUID]time.Time{employee.ID: yesterday}, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/schedule/predict", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "at least one employee is required")
+		env.PreferenceStore.AssertNotCalled(t, "GetPreferencesByEmployeeID", employee.ID)
+		env.ScheduleStore.AssertNotCalled(t, "StoreScheduleForUser")
+	})
+
+	t.Run("Failure_WorkPermitsError", func(t *testing.T) {
+		env.ResetMocks()
+		mockValidSchedulePrediction(env, orgID)
+		env.ComplianceStore.ExpectedCalls = nil
+		env.ComplianceStore.On("GetWorkPermitExpiries", orgID).Return(nil, errors.New("db error")).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/schedule/predict", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Body.String(), "failed to get employee work permits")
+	})
+
+	t.Run("Failure_MLClientError", func(t *testing.T) {
+		env.ResetMocks()
+		mlServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("bad payload"))
+		}))
+		defer mlServer.Close()
+		t.Setenv("ML_URL", mlServer.URL)
+
+		mockValidSchedulePrediction(env, orgID)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/schedule/predict", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "bad payload")
+		env.ScheduleStore.AssertNotCalled(t, "StoreScheduleForUser")
+	})
+}
+
+// mockValidSchedulePrediction registers store expectations for a minimal organization that
+// passes payload validation: one server needed from 09:00 to 13:00 on monday and one employee.
+func mockValidSchedulePrediction(env *ScheduleTestEnv, orgID uuid.UUID) *database.User {
+	lat, long := 30.0, 31.0
+	slotLen := 1.0
+	maxHours := 40
+	org := &database.Organization{ID: orgID, Name: "Test Org", Type: "restaurant", Location: database.Location{Latitude: &lat, Longitude: &long}}
+	rules := &database.OrganizationRules{OrganizationID: orgID, SlotLenHour: slotLen, MinShiftLengthSlots: 2, MinRestSlots: 0}
+	opHours := []database.OperatingHours{{Weekday: "monday", OpeningTime: "09:00:00", ClosingTime: "13:00:00"}}
+	demand := &database.DemandPredictResponse{Days: []database.PredictionDay{
+		{Day: "monday", Hours: []database.PredictionHour{{HourNo: 9, ItemCount: 4}, {HourNo: 10, ItemCount: 8}}},
+	}}
+	roles := []database.OrganizationRole{{OrganizationID: orgID, Role: "server", MinNeededPerShift: 1}}
+	salary := 15.0
+	employee := &database.User{ID: uuid.New(), FullName: "Sam Server", UserRole: "employee", OrganizationID: orgID, SalaryPerHour: &salary, MaxHoursPerWeek: &maxHours}
+
+	env.OrgStore.On("GetOrganizationByID", orgID).Return(org, nil)
+	env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(rules, nil)
+	env.OperatingHoursStore.On("GetOperatingHours", orgID).Return(opHours, nil)
+	env.DemandStore.On("GetLatestDemandHeatMap", orgID).Return(demand, nil)
+	env.ExceptionStore.On("GetExceptionsBetween", orgID, mock.Anything, mock.Anything).Return([]database.OperatingHoursException{}, nil)
+	env.RoleStore.On("GetRolesByOrganizationID", orgID).Return(roles, nil)
+	env.UserStore.On("GetUsersByOrganization", orgID).Return([]*database.User{employee}, nil)
+	env.PreferenceStore.On("GetPreferencesByEmployeeID", employee.ID).Return([]database.EmployeePreference{}, nil)
+	env.PreferenceStore.On("GetUnavailability", employee.ID, mock.Anything, mock.Anything).Return([]database.EmployeeUnavailability{}, nil)
+	env.UserRolesStore.On("GetUserRoles", employee.ID, orgID).Return([]string{"employee", "server"}, nil)
+	env.ComplianceStore.On("GetWorkPermitExpiries", orgID).Return(map[uuid.UUID]time.Time{}, nil)
+	return employee
+}
+
+// --- Schedule versions ---
+
+func TestGetScheduleVersionsHandler(t *testing.T) {
+	env := setupScheduleEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+
+	env.Router.GET("/:org/schedule/versions", authMiddleware(manager), env.Handler.GetScheduleVersionsHandler)
+
+	t.Run("Success_DefaultLimit", func(t *testing.T) {
+		env.ResetMocks()
+		percent := 75.0
+		versions := []database.ScheduleVersion{{ID: uuid.New(), Source: "ml", Shifts: 8, PreferredShifts: 6, PreferenceSatisfactionPercent: &percent}}
+		env.VersionStore.On("GetScheduleVersions", orgID, 10).Return(versions, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule/versions", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		data := resp["data"].([]any)
+		assert.Len(t, data, 1)
+		assert.Equal(t, 75.0, data[0].(map[string]any)["preference_satisfaction_percent"])
+	})
+
+	t.Run("Success_Limit", func(t *testing.T) {
+		env.ResetMocks()
+		env.VersionStore.On("GetScheduleVersions", orgID, 3).Return([]database.ScheduleVersion{}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule/versions?limit=3", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.VersionStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_InvalidLimit", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule/versions?limit=500", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		env.VersionStore.AssertNotCalled(t, "GetScheduleVersions", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failure_DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.VersionStore.On("GetScheduleVersions", orgID, 10).Return(nil, errors.New("db error"))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule/versions", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+		router := gin.New()
+		router.GET("/:org/schedule/versions", authMiddleware(employee), env.Handler.GetScheduleVersionsHandler)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule/versions", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestGetScheduleVersionHandler(t *testing.T) {
+	env := setupScheduleEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	versionID := uuid.New()
+
+	env.Router.GET("/:org/schedule/versions/:id", authMiddleware(admin), env.Handler.GetScheduleVersionHandler)
+
+	low, high := 25.0, 100.0
+	version := &database.ScheduleVersion{ID: versionID, Source: "ml", Shifts: 5, PreferredShifts: 2, Employees: []database.EmployeeSatisfaction{
+		{EmployeeID: uuid.New(), EmployeeName: "Ana", Shifts: 4, PreferredShifts: 1, SatisfactionPercent: &low},
+		{EmployeeID: uuid.New(), EmployeeName: "Ben", Shifts: 1, PreferredShifts: 1, SatisfactionPercent: &high},
+	}}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.VersionStore.On("GetScheduleVersion", orgID, versionID).Return(version, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule/versions/"+versionID.String(), nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		employees := resp["data"].(map[string]any)["employees"].([]any)
+		assert.Len(t, employees, 2)
+		assert.Equal(t, "Ana", employees[0].(map[string]any)["employee_name"])
+		assert.Equal(t, 25.0, employees[0].(map[string]any)["satisfaction_percent"])
+	})
+
+	t.Run("Success_Latest", func(t *testing.T) {
+		env.ResetMocks()
+		env.VersionStore.On("GetScheduleVersions", orgID, 1).Return([]database.ScheduleVersion{{ID: versionID}}, nil)
+		env.VersionStore.On("GetScheduleVersion", orgID, versionID).Return(version, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule/versions/latest", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.VersionStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_LatestNoneGenerated", func(t *testing.T) {
+		env.ResetMocks()
+		env.VersionStore.On("GetScheduleVersions", orgID, 1).Return([]database.ScheduleVersion{}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule/versions/latest", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Failure_InvalidID", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule/versions/not-a-uuid", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Failure_NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.VersionStore.On("GetScheduleVersion", orgID, versionID).Return(nil, sql.ErrNoRows)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule/versions/"+versionID.String(), nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Failure_DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.VersionStore.On("GetScheduleVersion", orgID, versionID).Return(nil, errors.New("db error"))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule/versions/"+versionID.String(), nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+// --- CompareScheduleScenariosHandler ---
+
+func TestCompareScheduleScenariosHandler(t *testing.T) {
+	env := setupScheduleEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+
+	env.Router.POST("/:org/schedule/scenarios", authMiddleware(admin), env.Handler.CompareScheduleScenariosHandler)
+
+	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+		router := gin.New()
+		router.POST("/:org/schedule/scenarios", authMiddleware(employee), env.Handler.CompareScheduleScenariosHandler)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/schedule/scenarios", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Failure_NoScenarios", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/schedule/scenarios", strings.NewReader(`{"scenarios": []}`))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		env.OrgStore.AssertNotCalled(t, "GetOrganizationByID", orgID)
+	})
+
+	t.Run("Success_ComparesScenarios", func(t *testing.T) {
+		env.ResetMocks()
+		mlServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer mlServer.Close()
+		t.Setenv("ML_URL", mlServer.URL)
+
+		employee := mockValidSchedulePrediction(env, orgID)
+
+		body := `{"scenarios": [
+			{"name": "baseline", "max_labor_cost": 50},
+			{"name": "without sam", "exclude_employees": ["` + employee.ID.String() + `"]}
+		]}`
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/schedule/scenarios", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data []api.ScheduleScenarioResult `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Len(t, resp.Data, 2)
+
+		// The 50 budget only pays for 3 of the 4 hours at 15/hour
+		baseline := resp.Data[0]
+		assert.Equal(t, "baseline", baseline.Name)
+		assert.Equal(t, "heuristic_fallback", baseline.ScheduleSource)
+		assert.Equal(t, 45.0, baseline.LaborCost)
+		assert.Equal(t, 3.0, baseline.ScheduledHours)
+		assert.Equal(t, 75.0, baseline.CoveragePercent)
+		assert.Nil(t, baseline.PreferenceSatisfactionPercent)
+		assert.NotNil(t, baseline.WithinBudget)
+		assert.True(t, *baseline.WithinBudget)
+		assert.Equal(t, 90.0, *baseline.BudgetUtilization.UtilizationPercent)
+
+		excluded := resp.Data[1]
+		assert.Equal(t, "without sam", excluded.Name)
+		assert.Contains(t, excluded.Details, "at least one employee is required to generate a schedule")
+
+		// Scenarios are previews and never stored
+		env.ScheduleStore.AssertNotCalled(t, "StoreScheduleForUser")
+	})
+
+	t.Run("MLRejection_ErrorNotExposed", func(t *testing.T) {
+		env.ResetMocks()
+		mlServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"detail": "internal solver trace"}`))
+		}))
+		defer mlServer.Close()
+		t.Setenv("ML_URL", mlServer.URL)
+
+		mockValidSchedulePrediction(env, orgID)
+
+		body := `{"scenarios": [{"name": "baseline"}]}`
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/schedule/scenarios", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data []api.ScheduleScenarioResult `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Len(t, resp.Data, 1)
+		assert.Equal(t, "failed to generate the schedule of the scenario", resp.Data[0].Error)
+		assert.NotContains(t, w.Body.String(), "ML service")
+	})
+}
+
+// --- ReplayScheduleHandler ---
+
+func TestReplayScheduleHandler(t *testing.T) {
+	env := setupScheduleEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/schedule/replay"
+	path := "/" + orgID.String() + "/schedule/replay"
+	handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.ReplayScheduleHandler}
+	weekStart := time.Date(2026, 1, 5, 0, 0, 0, 0, time.Local)
+	weekEnd := weekStart.AddDate(0, 0, 7)
+	replay := func(w *httptest.ResponseRecorder) (api.ScheduleReplayWeek, api.ScheduleReplayResult, []api.ScheduleReplayResult) {
+		var resp struct {
+			Data struct {
+				Week      api.ScheduleReplayWeek     `json:"week"`
+				Current   api.ScheduleReplayResult   `json:"current"`
+				Scenarios []api.ScheduleReplayResult `json:"scenarios"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Data.Week, resp.Data.Current, resp.Data.Scenarios
+	}
+
+	t.Run("Success_ComparesRules", func(t *testing.T) {
+		env.ResetMocks()
+		employee := mockValidSchedulePrediction(env, orgID)
+		env.OrderStore.On("GetHourlyDemand", orgID, weekStart, weekEnd).Return([]database.HourlyDemand{
+			{Date: weekStart, Hour: 9, Orders: 3, Items: 4},
+			{Date: weekStart, Hour: 10, Orders: 5, Items: 8},
+		}, nil).Once()
+		env.ScheduleStore.On("GetScheduledHours", orgID, weekStart, weekEnd).Return([]database.EmployeeHours{
+			{EmployeeID: employee.ID, WorkingHours: 6},
+		}, nil).Once()
+
+		body := `{"week_start": "2026-01-05", "scenarios": [
+			{"name": "tight budget", "max_labor_cost": 30},
+			{"name": "budget but meet all demand", "max_labor_cost": 30, "meet_all_demand": true}
+		]}`
+		w := jobRequest("POST", route, path, handlers, json.RawMessage(body))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		week, current, scenarios := replay(w)
+		assert.Equal(t, "2026-01-05", week.From)
+		assert.Equal(t, "2026-01-11", week.To)
+		assert.Equal(t, 8, week.Orders)
+		assert.Equal(t, 12, week.Items)
+		assert.Equal(t, 90.0, week.ScheduledLaborCost)
+		assert.Equal(t, 6.0, week.ScheduledHours)
+
+		assert.Equal(t, "current rules", current.Name)
+		assert.Equal(t, 60.0, current.LaborCost)
+		assert.Equal(t, 100.0, current.CoveragePercent)
+
+		// The 30 budget only pays for 2 of the 4 hours at 15/hour
+		if assert.Len(t, scenarios, 2) {
+			assert.Equal(t, 30.0, scenarios[0].LaborCost)
+			assert.Equal(t, 50.0, scenarios[0].CoveragePercent)
+			assert.Equal(t, 2, scenarios[0].UncoveredSlots)
+			assert.Equal(t, -30.0, *scenarios[0].LaborCostDifference)
+			assert.Equal(t, -50.0, *scenarios[0].CoveragePercentDifference)
+
+			assert.Equal(t, 60.0, scenarios[1].LaborCost)
+			assert.Equal(t, 0.0, *scenarios[1].LaborCostDifference)
+		}
+
+		// Replays are analyses and never stored or sent to the ML service
+		env.ScheduleStore.AssertNotCalled(t, "StoreScheduleForUser")
+	})
+
+	t.Run("Failure_WeekNotOver", func(t *testing.T) {
+		env.ResetMocks()
+		weekStart := time.Now().AddDate(0, 0, -3).Format(time.DateOnly)
+
+		w := jobRequest("POST", route, path, handlers, json.RawMessage(`{"week_start": "`+weekStart+`", "scenarios": [{"name": "a"}]}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Only weeks that are over can be replayed")
+		env.OrderStore.AssertNotCalled(t, "GetHourlyDemand", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failure_InvalidRules", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("POST", route, path, handlers, json.RawMessage(`{"week_start": "2026-01-05", "scenarios": [{"name": "a", "slot_len_hour": 0}]}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.ReplayScheduleHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Failure_DBError", func(t *testing.T) {
+		env.ResetMocks()
+		mockValidSchedulePrediction(env, orgID)
+		env.OrderStore.On("GetHourlyDemand", orgID, weekStart, weekEnd).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("POST", route, path, handlers, json.RawMessage(`{"week_start": "2026-01-05", "scenarios": [{"name": "a"}]}`))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+// --- GetScheduleReadinessHandler ---
+
+func TestGetScheduleReadinessHandler(t *testing.T) {
+	env := setupScheduleEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/schedule/readiness"
+	path := "/" + orgID.String() + "/schedule/readiness"
+	handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetScheduleReadinessHandler}
+	readiness := func(w *httptest.ResponseRecorder) api.ScheduleReadiness {
+		var resp struct {
+			Data api.ScheduleReadiness `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Data
+	}
+
+	t.Run("Ready", func(t *testing.T) {
+		env.ResetMocks()
+		mockValidSchedulePrediction(env, orgID)
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		data := readiness(w)
+		assert.True(t, data.Ready)
+		assert.Equal(t, 4, data.SlotsChecked)
+		assert.Empty(t, data.Shortfalls)
+		assert.Equal(t, []api.RoleReadiness{{Role: "server", Employees: 1, NeededHours: 4, AvailableHours: 4}}, data.Roles)
+		// nothing is generated or sent to the ML service
+		env.ScheduleStore.AssertNotCalled(t, "StoreScheduleForUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ShortfallsFromAvailability", func(t *testing.T) {
+		env.ResetMocks()
+		employee := mockValidSchedulePrediction(env, orgID)
+		from, to := "11:00", "13:00"
+		env.PreferenceStore.ExpectedCalls = nil
+		env.PreferenceStore.On("GetPreferencesByEmployeeID", employee.ID).
+			Return([]database.EmployeePreference{{EmployeeID: employee.ID, Day: "monday", AvailableStartTime: &from, AvailableEndTime: &to}}, nil)
+		env.PreferenceStore.On("GetUnavailability", employee.ID, mock.Anything, mock.Anything).Return([]database.EmployeeUnavailability{}, nil)
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		data := readiness(w)
+		assert.False(t, data.Ready)
+		assert.Equal(t, 2, data.ShortSlots)
+		assert.Equal(t, []api.SlotShortfall{
+			{Day: "monday", Time: "09:00-10:00", Role: "server", Required: 1, Available: 0, Shortfall: 1},
+			{Day: "monday", Time: "10:00-11:00", Role: "server", Required: 1, Available: 0, Shortfall: 1},
+		}, data.Shortfalls)
+		assert.Equal(t, []api.RoleReadiness{{Role: "server", Employees: 1, NeededHours: 4, AvailableHours: 2, ShortSlots: 2}}, data.Roles)
+	})
+
+	t.Run("ApprovedUnavailabilityExcluded", func(t *testing.T) {
+		env.ResetMocks()
+		employee := mockValidSchedulePrediction(env, orgID)
+		// the employee never submitted availability, but their holiday covers the next monday
+		monday := time.Now()
+		for monday.Weekday() != time.Monday {
+			monday = monday.AddDate(0, 0, 1)
+		}
+		holiday := time.Date(monday.Year(), monday.Month(), monday.Day(), 0, 0, 0, 0, time.UTC)
+		env.PreferenceStore.ExpectedCalls = nil
+		env.PreferenceStore.On("GetPreferencesByEmployeeID", employee.ID).Return([]database.EmployeePreference{}, nil)
+		env.PreferenceStore.On("GetUnavailability", employee.ID, mock.Anything, mock.Anything).
+			Return([]database.EmployeeUnavailability{{EmployeeID: employee.ID, Reason: "holiday", StartDate: holiday, EndDate: holiday}}, nil)
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		data := readiness(w)
+		assert.False(t, data.Ready)
+		assert.Equal(t, 4, data.ShortSlots)
+		assert.Equal(t, []api.RoleReadiness{{Role: "server", Employees: 1, NeededHours: 4, AvailableHours: 0, ShortSlots: 4}}, data.Roles)
+	})
+
+	t.Run("UnavailabilityError", func(t *testing.T) {
+		env.ResetMocks()
+		employee := mockValidSchedulePrediction(env, orgID)
+		env.PreferenceStore.ExpectedCalls = nil
+		env.PreferenceStore.On("GetPreferencesByEmployeeID", employee.ID).Return([]database.EmployeePreference{}, nil)
+		env.PreferenceStore.On("GetUnavailability", employee.ID, mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Body.String(), "failed to get employee unavailability")
+	})
+
+	t.Run("InvalidInput_ReportsProblems", func(t *testing.T) {
+		env.ResetMocks()
+		// registered first, so it is used instead of the located organization of the helper
+		env.OrgStore.On("GetOrganizationByID", orgID).Return(&database.Organization{ID: orgID, Name: "Test Org", Type: "restaurant"}, nil).Once()
+		mockValidSchedulePrediction(env, orgID)
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		data := readiness(w)
+		assert.False(t, data.Ready)
+		assert.Empty(t, data.Shortfalls)
+		assert.Contains(t, data.Problems, "organization latitude is required, please update the organization location")
+	})
+
+	t.Run("NoDemand", func(t *testing.T) {
+		env.ResetMocks()
+		env.OrgStore.On("GetOrganizationByID", orgID).Return(&database.Organization{ID: orgID}, nil).Once()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(&database.OrganizationRules{OrganizationID: orgID}, nil).Once()
+		env.OperatingHoursStore.On("GetOperatingHours", orgID).Return([]database.OperatingHours{}, nil).Once()
+		env.DemandStore.On("GetLatestDemandHeatMap", orgID).Return(nil, nil).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "no demand predictions found")
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetScheduleReadinessHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		env.OrgStore.AssertNotCalled(t, "GetOrganizationByID", orgID)
+	})
+}
+
+// --- AcknowledgeScheduleHandler ---
+
+func TestAcknowledgeScheduleHandler(t *testing.T) {
+	env := setupScheduleEnv()
+	orgID := uuid.New()
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+	env.Router.POST("/:org/me/schedule/acknowledge", authMiddleware(employee), env.Handler.AcknowledgeScheduleHandler)
+
+	t.Run("Success_AllShifts", func(t *testing.T) {
+		env.ResetMocks()
+		env.ScheduleStore.On("AcknowledgeShifts", orgID, employee.ID, []database.ShiftKey{}).Return(int64(3), nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/me/schedule/acknowledge", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"acknowledged_shifts":3`)
+		env.ScheduleStore.AssertExpectations(t)
+	})
+
+	t.Run("Success_SelectedShifts", func(t *testing.T) {
+		env.ResetMocks()
+		expected := []database.ShiftKey{
+			{Date: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), StartTime: "09:00:00", EndTime: "13:00:00"},
+		}
+		env.ScheduleStore.On("AcknowledgeShifts", orgID, employee.ID, expected).Return(int64(1), nil).Once()
+
+		body := `{"shifts":[{"schedule_date":"2026-10-19","start_time":"09:00:00","end_time":"13:00:00"}]}`
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/me/schedule/acknowledge", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"acknowledged_shifts":1`)
+		env.ScheduleStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_InvalidDate", func(t *testing.T) {
+		env.ResetMocks()
+
+		body := `{"shifts":[{"schedule_date":"19/10/2026","start_time":"09:00","end_time":"13:00"}]}`
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/me/schedule/acknowledge", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "schedule_date")
+		env.ScheduleStore.AssertNotCalled(t, "AcknowledgeShifts", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failure_AdminForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+		router := gin.New()
+		router.POST("/:org/me/schedule/acknowledge", authMiddleware(admin), env.Handler.AcknowledgeScheduleHandler)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/me/schedule/acknowledge", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Failure_DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.ScheduleStore.On("AcknowledgeShifts", orgID, employee.ID, []database.ShiftKey{}).Return(int64(0), errors.New("db error")).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/me/schedule/acknowledge", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Body.String(), "Failed to acknowledge schedule")
+		env.ScheduleStore.AssertExpectations(t)
+	})
+}
+
+// --- GetScheduleAcknowledgmentsHandler ---
+
+func TestGetScheduleAcknowledgmentsHandler(t *testing.T) {
+	env := setupScheduleEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+
+	env.Router.GET("/:org/schedule/acknowledgments", authMiddleware(manager), env.Handler.GetScheduleAcknowledgmentsHandler)
+
+	t.Run("Success_Summary", func(t *testing.T) {
+		env.ResetMocks()
+		samID, alexID := uuid.New(), uuid.New()
+		acknowledgedAt := time.Now().Add(-time.Hour)
+		remindedAt := time.Now().Add(-2 * time.Hour)
+		shifts := []database.ShiftAcknowledgment{
+			{EmployeeID: samID, EmployeeName: "Sam", Day: "monday", StartTime: "09:00:00", EndTime: "13:00:00", AcknowledgedAt: &acknowledgedAt},
+			{EmployeeID: alexID, EmployeeName: "Alex", Day: "monday", StartTime: "09:00:00", EndTime: "13:00:00", LastRemindedAt: &remindedAt},
+			{EmployeeID: samID, EmployeeName: "Sam", Day: "tuesday", StartTime: "09:00:00", EndTime: "13:00:00", AcknowledgedAt: &acknowledgedAt},
+			{EmployeeID: alexID, EmployeeName: "Alex", Day: "tuesday", StartTime: "09:00:00", EndTime: "13:00:00", AcknowledgedAt: &acknowledgedAt},
+		}
+		env.ScheduleStore.On("GetShiftAcknowledgments", orgID, database.ShiftFilter{}).Return(shifts, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule/acknowledgments", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data api.ScheduleAcknowledgmentDashboard `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, 4, resp.Data.TotalShifts)
+		assert.Equal(t, 3, resp.Data.AcknowledgedShifts)
+		assert.Equal(t, 1, resp.Data.PendingShifts)
+		assert.Equal(t, 75.0, resp.Data.AcknowledgmentPercent)
+		if assert.Len(t, resp.Data.Employees, 2) {
+			assert.Equal(t, "Sam", resp.Data.Employees[0].EmployeeName)
+			assert.True(t, resp.Data.Employees[0].FullyAcknowledged)
+			assert.Equal(t, "Alex", resp.Data.Employees[1].EmployeeName)
+			assert.False(t, resp.Data.Employees[1].FullyAcknowledged)
+			assert.Equal(t, 1, resp.Data.Employees[1].PendingShifts)
+			assert.NotNil(t, resp.Data.Employees[1].LastRemindedAt)
+		}
+		env.ScheduleStore.AssertExpectations(t)
+	})
+
+	t.Run("Success_NoShifts", func(t *testing.T) {
+		env.ResetMocks()
+		env.ScheduleStore.On("GetShiftAcknowledgments", orgID, database.ShiftFilter{}).Return(nil, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule/acknowledgments", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"employees":[]`)
+		assert.Contains(t, w.Body.String(), `"shifts":[]`)
+	})
+
+	t.Run("Success_Filtered", func(t *testing.T) {
+		env.ResetMocks()
+		acknowledged := false
+		filter := database.ShiftFilter{Roles: []string{"driver", "cashier"}, Acknowledged: &acknowledged}
+		env.ScheduleStore.On("GetShiftAcknowledgments", orgID, filter).Return(nil, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule/acknowledgments?role=driver&role=cashier&acknowledged=false", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.ScheduleStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_InvalidAcknowledged", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule/acknowledgments?acknowledged=maybe", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "acknowledged must be true or false")
+	})
+
+	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+		router := gin.New()
+		router.GET("/:org/schedule/acknowledgments", authMiddleware(employee), env.Handler.GetScheduleAcknowledgmentsHandler)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule/acknowledgments", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Failure_DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.ScheduleStore.On("GetShiftAcknowledgments", orgID, database.ShiftFilter{}).Return(nil, errors.New("db error")).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule/acknowledgments", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestClearScheduleHandler(t *testing.T) {
+	env := setupScheduleEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	from := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)
+
+	env.Router.DELETE("/:org/schedule", authMiddleware(manager), env.Handler.ClearScheduleHandler)
+
+	clear := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/"+orgID.String()+"/schedule?"+query, nil)
+		env.Router.ServeHTTP(w, req)
+		return w
+	}
+
+	summary := &database.ScheduleClearSummary{
+		From:               from,
+		To:                 to,
+		Shifts:             5,
+		AcknowledgedShifts: 3,
+		QueuedOffers:       1,
+		Employees: []database.ScheduleClearedEmployee{
+			{EmployeeID: uuid.New(), EmployeeName: "Alex", Email: "alex@example.com", Shifts: 3},
+			{EmployeeID: uuid.New(), EmployeeName: "Sam", Email: "sam@example.com", Shifts: 2},
+		},
+	}
+
+	t.Run("DryRunByDefault", func(t *testing.T) {
+		env.ResetMocks()
+		env.ScheduleStore.On("GetScheduleClearSummary", orgID, from, to).Return(summary, nil).Once()
+
+		w := clear("from=2026-10-19&to=2026-10-25")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"dry_run":true`)
+		assert.Contains(t, w.Body.String(), `"shifts":5`)
+		env.ScheduleStore.AssertNotCalled(t, "ClearSchedule", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		env.EmailService.AssertNotCalled(t, "SendScheduleClearedEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Confirmed_ClearsAndNotifies", func(t *testing.T) {
+		env.ResetMocks()
+		env.ScheduleStore.On("ClearSchedule", orgID, from, to, manager.ID).Return(summary, nil).Once()
+		env.EmailService.On("SendScheduleClearedEmail", "alex@example.com", "Alex", "2026-10-19", "2026-10-25").Return(nil).Once()
+		env.EmailService.On("SendScheduleClearedEmail", "sam@example.com", "Sam", "2026-10-19", "2026-10-25").Return(errors.New("smtp down")).Once()
+
+		w := clear("from=2026-10-19&to=2026-10-25&dry_run=false")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"dry_run":false`)
+		assert.Contains(t, w.Body.String(), `"notified_employees":1`)
+		env.ScheduleStore.AssertExpectations(t)
+		env.EmailService.AssertExpectations(t)
+	})
+
+	t.Run("InvalidRange", func(t *testing.T) {
+		env.ResetMocks()
+
+		assert.Equal(t, http.StatusBadRequest, clear("to=2026-10-25").Code)
+		assert.Equal(t, http.StatusBadRequest, clear("from=2026-10-25&to=2026-10-19").Code)
+		assert.Equal(t, http.StatusBadRequest, clear("from=2026-10-01&to=2026-12-01").Code)
+		assert.Equal(t, http.StatusBadRequest, clear("from=2026-10-19&to=2026-10-25&dry_run=maybe").Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.ScheduleStore.On("ClearSchedule", orgID, from, to, manager.ID).Return(nil, errors.New("db error")).Once()
+
+		w := clear("from=2026-10-19&to=2026-10-25&dry_run=false")
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("Forbidden_Employee", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+		router := gin.New()
+		router.DELETE("/:org/schedule", authMiddleware(employee), env.Handler.ClearScheduleHandler)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/"+orgID.String()+"/schedule?from=2026-10-19&to=2026-10-25&dry_run=false", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestCreateStandbyShiftHandler(t *testing.T) {
+	env := setupScheduleEnv()
+	orgID := uuid.New()
+	employeeID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/schedule/shifts/standby"
+	path := "/" + orgID.String() + "/schedule/shifts/standby"
+	handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.CreateStandbyShiftHandler}
+	date := time.Now().UTC().AddDate(0, 0, 3).Truncate(24 * time.Hour)
+	body := map[string]any{"employee_id": employeeID, "date": date.Format(time.DateOnly), "start_time": "17:00", "end_time": "22:00"}
+	key := database.ShiftKey{Date: date, StartTime: "17:00", EndTime: "22:00"}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		shift := &database.Shift{ID: uuid.New(), EmployeeID: employeeID, Date: date, StartTime: "17:00", EndTime: "22:00", ShiftType: database.ShiftStandby}
+		env.ComplianceStore.On("GetWorkPermitExpiries", orgID).Return(map[uuid.UUID]time.Time{employeeID: date}, nil).Once()
+		env.ScheduleStore.On("StoreStandbyShift", orgID, employeeID, key, manager.ID).Return(shift, nil).Once()
+
+		w := jobRequest("POST", route, path, handlers, body)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"shift_type":"standby"`)
+		env.ScheduleStore.AssertExpectations(t)
+	})
+
+	t.Run("StoreErrors", func(t *testing.T) {
+		cases := map[error]int{
+			sql.ErrNoRows:             http.StatusNotFound,
+			database.ErrShiftOverlaps: http.StatusConflict,
+			errors.New("db error"):    http.StatusInternalServerError,
+		}
+		for storeErr, status := range cases {
+			env.ResetMocks()
+			env.ComplianceStore.On("GetWorkPermitExpiries", orgID).Return(map[uuid.UUID]time.Time{}, nil).Once()
+			env.ScheduleStore.On("StoreStandbyShift", orgID, employeeID, key, manager.ID).Return(nil, storeErr).Once()
+
+			w := jobRequest("POST", route, path, handlers, body)
+
+			assert.Equal(t, status, w.Code, storeErr.Error())
+		}
+	})
+
+	t.Run("InvalidRequest", func(t *testing.T) {
+		env.ResetMocks()
+		invalid := []map[string]any{
+			{"employee_id": employeeID, "date": "19-10-2026", "start_time": "17:00", "end_time": "22:00"},
+			{"employee_id": employeeID, "date": date.Format(time.DateOnly), "start_time": "5pm", "end_time": "22:00"},
+			{"employee_id": employeeID, "date": date.Format(time.DateOnly), "start_time": "22:00", "end_time": "17:00"},
+			{"employee_id": employeeID, "date": "2020-01-06", "start_time": "17:00", "end_time": "22:00"},
+			{"date": date.Format(time.DateOnly), "start_time": "17:00", "end_time": "22:00"},
+		}
+		for _, request := range invalid {
+			w := jobRequest("POST", route, path, handlers, request)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, request)
+		}
+		env.ScheduleStore.AssertNotCalled(t, "StoreStandbyShift", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Conflict_WorkPermitExpired", func(t *testing.T) {
+		env.ResetMocks()
+		env.ComplianceStore.On("GetWorkPermitExpiries", orgID).Return(map[uuid.UUID]time.Time{employeeID: date.AddDate(0, 0, -1)}, nil).Once()
+
+		w := jobRequest("POST", route, path, handlers, body)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "work permit expires on "+date.AddDate(0, 0, -1).Format(time.DateOnly))
+		env.ScheduleStore.AssertNotCalled(t, "StoreStandbyShift", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Forbidden_Employee", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: employeeID, OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.CreateStandbyShiftHandler}, body)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestActivateShiftHandler(t *testing.T) {
+	env := setupScheduleEnv()
+	orgID := uuid.New()
+	shiftID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/schedule/shifts/:id/activate"
+	path := "/" + orgID.String() + "/schedule/shifts/" + shiftID.String() + "/activate"
+	handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.ActivateShiftHandler}
+	activatedAt := time.Now()
+	shift := &database.Shift{
+		ID:            shiftID,
+		EmployeeID:    uuid.New(),
+		EmployeeName:  "Sam",
+		EmployeeEmail: "sam@example.com",
+		Date:          time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		StartTime:     "17:00:00",
+		EndTime:       "22:00:00",
+		ShiftType:     database.ShiftWorking,
+		ActivatedAt:   &activatedAt,
+	}
+
+	t.Run("ActivatesAndNotifies", func(t *testing.T) {
+		env.ResetMocks()
+		env.ScheduleStore.On("ActivateShift", orgID, shiftID, mock.AnythingOfType("time.Time"), manager.ID).Return(shift, nil).Once()
+		env.EmailService.On("SendStandbyActivatedEmail", "sam@example.com", "Sam", "2026-10-19", "17:00:00", "22:00:00").Return(nil).Once()
+
+		w := jobRequest("POST", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"shift_type":"working"`)
+		assert.Contains(t, w.Body.String(), `"notified":true`)
+		env.ScheduleStore.AssertExpectations(t)
+		env.EmailService.AssertExpectations(t)
+	})
+
+	t.Run("EmailFailureStillActivates", func(t *testing.T) {
+		env.ResetMocks()
+		env.ScheduleStore.On("ActivateShift", orgID, shiftID, mock.Anything, manager.ID).Return(shift, nil).Once()
+		env.EmailService.On("SendStandbyActivatedEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("smtp down")).Once()
+
+		w := jobRequest("POST", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"notified":false`)
+	})
+
+	t.Run("StoreErrors", func(t *testing.T) {
+		cases := map[error]int{
+			sql.ErrNoRows:               http.StatusNotFound,
+			database.ErrShiftNotStandby: http.StatusConflict,
+			database.ErrShiftEnded:      http.StatusConflict,
+			errors.New("db error"):      http.StatusInternalServerError,
+		}
+		for storeErr, status := range cases {
+			env.ResetMocks()
+			env.ScheduleStore.On("ActivateShift", orgID, shiftID, mock.Anything, manager.ID).Return(nil, storeErr).Once()
+
+			w := jobRequest("POST", route, path, handlers, nil)
+
+			assert.Equal(t, status, w.Code, storeErr.Error())
+			env.EmailService.AssertNotCalled(t, "SendStandbyActivatedEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("POST", route, "/"+orgID.String()+"/schedule/shifts/not-a-uuid/activate", handlers, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Forbidden_Employee", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.ActivateShiftHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		env.ScheduleStore.AssertNotCalled(t, "ActivateShift", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestCancelShiftHandler(t *testing.T) {
+	env := setupScheduleEnv()
+	orgID := uuid.New()
+	shiftID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/schedule/shifts/:id/cancel"
+	path := "/" + orgID.String() + "/schedule/shifts/" + shiftID.String() + "/cancel"
+	handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.CancelShiftHandler}
+	cancellation := &database.ShiftCancellation{
+		ID:                  uuid.New(),
+		ShiftID:             shiftID,
+		EmployeeID:          uuid.New(),
+		EmployeeName:        "Sam",
+		EmployeeEmail:       "sam@example.com",
+		Date:                time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		StartTime:           "17:00:00",
+		EndTime:             "22:00:00",
+		ShiftType:           database.ShiftWorking,
+		Reason:              "Private event cancelled",
+		CancelledBy:         &manager.ID,
+		NoticeHours:         50.5,
+		RequiredNoticeHours: 48,
+	}
+	rules := &database.OrganizationRules{OrganizationID: orgID, CancellationNoticeHours: 48}
+
+	t.Run("CancelsAndNotifies", func(t *testing.T) {
+		env.ResetMocks()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(rules, nil).Once()
+		env.ScheduleStore.On("CancelShift", orgID, shiftID, mock.MatchedBy(func(request database.ShiftCancellationRequest) bool {
+			return request.Reason == "Private event cancelled" && request.RequiredNoticeHours == 48 && !request.AllowLate && request.OfferShift
+		}), manager.ID).Return(cancellation, nil).Once()
+		env.EmailService.On("SendShiftCancelledEmail", "sam@example.com", "Sam", "2026-10-19", "17:00:00", "22:00:00", "Private event cancelled").Return(nil).Once()
+
+		w := jobRequest("POST", route, path, handlers, gin.H{"reason": "Private event cancelled", "offer_to_marketplace": true})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"late":false`)
+		assert.Contains(t, w.Body.String(), `"notified":true`)
+		assert.NotContains(t, w.Body.String(), "sam@example.com")
+		env.ScheduleStore.AssertExpectations(t)
+		env.EmailService.AssertExpectations(t)
+	})
+
+	t.Run("DefaultNoticeWithoutRules", func(t *testing.T) {
+		env.ResetMocks()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(nil, nil).Once()
+		env.ScheduleStore.On("CancelShift", orgID, shiftID, mock.MatchedBy(func(request database.ShiftCancellationRequest) bool {
+			return request.RequiredNoticeHours == 24
+		}), manager.ID).Return(cancellation, nil).Once()
+		env.EmailService.On("SendShiftCancelledEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+		w := jobRequest("POST", route, path, handlers, gin.H{"reason": "Overstaffed"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.ScheduleStore.AssertExpectations(t)
+	})
+
+	t.Run("LateWithOverride", func(t *testing.T) {
+		env.ResetMocks()
+		late := *cancellation
+		late.NoticeHours, late.Late = 3, true
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(rules, nil).Once()
+		env.ScheduleStore.On("CancelShift", orgID, shiftID, mock.MatchedBy(func(request database.ShiftCancellationRequest) bool {
+			return request.AllowLate
+		}), manager.ID).Return(&late, nil).Once()
+		env.EmailService.On("SendShiftCancelledEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("smtp down")).Once()
+
+		w := jobRequest("POST", route, path, handlers, gin.H{"reason": "Kitchen flooded", "override_notice": true})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"late":true`)
+		assert.Contains(t, w.Body.String(), `"notified":false`)
+	})
+
+	t.Run("StoreErrors", func(t *testing.T) {
+		cases := map[error]int{
+			sql.ErrNoRows:                  http.StatusNotFound,
+			database.ErrShiftStarted:       http.StatusConflict,
+			database.ErrCancellationNotice: http.StatusConflict,
+			errors.New("db error"):         http.StatusInternalServerError,
+		}
+		for storeErr, status := range cases {
+			env.ResetMocks()
+			env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(rules, nil).Once()
+			env.ScheduleStore.On("CancelShift", orgID, shiftID, mock.Anything, manager.ID).Return(nil, storeErr).Once()
+
+			w := jobRequest("POST", route, path, handlers, gin.H{"reason": "Overstaffed"})
+
+			assert.Equal(t, status, w.Code, storeErr.Error())
+			if storeErr == database.ErrCancellationNotice {
+				assert.Contains(t, w.Body.String(), "at least 48 hours")
+			}
+			env.EmailService.AssertNotCalled(t, "SendShiftCancelledEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("RulesError", func(t *testing.T) {
+		env.ResetMocks()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("POST", route, path, handlers, gin.H{"reason": "Overstaffed"})
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		env.ScheduleStore.AssertNotCalled(t, "CancelShift", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("MissingReason", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("POST", route, path, handlers, gin.H{"offer_to_marketplace": true})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("POST", route, "/"+orgID.String()+"/schedule/shifts/not-a-uuid/cancel", handlers, gin.H{"reason": "Overstaffed"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Forbidden_Employee", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.CancelShiftHandler}, gin.H{"reason": "Overstaffed"})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		env.ScheduleStore.AssertNotCalled(t, "CancelShift", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetShiftCancellationsHandler(t *testing.T) {
+	env := setupScheduleEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	route := "/:org/schedule/cancellations"
+	path := "/" + orgID.String() + "/schedule/cancellations"
+	handlers := []gin.HandlerFunc{authMiddleware(admin), env.Handler.GetShiftCancellationsHandler}
+
+	t.Run("CountsLate", func(t *testing.T) {
+		env.ResetMocks()
+		env.ScheduleStore.On("GetShiftCancellations", orgID, database.CancellationFilter{}).Return([]database.ShiftCancellation{
+			{ID: uuid.New(), Late: true, NoticeHours: 3},
+			{ID: uuid.New(), NoticeHours: 72},
+		}, nil).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		data := resp["data"].(map[string]any)
+		assert.Equal(t, 2.0, data["total"])
+		assert.Equal(t, 1.0, data["late"])
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		env.ResetMocks()
+		env.ScheduleStore.On("GetShiftCancellations", orgID, mock.MatchedBy(func(filter database.CancellationFilter) bool {
+			return filter.Late != nil && *filter.Late && filter.From.Format(time.DateOnly) == "2026-10-01" && filter.To.Format(time.DateOnly) == "2026-11-01"
+		})).Return([]database.ShiftCancellation{}, nil).Once()
+
+		w := jobRequest("GET", route, path+"?from=2026-10-01&to=2026-10-31&late=true", handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.ScheduleStore.AssertExpectations(t)
+	})
+
+	t.Run("InvalidLate", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := jobRequest("GET", route, path+"?late=maybe", handlers, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.ScheduleStore.On("GetShiftCancellations", orgID, mock.Anything).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("Forbidden_Employee", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetShiftCancellationsHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestGetShiftEventsHandler(t *testing.T) {
+	env := setupScheduleEnv()
+	orgID := uuid.New()
+	shiftID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	sam := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	alex := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	route := "/:org/schedule/shifts/:id/events"
+	path := "/" + orgID.String() + "/schedule/shifts/" + shiftID.String() + "/events"
+	date := time.Date(2026, 10, 24, 0, 0, 0, 0, time.UTC)
+	events := []database.ScheduleEvent{
+		{ShiftID: shiftID, EventType: database.ScheduleEventGenerated, EmployeeID: sam.ID, Date: date, StartTime: "17:00:00", EndTime: "22:00:00", ShiftType: database.ShiftStandby},
+		{ShiftID: shiftID, EventType: database.ScheduleEventEdited, EmployeeID: sam.ID, Date: date, StartTime: "17:00:00", EndTime: "22:00:00", ShiftType: database.ShiftWorking, ActorID: &manager.ID},
+	}
+	get := func(user *database.User, path string) *httptest.ResponseRecorder {
+		return jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(user), env.Handler.GetShiftEventsHandler}, nil)
+	}
+
+	t.Run("Manager", func(t *testing.T) {
+		env.ResetMocks()
+		env.ScheduleStore.On("GetShiftEvents", orgID, shiftID).Return(events, nil).Once()
+
+		w := get(manager, path)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"event_type":"edited"`)
+		env.ScheduleStore.AssertExpectations(t)
+	})
+
+	t.Run("EmployeeOwnShift", func(t *testing.T) {
+		env.ResetMocks()
+		env.ScheduleStore.On("GetShiftEvents", orgID, shiftID).Return(events, nil).Once()
+
+		assert.Equal(t, http.StatusOK, get(sam, path).Code)
+	})
+
+	t.Run("EmployeeOtherShift", func(t *testing.T) {
+		env.ResetMocks()
+		env.ScheduleStore.On("GetShiftEvents", orgID, shiftID).Return(events, nil).Once()
+
+		assert.Equal(t, http.StatusNotFound, get(alex, path).Code)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.ScheduleStore.On("GetShiftEvents", orgID, shiftID).Return([]database.ScheduleEvent{}, nil).Once()
+
+		assert.Equal(t, http.StatusNotFound, get(manager, path).Code)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		env.ResetMocks()
+
+		assert.Equal(t, http.StatusBadRequest, get(manager, "/"+orgID.String()+"/schedule/shifts/abc/events").Code)
+		env.ScheduleStore.AssertNotCalled(t, "GetShiftEvents", mock.Anything, mock.Anything)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.ScheduleStore.On("GetShiftEvents", orgID, shiftID).Return(nil, errors.New("db error")).Once()
+
+		assert.Equal(t, http.StatusInternalServerError, get(manager, path).Code)
+	})
 }
@@ -0,0 +1,280 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type SecurityTestEnv struct {
+	Router             *gin.Engine
+	LoginSecurityStore *MockLoginSecurityStore
+	AuditStore         *MockAuditStore
+	UserStore          *MockUserStore
+	EmailService       *MockEmailService
+	Handler            *api.SecurityHandler
+}
+
+func setupSecurityEnv() *SecurityTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	loginSecurityStore := new(MockLoginSecurityStore)
+	auditStore := new(MockAuditStore)
+	userStore := new(MockUserStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &SecurityTestEnv{
+		Router:             gin.New(),
+		LoginSecurityStore: loginSecurityStore,
+		AuditStore:         auditStore,
+		UserStore:          userStore,
+		EmailService:       new(MockEmailService),
+		Handler:            api.NewSecurityHandler(loginSecurityStore, auditStore, userStore, logger),
+	}
+}
+
+func (env *SecurityTestEnv) ResetMocks() {
+	env.LoginSecurityStore.ExpectedCalls = nil
+	env.LoginSecurityStore.Calls = nil
+	env.AuditStore.ExpectedCalls = nil
+	env.AuditStore.Calls = nil
+	env.UserStore.ExpectedCalls = nil
+	env.UserStore.Calls = nil
+	env.EmailService.ExpectedCalls = nil
+	env.EmailService.Calls = nil
+}
+
+func TestUnlockAccountHandler(t *testing.T) {
+	env := setupSecurityEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+	unlock := func(user *database.User, employeeID string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.POST("/:org/staffing/employees/:id/unlock", authMiddleware(user), env.Handler.UnlockAccountHandler)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/staffing/employees/"+employeeID+"/unlock", nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByID", employee.ID).Return(employee, nil)
+		env.LoginSecurityStore.On("UnlockAccount", employee.ID).Return(nil)
+		env.AuditStore.On("RecordEvent", mock.MatchedBy(func(e *database.AuditEvent) bool {
+			return e.Action == database.AuditAccountUnlocked && *e.ActorID == admin.ID && *e.TargetUserID == employee.ID
+		})).Return(nil)
+
+		w := unlock(admin, employee.ID.String())
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.AuditStore.AssertExpectations(t)
+	})
+
+	t.Run("OtherOrganization", func(t *testing.T) {
+		env.ResetMocks()
+		outsider := &database.User{ID: uuid.New(), OrganizationID: uuid.New(), UserRole: "employee"}
+		env.UserStore.On("GetUserByID", outsider.ID).Return(outsider, nil)
+
+		w := unlock(admin, outsider.ID.String())
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		env.LoginSecurityStore.AssertNotCalled(t, "UnlockAccount", mock.Anything)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := unlock(admin, "not-a-uuid")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := unlock(manager, employee.ID.String())
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByID", employee.ID).Return(employee, nil)
+		env.LoginSecurityStore.On("UnlockAccount", employee.ID).Return(errors.New("db error"))
+
+		w := unlock(admin, employee.ID.String())
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestGetAuditLogHandler(t *testing.T) {
+	env := setupSecurityEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+	get := func(user *database.User, query string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/:org/audit-log", authMiddleware(user), env.Handler.GetAuditLogHandler)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/audit-log"+query, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.AuditStore.On("GetEvents", orgID, database.AuditAccountLocked, 20).Return([]database.AuditEvent{
+			{ID: uuid.New(), OrganizationID: orgID, Action: database.AuditAccountLocked},
+		}, nil)
+
+		w := get(admin, "?action=account_locked&limit=20")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Len(t, response["data"], 1)
+	})
+
+	t.Run("InvalidLimit", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := get(admin, "?limit=0")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		env.ResetMocks()
+
+		w := get(employee, "")
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestLoginGuard(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	env := setupSecurityEnv()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	guard := &middleware.LoginGuard{
+		Store:        env.LoginSecurityStore,
+		AuditStore:   env.AuditStore,
+		EmailService: env.EmailService,
+		MaxAttempts:  3,
+		Lockout:      15 * time.Minute,
+		Logger:       logger,
+	}
+
+	user := &database.User{ID: uuid.New(), FullName: "Maya", Email: "maya@example.com", OrganizationID: uuid.New(), UserRole: "employee"}
+	user.PasswordHash.Set("correct-password")
+
+	authMw, err := middleware.NewAuthMiddleware(env.UserStore, guard)
+	assert.NoError(t, err)
+	router := gin.New()
+	router.POST("/login", authMw.LoginHandler)
+
+	login := func(password string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		body := `{"email": "maya@example.com", "password": "` + password + `"}`
+		req, _ := http.NewRequest("POST", "/login", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "TestBrowser/1.0")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("WrongPasswordIsCounted", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByEmail", user.Email).Return(user, nil)
+		env.LoginSecurityStore.On("GetLoginState", user.ID).Return(&database.LoginState{}, nil)
+		env.LoginSecurityStore.On("RecordFailedLogin", user.ID, 3, 15*time.Minute).Return(&database.LoginState{FailedAttempts: 1}, nil)
+		env.AuditStore.On("RecordEvent", mock.MatchedBy(func(e *database.AuditEvent) bool { return e.Action == database.AuditLoginFailed })).Return(nil)
+
+		w := login("wrong")
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		env.AuditStore.AssertExpectations(t)
+	})
+
+	t.Run("LockedAfterLimit", func(t *testing.T) {
+		env.ResetMocks()
+		lockedUntil := time.Now().Add(15 * time.Minute)
+		env.UserStore.On("GetUserByEmail", user.Email).Return(user, nil)
+		env.LoginSecurityStore.On("GetLoginState", user.ID).Return(&database.LoginState{}, nil)
+		env.LoginSecurityStore.On("RecordFailedLogin", user.ID, 3, 15*time.Minute).Return(&database.LoginState{LockedUntil: &lockedUntil}, nil)
+		env.AuditStore.On("RecordEvent", mock.Anything).Return(nil)
+
+		w := login("wrong")
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		env.AuditStore.AssertNumberOfCalls(t, "RecordEvent", 2)
+		assert.Equal(t, database.AuditAccountLocked, env.AuditStore.Calls[1].Arguments.Get(0).(*database.AuditEvent).Action)
+	})
+
+	t.Run("LockedAccountRejectsCorrectPassword", func(t *testing.T) {
+		env.ResetMocks()
+		lockedUntil := time.Now().Add(10 * time.Minute)
+		env.UserStore.On("GetUserByEmail", user.Email).Return(user, nil)
+		env.LoginSecurityStore.On("GetLoginState", user.ID).Return(&database.LoginState{LockedUntil: &lockedUntil}, nil)
+
+		w := login("correct-password")
+
+		assert.Equal(t, http.StatusLocked, w.Code)
+		env.LoginSecurityStore.AssertNotCalled(t, "ResetFailedLogins", mock.Anything)
+	})
+
+	t.Run("NewDeviceAlert", func(t *testing.T) {
+		env.ResetMocks()
+		sent := make(chan struct{})
+		env.UserStore.On("GetUserByEmail", user.Email).Return(user, nil)
+		env.LoginSecurityStore.On("GetLoginState", user.ID).Return(&database.LoginState{}, nil)
+		env.LoginSecurityStore.On("ResetFailedLogins", user.ID).Return(nil)
+		env.LoginSecurityStore.On("RememberDevice", user.ID, mock.Anything, mock.Anything, "TestBrowser/1.0").Return(true, nil)
+		env.AuditStore.On("RecordEvent", mock.MatchedBy(func(e *database.AuditEvent) bool { return e.Action == database.AuditNewDeviceLogin })).Return(nil)
+		env.EmailService.On("SendNewLoginEmail", user.Email, user.FullName, "TestBrowser/1.0", mock.Anything, mock.Anything).
+			Run(func(mock.Arguments) { close(sent) }).Return(nil)
+
+		w := login("correct-password")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		select {
+		case <-sent:
+		case <-time.After(time.Second):
+			t.Fatal("new login email was not sent")
+		}
+	})
+
+	t.Run("KnownDevice", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByEmail", user.Email).Return(user, nil)
+		env.LoginSecurityStore.On("GetLoginState", user.ID).Return(&database.LoginState{}, nil)
+		env.LoginSecurityStore.On("ResetFailedLogins", user.ID).Return(nil)
+		env.LoginSecurityStore.On("RememberDevice", user.ID, mock.Anything, mock.Anything, "TestBrowser/1.0").Return(false, nil)
+
+		w := login("correct-password")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.AuditStore.AssertNotCalled(t, "RecordEvent", mock.Anything)
+		env.EmailService.AssertNotCalled(t, "SendNewLoginEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,479 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type SkillTestEnv struct {
+	SkillStore   *MockSkillStore
+	RoleStore    *MockRolesStore
+	OrgStore     *MockOrgStore
+	EmailService *MockEmailService
+	Handler      *api.SkillHandler
+}
+
+func setupSkillEnv() *SkillTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	skillStore := new(MockSkillStore)
+	roleStore := new(MockRolesStore)
+	orgStore := new(MockOrgStore)
+	emailService := new(MockEmailService)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &SkillTestEnv{
+		SkillStore:   skillStore,
+		RoleStore:    roleStore,
+		OrgStore:     orgStore,
+		EmailService: emailService,
+		Handler:      api.NewSkillHandler(skillStore, roleStore, orgStore, emailService, logger),
+	}
+}
+
+func (env *SkillTestEnv) ResetMocks() {
+	env.SkillStore.ExpectedCalls = nil
+	env.SkillStore.Calls = nil
+	env.RoleStore.ExpectedCalls = nil
+	env.RoleStore.Calls = nil
+	env.OrgStore.ExpectedCalls = nil
+	env.OrgStore.Calls = nil
+	env.EmailService.ExpectedCalls = nil
+	env.EmailService.Calls = nil
+}
+
+func TestProposeSkillHandler(t *testing.T) {
+	env := setupSkillEnv()
+	orgID := uuid.New()
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee", FullName: "Sam Server", Email: "sam@example.com"}
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager", FullName: "Alex Manager", Email: "alex@example.com"}
+	route := "/:org/me/skills"
+	path := "/" + orgID.String() + "/me/skills"
+	nextYear := time.Now().AddDate(1, 0, 0).Format(time.DateOnly)
+
+	t.Run("Success_NotifiesManagers", func(t *testing.T) {
+		env.ResetMocks()
+		env.SkillStore.On("ProposeSkill", mock.MatchedBy(func(s *database.EmployeeSkill) bool {
+			return s.EmployeeID == employee.ID && s.Name == "Food handler" && s.Kind == database.SkillKindCertification &&
+				s.ExpiresOn.Format(time.DateOnly) == nextYear
+		})).Run(func(args mock.Arguments) {
+			args.Get(0).(*database.EmployeeSkill).Status = database.SkillPending
+		}).Return(nil).Once()
+		env.OrgStore.On("GetManagerEmailsByOrgID", orgID).Return([]string{"alex@example.com"}, nil).Once()
+		env.EmailService.On("SendSkillProposedEmail", []string{"alex@example.com"}, "Sam Server", "Food handler", database.SkillKindCertification).Return(nil).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.ProposeSkillHandler},
+			map[string]any{"name": "  Food handler ", "kind": "certification", "expires_on": nextYear})
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"pending"`)
+		env.SkillStore.AssertExpectations(t)
+		env.EmailService.AssertExpectations(t)
+	})
+
+	t.Run("Success_ManagerReviewedByAdmins", func(t *testing.T) {
+		env.ResetMocks()
+		env.SkillStore.On("ProposeSkill", mock.Anything).Return(nil).Once()
+		env.OrgStore.On("GetAdminEmailsByOrgID", orgID).Return([]string{"owner@example.com", "alex@example.com"}, nil).Once()
+		env.EmailService.On("SendSkillProposedEmail", []string{"owner@example.com"}, "Alex Manager", "Barista", database.SkillKindSkill).Return(nil).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.ProposeSkillHandler},
+			map[string]any{"name": "Barista"})
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		env.OrgStore.AssertNotCalled(t, "GetManagerEmailsByOrgID", orgID)
+		env.EmailService.AssertExpectations(t)
+	})
+
+	t.Run("Failure_ExpiredCertification", func(t *testing.T) {
+		env.ResetMocks()
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.ProposeSkillHandler},
+			map[string]any{"name": "Food handler", "kind": "certification", "expires_on": "2020-01-31"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		env.SkillStore.AssertNotCalled(t, "ProposeSkill", mock.Anything)
+	})
+
+	t.Run("Failure_InvalidKind", func(t *testing.T) {
+		env.ResetMocks()
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.ProposeSkillHandler},
+			map[string]any{"name": "Barista", "kind": "hobby"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Failure_BlankName", func(t *testing.T) {
+		env.ResetMocks()
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.ProposeSkillHandler},
+			map[string]any{"name": "   "})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestDeleteMySkillHandler(t *testing.T) {
+	env := setupSkillEnv()
+	orgID := uuid.New()
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	skillID := uuid.New()
+	route := "/:org/me/skills/:id"
+	path := "/" + orgID.String() + "/me/skills/" + skillID.String()
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.SkillStore.On("DeleteSkill", employee.ID, skillID).Return(nil).Once()
+
+		w := jobRequest("DELETE", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.DeleteMySkillHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.SkillStore.On("DeleteSkill", employee.ID, skillID).Return(sql.ErrNoRows).Once()
+
+		w := jobRequest("DELETE", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.DeleteMySkillHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestGetSkillsHandler(t *testing.T) {
+	env := setupSkillEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	route := "/:org/skills"
+	path := "/" + orgID.String() + "/skills"
+
+	t.Run("Success_Pending", func(t *testing.T) {
+		env.ResetMocks()
+		skills := []database.EmployeeSkill{{ID: uuid.New(), EmployeeID: employee.ID, Name: "Barista", Status: database.SkillPending}}
+		env.SkillStore.On("GetSkills", orgID, (*uuid.UUID)(nil), database.SkillPending).Return(skills, nil).Once()
+
+		w := jobRequest("GET", route, path+"?status=pending", []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetSkillsHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"name":"Barista"`)
+	})
+
+	t.Run("Success_OfEmployee", func(t *testing.T) {
+		env.ResetMocks()
+		env.SkillStore.On("GetSkills", orgID, &employee.ID, "").Return([]database.EmployeeSkill{}, nil).Once()
+
+		w := jobRequest("GET", route, path+"?employee_id="+employee.ID.String(), []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetSkillsHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.SkillStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_InvalidStatus", func(t *testing.T) {
+		env.ResetMocks()
+		w := jobRequest("GET", route, path+"?status=approved", []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetSkillsHandler}, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetSkillsHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestReviewSkillHandler(t *testing.T) {
+	env := setupSkillEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	skillID := uuid.New()
+	path := "/" + orgID.String() + "/skills/" + skillID.String()
+	pending := func() *database.EmployeeSkill {
+		return &database.EmployeeSkill{ID: skillID, OrganizationID: orgID, EmployeeID: employee.ID, EmployeeName: "Sam Server",
+			EmployeeEmail: "sam@example.com", Name: "Food handler", Kind: database.SkillKindCertification, Status: database.SkillPending}
+	}
+	verify := []gin.HandlerFunc{authMiddleware(manager), env.Handler.VerifySkillHandler}
+
+	t.Run("Success_Verify", func(t *testing.T) {
+		env.ResetMocks()
+		env.SkillStore.On("GetSkill", orgID, skillID).Return(pending(), nil).Once()
+		env.SkillStore.On("ReviewSkill", mock.MatchedBy(func(s *database.EmployeeSkill) bool {
+			return s.Status == database.SkillVerified && *s.ReviewedBy == manager.ID && s.ReviewNote == nil
+		})).Return(nil).Once()
+		env.EmailService.On("SendSkillReviewedEmail", "sam@example.com", "Sam Server", "Food handler", database.SkillVerified, (*string)(nil)).Return(nil).Once()
+
+		w := jobRequest("POST", "/:org/skills/:id/verify", path+"/verify", verify, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"verified"`)
+		env.SkillStore.AssertExpectations(t)
+		env.EmailService.AssertExpectations(t)
+	})
+
+	t.Run("Success_RejectWithNote", func(t *testing.T) {
+		env.ResetMocks()
+		env.SkillStore.On("GetSkill", orgID, skillID).Return(pending(), nil).Once()
+		env.SkillStore.On("ReviewSkill", mock.MatchedBy(func(s *database.EmployeeSkill) bool {
+			return s.Status == database.SkillRejected && *s.ReviewNote == "Please upload the certificate"
+		})).Return(nil).Once()
+		env.EmailService.On("SendSkillReviewedEmail", "sam@example.com", "Sam Server", "Food handler", database.SkillRejected, mock.Anything).Return(nil).Once()
+
+		w := jobRequest("POST", "/:org/skills/:id/reject", path+"/reject", []gin.HandlerFunc{authMiddleware(manager), env.Handler.RejectSkillHandler},
+			map[string]any{"note": "Please upload the certificate"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.EmailService.AssertExpectations(t)
+	})
+
+	t.Run("Failure_OwnSkill", func(t *testing.T) {
+		env.ResetMocks()
+		own := pending()
+		own.EmployeeID = manager.ID
+		env.SkillStore.On("GetSkill", orgID, skillID).Return(own, nil).Once()
+
+		w := jobRequest("POST", "/:org/skills/:id/verify", path+"/verify", verify, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		env.SkillStore.AssertNotCalled(t, "ReviewSkill", mock.Anything)
+	})
+
+	t.Run("Failure_AlreadyReviewed", func(t *testing.T) {
+		env.ResetMocks()
+		reviewed := pending()
+		reviewed.Status = database.SkillRejected
+		env.SkillStore.On("GetSkill", orgID, skillID).Return(reviewed, nil).Once()
+
+		w := jobRequest("POST", "/:org/skills/:id/verify", path+"/verify", verify, nil)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Failure_ReviewedConcurrently", func(t *testing.T) {
+		env.ResetMocks()
+		env.SkillStore.On("GetSkill", orgID, skillID).Return(pending(), nil).Once()
+		env.SkillStore.On("ReviewSkill", mock.Anything).Return(database.ErrSkillReviewed).Once()
+
+		w := jobRequest("POST", "/:org/skills/:id/verify", path+"/verify", verify, nil)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		env.EmailService.AssertNotCalled(t, "SendSkillReviewedEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failure_ExpiredCertification", func(t *testing.T) {
+		env.ResetMocks()
+		expired := pending()
+		lastYear := time.Now().AddDate(-1, 0, 0)
+		expired.ExpiresOn = &lastYear
+		env.SkillStore.On("GetSkill", orgID, skillID).Return(expired, nil).Once()
+
+		w := jobRequest("POST", "/:org/skills/:id/verify", path+"/verify", verify, nil)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "expired")
+	})
+
+	t.Run("Failure_NotFound", func(t *testing.T) {
+		env.ResetMocks()
+		env.SkillStore.On("GetSkill", orgID, skillID).Return(nil, sql.ErrNoRows).Once()
+
+		w := jobRequest("POST", "/:org/skills/:id/verify", path+"/verify", verify, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		w := jobRequest("POST", "/:org/skills/:id/verify", path+"/verify", []gin.HandlerFunc{authMiddleware(employee), env.Handler.VerifySkillHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestSetRoleSkillRequirementHandler(t *testing.T) {
+	env := setupSkillEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/skills/requirements/:role"
+	path := "/" + orgID.String() + "/skills/requirements/bartender"
+	bartender := &database.OrganizationRole{OrganizationID: orgID, Role: "bartender"}
+
+	t.Run("Success_Deduplicated", func(t *testing.T) {
+		env.ResetMocks()
+		env.RoleStore.On("GetRoleByName", orgID, "bartender").Return(bartender, nil).Once()
+		env.SkillStore.On("SetRoleSkillRequirement", orgID, database.RoleSkillRequirement{Role: "bartender", Skills: []string{"Responsible alcohol service", "Mixology"}}, admin.ID).Return(nil).Once()
+
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.SetRoleSkillRequirementHandler},
+			map[string]any{"skills": []string{"Responsible alcohol service", " Mixology", "mixology"}})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.SkillStore.AssertExpectations(t)
+	})
+
+	t.Run("Success_Cleared", func(t *testing.T) {
+		env.ResetMocks()
+		env.RoleStore.On("GetRoleByName", orgID, "bartender").Return(bartender, nil).Once()
+		env.SkillStore.On("SetRoleSkillRequirement", orgID, database.RoleSkillRequirement{Role: "bartender", Skills: []string{}}, admin.ID).Return(nil).Once()
+
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.SetRoleSkillRequirementHandler},
+			map[string]any{"skills": []string{}})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Failure_UnknownRole", func(t *testing.T) {
+		env.ResetMocks()
+		env.RoleStore.On("GetRoleByName", orgID, "bartender").Return(nil, nil).Once()
+
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.SetRoleSkillRequirementHandler},
+			map[string]any{"skills": []string{"Mixology"}})
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Failure_ManagerForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.SetRoleSkillRequirementHandler},
+			map[string]any{"skills": []string{"Mixology"}})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestPredictScheduleSkillRequirements(t *testing.T) {
+	env := setupScheduleEnv()
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	skills := new(MockSkillStore)
+	env.Handler.SkillStore = skills
+	env.Router.POST("/:org/schedule/predict", authMiddleware(admin), env.Handler.PredictScheduleHandler)
+	requirements := []database.RoleSkillRequirement{{Role: "server", Skills: []string{"Food handler"}}}
+
+	// predict returns the role names of the employees sent to the scheduler
+	predict := func() (int, [][]string) {
+		var roleNames [][]string
+		mlServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var input struct {
+				ScheduleInput struct {
+					Employees []struct {
+						RoleNames []string `json:"role_ids"`
+					} `json:"employees"`
+				} `json:"schedule_input"`
+			}
+			body, _ := io.ReadAll(r.Body)
+			json.Unmarshal(body, &input)
+			for _, employee := range input.ScheduleInput.Employees {
+				roleNames = append(roleNames, employee.RoleNames)
+			}
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer mlServer.Close()
+		t.Setenv("ML_URL", mlServer.URL)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/schedule/predict", nil)
+		env.Router.ServeHTTP(w, req)
+		return w.Code, roleNames
+	}
+
+	t.Run("Success_VerifiedSkill", func(t *testing.T) {
+		env.ResetMocks()
+		skills.ExpectedCalls, skills.Calls = nil, nil
+		employee := mockValidSchedulePrediction(env, orgID)
+		skills.On("GetRoleSkillRequirements", orgID).Return(requirements, nil).Once()
+		skills.On("GetVerifiedSkills", orgID, mock.Anything).Return(map[uuid.UUID][]string{employee.ID: {"food HANDLER"}}, nil).Once()
+
+		_, roleNames := predict()
+
+		assert.Equal(t, [][]string{{"employee", "server"}}, roleNames)
+		// verifications must hold until the end of the week scheduled
+		on := skills.Calls[1].Arguments.Get(1).(time.Time)
+		assert.Equal(t, time.Now().AddDate(0, 0, 6).Format(time.DateOnly), on.Format(time.DateOnly))
+	})
+
+	t.Run("Success_UnverifiedSkillExcluded", func(t *testing.T) {
+		env.ResetMocks()
+		skills.ExpectedCalls, skills.Calls = nil, nil
+		mockValidSchedulePrediction(env, orgID)
+		skills.On("GetRoleSkillRequirements", orgID).Return(requirements, nil).Once()
+		skills.On("GetVerifiedSkills", orgID, mock.Anything).Return(map[uuid.UUID][]string{}, nil).Once()
+
+		code, roleNames := predict()
+
+		assert.Equal(t, http.StatusUnprocessableEntity, code)
+		assert.Empty(t, roleNames)
+		env.ScheduleStore.AssertNotCalled(t, "StoreScheduleForUser")
+	})
+
+	t.Run("Success_NoRequirements", func(t *testing.T) {
+		env.ResetMocks()
+		skills.ExpectedCalls, skills.Calls = nil, nil
+		mockValidSchedulePrediction(env, orgID)
+		skills.On("GetRoleSkillRequirements", orgID).Return([]database.RoleSkillRequirement{}, nil).Once()
+
+		_, roleNames := predict()
+
+		assert.Equal(t, [][]string{{"employee", "server"}}, roleNames)
+		skills.AssertNotCalled(t, "GetVerifiedSkills", orgID, mock.Anything)
+	})
+
+	t.Run("Failure_RequirementsError", func(t *testing.T) {
+		env.ResetMocks()
+		skills.ExpectedCalls, skills.Calls = nil, nil
+		mockValidSchedulePrediction(env, orgID)
+		skills.On("GetRoleSkillRequirements", orgID).Return(nil, errors.New("db error")).Once()
+
+		code, _ := predict()
+
+		assert.Equal(t, http.StatusInternalServerError, code)
+	})
+}
+
+func TestSkillExpiryReminders(t *testing.T) {
+	skills := new(MockSkillStore)
+	orgStore := new(MockOrgStore)
+	emailService := new(MockEmailService)
+	reminders := &service.SkillExpiryService{SkillStore: skills, OrgStore: orgStore, EmailService: emailService,
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)), ReminderDays: 30}
+
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	orgID := uuid.New()
+	soon := time.Date(2026, 10, 30, 0, 0, 0, 0, time.UTC)
+	failing := time.Date(2026, 11, 10, 0, 0, 0, 0, time.UTC)
+	first := database.EmployeeSkill{ID: uuid.New(), OrganizationID: orgID, EmployeeName: "Sam Server", EmployeeEmail: "sam@example.com",
+		Name: "Food handler", ExpiresOn: &soon}
+	second := database.EmployeeSkill{ID: uuid.New(), OrganizationID: orgID, EmployeeName: "Alex Manager", EmployeeEmail: "alex@example.com",
+		Name: "First aid", ExpiresOn: &failing}
+
+	skills.On("GetExpiringSkills", time.Date(2026, 11, 15, 0, 0, 0, 0, time.UTC)).Return([]database.EmployeeSkill{first, second}, nil).Once()
+	orgStore.On("GetManagerEmailsByOrgID", orgID).Return([]string{"alex@example.com"}, nil).Once()
+	emailService.On("SendSkillExpiryEmail", []string{"sam@example.com", "alex@example.com"}, "Sam Server", "Food handler", "2026-10-30", 14).Return(nil).Once()
+	emailService.On("SendSkillExpiryEmail", []string{"alex@example.com"}, "Alex Manager", "First aid", "2026-11-10", 25).Return(errors.New("smtp down")).Once()
+	skills.On("MarkSkillExpiryReminded", first.ID).Return(nil).Once()
+
+	sent, err := reminders.SendReminders(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	// the managers of an organization are looked up once, the failed reminder is retried on the next check
+	orgStore.AssertNumberOfCalls(t, "GetManagerEmailsByOrgID", 1)
+	skills.AssertNotCalled(t, "MarkSkillExpiryReminded", second.ID)
+	emailService.AssertExpectations(t)
+}
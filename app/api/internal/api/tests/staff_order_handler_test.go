@@ -0,0 +1,169 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupStaffOrderHandler() (*api.StaffOrderHandler, *MockStaffOrderStore, *MockUserStore) {
+	gin.SetMode(gin.TestMode)
+	staffOrderStore := new(MockStaffOrderStore)
+	userStore := new(MockUserStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	return api.NewStaffOrderHandler(staffOrderStore, userStore, logger), staffOrderStore, userStore
+}
+
+func TestTagStaffOrderHandler(t *testing.T) {
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+	orderID := uuid.New()
+	route := "/:org/orders/:id/staff"
+	path := "/" + orgID.String() + "/orders/" + orderID.String() + "/staff"
+	body := map[string]any{"employee_id": employee.ID, "kind": "staff_meal"}
+
+	t.Run("Success", func(t *testing.T) {
+		handler, staffOrderStore, userStore := setupStaffOrderHandler()
+		userStore.On("GetUserByID", employee.ID).Return(employee, nil).Once()
+		staffOrderStore.On("TagStaffOrder", orgID, mock.MatchedBy(func(tag *database.StaffOrder) bool {
+			return tag.OrderID == orderID && tag.EmployeeID == employee.ID && tag.Kind == database.StaffOrderMeal && *tag.TaggedBy == manager.ID
+		})).Return(nil).Once()
+
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(manager), handler.TagStaffOrderHandler}, body)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"kind":"staff_meal"`)
+		staffOrderStore.AssertExpectations(t)
+	})
+
+	t.Run("InvalidKind", func(t *testing.T) {
+		handler, staffOrderStore, _ := setupStaffOrderHandler()
+
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(manager), handler.TagStaffOrderHandler},
+			map[string]any{"employee_id": employee.ID, "kind": "free_lunch"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		staffOrderStore.AssertNotCalled(t, "TagStaffOrder", mock.Anything, mock.Anything)
+	})
+
+	t.Run("EmployeeOfOtherOrganization", func(t *testing.T) {
+		handler, staffOrderStore, userStore := setupStaffOrderHandler()
+		outsider := &database.User{ID: employee.ID, OrganizationID: uuid.New(), UserRole: "employee"}
+		userStore.On("GetUserByID", employee.ID).Return(outsider, nil).Once()
+
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(manager), handler.TagStaffOrderHandler}, body)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "Employee not found")
+		staffOrderStore.AssertNotCalled(t, "TagStaffOrder", mock.Anything, mock.Anything)
+	})
+
+	t.Run("OrderNotFound", func(t *testing.T) {
+		handler, staffOrderStore, userStore := setupStaffOrderHandler()
+		userStore.On("GetUserByID", employee.ID).Return(employee, nil).Once()
+		staffOrderStore.On("TagStaffOrder", orgID, mock.Anything).Return(sql.ErrNoRows).Once()
+
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(manager), handler.TagStaffOrderHandler}, body)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "Order not found")
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		handler, _, _ := setupStaffOrderHandler()
+
+		w := jobRequest("PUT", route, path, []gin.HandlerFunc{authMiddleware(employee), handler.TagStaffOrderHandler}, body)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestUntagStaffOrderHandler(t *testing.T) {
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	orderID := uuid.New()
+	route := "/:org/orders/:id/staff"
+	path := "/" + orgID.String() + "/orders/" + orderID.String() + "/staff"
+
+	t.Run("Success", func(t *testing.T) {
+		handler, staffOrderStore, _ := setupStaffOrderHandler()
+		staffOrderStore.On("UntagStaffOrder", orgID, orderID).Return(nil).Once()
+
+		w := jobRequest("DELETE", route, path, []gin.HandlerFunc{authMiddleware(admin), handler.UntagStaffOrderHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("NotTagged", func(t *testing.T) {
+		handler, staffOrderStore, _ := setupStaffOrderHandler()
+		staffOrderStore.On("UntagStaffOrder", orgID, orderID).Return(sql.ErrNoRows).Once()
+
+		w := jobRequest("DELETE", route, path, []gin.HandlerFunc{authMiddleware(admin), handler.UntagStaffOrderHandler}, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestGetStaffMealReportHandler(t *testing.T) {
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/orders/staff-meals"
+	path := "/" + orgID.String() + "/orders/staff-meals"
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.Local)
+	consumption := []database.StaffMealConsumption{{
+		EmployeeID: uuid.New(), FullName: "Sam Cook", StaffMeals: 3, StaffMealValue: 45, StaffMealBenefit: 45,
+		DiscountedOrders: 2, DiscountedValue: 40, DiscountBenefit: 10, TotalBenefit: 55,
+	}}
+
+	t.Run("Month", func(t *testing.T) {
+		handler, staffOrderStore, _ := setupStaffOrderHandler()
+		staffOrderStore.On("GetStaffMealConsumption", orgID, from, from.AddDate(0, 1, 0)).Return(consumption, nil).Once()
+
+		w := jobRequest("GET", route, path+"?month=2026-09", []gin.HandlerFunc{authMiddleware(manager), handler.GetStaffMealReportHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data struct {
+				Month        string                          `json:"month"`
+				TotalBenefit float64                         `json:"total_benefit"`
+				Employees    []database.StaffMealConsumption `json:"employees"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "2026-09", response.Data.Month)
+		assert.Equal(t, 55.0, response.Data.TotalBenefit)
+		assert.Len(t, response.Data.Employees, 1)
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		handler, staffOrderStore, _ := setupStaffOrderHandler()
+		staffOrderStore.On("GetStaffMealConsumption", orgID, from, from.AddDate(0, 1, 0)).Return(consumption, nil).Once()
+
+		w := jobRequest("GET", route, path+"?month=2026-09&format=csv", []gin.HandlerFunc{authMiddleware(manager), handler.GetStaffMealReportHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "staff-meals-2026-09.csv")
+		assert.Contains(t, w.Body.String(), "Sam Cook,3,45.00,45.00,2,40.00,10.00,55.00")
+	})
+
+	t.Run("InvalidMonth", func(t *testing.T) {
+		handler, _, _ := setupStaffOrderHandler()
+
+		w := jobRequest("GET", route, path+"?month=09-2026", []gin.HandlerFunc{authMiddleware(manager), handler.GetStaffMealReportHandler}, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
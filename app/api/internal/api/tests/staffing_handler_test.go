@@ -29,6 +29,7 @@ type StaffingTestEnv struct {
 	RolesStore     *MockRolesStore
 	UploadService  *MockUploadService
 	EmailService   *MockEmailService
+	FieldStore     *MockCustomFieldStore
 	Handler        *api.StaffingHandler
 }
 
@@ -41,9 +42,11 @@ func setupStaffingEnv() *StaffingTestEnv {
 	rolesStore := new(MockRolesStore)
 	uploadService := new(MockUploadService)
 	emailService := new(MockEmailService)
+	fieldStore := new(MockCustomFieldStore)
+	fieldStore.NoFields()
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
-	handler := api.NewStaffingHandler(userStore, orgStore, userRolesStore, rolesStore, uploadService, emailService, logger)
+	handler := api.NewStaffingHandler(userStore, orgStore, userRolesStore, rolesStore, uploadService, emailService, fieldStore, logger)
 
 	return &StaffingTestEnv{
 		Router:         gin.New(),
@@ -53,6 +56,7 @@ func setupStaffingEnv() *StaffingTestEnv {
 		RolesStore:     rolesStore,
 		UploadService:  uploadService,
 		EmailService:   emailService,
+		FieldStore:     fieldStore,
 		Handler:        handler,
 	}
 }
@@ -70,6 +74,9 @@ func (env *StaffingTestEnv) ResetMocks() {
 	env.UploadService.Calls = nil
 	env.EmailService.ExpectedCalls = nil
 	env.EmailService.Calls = nil
+	env.FieldStore.ExpectedCalls = nil
+	env.FieldStore.Calls = nil
+	env.FieldStore.NoFields()
 }
 
 func TestGetStaffingSummary(t *testing.T) {
@@ -138,6 +145,45 @@ func TestGetAllEmployees(t *testing.T) {
 		env.UserStore.AssertExpectations(t)
 	})
 
+	t.Run("Success_CustomFieldFilter", func(t *testing.T) {
+		env.ResetMocks()
+		medium, large := &database.User{ID: uuid.New(), FullName: "John Doe"}, &database.User{ID: uuid.New(), FullName: "Jane Roe"}
+		env.UserStore.On("GetUsersByOrganization", orgID).Return([]*database.User{medium, large}, nil).Once()
+		env.FieldStore.ExpectedCalls = nil
+		env.FieldStore.On("ListFieldDefinitions", orgID, database.CustomFieldEmployee).
+			Return([]database.CustomFieldDefinition{{Key: "uniform_size", FieldType: database.CustomFieldSelect, Options: []string{"M", "L"}}}, nil).Once()
+		env.FieldStore.On("GetCustomFieldValues", orgID, database.CustomFieldEmployee, []uuid.UUID{medium.ID, large.ID}).
+			Return(map[uuid.UUID]map[string]any{medium.ID: {"uniform_size": "M"}, large.ID: {"uniform_size": "L"}}, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/staffing/employees?cf.uniform_size=M", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Employees []database.User `json:"employees"`
+			Total     int             `json:"total"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 1, response.Total)
+		assert.Equal(t, "John Doe", response.Employees[0].FullName)
+		assert.Equal(t, map[string]any{"uniform_size": "M"}, response.Employees[0].CustomFields)
+		// the users returned by the store are left untouched
+		assert.Nil(t, medium.CustomFields)
+		env.FieldStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_UnknownCustomField", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUsersByOrganization", orgID).Return([]*database.User{}, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/staffing/employees?cf.uniform_size=M", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
 	t.Run("Failure_DBError", func(t *testing.T) {
 		env.ResetMocks()
 		env.UserStore.On("GetUsersByOrganization", orgID).Return(nil, errors.New("db error")).Once()
@@ -280,6 +326,26 @@ func TestUploadEmployeesCSV(t *testing.T) {
 		assert.Contains(t, w.Body.String(), "Missing required header")
 	})
 
+	t.Run("Failure_TooManyRows", func(t *testing.T) {
+		env.ResetMocks()
+		env.UploadService.On("ParseCSV", mock.Anything).Return(nil, service.ErrTooManyRows).Once()
+
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("file", "huge.csv")
+		part.Write([]byte("dummy"))
+		writer.Close()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/staffing/upload", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Contains(t, w.Body.String(), "hint")
+	})
+
 	t.Run("Partial_Failure_RowValidation", func(t *testing.T) {
 		env.ResetMocks()
 		org := &database.Organization{ID: orgID, Name: "Clockwise"}
@@ -290,7 +356,7 @@ func TestUploadEmployeesCSV(t *testing.T) {
 				// Invalid role
 				{"full_name": "Bad Role", "email": "bad@test.com", "role": "wizard", "hourly_salary": "10", "roles": "[]"},
 				// Valid
-				{"full_name": "Good", "email": "good@test.com", "role": "staff", "hourly_salary": "10", "roles": "[]"},
+				{"full_name": "Good", "email": "good@test.com", "role": "employee", "hourly_salary": "10", "roles": "[]"},
 			},
 		}
 
@@ -0,0 +1,222 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type StatusBoardTestEnv struct {
+	StatusBoardStore *MockStatusBoardStore
+	ScheduleStore    *MockScheduleStore
+	OrderStore       *MockOrderStore
+	Handler          *api.StatusBoardHandler
+}
+
+func setupStatusBoardEnv() *StatusBoardTestEnv {
+	gin.SetMode(gin.TestMode)
+	env := &StatusBoardTestEnv{
+		StatusBoardStore: new(MockStatusBoardStore),
+		ScheduleStore:    new(MockScheduleStore),
+		OrderStore:       new(MockOrderStore),
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	env.Handler = api.NewStatusBoardHandler(env.StatusBoardStore, env.ScheduleStore, env.OrderStore, logger)
+	return env
+}
+
+// boardRequest reads the board of the :org organization, the token in the header or in the URL
+func boardRequest(env *StatusBoardTestEnv, path, header, etag string) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.GET("/status-board/:org", env.Handler.GetStatusBoardHandler)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", path, nil)
+	if header != "" {
+		req.Header.Set("Authorization", header)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestGetStatusBoardHandler(t *testing.T) {
+	orgID := uuid.New()
+	board := &database.StatusBoard{ID: uuid.New(), OrganizationID: orgID, Name: "Breakroom TV"}
+	path := "/status-board/" + orgID.String()
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	yesterday := today.AddDate(0, 0, -1)
+	shifts := []database.Shift{
+		{EmployeeName: "Yesterday Only", EmployeeEmail: "y@test.com", Date: yesterday, StartTime: "08:00:00", EndTime: "16:00:00"},
+		{EmployeeName: "Night Owl", Date: yesterday, StartTime: "22:00:00", EndTime: "02:00:00", ShiftType: "working"},
+		{EmployeeName: "Ada Lovelace", EmployeeEmail: "ada@test.com", Date: today, StartTime: "00:00:00", EndTime: "00:00:00", ShiftType: "working"},
+		{EmployeeName: "Tomorrow", Date: today.AddDate(0, 0, 1), StartTime: "08:00:00", EndTime: "16:00:00"},
+	}
+	expectView := func(env *StatusBoardTestEnv) {
+		env.ScheduleStore.On("GetShiftsFrom", orgID, yesterday).Return(shifts, nil).Once()
+		env.OrderStore.On("GetChannelBreakdown", orgID, today, mock.Anything).Return([]database.ChannelBreakdown{
+			{Channel: "pos", Orders: 12, DeliveryOrders: 2, Revenue: 240},
+			{Channel: "web", Orders: 3, DeliveryOrders: 3, Revenue: 90},
+		}, nil).Once()
+		env.OrderStore.On("GetOrders", orgID, mock.MatchedBy(func(filter database.OrderFilter) bool {
+			return filter.From != nil && filter.From.Equal(today) && len(filter.Statuses) == 1 && filter.Statuses[0] == "incompleted"
+		})).Return([]database.Order{{OrderID: uuid.New()}, {OrderID: uuid.New()}}, nil).Once()
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupStatusBoardEnv()
+		env.StatusBoardStore.On("GetBoardByToken", "board_abc").Return(board, nil).Once()
+		expectView(env)
+
+		w := boardRequest(env, path, "Board board_abc", "")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "private, max-age=30", w.Header().Get("Cache-Control"))
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+		var response struct {
+			Data api.StatusBoardView `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, today.Format(time.DateOnly), response.Data.Date)
+		assert.Equal(t, 30, response.Data.RefreshSeconds)
+		if assert.Len(t, response.Data.Shifts, 2) {
+			assert.Equal(t, "Night O.", response.Data.Shifts[0].EmployeeName)
+			assert.Equal(t, "Ada L.", response.Data.Shifts[1].EmployeeName)
+			assert.True(t, response.Data.Shifts[1].OnNow)
+		}
+		assert.Equal(t, 15, response.Data.Orders.Today)
+		assert.Equal(t, 2, response.Data.Orders.Open)
+		assert.Equal(t, 5, response.Data.Orders.Deliveries)
+		assert.NotContains(t, w.Body.String(), "ada@test.com")
+		assert.NotContains(t, w.Body.String(), "revenue")
+	})
+
+	t.Run("CachedAndNotModified", func(t *testing.T) {
+		env := setupStatusBoardEnv()
+		env.StatusBoardStore.On("GetBoardByToken", "board_abc").Return(board, nil).Twice()
+		expectView(env)
+
+		first := boardRequest(env, path+"?token=board_abc", "", "")
+		second := boardRequest(env, path+"?token=board_abc", "", first.Header().Get("ETag"))
+
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Equal(t, http.StatusNotModified, second.Code)
+		assert.Empty(t, second.Body.String())
+		env.ScheduleStore.AssertNumberOfCalls(t, "GetShiftsFrom", 1)
+	})
+
+	t.Run("MissingToken", func(t *testing.T) {
+		env := setupStatusBoardEnv()
+
+		w := boardRequest(env, path, "", "")
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("RevokedToken", func(t *testing.T) {
+		env := setupStatusBoardEnv()
+		env.StatusBoardStore.On("GetBoardByToken", "board_old").Return(nil, sql.ErrNoRows).Once()
+
+		w := boardRequest(env, path, "Board board_old", "")
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("OtherOrganization", func(t *testing.T) {
+		env := setupStatusBoardEnv()
+		env.StatusBoardStore.On("GetBoardByToken", "board_abc").Return(board, nil).Once()
+
+		w := boardRequest(env, "/status-board/"+uuid.New().String(), "Board board_abc", "")
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		env.ScheduleStore.AssertNotCalled(t, "GetShiftsFrom", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCreateStatusBoardHandler(t *testing.T) {
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/status-boards"
+	path := "/" + orgID.String() + "/status-boards"
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupStatusBoardEnv()
+		env.StatusBoardStore.On("ListBoards", orgID).Return([]database.StatusBoard{}, nil).Once()
+		env.StatusBoardStore.On("CreateBoard", orgID, mock.MatchedBy(func(board *database.StatusBoard) bool {
+			return board.Name == "Breakroom TV" && *board.CreatedBy == admin.ID
+		}), mock.MatchedBy(func(token string) bool {
+			return len(token) == len("board_")+64
+		})).Return(nil).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.CreateStatusBoardHandler},
+			api.StatusBoardRequest{Name: "Breakroom TV"})
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"token":"board_`)
+		env.StatusBoardStore.AssertExpectations(t)
+	})
+
+	t.Run("TooManyBoards", func(t *testing.T) {
+		env := setupStatusBoardEnv()
+		boards := make([]database.StatusBoard, 20)
+		env.StatusBoardStore.On("ListBoards", orgID).Return(boards, nil).Once()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(admin), env.Handler.CreateStatusBoardHandler},
+			api.StatusBoardRequest{Name: "Kitchen"})
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("ManagerForbidden", func(t *testing.T) {
+		env := setupStatusBoardEnv()
+
+		w := jobRequest("POST", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.CreateStatusBoardHandler},
+			api.StatusBoardRequest{Name: "Breakroom TV"})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestRevokeStatusBoardHandler(t *testing.T) {
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	boardID := uuid.New()
+	route := "/:org/status-boards/:id"
+	path := "/" + orgID.String() + "/status-boards/" + boardID.String()
+	handlers := func(env *StatusBoardTestEnv) []gin.HandlerFunc {
+		return []gin.HandlerFunc{authMiddleware(admin), env.Handler.RevokeStatusBoardHandler}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env := setupStatusBoardEnv()
+		env.StatusBoardStore.On("RevokeBoard", orgID, boardID).Return(nil).Once()
+
+		w := jobRequest("DELETE", route, path, handlers(env), nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		env := setupStatusBoardEnv()
+		env.StatusBoardStore.On("RevokeBoard", orgID, boardID).Return(sql.ErrNoRows).Once()
+
+		w := jobRequest("DELETE", route, path, handlers(env), nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type StatusTestEnv struct {
+	StatusStore *MockStatusStore
+	Handler     *api.StatusHandler
+}
+
+func setupStatusEnv() *StatusTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	statusStore := new(MockStatusStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &StatusTestEnv{
+		StatusStore: statusStore,
+		Handler:     api.NewStatusHandler(statusStore, logger),
+	}
+}
+
+func (env *StatusTestEnv) ResetMocks() {
+	env.StatusStore.ExpectedCalls = nil
+	env.StatusStore.Calls = nil
+}
+
+func TestGetStatusHandler(t *testing.T) {
+	env := setupStatusEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/status"
+	path := "/" + orgID.String() + "/status"
+	handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetStatusHandler}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		lag := int64(90000)
+		detail := "connection refused"
+		env.StatusStore.On("GetLatestEvent", orgID, database.StatusEventIngestion).
+			Return(&database.StatusEvent{Kind: database.StatusEventIngestion, Source: "orders_csv", DurationMs: &lag}, nil).Once()
+		env.StatusStore.On("GetLatestEvent", orgID, database.StatusEventScheduleGeneration).
+			Return(&database.StatusEvent{Kind: database.StatusEventScheduleGeneration, Source: "ml"}, nil).Once()
+		env.StatusStore.On("GetLatestEvent", orgID, database.StatusEventEmailFailure).
+			Return(&database.StatusEvent{Kind: database.StatusEventEmailFailure, Source: "smtp", Failed: true, Detail: &detail}, nil).Once()
+		env.StatusStore.On("GetMLLatency", orgID, mock.MatchedBy(func(since time.Time) bool {
+			return time.Since(since) > 23*time.Hour && time.Since(since) < 25*time.Hour
+		})).Return(&database.MLLatency{Requests: 12, Failures: 1, AvgMs: 850, P95Ms: 2100, MaxMs: 3000}, nil).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data struct {
+				LastIngestion          database.StatusEvent `json:"last_ingestion"`
+				LastScheduleGeneration database.StatusEvent `json:"last_schedule_generation"`
+				LastEmailFailure       database.StatusEvent `json:"last_email_failure"`
+				MLLatency              database.MLLatency   `json:"ml_latency"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, lag, *response.Data.LastIngestion.DurationMs)
+		assert.Equal(t, "ml", response.Data.LastScheduleGeneration.Source)
+		assert.Equal(t, detail, *response.Data.LastEmailFailure.Detail)
+		assert.Equal(t, 12, response.Data.MLLatency.Requests)
+		assert.Equal(t, 2100.0, response.Data.MLLatency.P95Ms)
+		env.StatusStore.AssertExpectations(t)
+	})
+
+	t.Run("NoEventsYet", func(t *testing.T) {
+		env.ResetMocks()
+		env.StatusStore.On("GetLatestEvent", orgID, mock.Anything).Return(nil, nil).Times(3)
+		env.StatusStore.On("GetMLLatency", orgID, mock.Anything).Return(&database.MLLatency{}, nil).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"last_ingestion":null`)
+		assert.Contains(t, w.Body.String(), `"last_email_failure":null`)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.StatusStore.On("GetLatestEvent", orgID, database.StatusEventIngestion).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		env.StatusStore.AssertNotCalled(t, "GetMLLatency", mock.Anything, mock.Anything)
+	})
+
+	t.Run("EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetStatusHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestRecordEmailFailures(t *testing.T) {
+	userStore := new(MockUserStore)
+	statusStore := new(MockStatusStore)
+	statusStore.AllowEvents()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	orgID := uuid.New()
+	userStore.On("GetUserByEmail", "sam@example.com").Return(&database.User{ID: uuid.New(), OrganizationID: orgID}, nil)
+	userStore.On("GetUserByEmail", "applicant@example.com").Return(nil, sql.ErrNoRows)
+
+	api.RecordEmailFailures(userStore, statusStore, logger)([]string{"sam@example.com", "applicant@example.com"}, errors.New("550 mailbox unavailable"))
+
+	// Only recipients with an account are attributed to an organization
+	failures := statusStore.RecordedEvents(database.StatusEventEmailFailure)
+	if assert.Len(t, failures, 1) {
+		assert.True(t, failures[0].Failed)
+		assert.Equal(t, "550 mailbox unavailable", *failures[0].Detail)
+	}
+	statusStore.AssertCalled(t, "RecordEvent", orgID, mock.Anything)
+}
@@ -0,0 +1,399 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// rfcTOTPSecret is the secret of the RFC 6238 test vectors, "12345678901234567890" in base32
+const rfcTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+type StepUpTestEnv struct {
+	UserStore          *MockUserStore
+	TOTPStore          *MockTOTPStore
+	AuditStore         *MockAuditStore
+	LoginSecurityStore *MockLoginSecurityStore
+	Guard              *middleware.StepUpGuard
+	Handler            *api.StepUpHandler
+}
+
+func setupStepUpEnv() *StepUpTestEnv {
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	env := &StepUpTestEnv{
+		UserStore:          new(MockUserStore),
+		TOTPStore:          new(MockTOTPStore),
+		AuditStore:         new(MockAuditStore),
+		LoginSecurityStore: new(MockLoginSecurityStore),
+		Guard:              &middleware.StepUpGuard{Window: 10 * time.Minute, Routes: map[string]bool{}, Logger: logger},
+	}
+	env.Handler = api.NewStepUpHandler(env.UserStore, env.TOTPStore, env.AuditStore, env.Guard, logger)
+	env.Handler.LoginGuard = &middleware.LoginGuard{
+		Store:       env.LoginSecurityStore,
+		AuditStore:  env.AuditStore,
+		MaxAttempts: 5,
+		Lockout:     15 * time.Minute,
+		Logger:      logger,
+	}
+	return env
+}
+
+// steppedUp marks the request as stepped up at at, as the JWT middleware does for step-up tokens
+func steppedUp(at time.Time) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("step_up_at", at)
+		c.Next()
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	// RFC 6238 appendix B, SHA1 at 59 seconds, truncated to 6 digits
+	at := time.Unix(59, 0)
+	code, err := service.TOTPCode(rfcTOTPSecret, service.TOTPCounter(at))
+	assert.NoError(t, err)
+	assert.Equal(t, "287082", code)
+
+	t.Run("Valid", func(t *testing.T) {
+		counter, ok := service.VerifyTOTP(rfcTOTPSecret, "287 082", at, 0)
+		assert.True(t, ok)
+		assert.Equal(t, int64(1), counter)
+	})
+
+	t.Run("PreviousPeriod", func(t *testing.T) {
+		_, ok := service.VerifyTOTP(rfcTOTPSecret, code, at.Add(service.TOTPPeriod), 0)
+		assert.True(t, ok)
+	})
+
+	t.Run("TooOld", func(t *testing.T) {
+		_, ok := service.VerifyTOTP(rfcTOTPSecret, code, at.Add(3*service.TOTPPeriod), 0)
+		assert.False(t, ok)
+	})
+
+	t.Run("Replayed", func(t *testing.T) {
+		_, ok := service.VerifyTOTP(rfcTOTPSecret, code, at, 1)
+		assert.False(t, ok)
+	})
+
+	t.Run("Wrong", func(t *testing.T) {
+		_, ok := service.VerifyTOTP(rfcTOTPSecret, "000000", at, 0)
+		assert.False(t, ok)
+		_, ok = service.VerifyTOTP(rfcTOTPSecret, "28708", at, 0)
+		assert.False(t, ok)
+	})
+
+	t.Run("URI", func(t *testing.T) {
+		uri := service.TOTPURI(rfcTOTPSecret, "ClockWise", "maya@example.com")
+		assert.Contains(t, uri, "otpauth://totp/ClockWise:maya@example.com?")
+		assert.Contains(t, uri, "secret="+rfcTOTPSecret)
+	})
+}
+
+func TestStepUpGuard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), FullName: "Ava", Email: "ava@example.com", OrganizationID: orgID, UserRole: "admin"}
+	t.Setenv("STEP_UP_WINDOW", "5m")
+	t.Setenv("STEP_UP_ROUTES", "DELETE /:org/staffing/members/:id, PUT  /:org/exports/encryption")
+	guard := middleware.NewStepUpGuard(slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	assert.Equal(t, 5*time.Minute, guard.Window)
+	assert.Equal(t, map[string]bool{"DELETE /:org/staffing/members/:id": true, "PUT /:org/exports/encryption": true}, guard.Routes)
+
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"message": "done"}) }
+	member := "/api/v1/" + orgID.String() + "/staffing/members/" + uuid.New().String()
+	route := func(handlers ...gin.HandlerFunc) *gin.Engine {
+		router := gin.New()
+		group := router.Group("/api/v1")
+		group.Use(handlers...)
+		group.Use(guard.Enforce("/api/v1"))
+		group.DELETE("/:org/staffing/members/:id", ok)
+		group.GET("/:org/staffing/members/:id", ok)
+		return router
+	}
+	send := func(router *gin.Engine, method, path, token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("UnprotectedRoute", func(t *testing.T) {
+		w := send(route(authMiddleware(admin)), "GET", member, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("NoStepUp", func(t *testing.T) {
+		w := send(route(authMiddleware(admin)), "DELETE", member, "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), `"step_up_required":true`)
+	})
+
+	t.Run("RecentStepUp", func(t *testing.T) {
+		w := send(route(authMiddleware(admin), steppedUp(time.Now().Add(-4*time.Minute))), "DELETE", member, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("ExpiredStepUp", func(t *testing.T) {
+		w := send(route(authMiddleware(admin), steppedUp(time.Now().Add(-6*time.Minute))), "DELETE", member, "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("StepUpToken", func(t *testing.T) {
+		t.Setenv("JWT_SECRET", "test-secret")
+		authMw, err := middleware.NewAuthMiddleware(new(MockUserStore), nil)
+		assert.NoError(t, err)
+		assert.NoError(t, authMw.MiddlewareInit())
+		router := route(authMw.MiddlewareFunc())
+
+		plain, err := authMw.TokenGenerator(context.Background(), admin)
+		assert.NoError(t, err)
+		w := send(router, "DELETE", member, plain.AccessToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		stepped, err := authMw.TokenGenerator(context.Background(), &middleware.StepUp{User: admin, At: time.Now()})
+		assert.NoError(t, err)
+		w = send(router, "DELETE", member, stepped.AccessToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestStepUpGuardDefaultRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	orgID := uuid.New()
+	employee := &database.User{ID: uuid.New(), FullName: "Sam", Email: "sam@example.com", OrganizationID: orgID, UserRole: "employee"}
+	t.Setenv("STEP_UP_ROUTES", "")
+	guard := middleware.NewStepUpGuard(slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"message": "done"}) }
+	route := func(handlers ...gin.HandlerFunc) *gin.Engine {
+		router := gin.New()
+		group := router.Group("/api/v1")
+		group.Use(handlers...)
+		group.Use(guard.Enforce("/api/v1"))
+		group.PUT("/:org/me/compliance", ok)
+		group.PUT("/:org/staffing/employees/:id/compliance", ok)
+		return router
+	}
+	send := func(router *gin.Engine, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", path, strings.NewReader(`{"bank_details": {"iban": "DE89370400440532013000"}}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+	own := "/api/v1/" + orgID.String() + "/me/compliance"
+	other := "/api/v1/" + orgID.String() + "/staffing/employees/" + employee.ID.String() + "/compliance"
+
+	t.Run("OwnBankDetailsNeedStepUp", func(t *testing.T) {
+		w := send(route(authMiddleware(employee)), own)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), `"step_up_required":true`)
+
+		w = send(route(authMiddleware(employee), steppedUp(time.Now())), own)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("EmployeeBankDetailsNeedStepUp", func(t *testing.T) {
+		w := send(route(authMiddleware(employee)), other)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestStepUpHandler(t *testing.T) {
+	orgID := uuid.New()
+	user := &database.User{ID: uuid.New(), FullName: "Ava", Email: "ava@example.com", OrganizationID: orgID, UserRole: "admin"}
+	fullUser := *user
+	fullUser.PasswordHash.Set("correct-password")
+	code, _ := service.TOTPCode(rfcTOTPSecret, service.TOTPCounter(time.Now()))
+	enabledAt := time.Now().Add(-24 * time.Hour)
+	totp := &database.UserTOTP{UserID: user.ID, Secret: rfcTOTPSecret, EnabledAt: &enabledAt}
+
+	stepUp := func(env *StepUpTestEnv, tokens *fakeTokenGenerator, body any) *httptest.ResponseRecorder {
+		return jobRequest("POST", "/auth/step-up", "/auth/step-up", []gin.HandlerFunc{authMiddleware(user), env.Handler.StepUpHandler(tokens)}, body)
+	}
+
+	t.Run("Password", func(t *testing.T) {
+		env := setupStepUpEnv()
+		tokens := &fakeTokenGenerator{}
+		env.UserStore.On("GetUserByID", user.ID).Return(&fullUser, nil)
+		env.LoginSecurityStore.On("GetLoginState", user.ID).Return(&database.LoginState{}, nil)
+		env.AuditStore.On("RecordEvent", mock.MatchedBy(func(e *database.AuditEvent) bool {
+			return e.Action == database.AuditStepUp && e.Details["method"] == "password"
+		})).Return(nil)
+
+		w := stepUp(env, tokens, gin.H{"password": "correct-password"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"access_token":"access"`)
+		assert.Contains(t, w.Body.String(), `"step_up_expires_at"`)
+		assert.Equal(t, user, tokens.issuedFor)
+		assert.WithinDuration(t, time.Now(), tokens.steppedUpAt, time.Minute)
+		env.AuditStore.AssertExpectations(t)
+	})
+
+	t.Run("WrongPasswordIsCounted", func(t *testing.T) {
+		env := setupStepUpEnv()
+		tokens := &fakeTokenGenerator{}
+		env.UserStore.On("GetUserByID", user.ID).Return(&fullUser, nil)
+		env.LoginSecurityStore.On("GetLoginState", user.ID).Return(&database.LoginState{}, nil)
+		env.LoginSecurityStore.On("RecordFailedLogin", user.ID, 5, 15*time.Minute).Return(&database.LoginState{FailedAttempts: 1}, nil)
+		env.AuditStore.On("RecordEvent", mock.MatchedBy(func(e *database.AuditEvent) bool { return e.Action == database.AuditLoginFailed })).Return(nil)
+
+		w := stepUp(env, tokens, gin.H{"password": "wrong"})
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Nil(t, tokens.issuedFor)
+		env.LoginSecurityStore.AssertExpectations(t)
+	})
+
+	t.Run("LockedAccount", func(t *testing.T) {
+		env := setupStepUpEnv()
+		lockedUntil := time.Now().Add(10 * time.Minute)
+		env.UserStore.On("GetUserByID", user.ID).Return(&fullUser, nil)
+		env.LoginSecurityStore.On("GetLoginState", user.ID).Return(&database.LoginState{LockedUntil: &lockedUntil}, nil)
+
+		w := stepUp(env, &fakeTokenGenerator{}, gin.H{"password": "correct-password"})
+
+		assert.Equal(t, http.StatusLocked, w.Code)
+	})
+
+	t.Run("Code", func(t *testing.T) {
+		env := setupStepUpEnv()
+		tokens := &fakeTokenGenerator{}
+		env.UserStore.On("GetUserByID", user.ID).Return(&fullUser, nil)
+		env.LoginSecurityStore.On("GetLoginState", user.ID).Return(&database.LoginState{}, nil)
+		env.TOTPStore.On("GetTOTP", user.ID).Return(totp, nil)
+		env.TOTPStore.On("UseTOTPCounter", user.ID, mock.AnythingOfType("int64")).Return(true, nil)
+		env.AuditStore.On("RecordEvent", mock.MatchedBy(func(e *database.AuditEvent) bool { return e.Details["method"] == "totp" })).Return(nil)
+
+		w := stepUp(env, tokens, gin.H{"code": code})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, user, tokens.issuedFor)
+		env.TOTPStore.AssertExpectations(t)
+	})
+
+	t.Run("CodeAlreadyUsed", func(t *testing.T) {
+		env := setupStepUpEnv()
+		env.UserStore.On("GetUserByID", user.ID).Return(&fullUser, nil)
+		env.LoginSecurityStore.On("GetLoginState", user.ID).Return(&database.LoginState{}, nil)
+		env.LoginSecurityStore.On("RecordFailedLogin", user.ID, 5, 15*time.Minute).Return(&database.LoginState{FailedAttempts: 1}, nil)
+		env.TOTPStore.On("GetTOTP", user.ID).Return(totp, nil)
+		env.TOTPStore.On("UseTOTPCounter", user.ID, mock.AnythingOfType("int64")).Return(false, nil)
+		env.AuditStore.On("RecordEvent", mock.Anything).Return(nil)
+
+		w := stepUp(env, &fakeTokenGenerator{}, gin.H{"code": code})
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("NoAuthenticatorApp", func(t *testing.T) {
+		env := setupStepUpEnv()
+		env.UserStore.On("GetUserByID", user.ID).Return(&fullUser, nil)
+		env.LoginSecurityStore.On("GetLoginState", user.ID).Return(&database.LoginState{}, nil)
+		env.TOTPStore.On("GetTOTP", user.ID).Return(nil, nil)
+
+		w := stepUp(env, &fakeTokenGenerator{}, gin.H{"code": code})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("PasswordOrCode", func(t *testing.T) {
+		env := setupStepUpEnv()
+
+		assert.Equal(t, http.StatusBadRequest, stepUp(env, &fakeTokenGenerator{}, gin.H{}).Code)
+		assert.Equal(t, http.StatusBadRequest, stepUp(env, &fakeTokenGenerator{}, gin.H{"password": "x", "code": code}).Code)
+	})
+}
+
+func TestTOTPHandlers(t *testing.T) {
+	user := &database.User{ID: uuid.New(), FullName: "Ava", Email: "ava@example.com", OrganizationID: uuid.New(), UserRole: "employee"}
+	enabledAt := time.Now()
+
+	t.Run("Setup", func(t *testing.T) {
+		env := setupStepUpEnv()
+		var secret string
+		env.TOTPStore.On("GetTOTP", user.ID).Return(nil, nil)
+		env.TOTPStore.On("SaveTOTPSecret", user.ID, mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+			secret = args.String(1)
+		}).Return(nil)
+
+		w := jobRequest("POST", "/auth/totp/setup", "/auth/totp/setup", []gin.HandlerFunc{authMiddleware(user), env.Handler.SetupTOTPHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Len(t, secret, 32)
+		assert.Contains(t, w.Body.String(), `"secret":"`+secret+`"`)
+		assert.Contains(t, w.Body.String(), "otpauth://totp/ClockWise:ava@example.com")
+	})
+
+	t.Run("ReplaceNeedsStepUp", func(t *testing.T) {
+		env := setupStepUpEnv()
+		env.TOTPStore.On("GetTOTP", user.ID).Return(&database.UserTOTP{UserID: user.ID, Secret: rfcTOTPSecret, EnabledAt: &enabledAt}, nil)
+
+		w := jobRequest("POST", "/auth/totp/setup", "/auth/totp/setup", []gin.HandlerFunc{authMiddleware(user), env.Handler.SetupTOTPHandler}, nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), `"step_up_required":true`)
+		env.TOTPStore.AssertNotCalled(t, "SaveTOTPSecret", mock.Anything, mock.Anything)
+
+		env.TOTPStore.On("SaveTOTPSecret", user.ID, mock.AnythingOfType("string")).Return(nil)
+		w = jobRequest("POST", "/auth/totp/setup", "/auth/totp/setup", []gin.HandlerFunc{authMiddleware(user), steppedUp(time.Now()), env.Handler.SetupTOTPHandler}, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Enable", func(t *testing.T) {
+		env := setupStepUpEnv()
+		code, _ := service.TOTPCode(rfcTOTPSecret, service.TOTPCounter(time.Now()))
+		env.TOTPStore.On("GetTOTP", user.ID).Return(&database.UserTOTP{UserID: user.ID, Secret: rfcTOTPSecret}, nil)
+		env.TOTPStore.On("EnableTOTP", user.ID, mock.AnythingOfType("int64")).Return(nil)
+		env.AuditStore.On("RecordEvent", mock.MatchedBy(func(e *database.AuditEvent) bool { return e.Action == database.AuditTOTPEnabled })).Return(nil)
+
+		w := jobRequest("POST", "/auth/totp/enable", "/auth/totp/enable", []gin.HandlerFunc{authMiddleware(user), env.Handler.EnableTOTPHandler}, gin.H{"code": code})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = jobRequest("POST", "/auth/totp/enable", "/auth/totp/enable", []gin.HandlerFunc{authMiddleware(user), env.Handler.EnableTOTPHandler}, gin.H{"code": "000000"})
+		if code != "000000" {
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		}
+		env.TOTPStore.AssertNumberOfCalls(t, "EnableTOTP", 1)
+	})
+
+	t.Run("EnableAlreadyEnabled", func(t *testing.T) {
+		env := setupStepUpEnv()
+		env.TOTPStore.On("GetTOTP", user.ID).Return(&database.UserTOTP{UserID: user.ID, Secret: rfcTOTPSecret, EnabledAt: &enabledAt}, nil)
+
+		w := jobRequest("POST", "/auth/totp/enable", "/auth/totp/enable", []gin.HandlerFunc{authMiddleware(user), env.Handler.EnableTOTPHandler}, gin.H{"code": "123456"})
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		env := setupStepUpEnv()
+		env.TOTPStore.On("DeleteTOTP", user.ID).Return(nil).Once()
+		env.AuditStore.On("RecordEvent", mock.MatchedBy(func(e *database.AuditEvent) bool { return e.Action == database.AuditTOTPDisabled })).Return(nil)
+
+		w := jobRequest("DELETE", "/auth/totp", "/auth/totp", []gin.HandlerFunc{authMiddleware(user), env.Handler.DeleteTOTPHandler}, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		env.TOTPStore.On("DeleteTOTP", user.ID).Return(sql.ErrNoRows).Once()
+		w = jobRequest("DELETE", "/auth/totp", "/auth/totp", []gin.HandlerFunc{authMiddleware(user), env.Handler.DeleteTOTPHandler}, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
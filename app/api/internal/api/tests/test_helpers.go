@@ -1,7 +1,10 @@
 package api
 
 import (
+	"context"
+	"io"
 	"mime/multipart"
+	"time"
 
 	"github.com/clockwise/clockwise/backend/internal/database"
 	"github.com/clockwise/clockwise/backend/internal/service"
@@ -75,8 +78,15 @@ func (m *MockUserStore) GetUsersByOrganization(orgID uuid.UUID) ([]*database.Use
 	return args.Get(0).([]*database.User), args.Error(1)
 }
 
-func (m *MockUserStore) GetUserByEmail(email string) (*database.User, error) { return nil, nil }
-func (m *MockUserStore) DeleteUser(id uuid.UUID) error                       { return nil }
+func (m *MockUserStore) GetUserByEmail(email string) (*database.User, error) {
+	args := m.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.User), args.Error(1)
+}
+
+func (m *MockUserStore) DeleteUser(id uuid.UUID) error { return nil }
 
 // MockRequestStore
 type MockRequestStore struct {
@@ -113,7 +123,19 @@ func (m *MockRequestStore) UpdateRequestStatus(id uuid.UUID, status string) erro
 }
 
 func (m *MockRequestStore) GetRequestsByOrganization(orgID uuid.UUID) ([]*database.RequestWithEmployee, error) {
-	return nil, nil
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*database.RequestWithEmployee), args.Error(1)
+}
+
+func (m *MockRequestStore) AcceptRequest(id uuid.UUID) (*database.EmployeeUnavailability, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.EmployeeUnavailability), args.Error(1)
 }
 
 // MockOrgStore
@@ -142,6 +164,14 @@ func (m *MockOrgStore) CreateOrgWithAdmin(org *database.Organization, admin *dat
 	return args.Error(0)
 }
 
+func (m *MockOrgStore) CloneOrganization(sourceID uuid.UUID, org *database.Organization, adminID uuid.UUID, options database.OrgCloneOptions) (*database.OrgClone, error) {
+	args := m.Called(sourceID, org, adminID, options)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.OrgClone), args.Error(1)
+}
+
 func (m *MockOrgStore) GetOrganizationByID(id uuid.UUID) (*database.Organization, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
@@ -158,6 +188,54 @@ func (m *MockOrgStore) GetOrganizationProfile(id uuid.UUID) (*database.Organizat
 	return args.Get(0).(*database.OrganizationProfile), args.Error(1)
 }
 
+func (m *MockOrgStore) SetCustomDomain(orgID uuid.UUID, domain *string, token *string) error {
+	args := m.Called(orgID, domain, token)
+	return args.Error(0)
+}
+
+func (m *MockOrgStore) GetCustomDomain(orgID uuid.UUID) (*database.CustomDomain, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.CustomDomain), args.Error(1)
+}
+
+func (m *MockOrgStore) VerifyCustomDomain(orgID uuid.UUID) error {
+	args := m.Called(orgID)
+	return args.Error(0)
+}
+
+func (m *MockOrgStore) GetCurrency(orgID uuid.UUID) (string, error) {
+	args := m.Called(orgID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockOrgStore) SetCurrency(orgID uuid.UUID, currency string) error {
+	args := m.Called(orgID, currency)
+	return args.Error(0)
+}
+
+func (m *MockOrgStore) GetMagicLinkEnabled(orgID uuid.UUID) (bool, error) {
+	args := m.Called(orgID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockOrgStore) SetMagicLinkEnabled(orgID uuid.UUID, enabled bool) error {
+	args := m.Called(orgID, enabled)
+	return args.Error(0)
+}
+
+func (m *MockOrgStore) SetBrandColors(orgID uuid.UUID, hex1, hex2, hex3 string) error {
+	args := m.Called(orgID, hex1, hex2, hex3)
+	return args.Error(0)
+}
+
+func (m *MockOrgStore) GetOrgIDByCustomDomain(domain string) (uuid.UUID, error) {
+	args := m.Called(domain)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
 // MockEmailService
 type MockEmailService struct {
 	mock.Mock
@@ -193,6 +271,151 @@ func (m *MockEmailService) SendRequestNotifyEmail(toEmails []string, employeeNam
 	return args.Error(0)
 }
 
+func (m *MockEmailService) SendOfferAcceptedEmailToManagerAndAdmin(toEmails []string, employeeName, offerStatus, starttime string) error {
+	args := m.Called(toEmails, employeeName, offerStatus, starttime)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendOfferDeclinedEmailToManagerAndAdmin(toEmails []string, employeeName, offerStatus, starttime string) error {
+	args := m.Called(toEmails, employeeName, offerStatus, starttime)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendShiftReminderEmail(toEmail, fullName string, shifts []string) error {
+	args := m.Called(toEmail, fullName, shifts)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendNotificationDigestEmail(toEmail, fullName string, notifications []string) error {
+	args := m.Called(toEmail, fullName, notifications)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendAnnouncementEmail(toEmails []string, authorName, title, message string) error {
+	args := m.Called(toEmails, authorName, title, message)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendScheduleClearedEmail(toEmail, fullName, from, to string) error {
+	args := m.Called(toEmail, fullName, from, to)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendClosureEmail(toEmail, fullName, date, reason string) error {
+	args := m.Called(toEmail, fullName, date, reason)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendNewLoginEmail(toEmail, fullName, device, ipAddress, loginTime string) error {
+	args := m.Called(toEmail, fullName, device, ipAddress, loginTime)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendCalendarInviteEmail(toEmail, fullName, title, location string, start, end time.Time, inviteID string) error {
+	args := m.Called(toEmail, fullName, title, location, start, end, inviteID)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendForecastVarianceEmail(toEmails []string, summary, suggestion string) error {
+	args := m.Called(toEmails, summary, suggestion)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendStandbyActivatedEmail(toEmail, fullName, date, startTime, endTime string) error {
+	args := m.Called(toEmail, fullName, date, startTime, endTime)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendRequestAwaitingApprovalEmail(toEmails []string, employeeName, requestType, approvedBy string) error {
+	args := m.Called(toEmails, employeeName, requestType, approvedBy)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendRequestStepApprovedEmail(toEmail, fullName, requestType, nextRole string) error {
+	args := m.Called(toEmail, fullName, requestType, nextRole)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendDocumentExpiryEmail(toEmails []string, employeeName, document, expiresOn string, daysLeft int) error {
+	args := m.Called(toEmails, employeeName, document, expiresOn, daysLeft)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendShiftCancelledEmail(toEmail, fullName, date, startTime, endTime, reason string) error {
+	args := m.Called(toEmail, fullName, date, startTime, endTime, reason)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendWeeklyScheduleEmail(toEmail, fullName, weekOf string, shifts []string, totalHours float64, estimatedPay *float64) error {
+	args := m.Called(toEmail, fullName, weekOf, shifts, totalHours, estimatedPay)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendDocumentSignatureEmail(toEmail, fullName, title, link, expiresOn string) error {
+	args := m.Called(toEmail, fullName, title, link, expiresOn)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendMagicLinkEmail(toEmail, fullName, link string, validFor time.Duration) error {
+	args := m.Called(toEmail, fullName, link, validFor)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendComplianceReportEmail(toEmails []string, weekOf string, lateCancellations int, employees []string, owedHours, owedPay float64) error {
+	args := m.Called(toEmails, weekOf, lateCancellations, employees, owedHours, owedPay)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendEncryptedComplianceReportEmail(toEmails []string, weekOf string, lateCancellations int, attachment service.EmailAttachment) error {
+	args := m.Called(toEmails, weekOf, lateCancellations, attachment)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendSkillProposedEmail(toEmails []string, employeeName, skill, kind string) error {
+	args := m.Called(toEmails, employeeName, skill, kind)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendSkillReviewedEmail(toEmail, fullName, skill, status string, note *string) error {
+	args := m.Called(toEmail, fullName, skill, status, note)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendSkillExpiryEmail(toEmails []string, employeeName, skill, expiresOn string, daysLeft int) error {
+	args := m.Called(toEmails, employeeName, skill, expiresOn, daysLeft)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendBroadcastEmail(toEmail, senderName, title, message string) error {
+	args := m.Called(toEmail, senderName, title, message)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendExportReadyEmail(toEmail, fullName, fileName, link, expiresAt string) error {
+	args := m.Called(toEmail, fullName, fileName, link, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendOrderAcceptanceEmail(toEmails []string, accepting bool, summary string) error {
+	args := m.Called(toEmails, accepting, summary)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendDeliverySLAEmail(toEmails []string, summary string) error {
+	args := m.Called(toEmails, summary)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendTrainingGraduationEmail(toEmails []string, summary string) error {
+	args := m.Called(toEmails, summary)
+	return args.Error(0)
+}
+
+func (m *MockEmailService) SendKPIReportEmail(toEmails []string, weekOf string, results []string) error {
+	args := m.Called(toEmails, weekOf, results)
+	return args.Error(0)
+}
+
 // MockRolesStore
 type MockRolesStore struct {
 	mock.Mock
@@ -286,6 +509,30 @@ func (m *MockInsightStore) GetInsightsForEmployee(orgID uuid.UUID, userID uuid.U
 	return args.Get(0).([]database.Insight), args.Error(1)
 }
 
+func (m *MockInsightStore) GetHourlyOrders(orgID uuid.UUID, from, to *time.Time) ([]database.HourlyOrders, error) {
+	args := m.Called(orgID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.HourlyOrders), args.Error(1)
+}
+
+func (m *MockInsightStore) GetTopItems(orgID uuid.UUID, from, to *time.Time, limit int) ([]database.TopItem, error) {
+	args := m.Called(orgID, from, to, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.TopItem), args.Error(1)
+}
+
+func (m *MockInsightStore) GetDeliveryOrdersToday(orgID uuid.UUID) ([]database.DeliveryOrder, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.DeliveryOrder), args.Error(1)
+}
+
 // MockPreferencesStore
 type MockPreferencesStore struct {
 	mock.Mock
@@ -296,17 +543,17 @@ func (m *MockPreferencesStore) UpsertPreference(pref *database.EmployeePreferenc
 	return args.Error(0)
 }
 
-func (m *MockPreferencesStore) UpsertPreferences(employeeID uuid.UUID, prefs []*database.EmployeePreference) error {
+func (m *MockPreferencesStore) UpsertPreferences(employeeID uuid.UUID, prefs []database.EmployeePreference) error {
 	args := m.Called(employeeID, prefs)
 	return args.Error(0)
 }
 
-func (m *MockPreferencesStore) GetPreferencesByEmployeeID(employeeID uuid.UUID) ([]*database.EmployeePreference, error) {
+func (m *MockPreferencesStore) GetPreferencesByEmployeeID(employeeID uuid.UUID) ([]database.EmployeePreference, error) {
 	args := m.Called(employeeID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*database.EmployeePreference), args.Error(1)
+	return args.Get(0).([]database.EmployeePreference), args.Error(1)
 }
 
 func (m *MockPreferencesStore) GetPreferenceByDay(employeeID uuid.UUID, day string) (*database.EmployeePreference, error) {
@@ -327,6 +574,19 @@ func (m *MockPreferencesStore) DeletePreferenceByDay(employeeID uuid.UUID, day s
 	return args.Error(0)
 }
 
+func (m *MockPreferencesStore) CopyPreferences(fromID uuid.UUID, toIDs []uuid.UUID) error {
+	args := m.Called(fromID, toIDs)
+	return args.Error(0)
+}
+
+func (m *MockPreferencesStore) GetUnavailability(employeeID uuid.UUID, from, to time.Time) ([]database.EmployeeUnavailability, error) {
+	args := m.Called(employeeID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.EmployeeUnavailability), args.Error(1)
+}
+
 // MockRulesStore
 type MockRulesStore struct {
 	mock.Mock
@@ -409,6 +669,14 @@ func (m *MockUploadService) ParseCSV(file multipart.File) (*service.CSVData, err
 	return args.Get(0).(*service.CSVData), args.Error(1)
 }
 
+func (m *MockUploadService) StreamCSV(file io.Reader) (*service.CSVStream, error) {
+	args := m.Called(file)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.CSVStream), args.Error(1)
+}
+
 // MockCampaignStore
 type MockCampaignStore struct {
 	mock.Mock
@@ -448,6 +716,27 @@ func (m *MockCampaignStore) GetCampaignInsights(orgID uuid.UUID) ([]database.Ins
 	return args.Get(0).([]database.Insight), args.Error(1)
 }
 
+func (m *MockCampaignStore) RecordRedemption(orgID uuid.UUID, code string, orderID uuid.UUID, at time.Time) error {
+	args := m.Called(orgID, code, orderID, at)
+	return args.Error(0)
+}
+
+func (m *MockCampaignStore) GetChannelPerformance(orgID uuid.UUID) ([]database.ChannelPerformance, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ChannelPerformance), args.Error(1)
+}
+
+func (m *MockCampaignStore) GetCampaignPerformance(orgID, campaignID uuid.UUID) (*database.CampaignPerformance, error) {
+	args := m.Called(orgID, campaignID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.CampaignPerformance), args.Error(1)
+}
+
 // MockOrderStore
 type MockOrderStore struct {
 	mock.Mock
@@ -461,6 +750,30 @@ func (m *MockOrderStore) GetAllOrders(orgID uuid.UUID) ([]database.Order, error)
 	return args.Get(0).([]database.Order), args.Error(1)
 }
 
+func (m *MockOrderStore) GetOrders(orgID uuid.UUID, filter database.OrderFilter) ([]database.Order, error) {
+	args := m.Called(orgID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.Order), args.Error(1)
+}
+
+func (m *MockOrderStore) GetOrdersInRange(orgID uuid.UUID, from, to time.Time) ([]database.Order, error) {
+	args := m.Called(orgID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.Order), args.Error(1)
+}
+
+func (m *MockOrderStore) GetOrder(orgID uuid.UUID, orderID uuid.UUID) (*database.Order, error) {
+	args := m.Called(orgID, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Order), args.Error(1)
+}
+
 func (m *MockOrderStore) GetAllOrdersForLastWeek(orgID uuid.UUID) ([]database.Order, error) {
 	args := m.Called(orgID)
 	if args.Get(0) == nil {
@@ -490,11 +803,67 @@ func (m *MockOrderStore) StoreOrder(orgID uuid.UUID, order *database.Order) erro
 	return args.Error(0)
 }
 
+func (m *MockOrderStore) StoreOrdersBulk(orgID uuid.UUID, orders []database.Order, onConflict string) ([]uuid.UUID, []uuid.UUID, error) {
+	args := m.Called(orgID, orders, onConflict)
+	var inserted, updated []uuid.UUID
+	if args.Get(0) != nil {
+		inserted = args.Get(0).([]uuid.UUID)
+	}
+	if args.Get(1) != nil {
+		updated = args.Get(1).([]uuid.UUID)
+	}
+	return inserted, updated, args.Error(2)
+}
+
+func (m *MockOrderStore) StoreNestedOrder(orgID uuid.UUID, order *database.Order) error {
+	args := m.Called(orgID, order)
+	return args.Error(0)
+}
+
+func (m *MockOrderStore) UpdateOrderProgress(orgID uuid.UUID, orderID uuid.UUID, orderStatus string, delivery *database.OrderDelivery) error {
+	args := m.Called(orgID, orderID, orderStatus, delivery)
+	return args.Error(0)
+}
+
+func (m *MockOrderStore) UpdateOrder(orgID uuid.UUID, order *database.Order) error {
+	args := m.Called(orgID, order)
+	return args.Error(0)
+}
+
+func (m *MockOrderStore) TransitionOrderStatus(orgID uuid.UUID, orderID uuid.UUID, toStatus string, changedBy *uuid.UUID, note *string) (*database.OrderStatusChange, error) {
+	args := m.Called(orgID, orderID, toStatus, changedBy, note)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.OrderStatusChange), args.Error(1)
+}
+
+func (m *MockOrderStore) GetOrderStatusHistory(orgID uuid.UUID, orderID uuid.UUID) ([]database.OrderStatusChange, error) {
+	args := m.Called(orgID, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.OrderStatusChange), args.Error(1)
+}
+
+func (m *MockOrderStore) DeleteOrder(orgID uuid.UUID, orderID uuid.UUID) error {
+	args := m.Called(orgID, orderID)
+	return args.Error(0)
+}
+
 func (m *MockOrderStore) StoreOrderItems(orgID uuid.UUID, orderID uuid.UUID, orderItem *database.OrderItem) error {
 	args := m.Called(orgID, orderID, orderItem)
 	return args.Error(0)
 }
 
+func (m *MockOrderStore) StoreOrderItemsBulk(orgID uuid.UUID, links []database.OrderItemLink) ([]int, error) {
+	args := m.Called(orgID, links)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int), args.Error(1)
+}
+
 func (m *MockOrderStore) StoreItems(orgID uuid.UUID, item *database.Item) error {
 	args := m.Called(orgID, item)
 	return args.Error(0)
@@ -508,6 +877,24 @@ func (m *MockOrderStore) GetAllItems(orgID uuid.UUID) ([]database.Item, error) {
 	return args.Get(0).([]database.Item), args.Error(1)
 }
 
+func (m *MockOrderStore) GetItem(orgID uuid.UUID, itemID uuid.UUID) (*database.Item, error) {
+	args := m.Called(orgID, itemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Item), args.Error(1)
+}
+
+func (m *MockOrderStore) UpdateItem(orgID uuid.UUID, item *database.Item, changedBy *uuid.UUID) error {
+	args := m.Called(orgID, item, changedBy)
+	return args.Error(0)
+}
+
+func (m *MockOrderStore) DeactivateItem(orgID uuid.UUID, itemID uuid.UUID) error {
+	args := m.Called(orgID, itemID)
+	return args.Error(0)
+}
+
 func (m *MockOrderStore) GetAllDeliveries(orgID uuid.UUID) ([]database.OrderDelivery, error) {
 	args := m.Called(orgID)
 	if args.Get(0) == nil {
@@ -516,6 +903,14 @@ func (m *MockOrderStore) GetAllDeliveries(orgID uuid.UUID) ([]database.OrderDeli
 	return args.Get(0).([]database.OrderDelivery), args.Error(1)
 }
 
+func (m *MockOrderStore) GetDeliveries(orgID uuid.UUID, filter database.DeliveryFilter) ([]database.OrderDelivery, error) {
+	args := m.Called(orgID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.OrderDelivery), args.Error(1)
+}
+
 func (m *MockOrderStore) GetAllDeliveriesForLastWeek(orgID uuid.UUID) ([]database.OrderDelivery, error) {
 	args := m.Called(orgID)
 	if args.Get(0) == nil {
@@ -553,15 +948,79 @@ func (m *MockOrderStore) GetItemsInsights(orgID uuid.UUID) ([]database.Insight,
 	return args.Get(0).([]database.Insight), args.Error(1)
 }
 
-// MockScheduleStore
-type MockScheduleStore struct {
-	mock.Mock
+func (m *MockOrderStore) GetChannelBreakdown(orgID uuid.UUID, from, to time.Time) ([]database.ChannelBreakdown, error) {
+	args := m.Called(orgID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ChannelBreakdown), args.Error(1)
 }
 
-func (m *MockScheduleStore) StoreScheduleForUser(orgID uuid.UUID, userID uuid.UUID, schedule *database.Schedule) error {
-	args := m.Called(orgID, userID, schedule)
-	return args.Error(0)
-}
+func (m *MockOrderStore) GetDiscountStats(orgID uuid.UUID, from, to time.Time) (*database.DiscountStats, error) {
+	args := m.Called(orgID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.DiscountStats), args.Error(1)
+}
+
+func (m *MockOrderStore) GetCustomerDiscounts(orgID uuid.UUID, from, to time.Time, minDiscountedOrders int) ([]database.CustomerDiscounts, error) {
+	args := m.Called(orgID, from, to, minDiscountedOrders)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.CustomerDiscounts), args.Error(1)
+}
+
+func (m *MockOrderStore) GetShiftDiscounts(orgID uuid.UUID, from, to time.Time) ([]database.ShiftDiscounts, error) {
+	args := m.Called(orgID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ShiftDiscounts), args.Error(1)
+}
+
+func (m *MockOrderStore) GetDeliveryVolume(orgID uuid.UUID, from, to time.Time) ([]database.DeliveryVolume, error) {
+	args := m.Called(orgID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.DeliveryVolume), args.Error(1)
+}
+
+func (m *MockOrderStore) GetHourlyDemand(orgID uuid.UUID, from, to time.Time) ([]database.HourlyDemand, error) {
+	args := m.Called(orgID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.HourlyDemand), args.Error(1)
+}
+
+func (m *MockOrderStore) GetDriverDeliveryStats(orgID uuid.UUID, from, to time.Time, onTimeMinutes int) ([]database.DriverDeliveryStats, error) {
+	args := m.Called(orgID, from, to, onTimeMinutes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.DriverDeliveryStats), args.Error(1)
+}
+
+func (m *MockOrderStore) GetDeliveryHeatmap(orgID uuid.UUID, cellSize float64, filter database.DeliveryFilter) ([]database.DeliveryHeatmapCell, error) {
+	args := m.Called(orgID, cellSize, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.DeliveryHeatmapCell), args.Error(1)
+}
+
+// MockScheduleStore
+type MockScheduleStore struct {
+	mock.Mock
+}
+
+func (m *MockScheduleStore) StoreScheduleForUser(orgID uuid.UUID, userID uuid.UUID, schedule *database.Schedule, actorID uuid.UUID) error {
+	args := m.Called(orgID, userID, schedule, actorID)
+	return args.Error(0)
+}
 
 func (m *MockScheduleStore) GetFullScheduleForSevenDays(orgID uuid.UUID) ([]database.Schedule, error) {
 	args := m.Called(orgID)
@@ -579,6 +1038,138 @@ func (m *MockScheduleStore) GetScheduleForEmployeeForSevenDays(orgID uuid.UUID,
 	return args.Get(0).([]database.Schedule), args.Error(1)
 }
 
+func (m *MockScheduleStore) AcknowledgeShifts(orgID uuid.UUID, userID uuid.UUID, shifts []database.ShiftKey) (int64, error) {
+	args := m.Called(orgID, userID, shifts)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockScheduleStore) GetShiftAcknowledgments(orgID uuid.UUID, filter database.ShiftFilter) ([]database.ShiftAcknowledgment, error) {
+	args := m.Called(orgID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ShiftAcknowledgment), args.Error(1)
+}
+
+func (m *MockScheduleStore) GetShiftsPendingReminder(cutoff time.Time) ([]database.ShiftAcknowledgment, error) {
+	args := m.Called(cutoff)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ShiftAcknowledgment), args.Error(1)
+}
+
+func (m *MockScheduleStore) MarkShiftRemindersSent(userID uuid.UUID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockScheduleStore) GetShiftsForWeeklyEmail(weekStart time.Time) ([]database.WeeklyShift, error) {
+	args := m.Called(weekStart)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.WeeklyShift), args.Error(1)
+}
+
+func (m *MockScheduleStore) MarkWeeklyEmailSent(userID uuid.UUID, weekStart time.Time) error {
+	args := m.Called(userID, weekStart)
+	return args.Error(0)
+}
+
+func (m *MockScheduleStore) GetScheduleClearSummary(orgID uuid.UUID, from time.Time, to time.Time) (*database.ScheduleClearSummary, error) {
+	args := m.Called(orgID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.ScheduleClearSummary), args.Error(1)
+}
+
+func (m *MockScheduleStore) ClearSchedule(orgID uuid.UUID, from time.Time, to time.Time, actorID uuid.UUID) (*database.ScheduleClearSummary, error) {
+	args := m.Called(orgID, from, to, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.ScheduleClearSummary), args.Error(1)
+}
+
+func (m *MockScheduleStore) StoreStandbyShift(orgID uuid.UUID, userID uuid.UUID, shift database.ShiftKey, actorID uuid.UUID) (*database.Shift, error) {
+	args := m.Called(orgID, userID, shift, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Shift), args.Error(1)
+}
+
+func (m *MockScheduleStore) ActivateShift(orgID uuid.UUID, shiftID uuid.UUID, at time.Time, actorID uuid.UUID) (*database.Shift, error) {
+	args := m.Called(orgID, shiftID, at, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Shift), args.Error(1)
+}
+
+func (m *MockScheduleStore) GetShiftEvents(orgID uuid.UUID, shiftID uuid.UUID) ([]database.ScheduleEvent, error) {
+	args := m.Called(orgID, shiftID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ScheduleEvent), args.Error(1)
+}
+
+func (m *MockScheduleStore) CancelShift(orgID uuid.UUID, shiftID uuid.UUID, request database.ShiftCancellationRequest, actorID uuid.UUID) (*database.ShiftCancellation, error) {
+	args := m.Called(orgID, shiftID, request, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.ShiftCancellation), args.Error(1)
+}
+
+func (m *MockScheduleStore) GetShiftCancellations(orgID uuid.UUID, filter database.CancellationFilter) ([]database.ShiftCancellation, error) {
+	args := m.Called(orgID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ShiftCancellation), args.Error(1)
+}
+
+func (m *MockScheduleStore) GetShiftsFrom(orgID uuid.UUID, from time.Time) ([]database.Shift, error) {
+	args := m.Called(orgID, from)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.Shift), args.Error(1)
+}
+
+func (m *MockScheduleStore) GetScheduledHours(orgID uuid.UUID, from time.Time, to time.Time) ([]database.EmployeeHours, error) {
+	args := m.Called(orgID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.EmployeeHours), args.Error(1)
+}
+
+func (m *MockScheduleStore) GetScheduleChanges(orgID uuid.UUID, from time.Time, to time.Time) ([]database.ScheduleChange, error) {
+	args := m.Called(orgID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ScheduleChange), args.Error(1)
+}
+
+func (m *MockScheduleStore) GetOrganizationsForComplianceReport(weekStart time.Time) ([]uuid.UUID, error) {
+	args := m.Called(weekStart)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockScheduleStore) MarkComplianceReportSent(orgID uuid.UUID, weekStart time.Time) error {
+	args := m.Called(orgID, weekStart)
+	return args.Error(0)
+}
+
 // MockDemandStore
 type MockDemandStore struct {
 	mock.Mock
@@ -601,3 +1192,1948 @@ func (m *MockDemandStore) DeleteDemandByOrganization(orgID uuid.UUID) (int64, er
 	args := m.Called(orgID)
 	return args.Get(0).(int64), args.Error(1)
 }
+
+// MockAnnouncementStore
+type MockAnnouncementStore struct {
+	mock.Mock
+}
+
+func (m *MockAnnouncementStore) CreateAnnouncement(announcement *database.Announcement) error {
+	args := m.Called(announcement)
+	return args.Error(0)
+}
+
+func (m *MockAnnouncementStore) GetAnnouncementByID(orgID uuid.UUID, id uuid.UUID) (*database.Announcement, error) {
+	args := m.Called(orgID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Announcement), args.Error(1)
+}
+
+func (m *MockAnnouncementStore) GetAnnouncementsForOrganization(orgID uuid.UUID) ([]database.Announcement, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.Announcement), args.Error(1)
+}
+
+func (m *MockAnnouncementStore) GetActiveAnnouncementsForUser(orgID uuid.UUID, userID uuid.UUID, roles []string) ([]database.Announcement, error) {
+	args := m.Called(orgID, userID, roles)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.Announcement), args.Error(1)
+}
+
+func (m *MockAnnouncementStore) DeleteAnnouncement(orgID uuid.UUID, id uuid.UUID) error {
+	args := m.Called(orgID, id)
+	return args.Error(0)
+}
+
+func (m *MockAnnouncementStore) MarkAnnouncementRead(id uuid.UUID, userID uuid.UUID) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+
+func (m *MockAnnouncementStore) GetAnnouncementReads(id uuid.UUID) ([]database.AnnouncementRead, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.AnnouncementRead), args.Error(1)
+}
+
+// MockMembershipStore
+type MockMembershipStore struct {
+	mock.Mock
+}
+
+func (m *MockMembershipStore) GetMembership(userID uuid.UUID, orgID uuid.UUID) (*database.OrganizationMembership, error) {
+	args := m.Called(userID, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.OrganizationMembership), args.Error(1)
+}
+
+func (m *MockMembershipStore) GetMembershipsForUser(userID uuid.UUID) ([]database.OrganizationMembership, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.OrganizationMembership), args.Error(1)
+}
+
+func (m *MockMembershipStore) AddMembership(membership *database.OrganizationMembership) error {
+	args := m.Called(membership)
+	return args.Error(0)
+}
+
+func (m *MockMembershipStore) RemoveMembership(userID uuid.UUID, orgID uuid.UUID) error {
+	args := m.Called(userID, orgID)
+	return args.Error(0)
+}
+
+// MockLoginSecurityStore
+type MockLoginSecurityStore struct {
+	mock.Mock
+}
+
+func (m *MockLoginSecurityStore) GetLoginState(userID uuid.UUID) (*database.LoginState, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.LoginState), args.Error(1)
+}
+
+func (m *MockLoginSecurityStore) RecordFailedLogin(userID uuid.UUID, maxAttempts int, lockout time.Duration) (*database.LoginState, error) {
+	args := m.Called(userID, maxAttempts, lockout)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.LoginState), args.Error(1)
+}
+
+func (m *MockLoginSecurityStore) ResetFailedLogins(userID uuid.UUID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockLoginSecurityStore) UnlockAccount(userID uuid.UUID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockLoginSecurityStore) RememberDevice(userID uuid.UUID, deviceHash, ipAddress, userAgent string) (bool, error) {
+	args := m.Called(userID, deviceHash, ipAddress, userAgent)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockAuditStore
+type MockAuditStore struct {
+	mock.Mock
+}
+
+func (m *MockAuditStore) RecordEvent(event *database.AuditEvent) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
+func (m *MockAuditStore) GetEvents(orgID uuid.UUID, action string, limit int) ([]database.AuditEvent, error) {
+	args := m.Called(orgID, action, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.AuditEvent), args.Error(1)
+}
+
+// MockOccupancyStore
+type MockOccupancyStore struct {
+	mock.Mock
+}
+
+func (m *MockOccupancyStore) GetTables(orgID uuid.UUID) ([]database.DiningTable, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.DiningTable), args.Error(1)
+}
+
+func (m *MockOccupancyStore) GetSeatingsBetween(orgID uuid.UUID, from, to time.Time) ([]database.TableSeating, error) {
+	args := m.Called(orgID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.TableSeating), args.Error(1)
+}
+
+// MockWaitTimeStore
+type MockWaitTimeStore struct {
+	mock.Mock
+}
+
+func (m *MockWaitTimeStore) GetOpenKitchenLoad(orgID uuid.UUID, since time.Time) (*database.KitchenLoad, error) {
+	args := m.Called(orgID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.KitchenLoad), args.Error(1)
+}
+
+func (m *MockWaitTimeStore) GetKitchenCapacity(orgID uuid.UUID, at time.Time) (*database.KitchenCapacity, error) {
+	args := m.Called(orgID, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.KitchenCapacity), args.Error(1)
+}
+
+func (m *MockWaitTimeStore) GetOrderProfile(orgID uuid.UUID) (*database.OrderProfile, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.OrderProfile), args.Error(1)
+}
+
+func (m *MockWaitTimeStore) MarkPrepared(orgID, orderID uuid.UUID, itemIDs []uuid.UUID, at time.Time) (*database.PreparedOrder, error) {
+	args := m.Called(orgID, orderID, itemIDs, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.PreparedOrder), args.Error(1)
+}
+
+func (m *MockWaitTimeStore) GetRecentPrepTime(orgID uuid.UUID, since time.Time) (*database.PrepTime, error) {
+	args := m.Called(orgID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.PrepTime), args.Error(1)
+}
+
+func (m *MockWaitTimeStore) GetItemPrepTimes(orgID uuid.UUID, since time.Time) ([]database.ItemPrepTime, error) {
+	args := m.Called(orgID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ItemPrepTime), args.Error(1)
+}
+
+func (m *MockWaitTimeStore) GetHourlyPrepTimes(orgID uuid.UUID, since time.Time) ([]database.HourPrepTime, error) {
+	args := m.Called(orgID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.HourPrepTime), args.Error(1)
+}
+
+// MockPrepListStore
+type MockPrepListStore struct {
+	mock.Mock
+}
+
+func (m *MockPrepListStore) GetDayForecast(orgID uuid.UUID, date time.Time) (*database.DayForecast, error) {
+	args := m.Called(orgID, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.DayForecast), args.Error(1)
+}
+
+func (m *MockPrepListStore) GetItemSales(orgID uuid.UUID, weekday time.Weekday, since time.Time) ([]database.ItemSales, error) {
+	args := m.Called(orgID, weekday, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ItemSales), args.Error(1)
+}
+
+// MockItemAvailabilityStore
+type MockItemAvailabilityStore struct {
+	mock.Mock
+}
+
+func (m *MockItemAvailabilityStore) GetAvailabilityWindows(orgID uuid.UUID) ([]database.ItemAvailabilityWindow, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ItemAvailabilityWindow), args.Error(1)
+}
+
+func (m *MockItemAvailabilityStore) SetItemAvailability(orgID uuid.UUID, itemID uuid.UUID, windows []database.ItemAvailabilityWindow) error {
+	args := m.Called(orgID, itemID, windows)
+	return args.Error(0)
+}
+
+// MockExternalEventStore
+type MockExternalEventStore struct {
+	mock.Mock
+}
+
+func (m *MockExternalEventStore) GetEvents(orgID uuid.UUID) ([]database.ExternalEvent, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ExternalEvent), args.Error(1)
+}
+
+func (m *MockExternalEventStore) GetEventsBetween(orgID uuid.UUID, from, to time.Time) ([]database.ExternalEvent, error) {
+	args := m.Called(orgID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ExternalEvent), args.Error(1)
+}
+
+func (m *MockExternalEventStore) GetEventByID(orgID uuid.UUID, id uuid.UUID) (*database.ExternalEvent, error) {
+	args := m.Called(orgID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.ExternalEvent), args.Error(1)
+}
+
+func (m *MockExternalEventStore) CreateEvent(orgID uuid.UUID, event *database.ExternalEvent) error {
+	args := m.Called(orgID, event)
+	return args.Error(0)
+}
+
+func (m *MockExternalEventStore) UpdateEvent(orgID uuid.UUID, event *database.ExternalEvent) error {
+	args := m.Called(orgID, event)
+	return args.Error(0)
+}
+
+func (m *MockExternalEventStore) DeleteEvent(orgID uuid.UUID, id uuid.UUID) error {
+	args := m.Called(orgID, id)
+	return args.Error(0)
+}
+
+// MockJobPostingStore
+type MockJobPostingStore struct {
+	mock.Mock
+}
+
+func (m *MockJobPostingStore) CreatePosting(posting *database.JobPosting) error {
+	args := m.Called(posting)
+	return args.Error(0)
+}
+
+func (m *MockJobPostingStore) GetPostings(orgID uuid.UUID) ([]database.JobPosting, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.JobPosting), args.Error(1)
+}
+
+func (m *MockJobPostingStore) GetOpenPostings(orgID uuid.UUID) ([]database.JobPosting, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.JobPosting), args.Error(1)
+}
+
+func (m *MockJobPostingStore) GetPostingByID(orgID uuid.UUID, id uuid.UUID) (*database.JobPosting, error) {
+	args := m.Called(orgID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.JobPosting), args.Error(1)
+}
+
+func (m *MockJobPostingStore) SetPostingStatus(orgID uuid.UUID, id uuid.UUID, status string) error {
+	args := m.Called(orgID, id, status)
+	return args.Error(0)
+}
+
+func (m *MockJobPostingStore) CreateApplicant(orgID uuid.UUID, applicant *database.JobApplicant) error {
+	args := m.Called(orgID, applicant)
+	return args.Error(0)
+}
+
+func (m *MockJobPostingStore) GetApplicants(orgID uuid.UUID, postingID uuid.UUID) ([]database.JobApplicant, error) {
+	args := m.Called(orgID, postingID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.JobApplicant), args.Error(1)
+}
+
+func (m *MockJobPostingStore) GetApplicantByID(orgID uuid.UUID, id uuid.UUID) (*database.JobApplicant, error) {
+	args := m.Called(orgID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.JobApplicant), args.Error(1)
+}
+
+func (m *MockJobPostingStore) SetApplicantStage(orgID uuid.UUID, id uuid.UUID, stage string) error {
+	args := m.Called(orgID, id, stage)
+	return args.Error(0)
+}
+
+// MockApplicantSessionStore
+type MockApplicantSessionStore struct {
+	mock.Mock
+}
+
+func (m *MockApplicantSessionStore) CreateSession(orgID uuid.UUID, session *database.ApplicantSession) error {
+	args := m.Called(orgID, session)
+	return args.Error(0)
+}
+
+func (m *MockApplicantSessionStore) GetSessionsForApplicant(orgID uuid.UUID, applicantID uuid.UUID) ([]database.ApplicantSession, error) {
+	args := m.Called(orgID, applicantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ApplicantSession), args.Error(1)
+}
+
+func (m *MockApplicantSessionStore) GetSessionsForSevenDays(orgID uuid.UUID) ([]database.ApplicantSession, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ApplicantSession), args.Error(1)
+}
+
+func (m *MockApplicantSessionStore) GetManagerConflicts(orgID uuid.UUID, managerID uuid.UUID, date time.Time, start, end string) ([]database.ManagerConflict, error) {
+	args := m.Called(orgID, managerID, date, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ManagerConflict), args.Error(1)
+}
+
+// MockEmployeeRecordStore
+type MockEmployeeRecordStore struct {
+	mock.Mock
+}
+
+func (m *MockEmployeeRecordStore) CreateRecord(orgID uuid.UUID, record *database.EmployeeRecord) error {
+	args := m.Called(orgID, record)
+	return args.Error(0)
+}
+
+func (m *MockEmployeeRecordStore) GetRecordsForEmployee(orgID uuid.UUID, employeeID uuid.UUID) ([]database.EmployeeRecord, error) {
+	args := m.Called(orgID, employeeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.EmployeeRecord), args.Error(1)
+}
+
+func (m *MockEmployeeRecordStore) GetRecordByID(orgID uuid.UUID, id uuid.UUID) (*database.EmployeeRecord, error) {
+	args := m.Called(orgID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.EmployeeRecord), args.Error(1)
+}
+
+func (m *MockEmployeeRecordStore) DeleteRecord(orgID uuid.UUID, id uuid.UUID) error {
+	args := m.Called(orgID, id)
+	return args.Error(0)
+}
+
+func (m *MockEmployeeRecordStore) PurgeExpiredRecords(now time.Time) (int64, error) {
+	args := m.Called(now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockOperatingHoursExceptionStore struct {
+	mock.Mock
+}
+
+func (m *MockOperatingHoursExceptionStore) GetExceptionsBetween(orgID uuid.UUID, from, to time.Time) ([]database.OperatingHoursException, error) {
+	args := m.Called(orgID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.OperatingHoursException), args.Error(1)
+}
+
+func (m *MockOperatingHoursExceptionStore) UpsertException(orgID uuid.UUID, exception *database.OperatingHoursException) error {
+	args := m.Called(orgID, exception)
+	return args.Error(0)
+}
+
+func (m *MockOperatingHoursExceptionStore) DeleteException(orgID uuid.UUID, date time.Time) error {
+	args := m.Called(orgID, date)
+	return args.Error(0)
+}
+
+type MockStatusStore struct {
+	mock.Mock
+}
+
+// AllowEvents accepts any recorded status event, for tests that do not check them
+func (m *MockStatusStore) AllowEvents() {
+	m.On("RecordEvent", mock.Anything, mock.Anything).Return(nil).Maybe()
+}
+
+// RecordedEvents returns the status events of the kind recorded so far
+func (m *MockStatusStore) RecordedEvents(kind string) []*database.StatusEvent {
+	var events []*database.StatusEvent
+	for _, call := range m.Calls {
+		if call.Method != "RecordEvent" {
+			continue
+		}
+		if event := call.Arguments.Get(1).(*database.StatusEvent); event.Kind == kind {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func (m *MockStatusStore) RecordEvent(orgID uuid.UUID, event *database.StatusEvent) error {
+	args := m.Called(orgID, event)
+	return args.Error(0)
+}
+
+func (m *MockStatusStore) GetLatestEvent(orgID uuid.UUID, kind string) (*database.StatusEvent, error) {
+	args := m.Called(orgID, kind)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.StatusEvent), args.Error(1)
+}
+
+func (m *MockStatusStore) GetMLLatency(orgID uuid.UUID, since time.Time) (*database.MLLatency, error) {
+	args := m.Called(orgID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.MLLatency), args.Error(1)
+}
+
+type MockIngestionRuleStore struct {
+	mock.Mock
+}
+
+func (m *MockIngestionRuleStore) GetRules(orgID uuid.UUID) ([]database.IngestionRule, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.IngestionRule), args.Error(1)
+}
+
+func (m *MockIngestionRuleStore) GetRulesForDataset(orgID uuid.UUID, dataset string) ([]database.IngestionRule, error) {
+	args := m.Called(orgID, dataset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.IngestionRule), args.Error(1)
+}
+
+func (m *MockIngestionRuleStore) CreateRule(orgID uuid.UUID, rule *database.IngestionRule) error {
+	args := m.Called(orgID, rule)
+	return args.Error(0)
+}
+
+func (m *MockIngestionRuleStore) DeleteRule(orgID uuid.UUID, ruleID uuid.UUID) error {
+	args := m.Called(orgID, ruleID)
+	return args.Error(0)
+}
+
+type MockNotificationStore struct {
+	mock.Mock
+}
+
+func (m *MockNotificationStore) GetSettings(userID uuid.UUID) (*database.NotificationSettings, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.NotificationSettings), args.Error(1)
+}
+
+func (m *MockNotificationStore) SetDigestMode(userID uuid.UUID, mode string) error {
+	args := m.Called(userID, mode)
+	return args.Error(0)
+}
+
+func (m *MockNotificationStore) GetDigestRecipients(emails []string) (map[string]uuid.UUID, error) {
+	args := m.Called(emails)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]uuid.UUID), args.Error(1)
+}
+
+func (m *MockNotificationStore) QueueNotification(userID uuid.UUID, dedupKey, summary string) error {
+	args := m.Called(userID, dedupKey, summary)
+	return args.Error(0)
+}
+
+func (m *MockNotificationStore) GetDueDigests(now time.Time) ([]database.NotificationDigest, error) {
+	args := m.Called(now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.NotificationDigest), args.Error(1)
+}
+
+func (m *MockNotificationStore) MarkDigestSent(userID uuid.UUID, notificationIDs []uuid.UUID, sentAt time.Time) error {
+	args := m.Called(userID, notificationIDs, sentAt)
+	return args.Error(0)
+}
+
+type MockEmailPreferenceStore struct {
+	mock.Mock
+}
+
+func (m *MockEmailPreferenceStore) GetOptOuts(email string) ([]string, error) {
+	args := m.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockEmailPreferenceStore) SetOptOuts(email string, categories []string) error {
+	args := m.Called(email, categories)
+	return args.Error(0)
+}
+
+func (m *MockEmailPreferenceStore) OptOut(email, category string) error {
+	args := m.Called(email, category)
+	return args.Error(0)
+}
+
+func (m *MockEmailPreferenceStore) GetOptedOut(category string, emails []string) (map[string]bool, error) {
+	args := m.Called(category, emails)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
+type MockBrandingStore struct {
+	mock.Mock
+}
+
+func (m *MockBrandingStore) GetLogo(orgID uuid.UUID) (*database.OrganizationLogo, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.OrganizationLogo), args.Error(1)
+}
+
+func (m *MockBrandingStore) GetLogoUpdatedAt(orgID uuid.UUID) (*time.Time, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*time.Time), args.Error(1)
+}
+
+func (m *MockBrandingStore) SetLogo(orgID uuid.UUID, contentType string, data []byte) error {
+	args := m.Called(orgID, contentType, data)
+	return args.Error(0)
+}
+
+type MockDeliveryPlatformStore struct {
+	mock.Mock
+}
+
+func (m *MockDeliveryPlatformStore) GetIntegrations(orgID uuid.UUID) ([]database.DeliveryPlatformIntegration, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.DeliveryPlatformIntegration), args.Error(1)
+}
+
+func (m *MockDeliveryPlatformStore) GetIntegration(orgID uuid.UUID, platform string) (*database.DeliveryPlatformIntegration, error) {
+	args := m.Called(orgID, platform)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.DeliveryPlatformIntegration), args.Error(1)
+}
+
+func (m *MockDeliveryPlatformStore) SaveIntegration(orgID uuid.UUID, integration *database.DeliveryPlatformIntegration) error {
+	args := m.Called(orgID, integration)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryPlatformStore) DeleteIntegration(orgID uuid.UUID, platform string) error {
+	args := m.Called(orgID, platform)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryPlatformStore) GetPolledIntegrations() ([]database.DeliveryPlatformIntegration, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.DeliveryPlatformIntegration), args.Error(1)
+}
+
+func (m *MockDeliveryPlatformStore) MarkPolled(orgID uuid.UUID, platform string, at time.Time) error {
+	args := m.Called(orgID, platform, at)
+	return args.Error(0)
+}
+
+func (m *MockDeliveryPlatformStore) MarkEventReceived(orgID uuid.UUID, platform string, at time.Time) error {
+	args := m.Called(orgID, platform, at)
+	return args.Error(0)
+}
+
+type MockForecastVarianceStore struct {
+	mock.Mock
+}
+
+func (m *MockForecastVarianceStore) GetForecastedOrganizations(date time.Time) ([]uuid.UUID, error) {
+	args := m.Called(date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockForecastVarianceStore) GetDayProgress(orgID uuid.UUID, date time.Time, throughHour int) (*database.DayProgress, error) {
+	args := m.Called(orgID, date, throughHour)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.DayProgress), args.Error(1)
+}
+
+func (m *MockForecastVarianceStore) GetStaffOnShift(orgID uuid.UUID, at time.Time) (int, error) {
+	args := m.Called(orgID, at)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockForecastVarianceStore) GetOnDutyManagerEmails(orgID uuid.UUID, at time.Time) ([]string, error) {
+	args := m.Called(orgID, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockForecastVarianceStore) GetLatestAlert(orgID uuid.UUID, date time.Time, direction string) (*database.ForecastVarianceAlert, error) {
+	args := m.Called(orgID, date, direction)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.ForecastVarianceAlert), args.Error(1)
+}
+
+func (m *MockForecastVarianceStore) StoreAlert(alert *database.ForecastVarianceAlert) error {
+	args := m.Called(alert)
+	return args.Error(0)
+}
+
+func (m *MockForecastVarianceStore) ListAlerts(orgID uuid.UUID, from, to time.Time) ([]database.ForecastVarianceAlert, error) {
+	args := m.Called(orgID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ForecastVarianceAlert), args.Error(1)
+}
+
+type MockOrderAcceptanceStore struct {
+	mock.Mock
+}
+
+func (m *MockOrderAcceptanceStore) GetAutoCloseOrganizations() ([]database.OrderAcceptanceSettings, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.OrderAcceptanceSettings), args.Error(1)
+}
+
+func (m *MockOrderAcceptanceStore) GetOrderLoad(orgID uuid.UUID, at time.Time) (*database.OrderLoad, error) {
+	args := m.Called(orgID, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.OrderLoad), args.Error(1)
+}
+
+func (m *MockOrderAcceptanceStore) SetAcceptingOrders(change *database.OrderAcceptanceChange) error {
+	args := m.Called(change)
+	return args.Error(0)
+}
+
+func (m *MockOrderAcceptanceStore) ListChanges(orgID uuid.UUID, from, to time.Time) ([]database.OrderAcceptanceChange, error) {
+	args := m.Called(orgID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.OrderAcceptanceChange), args.Error(1)
+}
+
+type MockMobileSummaryStore struct {
+	mock.Mock
+}
+
+func (m *MockMobileSummaryStore) GetPendingApprovals(orgID uuid.UUID, role string) (*database.PendingApprovals, error) {
+	args := m.Called(orgID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.PendingApprovals), args.Error(1)
+}
+
+func (m *MockMobileSummaryStore) GetAlertsSince(orgID uuid.UUID, since time.Time, limit int) ([]database.MobileAlert, error) {
+	args := m.Called(orgID, since, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.MobileAlert), args.Error(1)
+}
+
+// MockDeadLetterStore
+type MockDeadLetterStore struct {
+	mock.Mock
+}
+
+func (m *MockDeadLetterStore) CreateDeadLetter(orgID uuid.UUID, letter *database.DeadLetter) error {
+	args := m.Called(orgID, letter)
+	return args.Error(0)
+}
+
+func (m *MockDeadLetterStore) ListDeadLetters(orgID uuid.UUID, filter database.DeadLetterFilter) ([]database.DeadLetter, error) {
+	args := m.Called(orgID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.DeadLetter), args.Error(1)
+}
+
+func (m *MockDeadLetterStore) GetDeadLetter(orgID uuid.UUID, id uuid.UUID) (*database.DeadLetter, error) {
+	args := m.Called(orgID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.DeadLetter), args.Error(1)
+}
+
+func (m *MockDeadLetterStore) UpdatePayload(orgID uuid.UUID, id uuid.UUID, payload string, editedBy uuid.UUID, at time.Time) (*database.DeadLetter, error) {
+	args := m.Called(orgID, id, payload, editedBy, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.DeadLetter), args.Error(1)
+}
+
+func (m *MockDeadLetterStore) MarkReplayed(orgID uuid.UUID, id uuid.UUID, replayedBy uuid.UUID, at time.Time) (*database.DeadLetter, error) {
+	args := m.Called(orgID, id, replayedBy, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.DeadLetter), args.Error(1)
+}
+
+func (m *MockDeadLetterStore) RecordFailedReplay(orgID uuid.UUID, id uuid.UUID, reason string) (*database.DeadLetter, error) {
+	args := m.Called(orgID, id, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.DeadLetter), args.Error(1)
+}
+
+func (m *MockDeadLetterStore) GetFailureRates(orgID uuid.UUID, sources []string, since time.Time) ([]database.IngestionFailureRate, error) {
+	args := m.Called(orgID, sources, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.IngestionFailureRate), args.Error(1)
+}
+
+type MockDeliverySLAStore struct {
+	mock.Mock
+}
+
+func (m *MockDeliverySLAStore) GetDayBreaches(from, to time.Time) ([]database.DeliverySLADay, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.DeliverySLADay), args.Error(1)
+}
+
+func (m *MockDeliverySLAStore) StoreAlert(alert *database.DeliverySLAAlert) error {
+	args := m.Called(alert)
+	return args.Error(0)
+}
+
+type MockTrainingStore struct {
+	mock.Mock
+}
+
+func (m *MockTrainingStore) StartTraining(training *database.EmployeeTraining) error {
+	args := m.Called(training)
+	return args.Error(0)
+}
+
+func (m *MockTrainingStore) GetTrainings(orgID uuid.UUID) ([]database.EmployeeTraining, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.EmployeeTraining), args.Error(1)
+}
+
+func (m *MockTrainingStore) GetDueTrainings(on time.Time) ([]database.EmployeeTraining, error) {
+	args := m.Called(on)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.EmployeeTraining), args.Error(1)
+}
+
+func (m *MockTrainingStore) Graduate(orgID, userID uuid.UUID, at time.Time) error {
+	args := m.Called(orgID, userID, at)
+	return args.Error(0)
+}
+
+type MockKPITargetStore struct {
+	mock.Mock
+}
+
+func (m *MockKPITargetStore) GetTargets(orgID uuid.UUID) (*database.KPITargets, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.KPITargets), args.Error(1)
+}
+
+func (m *MockKPITargetStore) SetTargets(targets *database.KPITargets) error {
+	args := m.Called(targets)
+	return args.Error(0)
+}
+
+func (m *MockKPITargetStore) DeleteTargets(orgID uuid.UUID) error {
+	args := m.Called(orgID)
+	return args.Error(0)
+}
+
+func (m *MockKPITargetStore) GetSales(orgID uuid.UUID, from, to time.Time) (*database.KPISales, error) {
+	args := m.Called(orgID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.KPISales), args.Error(1)
+}
+
+func (m *MockKPITargetStore) GetOrganizationsForKPIReport(weekStart time.Time) ([]uuid.UUID, error) {
+	args := m.Called(weekStart)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockKPITargetStore) MarkKPIReportSent(orgID uuid.UUID, weekStart time.Time) error {
+	args := m.Called(orgID, weekStart)
+	return args.Error(0)
+}
+
+type MockBlackoutStore struct {
+	mock.Mock
+}
+
+func (m *MockBlackoutStore) CreateBlackout(orgID uuid.UUID, blackout *database.TimeOffBlackout) error {
+	args := m.Called(orgID, blackout)
+	return args.Error(0)
+}
+
+func (m *MockBlackoutStore) ListBlackouts(orgID uuid.UUID) ([]database.TimeOffBlackout, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.TimeOffBlackout), args.Error(1)
+}
+
+func (m *MockBlackoutStore) DeleteBlackout(orgID, blackoutID uuid.UUID) error {
+	args := m.Called(orgID, blackoutID)
+	return args.Error(0)
+}
+
+func (m *MockBlackoutStore) GetOverlappingBlackouts(orgID uuid.UUID, from, to time.Time) ([]database.TimeOffBlackout, error) {
+	args := m.Called(orgID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.TimeOffBlackout), args.Error(1)
+}
+
+type MockApprovalStore struct {
+	mock.Mock
+}
+
+func (m *MockApprovalStore) GetApprovalChains(orgID uuid.UUID) ([]database.ApprovalChain, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ApprovalChain), args.Error(1)
+}
+
+func (m *MockApprovalStore) GetApprovalChain(orgID uuid.UUID, requestType string) ([]string, error) {
+	args := m.Called(orgID, requestType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockApprovalStore) SetApprovalChain(orgID uuid.UUID, requestType string, steps []string, updatedBy uuid.UUID) error {
+	args := m.Called(orgID, requestType, steps, updatedBy)
+	return args.Error(0)
+}
+
+func (m *MockApprovalStore) DeleteApprovalChain(orgID uuid.UUID, requestType string) error {
+	args := m.Called(orgID, requestType)
+	return args.Error(0)
+}
+
+func (m *MockApprovalStore) StartApprovals(requestID uuid.UUID, steps []string) error {
+	args := m.Called(requestID, steps)
+	return args.Error(0)
+}
+
+func (m *MockApprovalStore) GetRequestApprovals(requestIDs []uuid.UUID) (map[uuid.UUID][]database.RequestApproval, error) {
+	args := m.Called(requestIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID][]database.RequestApproval), args.Error(1)
+}
+
+func (m *MockApprovalStore) DecideApprovalSteps(requestID uuid.UUID, steps []int, decision string, decidedBy uuid.UUID) error {
+	args := m.Called(requestID, steps, decision, decidedBy)
+	return args.Error(0)
+}
+
+type MockEmployeeComplianceStore struct {
+	mock.Mock
+}
+
+func (m *MockEmployeeComplianceStore) GetCompliance(userID uuid.UUID) (*database.EmployeeCompliance, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.EmployeeCompliance), args.Error(1)
+}
+
+func (m *MockEmployeeComplianceStore) SaveCompliance(compliance *database.EmployeeCompliance) error {
+	args := m.Called(compliance)
+	return args.Error(0)
+}
+
+func (m *MockEmployeeComplianceStore) GetWorkPermitExpiries(orgID uuid.UUID) (map[uuid.UUID]time.Time, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]time.Time), args.Error(1)
+}
+
+func (m *MockEmployeeComplianceStore) GetExpiringDocuments(until time.Time) ([]database.ExpiringDocument, error) {
+	args := m.Called(until)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ExpiringDocument), args.Error(1)
+}
+
+func (m *MockEmployeeComplianceStore) MarkExpiryAlerted(userID uuid.UUID, document string, days int) error {
+	args := m.Called(userID, document, days)
+	return args.Error(0)
+}
+
+type MockCustomFieldStore struct {
+	mock.Mock
+}
+
+// NoFields answers that the organization has no custom fields, for tests of list endpoints that do not check them
+func (m *MockCustomFieldStore) NoFields() {
+	m.On("ListFieldDefinitions", mock.Anything, mock.Anything).Return([]database.CustomFieldDefinition{}, nil).Maybe()
+}
+
+func (m *MockCustomFieldStore) ListFieldDefinitions(orgID uuid.UUID, entityType string) ([]database.CustomFieldDefinition, error) {
+	args := m.Called(orgID, entityType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.CustomFieldDefinition), args.Error(1)
+}
+
+func (m *MockCustomFieldStore) CreateFieldDefinition(orgID uuid.UUID, definition *database.CustomFieldDefinition) error {
+	args := m.Called(orgID, definition)
+	return args.Error(0)
+}
+
+func (m *MockCustomFieldStore) DeleteFieldDefinition(orgID, definitionID uuid.UUID) error {
+	args := m.Called(orgID, definitionID)
+	return args.Error(0)
+}
+
+func (m *MockCustomFieldStore) GetCustomFieldValues(orgID uuid.UUID, entityType string, entityIDs []uuid.UUID) (map[uuid.UUID]map[string]any, error) {
+	args := m.Called(orgID, entityType, entityIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]map[string]any), args.Error(1)
+}
+
+func (m *MockCustomFieldStore) SetCustomFieldValues(orgID uuid.UUID, entityType string, entityID uuid.UUID, values map[string]any) error {
+	args := m.Called(orgID, entityType, entityID, values)
+	return args.Error(0)
+}
+
+type MockSavedViewStore struct {
+	mock.Mock
+}
+
+func (m *MockSavedViewStore) ListViews(orgID, userID uuid.UUID, resource string) ([]database.SavedView, error) {
+	args := m.Called(orgID, userID, resource)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.SavedView), args.Error(1)
+}
+
+func (m *MockSavedViewStore) GetView(orgID, viewID uuid.UUID) (*database.SavedView, error) {
+	args := m.Called(orgID, viewID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.SavedView), args.Error(1)
+}
+
+func (m *MockSavedViewStore) CreateView(orgID uuid.UUID, view *database.SavedView) error {
+	args := m.Called(orgID, view)
+	return args.Error(0)
+}
+
+func (m *MockSavedViewStore) UpdateView(orgID uuid.UUID, view *database.SavedView) error {
+	args := m.Called(orgID, view)
+	return args.Error(0)
+}
+
+func (m *MockSavedViewStore) DeleteView(orgID, viewID uuid.UUID) error {
+	args := m.Called(orgID, viewID)
+	return args.Error(0)
+}
+
+type MockItemPriceStore struct {
+	mock.Mock
+}
+
+func (m *MockItemPriceStore) ApplyPriceChanges(orgID uuid.UUID, changes []database.ItemPriceChange) error {
+	args := m.Called(orgID, changes)
+	return args.Error(0)
+}
+
+func (m *MockItemPriceStore) GetPriceHistory(orgID, itemID uuid.UUID) ([]database.ItemPriceChange, error) {
+	args := m.Called(orgID, itemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ItemPriceChange), args.Error(1)
+}
+
+type MockWebhookStore struct {
+	mock.Mock
+}
+
+func (m *MockWebhookStore) GetWebhooks(orgID uuid.UUID) ([]database.Webhook, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookStore) GetWebhook(orgID, webhookID uuid.UUID) (*database.Webhook, error) {
+	args := m.Called(orgID, webhookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookStore) CreateWebhook(orgID uuid.UUID, webhook *database.Webhook) error {
+	args := m.Called(orgID, webhook)
+	return args.Error(0)
+}
+
+func (m *MockWebhookStore) UpdateWebhook(orgID uuid.UUID, webhook *database.Webhook) error {
+	args := m.Called(orgID, webhook)
+	return args.Error(0)
+}
+
+func (m *MockWebhookStore) DeleteWebhook(orgID, webhookID uuid.UUID) error {
+	args := m.Called(orgID, webhookID)
+	return args.Error(0)
+}
+
+func (m *MockWebhookStore) GetDeliveries(orgID, webhookID uuid.UUID, limit int) ([]database.WebhookDelivery, error) {
+	args := m.Called(orgID, webhookID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookStore) QueueEvent(orgID uuid.UUID, event string, payload []byte) (int64, error) {
+	args := m.Called(orgID, event, payload)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWebhookStore) ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]database.WebhookDelivery, error) {
+	args := m.Called(now, lease, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookStore) RecordAttempt(deliveryID uuid.UUID, attempt database.WebhookAttempt) error {
+	args := m.Called(deliveryID, attempt)
+	return args.Error(0)
+}
+
+type MockScheduleVersionStore struct {
+	mock.Mock
+}
+
+func (m *MockScheduleVersionStore) StoreScheduleVersion(orgID uuid.UUID, version *database.ScheduleVersion) error {
+	args := m.Called(orgID, version)
+	return args.Error(0)
+}
+
+func (m *MockScheduleVersionStore) GetScheduleVersions(orgID uuid.UUID, limit int) ([]database.ScheduleVersion, error) {
+	args := m.Called(orgID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ScheduleVersion), args.Error(1)
+}
+
+func (m *MockScheduleVersionStore) GetScheduleVersion(orgID, versionID uuid.UUID) (*database.ScheduleVersion, error) {
+	args := m.Called(orgID, versionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.ScheduleVersion), args.Error(1)
+}
+
+// AllowVersions accepts any stored schedule version, for tests that do not check them
+func (m *MockScheduleVersionStore) AllowVersions() {
+	m.On("StoreScheduleVersion", mock.Anything, mock.Anything).Return(nil).Maybe()
+}
+
+// StoredVersions returns the schedule versions stored so far
+func (m *MockScheduleVersionStore) StoredVersions() []*database.ScheduleVersion {
+	var versions []*database.ScheduleVersion
+	for _, call := range m.Calls {
+		if call.Method == "StoreScheduleVersion" {
+			versions = append(versions, call.Arguments.Get(1).(*database.ScheduleVersion))
+		}
+	}
+	return versions
+}
+
+type MockOrgRatingStore struct {
+	mock.Mock
+}
+
+func (m *MockOrgRatingStore) GetRatedOrganizations(since time.Time) ([]uuid.UUID, error) {
+	args := m.Called(since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockOrgRatingStore) GetOrderRatings(orgID uuid.UUID, since time.Time) ([]database.OrderRating, error) {
+	args := m.Called(orgID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.OrderRating), args.Error(1)
+}
+
+func (m *MockOrgRatingStore) StoreRating(rating *database.OrganizationRating) error {
+	args := m.Called(rating)
+	return args.Error(0)
+}
+
+func (m *MockOrgRatingStore) ListRatings(orgID uuid.UUID, from, to time.Time) ([]database.OrganizationRating, error) {
+	args := m.Called(orgID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.OrganizationRating), args.Error(1)
+}
+
+type MockKioskStore struct {
+	mock.Mock
+}
+
+func (m *MockKioskStore) CreateDevice(orgID uuid.UUID, device *database.KioskDevice, token string) error {
+	args := m.Called(orgID, device, token)
+	return args.Error(0)
+}
+
+func (m *MockKioskStore) ListDevices(orgID uuid.UUID) ([]database.KioskDevice, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.KioskDevice), args.Error(1)
+}
+
+func (m *MockKioskStore) RevokeDevice(orgID, deviceID uuid.UUID) error {
+	args := m.Called(orgID, deviceID)
+	return args.Error(0)
+}
+
+func (m *MockKioskStore) GetDeviceByToken(token string) (*database.KioskDevice, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.KioskDevice), args.Error(1)
+}
+
+func (m *MockKioskStore) SetPin(orgID, userID uuid.UUID, pinHash []byte) error {
+	args := m.Called(orgID, userID, pinHash)
+	return args.Error(0)
+}
+
+func (m *MockKioskStore) GetPin(orgID, userID uuid.UUID) (*database.KioskPin, error) {
+	args := m.Called(orgID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.KioskPin), args.Error(1)
+}
+
+func (m *MockKioskStore) RecordFailedPin(orgID, userID uuid.UUID, maxAttempts int, lockout time.Duration) (*database.LoginState, error) {
+	args := m.Called(orgID, userID, maxAttempts, lockout)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.LoginState), args.Error(1)
+}
+
+func (m *MockKioskStore) ResetFailedPins(orgID, userID uuid.UUID) error {
+	args := m.Called(orgID, userID)
+	return args.Error(0)
+}
+
+func (m *MockKioskStore) ListKioskEmployees(orgID uuid.UUID) ([]database.KioskEmployee, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.KioskEmployee), args.Error(1)
+}
+
+func (m *MockKioskStore) ClockIn(orgID uuid.UUID, entry *database.TimeClockEntry) error {
+	args := m.Called(orgID, entry)
+	return args.Error(0)
+}
+
+func (m *MockKioskStore) ClockOut(orgID, userID uuid.UUID, at time.Time) (*database.TimeClockEntry, error) {
+	args := m.Called(orgID, userID, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.TimeClockEntry), args.Error(1)
+}
+
+func (m *MockKioskStore) ListEntries(orgID uuid.UUID, from, to time.Time) ([]database.TimeClockEntry, error) {
+	args := m.Called(orgID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.TimeClockEntry), args.Error(1)
+}
+
+// MockStatusBoardStore
+type MockStatusBoardStore struct {
+	mock.Mock
+}
+
+func (m *MockStatusBoardStore) CreateBoard(orgID uuid.UUID, board *database.StatusBoard, token string) error {
+	args := m.Called(orgID, board, token)
+	return args.Error(0)
+}
+
+func (m *MockStatusBoardStore) ListBoards(orgID uuid.UUID) ([]database.StatusBoard, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.StatusBoard), args.Error(1)
+}
+
+func (m *MockStatusBoardStore) RevokeBoard(orgID, boardID uuid.UUID) error {
+	args := m.Called(orgID, boardID)
+	return args.Error(0)
+}
+
+func (m *MockStatusBoardStore) GetBoardByToken(token string) (*database.StatusBoard, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.StatusBoard), args.Error(1)
+}
+
+// MockExchangeRateStore
+type MockExchangeRateStore struct {
+	mock.Mock
+}
+
+func (m *MockExchangeRateStore) GetExchangeRates(base string, day time.Time) (map[string]float64, error) {
+	args := m.Called(base, day)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]float64), args.Error(1)
+}
+
+func (m *MockExchangeRateStore) StoreExchangeRates(base string, day time.Time, rates map[string]float64) error {
+	args := m.Called(base, day, rates)
+	return args.Error(0)
+}
+
+// MockExchangeRateProvider
+type MockExchangeRateProvider struct {
+	mock.Mock
+}
+
+func (m *MockExchangeRateProvider) Rates(ctx context.Context, base string, day time.Time) (map[string]float64, error) {
+	args := m.Called(base, day)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]float64), args.Error(1)
+}
+
+// MockStaffOrderStore
+type MockStaffOrderStore struct {
+	mock.Mock
+}
+
+func (m *MockStaffOrderStore) TagStaffOrder(orgID uuid.UUID, tag *database.StaffOrder) error {
+	args := m.Called(orgID, tag)
+	return args.Error(0)
+}
+
+func (m *MockStaffOrderStore) UntagStaffOrder(orgID, orderID uuid.UUID) error {
+	args := m.Called(orgID, orderID)
+	return args.Error(0)
+}
+
+func (m *MockStaffOrderStore) GetStaffMealConsumption(orgID uuid.UUID, from, to time.Time) ([]database.StaffMealConsumption, error) {
+	args := m.Called(orgID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.StaffMealConsumption), args.Error(1)
+}
+
+// MockEmployeeDocumentStore
+type MockEmployeeDocumentStore struct {
+	mock.Mock
+}
+
+func (m *MockEmployeeDocumentStore) GetTemplate(orgID uuid.UUID, kind string) (*database.DocumentTemplate, error) {
+	args := m.Called(orgID, kind)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.DocumentTemplate), args.Error(1)
+}
+
+func (m *MockEmployeeDocumentStore) SaveTemplate(orgID uuid.UUID, template *database.DocumentTemplate) error {
+	args := m.Called(orgID, template)
+	return args.Error(0)
+}
+
+func (m *MockEmployeeDocumentStore) CreateDocument(doc *database.EmployeeDocument, signToken string) error {
+	args := m.Called(doc, signToken)
+	return args.Error(0)
+}
+
+func (m *MockEmployeeDocumentStore) GetDocuments(orgID uuid.UUID, employeeID *uuid.UUID) ([]database.EmployeeDocument, error) {
+	args := m.Called(orgID, employeeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.EmployeeDocument), args.Error(1)
+}
+
+func (m *MockEmployeeDocumentStore) GetDocument(orgID, documentID uuid.UUID) (*database.EmployeeDocument, error) {
+	args := m.Called(orgID, documentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.EmployeeDocument), args.Error(1)
+}
+
+func (m *MockEmployeeDocumentStore) GetDocumentBySignToken(signToken string) (*database.EmployeeDocument, error) {
+	args := m.Called(signToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.EmployeeDocument), args.Error(1)
+}
+
+func (m *MockEmployeeDocumentStore) SignDocument(doc *database.EmployeeDocument, pdf []byte) error {
+	args := m.Called(doc, pdf)
+	return args.Error(0)
+}
+
+func (m *MockEmployeeDocumentStore) GetSignedPDF(orgID, documentID uuid.UUID) ([]byte, error) {
+	args := m.Called(orgID, documentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+// MockShiftNoteStore
+type MockShiftNoteStore struct {
+	mock.Mock
+}
+
+func (m *MockShiftNoteStore) CreateShiftNote(orgID uuid.UUID, note *database.ShiftNote) error {
+	args := m.Called(orgID, note)
+	return args.Error(0)
+}
+
+func (m *MockShiftNoteStore) GetUnresolvedShiftNotes(orgID uuid.UUID) ([]database.ShiftNote, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ShiftNote), args.Error(1)
+}
+
+func (m *MockShiftNoteStore) ResolveShiftNote(orgID, noteID, resolvedBy uuid.UUID) error {
+	args := m.Called(orgID, noteID, resolvedBy)
+	return args.Error(0)
+}
+
+type MockNotificationRoutingStore struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRoutingStore) GetRoutingRules(orgID uuid.UUID) ([]database.NotificationRoutingRule, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.NotificationRoutingRule), args.Error(1)
+}
+
+func (m *MockNotificationRoutingStore) CreateRoutingRule(orgID uuid.UUID, rule *database.NotificationRoutingRule) error {
+	args := m.Called(orgID, rule)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRoutingStore) DeleteRoutingRule(orgID uuid.UUID, ruleID uuid.UUID) error {
+	args := m.Called(orgID, ruleID)
+	return args.Error(0)
+}
+
+type MockSMSService struct {
+	mock.Mock
+}
+
+func (m *MockSMSService) SendSMS(toPhone, message string) error {
+	args := m.Called(toPhone, message)
+	return args.Error(0)
+}
+
+type MockMagicLinkStore struct {
+	mock.Mock
+}
+
+func (m *MockMagicLinkStore) CreateMagicLink(userID uuid.UUID, token, ipAddress string, expiresAt time.Time) error {
+	args := m.Called(userID, token, ipAddress, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockMagicLinkStore) CountRecentMagicLinks(userID uuid.UUID, since time.Time) (int, error) {
+	args := m.Called(userID, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMagicLinkStore) RecordMagicLinkRequest(ipAddress string, since time.Time) (int, error) {
+	args := m.Called(ipAddress, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMagicLinkStore) ConsumeMagicLink(token string) (uuid.UUID, error) {
+	args := m.Called(token)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+// MockOrderSheetStore
+type MockOrderSheetStore struct {
+	mock.Mock
+}
+
+func (m *MockOrderSheetStore) ListSyncs(orgID uuid.UUID) ([]database.OrderSheetSync, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.OrderSheetSync), args.Error(1)
+}
+
+func (m *MockOrderSheetStore) GetSync(orgID, syncID uuid.UUID) (*database.OrderSheetSync, error) {
+	args := m.Called(orgID, syncID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.OrderSheetSync), args.Error(1)
+}
+
+func (m *MockOrderSheetStore) CreateSync(orgID uuid.UUID, sheet *database.OrderSheetSync) error {
+	args := m.Called(orgID, sheet)
+	return args.Error(0)
+}
+
+func (m *MockOrderSheetStore) UpdateSync(orgID uuid.UUID, sheet *database.OrderSheetSync) error {
+	args := m.Called(orgID, sheet)
+	return args.Error(0)
+}
+
+func (m *MockOrderSheetStore) DeleteSync(orgID, syncID uuid.UUID) error {
+	args := m.Called(orgID, syncID)
+	return args.Error(0)
+}
+
+func (m *MockOrderSheetStore) GetEnabledSyncs() ([]database.OrderSheetSync, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.OrderSheetSync), args.Error(1)
+}
+
+func (m *MockOrderSheetStore) MarkSynced(syncID uuid.UUID, rowsImported int, at time.Time) error {
+	args := m.Called(syncID, rowsImported, at)
+	return args.Error(0)
+}
+
+func (m *MockOrderSheetStore) RecordRun(run *database.OrderSheetRun) error {
+	args := m.Called(run)
+	return args.Error(0)
+}
+
+func (m *MockOrderSheetStore) ListRuns(orgID, syncID uuid.UUID, limit int) ([]database.OrderSheetRun, error) {
+	args := m.Called(orgID, syncID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.OrderSheetRun), args.Error(1)
+}
+
+type MockStaffingAnalyticsStore struct {
+	mock.Mock
+}
+
+func (m *MockStaffingAnalyticsStore) GetStaffingVsDemand(orgID uuid.UUID, date time.Time, slotMinutes int) (*database.StaffingVsDemand, error) {
+	args := m.Called(orgID, date, slotMinutes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.StaffingVsDemand), args.Error(1)
+}
+
+func (m *MockStaffingAnalyticsStore) GetTurnover(orgID uuid.UUID, from time.Time, quarters int) ([]database.TurnoverQuarter, error) {
+	args := m.Called(orgID, from, quarters)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.TurnoverQuarter), args.Error(1)
+}
+
+// MockSkillStore
+type MockSkillStore struct {
+	mock.Mock
+}
+
+func (m *MockSkillStore) ProposeSkill(skill *database.EmployeeSkill) error {
+	args := m.Called(skill)
+	return args.Error(0)
+}
+
+func (m *MockSkillStore) GetSkills(orgID uuid.UUID, employeeID *uuid.UUID, status string) ([]database.EmployeeSkill, error) {
+	args := m.Called(orgID, employeeID, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.EmployeeSkill), args.Error(1)
+}
+
+func (m *MockSkillStore) GetSkill(orgID, skillID uuid.UUID) (*database.EmployeeSkill, error) {
+	args := m.Called(orgID, skillID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.EmployeeSkill), args.Error(1)
+}
+
+func (m *MockSkillStore) ReviewSkill(skill *database.EmployeeSkill) error {
+	args := m.Called(skill)
+	return args.Error(0)
+}
+
+func (m *MockSkillStore) DeleteSkill(employeeID, skillID uuid.UUID) error {
+	args := m.Called(employeeID, skillID)
+	return args.Error(0)
+}
+
+func (m *MockSkillStore) GetRoleSkillRequirements(orgID uuid.UUID) ([]database.RoleSkillRequirement, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.RoleSkillRequirement), args.Error(1)
+}
+
+func (m *MockSkillStore) SetRoleSkillRequirement(orgID uuid.UUID, requirement database.RoleSkillRequirement, updatedBy uuid.UUID) error {
+	args := m.Called(orgID, requirement, updatedBy)
+	return args.Error(0)
+}
+
+func (m *MockSkillStore) GetVerifiedSkills(orgID uuid.UUID, on time.Time) (map[uuid.UUID][]string, error) {
+	args := m.Called(orgID, on)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID][]string), args.Error(1)
+}
+
+func (m *MockSkillStore) GetExpiringSkills(until time.Time) ([]database.EmployeeSkill, error) {
+	args := m.Called(until)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.EmployeeSkill), args.Error(1)
+}
+
+func (m *MockSkillStore) MarkSkillExpiryReminded(skillID uuid.UUID) error {
+	args := m.Called(skillID)
+	return args.Error(0)
+}
+
+type MockBroadcastStore struct {
+	mock.Mock
+}
+
+func (m *MockBroadcastStore) CreateBroadcast(broadcast *database.Broadcast, deliveries []database.BroadcastDelivery) error {
+	args := m.Called(broadcast, deliveries)
+	return args.Error(0)
+}
+
+func (m *MockBroadcastStore) SetBroadcastDeliveryStatus(broadcastID uuid.UUID, delivery database.BroadcastDelivery) error {
+	args := m.Called(broadcastID, delivery)
+	return args.Error(0)
+}
+
+func (m *MockBroadcastStore) GetBroadcasts(orgID uuid.UUID) ([]database.Broadcast, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.Broadcast), args.Error(1)
+}
+
+func (m *MockBroadcastStore) GetBroadcast(orgID, broadcastID uuid.UUID) (*database.Broadcast, error) {
+	args := m.Called(orgID, broadcastID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Broadcast), args.Error(1)
+}
+
+func (m *MockBroadcastStore) GetBroadcastDeliveries(broadcastID uuid.UUID) ([]database.BroadcastDelivery, error) {
+	args := m.Called(broadcastID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.BroadcastDelivery), args.Error(1)
+}
+
+type MockOrderImportStore struct {
+	mock.Mock
+}
+
+func (m *MockOrderImportStore) CreateOrderImport(orderImport *database.OrderImport) error {
+	args := m.Called(orderImport)
+	return args.Error(0)
+}
+
+func (m *MockOrderImportStore) StageOrderImportRows(importID uuid.UUID, rows []database.OrderImportRow) error {
+	args := m.Called(importID, rows)
+	return args.Error(0)
+}
+
+func (m *MockOrderImportStore) ValidateOrderImport(orgID, importID uuid.UUID) (*database.OrderImport, error) {
+	args := m.Called(orgID, importID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.OrderImport), args.Error(1)
+}
+
+func (m *MockOrderImportStore) GetOrderImports(orgID uuid.UUID) ([]database.OrderImport, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.OrderImport), args.Error(1)
+}
+
+func (m *MockOrderImportStore) GetOrderImport(orgID, importID uuid.UUID) (*database.OrderImport, error) {
+	args := m.Called(orgID, importID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.OrderImport), args.Error(1)
+}
+
+func (m *MockOrderImportStore) GetOrderImportProblems(importID uuid.UUID, limit int) ([]database.OrderImportRow, error) {
+	args := m.Called(importID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.OrderImportRow), args.Error(1)
+}
+
+func (m *MockOrderImportStore) PromoteOrderImport(orgID, importID uuid.UUID) (*database.OrderImport, error) {
+	args := m.Called(orgID, importID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.OrderImport), args.Error(1)
+}
+
+func (m *MockOrderImportStore) DiscardOrderImport(orgID, importID uuid.UUID) error {
+	args := m.Called(orgID, importID)
+	return args.Error(0)
+}
+
+type MockExportJobStore struct {
+	mock.Mock
+}
+
+func (m *MockExportJobStore) CreateExportJob(job *database.ExportJob) error {
+	args := m.Called(job)
+	return args.Error(0)
+}
+
+func (m *MockExportJobStore) GetExportJobs(orgID uuid.UUID) ([]database.ExportJob, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ExportJob), args.Error(1)
+}
+
+func (m *MockExportJobStore) GetExportJob(orgID, jobID uuid.UUID) (*database.ExportJob, error) {
+	args := m.Called(orgID, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.ExportJob), args.Error(1)
+}
+
+func (m *MockExportJobStore) ClaimExportJob(now time.Time, lease time.Duration) (*database.ExportJob, error) {
+	args := m.Called(now, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.ExportJob), args.Error(1)
+}
+
+func (m *MockExportJobStore) CompleteExportJob(jobID uuid.UUID, fileKey string, rowCount int, sizeBytes int64, expiresAt time.Time) error {
+	args := m.Called(jobID, fileKey, rowCount, sizeBytes, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockExportJobStore) FailExportJob(jobID uuid.UUID, message string) error {
+	args := m.Called(jobID, message)
+	return args.Error(0)
+}
+
+func (m *MockExportJobStore) GetExpiredExportJobs(now time.Time) ([]database.ExportJob, error) {
+	args := m.Called(now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ExportJob), args.Error(1)
+}
+
+func (m *MockExportJobStore) MarkExportJobExpired(jobID uuid.UUID) error {
+	args := m.Called(jobID)
+	return args.Error(0)
+}
+
+type MockExportEncryptionStore struct {
+	mock.Mock
+}
+
+func (m *MockExportEncryptionStore) GetExportEncryption(orgID uuid.UUID) (*database.ExportEncryption, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.ExportEncryption), args.Error(1)
+}
+
+func (m *MockExportEncryptionStore) SetExportEncryption(encryption *database.ExportEncryption) error {
+	args := m.Called(encryption)
+	return args.Error(0)
+}
+
+func (m *MockExportEncryptionStore) DeleteExportEncryption(orgID uuid.UUID) error {
+	args := m.Called(orgID)
+	return args.Error(0)
+}
+
+// MockTOTPStore
+type MockTOTPStore struct {
+	mock.Mock
+}
+
+func (m *MockTOTPStore) GetTOTP(userID uuid.UUID) (*database.UserTOTP, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.UserTOTP), args.Error(1)
+}
+
+func (m *MockTOTPStore) SaveTOTPSecret(userID uuid.UUID, secret string) error {
+	args := m.Called(userID, secret)
+	return args.Error(0)
+}
+
+func (m *MockTOTPStore) EnableTOTP(userID uuid.UUID, counter int64) error {
+	args := m.Called(userID, counter)
+	return args.Error(0)
+}
+
+func (m *MockTOTPStore) UseTOTPCounter(userID uuid.UUID, counter int64) (bool, error) {
+	args := m.Called(userID, counter)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTOTPStore) DeleteTOTP(userID uuid.UUID) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
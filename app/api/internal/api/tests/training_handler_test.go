@@ -0,0 +1,291 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/mlproto"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type TrainingTestEnv struct {
+	UserStore     *MockUserStore
+	TrainingStore *MockTrainingStore
+	Handler       *api.TrainingHandler
+}
+
+func setupTrainingEnv() *TrainingTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	userStore := new(MockUserStore)
+	trainingStore := new(MockTrainingStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &TrainingTestEnv{
+		UserStore:     userStore,
+		TrainingStore: trainingStore,
+		Handler:       api.NewTrainingHandler(userStore, trainingStore, logger),
+	}
+}
+
+func (env *TrainingTestEnv) ResetMocks() {
+	env.UserStore.ExpectedCalls = nil
+	env.UserStore.Calls = nil
+	env.TrainingStore.ExpectedCalls = nil
+	env.TrainingStore.Calls = nil
+}
+
+func TestGetTrainingsHandler(t *testing.T) {
+	env := setupTrainingEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	route := "/:org/staffing/trainings"
+	path := "/" + orgID.String() + "/staffing/trainings"
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		trainings := []database.EmployeeTraining{{UserID: uuid.New(), OrganizationID: orgID, FullName: "Tara Trainee"}}
+		env.TrainingStore.On("GetTrainings", orgID).Return(trainings, nil).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetTrainingsHandler}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Tara Trainee")
+	})
+
+	t.Run("Failure_EmployeeForbidden", func(t *testing.T) {
+		env.ResetMocks()
+		employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(employee), env.Handler.GetTrainingsHandler}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		env.TrainingStore.AssertNotCalled(t, "GetTrainings", mock.Anything)
+	})
+
+	t.Run("Failure_DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.TrainingStore.On("GetTrainings", orgID).Return(nil, errors.New("db error")).Once()
+
+		w := jobRequest("GET", route, path, []gin.HandlerFunc{authMiddleware(manager), env.Handler.GetTrainingsHandler}, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestStartTrainingHandler(t *testing.T) {
+	env := setupTrainingEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee", FullName: "Tara Trainee"}
+	route := "/:org/staffing/employees/:id/training"
+	path := "/" + orgID.String() + "/staffing/employees/" + employee.ID.String() + "/training"
+	handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.StartTrainingHandler}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		env.UserStore.On("GetUserByID", employee.ID).Return(employee, nil).Once()
+		env.TrainingStore.On("StartTraining", mock.MatchedBy(func(training *database.EmployeeTraining) bool {
+			return training.UserID == employee.ID && training.OrganizationID == orgID && training.StartsOn.Equal(today) &&
+				training.EndsOn.Equal(today.AddDate(0, 0, 14)) && *training.StartedBy == manager.ID
+		})).Return(nil).Once()
+
+		w := jobRequest("PUT", route, path, handlers, api.StartTrainingRequest{Days: 14})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Employee training started successfully")
+		env.TrainingStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_OtherOrganization", func(t *testing.T) {
+		env.ResetMocks()
+		stranger := &database.User{ID: employee.ID, OrganizationID: uuid.New(), UserRole: "employee"}
+		env.UserStore.On("GetUserByID", employee.ID).Return(stranger, nil).Once()
+
+		w := jobRequest("PUT", route, path, handlers, api.StartTrainingRequest{Days: 14})
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		env.TrainingStore.AssertNotCalled(t, "StartTraining", mock.Anything)
+	})
+
+	t.Run("Failure_Admin", func(t *testing.T) {
+		env.ResetMocks()
+		admin := &database.User{ID: employee.ID, OrganizationID: orgID, UserRole: "admin"}
+		env.UserStore.On("GetUserByID", employee.ID).Return(admin, nil).Once()
+
+		w := jobRequest("PUT", route, path, handlers, api.StartTrainingRequest{Days: 14})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Admins are not scheduled")
+	})
+
+	t.Run("Failure_Validation", func(t *testing.T) {
+		env.ResetMocks()
+		env.UserStore.On("GetUserByID", employee.ID).Return(employee, nil).Once()
+
+		w := jobRequest("PUT", route, path, handlers, api.StartTrainingRequest{Days: 400})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		env.TrainingStore.AssertNotCalled(t, "StartTraining", mock.Anything)
+	})
+}
+
+func TestGraduateTrainingHandler(t *testing.T) {
+	env := setupTrainingEnv()
+	orgID := uuid.New()
+	manager := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "manager"}
+	employeeID := uuid.New()
+	route := "/:org/staffing/employees/:id/training"
+	path := "/" + orgID.String() + "/staffing/employees/" + employeeID.String() + "/training"
+	handlers := []gin.HandlerFunc{authMiddleware(manager), env.Handler.GraduateTrainingHandler}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.TrainingStore.On("Graduate", orgID, employeeID, mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+		w := jobRequest("DELETE", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		env.TrainingStore.AssertExpectations(t)
+	})
+
+	t.Run("Failure_NotInTraining", func(t *testing.T) {
+		env.ResetMocks()
+		env.TrainingStore.On("Graduate", orgID, employeeID, mock.AnythingOfType("time.Time")).Return(sql.ErrNoRows).Once()
+
+		w := jobRequest("DELETE", route, path, handlers, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "Employee is not in training")
+	})
+}
+
+func TestScheduleEmployeesInTraining(t *testing.T) {
+	env := setupScheduleEnv()
+	trainingStore := new(MockTrainingStore)
+	env.Handler.TrainingStore = trainingStore
+	orgID := uuid.New()
+	admin := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "admin"}
+	nextWeek := time.Now().AddDate(0, 0, 7)
+
+	env.Router.POST("/:org/schedule/predict", authMiddleware(admin), env.Handler.PredictScheduleHandler)
+	env.Router.GET("/:org/schedule", authMiddleware(admin), env.Handler.GetScheduleHandler)
+
+	t.Run("Success_SupernumeraryOutOfSoloRoles", func(t *testing.T) {
+		env.ResetMocks()
+		trainingStore.ExpectedCalls = nil
+		var sent mlproto.SchedulePredictRequest
+		mlServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			json.Unmarshal(body, &sent)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer mlServer.Close()
+		t.Setenv("ML_URL", mlServer.URL)
+
+		employee := mockValidSchedulePrediction(env, orgID)
+		wage := 12.0
+		trainee := &database.User{ID: uuid.New(), FullName: "Tara Trainee", UserRole: "employee", OrganizationID: orgID, SalaryPerHour: &wage}
+		driver := &database.User{ID: uuid.New(), FullName: "Dan Driver", UserRole: "employee", OrganizationID: orgID, SalaryPerHour: &wage}
+		env.RoleStore.ExpectedCalls = nil
+		env.RoleStore.On("GetRolesByOrganizationID", orgID).Return([]database.OrganizationRole{
+			{OrganizationID: orgID, Role: "driver", SoloCoverage: true},
+			{OrganizationID: orgID, Role: "server", MinNeededPerShift: 1},
+		}, nil)
+		env.UserStore.ExpectedCalls = nil
+		env.UserStore.On("GetUsersByOrganization", orgID).Return([]*database.User{employee, trainee, driver}, nil)
+		for _, user := range []*database.User{employee, trainee, driver} {
+			env.UserStore.On("GetUserByID", user.ID).Return(user, nil)
+			env.PreferenceStore.On("GetPreferencesByEmployeeID", user.ID).Return([]database.EmployeePreference{}, nil)
+			env.PreferenceStore.On("GetUnavailability", user.ID, mock.Anything, mock.Anything).Return([]database.EmployeeUnavailability{}, nil)
+		}
+		env.UserRolesStore.On("GetUserRoles", trainee.ID, orgID).Return([]string{"employee", "server", "driver"}, nil)
+		env.UserRolesStore.On("GetUserRoles", driver.ID, orgID).Return([]string{"employee", "driver"}, nil)
+		trainingStore.On("GetTrainings", orgID).Return([]database.EmployeeTraining{
+			{UserID: trainee.ID, OrganizationID: orgID, EndsOn: nextWeek},
+			{UserID: driver.ID, OrganizationID: orgID, EndsOn: nextWeek},
+		}, nil)
+		env.ScheduleStore.On("StoreScheduleForUser", orgID, mock.Anything, mock.AnythingOfType("*database.Schedule"), admin.ID).Return(nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/schedule/predict", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		// the driver in training holds no other role, so is left out
+		if employees := sent.ScheduleInput.Employees; assert.Len(t, employees, 2) {
+			assert.False(t, employees[0].Supernumerary)
+			assert.Equal(t, trainee.ID, employees[1].EmployeeID)
+			assert.True(t, employees[1].Supernumerary)
+			assert.Equal(t, []string{"employee", "server"}, employees[1].RoleNames)
+		}
+
+		var resp map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		// the trainee shadows the shift and does not count toward the coverage
+		output := resp["schedule_output"].(map[string]any)
+		assert.Equal(t, []any{map[string]any{"09:00-13:00": []any{"Sam Server", "Tara Trainee"}}}, output["monday"])
+		assert.Equal(t, 100.0, resp["coverage_percent"])
+		assert.Equal(t, []any{"Tara Trainee"}, resp["in_training"])
+	})
+
+	t.Run("Failure_TrainingsError", func(t *testing.T) {
+		env.ResetMocks()
+		trainingStore.ExpectedCalls = nil
+		mockValidSchedulePrediction(env, orgID)
+		trainingStore.On("GetTrainings", orgID).Return(nil, errors.New("db error")).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/"+orgID.String()+"/schedule/predict", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Body.String(), "failed to get employee trainings")
+	})
+
+	t.Run("Success_HighlightsTraineesInSchedule", func(t *testing.T) {
+		env.ResetMocks()
+		trainingStore.ExpectedCalls = nil
+		trainee, graduating, colleague := uuid.New(), uuid.New(), uuid.New()
+		today := time.Now()
+		schedules := []database.Schedule{
+			{Date: today, Day: today.Weekday().String(), StartTime: "09:00:00", EndTime: "17:00:00",
+				Employees: []string{colleague.String(), trainee.String(), graduating.String()}},
+		}
+		env.ScheduleStore.On("GetFullScheduleForSevenDays", orgID).Return(schedules, nil).Once()
+		env.SessionStore.On("GetSessionsForSevenDays", orgID).Return([]database.ApplicantSession{}, nil).Once()
+		trainingStore.On("GetTrainings", orgID).Return([]database.EmployeeTraining{
+			{UserID: trainee, OrganizationID: orgID, EndsOn: nextWeek},
+			// graduates today, so works today as the rest of the staff
+			{UserID: graduating, OrganizationID: orgID, EndsOn: today},
+		}, nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/schedule", nil)
+		env.Router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data []database.Schedule `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		if assert.Len(t, response.Data, 1) {
+			assert.Equal(t, []string{trainee.String()}, response.Data[0].InTraining)
+		}
+	})
+}
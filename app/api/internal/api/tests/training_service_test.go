@@ -0,0 +1,100 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type TrainingServiceTestEnv struct {
+	Store    *MockTrainingStore
+	OrgStore *MockOrgStore
+	Email    *MockEmailService
+	Service  *service.TrainingService
+}
+
+func setupTrainingServiceEnv() *TrainingServiceTestEnv {
+	store := new(MockTrainingStore)
+	orgStore := new(MockOrgStore)
+	email := new(MockEmailService)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &TrainingServiceTestEnv{
+		Store:    store,
+		OrgStore: orgStore,
+		Email:    email,
+		Service:  &service.TrainingService{Store: store, OrgStore: orgStore, EmailService: email, Logger: logger, Interval: time.Hour},
+	}
+}
+
+func (env *TrainingServiceTestEnv) ResetMocks() {
+	env.Store.ExpectedCalls = nil
+	env.Store.Calls = nil
+	env.OrgStore.ExpectedCalls = nil
+	env.OrgStore.Calls = nil
+	env.Email.ExpectedCalls = nil
+	env.Email.Calls = nil
+}
+
+func TestGraduateDueTrainings(t *testing.T) {
+	env := setupTrainingServiceEnv()
+	orgID := uuid.New()
+	now := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	emails := []string{"manager@example.com", "admin@example.com"}
+	training := database.EmployeeTraining{
+		UserID:         uuid.New(),
+		OrganizationID: orgID,
+		FullName:       "Tara Trainee",
+		StartsOn:       time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC),
+		EndsOn:         time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("GraduateAndNotify", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("GetDueTrainings", now).Return([]database.EmployeeTraining{training}, nil).Once()
+		env.Store.On("Graduate", orgID, training.UserID, now).Return(nil).Once()
+		env.OrgStore.On("GetManagerEmailsByOrgID", orgID).Return([]string{"manager@example.com"}, nil).Once()
+		env.OrgStore.On("GetAdminEmailsByOrgID", orgID).Return([]string{"admin@example.com"}, nil).Once()
+		env.Email.On("SendTrainingGraduationEmail", emails,
+			"Tara Trainee completed the training period of 14 days started on 2026-10-02 and is now scheduled like the rest of the staff.").Return(nil).Once()
+
+		graduated, err := env.Service.GraduateDue(now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, graduated)
+		env.Store.AssertExpectations(t)
+		env.OrgStore.AssertExpectations(t)
+		env.Email.AssertExpectations(t)
+	})
+
+	t.Run("AlreadyGraduated", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("GetDueTrainings", now).Return([]database.EmployeeTraining{training}, nil).Once()
+		env.Store.On("Graduate", orgID, training.UserID, now).Return(sql.ErrNoRows).Once()
+
+		graduated, err := env.Service.GraduateDue(now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, graduated)
+		env.Email.AssertNotCalled(t, "SendTrainingGraduationEmail", mock.Anything, mock.Anything)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.Store.On("GetDueTrainings", now).Return(nil, errors.New("db error")).Once()
+
+		graduated, err := env.Service.GraduateDue(now)
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, graduated)
+	})
+}
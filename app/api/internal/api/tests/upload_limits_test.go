@@ -0,0 +1,273 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupUploadLimitRouter(envKey string, defaultMaxMB int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/upload", middleware.LimitUpload(envKey, defaultMaxMB), func(c *gin.Context) {
+		file, _, err := c.Request.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+			return
+		}
+		defer file.Close()
+		c.JSON(http.StatusOK, gin.H{"message": "uploaded"})
+	})
+	return router
+}
+
+func uploadRequest(filename, contentType string, content []byte) *http.Request {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="file"; filename="`+filename+`"`)
+	header.Set("Content-Type", contentType)
+	part, _ := writer.CreatePart(header)
+	part.Write(content)
+	writer.Close()
+
+	req, _ := http.NewRequest("POST", "/upload", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestLimitUpload(t *testing.T) {
+	router := setupUploadLimitRouter("UPLOAD_MAX_MB_TEST", 1)
+
+	t.Run("Success", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, uploadRequest("orders.csv", "text/csv", []byte("order_id\n1\n")))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("TooLarge_ContentLength", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, uploadRequest("orders.csv", "text/csv", bytes.Repeat([]byte("a"), 2<<20)))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Contains(t, w.Body.String(), "1 MB")
+		assert.Contains(t, w.Body.String(), middleware.UploadSplitHint)
+	})
+
+	t.Run("TooLarge_UnknownLength", func(t *testing.T) {
+		req := uploadRequest("orders.csv", "text/csv", bytes.Repeat([]byte("a"), 2<<20))
+		req.ContentLength = -1
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("NotMultipart", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/upload", strings.NewReader(`{"file": "orders.csv"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+
+	t.Run("NotCSV_Extension", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, uploadRequest("orders.exe", "application/octet-stream", []byte("MZ")))
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+
+	t.Run("XLSX", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, uploadRequest("orders.xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", []byte("PK\x03\x04")))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("NotCSV_ContentType", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, uploadRequest("orders.csv", "image/png", []byte("png")))
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+
+	t.Run("EnvOverride", func(t *testing.T) {
+		t.Setenv("UPLOAD_MAX_MB_TEST_OVERRIDE", "3")
+		router := setupUploadLimitRouter("UPLOAD_MAX_MB_TEST_OVERRIDE", 1)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, uploadRequest("orders.csv", "text/csv", bytes.Repeat([]byte("a"), 2<<20)))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestLimitImageUpload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/upload", middleware.LimitImageUpload("UPLOAD_MAX_MB_TEST", 1), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "uploaded"})
+	})
+
+	t.Run("PNG", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, uploadRequest("logo.png", "image/png", []byte("\x89PNG\r\n\x1a\n")))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("NotImage", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, uploadRequest("logo.csv", "text/csv", []byte("a,b\n")))
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		assert.Contains(t, w.Body.String(), "PNG and JPEG")
+	})
+
+	t.Run("TooLarge_NoSplitHint", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, uploadRequest("logo.png", "image/png", bytes.Repeat([]byte("a"), 2<<20)))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.NotContains(t, w.Body.String(), "hint")
+	})
+}
+
+// fakeFileScanner returns a fixed verdict and records what it was given
+type fakeFileScanner struct {
+	result  *service.ScanResult
+	err     error
+	scanned []byte
+}
+
+func (f *fakeFileScanner) Scan(ctx context.Context, file multipart.File) (*service.ScanResult, error) {
+	f.scanned, _ = io.ReadAll(file)
+	return f.result, f.err
+}
+
+func setupScanUploadsRouter(scanner service.FileScanner) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	router := gin.New()
+	router.POST("/upload", middleware.LimitUpload("UPLOAD_MAX_MB_TEST", 1), middleware.ScanUploads(scanner, logger), func(c *gin.Context) {
+		file, _, err := c.Request.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+			return
+		}
+		defer file.Close()
+		content, _ := io.ReadAll(file)
+		c.JSON(http.StatusOK, gin.H{"content": string(content)})
+	})
+	return router
+}
+
+func TestScanUploads(t *testing.T) {
+	csv := []byte("order_id,total\n1,9.99\n")
+
+	t.Run("Clean", func(t *testing.T) {
+		scanner := &fakeFileScanner{result: &service.ScanResult{Clean: true, Scanner: "fake"}}
+		router := setupScanUploadsRouter(scanner)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, uploadRequest("orders.csv", "text/csv", csv))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, csv, scanner.scanned)
+		assert.Contains(t, w.Body.String(), "order_id,total", "the handler still reads the whole file")
+	})
+
+	t.Run("Infected", func(t *testing.T) {
+		scanner := &fakeFileScanner{result: &service.ScanResult{Clean: false, Signature: "Win.Test", Scanner: "fake"}}
+		router := setupScanUploadsRouter(scanner)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, uploadRequest("orders.csv", "text/csv", csv))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("ScannerUnavailable", func(t *testing.T) {
+		scanner := &fakeFileScanner{err: service.ErrScannerUnavailable}
+		router := setupScanUploadsRouter(scanner)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, uploadRequest("orders.csv", "text/csv", csv))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("BinaryContent", func(t *testing.T) {
+		scanner := &fakeFileScanner{err: errors.New("must not be called")}
+		router := setupScanUploadsRouter(scanner)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, uploadRequest("orders.csv", "text/csv", []byte("PK\x03\x04\x14\x00\x06\x00binary")))
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		assert.Nil(t, scanner.scanned)
+	})
+
+	t.Run("XLSXWorkbook", func(t *testing.T) {
+		workbook, _ := service.RenderTableXLSX("Orders", []string{"order_id"}, [][]string{{"1"}})
+		scanner := &fakeFileScanner{result: &service.ScanResult{Clean: true, Scanner: "fake"}}
+		router := setupScanUploadsRouter(scanner)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, uploadRequest("orders.xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", workbook))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, workbook, scanner.scanned)
+	})
+
+	t.Run("PNGImage", func(t *testing.T) {
+		png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+		scanner := &fakeFileScanner{result: &service.ScanResult{Clean: true, Scanner: "fake"}}
+		logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+		router := gin.New()
+		router.POST("/upload", middleware.LimitImageUpload("UPLOAD_MAX_MB_TEST", 1), middleware.ScanUploads(scanner, logger), func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"message": "uploaded"})
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, uploadRequest("logo.png", "image/png", png))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, png, scanner.scanned)
+
+		// an image whose content is not the one its name says is not scanned
+		scanner.scanned = nil
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, uploadRequest("logo.jpg", "image/jpeg", png))
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		assert.Nil(t, scanner.scanned)
+	})
+
+	t.Run("EICAR_SignatureScanner", func(t *testing.T) {
+		router := setupScanUploadsRouter(&service.SignatureScanner{})
+		eicar := `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, uploadRequest("orders.csv", "text/csv", []byte(eicar)))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+}
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type WaitTimeTestEnv struct {
+	WaitTimeStore *MockWaitTimeStore
+	RulesStore    *MockRulesStore
+	Handler       *api.WaitTimeHandler
+}
+
+func setupWaitTimeEnv() *WaitTimeTestEnv {
+	gin.SetMode(gin.TestMode)
+
+	waitTimeStore := new(MockWaitTimeStore)
+	rulesStore := new(MockRulesStore)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	return &WaitTimeTestEnv{
+		WaitTimeStore: waitTimeStore,
+		RulesStore:    rulesStore,
+		Handler:       api.NewWaitTimeHandler(waitTimeStore, rulesStore, logger),
+	}
+}
+
+func (env *WaitTimeTestEnv) ResetMocks() {
+	env.WaitTimeStore.ExpectedCalls = nil
+	env.WaitTimeStore.Calls = nil
+	env.RulesStore.ExpectedCalls = nil
+	env.RulesStore.Calls = nil
+}
+
+func TestEstimateWaitTime(t *testing.T) {
+	tuning := api.WaitTimeTuning{PrepBufferMinutes: 5, DeliveryMinutes: 25, Factor: 1.0, FallbackMinutes: 20}
+	// 2 cooks making 60 items per hour together, one item per minute
+	capacity := database.KitchenCapacity{StaffOnShift: 2, ItemsPerHour: 60}
+
+	t.Run("QueueAndOrder", func(t *testing.T) {
+		estimate := api.EstimateWaitTime(api.WaitTimeInput{
+			Load:           database.KitchenLoad{OpenOrders: 4, OpenItems: 10, PrepUnits: 12},
+			Capacity:       capacity,
+			OrderPrepUnits: 3,
+			OrderType:      "takeaway",
+			Tuning:         tuning,
+		})
+
+		assert.Equal(t, "kitchen_load", estimate.Basis)
+		assert.Equal(t, 12.0, estimate.QueueMinutes)
+		assert.Equal(t, 3.0, estimate.PrepMinutes)
+		assert.Equal(t, 0, estimate.DeliveryMinutes)
+		assert.Equal(t, 20, estimate.TotalMinutes)
+		assert.Equal(t, 20, estimate.QuoteMinutes)
+	})
+
+	t.Run("SingleItemTakesOneEmployee", func(t *testing.T) {
+		estimate := api.EstimateWaitTime(api.WaitTimeInput{
+			Capacity:       capacity,
+			OrderPrepUnits: 1,
+			OrderType:      "takeaway",
+			Tuning:         tuning,
+		})
+
+		// One cook makes 30 items per hour, so one item takes 2 minutes even with two cooks free
+		assert.Equal(t, 2.0, estimate.PrepMinutes)
+		assert.Equal(t, 7, estimate.TotalMinutes)
+		assert.Equal(t, 10, estimate.QuoteMinutes)
+	})
+
+	t.Run("DeliveryAndFactor", func(t *testing.T) {
+		slow := tuning
+		slow.Factor = 1.5
+		estimate := api.EstimateWaitTime(api.WaitTimeInput{
+			Load:           database.KitchenLoad{PrepUnits: 10},
+			Capacity:       capacity,
+			OrderPrepUnits: 4,
+			OrderType:      "delivery",
+			Tuning:         slow,
+		})
+
+		assert.Equal(t, 15.0, estimate.QueueMinutes)
+		assert.Equal(t, 6.0, estimate.PrepMinutes)
+		assert.Equal(t, 25, estimate.DeliveryMinutes)
+		assert.Equal(t, 51, estimate.TotalMinutes)
+		assert.Equal(t, 55, estimate.QuoteMinutes)
+	})
+
+	t.Run("NobodyOnShiftUsesRules", func(t *testing.T) {
+		estimate := api.EstimateWaitTime(api.WaitTimeInput{
+			Load:           database.KitchenLoad{PrepUnits: 10},
+			OrderPrepUnits: 4,
+			OrderType:      "takeaway",
+			Tuning:         tuning,
+		})
+
+		assert.Equal(t, "rules_fallback", estimate.Basis)
+		assert.Equal(t, 25, estimate.TotalMinutes)
+	})
+
+	t.Run("RecentPrepTimeIsTheFloor", func(t *testing.T) {
+		input := api.WaitTimeInput{
+			Load:              database.KitchenLoad{PrepUnits: 4},
+			Capacity:          capacity,
+			OrderPrepUnits:    2,
+			OrderType:         "takeaway",
+			Tuning:            tuning,
+			RecentPrepMinutes: 18,
+		}
+		estimate := api.EstimateWaitTime(input)
+
+		// The load alone would take 6 minutes, the kitchen has lately needed 18
+		assert.Equal(t, "recent_prep_times", estimate.Basis)
+		assert.Equal(t, 6.0, estimate.QueueMinutes+estimate.PrepMinutes)
+		assert.Equal(t, 23, estimate.TotalMinutes)
+
+		input.RecentPrepMinutes = 3
+		estimate = api.EstimateWaitTime(input)
+		assert.Equal(t, "kitchen_load", estimate.Basis)
+		assert.Equal(t, 11, estimate.TotalMinutes)
+	})
+
+	t.Run("NobodyOnShiftUsesRecentPrepTime", func(t *testing.T) {
+		estimate := api.EstimateWaitTime(api.WaitTimeInput{
+			OrderPrepUnits:    4,
+			OrderType:         "takeaway",
+			Tuning:            tuning,
+			RecentPrepMinutes: 12.5,
+		})
+
+		assert.Equal(t, "recent_prep_times", estimate.Basis)
+		assert.Equal(t, 18, estimate.TotalMinutes)
+	})
+}
+
+func TestGetWaitTimeEstimateHandler(t *testing.T) {
+	env := setupWaitTimeEnv()
+	orgID := uuid.New()
+	employee := &database.User{ID: uuid.New(), OrganizationID: orgID, UserRole: "employee"}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/:org/wait-time/estimate", authMiddleware(employee), env.Handler.GetWaitTimeEstimateHandler)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/"+orgID.String()+"/wait-time/estimate"+query, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	type response struct {
+		Data struct {
+			OrderType string               `json:"order_type"`
+			Estimate  api.WaitTimeEstimate `json:"estimate"`
+		} `json:"data"`
+	}
+
+	rules := &database.OrganizationRules{OrganizationID: orgID, Delivery: true, WaitingTime: 20, PrepBufferMinutes: 5, DeliveryMinutes: 30, WaitTimeFactor: 1.0}
+
+	t.Run("Success", func(t *testing.T) {
+		env.ResetMocks()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(rules, nil)
+		env.WaitTimeStore.On("GetOpenKitchenLoad", orgID, mock.Anything).Return(&database.KitchenLoad{OpenOrders: 2, OpenItems: 6, PrepUnits: 6}, nil)
+		env.WaitTimeStore.On("GetKitchenCapacity", orgID, mock.Anything).Return(&database.KitchenCapacity{StaffOnShift: 2, ItemsPerHour: 60}, nil)
+		env.WaitTimeStore.On("GetOrderProfile", orgID).Return(&database.OrderProfile{AvgItemsPerOrder: 3, AvgPrepUnits: 1}, nil)
+		// Too few orders prepared lately to be trusted
+		env.WaitTimeStore.On("GetRecentPrepTime", orgID, mock.Anything).Return(&database.PrepTime{Prepared: 2, AvgMinutes: 40}, nil)
+
+		w := get("?order_type=delivery")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp response
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "delivery", resp.Data.OrderType)
+		// 6 queued + 3 for an average order + 5 buffer + 30 delivery
+		assert.Equal(t, 44, resp.Data.Estimate.TotalMinutes)
+		assert.Equal(t, 45, resp.Data.Estimate.QuoteMinutes)
+	})
+
+	t.Run("ItemsOverrideAverageOrder", func(t *testing.T) {
+		env.ResetMocks()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(nil, nil)
+		env.WaitTimeStore.On("GetOpenKitchenLoad", orgID, mock.Anything).Return(&database.KitchenLoad{}, nil)
+		env.WaitTimeStore.On("GetKitchenCapacity", orgID, mock.Anything).Return(&database.KitchenCapacity{StaffOnShift: 1, ItemsPerHour: 60}, nil)
+		env.WaitTimeStore.On("GetOrderProfile", orgID).Return(&database.OrderProfile{}, nil)
+		env.WaitTimeStore.On("GetRecentPrepTime", orgID, mock.Anything).Return(&database.PrepTime{}, nil)
+
+		w := get("?items=10")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp response
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, 10.0, resp.Data.Estimate.PrepMinutes)
+		assert.Equal(t, 5, resp.Data.Estimate.BufferMinutes) // default tuning without rules
+	})
+
+	t.Run("RecentPrepTimes", func(t *testing.T) {
+		env.ResetMocks()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(rules, nil)
+		env.WaitTimeStore.On("GetOpenKitchenLoad", orgID, mock.Anything).Return(&database.KitchenLoad{OpenOrders: 1, OpenItems: 2, PrepUnits: 2}, nil)
+		env.WaitTimeStore.On("GetKitchenCapacity", orgID, mock.Anything).Return(&database.KitchenCapacity{StaffOnShift: 2, ItemsPerHour: 60}, nil)
+		env.WaitTimeStore.On("GetOrderProfile", orgID).Return(&database.OrderProfile{AvgItemsPerOrder: 3, AvgPrepUnits: 1}, nil)
+		env.WaitTimeStore.On("GetRecentPrepTime", orgID, mock.Anything).Return(&database.PrepTime{Prepared: 6, AvgMinutes: 22}, nil)
+
+		w := get("")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp response
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "recent_prep_times", resp.Data.Estimate.Basis)
+		assert.Equal(t, 22.0, resp.Data.Estimate.RecentPrepMinutes)
+		assert.Equal(t, 27, resp.Data.Estimate.TotalMinutes)
+	})
+
+	t.Run("NoDelivery", func(t *testing.T) {
+		env.ResetMocks()
+		noDelivery := *rules
+		noDelivery.Delivery = false
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(&noDelivery, nil)
+
+		w := get("?order_type=delivery")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("InvalidParameters", func(t *testing.T) {
+		env.ResetMocks()
+		assert.Equal(t, http.StatusBadRequest, get("?order_type=drone").Code)
+		assert.Equal(t, http.StatusBadRequest, get("?items=0").Code)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		env.ResetMocks()
+		env.RulesStore.On("GetRulesByOrganizationID", orgID).Return(rules, nil)
+		env.WaitTimeStore.On("GetOpenKitchenLoad", orgID, mock.Anything).Return(nil, errors.New("db error"))
+
+		w := get("")
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEstimateWeeklyPay(t *testing.T) {
+	shifts := []database.WeeklyShift{
+		{Shift: database.Shift{ShiftType: database.ShiftWorking}, Hours: 8},
+		{Shift: database.Shift{ShiftType: database.ShiftStandby}, Hours: 4, StandbyPayPercent: 25},
+	}
+
+	// 8 hours at 20 and 4 standby hours at 5
+	assert.Equal(t, 180.0, service.EstimateWeeklyPay(shifts, 20))
+	assert.Equal(t, 0.0, service.EstimateWeeklyPay(nil, 20))
+}
+
+func TestSendWeeklySchedules(t *testing.T) {
+	scheduleStore := new(MockScheduleStore)
+	userStore := new(MockUserStore)
+	emailService := new(MockEmailService)
+	weeklyService := &service.WeeklyScheduleEmailService{
+		ScheduleStore: scheduleStore,
+		UserStore:     userStore,
+		EmailService:  emailService,
+		Logger:        slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		SendHour:      18,
+	}
+	reset := func() {
+		for _, m := range []*mock.Mock{&scheduleStore.Mock, &userStore.Mock, &emailService.Mock} {
+			m.ExpectedCalls = nil
+			m.Calls = nil
+		}
+	}
+
+	// Sunday evening, the week starts on Monday October 19
+	sunday := time.Date(2026, 10, 18, 18, 30, 0, 0, time.UTC)
+	weekStart := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	ada, sam := uuid.New(), uuid.New()
+	shift := func(employeeID uuid.UUID, name string, day int, start, end, shiftType string, hours float64) database.WeeklyShift {
+		return database.WeeklyShift{
+			Shift: database.Shift{
+				ID: uuid.New(), EmployeeID: employeeID, EmployeeName: name, EmployeeEmail: name + "@example.com",
+				Date: weekStart.AddDate(0, 0, day), StartTime: start, EndTime: end, ShiftType: shiftType,
+			},
+			Hours:             hours,
+			StandbyPayPercent: 50,
+		}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		reset()
+		salary := 20.0
+		scheduleStore.On("GetShiftsForWeeklyEmail", weekStart).Return([]database.WeeklyShift{
+			shift(ada, "ada", 0, "09:00:00", "17:00:00", database.ShiftWorking, 8),
+			shift(ada, "ada", 2, "12:00:00", "16:00:00", database.ShiftStandby, 4),
+			shift(sam, "sam", 1, "10:00:00", "14:00:00", database.ShiftWorking, 4),
+		}, nil).Once()
+		userStore.On("GetUserByID", ada).Return(&database.User{ID: ada, SalaryPerHour: &salary}, nil).Once()
+		userStore.On("GetUserByID", sam).Return(&database.User{ID: sam}, nil).Once()
+		pay := 200.0
+		emailService.On("SendWeeklyScheduleEmail", "ada@example.com", "ada", "Oct 19, 2026", []string{
+			"Monday, Oct 19, 09:00 - 17:00",
+			"Wednesday, Oct 21, 12:00 - 16:00 (standby)",
+		}, 12.0, &pay).Return(nil).Once()
+		// without a salary the pay is left out
+		emailService.On("SendWeeklyScheduleEmail", "sam@example.com", "sam", "Oct 19, 2026", []string{
+			"Tuesday, Oct 20, 10:00 - 14:00",
+		}, 4.0, (*float64)(nil)).Return(nil).Once()
+		scheduleStore.On("MarkWeeklyEmailSent", ada, weekStart).Return(nil).Once()
+		scheduleStore.On("MarkWeeklyEmailSent", sam, weekStart).Return(nil).Once()
+
+		sent, err := weeklyService.SendWeeklySchedules(sunday)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, sent)
+		emailService.AssertExpectations(t)
+		scheduleStore.AssertExpectations(t)
+	})
+
+	t.Run("EmailFails_NotMarked", func(t *testing.T) {
+		reset()
+		scheduleStore.On("GetShiftsForWeeklyEmail", weekStart).Return([]database.WeeklyShift{
+			shift(ada, "ada", 0, "09:00:00", "17:00:00", database.ShiftWorking, 8),
+		}, nil).Once()
+		userStore.On("GetUserByID", ada).Return(nil, errors.New("db error")).Once()
+		emailService.On("SendWeeklyScheduleEmail", "ada@example.com", "ada", "Oct 19, 2026", mock.Anything, 8.0, (*float64)(nil)).
+			Return(errors.New("smtp down")).Once()
+
+		sent, err := weeklyService.SendWeeklySchedules(sunday)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		// retried in the next round
+		scheduleStore.AssertNotCalled(t, "MarkWeeklyEmailSent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("NotDue", func(t *testing.T) {
+		reset()
+		for _, now := range []time.Time{
+			time.Date(2026, 10, 18, 17, 59, 0, 0, time.UTC), // Sunday before the send hour
+			time.Date(2026, 10, 19, 18, 30, 0, 0, time.UTC), // Monday
+		} {
+			sent, err := weeklyService.SendWeeklySchedules(now)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, sent)
+		}
+		scheduleStore.AssertNotCalled(t, "GetShiftsForWeeklyEmail", mock.Anything)
+	})
+
+	t.Run("StoreError", func(t *testing.T) {
+		reset()
+		scheduleStore.On("GetShiftsForWeeklyEmail", weekStart).Return(nil, errors.New("db error")).Once()
+
+		_, err := weeklyService.SendWeeklySchedules(sunday)
+
+		assert.Error(t, err)
+	})
+}
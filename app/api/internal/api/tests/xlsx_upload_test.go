@@ -0,0 +1,146 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// uploadedFile stores content in a temporary file, which can be read at any offset like a multipart upload
+func uploadedFile(t *testing.T, content []byte) *os.File {
+	t.Helper()
+	file, err := os.CreateTemp(t.TempDir(), "upload")
+	require.NoError(t, err)
+	_, err = file.Write(content)
+	require.NoError(t, err)
+	_, err = file.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	t.Cleanup(func() { file.Close() })
+	return file
+}
+
+// posWorkbook is a workbook as exported by a POS: shared strings, rich text, a date column, a skipped cell
+// and a blank row, with its first sheet stored as sheet2.xml
+func posWorkbook(t *testing.T) []byte {
+	t.Helper()
+	parts := map[string]string{
+		"[Content_Types].xml": `<?xml version="1.0" encoding="UTF-8"?><Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"/>`,
+		"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Orders" sheetId="1" r:id="rId7"/><sheet name="Summary" sheetId="2" r:id="rId8"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId8" Target="worksheets/sheet1.xml"/><Relationship Id="rId7" Target="/xl/worksheets/sheet2.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>order_id</t></si><si><t>create_time</t></si><si><t>channel</t></si><si><r><t>Deli</t></r><r><t>veroo</t></r></si><si><t>total</t></si></sst>`,
+		"xl/styles.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts><numFmt numFmtId="164" formatCode="yyyy\-mm\-dd hh:mm"/><numFmt numFmtId="165" formatCode="&quot;$&quot;#,##0.00"/></numFmts>
+<cellXfs><xf numFmtId="0"/><xf numFmtId="164"/><xf numFmtId="165"/><xf numFmtId="14"/></cellXfs></styleSheet>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="inlineStr"><is><t>summary</t></is></c></row></sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c><c r="D1" t="s"><v>4</v></c></row>
+<row r="2"><c r="A2"><v>1001</v></c><c r="B2" s="1"><v>46314.5</v></c><c r="C2" t="s"><v>3</v></c><c r="D2" s="2"><v>24.5</v></c></row>
+<row r="3"><c r="A3" s="0"/></row>
+<row r="5"><c r="A5"><v>1002</v></c><c r="B5" s="3"><v>46315</v></c><c r="D5"><v>8</v></c></row>
+</sheetData></worksheet>`,
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range parts {
+		part, err := archive.Create(name)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, archive.Close())
+	return buf.Bytes()
+}
+
+func TestUploadServiceXLSX(t *testing.T) {
+	uploads := &service.CSVUploadService{Logger: slog.New(slog.NewTextHandler(os.Stdout, nil)), MaxRows: 100, MaxStreamRows: 100}
+
+	t.Run("ParseWorkbook", func(t *testing.T) {
+		data, err := uploads.ParseCSV(uploadedFile(t, posWorkbook(t)))
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"order_id", "create_time", "channel", "total"}, data.Headers)
+		assert.Equal(t, []map[string]string{
+			{"order_id": "1001", "create_time": "2026-10-19 12:00:00", "channel": "Deliveroo", "total": "24.5"},
+			{"order_id": "1002", "create_time": "2026-10-20", "channel": "", "total": "8"},
+		}, data.Rows)
+		assert.Equal(t, 2, data.Total)
+	})
+
+	t.Run("ParseRenderedWorkbook", func(t *testing.T) {
+		workbook, err := service.RenderTableXLSX("Items", []string{"name", "price"}, [][]string{{"Falafel wrap", "7.50"}})
+		require.NoError(t, err)
+
+		data, err := uploads.ParseCSV(uploadedFile(t, workbook))
+
+		require.NoError(t, err)
+		assert.Equal(t, []map[string]string{{"name": "Falafel wrap", "price": "7.50"}}, data.Rows)
+	})
+
+	t.Run("StreamWorkbook", func(t *testing.T) {
+		stream, err := uploads.StreamCSV(uploadedFile(t, posWorkbook(t)))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"order_id", "create_time", "channel", "total"}, stream.Headers)
+
+		var ids []string
+		for {
+			row, err := stream.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			ids = append(ids, row["order_id"])
+		}
+		assert.Equal(t, []string{"1001", "1002"}, ids)
+	})
+
+	t.Run("TooManyRows", func(t *testing.T) {
+		rows := make([][]string, 3)
+		for i := range rows {
+			rows[i] = []string{"row"}
+		}
+		workbook, err := service.RenderTableXLSX("Items", []string{"name"}, rows)
+		require.NoError(t, err)
+
+		limited := &service.CSVUploadService{Logger: uploads.Logger, MaxRows: 2}
+		_, err = limited.ParseCSV(uploadedFile(t, workbook))
+
+		assert.ErrorIs(t, err, service.ErrTooManyRows)
+	})
+
+	t.Run("CorruptWorkbook", func(t *testing.T) {
+		_, err := uploads.ParseCSV(uploadedFile(t, []byte("PK\x03\x04 not really a zip archive")))
+
+		assert.ErrorIs(t, err, service.ErrInvalidFormat)
+	})
+
+	t.Run("CSVUnchanged", func(t *testing.T) {
+		data, err := uploads.ParseCSV(uploadedFile(t, []byte("order_id, total\n1, 9.99\n")))
+
+		require.NoError(t, err)
+		assert.Equal(t, []map[string]string{{"order_id": "1", "total": "9.99"}}, data.Rows)
+	})
+
+	t.Run("StreamCSV", func(t *testing.T) {
+		stream, err := uploads.StreamCSV(strings.NewReader("order_id\n1\n"))
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"order_id"}, stream.Headers)
+	})
+}
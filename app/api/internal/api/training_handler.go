@@ -0,0 +1,166 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TrainingHandler manages the training periods of new hires. Employees in training are scheduled on top of
+// the staff the demand needs, never in solo coverage roles, and graduate by themselves when it ends.
+type TrainingHandler struct {
+	UserStore     database.UserStore
+	TrainingStore database.TrainingStore
+	Logger        *slog.Logger
+}
+
+func NewTrainingHandler(userStore database.UserStore, trainingStore database.TrainingStore, logger *slog.Logger) *TrainingHandler {
+	return &TrainingHandler{
+		UserStore:     userStore,
+		TrainingStore: trainingStore,
+		Logger:        logger,
+	}
+}
+
+type StartTrainingRequest struct {
+	Days int `json:"days" binding:"required,min=1,max=365"`
+}
+
+// NewTraining is the training period of the employee, from the day of now for the given days
+func NewTraining(employee *database.User, days int, startedBy uuid.UUID, now time.Time) *database.EmployeeTraining {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return &database.EmployeeTraining{
+		UserID:         employee.ID,
+		OrganizationID: employee.OrganizationID,
+		FullName:       employee.FullName,
+		StartsOn:       today,
+		EndsOn:         today.AddDate(0, 0, days),
+		StartedBy:      &startedBy,
+	}
+}
+
+// GetTrainingsHandler lists the employees in training, the first to graduate first
+func (th *TrainingHandler) GetTrainingsHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can view employee trainings"})
+		return
+	}
+
+	trainings, err := th.TrainingStore.GetTrainings(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve employee trainings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Employee trainings retrieved successfully", "data": trainings})
+}
+
+// StartTrainingHandler puts the employee in training for the given days from today, restarting the
+// period of an employee already in training
+func (th *TrainingHandler) StartTrainingHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can manage employee trainings"})
+		return
+	}
+
+	employeeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid employee ID"})
+		return
+	}
+	employee, err := th.UserStore.GetUserByID(employeeID)
+	if err != nil || employee == nil || employee.OrganizationID != user.OrganizationID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		return
+	}
+	if employee.UserRole == "admin" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Admins are not scheduled and cannot be in training"})
+		return
+	}
+
+	var req StartTrainingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	training := NewTraining(employee, req.Days, user.ID, time.Now())
+	if err := th.TrainingStore.StartTraining(training); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start employee training"})
+		return
+	}
+
+	th.Logger.Info("employee training started", "employee_id", employee.ID, "ends_on", training.EndsOn.Format(time.DateOnly))
+	c.JSON(http.StatusOK, gin.H{"message": "Employee training started successfully", "data": training})
+}
+
+// GraduateTrainingHandler ends the training of the employee early
+func (th *TrainingHandler) GraduateTrainingHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	if user.UserRole != "admin" && user.UserRole != "manager" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and managers can manage employee trainings"})
+		return
+	}
+
+	employeeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid employee ID"})
+		return
+	}
+
+	if err := th.TrainingStore.Graduate(user.OrganizationID, employeeID, time.Now()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Employee is not in training"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to graduate employee"})
+		return
+	}
+
+	th.Logger.Info("employee graduated early", "employee_id", employeeID, "by", user.ID)
+	c.JSON(http.StatusOK, gin.H{"message": "Employee graduated successfully"})
+}
+
+// withTrainees marks, in every schedule entry, the employees still in training on its date
+func (sh *ScheduleHandler) withTrainees(orgID uuid.UUID, schedules []database.Schedule) []database.Schedule {
+	if sh.TrainingStore == nil {
+		return schedules
+	}
+	trainings, err := sh.TrainingStore.GetTrainings(orgID)
+	if err != nil {
+		// the schedule is still useful without the highlight
+		sh.Logger.Error("failed to get employee trainings for schedule", "error", err, "org_id", orgID)
+		return schedules
+	}
+
+	for i, schedule := range schedules {
+		for _, training := range trainings {
+			if training.InTrainingOn(schedule.Date) && slices.Contains(schedule.Employees, training.UserID.String()) {
+				schedules[i].InTraining = append(schedules[i].InTraining, training.UserID.String())
+			}
+		}
+	}
+	return schedules
+}
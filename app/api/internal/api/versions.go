@@ -0,0 +1,8 @@
+package api
+
+import "github.com/clockwise/clockwise/backend/internal/middleware"
+
+// V1Shims keep the v1 shape of the responses changed in v2, keyed by method and route such as
+// "GET /:org/branding". Handlers write the v2 shape and add a shim here when it breaks v1 clients.
+// The unversioned /api routes use them too.
+var V1Shims = map[string]middleware.ResponseShim{}
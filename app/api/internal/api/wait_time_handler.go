@@ -0,0 +1,237 @@
+package api
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// Tuning used until an organization saves its rules
+	defaultPrepBufferMinutes = 5
+	defaultDeliveryMinutes   = 25
+	defaultWaitTimeFactor    = 1.0
+	defaultWaitingTime       = 15
+
+	// Incomplete orders older than this are treated as stale data rather than queued work
+	openOrderWindow = 4 * time.Hour
+
+	// Orders prepared this recently tell how fast the kitchen is going right now, once there are enough of them
+	recentPrepWindow    = time.Hour
+	minRecentPrepOrders = 3
+
+	// Quotes are rounded up to this many minutes
+	waitTimeQuoteStep = 5
+
+	waitTimeBasisKitchen = "kitchen_load"
+	waitTimeBasisRecent  = "recent_prep_times"
+	waitTimeBasisRules   = "rules_fallback"
+)
+
+type WaitTimeHandler struct {
+	WaitTimeStore database.WaitTimeStore
+	RulesStore    database.RulesStore
+	Logger        *slog.Logger
+}
+
+func NewWaitTimeHandler(waitTimeStore database.WaitTimeStore, rulesStore database.RulesStore, logger *slog.Logger) *WaitTimeHandler {
+	return &WaitTimeHandler{
+		WaitTimeStore: waitTimeStore,
+		RulesStore:    rulesStore,
+		Logger:        logger,
+	}
+}
+
+// WaitTimeTuning is the per organization tuning of the estimate, stored with the rules
+type WaitTimeTuning struct {
+	PrepBufferMinutes int     `json:"prep_buffer_minutes"`
+	DeliveryMinutes   int     `json:"delivery_minutes"`
+	Factor            float64 `json:"wait_time_factor"`
+	// FallbackMinutes is quoted for the kitchen when nobody who prepares items is on shift
+	FallbackMinutes int `json:"waiting_time"`
+}
+
+// WaitTimeInput is what the estimate is computed from
+type WaitTimeInput struct {
+	Load           database.KitchenLoad
+	Capacity       database.KitchenCapacity
+	OrderPrepUnits float64
+	OrderType      string
+	Tuning         WaitTimeTuning
+	// RecentPrepMinutes is the measured prep time of the orders prepared lately, 0 when unknown
+	RecentPrepMinutes float64
+}
+
+// WaitTimeEstimate is the time until an order placed now is ready, or delivered for deliveries
+type WaitTimeEstimate struct {
+	Basis             string  `json:"basis"`
+	QueueMinutes      float64 `json:"queue_minutes"`
+	PrepMinutes       float64 `json:"prep_minutes"`
+	RecentPrepMinutes float64 `json:"recent_prep_minutes,omitempty"`
+	BufferMinutes     int     `json:"buffer_minutes"`
+	DeliveryMinutes   int     `json:"delivery_minutes"`
+	TotalMinutes      int     `json:"total_minutes"`
+	QuoteMinutes      int     `json:"quote_minutes"`
+}
+
+// EstimateWaitTime drains the queued prep units at the speed of the staff on shift, then prepares the new
+// order, which cannot take less than one employee needs for one item. The kitchen time is scaled by the
+// organization's factor and is never quoted below the measured recent prep time, then the buffer and, for
+// deliveries, the delivery time are added.
+func EstimateWaitTime(input WaitTimeInput) WaitTimeEstimate {
+	tuning := input.Tuning
+	if tuning.Factor <= 0 {
+		tuning.Factor = defaultWaitTimeFactor
+	}
+
+	estimate := WaitTimeEstimate{BufferMinutes: tuning.PrepBufferMinutes, RecentPrepMinutes: input.RecentPrepMinutes}
+	var kitchen float64
+	if input.Capacity.ItemsPerHour <= 0 || input.Capacity.StaffOnShift == 0 {
+		estimate.Basis = waitTimeBasisRules
+		kitchen = float64(tuning.FallbackMinutes)
+		if input.RecentPrepMinutes > 0 {
+			estimate.Basis = waitTimeBasisRecent
+			kitchen = input.RecentPrepMinutes
+		}
+	} else {
+		estimate.Basis = waitTimeBasisKitchen
+		perMinute := input.Capacity.ItemsPerHour / 60
+		estimate.QueueMinutes = roundMinutes(input.Load.PrepUnits / perMinute * tuning.Factor)
+
+		singleItem := float64(input.Capacity.StaffOnShift) / perMinute
+		estimate.PrepMinutes = roundMinutes(math.Max(input.OrderPrepUnits/perMinute, singleItem) * tuning.Factor)
+		kitchen = estimate.QueueMinutes + estimate.PrepMinutes
+		if input.RecentPrepMinutes > kitchen {
+			estimate.Basis = waitTimeBasisRecent
+			kitchen = input.RecentPrepMinutes
+		}
+	}
+
+	if input.OrderType == "delivery" {
+		estimate.DeliveryMinutes = tuning.DeliveryMinutes
+	}
+	estimate.TotalMinutes = int(math.Ceil(kitchen)) + estimate.BufferMinutes + estimate.DeliveryMinutes
+	estimate.QuoteMinutes = (estimate.TotalMinutes + waitTimeQuoteStep - 1) / waitTimeQuoteStep * waitTimeQuoteStep
+	return estimate
+}
+
+// recentPrepMinutes is the recent prep time, 0 until enough orders were prepared to trust it
+func recentPrepMinutes(recent *database.PrepTime) float64 {
+	if recent == nil || recent.Prepared < minRecentPrepOrders {
+		return 0
+	}
+	return recent.AvgMinutes
+}
+
+func roundMinutes(minutes float64) float64 {
+	return math.Round(minutes*10) / 10
+}
+
+// waitTimeTuning reads the tuning from the rules, or the defaults when none are saved
+func waitTimeTuning(rules *database.OrganizationRules) WaitTimeTuning {
+	if rules == nil {
+		return WaitTimeTuning{
+			PrepBufferMinutes: defaultPrepBufferMinutes,
+			DeliveryMinutes:   defaultDeliveryMinutes,
+			Factor:            defaultWaitTimeFactor,
+			FallbackMinutes:   defaultWaitingTime,
+		}
+	}
+	return WaitTimeTuning{
+		PrepBufferMinutes: rules.PrepBufferMinutes,
+		DeliveryMinutes:   rules.DeliveryMinutes,
+		Factor:            rules.WaitTimeFactor,
+		FallbackMinutes:   rules.WaitingTime,
+	}
+}
+
+// GetWaitTimeEstimateHandler estimates the wait for an order placed now so phone staff can quote
+// pickup and delivery times. order_type defaults to takeaway and items to the size of an average order.
+func (wh *WaitTimeHandler) GetWaitTimeEstimateHandler(c *gin.Context) {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return
+	}
+
+	orderType := c.DefaultQuery("order_type", "takeaway")
+	if orderType != "takeaway" && orderType != "delivery" && orderType != "dine in" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order_type, expected takeaway, delivery or dine in"})
+		return
+	}
+
+	items := 0
+	if value := c.Query("items"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 500 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid items, expected a number between 1 and 500"})
+			return
+		}
+		items = parsed
+	}
+
+	rules, err := wh.RulesStore.GetRulesByOrganizationID(user.OrganizationID)
+	if err != nil {
+		wh.Logger.Error("failed to get rules", "error", err, "org_id", user.OrganizationID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to estimate wait time"})
+		return
+	}
+	if rules != nil && orderType == "delivery" && !rules.Delivery {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The organization does not deliver"})
+		return
+	}
+
+	now := time.Now()
+	load, err := wh.WaitTimeStore.GetOpenKitchenLoad(user.OrganizationID, now.Add(-openOrderWindow))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to estimate wait time"})
+		return
+	}
+	capacity, err := wh.WaitTimeStore.GetKitchenCapacity(user.OrganizationID, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to estimate wait time"})
+		return
+	}
+	profile, err := wh.WaitTimeStore.GetOrderProfile(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to estimate wait time"})
+		return
+	}
+	recent, err := wh.WaitTimeStore.GetRecentPrepTime(user.OrganizationID, now.Add(-recentPrepWindow))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to estimate wait time"})
+		return
+	}
+
+	// Without history an item counts as one prep unit and an order as one item
+	unitsPerItem := math.Max(profile.AvgPrepUnits, 1)
+	orderItems := float64(items)
+	if items == 0 {
+		orderItems = math.Max(profile.AvgItemsPerOrder, 1)
+	}
+
+	estimate := EstimateWaitTime(WaitTimeInput{
+		Load:              *load,
+		Capacity:          *capacity,
+		OrderPrepUnits:    orderItems * unitsPerItem,
+		OrderType:         orderType,
+		Tuning:            waitTimeTuning(rules),
+		RecentPrepMinutes: recentPrepMinutes(recent),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Wait time estimated successfully",
+		"data": gin.H{
+			"order_type": orderType,
+			"estimate":   estimate,
+			"ready_at":   now.Add(time.Duration(estimate.QuoteMinutes) * time.Minute),
+			"load":       load,
+			"capacity":   capacity,
+		},
+	})
+}
@@ -0,0 +1,311 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+	"github.com/clockwise/clockwise/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	maxWebhooksPerOrganization = 10
+	defaultWebhookDeliveries   = 50
+	maxWebhookDeliveries       = 200
+)
+
+type WebhookHandler struct {
+	WebhookStore database.WebhookStore
+	Logger       *slog.Logger
+}
+
+func NewWebhookHandler(webhookStore database.WebhookStore, logger *slog.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		WebhookStore: webhookStore,
+		Logger:       logger,
+	}
+}
+
+// WebhookRequest creates or replaces a webhook. RotateSecret replaces the signing secret of an existing
+// webhook, which is then returned once like the secret of a new webhook.
+type WebhookRequest struct {
+	URL          string   `json:"url" binding:"required,max=2000"`
+	Events       []string `json:"events" binding:"required,min=1"`
+	Enabled      *bool    `json:"enabled"`
+	RotateSecret bool     `json:"rotate_secret"`
+}
+
+// WebhookWithSecret is a webhook with its signing secret, only sent when the secret is created
+type WebhookWithSecret struct {
+	database.Webhook
+	Secret string `json:"secret"`
+}
+
+// ValidateWebhookURL accepts https URLs whose host is a name or a public address
+func ValidateWebhookURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return errors.New("url must be an absolute URL")
+	}
+	if parsed.Scheme != "https" {
+		return errors.New("url must use https")
+	}
+	if parsed.User != nil {
+		return errors.New("url must not contain credentials")
+	}
+	host := parsed.Hostname()
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errors.New("url must not point to a private address")
+	}
+	if ip := net.ParseIP(host); ip != nil && (ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()) {
+		return errors.New("url must not point to a private address")
+	}
+	return nil
+}
+
+// webhookEvents checks the events of a request, dropping duplicates
+func webhookEvents(events []string) ([]string, error) {
+	valid := []string{}
+	for _, event := range events {
+		if !slices.Contains(database.WebhookEvents, event) {
+			return nil, errors.New("unknown event " + event + ", expected one of " + strings.Join(database.WebhookEvents, ", "))
+		}
+		if !slices.Contains(valid, event) {
+			valid = append(valid, event)
+		}
+	}
+	return valid, nil
+}
+
+func newWebhookSecret() (string, error) {
+	secret, err := utils.GenerateRandomPassword(32)
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + secret, nil
+}
+
+// adminOnly lets admins through, webhooks send the schedule and requests of the organization outside
+func (wh *WebhookHandler) adminOnly(c *gin.Context) *database.User {
+	user := middleware.ValidateOrgAccess(c)
+	if user == nil {
+		return nil
+	}
+	if user.UserRole != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can manage webhooks"})
+		return nil
+	}
+	return user
+}
+
+// bindWebhook reads and checks a webhook request, writing a 400 response when it is invalid
+func bindWebhook(c *gin.Context) (*WebhookRequest, []string, bool) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+	req.URL = strings.TrimSpace(req.URL)
+	if err := ValidateWebhookURL(req.URL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+	events, err := webhookEvents(req.Events)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+	return &req, events, true
+}
+
+// GetWebhooksHandler lists the webhooks of the organization and the events they can subscribe to
+func (wh *WebhookHandler) GetWebhooksHandler(c *gin.Context) {
+	user := wh.adminOnly(c)
+	if user == nil {
+		return
+	}
+
+	webhooks, err := wh.WebhookStore.GetWebhooks(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Webhooks retrieved successfully",
+		"data":    gin.H{"webhooks": webhooks, "events": database.WebhookEvents},
+	})
+}
+
+// CreateWebhookHandler adds a webhook and returns its signing secret, which is not shown again
+func (wh *WebhookHandler) CreateWebhookHandler(c *gin.Context) {
+	user := wh.adminOnly(c)
+	if user == nil {
+		return
+	}
+
+	req, events, ok := bindWebhook(c)
+	if !ok {
+		return
+	}
+
+	existing, err := wh.WebhookStore.GetWebhooks(user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+	if len(existing) >= maxWebhooksPerOrganization {
+		c.JSON(http.StatusConflict, gin.H{"error": "An organization can have at most " + strconv.Itoa(maxWebhooksPerOrganization) + " webhooks"})
+		return
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		wh.Logger.Error("failed to generate webhook secret", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+	webhook := &database.Webhook{URL: req.URL, Secret: secret, Events: events, Enabled: req.Enabled == nil || *req.Enabled, CreatedBy: &user.ID}
+	if err := wh.WebhookStore.CreateWebhook(user.OrganizationID, webhook); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Webhook created successfully. Store the secret now, it will not be shown again",
+		"data":    WebhookWithSecret{Webhook: *webhook, Secret: secret},
+	})
+}
+
+// webhook returns the webhook of the :id parameter, writing a 400 or 404 response when there is none
+func (wh *WebhookHandler) webhook(c *gin.Context, orgID uuid.UUID) *database.Webhook {
+	webhookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return nil
+	}
+	webhook, err := wh.WebhookStore.GetWebhook(orgID, webhookID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return nil
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhook"})
+		return nil
+	}
+	return webhook
+}
+
+// UpdateWebhookHandler replaces the URL, events and state of a webhook, and its secret on request
+func (wh *WebhookHandler) UpdateWebhookHandler(c *gin.Context) {
+	user := wh.adminOnly(c)
+	if user == nil {
+		return
+	}
+
+	webhook := wh.webhook(c, user.OrganizationID)
+	if webhook == nil {
+		return
+	}
+	req, events, ok := bindWebhook(c)
+	if !ok {
+		return
+	}
+
+	webhook.URL, webhook.Events = req.URL, events
+	if req.Enabled != nil {
+		webhook.Enabled = *req.Enabled
+	}
+	if req.RotateSecret {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			wh.Logger.Error("failed to generate webhook secret", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+			return
+		}
+		webhook.Secret = secret
+	}
+	if err := wh.WebhookStore.UpdateWebhook(user.OrganizationID, webhook); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+		return
+	}
+
+	if req.RotateSecret {
+		wh.Logger.Info("webhook secret rotated", "org_id", user.OrganizationID, "webhook_id", webhook.ID, "by", user.ID)
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Webhook updated successfully. Store the new secret now, it will not be shown again",
+			"data":    WebhookWithSecret{Webhook: *webhook, Secret: webhook.Secret},
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook updated successfully", "data": webhook})
+}
+
+// DeleteWebhookHandler removes a webhook and its deliveries
+func (wh *WebhookHandler) DeleteWebhookHandler(c *gin.Context) {
+	user := wh.adminOnly(c)
+	if user == nil {
+		return
+	}
+
+	webhookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+	if err := wh.WebhookStore.DeleteWebhook(user.OrganizationID, webhookID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// GetWebhookDeliveriesHandler lists the latest deliveries of a webhook with their attempts and errors,
+// ?limit= of them (50 by default, at most 200)
+func (wh *WebhookHandler) GetWebhookDeliveriesHandler(c *gin.Context) {
+	user := wh.adminOnly(c)
+	if user == nil {
+		return
+	}
+
+	limit := defaultWebhookDeliveries
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxWebhookDeliveries {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxWebhookDeliveries)})
+			return
+		}
+		limit = parsed
+	}
+
+	webhook := wh.webhook(c, user.OrganizationID)
+	if webhook == nil {
+		return
+	}
+	deliveries, err := wh.WebhookStore.GetDeliveries(user.OrganizationID, webhook.ID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhook deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deliveries retrieved successfully", "data": deliveries})
+}
@@ -11,8 +11,16 @@ import (
 const (
 	// Campaign insights (aggregations) cache TTL
 	CampaignInsightsCacheTTL = 5 * time.Minute
+
+	// Campaigns with their items, invalidated by every campaign and item write
+	CampaignListCacheTTL = 10 * time.Minute
 )
 
+// campaignListKey caches all the campaigns of an organization with their items
+func campaignListKey(org_id uuid.UUID) string {
+	return fmt.Sprintf("org:%s:campaigns", org_id)
+}
+
 type CachedCampaignStore struct {
 	store database.CampaignStore
 	cache *CacheService
@@ -32,8 +40,12 @@ func (ccs *CachedCampaignStore) StoreCampaign(org_id uuid.UUID, campaign databas
 		return err
 	}
 
-	// Invalidate computed insights
-	_ = ccs.cache.Delete(fmt.Sprintf("org:%s:campaign_insights", org_id))
+	// Invalidate the list and computed insights
+	_ = ccs.cache.Delete(
+		campaignListKey(org_id),
+		fmt.Sprintf("org:%s:campaign_insights", org_id),
+		fmt.Sprintf("org:%s:campaign_channels", org_id),
+	)
 
 	return nil
 }
@@ -45,15 +57,30 @@ func (ccs *CachedCampaignStore) StoreCampaignItems(org_id, campaign_id uuid.UUID
 		return err
 	}
 
-	// Invalidate computed insights
-	_ = ccs.cache.Delete(fmt.Sprintf("org:%s:campaign_insights", org_id))
+	// Invalidate the list and computed insights
+	_ = ccs.cache.Delete(campaignListKey(org_id), fmt.Sprintf("org:%s:campaign_insights", org_id))
 
 	return nil
 }
 
-// GetAllCampaigns is a list operation - DON'T CACHE
+// GetAllCampaigns is the full list, loaded with one query per campaign - CACHE IT
+// Cache key: org:{uuid}:campaigns
 func (ccs *CachedCampaignStore) GetAllCampaigns(org_id uuid.UUID) ([]database.Campaign, error) {
-	return ccs.store.GetAllCampaigns(org_id)
+	key := campaignListKey(org_id)
+
+	var campaigns []database.Campaign
+	if err := ccs.cache.Get(key, &campaigns); err == nil {
+		return campaigns, nil
+	}
+
+	campaigns, err := ccs.store.GetAllCampaigns(org_id)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = ccs.cache.Set(key, campaigns, CampaignListCacheTTL)
+
+	return campaigns, nil
 }
 
 // GetAllCampaignsFromLastWeek is a filtered list operation - DON'T CACHE
@@ -85,3 +112,40 @@ func (ccs *CachedCampaignStore) GetCampaignInsights(org_id uuid.UUID) ([]databas
 
 	return insights, nil
 }
+
+// RecordRedemption invalidates the channel breakdown
+func (ccs *CachedCampaignStore) RecordRedemption(org_id uuid.UUID, code string, order_id uuid.UUID, at time.Time) error {
+	err := ccs.store.RecordRedemption(org_id, code, order_id, at)
+	if err != nil {
+		return err
+	}
+
+	_ = ccs.cache.Delete(fmt.Sprintf("org:%s:campaign_channels", org_id))
+
+	return nil
+}
+
+// GetChannelPerformance is aggregated data - CACHE IT
+// Cache key: org:{uuid}:campaign_channels
+func (ccs *CachedCampaignStore) GetChannelPerformance(org_id uuid.UUID) ([]database.ChannelPerformance, error) {
+	key := fmt.Sprintf("org:%s:campaign_channels", org_id)
+
+	var channels []database.ChannelPerformance
+	if err := ccs.cache.Get(key, &channels); err == nil {
+		return channels, nil
+	}
+
+	channels, err := ccs.store.GetChannelPerformance(org_id)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = ccs.cache.Set(key, channels, CampaignInsightsCacheTTL)
+
+	return channels, nil
+}
+
+// GetCampaignPerformance feeds the ROI feedback of a single campaign - DON'T CACHE
+func (ccs *CachedCampaignStore) GetCampaignPerformance(org_id, campaign_id uuid.UUID) (*database.CampaignPerformance, error) {
+	return ccs.store.GetCampaignPerformance(org_id, campaign_id)
+}
@@ -84,3 +84,17 @@ func (cis *CachedInsightStore) GetInsightsForEmployee(org_id, employee_id uuid.U
 	_ = cis.cache.Set(key, insights, InsightCacheTTL)
 	return insights, nil
 }
+
+// Drill-downs are filtered by period and read when a card is opened, so they are not cached
+
+func (cis *CachedInsightStore) GetHourlyOrders(org_id uuid.UUID, from, to *time.Time) ([]database.HourlyOrders, error) {
+	return cis.store.GetHourlyOrders(org_id, from, to)
+}
+
+func (cis *CachedInsightStore) GetTopItems(org_id uuid.UUID, from, to *time.Time, limit int) ([]database.TopItem, error) {
+	return cis.store.GetTopItems(org_id, from, to, limit)
+}
+
+func (cis *CachedInsightStore) GetDeliveryOrdersToday(org_id uuid.UUID) ([]database.DeliveryOrder, error) {
+	return cis.store.GetDeliveryOrdersToday(org_id)
+}
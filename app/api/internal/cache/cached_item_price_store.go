@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// CachedItemPriceStore keeps the cached items of the order store fresh when prices change. Nothing of
+// its own is cached.
+type CachedItemPriceStore struct {
+	store database.ItemPriceStore
+	cache *CacheService
+}
+
+func NewCachedItemPriceStore(store database.ItemPriceStore, cache *CacheService) database.ItemPriceStore {
+	return &CachedItemPriceStore{
+		store: store,
+		cache: cache,
+	}
+}
+
+// ApplyPriceChanges invalidates the items, items insights and the campaigns listing the items
+func (cps *CachedItemPriceStore) ApplyPriceChanges(org_id uuid.UUID, changes []database.ItemPriceChange) error {
+	err := cps.store.ApplyPriceChanges(org_id, changes)
+	if err != nil {
+		return err
+	}
+
+	_ = cps.cache.Delete(itemListKey(org_id), campaignListKey(org_id), fmt.Sprintf("org:%s:insights:items", org_id))
+	return nil
+}
+
+// GetPriceHistory is an audit trail - DON'T CACHE
+func (cps *CachedItemPriceStore) GetPriceHistory(org_id, item_id uuid.UUID) ([]database.ItemPriceChange, error) {
+	return cps.store.GetPriceHistory(org_id, item_id)
+}
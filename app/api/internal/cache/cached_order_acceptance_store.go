@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// CachedOrderAcceptanceStore keeps the cached rules in step with the auto-close controller, which changes
+// accepting_orders behind the rules store
+type CachedOrderAcceptanceStore struct {
+	store database.OrderAcceptanceStore
+	cache *CacheService
+}
+
+func NewCachedOrderAcceptanceStore(store database.OrderAcceptanceStore, cache *CacheService) database.OrderAcceptanceStore {
+	return &CachedOrderAcceptanceStore{
+		store: store,
+		cache: cache,
+	}
+}
+
+// GetAutoCloseOrganizations bypasses cache, the controller needs the current state
+func (cas *CachedOrderAcceptanceStore) GetAutoCloseOrganizations() ([]database.OrderAcceptanceSettings, error) {
+	return cas.store.GetAutoCloseOrganizations()
+}
+
+// GetOrderLoad bypasses cache, the load of the hour changes with every order
+func (cas *CachedOrderAcceptanceStore) GetOrderLoad(org_id uuid.UUID, at time.Time) (*database.OrderLoad, error) {
+	return cas.store.GetOrderLoad(org_id, at)
+}
+
+// SetAcceptingOrders updates DB and invalidates the rules cache
+func (cas *CachedOrderAcceptanceStore) SetAcceptingOrders(change *database.OrderAcceptanceChange) error {
+	err := cas.store.SetAcceptingOrders(change)
+	if err != nil {
+		return err
+	}
+
+	_ = cas.cache.Delete(fmt.Sprintf("org:%s:rules", change.OrganizationID))
+	return nil
+}
+
+// ListChanges bypasses cache
+func (cas *CachedOrderAcceptanceStore) ListChanges(org_id uuid.UUID, from, to time.Time) ([]database.OrderAcceptanceChange, error) {
+	return cas.store.ListChanges(org_id, from, to)
+}
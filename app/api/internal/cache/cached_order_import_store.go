@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// CachedOrderImportStore keeps the cached orders of the order store fresh when an import is promoted.
+// The staging area is not cached, it changes with every step of an import.
+type CachedOrderImportStore struct {
+	store database.OrderImportStore
+	cache *CacheService
+}
+
+func NewCachedOrderImportStore(store database.OrderImportStore, cache *CacheService) database.OrderImportStore {
+	return &CachedOrderImportStore{
+		store: store,
+		cache: cache,
+	}
+}
+
+func (cis *CachedOrderImportStore) CreateOrderImport(orderImport *database.OrderImport) error {
+	return cis.store.CreateOrderImport(orderImport)
+}
+
+func (cis *CachedOrderImportStore) StageOrderImportRows(import_id uuid.UUID, rows []database.OrderImportRow) error {
+	return cis.store.StageOrderImportRows(import_id, rows)
+}
+
+func (cis *CachedOrderImportStore) ValidateOrderImport(org_id uuid.UUID, import_id uuid.UUID) (*database.OrderImport, error) {
+	return cis.store.ValidateOrderImport(org_id, import_id)
+}
+
+func (cis *CachedOrderImportStore) GetOrderImports(org_id uuid.UUID) ([]database.OrderImport, error) {
+	return cis.store.GetOrderImports(org_id)
+}
+
+func (cis *CachedOrderImportStore) GetOrderImport(org_id uuid.UUID, import_id uuid.UUID) (*database.OrderImport, error) {
+	return cis.store.GetOrderImport(org_id, import_id)
+}
+
+func (cis *CachedOrderImportStore) GetOrderImportProblems(import_id uuid.UUID, limit int) ([]database.OrderImportRow, error) {
+	return cis.store.GetOrderImportProblems(import_id, limit)
+}
+
+// PromoteOrderImport invalidates the orders, their insights and the campaign channels the promoted
+// orders redeemed
+func (cis *CachedOrderImportStore) PromoteOrderImport(org_id uuid.UUID, import_id uuid.UUID) (*database.OrderImport, error) {
+	orderImport, err := cis.store.PromoteOrderImport(org_id, import_id)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = cis.cache.Delete(
+		orderListKey(org_id),
+		fmt.Sprintf("org:%s:insights:orders", org_id),
+		fmt.Sprintf("org:%s:insights:items", org_id),
+		fmt.Sprintf("org:%s:insights:deliveries", org_id),
+		fmt.Sprintf("org:%s:campaign_channels", org_id),
+	)
+	return orderImport, nil
+}
+
+func (cis *CachedOrderImportStore) DiscardOrderImport(org_id uuid.UUID, import_id uuid.UUID) error {
+	return cis.store.DiscardOrderImport(org_id, import_id)
+}
@@ -12,8 +12,24 @@ const (
 	// Aggregated insights (counts, sums, busiest days)
 	// Cache for short duration to keep dashboards relatively fresh
 	OrderInsightsCacheTTL = 2 * time.Minute
+
+	// Full order and item lists, read by every upload and several insights endpoints. Every write
+	// through the stores invalidates them, the TTL only bounds what other writers may leave stale.
+	OrderListCacheTTL = 5 * time.Minute
+	ItemListCacheTTL  = 15 * time.Minute
 )
 
+// orderListKey caches all the orders of an organization with their items and deliveries
+func orderListKey(org_id uuid.UUID) string {
+	return fmt.Sprintf("org:%s:orders", org_id)
+}
+
+// itemListKey caches the menu items of an organization. v2 holds is_active, items cached before it was
+// added would read as discontinued.
+func itemListKey(org_id uuid.UUID) string {
+	return fmt.Sprintf("org:%s:items:v2", org_id)
+}
+
 type CachedOrderStore struct {
 	store database.OrderStore
 	cache *CacheService
@@ -32,22 +48,30 @@ func (cos *CachedOrderStore) GetAllOrdersForLastWeek(org_id uuid.UUID) ([]databa
 	return cos.store.GetAllOrdersForLastWeek(org_id)
 }
 
-func (cos *CachedOrderStore) GetAllOrders(org_id uuid.UUID) ([]database.Order, error) {
-	return cos.store.GetAllOrders(org_id)
+func (cos *CachedOrderStore) GetOrders(org_id uuid.UUID, filter database.OrderFilter) ([]database.Order, error) {
+	return cos.store.GetOrders(org_id, filter)
 }
 
-func (cos *CachedOrderStore) GetTodaysOrder(org_id uuid.UUID) ([]database.Order, error) {
-	return cos.store.GetTodaysOrder(org_id)
+func (cos *CachedOrderStore) GetOrdersInRange(org_id uuid.UUID, from, to time.Time) ([]database.Order, error) {
+	return cos.store.GetOrdersInRange(org_id, from, to)
 }
 
-func (cos *CachedOrderStore) GetAllItems(org_id uuid.UUID) ([]database.Item, error) {
-	return cos.store.GetAllItems(org_id)
+func (cos *CachedOrderStore) GetOrder(org_id uuid.UUID, order_id uuid.UUID) (*database.Order, error) {
+	return cos.store.GetOrder(org_id, order_id)
+}
+
+func (cos *CachedOrderStore) GetTodaysOrder(org_id uuid.UUID) ([]database.Order, error) {
+	return cos.store.GetTodaysOrder(org_id)
 }
 
 func (cos *CachedOrderStore) GetAllDeliveries(org_id uuid.UUID) ([]database.OrderDelivery, error) {
 	return cos.store.GetAllDeliveries(org_id)
 }
 
+func (cos *CachedOrderStore) GetDeliveries(org_id uuid.UUID, filter database.DeliveryFilter) ([]database.OrderDelivery, error) {
+	return cos.store.GetDeliveries(org_id, filter)
+}
+
 func (cos *CachedOrderStore) GetAllDeliveriesForLastWeek(org_id uuid.UUID) ([]database.OrderDelivery, error) {
 	return cos.store.GetAllDeliveriesForLastWeek(org_id)
 }
@@ -56,6 +80,89 @@ func (cos *CachedOrderStore) GetTodaysDeliveries(org_id uuid.UUID) ([]database.O
 	return cos.store.GetTodaysDeliveries(org_id)
 }
 
+// GetChannelBreakdown is not cached, every report asks for its own period
+func (cos *CachedOrderStore) GetChannelBreakdown(org_id uuid.UUID, from, to time.Time) ([]database.ChannelBreakdown, error) {
+	return cos.store.GetChannelBreakdown(org_id, from, to)
+}
+
+// GetDiscountStats, GetCustomerDiscounts and GetShiftDiscounts are not cached, loss-prevention reviews
+// need the discounts as they are
+func (cos *CachedOrderStore) GetDiscountStats(org_id uuid.UUID, from, to time.Time) (*database.DiscountStats, error) {
+	return cos.store.GetDiscountStats(org_id, from, to)
+}
+
+func (cos *CachedOrderStore) GetCustomerDiscounts(org_id uuid.UUID, from, to time.Time, minDiscountedOrders int) ([]database.CustomerDiscounts, error) {
+	return cos.store.GetCustomerDiscounts(org_id, from, to, minDiscountedOrders)
+}
+
+func (cos *CachedOrderStore) GetShiftDiscounts(org_id uuid.UUID, from, to time.Time) ([]database.ShiftDiscounts, error) {
+	return cos.store.GetShiftDiscounts(org_id, from, to)
+}
+
+// GetDeliveryVolume is not cached, the period moves with every schedule
+func (cos *CachedOrderStore) GetDeliveryVolume(org_id uuid.UUID, from, to time.Time) ([]database.DeliveryVolume, error) {
+	return cos.store.GetDeliveryVolume(org_id, from, to)
+}
+
+// GetHourlyDemand is not cached, it is read for one-off analyses
+func (cos *CachedOrderStore) GetHourlyDemand(org_id uuid.UUID, from, to time.Time) ([]database.HourlyDemand, error) {
+	return cos.store.GetHourlyDemand(org_id, from, to)
+}
+
+// GetDriverDeliveryStats is not cached, managers pick the period
+func (cos *CachedOrderStore) GetDriverDeliveryStats(org_id uuid.UUID, from, to time.Time, onTimeMinutes int) ([]database.DriverDeliveryStats, error) {
+	return cos.store.GetDriverDeliveryStats(org_id, from, to, onTimeMinutes)
+}
+
+// GetDeliveryHeatmap is not cached, managers pick the grid and the period
+func (cos *CachedOrderStore) GetDeliveryHeatmap(org_id uuid.UUID, cellSize float64, filter database.DeliveryFilter) ([]database.DeliveryHeatmapCell, error) {
+	return cos.store.GetDeliveryHeatmap(org_id, cellSize, filter)
+}
+
+// --- Read Operations (Full Lists) - CACHE ---
+
+// GetAllOrders
+// Cache key: org:{uuid}:orders
+func (cos *CachedOrderStore) GetAllOrders(org_id uuid.UUID) ([]database.Order, error) {
+	key := orderListKey(org_id)
+
+	var orders []database.Order
+	if err := cos.cache.Get(key, &orders); err == nil {
+		// The organization is not part of the cached JSON
+		for i := range orders {
+			orders[i].OrganizationID = org_id
+		}
+		return orders, nil
+	}
+
+	orders, err := cos.store.GetAllOrders(org_id)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = cos.cache.Set(key, orders, OrderListCacheTTL)
+	return orders, nil
+}
+
+// GetAllItems
+// Cache key: org:{uuid}:items
+func (cos *CachedOrderStore) GetAllItems(org_id uuid.UUID) ([]database.Item, error) {
+	key := itemListKey(org_id)
+
+	var items []database.Item
+	if err := cos.cache.Get(key, &items); err == nil {
+		return items, nil
+	}
+
+	items, err := cos.store.GetAllItems(org_id)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = cos.cache.Set(key, items, ItemListCacheTTL)
+	return items, nil
+}
+
 // --- Read Operations (Computed/Aggregated) - CACHE ---
 
 // GetOrdersInsights
@@ -125,6 +232,7 @@ func (cos *CachedOrderStore) StoreOrder(org_id uuid.UUID, order *database.Order)
 	}
 
 	keys := []string{
+		orderListKey(org_id),
 		fmt.Sprintf("org:%s:insights:orders", org_id),
 		fmt.Sprintf("org:%s:insights:items", org_id), // Counts/most ordered might change
 	}
@@ -137,7 +245,116 @@ func (cos *CachedOrderStore) StoreOrder(org_id uuid.UUID, order *database.Order)
 	return nil
 }
 
-// StoreDelivery invalidates delivery insights
+// StoreOrdersBulk invalidates orders, items and, when a delivery was stored, delivery insights
+func (cos *CachedOrderStore) StoreOrdersBulk(org_id uuid.UUID, orders []database.Order, onConflict string) ([]uuid.UUID, []uuid.UUID, error) {
+	inserted, updated, err := cos.store.StoreOrdersBulk(org_id, orders, onConflict)
+	if err != nil || len(inserted)+len(updated) == 0 {
+		return inserted, updated, err
+	}
+
+	keys := []string{
+		orderListKey(org_id),
+		fmt.Sprintf("org:%s:insights:orders", org_id),
+		fmt.Sprintf("org:%s:insights:items", org_id),
+	}
+	for _, order := range orders {
+		if order.OrderType == "delivery" {
+			keys = append(keys, fmt.Sprintf("org:%s:insights:deliveries", org_id))
+			break
+		}
+	}
+
+	_ = cos.cache.Delete(keys...)
+	return inserted, updated, nil
+}
+
+// StoreNestedOrder invalidates orders, items and, for deliveries, delivery insights
+func (cos *CachedOrderStore) StoreNestedOrder(org_id uuid.UUID, order *database.Order) error {
+	err := cos.store.StoreNestedOrder(org_id, order)
+	if err != nil {
+		return err
+	}
+
+	keys := []string{
+		orderListKey(org_id),
+		fmt.Sprintf("org:%s:insights:orders", org_id),
+		fmt.Sprintf("org:%s:insights:items", org_id),
+	}
+
+	if order.DeliveryStatus != nil {
+		keys = append(keys, fmt.Sprintf("org:%s:insights:deliveries", org_id))
+	}
+
+	_ = cos.cache.Delete(keys...)
+	return nil
+}
+
+// UpdateOrderProgress invalidates orders and, when a delivery moved, delivery insights
+func (cos *CachedOrderStore) UpdateOrderProgress(org_id uuid.UUID, order_id uuid.UUID, orderStatus string, delivery *database.OrderDelivery) error {
+	err := cos.store.UpdateOrderProgress(org_id, order_id, orderStatus, delivery)
+	if err != nil {
+		return err
+	}
+
+	keys := []string{orderListKey(org_id), fmt.Sprintf("org:%s:insights:orders", org_id)}
+	if delivery != nil {
+		keys = append(keys, fmt.Sprintf("org:%s:insights:deliveries", org_id))
+	}
+
+	_ = cos.cache.Delete(keys...)
+	return nil
+}
+
+// UpdateOrder invalidates orders, items and delivery insights, the order may have moved in or out of
+// deliveries
+func (cos *CachedOrderStore) UpdateOrder(org_id uuid.UUID, order *database.Order) error {
+	err := cos.store.UpdateOrder(org_id, order)
+	if err != nil {
+		return err
+	}
+
+	_ = cos.cache.Delete(
+		orderListKey(org_id),
+		fmt.Sprintf("org:%s:insights:orders", org_id),
+		fmt.Sprintf("org:%s:insights:items", org_id),
+		fmt.Sprintf("org:%s:insights:deliveries", org_id),
+	)
+	return nil
+}
+
+// TransitionOrderStatus invalidates orders
+func (cos *CachedOrderStore) TransitionOrderStatus(org_id uuid.UUID, order_id uuid.UUID, toStatus string, changedBy *uuid.UUID, note *string) (*database.OrderStatusChange, error) {
+	change, err := cos.store.TransitionOrderStatus(org_id, order_id, toStatus, changedBy, note)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = cos.cache.Delete(orderListKey(org_id), fmt.Sprintf("org:%s:insights:orders", org_id))
+	return change, nil
+}
+
+// GetOrderStatusHistory is not cached, audits read the latest changes
+func (cos *CachedOrderStore) GetOrderStatusHistory(org_id uuid.UUID, order_id uuid.UUID) ([]database.OrderStatusChange, error) {
+	return cos.store.GetOrderStatusHistory(org_id, order_id)
+}
+
+// DeleteOrder invalidates orders, items and delivery insights
+func (cos *CachedOrderStore) DeleteOrder(org_id uuid.UUID, order_id uuid.UUID) error {
+	err := cos.store.DeleteOrder(org_id, order_id)
+	if err != nil {
+		return err
+	}
+
+	_ = cos.cache.Delete(
+		orderListKey(org_id),
+		fmt.Sprintf("org:%s:insights:orders", org_id),
+		fmt.Sprintf("org:%s:insights:items", org_id),
+		fmt.Sprintf("org:%s:insights:deliveries", org_id),
+	)
+	return nil
+}
+
+// StoreDelivery invalidates delivery insights and the orders the delivery is listed with
 func (cos *CachedOrderStore) StoreDelivery(org_id uuid.UUID, delivery *database.OrderDelivery) error {
 	err := cos.store.StoreDelivery(org_id, delivery)
 	if err != nil {
@@ -146,7 +363,7 @@ func (cos *CachedOrderStore) StoreDelivery(org_id uuid.UUID, delivery *database.
 
 	// Verify org_id via order lookup is complex here, so we assume caller provides correct org_id
 	// or we accept imperfect invalidation. Since org_id is passed:
-	_ = cos.cache.Delete(fmt.Sprintf("org:%s:insights:deliveries", org_id))
+	_ = cos.cache.Delete(orderListKey(org_id), fmt.Sprintf("org:%s:insights:deliveries", org_id))
 	return nil
 }
 
@@ -158,19 +375,62 @@ func (cos *CachedOrderStore) StoreOrderItems(org_id uuid.UUID, order_id uuid.UUI
 	}
 
 	_ = cos.cache.Delete(
+		orderListKey(org_id),
 		fmt.Sprintf("org:%s:insights:orders", org_id),
 		fmt.Sprintf("org:%s:insights:items", org_id),
 	)
 	return nil
 }
 
-// StoreItems invalidates items insights
+// StoreOrderItemsBulk invalidates orders and items insights
+func (cos *CachedOrderStore) StoreOrderItemsBulk(org_id uuid.UUID, links []database.OrderItemLink) ([]int, error) {
+	unknown, err := cos.store.StoreOrderItemsBulk(org_id, links)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = cos.cache.Delete(
+		orderListKey(org_id),
+		fmt.Sprintf("org:%s:insights:orders", org_id),
+		fmt.Sprintf("org:%s:insights:items", org_id),
+	)
+	return unknown, nil
+}
+
+// StoreItems invalidates the items, items insights and the campaigns listing the items
 func (cos *CachedOrderStore) StoreItems(org_id uuid.UUID, item *database.Item) error {
 	err := cos.store.StoreItems(org_id, item)
 	if err != nil {
 		return err
 	}
 
-	_ = cos.cache.Delete(fmt.Sprintf("org:%s:insights:items", org_id))
+	_ = cos.cache.Delete(itemListKey(org_id), campaignListKey(org_id), fmt.Sprintf("org:%s:insights:items", org_id))
+	return nil
+}
+
+// GetItem is not cached, it is read before the item is changed
+func (cos *CachedOrderStore) GetItem(org_id uuid.UUID, item_id uuid.UUID) (*database.Item, error) {
+	return cos.store.GetItem(org_id, item_id)
+}
+
+// UpdateItem invalidates the items, items insights and the campaigns listing the items
+func (cos *CachedOrderStore) UpdateItem(org_id uuid.UUID, item *database.Item, changedBy *uuid.UUID) error {
+	err := cos.store.UpdateItem(org_id, item, changedBy)
+	if err != nil {
+		return err
+	}
+
+	_ = cos.cache.Delete(itemListKey(org_id), campaignListKey(org_id), fmt.Sprintf("org:%s:insights:items", org_id))
+	return nil
+}
+
+// DeactivateItem invalidates the items, items insights and the campaigns listing the items
+func (cos *CachedOrderStore) DeactivateItem(org_id uuid.UUID, item_id uuid.UUID) error {
+	err := cos.store.DeactivateItem(org_id, item_id)
+	if err != nil {
+		return err
+	}
+
+	_ = cos.cache.Delete(itemListKey(org_id), campaignListKey(org_id), fmt.Sprintf("org:%s:insights:items", org_id))
 	return nil
 }
@@ -34,6 +34,11 @@ func (cos *CachedOrgStore) CreateOrgWithAdmin(org *database.Organization, adminU
 	return cos.store.CreateOrgWithAdmin(org, adminUser, password)
 }
 
+// CloneOrganization creates new data - no cache interaction needed
+func (cos *CachedOrgStore) CloneOrganization(sourceID uuid.UUID, org *database.Organization, adminID uuid.UUID, options database.OrgCloneOptions) (*database.OrgClone, error) {
+	return cos.store.CloneOrganization(sourceID, org, adminID, options)
+}
+
 // GetOrganizationByID retrieves static org details
 // Cache key: org:{uuid}
 func (cos *CachedOrgStore) GetOrganizationByID(id uuid.UUID) (*database.Organization, error) {
@@ -109,3 +114,58 @@ func (cos *CachedOrgStore) GetAdminEmailsByOrgID(orgID uuid.UUID) ([]string, err
 	_ = cos.cache.Set(key, emails, OrgEmailsCacheTTL)
 	return emails, nil
 }
+
+// SetCustomDomain updates the org row, so the cached details are dropped
+func (cos *CachedOrgStore) SetCustomDomain(orgID uuid.UUID, domain *string, token *string) error {
+	if err := cos.store.SetCustomDomain(orgID, domain, token); err != nil {
+		return err
+	}
+	_ = cos.cache.Delete(fmt.Sprintf("org:%s", orgID))
+	return nil
+}
+
+// GetCustomDomain is read when an admin configures the domain, not cached
+func (cos *CachedOrgStore) GetCustomDomain(orgID uuid.UUID) (*database.CustomDomain, error) {
+	return cos.store.GetCustomDomain(orgID)
+}
+
+// VerifyCustomDomain updates the org row, so the cached details are dropped
+func (cos *CachedOrgStore) VerifyCustomDomain(orgID uuid.UUID) error {
+	if err := cos.store.VerifyCustomDomain(orgID); err != nil {
+		return err
+	}
+	_ = cos.cache.Delete(fmt.Sprintf("org:%s", orgID))
+	return nil
+}
+
+// SetBrandColors updates the org row, so the cached details and profile are dropped
+func (cos *CachedOrgStore) SetBrandColors(orgID uuid.UUID, hex1, hex2, hex3 string) error {
+	if err := cos.store.SetBrandColors(orgID, hex1, hex2, hex3); err != nil {
+		return err
+	}
+	_ = cos.cache.Delete(fmt.Sprintf("org:%s", orgID), fmt.Sprintf("org:%s:profile", orgID))
+	return nil
+}
+
+// GetOrgIDByCustomDomain is not cached here, the CORS middleware keeps its own short lived cache of origins
+func (cos *CachedOrgStore) GetOrgIDByCustomDomain(domain string) (uuid.UUID, error) {
+	return cos.store.GetOrgIDByCustomDomain(domain)
+}
+
+// GetCurrency is read when reporting, not cached
+func (cos *CachedOrgStore) GetCurrency(orgID uuid.UUID) (string, error) {
+	return cos.store.GetCurrency(orgID)
+}
+
+func (cos *CachedOrgStore) SetCurrency(orgID uuid.UUID, currency string) error {
+	return cos.store.SetCurrency(orgID, currency)
+}
+
+// GetMagicLinkEnabled is read when a login link is requested, not cached
+func (cos *CachedOrgStore) GetMagicLinkEnabled(orgID uuid.UUID) (bool, error) {
+	return cos.store.GetMagicLinkEnabled(orgID)
+}
+
+func (cos *CachedOrgStore) SetMagicLinkEnabled(orgID uuid.UUID, enabled bool) error {
+	return cos.store.SetMagicLinkEnabled(orgID, enabled)
+}
@@ -136,3 +136,28 @@ func (cps *CachedPreferencesStore) DeletePreferenceByDay(employeeID uuid.UUID, d
 
 	return nil
 }
+
+// CopyPreferences invalidates everything for the employees copied to
+func (cps *CachedPreferencesStore) CopyPreferences(fromID uuid.UUID, toIDs []uuid.UUID) error {
+	err := cps.store.CopyPreferences(fromID, toIDs)
+	if err != nil {
+		return err
+	}
+
+	var keys []string
+	for _, employeeID := range toIDs {
+		keys = append(keys, fmt.Sprintf("user:%s:preferences", employeeID))
+		for _, day := range database.ValidDays {
+			keys = append(keys, fmt.Sprintf("user:%s:preferences:%s", employeeID, day))
+		}
+	}
+
+	_ = cps.cache.Delete(keys...)
+	return nil
+}
+
+// GetUnavailability is a date range query - DON'T CACHE
+// (it changes whenever a request is approved, which does not go through this store)
+func (cps *CachedPreferencesStore) GetUnavailability(employeeID uuid.UUID, from, to time.Time) ([]database.EmployeeUnavailability, error) {
+	return cps.store.GetUnavailability(employeeID, from, to)
+}
@@ -70,3 +70,14 @@ func (crs *CachedRequestStore) UpdateRequestStatus(id uuid.UUID, status string)
 	_ = crs.cache.Delete(fmt.Sprintf("request:%s", id))
 	return nil
 }
+
+// AcceptRequest invalidates the specific request cache
+func (crs *CachedRequestStore) AcceptRequest(id uuid.UUID) (*database.EmployeeUnavailability, error) {
+	unavailability, err := crs.store.AcceptRequest(id)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = crs.cache.Delete(fmt.Sprintf("request:%s", id))
+	return unavailability, nil
+}
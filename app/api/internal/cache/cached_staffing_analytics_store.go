@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	// Past days no longer change, they are computed once a day
+	StaffingVsDemandCacheTTL = 24 * time.Hour
+	// Today and the days ahead still get orders and schedule changes
+	StaffingVsDemandCurrentCacheTTL = 5 * time.Minute
+)
+
+type CachedStaffingAnalyticsStore struct {
+	store database.StaffingAnalyticsStore
+	cache *CacheService
+}
+
+func NewCachedStaffingAnalyticsStore(store database.StaffingAnalyticsStore, cache *CacheService) database.StaffingAnalyticsStore {
+	return &CachedStaffingAnalyticsStore{
+		store: store,
+		cache: cache,
+	}
+}
+
+// GetStaffingVsDemand retrieves the staffing of a day against its demand with caching
+// Cache key: org:{uuid}:staffing_vs_demand:{date}:{slot_minutes}
+func (cas *CachedStaffingAnalyticsStore) GetStaffingVsDemand(org_id uuid.UUID, date time.Time, slotMinutes int) (*database.StaffingVsDemand, error) {
+	key := fmt.Sprintf("org:%s:staffing_vs_demand:%s:%d", org_id, date.Format(time.DateOnly), slotMinutes)
+
+	var result database.StaffingVsDemand
+	if err := cas.cache.Get(key, &result); err == nil {
+		return &result, nil
+	}
+
+	resultPtr, err := cas.store.GetStaffingVsDemand(org_id, date, slotMinutes)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := StaffingVsDemandCurrentCacheTTL
+	now := time.Now()
+	if date.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, date.Location())) {
+		ttl = StaffingVsDemandCacheTTL
+	}
+	_ = cas.cache.Set(key, resultPtr, ttl)
+
+	return resultPtr, nil
+}
+
+// GetTurnover is not cached, it changes with every hire, resignation and layoff
+func (cas *CachedStaffingAnalyticsStore) GetTurnover(org_id uuid.UUID, from time.Time, quarters int) ([]database.TurnoverQuarter, error) {
+	return cas.store.GetTurnover(org_id, from, quarters)
+}
@@ -40,6 +40,8 @@ type cacheableUser struct {
 	PreferredHoursPerWeek *int      `json:"preferred_hours_per_week,omitempty"`
 	MaxConsecSlots        *int      `json:"max_consec_slots,omitempty"`
 	OnCall                *bool     `json:"on_call"`
+	Phone                 *string   `json:"phone,omitempty"`
+	EmergencyContact      *string   `json:"emergency_contact,omitempty"`
 	CreatedAt             time.Time `json:"created_at"`
 	UpdatedAt             time.Time `json:"updated_at"`
 }
@@ -57,6 +59,8 @@ func (cu *cacheableUser) toUser() *database.User {
 		PreferredHoursPerWeek: cu.PreferredHoursPerWeek,
 		MaxConsecSlots:        cu.MaxConsecSlots,
 		OnCall:                cu.OnCall,
+		Phone:                 cu.Phone,
+		EmergencyContact:      cu.EmergencyContact,
 		CreatedAt:             cu.CreatedAt,
 		UpdatedAt:             cu.UpdatedAt,
 	}
@@ -83,6 +87,8 @@ func fromUser(user *database.User) *cacheableUser {
 		PreferredHoursPerWeek: user.PreferredHoursPerWeek,
 		MaxConsecSlots:        user.MaxConsecSlots,
 		OnCall:                user.OnCall,
+		Phone:                 user.Phone,
+		EmergencyContact:      user.EmergencyContact,
 		CreatedAt:             user.CreatedAt,
 		UpdatedAt:             user.UpdatedAt,
 	}
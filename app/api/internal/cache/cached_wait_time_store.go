@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// CachedWaitTimeStore keeps the cached order list in step with the kitchen display, which marks orders
+// prepared behind the order store
+type CachedWaitTimeStore struct {
+	store database.WaitTimeStore
+	cache *CacheService
+}
+
+func NewCachedWaitTimeStore(store database.WaitTimeStore, cache *CacheService) database.WaitTimeStore {
+	return &CachedWaitTimeStore{
+		store: store,
+		cache: cache,
+	}
+}
+
+// GetOpenKitchenLoad bypasses cache, the load changes with every order
+func (cws *CachedWaitTimeStore) GetOpenKitchenLoad(org_id uuid.UUID, since time.Time) (*database.KitchenLoad, error) {
+	return cws.store.GetOpenKitchenLoad(org_id, since)
+}
+
+// GetKitchenCapacity bypasses cache
+func (cws *CachedWaitTimeStore) GetKitchenCapacity(org_id uuid.UUID, at time.Time) (*database.KitchenCapacity, error) {
+	return cws.store.GetKitchenCapacity(org_id, at)
+}
+
+// GetOrderProfile bypasses cache
+func (cws *CachedWaitTimeStore) GetOrderProfile(org_id uuid.UUID) (*database.OrderProfile, error) {
+	return cws.store.GetOrderProfile(org_id)
+}
+
+// MarkPrepared updates DB and invalidates orders
+func (cws *CachedWaitTimeStore) MarkPrepared(org_id, order_id uuid.UUID, item_ids []uuid.UUID, at time.Time) (*database.PreparedOrder, error) {
+	order, err := cws.store.MarkPrepared(org_id, order_id, item_ids, at)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = cws.cache.Delete(orderListKey(org_id))
+	return order, nil
+}
+
+// GetRecentPrepTime bypasses cache
+func (cws *CachedWaitTimeStore) GetRecentPrepTime(org_id uuid.UUID, since time.Time) (*database.PrepTime, error) {
+	return cws.store.GetRecentPrepTime(org_id, since)
+}
+
+// GetItemPrepTimes bypasses cache
+func (cws *CachedWaitTimeStore) GetItemPrepTimes(org_id uuid.UUID, since time.Time) ([]database.ItemPrepTime, error) {
+	return cws.store.GetItemPrepTimes(org_id, since)
+}
+
+// GetHourlyPrepTimes bypasses cache
+func (cws *CachedWaitTimeStore) GetHourlyPrepTimes(org_id uuid.UUID, since time.Time) ([]database.HourPrepTime, error) {
+	return cws.store.GetHourlyPrepTimes(org_id, since)
+}
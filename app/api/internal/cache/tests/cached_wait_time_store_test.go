@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/cache"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubWaitTimeStore answers MarkPrepared, the reads are not used by these tests
+type stubWaitTimeStore struct {
+	database.WaitTimeStore
+	order *database.PreparedOrder
+	err   error
+}
+
+func (s *stubWaitTimeStore) MarkPrepared(org_id, order_id uuid.UUID, item_ids []uuid.UUID, at time.Time) (*database.PreparedOrder, error) {
+	return s.order, s.err
+}
+
+func TestCachedWaitTimeStoreMarkPrepared(t *testing.T) {
+	cacheService, fake := NewTestCache(t)
+	orgID := uuid.New()
+	orderID := uuid.New()
+	orderListKey := fmt.Sprintf("org:%s:orders", orgID)
+	otherOrgKey := fmt.Sprintf("org:%s:orders", uuid.New())
+
+	t.Run("Success_InvalidatesOrders", func(t *testing.T) {
+		assert.NoError(t, cacheService.Set(orderListKey, []database.Order{{OrderID: orderID}}, time.Minute))
+		assert.NoError(t, cacheService.Set(otherOrgKey, []database.Order{{OrderID: uuid.New()}}, time.Minute))
+		store := cache.NewCachedWaitTimeStore(&stubWaitTimeStore{order: &database.PreparedOrder{OrderID: orderID, PreparedItems: 2}}, cacheService)
+
+		order, err := store.MarkPrepared(orgID, orderID, nil, time.Now())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, order.PreparedItems)
+		assert.False(t, fake.Has(orderListKey), "the orders of the organization are read again")
+		assert.True(t, fake.Has(otherOrgKey), "other organizations keep their cache")
+	})
+
+	t.Run("Failure_KeepsOrders", func(t *testing.T) {
+		assert.NoError(t, cacheService.Set(orderListKey, []database.Order{{OrderID: orderID}}, time.Minute))
+		store := cache.NewCachedWaitTimeStore(&stubWaitTimeStore{err: errors.New("db error")}, cacheService)
+
+		order, err := store.MarkPrepared(orgID, orderID, nil, time.Now())
+
+		assert.Error(t, err)
+		assert.Nil(t, order)
+		assert.True(t, fake.Has(orderListKey))
+	})
+}
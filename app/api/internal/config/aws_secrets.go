@@ -0,0 +1,171 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSSecretsManagerProvider reads secrets from one AWS Secrets Manager secret whose SecretString is a JSON
+// object keyed by secret name. Requests are signed with Signature Version 4.
+type AWSSecretsManagerProvider struct {
+	SecretID        string
+	Region          string
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Client          *http.Client
+}
+
+// NewAWSSecretsManagerProviderFromEnv configures Secrets Manager from AWS_SECRET_ID, AWS_REGION and the standard
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN variables. AWS_ENDPOINT_URL overrides the endpoint.
+func NewAWSSecretsManagerProviderFromEnv() (*AWSSecretsManagerProvider, error) {
+	provider := &AWSSecretsManagerProvider{
+		SecretID:        os.Getenv("AWS_SECRET_ID"),
+		Region:          os.Getenv("AWS_REGION"),
+		Endpoint:        os.Getenv("AWS_ENDPOINT_URL"),
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		Client:          &http.Client{Timeout: 10 * time.Second},
+	}
+	if provider.SecretID == "" || provider.Region == "" {
+		return nil, errors.New("AWS_SECRET_ID and AWS_REGION are required for the aws secrets provider")
+	}
+	if provider.AccessKeyID == "" || provider.SecretAccessKey == "" {
+		return nil, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required for the aws secrets provider")
+	}
+	if provider.Endpoint == "" {
+		provider.Endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", provider.Region)
+	}
+	return provider, nil
+}
+
+func (p *AWSSecretsManagerProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	payload, err := json.Marshal(map[string]string{"SecretId": p.SecretID})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(p.Endpoint, "/")+"/", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	p.sign(req, payload, time.Now().UTC())
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach aws secrets manager: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var awsErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&awsErr)
+		if strings.HasSuffix(awsErr.Type, "ResourceNotFoundException") {
+			return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+		}
+		return "", fmt.Errorf("aws secrets manager returned status %d: %s %s", resp.StatusCode, awsErr.Type, awsErr.Message)
+	}
+
+	var body struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode aws secrets manager response: %w", err)
+	}
+
+	var values map[string]string
+	if err := json.Unmarshal([]byte(body.SecretString), &values); err != nil {
+		return "", fmt.Errorf("aws secret %s must be a JSON object of strings", p.SecretID)
+	}
+	value, ok := values[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	return value, nil
+}
+
+// sign adds the Signature Version 4 headers for the secretsmanager service
+func (p *AWSSecretsManagerProvider) sign(req *http.Request, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	scope := date + "/" + p.Region + "/secretsmanager/aws4_request"
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if p.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", p.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(req.Header.Get(name))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hashHex(payload),
+	}, "\n")
+
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hashHex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+p.SecretAccessKey), date)
+	key = hmacSHA256(key, p.Region)
+	key = hmacSHA256(key, "secretsmanager")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		p.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(query url.Values) string {
+	return strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// Config is the deployment configuration of the API. Plain settings come from the environment,
+// credentials are resolved through Secrets so they can live in Vault or AWS Secrets Manager.
+type Config struct {
+	Secrets  SecretsProvider
+	CORS     CORSConfig
+	CSRF     CSRFConfig
+	Versions APIVersionsConfig
+	Database DatabaseConfig
+	Logger   *slog.Logger
+}
+
+// Load reads the configuration of the current deployment
+func Load(logger *slog.Logger) (*Config, error) {
+	secrets, err := NewSecretsProviderFromEnv(logger)
+	if err != nil {
+		return nil, err
+	}
+	return &Config{
+		Secrets:  secrets,
+		CORS:     loadCORSConfig(logger),
+		CSRF:     loadCSRFConfig(logger),
+		Versions: loadAPIVersionsConfig(logger),
+		Database: loadDatabaseConfig(logger),
+		Logger:   logger,
+	}, nil
+}
+
+// Secret returns a credential, or an empty string when it is not configured anywhere
+func (c *Config) Secret(ctx context.Context, name string) string {
+	value, err := c.Secrets.GetSecret(ctx, name)
+	if err != nil {
+		c.Logger.Warn("secret unavailable", "secret", name, "error", err)
+		return ""
+	}
+	return value
+}
+
+// SecretSource returns a function resolving a credential on every call, for connections that must pick
+// up rotated credentials without restarting the API
+func (c *Config) SecretSource(name string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		return c.Secrets.GetSecret(ctx, name)
+	}
+}
+
+func durationFromEnv(key string, fallback time.Duration, logger *slog.Logger) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		logger.Warn("invalid duration in environment, using default", "key", key, "value", value, "default", fallback)
+		return fallback
+	}
+	return duration
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultAllowedOrigins are the dashboard origins used in local development
+var defaultAllowedOrigins = []string{"http://localhost:3000", "http://localhost:80", "http://localhost:8000", "http://localhost:8080"}
+
+// CORSConfig lists the browser origins allowed to call the API
+type CORSConfig struct {
+	// AllowedOrigins may contain wildcards such as https://*.clockwise.app
+	AllowedOrigins []string
+	// AllowCustomDomains also allows the custom domain each organization verified with a TXT record
+	AllowCustomDomains bool
+	MaxAge             time.Duration
+}
+
+// CSRFConfig configures double submit cookie protection for requests authenticated by the
+// access_token cookie. Requests sending a bearer token are not affected.
+type CSRFConfig struct {
+	Enabled      bool
+	CookieName   string
+	HeaderName   string
+	SecureCookie bool
+}
+
+func loadCORSConfig(logger *slog.Logger) CORSConfig {
+	origins := defaultAllowedOrigins
+	if value := os.Getenv("CORS_ALLOWED_ORIGINS"); value != "" {
+		origins = splitList(value)
+	}
+	return CORSConfig{
+		AllowedOrigins:     origins,
+		AllowCustomDomains: boolFromEnv("CORS_ALLOW_CUSTOM_DOMAINS", false, logger),
+		MaxAge:             durationFromEnv("CORS_MAX_AGE", 12*time.Hour, logger),
+	}
+}
+
+func loadCSRFConfig(logger *slog.Logger) CSRFConfig {
+	cfg := CSRFConfig{
+		Enabled:      boolFromEnv("CSRF_ENABLED", false, logger),
+		CookieName:   os.Getenv("CSRF_COOKIE_NAME"),
+		HeaderName:   os.Getenv("CSRF_HEADER_NAME"),
+		SecureCookie: boolFromEnv("CSRF_COOKIE_SECURE", true, logger),
+	}
+	if cfg.CookieName == "" {
+		cfg.CookieName = "csrf_token"
+	}
+	if cfg.HeaderName == "" {
+		cfg.HeaderName = "X-CSRF-Token"
+	}
+	return cfg
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func boolFromEnv(key string, fallback bool, logger *slog.Logger) bool {
+	switch strings.ToLower(os.Getenv(key)) {
+	case "":
+		return fallback
+	case "1", "true", "yes", "on":
+		return true
+	case "0", "false", "no", "off":
+		return false
+	default:
+		logger.Warn("invalid boolean in environment, using default", "key", key, "value", os.Getenv(key), "default", fallback)
+		return fallback
+	}
+}
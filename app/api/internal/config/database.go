@@ -0,0 +1,46 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+)
+
+// DatabaseConfig sizes the connection pool of the database and sets when queries are logged as slow
+type DatabaseConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// SlowQueryThreshold is the duration above which a query is logged with its caller
+	SlowQueryThreshold time.Duration
+}
+
+func loadDatabaseConfig(logger *slog.Logger) DatabaseConfig {
+	cfg := DatabaseConfig{
+		MaxOpenConns:       intFromEnv("DB_MAX_OPEN_CONNS", 25, logger),
+		MaxIdleConns:       intFromEnv("DB_MAX_IDLE_CONNS", 10, logger),
+		ConnMaxLifetime:    durationFromEnv("DB_CONN_MAX_LIFETIME", 30*time.Minute, logger),
+		ConnMaxIdleTime:    durationFromEnv("DB_CONN_MAX_IDLE_TIME", 5*time.Minute, logger),
+		SlowQueryThreshold: durationFromEnv("DB_SLOW_QUERY_THRESHOLD", 500*time.Millisecond, logger),
+	}
+	if cfg.MaxIdleConns > cfg.MaxOpenConns {
+		logger.Warn("DB_MAX_IDLE_CONNS is above DB_MAX_OPEN_CONNS, using DB_MAX_OPEN_CONNS", "max_idle_conns", cfg.MaxIdleConns, "max_open_conns", cfg.MaxOpenConns)
+		cfg.MaxIdleConns = cfg.MaxOpenConns
+	}
+	return cfg
+}
+
+func intFromEnv(key string, fallback int, logger *slog.Logger) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		logger.Warn("invalid number in environment, using default", "key", key, "value", value, "default", fallback)
+		return fallback
+	}
+	return number
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadDatabaseConfig(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		cfg := loadDatabaseConfig(slog.Default())
+		assert.Equal(t, 25, cfg.MaxOpenConns)
+		assert.Equal(t, 10, cfg.MaxIdleConns)
+		assert.Equal(t, 30*time.Minute, cfg.ConnMaxLifetime)
+		assert.Equal(t, 500*time.Millisecond, cfg.SlowQueryThreshold)
+	})
+
+	t.Run("FromEnvironment", func(t *testing.T) {
+		t.Setenv("DB_MAX_OPEN_CONNS", "50")
+		t.Setenv("DB_MAX_IDLE_CONNS", "20")
+		t.Setenv("DB_CONN_MAX_IDLE_TIME", "1m")
+		t.Setenv("DB_SLOW_QUERY_THRESHOLD", "2s")
+
+		cfg := loadDatabaseConfig(slog.Default())
+		assert.Equal(t, 50, cfg.MaxOpenConns)
+		assert.Equal(t, 20, cfg.MaxIdleConns)
+		assert.Equal(t, time.Minute, cfg.ConnMaxIdleTime)
+		assert.Equal(t, 2*time.Second, cfg.SlowQueryThreshold)
+	})
+
+	t.Run("InvalidValuesUseDefaults", func(t *testing.T) {
+		t.Setenv("DB_MAX_OPEN_CONNS", "many")
+		t.Setenv("DB_MAX_IDLE_CONNS", "-1")
+		t.Setenv("DB_SLOW_QUERY_THRESHOLD", "fast")
+
+		cfg := loadDatabaseConfig(slog.Default())
+		assert.Equal(t, 25, cfg.MaxOpenConns)
+		assert.Equal(t, 10, cfg.MaxIdleConns)
+		assert.Equal(t, 500*time.Millisecond, cfg.SlowQueryThreshold)
+	})
+
+	t.Run("IdleCappedByOpen", func(t *testing.T) {
+		t.Setenv("DB_MAX_OPEN_CONNS", "5")
+
+		cfg := loadDatabaseConfig(slog.Default())
+		assert.Equal(t, 5, cfg.MaxIdleConns)
+	})
+}
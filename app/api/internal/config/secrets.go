@@ -0,0 +1,140 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretsProvider resolves credentials such as SMTP_PASSWORD or POSTGRES_PASSWORD by name
+type SecretsProvider interface {
+	GetSecret(ctx context.Context, name string) (string, error)
+}
+
+// NewSecretsProviderFromEnv returns the provider selected by SECRETS_PROVIDER: "vault" for HashiCorp Vault,
+// "aws" for AWS Secrets Manager, or the environment otherwise. Remote providers are cached for
+// SECRETS_CACHE_TTL (default 5m) and fall back to the environment for secrets they do not hold.
+func NewSecretsProviderFromEnv(logger *slog.Logger) (SecretsProvider, error) {
+	ttl := durationFromEnv("SECRETS_CACHE_TTL", 5*time.Minute, logger)
+
+	var remote SecretsProvider
+	switch strings.ToLower(os.Getenv("SECRETS_PROVIDER")) {
+	case "", "env":
+		return &EnvSecretsProvider{}, nil
+	case "vault":
+		vault, err := NewVaultSecretsProviderFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		remote = vault
+	case "aws":
+		aws, err := NewAWSSecretsManagerProviderFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		remote = aws
+	default:
+		return nil, fmt.Errorf("unknown secrets provider %q", os.Getenv("SECRETS_PROVIDER"))
+	}
+
+	logger.Info("secrets provider configured", "provider", os.Getenv("SECRETS_PROVIDER"), "cache_ttl", ttl)
+	return NewCachedSecretsProvider(&ChainSecretsProvider{Providers: []SecretsProvider{remote, &EnvSecretsProvider{}}}, ttl, logger), nil
+}
+
+// EnvSecretsProvider reads secrets from environment variables of the same name
+type EnvSecretsProvider struct{}
+
+func (p *EnvSecretsProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	return value, nil
+}
+
+// ChainSecretsProvider returns the secret of the first provider that holds it
+type ChainSecretsProvider struct {
+	Providers []SecretsProvider
+}
+
+func (p *ChainSecretsProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	for _, provider := range p.Providers {
+		value, err := provider.GetSecret(ctx, name)
+		if err == nil {
+			return value, nil
+		}
+		if !errors.Is(err, ErrSecretNotFound) {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+}
+
+type cachedSecret struct {
+	value     string
+	expiresAt time.Time
+}
+
+// CachedSecretsProvider keeps secrets for a TTL so remote stores are not hit on every connection or email.
+// When the remote store is unreachable an expired secret is served rather than failing.
+type CachedSecretsProvider struct {
+	provider SecretsProvider
+	ttl      time.Duration
+	logger   *slog.Logger
+
+	mu      sync.Mutex
+	secrets map[string]cachedSecret
+}
+
+func NewCachedSecretsProvider(provider SecretsProvider, ttl time.Duration, logger *slog.Logger) *CachedSecretsProvider {
+	return &CachedSecretsProvider{
+		provider: provider,
+		ttl:      ttl,
+		logger:   logger,
+		secrets:  make(map[string]cachedSecret),
+	}
+}
+
+func (p *CachedSecretsProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	p.mu.Lock()
+	cached, ok := p.secrets[name]
+	p.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.value, nil
+	}
+
+	value, err := p.provider.GetSecret(ctx, name)
+	if err != nil {
+		if ok && !errors.Is(err, ErrSecretNotFound) {
+			p.logger.Warn("failed to refresh secret, using the cached value", "secret", name, "error", err)
+			return cached.value, nil
+		}
+		return "", err
+	}
+
+	p.mu.Lock()
+	p.secrets[name] = cachedSecret{value: value, expiresAt: time.Now().Add(p.ttl)}
+	p.mu.Unlock()
+	return value, nil
+}
+
+// Invalidate drops a cached secret, so that a credential rejected after a rotation is fetched again
+func (p *CachedSecretsProvider) Invalidate(name string) {
+	p.mu.Lock()
+	delete(p.secrets, name)
+	p.mu.Unlock()
+}
+
+// InvalidateSecret drops a cached secret when the provider caches secrets
+func InvalidateSecret(provider SecretsProvider, name string) {
+	if cached, ok := provider.(*CachedSecretsProvider); ok {
+		cached.Invalidate(name)
+	}
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingProvider struct {
+	value string
+	err   error
+	calls int
+}
+
+func (p *countingProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	p.calls++
+	return p.value, p.err
+}
+
+func TestCachedSecretsProvider(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("CachesUntilInvalidated", func(t *testing.T) {
+		remote := &countingProvider{value: "old"}
+		cached := NewCachedSecretsProvider(remote, time.Hour, slog.Default())
+
+		value, _ := cached.GetSecret(ctx, "SMTP_PASSWORD")
+		assert.Equal(t, "old", value)
+		remote.value = "rotated"
+		value, _ = cached.GetSecret(ctx, "SMTP_PASSWORD")
+		assert.Equal(t, "old", value)
+		assert.Equal(t, 1, remote.calls)
+
+		InvalidateSecret(cached, "SMTP_PASSWORD")
+		value, _ = cached.GetSecret(ctx, "SMTP_PASSWORD")
+		assert.Equal(t, "rotated", value)
+	})
+
+	t.Run("ServesExpiredValueWhenRemoteIsDown", func(t *testing.T) {
+		remote := &countingProvider{value: "secret"}
+		cached := NewCachedSecretsProvider(remote, time.Nanosecond, slog.Default())
+
+		cached.GetSecret(ctx, "POSTGRES_PASSWORD")
+		time.Sleep(time.Millisecond)
+		remote.err = errors.New("connection refused")
+
+		value, err := cached.GetSecret(ctx, "POSTGRES_PASSWORD")
+		assert.NoError(t, err)
+		assert.Equal(t, "secret", value)
+	})
+}
+
+func TestChainSecretsProviderFallsBackToEnv(t *testing.T) {
+	t.Setenv("SMTP_USERNAME", "mailer")
+	chain := &ChainSecretsProvider{Providers: []SecretsProvider{&countingProvider{err: ErrSecretNotFound}, &EnvSecretsProvider{}}}
+
+	value, err := chain.GetSecret(context.Background(), "SMTP_USERNAME")
+	assert.NoError(t, err)
+	assert.Equal(t, "mailer", value)
+
+	_, err = chain.GetSecret(context.Background(), "MISSING_SECRET")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+func TestVaultSecretsProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/secret/data/clockwise", r.URL.Path)
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"SMTP_PASSWORD":"from-vault"},"metadata":{"version":3}}}`))
+	}))
+	defer server.Close()
+
+	provider := &VaultSecretsProvider{Address: server.URL, Path: "secret/data/clockwise", Token: "token", Client: server.Client()}
+
+	value, err := provider.GetSecret(context.Background(), "SMTP_PASSWORD")
+	assert.NoError(t, err)
+	assert.Equal(t, "from-vault", value)
+
+	_, err = provider.GetSecret(context.Background(), "POSTGRES_PASSWORD")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+func TestAWSSecretsManagerProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+		assert.Contains(t, r.Header.Get("Authorization"), "/eu-west-1/secretsmanager/aws4_request")
+		w.Write([]byte(`{"Name":"clockwise","SecretString":"{\"POSTGRES_PASSWORD\":\"from-aws\"}"}`))
+	}))
+	defer server.Close()
+
+	provider := &AWSSecretsManagerProvider{
+		SecretID:        "clockwise",
+		Region:          "eu-west-1",
+		Endpoint:        server.URL,
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		Client:          server.Client(),
+	}
+
+	value, err := provider.GetSecret(context.Background(), "POSTGRES_PASSWORD")
+	assert.NoError(t, err)
+	assert.Equal(t, "from-aws", value)
+}
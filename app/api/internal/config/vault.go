@@ -0,0 +1,96 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// VaultSecretsProvider reads secrets from one HashiCorp Vault secret whose keys are the secret names,
+// e.g. the KV v2 path "secret/data/clockwise" holding SMTP_PASSWORD and POSTGRES_PASSWORD
+type VaultSecretsProvider struct {
+	Address string
+	Path    string
+	// Token is read from TokenFile on every request when set, so tokens renewed by a Vault agent are used
+	Token     string
+	TokenFile string
+	Client    *http.Client
+}
+
+// NewVaultSecretsProviderFromEnv configures Vault from VAULT_ADDR, VAULT_SECRET_PATH and VAULT_TOKEN or VAULT_TOKEN_FILE
+func NewVaultSecretsProviderFromEnv() (*VaultSecretsProvider, error) {
+	provider := &VaultSecretsProvider{
+		Address:   strings.TrimRight(os.Getenv("VAULT_ADDR"), "/"),
+		Path:      strings.Trim(os.Getenv("VAULT_SECRET_PATH"), "/"),
+		Token:     os.Getenv("VAULT_TOKEN"),
+		TokenFile: os.Getenv("VAULT_TOKEN_FILE"),
+		Client:    &http.Client{Timeout: 10 * time.Second},
+	}
+	if provider.Address == "" || provider.Path == "" {
+		return nil, errors.New("VAULT_ADDR and VAULT_SECRET_PATH are required for the vault secrets provider")
+	}
+	if provider.Token == "" && provider.TokenFile == "" {
+		return nil, errors.New("VAULT_TOKEN or VAULT_TOKEN_FILE is required for the vault secrets provider")
+	}
+	return provider, nil
+}
+
+func (p *VaultSecretsProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	token := p.Token
+	if p.TokenFile != "" {
+		data, err := os.ReadFile(p.TokenFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read vault token file: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.Address+"/v1/"+p.Path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach vault: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned status %d for %s", resp.StatusCode, p.Path)
+	}
+
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode vault response: %w", err)
+	}
+
+	// KV v2 nests the values under data.data, KV v1 returns them under data
+	values := body.Data
+	if nested, ok := body.Data["data"]; ok {
+		if err := json.Unmarshal(nested, &values); err != nil {
+			return "", fmt.Errorf("failed to decode vault secret: %w", err)
+		}
+	}
+
+	raw, ok := values[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("vault secret %s is not a string", name)
+	}
+	return value, nil
+}
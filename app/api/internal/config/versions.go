@@ -0,0 +1,47 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"time"
+)
+
+// The unversioned /api routes are deprecated since /api/v1 was introduced and removed six months later
+var (
+	defaultLegacyDeprecated = time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	defaultLegacySunset     = time.Date(2027, 4, 16, 0, 0, 0, 0, time.UTC)
+)
+
+// APIVersionsConfig dates the retirement of API versions, announced to clients in the Deprecation and
+// Sunset headers. Zero dates are not announced.
+type APIVersionsConfig struct {
+	// LegacyDeprecated and LegacySunset date the unversioned /api routes, aliases of /api/v1
+	LegacyDeprecated time.Time
+	LegacySunset     time.Time
+	// V1Deprecated and V1Sunset date /api/v1 once clients should move to /api/v2
+	V1Deprecated time.Time
+	V1Sunset     time.Time
+}
+
+func loadAPIVersionsConfig(logger *slog.Logger) APIVersionsConfig {
+	return APIVersionsConfig{
+		LegacyDeprecated: dateFromEnv("API_LEGACY_DEPRECATED", defaultLegacyDeprecated, logger),
+		LegacySunset:     dateFromEnv("API_LEGACY_SUNSET", defaultLegacySunset, logger),
+		V1Deprecated:     dateFromEnv("API_V1_DEPRECATED", time.Time{}, logger),
+		V1Sunset:         dateFromEnv("API_V1_SUNSET", time.Time{}, logger),
+	}
+}
+
+// dateFromEnv reads a YYYY-MM-DD date, in UTC
+func dateFromEnv(key string, fallback time.Time, logger *slog.Logger) time.Time {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		logger.Warn("invalid date in environment, using default", "key", key, "value", value, "default", fallback)
+		return fallback
+	}
+	return date
+}
@@ -0,0 +1,333 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Announcement is a message broadcast by managers to the staff of an organization.
+// An empty TargetRoles list means everyone in the organization.
+type Announcement struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	AuthorID       *uuid.UUID `json:"author_id"`
+	AuthorName     string     `json:"author_name"`
+	Title          string     `json:"title"`
+	Message        string     `json:"message"`
+	Pinned         bool       `json:"pinned"`
+	TargetRoles    []string   `json:"target_roles"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ReadCount      *int       `json:"read_count,omitempty"` // set when listed for managers
+	ReadAt         *time.Time `json:"read_at,omitempty"`    // set when listed for the current user
+}
+
+// AnnouncementRead is a read receipt of an announcement
+type AnnouncementRead struct {
+	UserID   uuid.UUID `json:"user_id"`
+	FullName string    `json:"full_name"`
+	ReadAt   time.Time `json:"read_at"`
+}
+
+type AnnouncementStore interface {
+	CreateAnnouncement(announcement *Announcement) error
+	GetAnnouncementByID(org_id uuid.UUID, id uuid.UUID) (*Announcement, error)
+	GetAnnouncementsForOrganization(org_id uuid.UUID) ([]Announcement, error)
+	GetActiveAnnouncementsForUser(org_id uuid.UUID, user_id uuid.UUID, roles []string) ([]Announcement, error)
+	DeleteAnnouncement(org_id uuid.UUID, id uuid.UUID) error
+	MarkAnnouncementRead(id uuid.UUID, user_id uuid.UUID) error
+	GetAnnouncementReads(id uuid.UUID) ([]AnnouncementRead, error)
+}
+
+type PostgresAnnouncementStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresAnnouncementStore(DB *sql.DB, Logger *slog.Logger) *PostgresAnnouncementStore {
+	return &PostgresAnnouncementStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// CreateAnnouncement stores a new announcement, filling in its ID and creation time
+func (s *PostgresAnnouncementStore) CreateAnnouncement(announcement *Announcement) error {
+	if announcement.ID == uuid.Nil {
+		announcement.ID = uuid.New()
+	}
+	if announcement.CreatedAt.IsZero() {
+		announcement.CreatedAt = time.Now()
+	}
+	if announcement.TargetRoles == nil {
+		announcement.TargetRoles = []string{}
+	}
+
+	query := `
+		INSERT INTO announcements (id, organization_id, author_id, title, message, pinned, target_roles, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := s.DB.Exec(query,
+		announcement.ID,
+		announcement.OrganizationID,
+		announcement.AuthorID,
+		announcement.Title,
+		announcement.Message,
+		announcement.Pinned,
+		pq.Array(announcement.TargetRoles),
+		announcement.ExpiresAt,
+		announcement.CreatedAt,
+	)
+	if err != nil {
+		s.Logger.Error("failed to create announcement", "error", err, "org_id", announcement.OrganizationID)
+		return err
+	}
+
+	s.Logger.Info("announcement created", "id", announcement.ID, "org_id", announcement.OrganizationID)
+	return nil
+}
+
+// GetAnnouncementByID retrieves an announcement of the organization, returning sql.ErrNoRows if it does not exist
+func (s *PostgresAnnouncementStore) GetAnnouncementByID(org_id uuid.UUID, id uuid.UUID) (*Announcement, error) {
+	query := `
+		SELECT a.id, a.organization_id, a.author_id, COALESCE(u.full_name, ''), a.title, a.message,
+			a.pinned, a.target_roles, a.expires_at, a.created_at
+		FROM announcements a
+		LEFT JOIN users u ON a.author_id = u.id
+		WHERE a.id = $1 AND a.organization_id = $2
+	`
+
+	announcement, err := scanAnnouncement(s.DB.QueryRow(query, id, org_id))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get announcement", "error", err, "id", id)
+		}
+		return nil, err
+	}
+	return announcement, nil
+}
+
+// GetAnnouncementsForOrganization retrieves every announcement of the organization, including expired
+// ones, with the number of read receipts. Pinned announcements come first.
+func (s *PostgresAnnouncementStore) GetAnnouncementsForOrganization(org_id uuid.UUID) ([]Announcement, error) {
+	query := `
+		SELECT a.id, a.organization_id, a.author_id, COALESCE(u.full_name, ''), a.title, a.message,
+			a.pinned, a.target_roles, a.expires_at, a.created_at,
+			(SELECT COUNT(*) FROM announcement_reads r WHERE r.announcement_id = a.id)
+		FROM announcements a
+		LEFT JOIN users u ON a.author_id = u.id
+		WHERE a.organization_id = $1
+		ORDER BY a.pinned DESC, a.created_at DESC
+	`
+
+	rows, err := s.DB.Query(query, org_id)
+	if err != nil {
+		s.Logger.Error("failed to get announcements", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var announcements []Announcement
+	for rows.Next() {
+		var announcement Announcement
+		var authorID uuid.NullUUID
+		var targetRoles pq.StringArray
+		var expiresAt sql.NullTime
+		var readCount int
+
+		err := rows.Scan(
+			&announcement.ID,
+			&announcement.OrganizationID,
+			&authorID,
+			&announcement.AuthorName,
+			&announcement.Title,
+			&announcement.Message,
+			&announcement.Pinned,
+			&targetRoles,
+			&expiresAt,
+			&announcement.CreatedAt,
+			&readCount,
+		)
+		if err != nil {
+			s.Logger.Error("failed to scan announcement row", "error", err)
+			return nil, err
+		}
+		setAnnouncementNullables(&announcement, authorID, targetRoles, expiresAt)
+		announcement.ReadCount = &readCount
+		announcements = append(announcements, announcement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return announcements, nil
+}
+
+// GetActiveAnnouncementsForUser retrieves the unexpired announcements that target everyone or one of
+// the given roles, with the time the user read each of them. Pinned announcements come first.
+func (s *PostgresAnnouncementStore) GetActiveAnnouncementsForUser(org_id uuid.UUID, user_id uuid.UUID, roles []string) ([]Announcement, error) {
+	query := `
+		SELECT a.id, a.organization_id, a.author_id, COALESCE(u.full_name, ''), a.title, a.message,
+			a.pinned, a.target_roles, a.expires_at, a.created_at, r.read_at
+		FROM announcements a
+		LEFT JOIN users u ON a.author_id = u.id
+		LEFT JOIN announcement_reads r ON r.announcement_id = a.id AND r.user_id = $2
+		WHERE a.organization_id = $1
+			AND (a.expires_at IS NULL OR a.expires_at > NOW())
+			AND (CARDINALITY(a.target_roles) = 0 OR a.target_roles && $3)
+		ORDER BY a.pinned DESC, a.created_at DESC
+	`
+
+	rows, err := s.DB.Query(query, org_id, user_id, pq.Array(roles))
+	if err != nil {
+		s.Logger.Error("failed to get announcements for user", "error", err, "user_id", user_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var announcements []Announcement
+	for rows.Next() {
+		var announcement Announcement
+		var authorID uuid.NullUUID
+		var targetRoles pq.StringArray
+		var expiresAt, readAt sql.NullTime
+
+		err := rows.Scan(
+			&announcement.ID,
+			&announcement.OrganizationID,
+			&authorID,
+			&announcement.AuthorName,
+			&announcement.Title,
+			&announcement.Message,
+			&announcement.Pinned,
+			&targetRoles,
+			&expiresAt,
+			&announcement.CreatedAt,
+			&readAt,
+		)
+		if err != nil {
+			s.Logger.Error("failed to scan announcement row", "error", err)
+			return nil, err
+		}
+		setAnnouncementNullables(&announcement, authorID, targetRoles, expiresAt)
+		if readAt.Valid {
+			announcement.ReadAt = &readAt.Time
+		}
+		announcements = append(announcements, announcement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return announcements, nil
+}
+
+// DeleteAnnouncement removes an announcement and its read receipts
+func (s *PostgresAnnouncementStore) DeleteAnnouncement(org_id uuid.UUID, id uuid.UUID) error {
+	query := `DELETE FROM announcements WHERE id = $1 AND organization_id = $2`
+	result, err := s.DB.Exec(query, id, org_id)
+	if err != nil {
+		s.Logger.Error("failed to delete announcement", "error", err, "id", id)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	s.Logger.Info("announcement deleted", "id", id, "org_id", org_id)
+	return nil
+}
+
+// MarkAnnouncementRead records a read receipt, keeping the time of the first read
+func (s *PostgresAnnouncementStore) MarkAnnouncementRead(id uuid.UUID, user_id uuid.UUID) error {
+	query := `
+		INSERT INTO announcement_reads (announcement_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT (announcement_id, user_id) DO NOTHING
+	`
+	if _, err := s.DB.Exec(query, id, user_id); err != nil {
+		s.Logger.Error("failed to mark announcement read", "error", err, "id", id, "user_id", user_id)
+		return err
+	}
+	return nil
+}
+
+// GetAnnouncementReads retrieves the read receipts of an announcement, oldest first
+func (s *PostgresAnnouncementStore) GetAnnouncementReads(id uuid.UUID) ([]AnnouncementRead, error) {
+	query := `
+		SELECT r.user_id, u.full_name, r.read_at
+		FROM announcement_reads r
+		INNER JOIN users u ON r.user_id = u.id
+		WHERE r.announcement_id = $1
+		ORDER BY r.read_at
+	`
+
+	rows, err := s.DB.Query(query, id)
+	if err != nil {
+		s.Logger.Error("failed to get announcement reads", "error", err, "id", id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reads []AnnouncementRead
+	for rows.Next() {
+		var read AnnouncementRead
+		if err := rows.Scan(&read.UserID, &read.FullName, &read.ReadAt); err != nil {
+			s.Logger.Error("failed to scan announcement read row", "error", err)
+			return nil, err
+		}
+		reads = append(reads, read)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return reads, nil
+}
+
+func scanAnnouncement(row *sql.Row) (*Announcement, error) {
+	var announcement Announcement
+	var authorID uuid.NullUUID
+	var targetRoles pq.StringArray
+	var expiresAt sql.NullTime
+
+	err := row.Scan(
+		&announcement.ID,
+		&announcement.OrganizationID,
+		&authorID,
+		&announcement.AuthorName,
+		&announcement.Title,
+		&announcement.Message,
+		&announcement.Pinned,
+		&targetRoles,
+		&expiresAt,
+		&announcement.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	setAnnouncementNullables(&announcement, authorID, targetRoles, expiresAt)
+	return &announcement, nil
+}
+
+func setAnnouncementNullables(announcement *Announcement, authorID uuid.NullUUID, targetRoles pq.StringArray, expiresAt sql.NullTime) {
+	if authorID.Valid {
+		announcement.AuthorID = &authorID.UUID
+	}
+	announcement.TargetRoles = []string(targetRoles)
+	if announcement.TargetRoles == nil {
+		announcement.TargetRoles = []string{}
+	}
+	if expiresAt.Valid {
+		announcement.ExpiresAt = &expiresAt.Time
+	}
+}
@@ -0,0 +1,202 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ApplicantSessionInterview = "interview"
+	ApplicantSessionTrial     = "trial"
+)
+
+// ApplicantSession is an interview or trial shift of an applicant with a manager. Sessions take up the
+// manager's time on the schedule grid but are not productive hours.
+type ApplicantSession struct {
+	ID             uuid.UUID  `json:"id"`
+	ApplicantID    uuid.UUID  `json:"applicant_id"`
+	ApplicantName  string     `json:"applicant_name"`
+	ApplicantEmail string     `json:"applicant_email"`
+	Kind           string     `json:"kind"`
+	ManagerID      uuid.UUID  `json:"manager_id"`
+	ManagerName    string     `json:"manager_name"`
+	Date           time.Time  `json:"session_date"`
+	StartTime      string     `json:"start_time"`
+	EndTime        string     `json:"end_time"`
+	Location       *string    `json:"location,omitempty"`
+	Notes          *string    `json:"notes,omitempty"`
+	CreatedBy      *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// ManagerConflict is a shift or another applicant session of a manager that overlaps a requested slot
+type ManagerConflict struct {
+	Kind      string    `json:"kind"` // shift, interview or trial
+	Date      time.Time `json:"date"`
+	StartTime string    `json:"start_time"`
+	EndTime   string    `json:"end_time"`
+}
+
+type ApplicantSessionStore interface {
+	CreateSession(org_id uuid.UUID, session *ApplicantSession) error
+	GetSessionsForApplicant(org_id uuid.UUID, applicant_id uuid.UUID) ([]ApplicantSession, error)
+	GetSessionsForSevenDays(org_id uuid.UUID) ([]ApplicantSession, error)
+	GetManagerConflicts(org_id uuid.UUID, manager_id uuid.UUID, date time.Time, start, end string) ([]ManagerConflict, error)
+}
+
+type PostgresApplicantSessionStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresApplicantSessionStore(DB *sql.DB, Logger *slog.Logger) *PostgresApplicantSessionStore {
+	return &PostgresApplicantSessionStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+const applicantSessionColumns = `s.id, s.applicant_id, a.full_name, a.email, s.kind, s.manager_id, u.full_name,
+	s.session_date, s.start_hour, s.end_hour, s.location, s.notes, s.created_by, s.created_at`
+
+const applicantSessionJoins = `
+	FROM applicant_sessions s
+	INNER JOIN job_applicants a ON a.id = s.applicant_id
+	INNER JOIN users u ON u.id = s.manager_id`
+
+func scanApplicantSession(row rowScanner) (*ApplicantSession, error) {
+	var session ApplicantSession
+	var createdBy uuid.NullUUID
+	if err := row.Scan(
+		&session.ID,
+		&session.ApplicantID,
+		&session.ApplicantName,
+		&session.ApplicantEmail,
+		&session.Kind,
+		&session.ManagerID,
+		&session.ManagerName,
+		&session.Date,
+		&session.StartTime,
+		&session.EndTime,
+		&session.Location,
+		&session.Notes,
+		&createdBy,
+		&session.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if createdBy.Valid {
+		session.CreatedBy = &createdBy.UUID
+	}
+	return &session, nil
+}
+
+func (s *PostgresApplicantSessionStore) querySessions(query string, args ...any) ([]ApplicantSession, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		s.Logger.Error("failed to get applicant sessions", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []ApplicantSession{}
+	for rows.Next() {
+		session, err := scanApplicantSession(rows)
+		if err != nil {
+			s.Logger.Error("failed to scan applicant session", "error", err)
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+	return sessions, rows.Err()
+}
+
+// CreateSession stores a new session, filling in its ID and creation time
+func (s *PostgresApplicantSessionStore) CreateSession(org_id uuid.UUID, session *ApplicantSession) error {
+	if session.ID == uuid.Nil {
+		session.ID = uuid.New()
+	}
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO applicant_sessions (id, organization_id, applicant_id, kind, manager_id, session_date, start_hour, end_hour, location, notes, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	_, err := s.DB.Exec(query,
+		session.ID,
+		org_id,
+		session.ApplicantID,
+		session.Kind,
+		session.ManagerID,
+		session.Date,
+		session.StartTime,
+		session.EndTime,
+		session.Location,
+		session.Notes,
+		session.CreatedBy,
+		session.CreatedAt,
+	)
+	if err != nil {
+		s.Logger.Error("failed to create applicant session", "error", err, "applicant_id", session.ApplicantID)
+		return err
+	}
+
+	s.Logger.Info("applicant session scheduled", "id", session.ID, "kind", session.Kind, "manager_id", session.ManagerID)
+	return nil
+}
+
+// GetSessionsForApplicant lists every session of an applicant in chronological order
+func (s *PostgresApplicantSessionStore) GetSessionsForApplicant(org_id uuid.UUID, applicant_id uuid.UUID) ([]ApplicantSession, error) {
+	query := `SELECT ` + applicantSessionColumns + applicantSessionJoins + `
+		WHERE s.organization_id = $1 AND s.applicant_id = $2
+		ORDER BY s.session_date, s.start_hour
+	`
+	return s.querySessions(query, org_id, applicant_id)
+}
+
+// GetSessionsForSevenDays lists the sessions of the organization in the same 7 day window as the schedule
+func (s *PostgresApplicantSessionStore) GetSessionsForSevenDays(org_id uuid.UUID) ([]ApplicantSession, error) {
+	query := `SELECT ` + applicantSessionColumns + applicantSessionJoins + `
+		WHERE s.organization_id = $1
+			AND s.session_date >= CURRENT_DATE
+			AND s.session_date < CURRENT_DATE + INTERVAL '7 days'
+		ORDER BY s.session_date, s.start_hour
+	`
+	return s.querySessions(query, org_id)
+}
+
+// GetManagerConflicts lists the shifts and sessions of a manager overlapping the start to end slot of a day
+func (s *PostgresApplicantSessionStore) GetManagerConflicts(org_id uuid.UUID, manager_id uuid.UUID, date time.Time, start, end string) ([]ManagerConflict, error) {
+	query := `
+		SELECT 'shift', schedule_date, start_hour, end_hour
+		FROM schedules
+		WHERE employee_id = $1 AND schedule_date = $3 AND start_hour < $5 AND end_hour > $4
+		UNION ALL
+		SELECT kind, session_date, start_hour, end_hour
+		FROM applicant_sessions
+		WHERE manager_id = $1 AND organization_id = $2 AND session_date = $3 AND start_hour < $5 AND end_hour > $4
+		ORDER BY 3
+	`
+	rows, err := s.DB.Query(query, manager_id, org_id, date, start, end)
+	if err != nil {
+		s.Logger.Error("failed to check manager conflicts", "error", err, "manager_id", manager_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	conflicts := []ManagerConflict{}
+	for rows.Next() {
+		var conflict ManagerConflict
+		if err := rows.Scan(&conflict.Kind, &conflict.Date, &conflict.StartTime, &conflict.EndTime); err != nil {
+			s.Logger.Error("failed to scan manager conflict", "error", err)
+			return nil, err
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts, rows.Err()
+}
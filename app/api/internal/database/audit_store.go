@@ -0,0 +1,127 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit log actions
+const (
+	AuditLoginFailed     = "login_failed"
+	AuditAccountLocked   = "account_locked"
+	AuditAccountUnlocked = "account_unlocked"
+	AuditNewDeviceLogin  = "new_device_login"
+	AuditStepUp          = "step_up"
+	AuditTOTPEnabled     = "totp_enabled"
+	AuditTOTPDisabled    = "totp_disabled"
+)
+
+// AuditEvent records a security relevant action in an organization. ActorID is the user who acted,
+// TargetUserID the user the action was about. Either may be empty.
+type AuditEvent struct {
+	ID             uuid.UUID      `json:"id"`
+	OrganizationID uuid.UUID      `json:"organization_id"`
+	ActorID        *uuid.UUID     `json:"actor_id,omitempty"`
+	TargetUserID   *uuid.UUID     `json:"target_user_id,omitempty"`
+	Action         string         `json:"action"`
+	Details        map[string]any `json:"details"`
+	IPAddress      string         `json:"ip_address"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+type AuditStore interface {
+	RecordEvent(event *AuditEvent) error
+	GetEvents(orgID uuid.UUID, action string, limit int) ([]AuditEvent, error)
+}
+
+type PostgresAuditStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresAuditStore(DB *sql.DB, Logger *slog.Logger) *PostgresAuditStore {
+	return &PostgresAuditStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// RecordEvent appends an event to the audit log
+func (s *PostgresAuditStore) RecordEvent(event *AuditEvent) error {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	if event.Details == nil {
+		event.Details = map[string]any{}
+	}
+
+	details, err := json.Marshal(event.Details)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO audit_log (id, organization_id, actor_id, target_user_id, action, details, ip_address, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err = s.DB.Exec(query, event.ID, event.OrganizationID, event.ActorID, event.TargetUserID, event.Action, details, event.IPAddress, event.CreatedAt)
+	if err != nil {
+		s.Logger.Error("failed to record audit event", "error", err, "action", event.Action, "org_id", event.OrganizationID)
+		return err
+	}
+	return nil
+}
+
+// GetEvents retrieves the latest events of the organization, newest first, optionally only those of one action
+func (s *PostgresAuditStore) GetEvents(orgID uuid.UUID, action string, limit int) ([]AuditEvent, error) {
+	query := `
+		SELECT id, organization_id, actor_id, target_user_id, action, details, ip_address, created_at
+		FROM audit_log
+		WHERE organization_id = $1 AND ($2 = '' OR action = $2)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+
+	rows, err := s.DB.Query(query, orgID, action, limit)
+	if err != nil {
+		s.Logger.Error("failed to get audit events", "error", err, "org_id", orgID)
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []AuditEvent{}
+	for rows.Next() {
+		var event AuditEvent
+		var details []byte
+		err := rows.Scan(
+			&event.ID,
+			&event.OrganizationID,
+			&event.ActorID,
+			&event.TargetUserID,
+			&event.Action,
+			&details,
+			&event.IPAddress,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			s.Logger.Error("failed to scan audit event row", "error", err)
+			return nil, err
+		}
+		if err := json.Unmarshal(details, &event.Details); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,136 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Blackout actions decide what happens to holiday requests overlapping the blackout
+const (
+	BlackoutReject = "reject" // the request is refused when submitted
+	BlackoutFlag   = "flag"   // the request is accepted but only an admin can approve it
+)
+
+// TimeOffBlackout is a peak period, e.g. Ramadan nights or December, when employees cannot take
+// holidays
+type TimeOffBlackout struct {
+	ID        uuid.UUID  `json:"id"`
+	StartDate time.Time  `json:"start_date"`
+	EndDate   time.Time  `json:"end_date"`
+	Reason    string     `json:"reason"`
+	Action    string     `json:"action"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type BlackoutStore interface {
+	CreateBlackout(org_id uuid.UUID, blackout *TimeOffBlackout) error
+	ListBlackouts(org_id uuid.UUID) ([]TimeOffBlackout, error)
+	DeleteBlackout(org_id, blackout_id uuid.UUID) error
+	GetOverlappingBlackouts(org_id uuid.UUID, from, to time.Time) ([]TimeOffBlackout, error)
+}
+
+type PostgresBlackoutStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresBlackoutStore(DB *sql.DB, Logger *slog.Logger) *PostgresBlackoutStore {
+	return &PostgresBlackoutStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+func (s *PostgresBlackoutStore) CreateBlackout(org_id uuid.UUID, blackout *TimeOffBlackout) error {
+	if blackout.Action == "" {
+		blackout.Action = BlackoutReject
+	}
+
+	query := `
+		INSERT INTO time_off_blackouts (organization_id, start_date, end_date, reason, action, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+	err := s.DB.QueryRow(query, org_id, blackout.StartDate, blackout.EndDate, blackout.Reason, blackout.Action, blackout.CreatedBy).
+		Scan(&blackout.ID, &blackout.CreatedAt)
+	if err != nil {
+		s.Logger.Error("failed to create time-off blackout", "error", err, "org_id", org_id)
+		return err
+	}
+
+	s.Logger.Info("time-off blackout created", "org_id", org_id, "blackout_id", blackout.ID)
+	return nil
+}
+
+// ListBlackouts lists the blackouts that have not ended yet in chronological order
+func (s *PostgresBlackoutStore) ListBlackouts(org_id uuid.UUID) ([]TimeOffBlackout, error) {
+	query := `
+		SELECT id, start_date, end_date, reason, action, created_by, created_at
+		FROM time_off_blackouts
+		WHERE organization_id = $1 AND end_date >= CURRENT_DATE
+		ORDER BY start_date, end_date
+	`
+	return s.queryBlackouts(query, org_id)
+}
+
+// DeleteBlackout lifts a blackout, returning sql.ErrNoRows if the organization has no such blackout
+func (s *PostgresBlackoutStore) DeleteBlackout(org_id, blackout_id uuid.UUID) error {
+	query := `DELETE FROM time_off_blackouts WHERE organization_id = $1 AND id = $2`
+	result, err := s.DB.Exec(query, org_id, blackout_id)
+	if err != nil {
+		s.Logger.Error("failed to delete time-off blackout", "error", err, "org_id", org_id, "blackout_id", blackout_id)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	s.Logger.Info("time-off blackout deleted", "org_id", org_id, "blackout_id", blackout_id)
+	return nil
+}
+
+// GetOverlappingBlackouts lists the blackouts sharing at least one day with from-to (inclusive),
+// rejecting blackouts first
+func (s *PostgresBlackoutStore) GetOverlappingBlackouts(org_id uuid.UUID, from, to time.Time) ([]TimeOffBlackout, error) {
+	query := `
+		SELECT id, start_date, end_date, reason, action, created_by, created_at
+		FROM time_off_blackouts
+		WHERE organization_id = $1 AND start_date <= $3 AND end_date >= $2
+		ORDER BY action = 'reject' DESC, start_date
+	`
+	return s.queryBlackouts(query, org_id, from, to)
+}
+
+func (s *PostgresBlackoutStore) queryBlackouts(query string, org_id uuid.UUID, args ...any) ([]TimeOffBlackout, error) {
+	rows, err := s.DB.Query(query, append([]any{org_id}, args...)...)
+	if err != nil {
+		s.Logger.Error("failed to get time-off blackouts", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	blackouts := []TimeOffBlackout{}
+	for rows.Next() {
+		var blackout TimeOffBlackout
+		var createdBy uuid.NullUUID
+		if err := rows.Scan(&blackout.ID, &blackout.StartDate, &blackout.EndDate, &blackout.Reason,
+			&blackout.Action, &createdBy, &blackout.CreatedAt); err != nil {
+			s.Logger.Error("failed to scan time-off blackout", "error", err)
+			return nil, err
+		}
+		if createdBy.Valid {
+			blackout.CreatedBy = &createdBy.UUID
+		}
+		blackouts = append(blackouts, blackout)
+	}
+	return blackouts, rows.Err()
+}
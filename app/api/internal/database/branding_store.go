@@ -0,0 +1,82 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrganizationLogo is the logo image of an organization, PNG or JPEG
+type OrganizationLogo struct {
+	ContentType string
+	Data        []byte
+	UpdatedAt   time.Time
+}
+
+// BrandingStore keeps the logos of organizations, their brand colors are the hex codes of the OrgStore
+type BrandingStore interface {
+	GetLogo(orgID uuid.UUID) (*OrganizationLogo, error)
+	GetLogoUpdatedAt(orgID uuid.UUID) (*time.Time, error)
+	SetLogo(orgID uuid.UUID, contentType string, data []byte) error
+}
+
+type PostgresBrandingStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresBrandingStore(DB *sql.DB, Logger *slog.Logger) *PostgresBrandingStore {
+	return &PostgresBrandingStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// GetLogo returns the logo of the organization, or sql.ErrNoRows when it has none
+func (s *PostgresBrandingStore) GetLogo(orgID uuid.UUID) (*OrganizationLogo, error) {
+	query := `SELECT content_type, data, updated_at FROM organization_logos WHERE organization_id = $1`
+
+	var logo OrganizationLogo
+	if err := s.DB.QueryRow(query, orgID).Scan(&logo.ContentType, &logo.Data, &logo.UpdatedAt); err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get organization logo", "error", err, "organization_id", orgID)
+		}
+		return nil, err
+	}
+	return &logo, nil
+}
+
+// GetLogoUpdatedAt returns when the logo of the organization last changed without loading it, nil when
+// it has none
+func (s *PostgresBrandingStore) GetLogoUpdatedAt(orgID uuid.UUID) (*time.Time, error) {
+	query := `SELECT updated_at FROM organization_logos WHERE organization_id = $1`
+
+	var updatedAt time.Time
+	if err := s.DB.QueryRow(query, orgID).Scan(&updatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		s.Logger.Error("failed to get organization logo", "error", err, "organization_id", orgID)
+		return nil, err
+	}
+	return &updatedAt, nil
+}
+
+// SetLogo replaces the logo of the organization
+func (s *PostgresBrandingStore) SetLogo(orgID uuid.UUID, contentType string, data []byte) error {
+	query := `
+		INSERT INTO organization_logos (organization_id, content_type, data, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			content_type = EXCLUDED.content_type,
+			data = EXCLUDED.data,
+			updated_at = EXCLUDED.updated_at
+	`
+	if _, err := s.DB.Exec(query, orgID, contentType, data, time.Now()); err != nil {
+		s.Logger.Error("failed to set organization logo", "error", err, "organization_id", orgID)
+		return err
+	}
+	return nil
+}
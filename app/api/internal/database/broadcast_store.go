@@ -0,0 +1,238 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// States of the delivery of a broadcast to a recipient
+const (
+	BroadcastDeliveryPending = "pending"
+	BroadcastDeliverySent    = "sent"
+	BroadcastDeliveryFailed  = "failed"
+)
+
+// BroadcastFilters pick the recipients of a broadcast. Members match when they match every filter set,
+// and a list filter when they match any of its values. Departments are the values of the `department`
+// custom field of the employees.
+type BroadcastFilters struct {
+	Roles             []string `json:"roles,omitempty"`
+	Departments       []string `json:"departments,omitempty"`
+	ScheduledTomorrow bool     `json:"scheduled_tomorrow,omitempty"`
+}
+
+// Broadcast is a message sent by an admin to a filtered group of the staff. The counts are those of its
+// deliveries, a recipient reached on two channels counts twice.
+type Broadcast struct {
+	ID             uuid.UUID        `json:"id"`
+	OrganizationID uuid.UUID        `json:"organization_id"`
+	SentBy         *uuid.UUID       `json:"sent_by"`
+	SentByName     string           `json:"sent_by_name"`
+	Title          string           `json:"title"`
+	Message        string           `json:"message"`
+	Channels       []string         `json:"channels"`
+	Filters        BroadcastFilters `json:"filters"`
+	Recipients     int              `json:"recipients"`
+	Pending        int              `json:"pending"`
+	Sent           int              `json:"sent"`
+	Failed         int              `json:"failed"`
+	CreatedAt      time.Time        `json:"created_at"`
+}
+
+// BroadcastDelivery is the message of a broadcast to one recipient on one channel
+type BroadcastDelivery struct {
+	UserID   uuid.UUID  `json:"user_id"`
+	FullName string     `json:"full_name"`
+	Channel  string     `json:"channel"`
+	Status   string     `json:"status"`
+	Error    *string    `json:"error"`
+	SentAt   *time.Time `json:"sent_at"`
+}
+
+type BroadcastStore interface {
+	CreateBroadcast(broadcast *Broadcast, deliveries []BroadcastDelivery) error
+	SetBroadcastDeliveryStatus(broadcast_id uuid.UUID, delivery BroadcastDelivery) error
+	GetBroadcasts(org_id uuid.UUID) ([]Broadcast, error)
+	GetBroadcast(org_id uuid.UUID, broadcast_id uuid.UUID) (*Broadcast, error)
+	GetBroadcastDeliveries(broadcast_id uuid.UUID) ([]BroadcastDelivery, error)
+}
+
+type PostgresBroadcastStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresBroadcastStore(DB *sql.DB, Logger *slog.Logger) *PostgresBroadcastStore {
+	return &PostgresBroadcastStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// CreateBroadcast stores the broadcast with its deliveries pending, filling in its ID, creation time and
+// counts
+func (s *PostgresBroadcastStore) CreateBroadcast(broadcast *Broadcast, deliveries []BroadcastDelivery) error {
+	filters, err := json.Marshal(broadcast.Filters)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		s.Logger.Error("failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO notification_broadcasts (organization_id, sent_by, title, message, channels, filters)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRow(query, broadcast.OrganizationID, broadcast.SentBy, broadcast.Title, broadcast.Message,
+		pq.Array(broadcast.Channels), filters).Scan(&broadcast.ID, &broadcast.CreatedAt)
+	if err != nil {
+		s.Logger.Error("failed to create broadcast", "error", err, "org_id", broadcast.OrganizationID)
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		_, err := tx.Exec(`INSERT INTO notification_broadcast_deliveries (broadcast_id, user_id, channel) VALUES ($1, $2, $3)`,
+			broadcast.ID, delivery.UserID, delivery.Channel)
+		if err != nil {
+			s.Logger.Error("failed to create broadcast delivery", "error", err, "broadcast_id", broadcast.ID, "user_id", delivery.UserID)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.Logger.Error("failed to commit broadcast", "error", err, "broadcast_id", broadcast.ID)
+		return err
+	}
+
+	broadcast.Recipients = len(deliveries)
+	broadcast.Pending = len(deliveries)
+	s.Logger.Info("broadcast created", "id", broadcast.ID, "org_id", broadcast.OrganizationID, "deliveries", len(deliveries))
+	return nil
+}
+
+// SetBroadcastDeliveryStatus records whether the message of the delivery was sent
+func (s *PostgresBroadcastStore) SetBroadcastDeliveryStatus(broadcast_id uuid.UUID, delivery BroadcastDelivery) error {
+	query := `
+		UPDATE notification_broadcast_deliveries
+		SET status = $4, error = $5, sent_at = CASE WHEN $4 = 'sent' THEN NOW() END
+		WHERE broadcast_id = $1 AND user_id = $2 AND channel = $3
+	`
+	_, err := s.DB.Exec(query, broadcast_id, delivery.UserID, delivery.Channel, delivery.Status, delivery.Error)
+	if err != nil {
+		s.Logger.Error("failed to set broadcast delivery status", "error", err, "broadcast_id", broadcast_id, "user_id", delivery.UserID)
+		return err
+	}
+	return nil
+}
+
+const broadcastColumns = `
+	b.id, b.organization_id, b.sent_by, COALESCE(u.full_name, ''), b.title, b.message, b.channels, b.filters, b.created_at,
+	COUNT(d.user_id),
+	COUNT(d.user_id) FILTER (WHERE d.status = 'pending'),
+	COUNT(d.user_id) FILTER (WHERE d.status = 'sent'),
+	COUNT(d.user_id) FILTER (WHERE d.status = 'failed')
+	FROM notification_broadcasts b
+	LEFT JOIN users u ON u.id = b.sent_by
+	LEFT JOIN notification_broadcast_deliveries d ON d.broadcast_id = b.id
+`
+
+func scanBroadcast(row rowScanner) (*Broadcast, error) {
+	var broadcast Broadcast
+	var sentBy uuid.NullUUID
+	var channels pq.StringArray
+	var filters []byte
+	if err := row.Scan(&broadcast.ID, &broadcast.OrganizationID, &sentBy, &broadcast.SentByName, &broadcast.Title, &broadcast.Message,
+		&channels, &filters, &broadcast.CreatedAt, &broadcast.Recipients, &broadcast.Pending, &broadcast.Sent, &broadcast.Failed); err != nil {
+		return nil, err
+	}
+	if sentBy.Valid {
+		broadcast.SentBy = &sentBy.UUID
+	}
+	broadcast.Channels = []string(channels)
+	if err := json.Unmarshal(filters, &broadcast.Filters); err != nil {
+		return nil, err
+	}
+	return &broadcast, nil
+}
+
+// GetBroadcasts lists the broadcasts of the organization with their delivery counts, newest first
+func (s *PostgresBroadcastStore) GetBroadcasts(org_id uuid.UUID) ([]Broadcast, error) {
+	query := `SELECT ` + broadcastColumns + `
+		WHERE b.organization_id = $1
+		GROUP BY b.id, u.full_name
+		ORDER BY b.created_at DESC
+	`
+	rows, err := s.DB.Query(query, org_id)
+	if err != nil {
+		s.Logger.Error("failed to get broadcasts", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	broadcasts := []Broadcast{}
+	for rows.Next() {
+		broadcast, err := scanBroadcast(rows)
+		if err != nil {
+			s.Logger.Error("failed to scan broadcast", "error", err)
+			return nil, err
+		}
+		broadcasts = append(broadcasts, *broadcast)
+	}
+	return broadcasts, rows.Err()
+}
+
+// GetBroadcast retrieves a broadcast of the organization with its delivery counts, returning
+// sql.ErrNoRows if it does not exist
+func (s *PostgresBroadcastStore) GetBroadcast(org_id uuid.UUID, broadcast_id uuid.UUID) (*Broadcast, error) {
+	query := `SELECT ` + broadcastColumns + `
+		WHERE b.id = $1 AND b.organization_id = $2
+		GROUP BY b.id, u.full_name
+	`
+	broadcast, err := scanBroadcast(s.DB.QueryRow(query, broadcast_id, org_id))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get broadcast", "error", err, "broadcast_id", broadcast_id)
+		}
+		return nil, err
+	}
+	return broadcast, nil
+}
+
+// GetBroadcastDeliveries lists the deliveries of a broadcast, failed ones first
+func (s *PostgresBroadcastStore) GetBroadcastDeliveries(broadcast_id uuid.UUID) ([]BroadcastDelivery, error) {
+	query := `
+		SELECT d.user_id, u.full_name, d.channel, d.status, d.error, d.sent_at
+		FROM notification_broadcast_deliveries d
+		JOIN users u ON u.id = d.user_id
+		WHERE d.broadcast_id = $1
+		ORDER BY d.status = 'failed' DESC, u.full_name, d.channel
+	`
+	rows, err := s.DB.Query(query, broadcast_id)
+	if err != nil {
+		s.Logger.Error("failed to get broadcast deliveries", "error", err, "broadcast_id", broadcast_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []BroadcastDelivery{}
+	for rows.Next() {
+		var delivery BroadcastDelivery
+		if err := rows.Scan(&delivery.UserID, &delivery.FullName, &delivery.Channel, &delivery.Status, &delivery.Error, &delivery.SentAt); err != nil {
+			s.Logger.Error("failed to scan broadcast delivery", "error", err)
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
@@ -4,10 +4,18 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// Channels a campaign can run on
+const (
+	CampaignChannelInStore          = "in_store"
+	CampaignChannelApp              = "app"
+	CampaignChannelDeliveryPlatform = "delivery_platform"
+)
+
 type Campaign struct {
 	ID              uuid.UUID `json:"id"`
 	Name            string    `json:"name"`
@@ -16,6 +24,26 @@ type Campaign struct {
 	EndTime         string    `json:"end_time"`
 	ItemsIncluded   []Item    `json:"items_included,omitempty"`
 	DiscountPercent *float64  `json:"discount"`
+	Channel         string    `json:"channel"`
+	RedemptionCode  *string   `json:"redemption_code,omitempty"`
+}
+
+// ChannelPerformance sums the campaigns of a channel and the orders that redeemed them
+type ChannelPerformance struct {
+	Channel     string  `json:"channel"`
+	Campaigns   int     `json:"campaigns"`
+	Redemptions int     `json:"redemptions"`
+	Revenue     float64 `json:"revenue"`
+	Discount    float64 `json:"discount"`
+}
+
+// CampaignPerformance sums the orders that redeemed a campaign. Revenue is net of the discount.
+type CampaignPerformance struct {
+	CampaignID  uuid.UUID `json:"campaign_id"`
+	Channel     string    `json:"channel"`
+	Redemptions int       `json:"redemptions"`
+	Revenue     float64   `json:"revenue"`
+	Discount    float64   `json:"discount"`
 }
 
 type CampaignStore interface {
@@ -24,6 +52,10 @@ type CampaignStore interface {
 	GetAllCampaigns(org_id uuid.UUID) ([]Campaign, error)
 	GetAllCampaignsFromLastWeek(org_ud uuid.UUID) ([]Campaign, error)
 	GetCampaignInsights(org_id uuid.UUID) ([]Insight, error)
+
+	RecordRedemption(org_id uuid.UUID, code string, order_id uuid.UUID, at time.Time) error
+	GetChannelPerformance(org_id uuid.UUID) ([]ChannelPerformance, error)
+	GetCampaignPerformance(org_id, campaign_id uuid.UUID) (*CampaignPerformance, error)
 }
 
 type PostgresCampaignStore struct {
@@ -48,15 +80,19 @@ func (pgcs *PostgresCampaignStore) StoreCampaign(org_id uuid.UUID, campaign Camp
 
 	// Insert campaign
 	query := `
-		INSERT INTO marketing_campaigns (id, organization_id, name, status, start_time_date, end_time_date, discount_percent)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO marketing_campaigns (id, organization_id, name, status, start_time_date, end_time_date, discount_percent, channel, redemption_code)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 	campaignID := campaign.ID
 	if campaignID == uuid.Nil {
 		campaignID = uuid.New()
 	}
+	channel := campaign.Channel
+	if channel == "" {
+		channel = CampaignChannelInStore
+	}
 
-	_, err = tx.Exec(query, campaignID, org_id, campaign.Name, campaign.Status, campaign.StartTime, campaign.EndTime, campaign.DiscountPercent)
+	_, err = tx.Exec(query, campaignID, org_id, campaign.Name, campaign.Status, campaign.StartTime, campaign.EndTime, campaign.DiscountPercent, channel, campaign.RedemptionCode)
 	if err != nil {
 		pgcs.Logger.Error("Failed to insert campaign", "error", err)
 		return err
@@ -100,7 +136,7 @@ func (pgcs *PostgresCampaignStore) StoreCampaignItems(org_id, campaign_id uuid.U
 
 func (pgcs *PostgresCampaignStore) GetAllCampaigns(org_id uuid.UUID) ([]Campaign, error) {
 	query := `
-		SELECT id, name, status, start_time_date, end_time_date, discount_percent
+		SELECT id, name, status, start_time_date, end_time_date, discount_percent, channel, redemption_code
 		FROM marketing_campaigns
 		WHERE organization_id = $1
 		ORDER BY start_time_date DESC
@@ -116,7 +152,7 @@ func (pgcs *PostgresCampaignStore) GetAllCampaigns(org_id uuid.UUID) ([]Campaign
 	var campaigns []Campaign
 	for rows.Next() {
 		var c Campaign
-		err := rows.Scan(&c.ID, &c.Name, &c.Status, &c.StartTime, &c.EndTime, &c.DiscountPercent)
+		err := rows.Scan(&c.ID, &c.Name, &c.Status, &c.StartTime, &c.EndTime, &c.DiscountPercent, &c.Channel, &c.RedemptionCode)
 		if err != nil {
 			pgcs.Logger.Error("Failed to scan campaign", "error", err)
 			return nil, err
@@ -138,7 +174,7 @@ func (pgcs *PostgresCampaignStore) GetAllCampaigns(org_id uuid.UUID) ([]Campaign
 
 func (pgcs *PostgresCampaignStore) GetAllCampaignsFromLastWeek(org_id uuid.UUID) ([]Campaign, error) {
 	query := `
-		SELECT id, name, status, start_time_date, end_time_date, discount_percent
+		SELECT id, name, status, start_time_date, end_time_date, discount_percent, channel, redemption_code
 		FROM marketing_campaigns
 		WHERE organization_id = $1
 		AND start_time_date >= NOW() - INTERVAL '7 days'
@@ -155,7 +191,7 @@ func (pgcs *PostgresCampaignStore) GetAllCampaignsFromLastWeek(org_id uuid.UUID)
 	var campaigns []Campaign
 	for rows.Next() {
 		var c Campaign
-		err := rows.Scan(&c.ID, &c.Name, &c.Status, &c.StartTime, &c.EndTime, &c.DiscountPercent)
+		err := rows.Scan(&c.ID, &c.Name, &c.Status, &c.StartTime, &c.EndTime, &c.DiscountPercent, &c.Channel, &c.RedemptionCode)
 		if err != nil {
 			pgcs.Logger.Error("Failed to scan campaign", "error", err)
 			return nil, err
@@ -255,6 +291,80 @@ func (pgcs *PostgresCampaignStore) GetCampaignInsights(org_id uuid.UUID) ([]Insi
 	return insights, nil
 }
 
+// RecordRedemption links an order to the campaign whose redemption code it used. Codes are matched
+// case-insensitively against campaigns running at the order time, sql.ErrNoRows means no campaign matched.
+func (pgcs *PostgresCampaignStore) RecordRedemption(org_id uuid.UUID, code string, order_id uuid.UUID, at time.Time) error {
+	query := `
+		INSERT INTO campaign_redemptions (campaign_id, order_id, organization_id, redeemed_at)
+		SELECT id, $3, organization_id, $4
+		FROM marketing_campaigns
+		WHERE organization_id = $1 AND UPPER(redemption_code) = UPPER($2)
+		AND start_time_date <= $4 AND end_time_date >= $4
+		ON CONFLICT DO NOTHING
+	`
+	result, err := pgcs.DB.Exec(query, org_id, code, order_id, at)
+	if err != nil {
+		pgcs.Logger.Error("Failed to record redemption", "error", err, "order_id", order_id)
+		return err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetChannelPerformance sums campaigns and their redemptions per channel
+func (pgcs *PostgresCampaignStore) GetChannelPerformance(org_id uuid.UUID) ([]ChannelPerformance, error) {
+	query := `
+		SELECT mc.channel, COUNT(DISTINCT mc.id), COUNT(o.id),
+			COALESCE(SUM(o.total_amount - o.discount_amount), 0), COALESCE(SUM(o.discount_amount), 0)
+		FROM marketing_campaigns mc
+		LEFT JOIN campaign_redemptions cr ON cr.campaign_id = mc.id
+		LEFT JOIN orders o ON o.id = cr.order_id
+		WHERE mc.organization_id = $1
+		GROUP BY mc.channel
+		ORDER BY mc.channel
+	`
+	rows, err := pgcs.DB.Query(query, org_id)
+	if err != nil {
+		pgcs.Logger.Error("Failed to get channel performance", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	channels := []ChannelPerformance{}
+	for rows.Next() {
+		var p ChannelPerformance
+		if err := rows.Scan(&p.Channel, &p.Campaigns, &p.Redemptions, &p.Revenue, &p.Discount); err != nil {
+			pgcs.Logger.Error("Failed to scan channel performance", "error", err)
+			return nil, err
+		}
+		channels = append(channels, p)
+	}
+	return channels, rows.Err()
+}
+
+// GetCampaignPerformance sums the redemptions of a campaign, or returns sql.ErrNoRows when the campaign does not exist
+func (pgcs *PostgresCampaignStore) GetCampaignPerformance(org_id, campaign_id uuid.UUID) (*CampaignPerformance, error) {
+	query := `
+		SELECT mc.channel, COUNT(o.id),
+			COALESCE(SUM(o.total_amount - o.discount_amount), 0), COALESCE(SUM(o.discount_amount), 0)
+		FROM marketing_campaigns mc
+		LEFT JOIN campaign_redemptions cr ON cr.campaign_id = mc.id
+		LEFT JOIN orders o ON o.id = cr.order_id
+		WHERE mc.organization_id = $1 AND mc.id = $2
+		GROUP BY mc.channel
+	`
+	p := CampaignPerformance{CampaignID: campaign_id}
+	if err := pgcs.DB.QueryRow(query, org_id, campaign_id).Scan(&p.Channel, &p.Redemptions, &p.Revenue, &p.Discount); err != nil {
+		if err != sql.ErrNoRows {
+			pgcs.Logger.Error("Failed to get campaign performance", "error", err, "campaign_id", campaign_id)
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
 // getCampaignItems fetches all items associated with a campaign
 func (pgcs *PostgresCampaignStore) getCampaignItems(campaignID uuid.UUID) ([]Item, error) {
 	query := `
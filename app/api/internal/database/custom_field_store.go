@@ -0,0 +1,234 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Entities custom fields can be added to
+const (
+	CustomFieldEmployee = "employee"
+	CustomFieldItem     = "item"
+	CustomFieldOrder    = "order"
+)
+
+// Types of custom fields, deciding which values they accept
+const (
+	CustomFieldText    = "text"
+	CustomFieldNumber  = "number"
+	CustomFieldBoolean = "boolean"
+	CustomFieldDate    = "date"
+	CustomFieldSelect  = "select"
+)
+
+// ErrCustomFieldExists is returned when the entity type already has a field with the same key
+var ErrCustomFieldExists = errors.New("custom field already exists")
+
+// customFieldTables are the tables holding the entities of each type, to check that an entity belongs
+// to the organization before setting its values
+var customFieldTables = map[string]string{
+	CustomFieldEmployee: "users",
+	CustomFieldItem:     "items",
+	CustomFieldOrder:    "orders",
+}
+
+// CustomFieldDefinition is an attribute an organization adds to its employees, items or orders.
+// Options lists the accepted values of select fields.
+type CustomFieldDefinition struct {
+	ID         uuid.UUID `json:"id"`
+	EntityType string    `json:"entity_type"`
+	Key        string    `json:"key"`
+	Label      string    `json:"label"`
+	FieldType  string    `json:"field_type"`
+	Options    []string  `json:"options"`
+	Required   bool      `json:"required"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type CustomFieldStore interface {
+	ListFieldDefinitions(org_id uuid.UUID, entityType string) ([]CustomFieldDefinition, error)
+	CreateFieldDefinition(org_id uuid.UUID, definition *CustomFieldDefinition) error
+	DeleteFieldDefinition(org_id, definition_id uuid.UUID) error
+	GetCustomFieldValues(org_id uuid.UUID, entityType string, entity_ids []uuid.UUID) (map[uuid.UUID]map[string]any, error)
+	SetCustomFieldValues(org_id uuid.UUID, entityType string, entity_id uuid.UUID, values map[string]any) error
+}
+
+type PostgresCustomFieldStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresCustomFieldStore(DB *sql.DB, Logger *slog.Logger) *PostgresCustomFieldStore {
+	return &PostgresCustomFieldStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// ListFieldDefinitions lists the custom fields of the entity type in the order they were added, or of
+// every entity type when entityType is empty
+func (s *PostgresCustomFieldStore) ListFieldDefinitions(org_id uuid.UUID, entityType string) ([]CustomFieldDefinition, error) {
+	query := `
+		SELECT id, entity_type, key, label, field_type, options, required, created_at
+		FROM custom_field_definitions
+		WHERE organization_id = $1 AND ($2 = '' OR entity_type = $2)
+		ORDER BY entity_type, created_at, key
+	`
+	rows, err := s.DB.Query(query, org_id, entityType)
+	if err != nil {
+		s.Logger.Error("failed to get custom field definitions", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	definitions := []CustomFieldDefinition{}
+	for rows.Next() {
+		var definition CustomFieldDefinition
+		var options pq.StringArray
+		if err := rows.Scan(&definition.ID, &definition.EntityType, &definition.Key, &definition.Label,
+			&definition.FieldType, &options, &definition.Required, &definition.CreatedAt); err != nil {
+			s.Logger.Error("failed to scan custom field definition", "error", err)
+			return nil, err
+		}
+		definition.Options = options
+		definitions = append(definitions, definition)
+	}
+	return definitions, rows.Err()
+}
+
+// CreateFieldDefinition adds a custom field, returning ErrCustomFieldExists if the entity type already
+// has a field with the same key
+func (s *PostgresCustomFieldStore) CreateFieldDefinition(org_id uuid.UUID, definition *CustomFieldDefinition) error {
+	if definition.Options == nil {
+		definition.Options = []string{}
+	}
+
+	query := `
+		INSERT INTO custom_field_definitions (organization_id, entity_type, key, label, field_type, options, required)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (organization_id, entity_type, key) DO NOTHING
+		RETURNING id, created_at
+	`
+	err := s.DB.QueryRow(query, org_id, definition.EntityType, definition.Key, definition.Label, definition.FieldType,
+		pq.Array(definition.Options), definition.Required).Scan(&definition.ID, &definition.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrCustomFieldExists
+	}
+	if err != nil {
+		s.Logger.Error("failed to create custom field definition", "error", err, "org_id", org_id)
+		return err
+	}
+
+	s.Logger.Info("custom field created", "org_id", org_id, "entity_type", definition.EntityType, "key", definition.Key)
+	return nil
+}
+
+// DeleteFieldDefinition removes a custom field and its values, returning sql.ErrNoRows if the
+// organization has no such field
+func (s *PostgresCustomFieldStore) DeleteFieldDefinition(org_id, definition_id uuid.UUID) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		s.Logger.Error("failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var entityType, key string
+	err = tx.QueryRow(`DELETE FROM custom_field_definitions WHERE organization_id = $1 AND id = $2 RETURNING entity_type, key`,
+		org_id, definition_id).Scan(&entityType, &key)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.Logger.Error("failed to delete custom field definition", "error", err, "org_id", org_id)
+		}
+		return err
+	}
+
+	query := `UPDATE custom_field_values SET "values" = "values" - $3 WHERE organization_id = $1 AND entity_type = $2 AND "values" ? $3`
+	if _, err := tx.Exec(query, org_id, entityType, key); err != nil {
+		s.Logger.Error("failed to delete custom field values", "error", err, "org_id", org_id, "key", key)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.Logger.Info("custom field deleted", "org_id", org_id, "entity_type", entityType, "key", key)
+	return nil
+}
+
+// GetCustomFieldValues returns the values of the given entities keyed by entity. Entities without
+// values are left out.
+func (s *PostgresCustomFieldStore) GetCustomFieldValues(org_id uuid.UUID, entityType string, entity_ids []uuid.UUID) (map[uuid.UUID]map[string]any, error) {
+	query := `
+		SELECT entity_id, "values"
+		FROM custom_field_values
+		WHERE organization_id = $1 AND entity_type = $2 AND entity_id = ANY($3::uuid[])
+	`
+	ids := make([]string, len(entity_ids))
+	for i, id := range entity_ids {
+		ids[i] = id.String()
+	}
+	rows, err := s.DB.Query(query, org_id, entityType, pq.Array(ids))
+	if err != nil {
+		s.Logger.Error("failed to get custom field values", "error", err, "org_id", org_id, "entity_type", entityType)
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := map[uuid.UUID]map[string]any{}
+	for rows.Next() {
+		var entityID uuid.UUID
+		var raw []byte
+		if err := rows.Scan(&entityID, &raw); err != nil {
+			s.Logger.Error("failed to scan custom field values", "error", err)
+			return nil, err
+		}
+		entityValues := map[string]any{}
+		if err := json.Unmarshal(raw, &entityValues); err != nil {
+			return nil, err
+		}
+		values[entityID] = entityValues
+	}
+	return values, rows.Err()
+}
+
+// SetCustomFieldValues replaces the values of an entity, returning sql.ErrNoRows if the organization
+// has no such entity
+func (s *PostgresCustomFieldStore) SetCustomFieldValues(org_id uuid.UUID, entityType string, entity_id uuid.UUID, values map[string]any) error {
+	table, ok := customFieldTables[entityType]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO custom_field_values (organization_id, entity_type, entity_id, "values", updated_at)
+		SELECT $1, $2, $3, $4, NOW()
+		WHERE EXISTS (SELECT 1 FROM ` + table + ` WHERE id = $3 AND organization_id = $1)
+		ON CONFLICT (organization_id, entity_type, entity_id)
+		DO UPDATE SET "values" = EXCLUDED."values", updated_at = EXCLUDED.updated_at
+	`
+	result, err := s.DB.Exec(query, org_id, entityType, entity_id, raw)
+	if err != nil {
+		s.Logger.Error("failed to set custom field values", "error", err, "org_id", org_id, "entity_id", entity_id)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"io/fs"
 	"log"
+	"log/slog"
 	"os"
 	"strconv"
 	"time"
 
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/clockwise/clockwise/backend/internal/config"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/pressly/goose/v3"
 )
@@ -30,7 +33,8 @@ type Service interface {
 }
 
 type service struct {
-	db *sql.DB
+	db          *sql.DB
+	slowQueries *SlowQueryLog
 }
 
 var (
@@ -59,6 +63,41 @@ func New() Service {
 	return dbInstance
 }
 
+// NewWithPasswordSource connects with the password returned by passwordSource instead of POSTGRES_PASSWORD.
+// It is called for every new connection, so a rotated password is used without restarting the API.
+// The pool is sized by pool and its queries slower than pool.SlowQueryThreshold are logged.
+func NewWithPasswordSource(passwordSource func(ctx context.Context) (string, error), pool config.DatabaseConfig, logger *slog.Logger) Service {
+	// Reuse Connection
+	if dbInstance != nil {
+		return dbInstance
+	}
+	connStr := fmt.Sprintf("postgres://%s@%s:%s/%s?sslmode=disable&search_path=%s", username, host, port, database, schema)
+	connConfig, err := pgx.ParseConfig(connStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	connector := stdlib.GetConnector(*connConfig, stdlib.OptionBeforeConnect(func(ctx context.Context, cc *pgx.ConnConfig) error {
+		password, err := passwordSource(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to resolve database password: %w", err)
+		}
+		cc.Password = password
+		return nil
+	}))
+	slowQueries := &SlowQueryLog{Threshold: pool.SlowQueryThreshold, Logger: logger}
+	db := sql.OpenDB(slowQueries.Connector(connector))
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+
+	dbInstance = &service{
+		db:          db,
+		slowQueries: slowQueries,
+	}
+	return dbInstance
+}
+
 // Health checks the health of the database connection by pinging the database.
 // It returns a map with keys indicating various health statistics.
 func (s *service) Health() map[string]string {
@@ -82,6 +121,7 @@ func (s *service) Health() map[string]string {
 
 	// Get database stats (like open connections, in use, idle, etc.)
 	dbStats := s.db.Stats()
+	stats["max_open_connections"] = strconv.Itoa(dbStats.MaxOpenConnections)
 	stats["open_connections"] = strconv.Itoa(dbStats.OpenConnections)
 	stats["in_use"] = strconv.Itoa(dbStats.InUse)
 	stats["idle"] = strconv.Itoa(dbStats.Idle)
@@ -89,6 +129,12 @@ func (s *service) Health() map[string]string {
 	stats["wait_duration"] = dbStats.WaitDuration.String()
 	stats["max_idle_closed"] = strconv.FormatInt(dbStats.MaxIdleClosed, 10)
 	stats["max_lifetime_closed"] = strconv.FormatInt(dbStats.MaxLifetimeClosed, 10)
+	if s.slowQueries != nil {
+		slow := s.slowQueries.Stats()
+		stats["slow_query_threshold"] = s.slowQueries.Threshold.String()
+		stats["slow_queries"] = strconv.FormatInt(slow.Count, 10)
+		stats["slowest_query"] = slow.Slowest.String()
+	}
 
 	// Evaluate stats to provide a health message
 	if dbStats.OpenConnections > 40 { // Assuming 50 is the max for this example
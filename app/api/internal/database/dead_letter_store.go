@@ -0,0 +1,251 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// How a dead-lettered payload was received
+const (
+	DeadLetterViaWebhook = "webhook"
+	DeadLetterViaPoll    = "poll"
+)
+
+// Statuses of a dead letter
+const (
+	DeadLetterPending  = "pending"
+	DeadLetterReplayed = "replayed"
+)
+
+// DeadLetter is a payload received from an ingestion source, a delivery platform, that could not be read.
+// Error is why it was rejected, or why its last replay failed. Payload is left out of lists.
+type DeadLetter struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganizationID uuid.UUID  `json:"-"`
+	Source         string     `json:"source"`
+	Via            string     `json:"via"`
+	Payload        string     `json:"payload,omitempty"`
+	Error          string     `json:"error"`
+	Status         string     `json:"status"`
+	Replays        int        `json:"replays"`
+	EditedBy       *uuid.UUID `json:"edited_by,omitempty"`
+	EditedAt       *time.Time `json:"edited_at,omitempty"`
+	ReplayedBy     *uuid.UUID `json:"replayed_by,omitempty"`
+	ReplayedAt     *time.Time `json:"replayed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// DeadLetterFilter narrows a list of dead letters. Empty fields match every dead letter.
+type DeadLetterFilter struct {
+	Source string
+	Status string
+	Limit  int
+}
+
+// IngestionFailureRate compares the payloads of a source that were dead-lettered with those ingested.
+// Ingested payloads are those that stored at least one order or update.
+type IngestionFailureRate struct {
+	Source      string  `json:"source"`
+	Received    int     `json:"received"`
+	Failed      int     `json:"failed"`
+	FailureRate float64 `json:"failure_rate_percent"`
+}
+
+type DeadLetterStore interface {
+	CreateDeadLetter(org_id uuid.UUID, letter *DeadLetter) error
+	ListDeadLetters(org_id uuid.UUID, filter DeadLetterFilter) ([]DeadLetter, error)
+	GetDeadLetter(org_id uuid.UUID, id uuid.UUID) (*DeadLetter, error)
+	UpdatePayload(org_id uuid.UUID, id uuid.UUID, payload string, editedBy uuid.UUID, at time.Time) (*DeadLetter, error)
+	MarkReplayed(org_id uuid.UUID, id uuid.UUID, replayedBy uuid.UUID, at time.Time) (*DeadLetter, error)
+	RecordFailedReplay(org_id uuid.UUID, id uuid.UUID, reason string) (*DeadLetter, error)
+	GetFailureRates(org_id uuid.UUID, sources []string, since time.Time) ([]IngestionFailureRate, error)
+}
+
+type PostgresDeadLetterStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresDeadLetterStore(DB *sql.DB, Logger *slog.Logger) *PostgresDeadLetterStore {
+	return &PostgresDeadLetterStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+const deadLetterColumns = `id, organization_id, source, via, payload, error, status, replays, edited_by, edited_at, replayed_by, replayed_at, created_at`
+
+func scanDeadLetter(row rowScanner) (*DeadLetter, error) {
+	var letter DeadLetter
+	if err := row.Scan(
+		&letter.ID,
+		&letter.OrganizationID,
+		&letter.Source,
+		&letter.Via,
+		&letter.Payload,
+		&letter.Error,
+		&letter.Status,
+		&letter.Replays,
+		&letter.EditedBy,
+		&letter.EditedAt,
+		&letter.ReplayedBy,
+		&letter.ReplayedAt,
+		&letter.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &letter, nil
+}
+
+// CreateDeadLetter stores a rejected payload as pending, filling in its ID and creation time
+func (s *PostgresDeadLetterStore) CreateDeadLetter(org_id uuid.UUID, letter *DeadLetter) error {
+	if letter.ID == uuid.Nil {
+		letter.ID = uuid.New()
+	}
+	if letter.CreatedAt.IsZero() {
+		letter.CreatedAt = time.Now()
+	}
+	letter.OrganizationID = org_id
+	letter.Status = DeadLetterPending
+
+	query := `
+		INSERT INTO ingestion_dead_letters (id, organization_id, source, via, payload, error, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := s.DB.Exec(query, letter.ID, org_id, letter.Source, letter.Via, letter.Payload, letter.Error, letter.Status, letter.CreatedAt)
+	if err != nil {
+		s.Logger.Error("failed to create dead letter", "error", err, "org_id", org_id, "source", letter.Source)
+		return err
+	}
+	return nil
+}
+
+// ListDeadLetters returns the dead letters matching the filter without their payloads, latest first
+func (s *PostgresDeadLetterStore) ListDeadLetters(org_id uuid.UUID, filter DeadLetterFilter) ([]DeadLetter, error) {
+	query := `
+		SELECT id, organization_id, source, via, '', error, status, replays, edited_by, edited_at, replayed_by, replayed_at, created_at
+		FROM ingestion_dead_letters
+		WHERE organization_id = $1 AND ($2 = '' OR source = $2) AND ($3 = '' OR status = $3)
+		ORDER BY created_at DESC, id
+		LIMIT $4
+	`
+	rows, err := s.DB.Query(query, org_id, filter.Source, filter.Status, filter.Limit)
+	if err != nil {
+		s.Logger.Error("failed to list dead letters", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	letters := []DeadLetter{}
+	for rows.Next() {
+		letter, err := scanDeadLetter(rows)
+		if err != nil {
+			s.Logger.Error("failed to scan dead letter", "error", err)
+			return nil, err
+		}
+		letters = append(letters, *letter)
+	}
+	return letters, rows.Err()
+}
+
+// GetDeadLetter returns a dead letter with its payload, sql.ErrNoRows when it is not the organization's
+func (s *PostgresDeadLetterStore) GetDeadLetter(org_id uuid.UUID, id uuid.UUID) (*DeadLetter, error) {
+	query := `SELECT ` + deadLetterColumns + ` FROM ingestion_dead_letters WHERE organization_id = $1 AND id = $2`
+	letter, err := scanDeadLetter(s.DB.QueryRow(query, org_id, id))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get dead letter", "error", err, "org_id", org_id, "id", id)
+		}
+		return nil, err
+	}
+	return letter, nil
+}
+
+// UpdatePayload replaces the payload of a pending dead letter, sql.ErrNoRows when there is no pending one
+func (s *PostgresDeadLetterStore) UpdatePayload(org_id uuid.UUID, id uuid.UUID, payload string, editedBy uuid.UUID, at time.Time) (*DeadLetter, error) {
+	query := `
+		UPDATE ingestion_dead_letters SET payload = $3, edited_by = $4, edited_at = $5
+		WHERE organization_id = $1 AND id = $2 AND status = 'pending'
+		RETURNING ` + deadLetterColumns
+	letter, err := scanDeadLetter(s.DB.QueryRow(query, org_id, id, payload, editedBy, at))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to update dead letter payload", "error", err, "org_id", org_id, "id", id)
+		}
+		return nil, err
+	}
+	return letter, nil
+}
+
+// MarkReplayed records a successful replay of a pending dead letter, sql.ErrNoRows when there is no
+// pending one
+func (s *PostgresDeadLetterStore) MarkReplayed(org_id uuid.UUID, id uuid.UUID, replayedBy uuid.UUID, at time.Time) (*DeadLetter, error) {
+	query := `
+		UPDATE ingestion_dead_letters SET status = 'replayed', replays = replays + 1, replayed_by = $3, replayed_at = $4
+		WHERE organization_id = $1 AND id = $2 AND status = 'pending'
+		RETURNING ` + deadLetterColumns
+	letter, err := scanDeadLetter(s.DB.QueryRow(query, org_id, id, replayedBy, at))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to mark dead letter replayed", "error", err, "org_id", org_id, "id", id)
+		}
+		return nil, err
+	}
+	return letter, nil
+}
+
+// RecordFailedReplay keeps a dead letter pending with the reason its replay failed, sql.ErrNoRows when
+// there is no pending one
+func (s *PostgresDeadLetterStore) RecordFailedReplay(org_id uuid.UUID, id uuid.UUID, reason string) (*DeadLetter, error) {
+	query := `
+		UPDATE ingestion_dead_letters SET error = $3, replays = replays + 1
+		WHERE organization_id = $1 AND id = $2 AND status = 'pending'
+		RETURNING ` + deadLetterColumns
+	letter, err := scanDeadLetter(s.DB.QueryRow(query, org_id, id, reason))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to record failed replay", "error", err, "org_id", org_id, "id", id)
+		}
+		return nil, err
+	}
+	return letter, nil
+}
+
+// GetFailureRates counts, per source, the payloads dead-lettered since since and those ingested, read from
+// the ingestion status events. Only the given sources are counted, those with nothing received are left out.
+func (s *PostgresDeadLetterStore) GetFailureRates(org_id uuid.UUID, sources []string, since time.Time) ([]IngestionFailureRate, error) {
+	query := `
+		SELECT source, COUNT(*), COUNT(*) FILTER (WHERE failed)
+		FROM (
+			SELECT source, FALSE AS failed FROM org_status_events
+			WHERE organization_id = $1 AND kind = $2 AND NOT failed AND created_at >= $3 AND source = ANY($4)
+			UNION ALL
+			SELECT source, TRUE FROM ingestion_dead_letters
+			WHERE organization_id = $1 AND created_at >= $3 AND source = ANY($4)
+		) received
+		GROUP BY source
+		ORDER BY source
+	`
+	rows, err := s.DB.Query(query, org_id, StatusEventIngestion, since, pq.Array(sources))
+	if err != nil {
+		s.Logger.Error("failed to get ingestion failure rates", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []IngestionFailureRate{}
+	for rows.Next() {
+		var rate IngestionFailureRate
+		if err := rows.Scan(&rate.Source, &rate.Received, &rate.Failed); err != nil {
+			s.Logger.Error("failed to scan ingestion failure rate", "error", err)
+			return nil, err
+		}
+		rate.FailureRate = percentOf(rate.Failed, rate.Received)
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
@@ -0,0 +1,217 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeliveryPlatformIntegration connects an organization to a delivery platform it sells on. Orders arrive
+// through webhooks signed with WebhookSecret or, when APIURL is set, are polled from the platform with
+// APIToken. The secret and token are never sent back to clients.
+type DeliveryPlatformIntegration struct {
+	OrganizationID uuid.UUID  `json:"-"`
+	Platform       string     `json:"platform"`
+	StoreID        string     `json:"store_id"`
+	WebhookSecret  string     `json:"-"`
+	APIURL         *string    `json:"api_url,omitempty"`
+	APIToken       *string    `json:"-"`
+	Enabled        bool       `json:"enabled"`
+	LastPolledAt   *time.Time `json:"last_polled_at,omitempty"`
+	LastEventAt    *time.Time `json:"last_event_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type DeliveryPlatformStore interface {
+	GetIntegrations(org_id uuid.UUID) ([]DeliveryPlatformIntegration, error)
+	GetIntegration(org_id uuid.UUID, platform string) (*DeliveryPlatformIntegration, error)
+	SaveIntegration(org_id uuid.UUID, integration *DeliveryPlatformIntegration) error
+	DeleteIntegration(org_id uuid.UUID, platform string) error
+	GetPolledIntegrations() ([]DeliveryPlatformIntegration, error)
+	MarkPolled(org_id uuid.UUID, platform string, at time.Time) error
+	MarkEventReceived(org_id uuid.UUID, platform string, at time.Time) error
+}
+
+// PostgresDeliveryPlatformStore seals the webhook secrets and API tokens with Cipher when it is set
+type PostgresDeliveryPlatformStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+	Cipher FieldCipher
+}
+
+func NewPostgresDeliveryPlatformStore(DB *sql.DB, Logger *slog.Logger, cipher FieldCipher) *PostgresDeliveryPlatformStore {
+	return &PostgresDeliveryPlatformStore{
+		DB:     DB,
+		Logger: Logger,
+		Cipher: cipher,
+	}
+}
+
+const deliveryPlatformColumns = `organization_id, platform, store_id, webhook_secret, api_url, api_token, enabled, last_polled_at, last_event_at, created_at, updated_at`
+
+func (s *PostgresDeliveryPlatformStore) scanIntegration(row rowScanner) (*DeliveryPlatformIntegration, error) {
+	var integration DeliveryPlatformIntegration
+	var secret, apiToken sql.NullString
+	if err := row.Scan(
+		&integration.OrganizationID,
+		&integration.Platform,
+		&integration.StoreID,
+		&secret,
+		&integration.APIURL,
+		&apiToken,
+		&integration.Enabled,
+		&integration.LastPolledAt,
+		&integration.LastEventAt,
+		&integration.CreatedAt,
+		&integration.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	opened, err := openString(s.Cipher, secret)
+	if err != nil {
+		return nil, err
+	}
+	if opened != nil {
+		integration.WebhookSecret = *opened
+	}
+	if integration.APIToken, err = openString(s.Cipher, apiToken); err != nil {
+		return nil, err
+	}
+	return &integration, nil
+}
+
+func (s *PostgresDeliveryPlatformStore) queryIntegrations(query string, args ...any) ([]DeliveryPlatformIntegration, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		s.Logger.Error("failed to get delivery platform integrations", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	integrations := []DeliveryPlatformIntegration{}
+	for rows.Next() {
+		integration, err := s.scanIntegration(rows)
+		if err != nil {
+			s.Logger.Error("failed to scan delivery platform integration", "error", err)
+			return nil, err
+		}
+		integrations = append(integrations, *integration)
+	}
+	return integrations, rows.Err()
+}
+
+// GetIntegrations lists the delivery platforms the organization is connected to
+func (s *PostgresDeliveryPlatformStore) GetIntegrations(org_id uuid.UUID) ([]DeliveryPlatformIntegration, error) {
+	return s.queryIntegrations(`
+		SELECT `+deliveryPlatformColumns+`
+		FROM delivery_platform_integrations
+		WHERE organization_id = $1
+		ORDER BY platform
+	`, org_id)
+}
+
+// GetIntegration returns the connection of the organization to a platform, or sql.ErrNoRows
+func (s *PostgresDeliveryPlatformStore) GetIntegration(org_id uuid.UUID, platform string) (*DeliveryPlatformIntegration, error) {
+	row := s.DB.QueryRow(`
+		SELECT `+deliveryPlatformColumns+`
+		FROM delivery_platform_integrations
+		WHERE organization_id = $1 AND platform = $2
+	`, org_id, platform)
+
+	integration, err := s.scanIntegration(row)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get delivery platform integration", "error", err, "platform", platform)
+		}
+		return nil, err
+	}
+	return integration, nil
+}
+
+// SaveIntegration connects the organization to a platform or replaces its connection, keeping when it
+// was last polled
+func (s *PostgresDeliveryPlatformStore) SaveIntegration(org_id uuid.UUID, integration *DeliveryPlatformIntegration) error {
+	secret, err := sealString(s.Cipher, &integration.WebhookSecret)
+	if err != nil {
+		return err
+	}
+	apiToken, err := sealString(s.Cipher, integration.APIToken)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	query := `
+		INSERT INTO delivery_platform_integrations (organization_id, platform, store_id, webhook_secret, api_url, api_token, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+		ON CONFLICT (organization_id, platform) DO UPDATE SET
+			store_id = EXCLUDED.store_id,
+			webhook_secret = EXCLUDED.webhook_secret,
+			api_url = EXCLUDED.api_url,
+			api_token = EXCLUDED.api_token,
+			enabled = EXCLUDED.enabled,
+			updated_at = EXCLUDED.updated_at
+		RETURNING created_at, updated_at
+	`
+	err = s.DB.QueryRow(query, org_id, integration.Platform, integration.StoreID, secret, integration.APIURL, apiToken, integration.Enabled, now).
+		Scan(&integration.CreatedAt, &integration.UpdatedAt)
+	if err != nil {
+		s.Logger.Error("failed to save delivery platform integration", "error", err, "platform", integration.Platform)
+		return err
+	}
+	integration.OrganizationID = org_id
+	return nil
+}
+
+// DeleteIntegration disconnects the organization from a platform, sql.ErrNoRows when it was not connected
+func (s *PostgresDeliveryPlatformStore) DeleteIntegration(org_id uuid.UUID, platform string) error {
+	result, err := s.DB.Exec(`DELETE FROM delivery_platform_integrations WHERE organization_id = $1 AND platform = $2`, org_id, platform)
+	if err != nil {
+		s.Logger.Error("failed to delete delivery platform integration", "error", err, "platform", platform)
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetPolledIntegrations lists the enabled integrations of every organization that are polled
+func (s *PostgresDeliveryPlatformStore) GetPolledIntegrations() ([]DeliveryPlatformIntegration, error) {
+	return s.queryIntegrations(`
+		SELECT ` + deliveryPlatformColumns + `
+		FROM delivery_platform_integrations
+		WHERE enabled AND api_url IS NOT NULL
+		ORDER BY organization_id, platform
+	`)
+}
+
+// MarkPolled records when the platform was last polled, the next poll asks for what changed since
+func (s *PostgresDeliveryPlatformStore) MarkPolled(org_id uuid.UUID, platform string, at time.Time) error {
+	_, err := s.DB.Exec(`
+		UPDATE delivery_platform_integrations SET last_polled_at = $3 WHERE organization_id = $1 AND platform = $2
+	`, org_id, platform, at)
+	if err != nil {
+		s.Logger.Error("failed to mark delivery platform polled", "error", err, "platform", platform)
+	}
+	return err
+}
+
+// MarkEventReceived records when the platform last sent an order or status update
+func (s *PostgresDeliveryPlatformStore) MarkEventReceived(org_id uuid.UUID, platform string, at time.Time) error {
+	_, err := s.DB.Exec(`
+		UPDATE delivery_platform_integrations SET last_event_at = $3 WHERE organization_id = $1 AND platform = $2
+	`, org_id, platform, at)
+	if err != nil {
+		s.Logger.Error("failed to mark delivery platform event", "error", err, "platform", platform)
+	}
+	return err
+}
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeliverySLADay is how the deliveries of an organization delivered on a day fared against its delivery
+// SLA, with the breach rate past which its managers are alerted
+type DeliverySLADay struct {
+	OrganizationID uuid.UUID
+	SLAMinutes     int
+	AlertPercent   int
+	Delivered      int
+	Breaches       int
+}
+
+// DeliverySLAAlert is an alert sent to the managers because too many deliveries of the day breached the
+// SLA. An organization is alerted at most once a day.
+type DeliverySLAAlert struct {
+	ID               uuid.UUID `json:"id"`
+	OrganizationID   uuid.UUID `json:"organization_id"`
+	Date             time.Time `json:"date"`
+	SLAMinutes       int       `json:"sla_minutes"`
+	Delivered        int       `json:"delivered"`
+	Breaches         int       `json:"breaches"`
+	BreachRate       float64   `json:"breach_rate_percent"`
+	ThresholdPercent int       `json:"threshold_percent"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type DeliverySLAStore interface {
+	GetDayBreaches(from, to time.Time) ([]DeliverySLADay, error)
+	StoreAlert(alert *DeliverySLAAlert) error
+}
+
+type PostgresDeliverySLAStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresDeliverySLAStore(DB *sql.DB, Logger *slog.Logger) *PostgresDeliverySLAStore {
+	return &PostgresDeliverySLAStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// GetDayBreaches counts, per organization, the deliveries out for delivery in [from, to) and delivered,
+// and those delivered later than the SLA of the organization. Organizations without rules get the default
+// SLA of 45 minutes and alert threshold of 20%.
+func (s *PostgresDeliverySLAStore) GetDayBreaches(from, to time.Time) ([]DeliverySLADay, error) {
+	query := `
+		SELECT o.organization_id, COALESCE(r.delivery_sla_minutes, 45), COALESCE(r.sla_breach_alert_percent, 20), COUNT(*),
+			COUNT(*) FILTER (WHERE d.delivered_time > d.out_for_delivery_time + make_interval(mins => COALESCE(r.delivery_sla_minutes, 45)))
+		FROM deliveries d
+		JOIN orders o ON o.id = d.order_id
+		LEFT JOIN organizations_rules r ON r.organization_id = o.organization_id
+		WHERE d.status = 'delivered' AND d.delivered_time >= d.out_for_delivery_time
+			AND d.out_for_delivery_time >= $1 AND d.out_for_delivery_time < $2
+		GROUP BY o.organization_id, r.delivery_sla_minutes, r.sla_breach_alert_percent
+	`
+	rows, err := s.DB.Query(query, from, to)
+	if err != nil {
+		s.Logger.Error("failed to get delivery SLA breaches", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	days := []DeliverySLADay{}
+	for rows.Next() {
+		var day DeliverySLADay
+		if err := rows.Scan(&day.OrganizationID, &day.SLAMinutes, &day.AlertPercent, &day.Delivered, &day.Breaches); err != nil {
+			s.Logger.Error("failed to scan delivery SLA breaches", "error", err)
+			return nil, err
+		}
+		days = append(days, day)
+	}
+	return days, rows.Err()
+}
+
+// StoreAlert logs an alert, giving it an ID and creation time when missing. It returns sql.ErrNoRows when
+// the organization was already alerted that day.
+func (s *PostgresDeliverySLAStore) StoreAlert(alert *DeliverySLAAlert) error {
+	if alert.ID == uuid.Nil {
+		alert.ID = uuid.New()
+	}
+	if alert.CreatedAt.IsZero() {
+		alert.CreatedAt = time.Now()
+	}
+	query := `
+		INSERT INTO delivery_sla_alerts (id, organization_id, alert_date, sla_minutes, delivered, breaches, breach_rate,
+			threshold_percent, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (organization_id, alert_date) DO NOTHING
+	`
+	result, err := s.DB.Exec(query,
+		alert.ID, alert.OrganizationID, alert.Date.Format(time.DateOnly), alert.SLAMinutes, alert.Delivered, alert.Breaches,
+		alert.BreachRate, alert.ThresholdPercent, alert.CreatedAt,
+	)
+	if err != nil {
+		s.Logger.Error("failed to store delivery SLA alert", "error", err, "organization_id", alert.OrganizationID)
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
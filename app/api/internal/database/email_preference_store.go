@@ -0,0 +1,142 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Categories of non-critical emails recipients can opt out of. Account, request and schedule emails
+// are always sent.
+const (
+	EmailCategoryReminders     = "reminders"
+	EmailCategoryDigests       = "digests"
+	EmailCategoryMarketing     = "marketing"
+	EmailCategoryWeeklySummary = "weekly_summary"
+)
+
+var EmailCategories = []string{EmailCategoryReminders, EmailCategoryDigests, EmailCategoryMarketing, EmailCategoryWeeklySummary}
+
+// EmailPreferenceStore keeps opt-outs by email address, so they also apply to addresses unsubscribed
+// from a link without logging in. Addresses are compared case-insensitively.
+type EmailPreferenceStore interface {
+	GetOptOuts(email string) ([]string, error)
+	SetOptOuts(email string, categories []string) error
+	OptOut(email, category string) error
+	GetOptedOut(category string, emails []string) (map[string]bool, error)
+}
+
+type PostgresEmailPreferenceStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresEmailPreferenceStore(DB *sql.DB, Logger *slog.Logger) *PostgresEmailPreferenceStore {
+	return &PostgresEmailPreferenceStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// GetOptOuts returns the categories the address opted out of
+func (s *PostgresEmailPreferenceStore) GetOptOuts(email string) ([]string, error) {
+	query := `SELECT category FROM email_opt_outs WHERE email = $1 ORDER BY category`
+
+	rows, err := s.DB.Query(query, strings.ToLower(email))
+	if err != nil {
+		s.Logger.Error("failed to get email opt-outs", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []string{}
+	for rows.Next() {
+		var category string
+		if err := rows.Scan(&category); err != nil {
+			s.Logger.Error("failed to scan email opt-out", "error", err)
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+// SetOptOuts replaces the categories the address opted out of
+func (s *PostgresEmailPreferenceStore) SetOptOuts(email string, categories []string) error {
+	email = strings.ToLower(email)
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		s.Logger.Error("failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM email_opt_outs WHERE email = $1`, email); err != nil {
+		s.Logger.Error("failed to clear email opt-outs", "error", err)
+		return err
+	}
+
+	now := time.Now()
+	for _, category := range categories {
+		_, err := tx.Exec(`INSERT INTO email_opt_outs (email, category, created_at) VALUES ($1, $2, $3)`, email, category, now)
+		if err != nil {
+			s.Logger.Error("failed to store email opt-out", "error", err, "category", category)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// OptOut adds a category to the opt-outs of the address, opting out twice is not an error
+func (s *PostgresEmailPreferenceStore) OptOut(email, category string) error {
+	query := `
+		INSERT INTO email_opt_outs (email, category, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (email, category) DO NOTHING
+	`
+	if _, err := s.DB.Exec(query, strings.ToLower(email), category, time.Now()); err != nil {
+		s.Logger.Error("failed to store email opt-out", "error", err, "category", category)
+		return err
+	}
+	return nil
+}
+
+// GetOptedOut returns which of the given addresses opted out of the category, keyed by the address as
+// given
+func (s *PostgresEmailPreferenceStore) GetOptedOut(category string, emails []string) (map[string]bool, error) {
+	byLower := make(map[string][]string, len(emails))
+	lower := make([]string, 0, len(emails))
+	for _, email := range emails {
+		key := strings.ToLower(email)
+		if _, ok := byLower[key]; !ok {
+			lower = append(lower, key)
+		}
+		byLower[key] = append(byLower[key], email)
+	}
+
+	query := `SELECT email FROM email_opt_outs WHERE category = $1 AND email = ANY($2)`
+	rows, err := s.DB.Query(query, category, pq.Array(lower))
+	if err != nil {
+		s.Logger.Error("failed to get opted out emails", "error", err, "category", category)
+		return nil, err
+	}
+	defer rows.Close()
+
+	optedOut := make(map[string]bool)
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			s.Logger.Error("failed to scan opted out email", "error", err)
+			return nil, err
+		}
+		for _, original := range byLower[email] {
+			optedOut[original] = true
+		}
+	}
+	return optedOut, rows.Err()
+}
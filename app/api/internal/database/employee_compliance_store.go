@@ -0,0 +1,285 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Identity documents whose expiry is tracked
+const (
+	DocumentNationalID = "national_id"
+	DocumentWorkPermit = "work_permit"
+)
+
+type EmergencyContact struct {
+	Name         *string `json:"name"`
+	Relationship *string `json:"relationship"`
+	Phone        *string `json:"phone"`
+}
+
+type BankDetails struct {
+	AccountHolder *string `json:"account_holder"`
+	BankName      *string `json:"bank_name"`
+	IBAN          *string `json:"iban"`
+}
+
+// EmployeeCompliance holds the emergency contact, identity documents and bank details of an employee
+type EmployeeCompliance struct {
+	UserID           uuid.UUID        `json:"user_id"`
+	EmergencyContact EmergencyContact `json:"emergency_contact"`
+	NationalID       *string          `json:"national_id"`
+	NationalIDExpiry *time.Time       `json:"national_id_expiry"`
+	WorkPermitNumber *string          `json:"work_permit_number"`
+	WorkPermitExpiry *time.Time       `json:"work_permit_expiry"`
+	BankDetails      BankDetails      `json:"bank_details"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+}
+
+// ExpiringDocument is a national ID or work permit close to or past its expiry. AlertedDays is the
+// smallest number of days before expiry its holder was alerted at, nil when they were not alerted yet.
+type ExpiringDocument struct {
+	EmployeeID     uuid.UUID
+	OrganizationID uuid.UUID
+	EmployeeName   string
+	EmployeeEmail  string
+	Document       string
+	ExpiresOn      time.Time
+	AlertedDays    *int
+}
+
+type EmployeeComplianceStore interface {
+	GetCompliance(user_id uuid.UUID) (*EmployeeCompliance, error)
+	SaveCompliance(compliance *EmployeeCompliance) error
+	GetWorkPermitExpiries(org_id uuid.UUID) (map[uuid.UUID]time.Time, error)
+	GetExpiringDocuments(until time.Time) ([]ExpiringDocument, error)
+	MarkExpiryAlerted(user_id uuid.UUID, document string, days int) error
+}
+
+// PostgresEmployeeComplianceStore seals the emergency contact phone, document numbers, account holder
+// and IBAN with Cipher when it is set
+type PostgresEmployeeComplianceStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+	Cipher FieldCipher
+}
+
+func NewPostgresEmployeeComplianceStore(DB *sql.DB, Logger *slog.Logger, cipher FieldCipher) *PostgresEmployeeComplianceStore {
+	return &PostgresEmployeeComplianceStore{
+		DB:     DB,
+		Logger: Logger,
+		Cipher: cipher,
+	}
+}
+
+// GetCompliance returns the compliance details of the employee, or sql.ErrNoRows when none were saved
+func (s *PostgresEmployeeComplianceStore) GetCompliance(user_id uuid.UUID) (*EmployeeCompliance, error) {
+	query := `
+		SELECT user_id, emergency_contact_name, emergency_contact_relationship, emergency_contact_phone,
+			national_id, national_id_expiry, work_permit_number, work_permit_expiry,
+			bank_account_holder, bank_name, bank_iban, updated_at
+		FROM employee_compliance
+		WHERE user_id = $1
+	`
+	compliance := EmployeeCompliance{}
+	var phone, nationalID, permitNumber, accountHolder, iban sql.NullString
+	var nationalIDExpiry, permitExpiry sql.NullTime
+	err := s.DB.QueryRow(query, user_id).Scan(
+		&compliance.UserID,
+		&compliance.EmergencyContact.Name,
+		&compliance.EmergencyContact.Relationship,
+		&phone,
+		&nationalID,
+		&nationalIDExpiry,
+		&permitNumber,
+		&permitExpiry,
+		&accountHolder,
+		&compliance.BankDetails.BankName,
+		&iban,
+		&compliance.UpdatedAt,
+	)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get employee compliance", "error", err, "user_id", user_id)
+		}
+		return nil, err
+	}
+
+	if nationalIDExpiry.Valid {
+		compliance.NationalIDExpiry = &nationalIDExpiry.Time
+	}
+	if permitExpiry.Valid {
+		compliance.WorkPermitExpiry = &permitExpiry.Time
+	}
+	for _, field := range []struct {
+		sealed sql.NullString
+		opened **string
+	}{
+		{phone, &compliance.EmergencyContact.Phone},
+		{nationalID, &compliance.NationalID},
+		{permitNumber, &compliance.WorkPermitNumber},
+		{accountHolder, &compliance.BankDetails.AccountHolder},
+		{iban, &compliance.BankDetails.IBAN},
+	} {
+		if *field.opened, err = openString(s.Cipher, field.sealed); err != nil {
+			s.Logger.Error("failed to decrypt employee compliance", "error", err, "user_id", user_id)
+			return nil, err
+		}
+	}
+	return &compliance, nil
+}
+
+// SaveCompliance replaces the compliance details of the employee. Expiry alerts start over for a
+// document whose expiry date changed.
+func (s *PostgresEmployeeComplianceStore) SaveCompliance(compliance *EmployeeCompliance) error {
+	sealed := make([]*string, 0, 5)
+	for _, value := range []*string{
+		compliance.EmergencyContact.Phone,
+		compliance.NationalID,
+		compliance.WorkPermitNumber,
+		compliance.BankDetails.AccountHolder,
+		compliance.BankDetails.IBAN,
+	} {
+		value, err := sealString(s.Cipher, value)
+		if err != nil {
+			s.Logger.Error("failed to encrypt employee compliance", "error", err, "user_id", compliance.UserID)
+			return err
+		}
+		sealed = append(sealed, value)
+	}
+
+	query := `
+		INSERT INTO employee_compliance (user_id, emergency_contact_name, emergency_contact_relationship,
+			emergency_contact_phone, national_id, national_id_expiry, work_permit_number, work_permit_expiry,
+			bank_account_holder, bank_name, bank_iban, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			emergency_contact_name = EXCLUDED.emergency_contact_name,
+			emergency_contact_relationship = EXCLUDED.emergency_contact_relationship,
+			emergency_contact_phone = EXCLUDED.emergency_contact_phone,
+			national_id = EXCLUDED.national_id,
+			national_id_expiry = EXCLUDED.national_id_expiry,
+			work_permit_number = EXCLUDED.work_permit_number,
+			work_permit_expiry = EXCLUDED.work_permit_expiry,
+			bank_account_holder = EXCLUDED.bank_account_holder,
+			bank_name = EXCLUDED.bank_name,
+			bank_iban = EXCLUDED.bank_iban,
+			national_id_alerted_days = CASE WHEN employee_compliance.national_id_expiry IS DISTINCT FROM EXCLUDED.national_id_expiry
+				THEN NULL ELSE employee_compliance.national_id_alerted_days END,
+			work_permit_alerted_days = CASE WHEN employee_compliance.work_permit_expiry IS DISTINCT FROM EXCLUDED.work_permit_expiry
+				THEN NULL ELSE employee_compliance.work_permit_alerted_days END,
+			updated_at = EXCLUDED.updated_at
+		RETURNING updated_at
+	`
+	err := s.DB.QueryRow(query,
+		compliance.UserID,
+		compliance.EmergencyContact.Name,
+		compliance.EmergencyContact.Relationship,
+		sealed[0],
+		sealed[1],
+		compliance.NationalIDExpiry,
+		sealed[2],
+		compliance.WorkPermitExpiry,
+		sealed[3],
+		compliance.BankDetails.BankName,
+		sealed[4],
+	).Scan(&compliance.UpdatedAt)
+	if err != nil {
+		s.Logger.Error("failed to save employee compliance", "error", err, "user_id", compliance.UserID)
+		return err
+	}
+
+	s.Logger.Info("employee compliance saved", "user_id", compliance.UserID)
+	return nil
+}
+
+// GetWorkPermitExpiries returns the work permit expiry of the employees of the organization that have
+// one, keyed by employee
+func (s *PostgresEmployeeComplianceStore) GetWorkPermitExpiries(org_id uuid.UUID) (map[uuid.UUID]time.Time, error) {
+	query := `
+		SELECT c.user_id, c.work_permit_expiry
+		FROM employee_compliance c
+		JOIN users u ON u.id = c.user_id
+		WHERE u.organization_id = $1 AND c.work_permit_expiry IS NOT NULL
+	`
+	rows, err := s.DB.Query(query, org_id)
+	if err != nil {
+		s.Logger.Error("failed to get work permit expiries", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	expiries := map[uuid.UUID]time.Time{}
+	for rows.Next() {
+		var userID uuid.UUID
+		var expiry time.Time
+		if err := rows.Scan(&userID, &expiry); err != nil {
+			s.Logger.Error("failed to scan work permit expiry", "error", err)
+			return nil, err
+		}
+		expiries[userID] = expiry
+	}
+	return expiries, rows.Err()
+}
+
+// GetExpiringDocuments lists the documents expiring on or before until, soonest first. Documents whose
+// holder was already alerted of their expiry are left out.
+func (s *PostgresEmployeeComplianceStore) GetExpiringDocuments(until time.Time) ([]ExpiringDocument, error) {
+	query := `
+		SELECT c.user_id, u.organization_id, u.full_name, u.email, 'national_id', c.national_id_expiry, c.national_id_alerted_days
+		FROM employee_compliance c
+		JOIN users u ON u.id = c.user_id
+		WHERE c.national_id_expiry <= $1 AND (c.national_id_alerted_days IS NULL OR c.national_id_alerted_days > 0)
+		UNION ALL
+		SELECT c.user_id, u.organization_id, u.full_name, u.email, 'work_permit', c.work_permit_expiry, c.work_permit_alerted_days
+		FROM employee_compliance c
+		JOIN users u ON u.id = c.user_id
+		WHERE c.work_permit_expiry <= $1 AND (c.work_permit_alerted_days IS NULL OR c.work_permit_alerted_days > 0)
+		ORDER BY 6, 1
+	`
+	rows, err := s.DB.Query(query, until)
+	if err != nil {
+		s.Logger.Error("failed to get expiring documents", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	documents := []ExpiringDocument{}
+	for rows.Next() {
+		var document ExpiringDocument
+		var alertedDays sql.NullInt64
+		if err := rows.Scan(&document.EmployeeID, &document.OrganizationID, &document.EmployeeName, &document.EmployeeEmail,
+			&document.Document, &document.ExpiresOn, &alertedDays); err != nil {
+			s.Logger.Error("failed to scan expiring document", "error", err)
+			return nil, err
+		}
+		if alertedDays.Valid {
+			days := int(alertedDays.Int64)
+			document.AlertedDays = &days
+		}
+		documents = append(documents, document)
+	}
+	return documents, rows.Err()
+}
+
+// MarkExpiryAlerted records that the holder of the document was alerted days before its expiry
+func (s *PostgresEmployeeComplianceStore) MarkExpiryAlerted(user_id uuid.UUID, document string, days int) error {
+	var query string
+	switch document {
+	case DocumentNationalID:
+		query = `UPDATE employee_compliance SET national_id_alerted_days = $2 WHERE user_id = $1`
+	case DocumentWorkPermit:
+		query = `UPDATE employee_compliance SET work_permit_alerted_days = $2 WHERE user_id = $1`
+	default:
+		return fmt.Errorf("unknown document %q", document)
+	}
+
+	if _, err := s.DB.Exec(query, user_id, days); err != nil {
+		s.Logger.Error("failed to mark document expiry alerted", "error", err, "user_id", user_id, "document", document)
+		return err
+	}
+	return nil
+}
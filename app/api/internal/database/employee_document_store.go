@@ -0,0 +1,233 @@
+package database
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of generated employee documents
+const (
+	DocumentOfferLetter       = "offer_letter"
+	DocumentTerminationLetter = "termination_letter"
+)
+
+var ErrDocumentSigned = errors.New("document is already signed")
+
+// IsDocumentKind reports whether kind is a letter documents can be generated for
+func IsDocumentKind(kind string) bool {
+	return kind == DocumentOfferLetter || kind == DocumentTerminationLetter
+}
+
+// DocumentTemplate is the title and text/template body of a kind of letter
+type DocumentTemplate struct {
+	Kind      string     `json:"kind"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	UpdatedBy *uuid.UUID `json:"updated_by"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// EmployeeDocument is a letter generated for an employee. EmployeeID is nil once the employee's account is
+// gone, the name and email they had are kept. The signed PDF is loaded with GetSignedPDF.
+type EmployeeDocument struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	EmployeeID     *uuid.UUID `json:"employee_id"`
+	EmployeeName   string     `json:"employee_name"`
+	EmployeeEmail  string     `json:"employee_email"`
+	Kind           string     `json:"kind"`
+	Title          string     `json:"title"`
+	Body           string     `json:"body"`
+	CreatedBy      *uuid.UUID `json:"created_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	SignExpiresAt  time.Time  `json:"sign_expires_at"`
+	SignedAt       *time.Time `json:"signed_at"`
+	SignerName     *string    `json:"signer_name"`
+	SignerIP       *string    `json:"signer_ip"`
+	SignedSHA256   *string    `json:"signed_sha256"`
+}
+
+type EmployeeDocumentStore interface {
+	GetTemplate(org_id uuid.UUID, kind string) (*DocumentTemplate, error)
+	SaveTemplate(org_id uuid.UUID, template *DocumentTemplate) error
+	CreateDocument(doc *EmployeeDocument, signToken string) error
+	GetDocuments(org_id uuid.UUID, employee_id *uuid.UUID) ([]EmployeeDocument, error)
+	GetDocument(org_id, document_id uuid.UUID) (*EmployeeDocument, error)
+	GetDocumentBySignToken(signToken string) (*EmployeeDocument, error)
+	SignDocument(doc *EmployeeDocument, pdf []byte) error
+	GetSignedPDF(org_id, document_id uuid.UUID) ([]byte, error)
+}
+
+type PostgresEmployeeDocumentStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresEmployeeDocumentStore(DB *sql.DB, Logger *slog.Logger) *PostgresEmployeeDocumentStore {
+	return &PostgresEmployeeDocumentStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// HashSignToken is how signing tokens are stored, the tokens themselves are only emailed to the employee
+func HashSignToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+const employeeDocumentColumns = `
+	id, organization_id, employee_id, employee_name, employee_email, kind, title, body, created_by, created_at,
+	sign_expires_at, signed_at, signer_name, signer_ip, signed_sha256
+`
+
+func scanEmployeeDocument(row interface{ Scan(...any) error }) (*EmployeeDocument, error) {
+	var doc EmployeeDocument
+	err := row.Scan(&doc.ID, &doc.OrganizationID, &doc.EmployeeID, &doc.EmployeeName, &doc.EmployeeEmail, &doc.Kind,
+		&doc.Title, &doc.Body, &doc.CreatedBy, &doc.CreatedAt, &doc.SignExpiresAt, &doc.SignedAt, &doc.SignerName,
+		&doc.SignerIP, &doc.SignedSHA256)
+	if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// GetTemplate returns the organization's template of the kind, or sql.ErrNoRows when it uses the built-in one
+func (s *PostgresEmployeeDocumentStore) GetTemplate(org_id uuid.UUID, kind string) (*DocumentTemplate, error) {
+	query := `SELECT kind, title, body, updated_by, updated_at FROM document_templates WHERE organization_id = $1 AND kind = $2`
+	var template DocumentTemplate
+	err := s.DB.QueryRow(query, org_id, kind).Scan(&template.Kind, &template.Title, &template.Body, &template.UpdatedBy, &template.UpdatedAt)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get document template", "error", err, "org_id", org_id, "kind", kind)
+		}
+		return nil, err
+	}
+	return &template, nil
+}
+
+// SaveTemplate replaces the organization's template of the kind
+func (s *PostgresEmployeeDocumentStore) SaveTemplate(org_id uuid.UUID, template *DocumentTemplate) error {
+	query := `
+		INSERT INTO document_templates (organization_id, kind, title, body, updated_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (organization_id, kind) DO UPDATE
+		SET title = EXCLUDED.title, body = EXCLUDED.body, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+		RETURNING updated_at
+	`
+	err := s.DB.QueryRow(query, org_id, template.Kind, template.Title, template.Body, template.UpdatedBy).Scan(&template.UpdatedAt)
+	if err != nil {
+		s.Logger.Error("failed to save document template", "error", err, "org_id", org_id, "kind", template.Kind)
+		return err
+	}
+	return nil
+}
+
+// CreateDocument stores a generated letter the employee signs with signToken until doc.SignExpiresAt
+func (s *PostgresEmployeeDocumentStore) CreateDocument(doc *EmployeeDocument, signToken string) error {
+	query := `
+		INSERT INTO employee_documents (organization_id, employee_id, employee_name, employee_email, kind, title, body,
+			created_by, sign_token_hash, sign_expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at
+	`
+	err := s.DB.QueryRow(query, doc.OrganizationID, doc.EmployeeID, doc.EmployeeName, doc.EmployeeEmail, doc.Kind,
+		doc.Title, doc.Body, doc.CreatedBy, HashSignToken(signToken), doc.SignExpiresAt).Scan(&doc.ID, &doc.CreatedAt)
+	if err != nil {
+		s.Logger.Error("failed to create employee document", "error", err, "org_id", doc.OrganizationID)
+		return err
+	}
+	return nil
+}
+
+// GetDocuments lists the documents of the organization, of one employee when employee_id is set, newest first
+func (s *PostgresEmployeeDocumentStore) GetDocuments(org_id uuid.UUID, employee_id *uuid.UUID) ([]EmployeeDocument, error) {
+	where, args := Where("organization_id = ?", org_id).
+		AndIf(employee_id != nil, "employee_id = ?", employee_id).
+		Build()
+	query := `SELECT ` + employeeDocumentColumns + ` FROM employee_documents WHERE ` + where + ` ORDER BY created_at DESC`
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		s.Logger.Error("failed to get employee documents", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	documents := []EmployeeDocument{}
+	for rows.Next() {
+		doc, err := scanEmployeeDocument(rows)
+		if err != nil {
+			s.Logger.Error("failed to scan employee document", "error", err)
+			return nil, err
+		}
+		documents = append(documents, *doc)
+	}
+	return documents, rows.Err()
+}
+
+// GetDocument returns a document of the organization, or sql.ErrNoRows
+func (s *PostgresEmployeeDocumentStore) GetDocument(org_id, document_id uuid.UUID) (*EmployeeDocument, error) {
+	query := `SELECT ` + employeeDocumentColumns + ` FROM employee_documents WHERE id = $1 AND organization_id = $2`
+	doc, err := scanEmployeeDocument(s.DB.QueryRow(query, document_id, org_id))
+	if err != nil && err != sql.ErrNoRows {
+		s.Logger.Error("failed to get employee document", "error", err, "document_id", document_id)
+	}
+	return doc, err
+}
+
+// GetDocumentBySignToken returns the document signed with the token, or sql.ErrNoRows for unknown and
+// expired tokens. Signed documents are still returned, so their signer can download them.
+func (s *PostgresEmployeeDocumentStore) GetDocumentBySignToken(signToken string) (*EmployeeDocument, error) {
+	query := `SELECT ` + employeeDocumentColumns + ` FROM employee_documents
+		WHERE sign_token_hash = $1 AND (signed_at IS NOT NULL OR sign_expires_at > NOW())`
+	doc, err := scanEmployeeDocument(s.DB.QueryRow(query, HashSignToken(signToken)))
+	if err != nil && err != sql.ErrNoRows {
+		s.Logger.Error("failed to get employee document by sign token", "error", err)
+	}
+	return doc, err
+}
+
+// SignDocument stores the signature of doc, its signer, IP and time, and the signed PDF. Returns
+// ErrDocumentSigned when it was signed in the meantime.
+func (s *PostgresEmployeeDocumentStore) SignDocument(doc *EmployeeDocument, pdf []byte) error {
+	sum := sha256.Sum256(pdf)
+	checksum := hex.EncodeToString(sum[:])
+	query := `
+		UPDATE employee_documents
+		SET signed_at = $2, signer_name = $3, signer_ip = $4, signed_pdf = $5, signed_sha256 = $6
+		WHERE id = $1 AND signed_at IS NULL
+	`
+	result, err := s.DB.Exec(query, doc.ID, doc.SignedAt, doc.SignerName, doc.SignerIP, pdf, checksum)
+	if err != nil {
+		s.Logger.Error("failed to sign employee document", "error", err, "document_id", doc.ID)
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrDocumentSigned
+	}
+	doc.SignedSHA256 = &checksum
+	return nil
+}
+
+// GetSignedPDF returns the signed PDF of a document, or sql.ErrNoRows when it is not signed
+func (s *PostgresEmployeeDocumentStore) GetSignedPDF(org_id, document_id uuid.UUID) ([]byte, error) {
+	query := `SELECT signed_pdf FROM employee_documents WHERE id = $1 AND organization_id = $2 AND signed_at IS NOT NULL`
+	var pdf []byte
+	if err := s.DB.QueryRow(query, document_id, org_id).Scan(&pdf); err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get signed document", "error", err, "document_id", document_id)
+		}
+		return nil, err
+	}
+	return pdf, nil
+}
@@ -0,0 +1,176 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EmployeeRecordNote         = "note"
+	EmployeeRecordWarning      = "warning"
+	EmployeeRecordWriteUp      = "write_up"
+	EmployeeRecordCommendation = "commendation"
+)
+
+// EmployeeRecord is a confidential note or disciplinary action about an employee. Only admins and the
+// manager who wrote it may read it, and it is purged once RetainUntil has passed.
+type EmployeeRecord struct {
+	ID          uuid.UUID  `json:"id"`
+	EmployeeID  uuid.UUID  `json:"employee_id"`
+	AuthorID    *uuid.UUID `json:"author_id,omitempty"`
+	AuthorName  *string    `json:"author_name,omitempty"`
+	Kind        string     `json:"kind"`
+	Title       string     `json:"title"`
+	Details     *string    `json:"details,omitempty"`
+	RetainUntil time.Time  `json:"retain_until"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type EmployeeRecordStore interface {
+	CreateRecord(org_id uuid.UUID, record *EmployeeRecord) error
+	GetRecordsForEmployee(org_id uuid.UUID, employee_id uuid.UUID) ([]EmployeeRecord, error)
+	GetRecordByID(org_id uuid.UUID, id uuid.UUID) (*EmployeeRecord, error)
+	DeleteRecord(org_id uuid.UUID, id uuid.UUID) error
+	PurgeExpiredRecords(now time.Time) (int64, error)
+}
+
+type PostgresEmployeeRecordStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresEmployeeRecordStore(DB *sql.DB, Logger *slog.Logger) *PostgresEmployeeRecordStore {
+	return &PostgresEmployeeRecordStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// Records past retain_until are left out of reads even before the purge removes them
+const employeeRecordSelect = `
+	SELECT r.id, r.employee_id, r.author_id, u.full_name, r.kind, r.title, r.details, r.retain_until, r.created_at
+	FROM employee_records r
+	LEFT JOIN users u ON u.id = r.author_id
+	WHERE r.organization_id = $1 AND r.retain_until >= CURRENT_DATE`
+
+func scanEmployeeRecord(row rowScanner) (*EmployeeRecord, error) {
+	var record EmployeeRecord
+	var authorID uuid.NullUUID
+	if err := row.Scan(
+		&record.ID,
+		&record.EmployeeID,
+		&authorID,
+		&record.AuthorName,
+		&record.Kind,
+		&record.Title,
+		&record.Details,
+		&record.RetainUntil,
+		&record.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if authorID.Valid {
+		record.AuthorID = &authorID.UUID
+	}
+	return &record, nil
+}
+
+// CreateRecord stores a new record, filling in its ID and creation time
+func (s *PostgresEmployeeRecordStore) CreateRecord(org_id uuid.UUID, record *EmployeeRecord) error {
+	if record.ID == uuid.Nil {
+		record.ID = uuid.New()
+	}
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO employee_records (id, organization_id, employee_id, author_id, kind, title, details, retain_until, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := s.DB.Exec(query,
+		record.ID,
+		org_id,
+		record.EmployeeID,
+		record.AuthorID,
+		record.Kind,
+		record.Title,
+		record.Details,
+		record.RetainUntil,
+		record.CreatedAt,
+	)
+	if err != nil {
+		s.Logger.Error("failed to create employee record", "error", err, "employee_id", record.EmployeeID)
+		return err
+	}
+
+	s.Logger.Info("employee record created", "id", record.ID, "kind", record.Kind, "employee_id", record.EmployeeID)
+	return nil
+}
+
+// GetRecordsForEmployee lists the records about an employee, the most recent first
+func (s *PostgresEmployeeRecordStore) GetRecordsForEmployee(org_id uuid.UUID, employee_id uuid.UUID) ([]EmployeeRecord, error) {
+	query := employeeRecordSelect + ` AND r.employee_id = $2 ORDER BY r.created_at DESC`
+	rows, err := s.DB.Query(query, org_id, employee_id)
+	if err != nil {
+		s.Logger.Error("failed to get employee records", "error", err, "employee_id", employee_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []EmployeeRecord{}
+	for rows.Next() {
+		record, err := scanEmployeeRecord(rows)
+		if err != nil {
+			s.Logger.Error("failed to scan employee record", "error", err)
+			return nil, err
+		}
+		records = append(records, *record)
+	}
+	return records, rows.Err()
+}
+
+// GetRecordByID retrieves a record of the organization, returning sql.ErrNoRows if it does not exist
+func (s *PostgresEmployeeRecordStore) GetRecordByID(org_id uuid.UUID, id uuid.UUID) (*EmployeeRecord, error) {
+	record, err := scanEmployeeRecord(s.DB.QueryRow(employeeRecordSelect+` AND r.id = $2`, org_id, id))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get employee record", "error", err, "id", id)
+		}
+		return nil, err
+	}
+	return record, nil
+}
+
+// DeleteRecord removes a record of the organization, returning sql.ErrNoRows if it does not exist
+func (s *PostgresEmployeeRecordStore) DeleteRecord(org_id uuid.UUID, id uuid.UUID) error {
+	result, err := s.DB.Exec(`DELETE FROM employee_records WHERE id = $1 AND organization_id = $2`, id, org_id)
+	if err != nil {
+		s.Logger.Error("failed to delete employee record", "error", err, "id", id)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	s.Logger.Info("employee record deleted", "id", id, "org_id", org_id)
+	return nil
+}
+
+// PurgeExpiredRecords deletes every record whose retention ended before now and returns how many were removed
+func (s *PostgresEmployeeRecordStore) PurgeExpiredRecords(now time.Time) (int64, error) {
+	result, err := s.DB.Exec(`DELETE FROM employee_records WHERE retain_until < $1`, now)
+	if err != nil {
+		s.Logger.Error("failed to purge expired employee records", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+)
+
+// ExchangeRateStore caches the daily exchange rates of the rate provider, so a day's rates are fetched once
+type ExchangeRateStore interface {
+	GetExchangeRates(base string, day time.Time) (map[string]float64, error)
+	StoreExchangeRates(base string, day time.Time, rates map[string]float64) error
+}
+
+type PostgresExchangeRateStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresExchangeRateStore(DB *sql.DB, Logger *slog.Logger) *PostgresExchangeRateStore {
+	return &PostgresExchangeRateStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// GetExchangeRates returns the cached rates of the day as units of each currency per unit of base, empty
+// when the day was not fetched yet
+func (s *PostgresExchangeRateStore) GetExchangeRates(base string, day time.Time) (map[string]float64, error) {
+	query := `
+		SELECT quote_currency, rate
+		FROM exchange_rates
+		WHERE rate_date = $1::DATE AND base_currency = $2
+	`
+	rows, err := s.DB.Query(query, day, base)
+	if err != nil {
+		s.Logger.Error("failed to get exchange rates", "error", err, "base", base, "day", day)
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := make(map[string]float64)
+	for rows.Next() {
+		var currency string
+		var rate float64
+		if err := rows.Scan(&currency, &rate); err != nil {
+			s.Logger.Error("failed to scan exchange rate", "error", err)
+			return nil, err
+		}
+		rates[currency] = rate
+	}
+	return rates, rows.Err()
+}
+
+// StoreExchangeRates caches the rates of the day in one transaction, rates already cached are kept
+func (s *PostgresExchangeRateStore) StoreExchangeRates(base string, day time.Time, rates map[string]float64) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO exchange_rates (rate_date, base_currency, quote_currency, rate)
+		VALUES ($1::DATE, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`
+	for currency, rate := range rates {
+		if _, err := tx.Exec(query, day, base, currency, rate); err != nil {
+			s.Logger.Error("failed to store exchange rate", "error", err, "base", base, "currency", currency)
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ExportEncryptionPGP        = "pgp"
+	ExportEncryptionPassphrase = "passphrase"
+)
+
+// ExportEncryption is how the exports and emailed reports of an organization are encrypted: to the
+// OpenPGP public key of the organization, or with a passphrase shared with its admins
+type ExportEncryption struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Mode           string    `json:"mode"`
+	PublicKey      *string   `json:"public_key,omitempty"`
+	KeyFingerprint *string   `json:"key_fingerprint,omitempty"`
+	// Passphrase is never sent back, it is sealed with the Cipher of the store
+	Passphrase *string    `json:"-"`
+	UpdatedBy  *uuid.UUID `json:"updated_by"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+type ExportEncryptionStore interface {
+	GetExportEncryption(org_id uuid.UUID) (*ExportEncryption, error)
+	SetExportEncryption(encryption *ExportEncryption) error
+	DeleteExportEncryption(org_id uuid.UUID) error
+}
+
+// PostgresExportEncryptionStore seals the passphrases with Cipher when it is set
+type PostgresExportEncryptionStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+	Cipher FieldCipher
+}
+
+func NewPostgresExportEncryptionStore(DB *sql.DB, Logger *slog.Logger, cipher FieldCipher) *PostgresExportEncryptionStore {
+	return &PostgresExportEncryptionStore{
+		DB:     DB,
+		Logger: Logger,
+		Cipher: cipher,
+	}
+}
+
+// GetExportEncryption returns how the files of the organization are encrypted, nil when they are not
+func (s *PostgresExportEncryptionStore) GetExportEncryption(org_id uuid.UUID) (*ExportEncryption, error) {
+	query := `
+		SELECT organization_id, mode, public_key, key_fingerprint, passphrase, updated_by, updated_at
+		FROM organization_export_encryption
+		WHERE organization_id = $1
+	`
+	var encryption ExportEncryption
+	var publicKey, fingerprint, passphrase sql.NullString
+	var updatedBy uuid.NullUUID
+	err := s.DB.QueryRow(query, org_id).Scan(&encryption.OrganizationID, &encryption.Mode, &publicKey, &fingerprint, &passphrase, &updatedBy, &encryption.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		s.Logger.Error("failed to get export encryption", "error", err, "org_id", org_id)
+		return nil, err
+	}
+
+	if publicKey.Valid {
+		encryption.PublicKey = &publicKey.String
+	}
+	if fingerprint.Valid {
+		encryption.KeyFingerprint = &fingerprint.String
+	}
+	if updatedBy.Valid {
+		encryption.UpdatedBy = &updatedBy.UUID
+	}
+	if encryption.Passphrase, err = openString(s.Cipher, passphrase); err != nil {
+		s.Logger.Error("failed to open export passphrase", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	return &encryption, nil
+}
+
+// SetExportEncryption replaces how the files of the organization are encrypted
+func (s *PostgresExportEncryptionStore) SetExportEncryption(encryption *ExportEncryption) error {
+	passphrase, err := sealString(s.Cipher, encryption.Passphrase)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO organization_export_encryption (organization_id, mode, public_key, key_fingerprint, passphrase, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (organization_id) DO UPDATE SET
+			mode = EXCLUDED.mode,
+			public_key = EXCLUDED.public_key,
+			key_fingerprint = EXCLUDED.key_fingerprint,
+			passphrase = EXCLUDED.passphrase,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at
+		RETURNING updated_at
+	`
+	err = s.DB.QueryRow(query, encryption.OrganizationID, encryption.Mode, encryption.PublicKey, encryption.KeyFingerprint,
+		passphrase, encryption.UpdatedBy).Scan(&encryption.UpdatedAt)
+	if err != nil {
+		s.Logger.Error("failed to set export encryption", "error", err, "org_id", encryption.OrganizationID)
+		return err
+	}
+	return nil
+}
+
+// DeleteExportEncryption stops encrypting the files of the organization, sql.ErrNoRows when they were not
+func (s *PostgresExportEncryptionStore) DeleteExportEncryption(org_id uuid.UUID) error {
+	result, err := s.DB.Exec(`DELETE FROM organization_export_encryption WHERE organization_id = $1`, org_id)
+	if err != nil {
+		s.Logger.Error("failed to delete export encryption", "error", err, "org_id", org_id)
+		return err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,278 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entities an organization can export, by the time their rows are filtered on
+const (
+	ExportEntityOrders     = "orders"     // create time
+	ExportEntityDeliveries = "deliveries" // out for delivery time
+)
+
+// File formats of an export
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+)
+
+// States of an export job. A completed job expires once its file is deleted.
+const (
+	ExportJobQueued    = "queued"
+	ExportJobRunning   = "running"
+	ExportJobCompleted = "completed"
+	ExportJobFailed    = "failed"
+	ExportJobExpired   = "expired"
+)
+
+// ExportJob is an export of the rows of an entity created from From to To, both days included, produced
+// in the background. FileKey locates the file in the export storage once the job is completed.
+type ExportJob struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	RequestedBy    *uuid.UUID `json:"requested_by"`
+	Entity         string     `json:"entity"`
+	From           time.Time  `json:"from"`
+	To             time.Time  `json:"to"`
+	Format         string     `json:"format"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	FileKey        *string    `json:"-"`
+	RowCount       *int       `json:"row_count"`
+	SizeBytes      *int64     `json:"size_bytes"`
+	Error          *string    `json:"error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	StartedAt      *time.Time `json:"started_at"`
+	CompletedAt    *time.Time `json:"completed_at"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+
+	// RequesterEmail and RequesterName are set on claimed jobs, empty when the requester was deleted
+	RequesterEmail string `json:"-"`
+	RequesterName  string `json:"-"`
+}
+
+// FileName is the name the export is downloaded as, ending in .gpg once the file was stored encrypted
+func (j *ExportJob) FileName() string {
+	name := j.Entity + "-" + j.From.Format(time.DateOnly) + "-" + j.To.Format(time.DateOnly) + "." + j.Format
+	if j.Encrypted() {
+		name += ".gpg"
+	}
+	return name
+}
+
+// Encrypted reports whether the file of the job was encrypted for the organization
+func (j *ExportJob) Encrypted() bool {
+	return j.FileKey != nil && strings.HasSuffix(*j.FileKey, ".gpg")
+}
+
+type ExportJobStore interface {
+	CreateExportJob(job *ExportJob) error
+	GetExportJobs(org_id uuid.UUID) ([]ExportJob, error)
+	GetExportJob(org_id uuid.UUID, job_id uuid.UUID) (*ExportJob, error)
+	ClaimExportJob(now time.Time, lease time.Duration) (*ExportJob, error)
+	CompleteExportJob(job_id uuid.UUID, fileKey string, rowCount int, sizeBytes int64, expiresAt time.Time) error
+	FailExportJob(job_id uuid.UUID, message string) error
+	GetExpiredExportJobs(now time.Time) ([]ExportJob, error)
+	MarkExportJobExpired(job_id uuid.UUID) error
+}
+
+type PostgresExportJobStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresExportJobStore(DB *sql.DB, Logger *slog.Logger) *PostgresExportJobStore {
+	return &PostgresExportJobStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+const exportJobColumns = `id, organization_id, requested_by, entity, from_date, to_date, format, status, attempts, file_key,
+	row_count, size_bytes, error, created_at, started_at, completed_at, expires_at`
+
+// scanExportJob scans the exportJobColumns of a row, then the extra columns into extra
+func scanExportJob(row rowScanner, extra ...any) (*ExportJob, error) {
+	var job ExportJob
+	var requestedBy uuid.NullUUID
+	var fileKey, message sql.NullString
+	var rowCount sql.NullInt32
+	var sizeBytes sql.NullInt64
+	var startedAt, completedAt, expiresAt sql.NullTime
+	dest := []any{&job.ID, &job.OrganizationID, &requestedBy, &job.Entity, &job.From, &job.To, &job.Format, &job.Status,
+		&job.Attempts, &fileKey, &rowCount, &sizeBytes, &message, &job.CreatedAt, &startedAt, &completedAt, &expiresAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	if requestedBy.Valid {
+		job.RequestedBy = &requestedBy.UUID
+	}
+	if fileKey.Valid {
+		job.FileKey = &fileKey.String
+	}
+	if rowCount.Valid {
+		count := int(rowCount.Int32)
+		job.RowCount = &count
+	}
+	if sizeBytes.Valid {
+		job.SizeBytes = &sizeBytes.Int64
+	}
+	if message.Valid {
+		job.Error = &message.String
+	}
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if completedAt.Valid {
+		job.CompletedAt = &completedAt.Time
+	}
+	if expiresAt.Valid {
+		job.ExpiresAt = &expiresAt.Time
+	}
+	return &job, nil
+}
+
+// CreateExportJob queues an export, filling in its ID, status and creation time
+func (s *PostgresExportJobStore) CreateExportJob(job *ExportJob) error {
+	query := `
+		INSERT INTO export_jobs (organization_id, requested_by, entity, from_date, to_date, format)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, status, created_at
+	`
+	err := s.DB.QueryRow(query, job.OrganizationID, job.RequestedBy, job.Entity, job.From, job.To, job.Format).
+		Scan(&job.ID, &job.Status, &job.CreatedAt)
+	if err != nil {
+		s.Logger.Error("failed to create export job", "error", err, "org_id", job.OrganizationID)
+		return err
+	}
+	return nil
+}
+
+// GetExportJobs lists the exports of the organization, newest first
+func (s *PostgresExportJobStore) GetExportJobs(org_id uuid.UUID) ([]ExportJob, error) {
+	query := `SELECT ` + exportJobColumns + ` FROM export_jobs WHERE organization_id = $1 ORDER BY created_at DESC`
+	rows, err := s.DB.Query(query, org_id)
+	if err != nil {
+		s.Logger.Error("failed to get export jobs", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []ExportJob{}
+	for rows.Next() {
+		job, err := scanExportJob(rows)
+		if err != nil {
+			s.Logger.Error("failed to scan export job", "error", err)
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, rows.Err()
+}
+
+// GetExportJob retrieves an export of the organization, returning sql.ErrNoRows if it does not exist
+func (s *PostgresExportJobStore) GetExportJob(org_id uuid.UUID, job_id uuid.UUID) (*ExportJob, error) {
+	query := `SELECT ` + exportJobColumns + ` FROM export_jobs WHERE id = $1 AND organization_id = $2`
+	job, err := scanExportJob(s.DB.QueryRow(query, job_id, org_id))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get export job", "error", err, "job_id", job_id)
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// ClaimExportJob marks the oldest queued job running and returns it with the email and name of its
+// requester, nil when no job is waiting. A job left running for longer than lease, by an instance of the
+// API that stopped, is claimed again. Other instances skip the jobs being claimed.
+func (s *PostgresExportJobStore) ClaimExportJob(now time.Time, lease time.Duration) (*ExportJob, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE export_jobs SET status = 'running', started_at = $1, attempts = attempts + 1
+			WHERE id = (
+				SELECT id FROM export_jobs
+				WHERE status = 'queued' OR (status = 'running' AND started_at < $2)
+				ORDER BY created_at
+				LIMIT 1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING ` + exportJobColumns + `
+		)
+		SELECT c.*, COALESCE(u.email, ''), COALESCE(u.full_name, '')
+		FROM claimed c
+		LEFT JOIN users u ON u.id = c.requested_by
+	`
+	var email, name string
+	job, err := scanExportJob(s.DB.QueryRow(query, now, now.Add(-lease)), &email, &name)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		s.Logger.Error("failed to claim export job", "error", err)
+		return nil, err
+	}
+	job.RequesterEmail, job.RequesterName = email, name
+	return job, nil
+}
+
+// CompleteExportJob records the file of a job, downloadable until expiresAt
+func (s *PostgresExportJobStore) CompleteExportJob(job_id uuid.UUID, fileKey string, rowCount int, sizeBytes int64, expiresAt time.Time) error {
+	query := `
+		UPDATE export_jobs
+		SET status = 'completed', file_key = $2, row_count = $3, size_bytes = $4, error = NULL, completed_at = NOW(), expires_at = $5
+		WHERE id = $1
+	`
+	if _, err := s.DB.Exec(query, job_id, fileKey, rowCount, sizeBytes, expiresAt); err != nil {
+		s.Logger.Error("failed to complete export job", "error", err, "job_id", job_id)
+		return err
+	}
+	return nil
+}
+
+// FailExportJob records why a job could not be produced. Failed jobs are not retried.
+func (s *PostgresExportJobStore) FailExportJob(job_id uuid.UUID, message string) error {
+	query := `UPDATE export_jobs SET status = 'failed', error = $2, completed_at = NOW() WHERE id = $1`
+	if _, err := s.DB.Exec(query, job_id, message); err != nil {
+		s.Logger.Error("failed to record export job failure", "error", err, "job_id", job_id)
+		return err
+	}
+	return nil
+}
+
+// GetExpiredExportJobs lists the completed jobs whose file has expired at now
+func (s *PostgresExportJobStore) GetExpiredExportJobs(now time.Time) ([]ExportJob, error) {
+	query := `SELECT ` + exportJobColumns + ` FROM export_jobs WHERE status = 'completed' AND expires_at <= $1 ORDER BY expires_at`
+	rows, err := s.DB.Query(query, now)
+	if err != nil {
+		s.Logger.Error("failed to get expired export jobs", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []ExportJob{}
+	for rows.Next() {
+		job, err := scanExportJob(rows)
+		if err != nil {
+			s.Logger.Error("failed to scan export job", "error", err)
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, rows.Err()
+}
+
+// MarkExportJobExpired records that the file of a job was deleted
+func (s *PostgresExportJobStore) MarkExportJobExpired(job_id uuid.UUID) error {
+	query := `UPDATE export_jobs SET status = 'expired', file_key = NULL WHERE id = $1`
+	if _, err := s.DB.Exec(query, job_id); err != nil {
+		s.Logger.Error("failed to expire export job", "error", err, "job_id", job_id)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ExternalEventConcert     = "concert"
+	ExternalEventSports      = "sports"
+	ExternalEventFestival    = "festival"
+	ExternalEventRoadClosure = "road_closure"
+	ExternalEventCompetitor  = "competitor"
+	ExternalEventOther       = "other"
+)
+
+// ExternalEvent is something happening around the organization that moves demand, like a concert
+// nearby, a match, a road closure or a competitor opening
+type ExternalEvent struct {
+	ID                 uuid.UUID `json:"id"`
+	Name               string    `json:"name"`
+	Category           string    `json:"category"`
+	Description        *string   `json:"description,omitempty"`
+	Location           *string   `json:"location,omitempty"`
+	StartTime          time.Time `json:"start_time"`
+	EndTime            time.Time `json:"end_time"`
+	ExpectedAttendance *int      `json:"expected_attendance,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+type ExternalEventStore interface {
+	GetEvents(org_id uuid.UUID) ([]ExternalEvent, error)
+	GetEventsBetween(org_id uuid.UUID, from, to time.Time) ([]ExternalEvent, error)
+	GetEventByID(org_id uuid.UUID, id uuid.UUID) (*ExternalEvent, error)
+	CreateEvent(org_id uuid.UUID, event *ExternalEvent) error
+	UpdateEvent(org_id uuid.UUID, event *ExternalEvent) error
+	DeleteEvent(org_id uuid.UUID, id uuid.UUID) error
+}
+
+type PostgresExternalEventStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresExternalEventStore(DB *sql.DB, Logger *slog.Logger) *PostgresExternalEventStore {
+	return &PostgresExternalEventStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+const externalEventColumns = `id, name, category, description, location, start_time, end_time, expected_attendance, created_at`
+
+type eventScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanExternalEvent(row eventScanner) (*ExternalEvent, error) {
+	var event ExternalEvent
+	if err := row.Scan(
+		&event.ID,
+		&event.Name,
+		&event.Category,
+		&event.Description,
+		&event.Location,
+		&event.StartTime,
+		&event.EndTime,
+		&event.ExpectedAttendance,
+		&event.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (s *PostgresExternalEventStore) queryEvents(query string, args ...any) ([]ExternalEvent, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		s.Logger.Error("failed to get external events", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []ExternalEvent{}
+	for rows.Next() {
+		event, err := scanExternalEvent(rows)
+		if err != nil {
+			s.Logger.Error("failed to scan external event", "error", err)
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+	return events, rows.Err()
+}
+
+// GetEvents lists every event of the organization, the most recent first
+func (s *PostgresExternalEventStore) GetEvents(org_id uuid.UUID) ([]ExternalEvent, error) {
+	query := `SELECT ` + externalEventColumns + ` FROM external_events WHERE organization_id = $1 ORDER BY start_time DESC`
+	return s.queryEvents(query, org_id)
+}
+
+// GetEventsBetween lists the events overlapping the [from, to) window in chronological order
+func (s *PostgresExternalEventStore) GetEventsBetween(org_id uuid.UUID, from, to time.Time) ([]ExternalEvent, error) {
+	query := `
+		SELECT ` + externalEventColumns + `
+		FROM external_events
+		WHERE organization_id = $1 AND start_time < $3 AND end_time > $2
+		ORDER BY start_time
+	`
+	return s.queryEvents(query, org_id, from, to)
+}
+
+// GetEventByID retrieves an event of the organization, returning sql.ErrNoRows if it does not exist
+func (s *PostgresExternalEventStore) GetEventByID(org_id uuid.UUID, id uuid.UUID) (*ExternalEvent, error) {
+	query := `SELECT ` + externalEventColumns + ` FROM external_events WHERE id = $1 AND organization_id = $2`
+	event, err := scanExternalEvent(s.DB.QueryRow(query, id, org_id))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get external event", "error", err, "id", id)
+		}
+		return nil, err
+	}
+	return event, nil
+}
+
+// CreateEvent stores a new event, filling in its ID and creation time
+func (s *PostgresExternalEventStore) CreateEvent(org_id uuid.UUID, event *ExternalEvent) error {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO external_events (id, organization_id, name, category, description, location, start_time, end_time, expected_attendance, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	_, err := s.DB.Exec(query,
+		event.ID,
+		org_id,
+		event.Name,
+		event.Category,
+		event.Description,
+		event.Location,
+		event.StartTime,
+		event.EndTime,
+		event.ExpectedAttendance,
+		event.CreatedAt,
+	)
+	if err != nil {
+		s.Logger.Error("failed to create external event", "error", err, "org_id", org_id)
+		return err
+	}
+
+	s.Logger.Info("external event created", "id", event.ID, "org_id", org_id)
+	return nil
+}
+
+// UpdateEvent overwrites an event of the organization, returning sql.ErrNoRows if it does not exist
+func (s *PostgresExternalEventStore) UpdateEvent(org_id uuid.UUID, event *ExternalEvent) error {
+	query := `
+		UPDATE external_events
+		SET name = $3, category = $4, description = $5, location = $6, start_time = $7, end_time = $8, expected_attendance = $9
+		WHERE id = $1 AND organization_id = $2
+		RETURNING created_at
+	`
+	err := s.DB.QueryRow(query,
+		event.ID,
+		org_id,
+		event.Name,
+		event.Category,
+		event.Description,
+		event.Location,
+		event.StartTime,
+		event.EndTime,
+		event.ExpectedAttendance,
+	).Scan(&event.CreatedAt)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to update external event", "error", err, "id", event.ID)
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteEvent removes an event of the organization, returning sql.ErrNoRows if it does not exist
+func (s *PostgresExternalEventStore) DeleteEvent(org_id uuid.UUID, id uuid.UUID) error {
+	result, err := s.DB.Exec(`DELETE FROM external_events WHERE id = $1 AND organization_id = $2`, id, org_id)
+	if err != nil {
+		s.Logger.Error("failed to delete external event", "error", err, "id", id)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	s.Logger.Info("external event deleted", "id", id, "org_id", org_id)
+	return nil
+}
@@ -0,0 +1,252 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ways the orders of the day can drift from the forecast and what the manager on duty is told to do
+const (
+	VarianceAbove = "above"
+	VarianceBelow = "below"
+
+	SuggestCallIn   = "call_in"
+	SuggestSendHome = "send_home"
+)
+
+// DayProgress compares the orders of a day so far with the forecast of the same hours. The hours before
+// ThroughHour have elapsed.
+type DayProgress struct {
+	Date           time.Time `json:"date"`
+	ThroughHour    int       `json:"through_hour"`
+	ForecastOrders int       `json:"forecast_orders"`
+	ActualOrders   int       `json:"actual_orders"`
+}
+
+// ForecastVarianceAlert is an alert raised when the orders of the day so far drifted from the forecast by
+// more than the threshold. DayForecastOrders and DayActualOrders cover the whole day and are only set
+// when alerts are listed, to tell whether the drift held.
+type ForecastVarianceAlert struct {
+	ID                   uuid.UUID `json:"id"`
+	OrganizationID       uuid.UUID `json:"organization_id"`
+	Date                 time.Time `json:"date"`
+	ThroughHour          int       `json:"through_hour"`
+	ForecastOrders       int       `json:"forecast_orders"`
+	ActualOrders         int       `json:"actual_orders"`
+	VariancePercent      float64   `json:"variance_percent"`
+	Direction            string    `json:"direction"`
+	Suggestion           string    `json:"suggestion"`
+	StaffOnShift         int       `json:"staff_on_shift"`
+	SuggestedStaffChange int       `json:"suggested_staff_change"`
+	NotifiedManagers     int       `json:"notified_managers"`
+	CreatedAt            time.Time `json:"created_at"`
+	DayForecastOrders    *int      `json:"day_forecast_orders,omitempty"`
+	DayActualOrders      *int      `json:"day_actual_orders,omitempty"`
+}
+
+type ForecastVarianceStore interface {
+	GetForecastedOrganizations(date time.Time) ([]uuid.UUID, error)
+	GetDayProgress(org_id uuid.UUID, date time.Time, throughHour int) (*DayProgress, error)
+	GetStaffOnShift(org_id uuid.UUID, at time.Time) (int, error)
+	GetOnDutyManagerEmails(org_id uuid.UUID, at time.Time) ([]string, error)
+
+	GetLatestAlert(org_id uuid.UUID, date time.Time, direction string) (*ForecastVarianceAlert, error)
+	StoreAlert(alert *ForecastVarianceAlert) error
+	ListAlerts(org_id uuid.UUID, from, to time.Time) ([]ForecastVarianceAlert, error)
+}
+
+type PostgresForecastVarianceStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresForecastVarianceStore(DB *sql.DB, Logger *slog.Logger) *PostgresForecastVarianceStore {
+	return &PostgresForecastVarianceStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// GetForecastedOrganizations lists the organizations with a demand forecast for the day
+func (s *PostgresForecastVarianceStore) GetForecastedOrganizations(date time.Time) ([]uuid.UUID, error) {
+	query := `SELECT DISTINCT organization_id FROM demand WHERE demand_date = $1`
+
+	rows, err := s.DB.Query(query, date.Format(time.DateOnly))
+	if err != nil {
+		s.Logger.Error("failed to get forecasted organizations", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	orgs := []uuid.UUID{}
+	for rows.Next() {
+		var org uuid.UUID
+		if err := rows.Scan(&org); err != nil {
+			s.Logger.Error("failed to scan forecasted organization", "error", err)
+			return nil, err
+		}
+		orgs = append(orgs, org)
+	}
+	return orgs, rows.Err()
+}
+
+// GetDayProgress sums the forecast and counts the orders of the day in the hours before throughHour
+func (s *PostgresForecastVarianceStore) GetDayProgress(org_id uuid.UUID, date time.Time, throughHour int) (*DayProgress, error) {
+	query := `
+		SELECT
+			(SELECT COALESCE(SUM(order_count), 0) FROM demand WHERE organization_id = $1 AND demand_date = $2 AND hour < $3),
+			(SELECT COUNT(*) FROM orders WHERE organization_id = $1 AND create_time >= $4 AND create_time < $5)
+	`
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	progress := DayProgress{Date: start, ThroughHour: throughHour}
+	err := s.DB.QueryRow(query, org_id, start.Format(time.DateOnly), throughHour, start, start.Add(time.Duration(throughHour)*time.Hour)).
+		Scan(&progress.ForecastOrders, &progress.ActualOrders)
+	if err != nil {
+		s.Logger.Error("failed to get day progress", "error", err, "organization_id", org_id)
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// GetStaffOnShift counts the employees scheduled at the given time
+func (s *PostgresForecastVarianceStore) GetStaffOnShift(org_id uuid.UUID, at time.Time) (int, error) {
+	query := `
+		SELECT COUNT(DISTINCT s.employee_id)
+		FROM schedules s
+		JOIN users u ON u.id = s.employee_id
+		WHERE u.organization_id = $1 AND s.shift_type = 'working'
+		AND (s.schedule_date + s.start_hour) <= $2
+		AND (s.schedule_date + s.end_hour) > $2
+	`
+	var staff int
+	if err := s.DB.QueryRow(query, org_id, at).Scan(&staff); err != nil {
+		s.Logger.Error("failed to get staff on shift", "error", err, "organization_id", org_id)
+		return 0, err
+	}
+	return staff, nil
+}
+
+// GetOnDutyManagerEmails lists the emails of the admins and managers scheduled at the given time
+func (s *PostgresForecastVarianceStore) GetOnDutyManagerEmails(org_id uuid.UUID, at time.Time) ([]string, error) {
+	query := `
+		SELECT DISTINCT u.email
+		FROM schedules s
+		JOIN users u ON u.id = s.employee_id
+		WHERE u.organization_id = $1 AND u.user_role IN ('admin', 'manager') AND s.shift_type = 'working'
+		AND (s.schedule_date + s.start_hour) <= $2
+		AND (s.schedule_date + s.end_hour) > $2
+		ORDER BY u.email
+	`
+	return s.emails(query, org_id, at)
+}
+
+func (s *PostgresForecastVarianceStore) emails(query string, args ...any) ([]string, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		s.Logger.Error("failed to get manager emails", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	emails := []string{}
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			s.Logger.Error("failed to scan manager email", "error", err)
+			return nil, err
+		}
+		emails = append(emails, email)
+	}
+	return emails, rows.Err()
+}
+
+// GetLatestAlert returns the latest alert of the day in the direction, or sql.ErrNoRows without one
+func (s *PostgresForecastVarianceStore) GetLatestAlert(org_id uuid.UUID, date time.Time, direction string) (*ForecastVarianceAlert, error) {
+	query := `
+		SELECT id, organization_id, alert_date, through_hour, forecast_orders, actual_orders, variance_percent,
+			direction, suggestion, staff_on_shift, suggested_staff_change, notified_managers, created_at
+		FROM forecast_variance_alerts
+		WHERE organization_id = $1 AND alert_date = $2 AND direction = $3
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+	var alert ForecastVarianceAlert
+	err := s.DB.QueryRow(query, org_id, date.Format(time.DateOnly), direction).Scan(
+		&alert.ID, &alert.OrganizationID, &alert.Date, &alert.ThroughHour, &alert.ForecastOrders, &alert.ActualOrders,
+		&alert.VariancePercent, &alert.Direction, &alert.Suggestion, &alert.StaffOnShift, &alert.SuggestedStaffChange,
+		&alert.NotifiedManagers, &alert.CreatedAt,
+	)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get latest forecast variance alert", "error", err, "organization_id", org_id)
+		}
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// StoreAlert logs an alert, giving it an ID and creation time when missing
+func (s *PostgresForecastVarianceStore) StoreAlert(alert *ForecastVarianceAlert) error {
+	if alert.ID == uuid.Nil {
+		alert.ID = uuid.New()
+	}
+	if alert.CreatedAt.IsZero() {
+		alert.CreatedAt = time.Now()
+	}
+	query := `
+		INSERT INTO forecast_variance_alerts (id, organization_id, alert_date, through_hour, forecast_orders, actual_orders,
+			variance_percent, direction, suggestion, staff_on_shift, suggested_staff_change, notified_managers, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+	_, err := s.DB.Exec(query,
+		alert.ID, alert.OrganizationID, alert.Date.Format(time.DateOnly), alert.ThroughHour, alert.ForecastOrders, alert.ActualOrders,
+		alert.VariancePercent, alert.Direction, alert.Suggestion, alert.StaffOnShift, alert.SuggestedStaffChange,
+		alert.NotifiedManagers, alert.CreatedAt,
+	)
+	if err != nil {
+		s.Logger.Error("failed to store forecast variance alert", "error", err, "organization_id", alert.OrganizationID)
+		return err
+	}
+	return nil
+}
+
+// ListAlerts lists the alerts of the days from from up to, excluding, to, newest first, with the forecast
+// and the orders of their whole day
+func (s *PostgresForecastVarianceStore) ListAlerts(org_id uuid.UUID, from, to time.Time) ([]ForecastVarianceAlert, error) {
+	query := `
+		SELECT a.id, a.organization_id, a.alert_date, a.through_hour, a.forecast_orders, a.actual_orders, a.variance_percent,
+			a.direction, a.suggestion, a.staff_on_shift, a.suggested_staff_change, a.notified_managers, a.created_at,
+			(SELECT COALESCE(SUM(d.order_count), 0) FROM demand d WHERE d.organization_id = a.organization_id AND d.demand_date = a.alert_date),
+			(SELECT COUNT(*) FROM orders o WHERE o.organization_id = a.organization_id
+				AND o.create_time >= a.alert_date AND o.create_time < a.alert_date + INTERVAL '1 day')
+		FROM forecast_variance_alerts a
+		WHERE a.organization_id = $1 AND a.alert_date >= $2 AND a.alert_date < $3
+		ORDER BY a.created_at DESC
+	`
+	rows, err := s.DB.Query(query, org_id, from.Format(time.DateOnly), to.Format(time.DateOnly))
+	if err != nil {
+		s.Logger.Error("failed to list forecast variance alerts", "error", err, "organization_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := []ForecastVarianceAlert{}
+	for rows.Next() {
+		var alert ForecastVarianceAlert
+		var dayForecast, dayActual int
+		if err := rows.Scan(
+			&alert.ID, &alert.OrganizationID, &alert.Date, &alert.ThroughHour, &alert.ForecastOrders, &alert.ActualOrders,
+			&alert.VariancePercent, &alert.Direction, &alert.Suggestion, &alert.StaffOnShift, &alert.SuggestedStaffChange,
+			&alert.NotifiedManagers, &alert.CreatedAt, &dayForecast, &dayActual,
+		); err != nil {
+			s.Logger.Error("failed to scan forecast variance alert", "error", err)
+			return nil, err
+		}
+		alert.DayForecastOrders, alert.DayActualOrders = &dayForecast, &dayActual
+		alerts = append(alerts, alert)
+	}
+	return alerts, rows.Err()
+}
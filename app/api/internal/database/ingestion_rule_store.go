@@ -0,0 +1,129 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IngestionRule is a sanity check applied to a column of the rows an organization imports. Rows
+// breaking a rule are rejected.
+//
+//   - required: the column must not be empty
+//   - min / max: the column must be a number of at least / at most Value
+//   - one_of: the column must be one of the comma separated values of Value
+type IngestionRule struct {
+	ID        uuid.UUID  `json:"id"`
+	Dataset   string     `json:"dataset"`
+	Field     string     `json:"field"`
+	Operator  string     `json:"operator"`
+	Value     string     `json:"value"`
+	Message   *string    `json:"message,omitempty"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type IngestionRuleStore interface {
+	GetRules(org_id uuid.UUID) ([]IngestionRule, error)
+	GetRulesForDataset(org_id uuid.UUID, dataset string) ([]IngestionRule, error)
+	CreateRule(org_id uuid.UUID, rule *IngestionRule) error
+	DeleteRule(org_id uuid.UUID, rule_id uuid.UUID) error
+}
+
+type PostgresIngestionRuleStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresIngestionRuleStore(DB *sql.DB, Logger *slog.Logger) *PostgresIngestionRuleStore {
+	return &PostgresIngestionRuleStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+const ingestionRuleColumns = `id, dataset, field, operator, value, message, created_by, created_at`
+
+func (s *PostgresIngestionRuleStore) queryRules(query string, args ...any) ([]IngestionRule, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		s.Logger.Error("failed to get ingestion rules", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []IngestionRule{}
+	for rows.Next() {
+		var rule IngestionRule
+		var createdBy uuid.NullUUID
+		if err := rows.Scan(
+			&rule.ID,
+			&rule.Dataset,
+			&rule.Field,
+			&rule.Operator,
+			&rule.Value,
+			&rule.Message,
+			&createdBy,
+			&rule.CreatedAt,
+		); err != nil {
+			s.Logger.Error("failed to scan ingestion rule", "error", err)
+			return nil, err
+		}
+		if createdBy.Valid {
+			rule.CreatedBy = &createdBy.UUID
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func (s *PostgresIngestionRuleStore) GetRules(org_id uuid.UUID) ([]IngestionRule, error) {
+	query := `SELECT ` + ingestionRuleColumns + ` FROM ingestion_rules WHERE organization_id = $1 ORDER BY dataset, field, created_at`
+	return s.queryRules(query, org_id)
+}
+
+func (s *PostgresIngestionRuleStore) GetRulesForDataset(org_id uuid.UUID, dataset string) ([]IngestionRule, error) {
+	query := `SELECT ` + ingestionRuleColumns + ` FROM ingestion_rules WHERE organization_id = $1 AND dataset = $2 ORDER BY field, created_at`
+	return s.queryRules(query, org_id, dataset)
+}
+
+func (s *PostgresIngestionRuleStore) CreateRule(org_id uuid.UUID, rule *IngestionRule) error {
+	rule.ID = uuid.New()
+	rule.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO ingestion_rules (id, organization_id, dataset, field, operator, value, message, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := s.DB.Exec(query, rule.ID, org_id, rule.Dataset, rule.Field, rule.Operator, rule.Value, rule.Message, rule.CreatedBy, rule.CreatedAt)
+	if err != nil {
+		s.Logger.Error("failed to create ingestion rule", "error", err, "org_id", org_id)
+		return err
+	}
+
+	s.Logger.Info("ingestion rule created", "org_id", org_id, "rule_id", rule.ID, "dataset", rule.Dataset)
+	return nil
+}
+
+// DeleteRule removes a rule, returning sql.ErrNoRows if the organization has no such rule
+func (s *PostgresIngestionRuleStore) DeleteRule(org_id uuid.UUID, rule_id uuid.UUID) error {
+	query := `DELETE FROM ingestion_rules WHERE id = $1 AND organization_id = $2`
+	result, err := s.DB.Exec(query, rule_id, org_id)
+	if err != nil {
+		s.Logger.Error("failed to delete ingestion rule", "error", err, "org_id", org_id, "rule_id", rule_id)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	s.Logger.Info("ingestion rule deleted", "org_id", org_id, "rule_id", rule_id)
+	return nil
+}
@@ -9,20 +9,68 @@ import (
 	"github.com/google/uuid"
 )
 
+// Drill-down paths of headline insights, below /api/:org
+const (
+	DrillDownBusiestHour      = "/insights/busiest-hour"
+	DrillDownMostSellingItems = "/insights/most-selling-items"
+	DrillDownDeliveriesToday  = "/insights/deliveries-today"
+	DrillDownKPIProgress      = "/targets/progress"
+)
+
+// Insight is a card of the dashboard. DrillDown is the path of the endpoint returning the rows behind
+// the statistic, for the cards that have one.
 type Insight struct {
 	Title     string `json:"title"`
 	Statistic string `json:"statistic"`
+	DrillDown string `json:"drill_down,omitempty"`
+}
+
+// HourlyOrders is the orders created in an hour of the day, the series behind the busiest hour
+type HourlyOrders struct {
+	Hour    int     `json:"hour"`
+	Orders  int     `json:"orders"`
+	Revenue float64 `json:"revenue"`
+}
+
+// TopItem is the sales of an item, the rows behind the most selling items. SoldCount is the number of
+// orders with the item, which ranks the items like the insight, Quantity the units sold.
+type TopItem struct {
+	ItemID    uuid.UUID `json:"item_id"`
+	Name      string    `json:"name"`
+	SoldCount int       `json:"sold_count"`
+	Quantity  int       `json:"quantity"`
+	Revenue   float64   `json:"revenue"`
+}
+
+// DeliveryOrder is a delivery order with its delivery, which is empty until the order leaves
+type DeliveryOrder struct {
+	OrderID            uuid.UUID  `json:"order_id"`
+	CreateTime         time.Time  `json:"create_time"`
+	OrderStatus        string     `json:"order_status"`
+	TotalAmount        float64    `json:"total_amount"`
+	DriverID           *uuid.UUID `json:"driver_id"`
+	DriverName         *string    `json:"driver_name"`
+	DeliveryStatus     *string    `json:"delivery_status"`
+	OutForDeliveryTime *time.Time `json:"out_for_delivery_time"`
+	DeliveredTime      *time.Time `json:"delivered_time"`
 }
 
 type InsightStore interface {
 	GetInsightsForAdmin(org_id uuid.UUID) ([]Insight, error)
 	GetInsightsForManager(org_id, manager_id uuid.UUID) ([]Insight, error)
 	GetInsightsForEmployee(org_id, employee_id uuid.UUID) ([]Insight, error)
+
+	// Drill-downs of the headline insights, from and to bound the create time of the orders when set
+	GetHourlyOrders(org_id uuid.UUID, from, to *time.Time) ([]HourlyOrders, error)
+	GetTopItems(org_id uuid.UUID, from, to *time.Time, limit int) ([]TopItem, error)
+	GetDeliveryOrdersToday(org_id uuid.UUID) ([]DeliveryOrder, error)
 }
 
+// PostgresInsightStore decrypts salaries with Cipher when column encryption is enabled
 type PostgresInsightStore struct {
 	DB     *sql.DB
 	Logger *slog.Logger
+	Cipher FieldCipher
 }
 
 // SQL Queries for Admin Insights
@@ -42,19 +90,12 @@ const (
 		GROUP BY user_role
 	`
 
-	// Average Employee Salaries
-	queryAverageEmployeeSalary = `
-		SELECT COALESCE(AVG(salary_per_hour), 0) 
-		FROM users 
-		WHERE organization_id = $1 AND user_role != 'admin'
-	`
-
-	// Average Employee Salaries per role
-	queryAverageSalaryPerRole = `
-		SELECT user_role, COALESCE(AVG(salary_per_hour), 0) as avg_salary 
+	// Employee Salaries, averaged in Go because encrypted salaries cannot be aggregated by PostgreSQL
+	queryEmployeeSalaries = `
+		SELECT user_role, salary_per_hour, salary_per_hour_encrypted
 		FROM users 
 		WHERE organization_id = $1 AND user_role != 'admin'
-		GROUP BY user_role
+		ORDER BY user_role
 	`
 
 	// Number of tables
@@ -99,13 +140,23 @@ const (
 		AND DATE(create_time) = CURRENT_DATE
 	`
 
-	// Total Revenue (sum of item prices for all orders)
+	// Total Revenue (sum of item prices for all orders, staff meals and employee discounts left out)
 	queryTotalRevenue = `
 		SELECT COALESCE(SUM(i.price), 0)
 		FROM orders o
 		JOIN order_items oi ON o.id = oi.order_id
 		JOIN items i ON oi.item_id = i.id
 		WHERE o.organization_id = $1
+		AND ` + staffOrderExcluded + `
+	`
+
+	// Staff orders of the current month and the discounts given on them
+	queryStaffOrdersThisMonth = `
+		SELECT COUNT(*), COALESCE(SUM(o.discount_amount), 0)
+		FROM staff_orders so
+		JOIN orders o ON o.id = so.order_id
+		WHERE so.organization_id = $1
+		AND o.create_time >= date_trunc('month', CURRENT_DATE)
 	`
 
 	// Number of employees for every role in the current shift
@@ -114,6 +165,7 @@ const (
 		FROM users u
 		JOIN schedules s ON u.id = s.employee_id
 		WHERE u.organization_id = $1 
+		AND s.shift_type = 'working'
 		AND (s.schedule_date + s.start_hour) <= $2 
 		AND (s.schedule_date + s.end_hour) >= $2
 		GROUP BY u.user_role
@@ -141,7 +193,7 @@ const (
 
 	// Manager Salary
 	queryManagerSalary = `
-		SELECT COALESCE(salary_per_hour, 0)
+		SELECT salary_per_hour, salary_per_hour_encrypted
 		FROM users
 		WHERE id = $1 AND organization_id = $2
 	`
@@ -169,6 +221,7 @@ const (
 		JOIN schedules s ON u.id = s.employee_id
 		WHERE u.organization_id = $1
 		AND u.user_role = 'manager'
+		AND s.shift_type = 'working'
 		AND (s.schedule_date + s.start_hour) <= $2
 		AND (s.schedule_date + s.end_hour) >= $2
 	`
@@ -197,6 +250,7 @@ func (pgis *PostgresInsightStore) GetInsightsForAdmin(org_id uuid.UUID) ([]Insig
 		- Orders Served Today
 		- Number of orders per type (dine in, delivery, takeaway)
 		- Total Revenue
+		- Staff meals and employee discounts this month
 		- Number of employees for every role in the current shift
 		- Most Selling items
 	*/
@@ -233,33 +287,12 @@ func (pgis *PostgresInsightStore) GetInsightsForAdmin(org_id uuid.UUID) ([]Insig
 	}
 
 	// 3. Average Employee Salary
-	var avgSalary float64
-	err = pgis.DB.QueryRow(queryAverageEmployeeSalary, org_id).Scan(&avgSalary)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get average salary: %w", err)
-	}
-	insights = append(insights, Insight{
-		Title:     "Average Employee Salary (per hour)",
-		Statistic: fmt.Sprintf("$%.2f", avgSalary),
-	})
-
 	// 4. Average Salary per role
-	rows, err = pgis.DB.Query(queryAverageSalaryPerRole, org_id)
+	salaryInsights, err := pgis.averageSalaryInsights(org_id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get average salary per role: %w", err)
-	}
-	defer rows.Close()
-	for rows.Next() {
-		var role string
-		var avgRoleSalary float64
-		if err := rows.Scan(&role, &avgRoleSalary); err != nil {
-			return nil, fmt.Errorf("failed to scan average salary per role: %w", err)
-		}
-		insights = append(insights, Insight{
-			Title:     fmt.Sprintf("Average %s Salary (per hour)", role),
-			Statistic: fmt.Sprintf("$%.2f", avgRoleSalary),
-		})
+		return nil, err
 	}
+	insights = append(insights, salaryInsights...)
 
 	// 5. Number of Tables
 	var tableCount int
@@ -346,6 +379,18 @@ func (pgis *PostgresInsightStore) GetInsightsForAdmin(org_id uuid.UUID) ([]Insig
 		Statistic: fmt.Sprintf("$%.2f", totalRevenue),
 	})
 
+	// 11. Staff meals and employee discounts, reported apart from the revenue
+	var staffOrders int
+	var staffDiscount float64
+	err = pgis.DB.QueryRow(queryStaffOrdersThisMonth, org_id).Scan(&staffOrders, &staffDiscount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staff orders: %w", err)
+	}
+	insights = append(insights, Insight{
+		Title:     "Staff Meals & Discounts (This Month)",
+		Statistic: fmt.Sprintf("%d orders, $%.2f", staffOrders, staffDiscount),
+	})
+
 	// 12. Employees per role in current shift
 	rows, err = pgis.DB.Query(queryEmployeesPerRoleCurrentShift, org_id, currentTime)
 
@@ -390,6 +435,7 @@ func (pgis *PostgresInsightStore) GetInsightsForAdmin(org_id uuid.UUID) ([]Insig
 		insights = append(insights, Insight{
 			Title:     "Most Selling Items",
 			Statistic: topItems,
+			DrillDown: DrillDownMostSellingItems,
 		})
 	}
 
@@ -412,8 +458,7 @@ func (pgis *PostgresInsightStore) GetInsightsForManager(org_id, manager_id uuid.
 	var insights []Insight
 
 	// 1. Manager Salary
-	var managerSalary float64
-	err := pgis.DB.QueryRow(queryManagerSalary, manager_id, org_id).Scan(&managerSalary)
+	managerSalary, err := pgis.userSalary(manager_id, org_id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get manager salary: %w", err)
 	}
@@ -530,6 +575,7 @@ func (pgis *PostgresInsightStore) GetInsightsForManager(org_id, manager_id uuid.
 	insights = append(insights, Insight{
 		Title:     "Deliveries Today",
 		Statistic: fmt.Sprintf("%d", deliveriesToday),
+		DrillDown: DrillDownDeliveriesToday,
 	})
 
 	return insights, nil
@@ -552,8 +598,7 @@ func (pgis *PostgresInsightStore) GetInsightsForEmployee(org_id, employee_id uui
 	currentTime := time.Now()
 
 	// 1. Employee Salary
-	var employeeSalary float64
-	err := pgis.DB.QueryRow(queryManagerSalary, employee_id, org_id).Scan(&employeeSalary)
+	employeeSalary, err := pgis.userSalary(employee_id, org_id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get employee salary: %w", err)
 	}
@@ -680,3 +725,202 @@ func (pgis *PostgresInsightStore) GetInsightsForEmployee(org_id, employee_id uui
 
 	return insights, nil
 }
+
+// averageSalaryInsights averages the salaries of the organization and of every role
+func (pgis *PostgresInsightStore) averageSalaryInsights(org_id uuid.UUID) ([]Insight, error) {
+	rows, err := pgis.DB.Query(queryEmployeeSalaries, org_id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get employee salaries: %w", err)
+	}
+	defer rows.Close()
+
+	var total float64
+	var count int
+	var roles []string
+	roleTotals := make(map[string]float64)
+	roleCounts := make(map[string]int)
+	for rows.Next() {
+		var role string
+		var plain sql.NullFloat64
+		var sealed sql.NullString
+		if err := rows.Scan(&role, &plain, &sealed); err != nil {
+			return nil, fmt.Errorf("failed to scan employee salary: %w", err)
+		}
+		if _, ok := roleCounts[role]; !ok {
+			roles = append(roles, role)
+			roleCounts[role] = 0
+		}
+
+		salary, err := openSalary(pgis.Cipher, plain, sealed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt employee salary: %w", err)
+		}
+		if salary == nil {
+			continue
+		}
+		total += *salary
+		count++
+		roleTotals[role] += *salary
+		roleCounts[role]++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get employee salaries: %w", err)
+	}
+
+	insights := []Insight{{
+		Title:     "Average Employee Salary (per hour)",
+		Statistic: fmt.Sprintf("$%.2f", average(total, count)),
+	}}
+	for _, role := range roles {
+		insights = append(insights, Insight{
+			Title:     fmt.Sprintf("Average %s Salary (per hour)", role),
+			Statistic: fmt.Sprintf("$%.2f", average(roleTotals[role], roleCounts[role])),
+		})
+	}
+	return insights, nil
+}
+
+// userSalary returns the salary of a user, 0 for users without one
+func (pgis *PostgresInsightStore) userSalary(user_id, org_id uuid.UUID) (float64, error) {
+	var plain sql.NullFloat64
+	var sealed sql.NullString
+	if err := pgis.DB.QueryRow(queryManagerSalary, user_id, org_id).Scan(&plain, &sealed); err != nil {
+		return 0, err
+	}
+	salary, err := openSalary(pgis.Cipher, plain, sealed)
+	if err != nil || salary == nil {
+		return 0, err
+	}
+	return *salary, nil
+}
+
+func average(total float64, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return total / float64(count)
+}
+
+// GetHourlyOrders returns the orders and net revenue of every hour of the day, all 24 hours in order
+func (pgis *PostgresInsightStore) GetHourlyOrders(org_id uuid.UUID, from, to *time.Time) ([]HourlyOrders, error) {
+	where, args := Where("organization_id = ?", org_id).
+		AndIf(from != nil, "create_time >= ?", from).
+		AndIf(to != nil, "create_time < ?", to).
+		Build()
+	rows, err := pgis.DB.Query(`
+		SELECT EXTRACT(HOUR FROM create_time)::int, COUNT(*), COALESCE(SUM(total_amount - discount_amount), 0)
+		FROM orders
+		WHERE `+where+`
+		GROUP BY 1
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hourly orders: %w", err)
+	}
+	defer rows.Close()
+
+	hours := make([]HourlyOrders, 24)
+	for hour := range hours {
+		hours[hour].Hour = hour
+	}
+	for rows.Next() {
+		var h HourlyOrders
+		if err := rows.Scan(&h.Hour, &h.Orders, &h.Revenue); err != nil {
+			return nil, fmt.Errorf("failed to scan hourly orders: %w", err)
+		}
+		if h.Hour >= 0 && h.Hour < 24 {
+			hours[h.Hour] = h
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get hourly orders: %w", err)
+	}
+	return hours, nil
+}
+
+// GetTopItems returns the limit most ordered items, ranked like the most selling items insight
+func (pgis *PostgresInsightStore) GetTopItems(org_id uuid.UUID, from, to *time.Time, limit int) ([]TopItem, error) {
+	where, args := Where("o.organization_id = ?", org_id).
+		AndIf(from != nil, "o.create_time >= ?", from).
+		AndIf(to != nil, "o.create_time < ?", to).
+		Build()
+	args = append(args, limit)
+	rows, err := pgis.DB.Query(fmt.Sprintf(`
+		SELECT i.id, i.name, COUNT(oi.item_id), COALESCE(SUM(oi.quantity), 0), COALESCE(SUM(oi.total_price), 0)
+		FROM items i
+		JOIN order_items oi ON i.id = oi.item_id
+		JOIN orders o ON oi.order_id = o.id
+		WHERE %s
+		GROUP BY i.id, i.name
+		ORDER BY COUNT(oi.item_id) DESC, i.name
+		LIMIT $%d
+	`, where, len(args)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top items: %w", err)
+	}
+	defer rows.Close()
+
+	sales := []TopItem{}
+	for rows.Next() {
+		var item TopItem
+		if err := rows.Scan(&item.ItemID, &item.Name, &item.SoldCount, &item.Quantity, &item.Revenue); err != nil {
+			return nil, fmt.Errorf("failed to scan top items: %w", err)
+		}
+		sales = append(sales, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get top items: %w", err)
+	}
+	return sales, nil
+}
+
+// GetDeliveryOrdersToday returns the delivery orders created today, the rows counted by the deliveries
+// today insight, newest first
+func (pgis *PostgresInsightStore) GetDeliveryOrdersToday(org_id uuid.UUID) ([]DeliveryOrder, error) {
+	rows, err := pgis.DB.Query(`
+		SELECT o.id, o.create_time, o.order_status, o.total_amount,
+			d.driver_id, u.full_name, d.status, d.out_for_delivery_time, d.delivered_time
+		FROM orders o
+		LEFT JOIN deliveries d ON d.order_id = o.id
+		LEFT JOIN users u ON u.id = d.driver_id
+		WHERE o.organization_id = $1
+		AND o.order_type = 'delivery'
+		AND DATE(o.create_time) = CURRENT_DATE
+		ORDER BY o.create_time DESC
+	`, org_id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deliveries today: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []DeliveryOrder{}
+	for rows.Next() {
+		var order DeliveryOrder
+		var driverID uuid.NullUUID
+		var driverName, status sql.NullString
+		var outForDelivery, delivered sql.NullTime
+		if err := rows.Scan(&order.OrderID, &order.CreateTime, &order.OrderStatus, &order.TotalAmount,
+			&driverID, &driverName, &status, &outForDelivery, &delivered); err != nil {
+			return nil, fmt.Errorf("failed to scan deliveries today: %w", err)
+		}
+		if driverID.Valid {
+			order.DriverID = &driverID.UUID
+		}
+		if driverName.Valid {
+			order.DriverName = &driverName.String
+		}
+		if status.Valid {
+			order.DeliveryStatus = &status.String
+		}
+		if outForDelivery.Valid {
+			order.OutForDeliveryTime = &outForDelivery.Time
+		}
+		if delivered.Valid {
+			order.DeliveredTime = &delivered.Time
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get deliveries today: %w", err)
+	}
+	return orders, nil
+}
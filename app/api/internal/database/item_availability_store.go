@@ -0,0 +1,107 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// ItemAvailabilityWindow is a period in which an item can be ordered. Dates bound the season and
+// times bound the hours of each day in it, a missing bound leaves that side open.
+type ItemAvailabilityWindow struct {
+	ID        uuid.UUID `json:"id"`
+	ItemID    uuid.UUID `json:"item_id"`
+	StartDate *string   `json:"start_date,omitempty"`
+	EndDate   *string   `json:"end_date,omitempty"`
+	StartTime *string   `json:"start_time,omitempty"`
+	EndTime   *string   `json:"end_time,omitempty"`
+}
+
+type ItemAvailabilityStore interface {
+	GetAvailabilityWindows(org_id uuid.UUID) ([]ItemAvailabilityWindow, error)
+	SetItemAvailability(org_id uuid.UUID, item_id uuid.UUID, windows []ItemAvailabilityWindow) error
+}
+
+type PostgresItemAvailabilityStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresItemAvailabilityStore(DB *sql.DB, Logger *slog.Logger) *PostgresItemAvailabilityStore {
+	return &PostgresItemAvailabilityStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// GetAvailabilityWindows lists the availability windows of all items of the organization
+func (s *PostgresItemAvailabilityStore) GetAvailabilityWindows(org_id uuid.UUID) ([]ItemAvailabilityWindow, error) {
+	query := `
+		SELECT id, item_id, TO_CHAR(start_date, 'YYYY-MM-DD'), TO_CHAR(end_date, 'YYYY-MM-DD'),
+			TO_CHAR(start_time, 'HH24:MI'), TO_CHAR(end_time, 'HH24:MI')
+		FROM item_availability
+		WHERE organization_id = $1
+		ORDER BY item_id, start_date NULLS FIRST, start_time NULLS FIRST
+	`
+	rows, err := s.DB.Query(query, org_id)
+	if err != nil {
+		s.Logger.Error("failed to get item availability", "error", err, "organization_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	windows := []ItemAvailabilityWindow{}
+	for rows.Next() {
+		var window ItemAvailabilityWindow
+		if err := rows.Scan(&window.ID, &window.ItemID, &window.StartDate, &window.EndDate, &window.StartTime, &window.EndTime); err != nil {
+			s.Logger.Error("failed to scan item availability", "error", err)
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+	return windows, rows.Err()
+}
+
+// SetItemAvailability replaces the windows of an item, no windows make it always available.
+// Returns sql.ErrNoRows when the item does not belong to the organization.
+func (s *PostgresItemAvailabilityStore) SetItemAvailability(org_id uuid.UUID, item_id uuid.UUID, windows []ItemAvailabilityWindow) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		s.Logger.Error("failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM items WHERE id = $1 AND organization_id = $2)`, item_id, org_id).Scan(&exists); err != nil {
+		s.Logger.Error("failed to check item", "error", err, "item_id", item_id)
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.Exec(`DELETE FROM item_availability WHERE organization_id = $1 AND item_id = $2`, org_id, item_id); err != nil {
+		s.Logger.Error("failed to delete item availability", "error", err, "item_id", item_id)
+		return err
+	}
+
+	insertQuery := `INSERT INTO item_availability (id, organization_id, item_id, start_date, end_date, start_time, end_time)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	for i := range windows {
+		windows[i].ID = uuid.New()
+		windows[i].ItemID = item_id
+		w := windows[i]
+		if _, err := tx.Exec(insertQuery, w.ID, org_id, item_id, w.StartDate, w.EndDate, w.StartTime, w.EndTime); err != nil {
+			s.Logger.Error("failed to insert item availability", "error", err, "item_id", item_id)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.Logger.Error("failed to commit transaction", "error", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrItemPriceChanged is returned when the price of an item changed since the changes were computed
+var ErrItemPriceChanged = errors.New("item price changed")
+
+// ItemPriceChange is a change of the price of an item, as previewed or recorded in its price history
+type ItemPriceChange struct {
+	ItemID    uuid.UUID  `json:"item_id"`
+	ItemName  string     `json:"item_name"`
+	OldPrice  float64    `json:"old_price"`
+	NewPrice  float64    `json:"new_price"`
+	ChangedBy *uuid.UUID `json:"changed_by,omitempty"`
+	Reason    *string    `json:"reason,omitempty"`
+	ChangedAt time.Time  `json:"changed_at"`
+}
+
+type ItemPriceStore interface {
+	ApplyPriceChanges(org_id uuid.UUID, changes []ItemPriceChange) error
+	GetPriceHistory(org_id, item_id uuid.UUID) ([]ItemPriceChange, error)
+}
+
+type PostgresItemPriceStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresItemPriceStore(DB *sql.DB, Logger *slog.Logger) *PostgresItemPriceStore {
+	return &PostgresItemPriceStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// ApplyPriceChanges updates the prices and records them in the price history in one transaction. Each
+// item must still have its old price, otherwise nothing is changed and ErrItemPriceChanged is returned.
+func (s *PostgresItemPriceStore) ApplyPriceChanges(org_id uuid.UUID, changes []ItemPriceChange) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		s.Logger.Error("failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	for i := range changes {
+		change := &changes[i]
+		result, err := tx.Exec(`UPDATE items SET price = $3 WHERE organization_id = $1 AND id = $2 AND price = $4`,
+			org_id, change.ItemID, change.NewPrice, change.OldPrice)
+		if err != nil {
+			s.Logger.Error("failed to update item price", "error", err, "org_id", org_id, "item_id", change.ItemID)
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return ErrItemPriceChanged
+		}
+
+		query := `
+			INSERT INTO item_price_history (organization_id, item_id, old_price, new_price, changed_by, reason)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING changed_at
+		`
+		err = tx.QueryRow(query, org_id, change.ItemID, change.OldPrice, change.NewPrice, change.ChangedBy, change.Reason).
+			Scan(&change.ChangedAt)
+		if err != nil {
+			s.Logger.Error("failed to record item price change", "error", err, "org_id", org_id, "item_id", change.ItemID)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.Logger.Info("item prices changed", "org_id", org_id, "count", len(changes))
+	return nil
+}
+
+// GetPriceHistory lists the price changes of an item, newest first
+func (s *PostgresItemPriceStore) GetPriceHistory(org_id, item_id uuid.UUID) ([]ItemPriceChange, error) {
+	query := `
+		SELECT h.item_id, i.name, h.old_price, h.new_price, h.changed_by, h.reason, h.changed_at
+		FROM item_price_history h
+		JOIN items i ON i.id = h.item_id
+		WHERE h.organization_id = $1 AND h.item_id = $2
+		ORDER BY h.changed_at DESC
+	`
+	rows, err := s.DB.Query(query, org_id, item_id)
+	if err != nil {
+		s.Logger.Error("failed to get item price history", "error", err, "org_id", org_id, "item_id", item_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []ItemPriceChange{}
+	for rows.Next() {
+		var change ItemPriceChange
+		if err := rows.Scan(&change.ItemID, &change.ItemName, &change.OldPrice, &change.NewPrice, &change.ChangedBy,
+			&change.Reason, &change.ChangedAt); err != nil {
+			s.Logger.Error("failed to scan item price change", "error", err)
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
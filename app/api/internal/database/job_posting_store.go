@@ -0,0 +1,366 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrDuplicateApplication = errors.New("already applied to this posting")
+
+const (
+	JobPostingOpen   = "open"
+	JobPostingClosed = "closed"
+
+	JobPostingSourceManual         = "manual"
+	JobPostingSourceRecommendation = "recommendation"
+)
+
+// Applicant pipeline stages, hired and rejected are final
+const (
+	ApplicantStageApplied   = "applied"
+	ApplicantStageScreening = "screening"
+	ApplicantStageInterview = "interview"
+	ApplicantStageTrial     = "trial"
+	ApplicantStageOffer     = "offer"
+	ApplicantStageHired     = "hired"
+	ApplicantStageRejected  = "rejected"
+)
+
+// JobPosting is an open position of an organization. Postings created from a hiring recommendation
+// keep the reason the recommendation gave.
+type JobPosting struct {
+	ID                   uuid.UUID  `json:"id"`
+	OrganizationID       uuid.UUID  `json:"organization_id"`
+	Role                 string     `json:"role"`
+	Title                string     `json:"title"`
+	Description          *string    `json:"description,omitempty"`
+	Openings             int        `json:"openings"`
+	Status               string     `json:"status"`
+	Source               string     `json:"source"`
+	RecommendationReason *string    `json:"recommendation_reason,omitempty"`
+	CreatedBy            *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt            time.Time  `json:"created_at"`
+	ApplicantCount       *int       `json:"applicant_count,omitempty"` // set when listed for managers
+}
+
+// JobApplicant is a person who applied to a posting
+type JobApplicant struct {
+	ID        uuid.UUID `json:"id"`
+	PostingID uuid.UUID `json:"posting_id"`
+	FullName  string    `json:"full_name"`
+	Email     string    `json:"email"`
+	Phone     *string   `json:"phone,omitempty"`
+	Message   *string   `json:"message,omitempty"`
+	Stage     string    `json:"stage"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type JobPostingStore interface {
+	CreatePosting(posting *JobPosting) error
+	GetPostings(org_id uuid.UUID) ([]JobPosting, error)
+	GetOpenPostings(org_id uuid.UUID) ([]JobPosting, error)
+	GetPostingByID(org_id uuid.UUID, id uuid.UUID) (*JobPosting, error)
+	SetPostingStatus(org_id uuid.UUID, id uuid.UUID, status string) error
+	CreateApplicant(org_id uuid.UUID, applicant *JobApplicant) error
+	GetApplicants(org_id uuid.UUID, posting_id uuid.UUID) ([]JobApplicant, error)
+	GetApplicantByID(org_id uuid.UUID, id uuid.UUID) (*JobApplicant, error)
+	SetApplicantStage(org_id uuid.UUID, id uuid.UUID, stage string) error
+}
+
+type PostgresJobPostingStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresJobPostingStore(DB *sql.DB, Logger *slog.Logger) *PostgresJobPostingStore {
+	return &PostgresJobPostingStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+const jobPostingColumns = `p.id, p.organization_id, p.role, p.title, p.description, p.openings, p.status, p.source,
+	p.recommendation_reason, p.created_by, p.created_at`
+
+const jobApplicantColumns = `id, posting_id, full_name, email, phone, message, stage, created_at, updated_at`
+
+// rowScanner is a *sql.Row or *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanJobPosting(row rowScanner, extra ...any) (*JobPosting, error) {
+	var posting JobPosting
+	var createdBy uuid.NullUUID
+	dest := []any{
+		&posting.ID,
+		&posting.OrganizationID,
+		&posting.Role,
+		&posting.Title,
+		&posting.Description,
+		&posting.Openings,
+		&posting.Status,
+		&posting.Source,
+		&posting.RecommendationReason,
+		&createdBy,
+		&posting.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	if createdBy.Valid {
+		posting.CreatedBy = &createdBy.UUID
+	}
+	return &posting, nil
+}
+
+func scanJobApplicant(row rowScanner) (*JobApplicant, error) {
+	var applicant JobApplicant
+	if err := row.Scan(
+		&applicant.ID,
+		&applicant.PostingID,
+		&applicant.FullName,
+		&applicant.Email,
+		&applicant.Phone,
+		&applicant.Message,
+		&applicant.Stage,
+		&applicant.CreatedAt,
+		&applicant.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &applicant, nil
+}
+
+// CreatePosting stores a new posting, filling in its ID and creation time
+func (s *PostgresJobPostingStore) CreatePosting(posting *JobPosting) error {
+	if posting.ID == uuid.Nil {
+		posting.ID = uuid.New()
+	}
+	if posting.CreatedAt.IsZero() {
+		posting.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO job_postings (id, organization_id, role, title, description, openings, status, source, recommendation_reason, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err := s.DB.Exec(query,
+		posting.ID,
+		posting.OrganizationID,
+		posting.Role,
+		posting.Title,
+		posting.Description,
+		posting.Openings,
+		posting.Status,
+		posting.Source,
+		posting.RecommendationReason,
+		posting.CreatedBy,
+		posting.CreatedAt,
+	)
+	if err != nil {
+		s.Logger.Error("failed to create job posting", "error", err, "org_id", posting.OrganizationID)
+		return err
+	}
+
+	s.Logger.Info("job posting created", "id", posting.ID, "org_id", posting.OrganizationID, "role", posting.Role)
+	return nil
+}
+
+// GetPostings lists every posting of the organization with its number of applicants, open postings first
+func (s *PostgresJobPostingStore) GetPostings(org_id uuid.UUID) ([]JobPosting, error) {
+	query := `
+		SELECT ` + jobPostingColumns + `,
+			(SELECT COUNT(*) FROM job_applicants a WHERE a.posting_id = p.id)
+		FROM job_postings p
+		WHERE p.organization_id = $1
+		ORDER BY p.status = 'open' DESC, p.created_at DESC
+	`
+	rows, err := s.DB.Query(query, org_id)
+	if err != nil {
+		s.Logger.Error("failed to get job postings", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	postings := []JobPosting{}
+	for rows.Next() {
+		var applicantCount int
+		posting, err := scanJobPosting(rows, &applicantCount)
+		if err != nil {
+			s.Logger.Error("failed to scan job posting", "error", err)
+			return nil, err
+		}
+		posting.ApplicantCount = &applicantCount
+		postings = append(postings, *posting)
+	}
+	return postings, rows.Err()
+}
+
+// GetOpenPostings lists the postings of the organization that accept applications, newest first
+func (s *PostgresJobPostingStore) GetOpenPostings(org_id uuid.UUID) ([]JobPosting, error) {
+	query := `
+		SELECT ` + jobPostingColumns + `
+		FROM job_postings p
+		WHERE p.organization_id = $1 AND p.status = 'open'
+		ORDER BY p.created_at DESC
+	`
+	rows, err := s.DB.Query(query, org_id)
+	if err != nil {
+		s.Logger.Error("failed to get open job postings", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	postings := []JobPosting{}
+	for rows.Next() {
+		posting, err := scanJobPosting(rows)
+		if err != nil {
+			s.Logger.Error("failed to scan job posting", "error", err)
+			return nil, err
+		}
+		postings = append(postings, *posting)
+	}
+	return postings, rows.Err()
+}
+
+// GetPostingByID retrieves a posting of the organization, returning sql.ErrNoRows if it does not exist
+func (s *PostgresJobPostingStore) GetPostingByID(org_id uuid.UUID, id uuid.UUID) (*JobPosting, error) {
+	query := `SELECT ` + jobPostingColumns + ` FROM job_postings p WHERE p.id = $1 AND p.organization_id = $2`
+	posting, err := scanJobPosting(s.DB.QueryRow(query, id, org_id))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get job posting", "error", err, "id", id)
+		}
+		return nil, err
+	}
+	return posting, nil
+}
+
+// SetPostingStatus opens or closes a posting, returning sql.ErrNoRows if it does not exist
+func (s *PostgresJobPostingStore) SetPostingStatus(org_id uuid.UUID, id uuid.UUID, status string) error {
+	result, err := s.DB.Exec(`UPDATE job_postings SET status = $3 WHERE id = $1 AND organization_id = $2`, id, org_id, status)
+	if err != nil {
+		s.Logger.Error("failed to update job posting status", "error", err, "id", id)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// CreateApplicant stores an application to a posting, filling in its ID, stage and times.
+// Returns ErrDuplicateApplication when the email already applied to the posting.
+func (s *PostgresJobPostingStore) CreateApplicant(org_id uuid.UUID, applicant *JobApplicant) error {
+	if applicant.ID == uuid.Nil {
+		applicant.ID = uuid.New()
+	}
+	if applicant.Stage == "" {
+		applicant.Stage = ApplicantStageApplied
+	}
+	now := time.Now()
+	applicant.CreatedAt = now
+	applicant.UpdatedAt = now
+
+	query := `
+		INSERT INTO job_applicants (id, posting_id, organization_id, full_name, email, phone, message, stage, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+		ON CONFLICT (posting_id, LOWER(email)) DO NOTHING
+	`
+	result, err := s.DB.Exec(query,
+		applicant.ID,
+		applicant.PostingID,
+		org_id,
+		applicant.FullName,
+		applicant.Email,
+		applicant.Phone,
+		applicant.Message,
+		applicant.Stage,
+		now,
+	)
+	if err != nil {
+		s.Logger.Error("failed to create job applicant", "error", err, "posting_id", applicant.PostingID)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrDuplicateApplication
+	}
+
+	s.Logger.Info("job application received", "id", applicant.ID, "posting_id", applicant.PostingID, "org_id", org_id)
+	return nil
+}
+
+// GetApplicants lists the applicants of a posting of the organization, oldest first
+func (s *PostgresJobPostingStore) GetApplicants(org_id uuid.UUID, posting_id uuid.UUID) ([]JobApplicant, error) {
+	query := `
+		SELECT ` + jobApplicantColumns + `
+		FROM job_applicants
+		WHERE organization_id = $1 AND posting_id = $2
+		ORDER BY created_at
+	`
+	rows, err := s.DB.Query(query, org_id, posting_id)
+	if err != nil {
+		s.Logger.Error("failed to get job applicants", "error", err, "posting_id", posting_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	applicants := []JobApplicant{}
+	for rows.Next() {
+		applicant, err := scanJobApplicant(rows)
+		if err != nil {
+			s.Logger.Error("failed to scan job applicant", "error", err)
+			return nil, err
+		}
+		applicants = append(applicants, *applicant)
+	}
+	return applicants, rows.Err()
+}
+
+// GetApplicantByID retrieves an applicant of the organization, returning sql.ErrNoRows if it does not exist
+func (s *PostgresJobPostingStore) GetApplicantByID(org_id uuid.UUID, id uuid.UUID) (*JobApplicant, error) {
+	query := `SELECT ` + jobApplicantColumns + ` FROM job_applicants WHERE id = $1 AND organization_id = $2`
+	applicant, err := scanJobApplicant(s.DB.QueryRow(query, id, org_id))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get job applicant", "error", err, "id", id)
+		}
+		return nil, err
+	}
+	return applicant, nil
+}
+
+// SetApplicantStage moves an applicant through the pipeline, returning sql.ErrNoRows if it does not exist
+func (s *PostgresJobPostingStore) SetApplicantStage(org_id uuid.UUID, id uuid.UUID, stage string) error {
+	query := `UPDATE job_applicants SET stage = $3, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND organization_id = $2`
+	result, err := s.DB.Exec(query, id, org_id, stage)
+	if err != nil {
+		s.Logger.Error("failed to update job applicant stage", "error", err, "id", id)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,334 @@
+package database
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAlreadyClockedIn = errors.New("employee is already clocked in")
+	ErrNotClockedIn     = errors.New("employee is not clocked in")
+)
+
+// KioskDevice is a shared tablet of an organization employees clock in and out on. It authenticates
+// with a device token that only works for its own organization, until the device is revoked.
+type KioskDevice struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	Name           string     `json:"name"`
+	CreatedBy      *uuid.UUID `json:"created_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastSeenAt     *time.Time `json:"last_seen_at"`
+	RevokedAt      *time.Time `json:"revoked_at"`
+}
+
+// KioskPin is the PIN an employee clocks in with on the kiosks of an organization, with the failed
+// attempts tracked like failed logins
+type KioskPin struct {
+	UserID         uuid.UUID
+	OrganizationID uuid.UUID
+	Pin            Password
+	State          LoginState
+}
+
+// KioskEmployee is an employee who can clock in on the kiosks, ClockedInAt is set while clocked in
+type KioskEmployee struct {
+	ID          uuid.UUID  `json:"id"`
+	FullName    string     `json:"full_name"`
+	ClockedInAt *time.Time `json:"clocked_in_at"`
+}
+
+// TimeClockEntry is the time an employee was clocked in, ClockOutAt is nil while still clocked in
+type TimeClockEntry struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	EmployeeID     uuid.UUID  `json:"employee_id"`
+	EmployeeName   string     `json:"employee_name,omitempty"`
+	DeviceID       *uuid.UUID `json:"device_id"`
+	ClockInAt      time.Time  `json:"clock_in_at"`
+	ClockOutAt     *time.Time `json:"clock_out_at"`
+}
+
+type KioskStore interface {
+	CreateDevice(org_id uuid.UUID, device *KioskDevice, token string) error
+	ListDevices(org_id uuid.UUID) ([]KioskDevice, error)
+	RevokeDevice(org_id, device_id uuid.UUID) error
+	GetDeviceByToken(token string) (*KioskDevice, error)
+
+	SetPin(org_id, user_id uuid.UUID, pinHash []byte) error
+	GetPin(org_id, user_id uuid.UUID) (*KioskPin, error)
+	RecordFailedPin(org_id, user_id uuid.UUID, maxAttempts int, lockout time.Duration) (*LoginState, error)
+	ResetFailedPins(org_id, user_id uuid.UUID) error
+	ListKioskEmployees(org_id uuid.UUID) ([]KioskEmployee, error)
+
+	ClockIn(org_id uuid.UUID, entry *TimeClockEntry) error
+	ClockOut(org_id, user_id uuid.UUID, at time.Time) (*TimeClockEntry, error)
+	ListEntries(org_id uuid.UUID, from, to time.Time) ([]TimeClockEntry, error)
+}
+
+type PostgresKioskStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresKioskStore(DB *sql.DB, Logger *slog.Logger) *PostgresKioskStore {
+	return &PostgresKioskStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// HashKioskToken is how device tokens are stored, the tokens themselves are only known to the devices
+func HashKioskToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateDevice registers a kiosk device authenticating with token
+func (s *PostgresKioskStore) CreateDevice(org_id uuid.UUID, device *KioskDevice, token string) error {
+	query := `
+		INSERT INTO kiosk_devices (organization_id, name, token_hash, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	device.OrganizationID = org_id
+	err := s.DB.QueryRow(query, org_id, device.Name, HashKioskToken(token), device.CreatedBy).Scan(&device.ID, &device.CreatedAt)
+	if err != nil {
+		s.Logger.Error("failed to create kiosk device", "error", err, "org_id", org_id)
+		return err
+	}
+	return nil
+}
+
+// ListDevices lists the kiosk devices of the organization, revoked ones included, newest first
+func (s *PostgresKioskStore) ListDevices(org_id uuid.UUID) ([]KioskDevice, error) {
+	query := `
+		SELECT id, organization_id, name, created_by, created_at, last_seen_at, revoked_at
+		FROM kiosk_devices
+		WHERE organization_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := s.DB.Query(query, org_id)
+	if err != nil {
+		s.Logger.Error("failed to list kiosk devices", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	devices := []KioskDevice{}
+	for rows.Next() {
+		var device KioskDevice
+		if err := rows.Scan(&device.ID, &device.OrganizationID, &device.Name, &device.CreatedBy, &device.CreatedAt,
+			&device.LastSeenAt, &device.RevokedAt); err != nil {
+			s.Logger.Error("failed to scan kiosk device", "error", err)
+			return nil, err
+		}
+		devices = append(devices, device)
+	}
+	return devices, rows.Err()
+}
+
+// RevokeDevice stops a device from authenticating, returning sql.ErrNoRows if the organization has no
+// such active device
+func (s *PostgresKioskStore) RevokeDevice(org_id, device_id uuid.UUID) error {
+	query := `UPDATE kiosk_devices SET revoked_at = NOW() WHERE id = $1 AND organization_id = $2 AND revoked_at IS NULL`
+	result, err := s.DB.Exec(query, device_id, org_id)
+	if err != nil {
+		s.Logger.Error("failed to revoke kiosk device", "error", err, "device_id", device_id)
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetDeviceByToken retrieves the active device authenticating with token and records that it was seen,
+// returning sql.ErrNoRows for unknown and revoked tokens
+func (s *PostgresKioskStore) GetDeviceByToken(token string) (*KioskDevice, error) {
+	query := `
+		UPDATE kiosk_devices SET last_seen_at = NOW()
+		WHERE token_hash = $1 AND revoked_at IS NULL
+		RETURNING id, organization_id, name, created_by, created_at, last_seen_at, revoked_at
+	`
+	var device KioskDevice
+	err := s.DB.QueryRow(query, HashKioskToken(token)).Scan(&device.ID, &device.OrganizationID, &device.Name,
+		&device.CreatedBy, &device.CreatedAt, &device.LastSeenAt, &device.RevokedAt)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get kiosk device", "error", err)
+		}
+		return nil, err
+	}
+	return &device, nil
+}
+
+// SetPin sets or replaces the kiosk PIN of a member of the organization, clearing its failed attempts
+func (s *PostgresKioskStore) SetPin(org_id, user_id uuid.UUID, pinHash []byte) error {
+	query := `
+		INSERT INTO kiosk_pins (user_id, organization_id, pin_hash)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, organization_id)
+		DO UPDATE SET pin_hash = EXCLUDED.pin_hash, failed_attempts = 0, locked_until = NULL, updated_at = NOW()
+	`
+	if _, err := s.DB.Exec(query, user_id, org_id, string(pinHash)); err != nil {
+		s.Logger.Error("failed to set kiosk pin", "error", err, "user_id", user_id)
+		return err
+	}
+	return nil
+}
+
+// GetPin retrieves the kiosk PIN of an employee, returning sql.ErrNoRows if they have none
+func (s *PostgresKioskStore) GetPin(org_id, user_id uuid.UUID) (*KioskPin, error) {
+	query := `SELECT pin_hash, failed_attempts, locked_until FROM kiosk_pins WHERE user_id = $1 AND organization_id = $2`
+
+	pin := KioskPin{UserID: user_id, OrganizationID: org_id}
+	var hash string
+	err := s.DB.QueryRow(query, user_id, org_id).Scan(&hash, &pin.State.FailedAttempts, &pin.State.LockedUntil)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get kiosk pin", "error", err, "user_id", user_id)
+		}
+		return nil, err
+	}
+	pin.Pin = NewPasswordFromHash([]byte(hash))
+	return &pin, nil
+}
+
+// RecordFailedPin counts a wrong PIN. On the maxAttempts-th consecutive failure the PIN is locked for
+// the lockout duration and the counter starts over.
+func (s *PostgresKioskStore) RecordFailedPin(org_id, user_id uuid.UUID, maxAttempts int, lockout time.Duration) (*LoginState, error) {
+	query := `
+		UPDATE kiosk_pins
+		SET failed_attempts = CASE WHEN failed_attempts + 1 >= $3 THEN 0 ELSE failed_attempts + 1 END,
+			locked_until = CASE WHEN failed_attempts + 1 >= $3 THEN $4 ELSE locked_until END
+		WHERE user_id = $1 AND organization_id = $2
+		RETURNING failed_attempts, locked_until
+	`
+	var state LoginState
+	err := s.DB.QueryRow(query, user_id, org_id, maxAttempts, time.Now().Add(lockout)).Scan(&state.FailedAttempts, &state.LockedUntil)
+	if err != nil {
+		s.Logger.Error("failed to record failed kiosk pin", "error", err, "user_id", user_id)
+		return nil, err
+	}
+	return &state, nil
+}
+
+// ResetFailedPins clears the failure counter after a correct PIN
+func (s *PostgresKioskStore) ResetFailedPins(org_id, user_id uuid.UUID) error {
+	query := `UPDATE kiosk_pins SET failed_attempts = 0 WHERE user_id = $1 AND organization_id = $2 AND failed_attempts != 0`
+	if _, err := s.DB.Exec(query, user_id, org_id); err != nil {
+		s.Logger.Error("failed to reset failed kiosk pins", "error", err, "user_id", user_id)
+		return err
+	}
+	return nil
+}
+
+// ListKioskEmployees lists the members of the organization with a kiosk PIN by name, with the time they
+// clocked in while they are clocked in
+func (s *PostgresKioskStore) ListKioskEmployees(org_id uuid.UUID) ([]KioskEmployee, error) {
+	query := `
+		SELECT u.id, u.full_name, e.clock_in_at
+		FROM kiosk_pins p
+		INNER JOIN users u ON u.id = p.user_id
+		LEFT JOIN time_clock_entries e
+			ON e.employee_id = p.user_id AND e.organization_id = p.organization_id AND e.clock_out_at IS NULL
+		WHERE p.organization_id = $1
+		ORDER BY u.full_name
+	`
+	rows, err := s.DB.Query(query, org_id)
+	if err != nil {
+		s.Logger.Error("failed to list kiosk employees", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	employees := []KioskEmployee{}
+	for rows.Next() {
+		var employee KioskEmployee
+		if err := rows.Scan(&employee.ID, &employee.FullName, &employee.ClockedInAt); err != nil {
+			s.Logger.Error("failed to scan kiosk employee", "error", err)
+			return nil, err
+		}
+		employees = append(employees, employee)
+	}
+	return employees, rows.Err()
+}
+
+// ClockIn opens a time clock entry, returning ErrAlreadyClockedIn if the employee has an open one
+func (s *PostgresKioskStore) ClockIn(org_id uuid.UUID, entry *TimeClockEntry) error {
+	query := `
+		INSERT INTO time_clock_entries (organization_id, employee_id, device_id, clock_in_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+	entry.OrganizationID = org_id
+	err := s.DB.QueryRow(query, org_id, entry.EmployeeID, entry.DeviceID, entry.ClockInAt).Scan(&entry.ID)
+	if isUniqueViolation(err) {
+		return ErrAlreadyClockedIn
+	}
+	if err != nil {
+		s.Logger.Error("failed to clock in", "error", err, "employee_id", entry.EmployeeID)
+		return err
+	}
+	return nil
+}
+
+// ClockOut closes the open time clock entry of the employee, returning ErrNotClockedIn if there is none
+func (s *PostgresKioskStore) ClockOut(org_id, user_id uuid.UUID, at time.Time) (*TimeClockEntry, error) {
+	query := `
+		UPDATE time_clock_entries SET clock_out_at = GREATEST($3, clock_in_at)
+		WHERE organization_id = $1 AND employee_id = $2 AND clock_out_at IS NULL
+		RETURNING id, organization_id, employee_id, device_id, clock_in_at, clock_out_at
+	`
+	var entry TimeClockEntry
+	err := s.DB.QueryRow(query, org_id, user_id, at).Scan(&entry.ID, &entry.OrganizationID, &entry.EmployeeID,
+		&entry.DeviceID, &entry.ClockInAt, &entry.ClockOutAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotClockedIn
+	}
+	if err != nil {
+		s.Logger.Error("failed to clock out", "error", err, "employee_id", user_id)
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// ListEntries lists the time clock entries of the organization clocked in in [from, to), oldest first
+func (s *PostgresKioskStore) ListEntries(org_id uuid.UUID, from, to time.Time) ([]TimeClockEntry, error) {
+	query := `
+		SELECT e.id, e.organization_id, e.employee_id, u.full_name, e.device_id, e.clock_in_at, e.clock_out_at
+		FROM time_clock_entries e
+		INNER JOIN users u ON u.id = e.employee_id
+		WHERE e.organization_id = $1 AND e.clock_in_at >= $2 AND e.clock_in_at < $3
+		ORDER BY e.clock_in_at, u.full_name
+	`
+	rows, err := s.DB.Query(query, org_id, from, to)
+	if err != nil {
+		s.Logger.Error("failed to list time clock entries", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []TimeClockEntry{}
+	for rows.Next() {
+		var entry TimeClockEntry
+		if err := rows.Scan(&entry.ID, &entry.OrganizationID, &entry.EmployeeID, &entry.EmployeeName, &entry.DeviceID,
+			&entry.ClockInAt, &entry.ClockOutAt); err != nil {
+			s.Logger.Error("failed to scan time clock entry", "error", err)
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,163 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// KPITargets are the targets an organization tracks its weeks against, nil when not tracked. Revenue is
+// after discounts, labor cost a percent of that revenue and the rating the average rating of the orders.
+type KPITargets struct {
+	OrganizationID   uuid.UUID  `json:"organization_id"`
+	WeeklyRevenue    *float64   `json:"weekly_revenue"`
+	LaborCostPercent *float64   `json:"labor_cost_percent"`
+	AverageRating    *float64   `json:"average_rating"`
+	UpdatedBy        *uuid.UUID `json:"updated_by,omitempty"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// KPISales are the revenue and the order ratings of an organization over a period, AverageRating is nil
+// when no order was rated
+type KPISales struct {
+	Revenue       float64
+	AverageRating *float64
+	RatedOrders   int
+}
+
+type KPITargetStore interface {
+	GetTargets(org_id uuid.UUID) (*KPITargets, error)
+	SetTargets(targets *KPITargets) error
+	DeleteTargets(org_id uuid.UUID) error
+	GetSales(org_id uuid.UUID, from, to time.Time) (*KPISales, error)
+	GetOrganizationsForKPIReport(week_start time.Time) ([]uuid.UUID, error)
+	MarkKPIReportSent(org_id uuid.UUID, week_start time.Time) error
+}
+
+type PostgresKPITargetStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresKPITargetStore(DB *sql.DB, Logger *slog.Logger) *PostgresKPITargetStore {
+	return &PostgresKPITargetStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// GetTargets returns the targets of the organization, nil without error when it set none
+func (s *PostgresKPITargetStore) GetTargets(org_id uuid.UUID) (*KPITargets, error) {
+	query := `
+		SELECT organization_id, weekly_revenue, labor_cost_percent, average_rating, updated_by, updated_at
+		FROM kpi_targets WHERE organization_id = $1
+	`
+	var targets KPITargets
+	err := s.DB.QueryRow(query, org_id).Scan(&targets.OrganizationID, &targets.WeeklyRevenue, &targets.LaborCostPercent,
+		&targets.AverageRating, &targets.UpdatedBy, &targets.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		s.Logger.Error("failed to get KPI targets", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	return &targets, nil
+}
+
+// SetTargets replaces the targets of the organization, setting their update time when missing
+func (s *PostgresKPITargetStore) SetTargets(targets *KPITargets) error {
+	if targets.UpdatedAt.IsZero() {
+		targets.UpdatedAt = time.Now()
+	}
+	query := `
+		INSERT INTO kpi_targets (organization_id, weekly_revenue, labor_cost_percent, average_rating, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (organization_id) DO UPDATE SET
+			weekly_revenue = EXCLUDED.weekly_revenue,
+			labor_cost_percent = EXCLUDED.labor_cost_percent,
+			average_rating = EXCLUDED.average_rating,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := s.DB.Exec(query, targets.OrganizationID, targets.WeeklyRevenue, targets.LaborCostPercent, targets.AverageRating,
+		targets.UpdatedBy, targets.UpdatedAt)
+	if err != nil {
+		s.Logger.Error("failed to set KPI targets", "error", err, "org_id", targets.OrganizationID)
+		return err
+	}
+	return nil
+}
+
+// DeleteTargets stops tracking the targets of the organization. It returns sql.ErrNoRows when it set none.
+func (s *PostgresKPITargetStore) DeleteTargets(org_id uuid.UUID) error {
+	result, err := s.DB.Exec(`DELETE FROM kpi_targets WHERE organization_id = $1`, org_id)
+	if err != nil {
+		s.Logger.Error("failed to delete KPI targets", "error", err, "org_id", org_id)
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetSales sums the revenue after discounts and averages the ratings of the orders created in [from, to)
+func (s *PostgresKPITargetStore) GetSales(org_id uuid.UUID, from, to time.Time) (*KPISales, error) {
+	query := `
+		SELECT COALESCE(SUM(total_amount - discount_amount), 0), AVG(rating), COUNT(rating)
+		FROM orders
+		WHERE organization_id = $1 AND create_time >= $2 AND create_time < $3
+	`
+	var sales KPISales
+	if err := s.DB.QueryRow(query, org_id, from, to).Scan(&sales.Revenue, &sales.AverageRating, &sales.RatedOrders); err != nil {
+		s.Logger.Error("failed to get KPI sales", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	return &sales, nil
+}
+
+// GetOrganizationsForKPIReport lists the organizations with KPI targets whose admins were not sent the
+// KPI report of the week starting week_start yet
+func (s *PostgresKPITargetStore) GetOrganizationsForKPIReport(week_start time.Time) ([]uuid.UUID, error) {
+	query := `
+		SELECT t.organization_id
+		FROM kpi_targets t
+		WHERE (t.weekly_revenue IS NOT NULL OR t.labor_cost_percent IS NOT NULL OR t.average_rating IS NOT NULL)
+			AND NOT EXISTS (
+				SELECT 1 FROM kpi_report_emails e
+				WHERE e.organization_id = t.organization_id AND e.week_start = $1
+			)
+		ORDER BY t.organization_id
+	`
+	rows, err := s.DB.Query(query, week_start)
+	if err != nil {
+		s.Logger.Error("failed to get organizations for KPI report", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	orgs := []uuid.UUID{}
+	for rows.Next() {
+		var orgID uuid.UUID
+		if err := rows.Scan(&orgID); err != nil {
+			s.Logger.Error("failed to scan organization row", "error", err)
+			return nil, err
+		}
+		orgs = append(orgs, orgID)
+	}
+	return orgs, rows.Err()
+}
+
+// MarkKPIReportSent records that the KPI report of the week was sent to the organization
+func (s *PostgresKPITargetStore) MarkKPIReportSent(org_id uuid.UUID, week_start time.Time) error {
+	query := `INSERT INTO kpi_report_emails (organization_id, week_start) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	if _, err := s.DB.Exec(query, org_id, week_start); err != nil {
+		s.Logger.Error("failed to mark KPI report sent", "error", err, "org_id", org_id)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoginState is the failed login tracking of an account
+type LoginState struct {
+	FailedAttempts int        `json:"failed_attempts"`
+	LockedUntil    *time.Time `json:"locked_until,omitempty"`
+}
+
+// IsLocked reports whether the account is locked at the given time
+func (s *LoginState) IsLocked(now time.Time) bool {
+	return s.LockedUntil != nil && now.Before(*s.LockedUntil)
+}
+
+type LoginSecurityStore interface {
+	GetLoginState(userID uuid.UUID) (*LoginState, error)
+	RecordFailedLogin(userID uuid.UUID, maxAttempts int, lockout time.Duration) (*LoginState, error)
+	ResetFailedLogins(userID uuid.UUID) error
+	UnlockAccount(userID uuid.UUID) error
+	RememberDevice(userID uuid.UUID, deviceHash, ipAddress, userAgent string) (bool, error)
+}
+
+type PostgresLoginSecurityStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresLoginSecurityStore(DB *sql.DB, Logger *slog.Logger) *PostgresLoginSecurityStore {
+	return &PostgresLoginSecurityStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// GetLoginState retrieves the failed login tracking of a user, returning sql.ErrNoRows if the user does not exist
+func (s *PostgresLoginSecurityStore) GetLoginState(userID uuid.UUID) (*LoginState, error) {
+	query := `SELECT failed_login_attempts, locked_until FROM users WHERE id = $1`
+
+	var state LoginState
+	err := s.DB.QueryRow(query, userID).Scan(&state.FailedAttempts, &state.LockedUntil)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get login state", "error", err, "user_id", userID)
+		}
+		return nil, err
+	}
+	return &state, nil
+}
+
+// RecordFailedLogin counts a failed login. On the maxAttempts-th consecutive failure the account is
+// locked for the lockout duration and the counter starts over.
+func (s *PostgresLoginSecurityStore) RecordFailedLogin(userID uuid.UUID, maxAttempts int, lockout time.Duration) (*LoginState, error) {
+	query := `
+		UPDATE users
+		SET failed_login_attempts = CASE WHEN failed_login_attempts + 1 >= $2 THEN 0 ELSE failed_login_attempts + 1 END,
+			locked_until = CASE WHEN failed_login_attempts + 1 >= $2 THEN $3 ELSE locked_until END
+		WHERE id = $1
+		RETURNING failed_login_attempts, locked_until
+	`
+
+	var state LoginState
+	err := s.DB.QueryRow(query, userID, maxAttempts, time.Now().Add(lockout)).Scan(&state.FailedAttempts, &state.LockedUntil)
+	if err != nil {
+		s.Logger.Error("failed to record failed login", "error", err, "user_id", userID)
+		return nil, err
+	}
+	return &state, nil
+}
+
+// ResetFailedLogins clears the failure counter after a successful login
+func (s *PostgresLoginSecurityStore) ResetFailedLogins(userID uuid.UUID) error {
+	query := `UPDATE users SET failed_login_attempts = 0 WHERE id = $1 AND failed_login_attempts != 0`
+	_, err := s.DB.Exec(query, userID)
+	if err != nil {
+		s.Logger.Error("failed to reset failed logins", "error", err, "user_id", userID)
+	}
+	return err
+}
+
+// UnlockAccount lifts a lockout and clears the failure counter, returning sql.ErrNoRows if the user does not exist
+func (s *PostgresLoginSecurityStore) UnlockAccount(userID uuid.UUID) error {
+	query := `UPDATE users SET failed_login_attempts = 0, locked_until = NULL WHERE id = $1`
+	result, err := s.DB.Exec(query, userID)
+	if err != nil {
+		s.Logger.Error("failed to unlock account", "error", err, "user_id", userID)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RememberDevice records a login from a device and address. It returns true when the user had logged in
+// before but never from this device and address, the first login of an account is not reported as new.
+func (s *PostgresLoginSecurityStore) RememberDevice(userID uuid.UUID, deviceHash, ipAddress, userAgent string) (bool, error) {
+	var knownDevices int
+	err := s.DB.QueryRow(`SELECT COUNT(*) FROM login_devices WHERE user_id = $1`, userID).Scan(&knownDevices)
+	if err != nil {
+		s.Logger.Error("failed to count login devices", "error", err, "user_id", userID)
+		return false, err
+	}
+
+	query := `
+		INSERT INTO login_devices (user_id, device_hash, ip_address, user_agent)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, device_hash, ip_address) DO UPDATE SET last_seen_at = CURRENT_TIMESTAMP
+		RETURNING (xmax = 0)
+	`
+	var inserted bool
+	if err := s.DB.QueryRow(query, userID, deviceHash, ipAddress, userAgent).Scan(&inserted); err != nil {
+		s.Logger.Error("failed to remember login device", "error", err, "user_id", userID)
+		return false, err
+	}
+
+	return inserted && knownDevices > 0, nil
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MagicLinkStore interface {
+	CreateMagicLink(user_id uuid.UUID, token, ip_address string, expires_at time.Time) error
+	CountRecentMagicLinks(user_id uuid.UUID, since time.Time) (int, error)
+	RecordMagicLinkRequest(ip_address string, since time.Time) (int, error)
+	ConsumeMagicLink(token string) (uuid.UUID, error)
+}
+
+type PostgresMagicLinkStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresMagicLinkStore(DB *sql.DB, Logger *slog.Logger) *PostgresMagicLinkStore {
+	return &PostgresMagicLinkStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// CreateMagicLink stores the hash of a login link token of the user
+func (s *PostgresMagicLinkStore) CreateMagicLink(user_id uuid.UUID, token, ip_address string, expires_at time.Time) error {
+	query := `INSERT INTO magic_links (user_id, token_hash, ip_address, expires_at) VALUES ($1, $2, $3, $4)`
+	if _, err := s.DB.Exec(query, user_id, HashSignToken(token), ip_address, expires_at); err != nil {
+		s.Logger.Error("failed to create magic link", "error", err, "user_id", user_id)
+		return err
+	}
+	return nil
+}
+
+// CountRecentMagicLinks counts the links issued to the user since
+func (s *PostgresMagicLinkStore) CountRecentMagicLinks(user_id uuid.UUID, since time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM magic_links WHERE user_id = $1 AND created_at > $2`
+	var count int
+	if err := s.DB.QueryRow(query, user_id, since).Scan(&count); err != nil {
+		s.Logger.Error("failed to count magic links", "error", err, "user_id", user_id)
+		return 0, err
+	}
+	return count, nil
+}
+
+// RecordMagicLinkRequest records a login link request from the IP address and returns the requests it
+// made since, this one included. The requests of every address older than since are deleted.
+func (s *PostgresMagicLinkStore) RecordMagicLinkRequest(ip_address string, since time.Time) (int, error) {
+	// the statements of the query share a snapshot, the count does not see the inserted request
+	query := `
+		WITH expired AS (
+			DELETE FROM magic_link_requests WHERE requested_at <= $2
+		), recorded AS (
+			INSERT INTO magic_link_requests (ip_address) VALUES ($1)
+		)
+		SELECT COUNT(*) + 1 FROM magic_link_requests WHERE ip_address = $1 AND requested_at > $2
+	`
+	var count int
+	if err := s.DB.QueryRow(query, ip_address, since).Scan(&count); err != nil {
+		s.Logger.Error("failed to record magic link request", "error", err, "ip_address", ip_address)
+		return 0, err
+	}
+	return count, nil
+}
+
+// ConsumeMagicLink marks an unexpired link as used and returns its user. Returns sql.ErrNoRows when the
+// token is unknown, expired or already used.
+func (s *PostgresMagicLinkStore) ConsumeMagicLink(token string) (uuid.UUID, error) {
+	query := `
+		UPDATE magic_links SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id
+	`
+	var userID uuid.UUID
+	if err := s.DB.QueryRow(query, HashSignToken(token)).Scan(&userID); err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to consume magic link", "error", err)
+		}
+		return uuid.Nil, err
+	}
+	return userID, nil
+}
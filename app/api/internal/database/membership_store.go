@@ -0,0 +1,146 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrganizationMembership grants a user access to an organization with a role of that organization.
+// Every user is a member of their home organization (users.organization_id).
+type OrganizationMembership struct {
+	UserID           uuid.UUID `json:"user_id"`
+	OrganizationID   uuid.UUID `json:"organization_id"`
+	OrganizationName string    `json:"organization_name"`
+	UserRole         string    `json:"user_role"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type MembershipStore interface {
+	GetMembership(userID uuid.UUID, orgID uuid.UUID) (*OrganizationMembership, error)
+	GetMembershipsForUser(userID uuid.UUID) ([]OrganizationMembership, error)
+	AddMembership(membership *OrganizationMembership) error
+	RemoveMembership(userID uuid.UUID, orgID uuid.UUID) error
+}
+
+type PostgresMembershipStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresMembershipStore(DB *sql.DB, Logger *slog.Logger) *PostgresMembershipStore {
+	return &PostgresMembershipStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// GetMembership retrieves the membership of a user in an organization, returning sql.ErrNoRows if there is none
+func (s *PostgresMembershipStore) GetMembership(userID uuid.UUID, orgID uuid.UUID) (*OrganizationMembership, error) {
+	query := `
+		SELECT m.user_id, m.organization_id, o.name, m.user_role, m.created_at
+		FROM organization_memberships m
+		INNER JOIN organizations o ON m.organization_id = o.id
+		WHERE m.user_id = $1 AND m.organization_id = $2
+	`
+
+	var membership OrganizationMembership
+	err := s.DB.QueryRow(query, userID, orgID).Scan(
+		&membership.UserID,
+		&membership.OrganizationID,
+		&membership.OrganizationName,
+		&membership.UserRole,
+		&membership.CreatedAt,
+	)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get membership", "error", err, "user_id", userID, "org_id", orgID)
+		}
+		return nil, err
+	}
+	return &membership, nil
+}
+
+// GetMembershipsForUser retrieves every organization the user belongs to, oldest membership first
+func (s *PostgresMembershipStore) GetMembershipsForUser(userID uuid.UUID) ([]OrganizationMembership, error) {
+	query := `
+		SELECT m.user_id, m.organization_id, o.name, m.user_role, m.created_at
+		FROM organization_memberships m
+		INNER JOIN organizations o ON m.organization_id = o.id
+		WHERE m.user_id = $1
+		ORDER BY m.created_at
+	`
+
+	rows, err := s.DB.Query(query, userID)
+	if err != nil {
+		s.Logger.Error("failed to get memberships", "error", err, "user_id", userID)
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberships := []OrganizationMembership{}
+	for rows.Next() {
+		var membership OrganizationMembership
+		err := rows.Scan(
+			&membership.UserID,
+			&membership.OrganizationID,
+			&membership.OrganizationName,
+			&membership.UserRole,
+			&membership.CreatedAt,
+		)
+		if err != nil {
+			s.Logger.Error("failed to scan membership row", "error", err)
+			return nil, err
+		}
+		memberships = append(memberships, membership)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return memberships, nil
+}
+
+// AddMembership grants a user access to an organization, updating the role if they are already a member
+func (s *PostgresMembershipStore) AddMembership(membership *OrganizationMembership) error {
+	if membership.CreatedAt.IsZero() {
+		membership.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO organization_memberships (user_id, organization_id, user_role, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, organization_id) DO UPDATE SET user_role = EXCLUDED.user_role
+	`
+	_, err := s.DB.Exec(query, membership.UserID, membership.OrganizationID, membership.UserRole, membership.CreatedAt)
+	if err != nil {
+		s.Logger.Error("failed to add membership", "error", err, "user_id", membership.UserID, "org_id", membership.OrganizationID)
+		return err
+	}
+
+	s.Logger.Info("membership added", "user_id", membership.UserID, "org_id", membership.OrganizationID, "role", membership.UserRole)
+	return nil
+}
+
+// RemoveMembership revokes a user's access to an organization, returning sql.ErrNoRows if they were not a member
+func (s *PostgresMembershipStore) RemoveMembership(userID uuid.UUID, orgID uuid.UUID) error {
+	query := `DELETE FROM organization_memberships WHERE user_id = $1 AND organization_id = $2`
+	result, err := s.DB.Exec(query, userID, orgID)
+	if err != nil {
+		s.Logger.Error("failed to remove membership", "error", err, "user_id", userID, "org_id", orgID)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	s.Logger.Info("membership removed", "user_id", userID, "org_id", orgID)
+	return nil
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of the alerts of the mobile summary
+const (
+	MobileAlertForecastVariance = "forecast_variance"
+	MobileAlertOrdersPaused     = "orders_paused"
+	MobileAlertOrdersReopened   = "orders_reopened"
+)
+
+// PendingApprovals counts what waits for a decision of a manager or admin. Requests only count when
+// the next step of their approval chain is the role's, admins decide every step.
+type PendingApprovals struct {
+	Requests int `json:"requests"`
+	Skills   int `json:"skills"`
+	Total    int `json:"total"`
+}
+
+// MobileAlert is an alert of the day reduced to what a phone shows. Value is the variance in percent for
+// forecast variance alerts and the load in percent for order acceptance changes, nil when unknown.
+type MobileAlert struct {
+	Kind   string    `json:"kind"`
+	At     time.Time `json:"at"`
+	Detail string    `json:"detail"`
+	Value  *float64  `json:"value,omitempty"`
+}
+
+type MobileSummaryStore interface {
+	GetPendingApprovals(org_id uuid.UUID, role string) (*PendingApprovals, error)
+	GetAlertsSince(org_id uuid.UUID, since time.Time, limit int) ([]MobileAlert, error)
+}
+
+type PostgresMobileSummaryStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresMobileSummaryStore(DB *sql.DB, Logger *slog.Logger) *PostgresMobileSummaryStore {
+	return &PostgresMobileSummaryStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// GetPendingApprovals counts the requests waiting for role and the skills waiting for a review. Requests
+// submitted before approval chains have no steps and wait for a manager.
+func (s *PostgresMobileSummaryStore) GetPendingApprovals(org_id uuid.UUID, role string) (*PendingApprovals, error) {
+	query := `
+		SELECT
+			(
+				SELECT COUNT(*)
+				FROM requests r
+				JOIN users u ON u.id = r.employee_id
+				WHERE u.organization_id = $1 AND r.status = 'in queue'
+				AND ($2 = 'admin' OR COALESCE((
+					SELECT a.approver_role FROM request_approvals a
+					WHERE a.request_id = r.request_id AND a.decision IS NULL
+					ORDER BY a.step LIMIT 1
+				), 'manager') = $2)
+			),
+			(SELECT COUNT(*) FROM employee_skills WHERE organization_id = $1 AND status = 'pending')
+	`
+	var pending PendingApprovals
+	if err := s.DB.QueryRow(query, org_id, role).Scan(&pending.Requests, &pending.Skills); err != nil {
+		s.Logger.Error("failed to count pending approvals", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	pending.Total = pending.Requests + pending.Skills
+	return &pending, nil
+}
+
+// GetAlertsSince lists the forecast variance alerts and the automatic order acceptance changes since
+// since, newest first, at most limit of them
+func (s *PostgresMobileSummaryStore) GetAlertsSince(org_id uuid.UUID, since time.Time, limit int) ([]MobileAlert, error) {
+	query := `
+		SELECT kind, at, detail, value FROM (
+			SELECT $3 AS kind, created_at::TIMESTAMPTZ AS at,
+				direction || ' forecast through ' || LPAD(through_hour::TEXT, 2, '0') || ':00, ' || suggestion AS detail,
+				variance_percent::FLOAT8 AS value
+			FROM forecast_variance_alerts
+			WHERE organization_id = $1 AND created_at >= $2
+			UNION ALL
+			SELECT CASE WHEN accepting_orders THEN $4 ELSE $5 END, changed_at,
+				staff_on_shift || ' on shift for ' || GREATEST(forecast_orders, recent_orders) || ' orders',
+				load_percent::FLOAT8
+			FROM order_acceptance_changes
+			WHERE organization_id = $1 AND changed_at >= $2
+		) alerts
+		ORDER BY at DESC
+		LIMIT $6
+	`
+	rows, err := s.DB.Query(query, org_id, since, MobileAlertForecastVariance, MobileAlertOrdersReopened, MobileAlertOrdersPaused, limit)
+	if err != nil {
+		s.Logger.Error("failed to get mobile alerts", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := []MobileAlert{}
+	for rows.Next() {
+		var alert MobileAlert
+		if err := rows.Scan(&alert.Kind, &alert.At, &alert.Detail, &alert.Value); err != nil {
+			s.Logger.Error("failed to scan mobile alert", "error", err)
+			return nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, rows.Err()
+}
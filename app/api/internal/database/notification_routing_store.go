@@ -0,0 +1,133 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Channels a notification can be routed to
+const (
+	RoutingChannelEmail = "email"
+	RoutingChannelSMS   = "sms"
+)
+
+// Who a routed notification is sent to. The manager on duty is a manager whose shift covers the time of
+// the notification.
+const (
+	RoutingOnDutyManager     = "on_duty_manager"
+	RoutingManagers          = "managers"
+	RoutingAdmins            = "admins"
+	RoutingManagersAndAdmins = "managers_and_admins"
+)
+
+// NotificationRoutingRule routes the notifications of an event. WithinHours, when set, limits the rule to
+// events less than that many hours before the next shift of the employee. Rules are tried by position,
+// the first matching one wins.
+type NotificationRoutingRule struct {
+	ID          uuid.UUID  `json:"id"`
+	Event       string     `json:"event"`
+	WithinHours *int       `json:"within_hours"`
+	Channel     string     `json:"channel"`
+	Recipients  string     `json:"recipients"`
+	Position    int        `json:"position"`
+	CreatedBy   *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type NotificationRoutingStore interface {
+	GetRoutingRules(org_id uuid.UUID) ([]NotificationRoutingRule, error)
+	CreateRoutingRule(org_id uuid.UUID, rule *NotificationRoutingRule) error
+	DeleteRoutingRule(org_id uuid.UUID, rule_id uuid.UUID) error
+}
+
+type PostgresNotificationRoutingStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresNotificationRoutingStore(DB *sql.DB, Logger *slog.Logger) *PostgresNotificationRoutingStore {
+	return &PostgresNotificationRoutingStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// GetRoutingRules lists the rules of the organization in the order they are tried
+func (s *PostgresNotificationRoutingStore) GetRoutingRules(org_id uuid.UUID) ([]NotificationRoutingRule, error) {
+	query := `
+		SELECT id, event, within_hours, channel, recipients, position, created_by, created_at
+		FROM notification_routing_rules
+		WHERE organization_id = $1
+		ORDER BY position, created_at
+	`
+	rows, err := s.DB.Query(query, org_id)
+	if err != nil {
+		s.Logger.Error("failed to get notification routing rules", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []NotificationRoutingRule{}
+	for rows.Next() {
+		var rule NotificationRoutingRule
+		var withinHours sql.NullInt32
+		var createdBy uuid.NullUUID
+		if err := rows.Scan(&rule.ID, &rule.Event, &withinHours, &rule.Channel, &rule.Recipients, &rule.Position,
+			&createdBy, &rule.CreatedAt); err != nil {
+			s.Logger.Error("failed to scan notification routing rule", "error", err)
+			return nil, err
+		}
+		if withinHours.Valid {
+			hours := int(withinHours.Int32)
+			rule.WithinHours = &hours
+		}
+		if createdBy.Valid {
+			rule.CreatedBy = &createdBy.UUID
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func (s *PostgresNotificationRoutingStore) CreateRoutingRule(org_id uuid.UUID, rule *NotificationRoutingRule) error {
+	rule.ID = uuid.New()
+	rule.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO notification_routing_rules (id, organization_id, event, within_hours, channel, recipients, position, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := s.DB.Exec(query, rule.ID, org_id, rule.Event, rule.WithinHours, rule.Channel, rule.Recipients, rule.Position,
+		rule.CreatedBy, rule.CreatedAt)
+	if err != nil {
+		s.Logger.Error("failed to create notification routing rule", "error", err, "org_id", org_id)
+		return err
+	}
+
+	s.Logger.Info("notification routing rule created", "org_id", org_id, "rule_id", rule.ID, "event", rule.Event)
+	return nil
+}
+
+// DeleteRoutingRule removes a rule, returning sql.ErrNoRows if the organization has no such rule
+func (s *PostgresNotificationRoutingStore) DeleteRoutingRule(org_id uuid.UUID, rule_id uuid.UUID) error {
+	query := `DELETE FROM notification_routing_rules WHERE id = $1 AND organization_id = $2`
+	result, err := s.DB.Exec(query, rule_id, org_id)
+	if err != nil {
+		s.Logger.Error("failed to delete notification routing rule", "error", err, "org_id", org_id, "rule_id", rule_id)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	s.Logger.Info("notification routing rule deleted", "org_id", org_id, "rule_id", rule_id)
+	return nil
+}
@@ -0,0 +1,214 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Ways a user receives notifications
+const (
+	DigestImmediate = "immediate"
+	DigestHourly    = "hourly"
+	DigestDaily     = "daily"
+)
+
+type NotificationSettings struct {
+	DigestMode   string     `json:"digest_mode"`
+	LastDigestAt *time.Time `json:"last_digest_at,omitempty"`
+}
+
+// PendingNotification waits for the next digest of its user. Notifications sharing a dedup key are kept
+// once, with the summary of the latest and the number of occurrences.
+type PendingNotification struct {
+	ID          uuid.UUID `json:"id"`
+	DedupKey    string    `json:"dedup_key"`
+	Summary     string    `json:"summary"`
+	Occurrences int       `json:"occurrences"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NotificationDigest is the pending notifications of a user that are due to be emailed
+type NotificationDigest struct {
+	UserID        uuid.UUID
+	Email         string
+	FullName      string
+	Notifications []PendingNotification
+}
+
+type NotificationStore interface {
+	GetSettings(user_id uuid.UUID) (*NotificationSettings, error)
+	SetDigestMode(user_id uuid.UUID, mode string) error
+	GetDigestRecipients(emails []string) (map[string]uuid.UUID, error)
+	QueueNotification(user_id uuid.UUID, dedupKey, summary string) error
+	GetDueDigests(now time.Time) ([]NotificationDigest, error)
+	MarkDigestSent(user_id uuid.UUID, notification_ids []uuid.UUID, sentAt time.Time) error
+}
+
+type PostgresNotificationStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresNotificationStore(DB *sql.DB, Logger *slog.Logger) *PostgresNotificationStore {
+	return &PostgresNotificationStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// GetSettings returns the notification settings of the user, immediate emails when never set
+func (s *PostgresNotificationStore) GetSettings(user_id uuid.UUID) (*NotificationSettings, error) {
+	query := `SELECT digest_mode, last_digest_at FROM users_notification_settings WHERE user_id = $1`
+
+	settings := NotificationSettings{DigestMode: DigestImmediate}
+	err := s.DB.QueryRow(query, user_id).Scan(&settings.DigestMode, &settings.LastDigestAt)
+	if err != nil && err != sql.ErrNoRows {
+		s.Logger.Error("failed to get notification settings", "error", err, "user_id", user_id)
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// SetDigestMode changes how the user receives notifications. Daily digests are counted from the change.
+func (s *PostgresNotificationStore) SetDigestMode(user_id uuid.UUID, mode string) error {
+	query := `
+		INSERT INTO users_notification_settings (user_id, digest_mode, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET digest_mode = EXCLUDED.digest_mode, updated_at = EXCLUDED.updated_at
+	`
+	if _, err := s.DB.Exec(query, user_id, mode, time.Now()); err != nil {
+		s.Logger.Error("failed to set digest mode", "error", err, "user_id", user_id)
+		return err
+	}
+	return nil
+}
+
+// GetDigestRecipients returns, among the given emails, the ones of users receiving digests instead of
+// immediate emails, mapped to their user ID
+func (s *PostgresNotificationStore) GetDigestRecipients(emails []string) (map[string]uuid.UUID, error) {
+	query := `
+		SELECT u.email, u.id
+		FROM users u
+		JOIN users_notification_settings ns ON ns.user_id = u.id
+		WHERE u.email = ANY($1) AND ns.digest_mode <> 'immediate'
+	`
+	rows, err := s.DB.Query(query, pq.Array(emails))
+	if err != nil {
+		s.Logger.Error("failed to get digest recipients", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	recipients := make(map[string]uuid.UUID)
+	for rows.Next() {
+		var email string
+		var userID uuid.UUID
+		if err := rows.Scan(&email, &userID); err != nil {
+			s.Logger.Error("failed to scan digest recipient", "error", err)
+			return nil, err
+		}
+		recipients[email] = userID
+	}
+	return recipients, rows.Err()
+}
+
+// QueueNotification adds a notification to the next digest of the user. A notification with the same
+// dedup key still pending is counted again instead, and takes the new summary.
+func (s *PostgresNotificationStore) QueueNotification(user_id uuid.UUID, dedupKey, summary string) error {
+	now := time.Now()
+	query := `
+		INSERT INTO pending_notifications (id, user_id, dedup_key, summary, occurrences, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 1, $5, $5)
+		ON CONFLICT (user_id, dedup_key) DO UPDATE SET
+			summary = EXCLUDED.summary,
+			occurrences = pending_notifications.occurrences + 1,
+			updated_at = EXCLUDED.updated_at
+	`
+	if _, err := s.DB.Exec(query, uuid.New(), user_id, dedupKey, summary, now); err != nil {
+		s.Logger.Error("failed to queue notification", "error", err, "user_id", user_id, "dedup_key", dedupKey)
+		return err
+	}
+	return nil
+}
+
+// GetDueDigests returns the pending notifications of the users whose digest is due: an hour after the
+// last hourly digest, a day after the last daily digest (or the switch to daily digests), and right
+// away for users who went back to immediate emails
+func (s *PostgresNotificationStore) GetDueDigests(now time.Time) ([]NotificationDigest, error) {
+	query := `
+		SELECT u.id, u.email, u.full_name, p.id, p.dedup_key, p.summary, p.occurrences, p.created_at, p.updated_at
+		FROM pending_notifications p
+		JOIN users u ON u.id = p.user_id
+		LEFT JOIN users_notification_settings ns ON ns.user_id = p.user_id
+		WHERE ns.digest_mode IS NULL OR ns.digest_mode = 'immediate'
+			OR (ns.digest_mode = 'hourly' AND COALESCE(ns.last_digest_at, ns.updated_at) <= $1)
+			OR (ns.digest_mode = 'daily' AND COALESCE(ns.last_digest_at, ns.updated_at) <= $2)
+		ORDER BY u.id, p.created_at
+	`
+	rows, err := s.DB.Query(query, now.Add(-time.Hour), now.Add(-24*time.Hour))
+	if err != nil {
+		s.Logger.Error("failed to get due digests", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var digests []NotificationDigest
+	for rows.Next() {
+		var userID uuid.UUID
+		var email, fullName string
+		var notification PendingNotification
+		if err := rows.Scan(
+			&userID,
+			&email,
+			&fullName,
+			&notification.ID,
+			&notification.DedupKey,
+			&notification.Summary,
+			&notification.Occurrences,
+			&notification.CreatedAt,
+			&notification.UpdatedAt,
+		); err != nil {
+			s.Logger.Error("failed to scan pending notification", "error", err)
+			return nil, err
+		}
+		if len(digests) == 0 || digests[len(digests)-1].UserID != userID {
+			digests = append(digests, NotificationDigest{UserID: userID, Email: email, FullName: fullName})
+		}
+		last := &digests[len(digests)-1]
+		last.Notifications = append(last.Notifications, notification)
+	}
+	return digests, rows.Err()
+}
+
+// MarkDigestSent removes the emailed notifications and starts the next digest period of the user
+func (s *PostgresNotificationStore) MarkDigestSent(user_id uuid.UUID, notification_ids []uuid.UUID, sentAt time.Time) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		s.Logger.Error("failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	ids := make([]string, len(notification_ids))
+	for i, id := range notification_ids {
+		ids[i] = id.String()
+	}
+	_, err = tx.Exec(`DELETE FROM pending_notifications WHERE user_id = $1 AND id = ANY($2::uuid[])`, user_id, pq.Array(ids))
+	if err != nil {
+		s.Logger.Error("failed to delete sent notifications", "error", err, "user_id", user_id)
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE users_notification_settings SET last_digest_at = $2 WHERE user_id = $1`, user_id, sentAt)
+	if err != nil {
+		s.Logger.Error("failed to record digest", "error", err, "user_id", user_id)
+		return err
+	}
+
+	return tx.Commit()
+}
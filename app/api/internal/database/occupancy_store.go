@@ -0,0 +1,90 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DiningTable is a table of an organization and how many guests it seats
+type DiningTable struct {
+	TableNo  int `json:"table_no"`
+	Capacity int `json:"capacity"`
+}
+
+// TableSeating is a party seated at a table for an order, taken from order_tables
+type TableSeating struct {
+	OrderID        uuid.UUID `json:"order_id"`
+	TableNo        int       `json:"table_no"`
+	NumberOfPeople int       `json:"number_of_people"`
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+}
+
+type OccupancyStore interface {
+	GetTables(org_id uuid.UUID) ([]DiningTable, error)
+	GetSeatingsBetween(org_id uuid.UUID, from, to time.Time) ([]TableSeating, error)
+}
+
+type PostgresOccupancyStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresOccupancyStore(DB *sql.DB, Logger *slog.Logger) *PostgresOccupancyStore {
+	return &PostgresOccupancyStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// GetTables lists the tables of the organization ordered by number
+func (s *PostgresOccupancyStore) GetTables(org_id uuid.UUID) ([]DiningTable, error) {
+	query := `SELECT table_no, number_of_people FROM tables WHERE organization_id = $1 ORDER BY table_no`
+	rows, err := s.DB.Query(query, org_id)
+	if err != nil {
+		s.Logger.Error("failed to get tables", "error", err, "organization_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := []DiningTable{}
+	for rows.Next() {
+		var table DiningTable
+		if err := rows.Scan(&table.TableNo, &table.Capacity); err != nil {
+			s.Logger.Error("failed to scan table", "error", err)
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+// GetSeatingsBetween lists the seatings overlapping the [from, to] window
+func (s *PostgresOccupancyStore) GetSeatingsBetween(org_id uuid.UUID, from, to time.Time) ([]TableSeating, error) {
+	query := `
+		SELECT order_id, table_no, number_of_people, start_time, end_time
+		FROM order_tables
+		WHERE organization_id = $1 AND start_time <= $3 AND end_time >= $2
+		ORDER BY start_time, table_no
+	`
+	rows, err := s.DB.Query(query, org_id, from, to)
+	if err != nil {
+		s.Logger.Error("failed to get table seatings", "error", err, "organization_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	seatings := []TableSeating{}
+	for rows.Next() {
+		var seating TableSeating
+		if err := rows.Scan(&seating.OrderID, &seating.TableNo, &seating.NumberOfPeople, &seating.StartTime, &seating.EndTime); err != nil {
+			s.Logger.Error("failed to scan table seating", "error", err)
+			return nil, err
+		}
+		seatings = append(seatings, seating)
+	}
+	return seatings, rows.Err()
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OperatingHoursException closes the organization for a whole day regardless of its weekly
+// operating hours, e.g. for a public holiday
+type OperatingHoursException struct {
+	Date      time.Time  `json:"date"`
+	Reason    *string    `json:"reason,omitempty"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type OperatingHoursExceptionStore interface {
+	GetExceptionsBetween(org_id uuid.UUID, from, to time.Time) ([]OperatingHoursException, error)
+	UpsertException(org_id uuid.UUID, exception *OperatingHoursException) error
+	DeleteException(org_id uuid.UUID, date time.Time) error
+}
+
+type PostgresOperatingHoursExceptionStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresOperatingHoursExceptionStore(DB *sql.DB, Logger *slog.Logger) *PostgresOperatingHoursExceptionStore {
+	return &PostgresOperatingHoursExceptionStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// GetExceptionsBetween lists the closed days between from and to (inclusive) in chronological order
+func (s *PostgresOperatingHoursExceptionStore) GetExceptionsBetween(org_id uuid.UUID, from, to time.Time) ([]OperatingHoursException, error) {
+	query := `
+		SELECT exception_date, reason, created_by, created_at
+		FROM organizations_operating_hours_exceptions
+		WHERE organization_id = $1 AND exception_date BETWEEN $2 AND $3
+		ORDER BY exception_date
+	`
+	rows, err := s.DB.Query(query, org_id, from, to)
+	if err != nil {
+		s.Logger.Error("failed to get operating hours exceptions", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	exceptions := []OperatingHoursException{}
+	for rows.Next() {
+		var exception OperatingHoursException
+		var createdBy uuid.NullUUID
+		if err := rows.Scan(&exception.Date, &exception.Reason, &createdBy, &exception.CreatedAt); err != nil {
+			s.Logger.Error("failed to scan operating hours exception", "error", err)
+			return nil, err
+		}
+		if createdBy.Valid {
+			exception.CreatedBy = &createdBy.UUID
+		}
+		exceptions = append(exceptions, exception)
+	}
+	return exceptions, rows.Err()
+}
+
+// UpsertException closes the organization on the day of the exception, replacing the reason when
+// the day is already closed
+func (s *PostgresOperatingHoursExceptionStore) UpsertException(org_id uuid.UUID, exception *OperatingHoursException) error {
+	if exception.CreatedAt.IsZero() {
+		exception.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO organizations_operating_hours_exceptions (organization_id, exception_date, reason, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (organization_id, exception_date) DO UPDATE SET reason = EXCLUDED.reason
+		RETURNING created_at
+	`
+	err := s.DB.QueryRow(query, org_id, exception.Date, exception.Reason, exception.CreatedBy, exception.CreatedAt).Scan(&exception.CreatedAt)
+	if err != nil {
+		s.Logger.Error("failed to upsert operating hours exception", "error", err, "org_id", org_id, "date", exception.Date)
+		return err
+	}
+
+	s.Logger.Info("operating hours exception upserted", "org_id", org_id, "date", exception.Date)
+	return nil
+}
+
+// DeleteException reopens a closed day, returning sql.ErrNoRows if the day was not closed
+func (s *PostgresOperatingHoursExceptionStore) DeleteException(org_id uuid.UUID, date time.Time) error {
+	query := `DELETE FROM organizations_operating_hours_exceptions WHERE organization_id = $1 AND exception_date = $2`
+	result, err := s.DB.Exec(query, org_id, date)
+	if err != nil {
+		s.Logger.Error("failed to delete operating hours exception", "error", err, "org_id", org_id, "date", date)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	s.Logger.Info("operating hours exception deleted", "org_id", org_id, "date", date)
+	return nil
+}
@@ -0,0 +1,202 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrderAcceptanceSettings is the state and the auto-close rules of an organization letting its order
+// acceptance close by itself
+type OrderAcceptanceSettings struct {
+	OrganizationID        uuid.UUID
+	AcceptingOrders       bool
+	OrdersPerStaffHour    int
+	AutoCloseLoadPercent  int
+	AutoReopenLoadPercent int
+	OrdersAutoClosedAt    *time.Time
+}
+
+// OrderLoad is the demand of the current hour against the staff on shift. The demand is the forecast of
+// the hour or the orders of the last hour, whichever is higher.
+type OrderLoad struct {
+	ForecastOrders int `json:"forecast_orders"`
+	RecentOrders   int `json:"recent_orders"`
+	StaffOnShift   int `json:"staff_on_shift"`
+}
+
+// OrderAcceptanceChange is a change of accepting_orders made by the auto-close controller, with the load
+// it was made on. LoadPercent is nil when orders were expected and nobody was on shift.
+type OrderAcceptanceChange struct {
+	ID              uuid.UUID `json:"id"`
+	OrganizationID  uuid.UUID `json:"organization_id"`
+	AcceptingOrders bool      `json:"accepting_orders"`
+	ForecastOrders  int       `json:"forecast_orders"`
+	RecentOrders    int       `json:"recent_orders"`
+	StaffOnShift    int       `json:"staff_on_shift"`
+	Capacity        int       `json:"capacity"`
+	LoadPercent     *float64  `json:"load_percent"`
+	ChangedAt       time.Time `json:"changed_at"`
+}
+
+type OrderAcceptanceStore interface {
+	GetAutoCloseOrganizations() ([]OrderAcceptanceSettings, error)
+	GetOrderLoad(org_id uuid.UUID, at time.Time) (*OrderLoad, error)
+
+	SetAcceptingOrders(change *OrderAcceptanceChange) error
+	ListChanges(org_id uuid.UUID, from, to time.Time) ([]OrderAcceptanceChange, error)
+}
+
+type PostgresOrderAcceptanceStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresOrderAcceptanceStore(DB *sql.DB, Logger *slog.Logger) *PostgresOrderAcceptanceStore {
+	return &PostgresOrderAcceptanceStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// GetAutoCloseOrganizations lists the organizations whose rules turn auto-close on
+func (s *PostgresOrderAcceptanceStore) GetAutoCloseOrganizations() ([]OrderAcceptanceSettings, error) {
+	query := `
+		SELECT organization_id, accepting_orders, orders_per_staff_hour, auto_close_load_percent,
+			auto_reopen_load_percent, orders_auto_closed_at
+		FROM organizations_rules
+		WHERE auto_close_orders
+	`
+	rows, err := s.DB.Query(query)
+	if err != nil {
+		s.Logger.Error("failed to get auto-close organizations", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := []OrderAcceptanceSettings{}
+	for rows.Next() {
+		var setting OrderAcceptanceSettings
+		if err := rows.Scan(&setting.OrganizationID, &setting.AcceptingOrders, &setting.OrdersPerStaffHour,
+			&setting.AutoCloseLoadPercent, &setting.AutoReopenLoadPercent, &setting.OrdersAutoClosedAt); err != nil {
+			s.Logger.Error("failed to scan auto-close organization", "error", err)
+			return nil, err
+		}
+		settings = append(settings, setting)
+	}
+	return settings, rows.Err()
+}
+
+// GetOrderLoad reads the forecast of the hour of at, the orders of the hour before at and the employees on
+// shift at at
+func (s *PostgresOrderAcceptanceStore) GetOrderLoad(org_id uuid.UUID, at time.Time) (*OrderLoad, error) {
+	query := `
+		SELECT
+			(SELECT COALESCE(SUM(order_count), 0) FROM demand WHERE organization_id = $1 AND demand_date = $2 AND hour = $3),
+			(SELECT COUNT(*) FROM orders WHERE organization_id = $1 AND create_time > $4 AND create_time <= $5),
+			(
+				SELECT COUNT(DISTINCT s.employee_id)
+				FROM schedules s
+				JOIN users u ON u.id = s.employee_id
+				WHERE u.organization_id = $1 AND s.shift_type = 'working'
+				AND (s.schedule_date + s.start_hour) <= $5
+				AND (s.schedule_date + s.end_hour) > $5
+			)
+	`
+	var load OrderLoad
+	err := s.DB.QueryRow(query, org_id, at.Format(time.DateOnly), at.Hour(), at.Add(-time.Hour), at).
+		Scan(&load.ForecastOrders, &load.RecentOrders, &load.StaffOnShift)
+	if err != nil {
+		s.Logger.Error("failed to get order load", "error", err, "organization_id", org_id)
+		return nil, err
+	}
+	return &load, nil
+}
+
+// SetAcceptingOrders applies the change to the rules and logs it in one transaction. Closing marks the
+// orders as closed automatically, reopening only applies to orders closed that way, so orders a manager
+// closed stay closed. Returns sql.ErrNoRows when the rules are no longer in the state the change expects.
+func (s *PostgresOrderAcceptanceStore) SetAcceptingOrders(change *OrderAcceptanceChange) error {
+	if change.ID == uuid.Nil {
+		change.ID = uuid.New()
+	}
+	if change.ChangedAt.IsZero() {
+		change.ChangedAt = time.Now()
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		s.Logger.Error("failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE organizations_rules SET accepting_orders = FALSE, orders_auto_closed_at = $2
+		WHERE organization_id = $1 AND auto_close_orders AND accepting_orders
+	`
+	args := []any{change.OrganizationID, change.ChangedAt}
+	if change.AcceptingOrders {
+		query = `
+			UPDATE organizations_rules SET accepting_orders = TRUE, orders_auto_closed_at = NULL
+			WHERE organization_id = $1 AND auto_close_orders AND NOT accepting_orders AND orders_auto_closed_at IS NOT NULL
+		`
+		args = args[:1]
+	}
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		s.Logger.Error("failed to set accepting orders", "error", err, "organization_id", change.OrganizationID)
+		return err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO order_acceptance_changes (id, organization_id, accepting_orders, forecast_orders, recent_orders,
+			staff_on_shift, capacity, load_percent, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, change.ID, change.OrganizationID, change.AcceptingOrders, change.ForecastOrders, change.RecentOrders,
+		change.StaffOnShift, change.Capacity, change.LoadPercent, change.ChangedAt)
+	if err != nil {
+		s.Logger.Error("failed to log order acceptance change", "error", err, "organization_id", change.OrganizationID)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.Logger.Error("failed to commit transaction", "error", err)
+		return err
+	}
+	return nil
+}
+
+// ListChanges lists the changes made from from up to, excluding, to, newest first
+func (s *PostgresOrderAcceptanceStore) ListChanges(org_id uuid.UUID, from, to time.Time) ([]OrderAcceptanceChange, error) {
+	query := `
+		SELECT id, organization_id, accepting_orders, forecast_orders, recent_orders, staff_on_shift, capacity,
+			load_percent, changed_at
+		FROM order_acceptance_changes
+		WHERE organization_id = $1 AND changed_at >= $2 AND changed_at < $3
+		ORDER BY changed_at DESC
+	`
+	rows, err := s.DB.Query(query, org_id, from, to)
+	if err != nil {
+		s.Logger.Error("failed to list order acceptance changes", "error", err, "organization_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []OrderAcceptanceChange{}
+	for rows.Next() {
+		var change OrderAcceptanceChange
+		if err := rows.Scan(&change.ID, &change.OrganizationID, &change.AcceptingOrders, &change.ForecastOrders,
+			&change.RecentOrders, &change.StaffOnShift, &change.Capacity, &change.LoadPercent, &change.ChangedAt); err != nil {
+			s.Logger.Error("failed to scan order acceptance change", "error", err)
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
@@ -0,0 +1,472 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// States of a staged orders import
+const (
+	OrderImportStaged    = "staged"
+	OrderImportPromoted  = "promoted"
+	OrderImportDiscarded = "discarded"
+)
+
+// States of a row of a staged import. Rows are pending until the import is validated.
+const (
+	OrderImportRowPending   = "pending"
+	OrderImportRowValid     = "valid"
+	OrderImportRowInvalid   = "invalid"
+	OrderImportRowDuplicate = "duplicate"
+)
+
+var ErrOrderImportNotStaged = errors.New("order import was already promoted or discarded")
+
+// OrderImport is an orders file loaded into the staging area. Its rows reach the orders table only once
+// it is promoted, all together.
+type OrderImport struct {
+	ID              uuid.UUID  `json:"id"`
+	OrganizationID  uuid.UUID  `json:"organization_id"`
+	UploadedBy      *uuid.UUID `json:"uploaded_by"`
+	FileName        string     `json:"file_name"`
+	Status          string     `json:"status"`
+	TotalRows       int        `json:"total_rows"`
+	ValidRows       int        `json:"valid_rows"`
+	InvalidRows     int        `json:"invalid_rows"`
+	DuplicateRows   int        `json:"duplicate_rows"`
+	PromotedRows    int        `json:"promoted_rows"`
+	RedemptionCount int        `json:"redemption_count"`
+	CreatedAt       time.Time  `json:"created_at"`
+	PromotedAt      *time.Time `json:"promoted_at"`
+}
+
+// OrderImportRow is a line of a staged file. The typed fields are nil when the value could not be parsed,
+// Error telling why the row is invalid or a duplicate.
+type OrderImportRow struct {
+	Line           int        `json:"line"`
+	OrderID        *uuid.UUID `json:"order_id"`
+	UserID         *uuid.UUID `json:"user_id"`
+	CreateTime     *time.Time `json:"create_time"`
+	OrderType      string     `json:"order_type"`
+	OrderStatus    string     `json:"order_status"`
+	TotalAmount    *float64   `json:"total_amount"`
+	DiscountAmount *float64   `json:"discount_amount"`
+	Rating         *float64   `json:"rating"`
+	Channel        string     `json:"channel"`
+	RedemptionCode string     `json:"redemption_code"`
+	Status         string     `json:"status"`
+	Error          *string    `json:"error"`
+}
+
+type OrderImportStore interface {
+	CreateOrderImport(orderImport *OrderImport) error
+	StageOrderImportRows(import_id uuid.UUID, rows []OrderImportRow) error
+	ValidateOrderImport(org_id uuid.UUID, import_id uuid.UUID) (*OrderImport, error)
+	GetOrderImports(org_id uuid.UUID) ([]OrderImport, error)
+	GetOrderImport(org_id uuid.UUID, import_id uuid.UUID) (*OrderImport, error)
+	GetOrderImportProblems(import_id uuid.UUID, limit int) ([]OrderImportRow, error)
+	PromoteOrderImport(org_id uuid.UUID, import_id uuid.UUID) (*OrderImport, error)
+	DiscardOrderImport(org_id uuid.UUID, import_id uuid.UUID) error
+}
+
+type PostgresOrderImportStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresOrderImportStore(DB *sql.DB, Logger *slog.Logger) *PostgresOrderImportStore {
+	return &PostgresOrderImportStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+const orderImportColumns = `id, organization_id, uploaded_by, file_name, status, total_rows, valid_rows, invalid_rows,
+	duplicate_rows, promoted_rows, redemption_count, created_at, promoted_at`
+
+func scanOrderImport(row rowScanner) (*OrderImport, error) {
+	var orderImport OrderImport
+	var uploadedBy uuid.NullUUID
+	var promotedAt sql.NullTime
+	if err := row.Scan(&orderImport.ID, &orderImport.OrganizationID, &uploadedBy, &orderImport.FileName, &orderImport.Status,
+		&orderImport.TotalRows, &orderImport.ValidRows, &orderImport.InvalidRows, &orderImport.DuplicateRows,
+		&orderImport.PromotedRows, &orderImport.RedemptionCount, &orderImport.CreatedAt, &promotedAt); err != nil {
+		return nil, err
+	}
+	if uploadedBy.Valid {
+		orderImport.UploadedBy = &uploadedBy.UUID
+	}
+	if promotedAt.Valid {
+		orderImport.PromotedAt = &promotedAt.Time
+	}
+	return &orderImport, nil
+}
+
+// CreateOrderImport opens an import in the staging area, filling in its ID, status and creation time
+func (s *PostgresOrderImportStore) CreateOrderImport(orderImport *OrderImport) error {
+	query := `
+		INSERT INTO order_imports (organization_id, uploaded_by, file_name)
+		VALUES ($1, $2, $3)
+		RETURNING id, status, created_at
+	`
+	err := s.DB.QueryRow(query, orderImport.OrganizationID, orderImport.UploadedBy, orderImport.FileName).
+		Scan(&orderImport.ID, &orderImport.Status, &orderImport.CreatedAt)
+	if err != nil {
+		s.Logger.Error("failed to create order import", "error", err, "org_id", orderImport.OrganizationID)
+		return err
+	}
+	return nil
+}
+
+// Rows of a staged import inserted by one statement, 14 parameters each
+const orderImportStageChunk = 1000
+
+// StageOrderImportRows loads rows of a file into the staging area with a multi-row insert per
+// orderImportStageChunk rows. Rows without a status are pending validation.
+func (s *PostgresOrderImportStore) StageOrderImportRows(import_id uuid.UUID, rows []OrderImportRow) error {
+	for start := 0; start < len(rows); start += orderImportStageChunk {
+		chunk := rows[start:min(start+orderImportStageChunk, len(rows))]
+
+		var values strings.Builder
+		args := make([]any, 0, len(chunk)*14)
+		for i, row := range chunk {
+			if i > 0 {
+				values.WriteString(", ")
+			}
+			n := i * 14
+			fmt.Fprintf(&values, "($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+				n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9, n+10, n+11, n+12, n+13, n+14)
+			status := row.Status
+			if status == "" {
+				status = OrderImportRowPending
+			}
+			args = append(args, import_id, row.Line, row.OrderID, row.UserID, row.CreateTime, row.OrderType, row.OrderStatus,
+				row.TotalAmount, row.DiscountAmount, row.Rating, row.Channel, row.RedemptionCode, status, row.Error)
+		}
+
+		query := `
+			INSERT INTO order_import_rows (import_id, line, order_id, user_id, create_time, order_type, order_status,
+				total_amount, discount_amount, rating, channel, redemption_code, status, error)
+			VALUES ` + values.String()
+		if _, err := s.DB.Exec(query, args...); err != nil {
+			s.Logger.Error("failed to stage order import rows", "error", err, "import_id", import_id, "count", len(chunk))
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateOrderImport checks the pending rows of an import against the constraints of the orders table,
+// then marks as duplicates the rows repeating the order_id of an earlier line or of an order already
+// stored. Each check is one statement over every row of the import. Returns the import with its counts,
+// sql.ErrNoRows when it does not exist.
+func (s *PostgresOrderImportStore) ValidateOrderImport(org_id uuid.UUID, import_id uuid.UUID) (*OrderImport, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		s.Logger.Error("failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRow(`SELECT status FROM order_imports WHERE id = $1 AND organization_id = $2 FOR UPDATE`, import_id, org_id).Scan(&status)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get order import", "error", err, "import_id", import_id)
+		}
+		return nil, err
+	}
+	if status != OrderImportStaged {
+		return nil, ErrOrderImportNotStaged
+	}
+
+	steps := []struct {
+		name  string
+		query string
+		args  []any
+	}{
+		{"constraints", `
+			UPDATE order_import_rows r SET status = 'invalid', error = p.problem
+			FROM (
+				SELECT line, CASE
+					WHEN order_type NOT IN ('delivery', 'takeaway', 'dine in') THEN 'order_type must be one of delivery, takeaway, dine in'
+					WHEN order_status NOT IN ('completed', 'incompleted') THEN 'order_status must be one of completed, incompleted'
+					WHEN total_amount < 0 OR total_amount >= 100000000 THEN 'total_amount must be a positive number under 100000000'
+					WHEN discount_amount < 0 THEN 'discount_amount must be a positive number'
+					WHEN discount_amount > total_amount THEN 'discount_amount cannot exceed total_amount'
+					WHEN rating >= 100000000 THEN 'rating must be under 100000000'
+					WHEN channel <> '' AND NOT channel = ANY($2::text[]) THEN 'channel must be one of ' || array_to_string($2::text[], ', ')
+				END AS problem
+				FROM order_import_rows
+				WHERE import_id = $1 AND status = 'pending'
+			) p
+			WHERE r.import_id = $1 AND r.line = p.line AND p.problem IS NOT NULL
+		`, []any{import_id, pq.Array(OrderChannels)}},
+		{"repeated lines", `
+			UPDATE order_import_rows r SET status = 'duplicate', error = 'order_id repeats line ' || d.first_line
+			FROM (
+				SELECT line, MIN(line) OVER (PARTITION BY order_id) AS first_line
+				FROM order_import_rows
+				WHERE import_id = $1 AND status = 'pending'
+			) d
+			WHERE r.import_id = $1 AND r.line = d.line AND d.line <> d.first_line
+		`, []any{import_id}},
+		{"stored orders", `
+			UPDATE order_import_rows r SET status = 'duplicate', error = 'order_id was already imported'
+			FROM orders o
+			WHERE r.import_id = $1 AND r.status = 'pending' AND o.id = r.order_id
+		`, []any{import_id}},
+		{"valid rows", `
+			UPDATE order_import_rows SET status = 'valid' WHERE import_id = $1 AND status = 'pending'
+		`, []any{import_id}},
+	}
+	for _, step := range steps {
+		if _, err := tx.Exec(step.query, step.args...); err != nil {
+			s.Logger.Error("failed to validate order import", "error", err, "import_id", import_id, "step", step.name)
+			return nil, err
+		}
+	}
+
+	query := `
+		UPDATE order_imports SET total_rows = c.total, valid_rows = c.valid, invalid_rows = c.invalid, duplicate_rows = c.duplicate
+		FROM (
+			SELECT COUNT(*) AS total,
+				COUNT(*) FILTER (WHERE status = 'valid') AS valid,
+				COUNT(*) FILTER (WHERE status = 'invalid') AS invalid,
+				COUNT(*) FILTER (WHERE status = 'duplicate') AS duplicate
+			FROM order_import_rows
+			WHERE import_id = $1
+		) c
+		WHERE id = $1
+		RETURNING ` + orderImportColumns
+	orderImport, err := scanOrderImport(tx.QueryRow(query, import_id))
+	if err != nil {
+		s.Logger.Error("failed to count order import rows", "error", err, "import_id", import_id)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.Logger.Error("failed to commit order import validation", "error", err, "import_id", import_id)
+		return nil, err
+	}
+	return orderImport, nil
+}
+
+// GetOrderImports lists the imports of the organization, newest first
+func (s *PostgresOrderImportStore) GetOrderImports(org_id uuid.UUID) ([]OrderImport, error) {
+	query := `SELECT ` + orderImportColumns + ` FROM order_imports WHERE organization_id = $1 ORDER BY created_at DESC`
+	rows, err := s.DB.Query(query, org_id)
+	if err != nil {
+		s.Logger.Error("failed to get order imports", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	imports := []OrderImport{}
+	for rows.Next() {
+		orderImport, err := scanOrderImport(rows)
+		if err != nil {
+			s.Logger.Error("failed to scan order import", "error", err)
+			return nil, err
+		}
+		imports = append(imports, *orderImport)
+	}
+	return imports, rows.Err()
+}
+
+// GetOrderImport retrieves an import of the organization, returning sql.ErrNoRows if it does not exist
+func (s *PostgresOrderImportStore) GetOrderImport(org_id uuid.UUID, import_id uuid.UUID) (*OrderImport, error) {
+	query := `SELECT ` + orderImportColumns + ` FROM order_imports WHERE id = $1 AND organization_id = $2`
+	orderImport, err := scanOrderImport(s.DB.QueryRow(query, import_id, org_id))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get order import", "error", err, "import_id", import_id)
+		}
+		return nil, err
+	}
+	return orderImport, nil
+}
+
+// GetOrderImportProblems lists the first limit invalid and duplicate rows of an import, by line
+func (s *PostgresOrderImportStore) GetOrderImportProblems(import_id uuid.UUID, limit int) ([]OrderImportRow, error) {
+	query := `
+		SELECT line, order_id, user_id, create_time, COALESCE(order_type, ''), COALESCE(order_status, ''), total_amount,
+			discount_amount, rating, COALESCE(channel, ''), COALESCE(redemption_code, ''), status, error
+		FROM order_import_rows
+		WHERE import_id = $1 AND status IN ('invalid', 'duplicate')
+		ORDER BY line
+		LIMIT $2
+	`
+	rows, err := s.DB.Query(query, import_id, limit)
+	if err != nil {
+		s.Logger.Error("failed to get order import problems", "error", err, "import_id", import_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	problems := []OrderImportRow{}
+	for rows.Next() {
+		var row OrderImportRow
+		var orderID, userID uuid.NullUUID
+		var createTime sql.NullTime
+		if err := rows.Scan(&row.Line, &orderID, &userID, &createTime, &row.OrderType, &row.OrderStatus, &row.TotalAmount,
+			&row.DiscountAmount, &row.Rating, &row.Channel, &row.RedemptionCode, &row.Status, &row.Error); err != nil {
+			s.Logger.Error("failed to scan order import row", "error", err)
+			return nil, err
+		}
+		if orderID.Valid {
+			row.OrderID = &orderID.UUID
+		}
+		if userID.Valid {
+			row.UserID = &userID.UUID
+		}
+		if createTime.Valid {
+			row.CreateTime = &createTime.Time
+		}
+		problems = append(problems, row)
+	}
+	return problems, rows.Err()
+}
+
+// PromoteOrderImport moves the valid rows of a staged import into the orders table in one transaction and
+// links them to the campaigns whose redemption code they used. Rows whose order was stored since the
+// import was validated become duplicates. The promoted rows leave the staging area, the invalid and
+// duplicate ones stay for review. Returns sql.ErrNoRows when the import does not exist and
+// ErrOrderImportNotStaged when it was already promoted or discarded.
+func (s *PostgresOrderImportStore) PromoteOrderImport(org_id uuid.UUID, import_id uuid.UUID) (*OrderImport, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		s.Logger.Error("failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRow(`SELECT status FROM order_imports WHERE id = $1 AND organization_id = $2 FOR UPDATE`, import_id, org_id).Scan(&status)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get order import", "error", err, "import_id", import_id)
+		}
+		return nil, err
+	}
+	if status != OrderImportStaged {
+		return nil, ErrOrderImportNotStaged
+	}
+
+	result, err := tx.Exec(`
+		UPDATE order_import_rows r SET status = 'duplicate', error = 'order_id was already imported'
+		FROM orders o
+		WHERE r.import_id = $1 AND r.status = 'valid' AND o.id = r.order_id
+	`, import_id)
+	if err != nil {
+		s.Logger.Error("failed to check order import duplicates", "error", err, "import_id", import_id)
+		return nil, err
+	}
+	late, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err = tx.Exec(`
+		INSERT INTO orders (id, user_id, organization_id, create_time, order_type, order_status, total_amount, discount_amount, rating, channel)
+		SELECT order_id, user_id, $2, create_time, order_type, order_status, total_amount, discount_amount, rating, COALESCE(NULLIF(channel, ''), 'direct')
+		FROM order_import_rows
+		WHERE import_id = $1 AND status = 'valid'
+		ON CONFLICT (id) DO NOTHING
+	`, import_id, org_id)
+	if err != nil {
+		s.Logger.Error("failed to promote order import", "error", err, "import_id", import_id)
+		return nil, err
+	}
+	promoted, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err = tx.Exec(`
+		INSERT INTO campaign_redemptions (campaign_id, order_id, organization_id, redeemed_at)
+		SELECT mc.id, r.order_id, mc.organization_id, r.create_time
+		FROM order_import_rows r
+		JOIN marketing_campaigns mc ON mc.organization_id = $2 AND UPPER(mc.redemption_code) = UPPER(r.redemption_code)
+			AND mc.start_time_date <= r.create_time AND mc.end_time_date >= r.create_time
+		WHERE r.import_id = $1 AND r.status = 'valid' AND r.redemption_code <> ''
+		ON CONFLICT DO NOTHING
+	`, import_id, org_id)
+	if err != nil {
+		s.Logger.Error("failed to record order import redemptions", "error", err, "import_id", import_id)
+		return nil, err
+	}
+	redemptions, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM order_import_rows WHERE import_id = $1 AND status = 'valid'`, import_id); err != nil {
+		s.Logger.Error("failed to clear promoted order import rows", "error", err, "import_id", import_id)
+		return nil, err
+	}
+
+	query := `
+		UPDATE order_imports
+		SET status = 'promoted', valid_rows = valid_rows - $2, duplicate_rows = duplicate_rows + $2,
+			promoted_rows = $3, redemption_count = $4, promoted_at = NOW()
+		WHERE id = $1
+		RETURNING ` + orderImportColumns
+	orderImport, err := scanOrderImport(tx.QueryRow(query, import_id, late, promoted, redemptions))
+	if err != nil {
+		s.Logger.Error("failed to mark order import promoted", "error", err, "import_id", import_id)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.Logger.Error("failed to commit order import promotion", "error", err, "import_id", import_id)
+		return nil, err
+	}
+	s.Logger.Info("order import promoted", "import_id", import_id, "org_id", org_id, "orders", promoted)
+	return orderImport, nil
+}
+
+// DiscardOrderImport drops the rows of a staged import, keeping its counts. Returns sql.ErrNoRows when the
+// import does not exist and ErrOrderImportNotStaged when it was already promoted or discarded.
+func (s *PostgresOrderImportStore) DiscardOrderImport(org_id uuid.UUID, import_id uuid.UUID) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		s.Logger.Error("failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRow(`SELECT status FROM order_imports WHERE id = $1 AND organization_id = $2 FOR UPDATE`, import_id, org_id).Scan(&status)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get order import", "error", err, "import_id", import_id)
+		}
+		return err
+	}
+	if status != OrderImportStaged {
+		return ErrOrderImportNotStaged
+	}
+
+	if _, err := tx.Exec(`DELETE FROM order_import_rows WHERE import_id = $1`, import_id); err != nil {
+		s.Logger.Error("failed to clear order import rows", "error", err, "import_id", import_id)
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE order_imports SET status = 'discarded' WHERE id = $1`, import_id); err != nil {
+		s.Logger.Error("failed to discard order import", "error", err, "import_id", import_id)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.Logger.Error("failed to commit order import discard", "error", err, "import_id", import_id)
+		return err
+	}
+	return nil
+}
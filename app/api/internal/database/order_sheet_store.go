@@ -0,0 +1,305 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Statuses of a sync of an order sheet
+const (
+	OrderSheetRunSuccess = "success"
+	OrderSheetRunFailed  = "failed"
+)
+
+// OrderSheetSync is a Google Sheet an organization keeps its order log in. Its new rows are imported
+// periodically like an orders CSV upload. ColumnMapping maps the columns of an orders CSV to the headers of
+// the sheet, columns it leaves out are read from headers of the same name. Credentials is the service
+// account key used to read sheets that are not shared by link, it is never sent back to clients.
+type OrderSheetSync struct {
+	ID             uuid.UUID         `json:"id"`
+	OrganizationID uuid.UUID         `json:"-"`
+	Name           string            `json:"name"`
+	SheetURL       string            `json:"sheet_url"`
+	Credentials    *string           `json:"-"`
+	ColumnMapping  map[string]string `json:"column_mapping"`
+	Enabled        bool              `json:"enabled"`
+	RowsImported   int               `json:"rows_imported"`
+	LastSyncedAt   *time.Time        `json:"last_synced_at,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// OrderSheetRun is the result of a sync of an order sheet. TotalRows are the new rows read from the sheet.
+type OrderSheetRun struct {
+	ID             uuid.UUID `json:"id"`
+	SyncID         uuid.UUID `json:"sync_id"`
+	StartedAt      time.Time `json:"started_at"`
+	FinishedAt     time.Time `json:"finished_at"`
+	Status         string    `json:"status"`
+	TotalRows      int       `json:"total_rows"`
+	SuccessCount   int       `json:"success_count"`
+	ErrorCount     int       `json:"error_count"`
+	DuplicateCount int       `json:"duplicate_count"`
+	RejectedCount  int       `json:"rejected_count"`
+	Error          *string   `json:"error,omitempty"`
+}
+
+type OrderSheetStore interface {
+	ListSyncs(org_id uuid.UUID) ([]OrderSheetSync, error)
+	GetSync(org_id, sync_id uuid.UUID) (*OrderSheetSync, error)
+	CreateSync(org_id uuid.UUID, sheet *OrderSheetSync) error
+	UpdateSync(org_id uuid.UUID, sheet *OrderSheetSync) error
+	DeleteSync(org_id, sync_id uuid.UUID) error
+	GetEnabledSyncs() ([]OrderSheetSync, error)
+	MarkSynced(sync_id uuid.UUID, rowsImported int, at time.Time) error
+	RecordRun(run *OrderSheetRun) error
+	ListRuns(org_id, sync_id uuid.UUID, limit int) ([]OrderSheetRun, error)
+}
+
+// PostgresOrderSheetStore seals the service account keys with Cipher when it is set
+type PostgresOrderSheetStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+	Cipher FieldCipher
+}
+
+func NewPostgresOrderSheetStore(DB *sql.DB, Logger *slog.Logger, cipher FieldCipher) *PostgresOrderSheetStore {
+	return &PostgresOrderSheetStore{
+		DB:     DB,
+		Logger: Logger,
+		Cipher: cipher,
+	}
+}
+
+const orderSheetColumns = `id, organization_id, name, sheet_url, credentials, column_mapping, enabled, rows_imported, last_synced_at, created_at, updated_at`
+
+func (s *PostgresOrderSheetStore) scanSync(row rowScanner) (*OrderSheetSync, error) {
+	var sheet OrderSheetSync
+	var credentials sql.NullString
+	var mapping []byte
+	if err := row.Scan(
+		&sheet.ID,
+		&sheet.OrganizationID,
+		&sheet.Name,
+		&sheet.SheetURL,
+		&credentials,
+		&mapping,
+		&sheet.Enabled,
+		&sheet.RowsImported,
+		&sheet.LastSyncedAt,
+		&sheet.CreatedAt,
+		&sheet.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	sheet.ColumnMapping = map[string]string{}
+	if err := json.Unmarshal(mapping, &sheet.ColumnMapping); err != nil {
+		return nil, err
+	}
+	var err error
+	if sheet.Credentials, err = openString(s.Cipher, credentials); err != nil {
+		return nil, err
+	}
+	return &sheet, nil
+}
+
+func (s *PostgresOrderSheetStore) querySyncs(query string, args ...any) ([]OrderSheetSync, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		s.Logger.Error("failed to get order sheets", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	sheets := []OrderSheetSync{}
+	for rows.Next() {
+		sheet, err := s.scanSync(rows)
+		if err != nil {
+			s.Logger.Error("failed to scan order sheet", "error", err)
+			return nil, err
+		}
+		sheets = append(sheets, *sheet)
+	}
+	return sheets, rows.Err()
+}
+
+// ListSyncs lists the order sheets of the organization by name
+func (s *PostgresOrderSheetStore) ListSyncs(org_id uuid.UUID) ([]OrderSheetSync, error) {
+	return s.querySyncs(`
+		SELECT `+orderSheetColumns+`
+		FROM order_sheet_syncs
+		WHERE organization_id = $1
+		ORDER BY name, created_at
+	`, org_id)
+}
+
+// GetSync returns an order sheet of the organization, or sql.ErrNoRows
+func (s *PostgresOrderSheetStore) GetSync(org_id, sync_id uuid.UUID) (*OrderSheetSync, error) {
+	row := s.DB.QueryRow(`
+		SELECT `+orderSheetColumns+`
+		FROM order_sheet_syncs
+		WHERE organization_id = $1 AND id = $2
+	`, org_id, sync_id)
+
+	sheet, err := s.scanSync(row)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.Logger.Error("failed to get order sheet", "error", err, "sync_id", sync_id)
+		}
+		return nil, err
+	}
+	return sheet, nil
+}
+
+// CreateSync connects an order sheet to the organization, its rows are read from the first
+func (s *PostgresOrderSheetStore) CreateSync(org_id uuid.UUID, sheet *OrderSheetSync) error {
+	mapping, err := json.Marshal(sheet.ColumnMapping)
+	if err != nil {
+		return err
+	}
+	credentials, err := sealString(s.Cipher, sheet.Credentials)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO order_sheet_syncs (organization_id, name, sheet_url, credentials, column_mapping, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, rows_imported, created_at, updated_at
+	`
+	err = s.DB.QueryRow(query, org_id, sheet.Name, sheet.SheetURL, credentials, mapping, sheet.Enabled).
+		Scan(&sheet.ID, &sheet.RowsImported, &sheet.CreatedAt, &sheet.UpdatedAt)
+	if err != nil {
+		s.Logger.Error("failed to create order sheet", "error", err, "org_id", org_id)
+		return err
+	}
+	sheet.OrganizationID = org_id
+	return nil
+}
+
+// UpdateSync replaces the settings of an order sheet, sql.ErrNoRows when the organization has no such
+// sheet. A sheet pointed at another URL is read again from its first row.
+func (s *PostgresOrderSheetStore) UpdateSync(org_id uuid.UUID, sheet *OrderSheetSync) error {
+	mapping, err := json.Marshal(sheet.ColumnMapping)
+	if err != nil {
+		return err
+	}
+	credentials, err := sealString(s.Cipher, sheet.Credentials)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE order_sheet_syncs SET
+			name = $3,
+			rows_imported = CASE WHEN sheet_url = $4 THEN rows_imported ELSE 0 END,
+			sheet_url = $4,
+			credentials = $5,
+			column_mapping = $6,
+			enabled = $7,
+			updated_at = NOW()
+		WHERE organization_id = $1 AND id = $2
+		RETURNING rows_imported, last_synced_at, created_at, updated_at
+	`
+	err = s.DB.QueryRow(query, org_id, sheet.ID, sheet.Name, sheet.SheetURL, credentials, mapping, sheet.Enabled).
+		Scan(&sheet.RowsImported, &sheet.LastSyncedAt, &sheet.CreatedAt, &sheet.UpdatedAt)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.Logger.Error("failed to update order sheet", "error", err, "sync_id", sheet.ID)
+		}
+		return err
+	}
+	sheet.OrganizationID = org_id
+	return nil
+}
+
+// DeleteSync disconnects an order sheet, the orders imported from it are kept. sql.ErrNoRows when the
+// organization has no such sheet.
+func (s *PostgresOrderSheetStore) DeleteSync(org_id, sync_id uuid.UUID) error {
+	result, err := s.DB.Exec(`DELETE FROM order_sheet_syncs WHERE organization_id = $1 AND id = $2`, org_id, sync_id)
+	if err != nil {
+		s.Logger.Error("failed to delete order sheet", "error", err, "sync_id", sync_id)
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetEnabledSyncs lists the enabled order sheets of every organization
+func (s *PostgresOrderSheetStore) GetEnabledSyncs() ([]OrderSheetSync, error) {
+	return s.querySyncs(`
+		SELECT ` + orderSheetColumns + `
+		FROM order_sheet_syncs
+		WHERE enabled
+		ORDER BY organization_id, created_at
+	`)
+}
+
+// MarkSynced records how many rows of the sheet were read, the next sync starts after them
+func (s *PostgresOrderSheetStore) MarkSynced(sync_id uuid.UUID, rowsImported int, at time.Time) error {
+	_, err := s.DB.Exec(`
+		UPDATE order_sheet_syncs SET rows_imported = $2, last_synced_at = $3 WHERE id = $1
+	`, sync_id, rowsImported, at)
+	if err != nil {
+		s.Logger.Error("failed to mark order sheet synced", "error", err, "sync_id", sync_id)
+	}
+	return err
+}
+
+// RecordRun logs the result of a sync
+func (s *PostgresOrderSheetStore) RecordRun(run *OrderSheetRun) error {
+	query := `
+		INSERT INTO order_sheet_sync_runs (sync_id, started_at, finished_at, status, total_rows, success_count, error_count, duplicate_count, rejected_count, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+	err := s.DB.QueryRow(query, run.SyncID, run.StartedAt, run.FinishedAt, run.Status, run.TotalRows, run.SuccessCount,
+		run.ErrorCount, run.DuplicateCount, run.RejectedCount, run.Error).Scan(&run.ID)
+	if err != nil {
+		s.Logger.Error("failed to record order sheet run", "error", err, "sync_id", run.SyncID)
+	}
+	return err
+}
+
+// ListRuns lists the latest syncs of an order sheet of the organization, newest first
+func (s *PostgresOrderSheetStore) ListRuns(org_id, sync_id uuid.UUID, limit int) ([]OrderSheetRun, error) {
+	query := `
+		SELECT r.id, r.sync_id, r.started_at, r.finished_at, r.status, r.total_rows, r.success_count, r.error_count,
+			r.duplicate_count, r.rejected_count, r.error
+		FROM order_sheet_sync_runs r
+		JOIN order_sheet_syncs s ON s.id = r.sync_id
+		WHERE s.organization_id = $1 AND r.sync_id = $2
+		ORDER BY r.started_at DESC
+		LIMIT $3
+	`
+	rows, err := s.DB.Query(query, org_id, sync_id, limit)
+	if err != nil {
+		s.Logger.Error("failed to get order sheet runs", "error", err, "sync_id", sync_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []OrderSheetRun{}
+	for rows.Next() {
+		var run OrderSheetRun
+		if err := rows.Scan(&run.ID, &run.SyncID, &run.StartedAt, &run.FinishedAt, &run.Status, &run.TotalRows,
+			&run.SuccessCount, &run.ErrorCount, &run.DuplicateCount, &run.RejectedCount, &run.Error); err != nil {
+			s.Logger.Error("failed to scan order sheet run", "error", err)
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
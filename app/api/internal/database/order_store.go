@@ -2,13 +2,127 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+// Channels an order can be placed on
+const (
+	OrderChannelDirect    = "direct"
+	OrderChannelPOS       = "pos"
+	OrderChannelPhone     = "phone"
+	OrderChannelApp       = "app"
+	OrderChannelUberEats  = "uber_eats"
+	OrderChannelDeliveroo = "deliveroo"
+	OrderChannelTalabat   = "talabat"
+)
+
+// OrderChannels lists every channel, the organization's own first and the delivery platforms after
+var OrderChannels = []string{OrderChannelDirect, OrderChannelPOS, OrderChannelPhone, OrderChannelApp, OrderChannelUberEats, OrderChannelDeliveroo, OrderChannelTalabat}
+
+// IsOrderChannel reports whether channel is one of OrderChannels
+func IsOrderChannel(channel string) bool {
+	for _, c := range OrderChannels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPlatformChannel reports whether orders of the channel come from a third-party delivery platform
+func IsPlatformChannel(channel string) bool {
+	return channel == OrderChannelUberEats || channel == OrderChannelDeliveroo || channel == OrderChannelTalabat
+}
+
+// Statuses of an order. Orders taken live move from pending through preparing and ready to completed,
+// or are cancelled. Incompleted is the status of the open orders of imports and delivery platforms,
+// whose progress is not tracked.
+const (
+	OrderStatusPending     = "pending"
+	OrderStatusPreparing   = "preparing"
+	OrderStatusReady       = "ready"
+	OrderStatusCompleted   = "completed"
+	OrderStatusCancelled   = "cancelled"
+	OrderStatusIncompleted = "incompleted"
+)
+
+// OrderStatuses lists every status, in the order of the lifecycle
+var OrderStatuses = []string{OrderStatusPending, OrderStatusPreparing, OrderStatusReady, OrderStatusCompleted, OrderStatusCancelled, OrderStatusIncompleted}
+
+// OrderStatusTransitions are the statuses an order in a status can move to. Completed and cancelled
+// orders are final.
+var OrderStatusTransitions = map[string][]string{
+	OrderStatusPending:     {OrderStatusPreparing, OrderStatusCancelled},
+	OrderStatusPreparing:   {OrderStatusReady, OrderStatusCancelled},
+	OrderStatusReady:       {OrderStatusCompleted, OrderStatusCancelled},
+	OrderStatusIncompleted: {OrderStatusPreparing, OrderStatusReady, OrderStatusCompleted, OrderStatusCancelled},
+}
+
+// IsOrderStatus reports whether status is one of OrderStatuses
+func IsOrderStatus(status string) bool {
+	for _, s := range OrderStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// CanTransitionOrder reports whether an order in status from can move to status to
+func CanTransitionOrder(from, to string) bool {
+	for _, next := range OrderStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	ErrDuplicateOrder   = errors.New("order already exists")
+	ErrUnknownOrderItem = errors.New("item not found or does not belong to organization")
+	ErrItemExists       = errors.New("item already exists")
+)
+
+// OrderTransitionError is returned when an order cannot move from its status to the one asked for
+type OrderTransitionError struct {
+	From string
+	To   string
+}
+
+func (e *OrderTransitionError) Error() string {
+	return "an order cannot move from " + e.From + " to " + e.To
+}
+
+// How StoreOrdersBulk handles the orders whose ID is already taken
+const (
+	OrderConflictSkip      = "skip"
+	OrderConflictOverwrite = "overwrite"
+	OrderConflictFail      = "fail"
+)
+
+// OrderConflictError is returned by StoreOrdersBulk failing on an order whose ID is already taken. It is
+// an ErrDuplicateOrder.
+type OrderConflictError struct {
+	OrderID uuid.UUID
+}
+
+func (e *OrderConflictError) Error() string {
+	return "order " + e.OrderID.String() + " already exists"
+}
+
+func (e *OrderConflictError) Unwrap() error {
+	return ErrDuplicateOrder
+}
+
 type Order struct {
 	OrderID        uuid.UUID      `json:"order_id"`
 	UserID         uuid.UUID      `json:"user_id,omitempty"`
@@ -19,9 +133,11 @@ type Order struct {
 	TotalAmount    *float64       `json:"total_amount"`
 	DiscountAmount *float64       `json:"discount_amount"`
 	Rating         *float64       `json:"rating,omitempty"`
+	Channel        string         `json:"channel"`
 	OrderItems     []OrderItem    `json:"items"`
 	DeliveryStatus *OrderDelivery `json:"delivery_status,omitempty"`
 	OrderCount     int            `json:"item_count"`
+	CustomFields   map[string]any `json:"custom_fields,omitempty"`
 }
 
 type OrderItem struct {
@@ -30,11 +146,19 @@ type OrderItem struct {
 	TotalPrice *float64      `json:"total_price"`
 }
 
+// OrderItemLink is a row of an order items upload, the item OrderItem of the order OrderID
+type OrderItemLink struct {
+	OrderID uuid.UUID
+	OrderItem
+}
+
 type Item struct {
-	ItemID                      uuid.UUID `json:"item_id"`
-	Name                        string    `json:"name"`
-	NeededNumEmployeesToPrepare *int      `json:"needed_employees"`
-	Price                       *float64  `json:"price"`
+	ItemID                      uuid.UUID      `json:"item_id"`
+	Name                        string         `json:"name"`
+	NeededNumEmployeesToPrepare *int           `json:"needed_employees"`
+	Price                       *float64       `json:"price"`
+	IsActive                    bool           `json:"is_active"` // false once discontinued, kept in the orders it was sold in
+	CustomFields                map[string]any `json:"custom_fields,omitempty"`
 }
 
 type OrderDelivery struct {
@@ -46,29 +170,151 @@ type OrderDelivery struct {
 	DeliveryStatus     string    `json:"status"`
 }
 
+// ChannelBreakdown sums the orders of a channel. Revenue is net of discounts, and Share is the percent of
+// all the orders of the period placed on the channel.
+type ChannelBreakdown struct {
+	Channel           string  `json:"channel"`
+	Orders            int     `json:"orders"`
+	DeliveryOrders    int     `json:"delivery_orders"`
+	Share             float64 `json:"share_percent"`
+	Revenue           float64 `json:"revenue"`
+	Discount          float64 `json:"discount"`
+	AverageOrderValue float64 `json:"average_order_value"`
+}
+
+// DiscountStats sums orders and the discounts given on them. Sales are before the discount.
+type DiscountStats struct {
+	Orders           int     `json:"orders"`
+	DiscountedOrders int     `json:"discounted_orders"`
+	Sales            float64 `json:"sales"`
+	Discount         float64 `json:"discount"`
+}
+
+// CustomerDiscounts are the discounts given on the orders of a customer
+type CustomerDiscounts struct {
+	CustomerID uuid.UUID `json:"customer_id"`
+	DiscountStats
+}
+
+// ShiftDiscounts are the discounts given on the orders placed during the working shifts of an employee
+type ShiftDiscounts struct {
+	EmployeeID uuid.UUID `json:"employee_id"`
+	FullName   string    `json:"full_name"`
+	Shifts     int       `json:"shifts"`
+	DiscountStats
+}
+
+// DriverDeliveryStats sums the deliveries a driver went out for. Only delivered deliveries with a delivered
+// time are timed, and DistanceKm is the straight line from the organization to every drop-off and back.
+type DriverDeliveryStats struct {
+	DriverID        uuid.UUID `json:"driver_id"`
+	FullName        string    `json:"full_name"`
+	Deliveries      int       `json:"deliveries"`
+	Delivered       int       `json:"delivered"`
+	ActiveDays      int       `json:"active_days"`
+	DeliveryMinutes float64   `json:"-"`
+	OnTime          int       `json:"on_time"`
+	DistanceKm      float64   `json:"distance_km"`
+}
+
+// DeliveryHeatmapCell counts the deliveries dropped off in a cell of the delivery heatmap, the square of the
+// grid centered on Latitude and Longitude
+type DeliveryHeatmapCell struct {
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	Deliveries int     `json:"deliveries"`
+}
+
+// OrderStatusChange is a move of an order from one status to another, kept for auditing
+type OrderStatusChange struct {
+	ID            uuid.UUID  `json:"id"`
+	OrderID       uuid.UUID  `json:"order_id"`
+	FromStatus    string     `json:"from_status"`
+	ToStatus      string     `json:"to_status"`
+	ChangedBy     *uuid.UUID `json:"changed_by"`
+	ChangedByName string     `json:"changed_by_name"`
+	Note          *string    `json:"note"`
+	ChangedAt     time.Time  `json:"changed_at"`
+}
+
+// DeliveryVolume counts the delivery orders created on a weekday in an hour of the day
+type DeliveryVolume struct {
+	Weekday string `json:"day"`
+	Hour    int    `json:"hour"`
+	Orders  int    `json:"orders"`
+}
+
+// HourlyDemand is the orders received in an hour of a day and the items ordered in them
+type HourlyDemand struct {
+	Date   time.Time `json:"date"`
+	Hour   int       `json:"hour"`
+	Orders int       `json:"orders"`
+	Items  int       `json:"items"`
+}
+
 type Location struct {
 	Latitude  *float64 `json:"latitude,omitempty"`
 	Longitude *float64 `json:"longitude,omitempty"`
 }
 
+// OrderFilter narrows a list of orders. Unset fields match every order.
+type OrderFilter struct {
+	From     *time.Time // created at or after
+	To       *time.Time // created before
+	Statuses []string
+	Types    []string
+	Channels []string
+}
+
+// DeliveryFilter narrows a list of deliveries. Unset fields match every delivery.
+type DeliveryFilter struct {
+	From      *time.Time // out for delivery at or after
+	To        *time.Time // out for delivery before
+	Statuses  []string
+	DriverIDs []uuid.UUID
+}
+
 type OrderStore interface {
 	GetAllOrdersForLastWeek(org_id uuid.UUID) ([]Order, error)
 	GetAllOrders(org_id uuid.UUID) ([]Order, error)
+	GetOrders(org_id uuid.UUID, filter OrderFilter) ([]Order, error)
+	GetOrdersInRange(org_id uuid.UUID, from, to time.Time) ([]Order, error)
+	GetOrder(org_id uuid.UUID, order_id uuid.UUID) (*Order, error)
 	GetAllItems(org_id uuid.UUID) ([]Item, error)
 	GetTodaysOrder(org_id uuid.UUID) ([]Order, error)
 
 	GetOrdersInsights(org_id uuid.UUID) ([]Insight, error)
 	GetDeliveryInsights(org_id uuid.UUID) ([]Insight, error)
 	GetItemsInsights(org_id uuid.UUID) ([]Insight, error)
+	GetChannelBreakdown(org_id uuid.UUID, from, to time.Time) ([]ChannelBreakdown, error)
+	GetDiscountStats(org_id uuid.UUID, from, to time.Time) (*DiscountStats, error)
+	GetCustomerDiscounts(org_id uuid.UUID, from, to time.Time, minDiscountedOrders int) ([]CustomerDiscounts, error)
+	GetShiftDiscounts(org_id uuid.UUID, from, to time.Time) ([]ShiftDiscounts, error)
 
 	StoreOrder(org_id uuid.UUID, order *Order) error
+	StoreOrdersBulk(org_id uuid.UUID, orders []Order, onConflict string) ([]uuid.UUID, []uuid.UUID, error)
+	StoreNestedOrder(org_id uuid.UUID, order *Order) error
 	StoreOrderItems(org_id uuid.UUID, order_id uuid.UUID, orderItem *OrderItem) error
+	StoreOrderItemsBulk(org_id uuid.UUID, links []OrderItemLink) ([]int, error)
 	StoreItems(org_id uuid.UUID, item *Item) error
+	GetItem(org_id uuid.UUID, item_id uuid.UUID) (*Item, error)
+	UpdateItem(org_id uuid.UUID, item *Item, changedBy *uuid.UUID) error
+	DeactivateItem(org_id uuid.UUID, item_id uuid.UUID) error
 
 	GetAllDeliveries(org_id uuid.UUID) ([]OrderDelivery, error)
+	GetDeliveries(org_id uuid.UUID, filter DeliveryFilter) ([]OrderDelivery, error)
 	GetAllDeliveriesForLastWeek(org_id uuid.UUID) ([]OrderDelivery, error)
 	GetTodaysDeliveries(org_id uuid.UUID) ([]OrderDelivery, error)
+	GetDeliveryVolume(org_id uuid.UUID, from, to time.Time) ([]DeliveryVolume, error)
+	GetHourlyDemand(org_id uuid.UUID, from, to time.Time) ([]HourlyDemand, error)
+	GetDriverDeliveryStats(org_id uuid.UUID, from, to time.Time, onTimeMinutes int) ([]DriverDeliveryStats, error)
+	GetDeliveryHeatmap(org_id uuid.UUID, cellSize float64, filter DeliveryFilter) ([]DeliveryHeatmapCell, error)
 	StoreDelivery(org_id uuid.UUID, delivery *OrderDelivery) error
+	UpdateOrderProgress(org_id uuid.UUID, order_id uuid.UUID, orderStatus string, delivery *OrderDelivery) error
+	UpdateOrder(org_id uuid.UUID, order *Order) error
+	TransitionOrderStatus(org_id uuid.UUID, order_id uuid.UUID, toStatus string, changedBy *uuid.UUID, note *string) (*OrderStatusChange, error)
+	GetOrderStatusHistory(org_id uuid.UUID, order_id uuid.UUID) ([]OrderStatusChange, error)
+	DeleteOrder(org_id uuid.UUID, order_id uuid.UUID) error
 }
 
 type PostgresOrderStore struct {
@@ -85,7 +331,7 @@ func NewPostgresOrderStore(db *sql.DB, logger *slog.Logger) *PostgresOrderStore
 
 func (pgos *PostgresOrderStore) GetAllOrdersForLastWeek(org_id uuid.UUID) ([]Order, error) {
 	query := `
-		SELECT id, user_id, organization_id, create_time, order_type, order_status, total_amount, discount_amount, rating
+		SELECT id, user_id, organization_id, create_time, order_type, order_status, total_amount, discount_amount, rating, channel
 		FROM orders
 		WHERE organization_id = $1 AND create_time >= NOW() - INTERVAL '7 days'
 		ORDER BY create_time DESC
@@ -114,16 +360,34 @@ func (pgos *PostgresOrderStore) GetAllOrdersForLastWeek(org_id uuid.UUID) ([]Ord
 }
 
 func (pgos *PostgresOrderStore) GetAllOrders(org_id uuid.UUID) ([]Order, error) {
+	return pgos.GetOrders(org_id, OrderFilter{})
+}
+
+// GetOrdersInRange returns the orders created from from until to, to excluded, newest first
+func (pgos *PostgresOrderStore) GetOrdersInRange(org_id uuid.UUID, from, to time.Time) ([]Order, error) {
+	return pgos.GetOrders(org_id, OrderFilter{From: &from, To: &to})
+}
+
+// GetOrders returns the orders of an organization matching the filter, newest first
+func (pgos *PostgresOrderStore) GetOrders(org_id uuid.UUID, filter OrderFilter) ([]Order, error) {
+	where := Where("organization_id = ?", org_id).
+		AndIf(filter.From != nil, "create_time >= ?", filter.From).
+		AndIf(filter.To != nil, "create_time < ?", filter.To)
+	where = AndIn(where, "order_status", filter.Statuses)
+	where = AndIn(where, "order_type", filter.Types)
+	where = AndIn(where, "channel", filter.Channels)
+	conditions, args := where.Build()
+
 	query := `
-		SELECT id, user_id, organization_id, create_time, order_type, order_status, total_amount, discount_amount, rating
+		SELECT id, user_id, organization_id, create_time, order_type, order_status, total_amount, discount_amount, rating, channel
 		FROM orders
-		WHERE organization_id = $1
+		WHERE ` + conditions + `
 		ORDER BY create_time DESC
 	`
 
-	rows, err := pgos.DB.Query(query, org_id)
+	rows, err := pgos.DB.Query(query, args...)
 	if err != nil {
-		pgos.Logger.Error("Failed to get all orders", "error", err)
+		pgos.Logger.Error("Failed to get orders", "error", err)
 		return nil, err
 	}
 	defer rows.Close()
@@ -143,9 +407,47 @@ func (pgos *PostgresOrderStore) GetAllOrders(org_id uuid.UUID) ([]Order, error)
 	return pgos.populateDeliveries(orders)
 }
 
+// GetOrder returns an order of the organization with its items and delivery, sql.ErrNoRows when it does
+// not exist
+func (pgos *PostgresOrderStore) GetOrder(org_id uuid.UUID, order_id uuid.UUID) (*Order, error) {
+	query := `
+		SELECT id, user_id, organization_id, create_time, order_type, order_status, total_amount, discount_amount, rating, channel
+		FROM orders
+		WHERE id = $1 AND organization_id = $2
+	`
+
+	rows, err := pgos.DB.Query(query, order_id, org_id)
+	if err != nil {
+		pgos.Logger.Error("Failed to get order", "error", err, "order_id", order_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders, err := pgos.scanOrders(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	orders, err = pgos.populateOrderItems(orders)
+	if err != nil {
+		return nil, err
+	}
+
+	orders = pgos.setOrderCounts(orders)
+
+	orders, err = pgos.populateDeliveries(orders)
+	if err != nil {
+		return nil, err
+	}
+	return &orders[0], nil
+}
+
 func (pgos *PostgresOrderStore) GetTodaysOrder(org_id uuid.UUID) ([]Order, error) {
 	query := `
-		SELECT id, user_id, organization_id, create_time, order_type, order_status, total_amount, discount_amount, rating
+		SELECT id, user_id, organization_id, create_time, order_type, order_status, total_amount, discount_amount, rating, channel
 		FROM orders
 		WHERE organization_id = $1 AND DATE(create_time) = CURRENT_DATE
 		ORDER BY create_time DESC
@@ -238,14 +540,324 @@ func (pgos *PostgresOrderStore) GetOrdersInsights(org_id uuid.UUID) ([]Insight,
 		return nil, err
 	}
 	if busiestOrderHour.Valid {
-		insights = append(insights, Insight{Title: "Busiest Hour (Orders)", Statistic: fmt.Sprintf("%d:00", busiestOrderHour.Int64)})
+		insights = append(insights, Insight{Title: "Busiest Hour (Orders)", Statistic: fmt.Sprintf("%d:00", busiestOrderHour.Int64), DrillDown: DrillDownBusiestHour})
 	} else {
 		insights = append(insights, Insight{Title: "Busiest Hour (Orders)", Statistic: "N/A"})
 	}
 
+	// Orders per channel for last week, with their share of the week
+	rows, err := pgos.DB.Query(`
+		SELECT channel, COUNT(*)
+		FROM orders
+		WHERE organization_id = $1 AND create_time >= NOW() - INTERVAL '7 days'
+		GROUP BY channel
+		ORDER BY COUNT(*) DESC, channel
+	`, org_id)
+	if err != nil {
+		pgos.Logger.Error("Failed to get weekly orders per channel", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var channel string
+		var count int
+		if err := rows.Scan(&channel, &count); err != nil {
+			pgos.Logger.Error("Failed to scan weekly orders per channel", "error", err)
+			return nil, err
+		}
+		insights = append(insights, Insight{
+			Title:     fmt.Sprintf("Orders via %s (Last 7 Days)", channel),
+			Statistic: fmt.Sprintf("%d (%.1f%%)", count, percentOf(count, weeklyOrders)),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
 	return insights, nil
 }
 
+// GetChannelBreakdown sums the orders created in [from, to) per channel, busiest channel first
+func (pgos *PostgresOrderStore) GetChannelBreakdown(org_id uuid.UUID, from, to time.Time) ([]ChannelBreakdown, error) {
+	rows, err := pgos.DB.Query(`
+		SELECT channel, COUNT(*), COUNT(*) FILTER (WHERE order_type = 'delivery'),
+			COALESCE(SUM(total_amount - discount_amount), 0), COALESCE(SUM(discount_amount), 0)
+		FROM orders
+		WHERE organization_id = $1 AND create_time >= $2 AND create_time < $3
+		GROUP BY channel
+		ORDER BY COUNT(*) DESC, channel
+	`, org_id, from, to)
+	if err != nil {
+		pgos.Logger.Error("Failed to get channel breakdown", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	breakdown := []ChannelBreakdown{}
+	total := 0
+	for rows.Next() {
+		var b ChannelBreakdown
+		if err := rows.Scan(&b.Channel, &b.Orders, &b.DeliveryOrders, &b.Revenue, &b.Discount); err != nil {
+			pgos.Logger.Error("Failed to scan channel breakdown", "error", err)
+			return nil, err
+		}
+		if b.Orders > 0 {
+			b.AverageOrderValue = b.Revenue / float64(b.Orders)
+		}
+		total += b.Orders
+		breakdown = append(breakdown, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range breakdown {
+		breakdown[i].Share = percentOf(breakdown[i].Orders, total)
+	}
+	return breakdown, nil
+}
+
+// GetDiscountStats sums the orders created in [from, to) and their discounts
+func (pgos *PostgresOrderStore) GetDiscountStats(org_id uuid.UUID, from, to time.Time) (*DiscountStats, error) {
+	var stats DiscountStats
+	err := pgos.DB.QueryRow(`
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE discount_amount > 0),
+			COALESCE(SUM(total_amount), 0), COALESCE(SUM(discount_amount), 0)
+		FROM orders
+		WHERE organization_id = $1 AND create_time >= $2 AND create_time < $3
+	`, org_id, from, to).Scan(&stats.Orders, &stats.DiscountedOrders, &stats.Sales, &stats.Discount)
+	if err != nil {
+		pgos.Logger.Error("Failed to get discount stats", "error", err)
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetCustomerDiscounts sums the orders created in [from, to) per customer, for the customers discounted at
+// least minDiscountedOrders times, most discounted first
+func (pgos *PostgresOrderStore) GetCustomerDiscounts(org_id uuid.UUID, from, to time.Time, minDiscountedOrders int) ([]CustomerDiscounts, error) {
+	rows, err := pgos.DB.Query(`
+		SELECT user_id, COUNT(*), COUNT(*) FILTER (WHERE discount_amount > 0),
+			COALESCE(SUM(total_amount), 0), COALESCE(SUM(discount_amount), 0)
+		FROM orders
+		WHERE organization_id = $1 AND create_time >= $2 AND create_time < $3 AND user_id IS NOT NULL
+		GROUP BY user_id
+		HAVING COUNT(*) FILTER (WHERE discount_amount > 0) >= $4
+		ORDER BY SUM(discount_amount) DESC, user_id
+	`, org_id, from, to, minDiscountedOrders)
+	if err != nil {
+		pgos.Logger.Error("Failed to get customer discounts", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	customers := []CustomerDiscounts{}
+	for rows.Next() {
+		var c CustomerDiscounts
+		if err := rows.Scan(&c.CustomerID, &c.Orders, &c.DiscountedOrders, &c.Sales, &c.Discount); err != nil {
+			pgos.Logger.Error("Failed to scan customer discounts", "error", err)
+			return nil, err
+		}
+		customers = append(customers, c)
+	}
+	return customers, rows.Err()
+}
+
+// GetShiftDiscounts sums, per employee, the orders created in [from, to) while they were on a working
+// shift, most discounted first. Orders placed while several employees worked count for each of them.
+func (pgos *PostgresOrderStore) GetShiftDiscounts(org_id uuid.UUID, from, to time.Time) ([]ShiftDiscounts, error) {
+	rows, err := pgos.DB.Query(`
+		SELECT u.id, u.full_name, COUNT(DISTINCT s.id), COUNT(o.id), COUNT(o.id) FILTER (WHERE o.discount_amount > 0),
+			COALESCE(SUM(o.total_amount), 0), COALESCE(SUM(o.discount_amount), 0)
+		FROM schedules s
+		JOIN users u ON u.id = s.employee_id
+		LEFT JOIN orders o ON o.organization_id = u.organization_id
+			AND o.create_time >= (s.schedule_date + s.start_hour) AND o.create_time < (s.schedule_date + s.end_hour)
+			AND o.create_time >= $2 AND o.create_time < $3
+		WHERE u.organization_id = $1 AND s.shift_type = 'working'
+			AND (s.schedule_date + s.start_hour) < $3 AND (s.schedule_date + s.end_hour) > $2
+		GROUP BY u.id, u.full_name
+		ORDER BY COALESCE(SUM(o.discount_amount), 0) DESC, u.full_name
+	`, org_id, from, to)
+	if err != nil {
+		pgos.Logger.Error("Failed to get shift discounts", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	employees := []ShiftDiscounts{}
+	for rows.Next() {
+		var e ShiftDiscounts
+		if err := rows.Scan(&e.EmployeeID, &e.FullName, &e.Shifts, &e.Orders, &e.DiscountedOrders, &e.Sales, &e.Discount); err != nil {
+			pgos.Logger.Error("Failed to scan shift discounts", "error", err)
+			return nil, err
+		}
+		employees = append(employees, e)
+	}
+	return employees, rows.Err()
+}
+
+// GetDeliveryVolume counts the delivery orders created in [from, to) per weekday and hour of the day
+func (pgos *PostgresOrderStore) GetDeliveryVolume(org_id uuid.UUID, from, to time.Time) ([]DeliveryVolume, error) {
+	rows, err := pgos.DB.Query(`
+		SELECT EXTRACT(DOW FROM create_time)::int, EXTRACT(HOUR FROM create_time)::int, COUNT(*)
+		FROM orders
+		WHERE organization_id = $1 AND order_type = 'delivery' AND create_time >= $2 AND create_time < $3
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`, org_id, from, to)
+	if err != nil {
+		pgos.Logger.Error("Failed to get delivery volume", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	volume := []DeliveryVolume{}
+	for rows.Next() {
+		var dow int
+		var v DeliveryVolume
+		if err := rows.Scan(&dow, &v.Hour, &v.Orders); err != nil {
+			pgos.Logger.Error("Failed to scan delivery volume", "error", err)
+			return nil, err
+		}
+		v.Weekday = ValidDays[dow]
+		volume = append(volume, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return volume, nil
+}
+
+// GetHourlyDemand counts the orders created in [from, to) and the items ordered in them per day and hour,
+// leaving out the hours without orders
+func (pgos *PostgresOrderStore) GetHourlyDemand(org_id uuid.UUID, from, to time.Time) ([]HourlyDemand, error) {
+	rows, err := pgos.DB.Query(`
+		SELECT DATE(o.create_time), EXTRACT(HOUR FROM o.create_time)::int, COUNT(DISTINCT o.id), COALESCE(SUM(oi.quantity), 0)
+		FROM orders o
+		LEFT JOIN order_items oi ON oi.order_id = o.id
+		WHERE o.organization_id = $1 AND o.create_time >= $2 AND o.create_time < $3
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`, org_id, from, to)
+	if err != nil {
+		pgos.Logger.Error("Failed to get hourly demand", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	demand := []HourlyDemand{}
+	for rows.Next() {
+		var d HourlyDemand
+		if err := rows.Scan(&d.Date, &d.Hour, &d.Orders, &d.Items); err != nil {
+			pgos.Logger.Error("Failed to scan hourly demand", "error", err)
+			return nil, err
+		}
+		demand = append(demand, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return demand, nil
+}
+
+// GetDriverDeliveryStats sums, per driver, the deliveries out for delivery in [from, to), most deliveries
+// first. A delivery is on time when delivered within onTimeMinutes of leaving. Deliveries without a
+// location, or of an organization without one, add no distance.
+func (pgos *PostgresOrderStore) GetDriverDeliveryStats(org_id uuid.UUID, from, to time.Time, onTimeMinutes int) ([]DriverDeliveryStats, error) {
+	rows, err := pgos.DB.Query(`
+		SELECT d.driver_id, COALESCE(u.full_name, ''), COUNT(*),
+			COUNT(*) FILTER (WHERE d.status = 'delivered' AND d.delivered_time >= d.out_for_delivery_time),
+			COUNT(DISTINCT DATE(d.out_for_delivery_time)),
+			COALESCE(SUM(EXTRACT(EPOCH FROM d.delivered_time - d.out_for_delivery_time) / 60) FILTER (WHERE d.status = 'delivered' AND d.delivered_time >= d.out_for_delivery_time), 0),
+			COUNT(*) FILTER (WHERE d.status = 'delivered' AND d.delivered_time >= d.out_for_delivery_time AND d.delivered_time <= d.out_for_delivery_time + make_interval(mins => $4)),
+			COALESCE(SUM(2 * 6371 * 2 * ASIN(SQRT(
+				POWER(SIN(RADIANS(d.delivery_latitude - org.latitude) / 2), 2) +
+				COS(RADIANS(org.latitude)) * COS(RADIANS(d.delivery_latitude)) * POWER(SIN(RADIANS(d.delivery_longitude - org.longitude) / 2), 2)
+			))), 0)
+		FROM deliveries d
+		JOIN orders o ON o.id = d.order_id
+		JOIN organizations org ON org.id = o.organization_id
+		LEFT JOIN users u ON u.id = d.driver_id
+		WHERE o.organization_id = $1 AND d.driver_id IS NOT NULL AND d.out_for_delivery_time >= $2 AND d.out_for_delivery_time < $3
+		GROUP BY d.driver_id, u.full_name
+		ORDER BY COUNT(*) DESC, u.full_name
+	`, org_id, from, to, onTimeMinutes)
+	if err != nil {
+		pgos.Logger.Error("Failed to get driver delivery stats", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	drivers := []DriverDeliveryStats{}
+	for rows.Next() {
+		var d DriverDeliveryStats
+		if err := rows.Scan(&d.DriverID, &d.FullName, &d.Deliveries, &d.Delivered, &d.ActiveDays, &d.DeliveryMinutes, &d.OnTime, &d.DistanceKm); err != nil {
+			pgos.Logger.Error("Failed to scan driver delivery stats", "error", err)
+			return nil, err
+		}
+		drivers = append(drivers, d)
+	}
+	return drivers, rows.Err()
+}
+
+// GetDeliveryHeatmap counts the deliveries matching the filter per cell of a grid of cellSize degrees, the
+// busiest cell first. Drop-offs are rounded to the nearest multiple of cellSize, and deliveries without a
+// location are left out.
+func (pgos *PostgresOrderStore) GetDeliveryHeatmap(org_id uuid.UUID, cellSize float64, filter DeliveryFilter) ([]DeliveryHeatmapCell, error) {
+	where := Where("o.organization_id = ?", org_id).
+		And("d.delivery_latitude IS NOT NULL AND d.delivery_longitude IS NOT NULL").
+		AndIf(filter.From != nil, "d.out_for_delivery_time >= ?", filter.From).
+		AndIf(filter.To != nil, "d.out_for_delivery_time < ?", filter.To)
+	where = AndIn(where, "d.status", filter.Statuses)
+	where = AndIn(where, "d.driver_id", filter.DriverIDs)
+	conditions, args := where.Build()
+	cell := fmt.Sprintf("$%d::numeric", len(args)+1)
+	args = append(args, cellSize)
+
+	query := `
+		SELECT ROUND(d.delivery_latitude / ` + cell + `) * ` + cell + ` AS latitude,
+			ROUND(d.delivery_longitude / ` + cell + `) * ` + cell + ` AS longitude,
+			COUNT(*)
+		FROM deliveries d
+		JOIN orders o ON d.order_id = o.id
+		WHERE ` + conditions + `
+		GROUP BY 1, 2
+		ORDER BY COUNT(*) DESC, 1, 2
+	`
+
+	rows, err := pgos.DB.Query(query, args...)
+	if err != nil {
+		pgos.Logger.Error("Failed to get delivery heatmap", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	cells := []DeliveryHeatmapCell{}
+	for rows.Next() {
+		var cell DeliveryHeatmapCell
+		if err := rows.Scan(&cell.Latitude, &cell.Longitude, &cell.Deliveries); err != nil {
+			pgos.Logger.Error("Failed to scan delivery heatmap cell", "error", err)
+			return nil, err
+		}
+		cells = append(cells, cell)
+	}
+	return cells, rows.Err()
+}
+
+// describeBreaches writes the SLA breaches out of the deliveries delivered, with their share
+func describeBreaches(breaches, delivered int) string {
+	return fmt.Sprintf("%d of %d (%g%%)", breaches, delivered, percentOf(breaches, delivered))
+}
+
+// percentOf returns count as a percent of total rounded to 2 decimals, 0 when there is no total
+func percentOf(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(count)*10000/float64(total)) / 100
+}
+
 func (pgos *PostgresOrderStore) GetDeliveryInsights(org_id uuid.UUID) ([]Insight, error) {
 	var insights []Insight
 
@@ -288,6 +900,30 @@ func (pgos *PostgresOrderStore) GetDeliveryInsights(org_id uuid.UUID) ([]Insight
 	}
 	insights = append(insights, Insight{Title: "Deliveries (Today)", Statistic: fmt.Sprintf("%d", todayDeliveries)})
 
+	// Deliveries delivered later than the SLA of the organization, 45 minutes without rules
+	var slaMinutes, weeklyDelivered, weeklyBreaches, todayDelivered, todayBreaches int
+	err = pgos.DB.QueryRow(`
+		SELECT sla.minutes,
+			COUNT(d.order_id),
+			COUNT(d.order_id) FILTER (WHERE d.delivered_time > d.out_for_delivery_time + make_interval(mins => sla.minutes)),
+			COUNT(d.order_id) FILTER (WHERE DATE(d.out_for_delivery_time) = CURRENT_DATE),
+			COUNT(d.order_id) FILTER (WHERE DATE(d.out_for_delivery_time) = CURRENT_DATE AND d.delivered_time > d.out_for_delivery_time + make_interval(mins => sla.minutes))
+		FROM (SELECT COALESCE((SELECT delivery_sla_minutes FROM organizations_rules WHERE organization_id = $1), 45) AS minutes) sla
+		LEFT JOIN (deliveries d JOIN orders o ON d.order_id = o.id)
+			ON o.organization_id = $1 AND d.status = 'delivered' AND d.delivered_time >= d.out_for_delivery_time
+			AND d.out_for_delivery_time >= NOW() - INTERVAL '7 days'
+		GROUP BY sla.minutes
+	`, org_id).Scan(&slaMinutes, &weeklyDelivered, &weeklyBreaches, &todayDelivered, &todayBreaches)
+	if err != nil {
+		pgos.Logger.Error("Failed to get delivery SLA breaches", "error", err)
+		return nil, err
+	}
+	insights = append(insights,
+		Insight{Title: "Delivery SLA", Statistic: fmt.Sprintf("%d min", slaMinutes)},
+		Insight{Title: "SLA Breaches (Last 7 Days)", Statistic: describeBreaches(weeklyBreaches, weeklyDelivered)},
+		Insight{Title: "SLA Breaches (Today)", Statistic: describeBreaches(todayBreaches, todayDelivered)},
+	)
+
 	// Busiest Day for deliveries
 	var busiestDeliveryDay sql.NullString
 	err = pgos.DB.QueryRow(`
@@ -333,9 +969,197 @@ func (pgos *PostgresOrderStore) GetDeliveryInsights(org_id uuid.UUID) ([]Insight
 	return insights, nil
 }
 
-func (pgos *PostgresOrderStore) StoreOrder(org_id uuid.UUID, order *Order) error {
-	// Set OrderCount based on number of items
+func (pgos *PostgresOrderStore) StoreOrder(org_id uuid.UUID, order *Order) error {
+	// Set OrderCount based on number of items
+	order.OrderCount = len(order.OrderItems)
+	if order.Channel == "" {
+		order.Channel = OrderChannelDirect
+	}
+
+	tx, err := pgos.DB.Begin()
+	if err != nil {
+		pgos.Logger.Error("Failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	// Insert the order
+	query := `
+		INSERT INTO orders (id, user_id, organization_id, create_time, order_type, order_status, total_amount, discount_amount, rating, channel)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	_, err = tx.Exec(query, order.OrderID, order.UserID, org_id, order.CreateTime, order.OrderType, order.OrderStatus, order.TotalAmount, order.DiscountAmount, order.Rating, order.Channel)
+	if err != nil {
+		pgos.Logger.Error("Failed to insert order", "error", err)
+		return err
+	}
+
+	// If order is a delivery, insert delivery record
+	if order.OrderType == "delivery" && order.DeliveryStatus != nil {
+		deliveryQuery := `
+			INSERT INTO deliveries (order_id, driver_id, delivery_latitude, delivery_longitude, out_for_delivery_time, delivered_time, status)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`
+		_, err = tx.Exec(deliveryQuery,
+			order.OrderID,
+			order.DeliveryStatus.DriverID,
+			order.DeliveryStatus.DeliveryLocation.Latitude,
+			order.DeliveryStatus.DeliveryLocation.Longitude,
+			order.DeliveryStatus.OutForDeliveryTime,
+			order.DeliveryStatus.DeliveredTime,
+			order.DeliveryStatus.DeliveryStatus,
+		)
+		if err != nil {
+			pgos.Logger.Error("Failed to insert delivery", "error", err)
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		pgos.Logger.Error("Failed to commit transaction", "error", err)
+		return err
+	}
+
+	// If order has items, store them using StoreOrderItems
+	for _, oi := range order.OrderItems {
+		oiCopy := oi
+		err := pgos.StoreOrderItems(org_id, order.OrderID, &oiCopy)
+		if err != nil {
+			pgos.Logger.Error("Failed to store order item", "error", err, "item_id", oi.ItemID)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// orderBulkChunk is the rows of a statement of StoreOrdersBulk, ten parameters each keeps it far below the
+// 65535 parameters Postgres allows
+const orderBulkChunk = 1000
+
+// StoreOrdersBulk stores orders in one transaction with a multi-row insert per orderBulkChunk orders, for
+// imports too large to store an order at a time. Their items and deliveries are not stored. Orders whose ID
+// is already taken are handled along onConflict:
+//   - OrderConflictSkip keeps the stored order
+//   - OrderConflictOverwrite replaces the stored order when it belongs to the organization, the last of the
+//     orders repeating an ID wins
+//   - OrderConflictFail stores nothing and returns an *OrderConflictError
+//
+// The IDs of the orders inserted and of those overwritten are returned.
+func (pgos *PostgresOrderStore) StoreOrdersBulk(org_id uuid.UUID, orders []Order, onConflict string) ([]uuid.UUID, []uuid.UUID, error) {
+	if len(orders) == 0 {
+		return nil, nil, nil
+	}
+
+	tx, err := pgos.DB.Begin()
+	if err != nil {
+		pgos.Logger.Error("Failed to begin transaction", "error", err)
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	conflict := `ON CONFLICT (id) DO NOTHING`
+	if onConflict == OrderConflictOverwrite {
+		// a statement cannot update a row twice, so only the last of the orders repeating an ID is kept
+		last := make(map[uuid.UUID]int, len(orders))
+		for i, order := range orders {
+			last[order.OrderID] = i
+		}
+		unique := make([]Order, 0, len(last))
+		for i, order := range orders {
+			if last[order.OrderID] == i {
+				unique = append(unique, order)
+			}
+		}
+		orders = unique
+		conflict = `ON CONFLICT (id) DO UPDATE SET
+				user_id = EXCLUDED.user_id, create_time = EXCLUDED.create_time, order_type = EXCLUDED.order_type,
+				order_status = EXCLUDED.order_status, total_amount = EXCLUDED.total_amount,
+				discount_amount = EXCLUDED.discount_amount, rating = EXCLUDED.rating, channel = EXCLUDED.channel
+			WHERE orders.organization_id = EXCLUDED.organization_id`
+	}
+
+	inserted := make([]uuid.UUID, 0, len(orders))
+	var updated []uuid.UUID
+	for start := 0; start < len(orders); start += orderBulkChunk {
+		chunk := orders[start:min(start+orderBulkChunk, len(orders))]
+
+		var values strings.Builder
+		args := make([]any, 0, len(chunk)*10)
+		for i, order := range chunk {
+			channel := order.Channel
+			if channel == "" {
+				channel = OrderChannelDirect
+			}
+			if i > 0 {
+				values.WriteString(", ")
+			}
+			n := len(args)
+			fmt.Fprintf(&values, "($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9, n+10)
+			args = append(args, order.OrderID, order.UserID, org_id, order.CreateTime, order.OrderType, order.OrderStatus, order.TotalAmount, order.DiscountAmount, order.Rating, channel)
+		}
+
+		// xmax is only set on the rows an upsert updated
+		query := `
+			INSERT INTO orders (id, user_id, organization_id, create_time, order_type, order_status, total_amount, discount_amount, rating, channel)
+			VALUES ` + values.String() + `
+			` + conflict + `
+			RETURNING id, xmax = 0
+		`
+		rows, err := tx.Query(query, args...)
+		if err != nil {
+			pgos.Logger.Error("Failed to insert orders", "error", err, "count", len(chunk))
+			return nil, nil, err
+		}
+		stored := make(map[uuid.UUID]bool, len(chunk))
+		for rows.Next() {
+			var id uuid.UUID
+			var isInsert bool
+			if err := rows.Scan(&id, &isInsert); err != nil {
+				rows.Close()
+				pgos.Logger.Error("Failed to scan inserted order", "error", err)
+				return nil, nil, err
+			}
+			stored[id] = true
+			if isInsert {
+				inserted = append(inserted, id)
+			} else {
+				updated = append(updated, id)
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			pgos.Logger.Error("Failed to insert orders", "error", err, "count", len(chunk))
+			return nil, nil, err
+		}
+
+		if onConflict == OrderConflictFail {
+			for _, order := range chunk {
+				// an order repeated in the chunk was stored once
+				if !stored[order.OrderID] {
+					return nil, nil, &OrderConflictError{OrderID: order.OrderID}
+				}
+				delete(stored, order.OrderID)
+			}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		pgos.Logger.Error("Failed to commit transaction", "error", err)
+		return nil, nil, err
+	}
+	return inserted, updated, nil
+}
+
+// StoreNestedOrder stores an order with its items and delivery in one transaction, so an order failing
+// halfway leaves nothing behind. Returns ErrDuplicateOrder when the order ID is already taken and
+// ErrUnknownOrderItem when an item is not on the menu of the organization.
+func (pgos *PostgresOrderStore) StoreNestedOrder(org_id uuid.UUID, order *Order) error {
 	order.OrderCount = len(order.OrderItems)
+	if order.Channel == "" {
+		order.Channel = OrderChannelDirect
+	}
 
 	tx, err := pgos.DB.Begin()
 	if err != nil {
@@ -344,24 +1168,69 @@ func (pgos *PostgresOrderStore) StoreOrder(org_id uuid.UUID, order *Order) error
 	}
 	defer tx.Rollback()
 
-	// Insert the order
-	query := `
-		INSERT INTO orders (id, user_id, organization_id, create_time, order_type, order_status, total_amount, discount_amount, rating)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-	`
-	_, err = tx.Exec(query, order.OrderID, order.UserID, org_id, order.CreateTime, order.OrderType, order.OrderStatus, order.TotalAmount, order.DiscountAmount, order.Rating)
+	result, err := tx.Exec(`
+		INSERT INTO orders (id, user_id, organization_id, create_time, order_type, order_status, total_amount, discount_amount, rating, channel)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (id) DO NOTHING
+	`, order.OrderID, order.UserID, org_id, order.CreateTime, order.OrderType, order.OrderStatus, order.TotalAmount, order.DiscountAmount, order.Rating, order.Channel)
+	if err != nil {
+		pgos.Logger.Error("Failed to insert order", "error", err, "order_id", order.OrderID)
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		pgos.Logger.Error("Failed to insert order", "error", err)
 		return err
 	}
+	if rowsAffected == 0 {
+		return ErrDuplicateOrder
+	}
 
-	// If order is a delivery, insert delivery record
-	if order.OrderType == "delivery" && order.DeliveryStatus != nil {
-		deliveryQuery := `
+	if len(order.OrderItems) > 0 {
+		itemIDs := make([]string, 0, len(order.OrderItems))
+		distinct := make(map[uuid.UUID]bool)
+		for _, oi := range order.OrderItems {
+			if !distinct[oi.ItemID] {
+				distinct[oi.ItemID] = true
+				itemIDs = append(itemIDs, oi.ItemID.String())
+			}
+		}
+
+		var known int
+		err = tx.QueryRow(`
+			SELECT COUNT(*) FROM items WHERE organization_id = $1 AND id = ANY($2::uuid[])
+		`, org_id, pq.Array(itemIDs)).Scan(&known)
+		if err != nil {
+			pgos.Logger.Error("Failed to verify items exist", "error", err, "order_id", order.OrderID)
+			return err
+		}
+		if known != len(itemIDs) {
+			return ErrUnknownOrderItem
+		}
+
+		for _, oi := range order.OrderItems {
+			quantity := 1
+			if oi.Quantity != nil {
+				quantity = *oi.Quantity
+			}
+			_, err = tx.Exec(`
+				INSERT INTO order_items (order_id, item_id, quantity, total_price)
+				VALUES ($1, $2, $3, $4)
+				ON CONFLICT (order_id, item_id) DO UPDATE
+				SET quantity = order_items.quantity + EXCLUDED.quantity,
+				    total_price = order_items.total_price + EXCLUDED.total_price
+			`, order.OrderID, oi.ItemID, quantity, oi.TotalPrice)
+			if err != nil {
+				pgos.Logger.Error("Failed to insert order_item", "error", err, "order_id", order.OrderID, "item_id", oi.ItemID)
+				return err
+			}
+		}
+	}
+
+	if order.DeliveryStatus != nil {
+		_, err = tx.Exec(`
 			INSERT INTO deliveries (order_id, driver_id, delivery_latitude, delivery_longitude, out_for_delivery_time, delivered_time, status)
 			VALUES ($1, $2, $3, $4, $5, $6, $7)
-		`
-		_, err = tx.Exec(deliveryQuery,
+		`,
 			order.OrderID,
 			order.DeliveryStatus.DriverID,
 			order.DeliveryStatus.DeliveryLocation.Latitude,
@@ -371,7 +1240,7 @@ func (pgos *PostgresOrderStore) StoreOrder(org_id uuid.UUID, order *Order) error
 			order.DeliveryStatus.DeliveryStatus,
 		)
 		if err != nil {
-			pgos.Logger.Error("Failed to insert delivery", "error", err)
+			pgos.Logger.Error("Failed to insert delivery", "error", err, "order_id", order.OrderID)
 			return err
 		}
 	}
@@ -380,17 +1249,6 @@ func (pgos *PostgresOrderStore) StoreOrder(org_id uuid.UUID, order *Order) error
 		pgos.Logger.Error("Failed to commit transaction", "error", err)
 		return err
 	}
-
-	// If order has items, store them using StoreOrderItems
-	for _, oi := range order.OrderItems {
-		oiCopy := oi
-		err := pgos.StoreOrderItems(org_id, order.OrderID, &oiCopy)
-		if err != nil {
-			pgos.Logger.Error("Failed to store order item", "error", err, "item_id", oi.ItemID)
-			return err
-		}
-	}
-
 	return nil
 }
 
@@ -410,6 +1268,7 @@ func (pgos *PostgresOrderStore) scanOrders(rows *sql.Rows) ([]Order, error) {
 			&order.TotalAmount,
 			&order.DiscountAmount,
 			&order.Rating,
+			&order.Channel,
 		)
 		if err != nil {
 			pgos.Logger.Error("Failed to scan order row", "error", err)
@@ -439,26 +1298,21 @@ func (pgos *PostgresOrderStore) populateOrderItems(orders []Order) ([]Order, err
 		return orders, nil
 	}
 
-	// Build parameterized IN clause
-	orderIDs := make([]interface{}, len(orders))
+	orderIDs := make([]uuid.UUID, len(orders))
 	orderMap := make(map[uuid.UUID]int) // order_id -> index in orders slice
-	placeholders := ""
 	for i, order := range orders {
 		orderIDs[i] = order.OrderID
 		orderMap[order.OrderID] = i
-		if i > 0 {
-			placeholders += ", "
-		}
-		placeholders += fmt.Sprintf("$%d", i+1)
 	}
+	conditions, args := AndIn(new(Conditions), "order_id", orderIDs).Build()
 
-	query := fmt.Sprintf(`
+	query := `
 		SELECT order_id, item_id, quantity, total_price
 		FROM order_items
-		WHERE order_id IN (%s)
-	`, placeholders)
+		WHERE ` + conditions + `
+	`
 
-	rows, err := pgos.DB.Query(query, orderIDs...)
+	rows, err := pgos.DB.Query(query, args...)
 	if err != nil {
 		pgos.Logger.Error("Failed to get order items", "error", err)
 		return nil, err
@@ -491,26 +1345,21 @@ func (pgos *PostgresOrderStore) populateDeliveries(orders []Order) ([]Order, err
 		return orders, nil
 	}
 
-	// Build parameterized IN clause
-	orderIDs := make([]interface{}, len(orders))
+	orderIDs := make([]uuid.UUID, len(orders))
 	orderMap := make(map[uuid.UUID]int) // order_id -> index in orders slice
-	placeholders := ""
 	for i, order := range orders {
 		orderIDs[i] = order.OrderID
 		orderMap[order.OrderID] = i
-		if i > 0 {
-			placeholders += ", "
-		}
-		placeholders += fmt.Sprintf("$%d", i+1)
 	}
+	conditions, args := AndIn(new(Conditions), "order_id", orderIDs).Build()
 
-	query := fmt.Sprintf(`
+	query := `
 		SELECT order_id, driver_id, delivery_latitude, delivery_longitude, out_for_delivery_time, delivered_time, status
 		FROM deliveries
-		WHERE order_id IN (%s)
-	`, placeholders)
+		WHERE ` + conditions + `
+	`
 
-	rows, err := pgos.DB.Query(query, orderIDs...)
+	rows, err := pgos.DB.Query(query, args...)
 	if err != nil {
 		pgos.Logger.Error("Failed to get deliveries for orders", "error", err)
 		return nil, err
@@ -550,17 +1399,29 @@ func (pgos *PostgresOrderStore) populateDeliveries(orders []Order) ([]Order, err
 
 // GetAllDeliveries returns all deliveries for an organization
 func (pgos *PostgresOrderStore) GetAllDeliveries(org_id uuid.UUID) ([]OrderDelivery, error) {
+	return pgos.GetDeliveries(org_id, DeliveryFilter{})
+}
+
+// GetDeliveries returns the deliveries of an organization matching the filter, latest first
+func (pgos *PostgresOrderStore) GetDeliveries(org_id uuid.UUID, filter DeliveryFilter) ([]OrderDelivery, error) {
+	where := Where("o.organization_id = ?", org_id).
+		AndIf(filter.From != nil, "d.out_for_delivery_time >= ?", filter.From).
+		AndIf(filter.To != nil, "d.out_for_delivery_time < ?", filter.To)
+	where = AndIn(where, "d.status", filter.Statuses)
+	where = AndIn(where, "d.driver_id", filter.DriverIDs)
+	conditions, args := where.Build()
+
 	query := `
 		SELECT d.order_id, d.driver_id, d.delivery_latitude, d.delivery_longitude, d.out_for_delivery_time, d.delivered_time, d.status
 		FROM deliveries d
 		JOIN orders o ON d.order_id = o.id
-		WHERE o.organization_id = $1
+		WHERE ` + conditions + `
 		ORDER BY d.out_for_delivery_time DESC
 	`
 
-	rows, err := pgos.DB.Query(query, org_id)
+	rows, err := pgos.DB.Query(query, args...)
 	if err != nil {
-		pgos.Logger.Error("Failed to get all deliveries", "error", err)
+		pgos.Logger.Error("Failed to get deliveries", "error", err)
 		return nil, err
 	}
 	defer rows.Close()
@@ -678,6 +1539,234 @@ func (pgos *PostgresOrderStore) StoreDelivery(org_id uuid.UUID, delivery *OrderD
 	return nil
 }
 
+// UpdateOrderProgress sets the status of an order and, when delivery is not nil, records where its
+// delivery stands. The time the order went out for delivery is kept from the first update. Returns
+// sql.ErrNoRows when the order does not belong to the organization.
+func (pgos *PostgresOrderStore) UpdateOrderProgress(org_id uuid.UUID, order_id uuid.UUID, orderStatus string, delivery *OrderDelivery) error {
+	tx, err := pgos.DB.Begin()
+	if err != nil {
+		pgos.Logger.Error("Failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE orders SET order_status = $3 WHERE id = $1 AND organization_id = $2
+	`, order_id, org_id, orderStatus)
+	if err != nil {
+		pgos.Logger.Error("Failed to update order status", "error", err, "order_id", order_id)
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if delivery != nil {
+		var driverID, deliveredTime any
+		if delivery.DriverID != uuid.Nil {
+			driverID = delivery.DriverID
+		}
+		if !delivery.DeliveredTime.IsZero() {
+			deliveredTime = delivery.DeliveredTime
+		}
+		_, err = tx.Exec(`
+			INSERT INTO deliveries (order_id, driver_id, delivery_latitude, delivery_longitude, out_for_delivery_time, delivered_time, status)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (order_id) DO UPDATE
+			SET driver_id = COALESCE(EXCLUDED.driver_id, deliveries.driver_id),
+			    delivery_latitude = COALESCE(EXCLUDED.delivery_latitude, deliveries.delivery_latitude),
+			    delivery_longitude = COALESCE(EXCLUDED.delivery_longitude, deliveries.delivery_longitude),
+			    delivered_time = COALESCE(EXCLUDED.delivered_time, deliveries.delivered_time),
+			    status = EXCLUDED.status
+		`,
+			order_id,
+			driverID,
+			delivery.DeliveryLocation.Latitude,
+			delivery.DeliveryLocation.Longitude,
+			delivery.OutForDeliveryTime,
+			deliveredTime,
+			delivery.DeliveryStatus,
+		)
+		if err != nil {
+			pgos.Logger.Error("Failed to update delivery", "error", err, "order_id", order_id)
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		pgos.Logger.Error("Failed to commit transaction", "error", err)
+		return err
+	}
+	return nil
+}
+
+// UpdateOrder replaces the fields of an order of the organization, leaving its items and delivery as they
+// are. Returns sql.ErrNoRows when the order does not exist.
+func (pgos *PostgresOrderStore) UpdateOrder(org_id uuid.UUID, order *Order) error {
+	if order.Channel == "" {
+		order.Channel = OrderChannelDirect
+	}
+
+	result, err := pgos.DB.Exec(`
+		UPDATE orders
+		SET user_id = $3, create_time = $4, order_type = $5, order_status = $6, total_amount = $7,
+		    discount_amount = $8, rating = $9, channel = $10
+		WHERE id = $1 AND organization_id = $2
+	`, order.OrderID, org_id, order.UserID, order.CreateTime, order.OrderType, order.OrderStatus, order.TotalAmount, order.DiscountAmount, order.Rating, order.Channel)
+	if err != nil {
+		pgos.Logger.Error("Failed to update order", "error", err, "order_id", order.OrderID)
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteOrder deletes an order of the organization with its items, tables and custom field values, its
+// delivery, redemption and staff tag going with it. Returns sql.ErrNoRows when the order does not exist.
+// TransitionOrderStatus moves an order to toStatus and records the change in its history. The status is
+// read and changed under a lock, so two concurrent changes cannot both start from the same status.
+// Returns an *OrderTransitionError when the lifecycle does not allow the move and sql.ErrNoRows when the
+// order does not belong to the organization. An order becoming ready is prepared at that time, unless
+// its preparation was already recorded.
+func (pgos *PostgresOrderStore) TransitionOrderStatus(org_id uuid.UUID, order_id uuid.UUID, toStatus string, changedBy *uuid.UUID, note *string) (*OrderStatusChange, error) {
+	tx, err := pgos.DB.Begin()
+	if err != nil {
+		pgos.Logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var fromStatus string
+	err = tx.QueryRow(`SELECT order_status FROM orders WHERE id = $1 AND organization_id = $2 FOR UPDATE`, order_id, org_id).Scan(&fromStatus)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			pgos.Logger.Error("Failed to lock order", "error", err, "order_id", order_id)
+		}
+		return nil, err
+	}
+	if !CanTransitionOrder(fromStatus, toStatus) {
+		return nil, &OrderTransitionError{From: fromStatus, To: toStatus}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE orders
+		SET order_status = $2, prepared_at = CASE WHEN $2 = 'ready' THEN COALESCE(prepared_at, NOW()) ELSE prepared_at END
+		WHERE id = $1
+	`, order_id, toStatus)
+	if err != nil {
+		pgos.Logger.Error("Failed to update order status", "error", err, "order_id", order_id)
+		return nil, err
+	}
+
+	change := OrderStatusChange{OrderID: order_id, FromStatus: fromStatus, ToStatus: toStatus, ChangedBy: changedBy, Note: note}
+	err = tx.QueryRow(`
+		INSERT INTO order_status_history (order_id, organization_id, from_status, to_status, changed_by, note)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, changed_at
+	`, order_id, org_id, fromStatus, toStatus, changedBy, note).Scan(&change.ID, &change.ChangedAt)
+	if err != nil {
+		pgos.Logger.Error("Failed to record order status change", "error", err, "order_id", order_id)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		pgos.Logger.Error("Failed to commit order status change", "error", err, "order_id", order_id)
+		return nil, err
+	}
+	return &change, nil
+}
+
+// GetOrderStatusHistory lists the status changes of an order of the organization, oldest first
+func (pgos *PostgresOrderStore) GetOrderStatusHistory(org_id uuid.UUID, order_id uuid.UUID) ([]OrderStatusChange, error) {
+	query := `
+		SELECT h.id, h.order_id, h.from_status, h.to_status, h.changed_by, COALESCE(u.full_name, ''), h.note, h.changed_at
+		FROM order_status_history h
+		LEFT JOIN users u ON u.id = h.changed_by
+		WHERE h.order_id = $1 AND h.organization_id = $2
+		ORDER BY h.changed_at
+	`
+	rows, err := pgos.DB.Query(query, order_id, org_id)
+	if err != nil {
+		pgos.Logger.Error("Failed to get order status history", "error", err, "order_id", order_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []OrderStatusChange{}
+	for rows.Next() {
+		var change OrderStatusChange
+		var changedBy uuid.NullUUID
+		var note sql.NullString
+		if err := rows.Scan(&change.ID, &change.OrderID, &change.FromStatus, &change.ToStatus, &changedBy, &change.ChangedByName, &note, &change.ChangedAt); err != nil {
+			pgos.Logger.Error("Failed to scan order status change", "error", err)
+			return nil, err
+		}
+		if changedBy.Valid {
+			change.ChangedBy = &changedBy.UUID
+		}
+		if note.Valid {
+			change.Note = &note.String
+		}
+		history = append(history, change)
+	}
+	return history, rows.Err()
+}
+
+func (pgos *PostgresOrderStore) DeleteOrder(org_id uuid.UUID, order_id uuid.UUID) error {
+	tx, err := pgos.DB.Begin()
+	if err != nil {
+		pgos.Logger.Error("Failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM orders WHERE id = $1 AND organization_id = $2)`, order_id, org_id).Scan(&exists)
+	if err != nil {
+		pgos.Logger.Error("Failed to check order", "error", err, "order_id", order_id)
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	// order_items and order_tables do not cascade
+	for _, query := range []string{
+		`DELETE FROM order_items WHERE order_id = $1`,
+		`DELETE FROM order_tables WHERE order_id = $1`,
+	} {
+		if _, err := tx.Exec(query, order_id); err != nil {
+			pgos.Logger.Error("Failed to delete order", "error", err, "order_id", order_id)
+			return err
+		}
+	}
+	for _, query := range []string{
+		`DELETE FROM custom_field_values WHERE organization_id = $2 AND entity_type = 'order' AND entity_id = $1`,
+		`DELETE FROM orders WHERE id = $1 AND organization_id = $2`,
+	} {
+		if _, err := tx.Exec(query, order_id, org_id); err != nil {
+			pgos.Logger.Error("Failed to delete order", "error", err, "order_id", order_id)
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		pgos.Logger.Error("Failed to commit transaction", "error", err)
+		return err
+	}
+	return nil
+}
+
 // StoreOrderItems links an existing item to an order with quantity and total_price
 func (pgos *PostgresOrderStore) StoreOrderItems(org_id uuid.UUID, order_id uuid.UUID, orderItem *OrderItem) error {
 	// Verify the order exists and belongs to the organization
@@ -729,6 +1818,138 @@ func (pgos *PostgresOrderStore) StoreOrderItems(org_id uuid.UUID, order_id uuid.
 	return nil
 }
 
+// orderItemBulkChunk is the rows of an insert of StoreOrderItemsBulk, four parameters each
+const orderItemBulkChunk = 5000
+
+// StoreOrderItemsBulk links items to orders in one transaction, checking every order and item of the links
+// in one query and inserting them with a multi-row insert per orderItemBulkChunk links. As with
+// StoreOrderItems a quantity defaults to 1 and the links already stored, or repeated in links, add up.
+// Returns the indexes of the links whose order or item does not belong to the organization, which are not
+// stored.
+func (pgos *PostgresOrderStore) StoreOrderItemsBulk(org_id uuid.UUID, links []OrderItemLink) ([]int, error) {
+	if len(links) == 0 {
+		return nil, nil
+	}
+
+	orderIDs := make([]string, 0)
+	itemIDs := make([]string, 0)
+	seenOrders := make(map[uuid.UUID]bool)
+	seenItems := make(map[uuid.UUID]bool)
+	for _, link := range links {
+		if !seenOrders[link.OrderID] {
+			seenOrders[link.OrderID] = true
+			orderIDs = append(orderIDs, link.OrderID.String())
+		}
+		if !seenItems[link.ItemID] {
+			seenItems[link.ItemID] = true
+			itemIDs = append(itemIDs, link.ItemID.String())
+		}
+	}
+
+	tx, err := pgos.DB.Begin()
+	if err != nil {
+		pgos.Logger.Error("Failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		SELECT 'order', id FROM orders WHERE organization_id = $1 AND id = ANY($2::uuid[])
+		UNION ALL
+		SELECT 'item', id FROM items WHERE organization_id = $1 AND id = ANY($3::uuid[])
+	`, org_id, pq.Array(orderIDs), pq.Array(itemIDs))
+	if err != nil {
+		pgos.Logger.Error("Failed to verify orders and items exist", "error", err)
+		return nil, err
+	}
+	knownOrders := make(map[uuid.UUID]bool, len(orderIDs))
+	knownItems := make(map[uuid.UUID]bool, len(itemIDs))
+	for rows.Next() {
+		var kind string
+		var id uuid.UUID
+		if err := rows.Scan(&kind, &id); err != nil {
+			rows.Close()
+			pgos.Logger.Error("Failed to scan known order or item", "error", err)
+			return nil, err
+		}
+		if kind == "order" {
+			knownOrders[id] = true
+		} else {
+			knownItems[id] = true
+		}
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		pgos.Logger.Error("Failed to verify orders and items exist", "error", err)
+		return nil, err
+	}
+
+	// a statement cannot update a row twice, so the links repeating an order and item are added up first
+	type linkKey struct{ order, item uuid.UUID }
+	var unknown []int
+	var merged []OrderItemLink
+	position := make(map[linkKey]int)
+	for i, link := range links {
+		if !knownOrders[link.OrderID] || !knownItems[link.ItemID] {
+			unknown = append(unknown, i)
+			continue
+		}
+		quantity := 1
+		if link.Quantity != nil {
+			quantity = *link.Quantity
+		}
+		key := linkKey{link.OrderID, link.ItemID}
+		j, ok := position[key]
+		if !ok {
+			position[key] = len(merged)
+			merged = append(merged, OrderItemLink{OrderID: link.OrderID, OrderItem: OrderItem{ItemID: link.ItemID, Quantity: &quantity, TotalPrice: link.TotalPrice}})
+			continue
+		}
+		*merged[j].Quantity += quantity
+		// a missing price leaves the total unknown, as NULL does in the upsert
+		if merged[j].TotalPrice != nil && link.TotalPrice != nil {
+			total := *merged[j].TotalPrice + *link.TotalPrice
+			merged[j].TotalPrice = &total
+		} else {
+			merged[j].TotalPrice = nil
+		}
+	}
+
+	for start := 0; start < len(merged); start += orderItemBulkChunk {
+		chunk := merged[start:min(start+orderItemBulkChunk, len(merged))]
+
+		var values strings.Builder
+		args := make([]any, 0, len(chunk)*4)
+		for i, link := range chunk {
+			if i > 0 {
+				values.WriteString(", ")
+			}
+			n := len(args)
+			fmt.Fprintf(&values, "($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4)
+			args = append(args, link.OrderID, link.ItemID, *link.Quantity, link.TotalPrice)
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO order_items (order_id, item_id, quantity, total_price)
+			VALUES `+values.String()+`
+			ON CONFLICT (order_id, item_id) DO UPDATE
+			SET quantity = order_items.quantity + EXCLUDED.quantity,
+			    total_price = order_items.total_price + EXCLUDED.total_price
+		`, args...)
+		if err != nil {
+			pgos.Logger.Error("Failed to insert order_items", "error", err, "count", len(chunk))
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		pgos.Logger.Error("Failed to commit transaction", "error", err)
+		return nil, err
+	}
+	return unknown, nil
+}
+
 // StoreItems inserts an item into the items table for an organization
 func (pgos *PostgresOrderStore) StoreItems(org_id uuid.UUID, item *Item) error {
 	// Check if item already exists for this organization
@@ -742,7 +1963,7 @@ func (pgos *PostgresOrderStore) StoreItems(org_id uuid.UUID, item *Item) error {
 	}
 	if exists {
 		pgos.Logger.Warn("Item already exists for organization", "name", item.Name, "org_id", org_id)
-		return fmt.Errorf("item with name '%s' already exists", item.Name)
+		return fmt.Errorf("%w: %s", ErrItemExists, item.Name)
 	}
 
 	query := `
@@ -759,10 +1980,10 @@ func (pgos *PostgresOrderStore) StoreItems(org_id uuid.UUID, item *Item) error {
 	return nil
 }
 
-// GetAllItems returns all items for an organization
+// GetAllItems returns all items for an organization, the discontinued ones included
 func (pgos *PostgresOrderStore) GetAllItems(org_id uuid.UUID) ([]Item, error) {
 	query := `
-		SELECT id, name, needed_num_to_prepare, price
+		SELECT id, name, needed_num_to_prepare, price, is_active
 		FROM items
 		WHERE organization_id = $1
 		ORDER BY name ASC
@@ -778,7 +1999,7 @@ func (pgos *PostgresOrderStore) GetAllItems(org_id uuid.UUID) ([]Item, error) {
 	var items []Item
 	for rows.Next() {
 		var item Item
-		err := rows.Scan(&item.ItemID, &item.Name, &item.NeededNumEmployeesToPrepare, &item.Price)
+		err := rows.Scan(&item.ItemID, &item.Name, &item.NeededNumEmployeesToPrepare, &item.Price, &item.IsActive)
 		if err != nil {
 			pgos.Logger.Error("Failed to scan item row", "error", err)
 			return nil, err
@@ -793,6 +2014,99 @@ func (pgos *PostgresOrderStore) GetAllItems(org_id uuid.UUID) ([]Item, error) {
 	return items, nil
 }
 
+// GetItem returns an item of the organization, sql.ErrNoRows when there is none with that id
+func (pgos *PostgresOrderStore) GetItem(org_id uuid.UUID, item_id uuid.UUID) (*Item, error) {
+	var item Item
+	err := pgos.DB.QueryRow(`
+		SELECT id, name, needed_num_to_prepare, price, is_active
+		FROM items
+		WHERE organization_id = $1 AND id = $2
+	`, org_id, item_id).Scan(&item.ItemID, &item.Name, &item.NeededNumEmployeesToPrepare, &item.Price, &item.IsActive)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			pgos.Logger.Error("Failed to get item", "error", err, "item_id", item_id)
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// UpdateItem replaces the name, preparation staff, price and active flag of an item, recording a new price
+// in its price history. sql.ErrNoRows is returned when the item does not exist and ErrItemExists when
+// another item of the organization has the name.
+func (pgos *PostgresOrderStore) UpdateItem(org_id uuid.UUID, item *Item, changedBy *uuid.UUID) error {
+	tx, err := pgos.DB.Begin()
+	if err != nil {
+		pgos.Logger.Error("Failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var oldPrice float64
+	err = tx.QueryRow(`SELECT price FROM items WHERE organization_id = $1 AND id = $2 FOR UPDATE`, org_id, item.ItemID).Scan(&oldPrice)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			pgos.Logger.Error("Failed to get item", "error", err, "item_id", item.ItemID)
+		}
+		return err
+	}
+
+	var taken bool
+	err = tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM items WHERE organization_id = $1 AND name = $2 AND id <> $3)`,
+		org_id, item.Name, item.ItemID).Scan(&taken)
+	if err != nil {
+		pgos.Logger.Error("Failed to check if item exists", "error", err)
+		return err
+	}
+	if taken {
+		return fmt.Errorf("%w: %s", ErrItemExists, item.Name)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE items SET name = $3, needed_num_to_prepare = $4, price = $5, is_active = $6
+		WHERE organization_id = $1 AND id = $2
+	`, org_id, item.ItemID, item.Name, item.NeededNumEmployeesToPrepare, item.Price, item.IsActive)
+	if err != nil {
+		pgos.Logger.Error("Failed to update item", "error", err, "item_id", item.ItemID)
+		return err
+	}
+
+	if item.Price != nil && *item.Price != oldPrice {
+		_, err = tx.Exec(`
+			INSERT INTO item_price_history (organization_id, item_id, old_price, new_price, changed_by)
+			VALUES ($1, $2, $3, $4, $5)
+		`, org_id, item.ItemID, oldPrice, *item.Price, changedBy)
+		if err != nil {
+			pgos.Logger.Error("Failed to record item price change", "error", err, "item_id", item.ItemID)
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		pgos.Logger.Error("Failed to commit transaction", "error", err)
+		return err
+	}
+	return nil
+}
+
+// DeactivateItem discontinues an item, keeping it in the orders it was sold in. sql.ErrNoRows is returned
+// when the item does not exist.
+func (pgos *PostgresOrderStore) DeactivateItem(org_id uuid.UUID, item_id uuid.UUID) error {
+	result, err := pgos.DB.Exec(`UPDATE items SET is_active = FALSE WHERE organization_id = $1 AND id = $2`, org_id, item_id)
+	if err != nil {
+		pgos.Logger.Error("Failed to deactivate item", "error", err, "item_id", item_id)
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
 // GetItemsInsights returns insights about items for an organization
 func (pgos *PostgresOrderStore) GetItemsInsights(org_id uuid.UUID) ([]Insight, error) {
 	var insights []Insight
@@ -0,0 +1,158 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrderRating is the rating a customer gave an order
+type OrderRating struct {
+	Rating     float64   `json:"rating"`
+	CreateTime time.Time `json:"create_time"`
+}
+
+// OrganizationRating is a recomputation of the rating of an organization from the orders rated in the
+// WindowDays before ComputedAt, each weighted half as much every HalfLifeDays of age
+type OrganizationRating struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Rating         float64   `json:"rating"`
+	RatedOrders    int       `json:"rated_orders"`
+	WindowDays     int       `json:"window_days"`
+	HalfLifeDays   int       `json:"half_life_days"`
+	ComputedAt     time.Time `json:"computed_at"`
+}
+
+type OrgRatingStore interface {
+	GetRatedOrganizations(since time.Time) ([]uuid.UUID, error)
+	GetOrderRatings(org_id uuid.UUID, since time.Time) ([]OrderRating, error)
+	StoreRating(rating *OrganizationRating) error
+	ListRatings(org_id uuid.UUID, from, to time.Time) ([]OrganizationRating, error)
+}
+
+type PostgresOrgRatingStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresOrgRatingStore(DB *sql.DB, Logger *slog.Logger) *PostgresOrgRatingStore {
+	return &PostgresOrgRatingStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// GetRatedOrganizations lists the organizations with a rated order since since
+func (s *PostgresOrgRatingStore) GetRatedOrganizations(since time.Time) ([]uuid.UUID, error) {
+	query := `SELECT DISTINCT organization_id FROM orders WHERE rating IS NOT NULL AND create_time >= $1`
+
+	rows, err := s.DB.Query(query, since)
+	if err != nil {
+		s.Logger.Error("failed to get rated organizations", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	orgs := []uuid.UUID{}
+	for rows.Next() {
+		var orgID uuid.UUID
+		if err := rows.Scan(&orgID); err != nil {
+			s.Logger.Error("failed to scan rated organization", "error", err)
+			return nil, err
+		}
+		orgs = append(orgs, orgID)
+	}
+	return orgs, rows.Err()
+}
+
+// GetOrderRatings returns the ratings of the orders of the organization placed since since
+func (s *PostgresOrgRatingStore) GetOrderRatings(org_id uuid.UUID, since time.Time) ([]OrderRating, error) {
+	query := `
+		SELECT rating, create_time FROM orders
+		WHERE organization_id = $1 AND rating IS NOT NULL AND create_time >= $2
+		ORDER BY create_time
+	`
+	rows, err := s.DB.Query(query, org_id, since)
+	if err != nil {
+		s.Logger.Error("failed to get order ratings", "error", err, "organization_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ratings := []OrderRating{}
+	for rows.Next() {
+		var rating OrderRating
+		if err := rows.Scan(&rating.Rating, &rating.CreateTime); err != nil {
+			s.Logger.Error("failed to scan order rating", "error", err)
+			return nil, err
+		}
+		ratings = append(ratings, rating)
+	}
+	return ratings, rows.Err()
+}
+
+// StoreRating records the rating in the history and makes it the rating of the organization, giving it an
+// ID and computation time when missing
+func (s *PostgresOrgRatingStore) StoreRating(rating *OrganizationRating) error {
+	if rating.ID == uuid.Nil {
+		rating.ID = uuid.New()
+	}
+	if rating.ComputedAt.IsZero() {
+		rating.ComputedAt = time.Now()
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		s.Logger.Error("failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO organization_rating_history (id, organization_id, rating, rated_orders, window_days, half_life_days, computed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	if _, err := tx.Exec(query, rating.ID, rating.OrganizationID, rating.Rating, rating.RatedOrders, rating.WindowDays,
+		rating.HalfLifeDays, rating.ComputedAt); err != nil {
+		s.Logger.Error("failed to store organization rating", "error", err, "organization_id", rating.OrganizationID)
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE organizations SET rating = $2 WHERE id = $1`, rating.OrganizationID, rating.Rating); err != nil {
+		s.Logger.Error("failed to update organization rating", "error", err, "organization_id", rating.OrganizationID)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListRatings lists the ratings computed from from up to, excluding, to, oldest first
+func (s *PostgresOrgRatingStore) ListRatings(org_id uuid.UUID, from, to time.Time) ([]OrganizationRating, error) {
+	query := `
+		SELECT id, organization_id, rating, rated_orders, window_days, half_life_days, computed_at
+		FROM organization_rating_history
+		WHERE organization_id = $1 AND computed_at >= $2 AND computed_at < $3
+		ORDER BY computed_at
+	`
+	rows, err := s.DB.Query(query, org_id, from, to)
+	if err != nil {
+		s.Logger.Error("failed to list organization ratings", "error", err, "organization_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ratings := []OrganizationRating{}
+	for rows.Next() {
+		var rating OrganizationRating
+		if err := rows.Scan(&rating.ID, &rating.OrganizationID, &rating.Rating, &rating.RatedOrders, &rating.WindowDays,
+			&rating.HalfLifeDays, &rating.ComputedAt); err != nil {
+			s.Logger.Error("failed to scan organization rating", "error", err)
+			return nil, err
+		}
+		ratings = append(ratings, rating)
+	}
+	return ratings, rows.Err()
+}
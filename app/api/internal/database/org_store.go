@@ -2,13 +2,21 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+// ErrOrganizationExists is returned when the name, email or phone of a new organization is taken
+var ErrOrganizationExists = errors.New("an organization already uses this name, email or phone")
+
+// ErrCustomDomainTaken is returned when another organization already verified the custom domain
+var ErrCustomDomainTaken = errors.New("custom domain is verified by another organization")
+
 type Organization struct {
 	ID        uuid.UUID `json:"id"`
 	Name      string    `json:"name"`
@@ -39,12 +47,49 @@ type OrganizationProfile struct {
 	NumberOfEmployees int      `json:"number_of_employees"`
 }
 
+// OrgCloneOptions selects the configuration copied into a cloned organization
+type OrgCloneOptions struct {
+	Rules          bool
+	Roles          bool // with their production chains and skill requirements
+	OperatingHours bool // the weekly hours, not the exceptions
+	Items          bool
+	Campaigns      bool // copied inactive, as templates
+}
+
+// CustomDomain is the host name an organization serves its dashboard from. It is pending until the
+// token is published in the TXT record of the domain, and only verified domains are CORS origins.
+type CustomDomain struct {
+	Domain     string     `json:"domain"`
+	Token      string     `json:"verification_token"`
+	VerifiedAt *time.Time `json:"verified_at"`
+}
+
+// OrgClone tells what was copied into a cloned organization
+type OrgClone struct {
+	Organization   *Organization `json:"organization"`
+	Rules          bool          `json:"rules"`
+	Roles          int           `json:"roles"`
+	OperatingHours int           `json:"operating_hours"`
+	Items          int           `json:"items"`
+	Campaigns      int           `json:"campaigns"`
+}
+
 type OrgStore interface {
 	CreateOrgWithAdmin(org *Organization, adminUser *User, password string) error
+	CloneOrganization(sourceID uuid.UUID, org *Organization, adminID uuid.UUID, options OrgCloneOptions) (*OrgClone, error)
 	GetOrganizationByID(id uuid.UUID) (*Organization, error)
 	GetOrganizationProfile(id uuid.UUID) (*OrganizationProfile, error)
 	GetManagerEmailsByOrgID(orgID uuid.UUID) ([]string, error)
 	GetAdminEmailsByOrgID(orgID uuid.UUID) ([]string, error)
+	SetCustomDomain(orgID uuid.UUID, domain *string, token *string) error
+	GetCustomDomain(orgID uuid.UUID) (*CustomDomain, error)
+	VerifyCustomDomain(orgID uuid.UUID) error
+	SetBrandColors(orgID uuid.UUID, hex1, hex2, hex3 string) error
+	GetOrgIDByCustomDomain(domain string) (uuid.UUID, error)
+	GetCurrency(orgID uuid.UUID) (string, error)
+	SetCurrency(orgID uuid.UUID, currency string) error
+	GetMagicLinkEnabled(orgID uuid.UUID) (bool, error)
+	SetMagicLinkEnabled(orgID uuid.UUID, enabled bool) error
 }
 
 type PostgresOrgStore struct {
@@ -93,6 +138,186 @@ func (s *PostgresOrgStore) CreateOrgWithAdmin(org *Organization, user *User, pla
 	return tx.Commit()
 }
 
+// CloneOrganization creates org with the configuration of the source organization selected by options, in one
+// transaction. Orders, employees and schedules are never copied. The new organization takes the type, brand
+// colors and currency of the source, and the admin cloning it becomes its admin through a membership. Campaigns
+// keep their items only when the items are copied too. Returns sql.ErrNoRows when the source does not exist and
+// ErrOrganizationExists when the name, email or phone is taken.
+func (s *PostgresOrgStore) CloneOrganization(sourceID uuid.UUID, org *Organization, adminID uuid.UUID, options OrgCloneOptions) (*OrgClone, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	org.ID = uuid.New()
+	org.CreatedAt = time.Now()
+	org.UpdatedAt = org.CreatedAt
+	queryOrg := `
+		INSERT INTO organizations (id, name, address, latitude, longitude, email, phone, type, hex_code1, hex_code2, hex_code3,
+			currency, magic_link_enabled, created_at, updated_at)
+		SELECT $2, $3, $4, $5, $6, $7, $8, type, hex_code1, hex_code2, hex_code3, currency, magic_link_enabled, $9, $9
+		FROM organizations
+		WHERE id = $1
+		RETURNING type, hex_code1, hex_code2, hex_code3
+	`
+	err = tx.QueryRow(queryOrg, sourceID, org.ID, org.Name, org.Address, org.Location.Latitude, org.Location.Longitude, org.Email,
+		org.Phone, org.CreatedAt).Scan(&org.Type, &org.HexCode1, &org.HexCode2, &org.HexCode3)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrOrganizationExists
+		}
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to insert org: %w", err)
+	}
+
+	queryMember := `INSERT INTO organization_memberships (user_id, organization_id, user_role, created_at) VALUES ($1, $2, 'admin', $3)`
+	if _, err := tx.Exec(queryMember, adminID, org.ID, org.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to add admin membership: %w", err)
+	}
+
+	clone := &OrgClone{Organization: org}
+	// copyRows runs a statement copying rows of the source, returning how many were copied
+	copyRows := func(what, query string, args ...any) (int, error) {
+		result, err := tx.Exec(query, args...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to copy %s: %w", what, err)
+		}
+		count, err := result.RowsAffected()
+		return int(count), err
+	}
+
+	if options.Rules {
+		copied, err := copyRows("rules", `
+			INSERT INTO organizations_rules (organization_id, shift_max_hours, shift_min_hours, max_weekly_hours, min_weekly_hours,
+				fixed_shifts, number_of_shifts_per_day, meet_all_demand, min_rest_slots, slot_len_hour, min_shift_length_slots,
+				receiving_phone, delivery, waiting_time, accepting_orders, weekly_labor_budget, prep_buffer_minutes, delivery_minutes,
+				wait_time_factor, standby_pay_percent, cancellation_notice_hours, predictability_notice_days, predictability_pay_hours,
+				predictability_lost_hours_percent, auto_close_orders, orders_per_staff_hour, auto_close_load_percent,
+				auto_reopen_load_percent, delivery_sla_minutes, sla_breach_alert_percent)
+			SELECT $2, shift_max_hours, shift_min_hours, max_weekly_hours, min_weekly_hours,
+				fixed_shifts, number_of_shifts_per_day, meet_all_demand, min_rest_slots, slot_len_hour, min_shift_length_slots,
+				receiving_phone, delivery, waiting_time, accepting_orders, weekly_labor_budget, prep_buffer_minutes, delivery_minutes,
+				wait_time_factor, standby_pay_percent, cancellation_notice_hours, predictability_notice_days, predictability_pay_hours,
+				predictability_lost_hours_percent, auto_close_orders, orders_per_staff_hour, auto_close_load_percent,
+				auto_reopen_load_percent, delivery_sla_minutes, sla_breach_alert_percent
+			FROM organizations_rules
+			WHERE organization_id = $1
+		`, sourceID, org.ID)
+		if err != nil {
+			return nil, err
+		}
+		clone.Rules = copied > 0
+	}
+
+	if options.Roles {
+		// The default roles were created with the organization, they take the settings of the source
+		if clone.Roles, err = copyRows("roles", `
+			INSERT INTO organizations_roles (organization_id, role, min_needed_per_shift, items_per_role_per_hour, need_for_demand, independent, solo_coverage)
+			SELECT $2, role, min_needed_per_shift, items_per_role_per_hour, need_for_demand, independent, solo_coverage
+			FROM organizations_roles
+			WHERE organization_id = $1
+			ON CONFLICT (organization_id, role) DO UPDATE SET
+				min_needed_per_shift = EXCLUDED.min_needed_per_shift,
+				items_per_role_per_hour = EXCLUDED.items_per_role_per_hour,
+				need_for_demand = EXCLUDED.need_for_demand,
+				independent = EXCLUDED.independent,
+				solo_coverage = EXCLUDED.solo_coverage
+		`, sourceID, org.ID); err != nil {
+			return nil, err
+		}
+		if _, err := copyRows("production chains", `
+			INSERT INTO production_chain (organization_id, chain_name, role, contrib_factor)
+			SELECT $2, chain_name, role, contrib_factor FROM production_chain WHERE organization_id = $1
+		`, sourceID, org.ID); err != nil {
+			return nil, err
+		}
+		if _, err := copyRows("skill requirements", `
+			INSERT INTO role_skill_requirements (organization_id, role, skills)
+			SELECT $2, role, skills FROM role_skill_requirements WHERE organization_id = $1
+		`, sourceID, org.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.OperatingHours {
+		if clone.OperatingHours, err = copyRows("operating hours", `
+			INSERT INTO organizations_operating_hours (organization_id, weekday, opening_time, closing_time)
+			SELECT $2, weekday, opening_time, closing_time FROM organizations_operating_hours WHERE organization_id = $1
+		`, sourceID, org.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Items and campaigns get new IDs, the maps pair the IDs of the source with them
+	var itemsFrom, itemsTo []uuid.UUID
+	if options.Items {
+		if itemsFrom, itemsTo, err = cloneIDs(tx, `SELECT id FROM items WHERE organization_id = $1`, sourceID); err != nil {
+			return nil, fmt.Errorf("failed to get items: %w", err)
+		}
+		if clone.Items, err = copyRows("items", `
+			INSERT INTO items (id, organization_id, name, needed_num_to_prepare, price, is_active)
+			SELECT m.to_id, $1, i.name, i.needed_num_to_prepare, i.price, i.is_active
+			FROM items i
+			JOIN unnest($2::uuid[], $3::uuid[]) AS m(from_id, to_id) ON i.id = m.from_id
+		`, org.ID, pq.Array(itemsFrom), pq.Array(itemsTo)); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.Campaigns {
+		campaignsFrom, campaignsTo, err := cloneIDs(tx, `SELECT id FROM marketing_campaigns WHERE organization_id = $1`, sourceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get campaigns: %w", err)
+		}
+		if clone.Campaigns, err = copyRows("campaigns", `
+			INSERT INTO marketing_campaigns (id, organization_id, name, status, start_time_date, end_time_date, discount_percent,
+				channel, redemption_code)
+			SELECT m.to_id, $1, c.name, 'inactive', c.start_time_date, c.end_time_date, c.discount_percent, c.channel, c.redemption_code
+			FROM marketing_campaigns c
+			JOIN unnest($2::uuid[], $3::uuid[]) AS m(from_id, to_id) ON c.id = m.from_id
+		`, org.ID, pq.Array(campaignsFrom), pq.Array(campaignsTo)); err != nil {
+			return nil, err
+		}
+		if options.Items {
+			if _, err := copyRows("campaign items", `
+				INSERT INTO campaigns_items (campaign_id, item_id)
+				SELECT c.to_id, i.to_id
+				FROM campaigns_items ci
+				JOIN unnest($1::uuid[], $2::uuid[]) AS c(from_id, to_id) ON ci.campaign_id = c.from_id
+				JOIN unnest($3::uuid[], $4::uuid[]) AS i(from_id, to_id) ON ci.item_id = i.from_id
+			`, pq.Array(campaignsFrom), pq.Array(campaignsTo), pq.Array(itemsFrom), pq.Array(itemsTo)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+// cloneIDs reads the IDs a query returns and pairs each with a new ID
+func cloneIDs(tx *sql.Tx, query string, args ...any) (from, to []uuid.UUID, err error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, nil, err
+		}
+		from = append(from, id)
+		to = append(to, uuid.New())
+	}
+	return from, to, rows.Err()
+}
+
 func (s *PostgresOrgStore) GetOrganizationByID(id uuid.UUID) (*Organization, error) {
 	var org Organization
 	query := `SELECT id, name, address, latitude, longitude, email, type, phone, hex_code1, hex_code2, hex_code3, rating, created_at, updated_at FROM organizations WHERE id = $1`
@@ -179,3 +404,142 @@ func (s *PostgresOrgStore) GetAdminEmailsByOrgID(orgID uuid.UUID) ([]string, err
 	}
 	return emails, nil
 }
+
+// SetCustomDomain sets the host name the organization serves its dashboard from, pending until it is
+// verified with the token. nil removes it.
+func (s *PostgresOrgStore) SetCustomDomain(orgID uuid.UUID, domain *string, token *string) error {
+	query := `
+		UPDATE organizations
+		SET custom_domain = $1, custom_domain_token = $2, custom_domain_verified_at = NULL, updated_at = $3
+		WHERE id = $4
+	`
+	result, err := s.db.Exec(query, domain, token, time.Now(), orgID)
+	if err != nil {
+		return fmt.Errorf("failed to set custom domain: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetCustomDomain returns the custom domain of the organization and its verification, or sql.ErrNoRows
+// when it has none
+func (s *PostgresOrgStore) GetCustomDomain(orgID uuid.UUID) (*CustomDomain, error) {
+	var domain CustomDomain
+	query := `
+		SELECT custom_domain, COALESCE(custom_domain_token, ''), custom_domain_verified_at
+		FROM organizations
+		WHERE id = $1 AND custom_domain IS NOT NULL
+	`
+	if err := s.db.QueryRow(query, orgID).Scan(&domain.Domain, &domain.Token, &domain.VerifiedAt); err != nil {
+		return nil, err
+	}
+	return &domain, nil
+}
+
+// VerifyCustomDomain marks the custom domain of the organization as verified. sql.ErrNoRows is returned
+// when it has none and ErrCustomDomainTaken when another organization verified it first.
+func (s *PostgresOrgStore) VerifyCustomDomain(orgID uuid.UUID) error {
+	query := `UPDATE organizations SET custom_domain_verified_at = $1, updated_at = $1 WHERE id = $2 AND custom_domain IS NOT NULL`
+	result, err := s.db.Exec(query, time.Now(), orgID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrCustomDomainTaken
+		}
+		return fmt.Errorf("failed to verify custom domain: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetBrandColors changes the three brand colors of the organization, as hex codes without '#'
+func (s *PostgresOrgStore) SetBrandColors(orgID uuid.UUID, hex1, hex2, hex3 string) error {
+	query := `UPDATE organizations SET hex_code1 = $1, hex_code2 = $2, hex_code3 = $3, updated_at = $4 WHERE id = $5`
+	result, err := s.db.Exec(query, hex1, hex2, hex3, time.Now(), orgID)
+	if err != nil {
+		return fmt.Errorf("failed to set brand colors: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetOrgIDByCustomDomain returns the organization that verified the host name, or sql.ErrNoRows. Pending
+// domains are not returned.
+func (s *PostgresOrgStore) GetOrgIDByCustomDomain(domain string) (uuid.UUID, error) {
+	var orgID uuid.UUID
+	query := `SELECT id FROM organizations WHERE LOWER(custom_domain) = LOWER($1) AND custom_domain_verified_at IS NOT NULL`
+	if err := s.db.QueryRow(query, domain).Scan(&orgID); err != nil {
+		return uuid.Nil, err
+	}
+	return orgID, nil
+}
+
+// GetCurrency returns the ISO 4217 code of the currency the organization's amounts are in, or sql.ErrNoRows
+func (s *PostgresOrgStore) GetCurrency(orgID uuid.UUID) (string, error) {
+	var currency string
+	query := `SELECT currency FROM organizations WHERE id = $1`
+	if err := s.db.QueryRow(query, orgID).Scan(&currency); err != nil {
+		return "", err
+	}
+	return currency, nil
+}
+
+// SetCurrency changes the currency of the organization's amounts, they are not converted
+func (s *PostgresOrgStore) SetCurrency(orgID uuid.UUID, currency string) error {
+	query := `UPDATE organizations SET currency = $1, updated_at = $2 WHERE id = $3`
+	result, err := s.db.Exec(query, currency, time.Now(), orgID)
+	if err != nil {
+		return fmt.Errorf("failed to set currency: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetMagicLinkEnabled reports whether the members of the organization can log in with emailed links
+func (s *PostgresOrgStore) GetMagicLinkEnabled(orgID uuid.UUID) (bool, error) {
+	var enabled bool
+	query := `SELECT magic_link_enabled FROM organizations WHERE id = $1`
+	if err := s.db.QueryRow(query, orgID).Scan(&enabled); err != nil {
+		return false, err
+	}
+	return enabled, nil
+}
+
+func (s *PostgresOrgStore) SetMagicLinkEnabled(orgID uuid.UUID, enabled bool) error {
+	query := `UPDATE organizations SET magic_link_enabled = $1, updated_at = $2 WHERE id = $3`
+	result, err := s.db.Exec(query, enabled, time.Now(), orgID)
+	if err != nil {
+		return fmt.Errorf("failed to set magic link login: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
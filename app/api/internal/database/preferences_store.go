@@ -4,8 +4,10 @@ import (
 	"database/sql"
 	"errors"
 	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // EmployeePreference represents a single day's preference for an employee
@@ -18,6 +20,18 @@ type EmployeePreference struct {
 	AvailableEndTime   *string   `json:"available_end_time"`
 }
 
+// EmployeeUnavailability is a period, inclusive, an employee cannot be scheduled because their
+// holiday or call-off request was approved
+type EmployeeUnavailability struct {
+	ID         uuid.UUID `json:"id"`
+	EmployeeID uuid.UUID `json:"employee_id"`
+	RequestID  uuid.UUID `json:"request_id"`
+	Reason     string    `json:"reason"`
+	StartDate  time.Time `json:"start_date"`
+	EndDate    time.Time `json:"end_date"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
 // ValidDays is the list of valid day values
 var ValidDays = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
 
@@ -45,6 +59,10 @@ type PreferencesStore interface {
 	DeletePreferences(employeeID uuid.UUID) error
 	// Delete preference for a specific day
 	DeletePreferenceByDay(employeeID uuid.UUID, day string) error
+	// Replace the preferences of employees with a copy of another employee's
+	CopyPreferences(fromID uuid.UUID, toIDs []uuid.UUID) error
+	// Get the approved unavailability of an employee overlapping a period
+	GetUnavailability(employeeID uuid.UUID, from, to time.Time) ([]EmployeeUnavailability, error)
 }
 
 // PostgresPreferencesStore implements PreferencesStore using PostgreSQL
@@ -231,3 +249,65 @@ func (s *PostgresPreferencesStore) DeletePreferenceByDay(employeeID uuid.UUID, d
 	s.Logger.Info("preference deleted", "employee_id", employeeID, "day", day)
 	return nil
 }
+
+// CopyPreferences replaces the day preferences of the employees of toIDs with those of fromID, in one
+// transaction. Days fromID has no preference for are left without one.
+func (s *PostgresPreferencesStore) CopyPreferences(fromID uuid.UUID, toIDs []uuid.UUID) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.Logger.Error("failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM employees_preferences WHERE employee_id = ANY($1::uuid[])`, pq.Array(toIDs)); err != nil {
+		s.Logger.Error("failed to clear preferences before copy", "error", err, "from_id", fromID)
+		return err
+	}
+
+	query := `INSERT INTO employees_preferences
+		(employee_id, day, preferred_start_time, preferred_end_time, available_start_time, available_end_time)
+		SELECT t.id, p.day, p.preferred_start_time, p.preferred_end_time, p.available_start_time, p.available_end_time
+		FROM employees_preferences p
+		CROSS JOIN UNNEST($2::uuid[]) AS t(id)
+		WHERE p.employee_id = $1`
+	if _, err := tx.Exec(query, fromID, pq.Array(toIDs)); err != nil {
+		s.Logger.Error("failed to copy preferences", "error", err, "from_id", fromID)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.Logger.Error("failed to commit transaction", "error", err)
+		return err
+	}
+
+	s.Logger.Info("preferences copied", "from_id", fromID, "count", len(toIDs))
+	return nil
+}
+
+// GetUnavailability retrieves the unavailability of an employee sharing at least one day with from-to
+// (inclusive), in chronological order
+func (s *PostgresPreferencesStore) GetUnavailability(employeeID uuid.UUID, from, to time.Time) ([]EmployeeUnavailability, error) {
+	query := `SELECT id, employee_id, request_id, reason, start_date, end_date, created_at
+		FROM employee_unavailability
+		WHERE employee_id = $1 AND start_date <= $3 AND end_date >= $2
+		ORDER BY start_date, end_date`
+
+	rows, err := s.db.Query(query, employeeID, from, to)
+	if err != nil {
+		s.Logger.Error("failed to get unavailability", "error", err, "employee_id", employeeID)
+		return nil, err
+	}
+	defer rows.Close()
+
+	unavailability := []EmployeeUnavailability{}
+	for rows.Next() {
+		var u EmployeeUnavailability
+		if err := rows.Scan(&u.ID, &u.EmployeeID, &u.RequestID, &u.Reason, &u.StartDate, &u.EndDate, &u.CreatedAt); err != nil {
+			s.Logger.Error("failed to scan unavailability", "error", err)
+			return nil, err
+		}
+		unavailability = append(unavailability, u)
+	}
+	return unavailability, rows.Err()
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DayForecast is the demand forecast of one day, summed over its hours
+type DayForecast struct {
+	Date       time.Time `json:"date"`
+	OrderCount int       `json:"order_count"`
+	ItemCount  int       `json:"item_count"`
+	Hours      int       `json:"hours"`
+}
+
+// ItemSales is how many units of an item were sold in the history window, in total and on the weekday of the prep list
+type ItemSales struct {
+	ItemID         uuid.UUID `json:"item_id"`
+	Name           string    `json:"name"`
+	UnitsOnWeekday int       `json:"units_on_weekday"`
+	UnitsAllDays   int       `json:"units_all_days"`
+}
+
+type PrepListStore interface {
+	GetDayForecast(org_id uuid.UUID, date time.Time) (*DayForecast, error)
+	GetItemSales(org_id uuid.UUID, weekday time.Weekday, since time.Time) ([]ItemSales, error)
+}
+
+type PostgresPrepListStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresPrepListStore(DB *sql.DB, Logger *slog.Logger) *PostgresPrepListStore {
+	return &PostgresPrepListStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// GetDayForecast sums the predicted demand of a day, or returns sql.ErrNoRows when the day has no forecast
+func (s *PostgresPrepListStore) GetDayForecast(org_id uuid.UUID, date time.Time) (*DayForecast, error) {
+	query := `
+		SELECT COUNT(*), COALESCE(SUM(order_count), 0), COALESCE(SUM(item_count), 0)
+		FROM demand
+		WHERE organization_id = $1 AND demand_date = $2
+	`
+	forecast := DayForecast{Date: date}
+	if err := s.DB.QueryRow(query, org_id, date.Format(time.DateOnly)).Scan(&forecast.Hours, &forecast.OrderCount, &forecast.ItemCount); err != nil {
+		s.Logger.Error("failed to get day forecast", "error", err, "organization_id", org_id)
+		return nil, err
+	}
+	if forecast.Hours == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &forecast, nil
+}
+
+// GetItemSales lists the units sold per item still on the menu since the given time, ordered by name
+func (s *PostgresPrepListStore) GetItemSales(org_id uuid.UUID, weekday time.Weekday, since time.Time) ([]ItemSales, error) {
+	query := `
+		SELECT i.id, i.name,
+			COALESCE(SUM(oi.quantity) FILTER (WHERE EXTRACT(DOW FROM o.create_time) = $2), 0),
+			COALESCE(SUM(oi.quantity), 0)
+		FROM items i
+		JOIN order_items oi ON oi.item_id = i.id
+		JOIN orders o ON o.id = oi.order_id
+		WHERE i.organization_id = $1 AND i.is_active AND o.create_time >= $3
+		GROUP BY i.id, i.name
+		ORDER BY i.name
+	`
+	rows, err := s.DB.Query(query, org_id, int(weekday), since)
+	if err != nil {
+		s.Logger.Error("failed to get item sales", "error", err, "organization_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	sales := []ItemSales{}
+	for rows.Next() {
+		var item ItemSales
+		if err := rows.Scan(&item.ItemID, &item.Name, &item.UnitsOnWeekday, &item.UnitsAllDays); err != nil {
+			s.Logger.Error("failed to scan item sales", "error", err)
+			return nil, err
+		}
+		sales = append(sales, item)
+	}
+	return sales, rows.Err()
+}
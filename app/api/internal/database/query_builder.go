@@ -0,0 +1,66 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Conditions builds the WHERE clause of a query from filters that may or may not be set, so stores
+// never concatenate values into SQL. Conditions use ? for their arguments, which are numbered $1, $2...
+// in the order the conditions were added. A condition must not contain a literal ?.
+type Conditions struct {
+	conditions []string
+	args       []any
+}
+
+// Where starts the conditions of a query with the condition every row must match, e.g. the organization
+func Where(condition string, args ...any) *Conditions {
+	return new(Conditions).And(condition, args...)
+}
+
+// And adds a condition. It panics when the number of ? does not match the arguments, which is a bug
+// in the store and not something a request can cause.
+func (c *Conditions) And(condition string, args ...any) *Conditions {
+	if strings.Count(condition, "?") != len(args) {
+		panic(fmt.Sprintf("query condition %q has %d arguments", condition, len(args)))
+	}
+	for _, arg := range args {
+		c.args = append(c.args, arg)
+		condition = strings.Replace(condition, "?", fmt.Sprintf("$%d", len(c.args)), 1)
+	}
+	c.conditions = append(c.conditions, condition)
+	return c
+}
+
+// AndIf adds the condition only when ok, typically when an optional filter is set
+func (c *Conditions) AndIf(ok bool, condition string, args ...any) *Conditions {
+	if !ok {
+		return c
+	}
+	return c.And(condition, args...)
+}
+
+// AndIn adds column IN (values). An empty list adds nothing, so an unset filter matches every row.
+func AndIn[T any](c *Conditions, column string, values []T) *Conditions {
+	if len(values) == 0 {
+		return c
+	}
+	args := make([]any, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	return c.And(column+" IN ("+Placeholders(len(values))+")", args...)
+}
+
+// Placeholders returns n comma separated ?, for lists inside a subquery condition
+func Placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// Build returns the conditions joined by AND, without the WHERE keyword, and their arguments
+func (c *Conditions) Build() (string, []any) {
+	if len(c.conditions) == 0 {
+		return "TRUE", nil
+	}
+	return strings.Join(c.conditions, " AND "), c.args
+}
@@ -0,0 +1,238 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Decisions taken on the steps of an approval chain
+const (
+	ApprovalApproved = "approved"
+	ApprovalDeclined = "declined"
+)
+
+// ErrApprovalStepDecided is returned when a step was decided by someone else in the meantime
+var ErrApprovalStepDecided = errors.New("approval step already decided")
+
+// ApprovalChain lists the roles that must approve a request type, in order
+type ApprovalChain struct {
+	RequestType string   `json:"request_type"`
+	Steps       []string `json:"steps"`
+	// Default is set for request types the organization has not configured
+	Default bool `json:"default"`
+}
+
+// RequestApproval is a step of the approval chain of a request. Decision is nil until the step is
+// decided.
+type RequestApproval struct {
+	Step         int        `json:"step"`
+	ApproverRole string     `json:"approver_role"`
+	Decision     *string    `json:"decision"`
+	DecidedBy    *uuid.UUID `json:"decided_by,omitempty"`
+	DecidedAt    *time.Time `json:"decided_at,omitempty"`
+}
+
+// DefaultApprovalChain is the chain of request types the organization has not configured:
+// resignations need an admin sign-off after the manager review, other requests a single manager
+// (or admin) approval
+func DefaultApprovalChain(requestType string) []string {
+	if requestType == "resign" {
+		return []string{"manager", "admin"}
+	}
+	return []string{"manager"}
+}
+
+type ApprovalStore interface {
+	GetApprovalChains(org_id uuid.UUID) ([]ApprovalChain, error)
+	GetApprovalChain(org_id uuid.UUID, requestType string) ([]string, error)
+	SetApprovalChain(org_id uuid.UUID, requestType string, steps []string, updatedBy uuid.UUID) error
+	DeleteApprovalChain(org_id uuid.UUID, requestType string) error
+	StartApprovals(request_id uuid.UUID, steps []string) error
+	GetRequestApprovals(request_ids []uuid.UUID) (map[uuid.UUID][]RequestApproval, error)
+	DecideApprovalSteps(request_id uuid.UUID, steps []int, decision string, decidedBy uuid.UUID) error
+}
+
+type PostgresApprovalStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresApprovalStore(DB *sql.DB, Logger *slog.Logger) *PostgresApprovalStore {
+	return &PostgresApprovalStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// GetApprovalChains lists the chains the organization configured
+func (s *PostgresApprovalStore) GetApprovalChains(org_id uuid.UUID) ([]ApprovalChain, error) {
+	query := `SELECT request_type, steps FROM request_approval_chains WHERE organization_id = $1 ORDER BY request_type`
+	rows, err := s.DB.Query(query, org_id)
+	if err != nil {
+		s.Logger.Error("failed to get approval chains", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	chains := []ApprovalChain{}
+	for rows.Next() {
+		var chain ApprovalChain
+		var steps pq.StringArray
+		if err := rows.Scan(&chain.RequestType, &steps); err != nil {
+			s.Logger.Error("failed to scan approval chain", "error", err)
+			return nil, err
+		}
+		chain.Steps = steps
+		chains = append(chains, chain)
+	}
+	return chains, rows.Err()
+}
+
+// GetApprovalChain returns the chain of the request type, or the default one when the organization
+// has not configured it
+func (s *PostgresApprovalStore) GetApprovalChain(org_id uuid.UUID, requestType string) ([]string, error) {
+	query := `SELECT steps FROM request_approval_chains WHERE organization_id = $1 AND request_type = $2`
+	var steps pq.StringArray
+	err := s.DB.QueryRow(query, org_id, requestType).Scan(&steps)
+	if errors.Is(err, sql.ErrNoRows) {
+		return DefaultApprovalChain(requestType), nil
+	}
+	if err != nil {
+		s.Logger.Error("failed to get approval chain", "error", err, "org_id", org_id, "request_type", requestType)
+		return nil, err
+	}
+	return steps, nil
+}
+
+// SetApprovalChain replaces the chain of the request type. Requests already submitted keep the
+// chain they were submitted with.
+func (s *PostgresApprovalStore) SetApprovalChain(org_id uuid.UUID, requestType string, steps []string, updatedBy uuid.UUID) error {
+	query := `
+		INSERT INTO request_approval_chains (organization_id, request_type, steps, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (organization_id, request_type)
+		DO UPDATE SET steps = EXCLUDED.steps, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+	`
+	if _, err := s.DB.Exec(query, org_id, requestType, pq.Array(steps), updatedBy); err != nil {
+		s.Logger.Error("failed to set approval chain", "error", err, "org_id", org_id, "request_type", requestType)
+		return err
+	}
+
+	s.Logger.Info("approval chain set", "org_id", org_id, "request_type", requestType, "steps", steps)
+	return nil
+}
+
+// DeleteApprovalChain restores the default chain of the request type, returning sql.ErrNoRows if
+// the organization had not configured it
+func (s *PostgresApprovalStore) DeleteApprovalChain(org_id uuid.UUID, requestType string) error {
+	query := `DELETE FROM request_approval_chains WHERE organization_id = $1 AND request_type = $2`
+	result, err := s.DB.Exec(query, org_id, requestType)
+	if err != nil {
+		s.Logger.Error("failed to delete approval chain", "error", err, "org_id", org_id, "request_type", requestType)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// StartApprovals adds the pending steps of the chain to the request. Steps the request already has
+// are kept, so starting the chain twice is harmless.
+func (s *PostgresApprovalStore) StartApprovals(request_id uuid.UUID, steps []string) error {
+	query := `
+		INSERT INTO request_approvals (request_id, step, approver_role)
+		SELECT $1, step, role FROM UNNEST($2::VARCHAR(10)[]) WITH ORDINALITY AS chain(role, step)
+		ON CONFLICT (request_id, step) DO NOTHING
+	`
+	if _, err := s.DB.Exec(query, request_id, pq.Array(steps)); err != nil {
+		s.Logger.Error("failed to start approval chain", "error", err, "request_id", request_id)
+		return err
+	}
+	return nil
+}
+
+// GetRequestApprovals returns the steps of the given requests in order, keyed by request. Requests
+// without a chain are left out.
+func (s *PostgresApprovalStore) GetRequestApprovals(request_ids []uuid.UUID) (map[uuid.UUID][]RequestApproval, error) {
+	query := `
+		SELECT request_id, step, approver_role, decision, decided_by, decided_at
+		FROM request_approvals
+		WHERE request_id = ANY($1::uuid[])
+		ORDER BY request_id, step
+	`
+	ids := make([]string, len(request_ids))
+	for i, id := range request_ids {
+		ids[i] = id.String()
+	}
+	rows, err := s.DB.Query(query, pq.Array(ids))
+	if err != nil {
+		s.Logger.Error("failed to get request approvals", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	approvals := map[uuid.UUID][]RequestApproval{}
+	for rows.Next() {
+		var requestID uuid.UUID
+		var approval RequestApproval
+		var decidedBy uuid.NullUUID
+		var decidedAt sql.NullTime
+		if err := rows.Scan(&requestID, &approval.Step, &approval.ApproverRole, &approval.Decision, &decidedBy, &decidedAt); err != nil {
+			s.Logger.Error("failed to scan request approval", "error", err)
+			return nil, err
+		}
+		if decidedBy.Valid {
+			approval.DecidedBy = &decidedBy.UUID
+		}
+		if decidedAt.Valid {
+			approval.DecidedAt = &decidedAt.Time
+		}
+		approvals[requestID] = append(approvals[requestID], approval)
+	}
+	return approvals, rows.Err()
+}
+
+// DecideApprovalSteps records the decision on the pending steps of the request. Nothing is recorded
+// and ErrApprovalStepDecided is returned if any of them was decided in the meantime.
+func (s *PostgresApprovalStore) DecideApprovalSteps(request_id uuid.UUID, steps []int, decision string, decidedBy uuid.UUID) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		s.Logger.Error("failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE request_approvals SET decision = $3, decided_by = $4, decided_at = NOW()
+		WHERE request_id = $1 AND step = ANY($2) AND decision IS NULL
+	`
+	stepNumbers := make([]int64, len(steps))
+	for i, step := range steps {
+		stepNumbers[i] = int64(step)
+	}
+	result, err := tx.Exec(query, request_id, pq.Array(stepNumbers), decision, decidedBy)
+	if err != nil {
+		s.Logger.Error("failed to decide approval steps", "error", err, "request_id", request_id)
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected != int64(len(steps)) {
+		return ErrApprovalStepDecided
+	}
+
+	return tx.Commit()
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"log/slog"
 	"time"
 
@@ -16,6 +17,13 @@ type Request struct {
 	SubmittedAt time.Time `json:"submitted_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 	Status      string    `json:"status"`
+	// StartDate and EndDate are the days a holiday request is for
+	StartDate *time.Time `json:"start_date,omitempty"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
+	// BlackoutID is the blackout the request was flagged for when submitted
+	BlackoutID *uuid.UUID `json:"blackout_id,omitempty"`
+	// Approvals are the steps of the approval chain of the request, only set when listing requests
+	Approvals []RequestApproval `json:"approvals,omitempty"`
 }
 
 type RequestWithEmployee struct {
@@ -30,6 +38,7 @@ type RequestStore interface {
 	GetRequestsByEmployee(employeeID uuid.UUID) ([]*Request, error)
 	GetRequestsByOrganization(orgID uuid.UUID) ([]*RequestWithEmployee, error)
 	UpdateRequestStatus(id uuid.UUID, status string) error
+	AcceptRequest(id uuid.UUID) (*EmployeeUnavailability, error)
 }
 
 type PostgresRequestStore struct {
@@ -54,16 +63,18 @@ func (s *PostgresRequestStore) CreateRequest(req *Request) error {
 		req.Status = "in queue"
 	}
 
-	query := `INSERT INTO requests (request_id, employee_id, type, message, submitted_at, updated_at, status) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	query := `INSERT INTO requests (request_id, employee_id, type, message, submitted_at, updated_at, status, start_date, end_date, blackout_id) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
 
-	_, err := s.db.Exec(query, req.ID, req.EmployeeID, req.Type, req.Message, req.SubmittedAt, req.UpdatedAt, req.Status)
+	_, err := s.db.Exec(query, req.ID, req.EmployeeID, req.Type, req.Message, req.SubmittedAt, req.UpdatedAt, req.Status,
+		req.StartDate, req.EndDate, req.BlackoutID)
 	return err
 }
 
 func (s *PostgresRequestStore) GetRequestByID(id uuid.UUID) (*Request, error) {
 	var req Request
-	query := `SELECT request_id, employee_id, type, message, submitted_at, updated_at, status 
+	var blackoutID uuid.NullUUID
+	query := `SELECT request_id, employee_id, type, message, submitted_at, updated_at, status, start_date, end_date, blackout_id 
 		FROM requests WHERE request_id=$1`
 
 	err := s.db.QueryRow(query, id).Scan(
@@ -74,15 +85,21 @@ func (s *PostgresRequestStore) GetRequestByID(id uuid.UUID) (*Request, error) {
 		&req.SubmittedAt,
 		&req.UpdatedAt,
 		&req.Status,
+		&req.StartDate,
+		&req.EndDate,
+		&blackoutID,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if blackoutID.Valid {
+		req.BlackoutID = &blackoutID.UUID
+	}
 	return &req, nil
 }
 
 func (s *PostgresRequestStore) GetRequestsByEmployee(employeeID uuid.UUID) ([]*Request, error) {
-	query := `SELECT request_id, employee_id, type, message, submitted_at, updated_at, status 
+	query := `SELECT request_id, employee_id, type, message, submitted_at, updated_at, status, start_date, end_date, blackout_id 
 		FROM requests WHERE employee_id=$1 ORDER BY submitted_at DESC`
 
 	rows, err := s.db.Query(query, employeeID)
@@ -94,6 +111,7 @@ func (s *PostgresRequestStore) GetRequestsByEmployee(employeeID uuid.UUID) ([]*R
 	var requests []*Request
 	for rows.Next() {
 		var req Request
+		var blackoutID uuid.NullUUID
 		err := rows.Scan(
 			&req.ID,
 			&req.EmployeeID,
@@ -102,10 +120,16 @@ func (s *PostgresRequestStore) GetRequestsByEmployee(employeeID uuid.UUID) ([]*R
 			&req.SubmittedAt,
 			&req.UpdatedAt,
 			&req.Status,
+			&req.StartDate,
+			&req.EndDate,
+			&blackoutID,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if blackoutID.Valid {
+			req.BlackoutID = &blackoutID.UUID
+		}
 		requests = append(requests, &req)
 	}
 
@@ -114,7 +138,7 @@ func (s *PostgresRequestStore) GetRequestsByEmployee(employeeID uuid.UUID) ([]*R
 
 func (s *PostgresRequestStore) GetRequestsByOrganization(orgID uuid.UUID) ([]*RequestWithEmployee, error) {
 	query := `SELECT r.request_id, r.employee_id, r.type, r.message, r.submitted_at, r.updated_at, r.status,
-			u.full_name, u.email
+			r.start_date, r.end_date, r.blackout_id, u.full_name, u.email
 		FROM requests r
 		JOIN users u ON r.employee_id = u.id
 		WHERE u.organization_id=$1 
@@ -129,6 +153,7 @@ func (s *PostgresRequestStore) GetRequestsByOrganization(orgID uuid.UUID) ([]*Re
 	var requests []*RequestWithEmployee
 	for rows.Next() {
 		var req RequestWithEmployee
+		var blackoutID uuid.NullUUID
 		err := rows.Scan(
 			&req.ID,
 			&req.EmployeeID,
@@ -137,12 +162,18 @@ func (s *PostgresRequestStore) GetRequestsByOrganization(orgID uuid.UUID) ([]*Re
 			&req.SubmittedAt,
 			&req.UpdatedAt,
 			&req.Status,
+			&req.StartDate,
+			&req.EndDate,
+			&blackoutID,
 			&req.EmployeeName,
 			&req.EmployeeEmail,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if blackoutID.Valid {
+			req.BlackoutID = &blackoutID.UUID
+		}
 		requests = append(requests, &req)
 	}
 
@@ -164,3 +195,62 @@ func (s *PostgresRequestStore) UpdateRequestStatus(id uuid.UUID, status string)
 	}
 	return nil
 }
+
+// AcceptRequest accepts a request and, in the same transaction, makes the employee unavailable for
+// scheduling: holidays for their days, call-offs for the day of the employee's next shift from when
+// the call-off was submitted (or that day without one). Resignations make no one unavailable and
+// return nil.
+func (s *PostgresRequestStore) AcceptRequest(id uuid.UUID) (*EmployeeUnavailability, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.Logger.Error("failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var requestType string
+	var startDate, endDate *time.Time
+	var submittedAt time.Time
+	unavailability := &EmployeeUnavailability{RequestID: id}
+	query := `UPDATE requests SET status='accepted', updated_at=CURRENT_TIMESTAMP WHERE request_id=$1
+		RETURNING employee_id, type, start_date, end_date, submitted_at`
+	err = tx.QueryRow(query, id).Scan(&unavailability.EmployeeID, &requestType, &startDate, &endDate, &submittedAt)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.Logger.Error("failed to accept request", "error", err, "request_id", id)
+		}
+		return nil, err
+	}
+
+	var row *sql.Row
+	switch {
+	case requestType == "holiday" && startDate != nil && endDate != nil:
+		row = tx.QueryRow(`INSERT INTO employee_unavailability (employee_id, request_id, reason, start_date, end_date)
+			VALUES ($1, $2, 'holiday', $3, $4)
+			RETURNING id, reason, start_date, end_date, created_at`,
+			unavailability.EmployeeID, id, startDate, endDate)
+	case requestType == "calloff":
+		row = tx.QueryRow(`INSERT INTO employee_unavailability (employee_id, request_id, reason, start_date, end_date)
+			SELECT $1, $2, 'calloff', day, day
+			FROM (SELECT COALESCE(
+				(SELECT MIN(schedule_date) FROM schedules WHERE employee_id = $1 AND schedule_date >= $3::date),
+				$3::date) AS day) AS next_shift
+			RETURNING id, reason, start_date, end_date, created_at`,
+			unavailability.EmployeeID, id, submittedAt)
+	default:
+		unavailability = nil
+	}
+	if row != nil {
+		err := row.Scan(&unavailability.ID, &unavailability.Reason, &unavailability.StartDate, &unavailability.EndDate, &unavailability.CreatedAt)
+		if err != nil {
+			s.Logger.Error("failed to record unavailability", "error", err, "request_id", id)
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.Logger.Error("failed to commit transaction", "error", err)
+		return nil, err
+	}
+	return unavailability, nil
+}
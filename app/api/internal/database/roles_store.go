@@ -16,6 +16,8 @@ type OrganizationRole struct {
 	ItemsPerRolePerHour *int      `json:"items_per_employee_per_hour"`
 	NeedForDemand       bool      `json:"producing"`
 	Independent         *bool     `json:"is_independent"`
+	// SoloCoverage roles are worked alone, so employees in training are not scheduled in them
+	SoloCoverage bool `json:"solo_coverage"`
 }
 
 // RolesStore defines the interface for organization roles data operations
@@ -44,8 +46,8 @@ func NewPostgresRolesStore(db *sql.DB, logger *slog.Logger) *PostgresRolesStore
 // CreateRole creates a new role for an organization
 func (s *PostgresRolesStore) CreateRole(role *OrganizationRole) error {
 	query := `INSERT INTO organizations_roles 
-		(organization_id, role, min_needed_per_shift, items_per_role_per_hour, need_for_demand, independent) 
-		VALUES ($1, $2, $3, $4, $5, $6)`
+		(organization_id, role, min_needed_per_shift, items_per_role_per_hour, need_for_demand, independent, solo_coverage) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
 
 	_, err := s.db.Exec(query,
 		role.OrganizationID,
@@ -54,6 +56,7 @@ func (s *PostgresRolesStore) CreateRole(role *OrganizationRole) error {
 		role.ItemsPerRolePerHour,
 		role.NeedForDemand,
 		role.Independent,
+		role.SoloCoverage,
 	)
 	if err != nil {
 		s.Logger.Error("failed to create role", "error", err, "organization_id", role.OrganizationID, "role", role.Role)
@@ -66,7 +69,7 @@ func (s *PostgresRolesStore) CreateRole(role *OrganizationRole) error {
 
 // GetRolesByOrganizationID retrieves all roles for a specific organization
 func (s *PostgresRolesStore) GetRolesByOrganizationID(orgID uuid.UUID) ([]OrganizationRole, error) {
-	query := `SELECT organization_id, role, min_needed_per_shift, items_per_role_per_hour, need_for_demand, independent 
+	query := `SELECT organization_id, role, min_needed_per_shift, items_per_role_per_hour, need_for_demand, independent, solo_coverage 
 		FROM organizations_roles WHERE organization_id = $1 AND role != 'employee' AND role != 'admin' ORDER BY role`
 
 	rows, err := s.db.Query(query, orgID)
@@ -86,6 +89,7 @@ func (s *PostgresRolesStore) GetRolesByOrganizationID(orgID uuid.UUID) ([]Organi
 			&r.ItemsPerRolePerHour,
 			&r.NeedForDemand,
 			&r.Independent,
+			&r.SoloCoverage,
 		); err != nil {
 			s.Logger.Error("failed to scan role", "error", err)
 			return nil, err
@@ -104,7 +108,7 @@ func (s *PostgresRolesStore) GetRolesByOrganizationID(orgID uuid.UUID) ([]Organi
 func (s *PostgresRolesStore) GetRoleByName(orgID uuid.UUID, roleName string) (*OrganizationRole, error) {
 	var role OrganizationRole
 
-	query := `SELECT organization_id, role, min_needed_per_shift, items_per_role_per_hour, need_for_demand, independent 
+	query := `SELECT organization_id, role, min_needed_per_shift, items_per_role_per_hour, need_for_demand, independent, solo_coverage 
 		FROM organizations_roles WHERE organization_id = $1 AND role = $2`
 
 	err := s.db.QueryRow(query, orgID, roleName).Scan(
@@ -114,6 +118,7 @@ func (s *PostgresRolesStore) GetRoleByName(orgID uuid.UUID, roleName string) (*O
 		&role.ItemsPerRolePerHour,
 		&role.NeedForDemand,
 		&role.Independent,
+		&role.SoloCoverage,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -132,7 +137,8 @@ func (s *PostgresRolesStore) UpdateRole(role *OrganizationRole) error {
 		min_needed_per_shift = $3, 
 		items_per_role_per_hour = $4, 
 		need_for_demand = $5,
-		independent = $6 
+		independent = $6,
+		solo_coverage = $7 
 		WHERE organization_id = $1 AND role = $2`
 
 	result, err := s.db.Exec(query,
@@ -142,6 +148,7 @@ func (s *PostgresRolesStore) UpdateRole(role *OrganizationRole) error {
 		role.ItemsPerRolePerHour,
 		role.NeedForDemand,
 		role.Independent,
+		role.SoloCoverage,
 	)
 	if err != nil {
 		s.Logger.Error("failed to update role", "error", err, "organization_id", role.OrganizationID, "role", role.Role)
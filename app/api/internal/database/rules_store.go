@@ -12,22 +12,38 @@ import (
 
 // OrganizationRules represents the scheduling rules for an organization
 type OrganizationRules struct {
-	OrganizationID       uuid.UUID   `json:"organization_id"`
-	ShiftMaxHours        int         `json:"shift_max_hours"`
-	ShiftMinHours        int         `json:"shift_min_hours"`
-	MaxWeeklyHours       int         `json:"max_weekly_hours"`
-	MinWeeklyHours       int         `json:"min_weekly_hours"`
-	FixedShifts          bool        `json:"fixed_shifts"`
-	NumberOfShiftsPerDay *int        `json:"number_of_shifts_per_day"`
-	MeetAllDemand        bool        `json:"meet_all_demand"`
-	MinRestSlots         int         `json:"min_rest_slots"`
-	SlotLenHour          float64     `json:"slot_len_hour"`
-	MinShiftLengthSlots  int         `json:"min_shift_length_slots"`
-	ReceivingPhone       bool        `json:"receiving_phone"`
-	Delivery             bool        `json:"delivery"`
-	WaitingTime          int         `json:"waiting_time"`
-	AcceptingOrders      bool        `json:"accepting_orders"`
-	ShiftTimes           []ShiftTime `json:"shift_times,omitempty"`
+	OrganizationID                 uuid.UUID   `json:"organization_id"`
+	ShiftMaxHours                  int         `json:"shift_max_hours"`
+	ShiftMinHours                  int         `json:"shift_min_hours"`
+	MaxWeeklyHours                 int         `json:"max_weekly_hours"`
+	MinWeeklyHours                 int         `json:"min_weekly_hours"`
+	FixedShifts                    bool        `json:"fixed_shifts"`
+	NumberOfShiftsPerDay           *int        `json:"number_of_shifts_per_day"`
+	MeetAllDemand                  bool        `json:"meet_all_demand"`
+	MinRestSlots                   int         `json:"min_rest_slots"`
+	SlotLenHour                    float64     `json:"slot_len_hour"`
+	MinShiftLengthSlots            int         `json:"min_shift_length_slots"`
+	ReceivingPhone                 bool        `json:"receiving_phone"`
+	Delivery                       bool        `json:"delivery"`
+	WaitingTime                    int         `json:"waiting_time"`
+	AcceptingOrders                bool        `json:"accepting_orders"`
+	WeeklyLaborBudget              *float64    `json:"weekly_labor_budget"`
+	PrepBufferMinutes              int         `json:"prep_buffer_minutes"`
+	DeliveryMinutes                int         `json:"delivery_minutes"`
+	WaitTimeFactor                 float64     `json:"wait_time_factor"`
+	StandbyPayPercent              int         `json:"standby_pay_percent"`
+	CancellationNoticeHours        int         `json:"cancellation_notice_hours"`
+	PredictabilityNoticeDays       *int        `json:"predictability_notice_days"`
+	PredictabilityPayHours         float64     `json:"predictability_pay_hours"`
+	PredictabilityLostHoursPercent int         `json:"predictability_lost_hours_percent"`
+	AutoCloseOrders                bool        `json:"auto_close_orders"`        // Close order acceptance when the load of the hour is too high
+	OrdersPerStaffHour             int         `json:"orders_per_staff_hour"`    // Orders an employee on shift handles in an hour
+	AutoCloseLoadPercent           int         `json:"auto_close_load_percent"`  // Demand in percent of the staffed capacity that closes orders
+	AutoReopenLoadPercent          int         `json:"auto_reopen_load_percent"` // Demand in percent of the staffed capacity that reopens them
+	OrdersAutoClosedAt             *time.Time  `json:"orders_auto_closed_at"`    // Set while orders are closed automatically
+	DeliverySLAMinutes             int         `json:"delivery_sla_minutes"`     // Minutes from going out to delivered a delivery should take
+	SLABreachAlertPercent          int         `json:"sla_breach_alert_percent"` // Percent of the deliveries of a day breaching the SLA that alerts the managers
+	ShiftTimes                     []ShiftTime `json:"shift_times,omitempty"`
 }
 
 type ShiftTime struct {
@@ -125,8 +141,12 @@ func (s *PostgresRulesStore) CreateRules(rules *OrganizationRules) error {
 	query := `INSERT INTO organizations_rules 
 		(organization_id, shift_max_hours, shift_min_hours, max_weekly_hours, min_weekly_hours, 
 		 fixed_shifts, number_of_shifts_per_day, meet_all_demand, min_rest_slots, slot_len_hour, min_shift_length_slots,
-		 receiving_phone, delivery, waiting_time, accepting_orders) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+		 receiving_phone, delivery, waiting_time, accepting_orders, weekly_labor_budget,
+		 prep_buffer_minutes, delivery_minutes, wait_time_factor, standby_pay_percent, cancellation_notice_hours,
+		 predictability_notice_days, predictability_pay_hours, predictability_lost_hours_percent,
+		 auto_close_orders, orders_per_staff_hour, auto_close_load_percent, auto_reopen_load_percent,
+		 delivery_sla_minutes, sla_breach_alert_percent) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30)`
 
 	_, err := s.db.Exec(query,
 		rules.OrganizationID,
@@ -144,6 +164,21 @@ func (s *PostgresRulesStore) CreateRules(rules *OrganizationRules) error {
 		rules.Delivery,
 		rules.WaitingTime,
 		rules.AcceptingOrders,
+		rules.WeeklyLaborBudget,
+		rules.PrepBufferMinutes,
+		rules.DeliveryMinutes,
+		rules.WaitTimeFactor,
+		rules.StandbyPayPercent,
+		rules.CancellationNoticeHours,
+		rules.PredictabilityNoticeDays,
+		rules.PredictabilityPayHours,
+		rules.PredictabilityLostHoursPercent,
+		rules.AutoCloseOrders,
+		rules.OrdersPerStaffHour,
+		rules.AutoCloseLoadPercent,
+		rules.AutoReopenLoadPercent,
+		rules.DeliverySLAMinutes,
+		rules.SLABreachAlertPercent,
 	)
 	if err != nil {
 		s.Logger.Error("failed to create rules", "error", err, "organization_id", rules.OrganizationID)
@@ -168,7 +203,11 @@ func (s *PostgresRulesStore) GetRulesByOrganizationID(orgID uuid.UUID) (*Organiz
 
 	query := `SELECT organization_id, shift_max_hours, shift_min_hours, max_weekly_hours, min_weekly_hours,
 		fixed_shifts, number_of_shifts_per_day, meet_all_demand, min_rest_slots, slot_len_hour, min_shift_length_slots,
-		receiving_phone, delivery, waiting_time, accepting_orders 
+		receiving_phone, delivery, waiting_time, accepting_orders, weekly_labor_budget,
+		prep_buffer_minutes, delivery_minutes, wait_time_factor, standby_pay_percent, cancellation_notice_hours,
+		predictability_notice_days, predictability_pay_hours, predictability_lost_hours_percent,
+		auto_close_orders, orders_per_staff_hour, auto_close_load_percent, auto_reopen_load_percent, orders_auto_closed_at,
+		delivery_sla_minutes, sla_breach_alert_percent
 		FROM organizations_rules WHERE organization_id = $1`
 
 	err := s.db.QueryRow(query, orgID).Scan(
@@ -187,6 +226,22 @@ func (s *PostgresRulesStore) GetRulesByOrganizationID(orgID uuid.UUID) (*Organiz
 		&rules.Delivery,
 		&rules.WaitingTime,
 		&rules.AcceptingOrders,
+		&rules.WeeklyLaborBudget,
+		&rules.PrepBufferMinutes,
+		&rules.DeliveryMinutes,
+		&rules.WaitTimeFactor,
+		&rules.StandbyPayPercent,
+		&rules.CancellationNoticeHours,
+		&rules.PredictabilityNoticeDays,
+		&rules.PredictabilityPayHours,
+		&rules.PredictabilityLostHoursPercent,
+		&rules.AutoCloseOrders,
+		&rules.OrdersPerStaffHour,
+		&rules.AutoCloseLoadPercent,
+		&rules.AutoReopenLoadPercent,
+		&rules.OrdersAutoClosedAt,
+		&rules.DeliverySLAMinutes,
+		&rules.SLABreachAlertPercent,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -225,7 +280,23 @@ func (s *PostgresRulesStore) UpdateRules(rules *OrganizationRules) error {
 		receiving_phone = $12,
 		delivery = $13,
 		waiting_time = $14,
-		accepting_orders = $15 
+		accepting_orders = $15,
+		weekly_labor_budget = $16,
+		prep_buffer_minutes = $17,
+		delivery_minutes = $18,
+		wait_time_factor = $19,
+		standby_pay_percent = $20,
+		cancellation_notice_hours = $21,
+		predictability_notice_days = $22,
+		predictability_pay_hours = $23,
+		predictability_lost_hours_percent = $24,
+		auto_close_orders = $25,
+		orders_per_staff_hour = $26,
+		auto_close_load_percent = $27,
+		auto_reopen_load_percent = $28,
+		delivery_sla_minutes = $29,
+		sla_breach_alert_percent = $30,
+		orders_auto_closed_at = CASE WHEN accepting_orders = $15 THEN orders_auto_closed_at END
 		WHERE organization_id = $1`
 
 	result, err := s.db.Exec(query,
@@ -244,6 +315,21 @@ func (s *PostgresRulesStore) UpdateRules(rules *OrganizationRules) error {
 		rules.Delivery,
 		rules.WaitingTime,
 		rules.AcceptingOrders,
+		rules.WeeklyLaborBudget,
+		rules.PrepBufferMinutes,
+		rules.DeliveryMinutes,
+		rules.WaitTimeFactor,
+		rules.StandbyPayPercent,
+		rules.CancellationNoticeHours,
+		rules.PredictabilityNoticeDays,
+		rules.PredictabilityPayHours,
+		rules.PredictabilityLostHoursPercent,
+		rules.AutoCloseOrders,
+		rules.OrdersPerStaffHour,
+		rules.AutoCloseLoadPercent,
+		rules.AutoReopenLoadPercent,
+		rules.DeliverySLAMinutes,
+		rules.SLABreachAlertPercent,
 	)
 	if err != nil {
 		s.Logger.Error("failed to update rules", "error", err, "organization_id", rules.OrganizationID)
@@ -264,8 +350,12 @@ func (s *PostgresRulesStore) UpsertRules(rules *OrganizationRules) error {
 	query := `INSERT INTO organizations_rules 
 		(organization_id, shift_max_hours, shift_min_hours, max_weekly_hours, min_weekly_hours,
 		 fixed_shifts, number_of_shifts_per_day, meet_all_demand, min_rest_slots, slot_len_hour, min_shift_length_slots,
-		 receiving_phone, delivery, waiting_time, accepting_orders) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		 receiving_phone, delivery, waiting_time, accepting_orders, weekly_labor_budget,
+		 prep_buffer_minutes, delivery_minutes, wait_time_factor, standby_pay_percent, cancellation_notice_hours,
+		 predictability_notice_days, predictability_pay_hours, predictability_lost_hours_percent,
+		 auto_close_orders, orders_per_staff_hour, auto_close_load_percent, auto_reopen_load_percent,
+		 delivery_sla_minutes, sla_breach_alert_percent) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30)
 		ON CONFLICT (organization_id) DO UPDATE SET 
 		shift_max_hours = EXCLUDED.shift_max_hours,
 		shift_min_hours = EXCLUDED.shift_min_hours,
@@ -280,7 +370,23 @@ func (s *PostgresRulesStore) UpsertRules(rules *OrganizationRules) error {
 		receiving_phone = EXCLUDED.receiving_phone,
 		delivery = EXCLUDED.delivery,
 		waiting_time = EXCLUDED.waiting_time,
-		accepting_orders = EXCLUDED.accepting_orders`
+		accepting_orders = EXCLUDED.accepting_orders,
+		weekly_labor_budget = EXCLUDED.weekly_labor_budget,
+		prep_buffer_minutes = EXCLUDED.prep_buffer_minutes,
+		delivery_minutes = EXCLUDED.delivery_minutes,
+		wait_time_factor = EXCLUDED.wait_time_factor,
+		standby_pay_percent = EXCLUDED.standby_pay_percent,
+		cancellation_notice_hours = EXCLUDED.cancellation_notice_hours,
+		predictability_notice_days = EXCLUDED.predictability_notice_days,
+		predictability_pay_hours = EXCLUDED.predictability_pay_hours,
+		predictability_lost_hours_percent = EXCLUDED.predictability_lost_hours_percent,
+		auto_close_orders = EXCLUDED.auto_close_orders,
+		orders_per_staff_hour = EXCLUDED.orders_per_staff_hour,
+		auto_close_load_percent = EXCLUDED.auto_close_load_percent,
+		auto_reopen_load_percent = EXCLUDED.auto_reopen_load_percent,
+		delivery_sla_minutes = EXCLUDED.delivery_sla_minutes,
+		sla_breach_alert_percent = EXCLUDED.sla_breach_alert_percent,
+		orders_auto_closed_at = CASE WHEN organizations_rules.accepting_orders = EXCLUDED.accepting_orders THEN organizations_rules.orders_auto_closed_at END`
 
 	_, err := s.db.Exec(query,
 		rules.OrganizationID,
@@ -298,6 +404,21 @@ func (s *PostgresRulesStore) UpsertRules(rules *OrganizationRules) error {
 		rules.Delivery,
 		rules.WaitingTime,
 		rules.AcceptingOrders,
+		rules.WeeklyLaborBudget,
+		rules.PrepBufferMinutes,
+		rules.DeliveryMinutes,
+		rules.WaitTimeFactor,
+		rules.StandbyPayPercent,
+		rules.CancellationNoticeHours,
+		rules.PredictabilityNoticeDays,
+		rules.PredictabilityPayHours,
+		rules.PredictabilityLostHoursPercent,
+		rules.AutoCloseOrders,
+		rules.OrdersPerStaffHour,
+		rules.AutoCloseLoadPercent,
+		rules.AutoReopenLoadPercent,
+		rules.DeliverySLAMinutes,
+		rules.SLABreachAlertPercent,
 	)
 	if err != nil {
 		s.Logger.Error("failed to upsert rules", "error", err, "organization_id", rules.OrganizationID)
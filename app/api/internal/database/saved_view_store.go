@@ -0,0 +1,187 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ErrSavedViewExists is returned when the user already has a view of the resource with the same name
+var ErrSavedViewExists = errors.New("saved view already exists")
+
+// SavedView is a named set of filters of a list endpoint, e.g. the pending holiday requests. Filters
+// are the query parameters applied to the endpoint of the resource. Views are private to their creator
+// unless shared with the organization.
+type SavedView struct {
+	ID        uuid.UUID         `json:"id"`
+	CreatedBy uuid.UUID         `json:"created_by"`
+	Name      string            `json:"name"`
+	Resource  string            `json:"resource"`
+	Filters   map[string]string `json:"filters"`
+	Shared    bool              `json:"shared"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+type SavedViewStore interface {
+	ListViews(org_id, user_id uuid.UUID, resource string) ([]SavedView, error)
+	GetView(org_id, view_id uuid.UUID) (*SavedView, error)
+	CreateView(org_id uuid.UUID, view *SavedView) error
+	UpdateView(org_id uuid.UUID, view *SavedView) error
+	DeleteView(org_id, view_id uuid.UUID) error
+}
+
+type PostgresSavedViewStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresSavedViewStore(DB *sql.DB, Logger *slog.Logger) *PostgresSavedViewStore {
+	return &PostgresSavedViewStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// isUniqueViolation reports whether the error comes from a unique constraint
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func scanSavedView(scanner interface{ Scan(...any) error }) (*SavedView, error) {
+	var view SavedView
+	var filters []byte
+	if err := scanner.Scan(&view.ID, &view.CreatedBy, &view.Name, &view.Resource, &filters, &view.Shared,
+		&view.CreatedAt, &view.UpdatedAt); err != nil {
+		return nil, err
+	}
+	view.Filters = map[string]string{}
+	if err := json.Unmarshal(filters, &view.Filters); err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// ListViews lists the shared views of the organization and the private views of the user by name, of
+// one resource or of every resource when resource is empty
+func (s *PostgresSavedViewStore) ListViews(org_id, user_id uuid.UUID, resource string) ([]SavedView, error) {
+	query := `
+		SELECT id, created_by, name, resource, filters, shared, created_at, updated_at
+		FROM saved_views
+		WHERE organization_id = $1 AND (shared OR created_by = $2) AND ($3 = '' OR resource = $3)
+		ORDER BY resource, name, created_at
+	`
+	rows, err := s.DB.Query(query, org_id, user_id, resource)
+	if err != nil {
+		s.Logger.Error("failed to get saved views", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	views := []SavedView{}
+	for rows.Next() {
+		view, err := scanSavedView(rows)
+		if err != nil {
+			s.Logger.Error("failed to scan saved view", "error", err)
+			return nil, err
+		}
+		views = append(views, *view)
+	}
+	return views, rows.Err()
+}
+
+// GetView returns a view of the organization, or sql.ErrNoRows if there is none
+func (s *PostgresSavedViewStore) GetView(org_id, view_id uuid.UUID) (*SavedView, error) {
+	query := `
+		SELECT id, created_by, name, resource, filters, shared, created_at, updated_at
+		FROM saved_views
+		WHERE organization_id = $1 AND id = $2
+	`
+	view, err := scanSavedView(s.DB.QueryRow(query, org_id, view_id))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.Logger.Error("failed to get saved view", "error", err, "org_id", org_id, "view_id", view_id)
+		}
+		return nil, err
+	}
+	return view, nil
+}
+
+// CreateView saves a view, returning ErrSavedViewExists if its creator already has a view of the
+// resource with the same name
+func (s *PostgresSavedViewStore) CreateView(org_id uuid.UUID, view *SavedView) error {
+	filters, err := json.Marshal(view.Filters)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO saved_views (organization_id, created_by, name, resource, filters, shared)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+	err = s.DB.QueryRow(query, org_id, view.CreatedBy, view.Name, view.Resource, filters, view.Shared).
+		Scan(&view.ID, &view.CreatedAt, &view.UpdatedAt)
+	if isUniqueViolation(err) {
+		return ErrSavedViewExists
+	}
+	if err != nil {
+		s.Logger.Error("failed to create saved view", "error", err, "org_id", org_id)
+		return err
+	}
+
+	s.Logger.Info("saved view created", "org_id", org_id, "view_id", view.ID, "resource", view.Resource)
+	return nil
+}
+
+// UpdateView replaces the name, filters and sharing of a view, returning sql.ErrNoRows if the
+// organization has no such view and ErrSavedViewExists if the new name is taken
+func (s *PostgresSavedViewStore) UpdateView(org_id uuid.UUID, view *SavedView) error {
+	filters, err := json.Marshal(view.Filters)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE saved_views SET name = $3, filters = $4, shared = $5, updated_at = NOW()
+		WHERE organization_id = $1 AND id = $2
+		RETURNING updated_at
+	`
+	err = s.DB.QueryRow(query, org_id, view.ID, view.Name, filters, view.Shared).Scan(&view.UpdatedAt)
+	if isUniqueViolation(err) {
+		return ErrSavedViewExists
+	}
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.Logger.Error("failed to update saved view", "error", err, "org_id", org_id, "view_id", view.ID)
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteView removes a view, returning sql.ErrNoRows if the organization has no such view
+func (s *PostgresSavedViewStore) DeleteView(org_id, view_id uuid.UUID) error {
+	result, err := s.DB.Exec(`DELETE FROM saved_views WHERE organization_id = $1 AND id = $2`, org_id, view_id)
+	if err != nil {
+		s.Logger.Error("failed to delete saved view", "error", err, "org_id", org_id, "view_id", view_id)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	s.Logger.Info("saved view deleted", "org_id", org_id, "view_id", view_id)
+	return nil
+}
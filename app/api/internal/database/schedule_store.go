@@ -2,7 +2,10 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"log/slog"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,17 +23,222 @@ type ScheduleEntry struct {
 
 // Schedule represents grouped schedule with employees in same time slot
 type Schedule struct {
+	Date          time.Time `json:"schedule_date"`
+	Day           string    `json:"day"`
+	StartTime     string    `json:"start_time"`
+	EndTime       string    `json:"end_time"`
+	Employees     []string  `json:"employees"`                // employee IDs
+	ShiftIDs      []string  `json:"shift_ids,omitempty"`      // IDs of the employee shifts, in the order of Employees
+	Kind          string    `json:"kind,omitempty"`           // interview or trial for applicant sessions, standby for on-call shifts
+	Applicant     string    `json:"applicant,omitempty"`      // applicant of an interview or trial
+	NonProductive bool      `json:"non_productive,omitempty"` // the time does not count as productive hours
+	InTraining    []string  `json:"in_training,omitempty"`    // the Employees in training that day, scheduled on top of the staff needed
+}
+
+// Types of shift. Standby shifts are on-call: the employee is paid the standby rate unless a manager
+// activates the shift, which turns it into a working shift.
+const (
+	ShiftWorking = "working"
+	ShiftStandby = "standby"
+)
+
+var (
+	ErrShiftOverlaps   = errors.New("the employee already has a shift at that time")
+	ErrShiftNotStandby = errors.New("shift is not a standby shift")
+	ErrShiftEnded      = errors.New("shift has already ended")
+	ErrShiftStarted    = errors.New("shift has already started")
+	// ErrCancellationNotice is returned when a shift starts within the cancellation notice period and
+	// late cancellations were not allowed
+	ErrCancellationNotice = errors.New("shift starts within the cancellation notice period")
+)
+
+// Shift is a single shift of an employee
+type Shift struct {
+	ID            uuid.UUID  `json:"id"`
+	EmployeeID    uuid.UUID  `json:"employee_id"`
+	EmployeeName  string     `json:"employee_name"`
+	EmployeeEmail string     `json:"employee_email"`
+	Date          time.Time  `json:"schedule_date"`
+	Day           string     `json:"day"`
+	StartTime     string     `json:"start_time"`
+	EndTime       string     `json:"end_time"`
+	ShiftType     string     `json:"shift_type"`
+	ActivatedAt   *time.Time `json:"activated_at"`
+}
+
+// ShiftKey identifies a single shift of an employee
+type ShiftKey struct {
 	Date      time.Time `json:"schedule_date"`
-	Day       string    `json:"day"`
 	StartTime string    `json:"start_time"`
 	EndTime   string    `json:"end_time"`
-	Employees []string  `json:"employees"` // employee IDs
+}
+
+// ShiftAcknowledgment is the acknowledgment state of a single employee shift
+type ShiftAcknowledgment struct {
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	EmployeeID     uuid.UUID  `json:"employee_id"`
+	EmployeeName   string     `json:"employee_name"`
+	EmployeeEmail  string     `json:"employee_email"`
+	Date           time.Time  `json:"schedule_date"`
+	Day            string     `json:"day"`
+	StartTime      string     `json:"start_time"`
+	EndTime        string     `json:"end_time"`
+	PublishedAt    time.Time  `json:"published_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	LastRemindedAt *time.Time `json:"last_reminded_at"`
+}
+
+// WeeklyShift is a published shift of the week-at-a-glance email, with the length and the standby pay
+// of the organization needed to estimate its pay
+type WeeklyShift struct {
+	Shift
+	OrganizationID    uuid.UUID `json:"organization_id"`
+	Hours             float64   `json:"hours"`
+	StandbyPayPercent int       `json:"standby_pay_percent"`
+}
+
+// EmployeeHours is the hours an employee is scheduled over a period, standby hours are paid
+// StandbyPayPercent of the salary
+type EmployeeHours struct {
+	EmployeeID        uuid.UUID `json:"employee_id"`
+	WorkingHours      float64   `json:"working_hours"`
+	StandbyHours      float64   `json:"standby_hours"`
+	StandbyPayPercent int       `json:"standby_pay_percent"`
+}
+
+// Types of schedule event. Each change to a shift appends an event with the shift as it is after the
+// change, so the history of a shift can be replayed from its events.
+const (
+	ScheduleEventGenerated = "generated" // stored from a generated schedule
+	ScheduleEventCreated   = "created"   // scheduled by hand, e.g. a standby shift
+	ScheduleEventEdited    = "edited"    // times or type changed, e.g. a standby shift activated
+	ScheduleEventSwapped   = "swapped"   // handed over to another employee
+	ScheduleEventCancelled = "cancelled" // removed from the schedule
+)
+
+// ScheduleEvent is a change to a shift, with the shift as it is after the change
+type ScheduleEvent struct {
+	ID           uuid.UUID  `json:"id"`
+	ShiftID      uuid.UUID  `json:"shift_id"`
+	EventType    string     `json:"event_type"`
+	EmployeeID   uuid.UUID  `json:"employee_id"`
+	EmployeeName string     `json:"employee_name"`
+	Date         time.Time  `json:"schedule_date"`
+	StartTime    string     `json:"start_time"`
+	EndTime      string     `json:"end_time"`
+	ShiftType    string     `json:"shift_type"`
+	ActorID      *uuid.UUID `json:"actor_id"`
+	ActorName    *string    `json:"actor_name"`
+	OccurredAt   time.Time  `json:"occurred_at"`
+}
+
+// ScheduleChange is an event changing a shift after it was scheduled, with the shift before the change
+// (nil for created shifts) and after it (as it was, for cancelled shifts). NoticeHours is the time from
+// the change to the earliest start of the shift before and after it.
+type ScheduleChange struct {
+	ShiftID           uuid.UUID  `json:"shift_id"`
+	EventType         string     `json:"event_type"`
+	EmployeeID        uuid.UUID  `json:"employee_id"`
+	EmployeeName      string     `json:"employee_name"`
+	Date              time.Time  `json:"schedule_date"`
+	StartTime         string     `json:"start_time"`
+	EndTime           string     `json:"end_time"`
+	ShiftType         string     `json:"shift_type"`
+	Hours             float64    `json:"hours"`
+	PreviousDate      *time.Time `json:"previous_schedule_date,omitempty"`
+	PreviousStartTime *string    `json:"previous_start_time,omitempty"`
+	PreviousEndTime   *string    `json:"previous_end_time,omitempty"`
+	PreviousHours     *float64   `json:"previous_hours,omitempty"`
+	NoticeHours       float64    `json:"notice_hours"`
+	OccurredAt        time.Time  `json:"occurred_at"`
+}
+
+// ShiftCancellation is a published shift cancelled by a manager, with the shift as it was. A late
+// cancellation had less notice than the organization required when it was made.
+type ShiftCancellation struct {
+	ID                  uuid.UUID  `json:"id"`
+	ShiftID             uuid.UUID  `json:"shift_id"`
+	EmployeeID          uuid.UUID  `json:"employee_id"`
+	EmployeeName        string     `json:"employee_name"`
+	EmployeeEmail       string     `json:"-"`
+	Date                time.Time  `json:"schedule_date"`
+	StartTime           string     `json:"start_time"`
+	EndTime             string     `json:"end_time"`
+	ShiftType           string     `json:"shift_type"`
+	Reason              string     `json:"reason"`
+	CancelledBy         *uuid.UUID `json:"cancelled_by"`
+	CancelledByName     *string    `json:"cancelled_by_name"`
+	NoticeHours         float64    `json:"notice_hours"`
+	RequiredNoticeHours int        `json:"required_notice_hours"`
+	Late                bool       `json:"late"`
+	OffersCreated       int        `json:"offers_created"`
+	CancelledAt         time.Time  `json:"cancelled_at"`
+}
+
+// ShiftCancellationRequest is how a shift is cancelled at a given time
+type ShiftCancellationRequest struct {
+	Reason              string
+	At                  time.Time
+	RequiredNoticeHours int
+	AllowLate           bool // cancel within the notice period as a late cancellation instead of ErrCancellationNotice
+	OfferShift          bool // offer the shift as overtime to the other employees free at that time
+}
+
+// CancellationFilter narrows a list of shift cancellations by the date of the shift, To excluded.
+// Unset fields match every cancellation.
+type CancellationFilter struct {
+	From *time.Time
+	To   *time.Time
+	Late *bool
+}
+
+// ShiftFilter narrows a list of shifts. Unset fields match every shift.
+type ShiftFilter struct {
+	EmployeeIDs  []uuid.UUID
+	Roles        []string // organization roles of the employee, any of them
+	Acknowledged *bool
+}
+
+// ScheduleClearSummary describes the shifts of an organization in a date range and what clearing them removes
+type ScheduleClearSummary struct {
+	From               time.Time                 `json:"from"`
+	To                 time.Time                 `json:"to"`
+	Shifts             int                       `json:"shifts"`
+	AcknowledgedShifts int                       `json:"acknowledged_shifts"`
+	QueuedOffers       int                       `json:"queued_offers"`
+	Employees          []ScheduleClearedEmployee `json:"employees"`
+}
+
+// ScheduleClearedEmployee is an employee with shifts in a cleared date range
+type ScheduleClearedEmployee struct {
+	EmployeeID   uuid.UUID `json:"employee_id"`
+	EmployeeName string    `json:"employee_name"`
+	Email        string    `json:"email"`
+	Shifts       int       `json:"shifts"`
 }
 
 type ScheduleStore interface {
-	StoreScheduleForUser(org_id uuid.UUID, user_id uuid.UUID, Schedule *Schedule) error
+	StoreScheduleForUser(org_id uuid.UUID, user_id uuid.UUID, Schedule *Schedule, actor_id uuid.UUID) error
 	GetFullScheduleForSevenDays(org_id uuid.UUID) ([]Schedule, error)
 	GetScheduleForEmployeeForSevenDays(org_id uuid.UUID, user_id uuid.UUID) ([]Schedule, error)
+	AcknowledgeShifts(org_id uuid.UUID, user_id uuid.UUID, shifts []ShiftKey) (int64, error)
+	GetShiftAcknowledgments(org_id uuid.UUID, filter ShiftFilter) ([]ShiftAcknowledgment, error)
+	GetShiftsPendingReminder(cutoff time.Time) ([]ShiftAcknowledgment, error)
+	MarkShiftRemindersSent(user_id uuid.UUID) error
+	GetShiftsForWeeklyEmail(week_start time.Time) ([]WeeklyShift, error)
+	MarkWeeklyEmailSent(user_id uuid.UUID, week_start time.Time) error
+	GetScheduleClearSummary(org_id uuid.UUID, from time.Time, to time.Time) (*ScheduleClearSummary, error)
+	ClearSchedule(org_id uuid.UUID, from time.Time, to time.Time, actor_id uuid.UUID) (*ScheduleClearSummary, error)
+	StoreStandbyShift(org_id uuid.UUID, user_id uuid.UUID, shift ShiftKey, actor_id uuid.UUID) (*Shift, error)
+	ActivateShift(org_id uuid.UUID, shift_id uuid.UUID, at time.Time, actor_id uuid.UUID) (*Shift, error)
+	GetShiftEvents(org_id uuid.UUID, shift_id uuid.UUID) ([]ScheduleEvent, error)
+	CancelShift(org_id uuid.UUID, shift_id uuid.UUID, request ShiftCancellationRequest, actor_id uuid.UUID) (*ShiftCancellation, error)
+	GetShiftCancellations(org_id uuid.UUID, filter CancellationFilter) ([]ShiftCancellation, error)
+	GetShiftsFrom(org_id uuid.UUID, from time.Time) ([]Shift, error)
+	GetScheduledHours(org_id uuid.UUID, from time.Time, to time.Time) ([]EmployeeHours, error)
+	GetScheduleChanges(org_id uuid.UUID, from time.Time, to time.Time) ([]ScheduleChange, error)
+	GetOrganizationsForComplianceReport(week_start time.Time) ([]uuid.UUID, error)
+	MarkComplianceReportSent(org_id uuid.UUID, week_start time.Time) error
 }
 
 type PostgresScheduleStore struct {
@@ -48,8 +256,8 @@ func NewPostgresScheduleStore(userStore UserStore, DB *sql.DB, Logger *slog.Logg
 }
 
 // StoreScheduleForUser stores schedule entries for a user
-// Each time slot in the Schedule results in a separate row in the database
-func (s *PostgresScheduleStore) StoreScheduleForUser(org_id uuid.UUID, user_id uuid.UUID, schedule *Schedule) error {
+// Each time slot in the Schedule results in a separate row in the database, generated by actor_id
+func (s *PostgresScheduleStore) StoreScheduleForUser(org_id uuid.UUID, user_id uuid.UUID, schedule *Schedule, actor_id uuid.UUID) error {
 	// Verify user belongs to the organization
 	var exists bool
 	checkQuery := `SELECT EXISTS(SELECT 1 FROM users WHERE id = $1 AND organization_id = $2)`
@@ -63,11 +271,16 @@ func (s *PostgresScheduleStore) StoreScheduleForUser(org_id uuid.UUID, user_id u
 		return sql.ErrNoRows
 	}
 
-	// Insert schedule entry for this user
+	// Insert schedule entry for this user, and its event when the shift is new
 	query := `
-		INSERT INTO schedules (schedule_date, day, start_hour, end_hour, employee_id)
-		VALUES ($1, $2, $3, $4, $5)
-		ON CONFLICT (schedule_date, start_hour, end_hour, employee_id) DO NOTHING
+		WITH shift AS (
+			INSERT INTO schedules (schedule_date, day, start_hour, end_hour, employee_id)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (schedule_date, start_hour, end_hour, employee_id) DO NOTHING
+			RETURNING id, employee_id, schedule_date, start_hour, end_hour, shift_type
+		)
+		INSERT INTO schedule_events (organization_id, shift_id, event_type, employee_id, schedule_date, start_hour, end_hour, shift_type, actor_id)
+		SELECT $6, id, 'generated', employee_id, schedule_date, start_hour, end_hour, shift_type, $7 FROM shift
 	`
 
 	_, err = s.DB.Exec(query,
@@ -76,6 +289,8 @@ func (s *PostgresScheduleStore) StoreScheduleForUser(org_id uuid.UUID, user_id u
 		schedule.StartTime,
 		schedule.EndTime,
 		user_id,
+		org_id,
+		actor_id,
 	)
 	if err != nil {
 		s.Logger.Error("failed to store schedule", "error", err, "user_id", user_id)
@@ -95,13 +310,15 @@ func (s *PostgresScheduleStore) GetFullScheduleForSevenDays(org_id uuid.UUID) ([
 			s.day,
 			s.start_hour,
 			s.end_hour,
-			ARRAY_AGG(s.employee_id::TEXT) as employees
+			s.shift_type,
+			ARRAY_AGG(s.employee_id::TEXT) as employees,
+			ARRAY_AGG(s.id::TEXT) as shift_ids
 		FROM schedules s
 		INNER JOIN users u ON s.employee_id = u.id
 		WHERE u.organization_id = $1
 			AND s.schedule_date >= CURRENT_DATE
 			AND s.schedule_date < CURRENT_DATE + INTERVAL '7 days'
-		GROUP BY s.schedule_date, s.day, s.start_hour, s.end_hour
+		GROUP BY s.schedule_date, s.day, s.start_hour, s.end_hour, s.shift_type
 		ORDER BY s.schedule_date, s.start_hour
 	`
 
@@ -115,23 +332,25 @@ func (s *PostgresScheduleStore) GetFullScheduleForSevenDays(org_id uuid.UUID) ([
 	var schedules []Schedule
 	for rows.Next() {
 		var schedule Schedule
-		var employees pq.StringArray
+		var shiftType string
+		var employees, shiftIDs pq.StringArray
 
 		err := rows.Scan(
 			&schedule.Date,
 			&schedule.Day,
 			&schedule.StartTime,
 			&schedule.EndTime,
+			&shiftType,
 			&employees,
+			&shiftIDs,
 		)
 		if err != nil {
 			s.Logger.Error("failed to scan schedule row", "error", err)
 			return nil, err
 		}
 
-		schedule.Employees = []string(employees)
-		var names []string
-		for _, empID := range schedule.Employees {
+		var names, ids []string
+		for i, empID := range employees {
 			employeeID, _ := uuid.Parse(empID)
 			emp, err := s.UserStore.GetUserByID(employeeID)
 			if err != nil {
@@ -139,8 +358,11 @@ func (s *PostgresScheduleStore) GetFullScheduleForSevenDays(org_id uuid.UUID) ([
 				continue
 			}
 			names = append(names, emp.FullName)
+			ids = append(ids, shiftIDs[i])
 		}
 		schedule.Employees = names
+		schedule.ShiftIDs = ids
+		setShiftKind(&schedule, shiftType)
 		schedules = append(schedules, schedule)
 	}
 
@@ -173,7 +395,9 @@ func (s *PostgresScheduleStore) GetScheduleForEmployeeForSevenDays(org_id uuid.U
 			day,
 			start_hour,
 			end_hour,
-			employee_id
+			employee_id,
+			id,
+			shift_type
 		FROM schedules
 		WHERE employee_id = $1
 			AND schedule_date >= CURRENT_DATE
@@ -191,7 +415,8 @@ func (s *PostgresScheduleStore) GetScheduleForEmployeeForSevenDays(org_id uuid.U
 	var schedules []Schedule
 	for rows.Next() {
 		var schedule Schedule
-		var employeeID uuid.UUID
+		var employeeID, shiftID uuid.UUID
+		var shiftType string
 
 		err := rows.Scan(
 			&schedule.Date,
@@ -199,11 +424,16 @@ func (s *PostgresScheduleStore) GetScheduleForEmployeeForSevenDays(org_id uuid.U
 			&schedule.StartTime,
 			&schedule.EndTime,
 			&employeeID,
+			&shiftID,
+			&shiftType,
 		)
 		if err != nil {
 			s.Logger.Error("failed to scan schedule row", "error", err)
 			return nil, err
 		}
+		schedule.Employees = []string{employeeID.String()}
+		schedule.ShiftIDs = []string{shiftID.String()}
+		setShiftKind(&schedule, shiftType)
 		schedules = append(schedules, schedule)
 	}
 
@@ -214,3 +444,985 @@ func (s *PostgresScheduleStore) GetScheduleForEmployeeForSevenDays(org_id uuid.U
 	s.Logger.Info("retrieved employee schedule", "user_id", user_id, "count", len(schedules))
 	return schedules, nil
 }
+
+// AcknowledgeShifts marks the given upcoming shifts of an employee as acknowledged.
+// When no shifts are given, every upcoming unacknowledged shift of the employee is acknowledged.
+// It returns the number of shifts that were newly acknowledged.
+func (s *PostgresScheduleStore) AcknowledgeShifts(org_id uuid.UUID, user_id uuid.UUID, shifts []ShiftKey) (int64, error) {
+	var exists bool
+	checkQuery := `SELECT EXISTS(SELECT 1 FROM users WHERE id = $1 AND organization_id = $2)`
+	err := s.DB.QueryRow(checkQuery, user_id, org_id).Scan(&exists)
+	if err != nil {
+		s.Logger.Error("failed to verify user organization", "error", err, "user_id", user_id, "org_id", org_id)
+		return 0, err
+	}
+	if !exists {
+		s.Logger.Warn("user does not belong to organization", "user_id", user_id, "org_id", org_id)
+		return 0, sql.ErrNoRows
+	}
+
+	if len(shifts) == 0 {
+		query := `
+			UPDATE schedules SET acknowledged_at = NOW()
+			WHERE employee_id = $1
+				AND acknowledged_at IS NULL
+				AND schedule_date >= CURRENT_DATE
+		`
+		result, err := s.DB.Exec(query, user_id)
+		if err != nil {
+			s.Logger.Error("failed to acknowledge shifts", "error", err, "user_id", user_id)
+			return 0, err
+		}
+		acknowledged, _ := result.RowsAffected()
+		s.Logger.Info("shifts acknowledged", "user_id", user_id, "count", acknowledged)
+		return acknowledged, nil
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		s.Logger.Error("failed to begin transaction", "error", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE schedules SET acknowledged_at = NOW()
+		WHERE employee_id = $1
+			AND schedule_date = $2
+			AND start_hour = $3
+			AND end_hour = $4
+			AND acknowledged_at IS NULL
+	`
+	var acknowledged int64
+	for _, shift := range shifts {
+		result, err := tx.Exec(query, user_id, shift.Date, shift.StartTime, shift.EndTime)
+		if err != nil {
+			s.Logger.Error("failed to acknowledge shift", "error", err, "user_id", user_id, "date", shift.Date)
+			return 0, err
+		}
+		affected, _ := result.RowsAffected()
+		acknowledged += affected
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.Logger.Error("failed to commit transaction", "error", err)
+		return 0, err
+	}
+
+	s.Logger.Info("shifts acknowledged", "user_id", user_id, "count", acknowledged)
+	return acknowledged, nil
+}
+
+// GetShiftAcknowledgments retrieves the acknowledgment state of the shifts in the organization matching
+// the filter for 7 days
+func (s *PostgresScheduleStore) GetShiftAcknowledgments(org_id uuid.UUID, filter ShiftFilter) ([]ShiftAcknowledgment, error) {
+	where := Where("u.organization_id = ?", org_id)
+	where = AndIn(where, "s.employee_id", filter.EmployeeIDs)
+	if len(filter.Roles) > 0 {
+		args := []any{org_id}
+		for _, role := range filter.Roles {
+			args = append(args, role)
+		}
+		where.And(`EXISTS (
+			SELECT 1 FROM user_roles ur
+			WHERE ur.user_id = s.employee_id AND ur.organization_id = ? AND ur.user_role IN (`+Placeholders(len(filter.Roles))+`)
+		)`, args...)
+	}
+	if filter.Acknowledged != nil {
+		where.AndIf(*filter.Acknowledged, "s.acknowledged_at IS NOT NULL").
+			AndIf(!*filter.Acknowledged, "s.acknowledged_at IS NULL")
+	}
+	conditions, args := where.Build()
+
+	query := `
+		SELECT
+			u.organization_id,
+			s.employee_id,
+			u.full_name,
+			u.email,
+			s.schedule_date,
+			s.day,
+			s.start_hour,
+			s.end_hour,
+			s.published_at,
+			s.acknowledged_at,
+			s.last_reminded_at
+		FROM schedules s
+		INNER JOIN users u ON s.employee_id = u.id
+		WHERE ` + conditions + `
+			AND s.schedule_date >= CURRENT_DATE
+			AND s.schedule_date < CURRENT_DATE + INTERVAL '7 days'
+		ORDER BY s.schedule_date, s.start_hour, u.full_name
+	`
+
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		s.Logger.Error("failed to get shift acknowledgments", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	acknowledgments, err := scanShiftAcknowledgments(rows)
+	if err != nil {
+		s.Logger.Error("failed to scan shift acknowledgment row", "error", err)
+		return nil, err
+	}
+
+	s.Logger.Info("retrieved shift acknowledgments", "org_id", org_id, "count", len(acknowledgments))
+	return acknowledgments, nil
+}
+
+// GetShiftsPendingReminder retrieves upcoming unacknowledged shifts across all organizations that
+// were published before the cutoff and have not been reminded about since the cutoff
+func (s *PostgresScheduleStore) GetShiftsPendingReminder(cutoff time.Time) ([]ShiftAcknowledgment, error) {
+	query := `
+		SELECT
+			u.organization_id,
+			s.employee_id,
+			u.full_name,
+			u.email,
+			s.schedule_date,
+			s.day,
+			s.start_hour,
+			s.end_hour,
+			s.published_at,
+			s.acknowledged_at,
+			s.last_reminded_at
+		FROM schedules s
+		INNER JOIN users u ON s.employee_id = u.id
+		WHERE s.acknowledged_at IS NULL
+			AND s.schedule_date >= CURRENT_DATE
+			AND s.published_at <= $1
+			AND (s.last_reminded_at IS NULL OR s.last_reminded_at <= $1)
+		ORDER BY s.employee_id, s.schedule_date, s.start_hour
+	`
+
+	rows, err := s.DB.Query(query, cutoff)
+	if err != nil {
+		s.Logger.Error("failed to get shifts pending reminder", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	shifts, err := scanShiftAcknowledgments(rows)
+	if err != nil {
+		s.Logger.Error("failed to scan shift acknowledgment row", "error", err)
+		return nil, err
+	}
+	return shifts, nil
+}
+
+// GetShiftsForWeeklyEmail retrieves the shifts of the week starting on week_start of every employee who was
+// not emailed about that week yet, by employee and time
+func (s *PostgresScheduleStore) GetShiftsForWeeklyEmail(week_start time.Time) ([]WeeklyShift, error) {
+	query := `
+		SELECT
+			s.id,
+			s.employee_id,
+			u.full_name,
+			u.email,
+			u.organization_id,
+			s.schedule_date,
+			s.day,
+			s.start_hour,
+			s.end_hour,
+			s.shift_type,
+			EXTRACT(EPOCH FROM (s.end_hour - s.start_hour)) / 3600 AS hours,
+			COALESCE(r.standby_pay_percent, 25)
+		FROM schedules s
+		INNER JOIN users u ON s.employee_id = u.id
+		LEFT JOIN organizations_rules r ON u.organization_id = r.organization_id
+		WHERE s.schedule_date >= $1
+			AND s.schedule_date < $1::DATE + 7
+			AND NOT EXISTS (
+				SELECT 1 FROM weekly_schedule_emails w WHERE w.user_id = s.employee_id AND w.week_start = $1
+			)
+		ORDER BY s.employee_id, s.schedule_date, s.start_hour
+	`
+
+	rows, err := s.DB.Query(query, week_start)
+	if err != nil {
+		s.Logger.Error("failed to get shifts for weekly email", "error", err, "week_start", week_start)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shifts []WeeklyShift
+	for rows.Next() {
+		var shift WeeklyShift
+		err := rows.Scan(
+			&shift.ID,
+			&shift.EmployeeID,
+			&shift.EmployeeName,
+			&shift.EmployeeEmail,
+			&shift.OrganizationID,
+			&shift.Date,
+			&shift.Day,
+			&shift.StartTime,
+			&shift.EndTime,
+			&shift.ShiftType,
+			&shift.Hours,
+			&shift.StandbyPayPercent,
+		)
+		if err != nil {
+			s.Logger.Error("failed to scan weekly shift row", "error", err)
+			return nil, err
+		}
+		shifts = append(shifts, shift)
+	}
+	return shifts, rows.Err()
+}
+
+// MarkWeeklyEmailSent records that the employee was emailed the shifts of the week starting on week_start
+func (s *PostgresScheduleStore) MarkWeeklyEmailSent(user_id uuid.UUID, week_start time.Time) error {
+	query := `INSERT INTO weekly_schedule_emails (user_id, week_start) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	if _, err := s.DB.Exec(query, user_id, week_start); err != nil {
+		s.Logger.Error("failed to mark weekly email sent", "error", err, "user_id", user_id)
+		return err
+	}
+	return nil
+}
+
+// MarkShiftRemindersSent records that the employee was reminded about their unacknowledged shifts
+func (s *PostgresScheduleStore) MarkShiftRemindersSent(user_id uuid.UUID) error {
+	query := `
+		UPDATE schedules SET last_reminded_at = NOW()
+		WHERE employee_id = $1
+			AND acknowledged_at IS NULL
+			AND schedule_date >= CURRENT_DATE
+	`
+	if _, err := s.DB.Exec(query, user_id); err != nil {
+		s.Logger.Error("failed to mark shift reminders sent", "error", err, "user_id", user_id)
+		return err
+	}
+	return nil
+}
+
+// GetScheduleClearSummary reports the shifts, acknowledgments and queued overtime offers of the
+// organization between from and to (inclusive) without changing anything
+func (s *PostgresScheduleStore) GetScheduleClearSummary(org_id uuid.UUID, from time.Time, to time.Time) (*ScheduleClearSummary, error) {
+	summary, err := scheduleClearSummary(s.DB, org_id, from, to)
+	if err != nil {
+		s.Logger.Error("failed to get schedule clear summary", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	return summary, nil
+}
+
+// ClearSchedule deletes the shifts of the organization between from and to (inclusive), with their
+// acknowledgments and the overtime offers still in queue for those days, in a single transaction.
+// Each shift gets a cancelled event by actor_id. It returns what was removed.
+func (s *PostgresScheduleStore) ClearSchedule(org_id uuid.UUID, from time.Time, to time.Time, actor_id uuid.UUID) (*ScheduleClearSummary, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		s.Logger.Error("failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	summary, err := scheduleClearSummary(tx, org_id, from, to)
+	if err != nil {
+		s.Logger.Error("failed to get schedule clear summary", "error", err, "org_id", org_id)
+		return nil, err
+	}
+
+	deleteShifts := `
+		WITH cancelled AS (
+			DELETE FROM schedules s
+			USING users u
+			WHERE s.employee_id = u.id
+				AND u.organization_id = $1
+				AND s.schedule_date BETWEEN $2 AND $3
+			RETURNING s.id, s.employee_id, s.schedule_date, s.start_hour, s.end_hour, s.shift_type
+		)
+		INSERT INTO schedule_events (organization_id, shift_id, event_type, employee_id, schedule_date, start_hour, end_hour, shift_type, actor_id)
+		SELECT $1, id, 'cancelled', employee_id, schedule_date, start_hour, end_hour, shift_type, $4 FROM cancelled
+	`
+	if _, err := tx.Exec(deleteShifts, org_id, from, to, actor_id); err != nil {
+		s.Logger.Error("failed to clear schedule", "error", err, "org_id", org_id)
+		return nil, err
+	}
+
+	deleteOffers := `
+		DELETE FROM over_time_offers o
+		USING users u
+		WHERE o.employee_id = u.id
+			AND u.organization_id = $1
+			AND o.status = 'in queue'
+			AND o.start_time::date BETWEEN $2 AND $3
+	`
+	if _, err := tx.Exec(deleteOffers, org_id, from, to); err != nil {
+		s.Logger.Error("failed to clear queued offers", "error", err, "org_id", org_id)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.Logger.Error("failed to commit transaction", "error", err)
+		return nil, err
+	}
+
+	s.Logger.Info("schedule cleared", "org_id", org_id, "from", from, "to", to, "shifts", summary.Shifts)
+	return summary, nil
+}
+
+// StoreStandbyShift schedules an on-call shift for an employee of the organization, created by actor_id.
+// It returns sql.ErrNoRows when the employee is not in the organization and ErrShiftOverlaps when they
+// already have a shift overlapping it.
+func (s *PostgresScheduleStore) StoreStandbyShift(org_id uuid.UUID, user_id uuid.UUID, shift ShiftKey, actor_id uuid.UUID) (*Shift, error) {
+	standby := Shift{
+		EmployeeID: user_id,
+		Date:       shift.Date,
+		Day:        strings.ToLower(shift.Date.Weekday().String()),
+		StartTime:  shift.StartTime,
+		EndTime:    shift.EndTime,
+		ShiftType:  ShiftStandby,
+	}
+
+	userQuery := `SELECT full_name, email FROM users WHERE id = $1 AND organization_id = $2`
+	if err := s.DB.QueryRow(userQuery, user_id, org_id).Scan(&standby.EmployeeName, &standby.EmployeeEmail); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.Logger.Error("failed to verify user organization", "error", err, "user_id", user_id, "org_id", org_id)
+		}
+		return nil, err
+	}
+
+	query := `
+		WITH shift AS (
+			INSERT INTO schedules (schedule_date, day, start_hour, end_hour, employee_id, shift_type)
+			SELECT $1, $2, $3, $4, $5, 'standby'
+			WHERE NOT EXISTS (
+				SELECT 1 FROM schedules
+				WHERE employee_id = $5 AND schedule_date = $1 AND start_hour < $4 AND end_hour > $3
+			)
+			RETURNING id, employee_id, schedule_date, start_hour, end_hour, shift_type
+		), event AS (
+			INSERT INTO schedule_events (organization_id, shift_id, event_type, employee_id, schedule_date, start_hour, end_hour, shift_type, actor_id)
+			SELECT $6, id, 'created', employee_id, schedule_date, start_hour, end_hour, shift_type, $7 FROM shift
+		)
+		SELECT id FROM shift
+	`
+	err := s.DB.QueryRow(query, shift.Date, standby.Day, shift.StartTime, shift.EndTime, user_id, org_id, actor_id).Scan(&standby.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrShiftOverlaps
+		}
+		s.Logger.Error("failed to store standby shift", "error", err, "user_id", user_id)
+		return nil, err
+	}
+
+	s.Logger.Info("standby shift stored", "user_id", user_id, "shift_id", standby.ID, "date", shift.Date)
+	return &standby, nil
+}
+
+// ActivateShift turns a standby shift of the organization into a working shift activated at the given
+// time by actor_id. It returns sql.ErrNoRows when the shift is not found, ErrShiftNotStandby when it is a
+// working shift (or was already activated) and ErrShiftEnded when it is over.
+func (s *PostgresScheduleStore) ActivateShift(org_id uuid.UUID, shift_id uuid.UUID, at time.Time, actor_id uuid.UUID) (*Shift, error) {
+	query := `
+		WITH shift AS (
+			UPDATE schedules s
+			SET shift_type = 'working', activated_at = $3
+			FROM users u
+			WHERE s.id = $1
+				AND s.employee_id = u.id
+				AND u.organization_id = $2
+				AND s.shift_type = 'standby'
+				AND (s.schedule_date + s.end_hour) > $3
+			RETURNING s.id, s.employee_id, u.full_name, u.email, s.schedule_date, s.day, s.start_hour, s.end_hour, s.shift_type, s.activated_at
+		), event AS (
+			INSERT INTO schedule_events (organization_id, shift_id, event_type, employee_id, schedule_date, start_hour, end_hour, shift_type, actor_id)
+			SELECT $2, id, 'edited', employee_id, schedule_date, start_hour, end_hour, shift_type, $4 FROM shift
+		)
+		SELECT id, employee_id, full_name, email, schedule_date, day, start_hour, end_hour, shift_type, activated_at FROM shift
+	`
+	var shift Shift
+	err := s.DB.QueryRow(query, shift_id, org_id, at, actor_id).Scan(
+		&shift.ID,
+		&shift.EmployeeID,
+		&shift.EmployeeName,
+		&shift.EmployeeEmail,
+		&shift.Date,
+		&shift.Day,
+		&shift.StartTime,
+		&shift.EndTime,
+		&shift.ShiftType,
+		&shift.ActivatedAt,
+	)
+	if err == nil {
+		s.Logger.Info("standby shift activated", "org_id", org_id, "shift_id", shift_id)
+		return &shift, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		s.Logger.Error("failed to activate shift", "error", err, "shift_id", shift_id)
+		return nil, err
+	}
+
+	// tell apart a missing shift from one that cannot be activated
+	var shiftType string
+	var ended bool
+	checkQuery := `
+		SELECT s.shift_type, (s.schedule_date + s.end_hour) <= $3
+		FROM schedules s
+		INNER JOIN users u ON s.employee_id = u.id
+		WHERE s.id = $1 AND u.organization_id = $2
+	`
+	if err := s.DB.QueryRow(checkQuery, shift_id, org_id, at).Scan(&shiftType, &ended); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.Logger.Error("failed to get shift", "error", err, "shift_id", shift_id)
+		}
+		return nil, err
+	}
+	if shiftType != ShiftStandby {
+		return nil, ErrShiftNotStandby
+	}
+	if ended {
+		return nil, ErrShiftEnded
+	}
+	// the shift changed between the two queries
+	return nil, ErrShiftNotStandby
+}
+
+// GetShiftEvents lists the events of a shift of the organization, oldest first. A shift without events
+// is not in the organization.
+func (s *PostgresScheduleStore) GetShiftEvents(org_id uuid.UUID, shift_id uuid.UUID) ([]ScheduleEvent, error) {
+	query := `
+		SELECT
+			e.id,
+			e.shift_id,
+			e.event_type,
+			e.employee_id,
+			u.full_name,
+			e.schedule_date,
+			e.start_hour,
+			e.end_hour,
+			e.shift_type,
+			e.actor_id,
+			a.full_name,
+			e.occurred_at
+		FROM schedule_events e
+		INNER JOIN users u ON e.employee_id = u.id
+		LEFT JOIN users a ON e.actor_id = a.id
+		WHERE e.organization_id = $1 AND e.shift_id = $2
+		ORDER BY e.occurred_at, e.id
+	`
+	rows, err := s.DB.Query(query, org_id, shift_id)
+	if err != nil {
+		s.Logger.Error("failed to get shift events", "error", err, "org_id", org_id, "shift_id", shift_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []ScheduleEvent{}
+	for rows.Next() {
+		var event ScheduleEvent
+		err := rows.Scan(
+			&event.ID,
+			&event.ShiftID,
+			&event.EventType,
+			&event.EmployeeID,
+			&event.EmployeeName,
+			&event.Date,
+			&event.StartTime,
+			&event.EndTime,
+			&event.ShiftType,
+			&event.ActorID,
+			&event.ActorName,
+			&event.OccurredAt,
+		)
+		if err != nil {
+			s.Logger.Error("failed to scan shift event row", "error", err)
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// CancelShift deletes a shift of the organization in a single transaction, with a cancelled event and a
+// cancellation by actor_id, and offers it to the other employees when requested. It returns
+// sql.ErrNoRows when the shift is not found, ErrShiftStarted when it has started and
+// ErrCancellationNotice when it starts within the notice period and late cancellations are not allowed.
+func (s *PostgresScheduleStore) CancelShift(org_id uuid.UUID, shift_id uuid.UUID, request ShiftCancellationRequest, actor_id uuid.UUID) (*ShiftCancellation, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		s.Logger.Error("failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	cancellation := ShiftCancellation{
+		ShiftID:             shift_id,
+		Reason:              request.Reason,
+		CancelledBy:         &actor_id,
+		RequiredNoticeHours: request.RequiredNoticeHours,
+	}
+	shiftQuery := `
+		SELECT s.employee_id, u.full_name, u.email, s.schedule_date, s.start_hour, s.end_hour, s.shift_type,
+			EXTRACT(EPOCH FROM (s.schedule_date + s.start_hour) - $3) / 3600
+		FROM schedules s
+		INNER JOIN users u ON s.employee_id = u.id
+		WHERE s.id = $1 AND u.organization_id = $2
+		FOR UPDATE OF s
+	`
+	err = tx.QueryRow(shiftQuery, shift_id, org_id, request.At).Scan(
+		&cancellation.EmployeeID,
+		&cancellation.EmployeeName,
+		&cancellation.EmployeeEmail,
+		&cancellation.Date,
+		&cancellation.StartTime,
+		&cancellation.EndTime,
+		&cancellation.ShiftType,
+		&cancellation.NoticeHours,
+	)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.Logger.Error("failed to get shift", "error", err, "shift_id", shift_id)
+		}
+		return nil, err
+	}
+	if cancellation.NoticeHours <= 0 {
+		return nil, ErrShiftStarted
+	}
+	cancellation.NoticeHours = math.Round(cancellation.NoticeHours*100) / 100
+	cancellation.Late = cancellation.NoticeHours < float64(request.RequiredNoticeHours)
+	if cancellation.Late && !request.AllowLate {
+		return nil, ErrCancellationNotice
+	}
+
+	deleteShift := `
+		WITH cancelled AS (
+			DELETE FROM schedules WHERE id = $1
+			RETURNING id, employee_id, schedule_date, start_hour, end_hour, shift_type
+		)
+		INSERT INTO schedule_events (organization_id, shift_id, event_type, employee_id, schedule_date, start_hour, end_hour, shift_type, actor_id)
+		SELECT $2, id, 'cancelled', employee_id, schedule_date, start_hour, end_hour, shift_type, $3 FROM cancelled
+	`
+	if _, err := tx.Exec(deleteShift, shift_id, org_id, actor_id); err != nil {
+		s.Logger.Error("failed to cancel shift", "error", err, "shift_id", shift_id)
+		return nil, err
+	}
+
+	if request.OfferShift {
+		// employees of the organization who have no shift overlapping the cancelled one
+		offerShift := `
+			INSERT INTO over_time_offers (employee_id, status, shift_length, start_time)
+			SELECT u.id, 'in queue', CEIL(EXTRACT(EPOCH FROM $5::time - $4::time) / 3600), $3::date + $4::time
+			FROM users u
+			WHERE u.organization_id = $1
+				AND u.user_role = 'employee'
+				AND u.id <> $2
+				AND NOT EXISTS (
+					SELECT 1 FROM schedules s
+					WHERE s.employee_id = u.id AND s.schedule_date = $3 AND s.start_hour < $5 AND s.end_hour > $4
+				)
+		`
+		result, err := tx.Exec(offerShift, org_id, cancellation.EmployeeID, cancellation.Date, cancellation.StartTime, cancellation.EndTime)
+		if err != nil {
+			s.Logger.Error("failed to offer cancelled shift", "error", err, "shift_id", shift_id)
+			return nil, err
+		}
+		offers, _ := result.RowsAffected()
+		cancellation.OffersCreated = int(offers)
+	}
+
+	insertCancellation := `
+		INSERT INTO shift_cancellations (organization_id, shift_id, employee_id, schedule_date, start_hour, end_hour, shift_type,
+			reason, cancelled_by, notice_hours, required_notice_hours, late, offers_created)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, cancelled_at
+	`
+	err = tx.QueryRow(insertCancellation, org_id, shift_id, cancellation.EmployeeID, cancellation.Date, cancellation.StartTime,
+		cancellation.EndTime, cancellation.ShiftType, cancellation.Reason, actor_id, cancellation.NoticeHours,
+		cancellation.RequiredNoticeHours, cancellation.Late, cancellation.OffersCreated).Scan(&cancellation.ID, &cancellation.CancelledAt)
+	if err != nil {
+		s.Logger.Error("failed to store shift cancellation", "error", err, "shift_id", shift_id)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.Logger.Error("failed to commit transaction", "error", err)
+		return nil, err
+	}
+
+	s.Logger.Info("shift cancelled", "org_id", org_id, "shift_id", shift_id, "late", cancellation.Late, "offers", cancellation.OffersCreated)
+	return &cancellation, nil
+}
+
+// GetShiftCancellations lists the shift cancellations of the organization matching the filter, latest
+// first
+func (s *PostgresScheduleStore) GetShiftCancellations(org_id uuid.UUID, filter CancellationFilter) ([]ShiftCancellation, error) {
+	where := Where("c.organization_id = ?", org_id).
+		AndIf(filter.From != nil, "c.schedule_date >= ?", filter.From).
+		AndIf(filter.To != nil, "c.schedule_date < ?", filter.To).
+		AndIf(filter.Late != nil, "c.late = ?", filter.Late)
+	conditions, args := where.Build()
+
+	query := `
+		SELECT
+			c.id,
+			c.shift_id,
+			c.employee_id,
+			u.full_name,
+			c.schedule_date,
+			c.start_hour,
+			c.end_hour,
+			c.shift_type,
+			c.reason,
+			c.cancelled_by,
+			a.full_name,
+			c.notice_hours,
+			c.required_notice_hours,
+			c.late,
+			c.offers_created,
+			c.cancelled_at
+		FROM shift_cancellations c
+		INNER JOIN users u ON c.employee_id = u.id
+		LEFT JOIN users a ON c.cancelled_by = a.id
+		WHERE ` + conditions + `
+		ORDER BY c.cancelled_at DESC, c.id
+	`
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		s.Logger.Error("failed to get shift cancellations", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	cancellations := []ShiftCancellation{}
+	for rows.Next() {
+		var cancellation ShiftCancellation
+		err := rows.Scan(
+			&cancellation.ID,
+			&cancellation.ShiftID,
+			&cancellation.EmployeeID,
+			&cancellation.EmployeeName,
+			&cancellation.Date,
+			&cancellation.StartTime,
+			&cancellation.EndTime,
+			&cancellation.ShiftType,
+			&cancellation.Reason,
+			&cancellation.CancelledBy,
+			&cancellation.CancelledByName,
+			&cancellation.NoticeHours,
+			&cancellation.RequiredNoticeHours,
+			&cancellation.Late,
+			&cancellation.OffersCreated,
+			&cancellation.CancelledAt,
+		)
+		if err != nil {
+			s.Logger.Error("failed to scan shift cancellation row", "error", err)
+			return nil, err
+		}
+		cancellations = append(cancellations, cancellation)
+	}
+	return cancellations, rows.Err()
+}
+
+// GetShiftsFrom returns the shifts of the employees of the organization on the date of from and after,
+// ordered by start
+func (s *PostgresScheduleStore) GetShiftsFrom(org_id uuid.UUID, from time.Time) ([]Shift, error) {
+	query := `
+		SELECT
+			s.id,
+			s.employee_id,
+			u.full_name,
+			u.email,
+			s.schedule_date,
+			s.day,
+			s.start_hour,
+			s.end_hour,
+			s.shift_type,
+			s.activated_at
+		FROM schedules s
+		INNER JOIN users u ON s.employee_id = u.id
+		WHERE u.organization_id = $1
+			AND s.schedule_date >= $2::DATE
+		ORDER BY s.schedule_date, s.start_hour, s.employee_id
+	`
+
+	rows, err := s.DB.Query(query, org_id, from)
+	if err != nil {
+		s.Logger.Error("failed to get shifts", "error", err, "org_id", org_id, "from", from)
+		return nil, err
+	}
+	defer rows.Close()
+
+	shifts := []Shift{}
+	for rows.Next() {
+		var shift Shift
+		err := rows.Scan(
+			&shift.ID,
+			&shift.EmployeeID,
+			&shift.EmployeeName,
+			&shift.EmployeeEmail,
+			&shift.Date,
+			&shift.Day,
+			&shift.StartTime,
+			&shift.EndTime,
+			&shift.ShiftType,
+			&shift.ActivatedAt,
+		)
+		if err != nil {
+			s.Logger.Error("failed to scan shift row", "error", err)
+			return nil, err
+		}
+		shifts = append(shifts, shift)
+	}
+	return shifts, rows.Err()
+}
+
+// GetScheduledHours sums the working and standby hours of each employee of the organization with
+// shifts in [from, to)
+func (s *PostgresScheduleStore) GetScheduledHours(org_id uuid.UUID, from time.Time, to time.Time) ([]EmployeeHours, error) {
+	query := `
+		SELECT
+			s.employee_id,
+			COALESCE(SUM(EXTRACT(EPOCH FROM (s.end_hour - s.start_hour)) / 3600) FILTER (WHERE s.shift_type = 'working'), 0),
+			COALESCE(SUM(EXTRACT(EPOCH FROM (s.end_hour - s.start_hour)) / 3600) FILTER (WHERE s.shift_type = 'standby'), 0),
+			COALESCE(MAX(r.standby_pay_percent), 25)
+		FROM schedules s
+		INNER JOIN users u ON s.employee_id = u.id
+		LEFT JOIN organizations_rules r ON u.organization_id = r.organization_id
+		WHERE u.organization_id = $1
+			AND s.schedule_date >= $2::DATE
+			AND s.schedule_date < $3::DATE
+		GROUP BY s.employee_id
+		ORDER BY s.employee_id
+	`
+
+	rows, err := s.DB.Query(query, org_id, from, to)
+	if err != nil {
+		s.Logger.Error("failed to get scheduled hours", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	hours := []EmployeeHours{}
+	for rows.Next() {
+		var h EmployeeHours
+		if err := rows.Scan(&h.EmployeeID, &h.WorkingHours, &h.StandbyHours, &h.StandbyPayPercent); err != nil {
+			s.Logger.Error("failed to scan scheduled hours row", "error", err)
+			return nil, err
+		}
+		hours = append(hours, h)
+	}
+	return hours, rows.Err()
+}
+
+// GetScheduleChanges lists the changes to the shifts of the organization dated in [from, to) made after
+// they were scheduled, oldest first. Generated shifts are part of the schedule as published, shifts
+// created by hand are changes.
+func (s *PostgresScheduleStore) GetScheduleChanges(org_id uuid.UUID, from time.Time, to time.Time) ([]ScheduleChange, error) {
+	query := `
+		WITH history AS (
+			SELECT
+				e.*,
+				LAG(e.schedule_date) OVER shift AS previous_date,
+				LAG(e.start_hour) OVER shift AS previous_start,
+				LAG(e.end_hour) OVER shift AS previous_end
+			FROM schedule_events e
+			WHERE e.organization_id = $1
+				AND e.shift_id IN (
+					SELECT shift_id FROM schedule_events
+					WHERE organization_id = $1 AND schedule_date >= $2::DATE AND schedule_date < $3::DATE
+				)
+			WINDOW shift AS (PARTITION BY e.shift_id ORDER BY e.occurred_at, e.id)
+		)
+		SELECT
+			h.shift_id,
+			h.event_type,
+			h.employee_id,
+			u.full_name,
+			h.schedule_date,
+			h.start_hour,
+			h.end_hour,
+			h.shift_type,
+			EXTRACT(EPOCH FROM (h.end_hour - h.start_hour)) / 3600,
+			h.previous_date,
+			h.previous_start,
+			h.previous_end,
+			EXTRACT(EPOCH FROM (h.previous_end - h.previous_start)) / 3600,
+			EXTRACT(EPOCH FROM LEAST(h.schedule_date + h.start_hour, COALESCE(h.previous_date + h.previous_start, h.schedule_date + h.start_hour)) - h.occurred_at) / 3600,
+			h.occurred_at
+		FROM history h
+		INNER JOIN users u ON h.employee_id = u.id
+		WHERE h.event_type <> 'generated'
+			AND h.schedule_date >= $2::DATE
+			AND h.schedule_date < $3::DATE
+		ORDER BY h.occurred_at, h.shift_id
+	`
+	rows, err := s.DB.Query(query, org_id, from, to)
+	if err != nil {
+		s.Logger.Error("failed to get schedule changes", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []ScheduleChange{}
+	for rows.Next() {
+		var change ScheduleChange
+		err := rows.Scan(
+			&change.ShiftID,
+			&change.EventType,
+			&change.EmployeeID,
+			&change.EmployeeName,
+			&change.Date,
+			&change.StartTime,
+			&change.EndTime,
+			&change.ShiftType,
+			&change.Hours,
+			&change.PreviousDate,
+			&change.PreviousStartTime,
+			&change.PreviousEndTime,
+			&change.PreviousHours,
+			&change.NoticeHours,
+			&change.OccurredAt,
+		)
+		if err != nil {
+			s.Logger.Error("failed to scan schedule change row", "error", err)
+			return nil, err
+		}
+		change.NoticeHours = math.Round(change.NoticeHours*100) / 100
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
+
+// GetOrganizationsForComplianceReport lists the organizations subject to predictable scheduling whose
+// admins were not sent the compliance report of the week starting week_start yet
+func (s *PostgresScheduleStore) GetOrganizationsForComplianceReport(week_start time.Time) ([]uuid.UUID, error) {
+	query := `
+		SELECT r.organization_id
+		FROM organizations_rules r
+		WHERE r.predictability_notice_days IS NOT NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM compliance_report_emails c
+				WHERE c.organization_id = r.organization_id AND c.week_start = $1
+			)
+		ORDER BY r.organization_id
+	`
+	rows, err := s.DB.Query(query, week_start)
+	if err != nil {
+		s.Logger.Error("failed to get organizations for compliance report", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	orgs := []uuid.UUID{}
+	for rows.Next() {
+		var orgID uuid.UUID
+		if err := rows.Scan(&orgID); err != nil {
+			s.Logger.Error("failed to scan organization row", "error", err)
+			return nil, err
+		}
+		orgs = append(orgs, orgID)
+	}
+	return orgs, rows.Err()
+}
+
+// MarkComplianceReportSent records that the compliance report of the week was sent to the organization
+func (s *PostgresScheduleStore) MarkComplianceReportSent(org_id uuid.UUID, week_start time.Time) error {
+	query := `INSERT INTO compliance_report_emails (organization_id, week_start) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	if _, err := s.DB.Exec(query, org_id, week_start); err != nil {
+		s.Logger.Error("failed to mark compliance report sent", "error", err, "org_id", org_id)
+		return err
+	}
+	return nil
+}
+
+// setShiftKind marks standby shifts on a schedule entry, they are not productive hours until activated
+func setShiftKind(schedule *Schedule, shiftType string) {
+	if shiftType == ShiftStandby {
+		schedule.Kind = ShiftStandby
+		schedule.NonProductive = true
+	}
+}
+
+type scheduleQueryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func scheduleClearSummary(q scheduleQueryer, org_id uuid.UUID, from time.Time, to time.Time) (*ScheduleClearSummary, error) {
+	summary := &ScheduleClearSummary{
+		From:      from,
+		To:        to,
+		Employees: []ScheduleClearedEmployee{},
+	}
+
+	query := `
+		SELECT u.id, u.full_name, u.email, COUNT(*), COUNT(s.acknowledged_at)
+		FROM schedules s
+		INNER JOIN users u ON s.employee_id = u.id
+		WHERE u.organization_id = $1
+			AND s.schedule_date BETWEEN $2 AND $3
+		GROUP BY u.id, u.full_name, u.email
+		ORDER BY u.full_name
+	`
+	rows, err := q.Query(query, org_id, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var employee ScheduleClearedEmployee
+		var acknowledged int
+		if err := rows.Scan(&employee.EmployeeID, &employee.EmployeeName, &employee.Email, &employee.Shifts, &acknowledged); err != nil {
+			return nil, err
+		}
+		summary.Shifts += employee.Shifts
+		summary.AcknowledgedShifts += acknowledged
+		summary.Employees = append(summary.Employees, employee)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	offersQuery := `
+		SELECT COUNT(*)
+		FROM over_time_offers o
+		INNER JOIN users u ON o.employee_id = u.id
+		WHERE u.organization_id = $1
+			AND o.status = 'in queue'
+			AND o.start_time::date BETWEEN $2 AND $3
+	`
+	if err := q.QueryRow(offersQuery, org_id, from, to).Scan(&summary.QueuedOffers); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+func scanShiftAcknowledgments(rows *sql.Rows) ([]ShiftAcknowledgment, error) {
+	var acknowledgments []ShiftAcknowledgment
+	for rows.Next() {
+		var ack ShiftAcknowledgment
+		var acknowledgedAt, lastRemindedAt sql.NullTime
+
+		err := rows.Scan(
+			&ack.OrganizationID,
+			&ack.EmployeeID,
+			&ack.EmployeeName,
+			&ack.EmployeeEmail,
+			&ack.Date,
+			&ack.Day,
+			&ack.StartTime,
+			&ack.EndTime,
+			&ack.PublishedAt,
+			&acknowledgedAt,
+			&lastRemindedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if acknowledgedAt.Valid {
+			ack.AcknowledgedAt = &acknowledgedAt.Time
+		}
+		if lastRemindedAt.Valid {
+			ack.LastRemindedAt = &lastRemindedAt.Time
+		}
+		acknowledgments = append(acknowledgments, ack)
+	}
+	return acknowledgments, rows.Err()
+}
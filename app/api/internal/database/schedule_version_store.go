@@ -0,0 +1,177 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScheduleVersion is a generated schedule and how well it matched the preferences of the employees.
+// PreferenceSatisfactionPercent is the share of preferred shifts of the employees who submitted
+// preferences, nil when none did. Employees is only set when a single version is read.
+type ScheduleVersion struct {
+	ID                            uuid.UUID              `json:"id"`
+	Source                        string                 `json:"source"`
+	GeneratedBy                   *uuid.UUID             `json:"generated_by"`
+	WeekStart                     time.Time              `json:"week_start"`
+	Shifts                        int                    `json:"shifts"`
+	PreferredShifts               int                    `json:"preferred_shifts"`
+	PreferenceSatisfactionPercent *float64               `json:"preference_satisfaction_percent"`
+	CreatedAt                     time.Time              `json:"created_at"`
+	Employees                     []EmployeeSatisfaction `json:"employees,omitempty"`
+}
+
+// EmployeeSatisfaction is the share of an employee's shifts inside their preferred hours, nil when they
+// submitted no preferences
+type EmployeeSatisfaction struct {
+	EmployeeID          uuid.UUID `json:"employee_id"`
+	EmployeeName        string    `json:"employee_name,omitempty"`
+	Shifts              int       `json:"shifts"`
+	PreferredShifts     int       `json:"preferred_shifts"`
+	SatisfactionPercent *float64  `json:"satisfaction_percent"`
+}
+
+type ScheduleVersionStore interface {
+	StoreScheduleVersion(org_id uuid.UUID, version *ScheduleVersion) error
+	GetScheduleVersions(org_id uuid.UUID, limit int) ([]ScheduleVersion, error)
+	GetScheduleVersion(org_id, version_id uuid.UUID) (*ScheduleVersion, error)
+}
+
+type PostgresScheduleVersionStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresScheduleVersionStore(DB *sql.DB, Logger *slog.Logger) *PostgresScheduleVersionStore {
+	return &PostgresScheduleVersionStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// StoreScheduleVersion stores a generated schedule with the satisfaction of each employee, setting its
+// ID and creation time
+func (s *PostgresScheduleVersionStore) StoreScheduleVersion(org_id uuid.UUID, version *ScheduleVersion) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		s.Logger.Error("failed to begin transaction", "error", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO schedule_versions (organization_id, source, generated_by, week_start, shifts, preferred_shifts, preference_satisfaction_percent)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRow(query, org_id, version.Source, version.GeneratedBy, version.WeekStart, version.Shifts,
+		version.PreferredShifts, version.PreferenceSatisfactionPercent).Scan(&version.ID, &version.CreatedAt)
+	if err != nil {
+		s.Logger.Error("failed to store schedule version", "error", err, "org_id", org_id)
+		return err
+	}
+
+	for _, employee := range version.Employees {
+		_, err := tx.Exec(`
+			INSERT INTO schedule_version_employees (version_id, employee_id, shifts, preferred_shifts, satisfaction_percent)
+			VALUES ($1, $2, $3, $4, $5)
+		`, version.ID, employee.EmployeeID, employee.Shifts, employee.PreferredShifts, employee.SatisfactionPercent)
+		if err != nil {
+			s.Logger.Error("failed to store employee satisfaction", "error", err, "version_id", version.ID, "employee_id", employee.EmployeeID)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.Logger.Error("failed to commit transaction", "error", err)
+		return err
+	}
+
+	s.Logger.Info("schedule version stored", "org_id", org_id, "version_id", version.ID, "satisfaction", version.PreferenceSatisfactionPercent)
+	return nil
+}
+
+const scheduleVersionColumns = `id, source, generated_by, week_start, shifts, preferred_shifts, preference_satisfaction_percent, created_at`
+
+func scanScheduleVersion(row rowScanner) (*ScheduleVersion, error) {
+	var version ScheduleVersion
+	err := row.Scan(&version.ID, &version.Source, &version.GeneratedBy, &version.WeekStart, &version.Shifts,
+		&version.PreferredShifts, &version.PreferenceSatisfactionPercent, &version.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// GetScheduleVersions lists the latest generated schedules of the organization, newest first, without
+// the employees
+func (s *PostgresScheduleVersionStore) GetScheduleVersions(org_id uuid.UUID, limit int) ([]ScheduleVersion, error) {
+	rows, err := s.DB.Query(`
+		SELECT `+scheduleVersionColumns+`
+		FROM schedule_versions
+		WHERE organization_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2
+	`, org_id, limit)
+	if err != nil {
+		s.Logger.Error("failed to get schedule versions", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []ScheduleVersion{}
+	for rows.Next() {
+		version, err := scanScheduleVersion(rows)
+		if err != nil {
+			s.Logger.Error("failed to scan schedule version", "error", err)
+			return nil, err
+		}
+		versions = append(versions, *version)
+	}
+	return versions, rows.Err()
+}
+
+// GetScheduleVersion returns a generated schedule of the organization with the satisfaction of each
+// employee, least satisfied first, or sql.ErrNoRows
+func (s *PostgresScheduleVersionStore) GetScheduleVersion(org_id, version_id uuid.UUID) (*ScheduleVersion, error) {
+	row := s.DB.QueryRow(`
+		SELECT `+scheduleVersionColumns+`
+		FROM schedule_versions
+		WHERE organization_id = $1 AND id = $2
+	`, org_id, version_id)
+	version, err := scanScheduleVersion(row)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.Logger.Error("failed to get schedule version", "error", err, "version_id", version_id)
+		}
+		return nil, err
+	}
+
+	rows, err := s.DB.Query(`
+		SELECT e.employee_id, u.full_name, e.shifts, e.preferred_shifts, e.satisfaction_percent
+		FROM schedule_version_employees e
+		INNER JOIN users u ON e.employee_id = u.id
+		WHERE e.version_id = $1
+		ORDER BY e.satisfaction_percent NULLS LAST, u.full_name
+	`, version_id)
+	if err != nil {
+		s.Logger.Error("failed to get employee satisfaction", "error", err, "version_id", version_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	version.Employees = []EmployeeSatisfaction{}
+	for rows.Next() {
+		var employee EmployeeSatisfaction
+		if err := rows.Scan(&employee.EmployeeID, &employee.EmployeeName, &employee.Shifts, &employee.PreferredShifts,
+			&employee.SatisfactionPercent); err != nil {
+			s.Logger.Error("failed to scan employee satisfaction", "error", err)
+			return nil, err
+		}
+		version.Employees = append(version.Employees, employee)
+	}
+	return version, rows.Err()
+}
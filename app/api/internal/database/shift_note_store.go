@@ -0,0 +1,122 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of shift notes
+const (
+	ShiftNoteGeneral  = "note"
+	ShiftNoteLowStock = "low_stock"
+)
+
+// IsShiftNoteKind reports whether kind is a general note or a low stock alert
+func IsShiftNoteKind(kind string) bool {
+	return kind == ShiftNoteGeneral || kind == ShiftNoteLowStock
+}
+
+// ShiftNote is a note left for the next shift, open until resolved. Low stock notes name the item running out.
+type ShiftNote struct {
+	ID            uuid.UUID  `json:"id"`
+	Kind          string     `json:"kind"`
+	ItemID        *uuid.UUID `json:"item_id"`
+	ItemName      *string    `json:"item_name"`
+	Body          string     `json:"body"`
+	CreatedBy     *uuid.UUID `json:"created_by"`
+	CreatedByName *string    `json:"created_by_name"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ResolvedBy    *uuid.UUID `json:"resolved_by,omitempty"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty"`
+}
+
+type ShiftNoteStore interface {
+	CreateShiftNote(org_id uuid.UUID, note *ShiftNote) error
+	GetUnresolvedShiftNotes(org_id uuid.UUID) ([]ShiftNote, error)
+	ResolveShiftNote(org_id, note_id, resolved_by uuid.UUID) error
+}
+
+type PostgresShiftNoteStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresShiftNoteStore(DB *sql.DB, Logger *slog.Logger) *PostgresShiftNoteStore {
+	return &PostgresShiftNoteStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// CreateShiftNote stores a note of the organization. Returns sql.ErrNoRows when the item of the note does
+// not belong to the organization.
+func (s *PostgresShiftNoteStore) CreateShiftNote(org_id uuid.UUID, note *ShiftNote) error {
+	query := `
+		INSERT INTO shift_notes (organization_id, kind, item_id, body, created_by)
+		SELECT $1, $2, $3, $4, $5
+		WHERE $3::UUID IS NULL OR EXISTS (SELECT 1 FROM items WHERE id = $3 AND organization_id = $1)
+		RETURNING id, created_at
+	`
+	err := s.DB.QueryRow(query, org_id, note.Kind, note.ItemID, note.Body, note.CreatedBy).Scan(&note.ID, &note.CreatedAt)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to create shift note", "error", err, "org_id", org_id)
+		}
+		return err
+	}
+	return nil
+}
+
+// GetUnresolvedShiftNotes lists the open notes of the organization, oldest first
+func (s *PostgresShiftNoteStore) GetUnresolvedShiftNotes(org_id uuid.UUID) ([]ShiftNote, error) {
+	query := `
+		SELECT n.id, n.kind, n.item_id, i.name, n.body, n.created_by, u.full_name, n.created_at
+		FROM shift_notes n
+		LEFT JOIN items i ON i.id = n.item_id
+		LEFT JOIN users u ON u.id = n.created_by
+		WHERE n.organization_id = $1 AND n.resolved_at IS NULL
+		ORDER BY n.created_at
+	`
+	rows, err := s.DB.Query(query, org_id)
+	if err != nil {
+		s.Logger.Error("failed to get shift notes", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := []ShiftNote{}
+	for rows.Next() {
+		var note ShiftNote
+		if err := rows.Scan(&note.ID, &note.Kind, &note.ItemID, &note.ItemName, &note.Body, &note.CreatedBy,
+			&note.CreatedByName, &note.CreatedAt); err != nil {
+			s.Logger.Error("failed to scan shift note", "error", err)
+			return nil, err
+		}
+		notes = append(notes, note)
+	}
+	return notes, rows.Err()
+}
+
+// ResolveShiftNote closes an open note, or returns sql.ErrNoRows when the organization has no such open note
+func (s *PostgresShiftNoteStore) ResolveShiftNote(org_id, note_id, resolved_by uuid.UUID) error {
+	query := `
+		UPDATE shift_notes SET resolved_by = $3, resolved_at = NOW()
+		WHERE id = $1 AND organization_id = $2 AND resolved_at IS NULL
+	`
+	result, err := s.DB.Exec(query, note_id, org_id, resolved_by)
+	if err != nil {
+		s.Logger.Error("failed to resolve shift note", "error", err, "note_id", note_id)
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,306 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Kinds of skills employees declare
+const (
+	SkillKindSkill         = "skill"
+	SkillKindCertification = "certification"
+)
+
+// Statuses of a declared skill, only verified skills count towards scheduling
+const (
+	SkillPending  = "pending"
+	SkillVerified = "verified"
+	SkillRejected = "rejected"
+)
+
+// ErrSkillReviewed is returned when a skill was reviewed by someone else in the meantime
+var ErrSkillReviewed = errors.New("skill already reviewed")
+
+// EmployeeSkill is a skill or certification an employee declared. ExpiresOn is the day a certification
+// stops being valid, nil for skills that do not expire.
+type EmployeeSkill struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	EmployeeID     uuid.UUID  `json:"employee_id"`
+	EmployeeName   string     `json:"employee_name"`
+	EmployeeEmail  string     `json:"employee_email"`
+	Name           string     `json:"name"`
+	Kind           string     `json:"kind"`
+	ExpiresOn      *time.Time `json:"expires_on"`
+	Note           *string    `json:"note"`
+	Status         string     `json:"status"`
+	ReviewedBy     *uuid.UUID `json:"reviewed_by"`
+	ReviewedAt     *time.Time `json:"reviewed_at"`
+	ReviewNote     *string    `json:"review_note"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// RoleSkillRequirement lists the skills an employee needs verified to be scheduled in a role
+type RoleSkillRequirement struct {
+	Role   string   `json:"role"`
+	Skills []string `json:"skills"`
+}
+
+type SkillStore interface {
+	ProposeSkill(skill *EmployeeSkill) error
+	GetSkills(org_id uuid.UUID, employee_id *uuid.UUID, status string) ([]EmployeeSkill, error)
+	GetSkill(org_id, skill_id uuid.UUID) (*EmployeeSkill, error)
+	ReviewSkill(skill *EmployeeSkill) error
+	DeleteSkill(employee_id, skill_id uuid.UUID) error
+	GetRoleSkillRequirements(org_id uuid.UUID) ([]RoleSkillRequirement, error)
+	SetRoleSkillRequirement(org_id uuid.UUID, requirement RoleSkillRequirement, updatedBy uuid.UUID) error
+	GetVerifiedSkills(org_id uuid.UUID, on time.Time) (map[uuid.UUID][]string, error)
+	GetExpiringSkills(until time.Time) ([]EmployeeSkill, error)
+	MarkSkillExpiryReminded(skill_id uuid.UUID) error
+}
+
+type PostgresSkillStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresSkillStore(DB *sql.DB, Logger *slog.Logger) *PostgresSkillStore {
+	return &PostgresSkillStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+const employeeSkillColumns = `
+	s.id, s.organization_id, s.employee_id, u.full_name, u.email, s.name, s.kind, s.expires_on, s.note, s.status,
+	s.reviewed_by, s.reviewed_at, s.review_note, s.created_at
+`
+
+func scanEmployeeSkill(row interface{ Scan(...any) error }) (*EmployeeSkill, error) {
+	var skill EmployeeSkill
+	err := row.Scan(&skill.ID, &skill.OrganizationID, &skill.EmployeeID, &skill.EmployeeName, &skill.EmployeeEmail, &skill.Name,
+		&skill.Kind, &skill.ExpiresOn, &skill.Note, &skill.Status, &skill.ReviewedBy, &skill.ReviewedAt, &skill.ReviewNote,
+		&skill.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &skill, nil
+}
+
+// ProposeSkill stores a skill declared by an employee for verification. Declaring a skill the employee
+// already has, whatever the case of its name, replaces it and sends it back for verification.
+func (s *PostgresSkillStore) ProposeSkill(skill *EmployeeSkill) error {
+	query := `
+		INSERT INTO employee_skills (organization_id, employee_id, name, kind, expires_on, note)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (employee_id, (LOWER(name))) DO UPDATE
+		SET name = EXCLUDED.name, kind = EXCLUDED.kind, expires_on = EXCLUDED.expires_on, note = EXCLUDED.note,
+			status = 'pending', reviewed_by = NULL, reviewed_at = NULL, review_note = NULL, expiry_reminded_at = NULL,
+			created_at = NOW()
+		RETURNING id, status, created_at
+	`
+	err := s.DB.QueryRow(query, skill.OrganizationID, skill.EmployeeID, skill.Name, skill.Kind, skill.ExpiresOn, skill.Note).
+		Scan(&skill.ID, &skill.Status, &skill.CreatedAt)
+	if err != nil {
+		s.Logger.Error("failed to propose skill", "error", err, "employee_id", skill.EmployeeID)
+		return err
+	}
+	return nil
+}
+
+// GetSkills lists the skills declared in the organization, of one employee when employee_id is set and
+// with the status when it is not empty, newest first
+func (s *PostgresSkillStore) GetSkills(org_id uuid.UUID, employee_id *uuid.UUID, status string) ([]EmployeeSkill, error) {
+	where, args := Where("s.organization_id = ?", org_id).
+		AndIf(employee_id != nil, "s.employee_id = ?", employee_id).
+		AndIf(status != "", "s.status = ?", status).
+		Build()
+	query := `SELECT ` + employeeSkillColumns + ` FROM employee_skills s JOIN users u ON u.id = s.employee_id
+		WHERE ` + where + ` ORDER BY s.created_at DESC`
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		s.Logger.Error("failed to get skills", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	skills := []EmployeeSkill{}
+	for rows.Next() {
+		skill, err := scanEmployeeSkill(rows)
+		if err != nil {
+			s.Logger.Error("failed to scan skill", "error", err)
+			return nil, err
+		}
+		skills = append(skills, *skill)
+	}
+	return skills, rows.Err()
+}
+
+// GetSkill returns a skill declared in the organization, or sql.ErrNoRows
+func (s *PostgresSkillStore) GetSkill(org_id, skill_id uuid.UUID) (*EmployeeSkill, error) {
+	query := `SELECT ` + employeeSkillColumns + ` FROM employee_skills s JOIN users u ON u.id = s.employee_id
+		WHERE s.id = $1 AND s.organization_id = $2`
+	skill, err := scanEmployeeSkill(s.DB.QueryRow(query, skill_id, org_id))
+	if err != nil && err != sql.ErrNoRows {
+		s.Logger.Error("failed to get skill", "error", err, "skill_id", skill_id)
+	}
+	return skill, err
+}
+
+// ReviewSkill records the status skill.Status given to a pending skill by skill.ReviewedBy. Returns
+// ErrSkillReviewed when it is not pending anymore.
+func (s *PostgresSkillStore) ReviewSkill(skill *EmployeeSkill) error {
+	query := `
+		UPDATE employee_skills
+		SET status = $3, reviewed_by = $4, reviewed_at = NOW(), review_note = $5
+		WHERE id = $1 AND organization_id = $2 AND status = 'pending'
+		RETURNING reviewed_at
+	`
+	err := s.DB.QueryRow(query, skill.ID, skill.OrganizationID, skill.Status, skill.ReviewedBy, skill.ReviewNote).Scan(&skill.ReviewedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrSkillReviewed
+	}
+	if err != nil {
+		s.Logger.Error("failed to review skill", "error", err, "skill_id", skill.ID)
+		return err
+	}
+
+	s.Logger.Info("skill reviewed", "skill_id", skill.ID, "status", skill.Status, "reviewed_by", skill.ReviewedBy)
+	return nil
+}
+
+// DeleteSkill removes a skill of the employee, returning sql.ErrNoRows if they have no such skill
+func (s *PostgresSkillStore) DeleteSkill(employee_id, skill_id uuid.UUID) error {
+	result, err := s.DB.Exec(`DELETE FROM employee_skills WHERE id = $1 AND employee_id = $2`, skill_id, employee_id)
+	if err != nil {
+		s.Logger.Error("failed to delete skill", "error", err, "skill_id", skill_id)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetRoleSkillRequirements lists the roles of the organization that require skills
+func (s *PostgresSkillStore) GetRoleSkillRequirements(org_id uuid.UUID) ([]RoleSkillRequirement, error) {
+	query := `SELECT role, skills FROM role_skill_requirements WHERE organization_id = $1 ORDER BY role`
+	rows, err := s.DB.Query(query, org_id)
+	if err != nil {
+		s.Logger.Error("failed to get role skill requirements", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	requirements := []RoleSkillRequirement{}
+	for rows.Next() {
+		var requirement RoleSkillRequirement
+		var skills pq.StringArray
+		if err := rows.Scan(&requirement.Role, &skills); err != nil {
+			s.Logger.Error("failed to scan role skill requirement", "error", err)
+			return nil, err
+		}
+		requirement.Skills = skills
+		requirements = append(requirements, requirement)
+	}
+	return requirements, rows.Err()
+}
+
+// SetRoleSkillRequirement replaces the skills the role requires, an empty list removes the requirement
+func (s *PostgresSkillStore) SetRoleSkillRequirement(org_id uuid.UUID, requirement RoleSkillRequirement, updatedBy uuid.UUID) error {
+	if len(requirement.Skills) == 0 {
+		query := `DELETE FROM role_skill_requirements WHERE organization_id = $1 AND role = $2`
+		if _, err := s.DB.Exec(query, org_id, requirement.Role); err != nil {
+			s.Logger.Error("failed to remove role skill requirement", "error", err, "org_id", org_id, "role", requirement.Role)
+			return err
+		}
+		return nil
+	}
+
+	query := `
+		INSERT INTO role_skill_requirements (organization_id, role, skills, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (organization_id, role)
+		DO UPDATE SET skills = EXCLUDED.skills, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+	`
+	if _, err := s.DB.Exec(query, org_id, requirement.Role, pq.Array(requirement.Skills), updatedBy); err != nil {
+		s.Logger.Error("failed to set role skill requirement", "error", err, "org_id", org_id, "role", requirement.Role)
+		return err
+	}
+
+	s.Logger.Info("role skill requirement set", "org_id", org_id, "role", requirement.Role, "skills", requirement.Skills)
+	return nil
+}
+
+// GetVerifiedSkills returns the names of the skills of the employees of the organization that are
+// verified and still valid on the day, keyed by employee
+func (s *PostgresSkillStore) GetVerifiedSkills(org_id uuid.UUID, on time.Time) (map[uuid.UUID][]string, error) {
+	query := `
+		SELECT employee_id, name
+		FROM employee_skills
+		WHERE organization_id = $1 AND status = 'verified' AND (expires_on IS NULL OR expires_on >= $2)
+		ORDER BY employee_id, name
+	`
+	rows, err := s.DB.Query(query, org_id, on.Format(time.DateOnly))
+	if err != nil {
+		s.Logger.Error("failed to get verified skills", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	skills := map[uuid.UUID][]string{}
+	for rows.Next() {
+		var employeeID uuid.UUID
+		var name string
+		if err := rows.Scan(&employeeID, &name); err != nil {
+			s.Logger.Error("failed to scan verified skill", "error", err)
+			return nil, err
+		}
+		skills[employeeID] = append(skills[employeeID], name)
+	}
+	return skills, rows.Err()
+}
+
+// GetExpiringSkills lists the verified skills of every organization expiring on or before until, soonest
+// first. Skills whose holder was already reminded are left out.
+func (s *PostgresSkillStore) GetExpiringSkills(until time.Time) ([]EmployeeSkill, error) {
+	query := `SELECT ` + employeeSkillColumns + ` FROM employee_skills s JOIN users u ON u.id = s.employee_id
+		WHERE s.status = 'verified' AND s.expires_on <= $1 AND s.expiry_reminded_at IS NULL
+		ORDER BY s.expires_on, s.id`
+	rows, err := s.DB.Query(query, until.Format(time.DateOnly))
+	if err != nil {
+		s.Logger.Error("failed to get expiring skills", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	skills := []EmployeeSkill{}
+	for rows.Next() {
+		skill, err := scanEmployeeSkill(rows)
+		if err != nil {
+			s.Logger.Error("failed to scan expiring skill", "error", err)
+			return nil, err
+		}
+		skills = append(skills, *skill)
+	}
+	return skills, rows.Err()
+}
+
+// MarkSkillExpiryReminded records that the holder of the skill was reminded of its expiry
+func (s *PostgresSkillStore) MarkSkillExpiryReminded(skill_id uuid.UUID) error {
+	if _, err := s.DB.Exec(`UPDATE employee_skills SET expiry_reminded_at = NOW() WHERE id = $1`, skill_id); err != nil {
+		s.Logger.Error("failed to mark skill expiry reminded", "error", err, "skill_id", skill_id)
+		return err
+	}
+	return nil
+}
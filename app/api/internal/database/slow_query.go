@@ -0,0 +1,197 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxLoggedQueryLength truncates the queries written to the slow query log
+const maxLoggedQueryLength = 500
+
+// SlowQueryLog logs the queries of a connection pool that take longer than Threshold, with the store
+// method that ran them and their sanitized arguments, and counts them for the health check
+type SlowQueryLog struct {
+	Threshold time.Duration
+	Logger    *slog.Logger
+
+	count   atomic.Int64
+	slowest atomic.Int64 // nanoseconds
+}
+
+// SlowQueryStats are the slow queries seen since the API started
+type SlowQueryStats struct {
+	Count   int64
+	Slowest time.Duration
+}
+
+// Stats returns the number of slow queries and the slowest duration seen
+func (l *SlowQueryLog) Stats() SlowQueryStats {
+	return SlowQueryStats{Count: l.count.Load(), Slowest: time.Duration(l.slowest.Load())}
+}
+
+// Connector wraps a driver connector so the queries of its connections go through the log
+func (l *SlowQueryLog) Connector(connector driver.Connector) driver.Connector {
+	return &slowQueryConnector{Connector: connector, log: l}
+}
+
+func (l *SlowQueryLog) observe(query string, args []driver.NamedValue, elapsed time.Duration) {
+	if elapsed < l.Threshold {
+		return
+	}
+	l.count.Add(1)
+	for {
+		slowest := l.slowest.Load()
+		if int64(elapsed) <= slowest || l.slowest.CompareAndSwap(slowest, int64(elapsed)) {
+			break
+		}
+	}
+
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > maxLoggedQueryLength {
+		query = query[:maxLoggedQueryLength] + "..."
+	}
+	l.Logger.Warn("slow query", "duration_ms", elapsed.Milliseconds(), "caller", queryCaller(),
+		"query", query, "args", SanitizeQueryArgs(args))
+}
+
+// SanitizeQueryArgs keeps the arguments that identify rows (numbers, booleans, times and UUIDs) and
+// redacts the others, which may hold personal data, password hashes or encrypted values
+func SanitizeQueryArgs(args []driver.NamedValue) []any {
+	sanitized := make([]any, len(args))
+	for i, arg := range args {
+		sanitized[i] = sanitizeQueryArg(arg.Value)
+	}
+	return sanitized
+}
+
+func sanitizeQueryArg(value any) any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case uuid.UUID:
+		return v.String()
+	case time.Time:
+		return v
+	case string:
+		if _, err := uuid.Parse(v); err == nil {
+			return v
+		}
+		return fmt.Sprintf("<redacted string, %d chars>", len(v))
+	case []byte:
+		return fmt.Sprintf("<redacted %d bytes>", len(v))
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil
+		}
+		return sanitizeQueryArg(rv.Elem().Interface())
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return value
+	}
+	return fmt.Sprintf("<redacted %T>", value)
+}
+
+// queryCaller returns the first function outside database/sql and this file, usually a store method
+func queryCaller() string {
+	pcs := make([]uintptr, 20)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "database/sql.") && !strings.HasSuffix(frame.File, "/slow_query.go") {
+			return frame.Function
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+type slowQueryConnector struct {
+	driver.Connector
+	log *SlowQueryLog
+}
+
+func (c *slowQueryConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &slowQueryConn{Conn: conn, log: c.log}, nil
+}
+
+// slowQueryConn times the queries and statements of a connection, passing everything else through to it
+type slowQueryConn struct {
+	driver.Conn
+	log *SlowQueryLog
+}
+
+func (c *slowQueryConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	c.log.observe(query, args, time.Since(start))
+	return result, err
+}
+
+// QueryContext times the query until its first rows are ready, which is when slow queries stall
+func (c *slowQueryConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	c.log.observe(query, args, time.Since(start))
+	return rows, err
+}
+
+func (c *slowQueryConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *slowQueryConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *slowQueryConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *slowQueryConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+func (c *slowQueryConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of staff orders
+const (
+	StaffOrderMeal     = "staff_meal"
+	StaffOrderDiscount = "employee_discount"
+)
+
+// IsStaffOrderKind reports whether kind is a staff meal or an employee discount
+func IsStaffOrderKind(kind string) bool {
+	return kind == StaffOrderMeal || kind == StaffOrderDiscount
+}
+
+// staffOrderExcluded leaves the staff orders out of a query on orders o, for revenue figures
+const staffOrderExcluded = `NOT EXISTS (SELECT 1 FROM staff_orders so WHERE so.order_id = o.id)`
+
+// StaffOrder tags an order eaten by an employee, a free staff meal or an order with an employee discount
+type StaffOrder struct {
+	OrderID    uuid.UUID  `json:"order_id"`
+	EmployeeID uuid.UUID  `json:"employee_id"`
+	Kind       string     `json:"kind"`
+	TaggedBy   *uuid.UUID `json:"tagged_by"`
+	TaggedAt   time.Time  `json:"tagged_at"`
+}
+
+// StaffMealConsumption is what an employee ate over a period. Value is the menu value of the orders, the
+// total amount, and Benefit the part the employee did not pay, the discount amount. TotalBenefit is what
+// payroll deduction policies apply to.
+type StaffMealConsumption struct {
+	EmployeeID       uuid.UUID `json:"employee_id"`
+	FullName         string    `json:"full_name"`
+	StaffMeals       int       `json:"staff_meals"`
+	StaffMealValue   float64   `json:"staff_meal_value"`
+	StaffMealBenefit float64   `json:"staff_meal_benefit"`
+	DiscountedOrders int       `json:"discounted_orders"`
+	DiscountedValue  float64   `json:"discounted_value"`
+	DiscountBenefit  float64   `json:"discount_benefit"`
+	TotalBenefit     float64   `json:"total_benefit"`
+}
+
+type StaffOrderStore interface {
+	TagStaffOrder(org_id uuid.UUID, tag *StaffOrder) error
+	UntagStaffOrder(org_id, order_id uuid.UUID) error
+	GetStaffMealConsumption(org_id uuid.UUID, from, to time.Time) ([]StaffMealConsumption, error)
+}
+
+type PostgresStaffOrderStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresStaffOrderStore(DB *sql.DB, Logger *slog.Logger) *PostgresStaffOrderStore {
+	return &PostgresStaffOrderStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// TagStaffOrder tags an order of the organization as eaten by the employee, replacing an earlier tag.
+// Returns sql.ErrNoRows when the order is not one of the organization.
+func (s *PostgresStaffOrderStore) TagStaffOrder(org_id uuid.UUID, tag *StaffOrder) error {
+	query := `
+		INSERT INTO staff_orders (order_id, organization_id, employee_id, kind, tagged_by)
+		SELECT id, organization_id, $3, $4, $5
+		FROM orders
+		WHERE id = $1 AND organization_id = $2
+		ON CONFLICT (order_id) DO UPDATE
+		SET employee_id = EXCLUDED.employee_id, kind = EXCLUDED.kind, tagged_by = EXCLUDED.tagged_by, tagged_at = NOW()
+		RETURNING tagged_at
+	`
+	err := s.DB.QueryRow(query, tag.OrderID, org_id, tag.EmployeeID, tag.Kind, tag.TaggedBy).Scan(&tag.TaggedAt)
+	if err != nil && err != sql.ErrNoRows {
+		s.Logger.Error("failed to tag staff order", "error", err, "order_id", tag.OrderID)
+	}
+	return err
+}
+
+// UntagStaffOrder counts the order as a customer order again, sql.ErrNoRows when it was not tagged
+func (s *PostgresStaffOrderStore) UntagStaffOrder(org_id, order_id uuid.UUID) error {
+	result, err := s.DB.Exec(`DELETE FROM staff_orders WHERE order_id = $1 AND organization_id = $2`, order_id, org_id)
+	if err != nil {
+		s.Logger.Error("failed to untag staff order", "error", err, "order_id", order_id)
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetStaffMealConsumption sums the staff orders created in [from, to) per employee, by name
+func (s *PostgresStaffOrderStore) GetStaffMealConsumption(org_id uuid.UUID, from, to time.Time) ([]StaffMealConsumption, error) {
+	query := `
+		SELECT so.employee_id, u.full_name,
+			COUNT(*) FILTER (WHERE so.kind = 'staff_meal'),
+			COALESCE(SUM(o.total_amount) FILTER (WHERE so.kind = 'staff_meal'), 0),
+			COALESCE(SUM(o.discount_amount) FILTER (WHERE so.kind = 'staff_meal'), 0),
+			COUNT(*) FILTER (WHERE so.kind = 'employee_discount'),
+			COALESCE(SUM(o.total_amount) FILTER (WHERE so.kind = 'employee_discount'), 0),
+			COALESCE(SUM(o.discount_amount) FILTER (WHERE so.kind = 'employee_discount'), 0)
+		FROM staff_orders so
+		JOIN orders o ON o.id = so.order_id
+		JOIN users u ON u.id = so.employee_id
+		WHERE so.organization_id = $1 AND o.create_time >= $2 AND o.create_time < $3
+		GROUP BY so.employee_id, u.full_name
+		ORDER BY u.full_name, so.employee_id
+	`
+	rows, err := s.DB.Query(query, org_id, from, to)
+	if err != nil {
+		s.Logger.Error("failed to get staff meal consumption", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	consumption := []StaffMealConsumption{}
+	for rows.Next() {
+		var c StaffMealConsumption
+		if err := rows.Scan(&c.EmployeeID, &c.FullName, &c.StaffMeals, &c.StaffMealValue, &c.StaffMealBenefit,
+			&c.DiscountedOrders, &c.DiscountedValue, &c.DiscountBenefit); err != nil {
+			s.Logger.Error("failed to scan staff meal consumption", "error", err)
+			return nil, err
+		}
+		c.TotalBenefit = c.StaffMealBenefit + c.DiscountBenefit
+		consumption = append(consumption, c)
+	}
+	return consumption, rows.Err()
+}
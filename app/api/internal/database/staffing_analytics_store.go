@@ -0,0 +1,246 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StaffingVsDemand lines up the demand forecast, the orders received and the employees scheduled over the
+// slots of a day for charting. The arrays hold a value per slot, in the order of Slots.
+type StaffingVsDemand struct {
+	Date            time.Time `json:"date"`
+	SlotMinutes     int       `json:"slot_minutes"`
+	Slots           []string  `json:"slots"`
+	PredictedOrders []float64 `json:"predicted_orders"`
+	ActualOrders    []int     `json:"actual_orders"`
+	ScheduledStaff  []int     `json:"scheduled_staff"`
+	ComputedAt      time.Time `json:"computed_at"`
+}
+
+// newHireRetentionDays is how long new hires must stay to count as retained
+const newHireRetentionDays = 90
+
+// TurnoverQuarter is the headcount changes and exits of a quarter. Rates are percentages, nil when there
+// was no one to compute them from.
+type TurnoverQuarter struct {
+	Quarter          string    `json:"quarter"`
+	Start            time.Time `json:"start"`
+	End              time.Time `json:"end"`
+	HeadcountStart   int       `json:"headcount_start"`
+	HeadcountEnd     int       `json:"headcount_end"`
+	NetChange        int       `json:"net_change"`
+	Hires            int       `json:"hires"`
+	VoluntaryExits   int       `json:"voluntary_exits"`
+	InvoluntaryExits int       `json:"involuntary_exits"`
+	// TurnoverRate is the exits against the average of the headcounts at the start and the end
+	TurnoverRate *float64 `json:"turnover_rate"`
+	// AverageTenureDays is the time the employees leaving in the quarter had worked, when their hire date is known
+	AverageTenureDays *float64 `json:"average_tenure_days"`
+	// NewHireRetention is the share of the hires of the quarter still employed 90 days later, over the hires
+	// whose 90 days are over
+	NewHireRetention *float64 `json:"new_hire_retention"`
+	RetentionCohort  int      `json:"retention_cohort"`
+}
+
+type StaffingAnalyticsStore interface {
+	GetStaffingVsDemand(org_id uuid.UUID, date time.Time, slotMinutes int) (*StaffingVsDemand, error)
+	GetTurnover(org_id uuid.UUID, from time.Time, quarters int) ([]TurnoverQuarter, error)
+}
+
+type PostgresStaffingAnalyticsStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresStaffingAnalyticsStore(DB *sql.DB, Logger *slog.Logger) *PostgresStaffingAnalyticsStore {
+	return &PostgresStaffingAnalyticsStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// GetStaffingVsDemand splits the day into slots of slotMinutes, which must divide the day. The hourly
+// forecast is shared between the slots overlapping each hour, and an employee counts in every slot their
+// working shift overlaps.
+func (s *PostgresStaffingAnalyticsStore) GetStaffingVsDemand(org_id uuid.UUID, date time.Time, slotMinutes int) (*StaffingVsDemand, error) {
+	query := `
+		WITH slots AS (
+			SELECT
+				slot,
+				$2::DATE + make_interval(mins => slot * $3) AS slot_start,
+				$2::DATE + make_interval(mins => (slot + 1) * $3) AS slot_end
+			FROM generate_series(0, 1440 / $3 - 1) AS slot
+		)
+		SELECT
+			sl.slot_start,
+			COALESCE((
+				SELECT SUM(d.order_count * GREATEST(0, LEAST(d.hour * 60 + 60, (sl.slot + 1) * $3) - GREATEST(d.hour * 60, sl.slot * $3)) / 60.0)
+				FROM demand d
+				WHERE d.organization_id = $1 AND d.demand_date = $2::DATE
+			), 0),
+			(
+				SELECT COUNT(*)
+				FROM orders o
+				WHERE o.organization_id = $1 AND o.create_time >= sl.slot_start AND o.create_time < sl.slot_end
+			),
+			(
+				SELECT COUNT(DISTINCT s.employee_id)
+				FROM schedules s
+				JOIN users u ON u.id = s.employee_id
+				WHERE u.organization_id = $1 AND s.shift_type = 'working'
+				AND (s.schedule_date + s.start_hour) < sl.slot_end
+				AND (s.schedule_date + s.end_hour) > sl.slot_start
+			)
+		FROM slots sl
+		ORDER BY sl.slot
+	`
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	rows, err := s.DB.Query(query, org_id, day.Format(time.DateOnly), slotMinutes)
+	if err != nil {
+		s.Logger.Error("failed to get staffing vs demand", "error", err, "organization_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := &StaffingVsDemand{
+		Date:            day,
+		SlotMinutes:     slotMinutes,
+		Slots:           []string{},
+		PredictedOrders: []float64{},
+		ActualOrders:    []int{},
+		ScheduledStaff:  []int{},
+		ComputedAt:      time.Now(),
+	}
+	for rows.Next() {
+		var slotStart time.Time
+		var predicted float64
+		var actual, staff int
+		if err := rows.Scan(&slotStart, &predicted, &actual, &staff); err != nil {
+			s.Logger.Error("failed to scan staffing vs demand row", "error", err)
+			return nil, err
+		}
+		result.Slots = append(result.Slots, slotStart.Format("15:04"))
+		result.PredictedOrders = append(result.PredictedOrders, math.Round(predicted*100)/100)
+		result.ActualOrders = append(result.ActualOrders, actual)
+		result.ScheduledStaff = append(result.ScheduledStaff, staff)
+	}
+	return result, rows.Err()
+}
+
+// employment is the hire and exit of an employee, the hire date is unknown for the exits recorded before
+// it was kept
+type employment struct {
+	hiredAt   *time.Time
+	exitedAt  *time.Time
+	voluntary bool
+}
+
+// employedAt reports whether the employee worked for the organization at t
+func (e employment) employedAt(t time.Time) bool {
+	return (e.hiredAt == nil || e.hiredAt.Before(t)) && (e.exitedAt == nil || !e.exitedAt.Before(t))
+}
+
+// GetTurnover reports quarters consecutive quarters starting with the quarter from starts. Employees are
+// the users other than admins. An employee leaves when their resignation is accepted, voluntarily, or when
+// they are laid off, involuntarily. The employees already removed are read from the layoffs.
+func (s *PostgresStaffingAnalyticsStore) GetTurnover(org_id uuid.UUID, from time.Time, quarters int) ([]TurnoverQuarter, error) {
+	query := `
+		SELECT u.created_at, resigned.at, resigned.at IS NOT NULL
+		FROM users u
+		LEFT JOIN LATERAL (
+			SELECT MIN(r.updated_at) AS at
+			FROM requests r
+			WHERE r.employee_id = u.id AND r.type = 'resign' AND r.status = 'accepted'
+		) resigned ON TRUE
+		WHERE u.organization_id = $1 AND u.user_role != 'admin'
+		UNION ALL
+		SELECT hired_at, action_date, action = 'resignation'
+		FROM layoffs_hirings
+		WHERE organization_id = $1 AND action IN ('layoff', 'resignation')
+	`
+	rows, err := s.DB.Query(query, org_id)
+	if err != nil {
+		s.Logger.Error("failed to get turnover", "error", err, "organization_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var employments []employment
+	for rows.Next() {
+		var e employment
+		if err := rows.Scan(&e.hiredAt, &e.exitedAt, &e.voluntary); err != nil {
+			s.Logger.Error("failed to scan turnover row", "error", err)
+			return nil, err
+		}
+		employments = append(employments, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	start := time.Date(from.Year(), from.Month()-(from.Month()-1)%3, 1, 0, 0, 0, 0, from.Location())
+	result := make([]TurnoverQuarter, 0, quarters)
+	for range quarters {
+		end := start.AddDate(0, 3, 0)
+		quarter := TurnoverQuarter{
+			Quarter: fmt.Sprintf("%d-Q%d", start.Year(), (int(start.Month())+2)/3),
+			Start:   start,
+			End:     end,
+		}
+
+		var tenureDays float64
+		var tenures, retained int
+		for _, e := range employments {
+			if e.employedAt(start) {
+				quarter.HeadcountStart++
+			}
+			if e.employedAt(end) {
+				quarter.HeadcountEnd++
+			}
+			if e.exitedAt != nil && !e.exitedAt.Before(start) && e.exitedAt.Before(end) {
+				if e.voluntary {
+					quarter.VoluntaryExits++
+				} else {
+					quarter.InvoluntaryExits++
+				}
+				if e.hiredAt != nil {
+					tenureDays += e.exitedAt.Sub(*e.hiredAt).Hours() / 24
+					tenures++
+				}
+			}
+			if e.hiredAt != nil && !e.hiredAt.Before(start) && e.hiredAt.Before(end) {
+				quarter.Hires++
+				if mark := e.hiredAt.AddDate(0, 0, newHireRetentionDays); !mark.After(now) {
+					quarter.RetentionCohort++
+					if e.exitedAt == nil || !e.exitedAt.Before(mark) {
+						retained++
+					}
+				}
+			}
+		}
+
+		quarter.NetChange = quarter.HeadcountEnd - quarter.HeadcountStart
+		if average := float64(quarter.HeadcountStart+quarter.HeadcountEnd) / 2; average > 0 {
+			rate := math.Round(float64(quarter.VoluntaryExits+quarter.InvoluntaryExits)/average*10000) / 100
+			quarter.TurnoverRate = &rate
+		}
+		if tenures > 0 {
+			average := math.Round(tenureDays/float64(tenures)*10) / 10
+			quarter.AverageTenureDays = &average
+		}
+		if quarter.RetentionCohort > 0 {
+			retention := math.Round(float64(retained)/float64(quarter.RetentionCohort)*10000) / 100
+			quarter.NewHireRetention = &retention
+		}
+
+		result = append(result, quarter)
+		start = end
+	}
+	return result, nil
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StatusBoard is a wall display of an organization, such as a breakroom TV. It reads the schedule of the
+// day and the live order counts with a token that only works for its own organization, until revoked.
+type StatusBoard struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	Name           string     `json:"name"`
+	CreatedBy      *uuid.UUID `json:"created_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastSeenAt     *time.Time `json:"last_seen_at"`
+	RevokedAt      *time.Time `json:"revoked_at"`
+}
+
+type StatusBoardStore interface {
+	CreateBoard(org_id uuid.UUID, board *StatusBoard, token string) error
+	ListBoards(org_id uuid.UUID) ([]StatusBoard, error)
+	RevokeBoard(org_id, board_id uuid.UUID) error
+	GetBoardByToken(token string) (*StatusBoard, error)
+}
+
+type PostgresStatusBoardStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresStatusBoardStore(DB *sql.DB, Logger *slog.Logger) *PostgresStatusBoardStore {
+	return &PostgresStatusBoardStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// CreateBoard registers a status board authenticating with token
+func (s *PostgresStatusBoardStore) CreateBoard(org_id uuid.UUID, board *StatusBoard, token string) error {
+	query := `
+		INSERT INTO status_boards (organization_id, name, token_hash, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	board.OrganizationID = org_id
+	err := s.DB.QueryRow(query, org_id, board.Name, HashSignToken(token), board.CreatedBy).Scan(&board.ID, &board.CreatedAt)
+	if err != nil {
+		s.Logger.Error("failed to create status board", "error", err, "org_id", org_id)
+		return err
+	}
+	return nil
+}
+
+// ListBoards lists the status boards of the organization, revoked ones included, newest first
+func (s *PostgresStatusBoardStore) ListBoards(org_id uuid.UUID) ([]StatusBoard, error) {
+	query := `
+		SELECT id, organization_id, name, created_by, created_at, last_seen_at, revoked_at
+		FROM status_boards
+		WHERE organization_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := s.DB.Query(query, org_id)
+	if err != nil {
+		s.Logger.Error("failed to list status boards", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	boards := []StatusBoard{}
+	for rows.Next() {
+		var board StatusBoard
+		if err := rows.Scan(&board.ID, &board.OrganizationID, &board.Name, &board.CreatedBy, &board.CreatedAt,
+			&board.LastSeenAt, &board.RevokedAt); err != nil {
+			s.Logger.Error("failed to scan status board", "error", err)
+			return nil, err
+		}
+		boards = append(boards, board)
+	}
+	return boards, rows.Err()
+}
+
+// RevokeBoard stops a board from reading, returning sql.ErrNoRows if the organization has no such active board
+func (s *PostgresStatusBoardStore) RevokeBoard(org_id, board_id uuid.UUID) error {
+	query := `UPDATE status_boards SET revoked_at = NOW() WHERE id = $1 AND organization_id = $2 AND revoked_at IS NULL`
+	result, err := s.DB.Exec(query, board_id, org_id)
+	if err != nil {
+		s.Logger.Error("failed to revoke status board", "error", err, "board_id", board_id)
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetBoardByToken retrieves the active board authenticating with token and records that it was seen,
+// returning sql.ErrNoRows for unknown and revoked tokens
+func (s *PostgresStatusBoardStore) GetBoardByToken(token string) (*StatusBoard, error) {
+	query := `
+		UPDATE status_boards SET last_seen_at = NOW()
+		WHERE token_hash = $1 AND revoked_at IS NULL
+		RETURNING id, organization_id, name, created_by, created_at, last_seen_at, revoked_at
+	`
+	var board StatusBoard
+	err := s.DB.QueryRow(query, HashSignToken(token)).Scan(&board.ID, &board.OrganizationID, &board.Name,
+		&board.CreatedBy, &board.CreatedAt, &board.LastSeenAt, &board.RevokedAt)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get status board", "error", err)
+		}
+		return nil, err
+	}
+	return &board, nil
+}
@@ -0,0 +1,132 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of status events
+const (
+	StatusEventIngestion          = "ingestion"
+	StatusEventScheduleGeneration = "schedule_generation"
+	StatusEventEmailFailure       = "email_failure"
+	StatusEventMLRequest          = "ml_request"
+)
+
+// StatusEvent is one health signal of the API for an organization. DurationMs holds the ingestion lag
+// for ingestions and the response time for ML requests.
+type StatusEvent struct {
+	ID         uuid.UUID `json:"id"`
+	Kind       string    `json:"kind"`
+	Source     string    `json:"source"`
+	DurationMs *int64    `json:"duration_ms,omitempty"`
+	Failed     bool      `json:"failed"`
+	Detail     *string   `json:"detail,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// MLLatency summarizes the ML requests of an organization over a window
+type MLLatency struct {
+	Requests int     `json:"requests"`
+	Failures int     `json:"failures"`
+	AvgMs    float64 `json:"avg_ms"`
+	P95Ms    float64 `json:"p95_ms"`
+	MaxMs    int64   `json:"max_ms"`
+}
+
+type StatusStore interface {
+	RecordEvent(org_id uuid.UUID, event *StatusEvent) error
+	GetLatestEvent(org_id uuid.UUID, kind string) (*StatusEvent, error)
+	GetMLLatency(org_id uuid.UUID, since time.Time) (*MLLatency, error)
+}
+
+type PostgresStatusStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresStatusStore(DB *sql.DB, Logger *slog.Logger) *PostgresStatusStore {
+	return &PostgresStatusStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+func (s *PostgresStatusStore) RecordEvent(org_id uuid.UUID, event *StatusEvent) error {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO org_status_events (id, organization_id, kind, source, duration_ms, failed, detail, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := s.DB.Exec(query, event.ID, org_id, event.Kind, event.Source, event.DurationMs, event.Failed, event.Detail, event.CreatedAt)
+	if err != nil {
+		s.Logger.Error("failed to record status event", "error", err, "org_id", org_id, "kind", event.Kind)
+		return err
+	}
+	return nil
+}
+
+// GetLatestEvent returns the most recent event of the kind, or nil when there is none
+func (s *PostgresStatusStore) GetLatestEvent(org_id uuid.UUID, kind string) (*StatusEvent, error) {
+	query := `
+		SELECT id, kind, source, duration_ms, failed, detail, created_at
+		FROM org_status_events
+		WHERE organization_id = $1 AND kind = $2
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+	var event StatusEvent
+	err := s.DB.QueryRow(query, org_id, kind).Scan(
+		&event.ID,
+		&event.Kind,
+		&event.Source,
+		&event.DurationMs,
+		&event.Failed,
+		&event.Detail,
+		&event.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		s.Logger.Error("failed to get latest status event", "error", err, "org_id", org_id, "kind", kind)
+		return nil, err
+	}
+	return &event, nil
+}
+
+// GetMLLatency summarizes the response times of the ML requests made since the given time
+func (s *PostgresStatusStore) GetMLLatency(org_id uuid.UUID, since time.Time) (*MLLatency, error) {
+	query := `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE failed),
+			COALESCE(AVG(duration_ms), 0),
+			COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY duration_ms), 0),
+			COALESCE(MAX(duration_ms), 0)
+		FROM org_status_events
+		WHERE organization_id = $1 AND kind = $2 AND created_at >= $3
+	`
+	var latency MLLatency
+	err := s.DB.QueryRow(query, org_id, StatusEventMLRequest, since).Scan(
+		&latency.Requests,
+		&latency.Failures,
+		&latency.AvgMs,
+		&latency.P95Ms,
+		&latency.MaxMs,
+	)
+	if err != nil {
+		s.Logger.Error("failed to get ML latency", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	return &latency, nil
+}
@@ -0,0 +1,224 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var announcementColumns = []string{"id", "organization_id", "author_id", "full_name", "title", "message", "pinned", "target_roles", "expires_at", "created_at"}
+
+func TestCreateAnnouncement(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresAnnouncementStore(db, logger)
+
+	orgID := uuid.New()
+	authorID := uuid.New()
+	query := regexp.QuoteMeta(`INSERT INTO announcements (id, organization_id, author_id, title, message, pinned, target_roles, expires_at, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`)
+
+	t.Run("Success", func(t *testing.T) {
+		announcement := &database.Announcement{
+			OrganizationID: orgID,
+			AuthorID:       &authorID,
+			Title:          "Menu change",
+			Message:        "New menu starts Monday",
+			Pinned:         true,
+			TargetRoles:    []string{"cook", "server"},
+		}
+		mock.ExpectExec(query).
+			WithArgs(sqlmock.AnyArg(), orgID, &authorID, "Menu change", "New menu starts Monday", true, "{\"cook\",\"server\"}", nil, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := store.CreateAnnouncement(announcement)
+		assert.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, announcement.ID)
+		assert.False(t, announcement.CreatedAt.IsZero())
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectExec(query).WillReturnError(fmt.Errorf("insert failed"))
+
+		err := store.CreateAnnouncement(&database.Announcement{OrganizationID: orgID, Title: "Hi", Message: "Hello"})
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetAnnouncementByID(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresAnnouncementStore(db, logger)
+
+	orgID := uuid.New()
+	id := uuid.New()
+	createdAt := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`FROM announcements a LEFT JOIN users u ON a.author_id = u.id WHERE a.id = $1 AND a.organization_id = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows(announcementColumns).
+			AddRow(id, orgID, nil, "", "Menu change", "New menu", false, "{cook}", nil, createdAt)
+		mock.ExpectQuery(query).WithArgs(id, orgID).WillReturnRows(rows)
+
+		announcement, err := store.GetAnnouncementByID(orgID, id)
+		assert.NoError(t, err)
+		assert.Equal(t, "Menu change", announcement.Title)
+		assert.Nil(t, announcement.AuthorID)
+		assert.Equal(t, []string{"cook"}, announcement.TargetRoles)
+		assert.Nil(t, announcement.ExpiresAt)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(id, orgID).WillReturnError(sql.ErrNoRows)
+
+		announcement, err := store.GetAnnouncementByID(orgID, id)
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.Nil(t, announcement)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetAnnouncementsForOrganization(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresAnnouncementStore(db, logger)
+
+	orgID := uuid.New()
+	authorID := uuid.New()
+	createdAt := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`(SELECT COUNT(*) FROM announcement_reads r WHERE r.announcement_id = a.id) FROM announcements a LEFT JOIN users u ON a.author_id = u.id WHERE a.organization_id = $1 ORDER BY a.pinned DESC, a.created_at DESC`)
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows(append(announcementColumns, "count")).
+			AddRow(uuid.New(), orgID, authorID, "Maya", "Pinned", "Read me", true, "{}", createdAt.AddDate(0, 0, 7), createdAt, 4).
+			AddRow(uuid.New(), orgID, authorID, "Maya", "Older", "Hello", false, "{}", nil, createdAt.AddDate(0, 0, -1), 0)
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnRows(rows)
+
+		announcements, err := store.GetAnnouncementsForOrganization(orgID)
+		assert.NoError(t, err)
+		assert.Len(t, announcements, 2)
+		assert.Equal(t, 4, *announcements[0].ReadCount)
+		assert.Equal(t, authorID, *announcements[0].AuthorID)
+		assert.NotNil(t, announcements[0].ExpiresAt)
+		assert.Equal(t, []string{}, announcements[1].TargetRoles)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnError(fmt.Errorf("db error"))
+
+		announcements, err := store.GetAnnouncementsForOrganization(orgID)
+		assert.Error(t, err)
+		assert.Nil(t, announcements)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetActiveAnnouncementsForUser(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresAnnouncementStore(db, logger)
+
+	orgID := uuid.New()
+	userID := uuid.New()
+	createdAt := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	readAt := createdAt.Add(time.Hour)
+	query := regexp.QuoteMeta(`WHERE a.organization_id = $1 AND (a.expires_at IS NULL OR a.expires_at > NOW()) AND (CARDINALITY(a.target_roles) = 0 OR a.target_roles && $3)`)
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows(append(announcementColumns, "read_at")).
+			AddRow(uuid.New(), orgID, nil, "", "For cooks", "Hello", false, "{cook}", nil, createdAt, readAt).
+			AddRow(uuid.New(), orgID, nil, "", "For everyone", "Hello", false, "{}", nil, createdAt, nil)
+		mock.ExpectQuery(query).WithArgs(orgID, userID, "{\"employee\",\"cook\"}").WillReturnRows(rows)
+
+		announcements, err := store.GetActiveAnnouncementsForUser(orgID, userID, []string{"employee", "cook"})
+		assert.NoError(t, err)
+		assert.Len(t, announcements, 2)
+		assert.Equal(t, readAt, *announcements[0].ReadAt)
+		assert.Nil(t, announcements[1].ReadAt)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("db error"))
+
+		announcements, err := store.GetActiveAnnouncementsForUser(orgID, userID, []string{"employee"})
+		assert.Error(t, err)
+		assert.Nil(t, announcements)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestDeleteAnnouncement(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresAnnouncementStore(db, logger)
+
+	orgID := uuid.New()
+	id := uuid.New()
+	query := regexp.QuoteMeta(`DELETE FROM announcements WHERE id = $1 AND organization_id = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(id, orgID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.DeleteAnnouncement(orgID, id)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(id, orgID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := store.DeleteAnnouncement(orgID, id)
+		assert.Equal(t, sql.ErrNoRows, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestAnnouncementReads(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresAnnouncementStore(db, logger)
+
+	id := uuid.New()
+	userID := uuid.New()
+	readAt := time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)
+	markQuery := regexp.QuoteMeta(`INSERT INTO announcement_reads (announcement_id, user_id) VALUES ($1, $2) ON CONFLICT (announcement_id, user_id) DO NOTHING`)
+	readsQuery := regexp.QuoteMeta(`SELECT r.user_id, u.full_name, r.read_at FROM announcement_reads r INNER JOIN users u ON r.user_id = u.id WHERE r.announcement_id = $1 ORDER BY r.read_at`)
+
+	t.Run("MarkRead", func(t *testing.T) {
+		mock.ExpectExec(markQuery).WithArgs(id, userID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.MarkAnnouncementRead(id, userID)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("MarkRead_DBError", func(t *testing.T) {
+		mock.ExpectExec(markQuery).WithArgs(id, userID).WillReturnError(fmt.Errorf("db error"))
+
+		err := store.MarkAnnouncementRead(id, userID)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("GetReads", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"user_id", "full_name", "read_at"}).AddRow(userID, "Sam", readAt)
+		mock.ExpectQuery(readsQuery).WithArgs(id).WillReturnRows(rows)
+
+		reads, err := store.GetAnnouncementReads(id)
+		assert.NoError(t, err)
+		assert.Len(t, reads, 1)
+		assert.Equal(t, "Sam", reads[0].FullName)
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateSession(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresApplicantSessionStore(db, logger)
+
+	orgID := uuid.New()
+	applicantID := uuid.New()
+	managerID := uuid.New()
+	day := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+	session := &database.ApplicantSession{ApplicantID: applicantID, Kind: "interview", ManagerID: managerID, Date: day, StartTime: "10:00", EndTime: "10:45"}
+	query := regexp.QuoteMeta(`INSERT INTO applicant_sessions (id, organization_id, applicant_id, kind, manager_id, session_date, start_hour, end_hour, location, notes, created_by, created_at)`)
+
+	mock.ExpectExec(query).
+		WithArgs(sqlmock.AnyArg(), orgID, applicantID, "interview", managerID, day, "10:00", "10:45", nil, nil, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, store.CreateSession(orgID, session))
+	assert.NotEqual(t, uuid.Nil, session.ID)
+	AssertExpectations(t, mock)
+}
+
+func TestGetSessionsForSevenDays(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresApplicantSessionStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`s.session_date < CURRENT_DATE + INTERVAL '7 days'`)
+	columns := []string{"id", "applicant_id", "full_name", "email", "kind", "manager_id", "full_name", "session_date", "start_hour", "end_hour", "location", "notes", "created_by", "created_at"}
+
+	t.Run("Success", func(t *testing.T) {
+		managerID := uuid.New()
+		rows := sqlmock.NewRows(columns).
+			AddRow(uuid.New(), uuid.New(), "Jamie Doe", "jamie@example.com", "trial", managerID, "Alex Manager", time.Now(), "10:00:00", "14:00:00", "Kitchen", nil, managerID, time.Now())
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnRows(rows)
+
+		sessions, err := store.GetSessionsForSevenDays(orgID)
+		assert.NoError(t, err)
+		if assert.Len(t, sessions, 1) {
+			assert.Equal(t, "Jamie Doe", sessions[0].ApplicantName)
+			assert.Equal(t, "Alex Manager", sessions[0].ManagerName)
+			assert.Equal(t, "Kitchen", *sessions[0].Location)
+			assert.Equal(t, managerID, *sessions[0].CreatedBy)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnError(fmt.Errorf("db error"))
+
+		sessions, err := store.GetSessionsForSevenDays(orgID)
+		assert.Error(t, err)
+		assert.Nil(t, sessions)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetManagerConflicts(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresApplicantSessionStore(db, logger)
+
+	orgID := uuid.New()
+	managerID := uuid.New()
+	day := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`FROM schedules WHERE employee_id = $1 AND schedule_date = $3 AND start_hour < $5 AND end_hour > $4`)
+
+	t.Run("Conflicts", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"kind", "date", "start_hour", "end_hour"}).
+			AddRow("shift", day, "09:00:00", "13:00:00").
+			AddRow("interview", day, "12:00:00", "12:30:00")
+		mock.ExpectQuery(query).WithArgs(managerID, orgID, day, "10:00", "12:15").WillReturnRows(rows)
+
+		conflicts, err := store.GetManagerConflicts(orgID, managerID, day, "10:00", "12:15")
+		assert.NoError(t, err)
+		if assert.Len(t, conflicts, 2) {
+			assert.Equal(t, "shift", conflicts[0].Kind)
+			assert.Equal(t, "interview", conflicts[1].Kind)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Free", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(managerID, orgID, day, "15:00", "16:00").
+			WillReturnRows(sqlmock.NewRows([]string{"kind", "date", "start_hour", "end_hour"}))
+
+		conflicts, err := store.GetManagerConflicts(orgID, managerID, day, "15:00", "16:00")
+		assert.NoError(t, err)
+		assert.Empty(t, conflicts)
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordAuditEvent(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresAuditStore(db, logger)
+
+	orgID := uuid.New()
+	targetID := uuid.New()
+	query := regexp.QuoteMeta(`INSERT INTO audit_log (id, organization_id, actor_id, target_user_id, action, details, ip_address, created_at)`)
+
+	t.Run("Success", func(t *testing.T) {
+		event := &database.AuditEvent{OrganizationID: orgID, TargetUserID: &targetID, Action: database.AuditLoginFailed, IPAddress: "10.0.0.1"}
+		mock.ExpectExec(query).
+			WithArgs(sqlmock.AnyArg(), orgID, nil, &targetID, database.AuditLoginFailed, []byte(`{}`), "10.0.0.1", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.RecordEvent(event)
+		assert.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, event.ID)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectExec(query).WillReturnError(fmt.Errorf("db error"))
+
+		err := store.RecordEvent(&database.AuditEvent{OrganizationID: orgID, Action: database.AuditLoginFailed})
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetAuditEvents(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresAuditStore(db, logger)
+
+	orgID := uuid.New()
+	actorID := uuid.New()
+	query := regexp.QuoteMeta(`FROM audit_log WHERE organization_id = $1 AND ($2 = '' OR action = $2) ORDER BY created_at DESC LIMIT $3`)
+	columns := []string{"id", "organization_id", "actor_id", "target_user_id", "action", "details", "ip_address", "created_at"}
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows(columns).
+			AddRow(uuid.New(), orgID, actorID, uuid.New(), database.AuditAccountUnlocked, []byte(`{}`), "10.0.0.1", time.Now()).
+			AddRow(uuid.New(), orgID, nil, uuid.New(), database.AuditAccountLocked, []byte(`{"failed_attempts":5}`), "10.0.0.2", time.Now())
+		mock.ExpectQuery(query).WithArgs(orgID, "", 100).WillReturnRows(rows)
+
+		events, err := store.GetEvents(orgID, "", 100)
+		assert.NoError(t, err)
+		assert.Len(t, events, 2)
+		assert.Equal(t, actorID, *events[0].ActorID)
+		assert.Nil(t, events[1].ActorID)
+		assert.Equal(t, float64(5), events[1].Details["failed_attempts"])
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var blackoutColumns = []string{"id", "start_date", "end_date", "reason", "action", "created_by", "created_at"}
+
+func TestCreateBlackout(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresBlackoutStore(db, logger)
+
+	orgID := uuid.New()
+	managerID := uuid.New()
+	query := regexp.QuoteMeta(`INSERT INTO time_off_blackouts (organization_id, start_date, end_date, reason, action, created_by)`)
+	start := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Success_DefaultsToReject", func(t *testing.T) {
+		blackoutID := uuid.New()
+		blackout := &database.TimeOffBlackout{StartDate: start, EndDate: end, Reason: "December peak", CreatedBy: &managerID}
+		mock.ExpectQuery(query).
+			WithArgs(orgID, start, end, "December peak", database.BlackoutReject, &managerID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(blackoutID, time.Now()))
+
+		err := store.CreateBlackout(orgID, blackout)
+		assert.NoError(t, err)
+		assert.Equal(t, blackoutID, blackout.ID)
+		assert.Equal(t, database.BlackoutReject, blackout.Action)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		blackout := &database.TimeOffBlackout{StartDate: start, EndDate: end, Reason: "December peak", Action: database.BlackoutFlag}
+		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("db error"))
+
+		err := store.CreateBlackout(orgID, blackout)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestListBlackouts(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresBlackoutStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`WHERE organization_id = $1 AND end_date >= CURRENT_DATE ORDER BY start_date, end_date`)
+
+	t.Run("Success", func(t *testing.T) {
+		managerID := uuid.New()
+		rows := sqlmock.NewRows(blackoutColumns).
+			AddRow(uuid.New(), time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC), "December peak", "reject", managerID, time.Now()).
+			AddRow(uuid.New(), time.Date(2027, 2, 8, 0, 0, 0, 0, time.UTC), time.Date(2027, 3, 9, 0, 0, 0, 0, time.UTC), "Ramadan nights", "flag", nil, time.Now())
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnRows(rows)
+
+		blackouts, err := store.ListBlackouts(orgID)
+		assert.NoError(t, err)
+		if assert.Len(t, blackouts, 2) {
+			assert.Equal(t, managerID, *blackouts[0].CreatedBy)
+			assert.Equal(t, database.BlackoutFlag, blackouts[1].Action)
+			assert.Nil(t, blackouts[1].CreatedBy)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnError(fmt.Errorf("db error"))
+
+		blackouts, err := store.ListBlackouts(orgID)
+		assert.Error(t, err)
+		assert.Nil(t, blackouts)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestDeleteBlackout(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresBlackoutStore(db, logger)
+
+	orgID := uuid.New()
+	blackoutID := uuid.New()
+	query := regexp.QuoteMeta(`DELETE FROM time_off_blackouts WHERE organization_id = $1 AND id = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID, blackoutID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.DeleteBlackout(orgID, blackoutID)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID, blackoutID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := store.DeleteBlackout(orgID, blackoutID)
+		assert.Equal(t, sql.ErrNoRows, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetOverlappingBlackouts(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresBlackoutStore(db, logger)
+
+	orgID := uuid.New()
+	from := time.Date(2026, 12, 20, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 12, 27, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`WHERE organization_id = $1 AND start_date <= $3 AND end_date >= $2 ORDER BY action = 'reject' DESC, start_date`)
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows(blackoutColumns).
+			AddRow(uuid.New(), time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC), "December peak", "reject", nil, time.Now())
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).WillReturnRows(rows)
+
+		blackouts, err := store.GetOverlappingBlackouts(orgID, from, to)
+		assert.NoError(t, err)
+		if assert.Len(t, blackouts, 1) {
+			assert.Equal(t, "December peak", blackouts[0].Reason)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NoOverlap", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).WillReturnRows(sqlmock.NewRows(blackoutColumns))
+
+		blackouts, err := store.GetOverlappingBlackouts(orgID, from, to)
+		assert.NoError(t, err)
+		assert.Empty(t, blackouts)
+		AssertExpectations(t, mock)
+	})
+}
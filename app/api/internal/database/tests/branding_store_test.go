@@ -0,0 +1,104 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOrganizationLogo(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresBrandingStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`SELECT content_type, data, updated_at FROM organization_logos WHERE organization_id = $1`)
+
+	t.Run("Success", func(t *testing.T) {
+		updatedAt := time.Now()
+		mock.ExpectQuery(query).WithArgs(orgID).
+			WillReturnRows(sqlmock.NewRows([]string{"content_type", "data", "updated_at"}).AddRow("image/png", []byte{0x89, 'P', 'N', 'G'}, updatedAt))
+
+		logo, err := store.GetLogo(orgID)
+		assert.NoError(t, err)
+		assert.Equal(t, "image/png", logo.ContentType)
+		assert.Equal(t, []byte{0x89, 'P', 'N', 'G'}, logo.Data)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NoLogo", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnError(sql.ErrNoRows)
+
+		logo, err := store.GetLogo(orgID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Nil(t, logo)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetLogoUpdatedAt(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresBrandingStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`SELECT updated_at FROM organization_logos WHERE organization_id = $1`)
+
+	t.Run("Success", func(t *testing.T) {
+		updatedAt := time.Date(2025, 10, 9, 8, 0, 0, 0, time.UTC)
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(updatedAt))
+
+		result, err := store.GetLogoUpdatedAt(orgID)
+		assert.NoError(t, err)
+		assert.Equal(t, updatedAt, *result)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NoLogo", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnError(sql.ErrNoRows)
+
+		result, err := store.GetLogoUpdatedAt(orgID)
+		assert.NoError(t, err)
+		assert.Nil(t, result)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnError(fmt.Errorf("connection refused"))
+
+		_, err := store.GetLogoUpdatedAt(orgID)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestSetOrganizationLogo(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresBrandingStore(db, logger)
+
+	orgID := uuid.New()
+	data := []byte{0xff, 0xd8, 0xff}
+	query := regexp.QuoteMeta(`INSERT INTO organization_logos (organization_id, content_type, data, updated_at)`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID, "image/jpeg", data, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.SetLogo(orgID, "image/jpeg", data))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID, "image/jpeg", data, sqlmock.AnyArg()).WillReturnError(fmt.Errorf("connection refused"))
+
+		assert.Error(t, store.SetLogo(orgID, "image/jpeg", data))
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var broadcastRowColumns = []string{"id", "organization_id", "sent_by", "full_name", "title", "message", "channels", "filters", "created_at",
+	"recipients", "pending", "sent", "failed"}
+
+func TestCreateBroadcast(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresBroadcastStore(db, logger)
+
+	orgID, adminID, broadcastID := uuid.New(), uuid.New(), uuid.New()
+	sam, lee := uuid.New(), uuid.New()
+	insertBroadcast := regexp.QuoteMeta(`INSERT INTO notification_broadcasts (organization_id, sent_by, title, message, channels, filters)`)
+	insertDelivery := regexp.QuoteMeta(`INSERT INTO notification_broadcast_deliveries (broadcast_id, user_id, channel) VALUES ($1, $2, $3)`)
+
+	newBroadcast := func() *database.Broadcast {
+		return &database.Broadcast{OrganizationID: orgID, SentBy: &adminID, Title: "Closed tomorrow", Message: "Water outage",
+			Channels: []string{"email", "sms"}, Filters: database.BroadcastFilters{Roles: []string{"bartender"}, ScheduledTomorrow: true}}
+	}
+	deliveries := []database.BroadcastDelivery{{UserID: sam, Channel: "sms"}, {UserID: lee, Channel: "email"}}
+
+	t.Run("Success", func(t *testing.T) {
+		broadcast := newBroadcast()
+		mock.ExpectBegin()
+		mock.ExpectQuery(insertBroadcast).
+			WithArgs(orgID, &adminID, "Closed tomorrow", "Water outage", pq.Array([]string{"email", "sms"}), []byte(`{"roles":["bartender"],"scheduled_tomorrow":true}`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(broadcastID, time.Now()))
+		mock.ExpectExec(insertDelivery).WithArgs(broadcastID, sam, "sms").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(insertDelivery).WithArgs(broadcastID, lee, "email").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := store.CreateBroadcast(broadcast, deliveries)
+		assert.NoError(t, err)
+		assert.Equal(t, broadcastID, broadcast.ID)
+		assert.Equal(t, 2, broadcast.Recipients)
+		assert.Equal(t, 2, broadcast.Pending)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DeliveryError", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(insertBroadcast).WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(broadcastID, time.Now()))
+		mock.ExpectExec(insertDelivery).WillReturnError(fmt.Errorf("db error"))
+		mock.ExpectRollback()
+
+		err := store.CreateBroadcast(newBroadcast(), deliveries)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestSetBroadcastDeliveryStatus(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresBroadcastStore(db, logger)
+
+	broadcastID, userID := uuid.New(), uuid.New()
+	failure := "mailbox full"
+
+	mock.ExpectExec(regexp.QuoteMeta(`WHERE broadcast_id = $1 AND user_id = $2 AND channel = $3`)).
+		WithArgs(broadcastID, userID, "email", database.BroadcastDeliveryFailed, &failure).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := store.SetBroadcastDeliveryStatus(broadcastID, database.BroadcastDelivery{UserID: userID, Channel: "email",
+		Status: database.BroadcastDeliveryFailed, Error: &failure})
+	assert.NoError(t, err)
+	AssertExpectations(t, mock)
+}
+
+func TestGetBroadcasts(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresBroadcastStore(db, logger)
+
+	orgID, adminID, broadcastID := uuid.New(), uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`FROM notification_broadcasts b`)
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows(broadcastRowColumns).AddRow(broadcastID, orgID, adminID, "Admin", "Closed tomorrow", "Water outage",
+			"{email,sms}", []byte(`{"departments":["Bar"]}`), time.Now(), 3, 1, 1, 1)
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnRows(rows)
+
+		broadcasts, err := store.GetBroadcasts(orgID)
+		assert.NoError(t, err)
+		if assert.Len(t, broadcasts, 1) {
+			assert.Equal(t, []string{"email", "sms"}, broadcasts[0].Channels)
+			assert.Equal(t, []string{"Bar"}, broadcasts[0].Filters.Departments)
+			assert.Equal(t, adminID, *broadcasts[0].SentBy)
+			assert.Equal(t, 3, broadcasts[0].Recipients)
+			assert.Equal(t, 1, broadcasts[0].Failed)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`WHERE b.id = $1 AND b.organization_id = $2`)).
+			WithArgs(broadcastID, orgID).WillReturnRows(sqlmock.NewRows(broadcastRowColumns))
+
+		broadcast, err := store.GetBroadcast(orgID, broadcastID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Nil(t, broadcast)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetBroadcastDeliveries(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresBroadcastStore(db, logger)
+
+	broadcastID, userID := uuid.New(), uuid.New()
+
+	rows := sqlmock.NewRows([]string{"user_id", "full_name", "channel", "status", "error", "sent_at"}).
+		AddRow(userID, "Lee", "email", database.BroadcastDeliveryFailed, "mailbox full", nil).
+		AddRow(userID, "Lee", "sms", database.BroadcastDeliverySent, nil, time.Now())
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM notification_broadcast_deliveries d`)).WithArgs(broadcastID).WillReturnRows(rows)
+
+	deliveries, err := store.GetBroadcastDeliveries(broadcastID)
+	assert.NoError(t, err)
+	if assert.Len(t, deliveries, 2) {
+		assert.Equal(t, "mailbox full", *deliveries[0].Error)
+		assert.Nil(t, deliveries[0].SentAt)
+		assert.NotNil(t, deliveries[1].SentAt)
+	}
+	AssertExpectations(t, mock)
+}
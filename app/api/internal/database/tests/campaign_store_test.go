@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/clockwise/clockwise/backend/internal/database"
@@ -28,12 +29,12 @@ func TestStoreCampaign(t *testing.T) {
 		DiscountPercent: func() *float64 { f := 15.0; return &f }(),
 	}
 
-	query := regexp.QuoteMeta(`INSERT INTO marketing_campaigns (id, organization_id, name, status, start_time_date, end_time_date, discount_percent) VALUES ($1, $2, $3, $4, $5, $6, $7)`)
+	query := regexp.QuoteMeta(`INSERT INTO marketing_campaigns (id, organization_id, name, status, start_time_date, end_time_date, discount_percent, channel, redemption_code) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`)
 
 	t.Run("Success", func(t *testing.T) {
 		mock.ExpectBegin()
 		mock.ExpectExec(query).
-			WithArgs(campaignID, orgID, campaign.Name, campaign.Status, campaign.StartTime, campaign.EndTime, campaign.DiscountPercent).
+			WithArgs(campaignID, orgID, campaign.Name, campaign.Status, campaign.StartTime, campaign.EndTime, campaign.DiscountPercent, "in_store", nil).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
 
@@ -105,12 +106,12 @@ func TestGetAllCampaigns(t *testing.T) {
 	orgID := uuid.New()
 	campaignID := uuid.New()
 
-	qCampaigns := regexp.QuoteMeta(`SELECT id, name, status, start_time_date, end_time_date, discount_percent FROM marketing_campaigns WHERE organization_id = $1 ORDER BY start_time_date DESC`)
+	qCampaigns := regexp.QuoteMeta(`SELECT id, name, status, start_time_date, end_time_date, discount_percent, channel, redemption_code FROM marketing_campaigns WHERE organization_id = $1 ORDER BY start_time_date DESC`)
 	qItems := regexp.QuoteMeta(`SELECT i.id, i.name, i.needed_num_to_prepare, i.price FROM items i JOIN campaigns_items ci ON i.id = ci.item_id WHERE ci.campaign_id = $1`)
 
 	t.Run("Success", func(t *testing.T) {
-		campaignRows := sqlmock.NewRows([]string{"id", "name", "status", "start_time_date", "end_time_date", "discount_percent"}).
-			AddRow(campaignID, "Summer Sale", "active", "2024-06-01", "2024-06-30", 15.0)
+		campaignRows := sqlmock.NewRows([]string{"id", "name", "status", "start_time_date", "end_time_date", "discount_percent", "channel", "redemption_code"}).
+			AddRow(campaignID, "Summer Sale", "active", "2024-06-01", "2024-06-30", 15.0, "app", "SUMMER15")
 		mock.ExpectQuery(qCampaigns).WithArgs(orgID).WillReturnRows(campaignRows)
 
 		// Items for this campaign
@@ -122,13 +123,15 @@ func TestGetAllCampaigns(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Len(t, campaigns, 1)
 		assert.Equal(t, "Summer Sale", campaigns[0].Name)
+		assert.Equal(t, "app", campaigns[0].Channel)
+		assert.Equal(t, "SUMMER15", *campaigns[0].RedemptionCode)
 		assert.Len(t, campaigns[0].ItemsIncluded, 1)
 		assert.Equal(t, "Burger", campaigns[0].ItemsIncluded[0].Name)
 		AssertExpectations(t, mock)
 	})
 
 	t.Run("EmptyResult", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "name", "status", "start_time_date", "end_time_date", "discount_percent"})
+		rows := sqlmock.NewRows([]string{"id", "name", "status", "start_time_date", "end_time_date", "discount_percent", "channel", "redemption_code"})
 		mock.ExpectQuery(qCampaigns).WithArgs(orgID).WillReturnRows(rows)
 
 		campaigns, err := store.GetAllCampaigns(orgID)
@@ -155,12 +158,12 @@ func TestGetAllCampaignsFromLastWeek(t *testing.T) {
 	orgID := uuid.New()
 	campaignID := uuid.New()
 
-	qCampaigns := regexp.QuoteMeta(`SELECT id, name, status, start_time_date, end_time_date, discount_percent FROM marketing_campaigns WHERE organization_id = $1 AND start_time_date >= NOW() - INTERVAL '7 days' ORDER BY start_time_date DESC`)
+	qCampaigns := regexp.QuoteMeta(`SELECT id, name, status, start_time_date, end_time_date, discount_percent, channel, redemption_code FROM marketing_campaigns WHERE organization_id = $1 AND start_time_date >= NOW() - INTERVAL '7 days' ORDER BY start_time_date DESC`)
 	qItems := regexp.QuoteMeta(`SELECT i.id, i.name, i.needed_num_to_prepare, i.price FROM items i JOIN campaigns_items ci ON i.id = ci.item_id WHERE ci.campaign_id = $1`)
 
 	t.Run("Success", func(t *testing.T) {
-		campaignRows := sqlmock.NewRows([]string{"id", "name", "status", "start_time_date", "end_time_date", "discount_percent"}).
-			AddRow(campaignID, "Flash Sale", "active", "2024-06-28", "2024-06-30", 20.0)
+		campaignRows := sqlmock.NewRows([]string{"id", "name", "status", "start_time_date", "end_time_date", "discount_percent", "channel", "redemption_code"}).
+			AddRow(campaignID, "Flash Sale", "active", "2024-06-28", "2024-06-30", 20.0, "in_store", nil)
 		mock.ExpectQuery(qCampaigns).WithArgs(orgID).WillReturnRows(campaignRows)
 
 		itemRows := sqlmock.NewRows([]string{"id", "name", "needed_num_to_prepare", "price"})
@@ -260,3 +263,100 @@ func TestGetCampaignInsights(t *testing.T) {
 		AssertExpectations(t, mock)
 	})
 }
+
+func TestRecordRedemption(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresCampaignStore(db, logger)
+
+	orgID := uuid.New()
+	orderID := uuid.New()
+	at := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`INSERT INTO campaign_redemptions (campaign_id, order_id, organization_id, redeemed_at) SELECT id, $3, organization_id, $4 FROM marketing_campaigns WHERE organization_id = $1 AND UPPER(redemption_code) = UPPER($2)`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID, "summer15", orderID, at).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.RecordRedemption(orgID, "summer15", orderID, at)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NoMatchingCampaign", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID, "EXPIRED", orderID, at).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := store.RecordRedemption(orgID, "EXPIRED", orderID, at)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID, "SUMMER15", orderID, at).WillReturnError(fmt.Errorf("db error"))
+
+		err := store.RecordRedemption(orgID, "SUMMER15", orderID, at)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetChannelPerformance(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresCampaignStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`FROM marketing_campaigns mc LEFT JOIN campaign_redemptions cr ON cr.campaign_id = mc.id LEFT JOIN orders o ON o.id = cr.order_id WHERE mc.organization_id = $1 GROUP BY mc.channel`)
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"channel", "campaigns", "redemptions", "revenue", "discount"}).
+			AddRow("app", 2, 30, 540.0, 60.0).
+			AddRow("in_store", 1, 0, 0.0, 0.0)
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnRows(rows)
+
+		channels, err := store.GetChannelPerformance(orgID)
+		assert.NoError(t, err)
+		assert.Equal(t, []database.ChannelPerformance{
+			{Channel: "app", Campaigns: 2, Redemptions: 30, Revenue: 540, Discount: 60},
+			{Channel: "in_store", Campaigns: 1},
+		}, channels)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnError(fmt.Errorf("db error"))
+
+		channels, err := store.GetChannelPerformance(orgID)
+		assert.Error(t, err)
+		assert.Nil(t, channels)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetCampaignPerformance(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresCampaignStore(db, logger)
+
+	orgID := uuid.New()
+	campaignID := uuid.New()
+	query := regexp.QuoteMeta(`WHERE mc.organization_id = $1 AND mc.id = $2 GROUP BY mc.channel`)
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"channel", "redemptions", "revenue", "discount"}).AddRow("app", 12, 216.0, 24.0)
+		mock.ExpectQuery(query).WithArgs(orgID, campaignID).WillReturnRows(rows)
+
+		performance, err := store.GetCampaignPerformance(orgID, campaignID)
+		assert.NoError(t, err)
+		assert.Equal(t, &database.CampaignPerformance{CampaignID: campaignID, Channel: "app", Redemptions: 12, Revenue: 216, Discount: 24}, performance)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, campaignID).WillReturnRows(sqlmock.NewRows([]string{"channel", "redemptions", "revenue", "discount"}))
+
+		performance, err := store.GetCampaignPerformance(orgID, campaignID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Nil(t, performance)
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,166 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListFieldDefinitions(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresCustomFieldStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`FROM custom_field_definitions WHERE organization_id = $1 AND ($2 = '' OR entity_type = $2)`)
+
+	t.Run("Success", func(t *testing.T) {
+		fieldID := uuid.New()
+		rows := sqlmock.NewRows([]string{"id", "entity_type", "key", "label", "field_type", "options", "required", "created_at"}).
+			AddRow(fieldID, "employee", "uniform_size", "Uniform size", "select", "{S,M,L}", true, time.Now())
+		mock.ExpectQuery(query).WithArgs(orgID, "employee").WillReturnRows(rows)
+
+		definitions, err := store.ListFieldDefinitions(orgID, "employee")
+		assert.NoError(t, err)
+		if assert.Len(t, definitions, 1) {
+			assert.Equal(t, fieldID, definitions[0].ID)
+			assert.Equal(t, []string{"S", "M", "L"}, definitions[0].Options)
+			assert.True(t, definitions[0].Required)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, "").WillReturnError(fmt.Errorf("db error"))
+
+		_, err := store.ListFieldDefinitions(orgID, "")
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestCreateFieldDefinition(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresCustomFieldStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`INSERT INTO custom_field_definitions (organization_id, entity_type, key, label, field_type, options, required)`)
+
+	t.Run("Success", func(t *testing.T) {
+		definition := &database.CustomFieldDefinition{EntityType: "item", Key: "vegan", Label: "Vegan", FieldType: "boolean"}
+		fieldID, now := uuid.New(), time.Now()
+		mock.ExpectQuery(query).WithArgs(orgID, "item", "vegan", "Vegan", "boolean", pq.Array([]string{}), false).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(fieldID, now))
+
+		err := store.CreateFieldDefinition(orgID, definition)
+		assert.NoError(t, err)
+		assert.Equal(t, fieldID, definition.ID)
+		assert.Equal(t, []string{}, definition.Options)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Exists", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(sql.ErrNoRows)
+
+		err := store.CreateFieldDefinition(orgID, &database.CustomFieldDefinition{EntityType: "item", Key: "vegan", Label: "Vegan", FieldType: "boolean"})
+		assert.ErrorIs(t, err, database.ErrCustomFieldExists)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestDeleteFieldDefinition(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresCustomFieldStore(db, logger)
+
+	orgID, fieldID := uuid.New(), uuid.New()
+	deleteQuery := regexp.QuoteMeta(`DELETE FROM custom_field_definitions WHERE organization_id = $1 AND id = $2 RETURNING entity_type, key`)
+
+	t.Run("RemovesValues", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(deleteQuery).WithArgs(orgID, fieldID).
+			WillReturnRows(sqlmock.NewRows([]string{"entity_type", "key"}).AddRow("employee", "uniform_size"))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE custom_field_values SET "values" = "values" - $3`)).
+			WithArgs(orgID, "employee", "uniform_size").WillReturnResult(sqlmock.NewResult(0, 4))
+		mock.ExpectCommit()
+
+		assert.NoError(t, store.DeleteFieldDefinition(orgID, fieldID))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(deleteQuery).WithArgs(orgID, fieldID).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		assert.ErrorIs(t, store.DeleteFieldDefinition(orgID, fieldID), sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetCustomFieldValues(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresCustomFieldStore(db, logger)
+
+	orgID, itemID := uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`WHERE organization_id = $1 AND entity_type = $2 AND entity_id = ANY($3::uuid[])`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, "item", pq.Array([]string{itemID.String()})).
+			WillReturnRows(sqlmock.NewRows([]string{"entity_id", "values"}).AddRow(itemID, []byte(`{"vegan": true, "calories": 450}`)))
+
+		values, err := store.GetCustomFieldValues(orgID, "item", []uuid.UUID{itemID})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"vegan": true, "calories": float64(450)}, values[itemID])
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("db error"))
+
+		_, err := store.GetCustomFieldValues(orgID, "item", []uuid.UUID{itemID})
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestSetCustomFieldValues(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresCustomFieldStore(db, logger)
+
+	orgID, orderID := uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`WHERE EXISTS (SELECT 1 FROM orders WHERE id = $3 AND organization_id = $1)`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID, "order", orderID, []byte(`{"table":"12"}`)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.SetCustomFieldValues(orgID, "order", orderID, map[string]any{"table": "12"}))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("EntityNotInOrganization", func(t *testing.T) {
+		mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := store.SetCustomFieldValues(orgID, "order", orderID, map[string]any{"table": "12"})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("UnknownEntityType", func(t *testing.T) {
+		err := store.SetCustomFieldValues(orgID, "shift", orderID, map[string]any{})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
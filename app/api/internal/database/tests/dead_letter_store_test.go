@@ -0,0 +1,201 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var deadLetterColumns = []string{
+	"id", "organization_id", "source", "via", "payload", "error", "status", "replays", "edited_by", "edited_at",
+	"replayed_by", "replayed_at", "created_at",
+}
+
+func TestCreateDeadLetter(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresDeadLetterStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`INSERT INTO ingestion_dead_letters (id, organization_id, source, via, payload, error, status, created_at)`)
+
+	t.Run("Success", func(t *testing.T) {
+		letter := &database.DeadLetter{Source: "talabat", Via: database.DeadLetterViaWebhook, Payload: `[`, Error: "unexpected end of JSON input"}
+		mock.ExpectExec(query).
+			WithArgs(sqlmock.AnyArg(), orgID, "talabat", "webhook", `[`, "unexpected end of JSON input", "pending", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.CreateDeadLetter(orgID, letter)
+		assert.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, letter.ID)
+		assert.Equal(t, database.DeadLetterPending, letter.Status)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectExec(query).WillReturnError(fmt.Errorf("db error"))
+
+		err := store.CreateDeadLetter(orgID, &database.DeadLetter{Source: "talabat", Via: database.DeadLetterViaPoll})
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestListDeadLetters(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresDeadLetterStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`WHERE organization_id = $1 AND ($2 = '' OR source = $2) AND ($3 = '' OR status = $3)`)
+
+	t.Run("Success", func(t *testing.T) {
+		createdAt := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+		rows := sqlmock.NewRows(deadLetterColumns).
+			AddRow(uuid.New(), orgID, "talabat", "poll", "", "invalid delivery platform payload", "pending", 1, nil, nil, nil, nil, createdAt)
+		mock.ExpectQuery(query).WithArgs(orgID, "talabat", "", 50).WillReturnRows(rows)
+
+		letters, err := store.ListDeadLetters(orgID, database.DeadLetterFilter{Source: "talabat", Limit: 50})
+		assert.NoError(t, err)
+		if assert.Len(t, letters, 1) {
+			assert.Equal(t, "poll", letters[0].Via)
+			assert.Empty(t, letters[0].Payload)
+			assert.Equal(t, 1, letters[0].Replays)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("db error"))
+
+		letters, err := store.ListDeadLetters(orgID, database.DeadLetterFilter{Limit: 50})
+		assert.Error(t, err)
+		assert.Nil(t, letters)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetDeadLetter(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresDeadLetterStore(db, logger)
+
+	orgID, id := uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`FROM ingestion_dead_letters WHERE organization_id = $1 AND id = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows(deadLetterColumns).
+			AddRow(id, orgID, "uber_eats", "webhook", `{"order": {}}`, "missing order id", "pending", 0, nil, nil, nil, nil, time.Now())
+		mock.ExpectQuery(query).WithArgs(orgID, id).WillReturnRows(rows)
+
+		letter, err := store.GetDeadLetter(orgID, id)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"order": {}}`, letter.Payload)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, id).WillReturnError(sql.ErrNoRows)
+
+		letter, err := store.GetDeadLetter(orgID, id)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Nil(t, letter)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestUpdateDeadLetters(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresDeadLetterStore(db, logger)
+
+	orgID, id, adminID := uuid.New(), uuid.New(), uuid.New()
+	at := time.Date(2026, 10, 16, 13, 0, 0, 0, time.UTC)
+
+	t.Run("UpdatePayload", func(t *testing.T) {
+		rows := sqlmock.NewRows(deadLetterColumns).
+			AddRow(id, orgID, "talabat", "webhook", `{"token": "tb-4"}`, "invalid", "pending", 0, adminID, at, nil, nil, at.Add(-time.Hour))
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE ingestion_dead_letters SET payload = $3, edited_by = $4, edited_at = $5`)).
+			WithArgs(orgID, id, `{"token": "tb-4"}`, adminID, at).WillReturnRows(rows)
+
+		letter, err := store.UpdatePayload(orgID, id, `{"token": "tb-4"}`, adminID, at)
+		assert.NoError(t, err)
+		assert.Equal(t, adminID, *letter.EditedBy)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("UpdatePayload_NotPending", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE ingestion_dead_letters SET payload = $3`)).WillReturnError(sql.ErrNoRows)
+
+		letter, err := store.UpdatePayload(orgID, id, `{}`, adminID, at)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Nil(t, letter)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("MarkReplayed", func(t *testing.T) {
+		rows := sqlmock.NewRows(deadLetterColumns).
+			AddRow(id, orgID, "talabat", "webhook", `{}`, "invalid", "replayed", 1, nil, nil, adminID, at, at.Add(-time.Hour))
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE ingestion_dead_letters SET status = 'replayed', replays = replays + 1`)).
+			WithArgs(orgID, id, adminID, at).WillReturnRows(rows)
+
+		letter, err := store.MarkReplayed(orgID, id, adminID, at)
+		assert.NoError(t, err)
+		assert.Equal(t, database.DeadLetterReplayed, letter.Status)
+		assert.Equal(t, at, *letter.ReplayedAt)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("RecordFailedReplay", func(t *testing.T) {
+		rows := sqlmock.NewRows(deadLetterColumns).
+			AddRow(id, orgID, "talabat", "webhook", `[`, "still invalid", "pending", 2, nil, nil, nil, nil, at.Add(-time.Hour))
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE ingestion_dead_letters SET error = $3, replays = replays + 1`)).
+			WithArgs(orgID, id, "still invalid").WillReturnRows(rows)
+
+		letter, err := store.RecordFailedReplay(orgID, id, "still invalid")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, letter.Replays)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetIngestionFailureRates(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresDeadLetterStore(db, logger)
+
+	orgID := uuid.New()
+	since := time.Date(2026, 10, 9, 0, 0, 0, 0, time.UTC)
+	sources := []string{"uber_eats", "talabat"}
+	query := regexp.QuoteMeta(`SELECT source, TRUE FROM ingestion_dead_letters`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, database.StatusEventIngestion, since, pq.Array(sources)).
+			WillReturnRows(sqlmock.NewRows([]string{"source", "received", "failed"}).AddRow("talabat", 40, 2).AddRow("uber_eats", 3, 0))
+
+		rates, err := store.GetFailureRates(orgID, sources, since)
+		assert.NoError(t, err)
+		assert.Equal(t, []database.IngestionFailureRate{
+			{Source: "talabat", Received: 40, Failed: 2, FailureRate: 5},
+			{Source: "uber_eats", Received: 3, Failed: 0, FailureRate: 0},
+		}, rates)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("db error"))
+
+		rates, err := store.GetFailureRates(orgID, sources, since)
+		assert.Error(t, err)
+		assert.Nil(t, rates)
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,133 @@
+package database
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var deliveryPlatformColumns = []string{"organization_id", "platform", "store_id", "webhook_secret", "api_url", "api_token", "enabled", "last_polled_at", "last_event_at", "created_at", "updated_at"}
+
+func TestGetDeliveryPlatformIntegration(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresDeliveryPlatformStore(db, logger, nil)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`SELECT organization_id, platform, store_id, webhook_secret, api_url, api_token, enabled, last_polled_at, last_event_at, created_at, updated_at FROM delivery_platform_integrations WHERE organization_id = $1 AND platform = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(query).WithArgs(orgID, "deliveroo").
+			WillReturnRows(sqlmock.NewRows(deliveryPlatformColumns).AddRow(orgID, "deliveroo", "store-42", "whsec_0123456789abcdef", nil, nil, true, nil, now, now, now))
+
+		integration, err := store.GetIntegration(orgID, "deliveroo")
+		assert.NoError(t, err)
+		assert.Equal(t, "store-42", integration.StoreID)
+		assert.Equal(t, "whsec_0123456789abcdef", integration.WebhookSecret)
+		assert.Nil(t, integration.APIToken)
+		assert.Nil(t, integration.LastPolledAt)
+		assert.Equal(t, now, *integration.LastEventAt)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotConnected", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, "talabat").WillReturnError(sql.ErrNoRows)
+
+		integration, err := store.GetIntegration(orgID, "talabat")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Nil(t, integration)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestSaveDeliveryPlatformIntegration(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	cipher, err := service.NewCryptoService("k1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=", "")
+	assert.NoError(t, err)
+	store := database.NewPostgresDeliveryPlatformStore(db, logger, cipher)
+
+	orgID := uuid.New()
+	apiURL := "https://platform.example.com/orders"
+	apiToken := "token-123"
+	query := regexp.QuoteMeta(`INSERT INTO delivery_platform_integrations (organization_id, platform, store_id, webhook_secret, api_url, api_token, enabled, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8) ON CONFLICT (organization_id, platform) DO UPDATE SET`)
+
+	t.Run("SealsSecrets", func(t *testing.T) {
+		integration := &database.DeliveryPlatformIntegration{Platform: "uber_eats", StoreID: "store-1", WebhookSecret: "whsec_0123456789abcdef", APIURL: &apiURL, APIToken: &apiToken, Enabled: true}
+		now := time.Now()
+
+		var sealedSecret, sealedToken string
+		mock.ExpectQuery(query).
+			WithArgs(orgID, "uber_eats", "store-1", Capture(&sealedSecret), &apiURL, Capture(&sealedToken), true, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(now, now))
+
+		err := store.SaveIntegration(orgID, integration)
+		assert.NoError(t, err)
+		assert.Equal(t, orgID, integration.OrganizationID)
+		assert.Equal(t, now, integration.UpdatedAt)
+		AssertExpectations(t, mock)
+
+		// Neither is written in plaintext, and both read back as they were saved
+		assert.NotContains(t, sealedSecret, "whsec_")
+		assert.NotEqual(t, apiToken, sealedToken)
+		mock.ExpectQuery(`FROM delivery_platform_integrations`).WithArgs(orgID, "uber_eats").
+			WillReturnRows(sqlmock.NewRows(deliveryPlatformColumns).AddRow(orgID, "uber_eats", "store-1", sealedSecret, apiURL, sealedToken, true, nil, nil, now, now))
+
+		saved, err := store.GetIntegration(orgID, "uber_eats")
+		assert.NoError(t, err)
+		assert.Equal(t, "whsec_0123456789abcdef", saved.WebhookSecret)
+		assert.Equal(t, apiToken, *saved.APIToken)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestDeleteDeliveryPlatformIntegration(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresDeliveryPlatformStore(db, logger, nil)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`DELETE FROM delivery_platform_integrations WHERE organization_id = $1 AND platform = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID, "talabat").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.DeleteIntegration(orgID, "talabat"))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotConnected", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID, "talabat").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, store.DeleteIntegration(orgID, "talabat"), sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetPolledIntegrations(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresDeliveryPlatformStore(db, logger, nil)
+
+	query := regexp.QuoteMeta(`FROM delivery_platform_integrations WHERE enabled AND api_url IS NOT NULL ORDER BY organization_id, platform`)
+
+	t.Run("Success", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(query).
+			WillReturnRows(sqlmock.NewRows(deliveryPlatformColumns).AddRow(uuid.New(), "talabat", "vendor-7", "secret", "https://platform.example.com/orders", "token", true, now, nil, now, now))
+
+		integrations, err := store.GetPolledIntegrations()
+		assert.NoError(t, err)
+		assert.Len(t, integrations, 1)
+		assert.Equal(t, now, *integrations[0].LastPolledAt)
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDayBreaches(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresDeliverySLAStore(db, logger)
+
+	from := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+	query := regexp.QuoteMeta(`AND d.out_for_delivery_time >= $1 AND d.out_for_delivery_time < $2`)
+	columns := []string{"organization_id", "sla_minutes", "alert_percent", "delivered", "breaches"}
+
+	t.Run("Success", func(t *testing.T) {
+		orgID := uuid.New()
+		mock.ExpectQuery(query).WithArgs(from, to).WillReturnRows(sqlmock.NewRows(columns).AddRow(orgID, 30, 20, 40, 9))
+
+		days, err := store.GetDayBreaches(from, to)
+		assert.NoError(t, err)
+		assert.Equal(t, []database.DeliverySLADay{
+			{OrganizationID: orgID, SLAMinutes: 30, AlertPercent: 20, Delivered: 40, Breaches: 9},
+		}, days)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(from, to).WillReturnError(fmt.Errorf("db error"))
+
+		days, err := store.GetDayBreaches(from, to)
+		assert.Error(t, err)
+		assert.Nil(t, days)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestStoreDeliverySLAAlert(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresDeliverySLAStore(db, logger)
+
+	orgID := uuid.New()
+	date := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`ON CONFLICT (organization_id, alert_date) DO NOTHING`)
+	alert := func() *database.DeliverySLAAlert {
+		return &database.DeliverySLAAlert{OrganizationID: orgID, Date: date, SLAMinutes: 30, Delivered: 40, Breaches: 9, BreachRate: 22.5, ThresholdPercent: 20}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(sqlmock.AnyArg(), orgID, "2026-10-16", 30, 40, 9, 22.5, 20, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		stored := alert()
+		err := store.StoreAlert(stored)
+		assert.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, stored.ID)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("AlreadyAlerted", func(t *testing.T) {
+		mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := store.StoreAlert(alert())
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetEmailOptOuts(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresEmailPreferenceStore(db, logger)
+
+	query := regexp.QuoteMeta(`SELECT category FROM email_opt_outs WHERE email = $1 ORDER BY category`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("sam@example.com").
+			WillReturnRows(sqlmock.NewRows([]string{"category"}).AddRow("digests").AddRow("marketing"))
+
+		categories, err := store.GetOptOuts("Sam@Example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"digests", "marketing"}, categories)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NoOptOuts", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("sam@example.com").WillReturnRows(sqlmock.NewRows([]string{"category"}))
+
+		categories, err := store.GetOptOuts("sam@example.com")
+		assert.NoError(t, err)
+		assert.Empty(t, categories)
+		assert.NotNil(t, categories)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestSetEmailOptOuts(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresEmailPreferenceStore(db, logger)
+
+	deleteQuery := regexp.QuoteMeta(`DELETE FROM email_opt_outs WHERE email = $1`)
+	insertQuery := regexp.QuoteMeta(`INSERT INTO email_opt_outs (email, category, created_at) VALUES ($1, $2, $3)`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteQuery).WithArgs("sam@example.com").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(insertQuery).WithArgs("sam@example.com", "reminders", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(insertQuery).WithArgs("sam@example.com", "marketing", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, store.SetOptOuts("Sam@Example.com", []string{"reminders", "marketing"}))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("InsertError", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteQuery).WithArgs("sam@example.com").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(insertQuery).WillReturnError(fmt.Errorf("db error"))
+		mock.ExpectRollback()
+
+		assert.Error(t, store.SetOptOuts("sam@example.com", []string{"digests"}))
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestEmailOptOut(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresEmailPreferenceStore(db, logger)
+
+	query := regexp.QuoteMeta(`ON CONFLICT (email, category) DO NOTHING`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs("sam@example.com", "reminders", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		assert.NoError(t, store.OptOut("SAM@example.com", "reminders"))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectExec(query).WillReturnError(fmt.Errorf("db error"))
+
+		assert.Error(t, store.OptOut("sam@example.com", "reminders"))
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetOptedOutEmails(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresEmailPreferenceStore(db, logger)
+
+	query := regexp.QuoteMeta(`SELECT email FROM email_opt_outs WHERE category = $1 AND email = ANY($2)`)
+
+	t.Run("KeyedByGivenAddress", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("marketing", pq.Array([]string{"sam@example.com", "alex@example.com"})).
+			WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("sam@example.com"))
+
+		optedOut, err := store.GetOptedOut("marketing", []string{"Sam@Example.com", "alex@example.com"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]bool{"Sam@Example.com": true}, optedOut)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("db error"))
+
+		optedOut, err := store.GetOptedOut("marketing", []string{"sam@example.com"})
+		assert.Error(t, err)
+		assert.Nil(t, optedOut)
+		AssertExpectations(t, mock)
+	})
+}
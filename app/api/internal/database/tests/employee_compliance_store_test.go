@@ -0,0 +1,189 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var complianceColumns = []string{"user_id", "emergency_contact_name", "emergency_contact_relationship", "emergency_contact_phone",
+	"national_id", "national_id_expiry", "work_permit_number", "work_permit_expiry",
+	"bank_account_holder", "bank_name", "bank_iban", "updated_at"}
+
+func TestSaveCompliance(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	cipher, err := service.NewCryptoService("k1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=", "")
+	assert.NoError(t, err)
+	store := database.NewPostgresEmployeeComplianceStore(db, logger, cipher)
+
+	userID := uuid.New()
+	name, phone, permit, holder, iban := "Mona Said", "+20 100 123 4567", "WP-2291", "Sam Server", "GB82WEST12345698765432"
+	expiry := time.Date(2027, 3, 31, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`INSERT INTO employee_compliance (user_id, emergency_contact_name, emergency_contact_relationship, emergency_contact_phone, national_id, national_id_expiry, work_permit_number, work_permit_expiry, bank_account_holder, bank_name, bank_iban, updated_at)`)
+
+	t.Run("SealsNumbers", func(t *testing.T) {
+		compliance := &database.EmployeeCompliance{
+			UserID:           userID,
+			EmergencyContact: database.EmergencyContact{Name: &name, Phone: &phone},
+			WorkPermitNumber: &permit,
+			WorkPermitExpiry: &expiry,
+			BankDetails:      database.BankDetails{AccountHolder: &holder, IBAN: &iban},
+		}
+		now := time.Now()
+
+		var sealedPhone, sealedPermit, sealedHolder, sealedIBAN string
+		mock.ExpectQuery(query).
+			WithArgs(userID, &name, nil, Capture(&sealedPhone), nil, nil, Capture(&sealedPermit), &expiry, Capture(&sealedHolder), nil, Capture(&sealedIBAN)).
+			WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(now))
+
+		err := store.SaveCompliance(compliance)
+		assert.NoError(t, err)
+		assert.Equal(t, now, compliance.UpdatedAt)
+		AssertExpectations(t, mock)
+
+		// Nothing sensitive is written in plaintext, and everything reads back as it was saved
+		for plain, sealed := range map[string]string{phone: sealedPhone, permit: sealedPermit, holder: sealedHolder, iban: sealedIBAN} {
+			assert.NotEqual(t, plain, sealed)
+		}
+		mock.ExpectQuery(`FROM employee_compliance`).WithArgs(userID).
+			WillReturnRows(sqlmock.NewRows(complianceColumns).
+				AddRow(userID, name, nil, sealedPhone, nil, nil, sealedPermit, expiry, sealedHolder, nil, sealedIBAN, now))
+
+		saved, err := store.GetCompliance(userID)
+		assert.NoError(t, err)
+		assert.Equal(t, phone, *saved.EmergencyContact.Phone)
+		assert.Equal(t, permit, *saved.WorkPermitNumber)
+		assert.Equal(t, expiry, *saved.WorkPermitExpiry)
+		assert.Equal(t, iban, *saved.BankDetails.IBAN)
+		assert.Nil(t, saved.NationalID)
+		assert.Nil(t, saved.NationalIDExpiry)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("db error"))
+
+		err := store.SaveCompliance(&database.EmployeeCompliance{UserID: userID})
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetCompliance(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresEmployeeComplianceStore(db, logger, nil)
+
+	userID := uuid.New()
+	query := regexp.QuoteMeta(`FROM employee_compliance WHERE user_id = $1`)
+
+	t.Run("NotSaved", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(userID).WillReturnError(sql.ErrNoRows)
+
+		compliance, err := store.GetCompliance(userID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Nil(t, compliance)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(userID).WillReturnError(fmt.Errorf("db error"))
+
+		_, err := store.GetCompliance(userID)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetWorkPermitExpiries(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresEmployeeComplianceStore(db, logger, nil)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`WHERE u.organization_id = $1 AND c.work_permit_expiry IS NOT NULL`)
+
+	t.Run("Success", func(t *testing.T) {
+		userID := uuid.New()
+		expiry := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+		mock.ExpectQuery(query).WithArgs(orgID).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "work_permit_expiry"}).AddRow(userID, expiry))
+
+		expiries, err := store.GetWorkPermitExpiries(orgID)
+		assert.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID]time.Time{userID: expiry}, expiries)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnError(fmt.Errorf("db error"))
+
+		_, err := store.GetWorkPermitExpiries(orgID)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetExpiringDocuments(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresEmployeeComplianceStore(db, logger, nil)
+
+	until := time.Date(2026, 11, 15, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`WHERE c.work_permit_expiry <= $1 AND (c.work_permit_alerted_days IS NULL OR c.work_permit_alerted_days > 0)`)
+
+	t.Run("Success", func(t *testing.T) {
+		userID, orgID := uuid.New(), uuid.New()
+		rows := sqlmock.NewRows([]string{"user_id", "organization_id", "full_name", "email", "document", "expires_on", "alerted_days"}).
+			AddRow(userID, orgID, "Sam Server", "sam@example.com", "work_permit", time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC), 30).
+			AddRow(userID, orgID, "Sam Server", "sam@example.com", "national_id", time.Date(2026, 11, 10, 0, 0, 0, 0, time.UTC), nil)
+		mock.ExpectQuery(query).WithArgs(until).WillReturnRows(rows)
+
+		documents, err := store.GetExpiringDocuments(until)
+		assert.NoError(t, err)
+		if assert.Len(t, documents, 2) {
+			assert.Equal(t, database.DocumentWorkPermit, documents[0].Document)
+			assert.Equal(t, 30, *documents[0].AlertedDays)
+			assert.Nil(t, documents[1].AlertedDays)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(until).WillReturnError(fmt.Errorf("db error"))
+
+		_, err := store.GetExpiringDocuments(until)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestMarkExpiryAlerted(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresEmployeeComplianceStore(db, logger, nil)
+
+	userID := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE employee_compliance SET work_permit_alerted_days = $2 WHERE user_id = $1`)).
+			WithArgs(userID, 7).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.MarkExpiryAlerted(userID, database.DocumentWorkPermit, 7))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("UnknownDocument", func(t *testing.T) {
+		assert.Error(t, store.MarkExpiryAlerted(userID, "passport", 7))
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var employeeDocumentRowColumns = []string{"id", "organization_id", "employee_id", "employee_name", "employee_email", "kind",
+	"title", "body", "created_by", "created_at", "sign_expires_at", "signed_at", "signer_name", "signer_ip", "signed_sha256"}
+
+func TestGetDocumentTemplate(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresEmployeeDocumentStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`SELECT kind, title, body, updated_by, updated_at FROM document_templates WHERE organization_id = $1 AND kind = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"kind", "title", "body", "updated_by", "updated_at"}).
+			AddRow(database.DocumentOfferLetter, "Offer", "Dear {{.EmployeeName}}", uuid.New(), time.Now())
+		mock.ExpectQuery(query).WithArgs(orgID, database.DocumentOfferLetter).WillReturnRows(rows)
+
+		template, err := store.GetTemplate(orgID, database.DocumentOfferLetter)
+		assert.NoError(t, err)
+		assert.Equal(t, "Dear {{.EmployeeName}}", template.Body)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("BuiltIn", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, database.DocumentTerminationLetter).WillReturnRows(sqlmock.NewRows([]string{"kind"}))
+
+		_, err := store.GetTemplate(orgID, database.DocumentTerminationLetter)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestCreateEmployeeDocument(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresEmployeeDocumentStore(db, logger)
+
+	orgID, employeeID, managerID, documentID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	expires := time.Now().Add(24 * time.Hour)
+	query := regexp.QuoteMeta(`INSERT INTO employee_documents (organization_id, employee_id, employee_name, employee_email, kind, title, body, created_by, sign_token_hash, sign_expires_at)`)
+
+	t.Run("StoresTokenHash", func(t *testing.T) {
+		now := time.Now()
+		doc := &database.EmployeeDocument{OrganizationID: orgID, EmployeeID: &employeeID, EmployeeName: "Sam", EmployeeEmail: "sam@example.com",
+			Kind: database.DocumentOfferLetter, Title: "Offer", Body: "Dear Sam", CreatedBy: &managerID, SignExpiresAt: expires}
+		mock.ExpectQuery(query).WithArgs(orgID, &employeeID, "Sam", "sam@example.com", database.DocumentOfferLetter, "Offer", "Dear Sam",
+			&managerID, database.HashSignToken("secret-token"), expires).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(documentID, now))
+
+		err := store.CreateDocument(doc, "secret-token")
+		assert.NoError(t, err)
+		assert.Equal(t, documentID, doc.ID)
+		assert.Len(t, database.HashSignToken("secret-token"), 64)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetEmployeeDocuments(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresEmployeeDocumentStore(db, logger)
+
+	orgID, employeeID := uuid.New(), uuid.New()
+
+	t.Run("OfEmployee", func(t *testing.T) {
+		rows := sqlmock.NewRows(employeeDocumentRowColumns).
+			AddRow(uuid.New(), orgID, employeeID, "Sam", "sam@example.com", database.DocumentOfferLetter, "Offer", "Dear Sam",
+				nil, time.Now(), time.Now(), nil, nil, nil, nil)
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM employee_documents WHERE organization_id = $1 AND employee_id = $2 ORDER BY created_at DESC`)).
+			WithArgs(orgID, &employeeID).WillReturnRows(rows)
+
+		documents, err := store.GetDocuments(orgID, &employeeID)
+		assert.NoError(t, err)
+		if assert.Len(t, documents, 1) {
+			assert.Nil(t, documents[0].SignedAt)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM employee_documents WHERE organization_id = $1 ORDER BY created_at DESC`)).
+			WithArgs(orgID).WillReturnError(fmt.Errorf("db error"))
+
+		_, err := store.GetDocuments(orgID, nil)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetDocumentBySignToken(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresEmployeeDocumentStore(db, logger)
+
+	query := regexp.QuoteMeta(`WHERE sign_token_hash = $1 AND (signed_at IS NOT NULL OR sign_expires_at > NOW())`)
+
+	t.Run("ExpiredOrUnknown", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(database.HashSignToken("token")).WillReturnRows(sqlmock.NewRows(employeeDocumentRowColumns))
+
+		_, err := store.GetDocumentBySignToken("token")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestSignEmployeeDocument(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresEmployeeDocumentStore(db, logger)
+
+	signedAt := time.Now().UTC()
+	signer, ip := "Sam Carter", "203.0.113.7"
+	doc := &database.EmployeeDocument{ID: uuid.New(), SignedAt: &signedAt, SignerName: &signer, SignerIP: &ip}
+	pdf := []byte("%PDF-1.4 signed")
+	query := regexp.QuoteMeta(`UPDATE employee_documents SET signed_at = $2, signer_name = $3, signer_ip = $4, signed_pdf = $5, signed_sha256 = $6 WHERE id = $1 AND signed_at IS NULL`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(doc.ID, &signedAt, &signer, &ip, pdf, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.SignDocument(doc, pdf))
+		if assert.NotNil(t, doc.SignedSHA256) {
+			assert.Len(t, *doc.SignedSHA256, 64)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("AlreadySigned", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(doc.ID, &signedAt, &signer, &ip, pdf, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, store.SignDocument(doc, pdf), database.ErrDocumentSigned)
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,152 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateEmployeeRecord(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresEmployeeRecordStore(db, logger)
+
+	orgID := uuid.New()
+	employeeID := uuid.New()
+	authorID := uuid.New()
+	retainUntil := time.Date(2027, 10, 15, 0, 0, 0, 0, time.UTC)
+	record := &database.EmployeeRecord{EmployeeID: employeeID, AuthorID: &authorID, Kind: "warning", Title: "Late three times", RetainUntil: retainUntil}
+	query := regexp.QuoteMeta(`INSERT INTO employee_records (id, organization_id, employee_id, author_id, kind, title, details, retain_until, created_at)`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(sqlmock.AnyArg(), orgID, employeeID, &authorID, "warning", "Late three times", nil, retainUntil, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		assert.NoError(t, store.CreateRecord(orgID, record))
+		assert.NotEqual(t, uuid.Nil, record.ID)
+		assert.False(t, record.CreatedAt.IsZero())
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectExec(query).WillReturnError(fmt.Errorf("db error"))
+
+		assert.Error(t, store.CreateRecord(orgID, &database.EmployeeRecord{EmployeeID: employeeID, Kind: "note", Title: "Note"}))
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetRecordsForEmployee(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresEmployeeRecordStore(db, logger)
+
+	orgID := uuid.New()
+	employeeID := uuid.New()
+	query := regexp.QuoteMeta(`r.retain_until >= CURRENT_DATE AND r.employee_id = $2 ORDER BY r.created_at DESC`)
+	columns := []string{"id", "employee_id", "author_id", "full_name", "kind", "title", "details", "retain_until", "created_at"}
+
+	t.Run("Success", func(t *testing.T) {
+		authorID := uuid.New()
+		rows := sqlmock.NewRows(columns).
+			AddRow(uuid.New(), employeeID, authorID, "Alex Manager", "commendation", "Covered a double shift", "Thanks!", time.Now(), time.Now()).
+			AddRow(uuid.New(), employeeID, nil, nil, "note", "Author left the company", nil, time.Now(), time.Now())
+		mock.ExpectQuery(query).WithArgs(orgID, employeeID).WillReturnRows(rows)
+
+		records, err := store.GetRecordsForEmployee(orgID, employeeID)
+		assert.NoError(t, err)
+		if assert.Len(t, records, 2) {
+			assert.Equal(t, authorID, *records[0].AuthorID)
+			assert.Equal(t, "Alex Manager", *records[0].AuthorName)
+			assert.Equal(t, "Thanks!", *records[0].Details)
+			assert.Nil(t, records[1].AuthorID)
+			assert.Nil(t, records[1].AuthorName)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, employeeID).WillReturnError(fmt.Errorf("db error"))
+
+		records, err := store.GetRecordsForEmployee(orgID, employeeID)
+		assert.Error(t, err)
+		assert.Nil(t, records)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetEmployeeRecordByID(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresEmployeeRecordStore(db, logger)
+
+	orgID := uuid.New()
+	recordID := uuid.New()
+	query := regexp.QuoteMeta(`AND r.id = $2`)
+
+	mock.ExpectQuery(query).WithArgs(orgID, recordID).WillReturnError(sql.ErrNoRows)
+
+	record, err := store.GetRecordByID(orgID, recordID)
+	assert.Equal(t, sql.ErrNoRows, err)
+	assert.Nil(t, record)
+	AssertExpectations(t, mock)
+}
+
+func TestDeleteEmployeeRecord(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresEmployeeRecordStore(db, logger)
+
+	orgID := uuid.New()
+	recordID := uuid.New()
+	query := regexp.QuoteMeta(`DELETE FROM employee_records WHERE id = $1 AND organization_id = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(recordID, orgID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.DeleteRecord(orgID, recordID))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(recordID, orgID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.Equal(t, sql.ErrNoRows, store.DeleteRecord(orgID, recordID))
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestPurgeExpiredRecords(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresEmployeeRecordStore(db, logger)
+
+	now := time.Now()
+	query := regexp.QuoteMeta(`DELETE FROM employee_records WHERE retain_until < $1`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(now).WillReturnResult(sqlmock.NewResult(0, 3))
+
+		purged, err := store.PurgeExpiredRecords(now)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), purged)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(now).WillReturnError(fmt.Errorf("db error"))
+
+		purged, err := store.PurgeExpiredRecords(now)
+		assert.Error(t, err)
+		assert.Zero(t, purged)
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExchangeRates(t *testing.T) {
+	db, mock := NewTestDB(t)
+	store := database.NewPostgresExchangeRateStore(db, NewTestLogger())
+
+	day := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	getQuery := regexp.QuoteMeta(`SELECT quote_currency, rate FROM exchange_rates WHERE rate_date = $1::DATE AND base_currency = $2`)
+	insertQuery := regexp.QuoteMeta(`INSERT INTO exchange_rates (rate_date, base_currency, quote_currency, rate) VALUES ($1::DATE, $2, $3, $4) ON CONFLICT DO NOTHING`)
+
+	t.Run("GetExchangeRates", func(t *testing.T) {
+		mock.ExpectQuery(getQuery).WithArgs(day, "USD").
+			WillReturnRows(sqlmock.NewRows([]string{"quote_currency", "rate"}).AddRow("EUR", 0.92).AddRow("GBP", 0.79))
+
+		rates, err := store.GetExchangeRates("USD", day)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]float64{"EUR": 0.92, "GBP": 0.79}, rates)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("GetExchangeRates_NotCached", func(t *testing.T) {
+		mock.ExpectQuery(getQuery).WithArgs(day, "USD").WillReturnRows(sqlmock.NewRows([]string{"quote_currency", "rate"}))
+
+		rates, err := store.GetExchangeRates("USD", day)
+		assert.NoError(t, err)
+		assert.Empty(t, rates)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("StoreExchangeRates", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(insertQuery).WithArgs(day, "USD", "EUR", 0.92).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := store.StoreExchangeRates("USD", day, map[string]float64{"EUR": 0.92})
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("StoreExchangeRates_RollsBack", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(insertQuery).WithArgs(day, "USD", "EUR", 0.92).WillReturnError(fmt.Errorf("db error"))
+		mock.ExpectRollback()
+
+		err := store.StoreExchangeRates("USD", day, map[string]float64{"EUR": 0.92})
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetExportEncryption(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresExportEncryptionStore(db, logger, nil)
+
+	orgID, adminID := uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`FROM organization_export_encryption WHERE organization_id = $1`)
+	columns := []string{"organization_id", "mode", "public_key", "key_fingerprint", "passphrase", "updated_by", "updated_at"}
+
+	t.Run("Passphrase", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(orgID, "passphrase", nil, nil, "correct horse battery", adminID, time.Now()))
+
+		encryption, err := store.GetExportEncryption(orgID)
+		assert.NoError(t, err)
+		if assert.NotNil(t, encryption) {
+			assert.Equal(t, database.ExportEncryptionPassphrase, encryption.Mode)
+			assert.Equal(t, "correct horse battery", *encryption.Passphrase)
+			assert.Nil(t, encryption.PublicKey)
+			assert.Equal(t, adminID, *encryption.UpdatedBy)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotEncrypted", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnError(sql.ErrNoRows)
+
+		encryption, err := store.GetExportEncryption(orgID)
+		assert.NoError(t, err)
+		assert.Nil(t, encryption)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestSetExportEncryption(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresExportEncryptionStore(db, logger, nil)
+
+	orgID, adminID := uuid.New(), uuid.New()
+	publicKey, fingerprint := "-----BEGIN PGP PUBLIC KEY BLOCK-----", "0123456789ABCDEF0123456789ABCDEF01234567"
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`ON CONFLICT (organization_id) DO UPDATE SET`)).
+		WithArgs(orgID, "pgp", &publicKey, &fingerprint, nil, &adminID).
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(now))
+
+	encryption := &database.ExportEncryption{OrganizationID: orgID, Mode: database.ExportEncryptionPGP, PublicKey: &publicKey,
+		KeyFingerprint: &fingerprint, UpdatedBy: &adminID}
+	assert.NoError(t, store.SetExportEncryption(encryption))
+	assert.Equal(t, now, encryption.UpdatedAt)
+	AssertExpectations(t, mock)
+}
+
+func TestDeleteExportEncryption(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresExportEncryptionStore(db, logger, nil)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`DELETE FROM organization_export_encryption WHERE organization_id = $1`)
+
+	mock.ExpectExec(query).WithArgs(orgID).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, store.DeleteExportEncryption(orgID))
+
+	mock.ExpectExec(query).WithArgs(orgID).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, store.DeleteExportEncryption(orgID), sql.ErrNoRows)
+	AssertExpectations(t, mock)
+}
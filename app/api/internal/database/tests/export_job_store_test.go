@@ -0,0 +1,102 @@
+package database
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var exportJobRowColumns = []string{"id", "organization_id", "requested_by", "entity", "from_date", "to_date", "format", "status", "attempts",
+	"file_key", "row_count", "size_bytes", "error", "created_at", "started_at", "completed_at", "expires_at"}
+
+func TestCreateExportJob(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresExportJobStore(db, logger)
+
+	orgID, userID, jobID := uuid.New(), uuid.New(), uuid.New()
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO export_jobs (organization_id, requested_by, entity, from_date, to_date, format)`)).
+		WithArgs(orgID, &userID, "orders", from, to, "xlsx").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "created_at"}).AddRow(jobID, database.ExportJobQueued, time.Now()))
+
+	job := &database.ExportJob{OrganizationID: orgID, RequestedBy: &userID, Entity: "orders", From: from, To: to, Format: "xlsx"}
+	err := store.CreateExportJob(job)
+	assert.NoError(t, err)
+	assert.Equal(t, jobID, job.ID)
+	assert.Equal(t, database.ExportJobQueued, job.Status)
+	assert.Equal(t, "orders-2020-01-01-2025-12-31.xlsx", job.FileName())
+	AssertExpectations(t, mock)
+}
+
+func TestClaimExportJob(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresExportJobStore(db, logger)
+
+	orgID, userID, jobID := uuid.New(), uuid.New(), uuid.New()
+	now := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	claim := regexp.QuoteMeta(`WHERE status = 'queued' OR (status = 'running' AND started_at < $2)`) + `.*` +
+		regexp.QuoteMeta(`FOR UPDATE SKIP LOCKED`) + `.*` + regexp.QuoteMeta(`LEFT JOIN users u ON u.id = c.requested_by`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(claim).WithArgs(now, now.Add(-30*time.Minute)).
+			WillReturnRows(sqlmock.NewRows(append(exportJobRowColumns, "email", "full_name")).
+				AddRow(jobID, orgID, userID, "deliveries", now, now, "csv", database.ExportJobRunning, 2, nil, nil, nil, nil, now, now, nil, nil, "sam@test.com", "Sam"))
+
+		job, err := store.ClaimExportJob(now, 30*time.Minute)
+		assert.NoError(t, err)
+		if assert.NotNil(t, job) {
+			assert.Equal(t, jobID, job.ID)
+			assert.Equal(t, 2, job.Attempts)
+			assert.Equal(t, "sam@test.com", job.RequesterEmail)
+			assert.Equal(t, "Sam", job.RequesterName)
+			assert.Nil(t, job.FileKey)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NoneWaiting", func(t *testing.T) {
+		mock.ExpectQuery(claim).WithArgs(now, now.Add(-30*time.Minute)).WillReturnError(sql.ErrNoRows)
+
+		job, err := store.ClaimExportJob(now, 30*time.Minute)
+		assert.NoError(t, err)
+		assert.Nil(t, job)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetExpiredExportJobs(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresExportJobStore(db, logger)
+
+	orgID, jobID := uuid.New(), uuid.New()
+	now := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	key := orgID.String() + "/" + jobID.String() + ".csv"
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE status = 'completed' AND expires_at <= $1`)).WithArgs(now).
+		WillReturnRows(sqlmock.NewRows(exportJobRowColumns).
+			AddRow(jobID, orgID, nil, "orders", now, now, "csv", database.ExportJobCompleted, 1, key, 120, 4096, nil, now, now, now, now))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE export_jobs SET status = 'expired', file_key = NULL WHERE id = $1`)).WithArgs(jobID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	jobs, err := store.GetExpiredExportJobs(now)
+	assert.NoError(t, err)
+	if assert.Len(t, jobs, 1) {
+		assert.Equal(t, key, *jobs[0].FileKey)
+		assert.Equal(t, 120, *jobs[0].RowCount)
+		assert.Equal(t, int64(4096), *jobs[0].SizeBytes)
+		assert.Nil(t, jobs[0].RequestedBy)
+	}
+	assert.NoError(t, store.MarkExportJobExpired(jobID))
+	AssertExpectations(t, mock)
+}
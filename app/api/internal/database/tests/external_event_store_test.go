@@ -0,0 +1,128 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var externalEventColumns = []string{"id", "name", "category", "description", "location", "start_time", "end_time", "expected_attendance", "created_at"}
+
+func TestGetEventsBetween(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresExternalEventStore(db, logger)
+
+	orgID := uuid.New()
+	from := time.Date(2025, 6, 7, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	query := regexp.QuoteMeta(`FROM external_events WHERE organization_id = $1 AND start_time < $3 AND end_time > $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		id := uuid.New()
+		rows := sqlmock.NewRows(externalEventColumns).
+			AddRow(id, "Cup Final", "sports", nil, "City Stadium", from.Add(18*time.Hour), from.Add(20*time.Hour), 40000, from)
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).WillReturnRows(rows)
+
+		events, err := store.GetEventsBetween(orgID, from, to)
+		assert.NoError(t, err)
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, id, events[0].ID)
+			assert.Equal(t, "City Stadium", *events[0].Location)
+			assert.Nil(t, events[0].Description)
+			assert.Equal(t, 40000, *events[0].ExpectedAttendance)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).WillReturnError(fmt.Errorf("db error"))
+
+		events, err := store.GetEventsBetween(orgID, from, to)
+		assert.Error(t, err)
+		assert.Nil(t, events)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestCreateEvent(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresExternalEventStore(db, logger)
+
+	orgID := uuid.New()
+	start := time.Date(2025, 6, 7, 18, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`INSERT INTO external_events (id, organization_id, name, category, description, location, start_time, end_time, expected_attendance, created_at)`)
+
+	event := &database.ExternalEvent{Name: "Cup Final", Category: "sports", StartTime: start, EndTime: start.Add(2 * time.Hour)}
+	mock.ExpectExec(query).
+		WithArgs(sqlmock.AnyArg(), orgID, "Cup Final", "sports", nil, nil, start, start.Add(2*time.Hour), nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := store.CreateEvent(orgID, event)
+	assert.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, event.ID)
+	assert.False(t, event.CreatedAt.IsZero())
+	AssertExpectations(t, mock)
+}
+
+func TestUpdateEvent(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresExternalEventStore(db, logger)
+
+	orgID := uuid.New()
+	start := time.Date(2025, 6, 7, 6, 0, 0, 0, time.UTC)
+	event := &database.ExternalEvent{ID: uuid.New(), Name: "Road Works", Category: "road_closure", StartTime: start, EndTime: start.Add(36 * time.Hour)}
+	query := regexp.QuoteMeta(`UPDATE external_events SET name = $3`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(event.ID, orgID, "Road Works", "road_closure", nil, nil, start, start.Add(36*time.Hour), nil).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(start))
+
+		err := store.UpdateEvent(orgID, event)
+		assert.NoError(t, err)
+		assert.Equal(t, start, event.CreatedAt)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(sql.ErrNoRows)
+
+		err := store.UpdateEvent(orgID, event)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestDeleteEvent(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresExternalEventStore(db, logger)
+
+	orgID := uuid.New()
+	eventID := uuid.New()
+	query := regexp.QuoteMeta(`DELETE FROM external_events WHERE id = $1 AND organization_id = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(eventID, orgID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.DeleteEvent(orgID, eventID))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(eventID, orgID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, store.DeleteEvent(orgID, eventID), sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,181 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var forecastVarianceAlertColumns = []string{
+	"id", "organization_id", "alert_date", "through_hour", "forecast_orders", "actual_orders", "variance_percent",
+	"direction", "suggestion", "staff_on_shift", "suggested_staff_change", "notified_managers", "created_at",
+}
+
+func TestGetForecastedOrganizations(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresForecastVarianceStore(db, logger)
+
+	date := time.Date(2026, 10, 15, 14, 5, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`SELECT DISTINCT organization_id FROM demand WHERE demand_date = $1`)
+
+	t.Run("Success", func(t *testing.T) {
+		orgID := uuid.New()
+		mock.ExpectQuery(query).WithArgs("2026-10-15").WillReturnRows(sqlmock.NewRows([]string{"organization_id"}).AddRow(orgID))
+
+		orgs, err := store.GetForecastedOrganizations(date)
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{orgID}, orgs)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("2026-10-15").WillReturnError(fmt.Errorf("db error"))
+
+		orgs, err := store.GetForecastedOrganizations(date)
+		assert.Error(t, err)
+		assert.Nil(t, orgs)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetDayProgress(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresForecastVarianceStore(db, logger)
+
+	orgID := uuid.New()
+	now := time.Date(2026, 10, 15, 14, 5, 0, 0, time.UTC)
+	start := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`FROM demand WHERE organization_id = $1 AND demand_date = $2 AND hour < $3`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, "2026-10-15", 14, start, start.Add(14*time.Hour)).
+			WillReturnRows(sqlmock.NewRows([]string{"forecast", "actual"}).AddRow(50, 66))
+
+		progress, err := store.GetDayProgress(orgID, now, 14)
+		assert.NoError(t, err)
+		assert.Equal(t, &database.DayProgress{Date: start, ThroughHour: 14, ForecastOrders: 50, ActualOrders: 66}, progress)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("db error"))
+
+		progress, err := store.GetDayProgress(orgID, now, 14)
+		assert.Error(t, err)
+		assert.Nil(t, progress)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetOnDutyManagerEmails(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresForecastVarianceStore(db, logger)
+
+	orgID := uuid.New()
+	at := time.Now()
+
+	t.Run("OnDuty", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`WHERE u.organization_id = $1 AND u.user_role IN ('admin', 'manager') AND s.shift_type = 'working'`)).WithArgs(orgID, at).
+			WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("manager@example.com"))
+
+		emails, err := store.GetOnDutyManagerEmails(orgID, at)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"manager@example.com"}, emails)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("StaffOnShift", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(DISTINCT s.employee_id)`)).WithArgs(orgID, at).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(6))
+
+		staff, err := store.GetStaffOnShift(orgID, at)
+		assert.NoError(t, err)
+		assert.Equal(t, 6, staff)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestForecastVarianceAlerts(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresForecastVarianceStore(db, logger)
+
+	orgID := uuid.New()
+	date := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	createdAt := date.Add(14 * time.Hour)
+
+	t.Run("StoreAlert", func(t *testing.T) {
+		alert := &database.ForecastVarianceAlert{
+			OrganizationID: orgID, Date: date, ThroughHour: 14, ForecastOrders: 50, ActualOrders: 66, VariancePercent: 32,
+			Direction: database.VarianceAbove, Suggestion: database.SuggestCallIn, StaffOnShift: 6, SuggestedStaffChange: 2,
+			NotifiedManagers: 1, CreatedAt: createdAt,
+		}
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO forecast_variance_alerts`)).
+			WithArgs(sqlmock.AnyArg(), orgID, "2026-10-15", 14, 50, 66, 32.0, "above", "call_in", 6, 2, 1, createdAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.StoreAlert(alert)
+		assert.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, alert.ID)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("GetLatestAlert", func(t *testing.T) {
+		alertID := uuid.New()
+		mock.ExpectQuery(regexp.QuoteMeta(`WHERE organization_id = $1 AND alert_date = $2 AND direction = $3`)).
+			WithArgs(orgID, "2026-10-15", "below").
+			WillReturnRows(sqlmock.NewRows(forecastVarianceAlertColumns).
+				AddRow(alertID, orgID, date, 12, 40, 24, -40.0, "below", "send_home", 5, 2, 2, createdAt))
+
+		alert, err := store.GetLatestAlert(orgID, date, database.VarianceBelow)
+		assert.NoError(t, err)
+		assert.Equal(t, alertID, alert.ID)
+		assert.Equal(t, -40.0, alert.VariancePercent)
+		assert.Equal(t, database.SuggestSendHome, alert.Suggestion)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("GetLatestAlert_None", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM forecast_variance_alerts`)).WillReturnError(sql.ErrNoRows)
+
+		alert, err := store.GetLatestAlert(orgID, date, database.VarianceAbove)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Nil(t, alert)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("ListAlerts", func(t *testing.T) {
+		from := date.AddDate(0, 0, -30)
+		to := date.AddDate(0, 0, 1)
+		mock.ExpectQuery(regexp.QuoteMeta(`WHERE a.organization_id = $1 AND a.alert_date >= $2 AND a.alert_date < $3`)).
+			WithArgs(orgID, "2026-09-15", "2026-10-16").
+			WillReturnRows(sqlmock.NewRows(append(forecastVarianceAlertColumns, "day_forecast", "day_actual")).
+				AddRow(uuid.New(), orgID, date, 14, 50, 66, 32.0, "above", "call_in", 6, 2, 1, createdAt, 110, 131))
+
+		alerts, err := store.ListAlerts(orgID, from, to)
+		assert.NoError(t, err)
+		assert.Len(t, alerts, 1)
+		assert.Equal(t, 110, *alerts[0].DayForecastOrders)
+		assert.Equal(t, 131, *alerts[0].DayActualOrders)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("ListAlerts_DBError", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM forecast_variance_alerts a`)).WillReturnError(fmt.Errorf("db error"))
+
+		alerts, err := store.ListAlerts(orgID, date, date.AddDate(0, 0, 1))
+		assert.Error(t, err)
+		assert.Nil(t, alerts)
+		AssertExpectations(t, mock)
+	})
+}
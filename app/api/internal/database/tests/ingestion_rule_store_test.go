@@ -0,0 +1,103 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetIngestionRulesForDataset(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresIngestionRuleStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`FROM ingestion_rules WHERE organization_id = $1 AND dataset = $2`)
+	columns := []string{"id", "dataset", "field", "operator", "value", "message", "created_by", "created_at"}
+
+	t.Run("Success", func(t *testing.T) {
+		adminID := uuid.New()
+		rows := sqlmock.NewRows(columns).
+			AddRow(uuid.New(), "orders", "rating", "min", "1", nil, nil, time.Now()).
+			AddRow(uuid.New(), "orders", "total_amount", "max", "2000", "Orders over $2,000 are typos", adminID, time.Now())
+		mock.ExpectQuery(query).WithArgs(orgID, "orders").WillReturnRows(rows)
+
+		rules, err := store.GetRulesForDataset(orgID, "orders")
+		assert.NoError(t, err)
+		if assert.Len(t, rules, 2) {
+			assert.Nil(t, rules[0].Message)
+			assert.Nil(t, rules[0].CreatedBy)
+			assert.Equal(t, "Orders over $2,000 are typos", *rules[1].Message)
+			assert.Equal(t, adminID, *rules[1].CreatedBy)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, "orders").WillReturnError(fmt.Errorf("db error"))
+
+		rules, err := store.GetRulesForDataset(orgID, "orders")
+		assert.Error(t, err)
+		assert.Nil(t, rules)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestCreateIngestionRule(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresIngestionRuleStore(db, logger)
+
+	orgID := uuid.New()
+	adminID := uuid.New()
+	query := regexp.QuoteMeta(`INSERT INTO ingestion_rules (id, organization_id, dataset, field, operator, value, message, created_by, created_at)`)
+
+	t.Run("Success", func(t *testing.T) {
+		rule := &database.IngestionRule{Dataset: "orders", Field: "rating", Operator: "max", Value: "5", CreatedBy: &adminID}
+		mock.ExpectExec(query).
+			WithArgs(sqlmock.AnyArg(), orgID, "orders", "rating", "max", "5", nil, &adminID, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		assert.NoError(t, store.CreateRule(orgID, rule))
+		assert.NotEqual(t, uuid.Nil, rule.ID)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectExec(query).WillReturnError(fmt.Errorf("db error"))
+
+		assert.Error(t, store.CreateRule(orgID, &database.IngestionRule{Dataset: "items", Field: "price", Operator: "required"}))
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestDeleteIngestionRule(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresIngestionRuleStore(db, logger)
+
+	orgID := uuid.New()
+	ruleID := uuid.New()
+	query := regexp.QuoteMeta(`DELETE FROM ingestion_rules WHERE id = $1 AND organization_id = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(ruleID, orgID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.DeleteRule(orgID, ruleID))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(ruleID, orgID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.Equal(t, sql.ErrNoRows, store.DeleteRule(orgID, ruleID))
+		AssertExpectations(t, mock)
+	})
+}
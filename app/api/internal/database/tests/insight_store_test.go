@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/clockwise/clockwise/backend/internal/database"
@@ -21,16 +22,16 @@ func TestGetInsightsForAdmin(t *testing.T) {
 	// Regex patterns to match the multi-line queries defined in insight_store.go
 	qNumEmployees := regexp.QuoteMeta(`SELECT COUNT(*) FROM users WHERE organization_id = $1 AND user_role != 'admin'`)
 	qEmpPerRole := regexp.QuoteMeta(`SELECT user_role, COUNT(*) as count FROM users WHERE organization_id = $1 AND user_role != 'admin' GROUP BY user_role`)
-	qAvgSalary := regexp.QuoteMeta(`SELECT COALESCE(AVG(salary_per_hour), 0) FROM users WHERE organization_id = $1 AND user_role != 'admin'`)
-	qAvgSalaryRole := regexp.QuoteMeta(`SELECT user_role, COALESCE(AVG(salary_per_hour), 0) as avg_salary FROM users WHERE organization_id = $1 AND user_role != 'admin' GROUP BY user_role`)
+	qSalaries := regexp.QuoteMeta(`SELECT user_role, salary_per_hour, salary_per_hour_encrypted FROM users WHERE organization_id = $1 AND user_role != 'admin' ORDER BY user_role`)
 	qNumTables := regexp.QuoteMeta(`SELECT COUNT(*) FROM tables WHERE organization_id = $1`)
 	qMaxCapacity := regexp.QuoteMeta(`SELECT COALESCE(SUM(number_of_people), 0) FROM tables WHERE organization_id = $1`)
 	qCurrPeople := regexp.QuoteMeta(`SELECT COALESCE(SUM(number_of_people), 0) FROM order_tables WHERE organization_id = $1 AND start_time <= $2 AND end_time >= $2`)
 	qAvgOrders := `SELECT COALESCE\(AVG\(daily_count\), 0\) FROM .*`
 	qOrdersToday := regexp.QuoteMeta(`SELECT COUNT(*) FROM orders WHERE organization_id = $1 AND DATE(create_time) = CURRENT_DATE`)
 	qOrdersType := regexp.QuoteMeta(`SELECT order_type, COUNT(*) as count FROM orders WHERE organization_id = $1 GROUP BY order_type`)
-	qRevenue := regexp.QuoteMeta(`SELECT COALESCE(SUM(i.price), 0) FROM orders o JOIN order_items oi ON o.id = oi.order_id JOIN items i ON oi.item_id = i.id WHERE o.organization_id = $1`)
-	qShiftEmp := regexp.QuoteMeta(`SELECT u.user_role, COUNT(*) as count FROM users u JOIN schedules s ON u.id = s.employee_id WHERE u.organization_id = $1 AND (s.schedule_date + s.start_hour) <= $2 AND (s.schedule_date + s.end_hour) >= $2 GROUP BY u.user_role`)
+	qRevenue := regexp.QuoteMeta(`SELECT COALESCE(SUM(i.price), 0) FROM orders o JOIN order_items oi ON o.id = oi.order_id JOIN items i ON oi.item_id = i.id WHERE o.organization_id = $1 AND NOT EXISTS (SELECT 1 FROM staff_orders so WHERE so.order_id = o.id)`)
+	qStaffOrders := regexp.QuoteMeta(`SELECT COUNT(*), COALESCE(SUM(o.discount_amount), 0) FROM staff_orders so JOIN orders o ON o.id = so.order_id WHERE so.organization_id = $1 AND o.create_time >= date_trunc('month', CURRENT_DATE)`)
+	qShiftEmp := regexp.QuoteMeta(`SELECT u.user_role, COUNT(*) as count FROM users u JOIN schedules s ON u.id = s.employee_id WHERE u.organization_id = $1 AND s.shift_type = 'working' AND (s.schedule_date + s.start_hour) <= $2 AND (s.schedule_date + s.end_hour) >= $2 GROUP BY u.user_role`)
 	qMostSelling := regexp.QuoteMeta(`SELECT i.name, COUNT(oi.item_id) as sold_count FROM items i JOIN order_items oi ON i.id = oi.item_id JOIN orders o ON oi.order_id = o.id WHERE o.organization_id = $1 GROUP BY i.id, i.name ORDER BY sold_count DESC LIMIT 5`)
 
 	t.Run("Success", func(t *testing.T) {
@@ -42,12 +43,11 @@ func TestGetInsightsForAdmin(t *testing.T) {
 			sqlmock.NewRows([]string{"user_role", "count"}).AddRow("server", 5).AddRow("chef", 5),
 		)
 
-		// 3. Average Salary (1 Item)
-		mock.ExpectQuery(qAvgSalary).WithArgs(orgID).WillReturnRows(NewRow(25.50))
-
-		// 4. Average Salary per Role (2 Items: server, chef)
-		mock.ExpectQuery(qAvgSalaryRole).WithArgs(orgID).WillReturnRows(
-			sqlmock.NewRows([]string{"user_role", "avg_salary"}).AddRow("server", 20.0).AddRow("chef", 30.0),
+		// 3. Average Salary (1 Item) and 4. Average Salary per Role (2 Items: chef, server)
+		mock.ExpectQuery(qSalaries).WithArgs(orgID).WillReturnRows(
+			sqlmock.NewRows([]string{"user_role", "salary_per_hour", "salary_per_hour_encrypted"}).
+				AddRow("chef", 30.0, nil).
+				AddRow("server", 20.0, nil),
 		)
 
 		// 5. Number of Tables (1 Item)
@@ -73,6 +73,11 @@ func TestGetInsightsForAdmin(t *testing.T) {
 		// 11. Total Revenue (1 Item)
 		mock.ExpectQuery(qRevenue).WithArgs(orgID).WillReturnRows(NewRow(1500.75))
 
+		// Staff Meals & Discounts (1 Item)
+		mock.ExpectQuery(qStaffOrders).WithArgs(orgID).WillReturnRows(
+			sqlmock.NewRows([]string{"count", "discount"}).AddRow(4, 22.5),
+		)
+
 		// 12. Employees in Shift (1 Item: server)
 		mock.ExpectQuery(qShiftEmp).WithArgs(orgID, sqlmock.AnyArg()).WillReturnRows(
 			sqlmock.NewRows([]string{"user_role", "count"}).AddRow("server", 3),
@@ -86,13 +91,17 @@ func TestGetInsightsForAdmin(t *testing.T) {
 		insights, err := store.GetInsightsForAdmin(orgID)
 
 		assert.NoError(t, err)
-		// Corrected calculation: 1 + 2 + 1 + 2 + 1 + 1 + 1 + 1 + 1 + 2 + 1 + 1 + 1 = 16 items
-		assert.Len(t, insights, 16)
+		// Corrected calculation: 1 + 2 + 1 + 2 + 1 + 1 + 1 + 1 + 1 + 2 + 1 + 1 + 1 + 1 = 17 items
+		assert.Len(t, insights, 17)
 
 		assert.Equal(t, "Number of Employees", insights[0].Title)
 		assert.Equal(t, "10", insights[0].Statistic)
 
-		// Verify the LAST item is Most Selling Items (Index 15)
+		assert.Equal(t, "Total Revenue", insights[13].Title)
+		assert.Equal(t, "Staff Meals & Discounts (This Month)", insights[14].Title)
+		assert.Equal(t, "4 orders, $22.50", insights[14].Statistic)
+
+		// Verify the LAST item is Most Selling Items (Index 16)
 		lastIdx := len(insights) - 1
 		assert.Equal(t, "Most Selling Items", insights[lastIdx].Title)
 		assert.Contains(t, insights[lastIdx].Statistic, "1. Burger (100)")
@@ -119,19 +128,19 @@ func TestGetInsightsForManager(t *testing.T) {
 	orgID := uuid.New()
 	managerID := uuid.New()
 
-	qManagerSalary := regexp.QuoteMeta(`SELECT COALESCE(salary_per_hour, 0) FROM users WHERE id = $1 AND organization_id = $2`)
+	qManagerSalary := regexp.QuoteMeta(`SELECT salary_per_hour, salary_per_hour_encrypted FROM users WHERE id = $1 AND organization_id = $2`)
 	qEmpPerRole := regexp.QuoteMeta(`SELECT user_role, COUNT(*) as count FROM users WHERE organization_id = $1 AND user_role != 'admin' GROUP BY user_role`)
 	qNumTables := regexp.QuoteMeta(`SELECT COUNT(*) FROM tables WHERE organization_id = $1`)
 	qMaxCapacity := regexp.QuoteMeta(`SELECT COALESCE(SUM(number_of_people), 0) FROM tables WHERE organization_id = $1`)
 	qCurrPeople := regexp.QuoteMeta(`SELECT COALESCE(SUM(number_of_people), 0) FROM order_tables WHERE organization_id = $1 AND start_time <= $2 AND end_time >= $2`)
 	qOrdersToday := regexp.QuoteMeta(`SELECT COUNT(*) FROM orders WHERE organization_id = $1 AND DATE(create_time) = CURRENT_DATE`)
-	qShiftEmp := regexp.QuoteMeta(`SELECT u.user_role, COUNT(*) as count FROM users u JOIN schedules s ON u.id = s.employee_id WHERE u.organization_id = $1 AND (s.schedule_date + s.start_hour) <= $2 AND (s.schedule_date + s.end_hour) >= $2 GROUP BY u.user_role`)
+	qShiftEmp := regexp.QuoteMeta(`SELECT u.user_role, COUNT(*) as count FROM users u JOIN schedules s ON u.id = s.employee_id WHERE u.organization_id = $1 AND s.shift_type = 'working' AND (s.schedule_date + s.start_hour) <= $2 AND (s.schedule_date + s.end_hour) >= $2 GROUP BY u.user_role`)
 	qOrdersType := regexp.QuoteMeta(`SELECT order_type, COUNT(*) as count FROM orders WHERE organization_id = $1 GROUP BY order_type`)
 	qDeliveries := regexp.QuoteMeta(`SELECT COUNT(*) FROM orders WHERE organization_id = $1 AND order_type = 'delivery' AND DATE(create_time) = CURRENT_DATE`)
 
 	t.Run("Success", func(t *testing.T) {
 		// 1. Manager Salary (1 Item)
-		mock.ExpectQuery(qManagerSalary).WithArgs(managerID, orgID).WillReturnRows(NewRow(35.00))
+		mock.ExpectQuery(qManagerSalary).WithArgs(managerID, orgID).WillReturnRows(sqlmock.NewRows([]string{"salary_per_hour", "salary_per_hour_encrypted"}).AddRow(35.00, nil))
 
 		// 2. Emp Per Role (2 Items: staff, intern)
 		mock.ExpectQuery(qEmpPerRole).WithArgs(orgID).WillReturnRows(
@@ -183,19 +192,19 @@ func TestGetInsightsForEmployee(t *testing.T) {
 	orgID := uuid.New()
 	employeeID := uuid.New()
 
-	qEmpSalary := regexp.QuoteMeta(`SELECT COALESCE(salary_per_hour, 0) FROM users WHERE id = $1 AND organization_id = $2`)
+	qEmpSalary := regexp.QuoteMeta(`SELECT salary_per_hour, salary_per_hour_encrypted FROM users WHERE id = $1 AND organization_id = $2`)
 	qRole := regexp.QuoteMeta(`SELECT user_role FROM users WHERE id = $1 AND organization_id = $2`)
 	qNumTables := regexp.QuoteMeta(`SELECT COUNT(*) FROM tables WHERE organization_id = $1`)
-	qManagers := regexp.QuoteMeta(`SELECT u.full_name FROM users u JOIN schedules s ON u.id = s.employee_id WHERE u.organization_id = $1 AND u.user_role = 'manager' AND (s.schedule_date + s.start_hour) <= $2 AND (s.schedule_date + s.end_hour) >= $2`)
+	qManagers := regexp.QuoteMeta(`SELECT u.full_name FROM users u JOIN schedules s ON u.id = s.employee_id WHERE u.organization_id = $1 AND u.user_role = 'manager' AND s.shift_type = 'working' AND (s.schedule_date + s.start_hour) <= $2 AND (s.schedule_date + s.end_hour) >= $2`)
 	qMaxCapacity := regexp.QuoteMeta(`SELECT COALESCE(SUM(number_of_people), 0) FROM tables WHERE organization_id = $1`)
 	qCurrPeople := regexp.QuoteMeta(`SELECT COALESCE(SUM(number_of_people), 0) FROM order_tables WHERE organization_id = $1 AND start_time <= $2 AND end_time >= $2`)
 	qOrdersToday := regexp.QuoteMeta(`SELECT COUNT(*) FROM orders WHERE organization_id = $1 AND DATE(create_time) = CURRENT_DATE`)
-	qShiftEmp := regexp.QuoteMeta(`SELECT u.user_role, COUNT(*) as count FROM users u JOIN schedules s ON u.id = s.employee_id WHERE u.organization_id = $1 AND (s.schedule_date + s.start_hour) <= $2 AND (s.schedule_date + s.end_hour) >= $2 GROUP BY u.user_role`)
+	qShiftEmp := regexp.QuoteMeta(`SELECT u.user_role, COUNT(*) as count FROM users u JOIN schedules s ON u.id = s.employee_id WHERE u.organization_id = $1 AND s.shift_type = 'working' AND (s.schedule_date + s.start_hour) <= $2 AND (s.schedule_date + s.end_hour) >= $2 GROUP BY u.user_role`)
 	qOrdersTypeToday := regexp.QuoteMeta(`SELECT order_type, COUNT(*) as count FROM orders WHERE organization_id = $1 AND DATE(create_time) = CURRENT_DATE GROUP BY order_type`)
 
 	t.Run("Success", func(t *testing.T) {
 		// 1. Salary (1 Item)
-		mock.ExpectQuery(qEmpSalary).WithArgs(employeeID, orgID).WillReturnRows(NewRow(15.00))
+		mock.ExpectQuery(qEmpSalary).WithArgs(employeeID, orgID).WillReturnRows(sqlmock.NewRows([]string{"salary_per_hour", "salary_per_hour_encrypted"}).AddRow(15.00, nil))
 
 		// 2. Role (1 Item)
 		mock.ExpectQuery(qRole).WithArgs(employeeID, orgID).WillReturnRows(NewRow("server"))
@@ -238,7 +247,7 @@ func TestGetInsightsForEmployee(t *testing.T) {
 	})
 
 	t.Run("NoManagerOnShift", func(t *testing.T) {
-		mock.ExpectQuery(qEmpSalary).WithArgs(employeeID, orgID).WillReturnRows(NewRow(15.00))
+		mock.ExpectQuery(qEmpSalary).WithArgs(employeeID, orgID).WillReturnRows(sqlmock.NewRows([]string{"salary_per_hour", "salary_per_hour_encrypted"}).AddRow(15.00, nil))
 		mock.ExpectQuery(qRole).WithArgs(employeeID, orgID).WillReturnRows(NewRow("server"))
 		mock.ExpectQuery(qNumTables).WithArgs(orgID).WillReturnRows(NewRow(10))
 
@@ -258,3 +267,89 @@ func TestGetInsightsForEmployee(t *testing.T) {
 		assert.Equal(t, "No manager on shift", insights[3].Statistic)
 	})
 }
+
+func TestGetHourlyOrders(t *testing.T) {
+	db, mock := NewTestDB(t)
+	store := &database.PostgresInsightStore{DB: db, Logger: NewTestLogger()}
+	orgID := uuid.New()
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 0, 7)
+
+	t.Run("Period", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM orders WHERE organization_id = $1 AND create_time >= $2 AND create_time < $3 GROUP BY 1`)).
+			WithArgs(orgID, &from, &to).
+			WillReturnRows(sqlmock.NewRows([]string{"hour", "count", "revenue"}).AddRow(12, 8, 160.5).AddRow(19, 11, 240.0))
+
+		hours, err := store.GetHourlyOrders(orgID, &from, &to)
+
+		assert.NoError(t, err)
+		assert.Len(t, hours, 24)
+		assert.Equal(t, database.HourlyOrders{Hour: 12, Orders: 8, Revenue: 160.5}, hours[12])
+		assert.Equal(t, 11, hours[19].Orders)
+		assert.Equal(t, database.HourlyOrders{Hour: 3}, hours[3])
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("AllTime", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM orders WHERE organization_id = $1 GROUP BY 1`)).
+			WithArgs(orgID).
+			WillReturnError(fmt.Errorf("db error"))
+
+		hours, err := store.GetHourlyOrders(orgID, nil, nil)
+
+		assert.Error(t, err)
+		assert.Nil(t, hours)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetTopItems(t *testing.T) {
+	db, mock := NewTestDB(t)
+	store := &database.PostgresInsightStore{DB: db, Logger: NewTestLogger()}
+	orgID := uuid.New()
+	burger, fries := uuid.New(), uuid.New()
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE o.organization_id = $1 AND o.create_time >= $2 GROUP BY i.id, i.name ORDER BY COUNT(oi.item_id) DESC, i.name LIMIT $3`)).
+		WithArgs(orgID, &from, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "count", "quantity", "revenue"}).
+			AddRow(burger, "Burger", 100, 130, 1300.0).
+			AddRow(fries, "Fries", 90, 95, 285.0))
+
+	items, err := store.GetTopItems(orgID, &from, nil, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []database.TopItem{
+		{ItemID: burger, Name: "Burger", SoldCount: 100, Quantity: 130, Revenue: 1300},
+		{ItemID: fries, Name: "Fries", SoldCount: 90, Quantity: 95, Revenue: 285},
+	}, items)
+	AssertExpectations(t, mock)
+}
+
+func TestGetDeliveryOrdersToday(t *testing.T) {
+	db, mock := NewTestDB(t)
+	store := &database.PostgresInsightStore{DB: db, Logger: NewTestLogger()}
+	orgID := uuid.New()
+	delivered, pending, driverID := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`LEFT JOIN deliveries d ON d.order_id = o.id LEFT JOIN users u ON u.id = d.driver_id WHERE o.organization_id = $1 AND o.order_type = 'delivery' AND DATE(o.create_time) = CURRENT_DATE`)).
+		WithArgs(orgID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "create_time", "order_status", "total_amount", "driver_id", "full_name", "status", "out_for_delivery_time", "delivered_time"}).
+			AddRow(pending, now, "incompleted", 18.0, nil, nil, nil, nil, nil).
+			AddRow(delivered, now.Add(-time.Hour), "completed", 32.5, driverID, "Dan", "delivered", now.Add(-50*time.Minute), now.Add(-20*time.Minute)))
+
+	orders, err := store.GetDeliveryOrdersToday(orgID)
+
+	assert.NoError(t, err)
+	if assert.Len(t, orders, 2) {
+		assert.Equal(t, pending, orders[0].OrderID)
+		assert.Nil(t, orders[0].DriverID)
+		assert.Nil(t, orders[0].DeliveryStatus)
+		assert.Equal(t, &driverID, orders[1].DriverID)
+		assert.Equal(t, "Dan", *orders[1].DriverName)
+		assert.Equal(t, "delivered", *orders[1].DeliveryStatus)
+		assert.NotNil(t, orders[1].DeliveredTime)
+	}
+	AssertExpectations(t, mock)
+}
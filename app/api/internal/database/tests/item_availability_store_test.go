@@ -0,0 +1,98 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAvailabilityWindows(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresItemAvailabilityStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`FROM item_availability WHERE organization_id = $1`)
+
+	t.Run("Success", func(t *testing.T) {
+		id, itemID := uuid.New(), uuid.New()
+		rows := sqlmock.NewRows([]string{"id", "item_id", "start_date", "end_date", "start_time", "end_time"}).
+			AddRow(id, itemID, "2025-09-15", "2025-11-30", nil, nil)
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnRows(rows)
+
+		windows, err := store.GetAvailabilityWindows(orgID)
+		assert.NoError(t, err)
+		assert.Len(t, windows, 1)
+		assert.Equal(t, itemID, windows[0].ItemID)
+		assert.Equal(t, "2025-09-15", *windows[0].StartDate)
+		assert.Nil(t, windows[0].StartTime)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnError(fmt.Errorf("db error"))
+
+		windows, err := store.GetAvailabilityWindows(orgID)
+		assert.Error(t, err)
+		assert.Nil(t, windows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestSetItemAvailability(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresItemAvailabilityStore(db, logger)
+
+	orgID := uuid.New()
+	itemID := uuid.New()
+	start, end := "11:30", "14:30"
+	windows := []database.ItemAvailabilityWindow{{StartTime: &start, EndTime: &end}}
+
+	existsQuery := regexp.QuoteMeta(`SELECT EXISTS(SELECT 1 FROM items WHERE id = $1 AND organization_id = $2)`)
+	deleteQuery := regexp.QuoteMeta(`DELETE FROM item_availability WHERE organization_id = $1 AND item_id = $2`)
+	insertQuery := regexp.QuoteMeta(`INSERT INTO item_availability (id, organization_id, item_id, start_date, end_date, start_time, end_time) VALUES ($1, $2, $3, $4, $5, $6, $7)`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(existsQuery).WithArgs(itemID, orgID).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectExec(deleteQuery).WithArgs(orgID, itemID).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(insertQuery).
+			WithArgs(sqlmock.AnyArg(), orgID, itemID, nil, nil, &start, &end).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		err := store.SetItemAvailability(orgID, itemID, windows)
+		assert.NoError(t, err)
+		assert.Equal(t, itemID, windows[0].ItemID)
+		assert.NotEqual(t, uuid.Nil, windows[0].ID)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("ItemNotFound", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(existsQuery).WithArgs(itemID, orgID).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectRollback()
+
+		err := store.SetItemAvailability(orgID, itemID, windows)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("RollbackOnError", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(existsQuery).WithArgs(itemID, orgID).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectExec(deleteQuery).WithArgs(orgID, itemID).WillReturnError(fmt.Errorf("delete failed"))
+		mock.ExpectRollback()
+
+		err := store.SetItemAvailability(orgID, itemID, windows)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
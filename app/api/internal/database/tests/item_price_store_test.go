@@ -0,0 +1,79 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyPriceChanges(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresItemPriceStore(db, logger)
+
+	orgID, userID, itemID := uuid.New(), uuid.New(), uuid.New()
+	update := regexp.QuoteMeta(`UPDATE items SET price = $3 WHERE organization_id = $1 AND id = $2 AND price = $4`)
+	insert := regexp.QuoteMeta(`INSERT INTO item_price_history (organization_id, item_id, old_price, new_price, changed_by, reason)`)
+
+	t.Run("Success", func(t *testing.T) {
+		now := time.Now()
+		changes := []database.ItemPriceChange{{ItemID: itemID, OldPrice: 10, NewPrice: 10.5, ChangedBy: &userID}}
+		mock.ExpectBegin()
+		mock.ExpectExec(update).WithArgs(orgID, itemID, 10.5, 10.0).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(insert).WithArgs(orgID, itemID, 10.0, 10.5, &userID, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"changed_at"}).AddRow(now))
+		mock.ExpectCommit()
+
+		assert.NoError(t, store.ApplyPriceChanges(orgID, changes))
+		assert.Equal(t, now, changes[0].ChangedAt)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("PriceChanged", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(update).WithArgs(orgID, itemID, 10.5, 10.0).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := store.ApplyPriceChanges(orgID, []database.ItemPriceChange{{ItemID: itemID, OldPrice: 10, NewPrice: 10.5}})
+		assert.ErrorIs(t, err, database.ErrItemPriceChanged)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetPriceHistory(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresItemPriceStore(db, logger)
+
+	orgID, itemID := uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`WHERE h.organization_id = $1 AND h.item_id = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"item_id", "name", "old_price", "new_price", "changed_by", "reason", "changed_at"}).
+			AddRow(itemID, "Burger", 10.0, 10.5, nil, "Supplier costs", time.Now())
+		mock.ExpectQuery(query).WithArgs(orgID, itemID).WillReturnRows(rows)
+
+		history, err := store.GetPriceHistory(orgID, itemID)
+		assert.NoError(t, err)
+		if assert.Len(t, history, 1) {
+			assert.Equal(t, "Burger", history[0].ItemName)
+			assert.Equal(t, "Supplier costs", *history[0].Reason)
+			assert.Nil(t, history[0].ChangedBy)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, itemID).WillReturnError(fmt.Errorf("db error"))
+
+		_, err := store.GetPriceHistory(orgID, itemID)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreatePosting(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresJobPostingStore(db, logger)
+
+	orgID := uuid.New()
+	reason := "Unmet demand: 120.0 items total"
+	posting := &database.JobPosting{
+		OrganizationID:       orgID,
+		Role:                 "server",
+		Title:                "Server",
+		Openings:             2,
+		Status:               "open",
+		Source:               "recommendation",
+		RecommendationReason: &reason,
+	}
+	query := regexp.QuoteMeta(`INSERT INTO job_postings (id, organization_id, role, title, description, openings, status, source, recommendation_reason, created_by, created_at)`)
+
+	mock.ExpectExec(query).
+		WithArgs(sqlmock.AnyArg(), orgID, "server", "Server", nil, 2, "open", "recommendation", &reason, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, store.CreatePosting(posting))
+	assert.NotEqual(t, uuid.Nil, posting.ID)
+	AssertExpectations(t, mock)
+}
+
+func TestGetPostings(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresJobPostingStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`(SELECT COUNT(*) FROM job_applicants a WHERE a.posting_id = p.id) FROM job_postings p WHERE p.organization_id = $1`)
+	columns := []string{"id", "organization_id", "role", "title", "description", "openings", "status", "source", "recommendation_reason", "created_by", "created_at", "applicants"}
+
+	t.Run("Success", func(t *testing.T) {
+		managerID := uuid.New()
+		rows := sqlmock.NewRows(columns).
+			AddRow(uuid.New(), orgID, "server", "Server", nil, 2, "open", "manual", nil, managerID, time.Now(), 3).
+			AddRow(uuid.New(), orgID, "cook", "Cook", nil, 1, "closed", "manual", nil, nil, time.Now(), 0)
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnRows(rows)
+
+		postings, err := store.GetPostings(orgID)
+		assert.NoError(t, err)
+		if assert.Len(t, postings, 2) {
+			assert.Equal(t, 3, *postings[0].ApplicantCount)
+			assert.Equal(t, managerID, *postings[0].CreatedBy)
+			assert.Nil(t, postings[1].CreatedBy)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnError(fmt.Errorf("db error"))
+
+		postings, err := store.GetPostings(orgID)
+		assert.Error(t, err)
+		assert.Nil(t, postings)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestCreateApplicant(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresJobPostingStore(db, logger)
+
+	orgID := uuid.New()
+	postingID := uuid.New()
+	query := regexp.QuoteMeta(`INSERT INTO job_applicants (id, posting_id, organization_id, full_name, email, phone, message, stage, created_at, updated_at)`)
+
+	t.Run("Success", func(t *testing.T) {
+		applicant := &database.JobApplicant{PostingID: postingID, FullName: "Jamie Doe", Email: "jamie@example.com"}
+		mock.ExpectExec(query).
+			WithArgs(sqlmock.AnyArg(), postingID, orgID, "Jamie Doe", "jamie@example.com", nil, nil, "applied", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		assert.NoError(t, store.CreateApplicant(orgID, applicant))
+		assert.Equal(t, "applied", applicant.Stage)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Duplicate", func(t *testing.T) {
+		applicant := &database.JobApplicant{PostingID: postingID, FullName: "Jamie Doe", Email: "JAMIE@example.com"}
+		mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, store.CreateApplicant(orgID, applicant), database.ErrDuplicateApplication)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestSetApplicantStage(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresJobPostingStore(db, logger)
+
+	orgID := uuid.New()
+	applicantID := uuid.New()
+	query := regexp.QuoteMeta(`UPDATE job_applicants SET stage = $3, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND organization_id = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(applicantID, orgID, "interview").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.SetApplicantStage(orgID, applicantID, "interview"))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(applicantID, orgID, "interview").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, store.SetApplicantStage(orgID, applicantID, "interview"), sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
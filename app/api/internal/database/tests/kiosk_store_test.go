@@ -0,0 +1,243 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var kioskDeviceColumns = []string{"id", "organization_id", "name", "created_by", "created_at", "last_seen_at", "revoked_at"}
+
+func TestCreateKioskDevice(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresKioskStore(db, logger)
+
+	orgID, adminID := uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`INSERT INTO kiosk_devices (organization_id, name, token_hash, created_by)`)
+
+	t.Run("Success_StoresTokenHash", func(t *testing.T) {
+		deviceID := uuid.New()
+		now := time.Now()
+		mock.ExpectQuery(query).WithArgs(orgID, "Front counter", database.HashKioskToken("kiosk_abc"), &adminID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(deviceID, now))
+
+		device := &database.KioskDevice{Name: "Front counter", CreatedBy: &adminID}
+		err := store.CreateDevice(orgID, device, "kiosk_abc")
+		assert.NoError(t, err)
+		assert.Equal(t, deviceID, device.ID)
+		assert.Equal(t, orgID, device.OrganizationID)
+		assert.Len(t, database.HashKioskToken("kiosk_abc"), 64)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("db error"))
+
+		err := store.CreateDevice(orgID, &database.KioskDevice{Name: "Front counter"}, "kiosk_abc")
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetKioskDeviceByToken(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresKioskStore(db, logger)
+
+	query := regexp.QuoteMeta(`UPDATE kiosk_devices SET last_seen_at = NOW()
+		WHERE token_hash = $1 AND revoked_at IS NULL`)
+
+	t.Run("Success", func(t *testing.T) {
+		deviceID, orgID := uuid.New(), uuid.New()
+		now := time.Now()
+		mock.ExpectQuery(query).WithArgs(database.HashKioskToken("kiosk_abc")).
+			WillReturnRows(sqlmock.NewRows(kioskDeviceColumns).AddRow(deviceID, orgID, "Front counter", nil, now, now, nil))
+
+		device, err := store.GetDeviceByToken("kiosk_abc")
+		assert.NoError(t, err)
+		assert.Equal(t, deviceID, device.ID)
+		assert.Equal(t, orgID, device.OrganizationID)
+		assert.Nil(t, device.RevokedAt)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("UnknownOrRevoked", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows(kioskDeviceColumns))
+
+		device, err := store.GetDeviceByToken("kiosk_old")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Nil(t, device)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestRevokeKioskDevice(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresKioskStore(db, logger)
+
+	orgID, deviceID := uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`UPDATE kiosk_devices SET revoked_at = NOW() WHERE id = $1 AND organization_id = $2 AND revoked_at IS NULL`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(deviceID, orgID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.RevokeDevice(orgID, deviceID))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(deviceID, orgID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, store.RevokeDevice(orgID, deviceID), sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestKioskPins(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresKioskStore(db, logger)
+
+	orgID, userID := uuid.New(), uuid.New()
+
+	t.Run("SetPin_ClearsLockout", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(`DO UPDATE SET pin_hash = EXCLUDED.pin_hash, failed_attempts = 0, locked_until = NULL`)).
+			WithArgs(userID, orgID, "hash").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.SetPin(orgID, userID, []byte("hash")))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("GetPin", func(t *testing.T) {
+		hash, err := database.Hash("1234")
+		assert.NoError(t, err)
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT pin_hash, failed_attempts, locked_until FROM kiosk_pins`)).WithArgs(userID, orgID).
+			WillReturnRows(sqlmock.NewRows([]string{"pin_hash", "failed_attempts", "locked_until"}).AddRow(string(hash), 2, nil))
+
+		pin, err := store.GetPin(orgID, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, pin.State.FailedAttempts)
+		match, err := pin.Pin.Matches("1234")
+		assert.NoError(t, err)
+		assert.True(t, match)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("GetPin_NoPin", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM kiosk_pins`)).WillReturnRows(sqlmock.NewRows([]string{"pin_hash", "failed_attempts", "locked_until"}))
+
+		pin, err := store.GetPin(orgID, userID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Nil(t, pin)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("RecordFailedPin_Locks", func(t *testing.T) {
+		lockedUntil := time.Now().Add(15 * time.Minute)
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE kiosk_pins
+		SET failed_attempts = CASE WHEN failed_attempts + 1 >= $3 THEN 0 ELSE failed_attempts + 1 END`)).
+			WithArgs(userID, orgID, 5, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"failed_attempts", "locked_until"}).AddRow(0, lockedUntil))
+
+		state, err := store.RecordFailedPin(orgID, userID, 5, 15*time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, state.IsLocked(time.Now()))
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestClockInOut(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresKioskStore(db, logger)
+
+	orgID, userID, deviceID := uuid.New(), uuid.New(), uuid.New()
+	clockInQuery := regexp.QuoteMeta(`INSERT INTO time_clock_entries (organization_id, employee_id, device_id, clock_in_at)`)
+	clockOutQuery := regexp.QuoteMeta(`UPDATE time_clock_entries SET clock_out_at = GREATEST($3, clock_in_at)
+		WHERE organization_id = $1 AND employee_id = $2 AND clock_out_at IS NULL`)
+	clockedIn := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	t.Run("ClockIn", func(t *testing.T) {
+		entryID := uuid.New()
+		mock.ExpectQuery(clockInQuery).WithArgs(orgID, userID, &deviceID, clockedIn).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(entryID))
+
+		entry := &database.TimeClockEntry{EmployeeID: userID, DeviceID: &deviceID, ClockInAt: clockedIn}
+		assert.NoError(t, store.ClockIn(orgID, entry))
+		assert.Equal(t, entryID, entry.ID)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("ClockIn_AlreadyClockedIn", func(t *testing.T) {
+		mock.ExpectQuery(clockInQuery).WillReturnError(&pq.Error{Code: "23505"})
+
+		err := store.ClockIn(orgID, &database.TimeClockEntry{EmployeeID: userID, ClockInAt: clockedIn})
+		assert.ErrorIs(t, err, database.ErrAlreadyClockedIn)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("ClockOut", func(t *testing.T) {
+		clockedOut := clockedIn.Add(8 * time.Hour)
+		mock.ExpectQuery(clockOutQuery).WithArgs(orgID, userID, clockedOut).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "organization_id", "employee_id", "device_id", "clock_in_at", "clock_out_at"}).
+				AddRow(uuid.New(), orgID, userID, deviceID, clockedIn, clockedOut))
+
+		entry, err := store.ClockOut(orgID, userID, clockedOut)
+		assert.NoError(t, err)
+		assert.Equal(t, clockedOut, *entry.ClockOutAt)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("ClockOut_NotClockedIn", func(t *testing.T) {
+		mock.ExpectQuery(clockOutQuery).WillReturnError(sql.ErrNoRows)
+
+		entry, err := store.ClockOut(orgID, userID, time.Now())
+		assert.ErrorIs(t, err, database.ErrNotClockedIn)
+		assert.Nil(t, entry)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestListTimeClockEntries(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresKioskStore(db, logger)
+
+	orgID, userID := uuid.New(), uuid.New()
+	from := time.Date(2026, 10, 9, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	query := regexp.QuoteMeta(`WHERE e.organization_id = $1 AND e.clock_in_at >= $2 AND e.clock_in_at < $3`)
+	columns := []string{"id", "organization_id", "employee_id", "full_name", "device_id", "clock_in_at", "clock_out_at"}
+
+	t.Run("Success", func(t *testing.T) {
+		clockedIn := from.Add(9 * time.Hour)
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(uuid.New(), orgID, userID, "Ada Lovelace", nil, clockedIn, nil))
+
+		entries, err := store.ListEntries(orgID, from, to)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+		assert.Equal(t, "Ada Lovelace", entries[0].EmployeeName)
+		assert.Nil(t, entries[0].ClockOutAt)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("db error"))
+
+		entries, err := store.ListEntries(orgID, from, to)
+		assert.Error(t, err)
+		assert.Nil(t, entries)
+		AssertExpectations(t, mock)
+	})
+}
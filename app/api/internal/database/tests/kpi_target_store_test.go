@@ -0,0 +1,188 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var kpiTargetColumns = []string{"organization_id", "weekly_revenue", "labor_cost_percent", "average_rating", "updated_by", "updated_at"}
+
+func TestGetKPITargets(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresKPITargetStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`FROM kpi_targets WHERE organization_id = $1`)
+
+	t.Run("Success", func(t *testing.T) {
+		updatedAt := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+		mock.ExpectQuery(query).WithArgs(orgID).
+			WillReturnRows(sqlmock.NewRows(kpiTargetColumns).AddRow(orgID, 7000.0, nil, 4.5, nil, updatedAt))
+
+		targets, err := store.GetTargets(orgID)
+		assert.NoError(t, err)
+		if assert.NotNil(t, targets) {
+			assert.Equal(t, 7000.0, *targets.WeeklyRevenue)
+			assert.Nil(t, targets.LaborCostPercent)
+			assert.Equal(t, 4.5, *targets.AverageRating)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NoneSet", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnRows(sqlmock.NewRows(kpiTargetColumns))
+
+		targets, err := store.GetTargets(orgID)
+		assert.NoError(t, err)
+		assert.Nil(t, targets)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("db error"))
+
+		targets, err := store.GetTargets(orgID)
+		assert.Error(t, err)
+		assert.Nil(t, targets)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestSetKPITargets(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresKPITargetStore(db, logger)
+
+	orgID, adminID := uuid.New(), uuid.New()
+	laborCost := 28.0
+	query := regexp.QuoteMeta(`ON CONFLICT (organization_id) DO UPDATE SET`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(orgID, nil, &laborCost, nil, &adminID, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		targets := &database.KPITargets{OrganizationID: orgID, LaborCostPercent: &laborCost, UpdatedBy: &adminID}
+		err := store.SetTargets(targets)
+		assert.NoError(t, err)
+		assert.False(t, targets.UpdatedAt.IsZero())
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectExec(query).WillReturnError(fmt.Errorf("db error"))
+
+		err := store.SetTargets(&database.KPITargets{OrganizationID: orgID, LaborCostPercent: &laborCost})
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestDeleteKPITargets(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresKPITargetStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`DELETE FROM kpi_targets WHERE organization_id = $1`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.DeleteTargets(orgID))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NoneSet", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, store.DeleteTargets(orgID), sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetKPISales(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresKPITargetStore(db, logger)
+
+	orgID := uuid.New()
+	from := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	query := regexp.QuoteMeta(`SELECT COALESCE(SUM(total_amount - discount_amount), 0), AVG(rating), COUNT(rating)`)
+	columns := []string{"revenue", "average_rating", "rated_orders"}
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).WillReturnRows(sqlmock.NewRows(columns).AddRow(6500.0, 4.6, 40))
+
+		sales, err := store.GetSales(orgID, from, to)
+		assert.NoError(t, err)
+		assert.Equal(t, 6500.0, sales.Revenue)
+		assert.Equal(t, 4.6, *sales.AverageRating)
+		assert.Equal(t, 40, sales.RatedOrders)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NoRatings", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).WillReturnRows(sqlmock.NewRows(columns).AddRow(0.0, nil, 0))
+
+		sales, err := store.GetSales(orgID, from, to)
+		assert.NoError(t, err)
+		assert.Nil(t, sales.AverageRating)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("db error"))
+
+		sales, err := store.GetSales(orgID, from, to)
+		assert.Error(t, err)
+		assert.Nil(t, sales)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestKPIReports(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresKPITargetStore(db, logger)
+
+	orgID := uuid.New()
+	weekStart := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+
+	t.Run("GetOrganizations", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`WHERE e.organization_id = t.organization_id AND e.week_start = $1`)).WithArgs(weekStart).
+			WillReturnRows(sqlmock.NewRows([]string{"organization_id"}).AddRow(orgID))
+
+		orgs, err := store.GetOrganizationsForKPIReport(weekStart)
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{orgID}, orgs)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("MarkSent", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO kpi_report_emails (organization_id, week_start) VALUES ($1, $2) ON CONFLICT DO NOTHING`)).
+			WithArgs(orgID, weekStart).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.MarkKPIReportSent(orgID, weekStart))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM kpi_targets t`)).WillReturnError(fmt.Errorf("db error"))
+
+		orgs, err := store.GetOrganizationsForKPIReport(weekStart)
+		assert.Error(t, err)
+		assert.Nil(t, orgs)
+		AssertExpectations(t, mock)
+	})
+}
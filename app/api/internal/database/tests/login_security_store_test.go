@@ -0,0 +1,108 @@
+package database
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordFailedLogin(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresLoginSecurityStore(db, logger)
+
+	userID := uuid.New()
+	query := regexp.QuoteMeta(`UPDATE users SET failed_login_attempts = CASE WHEN failed_login_attempts + 1 >= $2 THEN 0 ELSE failed_login_attempts + 1 END`)
+
+	t.Run("Counted", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(userID, 5, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"failed_login_attempts", "locked_until"}).AddRow(2, nil))
+
+		state, err := store.RecordFailedLogin(userID, 5, 15*time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, state.FailedAttempts)
+		assert.False(t, state.IsLocked(time.Now()))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Locked", func(t *testing.T) {
+		lockedUntil := time.Now().Add(15 * time.Minute)
+		mock.ExpectQuery(query).WithArgs(userID, 5, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"failed_login_attempts", "locked_until"}).AddRow(0, lockedUntil))
+
+		state, err := store.RecordFailedLogin(userID, 5, 15*time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, state.IsLocked(time.Now()))
+		assert.False(t, state.IsLocked(lockedUntil.Add(time.Second)))
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestUnlockAccount(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresLoginSecurityStore(db, logger)
+
+	userID := uuid.New()
+	query := regexp.QuoteMeta(`UPDATE users SET failed_login_attempts = 0, locked_until = NULL WHERE id = $1`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.UnlockAccount(userID))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.Equal(t, sql.ErrNoRows, store.UnlockAccount(userID))
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestRememberDevice(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresLoginSecurityStore(db, logger)
+
+	userID := uuid.New()
+	countQuery := regexp.QuoteMeta(`SELECT COUNT(*) FROM login_devices WHERE user_id = $1`)
+	upsertQuery := regexp.QuoteMeta(`INSERT INTO login_devices (user_id, device_hash, ip_address, user_agent)`)
+
+	t.Run("FirstLoginIsNotNew", func(t *testing.T) {
+		mock.ExpectQuery(countQuery).WithArgs(userID).WillReturnRows(NewRow(0))
+		mock.ExpectQuery(upsertQuery).WithArgs(userID, "hash", "10.0.0.1", "Browser").WillReturnRows(NewRow(true))
+
+		isNew, err := store.RememberDevice(userID, "hash", "10.0.0.1", "Browser")
+		assert.NoError(t, err)
+		assert.False(t, isNew)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NewDevice", func(t *testing.T) {
+		mock.ExpectQuery(countQuery).WithArgs(userID).WillReturnRows(NewRow(1))
+		mock.ExpectQuery(upsertQuery).WithArgs(userID, "other", "10.0.0.2", "Phone").WillReturnRows(NewRow(true))
+
+		isNew, err := store.RememberDevice(userID, "other", "10.0.0.2", "Phone")
+		assert.NoError(t, err)
+		assert.True(t, isNew)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("KnownDevice", func(t *testing.T) {
+		mock.ExpectQuery(countQuery).WithArgs(userID).WillReturnRows(NewRow(2))
+		mock.ExpectQuery(upsertQuery).WithArgs(userID, "hash", "10.0.0.1", "Browser").WillReturnRows(NewRow(false))
+
+		isNew, err := store.RememberDevice(userID, "hash", "10.0.0.1", "Browser")
+		assert.NoError(t, err)
+		assert.False(t, isNew)
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateMagicLink(t *testing.T) {
+	db, mock := NewTestDB(t)
+	store := database.NewPostgresMagicLinkStore(db, NewTestLogger())
+
+	userID := uuid.New()
+	expires := time.Now().Add(15 * time.Minute)
+
+	t.Run("StoresTokenHash", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO magic_links (user_id, token_hash, ip_address, expires_at) VALUES ($1, $2, $3, $4)`)).
+			WithArgs(userID, database.HashSignToken("secret-token"), "203.0.113.7", expires).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.CreateMagicLink(userID, "secret-token", "203.0.113.7", expires))
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestCountRecentMagicLinks(t *testing.T) {
+	db, mock := NewTestDB(t)
+	store := database.NewPostgresMagicLinkStore(db, NewTestLogger())
+
+	userID := uuid.New()
+	since := time.Now().Add(-time.Hour)
+
+	t.Run("PerUser", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM magic_links WHERE user_id = $1 AND created_at > $2`)).
+			WithArgs(userID, since).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+		forUser, err := store.CountRecentMagicLinks(userID, since)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, forUser)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestRecordMagicLinkRequest(t *testing.T) {
+	db, mock := NewTestDB(t)
+	store := database.NewPostgresMagicLinkStore(db, NewTestLogger())
+
+	since := time.Now().Add(-time.Hour)
+
+	t.Run("CountsThisRequest", func(t *testing.T) {
+		mock.ExpectQuery(`DELETE FROM magic_link_requests WHERE requested_at <= \$2.*INSERT INTO magic_link_requests \(ip_address\) VALUES \(\$1\).*SELECT COUNT\(\*\) \+ 1 FROM magic_link_requests`).
+			WithArgs("203.0.113.7", since).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(6))
+
+		fromIP, err := store.RecordMagicLinkRequest("203.0.113.7", since)
+		assert.NoError(t, err)
+		assert.Equal(t, 6, fromIP)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestConsumeMagicLink(t *testing.T) {
+	db, mock := NewTestDB(t)
+	store := database.NewPostgresMagicLinkStore(db, NewTestLogger())
+
+	query := regexp.QuoteMeta(`UPDATE magic_links SET used_at = NOW() WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW() RETURNING user_id`)
+
+	t.Run("Success", func(t *testing.T) {
+		userID := uuid.New()
+		mock.ExpectQuery(query).WithArgs(database.HashSignToken("token")).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(userID))
+
+		consumed, err := store.ConsumeMagicLink("token")
+		assert.NoError(t, err)
+		assert.Equal(t, userID, consumed)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("UsedOrExpired", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(database.HashSignToken("token")).WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+
+		_, err := store.ConsumeMagicLink("token")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,133 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var membershipColumns = []string{"user_id", "organization_id", "name", "user_role", "created_at"}
+
+func TestGetMembership(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresMembershipStore(db, logger)
+
+	userID := uuid.New()
+	orgID := uuid.New()
+	createdAt := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`FROM organization_memberships m INNER JOIN organizations o ON m.organization_id = o.id WHERE m.user_id = $1 AND m.organization_id = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows(membershipColumns).AddRow(userID, orgID, "Uptown", "manager", createdAt)
+		mock.ExpectQuery(query).WithArgs(userID, orgID).WillReturnRows(rows)
+
+		membership, err := store.GetMembership(userID, orgID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Uptown", membership.OrganizationName)
+		assert.Equal(t, "manager", membership.UserRole)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(userID, orgID).WillReturnError(sql.ErrNoRows)
+
+		membership, err := store.GetMembership(userID, orgID)
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.Nil(t, membership)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetMembershipsForUser(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresMembershipStore(db, logger)
+
+	userID := uuid.New()
+	createdAt := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`WHERE m.user_id = $1 ORDER BY m.created_at`)
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows(membershipColumns).
+			AddRow(userID, uuid.New(), "Downtown", "employee", createdAt).
+			AddRow(userID, uuid.New(), "Uptown", "manager", createdAt.AddDate(0, 1, 0))
+		mock.ExpectQuery(query).WithArgs(userID).WillReturnRows(rows)
+
+		memberships, err := store.GetMembershipsForUser(userID)
+		assert.NoError(t, err)
+		assert.Len(t, memberships, 2)
+		assert.Equal(t, "Uptown", memberships[1].OrganizationName)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(userID).WillReturnError(fmt.Errorf("db error"))
+
+		memberships, err := store.GetMembershipsForUser(userID)
+		assert.Error(t, err)
+		assert.Nil(t, memberships)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestAddMembership(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresMembershipStore(db, logger)
+
+	userID := uuid.New()
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`INSERT INTO organization_memberships (user_id, organization_id, user_role, created_at) VALUES ($1, $2, $3, $4) ON CONFLICT (user_id, organization_id) DO UPDATE SET user_role = EXCLUDED.user_role`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(userID, orgID, "manager", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		membership := &database.OrganizationMembership{UserID: userID, OrganizationID: orgID, UserRole: "manager"}
+		err := store.AddMembership(membership)
+		assert.NoError(t, err)
+		assert.False(t, membership.CreatedAt.IsZero())
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectExec(query).WillReturnError(fmt.Errorf("insert failed"))
+
+		err := store.AddMembership(&database.OrganizationMembership{UserID: userID, OrganizationID: orgID, UserRole: "employee"})
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestRemoveMembership(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresMembershipStore(db, logger)
+
+	userID := uuid.New()
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`DELETE FROM organization_memberships WHERE user_id = $1 AND organization_id = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(userID, orgID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.RemoveMembership(userID, orgID)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(userID, orgID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := store.RemoveMembership(userID, orgID)
+		assert.Equal(t, sql.ErrNoRows, err)
+		AssertExpectations(t, mock)
+	})
+}
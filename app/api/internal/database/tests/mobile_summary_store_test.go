@@ -0,0 +1,77 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPendingApprovals(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresMobileSummaryStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`FROM employee_skills WHERE organization_id = $1 AND status = 'pending'`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, "manager").
+			WillReturnRows(sqlmock.NewRows([]string{"requests", "skills"}).AddRow(2, 1))
+
+		pending, err := store.GetPendingApprovals(orgID, "manager")
+		assert.NoError(t, err)
+		assert.Equal(t, &database.PendingApprovals{Requests: 2, Skills: 1, Total: 3}, pending)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("db error"))
+
+		pending, err := store.GetPendingApprovals(orgID, "admin")
+		assert.Error(t, err)
+		assert.Nil(t, pending)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetAlertsSince(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresMobileSummaryStore(db, logger)
+
+	orgID := uuid.New()
+	since := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`FROM order_acceptance_changes`)
+
+	t.Run("Success", func(t *testing.T) {
+		at := time.Date(2026, 10, 15, 19, 30, 0, 0, time.UTC)
+		rows := sqlmock.NewRows([]string{"kind", "at", "detail", "value"}).
+			AddRow(database.MobileAlertOrdersPaused, at, "0 on shift for 12 orders", nil).
+			AddRow(database.MobileAlertForecastVariance, at.Add(-time.Hour), "above forecast through 18:00, call_in", 32.5)
+		mock.ExpectQuery(query).
+			WithArgs(orgID, since, database.MobileAlertForecastVariance, database.MobileAlertOrdersReopened, database.MobileAlertOrdersPaused, 20).
+			WillReturnRows(rows)
+
+		alerts, err := store.GetAlertsSince(orgID, since, 20)
+		assert.NoError(t, err)
+		assert.Len(t, alerts, 2)
+		assert.Nil(t, alerts[0].Value)
+		assert.Equal(t, 32.5, *alerts[1].Value)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("db error"))
+
+		alerts, err := store.GetAlertsSince(orgID, since, 20)
+		assert.Error(t, err)
+		assert.Nil(t, alerts)
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRoutingRules(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresNotificationRoutingStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`FROM notification_routing_rules WHERE organization_id = $1 ORDER BY position, created_at`)
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "event", "within_hours", "channel", "recipients", "position", "created_by", "created_at"}).
+			AddRow(uuid.New(), "calloff", 4, "sms", "on_duty_manager", 0, nil, time.Now()).
+			AddRow(uuid.New(), "calloff", nil, "email", "managers", 1, uuid.New(), time.Now())
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnRows(rows)
+
+		rules, err := store.GetRoutingRules(orgID)
+		assert.NoError(t, err)
+		if assert.Len(t, rules, 2) {
+			if assert.NotNil(t, rules[0].WithinHours) {
+				assert.Equal(t, 4, *rules[0].WithinHours)
+			}
+			assert.Nil(t, rules[0].CreatedBy)
+			assert.Nil(t, rules[1].WithinHours)
+			assert.NotNil(t, rules[1].CreatedBy)
+		}
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestCreateRoutingRule(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresNotificationRoutingStore(db, logger)
+
+	orgID := uuid.New()
+	within := 4
+	rule := &database.NotificationRoutingRule{Event: "calloff", WithinHours: &within, Channel: "sms", Recipients: "on_duty_manager"}
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO notification_routing_rules (id, organization_id, event, within_hours, channel, recipients, position, created_by, created_at)`)).
+			WithArgs(sqlmock.AnyArg(), orgID, "calloff", &within, "sms", "on_duty_manager", 0, nil, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.CreateRoutingRule(orgID, rule))
+		assert.NotEqual(t, uuid.Nil, rule.ID)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestDeleteRoutingRule(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresNotificationRoutingStore(db, logger)
+
+	orgID, ruleID := uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`DELETE FROM notification_routing_rules WHERE id = $1 AND organization_id = $2`)
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(ruleID, orgID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, store.DeleteRoutingRule(orgID, ruleID), sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetNotificationSettings(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresNotificationStore(db, logger)
+
+	userID := uuid.New()
+	query := regexp.QuoteMeta(`SELECT digest_mode, last_digest_at FROM users_notification_settings WHERE user_id = $1`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(userID).
+			WillReturnRows(sqlmock.NewRows([]string{"digest_mode", "last_digest_at"}).AddRow("hourly", time.Now()))
+
+		settings, err := store.GetSettings(userID)
+		assert.NoError(t, err)
+		assert.Equal(t, database.DigestHourly, settings.DigestMode)
+		assert.NotNil(t, settings.LastDigestAt)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DefaultsToImmediate", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(userID).WillReturnError(sql.ErrNoRows)
+
+		settings, err := store.GetSettings(userID)
+		assert.NoError(t, err)
+		assert.Equal(t, database.DigestImmediate, settings.DigestMode)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestQueueNotification(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresNotificationStore(db, logger)
+
+	userID := uuid.New()
+	query := regexp.QuoteMeta(`ON CONFLICT (user_id, dedup_key) DO UPDATE`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(sqlmock.AnyArg(), userID, "request:1:calloff", "Jane submitted a calloff request: Sick", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		assert.NoError(t, store.QueueNotification(userID, "request:1:calloff", "Jane submitted a calloff request: Sick"))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectExec(query).WillReturnError(fmt.Errorf("db error"))
+
+		assert.Error(t, store.QueueNotification(userID, "request:1:calloff", "summary"))
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetDueDigests(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresNotificationStore(db, logger)
+
+	now := time.Now()
+	query := regexp.QuoteMeta(`FROM pending_notifications p`)
+	columns := []string{"id", "email", "full_name", "id", "dedup_key", "summary", "occurrences", "created_at", "updated_at"}
+
+	t.Run("GroupsByUser", func(t *testing.T) {
+		first, second := uuid.New(), uuid.New()
+		rows := sqlmock.NewRows(columns).
+			AddRow(first, "a@test.com", "Alice", uuid.New(), "request:1:calloff", "Bob submitted a calloff request", 3, now, now).
+			AddRow(first, "a@test.com", "Alice", uuid.New(), "request:2:holiday", "Eve submitted a holiday request", 1, now, now).
+			AddRow(second, "c@test.com", "Carol", uuid.New(), "request:1:calloff", "Bob submitted a calloff request", 1, now, now)
+		mock.ExpectQuery(query).WithArgs(now.Add(-time.Hour), now.Add(-24*time.Hour)).WillReturnRows(rows)
+
+		digests, err := store.GetDueDigests(now)
+		assert.NoError(t, err)
+		if assert.Len(t, digests, 2) {
+			assert.Equal(t, first, digests[0].UserID)
+			assert.Len(t, digests[0].Notifications, 2)
+			assert.Equal(t, 3, digests[0].Notifications[0].Occurrences)
+			assert.Equal(t, "Carol", digests[1].FullName)
+			assert.Len(t, digests[1].Notifications, 1)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("db error"))
+
+		digests, err := store.GetDueDigests(now)
+		assert.Error(t, err)
+		assert.Nil(t, digests)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestMarkDigestSent(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresNotificationStore(db, logger)
+
+	userID := uuid.New()
+	notificationID := uuid.New()
+	sentAt := time.Now()
+	deleteQuery := regexp.QuoteMeta(`DELETE FROM pending_notifications WHERE user_id = $1 AND id = ANY($2::uuid[])`)
+	updateQuery := regexp.QuoteMeta(`UPDATE users_notification_settings SET last_digest_at = $2 WHERE user_id = $1`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteQuery).WithArgs(userID, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(updateQuery).WithArgs(userID, sentAt).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, store.MarkDigestSent(userID, []uuid.UUID{notificationID}, sentAt))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DeleteError", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteQuery).WillReturnError(fmt.Errorf("db error"))
+		mock.ExpectRollback()
+
+		assert.Error(t, store.MarkDigestSent(userID, []uuid.UUID{notificationID}, sentAt))
+		AssertExpectations(t, mock)
+	})
+}
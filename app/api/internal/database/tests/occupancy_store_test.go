@@ -0,0 +1,75 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTables(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOccupancyStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`SELECT table_no, number_of_people FROM tables WHERE organization_id = $1 ORDER BY table_no`)
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"table_no", "number_of_people"}).AddRow(1, 4).AddRow(2, 2)
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnRows(rows)
+
+		tables, err := store.GetTables(orgID)
+		assert.NoError(t, err)
+		assert.Equal(t, []database.DiningTable{{TableNo: 1, Capacity: 4}, {TableNo: 2, Capacity: 2}}, tables)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnError(fmt.Errorf("db error"))
+
+		tables, err := store.GetTables(orgID)
+		assert.Error(t, err)
+		assert.Nil(t, tables)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetSeatingsBetween(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOccupancyStore(db, logger)
+
+	orgID := uuid.New()
+	from := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+	query := regexp.QuoteMeta(`FROM order_tables WHERE organization_id = $1 AND start_time <= $3 AND end_time >= $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		orderID := uuid.New()
+		rows := sqlmock.NewRows([]string{"order_id", "table_no", "number_of_people", "start_time", "end_time"}).
+			AddRow(orderID, 3, 5, from.Add(12*time.Hour), from.Add(13*time.Hour))
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).WillReturnRows(rows)
+
+		seatings, err := store.GetSeatingsBetween(orgID, from, to)
+		assert.NoError(t, err)
+		assert.Len(t, seatings, 1)
+		assert.Equal(t, orderID, seatings[0].OrderID)
+		assert.Equal(t, 5, seatings[0].NumberOfPeople)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).WillReturnError(fmt.Errorf("db error"))
+
+		seatings, err := store.GetSeatingsBetween(orgID, from, to)
+		assert.Error(t, err)
+		assert.Nil(t, seatings)
+		AssertExpectations(t, mock)
+	})
+}
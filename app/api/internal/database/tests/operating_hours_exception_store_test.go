@@ -0,0 +1,109 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetExceptionsBetween(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOperatingHoursExceptionStore(db, logger)
+
+	orgID := uuid.New()
+	from := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`WHERE organization_id = $1 AND exception_date BETWEEN $2 AND $3`)
+	columns := []string{"exception_date", "reason", "created_by", "created_at"}
+
+	t.Run("Success", func(t *testing.T) {
+		adminID := uuid.New()
+		rows := sqlmock.NewRows(columns).
+			AddRow(time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC), "Christmas", adminID, time.Now()).
+			AddRow(time.Date(2026, 12, 26, 0, 0, 0, 0, time.UTC), nil, nil, time.Now())
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).WillReturnRows(rows)
+
+		exceptions, err := store.GetExceptionsBetween(orgID, from, to)
+		assert.NoError(t, err)
+		if assert.Len(t, exceptions, 2) {
+			assert.Equal(t, "Christmas", *exceptions[0].Reason)
+			assert.Equal(t, adminID, *exceptions[0].CreatedBy)
+			assert.Nil(t, exceptions[1].Reason)
+			assert.Nil(t, exceptions[1].CreatedBy)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).WillReturnError(fmt.Errorf("db error"))
+
+		exceptions, err := store.GetExceptionsBetween(orgID, from, to)
+		assert.Error(t, err)
+		assert.Nil(t, exceptions)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestUpsertException(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOperatingHoursExceptionStore(db, logger)
+
+	orgID := uuid.New()
+	adminID := uuid.New()
+	reason := "Christmas"
+	date := time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`ON CONFLICT (organization_id, exception_date) DO UPDATE SET reason = EXCLUDED.reason`)
+
+	t.Run("Success", func(t *testing.T) {
+		// Closing an already closed day keeps its original creation time
+		closedAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+		exception := &database.OperatingHoursException{Date: date, Reason: &reason, CreatedBy: &adminID}
+		mock.ExpectQuery(query).
+			WithArgs(orgID, date, &reason, &adminID, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(closedAt))
+
+		assert.NoError(t, store.UpsertException(orgID, exception))
+		assert.Equal(t, closedAt, exception.CreatedAt)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("db error"))
+
+		assert.Error(t, store.UpsertException(orgID, &database.OperatingHoursException{Date: date}))
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestDeleteException(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOperatingHoursExceptionStore(db, logger)
+
+	orgID := uuid.New()
+	date := time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`DELETE FROM organizations_operating_hours_exceptions WHERE organization_id = $1 AND exception_date = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID, date).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.DeleteException(orgID, date))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID, date).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.Equal(t, sql.ErrNoRows, store.DeleteException(orgID, date))
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,179 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var orderAcceptanceChangeColumns = []string{
+	"id", "organization_id", "accepting_orders", "forecast_orders", "recent_orders", "staff_on_shift", "capacity",
+	"load_percent", "changed_at",
+}
+
+func TestGetAutoCloseOrganizations(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderAcceptanceStore(db, logger)
+
+	query := regexp.QuoteMeta(`FROM organizations_rules`)
+
+	t.Run("Success", func(t *testing.T) {
+		orgID := uuid.New()
+		closedAt := time.Date(2026, 10, 15, 19, 0, 0, 0, time.UTC)
+		rows := sqlmock.NewRows([]string{"organization_id", "accepting_orders", "orders_per_staff_hour", "auto_close_load_percent", "auto_reopen_load_percent", "orders_auto_closed_at"}).
+			AddRow(orgID, false, 10, 150, 100, closedAt)
+		mock.ExpectQuery(query).WillReturnRows(rows)
+
+		settings, err := store.GetAutoCloseOrganizations()
+		assert.NoError(t, err)
+		assert.Len(t, settings, 1)
+		assert.Equal(t, orgID, settings[0].OrganizationID)
+		assert.False(t, settings[0].AcceptingOrders)
+		assert.Equal(t, closedAt, *settings[0].OrdersAutoClosedAt)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("db error"))
+
+		settings, err := store.GetAutoCloseOrganizations()
+		assert.Error(t, err)
+		assert.Nil(t, settings)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetOrderLoad(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderAcceptanceStore(db, logger)
+
+	orgID := uuid.New()
+	at := time.Date(2026, 10, 15, 19, 30, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`FROM demand WHERE organization_id = $1 AND demand_date = $2 AND hour = $3`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, "2026-10-15", 19, at.Add(-time.Hour), at).
+			WillReturnRows(sqlmock.NewRows([]string{"forecast", "recent", "staff"}).AddRow(60, 45, 3))
+
+		load, err := store.GetOrderLoad(orgID, at)
+		assert.NoError(t, err)
+		assert.Equal(t, &database.OrderLoad{ForecastOrders: 60, RecentOrders: 45, StaffOnShift: 3}, load)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("db error"))
+
+		load, err := store.GetOrderLoad(orgID, at)
+		assert.Error(t, err)
+		assert.Nil(t, load)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestSetAcceptingOrders(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderAcceptanceStore(db, logger)
+
+	orgID := uuid.New()
+	at := time.Date(2026, 10, 15, 19, 30, 0, 0, time.UTC)
+	closeQuery := regexp.QuoteMeta(`UPDATE organizations_rules SET accepting_orders = FALSE, orders_auto_closed_at = $2`)
+	reopenQuery := regexp.QuoteMeta(`UPDATE organizations_rules SET accepting_orders = TRUE, orders_auto_closed_at = NULL`)
+	insertQuery := regexp.QuoteMeta(`INSERT INTO order_acceptance_changes`)
+
+	t.Run("Close", func(t *testing.T) {
+		load := 200.0
+		change := &database.OrderAcceptanceChange{OrganizationID: orgID, ForecastOrders: 60, RecentOrders: 45, StaffOnShift: 3, Capacity: 30, LoadPercent: &load, ChangedAt: at}
+		mock.ExpectBegin()
+		mock.ExpectExec(closeQuery).WithArgs(orgID, at).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(insertQuery).WithArgs(sqlmock.AnyArg(), orgID, false, 60, 45, 3, 30, &load, at).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := store.SetAcceptingOrders(change)
+		assert.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, change.ID)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Reopen", func(t *testing.T) {
+		load := 90.0
+		change := &database.OrderAcceptanceChange{OrganizationID: orgID, AcceptingOrders: true, ForecastOrders: 27, StaffOnShift: 3, Capacity: 30, LoadPercent: &load, ChangedAt: at}
+		mock.ExpectBegin()
+		mock.ExpectExec(reopenQuery).WithArgs(orgID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(insertQuery).WithArgs(sqlmock.AnyArg(), orgID, true, 27, 0, 3, 30, &load, at).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := store.SetAcceptingOrders(change)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("AlreadyChanged", func(t *testing.T) {
+		// A manager reopened the orders by hand, nothing is logged
+		change := &database.OrderAcceptanceChange{OrganizationID: orgID, ChangedAt: at}
+		mock.ExpectBegin()
+		mock.ExpectExec(closeQuery).WithArgs(orgID, at).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := store.SetAcceptingOrders(change)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("InsertError", func(t *testing.T) {
+		change := &database.OrderAcceptanceChange{OrganizationID: orgID, ChangedAt: at}
+		mock.ExpectBegin()
+		mock.ExpectExec(closeQuery).WithArgs(orgID, at).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(insertQuery).WillReturnError(fmt.Errorf("db error"))
+		mock.ExpectRollback()
+
+		err := store.SetAcceptingOrders(change)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestListOrderAcceptanceChanges(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderAcceptanceStore(db, logger)
+
+	orgID := uuid.New()
+	from := time.Date(2026, 10, 8, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`WHERE organization_id = $1 AND changed_at >= $2 AND changed_at < $3`)
+
+	t.Run("Success", func(t *testing.T) {
+		changedAt := time.Date(2026, 10, 14, 19, 30, 0, 0, time.UTC)
+		rows := sqlmock.NewRows(orderAcceptanceChangeColumns).
+			AddRow(uuid.New(), orgID, true, 27, 20, 3, 30, 90.0, changedAt.Add(time.Hour)).
+			AddRow(uuid.New(), orgID, false, 12, 4, 0, 0, nil, changedAt)
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).WillReturnRows(rows)
+
+		changes, err := store.ListChanges(orgID, from, to)
+		assert.NoError(t, err)
+		assert.Len(t, changes, 2)
+		assert.Equal(t, 90.0, *changes[0].LoadPercent)
+		assert.Nil(t, changes[1].LoadPercent)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("db error"))
+
+		changes, err := store.ListChanges(orgID, from, to)
+		assert.Error(t, err)
+		assert.Nil(t, changes)
+		AssertExpectations(t, mock)
+	})
+}
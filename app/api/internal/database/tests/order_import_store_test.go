@@ -0,0 +1,188 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var orderImportRowColumns = []string{"id", "organization_id", "uploaded_by", "file_name", "status", "total_rows", "valid_rows",
+	"invalid_rows", "duplicate_rows", "promoted_rows", "redemption_count", "created_at", "promoted_at"}
+
+func TestStageOrderImportRows(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderImportStore(db, logger)
+
+	importID, orderID, userID := uuid.New(), uuid.New(), uuid.New()
+	created := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	total, discount := 20.0, 2.0
+	problem := "user_id must be a UUID"
+	insert := regexp.QuoteMeta(`INSERT INTO order_import_rows (import_id, line, order_id, user_id, create_time, order_type, order_status,`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(insert).
+			WithArgs(importID, 2, &orderID, &userID, &created, "takeaway", "completed", &total, &discount, nil, "", "SUMMER10", database.OrderImportRowPending, nil,
+				importID, 3, &orderID, nil, &created, "takeaway", "completed", &total, &discount, nil, "", "", database.OrderImportRowInvalid, &problem).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		err := store.StageOrderImportRows(importID, []database.OrderImportRow{
+			{Line: 2, OrderID: &orderID, UserID: &userID, CreateTime: &created, OrderType: "takeaway", OrderStatus: "completed",
+				TotalAmount: &total, DiscountAmount: &discount, RedemptionCode: "SUMMER10"},
+			{Line: 3, OrderID: &orderID, CreateTime: &created, OrderType: "takeaway", OrderStatus: "completed",
+				TotalAmount: &total, DiscountAmount: &discount, Status: database.OrderImportRowInvalid, Error: &problem},
+		})
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Chunks", func(t *testing.T) {
+		rows := make([]database.OrderImportRow, 1500)
+		for i := range rows {
+			rows[i] = database.OrderImportRow{Line: i + 2}
+		}
+		mock.ExpectExec(insert).WillReturnResult(sqlmock.NewResult(0, 1000))
+		mock.ExpectExec(insert).WillReturnResult(sqlmock.NewResult(0, 500))
+
+		err := store.StageOrderImportRows(importID, rows)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestValidateOrderImport(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderImportStore(db, logger)
+
+	orgID, importID := uuid.New(), uuid.New()
+	lock := regexp.QuoteMeta(`SELECT status FROM order_imports WHERE id = $1 AND organization_id = $2 FOR UPDATE`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(importID, orgID).WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(database.OrderImportStaged))
+		mock.ExpectExec(regexp.QuoteMeta(`WHEN order_type NOT IN ('delivery', 'takeaway', 'dine in')`)).WithArgs(importID, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`MIN(line) OVER (PARTITION BY order_id)`)).WithArgs(importID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`FROM orders o`)).WithArgs(importID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE order_import_rows SET status = 'valid'`)).WithArgs(importID).WillReturnResult(sqlmock.NewResult(0, 97))
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE order_imports SET total_rows = c.total`)).WithArgs(importID).
+			WillReturnRows(sqlmock.NewRows(orderImportRowColumns).AddRow(importID, orgID, nil, "history.csv", database.OrderImportStaged, 100, 97, 1, 2, 0, 0, time.Now(), nil))
+		mock.ExpectCommit()
+
+		orderImport, err := store.ValidateOrderImport(orgID, importID)
+		assert.NoError(t, err)
+		if assert.NotNil(t, orderImport) {
+			assert.Equal(t, 97, orderImport.ValidRows)
+			assert.Equal(t, 2, orderImport.DuplicateRows)
+			assert.Nil(t, orderImport.UploadedBy)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotStaged", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(importID, orgID).WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(database.OrderImportPromoted))
+		mock.ExpectRollback()
+
+		orderImport, err := store.ValidateOrderImport(orgID, importID)
+		assert.ErrorIs(t, err, database.ErrOrderImportNotStaged)
+		assert.Nil(t, orderImport)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestPromoteOrderImport(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderImportStore(db, logger)
+
+	orgID, importID := uuid.New(), uuid.New()
+	lock := regexp.QuoteMeta(`SELECT status FROM order_imports WHERE id = $1 AND organization_id = $2 FOR UPDATE`)
+	lateDuplicates := regexp.QuoteMeta(`WHERE r.import_id = $1 AND r.status = 'valid' AND o.id = r.order_id`)
+	insertOrders := regexp.QuoteMeta(`INSERT INTO orders (id, user_id, organization_id, create_time, order_type, order_status, total_amount, discount_amount, rating, channel)`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(importID, orgID).WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(database.OrderImportStaged))
+		mock.ExpectExec(lateDuplicates).WithArgs(importID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(insertOrders).WithArgs(importID, orgID).WillReturnResult(sqlmock.NewResult(0, 96))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO campaign_redemptions (campaign_id, order_id, organization_id, redeemed_at)`)).
+			WithArgs(importID, orgID).WillReturnResult(sqlmock.NewResult(0, 4))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM order_import_rows WHERE import_id = $1 AND status = 'valid'`)).WithArgs(importID).WillReturnResult(sqlmock.NewResult(0, 96))
+		mock.ExpectQuery(regexp.QuoteMeta(`SET status = 'promoted', valid_rows = valid_rows - $2`)).WithArgs(importID, int64(1), int64(96), int64(4)).
+			WillReturnRows(sqlmock.NewRows(orderImportRowColumns).AddRow(importID, orgID, nil, "history.csv", database.OrderImportPromoted, 100, 96, 1, 3, 96, 4, time.Now(), time.Now()))
+		mock.ExpectCommit()
+
+		orderImport, err := store.PromoteOrderImport(orgID, importID)
+		assert.NoError(t, err)
+		if assert.NotNil(t, orderImport) {
+			assert.Equal(t, database.OrderImportPromoted, orderImport.Status)
+			assert.Equal(t, 96, orderImport.PromotedRows)
+			assert.Equal(t, 4, orderImport.RedemptionCount)
+			assert.NotNil(t, orderImport.PromotedAt)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("InsertError_RollsBack", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(importID, orgID).WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(database.OrderImportStaged))
+		mock.ExpectExec(lateDuplicates).WithArgs(importID).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(insertOrders).WithArgs(importID, orgID).WillReturnError(fmt.Errorf("db error"))
+		mock.ExpectRollback()
+
+		orderImport, err := store.PromoteOrderImport(orgID, importID)
+		assert.Error(t, err)
+		assert.Nil(t, orderImport)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(importID, orgID).WillReturnRows(sqlmock.NewRows([]string{"status"}))
+		mock.ExpectRollback()
+
+		orderImport, err := store.PromoteOrderImport(orgID, importID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Nil(t, orderImport)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestDiscardOrderImport(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderImportStore(db, logger)
+
+	orgID, importID := uuid.New(), uuid.New()
+	lock := regexp.QuoteMeta(`SELECT status FROM order_imports WHERE id = $1 AND organization_id = $2 FOR UPDATE`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(importID, orgID).WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(database.OrderImportStaged))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM order_import_rows WHERE import_id = $1`)).WithArgs(importID).WillReturnResult(sqlmock.NewResult(0, 100))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE order_imports SET status = 'discarded' WHERE id = $1`)).WithArgs(importID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := store.DiscardOrderImport(orgID, importID)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("AlreadyDiscarded", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(importID, orgID).WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(database.OrderImportDiscarded))
+		mock.ExpectRollback()
+
+		err := store.DiscardOrderImport(orgID, importID)
+		assert.ErrorIs(t, err, database.ErrOrderImportNotStaged)
+		AssertExpectations(t, mock)
+	})
+}
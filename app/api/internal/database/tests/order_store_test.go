@@ -1,6 +1,7 @@
 package database
 
 import (
+	"database/sql"
 	"fmt"
 	"regexp"
 	"testing"
@@ -24,15 +25,15 @@ func TestGetAllOrders(t *testing.T) {
 	now := time.Now()
 
 	// Queries used in GetAllOrders
-	qSelectOrders := regexp.QuoteMeta(`SELECT id, user_id, organization_id, create_time, order_type, order_status, total_amount, discount_amount, rating FROM orders WHERE organization_id = $1 ORDER BY create_time DESC`)
+	qSelectOrders := regexp.QuoteMeta(`SELECT id, user_id, organization_id, create_time, order_type, order_status, total_amount, discount_amount, rating, channel FROM orders WHERE organization_id = $1 ORDER BY create_time DESC`)
 	qSelectItems := regexp.QuoteMeta(`SELECT order_id, item_id, quantity, total_price FROM order_items WHERE order_id IN ($1, $2)`)
 	qSelectDeliveries := regexp.QuoteMeta(`SELECT order_id, driver_id, delivery_latitude, delivery_longitude, out_for_delivery_time, delivered_time, status FROM deliveries WHERE order_id IN ($1, $2)`)
 
 	t.Run("Success_WithItemsAndDeliveries", func(t *testing.T) {
 		// 1. Mock Orders Query
-		rowsOrders := sqlmock.NewRows([]string{"id", "user_id", "organization_id", "create_time", "order_type", "order_status", "total_amount", "discount_amount", "rating"}).
-			AddRow(orderID1, userID, orgID, now, "dine in", "closed", 50.0, 0.0, 5.0).
-			AddRow(orderID2, userID, orgID, now, "delivery", "closed", 30.0, 5.0, 4.0)
+		rowsOrders := sqlmock.NewRows([]string{"id", "user_id", "organization_id", "create_time", "order_type", "order_status", "total_amount", "discount_amount", "rating", "channel"}).
+			AddRow(orderID1, userID, orgID, now, "dine in", "closed", 50.0, 0.0, 5.0, "direct").
+			AddRow(orderID2, userID, orgID, now, "delivery", "closed", 30.0, 5.0, 4.0, "uber_eats")
 		mock.ExpectQuery(qSelectOrders).WithArgs(orgID).WillReturnRows(rowsOrders)
 
 		// 2. Mock Items Query (populateOrderItems)
@@ -60,6 +61,7 @@ func TestGetAllOrders(t *testing.T) {
 
 		// Check Order 2
 		assert.Equal(t, orderID2, orders[1].OrderID)
+		assert.Equal(t, "uber_eats", orders[1].Channel)
 		assert.Len(t, orders[1].OrderItems, 1)
 		assert.NotNil(t, orders[1].DeliveryStatus)
 		assert.Equal(t, "delivered", orders[1].DeliveryStatus.DeliveryStatus)
@@ -76,6 +78,42 @@ func TestGetAllOrders(t *testing.T) {
 	})
 }
 
+func TestGetOrders(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderStore(db, logger)
+
+	orgID := uuid.New()
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 8, 0, 0, 0, 0, time.UTC)
+	columns := []string{"id", "user_id", "organization_id", "create_time", "order_type", "order_status", "total_amount", "discount_amount", "rating", "channel"}
+
+	t.Run("Filtered", func(t *testing.T) {
+		q := regexp.QuoteMeta(`FROM orders WHERE organization_id = $1 AND create_time >= $2 AND create_time < $3 AND order_status IN ($4) AND channel IN ($5, $6) ORDER BY create_time DESC`)
+		mock.ExpectQuery(q).WithArgs(orgID, &from, &to, "completed", "uber_eats", "deliveroo").WillReturnRows(sqlmock.NewRows(columns))
+
+		orders, err := store.GetOrders(orgID, database.OrderFilter{
+			From:     &from,
+			To:       &to,
+			Statuses: []string{"completed"},
+			Channels: []string{"uber_eats", "deliveroo"},
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, orders)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		q := regexp.QuoteMeta(`FROM orders WHERE organization_id = $1 AND order_type IN ($2) ORDER BY`)
+		mock.ExpectQuery(q).WithArgs(orgID, "delivery").WillReturnError(fmt.Errorf("db error"))
+
+		orders, err := store.GetOrders(orgID, database.OrderFilter{Types: []string{"delivery"}})
+		assert.Error(t, err)
+		assert.Nil(t, orders)
+		AssertExpectations(t, mock)
+	})
+}
+
 func TestStoreOrder(t *testing.T) {
 	db, mock := NewTestDB(t)
 	logger := NewTestLogger()
@@ -113,9 +151,9 @@ func TestStoreOrder(t *testing.T) {
 		mock.ExpectBegin()
 
 		// 1. Insert Order
-		qInsertOrder := regexp.QuoteMeta(`INSERT INTO orders (id, user_id, organization_id, create_time, order_type, order_status, total_amount, discount_amount, rating) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`)
+		qInsertOrder := regexp.QuoteMeta(`INSERT INTO orders (id, user_id, organization_id, create_time, order_type, order_status, total_amount, discount_amount, rating, channel) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`)
 		mock.ExpectExec(qInsertOrder).
-			WithArgs(order.OrderID, order.UserID, orgID, order.CreateTime, order.OrderType, order.OrderStatus, order.TotalAmount, order.DiscountAmount, order.Rating).
+			WithArgs(order.OrderID, order.UserID, orgID, order.CreateTime, order.OrderType, order.OrderStatus, order.TotalAmount, order.DiscountAmount, order.Rating, database.OrderChannelDirect).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		// 2. Insert Delivery
@@ -163,6 +201,157 @@ func TestStoreOrder(t *testing.T) {
 	})
 }
 
+func TestStoreOrdersBulk(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderStore(db, logger)
+
+	orgID := uuid.New()
+	now := time.Now()
+	total := 20.0
+	order := func() database.Order {
+		return database.Order{OrderID: uuid.New(), UserID: uuid.New(), CreateTime: now, OrderType: "takeaway", OrderStatus: "closed", TotalAmount: &total, DiscountAmount: &total}
+	}
+
+	t.Run("Success_SkipsExistingOrders", func(t *testing.T) {
+		first, existing := order(), order()
+		existing.Channel = database.OrderChannelPOS
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO orders (id, user_id, organization_id, create_time, order_type, order_status, total_amount, discount_amount, rating, channel) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10), ($11, $12, $13, $14, $15, $16, $17, $18, $19, $20) ON CONFLICT (id) DO NOTHING RETURNING id, xmax = 0`)).
+			WithArgs(first.OrderID, first.UserID, orgID, now, "takeaway", "closed", &total, &total, nil, database.OrderChannelDirect,
+				existing.OrderID, existing.UserID, orgID, now, "takeaway", "closed", &total, &total, nil, database.OrderChannelPOS).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "inserted"}).AddRow(first.OrderID, true))
+		mock.ExpectCommit()
+
+		inserted, updated, err := store.StoreOrdersBulk(orgID, []database.Order{first, existing}, database.OrderConflictSkip)
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{first.OrderID}, inserted)
+		assert.Empty(t, updated)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Success_ChunksLargeBatches", func(t *testing.T) {
+		orders := make([]database.Order, 1500)
+		for i := range orders {
+			orders[i] = order()
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`($9991, $9992, $9993, $9994, $9995, $9996, $9997, $9998, $9999, $10000) ON CONFLICT`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "inserted"}).AddRow(orders[0].OrderID, true))
+		mock.ExpectQuery(regexp.QuoteMeta(`($4991, $4992, $4993, $4994, $4995, $4996, $4997, $4998, $4999, $5000) ON CONFLICT`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "inserted"}).AddRow(orders[1000].OrderID, true))
+		mock.ExpectCommit()
+
+		inserted, _, err := store.StoreOrdersBulk(orgID, orders, database.OrderConflictSkip)
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{orders[0].OrderID, orders[1000].OrderID}, inserted)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Failure_RollsBackEveryChunk", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO orders`)).WillReturnError(fmt.Errorf("insert failed"))
+		mock.ExpectRollback()
+
+		_, _, err := store.StoreOrdersBulk(orgID, []database.Order{order()}, database.OrderConflictSkip)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Overwrite_KeepsLastRepeatedOrder", func(t *testing.T) {
+		first, existing := order(), order()
+		repeated := first
+		repeated.OrderStatus = "refunded"
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`ON CONFLICT (id) DO UPDATE SET`)).
+			WithArgs(existing.OrderID, existing.UserID, orgID, now, "takeaway", "closed", &total, &total, nil, database.OrderChannelDirect,
+				first.OrderID, first.UserID, orgID, now, "takeaway", "refunded", &total, &total, nil, database.OrderChannelDirect).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "inserted"}).AddRow(existing.OrderID, false).AddRow(first.OrderID, true))
+		mock.ExpectCommit()
+
+		inserted, updated, err := store.StoreOrdersBulk(orgID, []database.Order{first, existing, repeated}, database.OrderConflictOverwrite)
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{first.OrderID}, inserted)
+		assert.Equal(t, []uuid.UUID{existing.OrderID}, updated)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Fail_RollsBackOnExistingOrder", func(t *testing.T) {
+		first, existing := order(), order()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`ON CONFLICT (id) DO NOTHING`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "inserted"}).AddRow(first.OrderID, true))
+		mock.ExpectRollback()
+
+		_, _, err := store.StoreOrdersBulk(orgID, []database.Order{first, existing}, database.OrderConflictFail)
+		var conflict *database.OrderConflictError
+		if assert.ErrorAs(t, err, &conflict) {
+			assert.Equal(t, existing.OrderID, conflict.OrderID)
+		}
+		assert.ErrorIs(t, err, database.ErrDuplicateOrder)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestStoreOrderItemsBulk(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderStore(db, logger)
+
+	orgID := uuid.New()
+	orderID, burger, fries := uuid.New(), uuid.New(), uuid.New()
+	link := func(itemID uuid.UUID, quantity int, price float64) database.OrderItemLink {
+		return database.OrderItemLink{OrderID: orderID, OrderItem: database.OrderItem{ItemID: itemID, Quantity: &quantity, TotalPrice: &price}}
+	}
+
+	t.Run("Success_SkipsUnknownAndAddsUpRepeats", func(t *testing.T) {
+		unknownItem := uuid.New()
+		links := []database.OrderItemLink{link(burger, 2, 20), link(unknownItem, 1, 5), link(fries, 1, 4), link(burger, 1, 10)}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT 'order', id FROM orders WHERE organization_id = $1 AND id = ANY($2::uuid[])`)).
+			WithArgs(orgID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"kind", "id"}).AddRow("order", orderID).AddRow("item", burger).AddRow("item", fries))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO order_items (order_id, item_id, quantity, total_price) VALUES ($1, $2, $3, $4), ($5, $6, $7, $8) ON CONFLICT`)).
+			WithArgs(orderID, burger, 3, 30.0, orderID, fries, 1, 4.0).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		unknown, err := store.StoreOrderItemsBulk(orgID, links)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1}, unknown)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Success_NothingKnown", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT 'order', id FROM orders`)).
+			WillReturnRows(sqlmock.NewRows([]string{"kind", "id"}))
+		mock.ExpectCommit()
+
+		unknown, err := store.StoreOrderItemsBulk(orgID, []database.OrderItemLink{link(burger, 1, 10)})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{0}, unknown)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Failure_RollsBack", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT 'order', id FROM orders`)).
+			WillReturnRows(sqlmock.NewRows([]string{"kind", "id"}).AddRow("order", orderID).AddRow("item", burger))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO order_items`)).WillReturnError(fmt.Errorf("insert failed"))
+		mock.ExpectRollback()
+
+		_, err := store.StoreOrderItemsBulk(orgID, []database.OrderItemLink{link(burger, 1, 10)})
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
 func TestGetOrdersInsights(t *testing.T) {
 	db, mock := NewTestDB(t)
 	logger := NewTestLogger()
@@ -175,6 +364,7 @@ func TestGetOrdersInsights(t *testing.T) {
 	qToday := regexp.QuoteMeta(`SELECT COUNT(*) FROM orders WHERE organization_id = $1 AND DATE(create_time) = CURRENT_DATE`)
 	qBusiestDay := regexp.QuoteMeta(`SELECT TO_CHAR(create_time, 'Day') as day_name FROM orders WHERE organization_id = $1 GROUP BY TO_CHAR(create_time, 'Day'), EXTRACT(DOW FROM create_time) ORDER BY COUNT(*) DESC LIMIT 1`)
 	qBusiestHour := regexp.QuoteMeta(`SELECT EXTRACT(HOUR FROM create_time)::int as hour FROM orders WHERE organization_id = $1 GROUP BY EXTRACT(HOUR FROM create_time) ORDER BY COUNT(*) DESC LIMIT 1`)
+	qChannels := regexp.QuoteMeta(`SELECT channel, COUNT(*) FROM orders WHERE organization_id = $1 AND create_time >= NOW() - INTERVAL '7 days' GROUP BY channel ORDER BY COUNT(*) DESC, channel`)
 
 	t.Run("Success", func(t *testing.T) {
 		mock.ExpectQuery(qTotal).WithArgs(orgID).WillReturnRows(NewRow(100))
@@ -183,18 +373,336 @@ func TestGetOrdersInsights(t *testing.T) {
 
 		mock.ExpectQuery(qBusiestDay).WithArgs(orgID).WillReturnRows(NewRow("Friday"))
 		mock.ExpectQuery(qBusiestHour).WithArgs(orgID).WillReturnRows(NewRow(18))
+		mock.ExpectQuery(qChannels).WithArgs(orgID).
+			WillReturnRows(sqlmock.NewRows([]string{"channel", "count"}).AddRow("uber_eats", 12).AddRow("direct", 8))
 
 		insights, err := store.GetOrdersInsights(orgID)
 
 		assert.NoError(t, err)
-		assert.Len(t, insights, 5)
+		assert.Len(t, insights, 7)
 		assert.Equal(t, "Total Orders (All Time)", insights[0].Title)
 		assert.Equal(t, "100", insights[0].Statistic)
 		assert.Equal(t, "Busiest Day (Orders)", insights[3].Title)
 		assert.Equal(t, "Friday", insights[3].Statistic)
 		assert.Equal(t, "Busiest Hour (Orders)", insights[4].Title)
 		assert.Equal(t, "18:00", insights[4].Statistic)
+		assert.Equal(t, "Orders via uber_eats (Last 7 Days)", insights[5].Title)
+		assert.Equal(t, "12 (60.0%)", insights[5].Statistic)
+		assert.Equal(t, "8 (40.0%)", insights[6].Statistic)
+
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetChannelBreakdown(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderStore(db, logger)
+	orgID := uuid.New()
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 30)
+
+	query := regexp.QuoteMeta(`SELECT channel, COUNT(*), COUNT(*) FILTER (WHERE order_type = 'delivery'), COALESCE(SUM(total_amount - discount_amount), 0), COALESCE(SUM(discount_amount), 0) FROM orders WHERE organization_id = $1 AND create_time >= $2 AND create_time < $3 GROUP BY channel ORDER BY COUNT(*) DESC, channel`)
+	columns := []string{"channel", "orders", "delivery_orders", "revenue", "discount"}
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("talabat", 2, 2, 45.0, 5.0).AddRow("phone", 1, 0, 12.5, 0.0))
+
+		breakdown, err := store.GetChannelBreakdown(orgID, from, to)
+		assert.NoError(t, err)
+		if assert.Len(t, breakdown, 2) {
+			assert.Equal(t, "talabat", breakdown[0].Channel)
+			assert.Equal(t, 66.67, breakdown[0].Share)
+			assert.Equal(t, 22.5, breakdown[0].AverageOrderValue)
+			assert.Equal(t, 33.33, breakdown[1].Share)
+			assert.Equal(t, 0, breakdown[1].DeliveryOrders)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NoOrders", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).WillReturnRows(sqlmock.NewRows(columns))
+
+		breakdown, err := store.GetChannelBreakdown(orgID, from, to)
+		assert.NoError(t, err)
+		assert.NotNil(t, breakdown)
+		assert.Empty(t, breakdown)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestDiscountAudit(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderStore(db, logger)
+	orgID := uuid.New()
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 30)
+
+	t.Run("DiscountStats", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*), COUNT(*) FILTER (WHERE discount_amount > 0), COALESCE(SUM(total_amount), 0), COALESCE(SUM(discount_amount), 0) FROM orders WHERE organization_id = $1 AND create_time >= $2 AND create_time < $3`)).
+			WithArgs(orgID, from, to).
+			WillReturnRows(sqlmock.NewRows([]string{"orders", "discounted", "sales", "discount"}).AddRow(120, 15, 3000.0, 240.0))
+
+		stats, err := store.GetDiscountStats(orgID, from, to)
+		assert.NoError(t, err)
+		assert.Equal(t, database.DiscountStats{Orders: 120, DiscountedOrders: 15, Sales: 3000, Discount: 240}, *stats)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("CustomerDiscounts", func(t *testing.T) {
+		customerID := uuid.New()
+		mock.ExpectQuery(regexp.QuoteMeta(`HAVING COUNT(*) FILTER (WHERE discount_amount > 0) >= $4`)).
+			WithArgs(orgID, from, to, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "orders", "discounted", "sales", "discount"}).AddRow(customerID, 5, 4, 100.0, 25.0))
+
+		customers, err := store.GetCustomerDiscounts(orgID, from, to, 3)
+		assert.NoError(t, err)
+		if assert.Len(t, customers, 1) {
+			assert.Equal(t, customerID, customers[0].CustomerID)
+			assert.Equal(t, 4, customers[0].DiscountedOrders)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("ShiftDiscounts", func(t *testing.T) {
+		employeeID := uuid.New()
+		mock.ExpectQuery(regexp.QuoteMeta(`AND o.create_time >= (s.schedule_date + s.start_hour) AND o.create_time < (s.schedule_date + s.end_hour)`)).
+			WithArgs(orgID, from, to).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "full_name", "shifts", "orders", "discounted", "sales", "discount"}).
+				AddRow(employeeID, "Sam", 8, 40, 12, 1000.0, 160.0))
+
+		employees, err := store.GetShiftDiscounts(orgID, from, to)
+		assert.NoError(t, err)
+		if assert.Len(t, employees, 1) {
+			assert.Equal(t, "Sam", employees[0].FullName)
+			assert.Equal(t, 8, employees[0].Shifts)
+			assert.Equal(t, 160.0, employees[0].Discount)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("ShiftDiscounts_DBError", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM schedules s`)).WillReturnError(fmt.Errorf("db error"))
+
+		employees, err := store.GetShiftDiscounts(orgID, from, to)
+		assert.Error(t, err)
+		assert.Nil(t, employees)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetDeliveryVolume(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderStore(db, logger)
+	orgID := uuid.New()
+	to := time.Date(2025, 6, 29, 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -28)
+
+	query := regexp.QuoteMeta(`SELECT EXTRACT(DOW FROM create_time)::int, EXTRACT(HOUR FROM create_time)::int, COUNT(*) FROM orders WHERE organization_id = $1 AND order_type = 'delivery' AND create_time >= $2 AND create_time < $3 GROUP BY 1, 2 ORDER BY 1, 2`)
+	columns := []string{"dow", "hour", "orders"}
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(0, 19, 12).AddRow(5, 12, 7))
+
+		volume, err := store.GetDeliveryVolume(orgID, from, to)
+		assert.NoError(t, err)
+		assert.Equal(t, []database.DeliveryVolume{
+			{Weekday: "sunday", Hour: 19, Orders: 12},
+			{Weekday: "friday", Hour: 12, Orders: 7},
+		}, volume)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NoDeliveries", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).WillReturnRows(sqlmock.NewRows(columns))
+
+		volume, err := store.GetDeliveryVolume(orgID, from, to)
+		assert.NoError(t, err)
+		assert.NotNil(t, volume)
+		assert.Empty(t, volume)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).WillReturnError(fmt.Errorf("db error"))
+
+		_, err := store.GetDeliveryVolume(orgID, from, to)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetHourlyDemand(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderStore(db, logger)
+	orgID := uuid.New()
+	from := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+
+	query := regexp.QuoteMeta(`COUNT(DISTINCT o.id), COALESCE(SUM(oi.quantity), 0) FROM orders o LEFT JOIN order_items oi ON oi.order_id = o.id WHERE o.organization_id = $1 AND o.create_time >= $2 AND o.create_time < $3`)
+	columns := []string{"date", "hour", "orders", "items"}
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(from, 9, 3, 4).AddRow(from.AddDate(0, 0, 1), 12, 5, 0))
+
+		demand, err := store.GetHourlyDemand(orgID, from, to)
+		assert.NoError(t, err)
+		assert.Equal(t, []database.HourlyDemand{
+			{Date: from, Hour: 9, Orders: 3, Items: 4},
+			{Date: from.AddDate(0, 0, 1), Hour: 12, Orders: 5, Items: 0},
+		}, demand)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NoOrders", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).WillReturnRows(sqlmock.NewRows(columns))
+
+		demand, err := store.GetHourlyDemand(orgID, from, to)
+		assert.NoError(t, err)
+		assert.NotNil(t, demand)
+		assert.Empty(t, demand)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).WillReturnError(fmt.Errorf("db error"))
+
+		_, err := store.GetHourlyDemand(orgID, from, to)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetDriverDeliveryStats(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderStore(db, logger)
+	orgID := uuid.New()
+	to := time.Date(2025, 6, 29, 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -30)
+
+	query := regexp.QuoteMeta(`WHERE o.organization_id = $1 AND d.driver_id IS NOT NULL AND d.out_for_delivery_time >= $2 AND d.out_for_delivery_time < $3`)
+	columns := []string{"driver_id", "full_name", "deliveries", "delivered", "active_days", "minutes", "on_time", "distance_km"}
+
+	t.Run("Success", func(t *testing.T) {
+		driverID := uuid.New()
+		mock.ExpectQuery(query).WithArgs(orgID, from, to, 45).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(driverID, "Sam", 25, 24, 4, 780.0, 18, 123.4))
+
+		drivers, err := store.GetDriverDeliveryStats(orgID, from, to, 45)
+		assert.NoError(t, err)
+		assert.Equal(t, []database.DriverDeliveryStats{
+			{DriverID: driverID, FullName: "Sam", Deliveries: 25, Delivered: 24, ActiveDays: 4, DeliveryMinutes: 780, OnTime: 18, DistanceKm: 123.4},
+		}, drivers)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NoDeliveries", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from, to, 45).WillReturnRows(sqlmock.NewRows(columns))
+
+		drivers, err := store.GetDriverDeliveryStats(orgID, from, to, 45)
+		assert.NoError(t, err)
+		assert.NotNil(t, drivers)
+		assert.Empty(t, drivers)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from, to, 45).WillReturnError(fmt.Errorf("db error"))
+
+		drivers, err := store.GetDriverDeliveryStats(orgID, from, to, 45)
+		assert.Error(t, err)
+		assert.Nil(t, drivers)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetDeliveryHeatmap(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderStore(db, logger)
+	orgID := uuid.New()
+	columns := []string{"latitude", "longitude", "count"}
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT ROUND(d.delivery_latitude / $2::numeric) * $2::numeric AS latitude`)).
+			WithArgs(orgID, 0.01).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("30.05", "31.24", 12).AddRow("30.06", "31.22", 3))
+
+		cells, err := store.GetDeliveryHeatmap(orgID, 0.01, database.DeliveryFilter{})
+		assert.NoError(t, err)
+		assert.Equal(t, []database.DeliveryHeatmapCell{
+			{Latitude: 30.05, Longitude: 31.24, Deliveries: 12},
+			{Latitude: 30.06, Longitude: 31.22, Deliveries: 3},
+		}, cells)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Filtered", func(t *testing.T) {
+		from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+		to := from.AddDate(0, 0, 30)
+		mock.ExpectQuery(regexp.QuoteMeta(`AND d.out_for_delivery_time >= $2 AND d.out_for_delivery_time < $3 AND d.status IN ($4)`)).
+			WithArgs(orgID, from, to, "delivered", 0.005).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		cells, err := store.GetDeliveryHeatmap(orgID, 0.005, database.DeliveryFilter{From: &from, To: &to, Statuses: []string{"delivered"}})
+		assert.NoError(t, err)
+		assert.NotNil(t, cells)
+		assert.Empty(t, cells)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`GROUP BY 1, 2`)).WillReturnError(fmt.Errorf("db error"))
+
+		cells, err := store.GetDeliveryHeatmap(orgID, 0.01, database.DeliveryFilter{})
+		assert.Error(t, err)
+		assert.Nil(t, cells)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetDeliveryInsights(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderStore(db, logger)
+	orgID := uuid.New()
 
+	expectCounts := func() {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM deliveries d`)).WithArgs(orgID).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(120))
+		mock.ExpectQuery(regexp.QuoteMeta(`d.out_for_delivery_time >= NOW() - INTERVAL '7 days'`)).WithArgs(orgID).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(42))
+		mock.ExpectQuery(regexp.QuoteMeta(`DATE(d.out_for_delivery_time) = CURRENT_DATE`)).WithArgs(orgID).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(6))
+	}
+	slaQuery := regexp.QuoteMeta(`FROM (SELECT COALESCE((SELECT delivery_sla_minutes FROM organizations_rules WHERE organization_id = $1), 45) AS minutes) sla`)
+
+	t.Run("SLABreaches", func(t *testing.T) {
+		expectCounts()
+		mock.ExpectQuery(slaQuery).WithArgs(orgID).
+			WillReturnRows(sqlmock.NewRows([]string{"minutes", "delivered", "breaches", "today_delivered", "today_breaches"}).AddRow(30, 40, 3, 6, 0))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT TO_CHAR(d.out_for_delivery_time, 'Day')`)).WithArgs(orgID).WillReturnRows(sqlmock.NewRows([]string{"day_name"}).AddRow("Friday"))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXTRACT(HOUR FROM d.out_for_delivery_time)::int`)).WithArgs(orgID).WillReturnRows(sqlmock.NewRows([]string{"hour"}).AddRow(20))
+
+		insights, err := store.GetDeliveryInsights(orgID)
+		assert.NoError(t, err)
+		assert.Contains(t, insights, database.Insight{Title: "Delivery SLA", Statistic: "30 min"})
+		assert.Contains(t, insights, database.Insight{Title: "SLA Breaches (Last 7 Days)", Statistic: "3 of 40 (7.5%)"})
+		assert.Contains(t, insights, database.Insight{Title: "SLA Breaches (Today)", Statistic: "0 of 6 (0%)"})
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("SLAError", func(t *testing.T) {
+		expectCounts()
+		mock.ExpectQuery(slaQuery).WithArgs(orgID).WillReturnError(fmt.Errorf("db error"))
+
+		insights, err := store.GetDeliveryInsights(orgID)
+		assert.Error(t, err)
+		assert.Nil(t, insights)
 		AssertExpectations(t, mock)
 	})
 }
@@ -230,7 +738,7 @@ func TestStoreItems(t *testing.T) {
 		mock.ExpectQuery(qCheck).WithArgs(orgID, item.Name).WillReturnRows(NewRow(true))
 
 		err := store.StoreItems(orgID, item)
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, database.ErrItemExists)
 		assert.Contains(t, err.Error(), "already exists")
 		AssertExpectations(t, mock)
 	})
@@ -242,12 +750,12 @@ func TestGetAllItems(t *testing.T) {
 	store := database.NewPostgresOrderStore(db, logger)
 	orgID := uuid.New()
 
-	q := regexp.QuoteMeta(`SELECT id, name, needed_num_to_prepare, price FROM items WHERE organization_id = $1 ORDER BY name ASC`)
+	q := regexp.QuoteMeta(`SELECT id, name, needed_num_to_prepare, price, is_active FROM items WHERE organization_id = $1 ORDER BY name ASC`)
 
 	t.Run("Success", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "name", "needed_num_to_prepare", "price"}).
-			AddRow(uuid.New(), "Burger", 2, 10.0).
-			AddRow(uuid.New(), "Fries", 1, 5.0)
+		rows := sqlmock.NewRows([]string{"id", "name", "needed_num_to_prepare", "price", "is_active"}).
+			AddRow(uuid.New(), "Burger", 2, 10.0, true).
+			AddRow(uuid.New(), "Fries", 1, 5.0, false)
 
 		mock.ExpectQuery(q).WithArgs(orgID).WillReturnRows(rows)
 
@@ -255,6 +763,119 @@ func TestGetAllItems(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Len(t, items, 2)
 		assert.Equal(t, "Burger", items[0].Name)
+		assert.True(t, items[0].IsActive)
+		assert.False(t, items[1].IsActive)
+		AssertExpectations(t, mock)
+	})
+}
+
+var itemColumns = []string{"id", "name", "needed_num_to_prepare", "price", "is_active"}
+
+func TestGetItem(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderStore(db, logger)
+	orgID, itemID := uuid.New(), uuid.New()
+
+	q := regexp.QuoteMeta(`FROM items WHERE organization_id = $1 AND id = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(q).WithArgs(orgID, itemID).WillReturnRows(sqlmock.NewRows(itemColumns).AddRow(itemID, "Burger", 2, 10.0, false))
+
+		item, err := store.GetItem(orgID, itemID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Burger", item.Name)
+		assert.Equal(t, 10.0, *item.Price)
+		assert.False(t, item.IsActive)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectQuery(q).WithArgs(orgID, itemID).WillReturnRows(sqlmock.NewRows(itemColumns))
+
+		item, err := store.GetItem(orgID, itemID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Nil(t, item)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestUpdateItem(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderStore(db, logger)
+	orgID, adminID := uuid.New(), uuid.New()
+
+	needed, price := 2, 12.0
+	item := &database.Item{ItemID: uuid.New(), Name: "Burger", NeededNumEmployeesToPrepare: &needed, Price: &price, IsActive: true}
+
+	qPrice := regexp.QuoteMeta(`SELECT price FROM items WHERE organization_id = $1 AND id = $2 FOR UPDATE`)
+	qName := regexp.QuoteMeta(`SELECT EXISTS(SELECT 1 FROM items WHERE organization_id = $1 AND name = $2 AND id <> $3)`)
+	qUpdate := regexp.QuoteMeta(`UPDATE items SET name = $3, needed_num_to_prepare = $4, price = $5, is_active = $6 WHERE organization_id = $1 AND id = $2`)
+	qHistory := regexp.QuoteMeta(`INSERT INTO item_price_history (organization_id, item_id, old_price, new_price, changed_by)`)
+
+	t.Run("Success_RecordsPriceChange", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(qPrice).WithArgs(orgID, item.ItemID).WillReturnRows(sqlmock.NewRows([]string{"price"}).AddRow(10.0))
+		mock.ExpectQuery(qName).WithArgs(orgID, "Burger", item.ItemID).WillReturnRows(NewRow(false))
+		mock.ExpectExec(qUpdate).WithArgs(orgID, item.ItemID, "Burger", &needed, &price, true).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(qHistory).WithArgs(orgID, item.ItemID, 10.0, 12.0, &adminID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, store.UpdateItem(orgID, item, &adminID))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Success_SamePrice", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(qPrice).WithArgs(orgID, item.ItemID).WillReturnRows(sqlmock.NewRows([]string{"price"}).AddRow(12.0))
+		mock.ExpectQuery(qName).WithArgs(orgID, "Burger", item.ItemID).WillReturnRows(NewRow(false))
+		mock.ExpectExec(qUpdate).WithArgs(orgID, item.ItemID, "Burger", &needed, &price, true).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, store.UpdateItem(orgID, item, &adminID))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(qPrice).WithArgs(orgID, item.ItemID).WillReturnRows(sqlmock.NewRows([]string{"price"}))
+		mock.ExpectRollback()
+
+		assert.ErrorIs(t, store.UpdateItem(orgID, item, &adminID), sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NameTaken", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(qPrice).WithArgs(orgID, item.ItemID).WillReturnRows(sqlmock.NewRows([]string{"price"}).AddRow(12.0))
+		mock.ExpectQuery(qName).WithArgs(orgID, "Burger", item.ItemID).WillReturnRows(NewRow(true))
+		mock.ExpectRollback()
+
+		assert.ErrorIs(t, store.UpdateItem(orgID, item, &adminID), database.ErrItemExists)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestDeactivateItem(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderStore(db, logger)
+	orgID, itemID := uuid.New(), uuid.New()
+
+	q := regexp.QuoteMeta(`UPDATE items SET is_active = FALSE WHERE organization_id = $1 AND id = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(q).WithArgs(orgID, itemID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.DeactivateItem(orgID, itemID))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectExec(q).WithArgs(orgID, itemID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, store.DeactivateItem(orgID, itemID), sql.ErrNoRows)
 		AssertExpectations(t, mock)
 	})
 }
@@ -323,4 +944,328 @@ func TestGetAllDeliveries(t *testing.T) {
 		assert.Equal(t, "delivered", deliveries[0].DeliveryStatus)
 		AssertExpectations(t, mock)
 	})
+
+	t.Run("Filtered", func(t *testing.T) {
+		driverID := uuid.New()
+		from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+		q := regexp.QuoteMeta(`WHERE o.organization_id = $1 AND d.out_for_delivery_time >= $2 AND d.status IN ($3, $4) AND d.driver_id IN ($5) ORDER BY d.out_for_delivery_time DESC`)
+		rows := sqlmock.NewRows([]string{"order_id", "driver_id", "delivery_latitude", "delivery_longitude", "out_for_delivery_time", "delivered_time", "status"})
+		mock.ExpectQuery(q).WithArgs(orgID, &from, "out for delivery", "not delivered", driverID).WillReturnRows(rows)
+
+		deliveries, err := store.GetDeliveries(orgID, database.DeliveryFilter{
+			From:      &from,
+			Statuses:  []string{"out for delivery", "not delivered"},
+			DriverIDs: []uuid.UUID{driverID},
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, deliveries)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestStoreNestedOrder(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderStore(db, logger)
+
+	orgID := uuid.New()
+	itemID := uuid.New()
+	now := time.Now()
+	quantity := 2
+	totalPrice := 30.0
+	total, discount := 30.0, 0.0
+
+	insertOrder := regexp.QuoteMeta(`INSERT INTO orders (id, user_id, organization_id, create_time, order_type, order_status, total_amount, discount_amount, rating, channel) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) ON CONFLICT (id) DO NOTHING`)
+	countItems := regexp.QuoteMeta(`SELECT COUNT(*) FROM items WHERE organization_id = $1 AND id = ANY($2::uuid[])`)
+	insertItem := regexp.QuoteMeta(`INSERT INTO order_items (order_id, item_id, quantity, total_price) VALUES ($1, $2, $3, $4) ON CONFLICT (order_id, item_id) DO UPDATE`)
+	insertDelivery := regexp.QuoteMeta(`INSERT INTO deliveries (order_id, driver_id, delivery_latitude, delivery_longitude, out_for_delivery_time, delivered_time, status) VALUES ($1, $2, $3, $4, $5, $6, $7)`)
+
+	newOrder := func() *database.Order {
+		orderID := uuid.New()
+		return &database.Order{
+			OrderID:        orderID,
+			UserID:         uuid.New(),
+			CreateTime:     now,
+			OrderType:      "delivery",
+			OrderStatus:    "completed",
+			TotalAmount:    &total,
+			DiscountAmount: &discount,
+			OrderItems: []database.OrderItem{
+				{ItemID: itemID, Quantity: &quantity, TotalPrice: &totalPrice},
+				{ItemID: itemID, Quantity: &quantity, TotalPrice: &totalPrice},
+			},
+			DeliveryStatus: &database.OrderDelivery{OrderID: orderID, DriverID: uuid.New(), OutForDeliveryTime: now, DeliveryStatus: "out for delivery"},
+		}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		order := newOrder()
+		mock.ExpectBegin()
+		mock.ExpectExec(insertOrder).
+			WithArgs(order.OrderID, order.UserID, orgID, order.CreateTime, order.OrderType, order.OrderStatus, order.TotalAmount, order.DiscountAmount, order.Rating, database.OrderChannelDirect).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		// The same item twice is checked once
+		mock.ExpectQuery(countItems).WithArgs(orgID, sqlmock.AnyArg()).WillReturnRows(NewRow(1))
+		mock.ExpectExec(insertItem).WithArgs(order.OrderID, itemID, quantity, &totalPrice).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(insertItem).WithArgs(order.OrderID, itemID, quantity, &totalPrice).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(insertDelivery).WithArgs(order.OrderID, order.DeliveryStatus.DriverID, nil, nil, now, time.Time{}, "out for delivery").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := store.StoreNestedOrder(orgID, order)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, order.OrderCount)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DuplicateOrder", func(t *testing.T) {
+		order := newOrder()
+		mock.ExpectBegin()
+		mock.ExpectExec(insertOrder).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := store.StoreNestedOrder(orgID, order)
+		assert.ErrorIs(t, err, database.ErrDuplicateOrder)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("UnknownItemRollsBack", func(t *testing.T) {
+		order := newOrder()
+		mock.ExpectBegin()
+		mock.ExpectExec(insertOrder).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(countItems).WithArgs(orgID, sqlmock.AnyArg()).WillReturnRows(NewRow(0))
+		mock.ExpectRollback()
+
+		err := store.StoreNestedOrder(orgID, order)
+		assert.ErrorIs(t, err, database.ErrUnknownOrderItem)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DeliveryErrorRollsBack", func(t *testing.T) {
+		order := newOrder()
+		order.OrderItems = nil
+		mock.ExpectBegin()
+		mock.ExpectExec(insertOrder).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(insertDelivery).WillReturnError(fmt.Errorf("check constraint violated"))
+		mock.ExpectRollback()
+
+		err := store.StoreNestedOrder(orgID, order)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestUpdateOrderProgress(t *testing.T) {
+	db, mock := NewTestDB(t)
+	defer db.Close()
+
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderStore(db, logger)
+
+	orgID := uuid.New()
+	orderID := uuid.New()
+	now := time.Now()
+
+	updateOrder := regexp.QuoteMeta(`UPDATE orders SET order_status = $3 WHERE id = $1 AND organization_id = $2`)
+	upsertDelivery := regexp.QuoteMeta(`INSERT INTO deliveries (order_id, driver_id, delivery_latitude, delivery_longitude, out_for_delivery_time, delivered_time, status) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (order_id) DO UPDATE`)
+
+	t.Run("StatusAndDelivery", func(t *testing.T) {
+		delivery := &database.OrderDelivery{OrderID: orderID, OutForDeliveryTime: now, DeliveredTime: now, DeliveryStatus: "delivered"}
+		mock.ExpectBegin()
+		mock.ExpectExec(updateOrder).WithArgs(orderID, orgID, "completed").WillReturnResult(sqlmock.NewResult(0, 1))
+		// Platform couriers are not ClockWise drivers
+		mock.ExpectExec(upsertDelivery).WithArgs(orderID, nil, nil, nil, now, now, "delivered").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := store.UpdateOrderProgress(orgID, orderID, "completed", delivery)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("StatusOnly", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(updateOrder).WithArgs(orderID, orgID, "incompleted").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := store.UpdateOrderProgress(orgID, orderID, "incompleted", nil)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotInOrganization", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(updateOrder).WithArgs(orderID, orgID, "completed").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := store.UpdateOrderProgress(orgID, orderID, "completed", &database.OrderDelivery{OutForDeliveryTime: now, DeliveryStatus: "delivered"})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetOrder(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderStore(db, logger)
+
+	orgID := uuid.New()
+	orderID := uuid.New()
+	now := time.Now()
+	qSelectOrder := regexp.QuoteMeta(`FROM orders WHERE id = $1 AND organization_id = $2`)
+	columns := []string{"id", "user_id", "organization_id", "create_time", "order_type", "order_status", "total_amount", "discount_amount", "rating", "channel"}
+
+	t.Run("Success_WithItemsAndDelivery", func(t *testing.T) {
+		mock.ExpectQuery(qSelectOrder).WithArgs(orderID, orgID).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(orderID, uuid.New(), orgID, now, "delivery", "completed", 30.0, 5.0, nil, "direct"))
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM order_items WHERE order_id IN ($1)`)).WithArgs(orderID).
+			WillReturnRows(sqlmock.NewRows([]string{"order_id", "item_id", "quantity", "total_price"}).AddRow(orderID, uuid.New(), 2, 30.0))
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM deliveries WHERE order_id IN ($1)`)).WithArgs(orderID).
+			WillReturnRows(sqlmock.NewRows([]string{"order_id", "driver_id", "delivery_latitude", "delivery_longitude", "out_for_delivery_time", "delivered_time", "status"}).
+				AddRow(orderID, uuid.New(), 10.0, 20.0, now, nil, "out for delivery"))
+
+		order, err := store.GetOrder(orgID, orderID)
+		assert.NoError(t, err)
+		assert.Equal(t, orderID, order.OrderID)
+		assert.Equal(t, 1, order.OrderCount)
+		assert.Equal(t, "out for delivery", order.DeliveryStatus.DeliveryStatus)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectQuery(qSelectOrder).WithArgs(orderID, orgID).WillReturnRows(sqlmock.NewRows(columns))
+
+		order, err := store.GetOrder(orgID, orderID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Nil(t, order)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestUpdateOrder(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderStore(db, logger)
+
+	orgID := uuid.New()
+	total, discount := 20.0, 2.0
+	order := &database.Order{OrderID: uuid.New(), UserID: uuid.New(), CreateTime: time.Now(), OrderType: "takeaway", OrderStatus: "incompleted",
+		TotalAmount: &total, DiscountAmount: &discount}
+	updateOrder := regexp.QuoteMeta(`UPDATE orders SET user_id = $3, create_time = $4, order_type = $5, order_status = $6, total_amount = $7, discount_amount = $8, rating = $9, channel = $10 WHERE id = $1 AND organization_id = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(updateOrder).
+			WithArgs(order.OrderID, orgID, order.UserID, order.CreateTime, "takeaway", "incompleted", &total, &discount, nil, database.OrderChannelDirect).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.UpdateOrder(orgID, order)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotInOrganization", func(t *testing.T) {
+		mock.ExpectExec(updateOrder).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := store.UpdateOrder(orgID, order)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestDeleteOrder(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderStore(db, logger)
+
+	orgID := uuid.New()
+	orderID := uuid.New()
+	exists := regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM orders WHERE id = $1 AND organization_id = $2)`)
+
+	t.Run("Success_DeletesItemsFirst", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(exists).WithArgs(orderID, orgID).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM order_items WHERE order_id = $1`)).WithArgs(orderID).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM order_tables WHERE order_id = $1`)).WithArgs(orderID).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM custom_field_values`)).WithArgs(orderID, orgID).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM orders WHERE id = $1 AND organization_id = $2`)).WithArgs(orderID, orgID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := store.DeleteOrder(orgID, orderID)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(exists).WithArgs(orderID, orgID).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectRollback()
+
+		err := store.DeleteOrder(orgID, orderID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestTransitionOrderStatus(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrderStore(db, logger)
+
+	orgID := uuid.New()
+	orderID := uuid.New()
+	userID := uuid.New()
+	lock := regexp.QuoteMeta(`SELECT order_status FROM orders WHERE id = $1 AND organization_id = $2 FOR UPDATE`)
+
+	t.Run("Success", func(t *testing.T) {
+		changeID := uuid.New()
+		now := time.Now()
+		note := "Plated"
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(orderID, orgID).WillReturnRows(sqlmock.NewRows([]string{"order_status"}).AddRow("preparing"))
+		mock.ExpectExec(regexp.QuoteMeta(`THEN COALESCE(prepared_at, NOW())`)).WithArgs(orderID, "ready").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO order_status_history (order_id, organization_id, from_status, to_status, changed_by, note)`)).
+			WithArgs(orderID, orgID, "preparing", "ready", &userID, &note).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "changed_at"}).AddRow(changeID, now))
+		mock.ExpectCommit()
+
+		change, err := store.TransitionOrderStatus(orgID, orderID, "ready", &userID, &note)
+		assert.NoError(t, err)
+		if assert.NotNil(t, change) {
+			assert.Equal(t, changeID, change.ID)
+			assert.Equal(t, "preparing", change.FromStatus)
+			assert.Equal(t, "ready", change.ToStatus)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotAllowed", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(orderID, orgID).WillReturnRows(sqlmock.NewRows([]string{"order_status"}).AddRow("completed"))
+		mock.ExpectRollback()
+
+		_, err := store.TransitionOrderStatus(orgID, orderID, "preparing", &userID, nil)
+		var transitionErr *database.OrderTransitionError
+		if assert.ErrorAs(t, err, &transitionErr) {
+			assert.Equal(t, "completed", transitionErr.From)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lock).WithArgs(orderID, orgID).WillReturnRows(sqlmock.NewRows([]string{"order_status"}))
+		mock.ExpectRollback()
+
+		_, err := store.TransitionOrderStatus(orgID, orderID, "preparing", &userID, nil)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestCanTransitionOrder(t *testing.T) {
+	assert.True(t, database.CanTransitionOrder("pending", "preparing"))
+	assert.True(t, database.CanTransitionOrder("ready", "completed"))
+	assert.True(t, database.CanTransitionOrder("incompleted", "completed"))
+	assert.False(t, database.CanTransitionOrder("pending", "ready"))
+	assert.False(t, database.CanTransitionOrder("cancelled", "pending"))
+	assert.False(t, database.CanTransitionOrder("completed", "cancelled"))
 }
@@ -0,0 +1,156 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var organizationRatingColumns = []string{"id", "organization_id", "rating", "rated_orders", "window_days", "half_life_days", "computed_at"}
+
+func TestGetRatedOrganizations(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrgRatingStore(db, logger)
+
+	since := time.Date(2026, 7, 17, 3, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`SELECT DISTINCT organization_id FROM orders WHERE rating IS NOT NULL AND create_time >= $1`)
+
+	t.Run("Success", func(t *testing.T) {
+		orgID := uuid.New()
+		mock.ExpectQuery(query).WithArgs(since).WillReturnRows(sqlmock.NewRows([]string{"organization_id"}).AddRow(orgID))
+
+		orgs, err := store.GetRatedOrganizations(since)
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{orgID}, orgs)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(since).WillReturnError(fmt.Errorf("db error"))
+
+		orgs, err := store.GetRatedOrganizations(since)
+		assert.Error(t, err)
+		assert.Nil(t, orgs)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetOrderRatings(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrgRatingStore(db, logger)
+
+	orgID := uuid.New()
+	since := time.Date(2026, 7, 17, 3, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`FROM orders
+		WHERE organization_id = $1 AND rating IS NOT NULL AND create_time >= $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		placed := time.Date(2026, 10, 14, 13, 0, 0, 0, time.UTC)
+		mock.ExpectQuery(query).WithArgs(orgID, since).
+			WillReturnRows(sqlmock.NewRows([]string{"rating", "create_time"}).AddRow(4.5, placed).AddRow(3.0, placed.Add(time.Hour)))
+
+		ratings, err := store.GetOrderRatings(orgID, since)
+		assert.NoError(t, err)
+		assert.Len(t, ratings, 2)
+		assert.Equal(t, 4.5, ratings[0].Rating)
+		assert.Equal(t, placed, ratings[0].CreateTime)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NoRatings", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, since).WillReturnRows(sqlmock.NewRows([]string{"rating", "create_time"}))
+
+		ratings, err := store.GetOrderRatings(orgID, since)
+		assert.NoError(t, err)
+		assert.Empty(t, ratings)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, since).WillReturnError(fmt.Errorf("db error"))
+
+		ratings, err := store.GetOrderRatings(orgID, since)
+		assert.Error(t, err)
+		assert.Nil(t, ratings)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestStoreRating(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrgRatingStore(db, logger)
+
+	orgID := uuid.New()
+	computedAt := time.Date(2026, 10, 15, 3, 0, 0, 0, time.UTC)
+	insert := regexp.QuoteMeta(`INSERT INTO organization_rating_history`)
+	update := regexp.QuoteMeta(`UPDATE organizations SET rating = $2 WHERE id = $1`)
+
+	t.Run("Success", func(t *testing.T) {
+		rating := &database.OrganizationRating{OrganizationID: orgID, Rating: 4.37, RatedOrders: 120, WindowDays: 90, HalfLifeDays: 30, ComputedAt: computedAt}
+		mock.ExpectBegin()
+		mock.ExpectExec(insert).WithArgs(sqlmock.AnyArg(), orgID, 4.37, 120, 90, 30, computedAt).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(update).WithArgs(orgID, 4.37).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := store.StoreRating(rating)
+		assert.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, rating.ID)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("UpdateFailsRollsBack", func(t *testing.T) {
+		rating := &database.OrganizationRating{OrganizationID: orgID, Rating: 4.37, RatedOrders: 120, WindowDays: 90, HalfLifeDays: 30, ComputedAt: computedAt}
+		mock.ExpectBegin()
+		mock.ExpectExec(insert).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(update).WillReturnError(fmt.Errorf("db error"))
+		mock.ExpectRollback()
+
+		err := store.StoreRating(rating)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestListRatings(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrgRatingStore(db, logger)
+
+	orgID := uuid.New()
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`FROM organization_rating_history
+		WHERE organization_id = $1 AND computed_at >= $2 AND computed_at < $3`)
+
+	t.Run("Success", func(t *testing.T) {
+		id := uuid.New()
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).WillReturnRows(
+			sqlmock.NewRows(organizationRatingColumns).AddRow(id, orgID, 4.2, 80, 90, 30, from.Add(3*time.Hour)))
+
+		ratings, err := store.ListRatings(orgID, from, to)
+		assert.NoError(t, err)
+		assert.Len(t, ratings, 1)
+		assert.Equal(t, id, ratings[0].ID)
+		assert.Equal(t, 4.2, ratings[0].Rating)
+		assert.Equal(t, 80, ratings[0].RatedOrders)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).WillReturnError(fmt.Errorf("db error"))
+
+		ratings, err := store.ListRatings(orgID, from, to)
+		assert.Error(t, err)
+		assert.Nil(t, ratings)
+		AssertExpectations(t, mock)
+	})
+}
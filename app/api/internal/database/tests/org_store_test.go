@@ -10,6 +10,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/clockwise/clockwise/backend/internal/database"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -107,6 +108,111 @@ func TestCreateOrgWithAdmin(t *testing.T) {
 	})
 }
 
+func TestCloneOrganization(t *testing.T) {
+	db, mock := NewTestDB(t)
+	store := database.NewPostgresOrgStore(db, NewTestLogger())
+
+	sourceID, adminID := uuid.New(), uuid.New()
+	itemID, campaignID := uuid.New(), uuid.New()
+	newBranch := func() *database.Organization {
+		return &database.Organization{Name: "Downtown", Email: "downtown@example.com", Phone: "+15550100"}
+	}
+	insertOrg := regexp.QuoteMeta(`INSERT INTO organizations (id, name, address, latitude, longitude, email, phone, type,`)
+	insertMember := regexp.QuoteMeta(`INSERT INTO organization_memberships (user_id, organization_id, user_role, created_at) VALUES ($1, $2, 'admin', $3)`)
+	orgRow := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"type", "hex_code1", "hex_code2", "hex_code3"}).AddRow("cafe", "FFFFFF", "000000", "111111")
+	}
+
+	t.Run("Everything", func(t *testing.T) {
+		branch := newBranch()
+		mock.ExpectBegin()
+		mock.ExpectQuery(insertOrg).WithArgs(sourceID, sqlmock.AnyArg(), "Downtown", "", nil, nil, "downtown@example.com", "+15550100", sqlmock.AnyArg()).
+			WillReturnRows(orgRow())
+		mock.ExpectExec(insertMember).WithArgs(adminID, sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO organizations_rules`)).WithArgs(sourceID, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`ON CONFLICT (organization_id, role) DO UPDATE`)).WithArgs(sourceID, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 5))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO production_chain`)).WithArgs(sourceID, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO role_skill_requirements`)).WithArgs(sourceID, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO organizations_operating_hours`)).WithArgs(sourceID, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 7))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM items WHERE organization_id = $1`)).WithArgs(sourceID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemID))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO items (id, organization_id, name, needed_num_to_prepare, price, is_active)`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM marketing_campaigns WHERE organization_id = $1`)).WithArgs(sourceID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(campaignID))
+		mock.ExpectExec(regexp.QuoteMeta(`SELECT m.to_id, $1, c.name, 'inactive'`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO campaigns_items (campaign_id, item_id)`)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		clone, err := store.CloneOrganization(sourceID, branch, adminID,
+			database.OrgCloneOptions{Rules: true, Roles: true, OperatingHours: true, Items: true, Campaigns: true})
+		assert.NoError(t, err)
+		if assert.NotNil(t, clone) {
+			assert.NotEqual(t, uuid.Nil, clone.Organization.ID)
+			assert.Equal(t, "cafe", clone.Organization.Type)
+			assert.True(t, clone.Rules)
+			assert.Equal(t, 5, clone.Roles)
+			assert.Equal(t, 7, clone.OperatingHours)
+			assert.Equal(t, 1, clone.Items)
+			assert.Equal(t, 1, clone.Campaigns)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("CampaignsWithoutItems", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(insertOrg).WillReturnRows(orgRow())
+		mock.ExpectExec(insertMember).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id FROM marketing_campaigns WHERE organization_id = $1`)).WithArgs(sourceID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(campaignID))
+		mock.ExpectExec(regexp.QuoteMeta(`SELECT m.to_id, $1, c.name, 'inactive'`)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		clone, err := store.CloneOrganization(sourceID, newBranch(), adminID, database.OrgCloneOptions{Campaigns: true})
+		assert.NoError(t, err)
+		if assert.NotNil(t, clone) {
+			assert.False(t, clone.Rules)
+			assert.Equal(t, 1, clone.Campaigns)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NameTaken", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(insertOrg).WillReturnError(&pq.Error{Code: "23505"})
+		mock.ExpectRollback()
+
+		clone, err := store.CloneOrganization(sourceID, newBranch(), adminID, database.OrgCloneOptions{Rules: true})
+		assert.ErrorIs(t, err, database.ErrOrganizationExists)
+		assert.Nil(t, clone)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("SourceNotFound", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(insertOrg).WillReturnRows(sqlmock.NewRows([]string{"type", "hex_code1", "hex_code2", "hex_code3"}))
+		mock.ExpectRollback()
+
+		_, err := store.CloneOrganization(sourceID, newBranch(), adminID, database.OrgCloneOptions{Rules: true})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("CopyError_RollsBack", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(insertOrg).WillReturnRows(orgRow())
+		mock.ExpectExec(insertMember).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO organizations_operating_hours`)).WillReturnError(fmt.Errorf("db error"))
+		mock.ExpectRollback()
+
+		_, err := store.CloneOrganization(sourceID, newBranch(), adminID, database.OrgCloneOptions{OperatingHours: true})
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
 func TestGetOrganizationByID(t *testing.T) {
 	db, mock := NewTestDB(t)
 	logger := NewTestLogger()
@@ -249,3 +355,182 @@ func TestGetAdminEmailsByOrgID(t *testing.T) {
 		AssertExpectations(t, mock)
 	})
 }
+
+func TestSetCustomDomain(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrgStore(db, logger)
+
+	orgID := uuid.New()
+	domain := "shifts.example.com"
+	token := "clockwise-verify=abc"
+	query := regexp.QuoteMeta(`SET custom_domain = $1, custom_domain_token = $2, custom_domain_verified_at = NULL, updated_at = $3`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(&domain, &token, sqlmock.AnyArg(), orgID).WillReturnResult(sqlmock.NewResult(0, 1))
+		err := store.SetCustomDomain(orgID, &domain, &token)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(&domain, &token, sqlmock.AnyArg(), orgID).WillReturnResult(sqlmock.NewResult(0, 0))
+		err := store.SetCustomDomain(orgID, &domain, &token)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetCustomDomain(t *testing.T) {
+	db, mock := NewTestDB(t)
+	store := database.NewPostgresOrgStore(db, NewTestLogger())
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`SELECT custom_domain, COALESCE(custom_domain_token, ''), custom_domain_verified_at`)
+
+	t.Run("Pending", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnRows(sqlmock.NewRows([]string{"custom_domain", "token", "verified_at"}).
+			AddRow("shifts.example.com", "clockwise-verify=abc", nil))
+		domain, err := store.GetCustomDomain(orgID)
+		assert.NoError(t, err)
+		assert.Equal(t, "shifts.example.com", domain.Domain)
+		assert.Equal(t, "clockwise-verify=abc", domain.Token)
+		assert.Nil(t, domain.VerifiedAt)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotSet", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnError(sql.ErrNoRows)
+		_, err := store.GetCustomDomain(orgID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestVerifyCustomDomain(t *testing.T) {
+	db, mock := NewTestDB(t)
+	store := database.NewPostgresOrgStore(db, NewTestLogger())
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`UPDATE organizations SET custom_domain_verified_at = $1, updated_at = $1 WHERE id = $2 AND custom_domain IS NOT NULL`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(sqlmock.AnyArg(), orgID).WillReturnResult(sqlmock.NewResult(0, 1))
+		assert.NoError(t, store.VerifyCustomDomain(orgID))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotSet", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(sqlmock.AnyArg(), orgID).WillReturnResult(sqlmock.NewResult(0, 0))
+		assert.ErrorIs(t, store.VerifyCustomDomain(orgID), sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("VerifiedByAnotherOrganization", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(sqlmock.AnyArg(), orgID).WillReturnError(&pq.Error{Code: "23505"})
+		assert.ErrorIs(t, store.VerifyCustomDomain(orgID), database.ErrCustomDomainTaken)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetOrgIDByCustomDomain(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrgStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`SELECT id FROM organizations WHERE LOWER(custom_domain) = LOWER($1) AND custom_domain_verified_at IS NOT NULL`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("shifts.example.com").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(orgID))
+		id, err := store.GetOrgIDByCustomDomain("shifts.example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, orgID, id)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("unknown.example.com").WillReturnError(sql.ErrNoRows)
+		_, err := store.GetOrgIDByCustomDomain("unknown.example.com")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestSetBrandColors(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresOrgStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`UPDATE organizations SET hex_code1 = $1, hex_code2 = $2, hex_code3 = $3, updated_at = $4 WHERE id = $5`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs("BF4124", "010440", "F2DFDF", sqlmock.AnyArg(), orgID).WillReturnResult(sqlmock.NewResult(0, 1))
+		err := store.SetBrandColors(orgID, "BF4124", "010440", "F2DFDF")
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs("BF4124", "010440", "F2DFDF", sqlmock.AnyArg(), orgID).WillReturnResult(sqlmock.NewResult(0, 0))
+		err := store.SetBrandColors(orgID, "BF4124", "010440", "F2DFDF")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestOrganizationCurrency(t *testing.T) {
+	db, mock := NewTestDB(t)
+	store := database.NewPostgresOrgStore(db, NewTestLogger())
+
+	orgID := uuid.New()
+	getQuery := regexp.QuoteMeta(`SELECT currency FROM organizations WHERE id = $1`)
+	setQuery := regexp.QuoteMeta(`UPDATE organizations SET currency = $1, updated_at = $2 WHERE id = $3`)
+
+	t.Run("GetCurrency", func(t *testing.T) {
+		mock.ExpectQuery(getQuery).WithArgs(orgID).WillReturnRows(sqlmock.NewRows([]string{"currency"}).AddRow("EUR"))
+		currency, err := store.GetCurrency(orgID)
+		assert.NoError(t, err)
+		assert.Equal(t, "EUR", currency)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("SetCurrency", func(t *testing.T) {
+		mock.ExpectExec(setQuery).WithArgs("GBP", sqlmock.AnyArg(), orgID).WillReturnResult(sqlmock.NewResult(0, 1))
+		err := store.SetCurrency(orgID, "GBP")
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("SetCurrency_NotFound", func(t *testing.T) {
+		mock.ExpectExec(setQuery).WithArgs("GBP", sqlmock.AnyArg(), orgID).WillReturnResult(sqlmock.NewResult(0, 0))
+		err := store.SetCurrency(orgID, "GBP")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestOrganizationMagicLinkLogin(t *testing.T) {
+	db, mock := NewTestDB(t)
+	store := database.NewPostgresOrgStore(db, NewTestLogger())
+
+	orgID := uuid.New()
+
+	t.Run("GetMagicLinkEnabled", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT magic_link_enabled FROM organizations WHERE id = $1`)).WithArgs(orgID).
+			WillReturnRows(sqlmock.NewRows([]string{"magic_link_enabled"}).AddRow(true))
+		enabled, err := store.GetMagicLinkEnabled(orgID)
+		assert.NoError(t, err)
+		assert.True(t, enabled)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("SetMagicLinkEnabled_NotFound", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE organizations SET magic_link_enabled = $1, updated_at = $2 WHERE id = $3`)).
+			WithArgs(false, sqlmock.AnyArg(), orgID).WillReturnResult(sqlmock.NewResult(0, 0))
+		err := store.SetMagicLinkEnabled(orgID, false)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/clockwise/clockwise/backend/internal/database"
@@ -175,3 +176,80 @@ func TestDeletePreferenceByDay(t *testing.T) {
 		AssertExpectations(t, mock)
 	})
 }
+
+func TestCopyPreferences(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresPreferencesStore(db, logger)
+
+	fromID := uuid.New()
+	toIDs := []uuid.UUID{uuid.New(), uuid.New()}
+	deleteQuery := regexp.QuoteMeta(`DELETE FROM employees_preferences WHERE employee_id = ANY($1::uuid[])`)
+	copyQuery := regexp.QuoteMeta(`INSERT INTO employees_preferences (employee_id, day, preferred_start_time, preferred_end_time, available_start_time, available_end_time) SELECT t.id, p.day, p.preferred_start_time, p.preferred_end_time, p.available_start_time, p.available_end_time FROM employees_preferences p CROSS JOIN UNNEST($2::uuid[]) AS t(id) WHERE p.employee_id = $1`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteQuery).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(copyQuery).WillReturnResult(sqlmock.NewResult(0, 10))
+		mock.ExpectCommit()
+
+		err := store.CopyPreferences(fromID, toIDs)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("TransactionRollback", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteQuery).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(copyQuery).WillReturnError(fmt.Errorf("db error"))
+		mock.ExpectRollback()
+
+		err := store.CopyPreferences(fromID, toIDs)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetUnavailability(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresPreferencesStore(db, logger)
+
+	empID := uuid.New()
+	from := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 6)
+	query := regexp.QuoteMeta(`SELECT id, employee_id, request_id, reason, start_date, end_date, created_at FROM employee_unavailability WHERE employee_id = $1 AND start_date <= $3 AND end_date >= $2 ORDER BY start_date, end_date`)
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "employee_id", "request_id", "reason", "start_date", "end_date", "created_at"}).
+			AddRow(uuid.New(), empID, uuid.New(), "calloff", from, from, from).
+			AddRow(uuid.New(), empID, uuid.New(), "holiday", from.AddDate(0, 0, 3), from.AddDate(0, 0, 10), from)
+		mock.ExpectQuery(query).WithArgs(empID, from, to).WillReturnRows(rows)
+
+		unavailability, err := store.GetUnavailability(empID, from, to)
+		assert.NoError(t, err)
+		if assert.Len(t, unavailability, 2) {
+			assert.Equal(t, "calloff", unavailability[0].Reason)
+			assert.Equal(t, from.AddDate(0, 0, 10), unavailability[1].EndDate)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("None", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(empID, from, to).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		unavailability, err := store.GetUnavailability(empID, from, to)
+		assert.NoError(t, err)
+		assert.Empty(t, unavailability)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(empID, from, to).WillReturnError(fmt.Errorf("db error"))
+
+		unavailability, err := store.GetUnavailability(empID, from, to)
+		assert.Error(t, err)
+		assert.Nil(t, unavailability)
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDayForecast(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresPrepListStore(db, logger)
+
+	orgID := uuid.New()
+	date := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`FROM demand WHERE organization_id = $1 AND demand_date = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, "2025-03-14").
+			WillReturnRows(sqlmock.NewRows([]string{"hours", "orders", "items"}).AddRow(12, 40, 100))
+
+		forecast, err := store.GetDayForecast(orgID, date)
+		assert.NoError(t, err)
+		assert.Equal(t, &database.DayForecast{Date: date, OrderCount: 40, ItemCount: 100, Hours: 12}, forecast)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NoForecast", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, "2025-03-14").
+			WillReturnRows(sqlmock.NewRows([]string{"hours", "orders", "items"}).AddRow(0, 0, 0))
+
+		forecast, err := store.GetDayForecast(orgID, date)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Nil(t, forecast)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, "2025-03-14").WillReturnError(fmt.Errorf("db error"))
+
+		forecast, err := store.GetDayForecast(orgID, date)
+		assert.Error(t, err)
+		assert.Nil(t, forecast)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetItemSales(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresPrepListStore(db, logger)
+
+	orgID := uuid.New()
+	since := time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`WHERE i.organization_id = $1 AND i.is_active AND o.create_time >= $3 GROUP BY i.id, i.name ORDER BY i.name`)
+
+	t.Run("Success", func(t *testing.T) {
+		itemID := uuid.New()
+		rows := sqlmock.NewRows([]string{"id", "name", "units_on_weekday", "units_all_days"}).AddRow(itemID, "Burger", 12, 80)
+		mock.ExpectQuery(query).WithArgs(orgID, 5, since).WillReturnRows(rows)
+
+		sales, err := store.GetItemSales(orgID, time.Friday, since)
+		assert.NoError(t, err)
+		assert.Equal(t, []database.ItemSales{{ItemID: itemID, Name: "Burger", UnitsOnWeekday: 12, UnitsAllDays: 80}}, sales)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, 5, since).WillReturnError(fmt.Errorf("db error"))
+
+		sales, err := store.GetItemSales(orgID, time.Friday, since)
+		assert.Error(t, err)
+		assert.Nil(t, sales)
+		AssertExpectations(t, mock)
+	})
+}
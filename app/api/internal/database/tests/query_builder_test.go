@@ -0,0 +1,53 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditions(t *testing.T) {
+	orgID := uuid.New()
+
+	t.Run("NumbersArgumentsInOrder", func(t *testing.T) {
+		where := database.Where("organization_id = ?", orgID).
+			And("create_time >= ? AND create_time < ?", "2026-10-01", "2026-10-08")
+		where = database.AndIn(where, "channel", []string{"uber_eats", "deliveroo"})
+
+		conditions, args := where.Build()
+		assert.Equal(t, "organization_id = $1 AND create_time >= $2 AND create_time < $3 AND channel IN ($4, $5)", conditions)
+		assert.Equal(t, []any{orgID, "2026-10-01", "2026-10-08", "uber_eats", "deliveroo"}, args)
+	})
+
+	t.Run("UnsetFiltersSkipped", func(t *testing.T) {
+		where := database.Where("organization_id = ?", orgID).AndIf(false, "order_status = ?", "completed")
+		where = database.AndIn(where, "channel", []string(nil))
+
+		conditions, args := where.Build()
+		assert.Equal(t, "organization_id = $1", conditions)
+		assert.Equal(t, []any{orgID}, args)
+	})
+
+	t.Run("ValuesNeverInQuery", func(t *testing.T) {
+		conditions, args := database.Where("full_name = ?", "x' OR '1'='1").Build()
+		assert.Equal(t, "full_name = $1", conditions)
+		assert.Equal(t, []any{"x' OR '1'='1"}, args)
+	})
+
+	t.Run("Placeholders", func(t *testing.T) {
+		conditions, _ := database.Where("role IN ("+database.Placeholders(3)+")", "a", "b", "c").Build()
+		assert.Equal(t, "role IN ($1, $2, $3)", conditions)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		conditions, args := new(database.Conditions).Build()
+		assert.Equal(t, "TRUE", conditions)
+		assert.Empty(t, args)
+	})
+
+	t.Run("ArgumentCountMismatchPanics", func(t *testing.T) {
+		assert.Panics(t, func() { database.Where("organization_id = ? AND id = ?", orgID) })
+	})
+}
@@ -0,0 +1,212 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultApprovalChain(t *testing.T) {
+	assert.Equal(t, []string{"manager", "admin"}, database.DefaultApprovalChain("resign"))
+	assert.Equal(t, []string{"manager"}, database.DefaultApprovalChain("holiday"))
+	assert.Equal(t, []string{"manager"}, database.DefaultApprovalChain("calloff"))
+}
+
+func TestGetApprovalChains(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresApprovalStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`SELECT request_type, steps FROM request_approval_chains WHERE organization_id = $1 ORDER BY request_type`)
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"request_type", "steps"}).AddRow("holiday", "{manager,admin}")
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnRows(rows)
+
+		chains, err := store.GetApprovalChains(orgID)
+		assert.NoError(t, err)
+		assert.Equal(t, []database.ApprovalChain{{RequestType: "holiday", Steps: []string{"manager", "admin"}}}, chains)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnError(fmt.Errorf("db error"))
+
+		chains, err := store.GetApprovalChains(orgID)
+		assert.Error(t, err)
+		assert.Nil(t, chains)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetApprovalChain(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresApprovalStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`SELECT steps FROM request_approval_chains WHERE organization_id = $1 AND request_type = $2`)
+
+	t.Run("Configured", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, "holiday").WillReturnRows(sqlmock.NewRows([]string{"steps"}).AddRow("{admin}"))
+
+		steps, err := store.GetApprovalChain(orgID, "holiday")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"admin"}, steps)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, "resign").WillReturnError(sql.ErrNoRows)
+
+		steps, err := store.GetApprovalChain(orgID, "resign")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"manager", "admin"}, steps)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestSetApprovalChain(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresApprovalStore(db, logger)
+
+	orgID := uuid.New()
+	adminID := uuid.New()
+	query := regexp.QuoteMeta(`ON CONFLICT (organization_id, request_type) DO UPDATE SET steps = EXCLUDED.steps`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(orgID, "holiday", pq.Array([]string{"manager", "admin"}), adminID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.SetApprovalChain(orgID, "holiday", []string{"manager", "admin"}, adminID)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestDeleteApprovalChain(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresApprovalStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`DELETE FROM request_approval_chains WHERE organization_id = $1 AND request_type = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID, "resign").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.DeleteApprovalChain(orgID, "resign")
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID, "resign").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := store.DeleteApprovalChain(orgID, "resign")
+		assert.Equal(t, sql.ErrNoRows, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestStartApprovals(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresApprovalStore(db, logger)
+
+	requestID := uuid.New()
+	query := regexp.QuoteMeta(`SELECT $1, step, role FROM UNNEST($2::VARCHAR(10)[]) WITH ORDINALITY AS chain(role, step) ON CONFLICT (request_id, step) DO NOTHING`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(requestID, pq.Array([]string{"manager", "admin"})).WillReturnResult(sqlmock.NewResult(0, 2))
+
+		err := store.StartApprovals(requestID, []string{"manager", "admin"})
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectExec(query).WillReturnError(fmt.Errorf("db error"))
+
+		err := store.StartApprovals(requestID, []string{"manager"})
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetRequestApprovals(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresApprovalStore(db, logger)
+
+	first := uuid.New()
+	second := uuid.New()
+	query := regexp.QuoteMeta(`FROM request_approvals WHERE request_id = ANY($1::uuid[]) ORDER BY request_id, step`)
+
+	t.Run("Success", func(t *testing.T) {
+		managerID := uuid.New()
+		decidedAt := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+		rows := sqlmock.NewRows([]string{"request_id", "step", "approver_role", "decision", "decided_by", "decided_at"}).
+			AddRow(first, 1, "manager", "approved", managerID, decidedAt).
+			AddRow(first, 2, "admin", nil, nil, nil).
+			AddRow(second, 1, "manager", nil, nil, nil)
+		mock.ExpectQuery(query).WithArgs(pq.Array([]string{first.String(), second.String()})).WillReturnRows(rows)
+
+		approvals, err := store.GetRequestApprovals([]uuid.UUID{first, second})
+		assert.NoError(t, err)
+		if assert.Len(t, approvals[first], 2) {
+			assert.Equal(t, database.ApprovalApproved, *approvals[first][0].Decision)
+			assert.Equal(t, managerID, *approvals[first][0].DecidedBy)
+			assert.Equal(t, decidedAt, *approvals[first][0].DecidedAt)
+			assert.Nil(t, approvals[first][1].Decision)
+			assert.Nil(t, approvals[first][1].DecidedAt)
+		}
+		assert.Len(t, approvals[second], 1)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestDecideApprovalSteps(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresApprovalStore(db, logger)
+
+	requestID := uuid.New()
+	adminID := uuid.New()
+	query := regexp.QuoteMeta(`UPDATE request_approvals SET decision = $3, decided_by = $4, decided_at = NOW() WHERE request_id = $1 AND step = ANY($2) AND decision IS NULL`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(query).
+			WithArgs(requestID, pq.Array([]int64{1, 2}), database.ApprovalApproved, adminID).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		err := store.DecideApprovalSteps(requestID, []int{1, 2}, database.ApprovalApproved, adminID)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DecidedInTheMeantime", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(query).
+			WithArgs(requestID, pq.Array([]int64{1, 2}), database.ApprovalApproved, adminID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+
+		err := store.DecideApprovalSteps(requestID, []int{1, 2}, database.ApprovalApproved, adminID)
+		assert.ErrorIs(t, err, database.ErrApprovalStepDecided)
+		AssertExpectations(t, mock)
+	})
+}
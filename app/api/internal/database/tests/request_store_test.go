@@ -26,11 +26,11 @@ func TestCreateRequest(t *testing.T) {
 		Status:     "Pending",
 	}
 
-	query := regexp.QuoteMeta(`INSERT INTO requests (request_id, employee_id, type, message, submitted_at, updated_at, status) VALUES ($1, $2, $3, $4, $5, $6, $7)`)
+	query := regexp.QuoteMeta(`INSERT INTO requests (request_id, employee_id, type, message, submitted_at, updated_at, status, start_date, end_date, blackout_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`)
 
 	t.Run("Success", func(t *testing.T) {
 		mock.ExpectExec(query).
-			WithArgs(req.ID, req.EmployeeID, req.Type, req.Message, sqlmock.AnyArg(), sqlmock.AnyArg(), req.Status).
+			WithArgs(req.ID, req.EmployeeID, req.Type, req.Message, sqlmock.AnyArg(), sqlmock.AnyArg(), req.Status, req.StartDate, req.EndDate, req.BlackoutID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		err := store.CreateRequest(req)
@@ -52,17 +52,19 @@ func TestGetRequestByID(t *testing.T) {
 	store := database.NewPostgresRequestStore(db, logger)
 
 	reqID := uuid.New()
-	query := regexp.QuoteMeta(`SELECT request_id, employee_id, type, message, submitted_at, updated_at, status FROM requests WHERE request_id=$1`)
+	query := regexp.QuoteMeta(`SELECT request_id, employee_id, type, message, submitted_at, updated_at, status, start_date, end_date, blackout_id FROM requests WHERE request_id=$1`)
 
 	t.Run("Success", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"request_id", "employee_id", "type", "message", "submitted_at", "updated_at", "status"}).
-			AddRow(reqID, uuid.New(), "TimeOff", "Sick", time.Now(), time.Now(), "Pending")
+		rows := sqlmock.NewRows([]string{"request_id", "employee_id", "type", "message", "submitted_at", "updated_at", "status", "start_date", "end_date", "blackout_id"}).
+			AddRow(reqID, uuid.New(), "TimeOff", "Sick", time.Now(), time.Now(), "Pending", nil, nil, nil)
 
 		mock.ExpectQuery(query).WithArgs(reqID).WillReturnRows(rows)
 
 		req, err := store.GetRequestByID(reqID)
 		assert.NoError(t, err)
 		assert.Equal(t, reqID, req.ID)
+		assert.Nil(t, req.StartDate)
+		assert.Nil(t, req.BlackoutID)
 		AssertExpectations(t, mock)
 	})
 
@@ -81,17 +83,22 @@ func TestGetRequestsByEmployee(t *testing.T) {
 	store := database.NewPostgresRequestStore(db, logger)
 
 	empID := uuid.New()
-	query := regexp.QuoteMeta(`SELECT request_id, employee_id, type, message, submitted_at, updated_at, status FROM requests WHERE employee_id=$1 ORDER BY submitted_at DESC`)
+	start := time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC)
+	blackoutID := uuid.New()
+	query := regexp.QuoteMeta(`SELECT request_id, employee_id, type, message, submitted_at, updated_at, status, start_date, end_date, blackout_id FROM requests WHERE employee_id=$1 ORDER BY submitted_at DESC`)
 
 	t.Run("Success", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"request_id", "employee_id", "type", "message", "submitted_at", "updated_at", "status"}).
-			AddRow(uuid.New(), empID, "TimeOff", "Sick", time.Now(), time.Now(), "Pending")
+		rows := sqlmock.NewRows([]string{"request_id", "employee_id", "type", "message", "submitted_at", "updated_at", "status", "start_date", "end_date", "blackout_id"}).
+			AddRow(uuid.New(), empID, "holiday", "Trip", time.Now(), time.Now(), "Pending", start, start, blackoutID)
 
 		mock.ExpectQuery(query).WithArgs(empID).WillReturnRows(rows)
 
 		reqs, err := store.GetRequestsByEmployee(empID)
 		assert.NoError(t, err)
-		assert.Len(t, reqs, 1)
+		if assert.Len(t, reqs, 1) {
+			assert.Equal(t, start, *reqs[0].StartDate)
+			assert.Equal(t, blackoutID, *reqs[0].BlackoutID)
+		}
 		AssertExpectations(t, mock)
 	})
 }
@@ -102,11 +109,11 @@ func TestGetRequestsByOrganization(t *testing.T) {
 	store := database.NewPostgresRequestStore(db, logger)
 
 	orgID := uuid.New()
-	query := regexp.QuoteMeta(`SELECT r.request_id, r.employee_id, r.type, r.message, r.submitted_at, r.updated_at, r.status, u.full_name, u.email FROM requests r JOIN users u ON r.employee_id = u.id WHERE u.organization_id=$1 ORDER BY r.submitted_at DESC`)
+	query := regexp.QuoteMeta(`SELECT r.request_id, r.employee_id, r.type, r.message, r.submitted_at, r.updated_at, r.status, r.start_date, r.end_date, r.blackout_id, u.full_name, u.email FROM requests r JOIN users u ON r.employee_id = u.id WHERE u.organization_id=$1 ORDER BY r.submitted_at DESC`)
 
 	t.Run("Success", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"request_id", "employee_id", "type", "message", "submitted_at", "updated_at", "status", "full_name", "email"}).
-			AddRow(uuid.New(), uuid.New(), "TimeOff", "Sick", time.Now(), time.Now(), "Pending", "John Doe", "john@test.com")
+		rows := sqlmock.NewRows([]string{"request_id", "employee_id", "type", "message", "submitted_at", "updated_at", "status", "start_date", "end_date", "blackout_id", "full_name", "email"}).
+			AddRow(uuid.New(), uuid.New(), "TimeOff", "Sick", time.Now(), time.Now(), "Pending", nil, nil, nil, "John Doe", "john@test.com")
 
 		mock.ExpectQuery(query).WithArgs(orgID).WillReturnRows(rows)
 
@@ -142,3 +149,90 @@ func TestUpdateRequestStatus(t *testing.T) {
 		AssertExpectations(t, mock)
 	})
 }
+
+func TestAcceptRequest(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresRequestStore(db, logger)
+
+	reqID := uuid.New()
+	empID := uuid.New()
+	submitted := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	start := time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 4)
+	updateQuery := regexp.QuoteMeta(`UPDATE requests SET status='accepted', updated_at=CURRENT_TIMESTAMP WHERE request_id=$1 RETURNING employee_id, type, start_date, end_date, submitted_at`)
+	holidayQuery := regexp.QuoteMeta(`INSERT INTO employee_unavailability (employee_id, request_id, reason, start_date, end_date) VALUES ($1, $2, 'holiday', $3, $4)`)
+	calloffQuery := regexp.QuoteMeta(`SELECT $1, $2, 'calloff', day, day`) + `.*` + regexp.QuoteMeta(`schedule_date >= $3::date`)
+	requestColumns := []string{"employee_id", "type", "start_date", "end_date", "submitted_at"}
+	unavailabilityColumns := []string{"id", "reason", "start_date", "end_date", "created_at"}
+
+	t.Run("Holiday", func(t *testing.T) {
+		id := uuid.New()
+		mock.ExpectBegin()
+		mock.ExpectQuery(updateQuery).WithArgs(reqID).
+			WillReturnRows(sqlmock.NewRows(requestColumns).AddRow(empID, "holiday", start, end, submitted))
+		mock.ExpectQuery(holidayQuery).WithArgs(empID, reqID, &start, &end).
+			WillReturnRows(sqlmock.NewRows(unavailabilityColumns).AddRow(id, "holiday", start, end, submitted))
+		mock.ExpectCommit()
+
+		unavailability, err := store.AcceptRequest(reqID)
+		assert.NoError(t, err)
+		assert.Equal(t, id, unavailability.ID)
+		assert.Equal(t, empID, unavailability.EmployeeID)
+		assert.Equal(t, reqID, unavailability.RequestID)
+		assert.Equal(t, end, unavailability.EndDate)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Calloff_NextShift", func(t *testing.T) {
+		shiftDay := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+		mock.ExpectBegin()
+		mock.ExpectQuery(updateQuery).WithArgs(reqID).
+			WillReturnRows(sqlmock.NewRows(requestColumns).AddRow(empID, "calloff", nil, nil, submitted))
+		mock.ExpectQuery(calloffQuery).WithArgs(empID, reqID, submitted).
+			WillReturnRows(sqlmock.NewRows(unavailabilityColumns).AddRow(uuid.New(), "calloff", shiftDay, shiftDay, submitted))
+		mock.ExpectCommit()
+
+		unavailability, err := store.AcceptRequest(reqID)
+		assert.NoError(t, err)
+		assert.Equal(t, "calloff", unavailability.Reason)
+		assert.Equal(t, shiftDay, unavailability.StartDate)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Resign_NoUnavailability", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(updateQuery).WithArgs(reqID).
+			WillReturnRows(sqlmock.NewRows(requestColumns).AddRow(empID, "resign", nil, nil, submitted))
+		mock.ExpectCommit()
+
+		unavailability, err := store.AcceptRequest(reqID)
+		assert.NoError(t, err)
+		assert.Nil(t, unavailability)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(updateQuery).WithArgs(reqID).WillReturnRows(sqlmock.NewRows(requestColumns))
+		mock.ExpectRollback()
+
+		unavailability, err := store.AcceptRequest(reqID)
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.Nil(t, unavailability)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("UnavailabilityError_RollsBack", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(updateQuery).WithArgs(reqID).
+			WillReturnRows(sqlmock.NewRows(requestColumns).AddRow(empID, "holiday", start, end, submitted))
+		mock.ExpectQuery(holidayQuery).WillReturnError(fmt.Errorf("db error"))
+		mock.ExpectRollback()
+
+		unavailability, err := store.AcceptRequest(reqID)
+		assert.Error(t, err)
+		assert.Nil(t, unavailability)
+		AssertExpectations(t, mock)
+	})
+}
@@ -26,11 +26,11 @@ func TestCreateRole(t *testing.T) {
 		Independent:         func() *bool { b := false; return &b }(),
 	}
 
-	query := regexp.QuoteMeta(`INSERT INTO organizations_roles (organization_id, role, min_needed_per_shift, items_per_role_per_hour, need_for_demand, independent) VALUES ($1, $2, $3, $4, $5, $6)`)
+	query := regexp.QuoteMeta(`INSERT INTO organizations_roles (organization_id, role, min_needed_per_shift, items_per_role_per_hour, need_for_demand, independent, solo_coverage) VALUES ($1, $2, $3, $4, $5, $6, $7)`)
 
 	t.Run("Success", func(t *testing.T) {
 		mock.ExpectExec(query).
-			WithArgs(role.OrganizationID, role.Role, role.MinNeededPerShift, role.ItemsPerRolePerHour, role.NeedForDemand, role.Independent).
+			WithArgs(role.OrganizationID, role.Role, role.MinNeededPerShift, role.ItemsPerRolePerHour, role.NeedForDemand, role.Independent, role.SoloCoverage).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		err := store.CreateRole(role)
@@ -52,18 +52,20 @@ func TestGetRolesByOrganizationID(t *testing.T) {
 	store := database.NewPostgresRolesStore(db, logger)
 
 	orgID := uuid.New()
-	query := regexp.QuoteMeta(`SELECT organization_id, role, min_needed_per_shift, items_per_role_per_hour, need_for_demand, independent FROM organizations_roles WHERE organization_id = $1 ORDER BY role`)
+	query := regexp.QuoteMeta(`SELECT organization_id, role, min_needed_per_shift, items_per_role_per_hour, need_for_demand, independent, solo_coverage FROM organizations_roles WHERE organization_id = $1 AND role != 'employee' AND role != 'admin' ORDER BY role`)
 
 	t.Run("Success", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"organization_id", "role", "min_needed_per_shift", "items_per_role_per_hour", "need_for_demand", "independent"}).
-			AddRow(orgID, "Chef", 2, 5, true, false).
-			AddRow(orgID, "Server", 3, 10, true, true)
+		rows := sqlmock.NewRows([]string{"organization_id", "role", "min_needed_per_shift", "items_per_role_per_hour", "need_for_demand", "independent", "solo_coverage"}).
+			AddRow(orgID, "Chef", 2, 5, true, false, false).
+			AddRow(orgID, "Driver", 1, 10, true, true, true)
 
 		mock.ExpectQuery(query).WithArgs(orgID).WillReturnRows(rows)
 
 		roles, err := store.GetRolesByOrganizationID(orgID)
 		assert.NoError(t, err)
 		assert.Len(t, roles, 2)
+		assert.False(t, roles[0].SoloCoverage)
+		assert.True(t, roles[1].SoloCoverage)
 		AssertExpectations(t, mock)
 	})
 }
@@ -75,11 +77,11 @@ func TestGetRoleByName(t *testing.T) {
 
 	orgID := uuid.New()
 	roleName := "Chef"
-	query := regexp.QuoteMeta(`SELECT organization_id, role, min_needed_per_shift, items_per_role_per_hour, need_for_demand, independent FROM organizations_roles WHERE organization_id = $1 AND role = $2`)
+	query := regexp.QuoteMeta(`SELECT organization_id, role, min_needed_per_shift, items_per_role_per_hour, need_for_demand, independent, solo_coverage FROM organizations_roles WHERE organization_id = $1 AND role = $2`)
 
 	t.Run("Success", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"organization_id", "role", "min_needed_per_shift", "items_per_role_per_hour", "need_for_demand", "independent"}).
-			AddRow(orgID, roleName, 2, 5, true, false)
+		rows := sqlmock.NewRows([]string{"organization_id", "role", "min_needed_per_shift", "items_per_role_per_hour", "need_for_demand", "independent", "solo_coverage"}).
+			AddRow(orgID, roleName, 2, 5, true, false, false)
 
 		mock.ExpectQuery(query).WithArgs(orgID, roleName).WillReturnRows(rows)
 
@@ -110,13 +112,14 @@ func TestUpdateRole(t *testing.T) {
 		ItemsPerRolePerHour: func() *int { i := 10; return &i }(),
 		NeedForDemand:       false,
 		Independent:         func() *bool { b := true; return &b }(),
+		SoloCoverage:        true,
 	}
 
-	query := regexp.QuoteMeta(`UPDATE organizations_roles SET min_needed_per_shift = $3, items_per_role_per_hour = $4, need_for_demand = $5, independent = $6 WHERE organization_id = $1 AND role = $2`)
+	query := regexp.QuoteMeta(`UPDATE organizations_roles SET min_needed_per_shift = $3, items_per_role_per_hour = $4, need_for_demand = $5, independent = $6, solo_coverage = $7 WHERE organization_id = $1 AND role = $2`)
 
 	t.Run("Success", func(t *testing.T) {
 		mock.ExpectExec(query).
-			WithArgs(role.OrganizationID, role.Role, role.MinNeededPerShift, role.ItemsPerRolePerHour, role.NeedForDemand, role.Independent).
+			WithArgs(role.OrganizationID, role.Role, role.MinNeededPerShift, role.ItemsPerRolePerHour, role.NeedForDemand, role.Independent, role.SoloCoverage).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 
 		err := store.UpdateRole(role)
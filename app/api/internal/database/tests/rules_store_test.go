@@ -36,11 +36,11 @@ func TestCreateRules(t *testing.T) {
 		// ShiftTimes is empty, so CreateRules won't execute extra queries
 	}
 
-	query := regexp.QuoteMeta(`INSERT INTO organizations_rules (organization_id, shift_max_hours, shift_min_hours, max_weekly_hours, min_weekly_hours, fixed_shifts, number_of_shifts_per_day, meet_all_demand, min_rest_slots, slot_len_hour, min_shift_length_slots, receiving_phone, delivery, waiting_time, accepting_orders) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`)
+	query := regexp.QuoteMeta(`INSERT INTO organizations_rules (organization_id, shift_max_hours, shift_min_hours, max_weekly_hours, min_weekly_hours, fixed_shifts, number_of_shifts_per_day, meet_all_demand, min_rest_slots, slot_len_hour, min_shift_length_slots, receiving_phone, delivery, waiting_time, accepting_orders, weekly_labor_budget, prep_buffer_minutes, delivery_minutes, wait_time_factor, standby_pay_percent, cancellation_notice_hours, predictability_notice_days, predictability_pay_hours, predictability_lost_hours_percent, auto_close_orders, orders_per_staff_hour, auto_close_load_percent, auto_reopen_load_percent, delivery_sla_minutes, sla_breach_alert_percent) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30)`)
 
 	t.Run("Success", func(t *testing.T) {
 		mock.ExpectExec(query).
-			WithArgs(rules.OrganizationID, rules.ShiftMaxHours, rules.ShiftMinHours, rules.MaxWeeklyHours, rules.MinWeeklyHours, rules.FixedShifts, rules.NumberOfShiftsPerDay, rules.MeetAllDemand, rules.MinRestSlots, rules.SlotLenHour, rules.MinShiftLengthSlots, rules.ReceivingPhone, rules.Delivery, rules.WaitingTime, rules.AcceptingOrders).
+			WithArgs(rules.OrganizationID, rules.ShiftMaxHours, rules.ShiftMinHours, rules.MaxWeeklyHours, rules.MinWeeklyHours, rules.FixedShifts, rules.NumberOfShiftsPerDay, rules.MeetAllDemand, rules.MinRestSlots, rules.SlotLenHour, rules.MinShiftLengthSlots, rules.ReceivingPhone, rules.Delivery, rules.WaitingTime, rules.AcceptingOrders, rules.WeeklyLaborBudget, rules.PrepBufferMinutes, rules.DeliveryMinutes, rules.WaitTimeFactor, rules.StandbyPayPercent, rules.CancellationNoticeHours, rules.PredictabilityNoticeDays, rules.PredictabilityPayHours, rules.PredictabilityLostHoursPercent, rules.AutoCloseOrders, rules.OrdersPerStaffHour, rules.AutoCloseLoadPercent, rules.AutoReopenLoadPercent, rules.DeliverySLAMinutes, rules.SLABreachAlertPercent).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		err := store.CreateRules(rules)
@@ -64,13 +64,13 @@ func TestGetRulesByOrganizationID(t *testing.T) {
 	orgID := uuid.New()
 
 	// Queries
-	qRules := regexp.QuoteMeta(`SELECT organization_id, shift_max_hours, shift_min_hours, max_weekly_hours, min_weekly_hours, fixed_shifts, number_of_shifts_per_day, meet_all_demand, min_rest_slots, slot_len_hour, min_shift_length_slots, receiving_phone, delivery, waiting_time, accepting_orders FROM organizations_rules WHERE organization_id = $1`)
+	qRules := regexp.QuoteMeta(`SELECT organization_id, shift_max_hours, shift_min_hours, max_weekly_hours, min_weekly_hours, fixed_shifts, number_of_shifts_per_day, meet_all_demand, min_rest_slots, slot_len_hour, min_shift_length_slots, receiving_phone, delivery, waiting_time, accepting_orders, weekly_labor_budget, prep_buffer_minutes, delivery_minutes, wait_time_factor, standby_pay_percent, cancellation_notice_hours, predictability_notice_days, predictability_pay_hours, predictability_lost_hours_percent, auto_close_orders, orders_per_staff_hour, auto_close_load_percent, auto_reopen_load_percent, orders_auto_closed_at, delivery_sla_minutes, sla_breach_alert_percent FROM organizations_rules WHERE organization_id = $1`)
 	qShiftTimes := regexp.QuoteMeta(`SELECT start_time, end_time FROM organization_shift_times WHERE organization_id = $1 ORDER BY start_time`)
 
 	t.Run("Success", func(t *testing.T) {
 		// Mock Rules Query (FixedShifts = true)
-		rows := sqlmock.NewRows([]string{"organization_id", "shift_max_hours", "shift_min_hours", "max_weekly_hours", "min_weekly_hours", "fixed_shifts", "number_of_shifts_per_day", "meet_all_demand", "min_rest_slots", "slot_len_hour", "min_shift_length_slots", "receiving_phone", "delivery", "waiting_time", "accepting_orders"}).
-			AddRow(orgID, 8, 4, 40, 20, true, 3, true, 2, 1.0, 4, true, false, 15, true)
+		rows := sqlmock.NewRows([]string{"organization_id", "shift_max_hours", "shift_min_hours", "max_weekly_hours", "min_weekly_hours", "fixed_shifts", "number_of_shifts_per_day", "meet_all_demand", "min_rest_slots", "slot_len_hour", "min_shift_length_slots", "receiving_phone", "delivery", "waiting_time", "accepting_orders", "weekly_labor_budget", "prep_buffer_minutes", "delivery_minutes", "wait_time_factor", "standby_pay_percent", "cancellation_notice_hours", "predictability_notice_days", "predictability_pay_hours", "predictability_lost_hours_percent", "auto_close_orders", "orders_per_staff_hour", "auto_close_load_percent", "auto_reopen_load_percent", "orders_auto_closed_at", "delivery_sla_minutes", "sla_breach_alert_percent"}).
+			AddRow(orgID, 8, 4, 40, 20, true, 3, true, 2, 1.0, 4, true, false, 15, true, 4500.0, 5, 25, 1.0, 25, 24, 14, 1.0, 50, true, 12, 150, 100, nil, 30, 15)
 		mock.ExpectQuery(qRules).WithArgs(orgID).WillReturnRows(rows)
 
 		// Mock Shift Times Query (Required because FixedShifts is true)
@@ -82,6 +82,14 @@ func TestGetRulesByOrganizationID(t *testing.T) {
 		assert.Equal(t, orgID, rules.OrganizationID)
 		assert.Equal(t, 8, rules.ShiftMaxHours)
 		assert.True(t, rules.FixedShifts)
+		assert.Equal(t, 4500.0, *rules.WeeklyLaborBudget)
+		assert.Equal(t, 14, *rules.PredictabilityNoticeDays)
+		assert.Equal(t, 50, rules.PredictabilityLostHoursPercent)
+		assert.True(t, rules.AutoCloseOrders)
+		assert.Equal(t, 12, rules.OrdersPerStaffHour)
+		assert.Nil(t, rules.OrdersAutoClosedAt)
+		assert.Equal(t, 30, rules.DeliverySLAMinutes)
+		assert.Equal(t, 15, rules.SLABreachAlertPercent)
 		AssertExpectations(t, mock)
 	})
 
@@ -113,11 +121,11 @@ func TestUpdateRules(t *testing.T) {
 		MinShiftLengthSlots:  8,
 	}
 
-	query := regexp.QuoteMeta(`UPDATE organizations_rules SET shift_max_hours = $2, shift_min_hours = $3, max_weekly_hours = $4, min_weekly_hours = $5, fixed_shifts = $6, number_of_shifts_per_day = $7, meet_all_demand = $8, min_rest_slots = $9, slot_len_hour = $10, min_shift_length_slots = $11, receiving_phone = $12, delivery = $13, waiting_time = $14, accepting_orders = $15 WHERE organization_id = $1`)
+	query := regexp.QuoteMeta(`UPDATE organizations_rules SET shift_max_hours = $2, shift_min_hours = $3, max_weekly_hours = $4, min_weekly_hours = $5, fixed_shifts = $6, number_of_shifts_per_day = $7, meet_all_demand = $8, min_rest_slots = $9, slot_len_hour = $10, min_shift_length_slots = $11, receiving_phone = $12, delivery = $13, waiting_time = $14, accepting_orders = $15, weekly_labor_budget = $16, prep_buffer_minutes = $17, delivery_minutes = $18, wait_time_factor = $19, standby_pay_percent = $20, cancellation_notice_hours = $21, predictability_notice_days = $22, predictability_pay_hours = $23, predictability_lost_hours_percent = $24, auto_close_orders = $25, orders_per_staff_hour = $26, auto_close_load_percent = $27, auto_reopen_load_percent = $28, delivery_sla_minutes = $29, sla_breach_alert_percent = $30, orders_auto_closed_at = CASE WHEN accepting_orders = $15 THEN orders_auto_closed_at END WHERE organization_id = $1`)
 
 	t.Run("Success", func(t *testing.T) {
 		mock.ExpectExec(query).
-			WithArgs(rules.OrganizationID, rules.ShiftMaxHours, rules.ShiftMinHours, rules.MaxWeeklyHours, rules.MinWeeklyHours, rules.FixedShifts, rules.NumberOfShiftsPerDay, rules.MeetAllDemand, rules.MinRestSlots, rules.SlotLenHour, rules.MinShiftLengthSlots, rules.ReceivingPhone, rules.Delivery, rules.WaitingTime, rules.AcceptingOrders).
+			WithArgs(rules.OrganizationID, rules.ShiftMaxHours, rules.ShiftMinHours, rules.MaxWeeklyHours, rules.MinWeeklyHours, rules.FixedShifts, rules.NumberOfShiftsPerDay, rules.MeetAllDemand, rules.MinRestSlots, rules.SlotLenHour, rules.MinShiftLengthSlots, rules.ReceivingPhone, rules.Delivery, rules.WaitingTime, rules.AcceptingOrders, rules.WeeklyLaborBudget, rules.PrepBufferMinutes, rules.DeliveryMinutes, rules.WaitTimeFactor, rules.StandbyPayPercent, rules.CancellationNoticeHours, rules.PredictabilityNoticeDays, rules.PredictabilityPayHours, rules.PredictabilityLostHoursPercent, rules.AutoCloseOrders, rules.OrdersPerStaffHour, rules.AutoCloseLoadPercent, rules.AutoReopenLoadPercent, rules.DeliverySLAMinutes, rules.SLABreachAlertPercent).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 
 		err := store.UpdateRules(rules)
@@ -158,7 +166,7 @@ func TestUpsertRules(t *testing.T) {
 		// Empty ShiftTimes
 	}
 
-	queryUpsert := regexp.QuoteMeta(`INSERT INTO organizations_rules (organization_id, shift_max_hours, shift_min_hours, max_weekly_hours, min_weekly_hours, fixed_shifts, number_of_shifts_per_day, meet_all_demand, min_rest_slots, slot_len_hour, min_shift_length_slots, receiving_phone, delivery, waiting_time, accepting_orders) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) ON CONFLICT (organization_id) DO UPDATE SET shift_max_hours = EXCLUDED.shift_max_hours, shift_min_hours = EXCLUDED.shift_min_hours, max_weekly_hours = EXCLUDED.max_weekly_hours, min_weekly_hours = EXCLUDED.min_weekly_hours, fixed_shifts = EXCLUDED.fixed_shifts, number_of_shifts_per_day = EXCLUDED.number_of_shifts_per_day, meet_all_demand = EXCLUDED.meet_all_demand, min_rest_slots = EXCLUDED.min_rest_slots, slot_len_hour = EXCLUDED.slot_len_hour, min_shift_length_slots = EXCLUDED.min_shift_length_slots, receiving_phone = EXCLUDED.receiving_phone, delivery = EXCLUDED.delivery, waiting_time = EXCLUDED.waiting_time, accepting_orders = EXCLUDED.accepting_orders`)
+	queryUpsert := regexp.QuoteMeta(`INSERT INTO organizations_rules (organization_id, shift_max_hours, shift_min_hours, max_weekly_hours, min_weekly_hours, fixed_shifts, number_of_shifts_per_day, meet_all_demand, min_rest_slots, slot_len_hour, min_shift_length_slots, receiving_phone, delivery, waiting_time, accepting_orders, weekly_labor_budget, prep_buffer_minutes, delivery_minutes, wait_time_factor, standby_pay_percent, cancellation_notice_hours, predictability_notice_days, predictability_pay_hours, predictability_lost_hours_percent, auto_close_orders, orders_per_staff_hour, auto_close_load_percent, auto_reopen_load_percent, delivery_sla_minutes, sla_breach_alert_percent) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30) ON CONFLICT (organization_id) DO UPDATE SET shift_max_hours = EXCLUDED.shift_max_hours, shift_min_hours = EXCLUDED.shift_min_hours, max_weekly_hours = EXCLUDED.max_weekly_hours, min_weekly_hours = EXCLUDED.min_weekly_hours, fixed_shifts = EXCLUDED.fixed_shifts, number_of_shifts_per_day = EXCLUDED.number_of_shifts_per_day, meet_all_demand = EXCLUDED.meet_all_demand, min_rest_slots = EXCLUDED.min_rest_slots, slot_len_hour = EXCLUDED.slot_len_hour, min_shift_length_slots = EXCLUDED.min_shift_length_slots, receiving_phone = EXCLUDED.receiving_phone, delivery = EXCLUDED.delivery, waiting_time = EXCLUDED.waiting_time, accepting_orders = EXCLUDED.accepting_orders, weekly_labor_budget = EXCLUDED.weekly_labor_budget, prep_buffer_minutes = EXCLUDED.prep_buffer_minutes, delivery_minutes = EXCLUDED.delivery_minutes, wait_time_factor = EXCLUDED.wait_time_factor, standby_pay_percent = EXCLUDED.standby_pay_percent, cancellation_notice_hours = EXCLUDED.cancellation_notice_hours, predictability_notice_days = EXCLUDED.predictability_notice_days, predictability_pay_hours = EXCLUDED.predictability_pay_hours, predictability_lost_hours_percent = EXCLUDED.predictability_lost_hours_percent, auto_close_orders = EXCLUDED.auto_close_orders, orders_per_staff_hour = EXCLUDED.orders_per_staff_hour, auto_close_load_percent = EXCLUDED.auto_close_load_percent, auto_reopen_load_percent = EXCLUDED.auto_reopen_load_percent, delivery_sla_minutes = EXCLUDED.delivery_sla_minutes, sla_breach_alert_percent = EXCLUDED.sla_breach_alert_percent, orders_auto_closed_at = CASE WHEN organizations_rules.accepting_orders = EXCLUDED.accepting_orders THEN organizations_rules.orders_auto_closed_at END`)
 
 	// Since FixedShifts is true, setShiftTimes calls deleteShiftTimes
 	queryDeleteShiftTimes := regexp.QuoteMeta(`DELETE FROM organization_shift_times WHERE organization_id = $1`)
@@ -166,7 +174,7 @@ func TestUpsertRules(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		// 1. Upsert Rules
 		mock.ExpectExec(queryUpsert).
-			WithArgs(rules.OrganizationID, rules.ShiftMaxHours, rules.ShiftMinHours, rules.MaxWeeklyHours, rules.MinWeeklyHours, rules.FixedShifts, rules.NumberOfShiftsPerDay, rules.MeetAllDemand, rules.MinRestSlots, rules.SlotLenHour, rules.MinShiftLengthSlots, rules.ReceivingPhone, rules.Delivery, rules.WaitingTime, rules.AcceptingOrders).
+			WithArgs(rules.OrganizationID, rules.ShiftMaxHours, rules.ShiftMinHours, rules.MaxWeeklyHours, rules.MinWeeklyHours, rules.FixedShifts, rules.NumberOfShiftsPerDay, rules.MeetAllDemand, rules.MinRestSlots, rules.SlotLenHour, rules.MinShiftLengthSlots, rules.ReceivingPhone, rules.Delivery, rules.WaitingTime, rules.AcceptingOrders, rules.WeeklyLaborBudget, rules.PrepBufferMinutes, rules.DeliveryMinutes, rules.WaitTimeFactor, rules.StandbyPayPercent, rules.CancellationNoticeHours, rules.PredictabilityNoticeDays, rules.PredictabilityPayHours, rules.PredictabilityLostHoursPercent, rules.AutoCloseOrders, rules.OrdersPerStaffHour, rules.AutoCloseLoadPercent, rules.AutoReopenLoadPercent, rules.DeliverySLAMinutes, rules.SLABreachAlertPercent).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		// 2. Delete existing shift times (triggered by setShiftTimes)
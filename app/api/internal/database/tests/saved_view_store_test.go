@@ -0,0 +1,156 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var savedViewColumns = []string{"id", "created_by", "name", "resource", "filters", "shared", "created_at", "updated_at"}
+
+func TestListViews(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresSavedViewStore(db, logger)
+
+	orgID, userID := uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`WHERE organization_id = $1 AND (shared OR created_by = $2) AND ($3 = '' OR resource = $3)`)
+
+	t.Run("Success", func(t *testing.T) {
+		now := time.Now()
+		rows := sqlmock.NewRows(savedViewColumns).
+			AddRow(uuid.New(), userID, "Vegan items", "items", []byte(`{"cf.vegan": "true"}`), false, now, now)
+		mock.ExpectQuery(query).WithArgs(orgID, userID, "items").WillReturnRows(rows)
+
+		views, err := store.ListViews(orgID, userID, "items")
+		assert.NoError(t, err)
+		if assert.Len(t, views, 1) {
+			assert.Equal(t, map[string]string{"cf.vegan": "true"}, views[0].Filters)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, userID, "").WillReturnError(fmt.Errorf("db error"))
+
+		_, err := store.ListViews(orgID, userID, "")
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetView(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresSavedViewStore(db, logger)
+
+	orgID, viewID := uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`FROM saved_views WHERE organization_id = $1 AND id = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(query).WithArgs(orgID, viewID).
+			WillReturnRows(sqlmock.NewRows(savedViewColumns).AddRow(viewID, uuid.New(), "Late", "deliveries", []byte(`{}`), true, now, now))
+
+		view, err := store.GetView(orgID, viewID)
+		assert.NoError(t, err)
+		assert.True(t, view.Shared)
+		assert.Equal(t, map[string]string{}, view.Filters)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, viewID).WillReturnError(sql.ErrNoRows)
+
+		_, err := store.GetView(orgID, viewID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestCreateView(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresSavedViewStore(db, logger)
+
+	orgID, userID := uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`INSERT INTO saved_views (organization_id, created_by, name, resource, filters, shared)`)
+
+	t.Run("Success", func(t *testing.T) {
+		view := &database.SavedView{CreatedBy: userID, Name: "Table 12", Resource: "orders", Filters: map[string]string{"cf.table": "12"}, Shared: true}
+		viewID, now := uuid.New(), time.Now()
+		mock.ExpectQuery(query).WithArgs(orgID, userID, "Table 12", "orders", []byte(`{"cf.table":"12"}`), true).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(viewID, now, now))
+
+		assert.NoError(t, store.CreateView(orgID, view))
+		assert.Equal(t, viewID, view.ID)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NameTaken", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(&pq.Error{Code: "23505"})
+
+		err := store.CreateView(orgID, &database.SavedView{CreatedBy: userID, Name: "Table 12", Resource: "orders"})
+		assert.ErrorIs(t, err, database.ErrSavedViewExists)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestUpdateView(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresSavedViewStore(db, logger)
+
+	orgID, viewID := uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`UPDATE saved_views SET name = $3, filters = $4, shared = $5, updated_at = NOW()`)
+
+	t.Run("Success", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(query).WithArgs(orgID, viewID, "Late", []byte(`{"status":"late"}`), false).
+			WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(now))
+
+		view := &database.SavedView{ID: viewID, Name: "Late", Filters: map[string]string{"status": "late"}}
+		assert.NoError(t, store.UpdateView(orgID, view))
+		assert.Equal(t, now, view.UpdatedAt)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(sql.ErrNoRows)
+
+		err := store.UpdateView(orgID, &database.SavedView{ID: viewID, Name: "Late"})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestDeleteView(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresSavedViewStore(db, logger)
+
+	orgID, viewID := uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`DELETE FROM saved_views WHERE organization_id = $1 AND id = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID, viewID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.DeleteView(orgID, viewID))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID, viewID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, store.DeleteView(orgID, viewID), sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
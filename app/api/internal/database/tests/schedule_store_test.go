@@ -16,13 +16,14 @@ import (
 func TestStoreScheduleForUser(t *testing.T) {
 	db, mock := NewTestDB(t)
 	logger := NewTestLogger()
-	store := database.NewPostgresScheduleStore(db, logger)
+	store := database.NewPostgresScheduleStore(nil, db, logger)
 
 	orgID := uuid.New()
 	userID := uuid.New()
+	actorID := uuid.New()
 	scheduleDate := time.Date(2024, 6, 17, 0, 0, 0, 0, time.UTC)
-	startTime := time.Date(0, 1, 1, 9, 0, 0, 0, time.UTC)
-	endTime := time.Date(0, 1, 1, 17, 0, 0, 0, time.UTC)
+	startTime := "09:00:00"
+	endTime := "17:00:00"
 
 	schedule := &database.Schedule{
 		Date:      scheduleDate,
@@ -32,15 +33,16 @@ func TestStoreScheduleForUser(t *testing.T) {
 	}
 
 	checkQuery := regexp.QuoteMeta(`SELECT EXISTS(SELECT 1 FROM users WHERE id = $1 AND organization_id = $2)`)
-	insertQuery := regexp.QuoteMeta(`INSERT INTO schedules (schedule_date, day, start_hour, end_hour, employee_id) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (schedule_date, start_hour, end_hour, employee_id) DO NOTHING`)
+	insertQuery := regexp.QuoteMeta(`INSERT INTO schedules (schedule_date, day, start_hour, end_hour, employee_id) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (schedule_date, start_hour, end_hour, employee_id) DO NOTHING`) +
+		`.*` + regexp.QuoteMeta(`INSERT INTO schedule_events`) + `.*` + regexp.QuoteMeta(`'generated'`)
 
 	t.Run("Success", func(t *testing.T) {
 		mock.ExpectQuery(checkQuery).WithArgs(userID, orgID).WillReturnRows(NewRow(true))
 		mock.ExpectExec(insertQuery).
-			WithArgs(schedule.Date, schedule.Day, schedule.StartTime, schedule.EndTime, userID).
+			WithArgs(schedule.Date, schedule.Day, schedule.StartTime, schedule.EndTime, userID, orgID, actorID).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		err := store.StoreScheduleForUser(orgID, userID, schedule)
+		err := store.StoreScheduleForUser(orgID, userID, schedule, actorID)
 		assert.NoError(t, err)
 		AssertExpectations(t, mock)
 	})
@@ -48,7 +50,7 @@ func TestStoreScheduleForUser(t *testing.T) {
 	t.Run("UserNotInOrganization", func(t *testing.T) {
 		mock.ExpectQuery(checkQuery).WithArgs(userID, orgID).WillReturnRows(NewRow(false))
 
-		err := store.StoreScheduleForUser(orgID, userID, schedule)
+		err := store.StoreScheduleForUser(orgID, userID, schedule, actorID)
 		assert.Error(t, err)
 		assert.Equal(t, sql.ErrNoRows, err)
 		AssertExpectations(t, mock)
@@ -57,7 +59,7 @@ func TestStoreScheduleForUser(t *testing.T) {
 	t.Run("VerifyError", func(t *testing.T) {
 		mock.ExpectQuery(checkQuery).WithArgs(userID, orgID).WillReturnError(fmt.Errorf("db error"))
 
-		err := store.StoreScheduleForUser(orgID, userID, schedule)
+		err := store.StoreScheduleForUser(orgID, userID, schedule, actorID)
 		assert.Error(t, err)
 		AssertExpectations(t, mock)
 	})
@@ -66,7 +68,7 @@ func TestStoreScheduleForUser(t *testing.T) {
 		mock.ExpectQuery(checkQuery).WithArgs(userID, orgID).WillReturnRows(NewRow(true))
 		mock.ExpectExec(insertQuery).WillReturnError(fmt.Errorf("insert failed"))
 
-		err := store.StoreScheduleForUser(orgID, userID, schedule)
+		err := store.StoreScheduleForUser(orgID, userID, schedule, actorID)
 		assert.Error(t, err)
 		AssertExpectations(t, mock)
 	})
@@ -79,7 +81,7 @@ func TestStoreScheduleForUser(t *testing.T) {
 func TestGetScheduleForEmployeeForSevenDays(t *testing.T) {
 	db, mock := NewTestDB(t)
 	logger := NewTestLogger()
-	store := database.NewPostgresScheduleStore(db, logger)
+	store := database.NewPostgresScheduleStore(nil, db, logger)
 
 	orgID := uuid.New()
 	userID := uuid.New()
@@ -88,14 +90,15 @@ func TestGetScheduleForEmployeeForSevenDays(t *testing.T) {
 	endTime := time.Date(0, 1, 1, 17, 0, 0, 0, time.UTC)
 
 	checkQuery := regexp.QuoteMeta(`SELECT EXISTS(SELECT 1 FROM users WHERE id = $1 AND organization_id = $2)`)
-	scheduleQuery := regexp.QuoteMeta(`SELECT schedule_date, day, start_hour, end_hour, employee_id FROM schedules WHERE employee_id = $1 AND schedule_date >= CURRENT_DATE AND schedule_date < CURRENT_DATE + INTERVAL '7 days' ORDER BY schedule_date, start_hour`)
+	scheduleQuery := regexp.QuoteMeta(`SELECT schedule_date, day, start_hour, end_hour, employee_id, id, shift_type FROM schedules WHERE employee_id = $1 AND schedule_date >= CURRENT_DATE AND schedule_date < CURRENT_DATE + INTERVAL '7 days' ORDER BY schedule_date, start_hour`)
 
 	t.Run("Success", func(t *testing.T) {
 		mock.ExpectQuery(checkQuery).WithArgs(userID, orgID).WillReturnRows(NewRow(true))
 
-		rows := sqlmock.NewRows([]string{"schedule_date", "day", "start_hour", "end_hour", "employee_id"}).
-			AddRow(scheduleDate, "Monday", startTime, endTime, userID).
-			AddRow(scheduleDate.Add(24*time.Hour), "Tuesday", startTime, endTime, userID)
+		shiftID := uuid.New()
+		rows := sqlmock.NewRows([]string{"schedule_date", "day", "start_hour", "end_hour", "employee_id", "id", "shift_type"}).
+			AddRow(scheduleDate, "Monday", startTime, endTime, userID, shiftID, "working").
+			AddRow(scheduleDate.Add(24*time.Hour), "Tuesday", startTime, endTime, userID, uuid.New(), "standby")
 
 		mock.ExpectQuery(scheduleQuery).WithArgs(userID).WillReturnRows(rows)
 
@@ -104,7 +107,11 @@ func TestGetScheduleForEmployeeForSevenDays(t *testing.T) {
 		assert.Len(t, schedules, 2)
 		assert.Equal(t, "Monday", schedules[0].Day)
 		assert.Equal(t, []string{userID.String()}, schedules[0].Employees)
+		assert.Equal(t, []string{shiftID.String()}, schedules[0].ShiftIDs)
+		assert.Empty(t, schedules[0].Kind)
 		assert.Equal(t, "Tuesday", schedules[1].Day)
+		assert.Equal(t, database.ShiftStandby, schedules[1].Kind)
+		assert.True(t, schedules[1].NonProductive)
 		AssertExpectations(t, mock)
 	})
 
@@ -121,7 +128,7 @@ func TestGetScheduleForEmployeeForSevenDays(t *testing.T) {
 	t.Run("EmptyResult", func(t *testing.T) {
 		mock.ExpectQuery(checkQuery).WithArgs(userID, orgID).WillReturnRows(NewRow(true))
 
-		rows := sqlmock.NewRows([]string{"schedule_date", "day", "start_hour", "end_hour", "employee_id"})
+		rows := sqlmock.NewRows([]string{"schedule_date", "day", "start_hour", "end_hour", "employee_id", "id", "shift_type"})
 		mock.ExpectQuery(scheduleQuery).WithArgs(userID).WillReturnRows(rows)
 
 		schedules, err := store.GetScheduleForEmployeeForSevenDays(orgID, userID)
@@ -140,3 +147,706 @@ func TestGetScheduleForEmployeeForSevenDays(t *testing.T) {
 		AssertExpectations(t, mock)
 	})
 }
+
+func TestAcknowledgeShifts(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresScheduleStore(nil, db, logger)
+
+	orgID := uuid.New()
+	userID := uuid.New()
+	scheduleDate := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	checkQuery := regexp.QuoteMeta(`SELECT EXISTS(SELECT 1 FROM users WHERE id = $1 AND organization_id = $2)`)
+	allQuery := regexp.QuoteMeta(`UPDATE schedules SET acknowledged_at = NOW() WHERE employee_id = $1 AND acknowledged_at IS NULL AND schedule_date >= CURRENT_DATE`)
+	shiftQuery := regexp.QuoteMeta(`UPDATE schedules SET acknowledged_at = NOW() WHERE employee_id = $1 AND schedule_date = $2 AND start_hour = $3 AND end_hour = $4 AND acknowledged_at IS NULL`)
+
+	t.Run("Success_AllUpcoming", func(t *testing.T) {
+		mock.ExpectQuery(checkQuery).WithArgs(userID, orgID).WillReturnRows(NewRow(true))
+		mock.ExpectExec(allQuery).WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 4))
+
+		acknowledged, err := store.AcknowledgeShifts(orgID, userID, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), acknowledged)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Success_SelectedShifts", func(t *testing.T) {
+		shifts := []database.ShiftKey{
+			{Date: scheduleDate, StartTime: "09:00:00", EndTime: "13:00:00"},
+			{Date: scheduleDate, StartTime: "17:00:00", EndTime: "21:00:00"},
+		}
+		mock.ExpectQuery(checkQuery).WithArgs(userID, orgID).WillReturnRows(NewRow(true))
+		mock.ExpectBegin()
+		mock.ExpectExec(shiftQuery).WithArgs(userID, scheduleDate, "09:00:00", "13:00:00").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(shiftQuery).WithArgs(userID, scheduleDate, "17:00:00", "21:00:00").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		acknowledged, err := store.AcknowledgeShifts(orgID, userID, shifts)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), acknowledged)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("UpdateError_RollsBack", func(t *testing.T) {
+		shifts := []database.ShiftKey{{Date: scheduleDate, StartTime: "09:00:00", EndTime: "13:00:00"}}
+		mock.ExpectQuery(checkQuery).WithArgs(userID, orgID).WillReturnRows(NewRow(true))
+		mock.ExpectBegin()
+		mock.ExpectExec(shiftQuery).WillReturnError(fmt.Errorf("update failed"))
+		mock.ExpectRollback()
+
+		_, err := store.AcknowledgeShifts(orgID, userID, shifts)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("UserNotInOrganization", func(t *testing.T) {
+		mock.ExpectQuery(checkQuery).WithArgs(userID, orgID).WillReturnRows(NewRow(false))
+
+		_, err := store.AcknowledgeShifts(orgID, userID, nil)
+		assert.Equal(t, sql.ErrNoRows, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetShiftAcknowledgments(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresScheduleStore(nil, db, logger)
+
+	orgID := uuid.New()
+	userID := uuid.New()
+	scheduleDate := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	publishedAt := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	acknowledgedAt := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	columns := []string{"organization_id", "employee_id", "full_name", "email", "schedule_date", "day", "start_hour", "end_hour", "published_at", "acknowledged_at", "last_reminded_at"}
+
+	query := regexp.QuoteMeta(`FROM schedules s INNER JOIN users u ON s.employee_id = u.id WHERE u.organization_id = $1`)
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows(columns).
+			AddRow(orgID, userID, "Sam", "sam@example.com", scheduleDate, "monday", "09:00:00", "13:00:00", publishedAt, acknowledgedAt, nil).
+			AddRow(orgID, userID, "Sam", "sam@example.com", scheduleDate, "monday", "17:00:00", "21:00:00", publishedAt, nil, nil)
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnRows(rows)
+
+		acknowledgments, err := store.GetShiftAcknowledgments(orgID, database.ShiftFilter{})
+		assert.NoError(t, err)
+		assert.Len(t, acknowledgments, 2)
+		assert.Equal(t, "Sam", acknowledgments[0].EmployeeName)
+		assert.Equal(t, acknowledgedAt, *acknowledgments[0].AcknowledgedAt)
+		assert.Nil(t, acknowledgments[1].AcknowledgedAt)
+		assert.Nil(t, acknowledgments[1].LastRemindedAt)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Filtered", func(t *testing.T) {
+		acknowledged := false
+		filtered := regexp.QuoteMeta(`WHERE u.organization_id = $1 AND s.employee_id IN ($2) AND EXISTS (`) + `.*` +
+			regexp.QuoteMeta(`ur.organization_id = $3 AND ur.user_role IN ($4, $5)`) + `.*` +
+			regexp.QuoteMeta(`AND s.acknowledged_at IS NULL AND s.schedule_date >= CURRENT_DATE`)
+		rows := sqlmock.NewRows(columns).
+			AddRow(orgID, userID, "Sam", "sam@example.com", scheduleDate, "monday", "17:00:00", "21:00:00", publishedAt, nil, nil)
+		mock.ExpectQuery(filtered).WithArgs(orgID, userID, orgID, "driver", "cashier").WillReturnRows(rows)
+
+		acknowledgments, err := store.GetShiftAcknowledgments(orgID, database.ShiftFilter{
+			EmployeeIDs:  []uuid.UUID{userID},
+			Roles:        []string{"driver", "cashier"},
+			Acknowledged: &acknowledged,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, acknowledgments, 1)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnError(fmt.Errorf("db error"))
+
+		acknowledgments, err := store.GetShiftAcknowledgments(orgID, database.ShiftFilter{})
+		assert.Error(t, err)
+		assert.Nil(t, acknowledgments)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestShiftReminders(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresScheduleStore(nil, db, logger)
+
+	orgID := uuid.New()
+	userID := uuid.New()
+	cutoff := time.Date(2026, 10, 14, 8, 0, 0, 0, time.UTC)
+	columns := []string{"organization_id", "employee_id", "full_name", "email", "schedule_date", "day", "start_hour", "end_hour", "published_at", "acknowledged_at", "last_reminded_at"}
+
+	pendingQuery := regexp.QuoteMeta(`WHERE s.acknowledged_at IS NULL AND s.schedule_date >= CURRENT_DATE AND s.published_at <= $1 AND (s.last_reminded_at IS NULL OR s.last_reminded_at <= $1)`)
+	markQuery := regexp.QuoteMeta(`UPDATE schedules SET last_reminded_at = NOW() WHERE employee_id = $1 AND acknowledged_at IS NULL AND schedule_date >= CURRENT_DATE`)
+
+	t.Run("PendingReminder", func(t *testing.T) {
+		rows := sqlmock.NewRows(columns).
+			AddRow(orgID, userID, "Sam", "sam@example.com", cutoff.AddDate(0, 0, 5), "monday", "09:00:00", "13:00:00", cutoff.Add(-time.Hour), nil, nil)
+		mock.ExpectQuery(pendingQuery).WithArgs(cutoff).WillReturnRows(rows)
+
+		shifts, err := store.GetShiftsPendingReminder(cutoff)
+		assert.NoError(t, err)
+		assert.Len(t, shifts, 1)
+		assert.Equal(t, "sam@example.com", shifts[0].EmployeeEmail)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("MarkRemindersSent", func(t *testing.T) {
+		mock.ExpectExec(markQuery).WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.MarkShiftRemindersSent(userID)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("MarkRemindersSent_DBError", func(t *testing.T) {
+		mock.ExpectExec(markQuery).WithArgs(userID).WillReturnError(fmt.Errorf("db error"))
+
+		err := store.MarkShiftRemindersSent(userID)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestWeeklyScheduleEmails(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresScheduleStore(nil, db, logger)
+
+	orgID := uuid.New()
+	userID := uuid.New()
+	weekStart := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	columns := []string{"id", "employee_id", "full_name", "email", "organization_id", "schedule_date", "day", "start_hour", "end_hour", "shift_type", "hours", "standby_pay_percent"}
+
+	shiftsQuery := regexp.QuoteMeta(`WHERE s.schedule_date >= $1 AND s.schedule_date < $1::DATE + 7 AND NOT EXISTS ( SELECT 1 FROM weekly_schedule_emails w WHERE w.user_id = s.employee_id AND w.week_start = $1 )`)
+	markQuery := regexp.QuoteMeta(`INSERT INTO weekly_schedule_emails (user_id, week_start) VALUES ($1, $2) ON CONFLICT DO NOTHING`)
+
+	t.Run("ShiftsForWeeklyEmail", func(t *testing.T) {
+		rows := sqlmock.NewRows(columns).
+			AddRow(uuid.New(), userID, "Sam", "sam@example.com", orgID, weekStart, "monday", "09:00:00", "13:00:00", "working", 4.0, 25).
+			AddRow(uuid.New(), userID, "Sam", "sam@example.com", orgID, weekStart.AddDate(0, 0, 1), "tuesday", "18:00:00", "22:00:00", "standby", 4.0, 25)
+		mock.ExpectQuery(shiftsQuery).WithArgs(weekStart).WillReturnRows(rows)
+
+		shifts, err := store.GetShiftsForWeeklyEmail(weekStart)
+		assert.NoError(t, err)
+		assert.Len(t, shifts, 2)
+		assert.Equal(t, orgID, shifts[0].OrganizationID)
+		assert.Equal(t, "sam@example.com", shifts[0].EmployeeEmail)
+		assert.Equal(t, 4.0, shifts[1].Hours)
+		assert.Equal(t, database.ShiftStandby, shifts[1].ShiftType)
+		assert.Equal(t, 25, shifts[1].StandbyPayPercent)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("ShiftsForWeeklyEmail_DBError", func(t *testing.T) {
+		mock.ExpectQuery(shiftsQuery).WithArgs(weekStart).WillReturnError(fmt.Errorf("db error"))
+
+		shifts, err := store.GetShiftsForWeeklyEmail(weekStart)
+		assert.Error(t, err)
+		assert.Nil(t, shifts)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("MarkWeeklyEmailSent", func(t *testing.T) {
+		mock.ExpectExec(markQuery).WithArgs(userID, weekStart).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.MarkWeeklyEmailSent(userID, weekStart)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestClearSchedule(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresScheduleStore(nil, db, logger)
+
+	orgID := uuid.New()
+	samID := uuid.New()
+	alexID := uuid.New()
+	actorID := uuid.New()
+	from := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)
+
+	shiftsQuery := regexp.QuoteMeta(`SELECT u.id, u.full_name, u.email, COUNT(*), COUNT(s.acknowledged_at) FROM schedules s INNER JOIN users u ON s.employee_id = u.id WHERE u.organization_id = $1 AND s.schedule_date BETWEEN $2 AND $3 GROUP BY u.id, u.full_name, u.email ORDER BY u.full_name`)
+	offersQuery := regexp.QuoteMeta(`SELECT COUNT(*) FROM over_time_offers o INNER JOIN users u ON o.employee_id = u.id WHERE u.organization_id = $1 AND o.status = 'in queue' AND o.start_time::date BETWEEN $2 AND $3`)
+	deleteShifts := regexp.QuoteMeta(`DELETE FROM schedules s USING users u WHERE s.employee_id = u.id AND u.organization_id = $1 AND s.schedule_date BETWEEN $2 AND $3`) +
+		`.*` + regexp.QuoteMeta(`INSERT INTO schedule_events`) + `.*` + regexp.QuoteMeta(`'cancelled'`)
+	deleteOffers := regexp.QuoteMeta(`DELETE FROM over_time_offers o USING users u WHERE o.employee_id = u.id AND u.organization_id = $1 AND o.status = 'in queue' AND o.start_time::date BETWEEN $2 AND $3`)
+
+	shiftRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "full_name", "email", "count", "count"}).
+			AddRow(alexID, "Alex", "alex@example.com", 3, 1).
+			AddRow(samID, "Sam", "sam@example.com", 2, 2)
+	}
+
+	t.Run("Summary_DryRun", func(t *testing.T) {
+		mock.ExpectQuery(shiftsQuery).WithArgs(orgID, from, to).WillReturnRows(shiftRows())
+		mock.ExpectQuery(offersQuery).WithArgs(orgID, from, to).WillReturnRows(NewRow(1))
+
+		summary, err := store.GetScheduleClearSummary(orgID, from, to)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, summary.Shifts)
+		assert.Equal(t, 3, summary.AcknowledgedShifts)
+		assert.Equal(t, 1, summary.QueuedOffers)
+		assert.Len(t, summary.Employees, 2)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Summary_Empty", func(t *testing.T) {
+		mock.ExpectQuery(shiftsQuery).WithArgs(orgID, from, to).WillReturnRows(sqlmock.NewRows([]string{"id", "full_name", "email", "count", "count"}))
+		mock.ExpectQuery(offersQuery).WithArgs(orgID, from, to).WillReturnRows(NewRow(0))
+
+		summary, err := store.GetScheduleClearSummary(orgID, from, to)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, summary.Shifts)
+		assert.NotNil(t, summary.Employees)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Clear_Success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(shiftsQuery).WithArgs(orgID, from, to).WillReturnRows(shiftRows())
+		mock.ExpectQuery(offersQuery).WithArgs(orgID, from, to).WillReturnRows(NewRow(1))
+		mock.ExpectExec(deleteShifts).WithArgs(orgID, from, to, actorID).WillReturnResult(sqlmock.NewResult(0, 5))
+		mock.ExpectExec(deleteOffers).WithArgs(orgID, from, to).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		summary, err := store.ClearSchedule(orgID, from, to, actorID)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, summary.Shifts)
+		assert.Equal(t, "alex@example.com", summary.Employees[0].Email)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Clear_DeleteError_RollsBack", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(shiftsQuery).WithArgs(orgID, from, to).WillReturnRows(shiftRows())
+		mock.ExpectQuery(offersQuery).WithArgs(orgID, from, to).WillReturnRows(NewRow(0))
+		mock.ExpectExec(deleteShifts).WithArgs(orgID, from, to, actorID).WillReturnResult(sqlmock.NewResult(0, 5))
+		mock.ExpectExec(deleteOffers).WillReturnError(fmt.Errorf("delete failed"))
+		mock.ExpectRollback()
+
+		summary, err := store.ClearSchedule(orgID, from, to, actorID)
+		assert.Error(t, err)
+		assert.Nil(t, summary)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestStoreStandbyShift(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresScheduleStore(nil, db, logger)
+
+	orgID := uuid.New()
+	userID := uuid.New()
+	actorID := uuid.New()
+	shift := database.ShiftKey{Date: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), StartTime: "17:00", EndTime: "22:00"}
+
+	userQuery := regexp.QuoteMeta(`SELECT full_name, email FROM users WHERE id = $1 AND organization_id = $2`)
+	insertQuery := regexp.QuoteMeta(`INSERT INTO schedules (schedule_date, day, start_hour, end_hour, employee_id, shift_type) SELECT $1, $2, $3, $4, $5, 'standby' WHERE NOT EXISTS`) +
+		`.*` + regexp.QuoteMeta(`INSERT INTO schedule_events`) + `.*` + regexp.QuoteMeta(`'created'`)
+
+	t.Run("Success", func(t *testing.T) {
+		shiftID := uuid.New()
+		mock.ExpectQuery(userQuery).WithArgs(userID, orgID).
+			WillReturnRows(sqlmock.NewRows([]string{"full_name", "email"}).AddRow("Sam", "sam@example.com"))
+		mock.ExpectQuery(insertQuery).WithArgs(shift.Date, "monday", "17:00", "22:00", userID, orgID, actorID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(shiftID))
+
+		standby, err := store.StoreStandbyShift(orgID, userID, shift, actorID)
+		assert.NoError(t, err)
+		assert.Equal(t, shiftID, standby.ID)
+		assert.Equal(t, database.ShiftStandby, standby.ShiftType)
+		assert.Equal(t, "sam@example.com", standby.EmployeeEmail)
+		assert.Nil(t, standby.ActivatedAt)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("UserNotInOrganization", func(t *testing.T) {
+		mock.ExpectQuery(userQuery).WithArgs(userID, orgID).WillReturnError(sql.ErrNoRows)
+
+		standby, err := store.StoreStandbyShift(orgID, userID, shift, actorID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Nil(t, standby)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Overlaps", func(t *testing.T) {
+		mock.ExpectQuery(userQuery).WithArgs(userID, orgID).
+			WillReturnRows(sqlmock.NewRows([]string{"full_name", "email"}).AddRow("Sam", "sam@example.com"))
+		mock.ExpectQuery(insertQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		standby, err := store.StoreStandbyShift(orgID, userID, shift, actorID)
+		assert.ErrorIs(t, err, database.ErrShiftOverlaps)
+		assert.Nil(t, standby)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestActivateShift(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresScheduleStore(nil, db, logger)
+
+	orgID := uuid.New()
+	shiftID := uuid.New()
+	userID := uuid.New()
+	actorID := uuid.New()
+	at := time.Date(2026, 10, 19, 16, 30, 0, 0, time.UTC)
+
+	updateQuery := regexp.QuoteMeta(`UPDATE schedules s SET shift_type = 'working', activated_at = $3 FROM users u WHERE s.id = $1 AND s.employee_id = u.id AND u.organization_id = $2 AND s.shift_type = 'standby' AND (s.schedule_date + s.end_hour) > $3`) +
+		`.*` + regexp.QuoteMeta(`INSERT INTO schedule_events`) + `.*` + regexp.QuoteMeta(`'edited'`)
+	checkQuery := regexp.QuoteMeta(`SELECT s.shift_type, (s.schedule_date + s.end_hour) <= $3 FROM schedules s`)
+	columns := []string{"id", "employee_id", "full_name", "email", "schedule_date", "day", "start_hour", "end_hour", "shift_type", "activated_at"}
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(updateQuery).WithArgs(shiftID, orgID, at, actorID).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(shiftID, userID, "Sam", "sam@example.com", at, "monday", "17:00:00", "22:00:00", "working", at))
+
+		shift, err := store.ActivateShift(orgID, shiftID, at, actorID)
+		assert.NoError(t, err)
+		assert.Equal(t, database.ShiftWorking, shift.ShiftType)
+		assert.Equal(t, at, *shift.ActivatedAt)
+		assert.Equal(t, "Sam", shift.EmployeeName)
+		AssertExpectations(t, mock)
+	})
+
+	cases := map[string]struct {
+		rows *sqlmock.Rows
+		err  error
+	}{
+		"NotFound":  {rows: sqlmock.NewRows([]string{"shift_type", "ended"}), err: sql.ErrNoRows},
+		"Working":   {rows: sqlmock.NewRows([]string{"shift_type", "ended"}).AddRow("working", false), err: database.ErrShiftNotStandby},
+		"Ended":     {rows: sqlmock.NewRows([]string{"shift_type", "ended"}).AddRow("standby", true), err: database.ErrShiftEnded},
+		"Activated": {rows: sqlmock.NewRows([]string{"shift_type", "ended"}).AddRow("working", true), err: database.ErrShiftNotStandby},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mock.ExpectQuery(updateQuery).WithArgs(shiftID, orgID, at, actorID).WillReturnRows(sqlmock.NewRows(columns))
+			mock.ExpectQuery(checkQuery).WithArgs(shiftID, orgID, at).WillReturnRows(tc.rows)
+
+			shift, err := store.ActivateShift(orgID, shiftID, at, actorID)
+			assert.ErrorIs(t, err, tc.err)
+			assert.Nil(t, shift)
+			AssertExpectations(t, mock)
+		})
+	}
+}
+
+func TestGetShiftEvents(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresScheduleStore(nil, db, logger)
+
+	orgID := uuid.New()
+	shiftID := uuid.New()
+	samID := uuid.New()
+	managerID := uuid.New()
+	date := time.Date(2026, 10, 24, 0, 0, 0, 0, time.UTC)
+
+	query := regexp.QuoteMeta(`FROM schedule_events e INNER JOIN users u ON e.employee_id = u.id LEFT JOIN users a ON e.actor_id = a.id WHERE e.organization_id = $1 AND e.shift_id = $2 ORDER BY e.occurred_at, e.id`)
+	columns := []string{"id", "shift_id", "event_type", "employee_id", "full_name", "schedule_date", "start_hour", "end_hour", "shift_type", "actor_id", "full_name", "occurred_at"}
+
+	t.Run("Success", func(t *testing.T) {
+		generated := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+		rows := sqlmock.NewRows(columns).
+			AddRow(uuid.New(), shiftID, "created", samID, "Sam", date, "17:00:00", "22:00:00", "standby", nil, nil, generated).
+			AddRow(uuid.New(), shiftID, "edited", samID, "Sam", date, "17:00:00", "22:00:00", "working", managerID, "Maria", generated.Add(time.Hour))
+		mock.ExpectQuery(query).WithArgs(orgID, shiftID).WillReturnRows(rows)
+
+		events, err := store.GetShiftEvents(orgID, shiftID)
+		assert.NoError(t, err)
+		assert.Len(t, events, 2)
+		assert.Equal(t, database.ScheduleEventCreated, events[0].EventType)
+		assert.Nil(t, events[0].ActorID)
+		assert.Equal(t, database.ScheduleEventEdited, events[1].EventType)
+		assert.Equal(t, managerID, *events[1].ActorID)
+		assert.Equal(t, "Maria", *events[1].ActorName)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NoEvents", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, shiftID).WillReturnRows(sqlmock.NewRows(columns))
+
+		events, err := store.GetShiftEvents(orgID, shiftID)
+		assert.NoError(t, err)
+		assert.Empty(t, events)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, shiftID).WillReturnError(fmt.Errorf("db error"))
+
+		events, err := store.GetShiftEvents(orgID, shiftID)
+		assert.Error(t, err)
+		assert.Nil(t, events)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestCancelShift(t *testing.T) {
+	orgID := uuid.New()
+	shiftID := uuid.New()
+	userID := uuid.New()
+	actorID := uuid.New()
+	at := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	date := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	shiftQuery := regexp.QuoteMeta(`FROM schedules s INNER JOIN users u ON s.employee_id = u.id WHERE s.id = $1 AND u.organization_id = $2 FOR UPDATE OF s`)
+	deleteQuery := regexp.QuoteMeta(`DELETE FROM schedules WHERE id = $1`) + `.*` + regexp.QuoteMeta(`'cancelled'`)
+	offerQuery := regexp.QuoteMeta(`INSERT INTO over_time_offers (employee_id, status, shift_length, start_time)`)
+	insertQuery := regexp.QuoteMeta(`INSERT INTO shift_cancellations`)
+	shiftColumns := []string{"employee_id", "full_name", "email", "schedule_date", "start_hour", "end_hour", "shift_type", "notice_hours"}
+	request := func(allowLate, offer bool) database.ShiftCancellationRequest {
+		return database.ShiftCancellationRequest{Reason: "Overstaffed", At: at, RequiredNoticeHours: 24, AllowLate: allowLate, OfferShift: offer}
+	}
+
+	t.Run("OffersShift", func(t *testing.T) {
+		db, mock := NewTestDB(t)
+		store := database.NewPostgresScheduleStore(nil, db, NewTestLogger())
+		cancellationID := uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(shiftQuery).WithArgs(shiftID, orgID, at).
+			WillReturnRows(sqlmock.NewRows(shiftColumns).AddRow(userID, "Sam", "sam@example.com", date, "17:00:00", "22:00:00", "working", 53.0))
+		mock.ExpectExec(deleteQuery).WithArgs(shiftID, orgID, actorID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(offerQuery).WithArgs(orgID, userID, date, "17:00:00", "22:00:00").WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectQuery(insertQuery).
+			WithArgs(orgID, shiftID, userID, date, "17:00:00", "22:00:00", "working", "Overstaffed", actorID, 53.0, 24, false, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "cancelled_at"}).AddRow(cancellationID, at))
+		mock.ExpectCommit()
+
+		cancellation, err := store.CancelShift(orgID, shiftID, request(false, true), actorID)
+		assert.NoError(t, err)
+		assert.Equal(t, cancellationID, cancellation.ID)
+		assert.Equal(t, "sam@example.com", cancellation.EmployeeEmail)
+		assert.False(t, cancellation.Late)
+		assert.Equal(t, 3, cancellation.OffersCreated)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("LateAllowed", func(t *testing.T) {
+		db, mock := NewTestDB(t)
+		store := database.NewPostgresScheduleStore(nil, db, NewTestLogger())
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(shiftQuery).WithArgs(shiftID, orgID, at).
+			WillReturnRows(sqlmock.NewRows(shiftColumns).AddRow(userID, "Sam", "sam@example.com", date, "17:00:00", "22:00:00", "working", 5.256))
+		mock.ExpectExec(deleteQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(insertQuery).
+			WithArgs(orgID, shiftID, userID, date, "17:00:00", "22:00:00", "working", "Overstaffed", actorID, 5.26, 24, true, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "cancelled_at"}).AddRow(uuid.New(), at))
+		mock.ExpectCommit()
+
+		cancellation, err := store.CancelShift(orgID, shiftID, request(true, false), actorID)
+		assert.NoError(t, err)
+		assert.True(t, cancellation.Late)
+		assert.Equal(t, 5.26, cancellation.NoticeHours)
+		AssertExpectations(t, mock)
+	})
+
+	cases := map[string]struct {
+		rows *sqlmock.Rows
+		err  error
+	}{
+		"NotFound": {rows: sqlmock.NewRows(shiftColumns), err: sql.ErrNoRows},
+		"Started":  {rows: sqlmock.NewRows(shiftColumns).AddRow(userID, "Sam", "sam@example.com", date, "17:00:00", "22:00:00", "working", -1.0), err: database.ErrShiftStarted},
+		"Late":     {rows: sqlmock.NewRows(shiftColumns).AddRow(userID, "Sam", "sam@example.com", date, "17:00:00", "22:00:00", "working", 5.0), err: database.ErrCancellationNotice},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			db, mock := NewTestDB(t)
+			store := database.NewPostgresScheduleStore(nil, db, NewTestLogger())
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(shiftQuery).WithArgs(shiftID, orgID, at).WillReturnRows(tc.rows)
+			mock.ExpectRollback()
+
+			cancellation, err := store.CancelShift(orgID, shiftID, request(false, true), actorID)
+			assert.ErrorIs(t, err, tc.err)
+			assert.Nil(t, cancellation)
+			AssertExpectations(t, mock)
+		})
+	}
+}
+
+func TestGetShiftCancellations(t *testing.T) {
+	db, mock := NewTestDB(t)
+	store := database.NewPostgresScheduleStore(nil, db, NewTestLogger())
+
+	orgID := uuid.New()
+	managerID := uuid.New()
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	late := true
+	columns := []string{"id", "shift_id", "employee_id", "full_name", "schedule_date", "start_hour", "end_hour", "shift_type", "reason", "cancelled_by", "full_name", "notice_hours", "required_notice_hours", "late", "offers_created", "cancelled_at"}
+
+	t.Run("Filtered", func(t *testing.T) {
+		query := regexp.QuoteMeta(`WHERE c.organization_id = $1 AND c.schedule_date >= $2 AND c.schedule_date < $3 AND c.late = $4 ORDER BY c.cancelled_at DESC, c.id`)
+		mock.ExpectQuery(query).WithArgs(orgID, &from, &to, &late).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(uuid.New(), uuid.New(), uuid.New(), "Sam", from, "17:00:00", "22:00:00", "working", "Overstaffed", managerID, "Maya", 3.5, 24, true, 2, from).
+				AddRow(uuid.New(), uuid.New(), uuid.New(), "Ana", from, "09:00:00", "13:00:00", "standby", "Closed", nil, nil, 1.0, 24, true, 0, from))
+
+		cancellations, err := store.GetShiftCancellations(orgID, database.CancellationFilter{From: &from, To: &to, Late: &late})
+		assert.NoError(t, err)
+		if assert.Len(t, cancellations, 2) {
+			assert.Equal(t, "Maya", *cancellations[0].CancelledByName)
+			assert.Equal(t, 3.5, cancellations[0].NoticeHours)
+			assert.Nil(t, cancellations[1].CancelledBy)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Unfiltered", func(t *testing.T) {
+		query := regexp.QuoteMeta(`WHERE c.organization_id = $1 ORDER BY`)
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnRows(sqlmock.NewRows(columns))
+
+		cancellations, err := store.GetShiftCancellations(orgID, database.CancellationFilter{})
+		assert.NoError(t, err)
+		assert.Empty(t, cancellations)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetShiftsFrom(t *testing.T) {
+	db, mock := NewTestDB(t)
+	store := database.NewPostgresScheduleStore(nil, db, NewTestLogger())
+
+	orgID := uuid.New()
+	from := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	columns := []string{"id", "employee_id", "full_name", "email", "schedule_date", "day", "start_hour", "end_hour", "shift_type", "activated_at"}
+	query := regexp.QuoteMeta(`WHERE u.organization_id = $1 AND s.schedule_date >= $2::DATE ORDER BY s.schedule_date, s.start_hour, s.employee_id`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(uuid.New(), uuid.New(), "Sam", "sam@example.com", from, "monday", "09:00:00", "17:00:00", "working", nil).
+				AddRow(uuid.New(), uuid.New(), "Ana", "ana@example.com", from, "monday", "12:00:00", "16:00:00", "standby", nil))
+
+		shifts, err := store.GetShiftsFrom(orgID, from)
+		assert.NoError(t, err)
+		if assert.Len(t, shifts, 2) {
+			assert.Equal(t, "Sam", shifts[0].EmployeeName)
+			assert.Equal(t, "17:00:00", shifts[0].EndTime)
+			assert.Equal(t, database.ShiftStandby, shifts[1].ShiftType)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from).WillReturnError(sql.ErrConnDone)
+
+		shifts, err := store.GetShiftsFrom(orgID, from)
+		assert.Error(t, err)
+		assert.Nil(t, shifts)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetScheduledHours(t *testing.T) {
+	db, mock := NewTestDB(t)
+	store := database.NewPostgresScheduleStore(nil, db, NewTestLogger())
+
+	orgID := uuid.New()
+	from := time.Date(2026, 9, 16, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`WHERE u.organization_id = $1 AND s.schedule_date >= $2::DATE AND s.schedule_date < $3::DATE GROUP BY s.employee_id`)
+
+	t.Run("Success", func(t *testing.T) {
+		employeeID := uuid.New()
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).
+			WillReturnRows(sqlmock.NewRows([]string{"employee_id", "working", "standby", "standby_pay_percent"}).AddRow(employeeID, 32.0, 4.0, 25))
+
+		hours, err := store.GetScheduledHours(orgID, from, to)
+		assert.NoError(t, err)
+		assert.Equal(t, []database.EmployeeHours{{EmployeeID: employeeID, WorkingHours: 32, StandbyHours: 4, StandbyPayPercent: 25}}, hours)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).WillReturnError(sql.ErrConnDone)
+
+		hours, err := store.GetScheduledHours(orgID, from, to)
+		assert.Error(t, err)
+		assert.Nil(t, hours)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetScheduleChanges(t *testing.T) {
+	db, mock := NewTestDB(t)
+	store := database.NewPostgresScheduleStore(nil, db, NewTestLogger())
+
+	orgID := uuid.New()
+	from := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	columns := []string{"shift_id", "event_type", "employee_id", "full_name", "schedule_date", "start_hour", "end_hour", "shift_type", "hours", "previous_date", "previous_start", "previous_end", "previous_hours", "notice_hours", "occurred_at"}
+	query := regexp.QuoteMeta(`WHERE h.event_type <> 'generated' AND h.schedule_date >= $2::DATE AND h.schedule_date < $3::DATE ORDER BY h.occurred_at, h.shift_id`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(uuid.New(), "created", uuid.New(), "Sam", from, "17:00:00", "22:00:00", "working", 5.0, nil, nil, nil, nil, 30.123, from).
+				AddRow(uuid.New(), "edited", uuid.New(), "Ana", from, "09:00:00", "13:00:00", "working", 4.0, from, "09:00:00", "17:00:00", 8.0, 12.5, from))
+
+		changes, err := store.GetScheduleChanges(orgID, from, to)
+		assert.NoError(t, err)
+		if assert.Len(t, changes, 2) {
+			assert.Nil(t, changes[0].PreviousHours)
+			assert.Equal(t, 30.12, changes[0].NoticeHours)
+			assert.Equal(t, "17:00:00", *changes[1].PreviousEndTime)
+			assert.Equal(t, 8.0, *changes[1].PreviousHours)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).WillReturnError(sql.ErrConnDone)
+
+		changes, err := store.GetScheduleChanges(orgID, from, to)
+		assert.Error(t, err)
+		assert.Nil(t, changes)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestComplianceReports(t *testing.T) {
+	db, mock := NewTestDB(t)
+	store := database.NewPostgresScheduleStore(nil, db, NewTestLogger())
+
+	orgID := uuid.New()
+	weekStart := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	orgsQuery := regexp.QuoteMeta(`WHERE r.predictability_notice_days IS NOT NULL AND NOT EXISTS ( SELECT 1 FROM compliance_report_emails c WHERE c.organization_id = r.organization_id AND c.week_start = $1 )`)
+	markQuery := regexp.QuoteMeta(`INSERT INTO compliance_report_emails (organization_id, week_start) VALUES ($1, $2) ON CONFLICT DO NOTHING`)
+
+	t.Run("OrganizationsForComplianceReport", func(t *testing.T) {
+		mock.ExpectQuery(orgsQuery).WithArgs(weekStart).WillReturnRows(sqlmock.NewRows([]string{"organization_id"}).AddRow(orgID))
+
+		orgs, err := store.GetOrganizationsForComplianceReport(weekStart)
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{orgID}, orgs)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("OrganizationsForComplianceReport_DBError", func(t *testing.T) {
+		mock.ExpectQuery(orgsQuery).WithArgs(weekStart).WillReturnError(sql.ErrConnDone)
+
+		orgs, err := store.GetOrganizationsForComplianceReport(weekStart)
+		assert.Error(t, err)
+		assert.Nil(t, orgs)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("MarkComplianceReportSent", func(t *testing.T) {
+		mock.ExpectExec(markQuery).WithArgs(orgID, weekStart).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.MarkComplianceReportSent(orgID, weekStart)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var scheduleVersionColumns = []string{"id", "source", "generated_by", "week_start", "shifts", "preferred_shifts", "preference_satisfaction_percent", "created_at"}
+
+func TestStoreScheduleVersion(t *testing.T) {
+	orgID, userID := uuid.New(), uuid.New()
+	percent := 50.0
+	newVersion := func() *database.ScheduleVersion {
+		return &database.ScheduleVersion{
+			Source:                        "ml",
+			GeneratedBy:                   &userID,
+			WeekStart:                     time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC),
+			Shifts:                        3,
+			PreferredShifts:               1,
+			PreferenceSatisfactionPercent: &percent,
+			Employees: []database.EmployeeSatisfaction{
+				{EmployeeID: uuid.New(), Shifts: 2, PreferredShifts: 1, SatisfactionPercent: &percent},
+				{EmployeeID: uuid.New(), Shifts: 1},
+			},
+		}
+	}
+	versionQuery := regexp.QuoteMeta(`INSERT INTO schedule_versions (organization_id, source, generated_by, week_start, shifts, preferred_shifts, preference_satisfaction_percent) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`)
+	employeeQuery := regexp.QuoteMeta(`INSERT INTO schedule_version_employees (version_id, employee_id, shifts, preferred_shifts, satisfaction_percent) VALUES ($1, $2, $3, $4, $5)`)
+
+	t.Run("Success", func(t *testing.T) {
+		db, mock := NewTestDB(t)
+		store := database.NewPostgresScheduleVersionStore(db, NewTestLogger())
+		version := newVersion()
+		versionID, now := uuid.New(), time.Now()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(versionQuery).
+			WithArgs(orgID, "ml", &userID, version.WeekStart, 3, 1, &percent).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(versionID, now))
+		mock.ExpectExec(employeeQuery).WithArgs(versionID, version.Employees[0].EmployeeID, 2, 1, &percent).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(employeeQuery).WithArgs(versionID, version.Employees[1].EmployeeID, 1, 0, nil).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := store.StoreScheduleVersion(orgID, version)
+		assert.NoError(t, err)
+		assert.Equal(t, versionID, version.ID)
+		assert.Equal(t, now, version.CreatedAt)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("EmployeeErrorRollsBack", func(t *testing.T) {
+		db, mock := NewTestDB(t)
+		store := database.NewPostgresScheduleVersionStore(db, NewTestLogger())
+		version := newVersion()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(versionQuery).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(uuid.New(), time.Now()))
+		mock.ExpectExec(employeeQuery).WillReturnError(fmt.Errorf("foreign key violation"))
+		mock.ExpectRollback()
+
+		err := store.StoreScheduleVersion(orgID, version)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetScheduleVersions(t *testing.T) {
+	db, mock := NewTestDB(t)
+	store := database.NewPostgresScheduleVersionStore(db, NewTestLogger())
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`FROM schedule_versions WHERE organization_id = $1 ORDER BY created_at DESC, id LIMIT $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(query).WithArgs(orgID, 5).
+			WillReturnRows(sqlmock.NewRows(scheduleVersionColumns).
+				AddRow(uuid.New(), "ml", uuid.New(), now, 8, 6, 75.0, now).
+				AddRow(uuid.New(), "heuristic_fallback", nil, now, 4, 0, nil, now.Add(-time.Hour)))
+
+		versions, err := store.GetScheduleVersions(orgID, 5)
+		assert.NoError(t, err)
+		assert.Len(t, versions, 2)
+		assert.Equal(t, 75.0, *versions[0].PreferenceSatisfactionPercent)
+		assert.Nil(t, versions[1].PreferenceSatisfactionPercent)
+		assert.Nil(t, versions[1].GeneratedBy)
+		assert.Nil(t, versions[0].Employees)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, 5).WillReturnError(fmt.Errorf("connection reset"))
+
+		versions, err := store.GetScheduleVersions(orgID, 5)
+		assert.Error(t, err)
+		assert.Nil(t, versions)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetScheduleVersion(t *testing.T) {
+	db, mock := NewTestDB(t)
+	store := database.NewPostgresScheduleVersionStore(db, NewTestLogger())
+
+	orgID, versionID := uuid.New(), uuid.New()
+	versionQuery := regexp.QuoteMeta(`FROM schedule_versions WHERE organization_id = $1 AND id = $2`)
+	employeeQuery := regexp.QuoteMeta(`FROM schedule_version_employees e INNER JOIN users u ON e.employee_id = u.id WHERE e.version_id = $1 ORDER BY e.satisfaction_percent NULLS LAST, u.full_name`)
+
+	t.Run("Success", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(versionQuery).WithArgs(orgID, versionID).
+			WillReturnRows(sqlmock.NewRows(scheduleVersionColumns).AddRow(versionID, "ml", uuid.New(), now, 3, 1, 50.0, now))
+		mock.ExpectQuery(employeeQuery).WithArgs(versionID).
+			WillReturnRows(sqlmock.NewRows([]string{"employee_id", "full_name", "shifts", "preferred_shifts", "satisfaction_percent"}).
+				AddRow(uuid.New(), "Ana", 2, 1, 50.0).
+				AddRow(uuid.New(), "Ben", 1, 0, nil))
+
+		version, err := store.GetScheduleVersion(orgID, versionID)
+		assert.NoError(t, err)
+		if assert.Len(t, version.Employees, 2) {
+			assert.Equal(t, "Ana", version.Employees[0].EmployeeName)
+			assert.Equal(t, 50.0, *version.Employees[0].SatisfactionPercent)
+			assert.Nil(t, version.Employees[1].SatisfactionPercent)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectQuery(versionQuery).WithArgs(orgID, versionID).WillReturnError(sql.ErrNoRows)
+
+		version, err := store.GetScheduleVersion(orgID, versionID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Nil(t, version)
+		AssertExpectations(t, mock)
+	})
+}
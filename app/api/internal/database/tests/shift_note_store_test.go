@@ -0,0 +1,98 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateShiftNote(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresShiftNoteStore(db, logger)
+
+	orgID, itemID, userID, noteID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`INSERT INTO shift_notes (organization_id, kind, item_id, body, created_by) SELECT $1, $2, $3, $4, $5 WHERE $3::UUID IS NULL OR EXISTS (SELECT 1 FROM items WHERE id = $3 AND organization_id = $1)`)
+
+	t.Run("Success", func(t *testing.T) {
+		now := time.Now()
+		note := &database.ShiftNote{Kind: database.ShiftNoteLowStock, ItemID: &itemID, Body: "Two bags left", CreatedBy: &userID}
+		mock.ExpectQuery(query).WithArgs(orgID, database.ShiftNoteLowStock, &itemID, "Two bags left", &userID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(noteID, now))
+
+		assert.NoError(t, store.CreateShiftNote(orgID, note))
+		assert.Equal(t, noteID, note.ID)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("ItemOfOtherOrganization", func(t *testing.T) {
+		note := &database.ShiftNote{Kind: database.ShiftNoteLowStock, ItemID: &itemID, Body: "Two bags left", CreatedBy: &userID}
+		mock.ExpectQuery(query).WithArgs(orgID, database.ShiftNoteLowStock, &itemID, "Two bags left", &userID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}))
+
+		assert.ErrorIs(t, store.CreateShiftNote(orgID, note), sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetUnresolvedShiftNotes(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresShiftNoteStore(db, logger)
+
+	orgID, itemID := uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`FROM shift_notes n LEFT JOIN items i ON i.id = n.item_id LEFT JOIN users u ON u.id = n.created_by WHERE n.organization_id = $1 AND n.resolved_at IS NULL ORDER BY n.created_at`)
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "kind", "item_id", "name", "body", "created_by", "full_name", "created_at"}).
+			AddRow(uuid.New(), database.ShiftNoteLowStock, itemID, "Oat milk", "Two bags left", nil, nil, time.Now()).
+			AddRow(uuid.New(), database.ShiftNoteGeneral, nil, nil, "Fridge 2 is noisy", uuid.New(), "Sam", time.Now())
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnRows(rows)
+
+		notes, err := store.GetUnresolvedShiftNotes(orgID)
+		assert.NoError(t, err)
+		if assert.Len(t, notes, 2) {
+			assert.Equal(t, "Oat milk", *notes[0].ItemName)
+			assert.Nil(t, notes[1].ItemID)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnError(fmt.Errorf("db error"))
+
+		_, err := store.GetUnresolvedShiftNotes(orgID)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestResolveShiftNote(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresShiftNoteStore(db, logger)
+
+	orgID, noteID, userID := uuid.New(), uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`UPDATE shift_notes SET resolved_by = $3, resolved_at = NOW() WHERE id = $1 AND organization_id = $2 AND resolved_at IS NULL`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(noteID, orgID, userID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.ResolveShiftNote(orgID, noteID, userID))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("AlreadyResolved", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(noteID, orgID, userID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, store.ResolveShiftNote(orgID, noteID, userID), sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,235 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var employeeSkillRowColumns = []string{"id", "organization_id", "employee_id", "full_name", "email", "name", "kind", "expires_on",
+	"note", "status", "reviewed_by", "reviewed_at", "review_note", "created_at"}
+
+func TestProposeSkill(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresSkillStore(db, logger)
+
+	orgID, employeeID, skillID := uuid.New(), uuid.New(), uuid.New()
+	expires := time.Date(2027, 10, 31, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`ON CONFLICT (employee_id, (LOWER(name))) DO UPDATE`)
+
+	t.Run("BackToPending", func(t *testing.T) {
+		skill := &database.EmployeeSkill{OrganizationID: orgID, EmployeeID: employeeID, Name: "Food handler",
+			Kind: database.SkillKindCertification, ExpiresOn: &expires}
+		mock.ExpectQuery(query).WithArgs(orgID, employeeID, "Food handler", database.SkillKindCertification, &expires, (*string)(nil)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status", "created_at"}).AddRow(skillID, database.SkillPending, time.Now()))
+
+		err := store.ProposeSkill(skill)
+		assert.NoError(t, err)
+		assert.Equal(t, skillID, skill.ID)
+		assert.Equal(t, database.SkillPending, skill.Status)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("db error"))
+
+		err := store.ProposeSkill(&database.EmployeeSkill{OrganizationID: orgID, EmployeeID: employeeID, Name: "Barista"})
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetSkills(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresSkillStore(db, logger)
+
+	orgID, employeeID := uuid.New(), uuid.New()
+
+	t.Run("PendingOfEmployee", func(t *testing.T) {
+		rows := sqlmock.NewRows(employeeSkillRowColumns).AddRow(uuid.New(), orgID, employeeID, "Sam Server", "sam@example.com",
+			"Barista", database.SkillKindSkill, nil, nil, database.SkillPending, nil, nil, nil, time.Now())
+		mock.ExpectQuery(regexp.QuoteMeta(`WHERE s.organization_id = $1 AND s.employee_id = $2 AND s.status = $3 ORDER BY s.created_at DESC`)).
+			WithArgs(orgID, &employeeID, database.SkillPending).WillReturnRows(rows)
+
+		skills, err := store.GetSkills(orgID, &employeeID, database.SkillPending)
+		assert.NoError(t, err)
+		if assert.Len(t, skills, 1) {
+			assert.Equal(t, "Sam Server", skills[0].EmployeeName)
+			assert.Nil(t, skills[0].ExpiresOn)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("AllOfOrganization", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`WHERE s.organization_id = $1 ORDER BY s.created_at DESC`)).
+			WithArgs(orgID).WillReturnRows(sqlmock.NewRows(employeeSkillRowColumns))
+
+		skills, err := store.GetSkills(orgID, nil, "")
+		assert.NoError(t, err)
+		assert.Empty(t, skills)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestReviewSkill(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresSkillStore(db, logger)
+
+	orgID, skillID, managerID := uuid.New(), uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`WHERE id = $1 AND organization_id = $2 AND status = 'pending'`)
+	skill := func() *database.EmployeeSkill {
+		return &database.EmployeeSkill{ID: skillID, OrganizationID: orgID, Status: database.SkillVerified, ReviewedBy: &managerID}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		reviewed := skill()
+		now := time.Now()
+		mock.ExpectQuery(query).WithArgs(skillID, orgID, database.SkillVerified, &managerID, (*string)(nil)).
+			WillReturnRows(sqlmock.NewRows([]string{"reviewed_at"}).AddRow(now))
+
+		err := store.ReviewSkill(reviewed)
+		assert.NoError(t, err)
+		assert.Equal(t, now, *reviewed.ReviewedAt)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("AlreadyReviewed", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"reviewed_at"}))
+
+		err := store.ReviewSkill(skill())
+		assert.ErrorIs(t, err, database.ErrSkillReviewed)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestDeleteSkill(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresSkillStore(db, logger)
+
+	employeeID, skillID := uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`DELETE FROM employee_skills WHERE id = $1 AND employee_id = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(skillID, employeeID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.DeleteSkill(employeeID, skillID))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(skillID, employeeID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, store.DeleteSkill(employeeID, skillID), sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestRoleSkillRequirements(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresSkillStore(db, logger)
+
+	orgID, adminID := uuid.New(), uuid.New()
+
+	t.Run("Get", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"role", "skills"}).AddRow("bartender", "{\"Responsible alcohol service\",Mixology}")
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT role, skills FROM role_skill_requirements WHERE organization_id = $1 ORDER BY role`)).
+			WithArgs(orgID).WillReturnRows(rows)
+
+		requirements, err := store.GetRoleSkillRequirements(orgID)
+		assert.NoError(t, err)
+		assert.Equal(t, []database.RoleSkillRequirement{{Role: "bartender", Skills: []string{"Responsible alcohol service", "Mixology"}}}, requirements)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO role_skill_requirements (organization_id, role, skills, updated_by, updated_at)`)).
+			WithArgs(orgID, "bartender", pq.Array([]string{"Mixology"}), adminID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.SetRoleSkillRequirement(orgID, database.RoleSkillRequirement{Role: "bartender", Skills: []string{"Mixology"}}, adminID)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Cleared", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM role_skill_requirements WHERE organization_id = $1 AND role = $2`)).
+			WithArgs(orgID, "bartender").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.SetRoleSkillRequirement(orgID, database.RoleSkillRequirement{Role: "bartender"}, adminID)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetVerifiedSkills(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresSkillStore(db, logger)
+
+	orgID, sam, alex := uuid.New(), uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`WHERE organization_id = $1 AND status = 'verified' AND (expires_on IS NULL OR expires_on >= $2)`)
+
+	t.Run("KeyedByEmployee", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"employee_id", "name"}).
+			AddRow(sam, "Barista").AddRow(sam, "Food handler").AddRow(alex, "First aid")
+		mock.ExpectQuery(query).WithArgs(orgID, "2026-10-22").WillReturnRows(rows)
+
+		skills, err := store.GetVerifiedSkills(orgID, time.Date(2026, 10, 22, 18, 0, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID][]string{sam: {"Barista", "Food handler"}, alex: {"First aid"}}, skills)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("db error"))
+
+		skills, err := store.GetVerifiedSkills(orgID, time.Now())
+		assert.Error(t, err)
+		assert.Nil(t, skills)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestExpiringSkills(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresSkillStore(db, logger)
+
+	orgID, employeeID, skillID := uuid.New(), uuid.New(), uuid.New()
+	expires := time.Date(2026, 10, 30, 0, 0, 0, 0, time.UTC)
+
+	t.Run("NotReminded", func(t *testing.T) {
+		rows := sqlmock.NewRows(employeeSkillRowColumns).AddRow(skillID, orgID, employeeID, "Sam Server", "sam@example.com",
+			"Food handler", database.SkillKindCertification, expires, nil, database.SkillVerified, uuid.New(), time.Now(), nil, time.Now())
+		mock.ExpectQuery(regexp.QuoteMeta(`WHERE s.status = 'verified' AND s.expires_on <= $1 AND s.expiry_reminded_at IS NULL`)).
+			WithArgs("2026-11-15").WillReturnRows(rows)
+
+		skills, err := store.GetExpiringSkills(time.Date(2026, 11, 15, 0, 0, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		if assert.Len(t, skills, 1) {
+			assert.Equal(t, expires, *skills[0].ExpiresOn)
+			assert.Equal(t, "sam@example.com", skills[0].EmployeeEmail)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("MarkReminded", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE employee_skills SET expiry_reminded_at = NOW() WHERE id = $1`)).
+			WithArgs(skillID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.MarkSkillExpiryReminded(skillID))
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open(c.dsn) }
+func (c dsnConnector) Driver() driver.Driver                        { return c.driver }
+
+func TestSanitizeQueryArgs(t *testing.T) {
+	orgID := uuid.New()
+	name := "Sam Doe"
+	at := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+	args := database.SanitizeQueryArgs([]driver.NamedValue{
+		{Ordinal: 1, Value: orgID},
+		{Ordinal: 2, Value: orgID.String()},
+		{Ordinal: 3, Value: "sam@example.com"},
+		{Ordinal: 4, Value: []byte("$2a$10$hash")},
+		{Ordinal: 5, Value: &name},
+		{Ordinal: 6, Value: 42},
+		{Ordinal: 7, Value: true},
+		{Ordinal: 8, Value: at},
+		{Ordinal: 9, Value: (*string)(nil)},
+		{Ordinal: 10, Value: []string{"a"}},
+	})
+
+	assert.Equal(t, []any{
+		orgID.String(),
+		orgID.String(),
+		"<redacted string, 15 chars>",
+		"<redacted 11 bytes>",
+		"<redacted string, 7 chars>",
+		42,
+		true,
+		at,
+		nil,
+		"<redacted []string>",
+	}, args)
+}
+
+func TestSlowQueryLog(t *testing.T) {
+	mockDB, mock, err := sqlmock.NewWithDSN("slow_query_log")
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer mockDB.Close()
+
+	var logs bytes.Buffer
+	slowQueries := &database.SlowQueryLog{Threshold: 20 * time.Millisecond, Logger: slog.New(slog.NewTextHandler(&logs, nil))}
+	db := sql.OpenDB(slowQueries.Connector(dsnConnector{dsn: "slow_query_log", driver: mockDB.Driver()}))
+	defer db.Close()
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`SELECT COUNT(*) FROM orders WHERE organization_id = $1`)
+
+	t.Run("FastQueryNotLogged", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnRows(NewRow(3))
+
+		var count int
+		assert.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM orders WHERE organization_id = $1`, orgID).Scan(&count))
+		assert.Equal(t, int64(0), slowQueries.Stats().Count)
+		assert.Empty(t, logs.String())
+	})
+
+	t.Run("SlowQueryLogged", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, "secret").WillDelayFor(30 * time.Millisecond).WillReturnRows(NewRow(3))
+
+		var count int
+		err := db.QueryRow(`SELECT COUNT(*) FROM orders WHERE organization_id = $1 AND note = $2`, orgID, "secret").Scan(&count)
+		assert.NoError(t, err)
+
+		stats := slowQueries.Stats()
+		assert.Equal(t, int64(1), stats.Count)
+		assert.GreaterOrEqual(t, stats.Slowest, 30*time.Millisecond)
+		assert.Contains(t, logs.String(), "slow query")
+		assert.Contains(t, logs.String(), orgID.String())
+		assert.Contains(t, logs.String(), "TestSlowQueryLog")
+		assert.NotContains(t, logs.String(), "secret")
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("SlowExecLogged", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM orders`)).WillDelayFor(30 * time.Millisecond).WillReturnResult(sqlmock.NewResult(0, 2))
+
+		_, err := db.Exec(`DELETE FROM orders WHERE organization_id = $1`, orgID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), slowQueries.Stats().Count)
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagStaffOrder(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresStaffOrderStore(db, logger)
+
+	orgID, orderID, employeeID, managerID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`INSERT INTO staff_orders (order_id, organization_id, employee_id, kind, tagged_by) SELECT id, organization_id, $3, $4, $5 FROM orders WHERE id = $1 AND organization_id = $2 ON CONFLICT (order_id) DO UPDATE`)
+
+	t.Run("Success", func(t *testing.T) {
+		now := time.Now()
+		tag := &database.StaffOrder{OrderID: orderID, EmployeeID: employeeID, Kind: database.StaffOrderMeal, TaggedBy: &managerID}
+		mock.ExpectQuery(query).WithArgs(orderID, orgID, employeeID, database.StaffOrderMeal, &managerID).
+			WillReturnRows(sqlmock.NewRows([]string{"tagged_at"}).AddRow(now))
+
+		err := store.TagStaffOrder(orgID, tag)
+		assert.NoError(t, err)
+		assert.Equal(t, now, tag.TaggedAt)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("OrderNotFound", func(t *testing.T) {
+		tag := &database.StaffOrder{OrderID: orderID, EmployeeID: employeeID, Kind: database.StaffOrderDiscount}
+		mock.ExpectQuery(query).WithArgs(orderID, orgID, employeeID, database.StaffOrderDiscount, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"tagged_at"}))
+
+		err := store.TagStaffOrder(orgID, tag)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestUntagStaffOrder(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresStaffOrderStore(db, logger)
+
+	orgID, orderID := uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`DELETE FROM staff_orders WHERE order_id = $1 AND organization_id = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orderID, orgID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.UntagStaffOrder(orgID, orderID))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotTagged", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orderID, orgID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, store.UntagStaffOrder(orgID, orderID), sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetStaffMealConsumption(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresStaffOrderStore(db, logger)
+
+	orgID, employeeID := uuid.New(), uuid.New()
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	query := regexp.QuoteMeta(`FROM staff_orders so JOIN orders o ON o.id = so.order_id JOIN users u ON u.id = so.employee_id WHERE so.organization_id = $1 AND o.create_time >= $2 AND o.create_time < $3 GROUP BY so.employee_id, u.full_name`)
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"employee_id", "full_name", "staff_meals", "staff_meal_value", "staff_meal_benefit",
+			"discounted_orders", "discounted_value", "discount_benefit"}).
+			AddRow(employeeID, "Sam Cook", 3, 45.0, 45.0, 2, 40.0, 10.0)
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).WillReturnRows(rows)
+
+		consumption, err := store.GetStaffMealConsumption(orgID, from, to)
+		assert.NoError(t, err)
+		if assert.Len(t, consumption, 1) {
+			assert.Equal(t, "Sam Cook", consumption[0].FullName)
+			assert.Equal(t, 55.0, consumption[0].TotalBenefit)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, from, to).WillReturnError(fmt.Errorf("db error"))
+
+		_, err := store.GetStaffMealConsumption(orgID, from, to)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetStaffingVsDemand(t *testing.T) {
+	db, mock := NewTestDB(t)
+	store := database.NewPostgresStaffingAnalyticsStore(db, NewTestLogger())
+
+	orgID := uuid.New()
+	date := time.Date(2026, 10, 14, 15, 30, 0, 0, time.UTC)
+	day := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	columns := []string{"slot_start", "predicted_orders", "actual_orders", "scheduled_staff"}
+	query := regexp.QuoteMeta(`FROM generate_series(0, 1440 / $3 - 1) AS slot`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, "2026-10-14", 30).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(day, 0.0, 0, 0).
+				AddRow(day.Add(30*time.Minute), 5.555, 7, 2))
+
+		result, err := store.GetStaffingVsDemand(orgID, date, 30)
+		assert.NoError(t, err)
+		assert.Equal(t, day, result.Date)
+		assert.Equal(t, 30, result.SlotMinutes)
+		assert.Equal(t, []string{"00:00", "00:30"}, result.Slots)
+		assert.Equal(t, []float64{0, 5.56}, result.PredictedOrders)
+		assert.Equal(t, []int{0, 7}, result.ActualOrders)
+		assert.Equal(t, []int{0, 2}, result.ScheduledStaff)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, "2026-10-14", 60).WillReturnError(sql.ErrConnDone)
+
+		result, err := store.GetStaffingVsDemand(orgID, date, 60)
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetTurnover(t *testing.T) {
+	db, mock := NewTestDB(t)
+	store := database.NewPostgresStaffingAnalyticsStore(db, NewTestLogger())
+
+	orgID := uuid.New()
+	from := time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC)
+	at := func(month, day int) *time.Time {
+		t := time.Date(2025, time.Month(month), day, 9, 0, 0, 0, time.UTC)
+		return &t
+	}
+	columns := []string{"hired_at", "exited_at", "voluntary"}
+	query := regexp.QuoteMeta(`FROM layoffs_hirings WHERE organization_id = $1 AND action IN ('layoff', 'resignation')`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).
+			WillReturnRows(sqlmock.NewRows(columns).
+				// employed throughout
+				AddRow(time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC), nil, false).
+				// hired in Q1 and resigned 30 days later
+				AddRow(at(2, 1), at(3, 3), true).
+				// hired in Q1 and still employed
+				AddRow(at(3, 1), nil, false).
+				// laid off in Q2 after a year, hired before the quarter
+				AddRow(time.Date(2024, 4, 15, 9, 0, 0, 0, time.UTC), at(4, 15), false).
+				// laid off in Q2 before the hire dates were kept
+				AddRow(nil, at(5, 1), false))
+
+		result, err := store.GetTurnover(orgID, from, 2)
+		assert.NoError(t, err)
+		assert.Len(t, result, 2)
+
+		q1, q2 := result[0], result[1]
+		assert.Equal(t, "2025-Q1", q1.Quarter)
+		assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), q1.Start)
+		assert.Equal(t, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), q1.End)
+		assert.Equal(t, 3, q1.HeadcountStart)
+		assert.Equal(t, 4, q1.HeadcountEnd)
+		assert.Equal(t, 1, q1.NetChange)
+		assert.Equal(t, 2, q1.Hires)
+		assert.Equal(t, 1, q1.VoluntaryExits)
+		assert.Equal(t, 0, q1.InvoluntaryExits)
+		assert.Equal(t, 28.57, *q1.TurnoverRate)
+		assert.Equal(t, 30.0, *q1.AverageTenureDays)
+		assert.Equal(t, 2, q1.RetentionCohort)
+		assert.Equal(t, 50.0, *q1.NewHireRetention)
+
+		assert.Equal(t, "2025-Q2", q2.Quarter)
+		assert.Equal(t, 4, q2.HeadcountStart)
+		assert.Equal(t, 2, q2.HeadcountEnd)
+		assert.Equal(t, 2, q2.InvoluntaryExits)
+		assert.Equal(t, 0, q2.Hires)
+		assert.Equal(t, 365.0, *q2.AverageTenureDays)
+		assert.Nil(t, q2.NewHireRetention)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnError(sql.ErrConnDone)
+
+		result, err := store.GetTurnover(orgID, from, 4)
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		AssertExpectations(t, mock)
+	})
+}
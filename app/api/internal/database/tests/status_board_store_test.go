@@ -0,0 +1,125 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var statusBoardColumns = []string{"id", "organization_id", "name", "created_by", "created_at", "last_seen_at", "revoked_at"}
+
+func TestCreateStatusBoard(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresStatusBoardStore(db, logger)
+
+	orgID, adminID := uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`INSERT INTO status_boards (organization_id, name, token_hash, created_by)`)
+
+	t.Run("Success_StoresTokenHash", func(t *testing.T) {
+		boardID := uuid.New()
+		now := time.Now()
+		mock.ExpectQuery(query).WithArgs(orgID, "Breakroom TV", database.HashSignToken("board_abc"), &adminID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(boardID, now))
+
+		board := &database.StatusBoard{Name: "Breakroom TV", CreatedBy: &adminID}
+		err := store.CreateBoard(orgID, board, "board_abc")
+		assert.NoError(t, err)
+		assert.Equal(t, boardID, board.ID)
+		assert.Equal(t, orgID, board.OrganizationID)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(fmt.Errorf("db error"))
+
+		err := store.CreateBoard(orgID, &database.StatusBoard{Name: "Breakroom TV"}, "board_abc")
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestListStatusBoards(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresStatusBoardStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`SELECT id, organization_id, name, created_by, created_at, last_seen_at, revoked_at FROM status_boards WHERE organization_id = $1 ORDER BY created_at DESC`)
+
+	t.Run("Success", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnRows(sqlmock.NewRows(statusBoardColumns).
+			AddRow(uuid.New(), orgID, "Kitchen", nil, now, now, nil).
+			AddRow(uuid.New(), orgID, "Old TV", nil, now.Add(-time.Hour), nil, now))
+
+		boards, err := store.ListBoards(orgID)
+		assert.NoError(t, err)
+		if assert.Len(t, boards, 2) {
+			assert.Nil(t, boards[0].RevokedAt)
+			assert.NotNil(t, boards[1].RevokedAt)
+		}
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetStatusBoardByToken(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresStatusBoardStore(db, logger)
+
+	query := regexp.QuoteMeta(`UPDATE status_boards SET last_seen_at = NOW()
+		WHERE token_hash = $1 AND revoked_at IS NULL`)
+
+	t.Run("Success", func(t *testing.T) {
+		boardID, orgID := uuid.New(), uuid.New()
+		now := time.Now()
+		mock.ExpectQuery(query).WithArgs(database.HashSignToken("board_abc")).
+			WillReturnRows(sqlmock.NewRows(statusBoardColumns).AddRow(boardID, orgID, "Breakroom TV", nil, now, now, nil))
+
+		board, err := store.GetBoardByToken("board_abc")
+		assert.NoError(t, err)
+		assert.Equal(t, boardID, board.ID)
+		assert.Equal(t, orgID, board.OrganizationID)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("UnknownOrRevoked", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows(statusBoardColumns))
+
+		board, err := store.GetBoardByToken("board_old")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Nil(t, board)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestRevokeStatusBoard(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresStatusBoardStore(db, logger)
+
+	orgID, boardID := uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`UPDATE status_boards SET revoked_at = NOW() WHERE id = $1 AND organization_id = $2 AND revoked_at IS NULL`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(boardID, orgID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.RevokeBoard(orgID, boardID))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(boardID, orgID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, store.RevokeBoard(orgID, boardID), sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
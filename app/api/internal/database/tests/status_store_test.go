@@ -0,0 +1,104 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordStatusEvent(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresStatusStore(db, logger)
+
+	orgID := uuid.New()
+	duration := int64(420)
+	query := regexp.QuoteMeta(`INSERT INTO org_status_events (id, organization_id, kind, source, duration_ms, failed, detail, created_at)`)
+
+	t.Run("Success", func(t *testing.T) {
+		event := &database.StatusEvent{Kind: database.StatusEventMLRequest, Source: "/predict/demand", DurationMs: &duration}
+		mock.ExpectExec(query).
+			WithArgs(sqlmock.AnyArg(), orgID, "ml_request", "/predict/demand", &duration, false, nil, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		assert.NoError(t, store.RecordEvent(orgID, event))
+		assert.NotEqual(t, uuid.Nil, event.ID)
+		assert.False(t, event.CreatedAt.IsZero())
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectExec(query).WillReturnError(fmt.Errorf("db error"))
+
+		assert.Error(t, store.RecordEvent(orgID, &database.StatusEvent{Kind: database.StatusEventIngestion, Source: "orders_csv"}))
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetLatestStatusEvent(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresStatusStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`WHERE organization_id = $1 AND kind = $2 ORDER BY created_at DESC LIMIT 1`)
+	columns := []string{"id", "kind", "source", "duration_ms", "failed", "detail", "created_at"}
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows(columns).AddRow(uuid.New(), "email_failure", "smtp", nil, true, "550 mailbox unavailable", time.Now())
+		mock.ExpectQuery(query).WithArgs(orgID, database.StatusEventEmailFailure).WillReturnRows(rows)
+
+		event, err := store.GetLatestEvent(orgID, database.StatusEventEmailFailure)
+		assert.NoError(t, err)
+		if assert.NotNil(t, event) {
+			assert.True(t, event.Failed)
+			assert.Nil(t, event.DurationMs)
+			assert.Equal(t, "550 mailbox unavailable", *event.Detail)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NoEvent", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, database.StatusEventIngestion).WillReturnRows(sqlmock.NewRows(columns))
+
+		event, err := store.GetLatestEvent(orgID, database.StatusEventIngestion)
+		assert.NoError(t, err)
+		assert.Nil(t, event)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetMLLatency(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresStatusStore(db, logger)
+
+	orgID := uuid.New()
+	since := time.Now().Add(-24 * time.Hour)
+	query := regexp.QuoteMeta(`percentile_cont(0.95) WITHIN GROUP (ORDER BY duration_ms)`)
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"count", "failures", "avg", "p95", "max"}).AddRow(12, 1, 850.5, 2100.0, 3000)
+		mock.ExpectQuery(query).WithArgs(orgID, database.StatusEventMLRequest, since).WillReturnRows(rows)
+
+		latency, err := store.GetMLLatency(orgID, since)
+		assert.NoError(t, err)
+		assert.Equal(t, &database.MLLatency{Requests: 12, Failures: 1, AvgMs: 850.5, P95Ms: 2100, MaxMs: 3000}, latency)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, database.StatusEventMLRequest, since).WillReturnError(fmt.Errorf("db error"))
+
+		latency, err := store.GetMLLatency(orgID, since)
+		assert.Error(t, err)
+		assert.Nil(t, latency)
+		AssertExpectations(t, mock)
+	})
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"log/slog"
 	"os"
 	"testing"
@@ -42,6 +43,22 @@ func NewRow(val interface{}) *sqlmock.Rows {
 	return sqlmock.NewRows([]string{"value"}).AddRow(val)
 }
 
+// Capture matches any string argument and keeps it in dest, for values like ciphertexts that cannot be
+// predicted but must be checked afterwards.
+func Capture(dest *string) sqlmock.Argument {
+	return captureArg{dest}
+}
+
+type captureArg struct{ dest *string }
+
+func (c captureArg) Match(v driver.Value) bool {
+	value, ok := v.(string)
+	if ok {
+		*c.dest = value
+	}
+	return ok
+}
+
 // CheckError is a helper to assert no error occurred.
 func CheckError(t *testing.T, err error) {
 	assert.NoError(t, err)
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTOTP(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresTOTPStore(db, logger, nil)
+
+	userID := uuid.New()
+	query := regexp.QuoteMeta(`SELECT user_id, secret, enabled_at, last_used_counter, created_at FROM user_totp WHERE user_id = $1`)
+
+	t.Run("Enabled", func(t *testing.T) {
+		enabledAt := time.Now()
+		mock.ExpectQuery(query).WithArgs(userID).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "secret", "enabled_at", "last_used_counter", "created_at"}).
+				AddRow(userID, "JBSWY3DPEHPK3PXP", enabledAt, 58000000, enabledAt))
+
+		totp, err := store.GetTOTP(userID)
+		assert.NoError(t, err)
+		if assert.NotNil(t, totp) {
+			assert.Equal(t, "JBSWY3DPEHPK3PXP", totp.Secret)
+			assert.True(t, totp.Enabled())
+			assert.Equal(t, int64(58000000), totp.LastUsedCounter)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("None", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(userID).WillReturnError(sql.ErrNoRows)
+
+		totp, err := store.GetTOTP(userID)
+		assert.NoError(t, err)
+		assert.Nil(t, totp)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestSaveAndEnableTOTP(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresTOTPStore(db, logger, nil)
+
+	userID := uuid.New()
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO user_totp (user_id, secret)`)+`.*`+regexp.QuoteMeta(`enabled_at = NULL`)).
+		WithArgs(userID, "JBSWY3DPEHPK3PXP").WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, store.SaveTOTPSecret(userID, "JBSWY3DPEHPK3PXP"))
+
+	enable := regexp.QuoteMeta(`UPDATE user_totp SET enabled_at = NOW(), last_used_counter = $2 WHERE user_id = $1 AND enabled_at IS NULL`)
+	mock.ExpectExec(enable).WithArgs(userID, int64(58000000)).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, store.EnableTOTP(userID, 58000000))
+
+	mock.ExpectExec(enable).WithArgs(userID, int64(58000001)).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, store.EnableTOTP(userID, 58000001), sql.ErrNoRows)
+	AssertExpectations(t, mock)
+}
+
+func TestUseTOTPCounter(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresTOTPStore(db, logger, nil)
+
+	userID := uuid.New()
+	query := regexp.QuoteMeta(`UPDATE user_totp SET last_used_counter = $2 WHERE user_id = $1 AND enabled_at IS NOT NULL AND last_used_counter < $2`)
+
+	mock.ExpectExec(query).WithArgs(userID, int64(58000002)).WillReturnResult(sqlmock.NewResult(0, 1))
+	used, err := store.UseTOTPCounter(userID, 58000002)
+	assert.NoError(t, err)
+	assert.True(t, used)
+
+	// the code of that period was used by another request in the meantime
+	mock.ExpectExec(query).WithArgs(userID, int64(58000002)).WillReturnResult(sqlmock.NewResult(0, 0))
+	used, err = store.UseTOTPCounter(userID, 58000002)
+	assert.NoError(t, err)
+	assert.False(t, used)
+	AssertExpectations(t, mock)
+}
+
+func TestDeleteTOTP(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresTOTPStore(db, logger, nil)
+
+	userID := uuid.New()
+	query := regexp.QuoteMeta(`DELETE FROM user_totp WHERE user_id = $1`)
+
+	mock.ExpectExec(query).WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, store.DeleteTOTP(userID))
+
+	mock.ExpectExec(query).WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, store.DeleteTOTP(userID), sql.ErrNoRows)
+	AssertExpectations(t, mock)
+}
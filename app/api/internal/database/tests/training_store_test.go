@@ -0,0 +1,137 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var trainingColumns = []string{"user_id", "organization_id", "full_name", "starts_on", "ends_on", "started_by", "graduated_at", "created_at"}
+
+func TestStartTraining(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresTrainingStore(db, logger)
+
+	userID, orgID, managerID := uuid.New(), uuid.New(), uuid.New()
+	startsOn := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`ON CONFLICT (user_id) DO UPDATE SET`)
+	training := func() *database.EmployeeTraining {
+		return &database.EmployeeTraining{UserID: userID, OrganizationID: orgID, StartsOn: startsOn, EndsOn: startsOn.AddDate(0, 0, 14), StartedBy: &managerID}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(userID, orgID, "2026-10-16", "2026-10-30", &managerID, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		started := training()
+		err := store.StartTraining(started)
+		assert.NoError(t, err)
+		assert.False(t, started.CreatedAt.IsZero())
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectExec(query).WillReturnError(fmt.Errorf("db error"))
+
+		err := store.StartTraining(training())
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetTrainings(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresTrainingStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`WHERE t.organization_id = $1 AND t.graduated_at IS NULL`)
+
+	t.Run("Success", func(t *testing.T) {
+		userID := uuid.New()
+		startsOn := time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)
+		endsOn := startsOn.AddDate(0, 0, 14)
+		mock.ExpectQuery(query).WithArgs(orgID).
+			WillReturnRows(sqlmock.NewRows(trainingColumns).AddRow(userID, orgID, "Tara Trainee", startsOn, endsOn, nil, nil, startsOn))
+
+		trainings, err := store.GetTrainings(orgID)
+		assert.NoError(t, err)
+		assert.Equal(t, []database.EmployeeTraining{
+			{UserID: userID, OrganizationID: orgID, FullName: "Tara Trainee", StartsOn: startsOn, EndsOn: endsOn, CreatedAt: startsOn},
+		}, trainings)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnRows(sqlmock.NewRows(trainingColumns))
+
+		trainings, err := store.GetTrainings(orgID)
+		assert.NoError(t, err)
+		assert.Empty(t, trainings)
+		assert.NotNil(t, trainings)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnError(fmt.Errorf("db error"))
+
+		trainings, err := store.GetTrainings(orgID)
+		assert.Error(t, err)
+		assert.Nil(t, trainings)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetDueTrainings(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresTrainingStore(db, logger)
+
+	on := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`WHERE t.graduated_at IS NULL AND t.ends_on <= $1`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs("2026-10-16").
+			WillReturnRows(sqlmock.NewRows(trainingColumns).AddRow(uuid.New(), uuid.New(), "Tara Trainee", on, on, nil, nil, on))
+
+		trainings, err := store.GetDueTrainings(on)
+		assert.NoError(t, err)
+		assert.Len(t, trainings, 1)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGraduateEmployee(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresTrainingStore(db, logger)
+
+	orgID, userID := uuid.New(), uuid.New()
+	at := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	query := regexp.QuoteMeta(`UPDATE employee_trainings SET graduated_at = $3 WHERE organization_id = $1 AND user_id = $2 AND graduated_at IS NULL`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID, userID, at).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := store.Graduate(orgID, userID, at)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotInTraining", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID, userID, at).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := store.Graduate(orgID, userID, at)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
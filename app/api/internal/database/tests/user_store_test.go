@@ -8,6 +8,7 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
@@ -15,7 +16,7 @@ import (
 func TestCreateUser(t *testing.T) {
 	db, mock := NewTestDB(t)
 	logger := NewTestLogger()
-	store := database.NewPostgresUserStore(db, logger)
+	store := database.NewPostgresUserStore(db, logger, nil)
 
 	user := &database.User{
 		ID:             uuid.New(),
@@ -27,13 +28,13 @@ func TestCreateUser(t *testing.T) {
 	}
 	// Note: PasswordHash is private in struct but handled in store logic if set. Here we assume empty hash for simple insert test.
 
-	query := regexp.QuoteMeta(`insert into users (id, full_name, email, password_hash, user_role, organization_id, salary_per_hour, max_hours_per_week, preferred_hours_per_week, max_consec_slots, on_call, created_at, updated_at) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) returning id, created_at, updated_at`)
+	query := regexp.QuoteMeta(`insert into users (id, full_name, email, password_hash, user_role, organization_id, salary_per_hour, max_hours_per_week, preferred_hours_per_week, max_consec_slots, on_call, created_at, updated_at, salary_per_hour_encrypted, phone, emergency_contact) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) returning id, created_at, updated_at`)
 
 	t.Run("Success", func(t *testing.T) {
 		rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(user.ID, time.Now(), time.Now())
 
 		mock.ExpectQuery(query).
-			WithArgs(user.ID, user.FullName, user.Email, sqlmock.AnyArg(), user.UserRole, user.OrganizationID, user.SalaryPerHour, user.MaxHoursPerWeek, user.PreferredHoursPerWeek, user.MaxConsecSlots, user.OnCall, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WithArgs(user.ID, user.FullName, user.Email, sqlmock.AnyArg(), user.UserRole, user.OrganizationID, user.SalaryPerHour, user.MaxHoursPerWeek, user.PreferredHoursPerWeek, user.MaxConsecSlots, user.OnCall, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil, nil).
 			WillReturnRows(rows)
 
 		err := store.CreateUser(user)
@@ -42,23 +43,74 @@ func TestCreateUser(t *testing.T) {
 	})
 }
 
+func TestEncryptedUserColumns(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	cipher, err := service.NewCryptoService("k1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=", "")
+	assert.NoError(t, err)
+	store := database.NewPostgresUserStore(db, logger, cipher)
+
+	salary := 20.5
+	phone := "+1 555 0100"
+	sealedSalary, _ := cipher.Encrypt("20.5")
+	sealedPhone, _ := cipher.Encrypt(phone)
+	email := "john@example.com"
+
+	t.Run("CreateSealsColumns", func(t *testing.T) {
+		user := &database.User{ID: uuid.New(), FullName: "John Doe", Email: email, UserRole: "employee", OrganizationID: uuid.New(), SalaryPerHour: &salary, Phone: &phone}
+		rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(user.ID, time.Now(), time.Now())
+
+		mock.ExpectQuery(`insert into users`).
+			WithArgs(user.ID, user.FullName, user.Email, sqlmock.AnyArg(), user.UserRole, user.OrganizationID, nil, nil, nil, nil, nil, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
+			WillReturnRows(rows)
+
+		err := store.CreateUser(user)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("ReadOpensColumns", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "full_name", "email", "password_hash", "user_role", "organization_id", "salary_per_hour", "max_hours_per_week", "preferred_hours_per_week", "max_consec_slots", "on_call", "created_at", "updated_at", "salary_per_hour_encrypted", "phone", "emergency_contact"}).
+			AddRow(uuid.New(), "John Doe", email, []byte("hash"), "employee", uuid.New(), nil, 40, 30, 4, false, time.Now(), time.Now(), sealedSalary, sealedPhone, nil)
+		mock.ExpectQuery(`from users where email=`).WithArgs(email).WillReturnRows(rows)
+
+		user, err := store.GetUserByEmail(email)
+		assert.NoError(t, err)
+		assert.Equal(t, salary, *user.SalaryPerHour)
+		assert.Equal(t, phone, *user.Phone)
+		assert.Nil(t, user.EmergencyContact)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("RotatedKeyStillReadable", func(t *testing.T) {
+		rotated, err := service.NewCryptoService("k1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=,k2:ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=", "")
+		assert.NoError(t, err)
+		assert.True(t, rotated.NeedsRotation(sealedPhone))
+
+		opened, err := rotated.Decrypt(sealedPhone)
+		assert.NoError(t, err)
+		assert.Equal(t, phone, opened)
+	})
+}
+
 func TestGetUserByEmail(t *testing.T) {
 	db, mock := NewTestDB(t)
 	logger := NewTestLogger()
-	store := database.NewPostgresUserStore(db, logger)
+	store := database.NewPostgresUserStore(db, logger, nil)
 
 	email := "john@example.com"
-	query := regexp.QuoteMeta(`select id, full_name, email, password_hash, user_role, organization_id, salary_per_hour, max_hours_per_week, preferred_hours_per_week, max_consec_slots, on_call, created_at, updated_at from users where email=$1`)
+	query := regexp.QuoteMeta(`select id, full_name, email, password_hash, user_role, organization_id, salary_per_hour, max_hours_per_week, preferred_hours_per_week, max_consec_slots, on_call, created_at, updated_at, salary_per_hour_encrypted, phone, emergency_contact from users where email=$1`)
 
 	t.Run("Success", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "full_name", "email", "password_hash", "user_role", "organization_id", "salary_per_hour", "max_hours_per_week", "preferred_hours_per_week", "max_consec_slots", "on_call", "created_at", "updated_at"}).
-			AddRow(uuid.New(), "John Doe", email, []byte("hash"), "employee", uuid.New(), 20.0, 40, 30, 4, false, time.Now(), time.Now())
+		rows := sqlmock.NewRows([]string{"id", "full_name", "email", "password_hash", "user_role", "organization_id", "salary_per_hour", "max_hours_per_week", "preferred_hours_per_week", "max_consec_slots", "on_call", "created_at", "updated_at", "salary_per_hour_encrypted", "phone", "emergency_contact"}).
+			AddRow(uuid.New(), "John Doe", email, []byte("hash"), "employee", uuid.New(), 20.0, 40, 30, 4, false, time.Now(), time.Now(), nil, "+1 555 0100", nil)
 
 		mock.ExpectQuery(query).WithArgs(email).WillReturnRows(rows)
 
 		user, err := store.GetUserByEmail(email)
 		assert.NoError(t, err)
 		assert.Equal(t, email, user.Email)
+		assert.Equal(t, "+1 555 0100", *user.Phone)
 		AssertExpectations(t, mock)
 	})
 }
@@ -66,7 +118,7 @@ func TestGetUserByEmail(t *testing.T) {
 func TestUpdateUser(t *testing.T) {
 	db, mock := NewTestDB(t)
 	logger := NewTestLogger()
-	store := database.NewPostgresUserStore(db, logger)
+	store := database.NewPostgresUserStore(db, logger, nil)
 
 	user := &database.User{
 		ID:             uuid.New(),
@@ -76,11 +128,11 @@ func TestUpdateUser(t *testing.T) {
 		OrganizationID: uuid.New(),
 	}
 
-	query := regexp.QuoteMeta(`update users set full_name=$1, email=$2, user_role=$3, organization_id=$4, salary_per_hour=$5, max_hours_per_week=$6, preferred_hours_per_week=$7, max_consec_slots=$8, on_call=$9, updated_at=CURRENT_TIMESTAMP where id=$10 returning updated_at`)
+	query := regexp.QuoteMeta(`update users set full_name=$1, email=$2, user_role=$3, organization_id=$4, salary_per_hour=$5, max_hours_per_week=$6, preferred_hours_per_week=$7, max_consec_slots=$8, on_call=$9, salary_per_hour_encrypted=$11, phone=$12, emergency_contact=$13, updated_at=CURRENT_TIMESTAMP where id=$10 returning updated_at`)
 
 	t.Run("Success", func(t *testing.T) {
 		mock.ExpectExec(query).
-			WithArgs(user.FullName, user.Email, user.UserRole, user.OrganizationID, user.SalaryPerHour, user.MaxHoursPerWeek, user.PreferredHoursPerWeek, user.MaxConsecSlots, user.OnCall, user.ID).
+			WithArgs(user.FullName, user.Email, user.UserRole, user.OrganizationID, user.SalaryPerHour, user.MaxHoursPerWeek, user.PreferredHoursPerWeek, user.MaxConsecSlots, user.OnCall, user.ID, nil, nil, nil).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 
 		err := store.UpdateUser(user)
@@ -92,14 +144,15 @@ func TestUpdateUser(t *testing.T) {
 func TestLayoffUser(t *testing.T) {
 	db, mock := NewTestDB(t)
 	logger := NewTestLogger()
-	store := database.NewPostgresUserStore(db, logger)
+	store := database.NewPostgresUserStore(db, logger, nil)
 
 	userID := uuid.New()
 	reason := "Redundancy"
 	orgID := uuid.New()
 
-	getUserQuery := regexp.QuoteMeta(`SELECT full_name, email, organization_id FROM users WHERE id=$1`)
-	insertLayoffQuery := regexp.QuoteMeta(`INSERT INTO layoffs_hirings (id, user_id, user_name, user_email, organization_id, action, reason, action_date) VALUES ($1, $2, $3, $4, $5, 'layoff', $6, CURRENT_TIMESTAMP)`)
+	hiredAt := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	getUserQuery := regexp.QuoteMeta(`SELECT u.full_name, u.email, u.organization_id, u.created_at, (SELECT MIN(r.updated_at) FROM requests r WHERE r.employee_id = u.id AND r.type = 'resign' AND r.status = 'accepted') FROM users u WHERE u.id=$1`)
+	insertLayoffQuery := regexp.QuoteMeta(`INSERT INTO layoffs_hirings (id, user_id, user_name, user_email, organization_id, action, reason, action_date, hired_at) VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8, CURRENT_TIMESTAMP), $9)`)
 	deleteUserQuery := regexp.QuoteMeta(`DELETE FROM users WHERE id=$1`)
 
 	t.Run("Success", func(t *testing.T) {
@@ -107,11 +160,11 @@ func TestLayoffUser(t *testing.T) {
 
 		// 1. Get User Info
 		mock.ExpectQuery(getUserQuery).WithArgs(userID).
-			WillReturnRows(sqlmock.NewRows([]string{"full_name", "email", "organization_id"}).AddRow("John Doe", "john@example.com", orgID))
+			WillReturnRows(sqlmock.NewRows([]string{"full_name", "email", "organization_id", "created_at", "resigned_at"}).AddRow("John Doe", "john@example.com", orgID, hiredAt, nil))
 
 		// 2. Insert Layoff Record
 		mock.ExpectExec(insertLayoffQuery).
-			WithArgs(sqlmock.AnyArg(), userID, "John Doe", "john@example.com", orgID, reason).
+			WithArgs(sqlmock.AnyArg(), userID, "John Doe", "john@example.com", orgID, "layoff", reason, nil, hiredAt).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		// 3. Delete User
@@ -125,6 +178,23 @@ func TestLayoffUser(t *testing.T) {
 		AssertExpectations(t, mock)
 	})
 
+	t.Run("RecordsAcceptedResignation", func(t *testing.T) {
+		resignedAt := time.Date(2026, 9, 30, 17, 0, 0, 0, time.UTC)
+		mock.ExpectBegin()
+		mock.ExpectQuery(getUserQuery).WithArgs(userID).
+			WillReturnRows(sqlmock.NewRows([]string{"full_name", "email", "organization_id", "created_at", "resigned_at"}).AddRow("John Doe", "john@example.com", orgID, hiredAt, resignedAt))
+		mock.ExpectExec(insertLayoffQuery).
+			WithArgs(sqlmock.AnyArg(), userID, "John Doe", "john@example.com", orgID, "resignation", reason, &resignedAt, hiredAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(deleteUserQuery).WithArgs(userID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := store.LayoffUser(userID, reason)
+		assert.NoError(t, err)
+		AssertExpectations(t, mock)
+	})
+
 	t.Run("RollbackOnUserNotFound", func(t *testing.T) {
 		mock.ExpectBegin()
 		mock.ExpectQuery(getUserQuery).WithArgs(userID).WillReturnError(sql.ErrNoRows)
@@ -139,15 +209,15 @@ func TestLayoffUser(t *testing.T) {
 func TestGetProfile(t *testing.T) {
 	db, mock := NewTestDB(t)
 	logger := NewTestLogger()
-	store := database.NewPostgresUserStore(db, logger)
+	store := database.NewPostgresUserStore(db, logger, nil)
 
 	userID := uuid.New()
 	// Complex query - matching structure
-	query := regexp.QuoteMeta(`SELECT u.full_name, u.email, u.user_role, u.salary_per_hour, o.name as organization, u.created_at, COALESCE(SUM(EXTRACT(EPOCH FROM (s.end_hour - s.start_hour)) / 3600), 0) as total_hours, COALESCE(SUM( CASE WHEN s.schedule_date >= date_trunc('week', CURRENT_DATE) THEN EXTRACT(EPOCH FROM (s.end_hour - s.start_hour)) / 3600 ELSE 0 END ), 0) as week_hours FROM users u JOIN organizations o ON u.organization_id = o.id LEFT JOIN schedules s ON u.id = s.employee_id AND (s.schedule_date + s.end_hour) <= CURRENT_TIMESTAMP WHERE u.id = $1 GROUP BY u.id, u.full_name, u.email, u.user_role, u.salary_per_hour, o.name, u.created_at`)
+	query := regexp.QuoteMeta(`SELECT u.full_name, u.email, u.user_role, u.salary_per_hour, u.salary_per_hour_encrypted, o.name as organization, u.created_at, COALESCE(SUM(EXTRACT(EPOCH FROM (s.end_hour - s.start_hour)) / 3600) FILTER (WHERE s.shift_type = 'working'), 0) as total_hours, COALESCE(SUM( CASE WHEN s.schedule_date >= date_trunc('week', CURRENT_DATE) THEN EXTRACT(EPOCH FROM (s.end_hour - s.start_hour)) / 3600 ELSE 0 END ) FILTER (WHERE s.shift_type = 'working'), 0) as week_hours, COALESCE(SUM(EXTRACT(EPOCH FROM (s.end_hour - s.start_hour)) / 3600) FILTER (WHERE s.shift_type = 'standby'), 0) as standby_hours, COALESCE(r.standby_pay_percent, 25) as standby_pay_percent FROM users u JOIN organizations o ON u.organization_id = o.id LEFT JOIN organizations_rules r ON u.organization_id = r.organization_id LEFT JOIN schedules s ON u.id = s.employee_id AND (s.schedule_date + s.end_hour) <= CURRENT_TIMESTAMP WHERE u.id = $1 GROUP BY u.id, u.full_name, u.email, u.user_role, u.salary_per_hour, u.salary_per_hour_encrypted, o.name, u.created_at, r.standby_pay_percent`)
 
 	t.Run("Success", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"full_name", "email", "user_role", "salary_per_hour", "organization", "created_at", "total_hours", "week_hours"}).
-			AddRow("John Doe", "john@example.com", "employee", 25.0, "Acme Corp", time.Now(), 100.0, 40.0)
+		rows := sqlmock.NewRows([]string{"full_name", "email", "user_role", "salary_per_hour", "salary_per_hour_encrypted", "organization", "created_at", "total_hours", "week_hours", "standby_hours", "standby_pay_percent"}).
+			AddRow("John Doe", "john@example.com", "employee", 25.0, nil, "Acme Corp", time.Now(), 100.0, 40.0, 0.0, 25)
 
 		mock.ExpectQuery(query).WithArgs(userID).WillReturnRows(rows)
 
@@ -156,6 +226,21 @@ func TestGetProfile(t *testing.T) {
 		assert.Equal(t, "John Doe", profile.FullName)
 		assert.Equal(t, 25.0, *profile.SalaryPerHour)
 		assert.Equal(t, 100.0, *profile.HoursWorked)
+		assert.Nil(t, profile.StandbyHours)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("StandbyShifts", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"full_name", "email", "user_role", "salary_per_hour", "salary_per_hour_encrypted", "organization", "created_at", "total_hours", "week_hours", "standby_hours", "standby_pay_percent"}).
+			AddRow("John Doe", "john@example.com", "employee", 20.0, nil, "Acme Corp", time.Now(), 32.0, 8.0, 12.0, 30)
+
+		mock.ExpectQuery(query).WithArgs(userID).WillReturnRows(rows)
+
+		profile, err := store.GetProfile(userID)
+		assert.NoError(t, err)
+		assert.Equal(t, 32.0, *profile.HoursWorked)
+		assert.Equal(t, 12.0, *profile.StandbyHours)
+		assert.Equal(t, 6.0, *profile.StandbyPayPerHour)
 		AssertExpectations(t, mock)
 	})
 }
@@ -163,7 +248,7 @@ func TestGetProfile(t *testing.T) {
 func TestChangePassword(t *testing.T) {
 	db, mock := NewTestDB(t)
 	logger := NewTestLogger()
-	store := database.NewPostgresUserStore(db, logger)
+	store := database.NewPostgresUserStore(db, logger, nil)
 
 	userID := uuid.New()
 	newHash := []byte("newhash")
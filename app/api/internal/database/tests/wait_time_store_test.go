@@ -0,0 +1,277 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOpenKitchenLoad(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresWaitTimeStore(db, logger)
+
+	orgID := uuid.New()
+	since := time.Now().Add(-4 * time.Hour)
+	query := regexp.QuoteMeta(`WHERE o.organization_id = $1 AND o.order_status = 'incompleted' AND o.create_time >= $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, since).
+			WillReturnRows(sqlmock.NewRows([]string{"count", "items", "units"}).AddRow(3, 8, 11.0))
+
+		load, err := store.GetOpenKitchenLoad(orgID, since)
+		assert.NoError(t, err)
+		assert.Equal(t, &database.KitchenLoad{OpenOrders: 3, OpenItems: 8, PrepUnits: 11}, load)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, since).WillReturnError(fmt.Errorf("db error"))
+
+		load, err := store.GetOpenKitchenLoad(orgID, since)
+		assert.Error(t, err)
+		assert.Nil(t, load)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetKitchenCapacity(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresWaitTimeStore(db, logger)
+
+	orgID := uuid.New()
+	at := time.Now()
+	query := regexp.QuoteMeta(`AND s.shift_type = 'working' AND (s.schedule_date + s.start_hour) <= $2 AND (s.schedule_date + s.end_hour) > $2 GROUP BY s.employee_id`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, at).
+			WillReturnRows(sqlmock.NewRows([]string{"count", "items_per_hour"}).AddRow(4, 35.0))
+
+		capacity, err := store.GetKitchenCapacity(orgID, at)
+		assert.NoError(t, err)
+		assert.Equal(t, &database.KitchenCapacity{StaffOnShift: 4, ItemsPerHour: 35}, capacity)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, at).WillReturnError(fmt.Errorf("db error"))
+
+		capacity, err := store.GetKitchenCapacity(orgID, at)
+		assert.Error(t, err)
+		assert.Nil(t, capacity)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetOrderProfile(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresWaitTimeStore(db, logger)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`WHERE o.organization_id = $1 GROUP BY o.id`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).
+			WillReturnRows(sqlmock.NewRows([]string{"avg_items", "avg_units"}).AddRow(2.5, 1.2))
+
+		profile, err := store.GetOrderProfile(orgID)
+		assert.NoError(t, err)
+		assert.Equal(t, &database.OrderProfile{AvgItemsPerOrder: 2.5, AvgPrepUnits: 1.2}, profile)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnError(fmt.Errorf("db error"))
+
+		profile, err := store.GetOrderProfile(orgID)
+		assert.Error(t, err)
+		assert.Nil(t, profile)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestMarkPrepared(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresWaitTimeStore(db, logger)
+
+	orgID := uuid.New()
+	orderID := uuid.New()
+	created := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := created.Add(14 * time.Minute)
+	lockQuery := regexp.QuoteMeta(`SELECT create_time FROM orders WHERE id = $1 AND organization_id = $2 FOR UPDATE`)
+	orderQuery := regexp.QuoteMeta(`UPDATE orders SET prepared_at = $3`)
+	progressQuery := regexp.QuoteMeta(`SELECT o.prepared_at, COUNT(oi.item_id) FILTER (WHERE oi.prepared_at IS NULL)`)
+
+	t.Run("WholeOrder", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WithArgs(orderID, orgID).WillReturnRows(sqlmock.NewRows([]string{"create_time"}).AddRow(created))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE order_items SET prepared_at = $2 WHERE order_id = $1 AND prepared_at IS NULL`)).
+			WithArgs(orderID, at).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(orderQuery).WithArgs(orderID, orgID, at).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(progressQuery).WithArgs(orderID).WillReturnRows(sqlmock.NewRows([]string{"prepared_at", "remaining"}).AddRow(at, 0))
+		mock.ExpectCommit()
+
+		order, err := store.MarkPrepared(orgID, orderID, nil, at)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, order.PreparedItems)
+		assert.Equal(t, 0, order.RemainingItems)
+		assert.Equal(t, at, *order.PreparedAt)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("SomeItems", func(t *testing.T) {
+		itemID := uuid.New()
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WithArgs(orderID, orgID).WillReturnRows(sqlmock.NewRows([]string{"create_time"}).AddRow(created))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(DISTINCT item_id) FROM order_items WHERE order_id = $1 AND item_id = ANY($2)`)).
+			WithArgs(orderID, sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectExec(regexp.QuoteMeta(`AND item_id = ANY($3) AND prepared_at IS NULL`)).
+			WithArgs(orderID, at, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(orderQuery).WithArgs(orderID, orgID, at).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(progressQuery).WithArgs(orderID).WillReturnRows(sqlmock.NewRows([]string{"prepared_at", "remaining"}).AddRow(nil, 2))
+		mock.ExpectCommit()
+
+		order, err := store.MarkPrepared(orgID, orderID, []uuid.UUID{itemID, itemID}, at)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, order.PreparedItems)
+		assert.Equal(t, 2, order.RemainingItems)
+		assert.Nil(t, order.PreparedAt)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("UnknownItem", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WithArgs(orderID, orgID).WillReturnRows(sqlmock.NewRows([]string{"create_time"}).AddRow(created))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(DISTINCT item_id) FROM order_items`)).
+			WithArgs(orderID, sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectRollback()
+
+		order, err := store.MarkPrepared(orgID, orderID, []uuid.UUID{uuid.New(), uuid.New()}, at)
+		assert.ErrorIs(t, err, database.ErrUnknownOrderItem)
+		assert.Nil(t, order)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("BeforeCreated", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WithArgs(orderID, orgID).WillReturnRows(sqlmock.NewRows([]string{"create_time"}).AddRow(created))
+		mock.ExpectRollback()
+
+		order, err := store.MarkPrepared(orgID, orderID, nil, created.Add(-time.Minute))
+		assert.ErrorIs(t, err, database.ErrPreparedBeforeCreated)
+		assert.Nil(t, order)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).WithArgs(orderID, orgID).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		order, err := store.MarkPrepared(orgID, orderID, nil, at)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Nil(t, order)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetRecentPrepTime(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresWaitTimeStore(db, logger)
+
+	orgID := uuid.New()
+	since := time.Now().Add(-time.Hour)
+	query := regexp.QuoteMeta(`WHERE organization_id = $1 AND prepared_at >= $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, since).
+			WillReturnRows(sqlmock.NewRows([]string{"count", "avg"}).AddRow(6, 17.26))
+
+		prepTime, err := store.GetRecentPrepTime(orgID, since)
+		assert.NoError(t, err)
+		assert.Equal(t, &database.PrepTime{Prepared: 6, AvgMinutes: 17.3}, prepTime)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, since).WillReturnError(fmt.Errorf("db error"))
+
+		prepTime, err := store.GetRecentPrepTime(orgID, since)
+		assert.Error(t, err)
+		assert.Nil(t, prepTime)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetItemPrepTimes(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresWaitTimeStore(db, logger)
+
+	orgID := uuid.New()
+	since := time.Now().AddDate(0, 0, -28)
+	query := regexp.QuoteMeta(`WHERE o.organization_id = $1 AND o.create_time >= $2 AND oi.prepared_at IS NOT NULL`)
+
+	t.Run("Success", func(t *testing.T) {
+		itemID := uuid.New()
+		mock.ExpectQuery(query).WithArgs(orgID, since).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "count", "avg_minutes"}).AddRow(itemID, "Lasagna", 40, 21.54))
+
+		items, err := store.GetItemPrepTimes(orgID, since)
+		assert.NoError(t, err)
+		assert.Equal(t, []database.ItemPrepTime{{ItemID: itemID, Name: "Lasagna", PrepTime: database.PrepTime{Prepared: 40, AvgMinutes: 21.5}}}, items)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, since).WillReturnError(fmt.Errorf("db error"))
+
+		items, err := store.GetItemPrepTimes(orgID, since)
+		assert.Error(t, err)
+		assert.Nil(t, items)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetHourlyPrepTimes(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresWaitTimeStore(db, logger)
+
+	orgID := uuid.New()
+	since := time.Now().AddDate(0, 0, -28)
+	query := regexp.QuoteMeta(`EXTRACT(HOUR FROM create_time)::int AS hour`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, since).
+			WillReturnRows(sqlmock.NewRows([]string{"hour", "count", "avg"}).AddRow(12, 18, 11.0).AddRow(19, 25, 16.96))
+
+		hours, err := store.GetHourlyPrepTimes(orgID, since)
+		assert.NoError(t, err)
+		assert.Equal(t, []database.HourPrepTime{
+			{Hour: 12, PrepTime: database.PrepTime{Prepared: 18, AvgMinutes: 11}},
+			{Hour: 19, PrepTime: database.PrepTime{Prepared: 25, AvgMinutes: 17}},
+		}, hours)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID, since).WillReturnError(fmt.Errorf("db error"))
+
+		hours, err := store.GetHourlyPrepTimes(orgID, since)
+		assert.Error(t, err)
+		assert.Nil(t, hours)
+		AssertExpectations(t, mock)
+	})
+}
@@ -0,0 +1,259 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var webhookColumns = []string{"id", "url", "secret", "events", "enabled", "created_by", "created_at", "updated_at"}
+
+var webhookDeliveryColumns = []string{"id", "webhook_id", "event", "payload", "status", "attempts", "response_status", "error", "next_attempt_at", "created_at", "delivered_at"}
+
+func TestCreateWebhook(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	cipher, err := service.NewCryptoService("k1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=", "")
+	assert.NoError(t, err)
+	store := database.NewPostgresWebhookStore(db, logger, cipher)
+
+	orgID, userID := uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`INSERT INTO webhooks (organization_id, url, secret, events, enabled, created_by) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at, updated_at`)
+
+	t.Run("SealsSecret", func(t *testing.T) {
+		webhook := &database.Webhook{URL: "https://hooks.example.com", Secret: "whsec_0123456789abcdef", Events: []string{"shift.updated"}, Enabled: true, CreatedBy: &userID}
+		webhookID, now := uuid.New(), time.Now()
+
+		var sealed string
+		mock.ExpectQuery(query).
+			WithArgs(orgID, "https://hooks.example.com", Capture(&sealed), pq.Array([]string{"shift.updated"}), true, &userID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(webhookID, now, now))
+
+		err := store.CreateWebhook(orgID, webhook)
+		assert.NoError(t, err)
+		assert.Equal(t, webhookID, webhook.ID)
+		AssertExpectations(t, mock)
+
+		// the secret is not written in plaintext and reads back as it was saved
+		assert.NotContains(t, sealed, "whsec_")
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM webhooks WHERE organization_id = $1 AND id = $2`)).WithArgs(orgID, webhookID).
+			WillReturnRows(sqlmock.NewRows(webhookColumns).AddRow(webhookID, "https://hooks.example.com", sealed, pq.StringArray{"shift.updated"}, true, userID, now, now))
+
+		saved, err := store.GetWebhook(orgID, webhookID)
+		assert.NoError(t, err)
+		assert.Equal(t, "whsec_0123456789abcdef", saved.Secret)
+		assert.Equal(t, []string{"shift.updated"}, saved.Events)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetWebhook_NotFound(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresWebhookStore(db, logger, nil)
+
+	orgID, webhookID := uuid.New(), uuid.New()
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM webhooks WHERE organization_id = $1 AND id = $2`)).WithArgs(orgID, webhookID).
+		WillReturnError(sql.ErrNoRows)
+
+	webhook, err := store.GetWebhook(orgID, webhookID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Nil(t, webhook)
+	AssertExpectations(t, mock)
+}
+
+func TestGetWebhooks(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresWebhookStore(db, logger, nil)
+
+	orgID := uuid.New()
+	query := regexp.QuoteMeta(`FROM webhooks WHERE organization_id = $1 ORDER BY created_at, id`)
+
+	t.Run("Success", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnRows(sqlmock.NewRows(webhookColumns).
+			AddRow(uuid.New(), "https://hooks.example.com", "whsec_plain", pq.StringArray{"schedule.published", "request.approved"}, false, nil, now, now))
+
+		webhooks, err := store.GetWebhooks(orgID)
+		assert.NoError(t, err)
+		if assert.Len(t, webhooks, 1) {
+			assert.Equal(t, "whsec_plain", webhooks[0].Secret)
+			assert.False(t, webhooks[0].Enabled)
+			assert.Nil(t, webhooks[0].CreatedBy)
+		}
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectQuery(query).WithArgs(orgID).WillReturnError(fmt.Errorf("db error"))
+
+		_, err := store.GetWebhooks(orgID)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestUpdateWebhook(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresWebhookStore(db, logger, nil)
+
+	orgID := uuid.New()
+	webhook := &database.Webhook{ID: uuid.New(), URL: "https://hooks.example.com", Secret: "whsec_new", Events: []string{"shift.swapped"}}
+	query := regexp.QuoteMeta(`UPDATE webhooks SET url = $3, secret = $4, events = $5, enabled = $6, updated_at = NOW() WHERE organization_id = $1 AND id = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(query).WithArgs(orgID, webhook.ID, webhook.URL, "whsec_new", pq.Array(webhook.Events), false).
+			WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(now))
+
+		err := store.UpdateWebhook(orgID, webhook)
+		assert.NoError(t, err)
+		assert.Equal(t, now, webhook.UpdatedAt)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectQuery(query).WillReturnError(sql.ErrNoRows)
+
+		err := store.UpdateWebhook(orgID, webhook)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresWebhookStore(db, logger, nil)
+
+	orgID, webhookID := uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`DELETE FROM webhooks WHERE organization_id = $1 AND id = $2`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID, webhookID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, store.DeleteWebhook(orgID, webhookID))
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID, webhookID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, store.DeleteWebhook(orgID, webhookID), sql.ErrNoRows)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestGetWebhookDeliveries(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresWebhookStore(db, logger, nil)
+
+	orgID, webhookID := uuid.New(), uuid.New()
+	query := regexp.QuoteMeta(`WHERE w.organization_id = $1 AND d.webhook_id = $2 ORDER BY d.created_at DESC, d.id LIMIT $3`)
+
+	now := time.Now()
+	status, message := 500, "webhook answered 500"
+	mock.ExpectQuery(query).WithArgs(orgID, webhookID, 50).WillReturnRows(sqlmock.NewRows(webhookDeliveryColumns).
+		AddRow(uuid.New(), webhookID, "shift.updated", []byte(`{"event":"shift.updated"}`), "pending", 1, status, message, now, now, nil))
+
+	deliveries, err := store.GetDeliveries(orgID, webhookID, 50)
+	assert.NoError(t, err)
+	if assert.Len(t, deliveries, 1) {
+		assert.JSONEq(t, `{"event":"shift.updated"}`, string(deliveries[0].Payload))
+		assert.Equal(t, 500, *deliveries[0].ResponseStatus)
+		assert.Nil(t, deliveries[0].DeliveredAt)
+	}
+	AssertExpectations(t, mock)
+}
+
+func TestQueueWebhookEvent(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresWebhookStore(db, logger, nil)
+
+	orgID := uuid.New()
+	payload := []byte(`{"event":"request.approved"}`)
+	query := regexp.QuoteMeta(`INSERT INTO webhook_deliveries (webhook_id, event, payload) SELECT id, $2, $3 FROM webhooks WHERE organization_id = $1 AND enabled AND $2 = ANY(events)`)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).WithArgs(orgID, "request.approved", payload).WillReturnResult(sqlmock.NewResult(0, 2))
+
+		queued, err := store.QueueEvent(orgID, "request.approved", payload)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), queued)
+		AssertExpectations(t, mock)
+	})
+
+	t.Run("DBError", func(t *testing.T) {
+		mock.ExpectExec(query).WillReturnError(fmt.Errorf("db error"))
+
+		_, err := store.QueueEvent(orgID, "request.approved", payload)
+		assert.Error(t, err)
+		AssertExpectations(t, mock)
+	})
+}
+
+func TestClaimDueWebhookDeliveries(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresWebhookStore(db, logger, nil)
+
+	now := time.Now()
+	query := regexp.QuoteMeta(`UPDATE webhook_deliveries d SET next_attempt_at = $2`) + `.*` +
+		regexp.QuoteMeta(`WHERE due.status = 'pending' AND due.next_attempt_at <= $1 AND dw.enabled`) + `.*` +
+		regexp.QuoteMeta(`FOR UPDATE OF due SKIP LOCKED`) + `.*` + regexp.QuoteMeta(`w.url, w.secret`)
+
+	deliveryID := uuid.New()
+	mock.ExpectQuery(query).WithArgs(now, now.Add(5*time.Minute), 50).WillReturnRows(sqlmock.NewRows(append(webhookDeliveryColumns, "url", "secret")).
+		AddRow(deliveryID, uuid.New(), "shift.swapped", []byte(`{}`), "pending", 0, nil, nil, now, now, nil, "https://hooks.example.com", "whsec_plain"))
+
+	deliveries, err := store.ClaimDueDeliveries(now, 5*time.Minute, 50)
+	assert.NoError(t, err)
+	if assert.Len(t, deliveries, 1) {
+		assert.Equal(t, deliveryID, deliveries[0].ID)
+		assert.Equal(t, "https://hooks.example.com", deliveries[0].URL)
+		assert.Equal(t, "whsec_plain", deliveries[0].Secret)
+	}
+	AssertExpectations(t, mock)
+}
+
+func TestRecordWebhookAttempt(t *testing.T) {
+	db, mock := NewTestDB(t)
+	logger := NewTestLogger()
+	store := database.NewPostgresWebhookStore(db, logger, nil)
+
+	deliveryID := uuid.New()
+	query := regexp.QuoteMeta(`UPDATE webhook_deliveries SET status = $2, attempts = attempts + 1`)
+	status := 502
+	message := "webhook answered 502"
+	next := time.Now().Add(time.Minute)
+
+	for name, tc := range map[string]struct {
+		attempt database.WebhookAttempt
+		status  string
+	}{
+		"Delivered": {database.WebhookAttempt{Delivered: true, ResponseStatus: &status}, "delivered"},
+		"Retried":   {database.WebhookAttempt{ResponseStatus: &status, Error: &message, NextAttemptAt: &next}, "pending"},
+		"Failed":    {database.WebhookAttempt{ResponseStatus: &status, Error: &message}, "failed"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mock.ExpectExec(query).WithArgs(deliveryID, tc.status, tc.attempt.ResponseStatus, tc.attempt.Error, tc.attempt.NextAttemptAt).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			assert.NoError(t, store.RecordAttempt(deliveryID, tc.attempt))
+			AssertExpectations(t, mock)
+		})
+	}
+}
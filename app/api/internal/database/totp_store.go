@@ -0,0 +1,140 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserTOTP is the authenticator app of a user. It is pending until the user confirms a first code.
+type UserTOTP struct {
+	UserID uuid.UUID `json:"user_id"`
+	// Secret is never sent back once the app is set up, it is sealed with the Cipher of the store
+	Secret          string     `json:"-"`
+	EnabledAt       *time.Time `json:"enabled_at"`
+	LastUsedCounter int64      `json:"-"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// Enabled reports whether the user confirmed the app
+func (t *UserTOTP) Enabled() bool {
+	return t.EnabledAt != nil
+}
+
+type TOTPStore interface {
+	GetTOTP(user_id uuid.UUID) (*UserTOTP, error)
+	SaveTOTPSecret(user_id uuid.UUID, secret string) error
+	EnableTOTP(user_id uuid.UUID, counter int64) error
+	UseTOTPCounter(user_id uuid.UUID, counter int64) (bool, error)
+	DeleteTOTP(user_id uuid.UUID) error
+}
+
+// PostgresTOTPStore seals the secrets with Cipher when it is set
+type PostgresTOTPStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+	Cipher FieldCipher
+}
+
+func NewPostgresTOTPStore(DB *sql.DB, Logger *slog.Logger, cipher FieldCipher) *PostgresTOTPStore {
+	return &PostgresTOTPStore{
+		DB:     DB,
+		Logger: Logger,
+		Cipher: cipher,
+	}
+}
+
+// GetTOTP returns the authenticator app of the user, nil when they have none
+func (s *PostgresTOTPStore) GetTOTP(user_id uuid.UUID) (*UserTOTP, error) {
+	query := `SELECT user_id, secret, enabled_at, last_used_counter, created_at FROM user_totp WHERE user_id = $1`
+
+	var totp UserTOTP
+	var secret sql.NullString
+	err := s.DB.QueryRow(query, user_id).Scan(&totp.UserID, &secret, &totp.EnabledAt, &totp.LastUsedCounter, &totp.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		s.Logger.Error("failed to get totp", "error", err, "user_id", user_id)
+		return nil, err
+	}
+
+	opened, err := openString(s.Cipher, secret)
+	if err != nil {
+		s.Logger.Error("failed to open totp secret", "error", err, "user_id", user_id)
+		return nil, err
+	}
+	if opened != nil {
+		totp.Secret = *opened
+	}
+	return &totp, nil
+}
+
+// SaveTOTPSecret replaces the app of the user with a pending one using secret
+func (s *PostgresTOTPStore) SaveTOTPSecret(user_id uuid.UUID, secret string) error {
+	sealed, err := sealString(s.Cipher, &secret)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO user_totp (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET
+			secret = EXCLUDED.secret,
+			enabled_at = NULL,
+			last_used_counter = 0,
+			created_at = NOW()
+	`
+	if _, err := s.DB.Exec(query, user_id, sealed); err != nil {
+		s.Logger.Error("failed to save totp secret", "error", err, "user_id", user_id)
+		return err
+	}
+	return nil
+}
+
+// EnableTOTP confirms the pending app of the user with the period of its first code, sql.ErrNoRows when
+// there is no pending app
+func (s *PostgresTOTPStore) EnableTOTP(user_id uuid.UUID, counter int64) error {
+	query := `UPDATE user_totp SET enabled_at = NOW(), last_used_counter = $2 WHERE user_id = $1 AND enabled_at IS NULL`
+	result, err := s.DB.Exec(query, user_id, counter)
+	if err != nil {
+		s.Logger.Error("failed to enable totp", "error", err, "user_id", user_id)
+		return err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// UseTOTPCounter records the period of an accepted code. It returns false when a code of that period or a
+// later one was already used, so two requests cannot use the same code.
+func (s *PostgresTOTPStore) UseTOTPCounter(user_id uuid.UUID, counter int64) (bool, error) {
+	query := `UPDATE user_totp SET last_used_counter = $2 WHERE user_id = $1 AND enabled_at IS NOT NULL AND last_used_counter < $2`
+	result, err := s.DB.Exec(query, user_id, counter)
+	if err != nil {
+		s.Logger.Error("failed to use totp counter", "error", err, "user_id", user_id)
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// DeleteTOTP removes the app of the user, sql.ErrNoRows when they have none
+func (s *PostgresTOTPStore) DeleteTOTP(user_id uuid.UUID) error {
+	result, err := s.DB.Exec(`DELETE FROM user_totp WHERE user_id = $1`, user_id)
+	if err != nil {
+		s.Logger.Error("failed to delete totp", "error", err, "user_id", user_id)
+		return err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package database
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmployeeTraining is the training period of a new hire. Until EndsOn the employee is scheduled on top of
+// the staff the demand needs and never in solo coverage roles, then they are graduated.
+type EmployeeTraining struct {
+	UserID         uuid.UUID  `json:"user_id"`
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	FullName       string     `json:"full_name"`
+	StartsOn       time.Time  `json:"starts_on"`
+	EndsOn         time.Time  `json:"ends_on"`
+	StartedBy      *uuid.UUID `json:"started_by,omitempty"`
+	GraduatedAt    *time.Time `json:"graduated_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// InTrainingOn reports whether the employee is still in training on the day of date
+func (t EmployeeTraining) InTrainingOn(date time.Time) bool {
+	return t.GraduatedAt == nil && date.Format(time.DateOnly) < t.EndsOn.Format(time.DateOnly)
+}
+
+type TrainingStore interface {
+	StartTraining(training *EmployeeTraining) error
+	GetTrainings(org_id uuid.UUID) ([]EmployeeTraining, error)
+	GetDueTrainings(on time.Time) ([]EmployeeTraining, error)
+	Graduate(org_id, user_id uuid.UUID, at time.Time) error
+}
+
+type PostgresTrainingStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresTrainingStore(DB *sql.DB, Logger *slog.Logger) *PostgresTrainingStore {
+	return &PostgresTrainingStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+const trainingColumns = `t.user_id, t.organization_id, u.full_name, t.starts_on, t.ends_on, t.started_by, t.graduated_at, t.created_at`
+
+func scanTraining(row rowScanner) (*EmployeeTraining, error) {
+	var training EmployeeTraining
+	if err := row.Scan(
+		&training.UserID,
+		&training.OrganizationID,
+		&training.FullName,
+		&training.StartsOn,
+		&training.EndsOn,
+		&training.StartedBy,
+		&training.GraduatedAt,
+		&training.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &training, nil
+}
+
+// StartTraining puts the employee in training until training.EndsOn. An employee already in training, or
+// graduated, starts over with the new period.
+func (s *PostgresTrainingStore) StartTraining(training *EmployeeTraining) error {
+	if training.CreatedAt.IsZero() {
+		training.CreatedAt = time.Now()
+	}
+	query := `
+		INSERT INTO employee_trainings (user_id, organization_id, starts_on, ends_on, started_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE SET
+			starts_on = EXCLUDED.starts_on,
+			ends_on = EXCLUDED.ends_on,
+			started_by = EXCLUDED.started_by,
+			graduated_at = NULL
+	`
+	_, err := s.DB.Exec(query,
+		training.UserID, training.OrganizationID, training.StartsOn.Format(time.DateOnly), training.EndsOn.Format(time.DateOnly),
+		training.StartedBy, training.CreatedAt,
+	)
+	if err != nil {
+		s.Logger.Error("failed to start employee training", "error", err, "user_id", training.UserID)
+		return err
+	}
+	training.GraduatedAt = nil
+	return nil
+}
+
+// GetTrainings lists the employees of the organization in training, the first to graduate first
+func (s *PostgresTrainingStore) GetTrainings(org_id uuid.UUID) ([]EmployeeTraining, error) {
+	query := `SELECT ` + trainingColumns + `
+		FROM employee_trainings t
+		JOIN users u ON u.id = t.user_id
+		WHERE t.organization_id = $1 AND t.graduated_at IS NULL
+		ORDER BY t.ends_on, u.full_name
+	`
+	return s.queryTrainings(query, org_id)
+}
+
+// GetDueTrainings lists the trainings of every organization that end on or before the day of on and were
+// not graduated yet
+func (s *PostgresTrainingStore) GetDueTrainings(on time.Time) ([]EmployeeTraining, error) {
+	query := `SELECT ` + trainingColumns + `
+		FROM employee_trainings t
+		JOIN users u ON u.id = t.user_id
+		WHERE t.graduated_at IS NULL AND t.ends_on <= $1
+		ORDER BY t.organization_id, t.ends_on
+	`
+	return s.queryTrainings(query, on.Format(time.DateOnly))
+}
+
+func (s *PostgresTrainingStore) queryTrainings(query string, args ...any) ([]EmployeeTraining, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		s.Logger.Error("failed to get employee trainings", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	trainings := []EmployeeTraining{}
+	for rows.Next() {
+		training, err := scanTraining(rows)
+		if err != nil {
+			s.Logger.Error("failed to scan employee training", "error", err)
+			return nil, err
+		}
+		trainings = append(trainings, *training)
+	}
+	return trainings, rows.Err()
+}
+
+// Graduate ends the training of the employee at the given time. It returns sql.ErrNoRows when the
+// employee is not in training in the organization.
+func (s *PostgresTrainingStore) Graduate(org_id, user_id uuid.UUID, at time.Time) error {
+	query := `UPDATE employee_trainings SET graduated_at = $3 WHERE organization_id = $1 AND user_id = $2 AND graduated_at IS NULL`
+
+	result, err := s.DB.Exec(query, org_id, user_id, at)
+	if err != nil {
+		s.Logger.Error("failed to graduate employee", "error", err, "user_id", user_id)
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+var ErrEncryptionDisabled = errors.New("column encryption is not configured")
+
+// FieldCipher encrypts sensitive columns (salary, phone, emergency contact) before they are written
+// and decrypts them after they are read. A nil FieldCipher stores those columns in plaintext.
+type FieldCipher interface {
+	Encrypt(plaintext string) (string, error)
+	// Decrypt returns values written before encryption was enabled unchanged
+	Decrypt(value string) (string, error)
+	// NeedsRotation reports whether a value is plaintext or sealed with a key other than the active one
+	NeedsRotation(value string) bool
+}
+
+// sealSalary returns the values for the salary_per_hour and salary_per_hour_encrypted columns
+func sealSalary(cipher FieldCipher, salary *float64) (*float64, *string, error) {
+	if cipher == nil || salary == nil {
+		return salary, nil, nil
+	}
+	sealed, err := cipher.Encrypt(strconv.FormatFloat(*salary, 'f', -1, 64))
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, &sealed, nil
+}
+
+// openSalary reads a salary from whichever of the two salary columns is set
+func openSalary(cipher FieldCipher, plain sql.NullFloat64, sealed sql.NullString) (*float64, error) {
+	if !sealed.Valid {
+		if !plain.Valid {
+			return nil, nil
+		}
+		return &plain.Float64, nil
+	}
+	if cipher == nil {
+		return nil, ErrEncryptionDisabled
+	}
+	value, err := cipher.Decrypt(sealed.String)
+	if err != nil {
+		return nil, err
+	}
+	salary, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("decrypted salary is not a number: %w", err)
+	}
+	return &salary, nil
+}
+
+func sealString(cipher FieldCipher, value *string) (*string, error) {
+	if cipher == nil || value == nil {
+		return value, nil
+	}
+	sealed, err := cipher.Encrypt(*value)
+	if err != nil {
+		return nil, err
+	}
+	return &sealed, nil
+}
+
+func openString(cipher FieldCipher, value sql.NullString) (*string, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+	if cipher == nil {
+		return &value.String, nil
+	}
+	opened, err := cipher.Decrypt(value.String)
+	if err != nil {
+		return nil, err
+	}
+	return &opened, nil
+}
+
+// sensitiveColumns holds the raw values of the encrypted user columns
+type sensitiveColumns struct {
+	salary           sql.NullFloat64
+	sealedSalary     sql.NullString
+	phone            sql.NullString
+	emergencyContact sql.NullString
+}
+
+// open decrypts the columns into the user
+func (sc *sensitiveColumns) open(cipher FieldCipher, user *User) error {
+	var err error
+	if user.SalaryPerHour, err = openSalary(cipher, sc.salary, sc.sealedSalary); err != nil {
+		return err
+	}
+	if user.Phone, err = openString(cipher, sc.phone); err != nil {
+		return err
+	}
+	if user.EmergencyContact, err = openString(cipher, sc.emergencyContact); err != nil {
+		return err
+	}
+	return nil
+}
+
+// needsRotation reports whether any column is plaintext or sealed with a retired key
+func (sc *sensitiveColumns) needsRotation(cipher FieldCipher) bool {
+	if sc.salary.Valid {
+		return true
+	}
+	for _, value := range []sql.NullString{sc.sealedSalary, sc.phone, sc.emergencyContact} {
+		if value.Valid && cipher.NeedsRotation(value.String) {
+			return true
+		}
+	}
+	return false
+}
+
+// EncryptSensitiveColumns encrypts plaintext sensitive columns written before encryption was enabled and
+// re-encrypts values sealed with a retired key. It returns the number of users that were rewritten.
+func (pgus *PostgresUserStore) EncryptSensitiveColumns() (int, error) {
+	if pgus.cipher == nil {
+		return 0, ErrEncryptionDisabled
+	}
+	return pgus.rewriteSensitiveColumns(pgus.cipher, func(sc *sensitiveColumns) bool {
+		return sc.needsRotation(pgus.cipher)
+	})
+}
+
+// DecryptSensitiveColumns writes every sensitive column back in plaintext, before encryption is turned off
+// or the encrypted columns migration is rolled back. It returns the number of users that were rewritten.
+func (pgus *PostgresUserStore) DecryptSensitiveColumns() (int, error) {
+	if pgus.cipher == nil {
+		return 0, ErrEncryptionDisabled
+	}
+	return pgus.rewriteSensitiveColumns(nil, func(sc *sensitiveColumns) bool {
+		return sc.sealedSalary.Valid || sc.phone.Valid || sc.emergencyContact.Valid
+	})
+}
+
+// rewriteSensitiveColumns decrypts the rows selected by needsRewrite and writes them back sealed with target
+func (pgus *PostgresUserStore) rewriteSensitiveColumns(target FieldCipher, needsRewrite func(*sensitiveColumns) bool) (int, error) {
+	tx, err := pgus.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT id, salary_per_hour, salary_per_hour_encrypted, phone, emergency_contact FROM users FOR UPDATE`)
+	if err != nil {
+		return 0, err
+	}
+
+	var users []*User
+	for rows.Next() {
+		var id uuid.UUID
+		var sc sensitiveColumns
+		if err := rows.Scan(&id, &sc.salary, &sc.sealedSalary, &sc.phone, &sc.emergencyContact); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if !needsRewrite(&sc) {
+			continue
+		}
+		user := &User{ID: id}
+		if err := sc.open(pgus.cipher, user); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to decrypt user %s: %w", id, err)
+		}
+		users = append(users, user)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, user := range users {
+		salary, sealedSalary, err := sealSalary(target, user.SalaryPerHour)
+		if err != nil {
+			return 0, err
+		}
+		phone, err := sealString(target, user.Phone)
+		if err != nil {
+			return 0, err
+		}
+		emergencyContact, err := sealString(target, user.EmergencyContact)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = tx.Exec(`UPDATE users SET salary_per_hour = $2, salary_per_hour_encrypted = $3, phone = $4, emergency_contact = $5 WHERE id = $1`,
+			user.ID, salary, sealedSalary, phone, emergencyContact)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(users), nil
+}
@@ -63,19 +63,22 @@ func NewPasswordFromHash(hash []byte) Password {
 }
 
 type User struct {
-	ID                    uuid.UUID `json:"id"`
-	FullName              string    `json:"full_name"`
-	Email                 string    `json:"email"`
-	PasswordHash          Password  `json:"-"`
-	UserRole              string    `json:"user_role"`
-	SalaryPerHour         *float64  `json:"salary_per_hour,omitempty"`
-	OrganizationID        uuid.UUID `json:"organization_id"`
-	MaxHoursPerWeek       *int      `json:"max_hours_per_week,omitempty"`
-	PreferredHoursPerWeek *int      `json:"preferred_hours_per_week,omitempty"`
-	MaxConsecSlots        *int      `json:"max_consec_slots,omitempty"`
-	OnCall                *bool     `json:"on_call"`
-	CreatedAt             time.Time `json:"created_at"`
-	UpdatedAt             time.Time `json:"updated_at"`
+	ID                    uuid.UUID      `json:"id"`
+	FullName              string         `json:"full_name"`
+	Email                 string         `json:"email"`
+	PasswordHash          Password       `json:"-"`
+	UserRole              string         `json:"user_role"`
+	SalaryPerHour         *float64       `json:"salary_per_hour,omitempty"`
+	OrganizationID        uuid.UUID      `json:"organization_id"`
+	MaxHoursPerWeek       *int           `json:"max_hours_per_week,omitempty"`
+	PreferredHoursPerWeek *int           `json:"preferred_hours_per_week,omitempty"`
+	MaxConsecSlots        *int           `json:"max_consec_slots,omitempty"`
+	OnCall                *bool          `json:"on_call"`
+	Phone                 *string        `json:"phone,omitempty"`
+	EmergencyContact      *string        `json:"emergency_contact,omitempty"`
+	CreatedAt             time.Time      `json:"created_at"`
+	UpdatedAt             time.Time      `json:"updated_at"`
+	CustomFields          map[string]any `json:"custom_fields,omitempty"`
 }
 
 var AnonymousUser = &User{}
@@ -93,15 +96,20 @@ type UserProfile struct {
 	CreatedAt           time.Time `json:"created_at"`
 	HoursWorked         *float64  `json:"hours_worked,omitempty"`
 	HoursWorkedThisWeek *float64  `json:"this_week_hours,omitempty"`
+	StandbyHours        *float64  `json:"standby_hours,omitempty"`
+	StandbyPayPerHour   *float64  `json:"standby_pay_per_hour,omitempty"`
 }
 
+// PostgresUserStore encrypts salaries, phone numbers and emergency contacts with cipher when it is set
 type PostgresUserStore struct {
-	db *sql.DB
+	db     *sql.DB
+	cipher FieldCipher
 }
 
-func NewPostgresUserStore(db *sql.DB, Logger *slog.Logger) *PostgresUserStore {
+func NewPostgresUserStore(db *sql.DB, Logger *slog.Logger, cipher FieldCipher) *PostgresUserStore {
 	return &PostgresUserStore{
-		db: db,
+		db:     db,
+		cipher: cipher,
 	}
 }
 
@@ -129,25 +137,33 @@ func (pgus *PostgresUserStore) CreateUser(user *User) error {
 		user.UpdatedAt = time.Now()
 	}
 
+	salary, sealedSalary, phone, emergencyContact, err := pgus.sealSensitiveColumns(user)
+	if err != nil {
+		return err
+	}
+
 	query :=
 		`insert into users
-	(id, full_name, email, password_hash, user_role, organization_id, salary_per_hour, max_hours_per_week, preferred_hours_per_week, max_consec_slots, on_call, created_at, updated_at) 
-	values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) returning id, created_at, updated_at`
+	(id, full_name, email, password_hash, user_role, organization_id, salary_per_hour, max_hours_per_week, preferred_hours_per_week, max_consec_slots, on_call, created_at, updated_at, salary_per_hour_encrypted, phone, emergency_contact) 
+	values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) returning id, created_at, updated_at`
 
-	err := pgus.db.QueryRow(query,
+	err = pgus.db.QueryRow(query,
 		user.ID,
 		user.FullName,
 		user.Email,
 		user.PasswordHash.hash,
 		user.UserRole,
 		user.OrganizationID,
-		user.SalaryPerHour,
+		salary,
 		user.MaxHoursPerWeek,
 		user.PreferredHoursPerWeek,
 		user.MaxConsecSlots,
 		user.OnCall,
 		user.CreatedAt,
 		user.UpdatedAt,
+		sealedSalary,
+		phone,
+		emergencyContact,
 	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
@@ -160,10 +176,11 @@ func (pgus *PostgresUserStore) GetUserByEmail(email string) (*User, error) {
 	var user User
 	query :=
 		`select 
-	id, full_name, email, password_hash, user_role, organization_id, salary_per_hour, max_hours_per_week, preferred_hours_per_week, max_consec_slots, on_call, created_at, updated_at 
+	id, full_name, email, password_hash, user_role, organization_id, salary_per_hour, max_hours_per_week, preferred_hours_per_week, max_consec_slots, on_call, created_at, updated_at, salary_per_hour_encrypted, phone, emergency_contact 
 	from users where email=$1`
 
 	var hash []byte
+	var sc sensitiveColumns
 
 	err := pgus.db.QueryRow(query, email).Scan(
 		&user.ID,
@@ -172,27 +189,38 @@ func (pgus *PostgresUserStore) GetUserByEmail(email string) (*User, error) {
 		&hash,
 		&user.UserRole,
 		&user.OrganizationID,
-		&user.SalaryPerHour,
+		&sc.salary,
 		&user.MaxHoursPerWeek,
 		&user.PreferredHoursPerWeek,
 		&user.MaxConsecSlots,
 		&user.OnCall,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&sc.sealedSalary,
+		&sc.phone,
+		&sc.emergencyContact,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if err := sc.open(pgus.cipher, &user); err != nil {
+		return nil, err
+	}
 	user.PasswordHash.hash = hash
 	return &user, nil
 }
 
 func (pgus *PostgresUserStore) UpdateUser(user *User) error {
+	salary, sealedSalary, phone, emergencyContact, err := pgus.sealSensitiveColumns(user)
+	if err != nil {
+		return err
+	}
+
 	query :=
 		`update users 
-	set full_name=$1, email=$2, user_role=$3, organization_id=$4, salary_per_hour=$5, max_hours_per_week=$6, preferred_hours_per_week=$7, max_consec_slots=$8, on_call=$9, updated_at=CURRENT_TIMESTAMP where id=$10 
+	set full_name=$1, email=$2, user_role=$3, organization_id=$4, salary_per_hour=$5, max_hours_per_week=$6, preferred_hours_per_week=$7, max_consec_slots=$8, on_call=$9, salary_per_hour_encrypted=$11, phone=$12, emergency_contact=$13, updated_at=CURRENT_TIMESTAMP where id=$10 
 	returning updated_at`
-	res, err := pgus.db.Exec(query, user.FullName, user.Email, user.UserRole, user.OrganizationID, user.SalaryPerHour, user.MaxHoursPerWeek, user.PreferredHoursPerWeek, user.MaxConsecSlots, user.OnCall, user.ID)
+	res, err := pgus.db.Exec(query, user.FullName, user.Email, user.UserRole, user.OrganizationID, salary, user.MaxHoursPerWeek, user.PreferredHoursPerWeek, user.MaxConsecSlots, user.OnCall, user.ID, sealedSalary, phone, emergencyContact)
 	if err != nil {
 		return err
 	}
@@ -208,10 +236,11 @@ func (pgus *PostgresUserStore) UpdateUser(user *User) error {
 
 func (pgus *PostgresUserStore) GetUserByID(id uuid.UUID) (*User, error) {
 	var user User
-	query := `SELECT id, full_name, email, password_hash, user_role, organization_id, salary_per_hour, max_hours_per_week, preferred_hours_per_week, max_consec_slots, on_call, created_at, updated_at 
+	query := `SELECT id, full_name, email, password_hash, user_role, organization_id, salary_per_hour, max_hours_per_week, preferred_hours_per_week, max_consec_slots, on_call, created_at, updated_at, salary_per_hour_encrypted, phone, emergency_contact 
 		FROM users WHERE id=$1`
 
 	var hash []byte
+	var sc sensitiveColumns
 	err := pgus.db.QueryRow(query, id).Scan(
 		&user.ID,
 		&user.FullName,
@@ -219,23 +248,29 @@ func (pgus *PostgresUserStore) GetUserByID(id uuid.UUID) (*User, error) {
 		&hash,
 		&user.UserRole,
 		&user.OrganizationID,
-		&user.SalaryPerHour,
+		&sc.salary,
 		&user.MaxHoursPerWeek,
 		&user.PreferredHoursPerWeek,
 		&user.MaxConsecSlots,
 		&user.OnCall,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&sc.sealedSalary,
+		&sc.phone,
+		&sc.emergencyContact,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if err := sc.open(pgus.cipher, &user); err != nil {
+		return nil, err
+	}
 	user.PasswordHash.hash = hash
 	return &user, nil
 }
 
 func (pgus *PostgresUserStore) GetUsersByOrganization(orgID uuid.UUID) ([]*User, error) {
-	query := `SELECT id, full_name, email, user_role, organization_id, salary_per_hour, max_hours_per_week, preferred_hours_per_week, max_consec_slots, on_call, created_at, updated_at 
+	query := `SELECT id, full_name, email, user_role, organization_id, salary_per_hour, max_hours_per_week, preferred_hours_per_week, max_consec_slots, on_call, created_at, updated_at, salary_per_hour_encrypted, phone, emergency_contact 
 		FROM users WHERE organization_id=$1 ORDER BY created_at DESC`
 
 	rows, err := pgus.db.Query(query, orgID)
@@ -247,23 +282,30 @@ func (pgus *PostgresUserStore) GetUsersByOrganization(orgID uuid.UUID) ([]*User,
 	var users []*User
 	for rows.Next() {
 		var user User
+		var sc sensitiveColumns
 		err := rows.Scan(
 			&user.ID,
 			&user.FullName,
 			&user.Email,
 			&user.UserRole,
 			&user.OrganizationID,
-			&user.SalaryPerHour,
+			&sc.salary,
 			&user.MaxHoursPerWeek,
 			&user.PreferredHoursPerWeek,
 			&user.MaxConsecSlots,
 			&user.OnCall,
 			&user.CreatedAt,
 			&user.UpdatedAt,
+			&sc.sealedSalary,
+			&sc.phone,
+			&sc.emergencyContact,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if err := sc.open(pgus.cipher, &user); err != nil {
+			return nil, err
+		}
 		users = append(users, &user)
 	}
 
@@ -290,6 +332,8 @@ func (pgus *PostgresUserStore) DeleteUser(id uuid.UUID) error {
 	return nil
 }
 
+// LayoffUser removes the user and records their exit with their hire date. A user whose resignation was
+// accepted is recorded as a resignation on the day it was accepted, any other as a layoff.
 func (pgus *PostgresUserStore) LayoffUser(id uuid.UUID, reason string) error {
 	tx, err := pgus.db.Begin()
 	if err != nil {
@@ -300,17 +344,26 @@ func (pgus *PostgresUserStore) LayoffUser(id uuid.UUID, reason string) error {
 	// Get user info before deletion
 	var userName, userEmail string
 	var orgID uuid.UUID
-	getUserQuery := `SELECT full_name, email, organization_id FROM users WHERE id=$1`
-	err = tx.QueryRow(getUserQuery, id).Scan(&userName, &userEmail, &orgID)
+	var hiredAt time.Time
+	var resignedAt *time.Time
+	getUserQuery := `SELECT u.full_name, u.email, u.organization_id, u.created_at,
+		(SELECT MIN(r.updated_at) FROM requests r WHERE r.employee_id = u.id AND r.type = 'resign' AND r.status = 'accepted')
+		FROM users u WHERE u.id=$1`
+	err = tx.QueryRow(getUserQuery, id).Scan(&userName, &userEmail, &orgID, &hiredAt, &resignedAt)
 	if err != nil {
 		return err
 	}
 
+	action := "layoff"
+	if resignedAt != nil {
+		action = "resignation"
+	}
+
 	// Insert layoff record with user info
-	layoffQuery := `INSERT INTO layoffs_hirings (id, user_id, user_name, user_email, organization_id, action, reason, action_date) 
-		VALUES ($1, $2, $3, $4, $5, 'layoff', $6, CURRENT_TIMESTAMP)`
+	layoffQuery := `INSERT INTO layoffs_hirings (id, user_id, user_name, user_email, organization_id, action, reason, action_date, hired_at) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8, CURRENT_TIMESTAMP), $9)`
 
-	_, err = tx.Exec(layoffQuery, uuid.New(), id, userName, userEmail, orgID, reason)
+	_, err = tx.Exec(layoffQuery, uuid.New(), id, userName, userEmail, orgID, action, reason, resignedAt, hiredAt)
 	if err != nil {
 		return err
 	}
@@ -335,7 +388,10 @@ func (pgus *PostgresUserStore) LayoffUser(id uuid.UUID, reason string) error {
 // Get Profile of User From PostgreSQL Database (admins profile has salaries and hours empty)
 func (pgus *PostgresUserStore) GetProfile(id uuid.UUID) (*UserProfile, error) {
 	var profile UserProfile
-	var totalHours, weekHours sql.NullFloat64
+	var totalHours, weekHours, standbyHours sql.NullFloat64
+	var standbyPayPercent int
+	var salary sql.NullFloat64
+	var sealedSalary sql.NullString
 
 	// Get user info with organization name and calculated hours
 	query := `
@@ -344,36 +400,46 @@ func (pgus *PostgresUserStore) GetProfile(id uuid.UUID) (*UserProfile, error) {
 			u.email,
 			u.user_role,
 			u.salary_per_hour,
+			u.salary_per_hour_encrypted,
 			o.name as organization,
 			u.created_at,
-			COALESCE(SUM(EXTRACT(EPOCH FROM (s.end_hour - s.start_hour)) / 3600), 0) as total_hours,
+			COALESCE(SUM(EXTRACT(EPOCH FROM (s.end_hour - s.start_hour)) / 3600) FILTER (WHERE s.shift_type = 'working'), 0) as total_hours,
 			COALESCE(SUM(
 				CASE 
 					WHEN s.schedule_date >= date_trunc('week', CURRENT_DATE) 
 					THEN EXTRACT(EPOCH FROM (s.end_hour - s.start_hour)) / 3600
 					ELSE 0 
 				END
-			), 0) as week_hours
+			) FILTER (WHERE s.shift_type = 'working'), 0) as week_hours,
+			COALESCE(SUM(EXTRACT(EPOCH FROM (s.end_hour - s.start_hour)) / 3600) FILTER (WHERE s.shift_type = 'standby'), 0) as standby_hours,
+			COALESCE(r.standby_pay_percent, 25) as standby_pay_percent
 		FROM users u
 		JOIN organizations o ON u.organization_id = o.id
+		LEFT JOIN organizations_rules r ON u.organization_id = r.organization_id
 		LEFT JOIN schedules s ON u.id = s.employee_id AND (s.schedule_date + s.end_hour) <= CURRENT_TIMESTAMP
 		WHERE u.id = $1
-		GROUP BY u.id, u.full_name, u.email, u.user_role, u.salary_per_hour, o.name, u.created_at
+		GROUP BY u.id, u.full_name, u.email, u.user_role, u.salary_per_hour, u.salary_per_hour_encrypted, o.name, u.created_at, r.standby_pay_percent
 	`
 
 	err := pgus.db.QueryRow(query, id).Scan(
 		&profile.FullName,
 		&profile.Email,
 		&profile.UserRole,
-		&profile.SalaryPerHour,
+		&salary,
+		&sealedSalary,
 		&profile.Organization,
 		&profile.CreatedAt,
 		&totalHours,
 		&weekHours,
+		&standbyHours,
+		&standbyPayPercent,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if profile.SalaryPerHour, err = openSalary(pgus.cipher, salary, sealedSalary); err != nil {
+		return nil, err
+	}
 
 	// Set hours only for non-admin users
 	if profile.UserRole != "admin" && totalHours.Valid && totalHours.Float64 > 0 {
@@ -382,10 +448,36 @@ func (pgus *PostgresUserStore) GetProfile(id uuid.UUID) (*UserProfile, error) {
 	if profile.UserRole != "admin" && weekHours.Valid && weekHours.Float64 > 0 {
 		profile.HoursWorkedThisWeek = &weekHours.Float64
 	}
+	// Standby shifts that were not activated are paid a share of the hourly salary
+	if profile.UserRole != "admin" && standbyHours.Valid && standbyHours.Float64 > 0 {
+		profile.StandbyHours = &standbyHours.Float64
+		if profile.SalaryPerHour != nil {
+			standbyPay := *profile.SalaryPerHour * float64(standbyPayPercent) / 100
+			profile.StandbyPayPerHour = &standbyPay
+		}
+	}
 
 	return &profile, nil
 }
 
+// sealSensitiveColumns returns the values written to the salary_per_hour, salary_per_hour_encrypted,
+// phone and emergency_contact columns for a user
+func (pgus *PostgresUserStore) sealSensitiveColumns(user *User) (*float64, *string, *string, *string, error) {
+	salary, sealedSalary, err := sealSalary(pgus.cipher, user.SalaryPerHour)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	phone, err := sealString(pgus.cipher, user.Phone)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	emergencyContact, err := sealString(pgus.cipher, user.EmergencyContact)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return salary, sealedSalary, phone, emergencyContact, nil
+}
+
 // Change Password in the database for the user
 func (pgus *PostgresUserStore) ChangePassword(id uuid.UUID, passwordHash []byte) error {
 	query := `UPDATE users SET password_hash = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
@@ -0,0 +1,322 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ErrPreparedBeforeCreated is returned when an order is marked prepared before it was placed
+var ErrPreparedBeforeCreated = errors.New("prepared_at is before the order was created")
+
+// KitchenLoad is the work queued in orders that are neither completed nor prepared yet.
+// PrepUnits weighs every item by the staff it needs to be prepared (needed_num_to_prepare).
+type KitchenLoad struct {
+	OpenOrders int     `json:"open_orders"`
+	OpenItems  int     `json:"open_items"`
+	PrepUnits  float64 `json:"prep_units"`
+}
+
+// KitchenCapacity is the staff on shift and the items per hour they can prepare together
+type KitchenCapacity struct {
+	StaffOnShift int     `json:"staff_on_shift"`
+	ItemsPerHour float64 `json:"items_per_hour"`
+}
+
+// OrderProfile describes a typical order of the organization
+type OrderProfile struct {
+	AvgItemsPerOrder float64 `json:"avg_items_per_order"`
+	AvgPrepUnits     float64 `json:"avg_prep_units_per_item"`
+}
+
+// PrepTime is how long the kitchen took on average, from the order being placed to it being prepared
+type PrepTime struct {
+	Prepared   int     `json:"prepared"`
+	AvgMinutes float64 `json:"avg_prep_minutes"`
+}
+
+// ItemPrepTime is the prep time of the item across the orders it was prepared in
+type ItemPrepTime struct {
+	ItemID uuid.UUID `json:"item_id"`
+	Name   string    `json:"name"`
+	PrepTime
+}
+
+// HourPrepTime is the prep time of the orders placed in an hour of the day
+type HourPrepTime struct {
+	Hour int `json:"hour"`
+	PrepTime
+}
+
+// PreparedOrder is the progress of an order in the kitchen, PreparedAt is set once all its items are prepared
+type PreparedOrder struct {
+	OrderID        uuid.UUID  `json:"order_id"`
+	PreparedItems  int        `json:"prepared_items"`
+	RemainingItems int        `json:"remaining_items"`
+	PreparedAt     *time.Time `json:"prepared_at"`
+}
+
+type WaitTimeStore interface {
+	GetOpenKitchenLoad(org_id uuid.UUID, since time.Time) (*KitchenLoad, error)
+	GetKitchenCapacity(org_id uuid.UUID, at time.Time) (*KitchenCapacity, error)
+	GetOrderProfile(org_id uuid.UUID) (*OrderProfile, error)
+
+	MarkPrepared(org_id, order_id uuid.UUID, item_ids []uuid.UUID, at time.Time) (*PreparedOrder, error)
+	GetRecentPrepTime(org_id uuid.UUID, since time.Time) (*PrepTime, error)
+	GetItemPrepTimes(org_id uuid.UUID, since time.Time) ([]ItemPrepTime, error)
+	GetHourlyPrepTimes(org_id uuid.UUID, since time.Time) ([]HourPrepTime, error)
+}
+
+type PostgresWaitTimeStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+}
+
+func NewPostgresWaitTimeStore(DB *sql.DB, Logger *slog.Logger) *PostgresWaitTimeStore {
+	return &PostgresWaitTimeStore{
+		DB:     DB,
+		Logger: Logger,
+	}
+}
+
+// GetOpenKitchenLoad sums the items still to prepare of incomplete orders created since the given time
+func (s *PostgresWaitTimeStore) GetOpenKitchenLoad(org_id uuid.UUID, since time.Time) (*KitchenLoad, error) {
+	query := `
+		SELECT COUNT(DISTINCT o.id), COALESCE(SUM(oi.quantity), 0), COALESCE(SUM(oi.quantity * GREATEST(i.needed_num_to_prepare, 1)), 0)
+		FROM orders o
+		LEFT JOIN order_items oi ON oi.order_id = o.id AND oi.prepared_at IS NULL
+		LEFT JOIN items i ON i.id = oi.item_id
+		WHERE o.organization_id = $1 AND o.order_status = 'incompleted' AND o.create_time >= $2 AND o.prepared_at IS NULL
+	`
+	var load KitchenLoad
+	if err := s.DB.QueryRow(query, org_id, since).Scan(&load.OpenOrders, &load.OpenItems, &load.PrepUnits); err != nil {
+		s.Logger.Error("failed to get open kitchen load", "error", err, "organization_id", org_id)
+		return nil, err
+	}
+	return &load, nil
+}
+
+// GetKitchenCapacity counts the employees on shift at the given time. Employees with several roles
+// count with the fastest role that prepares items, roles that do not (need_for_demand = false) add nothing.
+func (s *PostgresWaitTimeStore) GetKitchenCapacity(org_id uuid.UUID, at time.Time) (*KitchenCapacity, error) {
+	query := `
+		SELECT COUNT(*), COALESCE(SUM(items_per_hour), 0)
+		FROM (
+			SELECT s.employee_id, COALESCE(MAX(r.items_per_role_per_hour), 0) AS items_per_hour
+			FROM schedules s
+			JOIN users u ON u.id = s.employee_id
+			LEFT JOIN user_roles ur ON ur.user_id = u.id AND ur.organization_id = u.organization_id
+			LEFT JOIN organizations_roles r ON r.organization_id = ur.organization_id AND r.role = ur.user_role AND r.need_for_demand = true
+			WHERE u.organization_id = $1
+			AND s.shift_type = 'working'
+			AND (s.schedule_date + s.start_hour) <= $2
+			AND (s.schedule_date + s.end_hour) > $2
+			GROUP BY s.employee_id
+		) AS on_shift
+	`
+	var capacity KitchenCapacity
+	if err := s.DB.QueryRow(query, org_id, at).Scan(&capacity.StaffOnShift, &capacity.ItemsPerHour); err != nil {
+		s.Logger.Error("failed to get kitchen capacity", "error", err, "organization_id", org_id)
+		return nil, err
+	}
+	return &capacity, nil
+}
+
+// GetOrderProfile averages the size of past orders and the prep requirement of their items
+func (s *PostgresWaitTimeStore) GetOrderProfile(org_id uuid.UUID) (*OrderProfile, error) {
+	query := `
+		SELECT COALESCE(AVG(order_items), 0), COALESCE(SUM(order_units) / NULLIF(SUM(order_items), 0), 0)
+		FROM (
+			SELECT SUM(oi.quantity) AS order_items, SUM(oi.quantity * GREATEST(i.needed_num_to_prepare, 1)) AS order_units
+			FROM orders o
+			JOIN order_items oi ON oi.order_id = o.id
+			JOIN items i ON i.id = oi.item_id
+			WHERE o.organization_id = $1
+			GROUP BY o.id
+		) AS past_orders
+	`
+	var profile OrderProfile
+	if err := s.DB.QueryRow(query, org_id).Scan(&profile.AvgItemsPerOrder, &profile.AvgPrepUnits); err != nil {
+		s.Logger.Error("failed to get order profile", "error", err, "organization_id", org_id)
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// MarkPrepared records the items of an order as prepared at the given time, all its items when item_ids is
+// empty. The order is prepared once none of its items is left. Returns sql.ErrNoRows when the order is not
+// in the organization, ErrUnknownOrderItem when an item is not on the order and ErrPreparedBeforeCreated
+// when at is before the order was placed. Items prepared before keep their time.
+func (s *PostgresWaitTimeStore) MarkPrepared(org_id, order_id uuid.UUID, item_ids []uuid.UUID, at time.Time) (*PreparedOrder, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		s.Logger.Error("failed to begin transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var createTime time.Time
+	err = tx.QueryRow(`SELECT create_time FROM orders WHERE id = $1 AND organization_id = $2 FOR UPDATE`, order_id, org_id).Scan(&createTime)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.Logger.Error("failed to get order", "error", err, "order_id", order_id)
+		}
+		return nil, err
+	}
+	if at.Before(createTime) {
+		return nil, ErrPreparedBeforeCreated
+	}
+
+	var result sql.Result
+	if len(item_ids) == 0 {
+		result, err = tx.Exec(`UPDATE order_items SET prepared_at = $2 WHERE order_id = $1 AND prepared_at IS NULL`, order_id, at)
+	} else {
+		var onOrder int
+		err = tx.QueryRow(`SELECT COUNT(DISTINCT item_id) FROM order_items WHERE order_id = $1 AND item_id = ANY($2)`, order_id, pq.Array(item_ids)).Scan(&onOrder)
+		if err != nil {
+			s.Logger.Error("failed to check order items", "error", err, "order_id", order_id)
+			return nil, err
+		}
+		if onOrder != len(uniqueIDs(item_ids)) {
+			return nil, ErrUnknownOrderItem
+		}
+		result, err = tx.Exec(`UPDATE order_items SET prepared_at = $2 WHERE order_id = $1 AND item_id = ANY($3) AND prepared_at IS NULL`, order_id, at, pq.Array(item_ids))
+	}
+	if err != nil {
+		s.Logger.Error("failed to mark order items prepared", "error", err, "order_id", order_id)
+		return nil, err
+	}
+	prepared, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE orders SET prepared_at = $3
+		WHERE id = $1 AND organization_id = $2 AND prepared_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM order_items WHERE order_id = $1 AND prepared_at IS NULL)
+	`, order_id, org_id, at)
+	if err != nil {
+		s.Logger.Error("failed to mark order prepared", "error", err, "order_id", order_id)
+		return nil, err
+	}
+
+	order := PreparedOrder{OrderID: order_id, PreparedItems: int(prepared)}
+	err = tx.QueryRow(`
+		SELECT o.prepared_at, COUNT(oi.item_id) FILTER (WHERE oi.prepared_at IS NULL)
+		FROM orders o
+		LEFT JOIN order_items oi ON oi.order_id = o.id
+		WHERE o.id = $1
+		GROUP BY o.prepared_at
+	`, order_id).Scan(&order.PreparedAt, &order.RemainingItems)
+	if err != nil {
+		s.Logger.Error("failed to get order progress", "error", err, "order_id", order_id)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.Logger.Error("failed to commit transaction", "error", err)
+		return nil, err
+	}
+	return &order, nil
+}
+
+// uniqueIDs drops the repeated IDs
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// roundTenth rounds minutes to a tenth
+func roundTenth(minutes float64) float64 {
+	return math.Round(minutes*10) / 10
+}
+
+// GetRecentPrepTime averages the prep time of the orders prepared since the given time
+func (s *PostgresWaitTimeStore) GetRecentPrepTime(org_id uuid.UUID, since time.Time) (*PrepTime, error) {
+	query := `
+		SELECT COUNT(*), COALESCE(AVG(EXTRACT(EPOCH FROM (prepared_at - create_time)) / 60), 0)
+		FROM orders
+		WHERE organization_id = $1 AND prepared_at >= $2
+	`
+	var prepTime PrepTime
+	if err := s.DB.QueryRow(query, org_id, since).Scan(&prepTime.Prepared, &prepTime.AvgMinutes); err != nil {
+		s.Logger.Error("failed to get recent prep time", "error", err, "organization_id", org_id)
+		return nil, err
+	}
+	prepTime.AvgMinutes = roundTenth(prepTime.AvgMinutes)
+	return &prepTime, nil
+}
+
+// GetItemPrepTimes averages the prep time of every item prepared in orders placed since the given time,
+// slowest first
+func (s *PostgresWaitTimeStore) GetItemPrepTimes(org_id uuid.UUID, since time.Time) ([]ItemPrepTime, error) {
+	query := `
+		SELECT i.id, i.name, COUNT(*), AVG(EXTRACT(EPOCH FROM (oi.prepared_at - o.create_time)) / 60) AS avg_minutes
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		JOIN items i ON i.id = oi.item_id
+		WHERE o.organization_id = $1 AND o.create_time >= $2 AND oi.prepared_at IS NOT NULL
+		GROUP BY i.id, i.name
+		ORDER BY avg_minutes DESC, i.name
+	`
+	rows, err := s.DB.Query(query, org_id, since)
+	if err != nil {
+		s.Logger.Error("failed to get item prep times", "error", err, "organization_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []ItemPrepTime{}
+	for rows.Next() {
+		var item ItemPrepTime
+		if err := rows.Scan(&item.ItemID, &item.Name, &item.Prepared, &item.AvgMinutes); err != nil {
+			s.Logger.Error("failed to scan item prep time", "error", err)
+			return nil, err
+		}
+		item.AvgMinutes = roundTenth(item.AvgMinutes)
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// GetHourlyPrepTimes averages the prep time of the orders placed since the given time per hour of the day
+func (s *PostgresWaitTimeStore) GetHourlyPrepTimes(org_id uuid.UUID, since time.Time) ([]HourPrepTime, error) {
+	query := `
+		SELECT EXTRACT(HOUR FROM create_time)::int AS hour, COUNT(*), AVG(EXTRACT(EPOCH FROM (prepared_at - create_time)) / 60)
+		FROM orders
+		WHERE organization_id = $1 AND create_time >= $2 AND prepared_at IS NOT NULL
+		GROUP BY hour
+		ORDER BY hour
+	`
+	rows, err := s.DB.Query(query, org_id, since)
+	if err != nil {
+		s.Logger.Error("failed to get hourly prep times", "error", err, "organization_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	hours := []HourPrepTime{}
+	for rows.Next() {
+		var hour HourPrepTime
+		if err := rows.Scan(&hour.Hour, &hour.Prepared, &hour.AvgMinutes); err != nil {
+			s.Logger.Error("failed to scan hourly prep time", "error", err)
+			return nil, err
+		}
+		hour.AvgMinutes = roundTenth(hour.AvgMinutes)
+		hours = append(hours, hour)
+	}
+	return hours, rows.Err()
+}
@@ -0,0 +1,373 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Scheduling events an organization can send to its webhooks
+const (
+	WebhookScheduleGenerated = "schedule.generated" // a schedule was generated for the next 7 days
+	WebhookSchedulePublished = "schedule.published" // the shifts of a generated schedule were published to the employees
+	WebhookShiftUpdated      = "shift.updated"      // a shift was created or changed by hand
+	WebhookShiftSwapped      = "shift.swapped"      // an employee took over a shift offered to them
+	WebhookRequestApproved   = "request.approved"   // a holiday, call off or resignation request was fully approved
+)
+
+// WebhookEvents are the events webhooks can subscribe to
+var WebhookEvents = []string{
+	WebhookScheduleGenerated,
+	WebhookSchedulePublished,
+	WebhookShiftUpdated,
+	WebhookShiftSwapped,
+	WebhookRequestApproved,
+}
+
+// States of a webhook delivery
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook posts the events it subscribed to to URL, signed with Secret. The secret is never sent back
+// to clients after the webhook is created.
+type Webhook struct {
+	ID        uuid.UUID  `json:"id"`
+	URL       string     `json:"url"`
+	Secret    string     `json:"-"`
+	Events    []string   `json:"events"`
+	Enabled   bool       `json:"enabled"`
+	CreatedBy *uuid.UUID `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// WebhookDelivery is an event sent, or to be sent, to a webhook. URL and Secret are the webhook's, only
+// set on the deliveries claimed for sending.
+type WebhookDelivery struct {
+	ID             uuid.UUID       `json:"id"`
+	WebhookID      uuid.UUID       `json:"webhook_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus *int            `json:"response_status"`
+	Error          *string         `json:"error"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	URL            string          `json:"-"`
+	Secret         string          `json:"-"`
+}
+
+// WebhookAttempt is the outcome of sending a delivery. A failed attempt is retried at NextAttemptAt,
+// or fails the delivery for good when NextAttemptAt is nil.
+type WebhookAttempt struct {
+	Delivered      bool
+	ResponseStatus *int
+	Error          *string
+	NextAttemptAt  *time.Time
+}
+
+type WebhookStore interface {
+	GetWebhooks(org_id uuid.UUID) ([]Webhook, error)
+	GetWebhook(org_id, webhook_id uuid.UUID) (*Webhook, error)
+	CreateWebhook(org_id uuid.UUID, webhook *Webhook) error
+	UpdateWebhook(org_id uuid.UUID, webhook *Webhook) error
+	DeleteWebhook(org_id, webhook_id uuid.UUID) error
+	GetDeliveries(org_id, webhook_id uuid.UUID, limit int) ([]WebhookDelivery, error)
+
+	QueueEvent(org_id uuid.UUID, event string, payload []byte) (int64, error)
+	ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]WebhookDelivery, error)
+	RecordAttempt(delivery_id uuid.UUID, attempt WebhookAttempt) error
+}
+
+// PostgresWebhookStore seals the webhook secrets with Cipher when it is set
+type PostgresWebhookStore struct {
+	DB     *sql.DB
+	Logger *slog.Logger
+	Cipher FieldCipher
+}
+
+func NewPostgresWebhookStore(DB *sql.DB, Logger *slog.Logger, cipher FieldCipher) *PostgresWebhookStore {
+	return &PostgresWebhookStore{
+		DB:     DB,
+		Logger: Logger,
+		Cipher: cipher,
+	}
+}
+
+const webhookColumns = `id, url, secret, events, enabled, created_by, created_at, updated_at`
+
+func (s *PostgresWebhookStore) scanWebhook(row rowScanner) (*Webhook, error) {
+	var webhook Webhook
+	var secret sql.NullString
+	var events pq.StringArray
+	if err := row.Scan(&webhook.ID, &webhook.URL, &secret, &events, &webhook.Enabled, &webhook.CreatedBy,
+		&webhook.CreatedAt, &webhook.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	opened, err := openString(s.Cipher, secret)
+	if err != nil {
+		return nil, err
+	}
+	if opened != nil {
+		webhook.Secret = *opened
+	}
+	webhook.Events = []string(events)
+	return &webhook, nil
+}
+
+// GetWebhooks lists the webhooks of the organization, oldest first
+func (s *PostgresWebhookStore) GetWebhooks(org_id uuid.UUID) ([]Webhook, error) {
+	rows, err := s.DB.Query(`
+		SELECT `+webhookColumns+`
+		FROM webhooks
+		WHERE organization_id = $1
+		ORDER BY created_at, id
+	`, org_id)
+	if err != nil {
+		s.Logger.Error("failed to get webhooks", "error", err, "org_id", org_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []Webhook{}
+	for rows.Next() {
+		webhook, err := s.scanWebhook(rows)
+		if err != nil {
+			s.Logger.Error("failed to scan webhook", "error", err)
+			return nil, err
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+// GetWebhook returns a webhook of the organization, or sql.ErrNoRows
+func (s *PostgresWebhookStore) GetWebhook(org_id, webhook_id uuid.UUID) (*Webhook, error) {
+	row := s.DB.QueryRow(`
+		SELECT `+webhookColumns+`
+		FROM webhooks
+		WHERE organization_id = $1 AND id = $2
+	`, org_id, webhook_id)
+
+	webhook, err := s.scanWebhook(row)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.Logger.Error("failed to get webhook", "error", err, "webhook_id", webhook_id)
+		}
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// CreateWebhook adds a webhook to the organization, setting its ID and creation time
+func (s *PostgresWebhookStore) CreateWebhook(org_id uuid.UUID, webhook *Webhook) error {
+	secret, err := sealString(s.Cipher, &webhook.Secret)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO webhooks (organization_id, url, secret, events, enabled, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+	err = s.DB.QueryRow(query, org_id, webhook.URL, secret, pq.Array(webhook.Events), webhook.Enabled, webhook.CreatedBy).
+		Scan(&webhook.ID, &webhook.CreatedAt, &webhook.UpdatedAt)
+	if err != nil {
+		s.Logger.Error("failed to create webhook", "error", err, "org_id", org_id)
+		return err
+	}
+
+	s.Logger.Info("webhook created", "org_id", org_id, "webhook_id", webhook.ID, "events", webhook.Events)
+	return nil
+}
+
+// UpdateWebhook replaces the URL, secret, events and state of a webhook, returning sql.ErrNoRows if the
+// organization has no such webhook
+func (s *PostgresWebhookStore) UpdateWebhook(org_id uuid.UUID, webhook *Webhook) error {
+	secret, err := sealString(s.Cipher, &webhook.Secret)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE webhooks SET url = $3, secret = $4, events = $5, enabled = $6, updated_at = NOW()
+		WHERE organization_id = $1 AND id = $2
+		RETURNING updated_at
+	`
+	err = s.DB.QueryRow(query, org_id, webhook.ID, webhook.URL, secret, pq.Array(webhook.Events), webhook.Enabled).
+		Scan(&webhook.UpdatedAt)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.Logger.Error("failed to update webhook", "error", err, "org_id", org_id, "webhook_id", webhook.ID)
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteWebhook removes a webhook and its deliveries, returning sql.ErrNoRows if the organization has
+// no such webhook
+func (s *PostgresWebhookStore) DeleteWebhook(org_id, webhook_id uuid.UUID) error {
+	result, err := s.DB.Exec(`DELETE FROM webhooks WHERE organization_id = $1 AND id = $2`, org_id, webhook_id)
+	if err != nil {
+		s.Logger.Error("failed to delete webhook", "error", err, "org_id", org_id, "webhook_id", webhook_id)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	s.Logger.Info("webhook deleted", "org_id", org_id, "webhook_id", webhook_id)
+	return nil
+}
+
+const webhookDeliveryColumns = `d.id, d.webhook_id, d.event, d.payload, d.status, d.attempts, d.response_status, d.error, d.next_attempt_at, d.created_at, d.delivered_at`
+
+func scanWebhookDelivery(row rowScanner, extra ...any) (*WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	var payload []byte
+	dest := []any{&delivery.ID, &delivery.WebhookID, &delivery.Event, &payload, &delivery.Status, &delivery.Attempts,
+		&delivery.ResponseStatus, &delivery.Error, &delivery.NextAttemptAt, &delivery.CreatedAt, &delivery.DeliveredAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	delivery.Payload = payload
+	return &delivery, nil
+}
+
+// GetDeliveries lists the latest deliveries of a webhook of the organization, newest first
+func (s *PostgresWebhookStore) GetDeliveries(org_id, webhook_id uuid.UUID, limit int) ([]WebhookDelivery, error) {
+	rows, err := s.DB.Query(`
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries d
+		INNER JOIN webhooks w ON d.webhook_id = w.id
+		WHERE w.organization_id = $1 AND d.webhook_id = $2
+		ORDER BY d.created_at DESC, d.id
+		LIMIT $3
+	`, org_id, webhook_id, limit)
+	if err != nil {
+		s.Logger.Error("failed to get webhook deliveries", "error", err, "webhook_id", webhook_id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			s.Logger.Error("failed to scan webhook delivery", "error", err)
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// QueueEvent queues the event for every enabled webhook of the organization subscribed to it and returns
+// how many deliveries were queued
+func (s *PostgresWebhookStore) QueueEvent(org_id uuid.UUID, event string, payload []byte) (int64, error) {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event, payload)
+		SELECT id, $2, $3
+		FROM webhooks
+		WHERE organization_id = $1 AND enabled AND $2 = ANY(events)
+	`
+	result, err := s.DB.Exec(query, org_id, event, payload)
+	if err != nil {
+		s.Logger.Error("failed to queue webhook event", "error", err, "org_id", org_id, "event", event)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ClaimDueDeliveries returns up to limit pending deliveries due at now with the URL and secret of their
+// webhook, pushing their next attempt back by lease so other instances of the API skip them while they
+// are sent. Deliveries of webhooks disabled since they were queued are left pending.
+func (s *PostgresWebhookStore) ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries d SET next_attempt_at = $2
+		FROM webhooks w
+		WHERE d.webhook_id = w.id AND d.id IN (
+			SELECT due.id
+			FROM webhook_deliveries due
+			INNER JOIN webhooks dw ON due.webhook_id = dw.id
+			WHERE due.status = 'pending' AND due.next_attempt_at <= $1 AND dw.enabled
+			ORDER BY due.next_attempt_at
+			LIMIT $3
+			FOR UPDATE OF due SKIP LOCKED
+		)
+		RETURNING ` + webhookDeliveryColumns + `, w.url, w.secret
+	`
+	rows, err := s.DB.Query(query, now, now.Add(lease), limit)
+	if err != nil {
+		s.Logger.Error("failed to claim webhook deliveries", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+	for rows.Next() {
+		var url string
+		var secret sql.NullString
+		delivery, err := scanWebhookDelivery(rows, &url, &secret)
+		if err != nil {
+			s.Logger.Error("failed to scan webhook delivery", "error", err)
+			return nil, err
+		}
+		opened, err := openString(s.Cipher, secret)
+		if err != nil {
+			s.Logger.Error("failed to open webhook secret", "error", err, "webhook_id", delivery.WebhookID)
+			return nil, err
+		}
+		delivery.URL = url
+		if opened != nil {
+			delivery.Secret = *opened
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// RecordAttempt records the outcome of sending a delivery: delivered, pending until its next attempt,
+// or failed
+func (s *PostgresWebhookStore) RecordAttempt(delivery_id uuid.UUID, attempt WebhookAttempt) error {
+	status := WebhookDeliveryFailed
+	if attempt.Delivered {
+		status = WebhookDeliveryDelivered
+	} else if attempt.NextAttemptAt != nil {
+		status = WebhookDeliveryPending
+	}
+
+	query := `
+		UPDATE webhook_deliveries SET
+			status = $2,
+			attempts = attempts + 1,
+			response_status = $3,
+			error = $4,
+			next_attempt_at = COALESCE($5, next_attempt_at),
+			delivered_at = CASE WHEN $2 = 'delivered' THEN NOW() END
+		WHERE id = $1
+	`
+	if _, err := s.DB.Exec(query, delivery_id, status, attempt.ResponseStatus, attempt.Error, attempt.NextAttemptAt); err != nil {
+		s.Logger.Error("failed to record webhook attempt", "error", err, "delivery_id", delivery_id)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/config"
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// customDomainTTL is how long an allowed custom domain is remembered, so preflight requests do not
+// query the database and removed custom domains stop being allowed within a minute
+const customDomainTTL = time.Minute
+
+// CORS allows the origins configured for the deployment and, when enabled, the verified https custom
+// domain of every organization. A "*" origin allows every origin without credentials, for local testing only.
+func CORS(cfg config.CORSConfig, orgStore database.OrgStore, logger *slog.Logger) gin.HandlerFunc {
+	corsConfig := cors.Config{
+		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
+		AllowHeaders:     []string{"Accept", "Authorization", "Content-Type", "Content-Encoding", "X-CSRF-Token"},
+		ExposeHeaders:    []string{"Deprecation", "Sunset", "Link"}, // so the dashboard can warn about retired API versions
+		AllowCredentials: true,
+		AllowWildcard:    true,
+		MaxAge:           cfg.MaxAge,
+	}
+
+	if slices.Contains(cfg.AllowedOrigins, "*") {
+		logger.Warn("CORS allows every origin, do not use this in production")
+		corsConfig.AllowAllOrigins = true
+		corsConfig.AllowCredentials = false
+		return cors.New(corsConfig)
+	}
+
+	corsConfig.AllowOrigins = cfg.AllowedOrigins
+	if cfg.AllowCustomDomains && orgStore != nil {
+		corsConfig.AllowOriginFunc = customDomainOrigins(orgStore, logger)
+	}
+	return cors.New(corsConfig)
+}
+
+// customDomainOrigins is consulted for origins missing from the configured list. Only allowed hosts are
+// remembered, so the cache holds at most the verified custom domains whatever origins clients send.
+func customDomainOrigins(orgStore database.OrgStore, logger *slog.Logger) func(origin string) bool {
+	var mu sync.Mutex
+	allowed := make(map[string]time.Time)
+
+	return func(origin string) bool {
+		parsed, err := url.Parse(origin)
+		if err != nil || parsed.Scheme != "https" || parsed.Hostname() == "" {
+			return false
+		}
+		host := strings.ToLower(parsed.Host)
+
+		mu.Lock()
+		expiresAt, ok := allowed[host]
+		if ok && !time.Now().Before(expiresAt) {
+			delete(allowed, host)
+			ok = false
+		}
+		mu.Unlock()
+		if ok {
+			return true
+		}
+
+		_, err = orgStore.GetOrgIDByCustomDomain(host)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				logger.Error("failed to check custom domain origin", "origin", origin, "error", err)
+			}
+			return false
+		}
+
+		mu.Lock()
+		allowed[host] = time.Now().Add(customDomainTTL)
+		mu.Unlock()
+		return true
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/clockwise/clockwise/backend/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// csrfTokenMaxAge matches the refresh window of the access_token cookie
+const csrfTokenMaxAge = 7 * 24 * 60 * 60
+
+// CSRFProtect rejects unsafe requests authenticated by the access_token cookie unless they echo the
+// CSRF cookie in the CSRF header (double submit cookie). Requests with an Authorization header are not
+// affected, since browsers never attach bearer tokens on their own.
+func CSRFProtect(cfg config.CSRFConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cfg.Enabled || isSafeMethod(c.Request.Method) || c.GetHeader("Authorization") != "" {
+			c.Next()
+			return
+		}
+		if _, err := c.Cookie("access_token"); err != nil {
+			c.Next()
+			return
+		}
+
+		cookie, err := c.Cookie(cfg.CookieName)
+		header := c.GetHeader(cfg.HeaderName)
+		if err != nil || cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) != 1 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Missing or invalid CSRF token"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// IssueCSRFToken sets a new CSRF cookie and returns its value, which the dashboard sends back in the CSRF header
+func IssueCSRFToken(cfg config.CSRFConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate CSRF token"})
+			return
+		}
+		token := base64.RawURLEncoding.EncodeToString(buf)
+
+		c.SetSameSite(http.SameSiteStrictMode)
+		// Readable by the dashboard's scripts on purpose, the value must be copied into the header
+		c.SetCookie(cfg.CookieName, token, csrfTokenMaxAge, "/", "", cfg.SecureCookie, false)
+		c.JSON(http.StatusOK, gin.H{
+			"message": "CSRF token issued",
+			"data":    gin.H{"token": token, "header": cfg.HeaderName},
+		})
+	}
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
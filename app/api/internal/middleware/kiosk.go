@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const kioskDeviceKey = "kiosk_device"
+
+// KioskDevice authenticates shared tablets by the device token in the "Authorization: Kiosk <token>"
+// header. A device only works for the :org organization it was registered for, revoked devices are
+// rejected like unknown ones.
+func KioskDevice(store database.KioskStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Kiosk ")
+		if !ok || strings.TrimSpace(token) == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing kiosk device token"})
+			return
+		}
+
+		orgID, err := uuid.Parse(c.Param("org"))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+			return
+		}
+
+		device, err := store.GetDeviceByToken(strings.TrimSpace(token))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or revoked kiosk device token"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify kiosk device"})
+			return
+		}
+		if device.OrganizationID != orgID {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Access denied: This kiosk device belongs to another organization"})
+			return
+		}
+
+		c.Set(kioskDeviceKey, device)
+		c.Next()
+	}
+}
+
+// KioskDeviceFromContext returns the device authenticated by KioskDevice, nil when there is none
+func KioskDeviceFromContext(c *gin.Context) *database.KioskDevice {
+	if value, ok := c.Get(kioskDeviceKey); ok {
+		if device, ok := value.(*database.KioskDevice); ok {
+			return device
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+var ErrAccountLocked = errors.New("account temporarily locked after too many failed logins, try again later")
+
+// LoginGuard tracks failed logins per account, locks accounts after LOGIN_MAX_ATTEMPTS consecutive
+// failures for LOGIN_LOCKOUT_DURATION and emails users when they log in from a new device or address.
+// Failures, lockouts and new device logins are recorded in the audit log.
+type LoginGuard struct {
+	Store        database.LoginSecurityStore
+	AuditStore   database.AuditStore
+	EmailService service.EmailService
+	MaxAttempts  int
+	Lockout      time.Duration
+	Logger       *slog.Logger
+}
+
+func NewLoginGuard(store database.LoginSecurityStore, auditStore database.AuditStore, emailService service.EmailService, logger *slog.Logger) *LoginGuard {
+	maxAttempts := 5
+	if value := os.Getenv("LOGIN_MAX_ATTEMPTS"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			maxAttempts = parsed
+		}
+	}
+	lockout := 15 * time.Minute
+	if value := os.Getenv("LOGIN_LOCKOUT_DURATION"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			lockout = parsed
+		}
+	}
+
+	return &LoginGuard{
+		Store:        store,
+		AuditStore:   auditStore,
+		EmailService: emailService,
+		MaxAttempts:  maxAttempts,
+		Lockout:      lockout,
+		Logger:       logger,
+	}
+}
+
+// CheckLocked returns ErrAccountLocked while the account is locked
+func (g *LoginGuard) CheckLocked(user *database.User) error {
+	state, err := g.Store.GetLoginState(user.ID)
+	if err != nil {
+		// Do not lock everyone out when the tracking is unavailable
+		g.Logger.Error("failed to check account lockout", "error", err, "user_id", user.ID)
+		return nil
+	}
+	if state.IsLocked(time.Now()) {
+		return ErrAccountLocked
+	}
+	return nil
+}
+
+// LoginFailed counts a wrong password and locks the account once the limit is reached
+func (g *LoginGuard) LoginFailed(c *gin.Context, user *database.User) {
+	state, err := g.Store.RecordFailedLogin(user.ID, g.MaxAttempts, g.Lockout)
+	if err != nil {
+		return
+	}
+
+	g.audit(c, user, database.AuditLoginFailed, map[string]any{"user_agent": c.Request.UserAgent()})
+	if state.IsLocked(time.Now()) {
+		g.Logger.Warn("account locked after failed logins", "user_id", user.ID, "locked_until", state.LockedUntil)
+		g.audit(c, user, database.AuditAccountLocked, map[string]any{
+			"failed_attempts": g.MaxAttempts,
+			"locked_until":    state.LockedUntil,
+		})
+	}
+}
+
+// LoginSucceeded clears the failure counter and warns the user about logins from new devices
+func (g *LoginGuard) LoginSucceeded(c *gin.Context, user *database.User) {
+	g.Store.ResetFailedLogins(user.ID)
+
+	userAgent := c.Request.UserAgent()
+	ipAddress := c.ClientIP()
+	hash := sha256.Sum256([]byte(userAgent))
+
+	isNew, err := g.Store.RememberDevice(user.ID, hex.EncodeToString(hash[:]), ipAddress, userAgent)
+	if err != nil || !isNew {
+		return
+	}
+
+	g.audit(c, user, database.AuditNewDeviceLogin, map[string]any{"user_agent": userAgent})
+
+	loginTime := time.Now().Format("2006-01-02 15:04 MST")
+	go func() {
+		if err := g.EmailService.SendNewLoginEmail(user.Email, user.FullName, userAgent, ipAddress, loginTime); err != nil {
+			g.Logger.Error("failed to send new login email", "error", err, "user_id", user.ID)
+		}
+	}()
+}
+
+func (g *LoginGuard) audit(c *gin.Context, user *database.User, action string, details map[string]any) {
+	g.AuditStore.RecordEvent(&database.AuditEvent{
+		OrganizationID: user.OrganizationID,
+		TargetUserID:   &user.ID,
+		Action:         action,
+		Details:        details,
+		IPAddress:      c.ClientIP(),
+	})
+}
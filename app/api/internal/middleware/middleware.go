@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"database/sql"
 	"net/http"
 	"os"
 	"time"
@@ -15,12 +16,15 @@ import (
 
 var identityKey = "user"
 
+var membershipKey = "membership"
+
 type Login struct {
 	Email    string `form:"email" json:"email" binding:"required"`
 	Password string `form:"password" json:"password" binding:"required"`
 }
 
-func NewAuthMiddleware(userStore database.UserStore) (*jwt.GinJWTMiddleware, error) {
+// NewAuthMiddleware configures JWT authentication. loginGuard may be nil to disable lockouts and new device alerts.
+func NewAuthMiddleware(userStore database.UserStore, loginGuard *LoginGuard) (*jwt.GinJWTMiddleware, error) {
 	return jwt.New(&jwt.GinJWTMiddleware{
 		Realm:             "ClockWise",
 		Key:               []byte(os.Getenv("JWT_SECRET")),
@@ -29,7 +33,7 @@ func NewAuthMiddleware(userStore database.UserStore) (*jwt.GinJWTMiddleware, err
 		IdentityKey:       identityKey,
 		PayloadFunc:       payloadFunc(),
 		IdentityHandler:   identityHandler(),
-		Authenticator:     authenticator(userStore),
+		Authenticator:     authenticator(userStore, loginGuard),
 		Authorizer:        authorizator(),
 		Unauthorized:      unauthorized(),
 		TokenLookup:       "header: Authorization, query: token, cookie: access_token",
@@ -41,7 +45,14 @@ func NewAuthMiddleware(userStore database.UserStore) (*jwt.GinJWTMiddleware, err
 }
 
 func payloadFunc() func(data any) gojwt.MapClaims {
-	return func(data any) gojwt.MapClaims {
+	var userClaims func(data any) gojwt.MapClaims
+	userClaims = func(data any) gojwt.MapClaims {
+		// Tokens issued by /auth/step-up also carry when the user re-authenticated
+		if v, ok := data.(*StepUp); ok {
+			claims := userClaims(v.User)
+			claims[stepUpKey] = v.At.Unix()
+			return claims
+		}
 		if v, ok := data.(*database.User); ok {
 			return gojwt.MapClaims{
 				"id":                       v.ID.String(),
@@ -58,6 +69,7 @@ func payloadFunc() func(data any) gojwt.MapClaims {
 		}
 		return gojwt.MapClaims{}
 	}
+	return userClaims
 }
 
 func identityHandler() func(c *gin.Context) any {
@@ -104,6 +116,10 @@ func identityHandler() func(c *gin.Context) any {
 				OnCall = &i
 			}
 		}
+		if v, ok := claims[stepUpKey].(float64); ok {
+			c.Set(stepUpKey, time.Unix(int64(v), 0))
+		}
+
 		return &database.User{
 			ID:                    uuid.MustParse(claims["id"].(string)),
 			FullName:              claims["full_name"].(string),
@@ -119,7 +135,7 @@ func identityHandler() func(c *gin.Context) any {
 	}
 }
 
-func authenticator(userStore database.UserStore) func(c *gin.Context) (any, error) {
+func authenticator(userStore database.UserStore, loginGuard *LoginGuard) func(c *gin.Context) (any, error) {
 	return func(c *gin.Context) (any, error) {
 		var loginVals Login
 		if err := c.ShouldBind(&loginVals); err != nil {
@@ -133,11 +149,23 @@ func authenticator(userStore database.UserStore) func(c *gin.Context) (any, erro
 			return nil, jwt.ErrFailedAuthentication
 		}
 
+		if loginGuard != nil {
+			if err := loginGuard.CheckLocked(user); err != nil {
+				return nil, err
+			}
+		}
+
 		match, err := user.PasswordHash.Matches(password)
 		if err != nil || !match {
+			if loginGuard != nil {
+				loginGuard.LoginFailed(c, user)
+			}
 			return nil, jwt.ErrFailedAuthentication
 		}
 
+		if loginGuard != nil {
+			loginGuard.LoginSucceeded(c, user)
+		}
 		return user, nil
 	}
 }
@@ -153,6 +181,9 @@ func authorizator() func(c *gin.Context, data any) bool {
 
 func unauthorized() func(c *gin.Context, code int, message string) {
 	return func(c *gin.Context, code int, message string) {
+		if message == ErrAccountLocked.Error() {
+			code = http.StatusLocked
+		}
 		c.JSON(code, gin.H{
 			"message": message,
 		})
@@ -169,7 +200,44 @@ func refreshResponse() func(c *gin.Context, token *core.Token) {
 	}
 }
 
-// ValidateOrgAccess validates that the :org URL parameter matches the user's organization ID.
+// OrgMembership re-checks the user's membership in the :org organization on every request.
+// The organization and role in a token are fixed when the token is issued, by login or by
+// /auth/switch-org, and stay valid until the token expires. Looking the membership up here
+// lets a removed membership or a changed role take effect immediately instead.
+func OrgMembership(membershipStore database.MembershipStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		currentUser, exists := c.Get(identityKey)
+		if !exists {
+			c.Next()
+			return
+		}
+		user := currentUser.(*database.User)
+
+		// Invalid or foreign organizations are reported by ValidateOrgAccess
+		orgID, err := uuid.Parse(c.Param("org"))
+		if err != nil || orgID != user.OrganizationID {
+			c.Next()
+			return
+		}
+
+		membership, err := membershipStore.GetMembership(user.ID, orgID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				c.AbortWithStatusJSON(403, gin.H{"error": "Access denied: You are no longer a member of this organization"})
+				return
+			}
+			c.AbortWithStatusJSON(500, gin.H{"error": "Failed to verify organization membership"})
+			return
+		}
+
+		c.Set(membershipKey, membership)
+		c.Next()
+	}
+}
+
+// ValidateOrgAccess validates that the :org URL parameter matches the organization the user's token is scoped to.
+// A token only grants access to one organization, members of several organizations switch with /auth/switch-org.
+// When OrgMembership has run, the user is returned with their current role in the organization.
 // Returns the user if valid, or sends an error response and returns nil if invalid.
 func ValidateOrgAccess(c *gin.Context) *database.User {
 	currentUser, exists := c.Get("user")
@@ -192,9 +260,17 @@ func ValidateOrgAccess(c *gin.Context) *database.User {
 	}
 
 	if orgID != user.OrganizationID {
-		c.JSON(403, gin.H{"error": "Access denied: You can only access your own organization"})
+		c.JSON(403, gin.H{"error": "Access denied: Switch to this organization with /api/auth/switch-org to access it"})
 		return nil
 	}
 
+	if m, ok := c.Get(membershipKey); ok {
+		if membership, ok := m.(*database.OrganizationMembership); ok && membership.UserRole != user.UserRole {
+			scoped := *user
+			scoped.UserRole = membership.UserRole
+			return &scoped
+		}
+	}
+
 	return user
 }
@@ -0,0 +1,305 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// mlQuotaWindow is the window of the hourly quota of ML calls
+const mlQuotaWindow = time.Hour
+
+// MLGuard softly limits how often the managers of an organization call the ML service. An organization
+// runs at most ML_MAX_CONCURRENT calls at once and ML_HOURLY_QUOTA calls an hour, and an endpoint waits
+// ML_COOLDOWN after a successful call with the same body before it calls the ML service again. A request
+// with the same body as a call of the organization to the endpoint in progress joins the call and gets its
+// response instead of starting a new one. The limits are kept in memory, per API instance.
+type MLGuard struct {
+	MaxConcurrent int
+	HourlyQuota   int
+	Cooldown      time.Duration
+	Logger        *slog.Logger
+
+	mu   sync.Mutex
+	orgs map[uuid.UUID]*mlUsage
+}
+
+// mlUsage is the ML calls of an organization
+type mlUsage struct {
+	running int
+	calls   []time.Time
+	// the calls in progress and the last successful calls, by endpoint and body
+	inFlight map[string]*mlCall
+	lastDone map[string]time.Time
+}
+
+// mlCall is a call in progress, its response is kept for the requests that join it
+type mlCall struct {
+	done        chan struct{}
+	status      int
+	contentType string
+	body        []byte
+}
+
+func NewMLGuard(logger *slog.Logger) *MLGuard {
+	maxConcurrent := 1
+	if value := os.Getenv("ML_MAX_CONCURRENT"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			maxConcurrent = parsed
+		}
+	}
+	hourlyQuota := 20
+	if value := os.Getenv("ML_HOURLY_QUOTA"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			hourlyQuota = parsed
+		}
+	}
+	cooldown := 30 * time.Second
+	if value := os.Getenv("ML_COOLDOWN"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed >= 0 {
+			cooldown = parsed
+		}
+	}
+
+	return &MLGuard{
+		MaxConcurrent: maxConcurrent,
+		HourlyQuota:   hourlyQuota,
+		Cooldown:      cooldown,
+		Logger:        logger,
+		orgs:          make(map[uuid.UUID]*mlUsage),
+	}
+}
+
+// Limit guards a route calling the ML service, endpoint names the call for joining and cooldowns so the
+// versions of the API share them. Every response carries the remaining quota in X-ML-Quota-Limit,
+// X-ML-Quota-Remaining and X-ML-Quota-Reset, and X-ML-Job tells whether the request started a call or
+// joined the one in progress. Calls rejected with a 4xx status do not count against the quota.
+// Requests of other roles are left to the handler to reject.
+func (g *MLGuard) Limit(endpoint string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orgID, ok := mlCaller(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		key, err := mlCallKey(c, endpoint)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+
+		now := time.Now()
+		g.mu.Lock()
+		usage := g.usage(orgID, now)
+
+		if call, ok := usage.inFlight[key]; ok {
+			g.setQuotaHeaders(c, usage, now)
+			g.mu.Unlock()
+			g.join(c, call, endpoint, orgID)
+			return
+		}
+
+		if usage.running >= g.MaxConcurrent {
+			g.setQuotaHeaders(c, usage, now)
+			g.mu.Unlock()
+			g.reject(c, 5*time.Second, "Another ML request of the organization is in progress, try again when it completes")
+			return
+		}
+		if next := usage.lastDone[key].Add(g.Cooldown); now.Before(next) {
+			g.setQuotaHeaders(c, usage, now)
+			g.mu.Unlock()
+			g.reject(c, next.Sub(now), "This was just generated, wait before generating it again")
+			return
+		}
+		if len(usage.calls) >= g.HourlyQuota {
+			g.setQuotaHeaders(c, usage, now)
+			g.mu.Unlock()
+			g.reject(c, usage.calls[0].Add(mlQuotaWindow).Sub(now), "The hourly quota of ML requests of the organization is used up")
+			return
+		}
+
+		call := &mlCall{done: make(chan struct{})}
+		usage.running++
+		usage.calls = append(usage.calls, now)
+		usage.inFlight[key] = call
+		g.setQuotaHeaders(c, usage, now)
+		g.mu.Unlock()
+
+		c.Header("X-ML-Job", "started")
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		defer func() {
+			c.Writer = writer.ResponseWriter
+			g.finish(orgID, endpoint, key, call, writer, now)
+		}()
+		c.Next()
+	}
+}
+
+// mlCallKey names the call of a request for joining and cooldowns, the endpoint and the SHA-256 of the
+// body with insignificant JSON whitespace removed. The body is put back for the handler.
+func mlCallKey(c *gin.Context, endpoint string) (string, error) {
+	if c.Request.Body == nil {
+		return endpoint, nil
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	c.Request.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if len(body) == 0 {
+		return endpoint, nil
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, body); err == nil {
+		body = compact.Bytes()
+	}
+	sum := sha256.Sum256(body)
+	return endpoint + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// mlCaller returns the organization of a request the guard applies to, those of admins and managers
+// of the :org organization
+func mlCaller(c *gin.Context) (uuid.UUID, bool) {
+	currentUser, exists := c.Get(identityKey)
+	if !exists {
+		return uuid.Nil, false
+	}
+	user := currentUser.(*database.User)
+	orgID, err := uuid.Parse(c.Param("org"))
+	if err != nil || orgID != user.OrganizationID {
+		return uuid.Nil, false
+	}
+
+	role := user.UserRole
+	if m, ok := c.Get(membershipKey); ok {
+		if membership, ok := m.(*database.OrganizationMembership); ok {
+			role = membership.UserRole
+		}
+	}
+	return orgID, role == "admin" || role == "manager"
+}
+
+// usage returns the calls of the organization with the calls that left the quota window dropped.
+// g.mu must be held.
+func (g *MLGuard) usage(orgID uuid.UUID, now time.Time) *mlUsage {
+	usage, ok := g.orgs[orgID]
+	if !ok {
+		usage = &mlUsage{inFlight: make(map[string]*mlCall), lastDone: make(map[string]time.Time)}
+		g.orgs[orgID] = usage
+	}
+	expired := 0
+	for expired < len(usage.calls) && !now.Before(usage.calls[expired].Add(mlQuotaWindow)) {
+		expired++
+	}
+	usage.calls = usage.calls[expired:]
+	for key, at := range usage.lastDone {
+		if !now.Before(at.Add(g.Cooldown)) {
+			delete(usage.lastDone, key)
+		}
+	}
+	return usage
+}
+
+// setQuotaHeaders must be called with g.mu held
+func (g *MLGuard) setQuotaHeaders(c *gin.Context, usage *mlUsage, now time.Time) {
+	reset := now
+	if len(usage.calls) > 0 {
+		reset = usage.calls[0].Add(mlQuotaWindow)
+	}
+	c.Header("X-ML-Quota-Limit", strconv.Itoa(g.HourlyQuota))
+	c.Header("X-ML-Quota-Remaining", strconv.Itoa(max(g.HourlyQuota-len(usage.calls), 0)))
+	c.Header("X-ML-Quota-Reset", strconv.FormatInt(reset.Unix(), 10))
+}
+
+func (g *MLGuard) reject(c *gin.Context, retryAfter time.Duration, message string) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+		"error":       message,
+		"retry_after": seconds,
+	})
+}
+
+// join waits for the call in progress and answers with its response
+func (g *MLGuard) join(c *gin.Context, call *mlCall, endpoint string, orgID uuid.UUID) {
+	g.Logger.Info("joining ML request in progress", "org_id", orgID, "endpoint", endpoint)
+	select {
+	case <-call.done:
+	case <-c.Request.Context().Done():
+		c.Abort()
+		return
+	}
+
+	// only the type is copied, encodings such as gzip are negotiated per request
+	c.Header("Content-Type", call.contentType)
+	c.Header("X-ML-Job", "joined")
+	c.Writer.WriteHeader(call.status)
+	c.Writer.Write(call.body)
+	c.Abort()
+}
+
+// finish releases the call and hands its response to the requests that joined it
+func (g *MLGuard) finish(orgID uuid.UUID, endpoint, key string, call *mlCall, writer *recordingWriter, started time.Time) {
+	call.status = writer.Status()
+	if !writer.Written() {
+		// the handler panicked, the recovery middleware answers with a 500
+		call.status = http.StatusInternalServerError
+	}
+	call.contentType = writer.Header().Get("Content-Type")
+	call.body = writer.body.Bytes()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	usage := g.orgs[orgID]
+	usage.running--
+	delete(usage.inFlight, key)
+	switch {
+	case call.status >= 200 && call.status < 300:
+		usage.lastDone[key] = time.Now()
+	case call.status >= 400 && call.status < 500:
+		// rejected before the ML service was called, the call is given back
+		for i, at := range usage.calls {
+			if at.Equal(started) {
+				usage.calls = append(usage.calls[:i], usage.calls[i+1:]...)
+				break
+			}
+		}
+	}
+	close(call.done)
+
+	g.Logger.Debug("ML request completed", "org_id", orgID, "endpoint", endpoint, "status", call.status,
+		"duration", time.Since(started))
+}
+
+// recordingWriter writes the response through and keeps a copy of its body
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+var stepUpKey = "step_up_at"
+
+// defaultStepUpRoutes are the routes that need a recent step-up unless STEP_UP_ROUTES is set. Routes are
+// written as the method and the path below the version prefix.
+var defaultStepUpRoutes = []string{
+	"DELETE /:org/staffing/employees/:id/layoff",
+	"DELETE /:org/staffing/members/:id",
+	"PUT /:org/staffing/employees/:id/compliance",
+	"PUT /:org/me/compliance",
+	"PUT /:org/exports/encryption",
+	"DELETE /:org/exports/encryption",
+	"DELETE /auth/totp",
+}
+
+// StepUp is the token data of a user who re-authenticated at At, with their password or a TOTP code
+type StepUp struct {
+	User *database.User
+	At   time.Time
+}
+
+// SteppedUpAt returns when the user of the request last re-authenticated, false when their token
+// carries no step-up
+func SteppedUpAt(c *gin.Context) (time.Time, bool) {
+	value, ok := c.Get(stepUpKey)
+	if !ok {
+		return time.Time{}, false
+	}
+	at, ok := value.(time.Time)
+	return at, ok
+}
+
+// StepUpGuard makes users re-authenticate before sensitive actions. The routes in STEP_UP_ROUTES, a
+// comma-separated list of "METHOD /path" with the path as registered below the version prefix (the
+// layoffs, member removals, bank detail changes and export encryption changes by default), are refused
+// unless the token of the user was issued by /auth/step-up within the last STEP_UP_WINDOW (10m).
+type StepUpGuard struct {
+	Window time.Duration
+	Routes map[string]bool
+	Logger *slog.Logger
+}
+
+func NewStepUpGuard(logger *slog.Logger) *StepUpGuard {
+	window := 10 * time.Minute
+	if value := os.Getenv("STEP_UP_WINDOW"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			window = parsed
+		}
+	}
+	routes := defaultStepUpRoutes
+	if value := os.Getenv("STEP_UP_ROUTES"); value != "" {
+		routes = strings.Split(value, ",")
+	}
+
+	guard := &StepUpGuard{
+		Window: window,
+		Routes: make(map[string]bool),
+		Logger: logger,
+	}
+	for _, route := range routes {
+		if route = strings.Join(strings.Fields(route), " "); route != "" {
+			guard.Routes[route] = true
+		}
+	}
+	return guard
+}
+
+// Enforce refuses the protected routes of the API mounted at prefix to users without a recent step-up.
+// It runs after authentication.
+func (g *StepUpGuard) Enforce(prefix string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + strings.TrimPrefix(c.FullPath(), prefix)
+		if !g.Routes[route] {
+			c.Next()
+			return
+		}
+
+		if g.Recent(c) {
+			c.Next()
+			return
+		}
+
+		if user, ok := c.Get(identityKey); ok {
+			if u, ok := user.(*database.User); ok {
+				g.Logger.Warn("step-up required", "user_id", u.ID, "route", route)
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error":            "Confirm your password or authenticator code with /auth/step-up to continue",
+			"step_up_required": true,
+		})
+	}
+}
+
+// Recent reports whether the user of the request re-authenticated within the window
+func (g *StepUpGuard) Recent(c *gin.Context) bool {
+	at, ok := SteppedUpAt(c)
+	return ok && time.Since(at) <= g.Window
+}
+
+// ExpiresAt is when a step-up made at at stops unlocking the protected routes
+func (g *StepUpGuard) ExpiresAt(at time.Time) time.Time {
+	return at.Add(g.Window)
+}
@@ -0,0 +1,235 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// UploadSplitHint tells clients what to do with uploads that exceed a limit
+const UploadSplitHint = "Split the file into smaller CSV files and upload them one after another"
+
+// multipartMemory is how much of an upload is kept in memory, the rest is buffered on disk
+const multipartMemory = 1 << 20
+
+var csvContentTypes = map[string]bool{
+	"":                         true, // not all clients send a type for file parts
+	"text/csv":                 true,
+	"application/csv":          true,
+	"text/plain":               true,
+	"application/vnd.ms-excel": true,
+	"application/octet-stream": true,
+}
+
+var xlsxContentTypes = map[string]bool{
+	"": true,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": true,
+	"application/zip":          true,
+	"application/octet-stream": true,
+}
+
+var imageContentTypes = map[string]bool{
+	"":                         true,
+	"image/png":                true,
+	"image/jpeg":               true,
+	"application/octet-stream": true,
+}
+
+// sniffedContentTypes are the contents ScanUploads accepts for the images of an image upload
+var sniffedContentTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+}
+
+// isSpreadsheetUpload reports whether a file part is a CSV file or an XLSX workbook by its name and type
+func isSpreadsheetUpload(file *multipart.FileHeader) bool {
+	partType, _, _ := mime.ParseMediaType(file.Header.Get("Content-Type"))
+	switch strings.ToLower(filepath.Ext(file.Filename)) {
+	case ".csv":
+		return csvContentTypes[partType]
+	case ".xlsx":
+		return xlsxContentTypes[partType]
+	}
+	return false
+}
+
+// isImageUpload reports whether a file part is a PNG or JPEG image by its name and type
+func isImageUpload(file *multipart.FileHeader) bool {
+	partType, _, _ := mime.ParseMediaType(file.Header.Get("Content-Type"))
+	if _, ok := sniffedContentTypes[strings.ToLower(filepath.Ext(file.Filename))]; !ok {
+		return false
+	}
+	return imageContentTypes[partType]
+}
+
+// LimitUpload guards a CSV upload route. It rejects requests that are not multipart forms (415),
+// bodies larger than the limit (413) and file parts that are not CSV files or XLSX workbooks (415).
+// The limit is defaultMaxMB megabytes unless the environment variable envKey overrides it.
+func LimitUpload(envKey string, defaultMaxMB int64) gin.HandlerFunc {
+	return limitUpload(envKey, defaultMaxMB, isSpreadsheetUpload, "Only CSV and XLSX files can be uploaded", UploadSplitHint)
+}
+
+// LimitImageUpload guards an image upload route like LimitUpload, for PNG and JPEG images
+func LimitImageUpload(envKey string, defaultMaxMB int64) gin.HandlerFunc {
+	return limitUpload(envKey, defaultMaxMB, isImageUpload, "Only PNG and JPEG images can be uploaded", "")
+}
+
+func limitUpload(envKey string, defaultMaxMB int64, accepted func(*multipart.FileHeader) bool, rejected, hint string) gin.HandlerFunc {
+	maxMB := defaultMaxMB
+	if value := os.Getenv(envKey); value != "" {
+		if parsed, err := strconv.ParseInt(value, 10, 64); err == nil && parsed > 0 {
+			maxMB = parsed
+		}
+	}
+	maxBytes := maxMB << 20
+
+	return func(c *gin.Context) {
+		mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+		if err != nil || mediaType != "multipart/form-data" {
+			c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{"error": "Uploads must be sent as multipart/form-data"})
+			return
+		}
+
+		if c.Request.ContentLength > maxBytes {
+			abortUploadTooLarge(c, maxMB, hint)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		if err := c.Request.ParseMultipartForm(multipartMemory); err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				abortUploadTooLarge(c, maxMB, hint)
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid multipart form"})
+			return
+		}
+
+		for _, files := range c.Request.MultipartForm.File {
+			for _, file := range files {
+				if !accepted(file) {
+					c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{"error": rejected})
+					return
+				}
+			}
+		}
+
+		c.Next()
+	}
+}
+
+func abortUploadTooLarge(c *gin.Context, maxMB int64, hint string) {
+	body := gin.H{"error": fmt.Sprintf("File too large, uploads to this endpoint are limited to %d MB", maxMB)}
+	if hint != "" {
+		body["hint"] = hint
+	}
+	c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, body)
+}
+
+// ScanUploads checks every file of a multipart form parsed by LimitUpload or LimitImageUpload before the
+// handler runs. Files whose content is not text, a zip archive for XLSX workbooks or the image their name
+// says for PNG and JPEG images, are rejected (415), files flagged by the scanner are rejected (422) and
+// uploads are refused while the scanner is unavailable (503). Every verdict is logged.
+func ScanUploads(scanner service.FileScanner, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.MultipartForm == nil {
+			c.Next()
+			return
+		}
+
+		var userID any
+		if currentUser, ok := c.Get(identityKey); ok {
+			userID = currentUser.(*database.User).ID
+		}
+
+		for _, files := range c.Request.MultipartForm.File {
+			for _, header := range files {
+				status, message := scanUpload(c, scanner, logger, header, userID)
+				if status != http.StatusOK {
+					c.AbortWithStatusJSON(status, gin.H{"error": message})
+					return
+				}
+			}
+		}
+
+		c.Next()
+	}
+}
+
+func scanUpload(c *gin.Context, scanner service.FileScanner, logger *slog.Logger, header *multipart.FileHeader, userID any) (int, string) {
+	file, err := header.Open()
+	if err != nil {
+		return http.StatusBadRequest, "Failed to read uploaded file"
+	}
+	defer file.Close()
+
+	sniff := make([]byte, 512)
+	n, err := io.ReadFull(file, sniff)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return http.StatusBadRequest, "Failed to read uploaded file"
+	}
+	if n > 0 {
+		detected := http.DetectContentType(sniff[:n])
+		extension := strings.ToLower(filepath.Ext(header.Filename))
+		if image, ok := sniffedContentTypes[extension]; ok {
+			if detected != image {
+				logger.Warn("upload rejected by content sniffing", "user_id", userID, "filename", header.Filename, "detected", detected)
+				return http.StatusUnsupportedMediaType, "Only PNG and JPEG images can be uploaded"
+			}
+		} else if workbook := extension == ".xlsx" && detected == "application/zip"; !workbook && !strings.HasPrefix(detected, "text/") {
+			logger.Warn("upload rejected by content sniffing", "user_id", userID, "filename", header.Filename, "detected", detected)
+			return http.StatusUnsupportedMediaType, "Only CSV and XLSX files can be uploaded"
+		}
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return http.StatusBadRequest, "Failed to read uploaded file"
+	}
+
+	hash := sha256.New()
+	result, err := scanner.Scan(c.Request.Context(), teeFile{File: file, reader: io.TeeReader(file, hash)})
+	if err != nil {
+		logger.Error("failed to scan upload", "error", err, "user_id", userID, "filename", header.Filename)
+		return http.StatusServiceUnavailable, "File scanning is unavailable, please try again later"
+	}
+
+	logger.Info("upload scanned",
+		"user_id", userID,
+		"path", c.FullPath(),
+		"filename", header.Filename,
+		"size", header.Size,
+		"sha256", hex.EncodeToString(hash.Sum(nil)),
+		"scanner", result.Scanner,
+		"clean", result.Clean,
+		"signature", result.Signature,
+	)
+	if !result.Clean {
+		return http.StatusUnprocessableEntity, "File rejected by virus scan"
+	}
+	return http.StatusOK, ""
+}
+
+// teeFile hashes a file while the scanner reads it
+type teeFile struct {
+	multipart.File
+	reader io.Reader
+}
+
+func (f teeFile) Read(p []byte) (int, error) {
+	return f.reader.Read(p)
+}
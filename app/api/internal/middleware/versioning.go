@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const apiVersionKey = "api_version"
+
+// ResponseShim rewrites the decoded JSON body of a successful response into the shape an older
+// version of the API returned
+type ResponseShim func(body any) any
+
+// APIVersion is a version of the API mounted under its own prefix
+type APIVersion struct {
+	// Name is what handlers see through RequestAPIVersion, such as "v1"
+	Name string
+	// Prefix is where the version is mounted, such as "/api/v1"
+	Prefix string
+	// Successor is the prefix of the version replacing this one, linked from deprecated versions
+	Successor string
+	// Deprecated and Sunset are sent in the Deprecation (RFC 9745) and Sunset (RFC 8594) headers when set
+	Deprecated time.Time
+	Sunset     time.Time
+	// Shims are keyed by method and route below the prefix, such as "GET /:org/branding"
+	Shims map[string]ResponseShim
+}
+
+// Versioned tags the requests of a version of the API, announces its deprecation and applies the response
+// shims of the route, so handlers only ever write the latest response shape
+func Versioned(version APIVersion) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(apiVersionKey, version.Name)
+
+		if !version.Deprecated.IsZero() {
+			c.Header("Deprecation", fmt.Sprintf("@%d", version.Deprecated.Unix()))
+			if version.Successor != "" {
+				successor := version.Successor + strings.TrimPrefix(c.Request.URL.Path, version.Prefix)
+				c.Header("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+			}
+		}
+		if !version.Sunset.IsZero() {
+			c.Header("Sunset", version.Sunset.UTC().Format(http.TimeFormat))
+		}
+
+		shim, ok := version.Shims[c.Request.Method+" "+strings.TrimPrefix(c.FullPath(), version.Prefix)]
+		if !ok {
+			c.Next()
+			return
+		}
+
+		writer := &shimWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+		writer.flush(shim)
+	}
+}
+
+// RequestAPIVersion returns the version of the API the request was sent to, for handlers whose behavior
+// differs between versions
+func RequestAPIVersion(c *gin.Context) string {
+	return c.GetString(apiVersionKey)
+}
+
+// shimWriter holds the response back until the shim has rewritten it
+type shimWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *shimWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *shimWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// flush writes the response, rewritten by the shim when it is a successful JSON response
+func (w *shimWriter) flush(shim ResponseShim) {
+	body := w.body.Bytes()
+
+	mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	if mediaType == "application/json" && w.Status() >= 200 && w.Status() < 300 {
+		var decoded any
+		if err := json.Unmarshal(body, &decoded); err == nil {
+			if rewritten, err := json.Marshal(shim(decoded)); err == nil {
+				body = rewritten
+			}
+		}
+	}
+
+	if len(body) == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	w.ResponseWriter.Write(body)
+}
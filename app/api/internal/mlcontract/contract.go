@@ -0,0 +1,193 @@
+// Package mlcontract holds the golden JSON fixtures of the requests the API sends to the ML service and
+// of the responses it reads back. Both sides check themselves against the same fixtures: the Go side
+// decodes them into the types the handlers marshal and unmarshal, the ML service validates them against
+// its pydantic models (app/ml/tests/test_ml_contract.py). A field renamed or added on one side only fails
+// one of the two checks.
+package mlcontract
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"sort"
+
+	"github.com/clockwise/clockwise/backend/internal/mlproto"
+)
+
+//go:embed fixtures
+var embedded embed.FS
+
+// Fixtures are the contract fixtures, contracts.json lists them per endpoint
+var Fixtures, _ = fs.Sub(embedded, "fixtures")
+
+// Contract pairs an ML endpoint with its request and response fixtures
+type Contract struct {
+	Endpoint string `json:"endpoint"`
+	Request  string `json:"request"`
+	Response string `json:"response"`
+	// IgnoredByService are the request fields the API sends that the ML service does not read yet, as
+	// paths like orders[].channel. Only the ML side uses them.
+	IgnoredByService []string `json:"ignored_by_service"`
+}
+
+// Drift is a difference between a fixture and the Go types of its endpoint
+type Drift struct {
+	Endpoint string
+	Fixture  string
+	Path     string
+	Problem  string
+}
+
+func (d Drift) String() string {
+	if d.Path == "" {
+		return fmt.Sprintf("%s (%s): %s", d.Endpoint, d.Fixture, d.Problem)
+	}
+	return fmt.Sprintf("%s (%s): %s: %s", d.Endpoint, d.Fixture, d.Path, d.Problem)
+}
+
+// goTypes are the types the handlers marshal the requests from and unmarshal the responses into
+var goTypes = map[string]struct {
+	Request  func() any
+	Response func() any
+}{
+	"/predict/schedule": {
+		Request:  func() any { return &mlproto.SchedulePredictRequest{} },
+		Response: func() any { return &mlproto.GenerateScheduleResponse{} },
+	},
+	"/predict/demand": {
+		Request:  func() any { return &mlproto.DemandPredictionRequest{} },
+		Response: func() any { return &mlproto.DemandPredictResponse{} },
+	},
+	"/recommend/campaigns": {
+		Request:  func() any { return &mlproto.CampaignRecommendationRequest{} },
+		Response: func() any { return &mlproto.CampaignRecommendationResponse{} },
+	},
+}
+
+// Load reads the contracts listed in contracts.json of fsys
+func Load(fsys fs.FS) ([]Contract, error) {
+	data, err := fs.ReadFile(fsys, "contracts.json")
+	if err != nil {
+		return nil, err
+	}
+	var contracts []Contract
+	if err := json.Unmarshal(data, &contracts); err != nil {
+		return nil, fmt.Errorf("invalid contracts.json: %w", err)
+	}
+	return contracts, nil
+}
+
+// Verify checks the embedded fixtures against the Go types
+func Verify() ([]Drift, error) {
+	return VerifyFS(Fixtures)
+}
+
+// VerifyFS checks the fixtures of fsys against the Go types. A request fixture drifts when it has a
+// field the Go type does not know or the Go type sends a field the fixture does not have, a response
+// fixture drifts when it does not decode or misses a field the Go type reads. Fields of the response
+// the API ignores are fine, the handlers decode leniently too.
+func VerifyFS(fsys fs.FS) ([]Drift, error) {
+	contracts, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []Drift
+	covered := make(map[string]bool, len(contracts))
+	for _, contract := range contracts {
+		covered[contract.Endpoint] = true
+		types, ok := goTypes[contract.Endpoint]
+		if !ok {
+			drifts = append(drifts, Drift{Endpoint: contract.Endpoint, Fixture: "contracts.json", Problem: "no Go types for the endpoint"})
+			continue
+		}
+
+		request, err := fs.ReadFile(fsys, contract.Request)
+		if err != nil {
+			return nil, err
+		}
+		drifts = append(drifts, compare(contract.Endpoint, contract.Request, request, types.Request(), true)...)
+
+		response, err := fs.ReadFile(fsys, contract.Response)
+		if err != nil {
+			return nil, err
+		}
+		drifts = append(drifts, compare(contract.Endpoint, contract.Response, response, types.Response(), false)...)
+	}
+
+	for endpoint := range goTypes {
+		if !covered[endpoint] {
+			drifts = append(drifts, Drift{Endpoint: endpoint, Fixture: "contracts.json", Problem: "no fixtures for the endpoint"})
+		}
+	}
+	sort.SliceStable(drifts, func(i, j int) bool { return drifts[i].Endpoint < drifts[j].Endpoint })
+	return drifts, nil
+}
+
+// compare decodes the fixture into target, strictly for requests, and reports the fields the Go type
+// encodes back that the fixture does not have
+func compare(endpoint, fixture string, data []byte, target any, strict bool) []Drift {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(target); err != nil {
+		return []Drift{{Endpoint: endpoint, Fixture: fixture, Problem: fmt.Sprintf("does not decode: %s", err)}}
+	}
+
+	encoded, err := json.Marshal(target)
+	if err != nil {
+		return []Drift{{Endpoint: endpoint, Fixture: fixture, Problem: fmt.Sprintf("does not encode back: %s", err)}}
+	}
+
+	var want, got any
+	json.Unmarshal(data, &want)
+	json.Unmarshal(encoded, &got)
+	have := make(map[string]bool)
+	fieldPaths("", want, have)
+	goPaths := make(map[string]bool)
+	fieldPaths("", got, goPaths)
+
+	problem := "read by the API but missing from the fixture"
+	if strict {
+		problem = "sent by the API but missing from the fixture"
+	}
+	var drifts []Drift
+	for _, p := range sortedKeys(goPaths) {
+		if !have[p] {
+			drifts = append(drifts, Drift{Endpoint: endpoint, Fixture: fixture, Path: p, Problem: problem})
+		}
+	}
+	return drifts
+}
+
+// fieldPaths collects the paths of the object fields in v, array elements collapsed to [] so every
+// element shares the paths of the first one
+func fieldPaths(prefix string, v any, paths map[string]bool) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			p := key
+			if prefix != "" {
+				p = prefix + "." + key
+			}
+			paths[p] = true
+			fieldPaths(p, value, paths)
+		}
+	case []any:
+		for _, value := range v {
+			fieldPaths(prefix+"[]", value, paths)
+		}
+	}
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
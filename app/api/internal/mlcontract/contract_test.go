@@ -0,0 +1,90 @@
+package mlcontract
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixturesMatchGoTypes(t *testing.T) {
+	drifts, err := Verify()
+	require.NoError(t, err)
+	assert.Empty(t, drifts)
+}
+
+// tampered copies the embedded fixtures with one of them rewritten
+func tampered(t *testing.T, name, old, new string) fs.FS {
+	t.Helper()
+	fsys := fstest.MapFS{}
+	err := fs.WalkDir(Fixtures, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(Fixtures, p)
+		if err != nil {
+			return err
+		}
+		if p == name {
+			require.Contains(t, string(data), old)
+			data = []byte(strings.Replace(string(data), old, new, 1))
+		}
+		fsys[p] = &fstest.MapFile{Data: data}
+		return nil
+	})
+	require.NoError(t, err)
+	return fsys
+}
+
+func TestVerifyFS_DetectsDrift(t *testing.T) {
+	t.Run("request field renamed by the ML side", func(t *testing.T) {
+		fsys := tampered(t, "predict_schedule.request.json", `"pref_hours"`, `"preferred_hours_per_week"`)
+		drifts, err := VerifyFS(fsys)
+		require.NoError(t, err)
+		require.NotEmpty(t, drifts)
+		assert.Contains(t, drifts[0].Problem, "does not decode")
+	})
+
+	t.Run("campaign request field misspelled", func(t *testing.T) {
+		fsys := tampered(t, "recommend_campaigns.request.json", `"discount_amount"`, `"discount_amont"`)
+		drifts, err := VerifyFS(fsys)
+		require.NoError(t, err)
+		require.Len(t, drifts, 1)
+		assert.Contains(t, drifts[0].Problem, "does not decode")
+	})
+
+	t.Run("request field missing from the fixture", func(t *testing.T) {
+		fsys := tampered(t, "predict_demand.request.json", `"fixed_shifts": true,`, ``)
+		drifts, err := VerifyFS(fsys)
+		require.NoError(t, err)
+		require.Len(t, drifts, 1)
+		assert.Equal(t, "place.fixed_shifts", drifts[0].Path)
+		assert.Contains(t, drifts[0].Problem, "sent by the API")
+	})
+
+	t.Run("response field read by the API missing", func(t *testing.T) {
+		fsys := tampered(t, "recommend_campaigns.response.json", `"confidence_level"`, `"confidence"`)
+		drifts, err := VerifyFS(fsys)
+		require.NoError(t, err)
+		require.Len(t, drifts, 1)
+		assert.Equal(t, "confidence_level", drifts[0].Path)
+		assert.Contains(t, drifts[0].Problem, "read by the API")
+	})
+
+	t.Run("response field the API does not read", func(t *testing.T) {
+		fsys := tampered(t, "predict_demand.response.json", `"restaurant_name"`, `"model_version": "v6", "restaurant_name"`)
+		drifts, err := VerifyFS(fsys)
+		require.NoError(t, err)
+		assert.Empty(t, drifts)
+	})
+
+	t.Run("endpoint without fixtures", func(t *testing.T) {
+		fsys := tampered(t, "contracts.json", `"/recommend/campaigns"`, `"/recommend/campaigns/feedback"`)
+		drifts, err := VerifyFS(fsys)
+		require.NoError(t, err)
+		require.Len(t, drifts, 2)
+	})
+}
@@ -0,0 +1,97 @@
+package mlproto
+
+import "github.com/clockwise/clockwise/backend/internal/database"
+
+// CampaignRecommendationRequest is the body of POST /recommend/campaigns
+type CampaignRecommendationRequest struct {
+	Place                   CampaignPlace            `json:"place"`
+	Orders                  []CampaignOrder          `json:"orders"`
+	Campaigns               []PastCampaign           `json:"campaigns"`
+	OrderItems              []CampaignOrderItem      `json:"order_items"`
+	RecommendationStartDate string                   `json:"recommendation_start_date"`
+	NumRecommendations      int                      `json:"num_recommendations"`
+	OptimizeFor             string                   `json:"optimize_for"`
+	MaxDiscount             float64                  `json:"max_discount"`
+	MinCampaignDurationDays int                      `json:"min_campaign_duration_days"`
+	MaxCampaignDurationDays int                      `json:"max_campaign_duration_days"`
+	AvailableItems          []string                 `json:"available_items"`
+	Events                  []database.ExternalEvent `json:"events"`
+}
+
+// CampaignPlace is the organization as the campaign recommender sees it, its opening hours keyed by
+// weekday and the phone orders sent as "enabled" or "disabled"
+type CampaignPlace struct {
+	PlaceID              string                          `json:"place_id"`
+	PlaceName            string                          `json:"place_name"`
+	Latitude             float64                         `json:"latitude"`
+	Longitude            float64                         `json:"longitude"`
+	Type                 string                          `json:"type"`
+	Delivery             bool                            `json:"delivery"`
+	ReceivingPhone       string                          `json:"receiving_phone"`
+	OpeningHours         map[string]CampaignOpeningHours `json:"opening_hours"`
+	FixedShifts          bool                            `json:"fixed_shifts"`
+	NumberOfShiftsPerDay int                             `json:"number_of_shifts_per_day"`
+	WaitingTime          int                             `json:"waiting_time"`
+}
+
+type CampaignOpeningHours struct {
+	Weekday     string `json:"weekday"`
+	OpeningTime string `json:"opening_time"`
+	ClosingTime string `json:"closing_time"`
+}
+
+// CampaignOrder is a past order, Items is the number of items in it
+type CampaignOrder struct {
+	Time           string  `json:"time"`
+	TotalAmount    float64 `json:"total_amount"`
+	Items          int     `json:"items"`
+	Status         string  `json:"status"`
+	DiscountAmount float64 `json:"discount_amount"`
+}
+
+// PastCampaign is a campaign the organization ran, with the names of its items
+type PastCampaign struct {
+	StartTime     string   `json:"start_time"`
+	EndTime       string   `json:"end_time"`
+	ItemsIncluded []string `json:"items_included"`
+	Discount      float64  `json:"discount"`
+}
+
+type CampaignOrderItem struct {
+	OrderID  string `json:"order_id"`
+	ItemID   string `json:"item_id"`
+	Quantity int    `json:"quantity"`
+}
+
+type RecommendedCampaignItem struct {
+	CampaignID            string         `json:"campaign_id"`
+	Items                 []string       `json:"items"`
+	DiscountPercentage    float64        `json:"discount_percentage"`
+	StartDate             string         `json:"start_date"`
+	EndDate               string         `json:"end_date"`
+	DurationDays          int            `json:"duration_days"`
+	ExpectedUplift        float64        `json:"expected_uplift"`
+	ExpectedROI           float64        `json:"expected_roi"`
+	ExpectedRevenue       float64        `json:"expected_revenue"`
+	ConfidenceScore       float64        `json:"confidence_score"`
+	Reasoning             string         `json:"reasoning"`
+	PriorityScore         float64        `json:"priority_score"`
+	RecommendedForContext map[string]any `json:"recommended_for_context"`
+}
+
+// CampaignRecommendationResponse is the response of POST /recommend/campaigns
+type CampaignRecommendationResponse struct {
+	RestaurantName     string                    `json:"restaurant_name"`
+	RecommendationDate string                    `json:"recommendation_date"`
+	Recommendations    []RecommendedCampaignItem `json:"recommendations"`
+	AnalysisSummary    map[string]any            `json:"analysis_summary"`
+	Insights           map[string]any            `json:"insights"`
+	ConfidenceLevel    string                    `json:"confidence_level"`
+}
+
+// CampaignFeedbackResponse is the response of POST /recommend/campaigns/feedback
+type CampaignFeedbackResponse struct {
+	Status            string         `json:"status"`
+	Message           string         `json:"message"`
+	UpdatedParameters map[string]any `json:"updated_parameters,omitempty"`
+}
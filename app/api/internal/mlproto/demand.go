@@ -0,0 +1,51 @@
+package mlproto
+
+import (
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// Place is the organization as the schedule and demand models see it
+type Place struct {
+	ID                 uuid.UUID                 `json:"place_id"`
+	Name               string                    `json:"name"`
+	Type               string                    `json:"type"`
+	Latitude           *float64                  `json:"latitude,omitempty"`
+	Longitude          *float64                  `json:"longitude,omitempty"`
+	WaitingTime        int                       `json:"waiting_time"`
+	ReceivingPhone     bool                      `json:"receiving_phone"`
+	Delivery           bool                      `json:"delivery"`
+	OpeningHours       []database.OperatingHours `json:"opening_hours"`
+	FixedShifts        bool                      `json:"fixed_shifts"`
+	NumberShiftsPerDay *int                      `json:"number_of_shifts_per_day,omitempty"`
+	ShiftTimes         []database.ShiftTime      `json:"shift_time"`
+	Rating             *float64                  `json:"rating,omitempty"`
+	AcceptingOrders    bool                      `json:"accepting_orders"`
+}
+
+// DemandPredictionRequest is the body of POST /predict/demand
+type DemandPredictionRequest struct {
+	Place                Place                    `json:"place"`
+	Orders               []database.Order         `json:"orders"`
+	Campaigns            []database.Campaign      `json:"campaigns"`
+	Events               []database.ExternalEvent `json:"events"`
+	PredicationStartDate string                   `json:"prediction_start_date"`
+	PredictionDays       *int                     `json:"prediction_days,omitempty"`
+	ClosedDates          []string                 `json:"closed_dates,omitempty"`
+	// ChannelMix is the share of each channel in the historical orders of every weekday and hour
+	ChannelMix []ChannelSlotMix `json:"channel_mix,omitempty"`
+}
+
+// ChannelSlotMix is where the orders of a weekday (0 is Sunday) and hour came from. Delivery and platform
+// orders need packing and handover more than dine in, so their shares tell packing-heavy surges apart.
+type ChannelSlotMix struct {
+	Weekday       int                `json:"weekday"`
+	Hour          int                `json:"hour"`
+	Orders        int                `json:"orders"`
+	Channels      map[string]float64 `json:"channels"`
+	DeliveryShare float64            `json:"delivery_share_percent"`
+	PlatformShare float64            `json:"platform_share_percent"`
+}
+
+// DemandPredictResponse is the response of POST /predict/demand, stored as is as the demand heatmap
+type DemandPredictResponse = database.DemandPredictResponse
@@ -0,0 +1,191 @@
+package mlproto
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func floatPtr(v float64) *float64 { return &v }
+func intPtr(v int) *int           { return &v }
+
+func TestCampaignRecommendationRequest_Marshal(t *testing.T) {
+	request := CampaignRecommendationRequest{
+		Place: CampaignPlace{
+			PlaceID:        "7c9e6679-7425-40de-944b-e07fc1f90ae7",
+			PlaceName:      "Cairo Bistro",
+			Latitude:       30.0444,
+			Longitude:      31.2357,
+			Type:           "restaurant",
+			Delivery:       true,
+			ReceivingPhone: "enabled",
+			OpeningHours: map[string]CampaignOpeningHours{
+				"monday": {Weekday: "monday", OpeningTime: "09:00:00", ClosingTime: "23:00:00"},
+			},
+			FixedShifts:          true,
+			NumberOfShiftsPerDay: 2,
+			WaitingTime:          15,
+		},
+		Orders:                  []CampaignOrder{{Time: "2026-10-12T12:30:00Z", TotalAmount: 45.5, Items: 2, Status: "closed", DiscountAmount: 5}},
+		Campaigns:               []PastCampaign{{StartTime: "2026-10-10T00:00:00Z", EndTime: "2026-10-17T23:59:59Z", ItemsIncluded: []string{"Koshari"}, Discount: 15}},
+		OrderItems:              []CampaignOrderItem{{OrderID: "9f1c2b3a-4d5e-4f60-8a7b-1c2d3e4f5a6b", ItemID: "3f2504e0-4f89-41d3-9a0c-0305e82c3301", Quantity: 2}},
+		RecommendationStartDate: "2026-10-19",
+		NumRecommendations:      5,
+		OptimizeFor:             "roi",
+		MaxDiscount:             30,
+		MinCampaignDurationDays: 3,
+		MaxCampaignDurationDays: 14,
+		AvailableItems:          []string{},
+		Events:                  []database.ExternalEvent{},
+	}
+
+	data, err := json.Marshal(request)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"place": {
+			"place_id": "7c9e6679-7425-40de-944b-e07fc1f90ae7",
+			"place_name": "Cairo Bistro",
+			"latitude": 30.0444,
+			"longitude": 31.2357,
+			"type": "restaurant",
+			"delivery": true,
+			"receiving_phone": "enabled",
+			"opening_hours": {"monday": {"weekday": "monday", "opening_time": "09:00:00", "closing_time": "23:00:00"}},
+			"fixed_shifts": true,
+			"number_of_shifts_per_day": 2,
+			"waiting_time": 15
+		},
+		"orders": [{"time": "2026-10-12T12:30:00Z", "total_amount": 45.5, "items": 2, "status": "closed", "discount_amount": 5}],
+		"campaigns": [{"start_time": "2026-10-10T00:00:00Z", "end_time": "2026-10-17T23:59:59Z", "items_included": ["Koshari"], "discount": 15}],
+		"order_items": [{"order_id": "9f1c2b3a-4d5e-4f60-8a7b-1c2d3e4f5a6b", "item_id": "3f2504e0-4f89-41d3-9a0c-0305e82c3301", "quantity": 2}],
+		"recommendation_start_date": "2026-10-19",
+		"num_recommendations": 5,
+		"optimize_for": "roi",
+		"max_discount": 30,
+		"min_campaign_duration_days": 3,
+		"max_campaign_duration_days": 14,
+		"available_items": [],
+		"events": []
+	}`, string(data))
+}
+
+func TestSchedulePredictRequest_Marshal(t *testing.T) {
+	employeeID := uuid.MustParse("1b4e28ba-2fa1-11d2-883f-0016d3cca427")
+	request := SchedulePredictRequest{
+		Place: Place{ID: uuid.MustParse("7c9e6679-7425-40de-944b-e07fc1f90ae7"), Name: "Cairo Bistro", Type: "restaurant"},
+		ScheduleInput: ScheduleInput{
+			Employees: []Employee{{
+				EmployeeID:            employeeID,
+				RoleNames:             []string{"cook"},
+				AvailableHours:        map[string]EmployeeHours{"monday": {From: "09:00:00", To: "17:00:00"}},
+				HourlyWage:            floatPtr(15.5),
+				PreferredHoursPerWeek: floatPtr(32),
+			}},
+			SchedulerConfig:     SchedulerConfig{SlotLenHour: floatPtr(1), MinRestSlots: intPtr(8)},
+			PredictionStartDate: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	data, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	place := decoded["place"].(map[string]any)
+	assert.Equal(t, "7c9e6679-7425-40de-944b-e07fc1f90ae7", place["place_id"])
+	assert.NotContains(t, place, "latitude", "unknown coordinates are left out")
+	assert.NotContains(t, place, "rating")
+
+	input := decoded["schedule_input"].(map[string]any)
+	assert.Equal(t, "2026-10-19T00:00:00Z", input["prediction_start_date"])
+	employee := input["employees"].([]any)[0].(map[string]any)
+	assert.Equal(t, []any{"cook"}, employee["role_ids"])
+	assert.Equal(t, 32.0, employee["pref_hours"])
+	assert.Equal(t, map[string]any{"from": "09:00:00", "to": "17:00:00"}, employee["available_hours"].(map[string]any)["monday"])
+	assert.Nil(t, employee["max_hours_per_week"])
+
+	config := input["scheduler_config"].(map[string]any)
+	assert.Equal(t, 8.0, config["min_rest_slots"])
+	assert.NotContains(t, config, "weekly_labor_budget", "no budget is left out")
+}
+
+func TestDemandPredictionRequest_Marshal(t *testing.T) {
+	request := DemandPredictionRequest{
+		Place:                Place{Name: "Cairo Bistro"},
+		Orders:               []database.Order{},
+		Campaigns:            []database.Campaign{},
+		Events:               []database.ExternalEvent{},
+		PredicationStartDate: "2026-10-19",
+	}
+
+	data, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "2026-10-19", decoded["prediction_start_date"])
+	assert.Equal(t, []any{}, decoded["orders"])
+	assert.NotContains(t, decoded, "prediction_days")
+	assert.NotContains(t, decoded, "closed_dates")
+	assert.NotContains(t, decoded, "channel_mix")
+}
+
+func TestGenerateScheduleResponse_Unmarshal(t *testing.T) {
+	body := `{
+		"schedule_output": {"monday": [{"09:00-10:00": ["1b4e28ba-2fa1-11d2-883f-0016d3cca427"]}], "tuesday": []},
+		"schedule_status": "OPTIMAL",
+		"schedule_message": "Schedule generated successfully",
+		"objective_value": 42,
+		"management_insights": {"has_solution": true, "peak_periods": [{"slot": 12}], "cost_analysis": {"total_cost": 31}},
+		"message": "ignored"
+	}`
+
+	var response GenerateScheduleResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &response))
+	assert.Equal(t, "OPTIMAL", response.ScheduleStatus)
+	require.NotNil(t, response.ObjectiveValue)
+	assert.Equal(t, 42.0, *response.ObjectiveValue)
+	assert.Equal(t, []string{"1b4e28ba-2fa1-11d2-883f-0016d3cca427"}, response.ScheduleOutput["monday"][0]["09:00-10:00"])
+	assert.True(t, response.ManagementInsights.HasSolution)
+	assert.Equal(t, 31.0, response.ManagementInsights.CostAnalysis["total_cost"])
+}
+
+func TestCampaignRecommendationResponse_Unmarshal(t *testing.T) {
+	body := `{
+		"restaurant_name": "Cairo Bistro",
+		"recommendation_date": "2026-10-19",
+		"recommendations": [{
+			"campaign_id": "rec_1",
+			"items": ["Koshari"],
+			"discount_percentage": 15,
+			"start_date": "2026-10-19",
+			"end_date": "2026-10-25",
+			"duration_days": 7,
+			"expected_uplift": 0.18,
+			"expected_roi": 2.4,
+			"expected_revenue": 1250.5,
+			"confidence_score": 0.72,
+			"reasoning": "Koshari sells best at lunch",
+			"priority_score": 0.81,
+			"recommended_for_context": {"time_slot": "lunch"}
+		}],
+		"analysis_summary": {},
+		"insights": {},
+		"confidence_level": "medium"
+	}`
+
+	var response CampaignRecommendationResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &response))
+	require.Len(t, response.Recommendations, 1)
+	recommendation := response.Recommendations[0]
+	assert.Equal(t, "rec_1", recommendation.CampaignID)
+	assert.Equal(t, 7, recommendation.DurationDays)
+	assert.Equal(t, 2.4, recommendation.ExpectedROI)
+	assert.Equal(t, "lunch", recommendation.RecommendedForContext["time_slot"])
+	assert.Equal(t, "medium", response.ConfidenceLevel)
+}
@@ -0,0 +1,98 @@
+// Package mlproto holds the request and response types of the ML service protocol. The handlers marshal
+// these instead of building maps by hand, so a misspelled field fails to compile rather than reaching the
+// ML service, and the contract fixtures of package mlcontract are checked against the same types.
+package mlproto
+
+import (
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// SchedulePredictRequest is the body of POST /predict/schedule
+type SchedulePredictRequest struct {
+	Place         Place         `json:"place"`
+	ScheduleInput ScheduleInput `json:"schedule_input"`
+}
+
+type ScheduleInput struct {
+	Roles               []database.OrganizationRole `json:"roles"`
+	Employees           []Employee                  `json:"employees"`
+	SchedulerConfig     SchedulerConfig             `json:"scheduler_config"`
+	DemandPredictions   []database.PredictionDay    `json:"demand_predictions"`
+	PredictionStartDate time.Time                   `json:"prediction_start_date"`
+	// DeliveryDemand is the demand of the driver role, absent when the organization does not deliver
+	DeliveryDemand *DeliveryDemand `json:"delivery_demand,omitempty"`
+}
+
+// DeliveryDemand is the delivery orders expected per hour of the scheduled days, from the average of the
+// same weekday and hour over the last HistoryWeeks weeks. The role delivering them is staffed from these
+// instead of the predicted items, items_per_employee_per_hour of the role read as deliveries per driver.
+type DeliveryDemand struct {
+	Role         string              `json:"role_id"`
+	HistoryWeeks int                 `json:"history_weeks"`
+	Days         []DeliveryDemandDay `json:"days"`
+}
+
+type DeliveryDemandDay struct {
+	Day   string               `json:"day_name"`
+	Date  string               `json:"date"`
+	Hours []DeliveryDemandHour `json:"hours"`
+}
+
+type DeliveryDemandHour struct {
+	HourNo         int `json:"hour"`
+	DeliveryOrders int `json:"delivery_orders"`
+}
+
+type Employee struct {
+	EmployeeID            uuid.UUID                `json:"employee_id"`
+	RoleNames             []string                 `json:"role_ids"`
+	AvailableDays         []string                 `json:"available_days"`
+	Preferred_Days        []string                 `json:"preferred_days"`
+	AvailableHours        map[string]EmployeeHours `json:"available_hours"`
+	PreferredHours        map[string]EmployeeHours `json:"preferred_hours"`
+	HourlyWage            *float64                 `json:"hourly_wage"`
+	MaxHoursPerWeek       *float64                 `json:"max_hours_per_week"`
+	MaxConsecSlots        *int                     `json:"max_consec_slots"`
+	PreferredHoursPerWeek *float64                 `json:"pref_hours"`
+	// Supernumerary employees are in training: they are scheduled on top of the staff the demand needs
+	// and do not count toward it
+	Supernumerary bool `json:"supernumerary,omitempty"`
+}
+
+type SchedulerConfig struct {
+	SlotLenHour         *float64 `json:"slot_len_hour"`
+	MinRestSlots        *int     `json:"min_rest_slots"`
+	MinShiftLengthSlots *int     `json:"min_shift_length_slots"`
+	MeetAllDemands      *bool    `json:"meet_all_demand"`
+	WeeklyLaborBudget   *float64 `json:"weekly_labor_budget,omitempty"`
+}
+
+type EmployeeHours struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// GenerateScheduleResponse is the response of POST /predict/schedule
+type GenerateScheduleResponse struct {
+	ScheduleOutput     map[string][]map[string][]string `json:"schedule_output"`
+	ScheduleStatus     string                           `json:"schedule_status"`
+	ScheduleMessage    string                           `json:"schedule_message"`
+	ObjectiveValue     *float64                         `json:"objective_value"`
+	ManagementInsights ManagementInsights               `json:"management_insights"`
+}
+
+type ManagementInsights struct {
+	HasSolution           bool             `json:"has_solution"`
+	PeakPeriods           []map[string]any `json:"peak_periods"`
+	CapacityAnalysis      map[string]any   `json:"capacity_analysis,omitempty"`
+	EmployeeUtilization   []map[string]any `json:"employee_utilization,omitempty"`
+	RoleDemand            map[string]any   `json:"role_demand,omitempty"`
+	HiringRecommendations []map[string]any `json:"hiring_recommendations,omitempty"`
+	CoverageGaps          []map[string]any `json:"coverage_gaps,omitempty"`
+	CostAnalysis          map[string]any   `json:"cost_analysis,omitempty"`
+	WorkloadDistribution  map[string]any   `json:"workload_distribution,omitempty"`
+	FeasibilityAnalysis   []map[string]any `json:"feasibility_analysis,omitempty"`
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/clockwise/clockwise/backend/internal/middleware"
 
 	jwt "github.com/appleboy/gin-jwt/v3"
-	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
 )
@@ -20,18 +19,13 @@ func (s *Server) RegisterRoutes() http.Handler {
 
 	r.Use(gzip.Gzip(gzip.BestCompression))
 
-	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:80", "http://localhost:8000", "http://localhost:8080"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowHeaders:     []string{"Accept", "Authorization", "Content-Type", "Content-Encoding"},
-		AllowCredentials: true,
-	}))
+	// Origins come from CORS_ALLOWED_ORIGINS plus the custom domains of organizations
+	r.Use(middleware.CORS(s.cors, s.orgStore, s.Logger))
 
-	api := r.Group("/api")
 	r.GET("/health", s.healthHandler)
 	r.GET("/ml/health", s.MLHealthHandler)
 
-	authMiddleware, err := middleware.NewAuthMiddleware(s.userStore)
+	authMiddleware, err := middleware.NewAuthMiddleware(s.userStore, s.loginGuard)
 	if err != nil {
 		log.Fatal("JWT Error:" + err.Error())
 	}
@@ -40,13 +34,72 @@ func (s *Server) RegisterRoutes() http.Handler {
 		log.Fatal("authMiddleware.MiddlewareInit() Error:" + err.Error())
 	}
 
+	// Checks uploaded files after LimitUpload has parsed them
+	scanUploads := middleware.ScanUploads(s.fileScanner, s.Logger)
+
+	// Every version gets the same routes, handlers and shims adapt the responses per version
+	for _, version := range s.apiVersions() {
+		api := r.Group(version.Prefix)
+		// Only enforced for cookie sessions when CSRF_ENABLED is set
+		api.Use(middleware.CSRFProtect(s.csrf), middleware.Versioned(version))
+		s.registerAPIRoutes(api, authMiddleware, scanUploads)
+	}
+
+	// Not found handling
+	r.NoRoute(s.notFoundHandler)
+	return r
+}
+
+// registerAPIRoutes registers the routes of one version of the API
+func (s *Server) registerAPIRoutes(api *gin.RouterGroup, authMiddleware *jwt.GinJWTMiddleware, scanUploads gin.HandlerFunc) {
+	api.GET("/csrf-token", middleware.IssueCSRFToken(s.csrf))
+
 	// --- Public Routes ---
 	api.POST("/login", authMiddleware.LoginHandler)
 	api.POST("/register", s.orgHandler.RegisterOrganization)
 
+	// Passwordless login with single-use links emailed to the members of organizations that enable it
+	api.POST("/auth/magic-link", s.magicLinkHandler.RequestMagicLinkHandler)                           // Email a login link, rate limited per account and IP address
+	api.POST("/auth/magic-link/exchange", s.magicLinkHandler.ExchangeMagicLinkHandler(authMiddleware)) // Exchange the link's token for a session
+
+	// Careers page of an organization, applicants do not have an account
+	careers := api.Group("/careers/:org")
+	careers.GET("", s.jobPostingHandler.GetPublicJobPostingsHandler)                  // Open postings
+	careers.POST("/postings/:id/apply", s.jobPostingHandler.ApplyToJobPostingHandler) // Apply to a posting
+
+	// Signed unsubscribe links of non-critical emails, POST for one-click unsubscribes from mail clients
+	api.GET("/unsubscribe", s.preferenceHandler.UnsubscribeHandler)
+	api.POST("/unsubscribe", s.preferenceHandler.UnsubscribeHandler)
+
+	// Logos are linked from emails, which cannot authenticate
+	api.GET("/branding/:org/logo", s.brandingHandler.GetLogoHandler)
+
+	// Offer and termination letters signed with the link emailed to the employee, who may have no account anymore
+	api.GET("/documents/sign/:token", s.documentHandler.GetSignDocumentHandler) // The letter, ?format=pdf downloads it
+	api.POST("/documents/sign/:token", s.documentHandler.SignDocumentHandler)   // Sign with the typed name, records the time and IP address
+
+	// Exports kept on disk, downloaded with the signed link emailed to the requester
+	api.GET("/exports/download", s.exportHandler.DownloadExportHandler)
+
+	// Orders and delivery status updates pushed by delivery platforms, verified by their signature
+	api.POST("/webhooks/:platform/:org", s.platformHandler.ReceiveWebhookHandler)
+
+	// Shared tablets employees clock in and out on with their PIN, authenticated by the device token
+	kiosk := api.Group("/kiosk/:org")
+	kiosk.Use(middleware.KioskDevice(s.kioskStore))
+	kiosk.GET("/employees", s.kioskHandler.GetKioskEmployeesHandler) // Employees with a PIN and whether they are clocked in
+	kiosk.POST("/clock-in", s.kioskHandler.KioskClockInHandler)      // Clock in with employee_id and pin
+	kiosk.POST("/clock-out", s.kioskHandler.KioskClockOutHandler)    // Clock out with employee_id and pin
+
+	// Wall displays showing the schedule of the day and the live order counts, authenticated by the board token
+	api.GET("/status-board/:org", s.statusBoardHandler.GetStatusBoardHandler)
+
 	// --- Protected Routes ---
+	// The sensitive routes need a password or authenticator code confirmed in the last few minutes
+	stepUp := s.stepUpGuard.Enforce(api.BasePath())
+
 	auth := api.Group("/auth")
-	auth.Use(authMiddleware.MiddlewareFunc())
+	auth.Use(authMiddleware.MiddlewareFunc(), stepUp)
 	auth.POST("/refresh", authMiddleware.RefreshHandler)
 	auth.POST("/logout", authMiddleware.LogoutHandler)
 	auth.GET("/me", func(c *gin.Context) {
@@ -62,35 +115,123 @@ func (s *Server) RegisterRoutes() http.Handler {
 	auth.GET("/profile", s.profileHandler.GetProfileHandler)
 	auth.POST("/profile/changepassword", s.profileHandler.ChangePasswordHandler)
 
+	// Organizations of users who work at several of them
+	auth.GET("/organizations", s.membershipHandler.GetMyOrganizationsHandler)                    // Organizations the user can switch to
+	auth.POST("/switch-org", s.membershipHandler.SwitchOrganizationHandler(authMiddleware))      // Token scoped to another organization
+	auth.GET("/organizations/consolidated", s.consolidationHandler.GetConsolidatedReportHandler) // Revenue and labor cost of the organizations the user administers in one currency (?from=&to=&currency=)
+
+	// Re-authentication before sensitive actions, with the password or an authenticator app
+	auth.POST("/step-up", s.stepUpHandler.StepUpHandler(authMiddleware)) // Token unlocking the sensitive routes for STEP_UP_WINDOW
+	auth.GET("/totp", s.stepUpHandler.GetTOTPHandler)                    // Whether the user has an authenticator app
+	auth.POST("/totp/setup", s.stepUpHandler.SetupTOTPHandler)           // New secret to scan, replacing an enabled app needs a step-up
+	auth.POST("/totp/enable", s.stepUpHandler.EnableTOTPHandler)         // Confirm the app with a first code
+	auth.DELETE("/totp", s.stepUpHandler.DeleteTOTPHandler)              // Remove the app
+
 	// Role management
 	organization := api.Group("/:org")
-	organization.Use(authMiddleware.MiddlewareFunc())
+	organization.Use(authMiddleware.MiddlewareFunc(), middleware.OrgMembership(s.membershipStore), stepUp)
 
-	organization.GET("", s.orgHandler.GetOrganizationProfile)                  // Get organization details
-	organization.POST("/request", s.employeeHandler.RequestHandlerForEmployee) // Request Calloff. An employee can request a calloff from their organization
+	organization.GET("", s.orgHandler.GetOrganizationProfile)                          // Get organization details
+	organization.PUT("/custom-domain", s.orgHandler.SetCustomDomainHandler)            // Admin sets the dashboard's custom domain, pending until verified
+	organization.POST("/custom-domain/verify", s.orgHandler.VerifyCustomDomainHandler) // Admin verifies the custom domain with its TXT record
+	organization.PUT("/currency", s.orgHandler.SetCurrencyHandler)                     // Admin sets the currency of the organization's amounts
+	organization.POST("/clone", s.orgHandler.CloneOrganizationHandler)                 // Admin opens a new branch with the rules, roles, hours, items and campaigns of this one
+	organization.POST("/request", s.employeeHandler.RequestHandlerForEmployee)         // Request Calloff. An employee can request a calloff from their organization
+	organization.GET("/status", s.statusHandler.GetStatusHandler)                      // API health of the organization (ingestion lag, schedules, emails, ML latency)
+	organization.GET("/branding", s.brandingHandler.GetBrandingHandler)                // Name, colors and logo URL for the frontend
+	organization.GET("/rating/trend", s.ratingHandler.GetRatingTrendHandler)           // Ratings recomputed from the order ratings and where they are heading (?from=&to=)
+
+	// Admin uploads the logo and sets the brand colors
+	organization.POST("/branding", middleware.LimitImageUpload("UPLOAD_MAX_MB_LOGO", 2), scanUploads, s.brandingHandler.UpdateBrandingHandler)
+
+	// Manager app on phone networks, ?fields=schedule,alerts.kind trims the payload
+	organization.GET("/mobile/summary", s.mobileHandler.GetMobileSummaryHandler) // Schedule and alerts of the day and the counts of pending approvals
+
+	// Passwordless login of the members, off by default
+	organization.GET("/magic-link", s.magicLinkHandler.GetMagicLinkSettingsHandler)    // Whether members can log in with emailed links
+	organization.PUT("/magic-link", s.magicLinkHandler.UpdateMagicLinkSettingsHandler) // Admin turns login links on or off
 
 	// Orders Management & Insights
 	orders := organization.Group("/orders")
 	orders.GET("", s.orderHandler.GetOrdersInsights)
-	orders.POST("/upload/orders", s.orderHandler.UploadAllPastOrdersCSV)
-	orders.POST("/upload/items", s.orderHandler.UploadOrderItemsCSV)
+	orders.POST("", s.orderHandler.CreateOrderHandler) // One order as JSON with its items and delivery
+	orders.POST("/upload/orders", middleware.LimitUpload("UPLOAD_MAX_MB_ORDERS", 100), scanUploads, s.orderHandler.UploadAllPastOrdersCSV)
+	orders.POST("/upload/items", middleware.LimitUpload("UPLOAD_MAX_MB_ORDER_ITEMS", 20), scanUploads, s.orderHandler.UploadOrderItemsCSV)
 	orders.GET("/all", s.orderHandler.GetAllOrders)
 	orders.GET("/week", s.orderHandler.GetAllOrdersForLastWeek)
 	orders.GET("/today", s.orderHandler.GetAllOrdersToday)
+	orders.POST("/validate", s.availabilityHandler.ValidateOrderHandler)      // Check a live order only has items on the menu right now
+	orders.POST("/batch", s.orderHandler.UploadOrdersBatch)                   // Orders with their items and delivery as JSON, stored one transaction per order
+	orders.GET("/channels", s.orderHandler.GetChannelReport)                  // Orders and revenue per channel (?from=&to=&format=json|csv)
+	orders.GET("/discounts/audit", s.orderHandler.GetDiscountAudit)           // Repeatedly discounted customers and discounts per employee shift (?from=&to=)
+	orders.GET("/staff-meals", s.staffOrderHandler.GetStaffMealReportHandler) // Staff meals and employee discounts per employee (?month=YYYY-MM&format=json|csv)
+	orders.PUT("/:id/staff", s.staffOrderHandler.TagStaffOrderHandler)        // Tag an order as an employee's staff meal or discount, out of revenue insights
+	orders.DELETE("/:id/staff", s.staffOrderHandler.UntagStaffOrderHandler)   // Count the order as a customer order again
+	// Large orders CSVs staged and checked in bulk, then promoted in one transaction or discarded
+	orders.POST("/imports", middleware.LimitUpload("UPLOAD_MAX_MB_ORDERS", 100), scanUploads, s.orderImportHandler.StageOrderImportHandler)
+	orders.GET("/imports", s.orderImportHandler.GetOrderImportsHandler)
+	orders.GET("/imports/:import", s.orderImportHandler.GetOrderImportHandler) // The import with its first invalid and duplicate rows
+	orders.POST("/imports/:import/promote", s.orderImportHandler.PromoteOrderImportHandler)
+	orders.DELETE("/imports/:import", s.orderImportHandler.DiscardOrderImportHandler)
+	orders.GET("/:id", s.orderHandler.GetOrderHandler)
+	orders.PATCH("/:id", s.orderHandler.UpdateOrderHandler)                        // Change the fields of an order, not its items or delivery
+	orders.PATCH("/:id/status", s.orderHandler.TransitionOrderStatusHandler)       // Move an order along pending, preparing, ready, completed or cancelled
+	orders.GET("/:id/status-history", s.orderHandler.GetOrderStatusHistoryHandler) // Status changes of an order for auditing
+	orders.DELETE("/:id", s.orderHandler.DeleteOrderHandler)
 
 	// Delivery Management & Insights
 	deliveries := organization.Group("/deliveries")
 	deliveries.GET("", s.orderHandler.GetDeliveryInsights)
-	deliveries.POST("/upload", s.orderHandler.UploadAllPastDeliveriesCSV)
+	deliveries.POST("/upload", middleware.LimitUpload("UPLOAD_MAX_MB_DELIVERIES", 20), scanUploads, s.orderHandler.UploadAllPastDeliveriesCSV)
 	deliveries.GET("/all", s.orderHandler.GetAllDeliveries)
 	deliveries.GET("/week", s.orderHandler.GetAllDeliveriesForLastWeek)
 	deliveries.GET("/today", s.orderHandler.GetAllDeliveriesToday)
+	deliveries.GET("/insights/drivers", s.orderHandler.GetDriverDeliveryInsights) // Average delivery time, deliveries per day, on-time rate and distance per driver (?from=&to=&on_time_minutes=)
+	deliveries.GET("/heatmap", s.orderHandler.GetDeliveryHeatmap)                 // Deliveries per cell of a grid of drop-offs (?cell_size=&from=&to=&status=&driver_id=)
+
+	// Full-history exports produced in the background, the requester is emailed a download link
+	exports := organization.Group("/exports")
+	exports.POST("", s.exportHandler.CreateExportHandler)                        // Queue an export of orders or deliveries (entity, from, to, format)
+	exports.GET("", s.exportHandler.GetExportsHandler)                           // Exports of the organization and their status
+	exports.GET("/encryption", s.exportHandler.GetExportEncryptionHandler)       // How exports and emailed reports are encrypted
+	exports.PUT("/encryption", s.exportHandler.SetExportEncryptionHandler)       // Admin sets a PGP public key or a passphrase
+	exports.DELETE("/encryption", s.exportHandler.DeleteExportEncryptionHandler) // Admin stops encrypting
+	exports.GET("/:job", s.exportHandler.GetExportHandler)                       // The export, with a fresh download link once completed
+
+	// Delivery platforms (Uber Eats, Deliveroo, Talabat) the organization receives orders from
+	platforms := organization.Group("/integrations/delivery-platforms")
+	platforms.GET("", s.platformHandler.GetDeliveryPlatformsHandler)                // Connected platforms and their webhook URLs
+	platforms.PUT("/:platform", s.platformHandler.SaveDeliveryPlatformHandler)      // Admin connects a platform or updates its connection
+	platforms.DELETE("/:platform", s.platformHandler.DeleteDeliveryPlatformHandler) // Admin disconnects a platform
+	// Platform payloads that could not be read, kept for review and replay
+	deadLetters := organization.Group("/integrations/dead-letters")
+	deadLetters.GET("", s.deadLetterHandler.GetDeadLettersHandler)               // Latest first, without payloads (?source=&status=&limit=)
+	deadLetters.GET("/metrics", s.deadLetterHandler.GetDeadLetterMetricsHandler) // Payloads received and failure rate per platform (?days=)
+	deadLetters.GET("/:id", s.deadLetterHandler.GetDeadLetterHandler)            // The dead letter with its payload
+	deadLetters.PUT("/:id", s.deadLetterHandler.UpdateDeadLetterHandler)         // Admin fixes the payload of a pending dead letter
+	deadLetters.POST("/:id/replay", s.deadLetterHandler.ReplayDeadLetterHandler) // Admin stores its events through the platform's integration
+
+	// Google Sheets the organization keeps its order log in, their new rows are imported periodically
+	orderSheets := organization.Group("/integrations/order-sheets")
+	orderSheets.GET("", s.orderSheetHandler.GetOrderSheetsHandler)             // Connected sheets
+	orderSheets.POST("", s.orderSheetHandler.CreateOrderSheetHandler)          // Admin connects a sheet
+	orderSheets.PUT("/:id", s.orderSheetHandler.UpdateOrderSheetHandler)       // Admin changes a sheet's URL, credentials or column mapping
+	orderSheets.DELETE("/:id", s.orderSheetHandler.DeleteOrderSheetHandler)    // Admin disconnects a sheet, its orders are kept
+	orderSheets.POST("/:id/sync", s.orderSheetHandler.SyncOrderSheetHandler)   // Import the new rows now
+	orderSheets.GET("/:id/runs", s.orderSheetHandler.GetOrderSheetRunsHandler) // Result log of the latest syncs
 
 	// Items Management & Insights
 	items := organization.Group("/items")
 	items.GET("", s.orderHandler.GetItemsInsights)
-	items.POST("/upload", s.orderHandler.UploadItemsCSV)
+	items.POST("/upload", middleware.LimitUpload("UPLOAD_MAX_MB_ITEMS", 5), scanUploads, s.orderHandler.UploadItemsCSV)
 	items.GET("/all", s.orderHandler.GetAllItems)
+	items.POST("", s.orderHandler.CreateItemHandler)                                   // Add one item to the menu
+	items.PATCH("/:item", s.orderHandler.UpdateItemHandler)                            // Change an item, a new price is kept in its price history
+	items.DELETE("/:item", s.orderHandler.DeleteItemHandler)                           // Discontinue an item, kept in the orders it was sold in
+	items.GET("/availability", s.availabilityHandler.GetItemAvailabilityHandler)       // Seasonal and time of day windows of the menu
+	items.PUT("/:item/availability", s.availabilityHandler.SetItemAvailabilityHandler) // Replace the windows of an item
+	items.POST("/bulk-price-update", s.itemPriceHandler.BulkPriceUpdateHandler)        // Menu-wide price change, dry run unless dry_run=false
+	items.GET("/:item/price-history", s.itemPriceHandler.GetPriceHistoryHandler)       // Price changes of an item
 
 	// Role management
 	roles := organization.Group("/roles")
@@ -100,12 +241,50 @@ func (s *Server) RegisterRoutes() http.Handler {
 	roles.PUT("/:role", s.rolesHandler.UpdateRole)    // Update role
 	roles.DELETE("/:role", s.rolesHandler.DeleteRole) // Delete role
 
+	// Org-specific attributes of employees, items and orders, listed with their entities and filtered with ?cf.<key>=
+	customFields := organization.Group("/custom-fields")
+	customFields.GET("", s.customFieldHandler.ListCustomFieldsHandler)                 // Fields of the organization (?entity_type=)
+	customFields.POST("", s.customFieldHandler.CreateCustomFieldHandler)               // Admin adds a field
+	customFields.DELETE("/:id", s.customFieldHandler.DeleteCustomFieldHandler)         // Admin removes a field and its values
+	customFields.PUT("/:entity/:id", s.customFieldHandler.SetCustomFieldValuesHandler) // Set the values of an employee, item or order
+
+	// Named filter sets of the list endpoints, private or shared with the organization
+	views := organization.Group("/views")
+	views.GET("", s.savedViewHandler.GetViewsHandler)          // Shared views and the user's own (?resource=)
+	views.POST("", s.savedViewHandler.CreateViewHandler)       // Save a view, admins and managers can share it
+	views.GET("/:id", s.savedViewHandler.GetViewHandler)       // A view with the path to apply it
+	views.PUT("/:id", s.savedViewHandler.UpdateViewHandler)    // Creator or admin only
+	views.DELETE("/:id", s.savedViewHandler.DeleteViewHandler) // Creator or admin only
+
+	// Outbound webhooks for schedule, shift and request events, admins only
+	webhooks := organization.Group("/webhooks")
+	webhooks.GET("", s.webhookHandler.GetWebhooksHandler)                         // Webhooks and the events they can subscribe to
+	webhooks.POST("", s.webhookHandler.CreateWebhookHandler)                      // Returns the signing secret once
+	webhooks.PUT("/:id", s.webhookHandler.UpdateWebhookHandler)                   // Change url, events or enabled, rotate_secret for a new secret
+	webhooks.DELETE("/:id", s.webhookHandler.DeleteWebhookHandler)                // Remove a webhook and its deliveries
+	webhooks.GET("/:id/deliveries", s.webhookHandler.GetWebhookDeliveriesHandler) // Latest deliveries with their attempts (?limit=)
+
+	// Kiosk devices and the time clock they record, devices are managed by admins
+	kioskAdmin := organization.Group("/kiosk")
+	kioskAdmin.GET("/devices", s.kioskHandler.GetKioskDevicesHandler)          // Registered devices, revoked ones included
+	kioskAdmin.POST("/devices", s.kioskHandler.CreateKioskDeviceHandler)       // Returns the device token once
+	kioskAdmin.DELETE("/devices/:id", s.kioskHandler.RevokeKioskDeviceHandler) // Revoke a lost or replaced device
+	kioskAdmin.GET("/entries", s.kioskHandler.GetTimeClockEntriesHandler)      // Clock ins and outs (?from=&to=)
+
+	// Status boards, registered by admins for the displays of the restaurant
+	statusBoards := organization.Group("/status-boards")
+	statusBoards.GET("", s.statusBoardHandler.GetStatusBoardsHandler)          // Registered boards, revoked ones included
+	statusBoards.POST("", s.statusBoardHandler.CreateStatusBoardHandler)       // Returns the board token once
+	statusBoards.DELETE("/:id", s.statusBoardHandler.RevokeStatusBoardHandler) // Revoke a removed or replaced display
+
 	// Public endpoint for orchestrator to discover venues
 	api.GET("/venues/active", s.surgeHandler.GetActiveVenues)
 
 	dashboard := organization.Group("/dashboard")
 	dashboard.GET("/demand", s.dashboardHandler.GetDemandHeatMapHandler)
-	dashboard.POST("/demand/predict", s.dashboardHandler.PredictDemandHeatMapHandler) // Send data and fetch demand from demand service
+	dashboard.POST("/demand/predict", s.mlGuard.Limit("demand"), s.dashboardHandler.PredictDemandHeatMapHandler) // Send data and fetch demand from demand service
+	dashboard.GET("/demand/latest/export", s.dashboardHandler.ExportDemandHeatMapHandler)                        // Latest heatmap flattened to CSV or XLSX for spreadsheets
+	dashboard.GET("/demand/variance-alerts", s.varianceHandler.GetVarianceAlertsHandler)                         // Intraday forecast variance alerts and how often they held
 
 
 	// Surge Detection Endpoints
@@ -116,7 +295,11 @@ func (s *Server) RegisterRoutes() http.Handler {
 	staffing := organization.Group("/staffing")
 	staffing.GET("", s.staffingHandler.GetStaffingSummary)
 	staffing.POST("", s.orgHandler.DelegateUser)
-	staffing.POST("/upload", s.staffingHandler.UploadEmployeesCSV)
+	staffing.POST("/upload", middleware.LimitUpload("UPLOAD_MAX_MB_EMPLOYEES", 5), scanUploads, s.staffingHandler.UploadEmployeesCSV)
+	staffing.POST("/members", s.membershipHandler.AddMemberHandler)          // Give a user of another organization access
+	staffing.DELETE("/members/:id", s.membershipHandler.RemoveMemberHandler) // Revoke the access of a member from another organization
+	staffing.GET("/turnover", s.analyticsHandler.GetTurnoverHandler)         // Headcount changes, exits, tenure and new-hire retention per quarter (?quarters=)
+	staffing.GET("/trainings", s.trainingHandler.GetTrainingsHandler)        // Employees in training, the first to graduate first
 
 	employees := staffing.Group("/employees")
 	employees.GET("", s.staffingHandler.GetAllEmployees)
@@ -124,6 +307,24 @@ func (s *Server) RegisterRoutes() http.Handler {
 	employee := employees.Group("/:id")
 	employee.DELETE("/layoff", s.employeeHandler.LayoffEmployee)
 	employee.GET("", s.employeeHandler.GetEmployeeDetails)
+	employee.POST("/unlock", s.securityHandler.UnlockAccountHandler) // Lift a lockout after too many failed logins
+
+	employee.GET("/export", s.personalDataHandler.ExportEmployeeDataHandler) // GDPR export of the employee's personal data
+
+	// Emergency contact, national ID, work permit and bank details, masked for managers
+	employee.GET("/compliance", s.complianceHandler.GetComplianceHandler)
+	employee.PUT("/compliance", s.complianceHandler.UpdateComplianceHandler) // Employee or admin only
+
+	// Confidential notes and disciplinary actions, for admins and the manager who wrote them
+	employee.GET("/records", s.recordHandler.GetEmployeeRecordsHandler)
+	employee.POST("/records", s.recordHandler.CreateEmployeeRecordHandler)
+	employee.DELETE("/records/:record", s.recordHandler.DeleteEmployeeRecordHandler)
+
+	employee.POST("/documents", s.documentHandler.CreateEmployeeDocumentHandler) // Generate an offer or termination letter for the employee to sign
+
+	// Training period of a new hire, scheduled on top of the staff needed and out of solo coverage roles
+	employee.PUT("/training", s.trainingHandler.StartTrainingHandler)       // Put the employee in training for days from today
+	employee.DELETE("/training", s.trainingHandler.GraduateTrainingHandler) // Graduate the employee early
 
 	employee.GET("/requests", s.employeeHandler.GetEmployeeRequests)
 
@@ -132,21 +333,98 @@ func (s *Server) RegisterRoutes() http.Handler {
 	employee.POST("/requests/decline", s.employeeHandler.DeclineRequest)
 
 	schedule := dashboard.Group("/schedule")
-	schedule.GET("/", s.scheduleHandler.GetCurrentUserScheduleHandler)  // Show schedule for manager and employee
-	schedule.GET("/all", s.scheduleHandler.GetScheduleHandler)          // If admin or manager show full schedule, if employee do not allow
-	schedule.POST("/predict", s.scheduleHandler.PredictScheduleHandler) // Refresh Schedule with the new weekly schedule
-
-	employee.GET("/schedule", s.scheduleHandler.GetEmployeeScheduleHandler) // Get Employee Schedule
+	schedule.GET("/", s.scheduleHandler.GetCurrentUserScheduleHandler)                                           // Show schedule for manager and employee
+	schedule.GET("/all", s.scheduleHandler.GetScheduleHandler)                                                   // If admin or manager show full schedule, if employee do not allow
+	schedule.POST("/predict", s.mlGuard.Limit("schedule"), s.scheduleHandler.PredictScheduleHandler)             // Refresh Schedule with the new weekly schedule
+	schedule.POST("/scenarios", s.mlGuard.Limit("scenarios"), s.scheduleHandler.CompareScheduleScenariosHandler) // Compare generated schedules under different settings without storing them
+	schedule.POST("/replay", s.scheduleHandler.ReplayScheduleHandler)                                            // Replay the orders of a past week under other rules with the fallback scheduler
+	schedule.GET("/acknowledgments", s.scheduleHandler.GetScheduleAcknowledgmentsHandler)                        // Which employees have confirmed their upcoming shifts
+	schedule.GET("/readiness", s.scheduleHandler.GetScheduleReadinessHandler)                                    // Slots the available employees cannot cover, checked before /predict
+	schedule.GET("/drivers/coverage", s.scheduleHandler.GetDriverCoverageHandler)                                // Delivery orders expected per hour against the drivers scheduled
+	schedule.GET("/versions", s.scheduleHandler.GetScheduleVersionsHandler)                                      // Generated schedules and how well they matched employee preferences
+	schedule.GET("/versions/:id", s.scheduleHandler.GetScheduleVersionHandler)                                   // Per-employee preference satisfaction of a generated schedule, id may be "latest"
+	schedule.DELETE("", s.scheduleHandler.ClearScheduleHandler)                                                  // Clear the shifts of a date range, dry run unless dry_run=false
+	schedule.POST("/shifts/standby", s.scheduleHandler.CreateStandbyShiftHandler)                                // Put an employee on call, paid the standby rate unless activated
+	schedule.POST("/shifts/:id/activate", s.scheduleHandler.ActivateShiftHandler)                                // Call in the employee of a standby shift and make it a working shift
+	schedule.POST("/shifts/:id/cancel", s.scheduleHandler.CancelShiftHandler)                                    // Cancel a published shift with a reason, late within the notice period
+	schedule.GET("/shifts/:id/events", s.scheduleHandler.GetShiftEventsHandler)                                  // Who generated, changed or cancelled a shift and when
+	schedule.GET("/cancellations", s.scheduleHandler.GetShiftCancellationsHandler)                               // Cancelled shifts and how many were late, for compliance reporting
+	schedule.GET("/predictability-pay", s.scheduleHandler.GetPredictabilityPayHandler)                           // Changes within the predictable scheduling notice period and the pay they owe
+
+	employee.GET("/schedule", s.scheduleHandler.GetEmployeeScheduleHandler)            // Get Employee Schedule
+	employee.POST("/impact-analysis", s.scheduleHandler.EmployeeImpactAnalysisHandler) // Simulate removing the employee from future schedules
+
+	// Self-service endpoints for the current user
+	me := organization.Group("/me")
+	me.POST("/schedule/acknowledge", s.scheduleHandler.AcknowledgeScheduleHandler)        // Confirm the published shifts have been seen
+	me.GET("/announcements", s.announcementHandler.GetMyAnnouncementsHandler)             // Active announcements addressed to the current user
+	me.POST("/announcements/:id/read", s.announcementHandler.MarkAnnouncementReadHandler) // Read receipt for an announcement
+	me.GET("/export", s.personalDataHandler.ExportMyDataHandler)                          // GDPR export of the current user's personal data
+	me.GET("/compliance", s.complianceHandler.GetComplianceHandler)                       // Own emergency contact, documents and bank details
+	me.PUT("/compliance", s.complianceHandler.UpdateComplianceHandler)                    // Keep them up to date
+	me.PUT("/kiosk-pin", s.kioskHandler.SetKioskPinHandler)                               // PIN for clocking in on the kiosks
+	me.GET("/notifications", s.notificationHandler.GetNotificationSettingsHandler)        // Immediate notification emails or hourly/daily digests
+	me.PUT("/notifications", s.notificationHandler.UpdateNotificationSettingsHandler)     // Switch between immediate emails and digests
+	me.GET("/email-preferences", s.preferenceHandler.GetEmailPreferencesHandler)          // Categories of non-critical emails opted out of
+	me.PUT("/email-preferences", s.preferenceHandler.UpdateEmailPreferencesHandler)       // Opt out of reminders, digests or marketing emails
+	me.GET("/documents", s.documentHandler.GetMyDocumentsHandler)                         // Offer and termination letters of the current user
+	me.GET("/skills", s.skillHandler.GetMySkillsHandler)                                  // Skills and certifications declared and their verification
+	me.POST("/skills", s.skillHandler.ProposeSkillHandler)                                // Declare a skill, pending until a manager verifies it
+	me.DELETE("/skills/:id", s.skillHandler.DeleteMySkillHandler)                         // Withdraw a declared skill
+
+	// Verification of the declared skills, which roles can require for scheduling
+	skills := organization.Group("/skills")
+	skills.GET("", s.skillHandler.GetSkillsHandler)                                  // Declared skills (?status=&employee_id=)
+	skills.POST("/:id/verify", s.skillHandler.VerifySkillHandler)                    // Verify a pending skill, it then counts towards scheduling
+	skills.POST("/:id/reject", s.skillHandler.RejectSkillHandler)                    // Reject a pending skill with an optional note
+	skills.GET("/requirements", s.skillHandler.GetRoleSkillRequirementsHandler)      // Roles requiring skills
+	skills.PUT("/requirements/:role", s.skillHandler.SetRoleSkillRequirementHandler) // Admin sets the skills a role requires
+
+	// Announcements broadcast by managers to the staff
+	announcements := organization.Group("/announcements")
+	announcements.GET("", s.announcementHandler.GetAnnouncementsHandler)               // All announcements with read counts
+	announcements.POST("", s.announcementHandler.CreateAnnouncementHandler)            // Post an announcement, optionally emailing the staff
+	announcements.DELETE("/:id", s.announcementHandler.DeleteAnnouncementHandler)      // Delete an announcement
+	announcements.GET("/:id/reads", s.announcementHandler.GetAnnouncementReadsHandler) // Who has read an announcement
+
+	// Shift handover report of the incoming manager and the notes left for the next shift
+	handover := organization.Group("/handover")
+	handover.GET("", s.handoverHandler.GetHandoverReportHandler)                   // Next shift staff, open orders, pending requests, notes, low stock and today's numbers (?shift=YYYY-MM-DDTHH:MM)
+	handover.POST("/notes", s.handoverHandler.CreateShiftNoteHandler)              // Leave a note or a low stock alert for the next shift
+	handover.POST("/notes/:id/resolve", s.handoverHandler.ResolveShiftNoteHandler) // Close a note once dealt with
+
+	// Rules routing the request notifications by email or SMS, changed by admins
+	routing := organization.Group("/notification-routing")
+	routing.GET("", s.routingHandler.GetRoutingRulesHandler)           // Rules in the order they are tried, the first match wins
+	routing.POST("", s.routingHandler.CreateRoutingRuleHandler)        // Route an event, optionally within_hours of the next shift
+	routing.DELETE("/:id", s.routingHandler.DeleteRoutingRuleHandler)  // Remove a rule
+	routing.POST("/simulate", s.routingHandler.SimulateRoutingHandler) // Matched rule and recipients of a sample event, nothing is sent
+
+	// Messages of the admins to the staff filtered by role, department or shifts tomorrow
+	notifications := organization.Group("/notifications")
+	notifications.POST("/broadcast", s.broadcastHandler.SendBroadcastHandler)            // Send a message by email and/or SMS, delivered in the background
+	notifications.POST("/broadcast/preview", s.broadcastHandler.PreviewBroadcastHandler) // Recipients a broadcast would reach per channel, nothing is sent
+	notifications.GET("/broadcasts", s.broadcastHandler.GetBroadcastsHandler)            // Broadcasts sent with their delivery counts
+	notifications.GET("/broadcasts/:id", s.broadcastHandler.GetBroadcastHandler)         // Delivery status of a broadcast per recipient
+
+	// Offer and termination letters of the employees and the templates they are generated from
+	documents := organization.Group("/documents")
+	documents.GET("", s.documentHandler.GetEmployeeDocumentsHandler)                   // Documents of the organization (?employee_id=)
+	documents.GET("/:id/pdf", s.documentHandler.GetDocumentPDFHandler)                 // Download a letter, the signed PDF once signed
+	documents.GET("/templates/:kind", s.documentHandler.GetDocumentTemplateHandler)    // Template of offer_letter or termination_letter
+	documents.PUT("/templates/:kind", s.documentHandler.UpdateDocumentTemplateHandler) // Admin replaces a template
 
 	campaigns := organization.Group("/campaigns")
-	campaigns.GET("", s.campaignHandler.GetCampaignsInsightsHandler)       // Campaign insights
-	campaigns.POST("/upload", s.campaignHandler.UploadCampaignsCSVHandler) // Upload Campaigns CSV
-	campaigns.POST("/upload/items", s.campaignHandler.UploadCampaignsItemsCSVHandlers)
+	campaigns.GET("", s.campaignHandler.GetCampaignsInsightsHandler) // Campaign insights
+	// Upload Campaigns CSV
+	campaigns.POST("/upload", middleware.LimitUpload("UPLOAD_MAX_MB_CAMPAIGNS", 5), scanUploads, s.campaignHandler.UploadCampaignsCSVHandler)
+	campaigns.POST("/upload/items", middleware.LimitUpload("UPLOAD_MAX_MB_CAMPAIGN_ITEMS", 5), scanUploads, s.campaignHandler.UploadCampaignsItemsCSVHandlers)
 	campaigns.GET("/all", s.campaignHandler.GetAllCampaignsHandler)              // Get All Campaigns
 	campaigns.GET("/week", s.campaignHandler.GetAllCampaignsForLastWeekHandler)  // Get All Campaigns for last week
 
-	campaigns.POST("/recommend", s.campaignHandler.RecommendCampaignsHandler)    // Get AI recommendations
-	campaigns.POST("/feedback", s.campaignHandler.SubmitCampaignFeedbackHandler) // Submit campaign feedback
+	campaigns.POST("/recommend", s.mlGuard.Limit("recommend"), s.campaignHandler.RecommendCampaignsHandler) // Get AI recommendations
+	campaigns.POST("/feedback", s.campaignHandler.SubmitCampaignFeedbackHandler)                            // Submit campaign feedback
+	campaigns.POST("/recommendations/accept", s.campaignHandler.AcceptCampaignRecommendationHandler)        // Run a recommended campaign, refused when it stacks on other discounts
 
 	// TODO: Offers management to those on call and in the shift in the current shift
 	offers := organization.Group("/offers")
@@ -154,23 +432,101 @@ func (s *Server) RegisterRoutes() http.Handler {
 	offers.POST("/accept",s.offerHandler.AcceptOfferHandler)  // Accept an offer
 	offers.POST("/decline",s.offerHandler.DeclineOfferHandler) // Decline an offer
 
+	// Security events such as failed logins, lockouts and new device logins
+	organization.GET("/audit-log", s.securityHandler.GetAuditLogHandler)
+
+	// Guests seated at the tables, for hosts
+	occupancy := organization.Group("/occupancy")
+	occupancy.GET("/current", s.occupancyHandler.GetCurrentOccupancyHandler)   // Guests seated right now, per table
+	occupancy.GET("/timeline", s.occupancyHandler.GetOccupancyTimelineHandler) // Guests seated over a day, per table
+
+	// Pickup and delivery times to quote customers, tuned by the rules
+	organization.GET("/wait-time/estimate", s.waitTimeHandler.GetWaitTimeEstimateHandler)
+
+	// Orders bumped by the kitchen display or the POS, and how long and how loaded the kitchen is
+	kitchen := organization.Group("/kitchen")
+	kitchen.GET("/load", s.waitTimeHandler.GetKitchenLoadHandler)                       // Open orders against the staff on shift
+	kitchen.GET("/prep-times", s.waitTimeHandler.GetPrepTimesHandler)                   // Average prep time per item and hour (?days=)
+	kitchen.POST("/orders/:order/prepared", s.waitTimeHandler.MarkOrderPreparedHandler) // Bump an order or some of its items
+
+	// Suggested prep quantities per item from the demand forecast, as JSON, CSV or PDF
+	organization.GET("/prep-list", s.prepListHandler.GetPrepListHandler)
+
+	// Calendar of external events (concerts, matches, road closures) that move demand
+	events := organization.Group("/events")
+	events.GET("", s.eventHandler.GetExternalEventsHandler)          // Optional from/to window
+	events.POST("", s.eventHandler.CreateExternalEventHandler)       // Add an event
+	events.GET("/:id", s.eventHandler.GetExternalEventHandler)       // Get one event
+	events.PUT("/:id", s.eventHandler.UpdateExternalEventHandler)    // Replace an event
+	events.DELETE("/:id", s.eventHandler.DeleteExternalEventHandler) // Remove an event
+
+	// Job postings, from hiring recommendations or by hand, and their applicants
+	jobPostings := organization.Group("/job-postings")
+	jobPostings.GET("", s.jobPostingHandler.GetJobPostingsHandler)                 // Postings with applicant counts
+	jobPostings.POST("", s.jobPostingHandler.CreateJobPostingHandler)              // Open a posting
+	jobPostings.PUT("/:id/status", s.jobPostingHandler.SetJobPostingStatusHandler) // Open or close a posting
+	jobPostings.GET("/:id/applicants", s.jobPostingHandler.GetApplicantsHandler)   // Applicants of a posting
+
+	// Move applicants through the hiring pipeline, interviews and trial shifts go on the schedule grid
+	applicants := organization.Group("/applicants")
+	applicants.PUT("/:id/stage", s.jobPostingHandler.SetApplicantStageHandler)         // Change pipeline stage
+	applicants.POST("/:id/sessions", s.sessionHandler.ScheduleApplicantSessionHandler) // Book an interview or trial shift
+	applicants.GET("/:id/sessions", s.sessionHandler.GetApplicantSessionsHandler)      // Sessions of an applicant
+
+	// Weekly revenue, labor cost and rating targets and how the organization does against them
+	targets := organization.Group("/targets")
+	targets.GET("", s.kpiTargetHandler.GetKPITargetsHandler)           // Targets of the organization, null when none are set
+	targets.PUT("", s.kpiTargetHandler.SetKPITargetsHandler)           // Admin replaces the targets
+	targets.DELETE("", s.kpiTargetHandler.DeleteKPITargetsHandler)     // Admin stops tracking the targets
+	targets.GET("/progress", s.kpiTargetHandler.GetKPIProgressHandler) // Actuals against the targets with their variance (?from=&to=)
+
 	// Insights that change from a user to another about general statistics & analytics
 	insights := organization.Group("/insights")
-	insights.GET("", s.insightHandler.GetInsightsHandler) // Get All insights
+	insights.GET("", s.insightHandler.GetInsightsHandler)                                     // Get All insights
+	insights.GET("/busiest-hour", s.insightHandler.GetBusiestHourDrillDownHandler)            // Orders of every hour of the day (?from=&to=)
+	insights.GET("/most-selling-items", s.insightHandler.GetMostSellingItemsDrillDownHandler) // Items ranked by orders with quantities and revenue (?from=&to=&limit=)
+	insights.GET("/deliveries-today", s.insightHandler.GetDeliveriesTodayDrillDownHandler)    // Delivery orders of today with their driver and status
+
+	// Chart data computed once per day and cached
+	analytics := organization.Group("/analytics")
+	analytics.GET("/staffing-vs-demand", s.analyticsHandler.GetStaffingVsDemandHandler) // Predicted demand, actual orders and scheduled headcount per slot (?date=)
 
 	// Preferences set by managers and employees
 	preferences := organization.Group("/preferences")                           // Employees only
 	preferences.GET("", s.preferencesHandler.GetCurrentEmployeePreferences)     // Get Current Employee Preferences
 	preferences.POST("", s.preferencesHandler.UpdateCurrentEmployeePreferences) // Edit current preferences
 
+	// Availability of other employees, set by admins and managers when onboarding
+	preferences.POST("/copy", s.preferencesHandler.CopyPreferencesHandler)                                                                      // Copy an employee's availability to others
+	preferences.POST("/upload", middleware.LimitUpload("UPLOAD_MAX_MB_PREFERENCES", 5), scanUploads, s.preferencesHandler.UploadPreferencesCSV) // Import weekly availability from CSV
+
 	// Rules set by the organization to be used in the scheduler and reccommendors
 	rules := organization.Group("/rules")                  // Rules of the organization
 	rules.GET("", s.rulesHandler.GetOrganizationRules)     // Get all the rules of the organization
 	rules.POST("", s.rulesHandler.UpdateOrganizationRules) // Edit the rules of the organization
 
-	// Not found handling
-	r.NoRoute(s.notFoundHandler)
-	return r
+	// Days closed despite the weekly operating hours, closing one cancels its shifts
+	rules.GET("/exceptions", s.closureHandler.GetOperatingHoursExceptionsHandler) // List the closed days
+	rules.PUT("/exceptions/:date", s.closureHandler.CloseDayHandler)              // Close a day
+	rules.DELETE("/exceptions/:date", s.closureHandler.ReopenDayHandler)          // Reopen a closed day
+
+	// Peak periods when holiday requests are refused or need an admin to approve them
+	rules.GET("/blackouts", s.employeeHandler.GetBlackoutsHandler)           // List the upcoming blackouts
+	rules.POST("/blackouts", s.employeeHandler.CreateBlackoutHandler)        // Block holidays during a period
+	rules.DELETE("/blackouts/:id", s.employeeHandler.DeleteBlackoutHandler) // Lift a blackout
+
+	// Roles that must approve each request type in order, e.g. a manager then an admin for resignations
+	rules.GET("/approval-chains", s.employeeHandler.GetApprovalChainsHandler)            // List the chain of every request type
+	rules.PUT("/approval-chains/:type", s.employeeHandler.SetApprovalChainHandler)       // Set the chain of a request type
+	rules.DELETE("/approval-chains/:type", s.employeeHandler.ResetApprovalChainHandler) // Restore the default chain
+
+	// Sanity checks applied to the rows of the CSV imports
+	rules.GET("/validation", s.ingestionHandler.GetIngestionRulesHandler)          // List the validation rules
+	rules.POST("/validation", s.ingestionHandler.CreateIngestionRuleHandler)       // Add a validation rule
+	rules.DELETE("/validation/:id", s.ingestionHandler.DeleteIngestionRuleHandler) // Remove a validation rule
+
+	// Orders paused and reopened by the auto-close controller when the demand outruns the staff on shift
+	rules.GET("/auto-close/history", s.acceptanceHandler.GetAutoCloseHistoryHandler) // List the changes with the load they were made on
 }
 
 // healthHandler godoc
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -12,7 +14,9 @@ import (
 
 	"github.com/clockwise/clockwise/backend/internal/api"
 	"github.com/clockwise/clockwise/backend/internal/cache"
+	"github.com/clockwise/clockwise/backend/internal/config"
 	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
 	"github.com/clockwise/clockwise/backend/internal/service"
 	"github.com/clockwise/clockwise/backend/migrations"
 )
@@ -21,20 +25,66 @@ type Server struct {
 	port int
 	db   database.Service
 
-	orgHandler         *api.OrgHandler
-	staffingHandler    *api.StaffingHandler
-	employeeHandler    *api.EmployeeHandler
-	insightHandler     *api.InsightHandler
-	preferencesHandler *api.PreferencesHandler
-	rulesHandler       *api.RulesHandler
-	rolesHandler       *api.RolesHandler
-	profileHandler     *api.ProfileHandler
-	orderHandler       *api.OrderHandler
-	dashboardHandler   *api.DashboardHandler
-	scheduleHandler    *api.ScheduleHandler
-	campaignHandler    *api.CampaignHandler
-	offerHandler       *api.OfferHandler
-	surgeHandler       *api.SurgeHandler
+	orgHandler           *api.OrgHandler
+	staffingHandler      *api.StaffingHandler
+	employeeHandler      *api.EmployeeHandler
+	insightHandler       *api.InsightHandler
+	preferencesHandler   *api.PreferencesHandler
+	rulesHandler         *api.RulesHandler
+	rolesHandler         *api.RolesHandler
+	profileHandler       *api.ProfileHandler
+	orderHandler         *api.OrderHandler
+	dashboardHandler     *api.DashboardHandler
+	scheduleHandler      *api.ScheduleHandler
+	campaignHandler      *api.CampaignHandler
+	offerHandler         *api.OfferHandler
+	surgeHandler         *api.SurgeHandler
+	announcementHandler  *api.AnnouncementHandler
+	membershipHandler    *api.MembershipHandler
+	securityHandler      *api.SecurityHandler
+	occupancyHandler     *api.OccupancyHandler
+	waitTimeHandler      *api.WaitTimeHandler
+	prepListHandler      *api.PrepListHandler
+	availabilityHandler  *api.ItemAvailabilityHandler
+	eventHandler         *api.ExternalEventHandler
+	jobPostingHandler    *api.JobPostingHandler
+	sessionHandler       *api.ApplicantSessionHandler
+	recordHandler        *api.EmployeeRecordHandler
+	personalDataHandler  *api.PersonalDataHandler
+	closureHandler       *api.OperatingHoursExceptionHandler
+	statusHandler        *api.StatusHandler
+	ingestionHandler     *api.IngestionRuleHandler
+	notificationHandler  *api.NotificationSettingsHandler
+	preferenceHandler    *api.EmailPreferenceHandler
+	brandingHandler      *api.BrandingHandler
+	platformHandler      *api.DeliveryPlatformHandler
+	deadLetterHandler    *api.DeadLetterHandler
+	varianceHandler      *api.ForecastVarianceHandler
+	complianceHandler    *api.EmployeeComplianceHandler
+	customFieldHandler   *api.CustomFieldHandler
+	savedViewHandler     *api.SavedViewHandler
+	itemPriceHandler     *api.ItemPriceHandler
+	webhookHandler       *api.WebhookHandler
+	ratingHandler        *api.OrgRatingHandler
+	kioskHandler         *api.KioskHandler
+	consolidationHandler *api.ConsolidationHandler
+	staffOrderHandler    *api.StaffOrderHandler
+	documentHandler      *api.EmployeeDocumentHandler
+	handoverHandler      *api.HandoverHandler
+	routingHandler       *api.NotificationRoutingHandler
+	magicLinkHandler     *api.MagicLinkHandler
+	statusBoardHandler   *api.StatusBoardHandler
+	orderSheetHandler    *api.OrderSheetHandler
+	analyticsHandler     *api.AnalyticsHandler
+	skillHandler         *api.SkillHandler
+	broadcastHandler     *api.BroadcastHandler
+	orderImportHandler   *api.OrderImportHandler
+	exportHandler        *api.ExportHandler
+	stepUpHandler        *api.StepUpHandler
+	acceptanceHandler    *api.OrderAcceptanceHandler
+	mobileHandler        *api.MobileHandler
+	trainingHandler      *api.TrainingHandler
+	kpiTargetHandler     *api.KPITargetHandler
 
 	userStore        database.UserStore
 	orgStore         database.OrgStore
@@ -48,6 +98,16 @@ type Server struct {
 	scheduleStore    database.ScheduleStore
 	offerStore       database.OfferStore
 	surgeStore       database.SurgeStore
+	membershipStore  database.MembershipStore
+	kioskStore       database.KioskStore
+
+	fileScanner service.FileScanner
+	loginGuard  *middleware.LoginGuard
+	mlGuard     *middleware.MLGuard
+	stepUpGuard *middleware.StepUpGuard
+	cors        config.CORSConfig
+	csrf        config.CSRFConfig
+	versions    config.APIVersionsConfig
 
 	Logger *slog.Logger
 }
@@ -55,11 +115,17 @@ type Server struct {
 func NewServer(Logger *slog.Logger) *http.Server {
 	port, _ := strconv.Atoi(os.Getenv("PORT"))
 
+	// Credentials are resolved from the environment, Vault or AWS Secrets Manager
+	cfg, err := config.Load(Logger)
+	if err != nil {
+		panic(fmt.Sprintf("failed to load configuration: %s", err))
+	}
+
 	// Create database service
-	dbService := database.New()
+	dbService := database.NewWithPasswordSource(cfg.SecretSource("POSTGRES_PASSWORD"), cfg.Database, Logger)
 
 	// Run migrations on startup
-	err := database.MigrateFS(dbService.GetDB(), ".", migrations.FS)
+	err = database.MigrateFS(dbService.GetDB(), ".", migrations.FS)
 
 	if err != nil {
 		panic(fmt.Sprintf("failed to run database migrations: %s", err))
@@ -77,8 +143,20 @@ func NewServer(Logger *slog.Logger) *http.Server {
 		Logger.Info("Redis cache initialized successfully", "addr", redisAddr)
 	}
 
+	// Encrypt salaries and contact details when encryption keys are configured
+	var fieldCipher database.FieldCipher
+	cryptoService, err := service.NewCryptoServiceFromEnv(Logger)
+	switch {
+	case err == nil:
+		fieldCipher = cryptoService
+	case errors.Is(err, service.ErrNoEncryptionKeys):
+		Logger.Warn("no encryption keys configured, salaries and contact details are stored in plaintext")
+	default:
+		panic(fmt.Sprintf("failed to load encryption keys: %s", err))
+	}
+
 	// Create base stores (PostgreSQL implementations)
-	baseUserStore := database.NewPostgresUserStore(dbService.GetDB(), Logger)
+	baseUserStore := database.NewPostgresUserStore(dbService.GetDB(), Logger, fieldCipher)
 	baseOrgStore := database.NewPostgresOrgStore(dbService.GetDB(), Logger)
 	baseRequestStore := database.NewPostgresRequestStore(dbService.GetDB(), Logger)
 	basePreferencesStore := database.NewPostgresPreferencesStore(dbService.GetDB(), Logger)
@@ -86,12 +164,63 @@ func NewServer(Logger *slog.Logger) *http.Server {
 	baseRolesStore := database.NewPostgresRolesStore(dbService.GetDB(), Logger)
 	baseUserRolesStore := database.NewPostgresUserRolesStore(dbService.GetDB(), Logger)
 	baseOperatingHoursStore := database.NewPostgresOperatingHoursStore(dbService.GetDB(), Logger)
-	baseInsightStore := &database.PostgresInsightStore{DB: dbService.GetDB(), Logger: Logger}
+	baseInsightStore := &database.PostgresInsightStore{DB: dbService.GetDB(), Logger: Logger, Cipher: fieldCipher}
 	baseOrderStore := &database.PostgresOrderStore{DB: dbService.GetDB(), Logger: Logger}
 	baseCampaignStore := database.NewPostgresCampaignStore(dbService.GetDB(), Logger)
 	baseDemandStore := database.NewPostgresDemandStore(dbService.GetDB(), Logger)
-	baseScheduleStore := database.NewPostgresScheduleStore(baseUserStore,dbService.GetDB(), Logger)
+	baseScheduleStore := database.NewPostgresScheduleStore(baseUserStore, dbService.GetDB(), Logger)
 	baseOfferStore := database.NewPostgresOfferStore(dbService.GetDB(), Logger)
+	announcementStore := database.NewPostgresAnnouncementStore(dbService.GetDB(), Logger)
+	membershipStore := database.NewPostgresMembershipStore(dbService.GetDB(), Logger)
+	loginSecurityStore := database.NewPostgresLoginSecurityStore(dbService.GetDB(), Logger)
+	auditStore := database.NewPostgresAuditStore(dbService.GetDB(), Logger)
+	occupancyStore := database.NewPostgresOccupancyStore(dbService.GetDB(), Logger)
+	baseWaitTimeStore := database.NewPostgresWaitTimeStore(dbService.GetDB(), Logger)
+	prepListStore := database.NewPostgresPrepListStore(dbService.GetDB(), Logger)
+	itemAvailabilityStore := database.NewPostgresItemAvailabilityStore(dbService.GetDB(), Logger)
+	externalEventStore := database.NewPostgresExternalEventStore(dbService.GetDB(), Logger)
+	jobPostingStore := database.NewPostgresJobPostingStore(dbService.GetDB(), Logger)
+	applicantSessionStore := database.NewPostgresApplicantSessionStore(dbService.GetDB(), Logger)
+	employeeRecordStore := database.NewPostgresEmployeeRecordStore(dbService.GetDB(), Logger)
+	operatingHoursExceptionStore := database.NewPostgresOperatingHoursExceptionStore(dbService.GetDB(), Logger)
+	statusStore := database.NewPostgresStatusStore(dbService.GetDB(), Logger)
+	ingestionRuleStore := database.NewPostgresIngestionRuleStore(dbService.GetDB(), Logger)
+	notificationStore := database.NewPostgresNotificationStore(dbService.GetDB(), Logger)
+	emailPreferenceStore := database.NewPostgresEmailPreferenceStore(dbService.GetDB(), Logger)
+	brandingStore := database.NewPostgresBrandingStore(dbService.GetDB(), Logger)
+	deliveryPlatformStore := database.NewPostgresDeliveryPlatformStore(dbService.GetDB(), Logger, fieldCipher)
+	forecastVarianceStore := database.NewPostgresForecastVarianceStore(dbService.GetDB(), Logger)
+	blackoutStore := database.NewPostgresBlackoutStore(dbService.GetDB(), Logger)
+	approvalStore := database.NewPostgresApprovalStore(dbService.GetDB(), Logger)
+	complianceStore := database.NewPostgresEmployeeComplianceStore(dbService.GetDB(), Logger, fieldCipher)
+	customFieldStore := database.NewPostgresCustomFieldStore(dbService.GetDB(), Logger)
+	savedViewStore := database.NewPostgresSavedViewStore(dbService.GetDB(), Logger)
+	baseItemPriceStore := database.NewPostgresItemPriceStore(dbService.GetDB(), Logger)
+	webhookStore := database.NewPostgresWebhookStore(dbService.GetDB(), Logger, fieldCipher)
+	scheduleVersionStore := database.NewPostgresScheduleVersionStore(dbService.GetDB(), Logger)
+	orgRatingStore := database.NewPostgresOrgRatingStore(dbService.GetDB(), Logger)
+	kioskStore := database.NewPostgresKioskStore(dbService.GetDB(), Logger)
+	exchangeRateStore := database.NewPostgresExchangeRateStore(dbService.GetDB(), Logger)
+	staffOrderStore := database.NewPostgresStaffOrderStore(dbService.GetDB(), Logger)
+	employeeDocumentStore := database.NewPostgresEmployeeDocumentStore(dbService.GetDB(), Logger)
+	shiftNoteStore := database.NewPostgresShiftNoteStore(dbService.GetDB(), Logger)
+	statusBoardStore := database.NewPostgresStatusBoardStore(dbService.GetDB(), Logger)
+	orderSheetStore := database.NewPostgresOrderSheetStore(dbService.GetDB(), Logger, fieldCipher)
+	notificationRoutingStore := database.NewPostgresNotificationRoutingStore(dbService.GetDB(), Logger)
+	magicLinkStore := database.NewPostgresMagicLinkStore(dbService.GetDB(), Logger)
+	baseStaffingAnalyticsStore := database.NewPostgresStaffingAnalyticsStore(dbService.GetDB(), Logger)
+	skillStore := database.NewPostgresSkillStore(dbService.GetDB(), Logger)
+	broadcastStore := database.NewPostgresBroadcastStore(dbService.GetDB(), Logger)
+	baseOrderImportStore := database.NewPostgresOrderImportStore(dbService.GetDB(), Logger)
+	exportJobStore := database.NewPostgresExportJobStore(dbService.GetDB(), Logger)
+	exportEncryptionStore := database.NewPostgresExportEncryptionStore(dbService.GetDB(), Logger, fieldCipher)
+	totpStore := database.NewPostgresTOTPStore(dbService.GetDB(), Logger, fieldCipher)
+	baseOrderAcceptanceStore := database.NewPostgresOrderAcceptanceStore(dbService.GetDB(), Logger)
+	mobileSummaryStore := database.NewPostgresMobileSummaryStore(dbService.GetDB(), Logger)
+	deadLetterStore := database.NewPostgresDeadLetterStore(dbService.GetDB(), Logger)
+	deliverySLAStore := database.NewPostgresDeliverySLAStore(dbService.GetDB(), Logger)
+	trainingStore := database.NewPostgresTrainingStore(dbService.GetDB(), Logger)
+	kpiTargetStore := database.NewPostgresKPITargetStore(dbService.GetDB(), Logger)
 
 	// Wrap stores with caching if Redis is available
 	var userStore database.UserStore
@@ -108,6 +237,11 @@ func NewServer(Logger *slog.Logger) *http.Server {
 	var demandStore database.DemandStore
 	var scheduleStore database.ScheduleStore
 	var offerStore database.OfferStore
+	var staffingAnalyticsStore database.StaffingAnalyticsStore
+	var itemPriceStore database.ItemPriceStore
+	var orderImportStore database.OrderImportStore
+	var orderAcceptanceStore database.OrderAcceptanceStore
+	var waitTimeStore database.WaitTimeStore
 
 	if cacheService != nil {
 		// Wrap with caching layer
@@ -125,6 +259,11 @@ func NewServer(Logger *slog.Logger) *http.Server {
 		demandStore = cache.NewCachedDemandStore(baseDemandStore, cacheService)
 		scheduleStore = baseScheduleStore
 		offerStore = baseOfferStore
+		staffingAnalyticsStore = cache.NewCachedStaffingAnalyticsStore(baseStaffingAnalyticsStore, cacheService)
+		itemPriceStore = cache.NewCachedItemPriceStore(baseItemPriceStore, cacheService)
+		orderImportStore = cache.NewCachedOrderImportStore(baseOrderImportStore, cacheService)
+		orderAcceptanceStore = cache.NewCachedOrderAcceptanceStore(baseOrderAcceptanceStore, cacheService)
+		waitTimeStore = cache.NewCachedWaitTimeStore(baseWaitTimeStore, cacheService)
 		Logger.Info("All stores wrapped with Redis caching layer")
 	} else {
 		// Use base stores directly (no caching)
@@ -142,26 +281,40 @@ func NewServer(Logger *slog.Logger) *http.Server {
 		demandStore = baseDemandStore
 		scheduleStore = baseScheduleStore
 		offerStore = baseOfferStore
+		staffingAnalyticsStore = baseStaffingAnalyticsStore
+		itemPriceStore = baseItemPriceStore
+		orderImportStore = baseOrderImportStore
+		orderAcceptanceStore = baseOrderAcceptanceStore
+		waitTimeStore = baseWaitTimeStore
 		Logger.Warn("Running without cache layer - all requests will hit PostgreSQL directly")
 	}
 
 	// Services
-	emailService := service.NewSMTPEmailService(Logger)
+	emailService := service.NewSMTPEmailService(Logger, cfg.Secrets)
+	emailService.OnFailure = api.RecordEmailFailures(userStore, statusStore, Logger)
+	// Non-critical emails carry a signed unsubscribe link and skip the recipients who opted out
+	unsubscribeSigner := service.NewUnsubscribeSigner()
+	emailService.Preferences = emailPreferenceStore
+	emailService.Unsubscribe = unsubscribeSigner
+	// Emails take the colors and logo of the organization of their recipient
+	emailService.Branding = api.EmailBrandingFor(userStore, orgStore, brandingStore, Logger)
 	uploadService := service.NewCSVUploadService(Logger)
+	fileScanner := service.NewFileScanner(Logger)
 
 	// Surge Store (no cache for now)
 	surgeStore := database.NewPostgresSurgeStore(dbService.GetDB(), Logger)
 
 	// Handlers for Endpoints
 	orgHandler := api.NewOrgHandler(orgStore, userStore, userRolesStore, rolesStore, emailService, Logger)
-	staffingHandler := api.NewStaffingHandler(userStore, orgStore, userRolesStore, rolesStore, uploadService, emailService, Logger)
-	employeeHandler := api.NewEmployeeHandler(userStore, emailService, requestStore, orgStore, Logger)
+	staffingHandler := api.NewStaffingHandler(userStore, orgStore, userRolesStore, rolesStore, uploadService, emailService, customFieldStore, Logger)
+	employeeHandler := api.NewEmployeeHandler(userStore, emailService, requestStore, orgStore, notificationStore, blackoutStore, approvalStore, Logger)
 	preferencesHandler := api.NewPreferencesHandler(preferencesStore, userRolesStore, userStore, rolesStore, Logger)
+	preferencesHandler.UploadService = uploadService
 	rulesHandler := api.NewRulesHandler(rulesStore, operatingHoursStore, Logger)
 	rolesHandler := api.NewRolesHandler(rolesStore, Logger)
 	insightHandler := api.NewInsightHandler(insightStore, Logger)
 	profileHandler := api.NewProfileHandler(userStore, Logger)
-	orderHandler := api.NewOrderHandler(orderStore, uploadService, Logger)
+	orderHandler := api.NewOrderHandler(orderStore, campaignStore, uploadService, ingestionRuleStore, statusStore, customFieldStore, rulesStore, Logger)
 	dashboardHandler := api.NewDashboardHandler(
 		orgStore,
 		rulesStore,
@@ -169,9 +322,12 @@ func NewServer(Logger *slog.Logger) *http.Server {
 		orderStore,
 		campaignStore,
 		demandStore,
+		externalEventStore,
+		operatingHoursExceptionStore,
+		statusStore,
 		Logger,
 	)
-	campaignHandler := api.NewCampaignHandler(campaignStore, uploadService, orderStore, orgStore, operatingHoursStore, rulesStore, Logger)
+	campaignHandler := api.NewCampaignHandler(campaignStore, uploadService, orderStore, orgStore, operatingHoursStore, rulesStore, itemAvailabilityStore, externalEventStore, statusStore, Logger)
 	surgeHandler := api.NewSurgeHandler(surgeStore, Logger)
 	scheduleHandler := api.NewScheduleHandler(
 		userStore,
@@ -186,8 +342,172 @@ func NewServer(Logger *slog.Logger) *http.Server {
 		demandStore,
 		rolesStore,
 		preferencesStore,
+		emailService,
+		applicantSessionStore,
+		operatingHoursExceptionStore,
+		statusStore,
+		complianceStore,
+		scheduleVersionStore,
 	)
 	offerHandler := api.NewOfferHandler(userStore, orgStore, offerStore, emailService, Logger)
+	announcementHandler := api.NewAnnouncementHandler(announcementStore, userStore, userRolesStore, rolesStore, emailService, Logger)
+	membershipHandler := api.NewMembershipHandler(membershipStore, userStore, Logger)
+	securityHandler := api.NewSecurityHandler(loginSecurityStore, auditStore, userStore, Logger)
+	occupancyHandler := api.NewOccupancyHandler(occupancyStore, externalEventStore, Logger)
+	waitTimeHandler := api.NewWaitTimeHandler(waitTimeStore, rulesStore, Logger)
+	prepListHandler := api.NewPrepListHandler(prepListStore, itemAvailabilityStore, orgStore, brandingStore, Logger)
+	availabilityHandler := api.NewItemAvailabilityHandler(itemAvailabilityStore, orderStore, Logger)
+	eventHandler := api.NewExternalEventHandler(externalEventStore, Logger)
+	jobPostingHandler := api.NewJobPostingHandler(jobPostingStore, rolesStore, Logger)
+	sessionHandler := api.NewApplicantSessionHandler(jobPostingStore, applicantSessionStore, userStore, emailService, Logger)
+	recordHandler := api.NewEmployeeRecordHandler(userStore, employeeRecordStore, Logger)
+	personalDataHandler := api.NewPersonalDataHandler(userStore, requestStore, preferencesStore, employeeRecordStore, customFieldStore, Logger)
+	closureHandler := api.NewOperatingHoursExceptionHandler(operatingHoursExceptionStore, scheduleStore, emailService, Logger)
+	statusHandler := api.NewStatusHandler(statusStore, Logger)
+	ingestionHandler := api.NewIngestionRuleHandler(ingestionRuleStore, Logger)
+	notificationHandler := api.NewNotificationSettingsHandler(notificationStore, Logger)
+	preferenceHandler := api.NewEmailPreferenceHandler(emailPreferenceStore, unsubscribeSigner, Logger)
+	brandingHandler := api.NewBrandingHandler(orgStore, brandingStore, Logger)
+	platformHandler := api.NewDeliveryPlatformHandler(deliveryPlatformStore, orderStore, statusStore, deadLetterStore, Logger)
+	deadLetterHandler := api.NewDeadLetterHandler(deadLetterStore, deliveryPlatformStore, platformHandler.ApplyPlatformEvents, Logger)
+	varianceHandler := api.NewForecastVarianceHandler(forecastVarianceStore, Logger)
+	acceptanceHandler := api.NewOrderAcceptanceHandler(orderAcceptanceStore, Logger)
+	mobileHandler := api.NewMobileHandler(mobileSummaryStore, scheduleStore, Logger)
+	complianceHandler := api.NewEmployeeComplianceHandler(userStore, complianceStore, Logger)
+	customFieldHandler := api.NewCustomFieldHandler(customFieldStore, Logger)
+	savedViewHandler := api.NewSavedViewHandler(savedViewStore, Logger)
+	itemPriceHandler := api.NewItemPriceHandler(itemPriceStore, orderStore, Logger)
+	webhookHandler := api.NewWebhookHandler(webhookStore, Logger)
+	ratingHandler := api.NewOrgRatingHandler(orgRatingStore, Logger)
+	kioskHandler := api.NewKioskHandler(kioskStore, Logger)
+	consolidationHandler := api.NewConsolidationHandler(membershipStore, orgStore, orderStore, scheduleStore, userStore,
+		service.NewExchangeRateProvider(exchangeRateStore, Logger), Logger)
+	staffOrderHandler := api.NewStaffOrderHandler(staffOrderStore, userStore, Logger)
+	handoverHandler := api.NewHandoverHandler(orderStore, scheduleStore, requestStore, shiftNoteStore, Logger)
+	statusBoardHandler := api.NewStatusBoardHandler(statusBoardStore, scheduleStore, orderStore, Logger)
+	orderSheetHandler := api.NewOrderSheetHandler(orderSheetStore, orderStore, campaignStore, ingestionRuleStore, statusStore, Logger)
+	orderImportHandler := api.NewOrderImportHandler(orderImportStore, uploadService, ingestionRuleStore, Logger)
+	analyticsHandler := api.NewAnalyticsHandler(staffingAnalyticsStore, rulesStore, Logger)
+	skillHandler := api.NewSkillHandler(skillStore, rolesStore, orgStore, emailService, Logger)
+
+	// Only schedule employees in the roles whose required skills they have verified
+	scheduleHandler.SkillStore = skillStore
+
+	// Schedule new hires in training on top of the staff needed, out of the roles worked alone
+	trainingHandler := api.NewTrainingHandler(userStore, trainingStore, Logger)
+	scheduleHandler.TrainingStore = trainingStore
+	orgHandler.Trainings = trainingStore
+
+	// Track the revenue, labor cost and rating of the organization against its targets
+	kpiTracker := service.NewKPITracker(kpiTargetStore, scheduleStore, userStore)
+	kpiTargetHandler := api.NewKPITargetHandler(kpiTargetStore, kpiTracker, Logger)
+	insightHandler.KPIs = kpiTracker
+	consolidationHandler.KPIs = kpiTracker
+
+	// Queue the scheduling events of organizations for their webhooks
+	webhookService := service.NewWebhookService(webhookStore, Logger)
+	scheduleHandler.Webhooks = webhookService
+	offerHandler.Webhooks = webhookService
+	employeeHandler.Webhooks = webhookService
+
+	// Offer and termination letters of the onboarding and layoff flows, signed with an emailed link
+	documentService := service.NewDocumentService(employeeDocumentStore, emailService, Logger)
+	documentHandler := api.NewEmployeeDocumentHandler(documentService, userStore, orgStore, brandingStore, Logger)
+	orgHandler.Documents = documentService
+	employeeHandler.Documents = documentService
+
+	// Route the request notifications along the rules of the organizations, by email or SMS
+	smsService := service.NewHTTPSMSService(Logger, cfg.Secrets)
+	notificationRouter := api.NewNotificationRouter(notificationRoutingStore, userStore, scheduleStore, smsService, Logger)
+	routingHandler := api.NewNotificationRoutingHandler(notificationRouter, Logger)
+	employeeHandler.Router = notificationRouter
+
+	// Messages of the admins to filtered groups of the staff, by email or SMS
+	broadcastHandler := api.NewBroadcastHandler(broadcastStore, userStore, userRolesStore, rolesStore, scheduleStore, customFieldStore, emailService, smsService, Logger)
+
+	// Lock accounts after repeated failed logins and alert users of logins from new devices
+	loginGuard := middleware.NewLoginGuard(loginSecurityStore, auditStore, emailService, Logger)
+
+	// Passwordless login with emailed links, for the organizations that enable it
+	magicLinkHandler := api.NewMagicLinkHandler(magicLinkStore, userStore, orgStore, emailService, Logger)
+	magicLinkHandler.LoginGuard = loginGuard
+
+	// Re-authentication before layoffs, member removals and other sensitive actions
+	stepUpGuard := middleware.NewStepUpGuard(Logger)
+	stepUpHandler := api.NewStepUpHandler(userStore, totpStore, auditStore, stepUpGuard, Logger)
+	stepUpHandler.LoginGuard = loginGuard
+
+	// Keep repeated clicks on generate from queueing ML solves
+	mlGuard := middleware.NewMLGuard(Logger)
+
+	// Remind employees who have not acknowledged their published shifts
+	shiftReminderService := service.NewShiftReminderService(scheduleStore, emailService, Logger)
+	go shiftReminderService.Start(context.Background())
+
+	// Email employees their shifts of the coming week on Sunday evening
+	weeklyScheduleEmailService := service.NewWeeklyScheduleEmailService(scheduleStore, userStore, emailService, Logger)
+	go weeklyScheduleEmailService.Start(context.Background())
+
+	// Email admins the late cancellations and predictability pay of the past week on Monday morning
+	complianceReportService := service.NewComplianceReportService(scheduleStore, rulesStore, userStore, orgStore, exportEncryptionStore, emailService, Logger)
+	go complianceReportService.Start(context.Background())
+
+	// Purge employee records once their retention has ended
+	recordRetentionService := service.NewRecordRetentionService(employeeRecordStore, Logger)
+	go recordRetentionService.Start(context.Background())
+
+	// Email the hourly and daily notification digests
+	notificationDigestService := service.NewNotificationDigestService(notificationStore, emailService, Logger)
+	go notificationDigestService.Start(context.Background())
+
+	// Fetch the orders of delivery platforms that do not send webhooks
+	deliveryPlatformPoller := service.NewDeliveryPlatformPoller(deliveryPlatformStore, deadLetterStore, platformHandler.ApplyPlatformEvents, Logger)
+	go deliveryPlatformPoller.Start(context.Background())
+
+	// Import the new rows of the order logs organizations keep in Google Sheets
+	orderSheetSyncer := service.NewOrderSheetSyncer(orderSheetStore, orderSheetHandler.ApplySheetRows, Logger)
+	orderSheetHandler.Syncer = orderSheetSyncer
+	go orderSheetSyncer.Start(context.Background())
+
+	// Tell the managers on duty when the orders of the day drift from the demand forecast
+	forecastVarianceService := service.NewForecastVarianceService(forecastVarianceStore, orgStore, emailService, Logger)
+	go forecastVarianceService.Start(context.Background())
+
+	// Pause orders while the demand of the hour runs past what the staff on shift can handle
+	orderAcceptanceService := service.NewOrderAcceptanceService(orderAcceptanceStore, orgStore, emailService, Logger)
+	go orderAcceptanceService.Start(context.Background())
+
+	// Tell the managers when too many deliveries of the day take longer than the delivery SLA
+	deliverySLAService := service.NewDeliverySLAService(deliverySLAStore, orgStore, emailService, Logger)
+	go deliverySLAService.Start(context.Background())
+
+	// Graduate the new hires whose training period ended and tell their managers
+	trainingService := service.NewTrainingService(trainingStore, orgStore, emailService, Logger)
+	go trainingService.Start(context.Background())
+
+	// Email the admins how the past week did against the KPI targets on Monday morning
+	kpiReportService := service.NewKPIReportService(kpiTracker, orgStore, emailService, Logger)
+	go kpiReportService.Start(context.Background())
+
+	// Alert employees and admins before national IDs and work permits expire
+	documentExpiryService := service.NewDocumentExpiryService(complianceStore, orgStore, emailService, Logger)
+	go documentExpiryService.Start(context.Background())
+
+	// Remind employees and managers before verified certifications expire
+	skillExpiryService := service.NewSkillExpiryService(skillStore, orgStore, emailService, Logger)
+	go skillExpiryService.Start(context.Background())
+
+	// Post the queued webhook deliveries, retrying failed ones
+	go webhookService.Start(context.Background())
+
+	// Produce the queued exports to the export storage and email their download links
+	exportService := service.NewExportService(exportJobStore, orderStore, exportEncryptionStore, service.NewExportStorage(Logger), emailService, Logger)
+	exportHandler := api.NewExportHandler(exportJobStore, exportEncryptionStore, exportService, Logger)
+	go exportService.Start(context.Background())
+
+	// Recompute the organization ratings from the ratings of recent orders
+	orgRatingService := service.NewOrgRatingService(orgRatingStore, Logger)
+	go orgRatingService.Start(context.Background())
 
 	NewServer := &Server{
 		port: port,
@@ -203,21 +523,77 @@ func NewServer(Logger *slog.Logger) *http.Server {
 		demandStore:      demandStore,
 		scheduleStore:    scheduleStore,
 		surgeStore:       surgeStore,
+		membershipStore:  membershipStore,
+		kioskStore:       kioskStore,
+
+		fileScanner: fileScanner,
+		loginGuard:  loginGuard,
+		mlGuard:     mlGuard,
+		stepUpGuard: stepUpGuard,
+		cors:        cfg.CORS,
+		csrf:        cfg.CSRF,
+		versions:    cfg.Versions,
 
-		orgHandler:         orgHandler,
-		staffingHandler:    staffingHandler,
-		employeeHandler:    employeeHandler,
-		preferencesHandler: preferencesHandler,
-		rulesHandler:       rulesHandler,
-		rolesHandler:       rolesHandler,
-		insightHandler:     insightHandler,
-		profileHandler:     profileHandler,
-		orderHandler:       orderHandler,
-		dashboardHandler:   dashboardHandler,
-		scheduleHandler:    scheduleHandler,
-		campaignHandler:    campaignHandler,
-		offerHandler:       offerHandler,
-		surgeHandler:       surgeHandler,
+		orgHandler:           orgHandler,
+		staffingHandler:      staffingHandler,
+		employeeHandler:      employeeHandler,
+		preferencesHandler:   preferencesHandler,
+		rulesHandler:         rulesHandler,
+		rolesHandler:         rolesHandler,
+		insightHandler:       insightHandler,
+		profileHandler:       profileHandler,
+		orderHandler:         orderHandler,
+		dashboardHandler:     dashboardHandler,
+		scheduleHandler:      scheduleHandler,
+		campaignHandler:      campaignHandler,
+		offerHandler:         offerHandler,
+		surgeHandler:         surgeHandler,
+		announcementHandler:  announcementHandler,
+		membershipHandler:    membershipHandler,
+		securityHandler:      securityHandler,
+		occupancyHandler:     occupancyHandler,
+		waitTimeHandler:      waitTimeHandler,
+		prepListHandler:      prepListHandler,
+		availabilityHandler:  availabilityHandler,
+		eventHandler:         eventHandler,
+		jobPostingHandler:    jobPostingHandler,
+		sessionHandler:       sessionHandler,
+		recordHandler:        recordHandler,
+		personalDataHandler:  personalDataHandler,
+		closureHandler:       closureHandler,
+		statusHandler:        statusHandler,
+		ingestionHandler:     ingestionHandler,
+		notificationHandler:  notificationHandler,
+		preferenceHandler:    preferenceHandler,
+		brandingHandler:      brandingHandler,
+		platformHandler:      platformHandler,
+		deadLetterHandler:    deadLetterHandler,
+		varianceHandler:      varianceHandler,
+		acceptanceHandler:    acceptanceHandler,
+		mobileHandler:        mobileHandler,
+		trainingHandler:      trainingHandler,
+		kpiTargetHandler:     kpiTargetHandler,
+		complianceHandler:    complianceHandler,
+		customFieldHandler:   customFieldHandler,
+		savedViewHandler:     savedViewHandler,
+		itemPriceHandler:     itemPriceHandler,
+		webhookHandler:       webhookHandler,
+		ratingHandler:        ratingHandler,
+		kioskHandler:         kioskHandler,
+		consolidationHandler: consolidationHandler,
+		staffOrderHandler:    staffOrderHandler,
+		documentHandler:      documentHandler,
+		handoverHandler:      handoverHandler,
+		routingHandler:       routingHandler,
+		magicLinkHandler:     magicLinkHandler,
+		stepUpHandler:        stepUpHandler,
+		statusBoardHandler:   statusBoardHandler,
+		orderSheetHandler:    orderSheetHandler,
+		analyticsHandler:     analyticsHandler,
+		skillHandler:         skillHandler,
+		broadcastHandler:     broadcastHandler,
+		orderImportHandler:   orderImportHandler,
+		exportHandler:        exportHandler,
 
 		Logger: Logger,
 	}
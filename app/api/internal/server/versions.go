@@ -0,0 +1,33 @@
+package server
+
+import (
+	"github.com/clockwise/clockwise/backend/internal/api"
+	"github.com/clockwise/clockwise/backend/internal/middleware"
+)
+
+// apiVersions are the versions of the API. The unversioned /api routes are kept as deprecated aliases
+// of v1 for the integrations written before versioning.
+func (s *Server) apiVersions() []middleware.APIVersion {
+	return []middleware.APIVersion{
+		{
+			Name:       "v1",
+			Prefix:     "/api",
+			Successor:  "/api/v1",
+			Deprecated: s.versions.LegacyDeprecated,
+			Sunset:     s.versions.LegacySunset,
+			Shims:      api.V1Shims,
+		},
+		{
+			Name:       "v1",
+			Prefix:     "/api/v1",
+			Successor:  "/api/v2",
+			Deprecated: s.versions.V1Deprecated,
+			Sunset:     s.versions.V1Sunset,
+			Shims:      api.V1Shims,
+		},
+		{
+			Name:   "v2",
+			Prefix: "/api/v2",
+		},
+	}
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+)
+
+const (
+	defaultComplianceReportInterval = time.Hour
+	defaultComplianceReportHour     = 8
+)
+
+// ComplianceReportService emails the admins of the organizations subject to predictable scheduling a
+// report of the past week on Monday morning: the late shift cancellations and the predictability pay owed
+// for the schedule changes made within the notice period. Each organization gets one report per week.
+// Organizations that encrypt their exports get the owed pay per employee as an encrypted attachment
+// rather than in the body of the email.
+type ComplianceReportService struct {
+	ScheduleStore database.ScheduleStore
+	RulesStore    database.RulesStore
+	UserStore     database.UserStore
+	OrgStore      database.OrgStore
+	// Encryption is optional, without it every report is sent in the body of the email
+	Encryption   database.ExportEncryptionStore
+	EmailService EmailService
+	Logger       *slog.Logger
+
+	// Interval is how often it is checked whether the reports are due
+	Interval time.Duration
+	// SendHour is the hour of Monday from which the reports are sent
+	SendHour int
+}
+
+// NewComplianceReportService reads COMPLIANCE_REPORT_INTERVAL (a Go duration) and COMPLIANCE_REPORT_HOUR
+// (0-23) and falls back to hourly checks from 08:00
+func NewComplianceReportService(scheduleStore database.ScheduleStore, rulesStore database.RulesStore, userStore database.UserStore, orgStore database.OrgStore, encryption database.ExportEncryptionStore, emailService EmailService, Logger *slog.Logger) *ComplianceReportService {
+	sendHour := defaultComplianceReportHour
+	if value := os.Getenv("COMPLIANCE_REPORT_HOUR"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed >= 0 && parsed < 24 {
+			sendHour = parsed
+		} else {
+			Logger.Warn("invalid hour in environment, using default", "key", "COMPLIANCE_REPORT_HOUR", "value", value, "default", sendHour)
+		}
+	}
+
+	return &ComplianceReportService{
+		ScheduleStore: scheduleStore,
+		RulesStore:    rulesStore,
+		UserStore:     userStore,
+		OrgStore:      orgStore,
+		Encryption:    encryption,
+		EmailService:  emailService,
+		Logger:        Logger,
+		Interval:      durationFromEnv("COMPLIANCE_REPORT_INTERVAL", defaultComplianceReportInterval, Logger),
+		SendHour:      sendHour,
+	}
+}
+
+// Start checks every Interval whether the weekly reports are due until the context is cancelled
+func (s *ComplianceReportService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.Logger.Info("compliance report service started", "interval", s.Interval, "send_hour", s.SendHour)
+	for {
+		select {
+		case <-ctx.Done():
+			s.Logger.Info("compliance report service stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.SendReports(time.Now()); err != nil {
+				s.Logger.Error("failed to send compliance reports", "error", err)
+			}
+		}
+	}
+}
+
+// SendReports emails the reports of the week that ended yesterday when now is Monday from SendHour, and
+// returns how many organizations were sent one. Organizations whose report failed are retried in the
+// next round.
+func (s *ComplianceReportService) SendReports(now time.Time) (int, error) {
+	if now.Weekday() != time.Monday || now.Hour() < s.SendHour {
+		return 0, nil
+	}
+	weekStart := time.Date(now.Year(), now.Month(), now.Day()-7, 0, 0, 0, 0, now.Location())
+	weekEnd := weekStart.AddDate(0, 0, 7)
+
+	orgs, err := s.ScheduleStore.GetOrganizationsForComplianceReport(weekStart)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	weekOf := weekStart.Format("Jan 2, 2006")
+	late := true
+	for _, orgID := range orgs {
+		rules, err := s.RulesStore.GetRulesByOrganizationID(orgID)
+		if err != nil {
+			s.Logger.Error("failed to get rules for compliance report", "error", err, "org_id", orgID)
+			continue
+		}
+		policy, ok := PredictabilityPolicyFromRules(rules)
+		if !ok {
+			continue
+		}
+
+		report, err := PredictabilityPay(s.ScheduleStore, s.UserStore, orgID, policy, weekStart, weekEnd)
+		if err != nil {
+			s.Logger.Error("failed to compute predictability pay", "error", err, "org_id", orgID)
+			continue
+		}
+		cancellations, err := s.ScheduleStore.GetShiftCancellations(orgID, database.CancellationFilter{From: &weekStart, To: &weekEnd, Late: &late})
+		if err != nil {
+			s.Logger.Error("failed to get late cancellations for compliance report", "error", err, "org_id", orgID)
+			continue
+		}
+		admins, err := s.OrgStore.GetAdminEmailsByOrgID(orgID)
+		if err != nil {
+			s.Logger.Error("failed to get admin emails", "error", err, "org_id", orgID)
+			continue
+		}
+
+		var encryption *database.ExportEncryption
+		if s.Encryption != nil {
+			if encryption, err = s.Encryption.GetExportEncryption(orgID); err != nil {
+				continue
+			}
+		}
+		if encryption != nil {
+			attachment, err := complianceReportAttachment(encryption, weekStart, report)
+			if err != nil {
+				s.Logger.Error("failed to encrypt compliance report", "error", err, "org_id", orgID)
+				continue
+			}
+			err = s.EmailService.SendEncryptedComplianceReportEmail(admins, weekOf, len(cancellations), *attachment)
+		} else {
+			err = s.EmailService.SendComplianceReportEmail(admins, weekOf, len(cancellations), summarizePredictabilityPay(report),
+				report.OwedHours, report.OwedPay)
+		}
+		if err != nil {
+			s.Logger.Error("failed to send compliance report email", "error", err, "org_id", orgID)
+			continue
+		}
+		if err := s.ScheduleStore.MarkComplianceReportSent(orgID, weekStart); err != nil {
+			s.Logger.Error("failed to record compliance report", "error", err, "org_id", orgID)
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		s.Logger.Info("compliance reports sent", "organizations", sent, "week_start", weekStart.Format(time.DateOnly))
+	}
+	return sent, nil
+}
+
+// summarizePredictabilityPay returns a line per employee owed predictability pay
+func summarizePredictabilityPay(report *PredictabilityPayReport) []string {
+	lines := make([]string, 0, len(report.Employees))
+	for _, employee := range report.Employees {
+		line := fmt.Sprintf("%s: %d changes, %.2f hours", employee.EmployeeName, employee.Changes, employee.OwedHours)
+		if employee.OwedPay != nil {
+			line += fmt.Sprintf(" ($%.2f)", *employee.OwedPay)
+		} else {
+			line += " (no hourly salary)"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// complianceReportAttachment returns the owed pay per employee as a CSV encrypted for the organization
+func complianceReportAttachment(encryption *database.ExportEncryption, weekStart time.Time, report *PredictabilityPayReport) (*EmailAttachment, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"employee", "changes", "owed_hours", "owed_pay"})
+	for _, employee := range report.Employees {
+		writer.Write([]string{
+			employee.EmployeeName,
+			strconv.Itoa(employee.Changes),
+			strconv.FormatFloat(employee.OwedHours, 'f', 2, 64),
+			exportFloat(employee.OwedPay),
+		})
+	}
+	writer.Write([]string{"total", "", strconv.FormatFloat(report.OwedHours, 'f', 2, 64), strconv.FormatFloat(report.OwedPay, 'f', 2, 64)})
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+
+	fileName := "compliance-report-" + weekStart.Format(time.DateOnly) + ".csv"
+	data, err := EncryptFile(encryption, fileName, buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return &EmailAttachment{FileName: fileName + EncryptedFileExtension, ContentType: EncryptedContentType, Data: data}, nil
+}
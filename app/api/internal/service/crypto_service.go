@@ -0,0 +1,144 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// encryptedValuePrefix marks values sealed by the CryptoService, followed by "<key id>:<base64 nonce+ciphertext>"
+const encryptedValuePrefix = "enc:"
+
+var (
+	ErrNoEncryptionKeys    = errors.New("no encryption keys configured")
+	ErrUnknownKeyID        = errors.New("value was encrypted with an unknown key")
+	ErrMalformedCiphertext = errors.New("malformed encrypted value")
+)
+
+// CryptoService encrypts sensitive columns with AES-256-GCM. It holds every key of the keyring so values
+// written with a retired key stay readable, while new values are always sealed with the active key.
+type CryptoService struct {
+	keys        map[string]cipher.AEAD
+	activeKeyID string
+}
+
+// NewCryptoService builds a CryptoService from a keyring such as "2025:<base64 key>,2026:<base64 key>".
+// Keys must decode to 32 bytes. activeKeyID defaults to the last key of the keyring.
+func NewCryptoService(keyring string, activeKeyID string) (*CryptoService, error) {
+	cs := &CryptoService{keys: make(map[string]cipher.AEAD)}
+
+	var lastKeyID string
+	for _, entry := range strings.Split(keyring, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		keyID, encodedKey, found := strings.Cut(entry, ":")
+		if !found || keyID == "" {
+			return nil, fmt.Errorf("encryption key entries must look like <key id>:<base64 key>")
+		}
+		key, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %s is not valid base64: %w", keyID, err)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("encryption key %s must be 32 bytes, got %d", keyID, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		cs.keys[keyID] = aead
+		lastKeyID = keyID
+	}
+
+	if len(cs.keys) == 0 {
+		return nil, ErrNoEncryptionKeys
+	}
+
+	cs.activeKeyID = lastKeyID
+	if activeKeyID != "" {
+		if _, ok := cs.keys[activeKeyID]; !ok {
+			return nil, fmt.Errorf("active encryption key %s is not in the keyring", activeKeyID)
+		}
+		cs.activeKeyID = activeKeyID
+	}
+	return cs, nil
+}
+
+// NewCryptoServiceFromEnv reads the keyring from ENCRYPTION_KEYS, or from the file at ENCRYPTION_KEYS_FILE
+// when the keys are mounted by a KMS or secret manager, and the active key from ENCRYPTION_ACTIVE_KEY.
+// It returns ErrNoEncryptionKeys when neither is set.
+func NewCryptoServiceFromEnv(logger *slog.Logger) (*CryptoService, error) {
+	keyring := os.Getenv("ENCRYPTION_KEYS")
+	if path := os.Getenv("ENCRYPTION_KEYS_FILE"); keyring == "" && path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read encryption keys file: %w", err)
+		}
+		keyring = strings.ReplaceAll(strings.TrimSpace(string(data)), "\n", ",")
+	}
+	if keyring == "" {
+		return nil, ErrNoEncryptionKeys
+	}
+
+	cs, err := NewCryptoService(keyring, os.Getenv("ENCRYPTION_ACTIVE_KEY"))
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("column encryption enabled", "keys", len(cs.keys), "active_key", cs.activeKeyID)
+	return cs, nil
+}
+
+// Encrypt seals a value with the active key
+func (cs *CryptoService) Encrypt(plaintext string) (string, error) {
+	aead := cs.keys[cs.activeKeyID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encryptedValuePrefix + cs.activeKeyID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value sealed with any key of the keyring.
+// Values written before encryption was enabled are returned unchanged.
+func (cs *CryptoService) Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedValuePrefix) {
+		return value, nil
+	}
+
+	keyID, encoded, found := strings.Cut(strings.TrimPrefix(value, encryptedValuePrefix), ":")
+	if !found {
+		return "", ErrMalformedCiphertext
+	}
+	aead, ok := cs.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKeyID, keyID)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", ErrMalformedCiphertext
+	}
+
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value sealed with key %s: %w", keyID, err)
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRotation reports whether a value is still plaintext or was sealed with a key other than the active one
+func (cs *CryptoService) NeedsRotation(value string) bool {
+	return !strings.HasPrefix(value, encryptedValuePrefix+cs.activeKeyID+":")
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+)
+
+const (
+	defaultDeliveryPlatformPollInterval = 2 * time.Minute
+	// How far back the first poll of an integration looks
+	firstPollWindow = time.Hour
+	// Largest poll response read, in bytes
+	maxPollResponseBytes = 10 << 20
+)
+
+// DeliveryPlatformPoller periodically fetches the orders and status updates of the integrations that
+// have an API URL, for platforms or stores that do not send webhooks
+type DeliveryPlatformPoller struct {
+	Store           database.DeliveryPlatformStore
+	DeadLetterStore database.DeadLetterStore
+	Client          *http.Client
+	Logger          *slog.Logger
+
+	// Interval is how often the integrations are polled
+	Interval time.Duration
+	// Apply stores the events polled from an integration, as the webhooks do
+	Apply func(integration database.DeliveryPlatformIntegration, events []PlatformEvent) PlatformSyncResult
+}
+
+// NewDeliveryPlatformPoller reads DELIVERY_PLATFORM_POLL_INTERVAL (a Go duration, e.g. "5m") and falls back
+// to polling every 2 minutes. Its client refuses to connect to loopback and private addresses.
+func NewDeliveryPlatformPoller(store database.DeliveryPlatformStore, deadLetterStore database.DeadLetterStore, apply func(database.DeliveryPlatformIntegration, []PlatformEvent) PlatformSyncResult, Logger *slog.Logger) *DeliveryPlatformPoller {
+	return &DeliveryPlatformPoller{
+		Store:           store,
+		DeadLetterStore: deadLetterStore,
+		Client:          publicHTTPClient(30 * time.Second),
+		Logger:          Logger,
+		Interval:        durationFromEnv("DELIVERY_PLATFORM_POLL_INTERVAL", defaultDeliveryPlatformPollInterval, Logger),
+		Apply:           apply,
+	}
+}
+
+// Start polls every Interval until the context is cancelled
+func (p *DeliveryPlatformPoller) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	p.Logger.Info("delivery platform poller started", "interval", p.Interval)
+	for {
+		select {
+		case <-ctx.Done():
+			p.Logger.Info("delivery platform poller stopped")
+			return
+		case <-ticker.C:
+			if _, err := p.PollOnce(ctx, time.Now()); err != nil {
+				p.Logger.Error("failed to poll delivery platforms", "error", err)
+			}
+		}
+	}
+}
+
+// PollOnce polls every integration once and returns how many were polled successfully. An integration
+// whose events could not all be stored is polled again from the same point next time, one whose response
+// could not be read is dead-lettered and moves on.
+func (p *DeliveryPlatformPoller) PollOnce(ctx context.Context, now time.Time) (int, error) {
+	integrations, err := p.Store.GetPolledIntegrations()
+	if err != nil {
+		return 0, err
+	}
+
+	polled := 0
+	for _, integration := range integrations {
+		connector, ok := DeliveryConnectors[integration.Platform]
+		if !ok {
+			continue
+		}
+
+		since := now.Add(-firstPollWindow)
+		if integration.LastPolledAt != nil {
+			since = *integration.LastPolledAt
+		}
+		body, err := p.fetch(ctx, integration, since)
+		if err != nil {
+			p.Logger.Error("failed to poll delivery platform", "error", err, "organization_id", integration.OrganizationID, "platform", integration.Platform)
+			continue
+		}
+		events, err := connector.Parse(body)
+		if err != nil {
+			p.Logger.Error("failed to read delivery platform poll", "error", err, "organization_id", integration.OrganizationID, "platform", integration.Platform)
+			// once dead-lettered the next poll moves on, polling the same orders again would fail the same way
+			letter := &database.DeadLetter{Source: integration.Platform, Via: database.DeadLetterViaPoll, Payload: string(body), Error: err.Error()}
+			if p.DeadLetterStore.CreateDeadLetter(integration.OrganizationID, letter) != nil {
+				continue
+			}
+		} else if result := p.Apply(integration, events); result.Failed > 0 {
+			p.Logger.Warn("delivery platform events failed, polling again", "organization_id", integration.OrganizationID, "platform", integration.Platform, "failed", result.Failed)
+			continue
+		}
+		if err := p.Store.MarkPolled(integration.OrganizationID, integration.Platform, now); err != nil {
+			continue
+		}
+		polled++
+	}
+	return polled, nil
+}
+
+// fetch asks the platform for the orders changed since the last poll
+func (p *DeliveryPlatformPoller) fetch(ctx context.Context, integration database.DeliveryPlatformIntegration, since time.Time) ([]byte, error) {
+	endpoint, err := url.Parse(*integration.APIURL)
+	if err != nil {
+		return nil, err
+	}
+	query := endpoint.Query()
+	query.Set("store_id", integration.StoreID)
+	query.Set("since", since.UTC().Format(time.RFC3339))
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if integration.APIToken != nil {
+		req.Header.Set("Authorization", "Bearer "+*integration.APIToken)
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("platform responded with status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxPollResponseBytes))
+}
@@ -0,0 +1,458 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidPlatformPayload = errors.New("invalid delivery platform payload")
+
+// PlatformEvent is an order or a status update sent by a delivery platform, in ClockWise terms
+type PlatformEvent struct {
+	// ExternalOrderID is the ID of the order on the platform
+	ExternalOrderID string
+	// Order is set when the event carries the whole order, status updates only carry the statuses
+	Order *PlatformOrder
+	// OrderStatus is completed or incompleted
+	OrderStatus string
+	// Delivery is set once the order has left the restaurant
+	Delivery   *PlatformDelivery
+	OccurredAt time.Time
+}
+
+type PlatformOrder struct {
+	CreateTime time.Time
+	// OrderType is delivery, or takeaway for orders collected by the customer
+	OrderType      string
+	TotalAmount    float64
+	DiscountAmount float64
+	Items          []PlatformOrderItem
+}
+
+type PlatformOrderItem struct {
+	// Reference is the merchant's own reference of the item on the platform, matched against item IDs
+	Reference  string
+	Name       string
+	Quantity   int
+	TotalPrice float64
+}
+
+type PlatformDelivery struct {
+	// Status is out for delivery, delivered or not delivered
+	Status             string
+	OutForDeliveryTime time.Time
+	DeliveredTime      time.Time
+	Latitude           *float64
+	Longitude          *float64
+}
+
+// PlatformSyncResult counts what happened to the events of a webhook or a poll
+type PlatformSyncResult struct {
+	Orders         int `json:"orders"`
+	Updates        int `json:"updates"`
+	UnknownOrders  int `json:"unknown_orders"`
+	UnmatchedItems int `json:"unmatched_items"`
+	Failed         int `json:"failed"`
+}
+
+// DeliveryConnector reads the webhooks of a delivery platform
+type DeliveryConnector interface {
+	// Verify checks the webhook was signed by the platform with the secret of the integration
+	Verify(header http.Header, body []byte, secret string) bool
+	// Parse normalizes a webhook payload, or a JSON array of payloads as returned when polling. Events
+	// that do not concern orders are skipped.
+	Parse(body []byte) ([]PlatformEvent, error)
+}
+
+// DeliveryConnectors are the platforms organizations can connect to, keyed by order channel
+var DeliveryConnectors = map[string]DeliveryConnector{
+	database.OrderChannelUberEats:  UberEatsConnector{},
+	database.OrderChannelDeliveroo: DeliverooConnector{},
+	database.OrderChannelTalabat:   TalabatConnector{},
+}
+
+// PlatformOrderID is the ClockWise ID of an order of a platform. It is derived from the platform's ID so
+// the same order sent twice, or polled after its webhook, is stored once.
+func PlatformOrderID(orgID uuid.UUID, platform, externalOrderID string) uuid.UUID {
+	return uuid.NewSHA1(orgID, []byte(platform+":"+externalOrderID))
+}
+
+// splitPayloads returns the payloads of a body holding one payload or a JSON array of them
+func splitPayloads(body []byte) ([]json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var payloads []json.RawMessage
+		if err := json.Unmarshal(trimmed, &payloads); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPlatformPayload, err)
+		}
+		return payloads, nil
+	}
+	return []json.RawMessage{trimmed}, nil
+}
+
+func parsePayloads[T any](body []byte, normalize func(payload T) *PlatformEvent) ([]PlatformEvent, error) {
+	payloads, err := splitPayloads(body)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []PlatformEvent{}
+	for _, raw := range payloads {
+		var payload T
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPlatformPayload, err)
+		}
+		event := normalize(payload)
+		if event == nil {
+			continue
+		}
+		if event.ExternalOrderID == "" {
+			return nil, fmt.Errorf("%w: missing order ID", ErrInvalidPlatformPayload)
+		}
+		if event.OccurredAt.IsZero() {
+			event.OccurredAt = time.Now()
+		}
+		events = append(events, *event)
+	}
+	return events, nil
+}
+
+// verifyHMAC checks a hex encoded HMAC-SHA256 of the message
+func verifyHMAC(signature string, message []byte, secret string) bool {
+	expected, err := hex.DecodeString(strings.TrimSpace(signature))
+	if err != nil || len(expected) == 0 || secret == "" {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(message)
+	return hmac.Equal(expected, mac.Sum(nil))
+}
+
+// deliveryFor returns the delivery of an order whose courier reached status at the given time
+func deliveryFor(status string, at time.Time, latitude, longitude *float64) *PlatformDelivery {
+	if status == "" {
+		return nil
+	}
+	delivery := &PlatformDelivery{Status: status, OutForDeliveryTime: at, Latitude: latitude, Longitude: longitude}
+	if status == "delivered" {
+		delivery.DeliveredTime = at
+	}
+	return delivery
+}
+
+// orderStatusFor is completed once the order reached the customer
+func orderStatusFor(delivered bool) string {
+	if delivered {
+		return "completed"
+	}
+	return "incompleted"
+}
+
+// UberEatsConnector reads Uber Eats webhooks, signed in X-Uber-Signature with the client secret of the
+// store. Amounts are in minor units.
+type UberEatsConnector struct{}
+
+type uberEatsPayload struct {
+	EventType string    `json:"event_type"`
+	EventTime time.Time `json:"event_time"`
+	Order     struct {
+		ID       string    `json:"id"`
+		Type     string    `json:"type"`
+		State    string    `json:"state"`
+		PlacedAt time.Time `json:"placed_at"`
+		Payment  struct {
+			Total    int64 `json:"total"`
+			Discount int64 `json:"discount"`
+		} `json:"payment"`
+		Items []struct {
+			ExternalID string `json:"external_id"`
+			Title      string `json:"title"`
+			Quantity   int    `json:"quantity"`
+			Price      int64  `json:"price"`
+		} `json:"items"`
+		Delivery *struct {
+			Status   string `json:"status"`
+			Location struct {
+				Latitude  *float64 `json:"latitude"`
+				Longitude *float64 `json:"longitude"`
+			} `json:"location"`
+		} `json:"delivery"`
+	} `json:"order"`
+}
+
+func (UberEatsConnector) Verify(header http.Header, body []byte, secret string) bool {
+	return verifyHMAC(header.Get("X-Uber-Signature"), body, secret)
+}
+
+func (UberEatsConnector) Parse(body []byte) ([]PlatformEvent, error) {
+	return parsePayloads(body, func(payload uberEatsPayload) *PlatformEvent {
+		order := payload.Order
+		event := &PlatformEvent{ExternalOrderID: order.ID, OccurredAt: payload.EventTime}
+
+		var deliveryStatus string
+		var latitude, longitude *float64
+		if order.Delivery != nil {
+			switch order.Delivery.Status {
+			case "EN_ROUTE_TO_DROPOFF", "ARRIVED_AT_DROPOFF":
+				deliveryStatus = "out for delivery"
+			case "COMPLETED":
+				deliveryStatus = "delivered"
+			case "FAILED":
+				deliveryStatus = "not delivered"
+			}
+			latitude, longitude = order.Delivery.Location.Latitude, order.Delivery.Location.Longitude
+		}
+		event.Delivery = deliveryFor(deliveryStatus, event.OccurredAt, latitude, longitude)
+		// picked up orders are done once the restaurant finishes them
+		event.OrderStatus = orderStatusFor(deliveryStatus == "delivered" || (order.Type == "PICK_UP" && order.State == "FINISHED"))
+
+		switch payload.EventType {
+		case "orders.notification":
+			orderType := "delivery"
+			if order.Type == "PICK_UP" {
+				orderType = "takeaway"
+			}
+			placed := &PlatformOrder{
+				CreateTime:     order.PlacedAt,
+				OrderType:      orderType,
+				TotalAmount:    float64(order.Payment.Total) / 100,
+				DiscountAmount: float64(order.Payment.Discount) / 100,
+			}
+			for _, item := range order.Items {
+				placed.Items = append(placed.Items, PlatformOrderItem{
+					Reference:  item.ExternalID,
+					Name:       item.Title,
+					Quantity:   item.Quantity,
+					TotalPrice: float64(item.Price) / 100,
+				})
+			}
+			event.Order = placed
+		case "orders.status_changed", "delivery.state_changed":
+		default:
+			return nil
+		}
+		return event
+	})
+}
+
+// DeliverooConnector reads Deliveroo webhooks, signed in X-Deliveroo-Hmac-Sha256 over the sequence GUID
+// header, a space and the body. Amounts are in minor units.
+type DeliverooConnector struct{}
+
+type deliverooPayload struct {
+	Event string `json:"event"`
+	Body  struct {
+		Order struct {
+			ID          string    `json:"id"`
+			Status      string    `json:"status"`
+			Fulfillment string    `json:"fulfillment_type"`
+			CreatedAt   time.Time `json:"created_at"`
+			UpdatedAt   time.Time `json:"updated_at"`
+			TotalPrice  struct {
+				Fractional int64 `json:"fractional"`
+			} `json:"total_price"`
+			OfferDiscount struct {
+				Fractional int64 `json:"fractional"`
+			} `json:"offer_discount"`
+			Items []struct {
+				PosItemID  string `json:"pos_item_id"`
+				Name       string `json:"name"`
+				Quantity   int    `json:"quantity"`
+				TotalPrice struct {
+					Fractional int64 `json:"fractional"`
+				} `json:"total_price"`
+			} `json:"items"`
+		} `json:"order"`
+		Rider *struct {
+			Status    string    `json:"status"`
+			At        time.Time `json:"at"`
+			Latitude  *float64  `json:"latitude"`
+			Longitude *float64  `json:"longitude"`
+		} `json:"rider"`
+	} `json:"body"`
+}
+
+func (DeliverooConnector) Verify(header http.Header, body []byte, secret string) bool {
+	guid := header.Get("X-Deliveroo-Sequence-Guid")
+	if guid == "" {
+		return false
+	}
+	return verifyHMAC(header.Get("X-Deliveroo-Hmac-Sha256"), append([]byte(guid+" "), body...), secret)
+}
+
+func (DeliverooConnector) Parse(body []byte) ([]PlatformEvent, error) {
+	return parsePayloads(body, func(payload deliverooPayload) *PlatformEvent {
+		order := payload.Body.Order
+		event := &PlatformEvent{ExternalOrderID: order.ID, OccurredAt: order.UpdatedAt}
+
+		var deliveryStatus string
+		if rider := payload.Body.Rider; rider != nil {
+			switch rider.Status {
+			case "rider_in_delivery":
+				deliveryStatus = "out for delivery"
+			case "rider_delivered":
+				deliveryStatus = "delivered"
+			case "rider_failed":
+				deliveryStatus = "not delivered"
+			}
+			if !rider.At.IsZero() {
+				event.OccurredAt = rider.At
+			}
+			event.Delivery = deliveryFor(deliveryStatus, event.OccurredAt, rider.Latitude, rider.Longitude)
+		}
+		event.OrderStatus = orderStatusFor(deliveryStatus == "delivered" || order.Status == "delivered" || (order.Fulfillment == "customer" && order.Status == "collected"))
+
+		switch payload.Event {
+		case "order.new":
+			orderType := "delivery"
+			if order.Fulfillment == "customer" {
+				orderType = "takeaway"
+			}
+			placed := &PlatformOrder{
+				CreateTime:     order.CreatedAt,
+				OrderType:      orderType,
+				TotalAmount:    float64(order.TotalPrice.Fractional) / 100,
+				DiscountAmount: float64(order.OfferDiscount.Fractional) / 100,
+			}
+			for _, item := range order.Items {
+				placed.Items = append(placed.Items, PlatformOrderItem{
+					Reference:  item.PosItemID,
+					Name:       item.Name,
+					Quantity:   item.Quantity,
+					TotalPrice: float64(item.TotalPrice.Fractional) / 100,
+				})
+			}
+			event.Order = placed
+		case "order.status_update", "rider.status_update":
+		default:
+			return nil
+		}
+		return event
+	})
+}
+
+// TalabatConnector reads the order dispatches of Talabat, which authenticate with the secret of the
+// integration as a bearer token. Amounts and quantities are decimal strings.
+type TalabatConnector struct{}
+
+type talabatPayload struct {
+	Token          string    `json:"token"`
+	Status         string    `json:"status"`
+	ExpeditionType string    `json:"expeditionType"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+	Price          struct {
+		GrandTotal     string `json:"grandTotal"`
+		DiscountAmount string `json:"discountAmountTotal"`
+	} `json:"price"`
+	Products []struct {
+		RemoteCode string `json:"remoteCode"`
+		Name       string `json:"name"`
+		Quantity   string `json:"quantity"`
+		PaidPrice  string `json:"paidPrice"`
+	} `json:"products"`
+	Delivery struct {
+		Address struct {
+			Latitude  *float64 `json:"latitude"`
+			Longitude *float64 `json:"longitude"`
+		} `json:"address"`
+	} `json:"delivery"`
+}
+
+func (TalabatConnector) Verify(header http.Header, body []byte, secret string) bool {
+	token, ok := strings.CutPrefix(header.Get("Authorization"), "Bearer ")
+	return ok && secret != "" && subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+}
+
+func (TalabatConnector) Parse(body []byte) ([]PlatformEvent, error) {
+	var invalid error
+	events, err := parsePayloads(body, func(payload talabatPayload) *PlatformEvent {
+		event := &PlatformEvent{ExternalOrderID: payload.Token, OccurredAt: payload.UpdatedAt}
+
+		var deliveryStatus string
+		switch payload.Status {
+		case "PICKED_UP":
+			deliveryStatus = "out for delivery"
+		case "DELIVERED":
+			deliveryStatus = "delivered"
+		case "DELIVERY_FAILED":
+			deliveryStatus = "not delivered"
+		}
+		if payload.ExpeditionType != "pickup" {
+			event.Delivery = deliveryFor(deliveryStatus, event.OccurredAt, payload.Delivery.Address.Latitude, payload.Delivery.Address.Longitude)
+		}
+		event.OrderStatus = orderStatusFor(deliveryStatus == "delivered" || (payload.ExpeditionType == "pickup" && payload.Status == "PICKED_UP"))
+
+		// status updates do not repeat the products
+		if len(payload.Products) == 0 {
+			return event
+		}
+
+		orderType := "delivery"
+		if payload.ExpeditionType == "pickup" {
+			orderType = "takeaway"
+		}
+		placed := &PlatformOrder{CreateTime: payload.CreatedAt, OrderType: orderType}
+		var err error
+		if placed.TotalAmount, err = parseDecimal(payload.Price.GrandTotal); err != nil {
+			invalid = err
+			return nil
+		}
+		if placed.DiscountAmount, err = parseDecimal(payload.Price.DiscountAmount); err != nil {
+			invalid = err
+			return nil
+		}
+		for _, product := range payload.Products {
+			quantity, err := parseDecimal(product.Quantity)
+			if err != nil {
+				invalid = err
+				return nil
+			}
+			price, err := parseDecimal(product.PaidPrice)
+			if err != nil {
+				invalid = err
+				return nil
+			}
+			placed.Items = append(placed.Items, PlatformOrderItem{
+				Reference:  product.RemoteCode,
+				Name:       product.Name,
+				Quantity:   int(quantity),
+				TotalPrice: price,
+			})
+		}
+		event.Order = placed
+		return event
+	})
+	if err != nil {
+		return nil, err
+	}
+	if invalid != nil {
+		return nil, invalid
+	}
+	return events, nil
+}
+
+// parseDecimal reads an amount sent as a string, empty amounts are zero
+func parseDecimal(value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q is not a number", ErrInvalidPlatformPayload, value)
+	}
+	return parsed, nil
+}
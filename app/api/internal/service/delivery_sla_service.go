@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+)
+
+const (
+	defaultDeliverySLAInterval      = 15 * time.Minute
+	defaultDeliverySLAMinDeliveries = 10
+)
+
+// DeliverySLAService compares the delivery times of the day so far with the delivery SLA of every
+// organization, and emails the managers once a day when too many deliveries breached it
+type DeliverySLAService struct {
+	Store        database.DeliverySLAStore
+	OrgStore     database.OrgStore
+	EmailService EmailService
+	Logger       *slog.Logger
+
+	// Interval is how often the breaches are counted
+	Interval time.Duration
+	// MinDeliveries is the deliveries needed so far before the breach rate is trusted, so one late
+	// delivery out of two in the morning does not raise an alert
+	MinDeliveries int
+}
+
+// NewDeliverySLAService reads DELIVERY_SLA_INTERVAL (a Go duration, e.g. "30m") and
+// DELIVERY_SLA_MIN_DELIVERIES, and falls back to checks every 15 minutes once 10 deliveries were delivered
+func NewDeliverySLAService(store database.DeliverySLAStore, orgStore database.OrgStore, emailService EmailService, Logger *slog.Logger) *DeliverySLAService {
+	return &DeliverySLAService{
+		Store:         store,
+		OrgStore:      orgStore,
+		EmailService:  emailService,
+		Logger:        Logger,
+		Interval:      durationFromEnv("DELIVERY_SLA_INTERVAL", defaultDeliverySLAInterval, Logger),
+		MinDeliveries: intFromEnv("DELIVERY_SLA_MIN_DELIVERIES", defaultDeliverySLAMinDeliveries, Logger),
+	}
+}
+
+// Start counts the breaches every Interval until the context is cancelled
+func (s *DeliverySLAService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.Logger.Info("delivery SLA service started", "interval", s.Interval)
+	for {
+		select {
+		case <-ctx.Done():
+			s.Logger.Info("delivery SLA service stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.CheckBreaches(time.Now()); err != nil {
+				s.Logger.Error("failed to check delivery SLA breaches", "error", err)
+			}
+		}
+	}
+}
+
+// CheckBreaches alerts the organizations whose deliveries today breached their SLA too often and returns
+// how many were alerted. The alert is logged before the email is sent, so an organization is not emailed
+// twice the same day.
+func (s *DeliverySLAService) CheckBreaches(now time.Time) (int, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	days, err := s.Store.GetDayBreaches(today, today.AddDate(0, 0, 1))
+	if err != nil {
+		return 0, err
+	}
+
+	alerted := 0
+	for _, day := range days {
+		alert := EvaluateSLABreaches(day, s.MinDeliveries)
+		if alert == nil {
+			continue
+		}
+		alert.Date = today
+		alert.CreatedAt = now
+
+		if err := s.Store.StoreAlert(alert); err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				s.Logger.Error("failed to store delivery SLA alert", "error", err, "organization_id", day.OrganizationID)
+			}
+			continue
+		}
+		alerted++
+
+		emails, err := managerAndAdminEmails(s.OrgStore, day.OrganizationID)
+		if err != nil {
+			s.Logger.Error("failed to get manager emails", "error", err, "organization_id", day.OrganizationID)
+			continue
+		}
+		if err := s.EmailService.SendDeliverySLAEmail(emails, describeSLABreaches(alert)); err != nil {
+			s.Logger.Error("failed to send delivery SLA email", "error", err, "organization_id", day.OrganizationID)
+		}
+	}
+
+	if alerted > 0 {
+		s.Logger.Info("delivery SLA alerts raised", "organizations", alerted)
+	}
+	return alerted, nil
+}
+
+// EvaluateSLABreaches returns the alert for the deliveries of a day, or nil when fewer than minDeliveries
+// were delivered or the breach rate is at most the alert threshold of the organization
+func EvaluateSLABreaches(day database.DeliverySLADay, minDeliveries int) *database.DeliverySLAAlert {
+	if day.Delivered <= 0 || day.Delivered < minDeliveries {
+		return nil
+	}
+
+	rate := math.Round(float64(day.Breaches)*10000/float64(day.Delivered)) / 100
+	if rate <= float64(day.AlertPercent) {
+		return nil
+	}
+	return &database.DeliverySLAAlert{
+		OrganizationID:   day.OrganizationID,
+		SLAMinutes:       day.SLAMinutes,
+		Delivered:        day.Delivered,
+		Breaches:         day.Breaches,
+		BreachRate:       rate,
+		ThresholdPercent: day.AlertPercent,
+	}
+}
+
+// describeSLABreaches writes the summary line of the alert email
+func describeSLABreaches(alert *database.DeliverySLAAlert) string {
+	return fmt.Sprintf("%d of the %d deliveries today (%g%%) took longer than the %d minute delivery SLA, above the %d%% alert threshold.",
+		alert.Breaches, alert.Delivered, alert.BreachRate, alert.SLAMinutes, alert.ThresholdPercent)
+}
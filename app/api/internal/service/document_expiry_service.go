@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+const defaultDocumentExpiryInterval = 24 * time.Hour
+
+// documentExpiryAlertDays are the number of days before expiry the employee and the admins are
+// alerted at, the last alert being sent once the document has expired
+var documentExpiryAlertDays = []int{30, 7, 0}
+
+var documentNames = map[string]string{
+	database.DocumentNationalID: "National ID",
+	database.DocumentWorkPermit: "Work permit",
+}
+
+// DocumentExpiryService periodically alerts employees and the admins of their organization when a
+// national ID or work permit is about to expire or has expired
+type DocumentExpiryService struct {
+	ComplianceStore database.EmployeeComplianceStore
+	OrgStore        database.OrgStore
+	EmailService    EmailService
+	Logger          *slog.Logger
+
+	// Interval is how often expiring documents are checked
+	Interval time.Duration
+}
+
+// NewDocumentExpiryService reads DOCUMENT_EXPIRY_INTERVAL (a Go duration, e.g. "12h") and falls back to daily checks
+func NewDocumentExpiryService(complianceStore database.EmployeeComplianceStore, orgStore database.OrgStore, emailService EmailService, Logger *slog.Logger) *DocumentExpiryService {
+	return &DocumentExpiryService{
+		ComplianceStore: complianceStore,
+		OrgStore:        orgStore,
+		EmailService:    emailService,
+		Logger:          Logger,
+		Interval:        durationFromEnv("DOCUMENT_EXPIRY_INTERVAL", defaultDocumentExpiryInterval, Logger),
+	}
+}
+
+// Start sends the alerts due right away and then every Interval until the context is cancelled
+func (s *DocumentExpiryService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.Logger.Info("document expiry service started", "interval", s.Interval)
+	s.alert(time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			s.Logger.Info("document expiry service stopped")
+			return
+		case <-ticker.C:
+			s.alert(time.Now())
+		}
+	}
+}
+
+func (s *DocumentExpiryService) alert(now time.Time) {
+	if _, err := s.SendAlerts(now); err != nil {
+		s.Logger.Error("failed to send document expiry alerts", "error", err)
+	}
+}
+
+// expiryAlertThreshold is the alert threshold a document expiring in daysLeft days has reached
+func expiryAlertThreshold(daysLeft int) int {
+	threshold := documentExpiryAlertDays[0]
+	for _, days := range documentExpiryAlertDays {
+		if daysLeft <= days {
+			threshold = days
+		}
+	}
+	return threshold
+}
+
+// SendAlerts emails the alerts of the documents that reached a new threshold and returns how many were sent
+func (s *DocumentExpiryService) SendAlerts(now time.Time) (int, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	documents, err := s.ComplianceStore.GetExpiringDocuments(today.AddDate(0, 0, documentExpiryAlertDays[0]))
+	if err != nil {
+		return 0, err
+	}
+
+	adminEmails := make(map[uuid.UUID][]string)
+	sent := 0
+	for _, document := range documents {
+		daysLeft := int(document.ExpiresOn.Sub(today).Hours() / 24)
+		threshold := expiryAlertThreshold(daysLeft)
+		if document.AlertedDays != nil && *document.AlertedDays <= threshold {
+			continue
+		}
+
+		admins, ok := adminEmails[document.OrganizationID]
+		if !ok {
+			admins, err = s.OrgStore.GetAdminEmailsByOrgID(document.OrganizationID)
+			if err != nil {
+				s.Logger.Error("failed to get admin emails", "error", err, "org_id", document.OrganizationID)
+			}
+			adminEmails[document.OrganizationID] = admins
+		}
+
+		recipients := []string{document.EmployeeEmail}
+		for _, email := range admins {
+			if email != document.EmployeeEmail {
+				recipients = append(recipients, email)
+			}
+		}
+		if err := s.EmailService.SendDocumentExpiryEmail(recipients, document.EmployeeName, documentNames[document.Document],
+			document.ExpiresOn.Format(time.DateOnly), daysLeft); err != nil {
+			s.Logger.Error("failed to send document expiry email", "error", err, "employee_id", document.EmployeeID)
+			continue
+		}
+		if err := s.ComplianceStore.MarkExpiryAlerted(document.EmployeeID, document.Document, threshold); err != nil {
+			s.Logger.Error("failed to record document expiry alert", "error", err, "employee_id", document.EmployeeID)
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		s.Logger.Info("document expiry alerts sent", "count", sent)
+	}
+	return sent, nil
+}
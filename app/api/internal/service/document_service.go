@@ -0,0 +1,134 @@
+package service
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// DocumentSignValidity is how long the link emailed to an employee signs their document
+const DocumentSignValidity = 30 * 24 * time.Hour
+
+// DocumentService generates offer and termination letters from the templates of an organization and
+// records their electronic signature. The employee signs with a link emailed to them, which also works
+// once they were laid off and cannot log in anymore.
+type DocumentService struct {
+	Store        database.EmployeeDocumentStore
+	EmailService EmailService
+	Logger       *slog.Logger
+	// SignURL is the public URL of the signing endpoint the token is appended to
+	SignURL string
+}
+
+func NewDocumentService(store database.EmployeeDocumentStore, emailService EmailService, logger *slog.Logger) *DocumentService {
+	return &DocumentService{
+		Store:        store,
+		EmailService: emailService,
+		Logger:       logger,
+		SignURL:      PublicAPIURL() + "/api/documents/sign/",
+	}
+}
+
+// Template returns the organization's template of the kind, or the built-in one
+func (s *DocumentService) Template(orgID uuid.UUID, kind string) (*database.DocumentTemplate, error) {
+	template, err := s.Store.GetTemplate(orgID, kind)
+	if err == nil {
+		return template, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	builtIn, ok := DefaultDocumentTemplate(kind)
+	if !ok {
+		return nil, fmt.Errorf("unknown document kind %q", kind)
+	}
+	return &builtIn, nil
+}
+
+// Issue generates a document of the kind for the employee, stores it and emails the employee the link to
+// sign it. The employee's name, email, role and pay fill the fields, the caller sets the others.
+func (s *DocumentService) Issue(orgID uuid.UUID, employee *database.User, kind string, fields DocumentFields, createdBy uuid.UUID) (*database.EmployeeDocument, error) {
+	template, err := s.Template(orgID, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	fields.EmployeeName = employee.FullName
+	fields.EmployeeEmail = employee.Email
+	fields.Role = employee.UserRole
+	fields.Date = now.Format(time.DateOnly)
+	if employee.SalaryPerHour != nil {
+		fields.HourlySalary = fmt.Sprintf("%.2f", *employee.SalaryPerHour)
+	}
+	title, body, err := RenderDocument(template.Title, template.Body, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := newSignToken()
+	if err != nil {
+		return nil, err
+	}
+	doc := &database.EmployeeDocument{
+		OrganizationID: orgID,
+		EmployeeID:     &employee.ID,
+		EmployeeName:   employee.FullName,
+		EmployeeEmail:  employee.Email,
+		Kind:           kind,
+		Title:          title,
+		Body:           body,
+		CreatedBy:      &createdBy,
+		SignExpiresAt:  now.Add(DocumentSignValidity),
+	}
+	if err := s.Store.CreateDocument(doc, token); err != nil {
+		return nil, err
+	}
+
+	link := s.SignURL + token
+	go func() {
+		if err := s.EmailService.SendDocumentSignatureEmail(doc.EmployeeEmail, doc.EmployeeName, doc.Title, link, doc.SignExpiresAt.Format(time.DateOnly)); err != nil {
+			s.Logger.Error("failed to send document signature email", "error", err, "document_id", doc.ID)
+		}
+	}()
+	s.Logger.Info("employee document issued", "document_id", doc.ID, "kind", kind, "org_id", orgID)
+	return doc, nil
+}
+
+// Sign records the signature of the document by signerName from signerIP and stores the signed PDF.
+// Returns database.ErrDocumentSigned when it was already signed.
+func (s *DocumentService) Sign(doc *database.EmployeeDocument, signerName, signerIP string, branding *PDFBranding) ([]byte, error) {
+	if doc.SignedAt != nil {
+		return nil, database.ErrDocumentSigned
+	}
+	signedAt := time.Now().UTC().Truncate(time.Second)
+	signerName = strings.TrimSpace(signerName)
+	doc.SignedAt = &signedAt
+	doc.SignerName = &signerName
+	doc.SignerIP = &signerIP
+
+	pdf := RenderDocumentPDF(branding, doc)
+	if err := s.Store.SignDocument(doc, pdf); err != nil {
+		doc.SignedAt, doc.SignerName, doc.SignerIP = nil, nil, nil
+		return nil, err
+	}
+	s.Logger.Info("employee document signed", "document_id", doc.ID, "signer_ip", signerIP)
+	return pdf, nil
+}
+
+// newSignToken returns a random URL safe signing token
+func newSignToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -1,11 +1,21 @@
 package service
 
 import (
+	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"html"
 	"log"
 	"log/slog"
 	"net/smtp"
+	"net/textproto"
 	"os"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/config"
+	"github.com/clockwise/clockwise/backend/internal/database"
 )
 
 type EmailService interface {
@@ -17,24 +27,172 @@ type EmailService interface {
 	SendRequestNotifyEmail(toEmails []string, employeeName, requestType, message string) error
 	SendOfferAcceptedEmailToManagerAndAdmin(toEmails []string, employeeName, offerStatus, starttime string) error
 	SendOfferDeclinedEmailToManagerAndAdmin(toEmails []string, employeeName, offerStatus, starttime string) error
+	SendShiftReminderEmail(toEmail, fullName string, shifts []string) error
+	SendNotificationDigestEmail(toEmail, fullName string, notifications []string) error
+	SendAnnouncementEmail(toEmails []string, authorName, title, message string) error
+	SendScheduleClearedEmail(toEmail, fullName, from, to string) error
+	SendClosureEmail(toEmail, fullName, date, reason string) error
+	SendNewLoginEmail(toEmail, fullName, device, ipAddress, loginTime string) error
+	SendCalendarInviteEmail(toEmail, fullName, title, location string, start, end time.Time, inviteID string) error
+	SendForecastVarianceEmail(toEmails []string, summary, suggestion string) error
+	SendStandbyActivatedEmail(toEmail, fullName, date, startTime, endTime string) error
+	SendRequestAwaitingApprovalEmail(toEmails []string, employeeName, requestType, approvedBy string) error
+	SendRequestStepApprovedEmail(toEmail, fullName, requestType, nextRole string) error
+	SendDocumentExpiryEmail(toEmails []string, employeeName, document, expiresOn string, daysLeft int) error
+	SendShiftCancelledEmail(toEmail, fullName, date, startTime, endTime, reason string) error
+	SendWeeklyScheduleEmail(toEmail, fullName, weekOf string, shifts []string, totalHours float64, estimatedPay *float64) error
+	SendDocumentSignatureEmail(toEmail, fullName, title, link, expiresOn string) error
+	SendMagicLinkEmail(toEmail, fullName, link string, validFor time.Duration) error
+	SendComplianceReportEmail(toEmails []string, weekOf string, lateCancellations int, employees []string, owedHours, owedPay float64) error
+	SendEncryptedComplianceReportEmail(toEmails []string, weekOf string, lateCancellations int, attachment EmailAttachment) error
+	SendSkillProposedEmail(toEmails []string, employeeName, skill, kind string) error
+	SendSkillReviewedEmail(toEmail, fullName, skill, status string, note *string) error
+	SendSkillExpiryEmail(toEmails []string, employeeName, skill, expiresOn string, daysLeft int) error
+	SendBroadcastEmail(toEmail, senderName, title, message string) error
+	SendExportReadyEmail(toEmail, fullName, fileName, link, expiresAt string) error
+	SendOrderAcceptanceEmail(toEmails []string, accepting bool, summary string) error
+	SendDeliverySLAEmail(toEmails []string, summary string) error
+	SendTrainingGraduationEmail(toEmails []string, summary string) error
+	SendKPIReportEmail(toEmails []string, weekOf string, results []string) error
 }
 
+// SMTPEmailService resolves SMTP_USERNAME and SMTP_PASSWORD through the secrets provider on every send,
+// so rotated credentials are picked up once the provider cache expires
 type SMTPEmailService struct {
-	host     string
-	port     string
-	username string
-	password string
-	Logger   *slog.Logger
+	host    string
+	port    string
+	secrets config.SecretsProvider
+	Logger  *slog.Logger
+	// OnFailure is called with the recipients of a message that could not be delivered
+	OnFailure func(to []string, err error)
+	// Preferences, when set, skips the recipients who opted out of the category of a non-critical email
+	Preferences database.EmailPreferenceStore
+	// Unsubscribe, when set, adds a signed unsubscribe link to non-critical emails
+	Unsubscribe *UnsubscribeSigner
+	// Branding, when set, returns the branding of the organization of a recipient, nil keeps the default look
+	Branding func(email string) *EmailBranding
+}
+
+// EmailBranding is the look of the emails sent to the members of an organization
+type EmailBranding struct {
+	Name string
+	// Hex1 and Hex2 are the start and end of the header gradient, 6 hex digits
+	Hex1 string
+	Hex2 string
+	// LogoURL is shown above the name when set
+	LogoURL string
+}
+
+// Every template starts with this header, which branding replaces with the colors and logo of the organization
+const (
+	defaultHeaderGradient = "#010440 0%, #031D40 100%"
+	defaultHeaderTitle    = "<h1>⏰ AntiClockWise</h1>"
+)
+
+// PublicAPIURL is the URL the API is reached at from emails, PUBLIC_API_URL or http://localhost:8080
+func PublicAPIURL() string {
+	if url := strings.TrimSuffix(os.Getenv("PUBLIC_API_URL"), "/"); url != "" {
+		return url
+	}
+	return "http://localhost:8080"
 }
 
-func NewSMTPEmailService(Logger *slog.Logger) *SMTPEmailService {
+func NewSMTPEmailService(Logger *slog.Logger, secrets config.SecretsProvider) *SMTPEmailService {
 	return &SMTPEmailService{
-		host:     os.Getenv("SMTP_HOST"),
-		port:     os.Getenv("SMTP_PORT"),
-		username: os.Getenv("SMTP_USERNAME"),
-		password: os.Getenv("SMTP_PASSWORD"),
-		Logger:   Logger,
+		host:    os.Getenv("SMTP_HOST"),
+		port:    os.Getenv("SMTP_PORT"),
+		secrets: secrets,
+		Logger:  Logger,
+	}
+}
+
+// sendMail sends a message with the current SMTP credentials. When the server rejects them the cached
+// credentials are dropped and the message is sent once more, in case they were rotated in the meantime.
+func (s *SMTPEmailService) sendMail(addr string, to []string, msg []byte) error {
+	msg = s.brand(to, msg)
+	err := s.sendMailWithCurrentCredentials(addr, to, msg)
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) && smtpErr.Code == 535 {
+		s.Logger.Warn("SMTP credentials rejected, retrying with refreshed credentials")
+		config.InvalidateSecret(s.secrets, "SMTP_USERNAME")
+		config.InvalidateSecret(s.secrets, "SMTP_PASSWORD")
+		err = s.sendMailWithCurrentCredentials(addr, to, msg)
+	}
+	if err != nil && s.OnFailure != nil {
+		s.OnFailure(to, err)
+	}
+	return err
+}
+
+// brand gives the message the branding of the organization of its first recipient
+func (s *SMTPEmailService) brand(to []string, msg []byte) []byte {
+	if s.Branding == nil || len(to) == 0 {
+		return msg
+	}
+	branding := s.Branding(to[0])
+	if branding == nil {
+		return msg
+	}
+
+	title := "<h1>" + html.EscapeString(branding.Name) + "</h1>"
+	if branding.LogoURL != "" {
+		title = fmt.Sprintf(`<img src="%s" alt="%s" style="max-height: 60px; max-width: 200px;">`, html.EscapeString(branding.LogoURL), html.EscapeString(branding.Name)) + title
+	}
+	replacer := strings.NewReplacer(
+		defaultHeaderGradient, fmt.Sprintf("#%s 0%%, #%s 100%%", branding.Hex1, branding.Hex2),
+		defaultHeaderTitle, title,
+	)
+	return []byte(replacer.Replace(string(msg)))
+}
+
+// optedIn returns the recipients who did not opt out of the category. When the preferences cannot be
+// read the email is sent to everyone.
+func (s *SMTPEmailService) optedIn(category string, to []string) []string {
+	if s.Preferences == nil || len(to) == 0 {
+		return to
+	}
+	optedOut, err := s.Preferences.GetOptedOut(category, to)
+	if err != nil {
+		s.Logger.Error("failed to check email opt-outs, sending anyway", "error", err, "category", category)
+		return to
+	}
+
+	recipients := make([]string, 0, len(to))
+	for _, email := range to {
+		if optedOut[email] {
+			s.Logger.Info("email skipped, recipient opted out", "category", category)
+			continue
+		}
+		recipients = append(recipients, email)
+	}
+	return recipients
+}
+
+// unsubscribe returns the List-Unsubscribe headers and the footer line linking the recipient to the
+// unsubscribe endpoint of the category, both empty without a signer
+func (s *SMTPEmailService) unsubscribe(email, category string) (string, string) {
+	if s.Unsubscribe == nil {
+		return "", ""
+	}
+	link := s.Unsubscribe.Link(email, category)
+	headers := fmt.Sprintf("List-Unsubscribe: <%s>\nList-Unsubscribe-Post: List-Unsubscribe=One-Click\n", link)
+	footer := fmt.Sprintf(`<p><a href="%s" style="color: #031D40;">Unsubscribe from %s emails</a></p>`, html.EscapeString(link), category)
+	return headers, footer
+}
+
+func (s *SMTPEmailService) sendMailWithCurrentCredentials(addr string, to []string, msg []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	username, err := s.secrets.GetSecret(ctx, "SMTP_USERNAME")
+	if err != nil {
+		return fmt.Errorf("failed to resolve SMTP username: %w", err)
+	}
+	password, err := s.secrets.GetSecret(ctx, "SMTP_PASSWORD")
+	if err != nil {
+		return fmt.Errorf("failed to resolve SMTP password: %w", err)
 	}
+	return smtp.SendMail(addr, smtp.PlainAuth("", username, password, s.host), username, to, msg)
 }
 
 func (s *SMTPEmailService) SendWelcomeEmail(toEmail, fullName, password, role string, organization string) error {
@@ -44,8 +202,6 @@ func (s *SMTPEmailService) SendWelcomeEmail(toEmail, fullName, password, role st
 		return nil
 	}
 
-	auth := smtp.PlainAuth("", s.username, s.password, s.host)
-
 	subject := "Subject: Welcome to AntiClockWise - Account Details\n"
 	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
 	body := fmt.Sprintf(`
@@ -289,7 +445,7 @@ func (s *SMTPEmailService) SendWelcomeEmail(toEmail, fullName, password, role st
 	msg := []byte(subject + mime + body)
 	addr := s.host + ":" + s.port
 
-	if err := smtp.SendMail(addr, auth, s.username, []string{toEmail}, msg); err != nil {
+	if err := s.sendMail(addr, []string{toEmail}, msg); err != nil {
 		return fmt.Errorf("failed to send email: %w", err)
 	}
 
@@ -302,8 +458,6 @@ func (s *SMTPEmailService) SendRequestApprovedEmail(toEmail, fullName, requestTy
 		return nil
 	}
 
-	auth := smtp.PlainAuth("", s.username, s.password, s.host)
-
 	subject := "Subject: Great News — Your Request Has Been Approved! \n"
 	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
 	body := fmt.Sprintf(`
@@ -352,7 +506,7 @@ func (s *SMTPEmailService) SendRequestApprovedEmail(toEmail, fullName, requestTy
 	msg := []byte(subject + mime + body)
 	addr := s.host + ":" + s.port
 
-	if err := smtp.SendMail(addr, auth, s.username, []string{toEmail}, msg); err != nil {
+	if err := s.sendMail(addr, []string{toEmail}, msg); err != nil {
 		return fmt.Errorf("failed to send request approved email: %w", err)
 	}
 	return nil
@@ -364,8 +518,6 @@ func (s *SMTPEmailService) SendRequestDeclinedEmail(toEmail, fullName, requestTy
 		return nil
 	}
 
-	auth := smtp.PlainAuth("", s.username, s.password, s.host)
-
 	subject := "Subject: Update on Your Request\n"
 	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
 	body := fmt.Sprintf(`
@@ -414,7 +566,7 @@ func (s *SMTPEmailService) SendRequestDeclinedEmail(toEmail, fullName, requestTy
 	msg := []byte(subject + mime + body)
 	addr := s.host + ":" + s.port
 
-	if err := smtp.SendMail(addr, auth, s.username, []string{toEmail}, msg); err != nil {
+	if err := s.sendMail(addr, []string{toEmail}, msg); err != nil {
 		return fmt.Errorf("failed to send request declined email: %w", err)
 	}
 	return nil
@@ -426,8 +578,6 @@ func (s *SMTPEmailService) SendLayoffEmail(toEmail, fullName, reason string) err
 		return nil
 	}
 
-	auth := smtp.PlainAuth("", s.username, s.password, s.host)
-
 	subject := "Subject: Important Notice Regarding Your Employment\n"
 	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
 	body := fmt.Sprintf(`
@@ -483,7 +633,7 @@ func (s *SMTPEmailService) SendLayoffEmail(toEmail, fullName, reason string) err
 	msg := []byte(subject + mime + body)
 	addr := s.host + ":" + s.port
 
-	if err := smtp.SendMail(addr, auth, s.username, []string{toEmail}, msg); err != nil {
+	if err := s.sendMail(addr, []string{toEmail}, msg); err != nil {
 		return fmt.Errorf("failed to send layoff email: %w", err)
 	}
 	return nil
@@ -495,8 +645,6 @@ func (s *SMTPEmailService) SendRequestSubmittedEmail(toEmail, fullName, requestT
 		return nil
 	}
 
-	auth := smtp.PlainAuth("", s.username, s.password, s.host)
-
 	subject := "Subject: Your Request Has Been Submitted\n"
 	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
 	body := fmt.Sprintf(`
@@ -552,7 +700,7 @@ func (s *SMTPEmailService) SendRequestSubmittedEmail(toEmail, fullName, requestT
 	msg := []byte(subject + mime + body)
 	addr := s.host + ":" + s.port
 
-	if err := smtp.SendMail(addr, auth, s.username, []string{toEmail}, msg); err != nil {
+	if err := s.sendMail(addr, []string{toEmail}, msg); err != nil {
 		return fmt.Errorf("failed to send request submitted email: %w", err)
 	}
 	return nil
@@ -564,8 +712,6 @@ func (s *SMTPEmailService) SendRequestNotifyEmail(toEmails []string, employeeNam
 		return nil
 	}
 
-	auth := smtp.PlainAuth("", s.username, s.password, s.host)
-
 	subject := "Subject: Action Required — New Employee Request Submitted\n"
 	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
 	body := fmt.Sprintf(`
@@ -624,7 +770,7 @@ func (s *SMTPEmailService) SendRequestNotifyEmail(toEmails []string, employeeNam
 	msg := []byte(subject + mime + body)
 	addr := s.host + ":" + s.port
 
-	if err := smtp.SendMail(addr, auth, s.username, toEmails, msg); err != nil {
+	if err := s.sendMail(addr, toEmails, msg); err != nil {
 		return fmt.Errorf("failed to send request notification email: %w", err)
 	}
 	return nil
@@ -636,4 +782,1839 @@ func (s *SMTPEmailService) SendOfferAcceptedEmailToManagerAndAdmin(toEmails []st
 
 func (s *SMTPEmailService) SendOfferDeclinedEmailToManagerAndAdmin(toEmails []string, employeeName, offerStatus, starttime string) error {
     return nil 
-}
\ No newline at end of file
+}
+
+func (s *SMTPEmailService) SendShiftReminderEmail(toEmail, fullName string, shifts []string) error {
+	if len(s.optedIn(database.EmailCategoryReminders, []string{toEmail})) == 0 {
+		return nil
+	}
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %s | Shift Acknowledgment Reminder | Shifts: %v\n", toEmail, shifts)
+		return nil
+	}
+
+	var shiftItems strings.Builder
+	for _, shift := range shifts {
+		fmt.Fprintf(&shiftItems, "<li>%s</li>", shift)
+	}
+
+	subject := "Subject: Reminder — Please Confirm Your Upcoming Shifts\n"
+	unsubscribeHeaders, unsubscribeFooter := s.unsubscribe(toEmail, database.EmailCategoryReminders)
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #fff3cd; color: #856404; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .detail-box { background: linear-gradient(135deg, #F2DFDF 0%%, #ffffff 100%%); border-left: 4px solid #BF4124; border-radius: 8px; padding: 20px 20px 20px 40px; margin: 20px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">Hello, %s! 👋</div>
+            <div class="badge">📅 SHIFTS AWAITING CONFIRMATION</div>
+            <p class="message">
+                Your schedule has been published and the following shifts are still waiting for your confirmation:
+            </p>
+            <ul class="detail-box">%s</ul>
+            <p class="message">
+                Please log in to AntiClockWise and acknowledge your schedule so your manager knows you have seen it.
+            </p>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+            %s
+        </div>
+    </div>
+</body>
+</html>`, fullName, shiftItems.String(), unsubscribeFooter)
+
+	msg := []byte(subject + unsubscribeHeaders + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, []string{toEmail}, msg); err != nil {
+		return fmt.Errorf("failed to send shift reminder email: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPEmailService) SendNotificationDigestEmail(toEmail, fullName string, notifications []string) error {
+	if len(s.optedIn(database.EmailCategoryDigests, []string{toEmail})) == 0 {
+		return nil
+	}
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %s | Notification Digest | Notifications: %v\n", toEmail, notifications)
+		return nil
+	}
+
+	var notificationItems strings.Builder
+	for _, notification := range notifications {
+		fmt.Fprintf(&notificationItems, "<li>%s</li>", html.EscapeString(notification))
+	}
+
+	subject := fmt.Sprintf("Subject: Your AntiClockWise Digest — %d New Notifications\n", len(notifications))
+	unsubscribeHeaders, unsubscribeFooter := s.unsubscribe(toEmail, database.EmailCategoryDigests)
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #fff3cd; color: #856404; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .detail-box { background: linear-gradient(135deg, #F2DFDF 0%%, #ffffff 100%%); border-left: 4px solid #BF4124; border-radius: 8px; padding: 20px 20px 20px 40px; margin: 20px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">Hello, %s! 👋</div>
+            <div class="badge">📬 YOUR NOTIFICATION DIGEST</div>
+            <p class="message">
+                Here is what happened since your last digest:
+            </p>
+            <ul class="detail-box">%s</ul>
+            <p class="message">
+                Please log in to AntiClockWise to follow up. You can switch back to immediate emails in your notification settings.
+            </p>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+            %s
+        </div>
+    </div>
+</body>
+</html>`, fullName, notificationItems.String(), unsubscribeFooter)
+
+	msg := []byte(subject + unsubscribeHeaders + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, []string{toEmail}, msg); err != nil {
+		return fmt.Errorf("failed to send notification digest email: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPEmailService) SendAnnouncementEmail(toEmails []string, authorName, title, message string) error {
+	toEmails = s.optedIn(database.EmailCategoryMarketing, toEmails)
+	if len(toEmails) == 0 {
+		return nil
+	}
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %v | Announcement from %s | Title: %s | Message: %s\n", toEmails, authorName, title, message)
+		return nil
+	}
+
+	// the title goes into a header, so it must stay on one line
+	subject := fmt.Sprintf("Subject: Announcement — %s\n", strings.NewReplacer("\r", " ", "\n", " ").Replace(title))
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	addr := s.host + ":" + s.port
+
+	// every recipient gets their own unsubscribe link, so the announcement is sent to each separately
+	var errs []error
+	for _, toEmail := range toEmails {
+		unsubscribeHeaders, unsubscribeFooter := s.unsubscribe(toEmail, database.EmailCategoryMarketing)
+		body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #e8f4fd; color: #010440; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .detail-box { background: linear-gradient(135deg, #F2DFDF 0%%, #ffffff 100%%); border-left: 4px solid #BF4124; border-radius: 8px; padding: 20px; margin: 20px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; white-space: pre-line; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">%s</div>
+            <div class="badge">📢 ANNOUNCEMENT</div>
+            <div class="detail-box">
+                <p class="message">%s</p>
+            </div>
+            <p style="font-size: 14px; color: #6c757d; margin-top: 25px;">Posted by %s. Log in to AntiClockWise to see all announcements.</p>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+            %s
+        </div>
+    </div>
+</body>
+</html>`, html.EscapeString(title), html.EscapeString(message), html.EscapeString(authorName), unsubscribeFooter)
+
+		msg := []byte(subject + unsubscribeHeaders + mime + body)
+		if err := s.sendMail(addr, []string{toEmail}, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("failed to send announcement email: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPEmailService) SendScheduleClearedEmail(toEmail, fullName, from, to string) error {
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %s | Schedule Cleared | From: %s | To: %s\n", toEmail, from, to)
+		return nil
+	}
+
+	subject := "Subject: Your Schedule Has Been Withdrawn\n"
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #f8d7da; color: #721c24; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .detail-box { background: linear-gradient(135deg, #F2DFDF 0%%, #ffffff 100%%); border-left: 4px solid #BF4124; border-radius: 8px; padding: 20px; margin: 20px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">Hello, %s! 👋</div>
+            <div class="badge">📅 SCHEDULE WITHDRAWN</div>
+            <p class="message">
+                Your manager has withdrawn the published schedule for the following days. Your shifts on these days are cancelled:
+            </p>
+            <div class="detail-box"><strong>%s</strong> to <strong>%s</strong></div>
+            <p class="message">
+                A new schedule will be published soon. Please log in to AntiClockWise to check for updates.
+            </p>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, html.EscapeString(fullName), from, to)
+
+	msg := []byte(subject + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, []string{toEmail}, msg); err != nil {
+		return fmt.Errorf("failed to send schedule cleared email: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPEmailService) SendClosureEmail(toEmail, fullName, date, reason string) error {
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %s | Closure | Date: %s | Reason: %s\n", toEmail, date, reason)
+		return nil
+	}
+
+	reasonLine := ""
+	if reason != "" {
+		reasonLine = fmt.Sprintf("<p class=\"message\"><strong>Reason:</strong> %s</p>", html.EscapeString(reason))
+	}
+
+	subject := "Subject: Your Shifts Are Cancelled - We Are Closed\n"
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #f8d7da; color: #721c24; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .detail-box { background: linear-gradient(135deg, #F2DFDF 0%%, #ffffff 100%%); border-left: 4px solid #BF4124; border-radius: 8px; padding: 20px; margin: 20px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">Hello, %s! 👋</div>
+            <div class="badge">🔒 CLOSED</div>
+            <p class="message">
+                We will be closed on the following day, so your shifts on that day are cancelled:
+            </p>
+            <div class="detail-box"><strong>%s</strong></div>
+            %s
+            <p class="message">
+                Please log in to AntiClockWise to check your updated schedule.
+            </p>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, html.EscapeString(fullName), date, reasonLine)
+
+	msg := []byte(subject + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, []string{toEmail}, msg); err != nil {
+		return fmt.Errorf("failed to send closure email: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPEmailService) SendNewLoginEmail(toEmail, fullName, device, ipAddress, loginTime string) error {
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %s | New Login | Device: %s | IP: %s | Time: %s\n", toEmail, device, ipAddress, loginTime)
+		return nil
+	}
+
+	subject := "Subject: New Sign-in to Your AntiClockWise Account\n"
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #fff3cd; color: #856404; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .detail-box { background: linear-gradient(135deg, #F2DFDF 0%%, #ffffff 100%%); border-left: 4px solid #BF4124; border-radius: 8px; padding: 20px; margin: 20px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">Hello, %s! 👋</div>
+            <div class="badge">🔐 NEW SIGN-IN</div>
+            <p class="message">
+                Your account was just signed in to from a device or location we have not seen before:
+            </p>
+            <div class="detail-box">
+                <p><strong>Device:</strong> %s</p>
+                <p><strong>IP address:</strong> %s</p>
+                <p><strong>Time:</strong> %s</p>
+            </div>
+            <p class="message">
+                If this was you, no action is needed. If not, change your password right away and contact your administrator.
+            </p>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, html.EscapeString(fullName), html.EscapeString(device), html.EscapeString(ipAddress), loginTime)
+
+	msg := []byte(subject + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, []string{toEmail}, msg); err != nil {
+		return fmt.Errorf("failed to send new login email: %w", err)
+	}
+	return nil
+}
+
+// calendarInvite builds an iCalendar event that mail clients offer to add to the calendar. Start and end
+// are written as floating local times, the same wall clock times the schedule uses.
+func calendarInvite(inviteID, title, location string, start, end time.Time) string {
+	escape := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//AntiClockWise//Scheduling//EN",
+		"METHOD:PUBLISH",
+		"BEGIN:VEVENT",
+		"UID:" + inviteID + "@anticlockwise",
+		"DTSTAMP:" + time.Now().UTC().Format("20060102T150405Z"),
+		"DTSTART:" + start.Format("20060102T150405"),
+		"DTEND:" + end.Format("20060102T150405"),
+		"SUMMARY:" + escape.Replace(title),
+	}
+	if location != "" {
+		lines = append(lines, "LOCATION:"+escape.Replace(location))
+	}
+	lines = append(lines, "END:VEVENT", "END:VCALENDAR")
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+func (s *SMTPEmailService) SendCalendarInviteEmail(toEmail, fullName, title, location string, start, end time.Time, inviteID string) error {
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %s | Calendar Invite | %s | %s - %s\n", toEmail, title, start.Format("2006-01-02 15:04"), end.Format("15:04"))
+		return nil
+	}
+
+	where := "To be confirmed"
+	if location != "" {
+		where = location
+	}
+
+	const boundary = "anticlockwise-invite"
+	subject := "Subject: Invitation: " + title + "\n"
+	mime := "MIME-version: 1.0;\nContent-Type: multipart/mixed; boundary=\"" + boundary + "\"\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #d4edda; color: #155724; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .detail-box { background: linear-gradient(135deg, #F2DFDF 0%%, #ffffff 100%%); border-left: 4px solid #BF4124; border-radius: 8px; padding: 20px; margin: 20px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">Hello, %s! 👋</div>
+            <div class="badge">📅 YOU'RE INVITED</div>
+            <p class="message">
+                You have been invited to the following appointment:
+            </p>
+            <div class="detail-box">
+                <p><strong>%s</strong></p>
+                <p><strong>When:</strong> %s, %s - %s</p>
+                <p><strong>Where:</strong> %s</p>
+            </div>
+            <p class="message">
+                The attached invite adds the appointment to your calendar.
+            </p>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, html.EscapeString(fullName), html.EscapeString(title), start.Format("Monday, January 2 2006"),
+		start.Format("15:04"), end.Format("15:04"), html.EscapeString(where))
+
+	parts := "--" + boundary + "\nContent-Type: text/html; charset=\"UTF-8\"\n\n" + body + "\n" +
+		"--" + boundary + "\nContent-Type: text/calendar; charset=\"UTF-8\"; method=PUBLISH\n" +
+		"Content-Disposition: attachment; filename=\"invite.ics\"\n\n" +
+		calendarInvite(inviteID, title, location, start, end) + "--" + boundary + "--\n"
+
+	msg := []byte(subject + mime + parts)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, []string{toEmail}, msg); err != nil {
+		return fmt.Errorf("failed to send calendar invite email: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPEmailService) SendForecastVarianceEmail(toEmails []string, summary, suggestion string) error {
+	if len(toEmails) == 0 {
+		return nil
+	}
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %v | Forecast Variance | %s | %s\n", toEmails, summary, suggestion)
+		return nil
+	}
+
+	subject := "Subject: Orders Are Off Forecast Today\n"
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #fff3cd; color: #856404; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .detail-box { background: linear-gradient(135deg, #F2DFDF 0%%, #ffffff 100%%); border-left: 4px solid #BF4124; border-radius: 8px; padding: 20px; margin: 20px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .action-note { background: #e8f4fd; border-left: 4px solid #010440; padding: 15px 20px; border-radius: 6px; margin: 20px 0; font-size: 14px; color: #010440; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">Attention Required 📈</div>
+            <div class="badge">⚠️ OFF FORECAST</div>
+            <div class="detail-box">%s</div>
+            <div class="action-note">
+                <strong>🔔 Suggestion:</strong> %s
+            </div>
+            <p class="message">
+                Please log in to AntiClockWise to check today's schedule.
+            </p>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, html.EscapeString(summary), html.EscapeString(suggestion))
+
+	msg := []byte(subject + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, toEmails, msg); err != nil {
+		return fmt.Errorf("failed to send forecast variance email: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPEmailService) SendStandbyActivatedEmail(toEmail, fullName, date, startTime, endTime string) error {
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %s | Standby Shift Activated | %s %s-%s\n", toEmail, date, startTime, endTime)
+		return nil
+	}
+
+	subject := "Subject: You Are Called In For Your Standby Shift\n"
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #fff3cd; color: #856404; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .detail-box { background: linear-gradient(135deg, #F2DFDF 0%%, #ffffff 100%%); border-left: 4px solid #BF4124; border-radius: 8px; padding: 20px; margin: 20px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">Hello, %s! 👋</div>
+            <div class="badge">📞 STANDBY ACTIVATED</div>
+            <p class="message">
+                Your manager has called you in. Your standby shift is now a working shift:
+            </p>
+            <div class="detail-box"><strong>%s</strong>, %s to %s</div>
+            <p class="message">
+                Please confirm with your manager and log in to AntiClockWise to check your schedule.
+            </p>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, html.EscapeString(fullName), date, startTime, endTime)
+
+	msg := []byte(subject + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, []string{toEmail}, msg); err != nil {
+		return fmt.Errorf("failed to send standby activated email: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPEmailService) SendRequestAwaitingApprovalEmail(toEmails []string, employeeName, requestType, approvedBy string) error {
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %v | %s Request from %s Awaiting Your Sign-off | Approved by %s\n", toEmails, requestType, employeeName, approvedBy)
+		return nil
+	}
+
+	subject := "Subject: Action Required — Request Awaiting Your Sign-off\n"
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #fff3cd; color: #856404; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .detail-box { background: linear-gradient(135deg, #F2DFDF 0%%, #ffffff 100%%); border-left: 4px solid #BF4124; border-radius: 8px; padding: 20px; margin: 20px 0; }
+        .detail-label { font-weight: 600; color: #031D40; font-size: 13px; text-transform: uppercase; margin-bottom: 5px; }
+        .detail-value { font-size: 15px; color: #0D0D0D; margin-bottom: 12px; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .action-note { background: #e8f4fd; border-left: 4px solid #010440; padding: 15px 20px; border-radius: 6px; margin: 20px 0; font-size: 14px; color: #010440; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">Attention Required 📋</div>
+            <div class="badge">✍️ AWAITING YOUR SIGN-OFF</div>
+            <p class="message">
+                A request from <strong>%s</strong> was approved by <strong>%s</strong> and now needs your sign-off before it is final.
+            </p>
+            <div class="detail-box">
+                <div class="detail-label">Request Type</div>
+                <div class="detail-value"><strong>%s</strong></div>
+            </div>
+            <div class="action-note">
+                <strong>🔔 Action Needed:</strong> Please log in to AntiClockWise to approve or decline this request.
+            </div>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, html.EscapeString(employeeName), html.EscapeString(approvedBy), requestType)
+
+	msg := []byte(subject + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, toEmails, msg); err != nil {
+		return fmt.Errorf("failed to send request awaiting approval email: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPEmailService) SendRequestStepApprovedEmail(toEmail, fullName, requestType, nextRole string) error {
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %s | %s Request Approved, Awaiting %s Sign-off\n", toEmail, requestType, nextRole)
+		return nil
+	}
+
+	subject := "Subject: Your Request Moved to the Next Approval Step\n"
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #fff3cd; color: #856404; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">Hello, %s! 👋</div>
+            <div class="badge">⏳ NEXT APPROVAL STEP</div>
+            <p class="message">
+                Your <strong>%s</strong> request was approved and is now waiting for the sign-off of an <strong>%s</strong>.
+                We will email you again once it is final.
+            </p>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, html.EscapeString(fullName), requestType, nextRole)
+
+	msg := []byte(subject + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, []string{toEmail}, msg); err != nil {
+		return fmt.Errorf("failed to send request step approved email: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPEmailService) SendDocumentExpiryEmail(toEmails []string, employeeName, document, expiresOn string, daysLeft int) error {
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %v | %s of %s Expires on %s (%d days left)\n", toEmails, document, employeeName, expiresOn, daysLeft)
+		return nil
+	}
+
+	status := fmt.Sprintf("expires in <strong>%d days</strong>, on %s", daysLeft, expiresOn)
+	if daysLeft <= 0 {
+		status = fmt.Sprintf("<strong>expired</strong> on %s", expiresOn)
+	}
+
+	subject := fmt.Sprintf("Subject: %s of %s Is Expiring\n", document, employeeName)
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .badge { display: inline-block; background: #f8d7da; color: #721c24; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="badge">🪪 DOCUMENT EXPIRY</div>
+            <p class="message">
+                The <strong>%s</strong> of <strong>%s</strong> %s.
+                Please upload the renewed document in the employee's compliance details. Employees are not
+                scheduled once their work permit has expired.
+            </p>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, document, html.EscapeString(employeeName), status)
+
+	msg := []byte(subject + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, toEmails, msg); err != nil {
+		return fmt.Errorf("failed to send document expiry email: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPEmailService) SendShiftCancelledEmail(toEmail, fullName, date, startTime, endTime, reason string) error {
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %s | Shift Cancelled | %s %s-%s | Reason: %s\n", toEmail, date, startTime, endTime, reason)
+		return nil
+	}
+
+	subject := "Subject: Your Shift Is Cancelled\n"
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #f8d7da; color: #721c24; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .detail-box { background: linear-gradient(135deg, #F2DFDF 0%%, #ffffff 100%%); border-left: 4px solid #BF4124; border-radius: 8px; padding: 20px; margin: 20px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">Hello, %s! 👋</div>
+            <div class="badge">❌ SHIFT CANCELLED</div>
+            <p class="message">
+                Your manager has cancelled the following shift:
+            </p>
+            <div class="detail-box"><strong>%s</strong>, %s to %s</div>
+            <p class="message"><strong>Reason:</strong> %s</p>
+            <p class="message">
+                Please log in to AntiClockWise to check your updated schedule.
+            </p>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, html.EscapeString(fullName), date, startTime, endTime, html.EscapeString(reason))
+
+	msg := []byte(subject + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, []string{toEmail}, msg); err != nil {
+		return fmt.Errorf("failed to send shift cancelled email: %w", err)
+	}
+	return nil
+}
+
+// SendWeeklyScheduleEmail lists the shifts of the coming week with their total hours and, when the salary
+// is known, the estimated pay
+func (s *SMTPEmailService) SendWeeklyScheduleEmail(toEmail, fullName, weekOf string, shifts []string, totalHours float64, estimatedPay *float64) error {
+	if len(s.optedIn(database.EmailCategoryWeeklySummary, []string{toEmail})) == 0 {
+		return nil
+	}
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %s | Week at a Glance (%s) | Shifts: %v | Hours: %.1f\n", toEmail, weekOf, shifts, totalHours)
+		return nil
+	}
+
+	var shiftItems strings.Builder
+	for _, shift := range shifts {
+		fmt.Fprintf(&shiftItems, "<li>%s</li>", html.EscapeString(shift))
+	}
+	summary := fmt.Sprintf("<strong>Total hours:</strong> %.1f", totalHours)
+	if estimatedPay != nil {
+		summary += fmt.Sprintf("<br><strong>Estimated pay:</strong> $%.2f", *estimatedPay)
+	}
+
+	subject := fmt.Sprintf("Subject: Your Week at a Glance — Week of %s\n", weekOf)
+	unsubscribeHeaders, unsubscribeFooter := s.unsubscribe(toEmail, database.EmailCategoryWeeklySummary)
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #d4edda; color: #155724; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .detail-box { background: linear-gradient(135deg, #F2DFDF 0%%, #ffffff 100%%); border-left: 4px solid #BF4124; border-radius: 8px; padding: 20px 20px 20px 40px; margin: 20px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">Hello, %s! 👋</div>
+            <div class="badge">🗓️ YOUR WEEK AT A GLANCE</div>
+            <p class="message">
+                Here are your shifts for the week of %s:
+            </p>
+            <ul class="detail-box">%s</ul>
+            <p class="message">%s</p>
+            <p class="message">
+                The estimated pay is before taxes and deductions. Please log in to AntiClockWise for any changes to your schedule.
+            </p>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+            %s
+        </div>
+    </div>
+</body>
+</html>`, html.EscapeString(fullName), weekOf, shiftItems.String(), summary, unsubscribeFooter)
+
+	msg := []byte(subject + unsubscribeHeaders + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, []string{toEmail}, msg); err != nil {
+		return fmt.Errorf("failed to send weekly schedule email: %w", err)
+	}
+	return nil
+}
+
+// SendDocumentSignatureEmail sends the link an employee signs a generated document with, also to employees
+// who were laid off and cannot log in anymore
+func (s *SMTPEmailService) SendDocumentSignatureEmail(toEmail, fullName, title, link, expiresOn string) error {
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %s | Document to Sign | %s | %s (until %s)\n", toEmail, title, link, expiresOn)
+		return nil
+	}
+
+	subject := fmt.Sprintf("Subject: Please Sign: %s\n", title)
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #d4edda; color: #155724; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .cta-button { display: inline-block; background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); color: #ffffff; text-decoration: none; padding: 14px 32px; border-radius: 8px; font-weight: 600; font-size: 16px; margin: 20px 0; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">Hello, %s! 👋</div>
+            <div class="badge">✍️ DOCUMENT TO SIGN</div>
+            <p class="message">
+                <strong>%s</strong> is ready for your signature. Open the link to read the document and sign it
+                electronically. The link works until %s.
+            </p>
+            <a class="cta-button" href="%s">Review and Sign</a>
+            <p class="message">
+                Your name, the time of signing and your IP address are recorded with your signature.
+            </p>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, html.EscapeString(fullName), html.EscapeString(title), expiresOn, html.EscapeString(link))
+
+	msg := []byte(subject + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, []string{toEmail}, msg); err != nil {
+		return fmt.Errorf("failed to send document signature email: %w", err)
+	}
+	return nil
+}
+
+// SendMagicLinkEmail sends the single-use link that logs an employee in without a password
+func (s *SMTPEmailService) SendMagicLinkEmail(toEmail, fullName, link string, validFor time.Duration) error {
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %s | Login Link | %s (valid %s)\n", toEmail, link, validFor)
+		return nil
+	}
+
+	subject := "Subject: Your AntiClockWise Login Link\n"
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #d4edda; color: #155724; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .cta-button { display: inline-block; background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); color: #ffffff; text-decoration: none; padding: 14px 32px; border-radius: 8px; font-weight: 600; font-size: 16px; margin: 20px 0; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">Hello, %s! 👋</div>
+            <div class="badge">🔑 LOGIN LINK</div>
+            <p class="message">
+                Use the button below to log in without your password. The link works once, for the next %d minutes.
+            </p>
+            <a class="cta-button" href="%s">Log In</a>
+            <p class="message">
+                If you did not ask for this link you can ignore this email, nobody can log in without it.
+            </p>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, html.EscapeString(fullName), int(validFor.Minutes()), html.EscapeString(link))
+
+	msg := []byte(subject + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, []string{toEmail}, msg); err != nil {
+		return fmt.Errorf("failed to send magic link email: %w", err)
+	}
+	return nil
+}
+
+// SendComplianceReportEmail sends the admins the weekly compliance report of their organization: the late
+// shift cancellations and the predictability pay owed per employee for the schedule changes of the week
+func (s *SMTPEmailService) SendComplianceReportEmail(toEmails []string, weekOf string, lateCancellations int, employees []string, owedHours, owedPay float64) error {
+	if len(toEmails) == 0 {
+		return nil
+	}
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %v | Compliance Report (%s) | Late cancellations: %d | Owed: %v | %.2f hours, $%.2f\n",
+			toEmails, weekOf, lateCancellations, employees, owedHours, owedPay)
+		return nil
+	}
+
+	owed := "<p class=\"message\">No predictability pay is owed for the changes of the week.</p>"
+	if len(employees) > 0 {
+		var items strings.Builder
+		for _, employee := range employees {
+			fmt.Fprintf(&items, "<li>%s</li>", html.EscapeString(employee))
+		}
+		owed = fmt.Sprintf(`<p class="message">Predictability pay owed for schedule changes made within the notice period:</p>
+            <ul class="detail-box">%s</ul>
+            <p class="message"><strong>Total:</strong> %.2f hours, $%.2f</p>`, items.String(), owedHours, owedPay)
+	}
+
+	subject := fmt.Sprintf("Subject: Scheduling Compliance Report — Week of %s\n", weekOf)
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #fff3cd; color: #856404; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .detail-box { background: linear-gradient(135deg, #F2DFDF 0%%, #ffffff 100%%); border-left: 4px solid #BF4124; border-radius: 8px; padding: 20px 20px 20px 40px; margin: 20px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">Weekly Compliance Report 📋</div>
+            <div class="badge">⚖️ WEEK OF %s</div>
+            <p class="message"><strong>Late shift cancellations:</strong> %d</p>
+            %s
+            <p class="message">
+                Please log in to AntiClockWise for the details of each change and make sure the pay is included in the next payroll.
+            </p>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, strings.ToUpper(weekOf), lateCancellations, owed)
+
+	msg := []byte(subject + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, toEmails, msg); err != nil {
+		return fmt.Errorf("failed to send compliance report email: %w", err)
+	}
+	return nil
+}
+
+// EmailAttachment is a file attached to an email
+type EmailAttachment struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+// mimePart returns the attachment as a base64 part of a multipart message
+func (a EmailAttachment) mimePart(boundary string) string {
+	encoded := base64.StdEncoding.EncodeToString(a.Data)
+	var lines strings.Builder
+	for len(encoded) > 76 {
+		lines.WriteString(encoded[:76] + "\n")
+		encoded = encoded[76:]
+	}
+	lines.WriteString(encoded + "\n")
+	return "--" + boundary + "\nContent-Type: " + a.ContentType + "; name=\"" + a.FileName + "\"\n" +
+		"Content-Transfer-Encoding: base64\n" +
+		"Content-Disposition: attachment; filename=\"" + a.FileName + "\"\n\n" + lines.String()
+}
+
+// SendEncryptedComplianceReportEmail sends the weekly compliance report of an organization that encrypts
+// its exports, the owed pay per employee is only in the encrypted attachment
+func (s *SMTPEmailService) SendEncryptedComplianceReportEmail(toEmails []string, weekOf string, lateCancellations int, attachment EmailAttachment) error {
+	if len(toEmails) == 0 {
+		return nil
+	}
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %v | Compliance Report (%s) | Late cancellations: %d | Attachment: %s (%d bytes)\n",
+			toEmails, weekOf, lateCancellations, attachment.FileName, len(attachment.Data))
+		return nil
+	}
+
+	const boundary = "anticlockwise-report"
+	subject := fmt.Sprintf("Subject: Scheduling Compliance Report — Week of %s\n", weekOf)
+	mime := "MIME-version: 1.0;\nContent-Type: multipart/mixed; boundary=\"" + boundary + "\"\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #fff3cd; color: #856404; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">Weekly Compliance Report 📋</div>
+            <div class="badge">⚖️ WEEK OF %s</div>
+            <p class="message"><strong>Late shift cancellations:</strong> %d</p>
+            <p class="message">
+                The predictability pay owed for the week is in the attached file, encrypted with the key of your organization.
+                Open it with gpg or another OpenPGP tool.
+            </p>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, strings.ToUpper(weekOf), lateCancellations)
+
+	parts := "--" + boundary + "\nContent-Type: text/html; charset=\"UTF-8\"\n\n" + body + "\n" +
+		attachment.mimePart(boundary) + "--" + boundary + "--\n"
+
+	msg := []byte(subject + mime + parts)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, toEmails, msg); err != nil {
+		return fmt.Errorf("failed to send compliance report email: %w", err)
+	}
+	return nil
+}
+
+// SendSkillProposedEmail asks the managers to verify a skill or certification an employee declared
+func (s *SMTPEmailService) SendSkillProposedEmail(toEmails []string, employeeName, skill, kind string) error {
+	if len(toEmails) == 0 {
+		return nil
+	}
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %v | %s Declared the %s %s | Awaiting Verification\n", toEmails, employeeName, kind, skill)
+		return nil
+	}
+
+	subject := fmt.Sprintf("Subject: Action Required — Verify the %s of %s\n", kind, employeeName)
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #fff3cd; color: #856404; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .action-note { background: #e8f4fd; border-left: 4px solid #010440; padding: 15px 20px; border-radius: 6px; margin: 20px 0; font-size: 14px; color: #010440; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">Attention Required 📋</div>
+            <div class="badge">🎓 AWAITING VERIFICATION</div>
+            <p class="message">
+                <strong>%s</strong> declared the %s <strong>%s</strong>. It does not count towards the roles they can be
+                scheduled in until it is verified.
+            </p>
+            <div class="action-note">
+                <strong>🔔 Action Needed:</strong> Please log in to AntiClockWise to verify or reject it.
+            </div>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, html.EscapeString(employeeName), kind, html.EscapeString(skill))
+
+	msg := []byte(subject + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, toEmails, msg); err != nil {
+		return fmt.Errorf("failed to send skill proposed email: %w", err)
+	}
+	return nil
+}
+
+// SendSkillReviewedEmail tells an employee whether a skill they declared was verified or rejected
+func (s *SMTPEmailService) SendSkillReviewedEmail(toEmail, fullName, skill, status string, note *string) error {
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %s | Skill %s %s\n", toEmail, skill, status)
+		return nil
+	}
+
+	badge := "✅ SKILL VERIFIED"
+	outcome := "was verified by your manager and now counts towards the roles you can be scheduled in."
+	if status == database.SkillRejected {
+		badge = "❌ SKILL NOT VERIFIED"
+		outcome = "could not be verified by your manager. You can declare it again with more details."
+	}
+	comment := ""
+	if note != nil && *note != "" {
+		comment = fmt.Sprintf(`<p class="message"><strong>Comment:</strong> %s</p>`, html.EscapeString(*note))
+	}
+
+	subject := fmt.Sprintf("Subject: Your Skill %s Was Reviewed\n", skill)
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #e8f4fd; color: #010440; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">Hello, %s! 👋</div>
+            <div class="badge">%s</div>
+            <p class="message">
+                <strong>%s</strong> %s
+            </p>
+            %s
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, html.EscapeString(fullName), badge, html.EscapeString(skill), outcome, comment)
+
+	msg := []byte(subject + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, []string{toEmail}, msg); err != nil {
+		return fmt.Errorf("failed to send skill reviewed email: %w", err)
+	}
+	return nil
+}
+
+// SendSkillExpiryEmail reminds an employee and their managers that a verified certification is about to
+// expire, after which the employee is not scheduled in the roles requiring it
+func (s *SMTPEmailService) SendSkillExpiryEmail(toEmails []string, employeeName, skill, expiresOn string, daysLeft int) error {
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %v | %s of %s Expires on %s (%d days left)\n", toEmails, skill, employeeName, expiresOn, daysLeft)
+		return nil
+	}
+
+	status := fmt.Sprintf("expires in <strong>%d days</strong>, on %s", daysLeft, expiresOn)
+	if daysLeft <= 0 {
+		status = fmt.Sprintf("<strong>expired</strong> on %s", expiresOn)
+	}
+
+	subject := fmt.Sprintf("Subject: %s of %s Is Expiring\n", skill, employeeName)
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .badge { display: inline-block; background: #f8d7da; color: #721c24; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="badge">🎓 CERTIFICATION EXPIRY</div>
+            <p class="message">
+                The <strong>%s</strong> of <strong>%s</strong> %s.
+                Once it has expired, they are not scheduled in the roles requiring it until the renewed
+                certification is declared and verified again.
+            </p>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, html.EscapeString(skill), html.EscapeString(employeeName), status)
+
+	msg := []byte(subject + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, toEmails, msg); err != nil {
+		return fmt.Errorf("failed to send skill expiry email: %w", err)
+	}
+	return nil
+}
+
+// SendBroadcastEmail sends a broadcast to one of its recipients. Broadcasts are operational messages of
+// the organization to its staff, so unlike announcements they cannot be opted out of.
+func (s *SMTPEmailService) SendBroadcastEmail(toEmail, senderName, title, message string) error {
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %s | Broadcast from %s | Title: %s | Message: %s\n", toEmail, senderName, title, message)
+		return nil
+	}
+
+	// the title goes into a header, so it must stay on one line
+	subject := fmt.Sprintf("Subject: %s\n", strings.NewReplacer("\r", " ", "\n", " ").Replace(title))
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #fff3cd; color: #856404; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .detail-box { background: linear-gradient(135deg, #F2DFDF 0%%, #ffffff 100%%); border-left: 4px solid #BF4124; border-radius: 8px; padding: 20px; margin: 20px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; white-space: pre-line; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">%s</div>
+            <div class="badge">📣 MESSAGE TO THE STAFF</div>
+            <div class="detail-box">
+                <p class="message">%s</p>
+            </div>
+            <p style="font-size: 14px; color: #6c757d; margin-top: 25px;">Sent by %s.</p>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, html.EscapeString(title), html.EscapeString(message), html.EscapeString(senderName))
+
+	msg := []byte(subject + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, []string{toEmail}, msg); err != nil {
+		return fmt.Errorf("failed to send broadcast email: %w", err)
+	}
+	return nil
+}
+
+// SendExportReadyEmail sends the requester of an export the link to download its file
+func (s *SMTPEmailService) SendExportReadyEmail(toEmail, fullName, fileName, link, expiresAt string) error {
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %s | Export Ready | %s | %s (until %s)\n", toEmail, fileName, link, expiresAt)
+		return nil
+	}
+
+	subject := fmt.Sprintf("Subject: Your Export Is Ready: %s\n", fileName)
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #d4edda; color: #155724; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .cta-button { display: inline-block; background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); color: #ffffff; text-decoration: none; padding: 14px 32px; border-radius: 8px; font-weight: 600; font-size: 16px; margin: 20px 0; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">Hello, %s! 👋</div>
+            <div class="badge">📦 EXPORT READY</div>
+            <p class="message">
+                Your export <strong>%s</strong> is ready. The link works until %s, a new one can be
+                requested from the exports page until the file is deleted.
+            </p>
+            <a class="cta-button" href="%s">Download</a>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, html.EscapeString(fullName), html.EscapeString(fileName), expiresAt, html.EscapeString(link))
+
+	msg := []byte(subject + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, []string{toEmail}, msg); err != nil {
+		return fmt.Errorf("failed to send export ready email: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPEmailService) SendOrderAcceptanceEmail(toEmails []string, accepting bool, summary string) error {
+	if len(toEmails) == 0 {
+		return nil
+	}
+	title, badge, note := "Orders Paused Automatically", "⛔ ORDERS PAUSED",
+		"Orders reopen by themselves once the load drops, or right away when accepting_orders is turned back on in the rules."
+	if accepting {
+		title, badge, note = "Orders Reopened Automatically", "✅ ORDERS REOPENED",
+			"The load dropped back to what the staff on shift can handle."
+	}
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %v | %s | %s\n", toEmails, title, summary)
+		return nil
+	}
+
+	subject := fmt.Sprintf("Subject: %s\n", title)
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #fff3cd; color: #856404; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .detail-box { background: linear-gradient(135deg, #F2DFDF 0%%, #ffffff 100%%); border-left: 4px solid #BF4124; border-radius: 8px; padding: 20px; margin: 20px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .action-note { background: #e8f4fd; border-left: 4px solid #010440; padding: 15px 20px; border-radius: 6px; margin: 20px 0; font-size: 14px; color: #010440; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">%s</div>
+            <div class="badge">%s</div>
+            <div class="detail-box">%s</div>
+            <div class="action-note">
+                <strong>🔔 Note:</strong> %s
+            </div>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, title, badge, html.EscapeString(summary), note)
+
+	msg := []byte(subject + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, toEmails, msg); err != nil {
+		return fmt.Errorf("failed to send order acceptance email: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPEmailService) SendDeliverySLAEmail(toEmails []string, summary string) error {
+	if len(toEmails) == 0 {
+		return nil
+	}
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %v | Delivery SLA Breached | %s\n", toEmails, summary)
+		return nil
+	}
+
+	subject := "Subject: Deliveries Are Running Late Today\n"
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #fff3cd; color: #856404; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .detail-box { background: linear-gradient(135deg, #F2DFDF 0%%, #ffffff 100%%); border-left: 4px solid #BF4124; border-radius: 8px; padding: 20px; margin: 20px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .action-note { background: #e8f4fd; border-left: 4px solid #010440; padding: 15px 20px; border-radius: 6px; margin: 20px 0; font-size: 14px; color: #010440; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">Attention Required 🛵</div>
+            <div class="badge">⚠️ DELIVERY SLA BREACHED</div>
+            <div class="detail-box">%s</div>
+            <div class="action-note">
+                <strong>🔔 Suggestion:</strong> Consider calling in more drivers or pausing orders until deliveries catch up.
+            </div>
+            <p class="message">
+                Please log in to AntiClockWise to check the driver insights of today.
+            </p>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, html.EscapeString(summary))
+
+	msg := []byte(subject + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, toEmails, msg); err != nil {
+		return fmt.Errorf("failed to send delivery SLA email: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPEmailService) SendTrainingGraduationEmail(toEmails []string, summary string) error {
+	if len(toEmails) == 0 {
+		return nil
+	}
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %v | Training Completed | %s\n", toEmails, summary)
+		return nil
+	}
+
+	subject := "Subject: An Employee Completed Their Training\n"
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #d4edda; color: #155724; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .detail-box { background: linear-gradient(135deg, #F2DFDF 0%%, #ffffff 100%%); border-left: 4px solid #BF4124; border-radius: 8px; padding: 20px; margin: 20px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .action-note { background: #e8f4fd; border-left: 4px solid #010440; padding: 15px 20px; border-radius: 6px; margin: 20px 0; font-size: 14px; color: #010440; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">Training Completed 🎓</div>
+            <div class="badge">✅ GRADUATED</div>
+            <div class="detail-box">%s</div>
+            <div class="action-note">
+                <strong>🔔 Note:</strong> The next generated schedules count them toward the staff needed, solo coverage roles included.
+            </div>
+            <p class="message">
+                Please log in to AntiClockWise to review their schedule.
+            </p>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, html.EscapeString(summary))
+
+	msg := []byte(subject + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, toEmails, msg); err != nil {
+		return fmt.Errorf("failed to send training graduation email: %w", err)
+	}
+	return nil
+}
+
+// SendKPIReportEmail sends the admins how the past week of their organization did against its KPI targets,
+// a line per KPI
+func (s *SMTPEmailService) SendKPIReportEmail(toEmails []string, weekOf string, results []string) error {
+	if len(toEmails) == 0 {
+		return nil
+	}
+	if s.host == "" {
+		log.Printf("\n[MOCK EMAIL] To: %v | KPI Report (%s) | %v\n", toEmails, weekOf, results)
+		return nil
+	}
+
+	var items strings.Builder
+	for _, result := range results {
+		fmt.Fprintf(&items, "<li>%s</li>", html.EscapeString(result))
+	}
+
+	subject := fmt.Sprintf("Subject: KPI Targets Report — Week of %s\n", weekOf)
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: 'Rubik', Arial, sans-serif; background-color: #F2DFDF; margin: 0; padding: 0; }
+        .container { max-width: 600px; margin: 0 auto; background: #ffffff; }
+        .header { background: linear-gradient(135deg, #010440 0%%, #031D40 100%%); padding: 30px; text-align: center; color: #ffffff; }
+        .header h1 { font-size: 28px; margin: 0 0 5px 0; }
+        .content { padding: 35px 40px; color: #0D0D0D; }
+        .greeting { font-size: 22px; color: #010440; font-weight: 600; margin-bottom: 15px; }
+        .badge { display: inline-block; background: #e8f4fd; color: #010440; padding: 8px 18px; border-radius: 20px; font-weight: 600; font-size: 14px; margin: 15px 0; }
+        .detail-box { background: linear-gradient(135deg, #F2DFDF 0%%, #ffffff 100%%); border-left: 4px solid #BF4124; border-radius: 8px; padding: 20px 20px 20px 40px; margin: 20px 0; }
+        .message { font-size: 16px; line-height: 1.8; margin: 20px 0; }
+        .footer { background-color: #F2DFDF; padding: 20px; text-align: center; font-size: 13px; color: #031D40; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⏰ AntiClockWise</h1>
+            <p>Workforce Management & Scheduling</p>
+        </div>
+        <div class="content">
+            <div class="greeting">Weekly KPI Report 🎯</div>
+            <div class="badge">📈 WEEK OF %s</div>
+            <p class="message">How the week did against the targets of your organization:</p>
+            <ul class="detail-box">%s</ul>
+            <p class="message">
+                Please log in to AntiClockWise to follow the progress or adjust the targets.
+            </p>
+        </div>
+        <div class="footer">
+            <p><strong>AntiClockWise</strong></p>
+            <p>This is an automated message. Please do not reply to this email.</p>
+            <p>&copy; 2026 AntiClockWise. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`, strings.ToUpper(weekOf), items.String())
+
+	msg := []byte(subject + mime + body)
+	addr := s.host + ":" + s.port
+
+	if err := s.sendMail(addr, toEmails, msg); err != nil {
+		return fmt.Errorf("failed to send KPI report email: %w", err)
+	}
+	return nil
+}
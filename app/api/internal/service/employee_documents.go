@@ -0,0 +1,201 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+)
+
+// Longest title and body of a document template
+const (
+	maxDocumentTitle = 200
+	maxDocumentBody  = 20000
+)
+
+// DocumentFields are the values a document template can use, e.g. {{.EmployeeName}}. Optional fields
+// are empty when unknown, templates test them with {{if .StartDate}}.
+type DocumentFields struct {
+	EmployeeName  string
+	EmployeeEmail string
+	Role          string
+	Organization  string
+	// Date the document is issued on, YYYY-MM-DD
+	Date         string
+	HourlySalary string
+	StartDate    string
+	LastDay      string
+	Reason       string
+}
+
+// defaultDocumentTemplates are used for the kinds an organization has no template of
+var defaultDocumentTemplates = map[string]database.DocumentTemplate{
+	database.DocumentOfferLetter: {
+		Kind:  database.DocumentOfferLetter,
+		Title: "Offer of Employment - {{.Organization}}",
+		Body: `{{.Date}}
+
+Dear {{.EmployeeName}},
+
+We are pleased to offer you the position of {{.Role}} at {{.Organization}}{{if .StartDate}}, starting on {{.StartDate}}{{end}}.{{if .HourlySalary}} Your pay will be {{.HourlySalary}} per hour.{{end}}
+
+Your shifts are scheduled according to your availability and the needs of the business, and are published in advance.
+
+Please sign this letter to accept the offer.
+
+Sincerely,
+{{.Organization}}`,
+	},
+	database.DocumentTerminationLetter: {
+		Kind:  database.DocumentTerminationLetter,
+		Title: "Termination of Employment - {{.Organization}}",
+		Body: `{{.Date}}
+
+Dear {{.EmployeeName}},
+
+This letter confirms that your employment as {{.Role}} at {{.Organization}} ends {{if .LastDay}}on {{.LastDay}}{{else}}effective immediately{{end}}.
+{{if .Reason}}
+Reason: {{.Reason}}
+{{end}}
+Any outstanding pay will be settled with the next payroll. Please return any property of {{.Organization}} still in your possession.
+
+Please sign this letter to acknowledge that you received it.
+
+Sincerely,
+{{.Organization}}`,
+	},
+}
+
+// DefaultDocumentTemplate returns the built-in template of a kind of document
+func DefaultDocumentTemplate(kind string) (database.DocumentTemplate, bool) {
+	template, ok := defaultDocumentTemplates[kind]
+	return template, ok
+}
+
+// RenderDocument fills the title and body templates with the fields
+func RenderDocument(titleTemplate, bodyTemplate string, fields DocumentFields) (title, body string, err error) {
+	if title, err = executeDocumentTemplate("title", titleTemplate, fields); err != nil {
+		return "", "", err
+	}
+	if body, err = executeDocumentTemplate("body", bodyTemplate, fields); err != nil {
+		return "", "", err
+	}
+	return strings.TrimSpace(title), strings.TrimSpace(body), nil
+}
+
+func executeDocumentTemplate(name, text string, fields DocumentFields) (string, error) {
+	parsed, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := parsed.Execute(&buf, fields); err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// ValidateDocumentTemplate checks the template renders, with every field set, and fits the limits
+func ValidateDocumentTemplate(title, body string) error {
+	if strings.TrimSpace(title) == "" || strings.TrimSpace(body) == "" {
+		return fmt.Errorf("title and body are required")
+	}
+	if len(title) > maxDocumentTitle {
+		return fmt.Errorf("title must be at most %d characters", maxDocumentTitle)
+	}
+	if len(body) > maxDocumentBody {
+		return fmt.Errorf("body must be at most %d characters", maxDocumentBody)
+	}
+	sample := DocumentFields{
+		EmployeeName: "Sam Carter", EmployeeEmail: "sam@example.com", Role: "waiter", Organization: "Example",
+		Date: "2026-01-01", HourlySalary: "15.00", StartDate: "2026-01-05", LastDay: "2026-01-31", Reason: "Restructuring",
+	}
+	_, _, err := RenderDocument(title, body, sample)
+	return err
+}
+
+// DocumentParagraphs splits a rendered body at its blank lines
+func DocumentParagraphs(body string) []string {
+	paragraphs := []string{}
+	for _, paragraph := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n\n") {
+		if paragraph = strings.Trim(paragraph, "\n"); strings.TrimSpace(paragraph) != "" {
+			paragraphs = append(paragraphs, paragraph)
+		}
+	}
+	return paragraphs
+}
+
+// RenderDocumentPDF renders a document below the branding of its organization. A signed document ends with
+// its signature: who signed it, when and from which IP address.
+func RenderDocumentPDF(branding *PDFBranding, doc *database.EmployeeDocument) []byte {
+	maxChars := (pdfPageWidth - 2*pdfMargin) / pdfCharWidth
+	lines := append(wrapPDFText(doc.Title, maxChars), "")
+	for _, paragraph := range DocumentParagraphs(doc.Body) {
+		for _, line := range strings.Split(paragraph, "\n") {
+			lines = append(lines, wrapPDFText(line, maxChars)...)
+		}
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, strings.Repeat("-", maxChars))
+	if doc.SignedAt != nil && doc.SignerName != nil {
+		signerIP := ""
+		if doc.SignerIP != nil {
+			signerIP = *doc.SignerIP
+		}
+		lines = append(lines,
+			"Electronically signed by "+*doc.SignerName+" ("+doc.EmployeeEmail+")",
+			"Signed at "+doc.SignedAt.UTC().Format(time.RFC3339)+" from IP address "+signerIP,
+		)
+	} else {
+		lines = append(lines, "Awaiting the signature of "+doc.EmployeeName+" ("+doc.EmployeeEmail+")")
+	}
+	lines = append(lines, "Document "+doc.ID.String())
+
+	// the last line of every page holds its number
+	linesPerPage := pdfLinesPerPage - 1
+	if branding != nil {
+		linesPerPage = (pdfPageHeight-2*pdfMargin-pdfBandHeight)/pdfLineHeight - 1
+	}
+	var pages [][]string
+	for len(lines) > linesPerPage {
+		pages = append(pages, lines[:linesPerPage])
+		lines = lines[linesPerPage:]
+	}
+	pages = append(pages, lines)
+	return writePDF(pages, branding)
+}
+
+// wrapPDFText breaks a line at spaces into lines of at most width characters, cutting longer words
+func wrapPDFText(text string, width int) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return []string{""}
+	}
+	var lines []string
+	line := []rune{}
+	for _, word := range words {
+		runes := []rune(word)
+		for len(runes) > width {
+			if len(line) > 0 {
+				lines = append(lines, string(line))
+				line = line[:0]
+			}
+			lines = append(lines, string(runes[:width]))
+			runes = runes[width:]
+		}
+		switch {
+		case len(line) == 0:
+			line = append(line, runes...)
+		case len(line)+1+len(runes) <= width:
+			line = append(append(line, ' '), runes...)
+		default:
+			lines = append(lines, string(line))
+			line = append([]rune{}, runes...)
+		}
+	}
+	return append(lines, string(line))
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+)
+
+// defaultExchangeRatesURL is the Frankfurter API, daily reference rates of the European Central Bank
+const defaultExchangeRatesURL = "https://api.frankfurter.app"
+
+var ErrExchangeRatesUnavailable = errors.New("exchange rates unavailable")
+
+// ExchangeRateProvider returns the exchange rates of a day as units of each currency per unit of base
+type ExchangeRateProvider interface {
+	Rates(ctx context.Context, base string, day time.Time) (map[string]float64, error)
+}
+
+// NewExchangeRateProvider returns the rates of the Frankfurter compatible API at EXCHANGE_RATES_URL,
+// cached per day in the store
+func NewExchangeRateProvider(store database.ExchangeRateStore, logger *slog.Logger) ExchangeRateProvider {
+	address := os.Getenv("EXCHANGE_RATES_URL")
+	if address == "" {
+		address = defaultExchangeRatesURL
+	}
+	return &CachedExchangeRates{
+		Store: store,
+		Provider: &HTTPExchangeRates{
+			URL:    strings.TrimRight(address, "/"),
+			Client: &http.Client{Timeout: durationFromEnv("EXCHANGE_RATES_TIMEOUT", 10*time.Second, logger)},
+			Logger: logger,
+		},
+		Logger: logger,
+	}
+}
+
+// HTTPExchangeRates fetches the rates of a day from GET {URL}/{YYYY-MM-DD}?from={base}, answered with
+// {"base": "EUR", "date": "2026-10-15", "rates": {"USD": 1.09}}
+type HTTPExchangeRates struct {
+	URL    string
+	Client *http.Client
+	Logger *slog.Logger
+}
+
+func (p *HTTPExchangeRates) Rates(ctx context.Context, base string, day time.Time) (map[string]float64, error) {
+	address := fmt.Sprintf("%s/%s?from=%s", p.URL, day.Format(time.DateOnly), url.QueryEscape(base))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, address, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		p.Logger.Error("failed to reach exchange rate provider", "error", err, "url", p.URL)
+		return nil, ErrExchangeRatesUnavailable
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		p.Logger.Error("exchange rate provider returned an error", "status", resp.StatusCode, "base", base)
+		return nil, ErrExchangeRatesUnavailable
+	}
+
+	var result struct {
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode exchange rates: %w", err)
+	}
+	return result.Rates, nil
+}
+
+// CachedExchangeRates asks the provider for the rates of a day only once, later requests are answered
+// from the store. A failure to cache the rates does not fail the request.
+type CachedExchangeRates struct {
+	Store    database.ExchangeRateStore
+	Provider ExchangeRateProvider
+	Logger   *slog.Logger
+}
+
+func (c *CachedExchangeRates) Rates(ctx context.Context, base string, day time.Time) (map[string]float64, error) {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	rates, err := c.Store.GetExchangeRates(base, day)
+	if err != nil {
+		return nil, err
+	}
+	if len(rates) > 0 {
+		return rates, nil
+	}
+
+	rates, err = c.Provider.Rates(ctx, base, day)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Store.StoreExchangeRates(base, day, rates); err != nil {
+		c.Logger.Error("failed to cache exchange rates", "error", err, "base", base, "day", day)
+	}
+	return rates, nil
+}
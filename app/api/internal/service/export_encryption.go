@@ -0,0 +1,100 @@
+package service
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/packet"
+	"golang.org/x/crypto/openpgp/s2k"
+)
+
+const (
+	// EncryptedFileExtension is appended to the name of an encrypted file, which gpg opens as is
+	EncryptedFileExtension = ".gpg"
+	EncryptedContentType   = "application/pgp-encrypted"
+	// MinExportPassphraseLength keeps passphrases from being guessed offline from a leaked file
+	MinExportPassphraseLength = 12
+)
+
+var ErrInvalidPublicKey = errors.New("public_key must be an ASCII-armored OpenPGP public key that can encrypt")
+
+var exportPGPConfig = &packet.Config{DefaultCipher: packet.CipherAES256}
+
+// readPGPKeys reads an ASCII-armored key ring. Keys that state no hash preference default to RIPEMD-160,
+// which is not compiled in; the files are not signed, so SHA-256 is assumed instead.
+func readPGPKeys(armored string) (openpgp.EntityList, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armored))
+	if err != nil {
+		return nil, err
+	}
+	sha256, _ := s2k.HashToHashId(crypto.SHA256)
+	for _, entity := range entities {
+		for _, identity := range entity.Identities {
+			if identity.SelfSignature != nil && len(identity.SelfSignature.PreferredHash) == 0 {
+				identity.SelfSignature.PreferredHash = []uint8{sha256}
+			}
+		}
+	}
+	return entities, nil
+}
+
+// ParsePGPPublicKey reads an ASCII-armored OpenPGP public key and returns the fingerprint of its primary
+// key. The key must be able to encrypt, a signing-only key is rejected.
+func ParsePGPPublicKey(armored string) (string, error) {
+	entities, err := readPGPKeys(armored)
+	if err != nil || len(entities) != 1 {
+		return "", ErrInvalidPublicKey
+	}
+	plaintext, err := openpgp.Encrypt(io.Discard, entities, nil, nil, exportPGPConfig)
+	if err != nil {
+		return "", ErrInvalidPublicKey
+	}
+	plaintext.Close()
+	return strings.ToUpper(hex.EncodeToString(entities[0].PrimaryKey.Fingerprint[:])), nil
+}
+
+// EncryptFile encrypts a file the way the organization asked for, into a binary OpenPGP message that
+// keeps fileName for gpg to restore
+func EncryptFile(encryption *database.ExportEncryption, fileName string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	hints := &openpgp.FileHints{IsBinary: true, FileName: fileName}
+
+	var plaintext io.WriteCloser
+	var err error
+	switch encryption.Mode {
+	case database.ExportEncryptionPGP:
+		if encryption.PublicKey == nil {
+			return nil, ErrInvalidPublicKey
+		}
+		entities, readErr := readPGPKeys(*encryption.PublicKey)
+		if readErr != nil {
+			return nil, readErr
+		}
+		plaintext, err = openpgp.Encrypt(&buf, entities, nil, hints, exportPGPConfig)
+	case database.ExportEncryptionPassphrase:
+		if encryption.Passphrase == nil {
+			return nil, fmt.Errorf("organization %s has no export passphrase", encryption.OrganizationID)
+		}
+		plaintext, err = openpgp.SymmetricallyEncrypt(&buf, []byte(*encryption.Passphrase), hints, exportPGPConfig)
+	default:
+		return nil, fmt.Errorf("unknown export encryption mode %q", encryption.Mode)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := plaintext.Write(data); err != nil {
+		return nil, err
+	}
+	if err := plaintext.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+)
+
+const (
+	defaultExportInterval  = 30 * time.Second
+	defaultExportLinkTTL   = 24 * time.Hour
+	defaultExportRetention = 7 * 24 * time.Hour
+	// How long a running job is left to its instance before another one claims it
+	exportJobLease = 30 * time.Minute
+	// A job claimed this many times without finishing keeps crashing its instance and is failed
+	maxExportAttempts = 3
+)
+
+// ExportService produces the queued export jobs of organizations to the export storage and emails their
+// requester a time-limited link to the file. The files of organizations that asked for it are encrypted
+// before they are stored. Files are deleted once their retention has ended.
+type ExportService struct {
+	Store        database.ExportJobStore
+	OrderStore   database.OrderStore
+	Encryption   database.ExportEncryptionStore
+	Storage      ExportStorage
+	EmailService EmailService
+	Logger       *slog.Logger
+
+	// Interval is how often queued jobs are looked for
+	Interval time.Duration
+	// LinkTTL is how long a download link works, a new one can be requested until the file expires
+	LinkTTL time.Duration
+	// Retention is how long the file of a completed job is kept
+	Retention time.Duration
+}
+
+// NewExportService reads EXPORT_INTERVAL, EXPORT_LINK_TTL and EXPORT_RETENTION (Go durations) and falls
+// back to looking for jobs every 30 seconds, links valid for a day and files kept for a week
+func NewExportService(store database.ExportJobStore, orderStore database.OrderStore, encryption database.ExportEncryptionStore, storage ExportStorage, emailService EmailService, Logger *slog.Logger) *ExportService {
+	return &ExportService{
+		Store:        store,
+		OrderStore:   orderStore,
+		Encryption:   encryption,
+		Storage:      storage,
+		EmailService: emailService,
+		Logger:       Logger,
+		Interval:     durationFromEnv("EXPORT_INTERVAL", defaultExportInterval, Logger),
+		LinkTTL:      durationFromEnv("EXPORT_LINK_TTL", defaultExportLinkTTL, Logger),
+		Retention:    durationFromEnv("EXPORT_RETENTION", defaultExportRetention, Logger),
+	}
+}
+
+// Start produces the queued jobs right away and then every Interval until the context is cancelled
+func (s *ExportService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.Logger.Info("export service started", "interval", s.Interval, "link_ttl", s.LinkTTL, "retention", s.Retention)
+	s.run(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			s.Logger.Info("export service stopped")
+			return
+		case <-ticker.C:
+			s.run(ctx)
+		}
+	}
+}
+
+func (s *ExportService) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := s.Store.ClaimExportJob(time.Now(), exportJobLease)
+		if err != nil || job == nil {
+			break
+		}
+		s.Produce(ctx, job)
+	}
+	s.Purge(ctx, time.Now())
+}
+
+// DownloadURL returns a link to the file of a completed job, valid for LinkTTL or until the file expires
+func (s *ExportService) DownloadURL(job *database.ExportJob) (string, *time.Time, error) {
+	if job.Status != database.ExportJobCompleted || job.FileKey == nil {
+		return "", nil, fmt.Errorf("export %s has no file", job.ID)
+	}
+	expires := time.Now().Add(s.LinkTTL)
+	if job.ExpiresAt != nil && job.ExpiresAt.Before(expires) {
+		expires = *job.ExpiresAt
+	}
+	link, err := s.Storage.SignedURL(*job.FileKey, job.FileName(), time.Until(expires))
+	if err != nil {
+		return "", nil, err
+	}
+	return link, &expires, nil
+}
+
+// Produce renders the file of a claimed job, stores it and emails the requester the link to download it.
+// A job that cannot be produced is failed with the reason.
+func (s *ExportService) Produce(ctx context.Context, job *database.ExportJob) {
+	if job.Attempts > maxExportAttempts {
+		s.fail(job, fmt.Sprintf("gave up after %d attempts", maxExportAttempts))
+		return
+	}
+
+	data, contentType, rows, err := s.Render(job)
+	if err != nil {
+		s.Logger.Error("failed to render export", "error", err, "job_id", job.ID)
+		s.fail(job, "Failed to read the "+job.Entity)
+		return
+	}
+
+	key := job.OrganizationID.String() + "/" + job.ID.String() + "." + job.Format
+	encryption, err := s.Encryption.GetExportEncryption(job.OrganizationID)
+	if err != nil {
+		s.fail(job, "Failed to read the encryption settings")
+		return
+	}
+	if encryption != nil {
+		if data, err = EncryptFile(encryption, job.FileName(), data); err != nil {
+			s.Logger.Error("failed to encrypt export", "error", err, "job_id", job.ID)
+			s.fail(job, "Failed to encrypt the file")
+			return
+		}
+		key += EncryptedFileExtension
+		contentType = EncryptedContentType
+	}
+	if err := s.Storage.Put(ctx, key, contentType, data); err != nil {
+		s.Logger.Error("failed to store export", "error", err, "job_id", job.ID)
+		s.fail(job, "Failed to store the file")
+		return
+	}
+	expiresAt := time.Now().Add(s.Retention)
+	if err := s.Store.CompleteExportJob(job.ID, key, rows, int64(len(data)), expiresAt); err != nil {
+		return
+	}
+	job.Status, job.FileKey, job.ExpiresAt = database.ExportJobCompleted, &key, &expiresAt
+	s.Logger.Info("export completed", "job_id", job.ID, "org_id", job.OrganizationID, "entity", job.Entity, "rows", rows, "bytes", len(data), "encrypted", encryption != nil)
+
+	if job.RequesterEmail == "" {
+		return
+	}
+	link, linkExpires, err := s.DownloadURL(job)
+	if err != nil {
+		s.Logger.Error("failed to sign export link", "error", err, "job_id", job.ID)
+		return
+	}
+	if err := s.EmailService.SendExportReadyEmail(job.RequesterEmail, job.RequesterName, job.FileName(), link, linkExpires.Format("2006-01-02 15:04 MST")); err != nil {
+		s.Logger.Error("failed to send export ready email", "error", err, "job_id", job.ID)
+	}
+}
+
+func (s *ExportService) fail(job *database.ExportJob, message string) {
+	if err := s.Store.FailExportJob(job.ID, message); err == nil {
+		s.Logger.Warn("export failed", "job_id", job.ID, "reason", message)
+	}
+}
+
+// Render returns the file of a job, its content type and the number of rows it holds
+func (s *ExportService) Render(job *database.ExportJob) ([]byte, string, int, error) {
+	from := time.Date(job.From.Year(), job.From.Month(), job.From.Day(), 0, 0, 0, 0, time.Local)
+	to := time.Date(job.To.Year(), job.To.Month(), job.To.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+
+	var headers []string
+	var rows [][]string
+	switch job.Entity {
+	case database.ExportEntityOrders:
+		orders, err := s.OrderStore.GetOrders(job.OrganizationID, database.OrderFilter{From: &from, To: &to})
+		if err != nil {
+			return nil, "", 0, err
+		}
+		headers = []string{"order_id", "user_id", "create_time", "order_type", "order_status", "channel", "total_amount", "discount_amount", "rating", "item_count"}
+		rows = make([][]string, 0, len(orders))
+		for _, o := range orders {
+			rows = append(rows, []string{
+				o.OrderID.String(),
+				o.UserID.String(),
+				o.CreateTime.Format(time.RFC3339),
+				o.OrderType,
+				o.OrderStatus,
+				o.Channel,
+				exportFloat(o.TotalAmount),
+				exportFloat(o.DiscountAmount),
+				exportFloat(o.Rating),
+				strconv.Itoa(o.OrderCount),
+			})
+		}
+	case database.ExportEntityDeliveries:
+		deliveries, err := s.OrderStore.GetDeliveries(job.OrganizationID, database.DeliveryFilter{From: &from, To: &to})
+		if err != nil {
+			return nil, "", 0, err
+		}
+		headers = []string{"order_id", "driver_id", "status", "out_for_delivery_time", "delivered_time", "latitude", "longitude"}
+		rows = make([][]string, 0, len(deliveries))
+		for _, d := range deliveries {
+			delivered := ""
+			if !d.DeliveredTime.IsZero() {
+				delivered = d.DeliveredTime.Format(time.RFC3339)
+			}
+			rows = append(rows, []string{
+				d.OrderID.String(),
+				d.DriverID.String(),
+				d.DeliveryStatus,
+				d.OutForDeliveryTime.Format(time.RFC3339),
+				delivered,
+				exportFloat(d.DeliveryLocation.Latitude),
+				exportFloat(d.DeliveryLocation.Longitude),
+			})
+		}
+	default:
+		return nil, "", 0, fmt.Errorf("unknown export entity %q", job.Entity)
+	}
+
+	if job.Format == database.ExportFormatXLSX {
+		workbook, err := RenderTableXLSX(job.Entity, headers, rows)
+		if err != nil {
+			return nil, "", 0, err
+		}
+		return workbook, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", len(rows), nil
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write(headers)
+	writer.WriteAll(rows)
+	if err := writer.Error(); err != nil {
+		return nil, "", 0, err
+	}
+	return buf.Bytes(), "text/csv; charset=utf-8", len(rows), nil
+}
+
+func exportFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', 2, 64)
+}
+
+// Purge deletes the files of the jobs expired at now
+func (s *ExportService) Purge(ctx context.Context, now time.Time) {
+	jobs, err := s.Store.GetExpiredExportJobs(now)
+	if err != nil {
+		return
+	}
+	for _, job := range jobs {
+		if job.FileKey != nil {
+			if err := s.Storage.Delete(ctx, *job.FileKey); err != nil {
+				s.Logger.Error("failed to delete expired export", "error", err, "job_id", job.ID)
+				continue
+			}
+		}
+		s.Store.MarkExportJobExpired(job.ID)
+	}
+}
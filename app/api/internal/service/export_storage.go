@@ -0,0 +1,303 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidDownloadToken = errors.New("invalid or expired download token")
+
+// Longest validity of a presigned S3 URL
+const maxS3PresignValidity = 7 * 24 * time.Hour
+
+// ExportStorage keeps the files of export jobs and hands out time-limited links to download them
+type ExportStorage interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// SignedURL returns a link downloading the file as fileName until validFor has passed
+	SignedURL(key, fileName string, validFor time.Duration) (string, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewExportStorage returns the storage selected by EXPORT_STORAGE: "s3" for the bucket EXPORT_S3_BUCKET,
+// or files under EXPORT_DIR served by the API with signed links otherwise
+func NewExportStorage(logger *slog.Logger) ExportStorage {
+	if strings.ToLower(os.Getenv("EXPORT_STORAGE")) == "s3" {
+		storage, err := NewS3ExportStorageFromEnv()
+		if err == nil {
+			return storage
+		}
+		logger.Error("failed to configure s3 export storage, exports are kept on disk", "error", err)
+	}
+
+	dir := os.Getenv("EXPORT_DIR")
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "clockwise-exports")
+	}
+	return NewLocalExportStorage(dir)
+}
+
+// LocalExportStorage keeps exports on the disk of the API. Its links point to the download endpoint of the
+// API with a token signed like the unsubscribe links, carrying the key, file name and expiry.
+type LocalExportStorage struct {
+	Dir    string
+	secret []byte
+	// BaseURL is the public URL of the download endpoint the token is appended to
+	BaseURL string
+}
+
+// NewLocalExportStorage signs with EXPORT_SECRET, or JWT_SECRET when it is not set, and links to the
+// PublicAPIURL
+func NewLocalExportStorage(dir string) *LocalExportStorage {
+	secret := os.Getenv("EXPORT_SECRET")
+	if secret == "" {
+		secret = os.Getenv("JWT_SECRET")
+	}
+	return &LocalExportStorage{Dir: dir, secret: []byte(secret), BaseURL: PublicAPIURL() + "/api/exports/download"}
+}
+
+// path keeps keys inside Dir
+func (l *LocalExportStorage) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" {
+		return "", fmt.Errorf("invalid export key %q", key)
+	}
+	return filepath.Join(l.Dir, clean), nil
+}
+
+func (l *LocalExportStorage) Put(ctx context.Context, key, contentType string, data []byte) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func (l *LocalExportStorage) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (l *LocalExportStorage) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+func (l *LocalExportStorage) SignedURL(key, fileName string, validFor time.Duration) (string, error) {
+	payload := key + "\n" + fileName + "\n" + strconv.FormatInt(time.Now().Add(validFor).Unix(), 10)
+	token := base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + base64.RawURLEncoding.EncodeToString(l.sign(payload))
+	return l.BaseURL + "?token=" + url.QueryEscape(token), nil
+}
+
+// Open checks the signature and expiry of a download token and returns the path of the file and the
+// name it is downloaded as
+func (l *LocalExportStorage) Open(token string, now time.Time) (string, string, error) {
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", "", ErrInvalidDownloadToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return "", "", ErrInvalidDownloadToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, l.sign(string(payload))) {
+		return "", "", ErrInvalidDownloadToken
+	}
+
+	parts := strings.Split(string(payload), "\n")
+	if len(parts) != 3 {
+		return "", "", ErrInvalidDownloadToken
+	}
+	expires, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || now.Unix() > expires {
+		return "", "", ErrInvalidDownloadToken
+	}
+	path, err := l.path(parts[0])
+	if err != nil {
+		return "", "", ErrInvalidDownloadToken
+	}
+	return path, parts[1], nil
+}
+
+// S3ExportStorage keeps exports in an S3 bucket, or a bucket of a compatible store at Endpoint. Requests
+// and download links are signed with Signature Version 4.
+type S3ExportStorage struct {
+	Bucket          string
+	Region          string
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Client          *http.Client
+}
+
+// NewS3ExportStorageFromEnv configures the bucket EXPORT_S3_BUCKET from AWS_REGION and the standard
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN variables. EXPORT_S3_ENDPOINT addresses
+// a compatible store, whose buckets are reached by path.
+func NewS3ExportStorageFromEnv() (*S3ExportStorage, error) {
+	storage := &S3ExportStorage{
+		Bucket:          os.Getenv("EXPORT_S3_BUCKET"),
+		Region:          os.Getenv("AWS_REGION"),
+		Endpoint:        strings.TrimSuffix(os.Getenv("EXPORT_S3_ENDPOINT"), "/"),
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		Client:          &http.Client{Timeout: 5 * time.Minute},
+	}
+	if storage.Bucket == "" || storage.Region == "" {
+		return nil, errors.New("EXPORT_S3_BUCKET and AWS_REGION are required for the s3 export storage")
+	}
+	if storage.AccessKeyID == "" || storage.SecretAccessKey == "" {
+		return nil, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required for the s3 export storage")
+	}
+	return storage, nil
+}
+
+// objectURL is the URL of the object at key, in the bucket's virtual host on AWS and by path elsewhere
+func (s *S3ExportStorage) objectURL(key string) *url.URL {
+	segments := strings.Split(strings.TrimPrefix(key, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	path := "/" + strings.Join(segments, "/")
+	if s.Endpoint != "" {
+		u, _ := url.Parse(s.Endpoint + "/" + url.PathEscape(s.Bucket) + path)
+		return u
+	}
+	u, _ := url.Parse(fmt.Sprintf("https://%s.s3.%s.amazonaws.com%s", s.Bucket, s.Region, path))
+	return u
+}
+
+func (s *S3ExportStorage) Put(ctx context.Context, key, contentType string, data []byte) error {
+	return s.do(ctx, http.MethodPut, key, contentType, data)
+}
+
+func (s *S3ExportStorage) Delete(ctx context.Context, key string) error {
+	return s.do(ctx, http.MethodDelete, key, "", nil)
+}
+
+func (s *S3ExportStorage) do(ctx context.Context, method, key, contentType string, data []byte) error {
+	target := s.objectURL(key)
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	now := time.Now().UTC()
+	payloadHash := s3HashHex(data)
+	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(req.Header.Get(name))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	scope, signature := s.signature(now, method, target.EscapedPath(), "", canonicalHeaders.String(), signedHeaders, payloadHash)
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKeyID, scope, signedHeaders, signature))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach s3: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 && !(method == http.MethodDelete && resp.StatusCode == http.StatusNotFound) {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("s3 %s %s returned status %d: %s", method, key, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// SignedURL presigns a GET of the object, answered as an attachment named fileName. S3 caps the validity
+// of presigned URLs at seven days.
+func (s *S3ExportStorage) SignedURL(key, fileName string, validFor time.Duration) (string, error) {
+	validFor = min(validFor, maxS3PresignValidity)
+	now := time.Now().UTC()
+	target := s.objectURL(key)
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s.AccessKeyID+"/"+s.scope(now))
+	query.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(validFor.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+	if s.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+	query.Set("response-content-disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	canonical := strings.ReplaceAll(query.Encode(), "+", "%20")
+
+	_, signature := s.signature(now, http.MethodGet, target.EscapedPath(), canonical, "host:"+target.Host+"\n", "host", "UNSIGNED-PAYLOAD")
+	target.RawQuery = canonical + "&X-Amz-Signature=" + signature
+	return target.String(), nil
+}
+
+func (s *S3ExportStorage) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.Region + "/s3/aws4_request"
+}
+
+// signature signs a canonical request for the s3 service and returns its credential scope and signature
+func (s *S3ExportStorage) signature(now time.Time, method, path, query, canonicalHeaders, signedHeaders, payloadHash string) (string, string) {
+	canonicalRequest := strings.Join([]string{method, path, query, canonicalHeaders, signedHeaders, payloadHash}, "\n")
+	scope := s.scope(now)
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", now.Format("20060102T150405Z"), scope, s3HashHex([]byte(canonicalRequest))}, "\n")
+
+	key := s3HMAC([]byte("AWS4"+s.SecretAccessKey), now.Format("20060102"))
+	key = s3HMAC(key, s.Region)
+	key = s3HMAC(key, "s3")
+	key = s3HMAC(key, "aws4_request")
+	return scope, hex.EncodeToString(s3HMAC(key, stringToSign))
+}
+
+func s3HashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func s3HMAC(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
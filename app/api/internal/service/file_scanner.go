@@ -0,0 +1,183 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// eicarSignature is the standard antivirus test file, always rejected even without a scanner daemon
+const eicarSignature = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// clamAVChunkSize is the size of the chunks streamed to clamd
+const clamAVChunkSize = 64 << 10
+
+var ErrScannerUnavailable = errors.New("file scanner unavailable")
+
+// ScanResult is the verdict of a scanner on a file
+type ScanResult struct {
+	Clean     bool   `json:"clean"`
+	Signature string `json:"signature,omitempty"`
+	Scanner   string `json:"scanner"`
+}
+
+// FileScanner checks uploaded files for malware before they are processed
+type FileScanner interface {
+	Scan(ctx context.Context, file multipart.File) (*ScanResult, error)
+}
+
+// NewFileScanner returns the scanner selected by FILE_SCANNER: "clamav" for a clamd daemon at
+// CLAMAV_ADDRESS, "http" for an external API at FILE_SCANNER_URL, or the built-in signature check otherwise
+func NewFileScanner(logger *slog.Logger) FileScanner {
+	timeout := durationFromEnv("FILE_SCANNER_TIMEOUT", 30*time.Second, logger)
+
+	switch strings.ToLower(os.Getenv("FILE_SCANNER")) {
+	case "clamav":
+		address := os.Getenv("CLAMAV_ADDRESS")
+		if address == "" {
+			address = "clamav:3310"
+		}
+		return &ClamAVScanner{Address: address, Timeout: timeout, Logger: logger}
+	case "http":
+		return &HTTPFileScanner{
+			URL:    os.Getenv("FILE_SCANNER_URL"),
+			APIKey: os.Getenv("FILE_SCANNER_API_KEY"),
+			Client: &http.Client{Timeout: timeout},
+			Logger: logger,
+		}
+	default:
+		logger.Warn("no file scanner configured, uploads are only checked for the EICAR test signature")
+		return &SignatureScanner{}
+	}
+}
+
+// SignatureScanner only detects the EICAR test file
+type SignatureScanner struct{}
+
+func (s *SignatureScanner) Scan(ctx context.Context, file multipart.File) (*ScanResult, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Contains(data, []byte(eicarSignature)) {
+		return &ScanResult{Clean: false, Signature: "Eicar-Test-Signature", Scanner: "signature"}, nil
+	}
+	return &ScanResult{Clean: true, Scanner: "signature"}, nil
+}
+
+// ClamAVScanner streams files to a clamd daemon with the INSTREAM command
+type ClamAVScanner struct {
+	Address string
+	Timeout time.Duration
+	Logger  *slog.Logger
+}
+
+func (s *ClamAVScanner) Scan(ctx context.Context, file multipart.File) (*ScanResult, error) {
+	dialer := net.Dialer{Timeout: s.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.Address)
+	if err != nil {
+		s.Logger.Error("failed to connect to clamav", "error", err, "address", s.Address)
+		return nil, ErrScannerUnavailable
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(s.Timeout))
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, fmt.Errorf("failed to start clamav stream: %w", err)
+	}
+
+	buf := make([]byte, clamAVChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, err := file.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(append(size, buf[:n]...)); err != nil {
+				return nil, fmt.Errorf("failed to stream file to clamav: %w", err)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return nil, fmt.Errorf("failed to end clamav stream: %w", err)
+	}
+
+	reply, err := io.ReadAll(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read clamav reply: %w", err)
+	}
+	return parseClamAVReply(string(reply))
+}
+
+// parseClamAVReply reads replies such as "stream: OK" and "stream: Eicar-Test-Signature FOUND"
+func parseClamAVReply(reply string) (*ScanResult, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	reply = strings.TrimPrefix(reply, "stream: ")
+
+	switch {
+	case reply == "OK":
+		return &ScanResult{Clean: true, Scanner: "clamav"}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return &ScanResult{Clean: false, Signature: strings.TrimSuffix(reply, " FOUND"), Scanner: "clamav"}, nil
+	default:
+		return nil, fmt.Errorf("unexpected clamav reply: %s", reply)
+	}
+}
+
+// HTTPFileScanner posts files to an external scanning API that answers with a ScanResult
+type HTTPFileScanner struct {
+	URL    string
+	APIKey string
+	Client *http.Client
+	Logger *slog.Logger
+}
+
+func (s *HTTPFileScanner) Scan(ctx context.Context, file multipart.File) (*ScanResult, error) {
+	if s.URL == "" {
+		return nil, ErrScannerUnavailable
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, file)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if s.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.APIKey)
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		s.Logger.Error("failed to reach file scanner", "error", err, "url", s.URL)
+		return nil, ErrScannerUnavailable
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		s.Logger.Error("file scanner returned an error", "status", resp.StatusCode)
+		return nil, ErrScannerUnavailable
+	}
+
+	var result ScanResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode file scanner response: %w", err)
+	}
+	result.Scanner = "http"
+	return &result, nil
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultForecastVarianceInterval  = 15 * time.Minute
+	defaultForecastVarianceThreshold = 25
+	defaultForecastVarianceMinOrders = 10
+	defaultForecastVarianceCooldown  = 2 * time.Hour
+)
+
+// ForecastVarianceService compares the orders of the day so far with the demand forecast of the elapsed
+// hours, and tells the managers on duty to call in or send home staff when they drift apart
+type ForecastVarianceService struct {
+	Store        database.ForecastVarianceStore
+	OrgStore     database.OrgStore
+	EmailService EmailService
+	Logger       *slog.Logger
+
+	// Interval is how often the orders are compared with the forecast
+	Interval time.Duration
+	// Threshold is the variance, in percent of the forecast, that raises an alert
+	Threshold int
+	// MinOrders is the forecast needed so far before the variance is trusted, so a quiet morning with 2
+	// orders instead of 1 does not raise an alert
+	MinOrders int
+	// Cooldown is how long after an alert the same drift is not alerted again
+	Cooldown time.Duration
+}
+
+// NewForecastVarianceService reads FORECAST_VARIANCE_INTERVAL and FORECAST_VARIANCE_COOLDOWN (Go durations,
+// e.g. "30m"), FORECAST_VARIANCE_THRESHOLD (percent) and FORECAST_VARIANCE_MIN_ORDERS, and falls back to
+// checks every 15 minutes, a 25% threshold, 10 forecast orders and a 2 hour cooldown
+func NewForecastVarianceService(store database.ForecastVarianceStore, orgStore database.OrgStore, emailService EmailService, Logger *slog.Logger) *ForecastVarianceService {
+	return &ForecastVarianceService{
+		Store:        store,
+		OrgStore:     orgStore,
+		EmailService: emailService,
+		Logger:       Logger,
+		Interval:     durationFromEnv("FORECAST_VARIANCE_INTERVAL", defaultForecastVarianceInterval, Logger),
+		Threshold:    intFromEnv("FORECAST_VARIANCE_THRESHOLD", defaultForecastVarianceThreshold, Logger),
+		MinOrders:    intFromEnv("FORECAST_VARIANCE_MIN_ORDERS", defaultForecastVarianceMinOrders, Logger),
+		Cooldown:     durationFromEnv("FORECAST_VARIANCE_COOLDOWN", defaultForecastVarianceCooldown, Logger),
+	}
+}
+
+// Start compares the orders with the forecast every Interval until the context is cancelled
+func (s *ForecastVarianceService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.Logger.Info("forecast variance service started", "interval", s.Interval, "threshold", s.Threshold)
+	for {
+		select {
+		case <-ctx.Done():
+			s.Logger.Info("forecast variance service stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.CheckVariance(time.Now()); err != nil {
+				s.Logger.Error("failed to check forecast variance", "error", err)
+			}
+		}
+	}
+}
+
+// CheckVariance alerts the organizations whose orders today drifted from the forecast and returns how many
+// were alerted. Every alert is logged, even when no manager could be emailed.
+func (s *ForecastVarianceService) CheckVariance(now time.Time) (int, error) {
+	throughHour := now.Hour()
+	if throughHour == 0 {
+		return 0, nil
+	}
+
+	orgs, err := s.Store.GetForecastedOrganizations(now)
+	if err != nil {
+		return 0, err
+	}
+
+	alerted := 0
+	for _, orgID := range orgs {
+		progress, err := s.Store.GetDayProgress(orgID, now, throughHour)
+		if err != nil {
+			continue
+		}
+		alert := EvaluateVariance(*progress, s.Threshold, s.MinOrders)
+		if alert == nil {
+			continue
+		}
+
+		latest, err := s.Store.GetLatestAlert(orgID, now, alert.Direction)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if latest != nil && now.Sub(latest.CreatedAt) < s.Cooldown {
+			continue
+		}
+
+		if staff, err := s.Store.GetStaffOnShift(orgID, now); err == nil {
+			alert.StaffOnShift = staff
+			alert.SuggestedStaffChange = suggestedStaffChange(alert.Direction, staff, alert.VariancePercent)
+		}
+		alert.OrganizationID = orgID
+		alert.CreatedAt = now
+
+		emails := s.managersToNotify(orgID, now)
+		summary, suggestion := describeVariance(alert)
+		if err := s.EmailService.SendForecastVarianceEmail(emails, summary, suggestion); err != nil {
+			s.Logger.Error("failed to send forecast variance email", "error", err, "organization_id", orgID)
+		} else {
+			alert.NotifiedManagers = len(emails)
+		}
+
+		if err := s.Store.StoreAlert(alert); err != nil {
+			continue
+		}
+		alerted++
+	}
+
+	if alerted > 0 {
+		s.Logger.Info("forecast variance alerts raised", "organizations", alerted)
+	}
+	return alerted, nil
+}
+
+// managersToNotify returns the admins and managers on shift, or all of them when none is
+func (s *ForecastVarianceService) managersToNotify(orgID uuid.UUID, now time.Time) []string {
+	emails, err := s.Store.GetOnDutyManagerEmails(orgID, now)
+	if err == nil && len(emails) > 0 {
+		return emails
+	}
+	emails, err = managerAndAdminEmails(s.OrgStore, orgID)
+	if err != nil {
+		return nil
+	}
+	return emails
+}
+
+// managerAndAdminEmails lists the emails of every manager and admin of the organization
+func managerAndAdminEmails(orgStore database.OrgStore, orgID uuid.UUID) ([]string, error) {
+	managers, err := orgStore.GetManagerEmailsByOrgID(orgID)
+	if err != nil {
+		return nil, err
+	}
+	admins, err := orgStore.GetAdminEmailsByOrgID(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return append(managers, admins...), nil
+}
+
+// EvaluateVariance returns the alert for the progress of a day, or nil when the orders are within threshold
+// percent of the forecast or less than minOrders were forecast so far
+func EvaluateVariance(progress database.DayProgress, threshold, minOrders int) *database.ForecastVarianceAlert {
+	if progress.ForecastOrders <= 0 || progress.ForecastOrders < minOrders {
+		return nil
+	}
+
+	variance := float64(progress.ActualOrders-progress.ForecastOrders) / float64(progress.ForecastOrders) * 100
+	if math.Abs(variance) <= float64(threshold) {
+		return nil
+	}
+
+	alert := &database.ForecastVarianceAlert{
+		Date:            progress.Date,
+		ThroughHour:     progress.ThroughHour,
+		ForecastOrders:  progress.ForecastOrders,
+		ActualOrders:    progress.ActualOrders,
+		VariancePercent: math.Round(variance*10) / 10,
+		Direction:       database.VarianceAbove,
+		Suggestion:      database.SuggestCallIn,
+	}
+	if variance < 0 {
+		alert.Direction = database.VarianceBelow
+		alert.Suggestion = database.SuggestSendHome
+	}
+	return alert
+}
+
+// suggestedStaffChange scales the staff on shift by the variance. At least one employee is called in when
+// orders run above the forecast, and one always stays on shift when they run below.
+func suggestedStaffChange(direction string, staff int, variancePercent float64) int {
+	change := int(math.Round(float64(staff) * math.Abs(variancePercent) / 100))
+	if direction == database.VarianceAbove {
+		return max(change, 1)
+	}
+	return max(min(change, staff-1), 0)
+}
+
+// describeVariance writes the summary and suggestion lines of the alert email
+func describeVariance(alert *database.ForecastVarianceAlert) (string, string) {
+	summary := fmt.Sprintf("Orders are %.0f%% %s the forecast today: %d orders by %02d:00 against %d forecast.",
+		math.Abs(alert.VariancePercent), alert.Direction, alert.ActualOrders, alert.ThroughHour, alert.ForecastOrders)
+
+	switch {
+	case alert.Suggestion == database.SuggestCallIn:
+		return summary, fmt.Sprintf("Consider calling in %d more staff (%d on shift now).", alert.SuggestedStaffChange, alert.StaffOnShift)
+	case alert.SuggestedStaffChange > 0:
+		return summary, fmt.Sprintf("Consider sending home %d of the %d staff on shift.", alert.SuggestedStaffChange, alert.StaffOnShift)
+	default:
+		return summary, "Consider whether everyone on shift is still needed."
+	}
+}
+
+func intFromEnv(key string, fallback int, Logger *slog.Logger) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		Logger.Warn("invalid number in environment, using default", "key", key, "value", value, "default", fallback)
+		return fallback
+	}
+	return parsed
+}
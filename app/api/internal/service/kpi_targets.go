@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// The KPIs an organization can set targets for
+const (
+	KPIWeeklyRevenue    = "weekly_revenue"
+	KPILaborCostPercent = "labor_cost_percent"
+	KPIAverageRating    = "average_rating"
+)
+
+const (
+	defaultKPIReportInterval = time.Hour
+	defaultKPIReportHour     = 8
+)
+
+var kpiLabels = map[string]string{
+	KPIWeeklyRevenue:    "Revenue",
+	KPILaborCostPercent: "Labor cost %",
+	KPIAverageRating:    "Average rating",
+}
+
+// KPIResult compares a KPI of a period with its target. The weekly revenue target is scaled to the days of
+// the period. Actual, Variance and Met are nil when the KPI cannot be measured: the labor cost without
+// revenue, the rating without rated orders.
+type KPIResult struct {
+	KPI    string   `json:"kpi"`
+	Target float64  `json:"target"`
+	Actual *float64 `json:"actual"`
+	// Variance is Actual minus Target, VariancePercent the same as a percent of Target
+	Variance        *float64 `json:"variance"`
+	VariancePercent *float64 `json:"variance_percent"`
+	// Met is whether the revenue and the rating reached their target and the labor cost stayed under it
+	Met *bool `json:"met"`
+}
+
+// KPIProgress is the KPIs of an organization over a period and how they compare with its targets. Employees
+// without an hourly salary are left out of the labor cost.
+type KPIProgress struct {
+	From                   string      `json:"from"`
+	To                     string      `json:"to"`
+	Revenue                float64     `json:"revenue"`
+	LaborCost              float64     `json:"labor_cost"`
+	LaborCostPercent       *float64    `json:"labor_cost_percent"`
+	AverageRating          *float64    `json:"average_rating"`
+	RatedOrders            int         `json:"rated_orders"`
+	EmployeesWithoutSalary int         `json:"employees_without_salary"`
+	Targets                []KPIResult `json:"targets"`
+}
+
+// LaborCost prices the scheduled hours at the hourly salary of the employees, standby hours at the
+// standby share of it, and counts the scheduled employees without a salary
+func LaborCost(employees []*database.User, hours []database.EmployeeHours) (float64, int) {
+	salaries := make(map[uuid.UUID]*float64, len(employees))
+	for _, employee := range employees {
+		salaries[employee.ID] = employee.SalaryPerHour
+	}
+	cost, withoutSalary := 0.0, 0
+	for _, h := range hours {
+		salary := salaries[h.EmployeeID]
+		if salary == nil {
+			withoutSalary++
+			continue
+		}
+		cost += *salary * (h.WorkingHours + h.StandbyHours*float64(h.StandbyPayPercent)/100)
+	}
+	return roundCents(cost), withoutSalary
+}
+
+// CompareKPIs measures the KPIs of the period [from, to) from its sales and labor cost and compares those
+// with a target with it
+func CompareKPIs(targets *database.KPITargets, from, to time.Time, sales database.KPISales, laborCost float64) KPIProgress {
+	progress := KPIProgress{
+		From:      from.Format(time.DateOnly),
+		To:        to.AddDate(0, 0, -1).Format(time.DateOnly),
+		Revenue:   roundCents(sales.Revenue),
+		LaborCost: laborCost,
+		Targets:   []KPIResult{},
+	}
+	if sales.Revenue > 0 {
+		percent := roundCents(laborCost / sales.Revenue * 100)
+		progress.LaborCostPercent = &percent
+	}
+	if sales.AverageRating != nil {
+		rating := roundCents(*sales.AverageRating)
+		progress.AverageRating = &rating
+	}
+	progress.RatedOrders = sales.RatedOrders
+
+	if targets.WeeklyRevenue != nil {
+		days := to.Sub(from).Hours() / 24
+		revenue := progress.Revenue
+		progress.Targets = append(progress.Targets, compareKPI(KPIWeeklyRevenue, roundCents(*targets.WeeklyRevenue*days/7), &revenue, true))
+	}
+	if targets.LaborCostPercent != nil {
+		progress.Targets = append(progress.Targets, compareKPI(KPILaborCostPercent, *targets.LaborCostPercent, progress.LaborCostPercent, false))
+	}
+	if targets.AverageRating != nil {
+		progress.Targets = append(progress.Targets, compareKPI(KPIAverageRating, *targets.AverageRating, progress.AverageRating, true))
+	}
+	return progress
+}
+
+// compareKPI compares actual with target, higher being better unless stated otherwise
+func compareKPI(kpi string, target float64, actual *float64, higherIsBetter bool) KPIResult {
+	result := KPIResult{KPI: kpi, Target: target}
+	if actual == nil {
+		return result
+	}
+	variance := roundCents(*actual - target)
+	percent := roundCents((*actual - target) / target * 100)
+	met := *actual >= target
+	if !higherIsBetter {
+		met = *actual <= target
+	}
+	result.Actual, result.Variance, result.VariancePercent, result.Met = actual, &variance, &percent, &met
+	return result
+}
+
+// KPILabel is the name of a KPI shown to people
+func KPILabel(kpi string) string {
+	if label, ok := kpiLabels[kpi]; ok {
+		return label
+	}
+	return kpi
+}
+
+// FormatKPIResult writes the actual against the target, e.g. "4210.50 against 4500.00 (-6.43%, missed)"
+func FormatKPIResult(result KPIResult) string {
+	if result.Actual == nil {
+		return fmt.Sprintf("not measured against %.2f", result.Target)
+	}
+	status := "missed"
+	if *result.Met {
+		status = "met"
+	}
+	return fmt.Sprintf("%.2f against %.2f (%+.2f%%, %s)", *result.Actual, result.Target, *result.VariancePercent, status)
+}
+
+// KPITracker measures the KPIs of organizations against their targets
+type KPITracker struct {
+	Store         database.KPITargetStore
+	ScheduleStore database.ScheduleStore
+	UserStore     database.UserStore
+}
+
+func NewKPITracker(store database.KPITargetStore, scheduleStore database.ScheduleStore, userStore database.UserStore) *KPITracker {
+	return &KPITracker{
+		Store:         store,
+		ScheduleStore: scheduleStore,
+		UserStore:     userStore,
+	}
+}
+
+// Progress measures the KPIs of the organization over [from, to) against its targets, nil without error
+// when the organization set no targets
+func (t *KPITracker) Progress(orgID uuid.UUID, from, to time.Time) (*KPIProgress, error) {
+	targets, err := t.Store.GetTargets(orgID)
+	if err != nil || targets == nil {
+		return nil, err
+	}
+	sales, err := t.Store.GetSales(orgID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	employees, err := t.UserStore.GetUsersByOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+	hours, err := t.ScheduleStore.GetScheduledHours(orgID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	laborCost, withoutSalary := LaborCost(employees, hours)
+	progress := CompareKPIs(targets, from, to, *sales, laborCost)
+	progress.EmployeesWithoutSalary = withoutSalary
+	return &progress, nil
+}
+
+// KPIReportService emails the admins of the organizations with KPI targets how the past week did against
+// them on Monday morning. Each organization gets one report per week.
+type KPIReportService struct {
+	Tracker      *KPITracker
+	OrgStore     database.OrgStore
+	EmailService EmailService
+	Logger       *slog.Logger
+
+	// Interval is how often it is checked whether the reports are due
+	Interval time.Duration
+	// SendHour is the hour of Monday from which the reports are sent
+	SendHour int
+}
+
+// NewKPIReportService reads KPI_REPORT_INTERVAL (a Go duration) and KPI_REPORT_HOUR (0-23) and falls back
+// to hourly checks from 08:00
+func NewKPIReportService(tracker *KPITracker, orgStore database.OrgStore, emailService EmailService, Logger *slog.Logger) *KPIReportService {
+	sendHour := defaultKPIReportHour
+	if value := os.Getenv("KPI_REPORT_HOUR"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed >= 0 && parsed < 24 {
+			sendHour = parsed
+		} else {
+			Logger.Warn("invalid hour in environment, using default", "key", "KPI_REPORT_HOUR", "value", value, "default", sendHour)
+		}
+	}
+
+	return &KPIReportService{
+		Tracker:      tracker,
+		OrgStore:     orgStore,
+		EmailService: emailService,
+		Logger:       Logger,
+		Interval:     durationFromEnv("KPI_REPORT_INTERVAL", defaultKPIReportInterval, Logger),
+		SendHour:     sendHour,
+	}
+}
+
+// Start checks every Interval whether the weekly reports are due until the context is cancelled
+func (s *KPIReportService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.Logger.Info("KPI report service started", "interval", s.Interval, "send_hour", s.SendHour)
+	for {
+		select {
+		case <-ctx.Done():
+			s.Logger.Info("KPI report service stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.SendReports(time.Now()); err != nil {
+				s.Logger.Error("failed to send KPI reports", "error", err)
+			}
+		}
+	}
+}
+
+// SendReports emails the reports of the week that ended yesterday when now is Monday from SendHour, and
+// returns how many organizations were sent one. Organizations whose report failed are retried in the
+// next round.
+func (s *KPIReportService) SendReports(now time.Time) (int, error) {
+	if now.Weekday() != time.Monday || now.Hour() < s.SendHour {
+		return 0, nil
+	}
+	weekStart := time.Date(now.Year(), now.Month(), now.Day()-7, 0, 0, 0, 0, now.Location())
+	weekEnd := weekStart.AddDate(0, 0, 7)
+
+	orgs, err := s.Tracker.Store.GetOrganizationsForKPIReport(weekStart)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	weekOf := weekStart.Format("Jan 2, 2006")
+	for _, orgID := range orgs {
+		progress, err := s.Tracker.Progress(orgID, weekStart, weekEnd)
+		if err != nil {
+			s.Logger.Error("failed to measure KPIs for report", "error", err, "org_id", orgID)
+			continue
+		}
+		if progress == nil {
+			continue
+		}
+		admins, err := s.OrgStore.GetAdminEmailsByOrgID(orgID)
+		if err != nil {
+			s.Logger.Error("failed to get admin emails", "error", err, "org_id", orgID)
+			continue
+		}
+
+		if err := s.EmailService.SendKPIReportEmail(admins, weekOf, summarizeKPIs(progress)); err != nil {
+			s.Logger.Error("failed to send KPI report email", "error", err, "org_id", orgID)
+			continue
+		}
+		if err := s.Tracker.Store.MarkKPIReportSent(orgID, weekStart); err != nil {
+			s.Logger.Error("failed to record KPI report", "error", err, "org_id", orgID)
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		s.Logger.Info("KPI reports sent", "organizations", sent, "week_start", weekStart.Format(time.DateOnly))
+	}
+	return sent, nil
+}
+
+// summarizeKPIs returns a line per KPI with a target
+func summarizeKPIs(progress *KPIProgress) []string {
+	lines := make([]string, 0, len(progress.Targets))
+	for _, result := range progress.Targets {
+		lines = append(lines, KPILabel(result.KPI)+": "+FormatKPIResult(result))
+	}
+	return lines
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+const defaultNotificationDigestInterval = 10 * time.Minute
+
+// NotificationDigestService periodically emails the notifications queued for users who receive hourly or
+// daily digests instead of an email per notification
+type NotificationDigestService struct {
+	NotificationStore database.NotificationStore
+	EmailService      EmailService
+	Logger            *slog.Logger
+
+	// Interval is how often due digests are checked, which bounds how late a digest can be
+	Interval time.Duration
+}
+
+// NewNotificationDigestService reads NOTIFICATION_DIGEST_INTERVAL (a Go duration, e.g. "5m") and falls
+// back to checks every 10 minutes
+func NewNotificationDigestService(notificationStore database.NotificationStore, emailService EmailService, Logger *slog.Logger) *NotificationDigestService {
+	return &NotificationDigestService{
+		NotificationStore: notificationStore,
+		EmailService:      emailService,
+		Logger:            Logger,
+		Interval:          durationFromEnv("NOTIFICATION_DIGEST_INTERVAL", defaultNotificationDigestInterval, Logger),
+	}
+}
+
+// Start sends the due digests every Interval until the context is cancelled
+func (s *NotificationDigestService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.Logger.Info("notification digest service started", "interval", s.Interval)
+	for {
+		select {
+		case <-ctx.Done():
+			s.Logger.Info("notification digest service stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.SendDigests(time.Now()); err != nil {
+				s.Logger.Error("failed to send notification digests", "error", err)
+			}
+		}
+	}
+}
+
+// SendDigests emails every due digest and returns how many were sent. Notifications of a digest that
+// could not be sent stay queued for the next round.
+func (s *NotificationDigestService) SendDigests(now time.Time) (int, error) {
+	digests, err := s.NotificationStore.GetDueDigests(now)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, digest := range digests {
+		lines := make([]string, len(digest.Notifications))
+		ids := make([]uuid.UUID, len(digest.Notifications))
+		for i, notification := range digest.Notifications {
+			lines[i] = notification.Summary
+			if notification.Occurrences > 1 {
+				lines[i] = fmt.Sprintf("%s (%d times)", notification.Summary, notification.Occurrences)
+			}
+			ids[i] = notification.ID
+		}
+
+		if err := s.EmailService.SendNotificationDigestEmail(digest.Email, digest.FullName, lines); err != nil {
+			s.Logger.Error("failed to send notification digest email", "error", err, "user_id", digest.UserID)
+			continue
+		}
+		if err := s.NotificationStore.MarkDigestSent(digest.UserID, ids, now); err != nil {
+			s.Logger.Error("failed to record notification digest", "error", err, "user_id", digest.UserID)
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		s.Logger.Info("notification digests sent", "users", sent)
+	}
+	return sent, nil
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+)
+
+const defaultOrderAutoCloseInterval = 5 * time.Minute
+
+// OrderAcceptanceService closes order acceptance of the organizations with auto-close on when the demand
+// of the hour runs past what the staff on shift can handle, and reopens it once the load drops. Every
+// change is logged with the load it was made on and emailed to the managers.
+type OrderAcceptanceService struct {
+	Store        database.OrderAcceptanceStore
+	OrgStore     database.OrgStore
+	EmailService EmailService
+	Logger       *slog.Logger
+
+	// Interval is how often the load is checked
+	Interval time.Duration
+}
+
+// NewOrderAcceptanceService reads ORDER_AUTO_CLOSE_INTERVAL (a Go duration, e.g. "2m") and falls back to
+// checks every 5 minutes
+func NewOrderAcceptanceService(store database.OrderAcceptanceStore, orgStore database.OrgStore, emailService EmailService, Logger *slog.Logger) *OrderAcceptanceService {
+	return &OrderAcceptanceService{
+		Store:        store,
+		OrgStore:     orgStore,
+		EmailService: emailService,
+		Logger:       Logger,
+		Interval:     durationFromEnv("ORDER_AUTO_CLOSE_INTERVAL", defaultOrderAutoCloseInterval, Logger),
+	}
+}
+
+// Start checks the load every Interval until the context is cancelled
+func (s *OrderAcceptanceService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.Logger.Info("order auto-close service started", "interval", s.Interval)
+	for {
+		select {
+		case <-ctx.Done():
+			s.Logger.Info("order auto-close service stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.ControlOrders(time.Now()); err != nil {
+				s.Logger.Error("failed to control order acceptance", "error", err)
+			}
+		}
+	}
+}
+
+// ControlOrders closes or reopens order acceptance where the load calls for it and returns how many
+// organizations were changed
+func (s *OrderAcceptanceService) ControlOrders(now time.Time) (int, error) {
+	orgs, err := s.Store.GetAutoCloseOrganizations()
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, settings := range orgs {
+		load, err := s.Store.GetOrderLoad(settings.OrganizationID, now)
+		if err != nil {
+			continue
+		}
+		change := EvaluateOrderLoad(settings, *load)
+		if change == nil {
+			continue
+		}
+		change.OrganizationID = settings.OrganizationID
+		change.ChangedAt = now
+
+		// A manager changed accepting_orders since the settings were read, theirs wins
+		if err := s.Store.SetAcceptingOrders(change); err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				s.Logger.Error("failed to change order acceptance", "error", err, "organization_id", settings.OrganizationID)
+			}
+			continue
+		}
+		changed++
+
+		emails, err := managerAndAdminEmails(s.OrgStore, settings.OrganizationID)
+		if err != nil {
+			s.Logger.Error("failed to get manager emails", "error", err, "organization_id", settings.OrganizationID)
+			continue
+		}
+		if err := s.EmailService.SendOrderAcceptanceEmail(emails, change.AcceptingOrders, describeOrderLoad(change)); err != nil {
+			s.Logger.Error("failed to send order acceptance email", "error", err, "organization_id", settings.OrganizationID)
+		}
+	}
+
+	if changed > 0 {
+		s.Logger.Info("order acceptance changed automatically", "organizations", changed)
+	}
+	return changed, nil
+}
+
+// EvaluateOrderLoad returns the change the load calls for, or nil when accepting_orders should stay as it
+// is. The demand is the forecast of the hour or the orders of the last hour, whichever is higher, against
+// the staff on shift times orders_per_staff_hour. Orders close when the load runs past
+// auto_close_load_percent, or when orders are expected and nobody is on shift. They only reopen by
+// themselves when they were closed automatically, once the load is at most auto_reopen_load_percent.
+func EvaluateOrderLoad(settings database.OrderAcceptanceSettings, load database.OrderLoad) *database.OrderAcceptanceChange {
+	change := &database.OrderAcceptanceChange{
+		ForecastOrders: load.ForecastOrders,
+		RecentOrders:   load.RecentOrders,
+		StaffOnShift:   load.StaffOnShift,
+		Capacity:       load.StaffOnShift * settings.OrdersPerStaffHour,
+	}
+	demand := max(load.ForecastOrders, load.RecentOrders)
+	switch {
+	case change.Capacity > 0:
+		percent := math.Round(float64(demand)/float64(change.Capacity)*1000) / 10
+		change.LoadPercent = &percent
+	case demand == 0:
+		percent := 0.0
+		change.LoadPercent = &percent
+	}
+
+	if settings.AcceptingOrders {
+		if change.LoadPercent != nil && *change.LoadPercent <= float64(settings.AutoCloseLoadPercent) {
+			return nil
+		}
+		change.AcceptingOrders = false
+		return change
+	}
+
+	if settings.OrdersAutoClosedAt == nil || change.LoadPercent == nil ||
+		*change.LoadPercent > float64(settings.AutoReopenLoadPercent) {
+		return nil
+	}
+	change.AcceptingOrders = true
+	return change
+}
+
+// describeOrderLoad writes the summary line of the order acceptance email
+func describeOrderLoad(change *database.OrderAcceptanceChange) string {
+	action := "Orders were paused"
+	if change.AcceptingOrders {
+		action = "Orders were reopened"
+	}
+	demand := fmt.Sprintf("%d orders expected this hour (%d forecast, %d in the last hour)",
+		max(change.ForecastOrders, change.RecentOrders), change.ForecastOrders, change.RecentOrders)
+	if change.LoadPercent == nil {
+		return fmt.Sprintf("%s: %s and nobody on shift.", action, demand)
+	}
+	return fmt.Sprintf("%s: %s against a capacity of %d with %d staff on shift, a load of %.0f%%.",
+		action, demand, change.Capacity, change.StaffOnShift, *change.LoadPercent)
+}
@@ -0,0 +1,371 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	defaultOrderSheetSyncInterval = 15 * time.Minute
+	// Largest sheet read, in bytes
+	maxSheetBytes = 20 << 20
+	// Scope of the access tokens of service accounts, read only
+	sheetsReadScope = "https://www.googleapis.com/auth/spreadsheets.readonly"
+	// Where service accounts get access tokens. The token_uri of an uploaded key is not used, the signed
+	// assertion is only ever sent to Google.
+	googleTokenURL = "https://oauth2.googleapis.com/token"
+)
+
+var (
+	ErrInvalidSheetURL         = errors.New("not a Google Sheets URL, expected https://docs.google.com/spreadsheets/d/<id>/...")
+	ErrInvalidSheetCredentials = errors.New("credentials must be the JSON key of a Google service account")
+
+	sheetIDPattern  = regexp.MustCompile(`^/spreadsheets/d/([a-zA-Z0-9_-]+)`)
+	sheetGIDPattern = regexp.MustCompile(`gid=([0-9]+)`)
+)
+
+// SheetRef is the spreadsheet and tab a sheet URL points at, GID is empty for the first tab
+type SheetRef struct {
+	SpreadsheetID string
+	GID           string
+}
+
+// ParseSheetURL reads the spreadsheet and tab of a Google Sheets URL as copied from the browser
+func ParseSheetURL(raw string) (SheetRef, error) {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || parsed.Host != "docs.google.com" {
+		return SheetRef{}, ErrInvalidSheetURL
+	}
+	match := sheetIDPattern.FindStringSubmatch(parsed.Path)
+	if match == nil {
+		return SheetRef{}, ErrInvalidSheetURL
+	}
+	ref := SheetRef{SpreadsheetID: match[1]}
+	// the tab is in the fragment of links copied from the address bar, in the query of shared ones
+	if gid := sheetGIDPattern.FindStringSubmatch(parsed.RawQuery + "#" + parsed.Fragment); gid != nil {
+		ref.GID = gid[1]
+	}
+	return ref, nil
+}
+
+// serviceAccountKey is the part of the JSON key of a Google service account used to get access tokens
+type serviceAccountKey struct {
+	Type        string `json:"type"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+}
+
+// ParseSheetCredentials checks that credentials are the JSON key of a service account and returns the
+// email the sheet must be shared with
+func ParseSheetCredentials(credentials string) (string, error) {
+	key, err := parseServiceAccountKey(credentials)
+	if err != nil {
+		return "", err
+	}
+	return key.ClientEmail, nil
+}
+
+func parseServiceAccountKey(credentials string) (*serviceAccountKey, error) {
+	var key serviceAccountKey
+	if err := json.Unmarshal([]byte(credentials), &key); err != nil || key.Type != "service_account" || key.ClientEmail == "" {
+		return nil, ErrInvalidSheetCredentials
+	}
+	if _, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(key.PrivateKey)); err != nil {
+		return nil, ErrInvalidSheetCredentials
+	}
+	return &key, nil
+}
+
+// OrderSheetSyncer periodically imports the new rows of the order logs organizations keep in Google
+// Sheets. Sheets shared by link are read through their CSV export, the others through the Sheets API with
+// the service account key of the sync.
+type OrderSheetSyncer struct {
+	Store  database.OrderSheetStore
+	Client *http.Client
+	Logger *slog.Logger
+
+	// Interval is how often the sheets are synced
+	Interval time.Duration
+	// ExportURL and SheetsURL are where sheets are read from and TokenURL where service accounts get access
+	// tokens, Google unless tests point them elsewhere
+	ExportURL string
+	SheetsURL string
+	TokenURL  string
+	// Apply imports the rows of a sheet read since the last sync. headers is the first row of the sheet and
+	// firstRow the index of the first of rows among the rows below it.
+	Apply func(sheet database.OrderSheetSync, headers []string, rows [][]string, firstRow int) database.OrderSheetRun
+
+	// syncs run one at a time, a sheet synced by hand while the syncer runs is not imported twice at once
+	mu sync.Mutex
+}
+
+// NewOrderSheetSyncer reads ORDER_SHEET_SYNC_INTERVAL (a Go duration, e.g. "1h") and falls back to syncing
+// every 15 minutes. Its client refuses to connect to loopback and private addresses, redirects are followed
+// because CSV exports redirect to googleusercontent.com.
+func NewOrderSheetSyncer(store database.OrderSheetStore, apply func(database.OrderSheetSync, []string, [][]string, int) database.OrderSheetRun, Logger *slog.Logger) *OrderSheetSyncer {
+	return &OrderSheetSyncer{
+		Store:     store,
+		Client:    &http.Client{Timeout: 60 * time.Second, Transport: publicTransport()},
+		Logger:    Logger,
+		Interval:  durationFromEnv("ORDER_SHEET_SYNC_INTERVAL", defaultOrderSheetSyncInterval, Logger),
+		ExportURL: "https://docs.google.com",
+		SheetsURL: "https://sheets.googleapis.com/v4",
+		TokenURL:  googleTokenURL,
+		Apply:     apply,
+	}
+}
+
+// Start syncs every Interval until the context is cancelled
+func (s *OrderSheetSyncer) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.Logger.Info("order sheet syncer started", "interval", s.Interval)
+	for {
+		select {
+		case <-ctx.Done():
+			s.Logger.Info("order sheet syncer stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.SyncAll(ctx); err != nil {
+				s.Logger.Error("failed to sync order sheets", "error", err)
+			}
+		}
+	}
+}
+
+// SyncAll syncs every enabled sheet once and returns how many were synced successfully
+func (s *OrderSheetSyncer) SyncAll(ctx context.Context) (int, error) {
+	sheets, err := s.Store.GetEnabledSyncs()
+	if err != nil {
+		return 0, err
+	}
+
+	synced := 0
+	for _, sheet := range sheets {
+		if run := s.Sync(ctx, sheet); run.Status == database.OrderSheetRunSuccess {
+			synced++
+		}
+	}
+	return synced, nil
+}
+
+// Sync imports the rows added to a sheet since its last sync and logs the result. A sheet with fewer rows
+// than were read, e.g. after rows were deleted, is read again from the top; orders already imported are
+// skipped. A sync that fails is tried again from the same row next time.
+func (s *OrderSheetSyncer) Sync(ctx context.Context, sheet database.OrderSheetSync) database.OrderSheetRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	started := time.Now()
+	run := database.OrderSheetRun{SyncID: sheet.ID, StartedAt: started, Status: database.OrderSheetRunSuccess}
+
+	values, err := s.fetch(ctx, sheet)
+	if err == nil && len(values) == 0 {
+		err = errors.New("the sheet is empty")
+	}
+	if err != nil {
+		s.Logger.Error("failed to read order sheet", "error", err, "organization_id", sheet.OrganizationID, "sync_id", sheet.ID)
+		message := err.Error()
+		run.Status, run.Error = database.OrderSheetRunFailed, &message
+	} else {
+		rows := values[1:]
+		firstRow := sheet.RowsImported
+		if firstRow > len(rows) {
+			s.Logger.Warn("order sheet has fewer rows than were read, reading it again", "sync_id", sheet.ID, "rows", len(rows), "rows_imported", firstRow)
+			firstRow = 0
+		}
+		run = s.Apply(sheet, values[0], rows[firstRow:], firstRow)
+		run.SyncID, run.StartedAt = sheet.ID, started
+		if run.Error != nil {
+			run.Status = database.OrderSheetRunFailed
+		} else {
+			run.Status = database.OrderSheetRunSuccess
+			_ = s.Store.MarkSynced(sheet.ID, len(rows), time.Now())
+		}
+	}
+
+	run.FinishedAt = time.Now()
+	_ = s.Store.RecordRun(&run)
+	s.Logger.Info("order sheet synced", "organization_id", sheet.OrganizationID, "sync_id", sheet.ID, "status", run.Status, "rows", run.TotalRows, "imported", run.SuccessCount)
+	return run
+}
+
+// fetch reads every row of the sheet, the headers first
+func (s *OrderSheetSyncer) fetch(ctx context.Context, sheet database.OrderSheetSync) ([][]string, error) {
+	ref, err := ParseSheetURL(sheet.SheetURL)
+	if err != nil {
+		return nil, err
+	}
+	if sheet.Credentials == nil {
+		return s.fetchExport(ctx, ref)
+	}
+
+	token, err := s.accessToken(ctx, *sheet.Credentials)
+	if err != nil {
+		return nil, err
+	}
+	return s.fetchValues(ctx, ref, token)
+}
+
+// fetchExport downloads the CSV export of a sheet shared by link
+func (s *OrderSheetSyncer) fetchExport(ctx context.Context, ref SheetRef) ([][]string, error) {
+	query := url.Values{"format": {"csv"}}
+	if ref.GID != "" {
+		query.Set("gid", ref.GID)
+	}
+	body, err := s.get(ctx, s.ExportURL+"/spreadsheets/d/"+url.PathEscape(ref.SpreadsheetID)+"/export?"+query.Encode(), "")
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("the sheet export is not valid CSV: %w", err)
+	}
+	return rows, nil
+}
+
+// fetchValues reads a tab with the Sheets API, looking its title up from its ID
+func (s *OrderSheetSyncer) fetchValues(ctx context.Context, ref SheetRef, token string) ([][]string, error) {
+	base := s.SheetsURL + "/spreadsheets/" + url.PathEscape(ref.SpreadsheetID)
+	body, err := s.get(ctx, base+"?fields="+url.QueryEscape("sheets.properties(sheetId,title)"), token)
+	if err != nil {
+		return nil, err
+	}
+	var spreadsheet struct {
+		Sheets []struct {
+			Properties struct {
+				SheetID int    `json:"sheetId"`
+				Title   string `json:"title"`
+			} `json:"properties"`
+		} `json:"sheets"`
+	}
+	if err := json.Unmarshal(body, &spreadsheet); err != nil {
+		return nil, fmt.Errorf("invalid Sheets API response: %w", err)
+	}
+	title := ""
+	for _, tab := range spreadsheet.Sheets {
+		if ref.GID == "" || strconv.Itoa(tab.Properties.SheetID) == ref.GID {
+			title = tab.Properties.Title
+			break
+		}
+	}
+	if title == "" {
+		return nil, errors.New("the tab of the sheet URL was not found")
+	}
+
+	body, err = s.get(ctx, base+"/values/"+url.PathEscape(title)+"?majorDimension=ROWS&valueRenderOption=FORMATTED_VALUE", token)
+	if err != nil {
+		return nil, err
+	}
+	var values struct {
+		Values [][]string `json:"values"`
+	}
+	if err := json.Unmarshal(body, &values); err != nil {
+		return nil, fmt.Errorf("invalid Sheets API response: %w", err)
+	}
+	return values.Values, nil
+}
+
+// accessToken exchanges a JWT signed with the service account key for an access token
+func (s *OrderSheetSyncer) accessToken(ctx context.Context, credentials string) (string, error) {
+	key, err := parseServiceAccountKey(credentials)
+	if err != nil {
+		return "", err
+	}
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(key.PrivateKey))
+	if err != nil {
+		return "", ErrInvalidSheetCredentials
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   key.ClientEmail,
+		"scope": sheetsReadScope,
+		"aud":   googleTokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"}, "assertion": {assertion}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("google refused the service account key with status %d", resp.StatusCode)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&token); err != nil || token.AccessToken == "" {
+		return "", errors.New("invalid access token response")
+	}
+	return token.AccessToken, nil
+}
+
+// get reads a response of Google, with the bearer token when one is given
+func (s *OrderSheetSyncer) get(ctx context.Context, endpoint, token string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("the sheet could not be read (status %d), check that it is shared by link or with the service account", resp.StatusCode)
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return nil, fmt.Errorf("google responded with status %d", resp.StatusCode)
+	}
+	// a sheet that is not shared by link redirects to the login page
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		return nil, errors.New("the sheet is not shared by link, share it or give the key of a service account it is shared with")
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSheetBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxSheetBytes {
+		return nil, fmt.Errorf("the sheet is larger than %d MB", maxSheetBytes>>20)
+	}
+	return body, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+)
+
+const (
+	defaultOrgRatingInterval     = 24 * time.Hour
+	defaultOrgRatingWindowDays   = 90
+	defaultOrgRatingHalfLifeDays = 30
+)
+
+// OrgRatingService recomputes the rating of every organization from the ratings of its recent orders,
+// recent ones weighing more, and keeps the history of the ratings it computed. Organizations without
+// rated orders in the window keep their rating.
+type OrgRatingService struct {
+	Store  database.OrgRatingStore
+	Logger *slog.Logger
+
+	// Interval is how often the ratings are recomputed
+	Interval time.Duration
+	// WindowDays is how far back the order ratings are read
+	WindowDays int
+	// HalfLifeDays is the age at which an order rating weighs half as much as one given today
+	HalfLifeDays int
+}
+
+// NewOrgRatingService reads ORG_RATING_INTERVAL (a Go duration, e.g. "12h"), ORG_RATING_WINDOW_DAYS and
+// ORG_RATING_HALF_LIFE_DAYS, and falls back to a daily recomputation over 90 days with a 30 day half life
+func NewOrgRatingService(store database.OrgRatingStore, Logger *slog.Logger) *OrgRatingService {
+	return &OrgRatingService{
+		Store:        store,
+		Logger:       Logger,
+		Interval:     durationFromEnv("ORG_RATING_INTERVAL", defaultOrgRatingInterval, Logger),
+		WindowDays:   intFromEnv("ORG_RATING_WINDOW_DAYS", defaultOrgRatingWindowDays, Logger),
+		HalfLifeDays: intFromEnv("ORG_RATING_HALF_LIFE_DAYS", defaultOrgRatingHalfLifeDays, Logger),
+	}
+}
+
+// Start recomputes the ratings once, then every Interval until the context is cancelled
+func (s *OrgRatingService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.Logger.Info("organization rating service started", "interval", s.Interval, "window_days", s.WindowDays)
+	if _, err := s.RecomputeRatings(time.Now()); err != nil {
+		s.Logger.Error("failed to recompute organization ratings", "error", err)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			s.Logger.Info("organization rating service stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.RecomputeRatings(time.Now()); err != nil {
+				s.Logger.Error("failed to recompute organization ratings", "error", err)
+			}
+		}
+	}
+}
+
+// RecomputeRatings rates every organization with rated orders in the window and returns how many were
+// rated. An organization that fails is skipped and retried on the next run.
+func (s *OrgRatingService) RecomputeRatings(now time.Time) (int, error) {
+	since := now.AddDate(0, 0, -s.WindowDays)
+	orgs, err := s.Store.GetRatedOrganizations(since)
+	if err != nil {
+		return 0, err
+	}
+
+	rated := 0
+	halfLife := time.Duration(s.HalfLifeDays) * 24 * time.Hour
+	for _, orgID := range orgs {
+		ratings, err := s.Store.GetOrderRatings(orgID, since)
+		if err != nil {
+			continue
+		}
+		rating, ok := WeightedRating(ratings, now, halfLife)
+		if !ok {
+			continue
+		}
+		err = s.Store.StoreRating(&database.OrganizationRating{
+			OrganizationID: orgID,
+			Rating:         rating,
+			RatedOrders:    len(ratings),
+			WindowDays:     s.WindowDays,
+			HalfLifeDays:   s.HalfLifeDays,
+			ComputedAt:     now,
+		})
+		if err != nil {
+			continue
+		}
+		rated++
+	}
+
+	if rated > 0 {
+		s.Logger.Info("organization ratings recomputed", "organizations", rated)
+	}
+	return rated, nil
+}
+
+// WeightedRating averages the order ratings, each weighted 0.5^(age/halfLife) so the rating follows
+// recent service, rounded to two decimals. Returns false without ratings.
+func WeightedRating(ratings []database.OrderRating, now time.Time, halfLife time.Duration) (float64, bool) {
+	var sum, weights float64
+	for _, rating := range ratings {
+		weight := 1.0
+		if age := now.Sub(rating.CreateTime); age > 0 && halfLife > 0 {
+			weight = math.Pow(0.5, float64(age)/float64(halfLife))
+		}
+		sum += rating.Rating * weight
+		weights += weight
+	}
+	if weights == 0 {
+		return 0, false
+	}
+	return math.Round(sum/weights*100) / 100, true
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Layout of RenderTablePDF on A4 paper, in points
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 40
+	pdfFontSize     = 10
+	pdfLineHeight   = 14
+	pdfCharWidth    = 6 // Courier glyphs are 0.6 em wide
+	pdfColumnGap    = 2
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLineHeight
+
+	// Band at the top of branded pages holding the logo and the name of the organization
+	pdfBandHeight     = 50
+	pdfBandLogoHeight = 34
+	pdfBandFontSize   = 14
+)
+
+// PDFBranding puts the brand color, logo and name of an organization in a band at the top of every page
+type PDFBranding struct {
+	Name string
+	// Color of the band, 6 hex digits
+	Color string
+	// Logo is a PNG or JPEG image, left out when empty or unreadable
+	Logo []byte
+}
+
+// RenderTablePDF renders a title and a table as a plain PDF document in a monospaced font, repeating the
+// header on every page. It needs no fonts or external tools, so exports also work in minimal containers.
+// Characters outside Latin-1 are printed as '?'.
+func RenderTablePDF(title string, headers []string, rows [][]string) []byte {
+	return RenderBrandedTablePDF(nil, title, headers, rows)
+}
+
+// RenderBrandedTablePDF renders the table of RenderTablePDF below the branding of an organization, a nil
+// branding gives the plain document
+func RenderBrandedTablePDF(branding *PDFBranding, title string, headers []string, rows [][]string) []byte {
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = utf8.RuneCountInString(header)
+	}
+	for _, row := range rows {
+		for i := 0; i < len(row) && i < len(widths); i++ {
+			widths[i] = max(widths[i], utf8.RuneCountInString(row[i]))
+		}
+	}
+	// Shrink the widest columns until the table fits the page
+	maxChars := (pdfPageWidth - 2*pdfMargin) / pdfCharWidth
+	for totalWidth(widths) > maxChars {
+		widest := 0
+		for i := range widths {
+			if widths[i] > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= 4 {
+			break
+		}
+		widths[widest]--
+	}
+
+	headerLine := formatPDFRow(headers, widths)
+	separator := strings.Repeat("-", min(totalWidth(widths), maxChars))
+
+	linesPerPage := pdfLinesPerPage
+	if branding != nil {
+		linesPerPage = (pdfPageHeight - 2*pdfMargin - pdfBandHeight) / pdfLineHeight
+	}
+
+	var pages [][]string
+	page := []string{title, "", headerLine, separator}
+	for _, row := range rows {
+		if len(page) == linesPerPage {
+			pages = append(pages, page)
+			page = []string{headerLine, separator}
+		}
+		page = append(page, formatPDFRow(row, widths))
+	}
+	pages = append(pages, page)
+
+	return writePDF(pages, branding)
+}
+
+func totalWidth(widths []int) int {
+	total := 0
+	for _, width := range widths {
+		total += width + pdfColumnGap
+	}
+	return total - pdfColumnGap
+}
+
+func formatPDFRow(cells []string, widths []int) string {
+	var line strings.Builder
+	for i, width := range widths {
+		cell := ""
+		if i < len(cells) {
+			cell = cells[i]
+		}
+		if runes := []rune(cell); len(runes) > width {
+			cell = string(runes[:width-1]) + "~"
+		}
+		line.WriteString(cell)
+		if i < len(widths)-1 {
+			line.WriteString(strings.Repeat(" ", width-utf8.RuneCountInString(cell)+pdfColumnGap))
+		}
+	}
+	return line.String()
+}
+
+// escapePDFText makes a line safe inside a PDF string literal in WinAnsi encoding
+func escapePDFText(text string) string {
+	var escaped strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			escaped.WriteByte('\\')
+			escaped.WriteRune(r)
+		case r < 32 || r > 255:
+			escaped.WriteByte('?')
+		case r > 126:
+			fmt.Fprintf(&escaped, "\\%03o", r)
+		default:
+			escaped.WriteRune(r)
+		}
+	}
+	return escaped.String()
+}
+
+// pdfColor converts a hex color to the components of the PDF rg operator, black when it is not a color
+func pdfColor(hex string) string {
+	value, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil || len(strings.TrimPrefix(hex, "#")) != 6 {
+		return "0 0 0"
+	}
+	return fmt.Sprintf("%.3f %.3f %.3f", float64(value>>16&0xff)/255, float64(value>>8&0xff)/255, float64(value&0xff)/255)
+}
+
+// pdfImage decodes a PNG or JPEG logo into a PDF image object, flattened on white. ok is false when the
+// data is not a readable image.
+func pdfImage(data []byte) (object string, width, height int, ok bool) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", 0, 0, false
+	}
+	bounds := img.Bounds()
+	width, height = bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return "", 0, 0, false
+	}
+
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	pixel := make([]byte, 3)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			white := 0xffff - a
+			pixel[0], pixel[1], pixel[2] = byte((r+white)>>8), byte((g+white)>>8), byte((b+white)>>8)
+			writer.Write(pixel)
+		}
+	}
+	writer.Close()
+
+	object = fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode /Length %d >>\nstream\n%s\nendstream",
+		width, height, compressed.Len(), compressed.String())
+	return object, width, height, true
+}
+
+// pdfBand draws the branding band at the top of a page, with the logo image as /Im1 when hasLogo
+func pdfBand(branding *PDFBranding, hasLogo bool, logoWidth, logoHeight int) string {
+	var band strings.Builder
+	bottom := pdfPageHeight - pdfBandHeight
+	fmt.Fprintf(&band, "q\n%s rg\n0 %d %d %d re f\nQ\n", pdfColor(branding.Color), bottom, pdfPageWidth, pdfBandHeight)
+
+	textX := pdfMargin
+	if hasLogo {
+		drawnWidth := logoWidth * pdfBandLogoHeight / logoHeight
+		fmt.Fprintf(&band, "q\n%d 0 0 %d %d %d cm\n/Im1 Do\nQ\n", drawnWidth, pdfBandLogoHeight, pdfMargin, bottom+(pdfBandHeight-pdfBandLogoHeight)/2)
+		textX += drawnWidth + 12
+	}
+	fmt.Fprintf(&band, "q\n1 1 1 rg\nBT\n/F1 %d Tf\n%d %d Td\n(%s) Tj\nET\nQ\n", pdfBandFontSize, textX, bottom+(pdfBandHeight-pdfBandFontSize)/2+3, escapePDFText(branding.Name))
+	return band.String()
+}
+
+// writePDF lays out pages of text lines below the optional branding band. Objects: 1 catalog, 2 page tree,
+// 3 font, 4 the logo of a branded document that has one, then a page and its content per page.
+func writePDF(pages [][]string, branding *PDFBranding) []byte {
+	var buf bytes.Buffer
+	offsets := []int{}
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	var logo string
+	var logoWidth, logoHeight int
+	hasLogo := false
+	if branding != nil && len(branding.Logo) > 0 {
+		logo, logoWidth, logoHeight, hasLogo = pdfImage(branding.Logo)
+	}
+	firstPage := 4
+	resources := "<< /Font << /F1 3 0 R >> >>"
+	if hasLogo {
+		firstPage = 5
+		resources = "<< /Font << /F1 3 0 R >> /XObject << /Im1 4 0 R >> >>"
+	}
+	top := pdfPageHeight - pdfMargin
+	if branding != nil {
+		top -= pdfBandHeight
+	}
+
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	if hasLogo {
+		object(logo)
+	}
+
+	for i, lines := range pages {
+		var content strings.Builder
+		if branding != nil {
+			content.WriteString(pdfBand(branding, hasLogo, logoWidth, logoHeight))
+		}
+		fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", pdfFontSize, pdfLineHeight, pdfMargin, top)
+		for _, line := range lines {
+			fmt.Fprintf(&content, "(%s) Tj T*\n", escapePDFText(line))
+		}
+		fmt.Fprintf(&content, "(Page %d of %d) Tj\nET", i+1, len(pages))
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources %s /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, resources, firstPage+1+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"math"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// Kinds of schedule change owing predictability pay
+const (
+	PredictabilityAdded     = "added"     // a shift scheduled by hand
+	PredictabilityChanged   = "changed"   // moved or lengthened, no hours lost
+	PredictabilityReduced   = "reduced"   // shortened
+	PredictabilityCancelled = "cancelled" // removed from the schedule
+)
+
+// PredictabilityPolicy is the predictable scheduling law of an organization: changes made less than
+// NoticeDays before a shift owe PayHours of pay when no hours are lost, and LostHoursPercent of the hours
+// lost otherwise
+type PredictabilityPolicy struct {
+	NoticeDays       int     `json:"notice_days"`
+	PayHours         float64 `json:"pay_hours"`
+	LostHoursPercent int     `json:"lost_hours_percent"`
+}
+
+// PredictabilityPolicyFromRules returns the policy set in the rules, false when the organization is not
+// subject to predictable scheduling
+func PredictabilityPolicyFromRules(rules *database.OrganizationRules) (PredictabilityPolicy, bool) {
+	if rules == nil || rules.PredictabilityNoticeDays == nil {
+		return PredictabilityPolicy{}, false
+	}
+	return PredictabilityPolicy{
+		NoticeDays:       *rules.PredictabilityNoticeDays,
+		PayHours:         rules.PredictabilityPayHours,
+		LostHoursPercent: rules.PredictabilityLostHoursPercent,
+	}, true
+}
+
+// PredictabilityPayChange is a schedule change made within the notice period and the pay it owes. OwedPay
+// is nil when the employee has no hourly salary.
+type PredictabilityPayChange struct {
+	database.ScheduleChange
+	Change    string   `json:"change"`
+	HoursLost float64  `json:"hours_lost"`
+	OwedHours float64  `json:"owed_hours"`
+	OwedPay   *float64 `json:"owed_pay"`
+}
+
+// PredictabilityPayEmployee is the predictability pay owed to an employee
+type PredictabilityPayEmployee struct {
+	EmployeeID   uuid.UUID `json:"employee_id"`
+	EmployeeName string    `json:"employee_name"`
+	Changes      int       `json:"changes"`
+	OwedHours    float64   `json:"owed_hours"`
+	OwedPay      *float64  `json:"owed_pay"`
+}
+
+// PredictabilityPayReport is the predictability pay owed for the changes to the shifts dated From to To.
+// OwedPay leaves out the employees without an hourly salary, counted in WithoutSalary.
+type PredictabilityPayReport struct {
+	From          time.Time                   `json:"from"`
+	To            time.Time                   `json:"to"`
+	Policy        PredictabilityPolicy        `json:"policy"`
+	Changes       []PredictabilityPayChange   `json:"changes"`
+	Employees     []PredictabilityPayEmployee `json:"employees"`
+	OwedHours     float64                     `json:"owed_hours"`
+	OwedPay       float64                     `json:"owed_pay"`
+	WithoutSalary int                         `json:"employees_without_salary"`
+}
+
+// classifyScheduleChange returns the kind of a change and the hours it cut, false for changes that owe
+// nothing: shifts traded between employees, and edits that keep the times of the shift such as an
+// activated standby shift
+func classifyScheduleChange(change database.ScheduleChange) (string, float64, bool) {
+	switch change.EventType {
+	case database.ScheduleEventCreated:
+		return PredictabilityAdded, 0, true
+	case database.ScheduleEventCancelled:
+		return PredictabilityCancelled, change.Hours, true
+	case database.ScheduleEventEdited:
+		if change.PreviousHours == nil {
+			return "", 0, false
+		}
+		if change.PreviousDate.Equal(change.Date) && *change.PreviousStartTime == change.StartTime && *change.PreviousEndTime == change.EndTime {
+			return "", 0, false
+		}
+		if change.Hours < *change.PreviousHours {
+			return PredictabilityReduced, *change.PreviousHours - change.Hours, true
+		}
+		return PredictabilityChanged, 0, true
+	}
+	return "", 0, false
+}
+
+// AssessPredictabilityPay flags the changes made less than the notice period of the policy before the
+// shift, and computes the pay they owe at the hourly salaries of the employees
+func AssessPredictabilityPay(changes []database.ScheduleChange, policy PredictabilityPolicy, salaries map[uuid.UUID]*float64) PredictabilityPayReport {
+	report := PredictabilityPayReport{
+		Policy:    policy,
+		Changes:   []PredictabilityPayChange{},
+		Employees: []PredictabilityPayEmployee{},
+	}
+	noticeHours := float64(policy.NoticeDays * 24)
+
+	byEmployee := make(map[uuid.UUID]int)
+	for _, change := range changes {
+		if change.NoticeHours >= noticeHours {
+			continue
+		}
+		kind, hoursLost, ok := classifyScheduleChange(change)
+		if !ok {
+			continue
+		}
+
+		owed := PredictabilityPayChange{ScheduleChange: change, Change: kind, HoursLost: roundCents(hoursLost)}
+		if hoursLost > 0 {
+			owed.OwedHours = roundCents(hoursLost * float64(policy.LostHoursPercent) / 100)
+		} else {
+			owed.OwedHours = policy.PayHours
+		}
+		salary := salaries[change.EmployeeID]
+		if salary != nil {
+			pay := roundCents(owed.OwedHours * *salary)
+			owed.OwedPay = &pay
+		}
+		report.Changes = append(report.Changes, owed)
+
+		i, seen := byEmployee[change.EmployeeID]
+		if !seen {
+			i = len(report.Employees)
+			byEmployee[change.EmployeeID] = i
+			report.Employees = append(report.Employees, PredictabilityPayEmployee{EmployeeID: change.EmployeeID, EmployeeName: change.EmployeeName})
+			if salary == nil {
+				report.WithoutSalary++
+			} else {
+				report.Employees[i].OwedPay = new(float64)
+			}
+		}
+		employee := &report.Employees[i]
+		employee.Changes++
+		employee.OwedHours = roundCents(employee.OwedHours + owed.OwedHours)
+		report.OwedHours = roundCents(report.OwedHours + owed.OwedHours)
+		if owed.OwedPay != nil {
+			*employee.OwedPay = roundCents(*employee.OwedPay + *owed.OwedPay)
+			report.OwedPay = roundCents(report.OwedPay + *owed.OwedPay)
+		}
+	}
+	return report
+}
+
+// PredictabilityPay reports the predictability pay owed for the changes to the shifts of the organization
+// dated in [from, to)
+func PredictabilityPay(scheduleStore database.ScheduleStore, userStore database.UserStore, orgID uuid.UUID, policy PredictabilityPolicy, from, to time.Time) (*PredictabilityPayReport, error) {
+	changes, err := scheduleStore.GetScheduleChanges(orgID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	users, err := userStore.GetUsersByOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+	salaries := make(map[uuid.UUID]*float64, len(users))
+	for _, user := range users {
+		salaries[user.ID] = user.SalaryPerHour
+	}
+
+	report := AssessPredictabilityPay(changes, policy, salaries)
+	report.From = from
+	report.To = to.AddDate(0, 0, -1)
+	return &report, nil
+}
+
+func roundCents(value float64) float64 {
+	return math.Round(value*100) / 100
+}
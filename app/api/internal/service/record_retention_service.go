@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+)
+
+const defaultRecordPurgeInterval = 24 * time.Hour
+
+// RecordRetentionService periodically deletes the employee records whose retention has ended
+type RecordRetentionService struct {
+	EmployeeRecordStore database.EmployeeRecordStore
+	Logger              *slog.Logger
+
+	// Interval is how often expired records are purged
+	Interval time.Duration
+}
+
+// NewRecordRetentionService reads RECORD_PURGE_INTERVAL (a Go duration, e.g. "12h") and falls back to daily purges
+func NewRecordRetentionService(employeeRecordStore database.EmployeeRecordStore, Logger *slog.Logger) *RecordRetentionService {
+	return &RecordRetentionService{
+		EmployeeRecordStore: employeeRecordStore,
+		Logger:              Logger,
+		Interval:            durationFromEnv("RECORD_PURGE_INTERVAL", defaultRecordPurgeInterval, Logger),
+	}
+}
+
+// Start purges expired records right away and then every Interval until the context is cancelled
+func (s *RecordRetentionService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.Logger.Info("record retention service started", "interval", s.Interval)
+	s.purge()
+	for {
+		select {
+		case <-ctx.Done():
+			s.Logger.Info("record retention service stopped")
+			return
+		case <-ticker.C:
+			s.purge()
+		}
+	}
+}
+
+func (s *RecordRetentionService) purge() {
+	purged, err := s.EmployeeRecordStore.PurgeExpiredRecords(time.Now())
+	if err != nil {
+		s.Logger.Error("failed to purge expired employee records", "error", err)
+		return
+	}
+	if purged > 0 {
+		s.Logger.Info("expired employee records purged", "count", purged)
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultShiftReminderInterval = time.Hour
+	defaultShiftReminderAfter    = 24 * time.Hour
+)
+
+// ShiftReminderService periodically emails employees who have not acknowledged their published shifts
+type ShiftReminderService struct {
+	ScheduleStore database.ScheduleStore
+	EmailService  EmailService
+	Logger        *slog.Logger
+
+	// Interval is how often pending acknowledgments are checked
+	Interval time.Duration
+	// RemindAfter is how long a shift stays unacknowledged before the first reminder, and between reminders
+	RemindAfter time.Duration
+}
+
+// NewShiftReminderService reads SHIFT_REMINDER_INTERVAL and SHIFT_REMINDER_AFTER (Go durations, e.g. "30m")
+// and falls back to hourly checks and a 24 hour reminder delay
+func NewShiftReminderService(scheduleStore database.ScheduleStore, emailService EmailService, Logger *slog.Logger) *ShiftReminderService {
+	return &ShiftReminderService{
+		ScheduleStore: scheduleStore,
+		EmailService:  emailService,
+		Logger:        Logger,
+		Interval:      durationFromEnv("SHIFT_REMINDER_INTERVAL", defaultShiftReminderInterval, Logger),
+		RemindAfter:   durationFromEnv("SHIFT_REMINDER_AFTER", defaultShiftReminderAfter, Logger),
+	}
+}
+
+// Start runs reminder rounds every Interval until the context is cancelled
+func (s *ShiftReminderService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.Logger.Info("shift reminder service started", "interval", s.Interval, "remind_after", s.RemindAfter)
+	for {
+		select {
+		case <-ctx.Done():
+			s.Logger.Info("shift reminder service stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.SendReminders(time.Now()); err != nil {
+				s.Logger.Error("failed to send shift reminders", "error", err)
+			}
+		}
+	}
+}
+
+// SendReminders emails every employee with shifts pending acknowledgment and returns how many were reminded
+func (s *ShiftReminderService) SendReminders(now time.Time) (int, error) {
+	pending, err := s.ScheduleStore.GetShiftsPendingReminder(now.Add(-s.RemindAfter))
+	if err != nil {
+		return 0, err
+	}
+
+	type reminder struct {
+		name   string
+		email  string
+		shifts []string
+	}
+	var order []uuid.UUID
+	reminders := make(map[uuid.UUID]*reminder)
+	for _, shift := range pending {
+		r, ok := reminders[shift.EmployeeID]
+		if !ok {
+			r = &reminder{name: shift.EmployeeName, email: shift.EmployeeEmail}
+			reminders[shift.EmployeeID] = r
+			order = append(order, shift.EmployeeID)
+		}
+		r.shifts = append(r.shifts, fmt.Sprintf("%s, %s - %s", shift.Date.Format("Monday, Jan 2"), shift.StartTime, shift.EndTime))
+	}
+
+	reminded := 0
+	for _, employeeID := range order {
+		r := reminders[employeeID]
+		if err := s.EmailService.SendShiftReminderEmail(r.email, r.name, r.shifts); err != nil {
+			s.Logger.Error("failed to send shift reminder email", "error", err, "employee_id", employeeID)
+			continue
+		}
+		if err := s.ScheduleStore.MarkShiftRemindersSent(employeeID); err != nil {
+			s.Logger.Error("failed to record shift reminder", "error", err, "employee_id", employeeID)
+			continue
+		}
+		reminded++
+	}
+
+	if reminded > 0 {
+		s.Logger.Info("shift reminders sent", "employees", reminded)
+	}
+	return reminded, nil
+}
+
+func durationFromEnv(key string, fallback time.Duration, Logger *slog.Logger) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		Logger.Warn("invalid duration in environment, using default", "key", key, "value", value, "default", fallback)
+		return fallback
+	}
+	return duration
+}
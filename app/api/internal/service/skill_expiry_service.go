@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultSkillExpiryInterval     = 24 * time.Hour
+	defaultSkillExpiryReminderDays = 30
+)
+
+// SkillExpiryService periodically reminds employees and the managers of their organization that a verified
+// certification is about to expire. Each verification is reminded of once, declaring the renewed
+// certification starts a new one.
+type SkillExpiryService struct {
+	SkillStore   database.SkillStore
+	OrgStore     database.OrgStore
+	EmailService EmailService
+	Logger       *slog.Logger
+
+	// Interval is how often expiring verifications are checked
+	Interval time.Duration
+	// ReminderDays is how many days before the expiry the reminder is sent
+	ReminderDays int
+}
+
+// NewSkillExpiryService reads SKILL_EXPIRY_INTERVAL (a Go duration) and SKILL_EXPIRY_REMINDER_DAYS and
+// falls back to daily checks reminding 30 days ahead
+func NewSkillExpiryService(skillStore database.SkillStore, orgStore database.OrgStore, emailService EmailService, Logger *slog.Logger) *SkillExpiryService {
+	return &SkillExpiryService{
+		SkillStore:   skillStore,
+		OrgStore:     orgStore,
+		EmailService: emailService,
+		Logger:       Logger,
+		Interval:     durationFromEnv("SKILL_EXPIRY_INTERVAL", defaultSkillExpiryInterval, Logger),
+		ReminderDays: intFromEnv("SKILL_EXPIRY_REMINDER_DAYS", defaultSkillExpiryReminderDays, Logger),
+	}
+}
+
+// Start sends the reminders due right away and then every Interval until the context is cancelled
+func (s *SkillExpiryService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.Logger.Info("skill expiry service started", "interval", s.Interval, "reminder_days", s.ReminderDays)
+	s.remind(time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			s.Logger.Info("skill expiry service stopped")
+			return
+		case <-ticker.C:
+			s.remind(time.Now())
+		}
+	}
+}
+
+func (s *SkillExpiryService) remind(now time.Time) {
+	if _, err := s.SendReminders(now); err != nil {
+		s.Logger.Error("failed to send skill expiry reminders", "error", err)
+	}
+}
+
+// SendReminders emails the reminders of the verifications expiring within ReminderDays and returns how many
+// were sent. Reminders that failed are retried on the next check.
+func (s *SkillExpiryService) SendReminders(now time.Time) (int, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	skills, err := s.SkillStore.GetExpiringSkills(today.AddDate(0, 0, s.ReminderDays))
+	if err != nil {
+		return 0, err
+	}
+
+	managerEmails := make(map[uuid.UUID][]string)
+	sent := 0
+	for _, skill := range skills {
+		if skill.ExpiresOn == nil {
+			continue
+		}
+		expiresOn := time.Date(skill.ExpiresOn.Year(), skill.ExpiresOn.Month(), skill.ExpiresOn.Day(), 0, 0, 0, 0, time.UTC)
+		daysLeft := int(expiresOn.Sub(today).Hours() / 24)
+
+		managers, ok := managerEmails[skill.OrganizationID]
+		if !ok {
+			managers, err = s.OrgStore.GetManagerEmailsByOrgID(skill.OrganizationID)
+			if err != nil {
+				s.Logger.Error("failed to get manager emails", "error", err, "org_id", skill.OrganizationID)
+			}
+			managerEmails[skill.OrganizationID] = managers
+		}
+
+		recipients := []string{skill.EmployeeEmail}
+		for _, email := range managers {
+			if email != skill.EmployeeEmail {
+				recipients = append(recipients, email)
+			}
+		}
+		if err := s.EmailService.SendSkillExpiryEmail(recipients, skill.EmployeeName, skill.Name, expiresOn.Format(time.DateOnly), daysLeft); err != nil {
+			s.Logger.Error("failed to send skill expiry email", "error", err, "skill_id", skill.ID)
+			continue
+		}
+		if err := s.SkillStore.MarkSkillExpiryReminded(skill.ID); err != nil {
+			s.Logger.Error("failed to record skill expiry reminder", "error", err, "skill_id", skill.ID)
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		s.Logger.Info("skill expiry reminders sent", "count", sent)
+	}
+	return sent, nil
+}
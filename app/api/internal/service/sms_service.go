@@ -0,0 +1,75 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/config"
+)
+
+type SMSService interface {
+	SendSMS(toPhone, message string) error
+}
+
+// HTTPSMSService posts text messages to the SMS gateway at SMS_API_URL as {"to", "message"}, authenticated
+// with the SMS_API_TOKEN secret as a bearer token. Without SMS_API_URL the messages are only logged.
+type HTTPSMSService struct {
+	url     string
+	secrets config.SecretsProvider
+	Client  *http.Client
+	Logger  *slog.Logger
+}
+
+func NewHTTPSMSService(Logger *slog.Logger, secrets config.SecretsProvider) *HTTPSMSService {
+	return &HTTPSMSService{
+		url:     os.Getenv("SMS_API_URL"),
+		secrets: secrets,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+		Logger:  Logger,
+	}
+}
+
+func (s *HTTPSMSService) SendSMS(toPhone, message string) error {
+	// Fallback for development if no gateway is configured
+	if s.url == "" {
+		log.Printf("\n[MOCK SMS] To: %s | %s\n", toPhone, message)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	token, err := s.secrets.GetSecret(ctx, "SMS_API_TOKEN")
+	if err != nil {
+		return fmt.Errorf("failed to resolve SMS token: %w", err)
+	}
+	body, err := json.Marshal(map[string]string{"to": toPhone, "message": message})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		s.Logger.Error("failed to send SMS", "error", err)
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		s.Logger.Error("SMS gateway rejected message", "status", resp.StatusCode)
+		return fmt.Errorf("SMS gateway returned %s", resp.Status)
+	}
+	return nil
+}
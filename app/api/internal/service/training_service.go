@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+)
+
+const defaultTrainingGraduationInterval = time.Hour
+
+// TrainingService graduates the employees whose training period ended and emails the managers of their
+// organization, so the next schedules count them toward the staff needed again
+type TrainingService struct {
+	Store        database.TrainingStore
+	OrgStore     database.OrgStore
+	EmailService EmailService
+	Logger       *slog.Logger
+
+	// Interval is how often the ended trainings are looked for
+	Interval time.Duration
+}
+
+// NewTrainingService reads TRAINING_GRADUATION_INTERVAL (a Go duration, e.g. "30m") and falls back to
+// graduating every hour
+func NewTrainingService(store database.TrainingStore, orgStore database.OrgStore, emailService EmailService, Logger *slog.Logger) *TrainingService {
+	return &TrainingService{
+		Store:        store,
+		OrgStore:     orgStore,
+		EmailService: emailService,
+		Logger:       Logger,
+		Interval:     durationFromEnv("TRAINING_GRADUATION_INTERVAL", defaultTrainingGraduationInterval, Logger),
+	}
+}
+
+// Start graduates the ended trainings every Interval until the context is cancelled
+func (s *TrainingService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.Logger.Info("training service started", "interval", s.Interval)
+	for {
+		select {
+		case <-ctx.Done():
+			s.Logger.Info("training service stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.GraduateDue(time.Now()); err != nil {
+				s.Logger.Error("failed to graduate employees", "error", err)
+			}
+		}
+	}
+}
+
+// GraduateDue graduates the employees whose training ended by the day of now and returns how many were
+// graduated. The graduation is stored before the email is sent, so the managers are not emailed twice.
+func (s *TrainingService) GraduateDue(now time.Time) (int, error) {
+	trainings, err := s.Store.GetDueTrainings(now)
+	if err != nil {
+		return 0, err
+	}
+
+	graduated := 0
+	for _, training := range trainings {
+		if err := s.Store.Graduate(training.OrganizationID, training.UserID, now); err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				s.Logger.Error("failed to graduate employee", "error", err, "user_id", training.UserID)
+			}
+			continue
+		}
+		graduated++
+
+		emails, err := managerAndAdminEmails(s.OrgStore, training.OrganizationID)
+		if err != nil {
+			s.Logger.Error("failed to get manager emails", "error", err, "organization_id", training.OrganizationID)
+			continue
+		}
+		if err := s.EmailService.SendTrainingGraduationEmail(emails, describeGraduation(training)); err != nil {
+			s.Logger.Error("failed to send training graduation email", "error", err, "user_id", training.UserID)
+		}
+	}
+
+	if graduated > 0 {
+		s.Logger.Info("employees graduated from training", "employees", graduated)
+	}
+	return graduated, nil
+}
+
+// describeGraduation writes the summary line of the graduation email
+func describeGraduation(training database.EmployeeTraining) string {
+	return fmt.Sprintf("%s completed the training period of %d days started on %s and is now scheduled like the rest of the staff.",
+		training.FullName, int(training.EndsOn.Sub(training.StartsOn).Hours()/24), training.StartsOn.Format(time.DateOnly))
+}
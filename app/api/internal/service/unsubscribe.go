@@ -0,0 +1,70 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+)
+
+var ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+
+// UnsubscribeSigner signs the unsubscribe links of non-critical emails, so an address can be
+// unsubscribed from a category without logging in but only by someone who received the email
+type UnsubscribeSigner struct {
+	secret []byte
+	// BaseURL is the public URL of the unsubscribe endpoint the token is appended to
+	BaseURL string
+}
+
+// NewUnsubscribeSigner signs with UNSUBSCRIBE_SECRET, or JWT_SECRET when it is not set, and links to
+// the PublicAPIURL
+func NewUnsubscribeSigner() *UnsubscribeSigner {
+	secret := os.Getenv("UNSUBSCRIBE_SECRET")
+	if secret == "" {
+		secret = os.Getenv("JWT_SECRET")
+	}
+	return &UnsubscribeSigner{secret: []byte(secret), BaseURL: PublicAPIURL() + "/api/unsubscribe"}
+}
+
+func (u *UnsubscribeSigner) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, u.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// Token returns the signed token unsubscribing the address from the category
+func (u *UnsubscribeSigner) Token(email, category string) string {
+	payload := strings.ToLower(email) + "\n" + category
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + base64.RawURLEncoding.EncodeToString(u.sign(payload))
+}
+
+// Link returns the unsubscribe URL of the address for the category
+func (u *UnsubscribeSigner) Link(email, category string) string {
+	return u.BaseURL + "?token=" + url.QueryEscape(u.Token(email, category))
+}
+
+// Parse checks the signature of a token and returns the address and category it unsubscribes
+func (u *UnsubscribeSigner) Parse(token string) (string, string, error) {
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", "", ErrInvalidUnsubscribeToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return "", "", ErrInvalidUnsubscribeToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, u.sign(string(payload))) {
+		return "", "", ErrInvalidUnsubscribeToken
+	}
+
+	email, category, ok := strings.Cut(string(payload), "\n")
+	if !ok || email == "" || category == "" {
+		return "", "", ErrInvalidUnsubscribeToken
+	}
+	return email, category, nil
+}
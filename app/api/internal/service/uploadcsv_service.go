@@ -3,15 +3,31 @@ package service
 import (
 	"encoding/csv"
 	"errors"
+	"io"
 	"log/slog"
 	"mime/multipart"
+	"os"
+	"strconv"
 )
 
 var (
 	ErrEmptyFile     = errors.New("csv file is empty")
 	ErrInvalidFormat = errors.New("invalid csv format")
+	ErrTooManyRows   = errors.New("csv file has too many rows")
 )
 
+// defaultMaxCSVRows is the row ceiling of an upload unless UPLOAD_MAX_ROWS overrides it
+const defaultMaxCSVRows = 100000
+
+// defaultMaxStreamedCSVRows is the row ceiling of a streamed upload unless UPLOAD_MAX_STREAM_ROWS overrides
+// it, streamed rows are not kept in memory
+const defaultMaxStreamedCSVRows = 1000000
+
+// recordReader reads the rows of an uploaded file as lists of fields, io.EOF after the last one
+type recordReader interface {
+	Read() ([]string, error)
+}
+
 type CSVData struct {
 	Headers []string            `json:"headers"`
 	Rows    []map[string]string `json:"rows"`
@@ -20,27 +36,57 @@ type CSVData struct {
 
 type UploadService interface {
 	ParseCSV(file multipart.File) (*CSVData, error)
+	StreamCSV(file io.Reader) (*CSVStream, error)
 }
 
 type CSVUploadService struct {
-	Logger *slog.Logger
+	Logger        *slog.Logger
+	MaxRows       int
+	MaxStreamRows int
 }
 
 func NewCSVUploadService(logger *slog.Logger) *CSVUploadService {
+	maxRows := defaultMaxCSVRows
+	if value, err := strconv.Atoi(os.Getenv("UPLOAD_MAX_ROWS")); err == nil && value > 0 {
+		maxRows = value
+	}
+
+	maxStreamRows := defaultMaxStreamedCSVRows
+	if value, err := strconv.Atoi(os.Getenv("UPLOAD_MAX_STREAM_ROWS")); err == nil && value > 0 {
+		maxStreamRows = value
+	}
+
 	return &CSVUploadService{
-		Logger: logger,
+		Logger:        logger,
+		MaxRows:       maxRows,
+		MaxStreamRows: maxStreamRows,
 	}
 }
 
-// ParseCSV parses a CSV file from a multipart upload and returns structured data
+// ParseCSV parses a CSV file or the first sheet of an XLSX workbook from a multipart upload and returns
+// structured data
 func (s *CSVUploadService) ParseCSV(file multipart.File) (*CSVData, error) {
-	csvReader := csv.NewReader(file)
-	csvReader.TrimLeadingSpace = true
-	
-	records, err := csvReader.ReadAll()
+	reader, err := s.openRecords(file)
 	if err != nil {
-		s.Logger.Error("failed to read csv", "error", err)
-		return nil, ErrInvalidFormat
+		return nil, err
+	}
+	
+	// Read record by record so oversized files are rejected without loading them whole
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			s.Logger.Error("failed to read csv", "error", err)
+			return nil, ErrInvalidFormat
+		}
+		if s.MaxRows > 0 && len(records) > s.MaxRows {
+			s.Logger.Warn("csv file exceeds row limit", "max_rows", s.MaxRows)
+			return nil, ErrTooManyRows
+		}
+		records = append(records, record)
 	}
 	
 	if len(records) == 0 {
@@ -71,3 +117,111 @@ func (s *CSVUploadService) ParseCSV(file multipart.File) (*CSVData, error) {
 		Total:   len(rows),
 	}, nil
 }
+
+// StreamCSV reads the headers of a CSV file, or of the first sheet of an XLSX workbook that can be read at
+// any offset, and returns a stream of its rows, for files too large to load whole with ParseCSV
+func (s *CSVUploadService) StreamCSV(file io.Reader) (*CSVStream, error) {
+	workbook, err := s.openWorkbook(file)
+	if err != nil {
+		return nil, err
+	}
+	if workbook != nil {
+		return newRecordStream(workbook, s.MaxStreamRows, s.Logger)
+	}
+	return NewCSVStream(file, s.MaxStreamRows, s.Logger)
+}
+
+// openRecords reads the rows of an XLSX workbook, or of a CSV file when the upload is not a workbook
+func (s *CSVUploadService) openRecords(file multipart.File) (recordReader, error) {
+	workbook, err := s.openWorkbook(file)
+	if err != nil || workbook != nil {
+		return workbook, err
+	}
+
+	csvReader := csv.NewReader(file)
+	csvReader.TrimLeadingSpace = true
+	return csvReader, nil
+}
+
+// openWorkbook returns a reader of the first sheet when file is an XLSX workbook, nil when it is not
+func (s *CSVUploadService) openWorkbook(file io.Reader) (recordReader, error) {
+	workbook, err := openXLSX(file)
+	if err == ErrEmptyFile {
+		s.Logger.Warn("xlsx workbook has no sheets")
+		return nil, ErrEmptyFile
+	}
+	if err != nil {
+		s.Logger.Error("failed to open xlsx workbook", "error", err)
+		return nil, ErrInvalidFormat
+	}
+	return workbook, nil
+}
+
+// CSVStream reads the rows of a CSV file or XLSX sheet one at a time. Only the current row is held in
+// memory.
+type CSVStream struct {
+	Headers []string
+	MaxRows int
+	Rows    int // rows read so far, the headers excluded
+
+	reader recordReader
+	logger *slog.Logger
+}
+
+// NewCSVStream reads the headers of r, returning ErrEmptyFile when there are none. maxRows caps the rows
+// read, zero reads them all.
+func NewCSVStream(r io.Reader, maxRows int, logger *slog.Logger) (*CSVStream, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+	return newRecordStream(reader, maxRows, logger)
+}
+
+func newRecordStream(reader recordReader, maxRows int, logger *slog.Logger) (*CSVStream, error) {
+	headers, err := reader.Read()
+	if err == io.EOF {
+		logger.Warn("csv file is empty")
+		return nil, ErrEmptyFile
+	}
+	if err != nil {
+		logger.Error("failed to read csv headers", "error", err)
+		return nil, ErrInvalidFormat
+	}
+
+	return &CSVStream{
+		// the record is reused by the next read
+		Headers: append([]string(nil), headers...),
+		MaxRows: maxRows,
+		reader:  reader,
+		logger:  logger,
+	}, nil
+}
+
+// Next returns the next row by header, io.EOF after the last one. Rows with fewer fields than headers
+// have the missing ones empty.
+func (s *CSVStream) Next() (map[string]string, error) {
+	record, err := s.reader.Read()
+	if err == io.EOF {
+		s.logger.Info("csv streamed successfully", "headers", s.Headers, "row_count", s.Rows)
+		return nil, io.EOF
+	}
+	if err != nil {
+		s.logger.Error("failed to read csv", "error", err, "row", s.Rows+1)
+		return nil, ErrInvalidFormat
+	}
+	if s.MaxRows > 0 && s.Rows >= s.MaxRows {
+		s.logger.Warn("csv file exceeds row limit", "max_rows", s.MaxRows)
+		return nil, ErrTooManyRows
+	}
+	s.Rows++
+
+	row := make(map[string]string, len(s.Headers))
+	for j, header := range s.Headers {
+		if j < len(record) {
+			row[header] = record[j]
+		} else {
+			row[header] = ""
+		}
+	}
+	return row, nil
+}
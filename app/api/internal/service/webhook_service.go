@@ -0,0 +1,235 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultWebhookDeliveryInterval = 30 * time.Second
+	// Deliveries sent per round
+	webhookDeliveryBatch = 50
+	// How long a claimed delivery is hidden from other instances while it is sent
+	webhookDeliveryLease = 5 * time.Minute
+	// Longest error or response body kept on a failed delivery
+	maxWebhookErrorLength = 500
+)
+
+// webhookRetryDelays are the waits before each retry of a failed delivery. A delivery that still fails
+// after the last one is given up.
+var webhookRetryDelays = []time.Duration{time.Minute, 5 * time.Minute, 30 * time.Minute, 2 * time.Hour, 12 * time.Hour}
+
+// Headers of the webhook requests. The signature is the hex HMAC-SHA256 of "<timestamp>.<body>" with the
+// secret of the webhook, so receivers can check the request comes from ClockWise and is recent.
+const (
+	WebhookEventHeader     = "X-ClockWise-Event"
+	WebhookDeliveryHeader  = "X-ClockWise-Delivery"
+	WebhookTimestampHeader = "X-ClockWise-Timestamp"
+	WebhookSignatureHeader = "X-ClockWise-Signature"
+)
+
+// WebhookPayload is the body posted to webhooks. ID identifies the event: it is the same for every
+// webhook and retry, so receivers can ignore events they have already handled.
+type WebhookPayload struct {
+	ID             uuid.UUID `json:"id"`
+	Event          string    `json:"event"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+	OccurredAt     time.Time `json:"occurred_at"`
+	Data           any       `json:"data"`
+}
+
+// WebhookService queues the scheduling events of organizations for their webhooks and periodically
+// posts the queued deliveries, retrying failed ones with increasing delays
+type WebhookService struct {
+	Store  database.WebhookStore
+	Client *http.Client
+	Logger *slog.Logger
+
+	// Interval is how often queued deliveries are sent
+	Interval time.Duration
+}
+
+// NewWebhookService reads WEBHOOK_DELIVERY_INTERVAL (a Go duration, e.g. "1m") and falls back to sending
+// every 30 seconds. Its client refuses to connect to loopback and private addresses.
+func NewWebhookService(store database.WebhookStore, Logger *slog.Logger) *WebhookService {
+	return &WebhookService{
+		Store: store,
+		// a webhook answering with a redirect is a failed delivery
+		Client:   publicHTTPClient(15 * time.Second),
+		Logger:   Logger,
+		Interval: durationFromEnv("WEBHOOK_DELIVERY_INTERVAL", defaultWebhookDeliveryInterval, Logger),
+	}
+}
+
+var errPrivateAddress = errors.New("connections to loopback or private addresses are refused")
+
+// publicHTTPClient is the client for URLs organizations configure. It refuses to connect to loopback and
+// private addresses, and does not follow redirects.
+func publicHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:       timeout,
+		Transport:     publicTransport(),
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+}
+
+// publicTransport only dials public addresses, whatever a host name resolves to
+func publicTransport() *http.Transport {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: refusePrivateAddresses}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+	return transport
+}
+
+func refusePrivateAddresses(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return errPrivateAddress
+	}
+	return nil
+}
+
+// Publish queues an event for the webhooks of the organization subscribed to it. A nil service publishes
+// nothing. Failing to queue is logged and not returned: the change that caused the event has been made.
+func (s *WebhookService) Publish(orgID uuid.UUID, event string, data any) {
+	if s == nil {
+		return
+	}
+	payload, err := json.Marshal(WebhookPayload{
+		ID:             uuid.New(),
+		Event:          event,
+		OrganizationID: orgID,
+		OccurredAt:     time.Now().UTC(),
+		Data:           data,
+	})
+	if err != nil {
+		s.Logger.Error("failed to encode webhook event", "error", err, "event", event)
+		return
+	}
+	queued, err := s.Store.QueueEvent(orgID, event, payload)
+	if err != nil {
+		s.Logger.Warn("failed to queue webhook event", "error", err, "org_id", orgID, "event", event)
+		return
+	}
+	if queued > 0 {
+		s.Logger.Info("webhook event queued", "org_id", orgID, "event", event, "webhooks", queued)
+	}
+}
+
+// Start sends the queued deliveries every Interval until the context is cancelled
+func (s *WebhookService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.Logger.Info("webhook service started", "interval", s.Interval)
+	for {
+		select {
+		case <-ctx.Done():
+			s.Logger.Info("webhook service stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.DeliverDue(ctx, time.Now()); err != nil {
+				s.Logger.Error("failed to send webhook deliveries", "error", err)
+			}
+		}
+	}
+}
+
+// DeliverDue sends the deliveries due at now and returns how many were delivered
+func (s *WebhookService) DeliverDue(ctx context.Context, now time.Time) (int, error) {
+	deliveries, err := s.Store.ClaimDueDeliveries(now, webhookDeliveryLease, webhookDeliveryBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, delivery := range deliveries {
+		attempt := s.send(ctx, delivery, now)
+		if err := s.Store.RecordAttempt(delivery.ID, attempt); err != nil {
+			continue
+		}
+		if attempt.Delivered {
+			delivered++
+		} else if attempt.NextAttemptAt == nil {
+			s.Logger.Warn("webhook delivery failed for good", "delivery_id", delivery.ID, "webhook_id", delivery.WebhookID,
+				"event", delivery.Event, "attempts", delivery.Attempts+1)
+		}
+	}
+
+	if delivered > 0 {
+		s.Logger.Info("webhook deliveries sent", "delivered", delivered, "claimed", len(deliveries))
+	}
+	return delivered, nil
+}
+
+// send posts a delivery once. Any 2xx response delivers it.
+func (s *WebhookService) send(ctx context.Context, delivery database.WebhookDelivery, now time.Time) database.WebhookAttempt {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return failedAttempt(delivery, now, nil, err.Error())
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ClockWise-Webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID.String())
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return failedAttempt(delivery, now, nil, err.Error())
+	}
+	defer resp.Body.Close()
+
+	status := resp.StatusCode
+	if status >= 200 && status < 300 {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxWebhookErrorLength))
+		return database.WebhookAttempt{Delivered: true, ResponseStatus: &status}
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookErrorLength))
+	return failedAttempt(delivery, now, &status, fmt.Sprintf("webhook answered %d: %s", status, bytes.TrimSpace(body)))
+}
+
+// failedAttempt schedules the next retry of a delivery, or none once the retries have run out
+func failedAttempt(delivery database.WebhookDelivery, now time.Time, status *int, message string) database.WebhookAttempt {
+	if len(message) > maxWebhookErrorLength {
+		message = message[:maxWebhookErrorLength]
+	}
+	attempt := database.WebhookAttempt{ResponseStatus: status, Error: &message}
+	if delivery.Attempts < len(webhookRetryDelays) {
+		next := now.Add(webhookRetryDelays[delivery.Attempts])
+		attempt.NextAttemptAt = &next
+	}
+	return attempt
+}
+
+// SignWebhook returns the hex HMAC-SHA256 of "<timestamp>.<body>" with the secret of a webhook
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/clockwise/clockwise/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultWeeklyScheduleEmailInterval = 30 * time.Minute
+	defaultWeeklyScheduleEmailHour     = 18
+)
+
+// WeeklyScheduleEmailService emails every employee their published shifts of the coming week on Sunday
+// evening, with the total hours and the pay estimated from their hourly salary. Each employee gets one
+// email per week, employees who opted out of the weekly_summary emails are skipped by the EmailService.
+type WeeklyScheduleEmailService struct {
+	ScheduleStore database.ScheduleStore
+	UserStore     database.UserStore
+	EmailService  EmailService
+	Logger        *slog.Logger
+
+	// Interval is how often it is checked whether the emails are due
+	Interval time.Duration
+	// SendHour is the hour of Sunday from which the emails are sent
+	SendHour int
+}
+
+// NewWeeklyScheduleEmailService reads WEEKLY_SCHEDULE_EMAIL_INTERVAL (a Go duration) and
+// WEEKLY_SCHEDULE_EMAIL_HOUR (0-23) and falls back to checks every 30 minutes from 18:00
+func NewWeeklyScheduleEmailService(scheduleStore database.ScheduleStore, userStore database.UserStore, emailService EmailService, Logger *slog.Logger) *WeeklyScheduleEmailService {
+	sendHour := defaultWeeklyScheduleEmailHour
+	if value := os.Getenv("WEEKLY_SCHEDULE_EMAIL_HOUR"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed >= 0 && parsed < 24 {
+			sendHour = parsed
+		} else {
+			Logger.Warn("invalid hour in environment, using default", "key", "WEEKLY_SCHEDULE_EMAIL_HOUR", "value", value, "default", sendHour)
+		}
+	}
+
+	return &WeeklyScheduleEmailService{
+		ScheduleStore: scheduleStore,
+		UserStore:     userStore,
+		EmailService:  emailService,
+		Logger:        Logger,
+		Interval:      durationFromEnv("WEEKLY_SCHEDULE_EMAIL_INTERVAL", defaultWeeklyScheduleEmailInterval, Logger),
+		SendHour:      sendHour,
+	}
+}
+
+// Start checks every Interval whether the weekly emails are due until the context is cancelled
+func (s *WeeklyScheduleEmailService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.Logger.Info("weekly schedule email service started", "interval", s.Interval, "send_hour", s.SendHour)
+	for {
+		select {
+		case <-ctx.Done():
+			s.Logger.Info("weekly schedule email service stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.SendWeeklySchedules(time.Now()); err != nil {
+				s.Logger.Error("failed to send weekly schedule emails", "error", err)
+			}
+		}
+	}
+}
+
+// SendWeeklySchedules emails the shifts of the week starting tomorrow when now is Sunday from SendHour,
+// and returns how many employees were emailed. Employees whose email failed are retried in the next round.
+func (s *WeeklyScheduleEmailService) SendWeeklySchedules(now time.Time) (int, error) {
+	if now.Weekday() != time.Sunday || now.Hour() < s.SendHour {
+		return 0, nil
+	}
+	weekStart := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+
+	shifts, err := s.ScheduleStore.GetShiftsForWeeklyEmail(weekStart)
+	if err != nil {
+		return 0, err
+	}
+
+	var order []uuid.UUID
+	byEmployee := make(map[uuid.UUID][]database.WeeklyShift)
+	for _, shift := range shifts {
+		if _, ok := byEmployee[shift.EmployeeID]; !ok {
+			order = append(order, shift.EmployeeID)
+		}
+		byEmployee[shift.EmployeeID] = append(byEmployee[shift.EmployeeID], shift)
+	}
+
+	sent := 0
+	weekOf := weekStart.Format("Jan 2, 2006")
+	for _, employeeID := range order {
+		employeeShifts := byEmployee[employeeID]
+		lines, totalHours := summarizeWeeklyShifts(employeeShifts)
+
+		var estimatedPay *float64
+		user, err := s.UserStore.GetUserByID(employeeID)
+		if err != nil {
+			// The shifts are still worth sending without the pay
+			s.Logger.Error("failed to get salary for weekly schedule email", "error", err, "employee_id", employeeID)
+		} else if user.SalaryPerHour != nil {
+			pay := EstimateWeeklyPay(employeeShifts, *user.SalaryPerHour)
+			estimatedPay = &pay
+		}
+
+		first := employeeShifts[0]
+		if err := s.EmailService.SendWeeklyScheduleEmail(first.EmployeeEmail, first.EmployeeName, weekOf, lines, totalHours, estimatedPay); err != nil {
+			s.Logger.Error("failed to send weekly schedule email", "error", err, "employee_id", employeeID)
+			continue
+		}
+		if err := s.ScheduleStore.MarkWeeklyEmailSent(employeeID, weekStart); err != nil {
+			s.Logger.Error("failed to record weekly schedule email", "error", err, "employee_id", employeeID)
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		s.Logger.Info("weekly schedule emails sent", "employees", sent, "week_start", weekStart.Format(time.DateOnly))
+	}
+	return sent, nil
+}
+
+// summarizeWeeklyShifts returns a line per shift and the hours of the week, standby shifts included
+func summarizeWeeklyShifts(shifts []database.WeeklyShift) ([]string, float64) {
+	lines := make([]string, 0, len(shifts))
+	totalHours := 0.0
+	for _, shift := range shifts {
+		line := fmt.Sprintf("%s, %s - %s", shift.Date.Format("Monday, Jan 2"), shortClock(shift.StartTime), shortClock(shift.EndTime))
+		if shift.ShiftType == database.ShiftStandby {
+			line += " (standby)"
+		}
+		lines = append(lines, line)
+		totalHours += shift.Hours
+	}
+	return lines, math.Round(totalHours*100) / 100
+}
+
+// EstimateWeeklyPay is the pay of the shifts at the hourly salary, standby shifts paid the standby share
+// of their organization, rounded to cents
+func EstimateWeeklyPay(shifts []database.WeeklyShift, salaryPerHour float64) float64 {
+	pay := 0.0
+	for _, shift := range shifts {
+		rate := salaryPerHour
+		if shift.ShiftType == database.ShiftStandby {
+			rate = salaryPerHour * float64(shift.StandbyPayPercent) / 100
+		}
+		pay += shift.Hours * rate
+	}
+	return math.Round(pay*100) / 100
+}
+
+// shortClock drops the seconds of a time of day, "09:00:00" becomes "09:00"
+func shortClock(clock string) string {
+	if len(clock) == len("15:04:05") {
+		return clock[:5]
+	}
+	return clock
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// xlsxMagic starts every XLSX workbook, a zip archive
+var xlsxMagic = []byte("PK\x03\x04")
+
+// errNotXLSX is returned for workbooks missing a part every workbook has
+var errNotXLSX = errors.New("not an xlsx workbook")
+
+// openXLSX returns a reader of the first sheet when file is an XLSX workbook, nil when it is not. Only
+// files that can be read at any offset, such as multipart uploads, are checked.
+func openXLSX(file io.Reader) (recordReader, error) {
+	readerAt, ok := file.(io.ReaderAt)
+	seeker, canSeek := file.(io.Seeker)
+	if !ok || !canSeek {
+		return nil, nil
+	}
+
+	magic := make([]byte, len(xlsxMagic))
+	if n, _ := readerAt.ReadAt(magic, 0); n < len(magic) || !bytes.Equal(magic, xlsxMagic) {
+		return nil, nil
+	}
+	size, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(readerAt, size)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := newXLSXSheetReader(archive)
+	if err != nil {
+		return nil, err
+	}
+	return reader, nil
+}
+
+// xlsxSheetReader reads the rows of the first sheet of a workbook one at a time. Only the shared strings
+// of the workbook are held in memory.
+type xlsxSheetReader struct {
+	decoder    *xml.Decoder
+	sheet      io.ReadCloser
+	strings    []string
+	dateStyles map[int]bool
+	date1904   bool
+	done       bool
+}
+
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+// String joins the runs of rich text
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var text strings.Builder
+	for _, run := range t.Runs {
+		text.WriteString(run.Text)
+	}
+	return text.String()
+}
+
+type xlsxCell struct {
+	Ref    string   `xml:"r,attr"`
+	Type   string   `xml:"t,attr"`
+	Style  int      `xml:"s,attr"`
+	Value  string   `xml:"v"`
+	Inline xlsxText `xml:"is"`
+}
+
+func newXLSXSheetReader(archive *zip.Reader) (*xlsxSheetReader, error) {
+	parts := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		parts[file.Name] = file
+	}
+
+	var workbook struct {
+		Properties struct {
+			Date1904 bool `xml:"date1904,attr"`
+		} `xml:"workbookPr"`
+		Sheets []struct {
+			RelationshipID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodeXLSXPart(parts, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	if len(workbook.Sheets) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	sheetPath := "xl/worksheets/sheet1.xml"
+	var relationships struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodeXLSXPart(parts, "xl/_rels/workbook.xml.rels", &relationships); err == nil {
+		for _, relationship := range relationships.Relationships {
+			if relationship.ID != workbook.Sheets[0].RelationshipID {
+				continue
+			}
+			if strings.HasPrefix(relationship.Target, "/") {
+				sheetPath = strings.TrimPrefix(relationship.Target, "/")
+			} else {
+				sheetPath = path.Join("xl", relationship.Target)
+			}
+		}
+	}
+
+	reader := &xlsxSheetReader{date1904: workbook.Properties.Date1904}
+
+	var sharedStrings struct {
+		Items []xlsxText `xml:"si"`
+	}
+	if err := decodeXLSXPart(parts, "xl/sharedStrings.xml", &sharedStrings); err != nil && err != errNotXLSX {
+		return nil, err
+	}
+	reader.strings = make([]string, len(sharedStrings.Items))
+	for i, item := range sharedStrings.Items {
+		reader.strings[i] = item.String()
+	}
+
+	reader.dateStyles = xlsxDateStyles(parts)
+
+	sheet, ok := parts[sheetPath]
+	if !ok {
+		return nil, errNotXLSX
+	}
+	content, err := sheet.Open()
+	if err != nil {
+		return nil, err
+	}
+	reader.sheet = content
+	reader.decoder = xml.NewDecoder(content)
+	return reader, nil
+}
+
+// Read returns the cells of the next row, leaving the cells missing before the last one empty
+func (r *xlsxSheetReader) Read() ([]string, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+	for {
+		token, err := r.decoder.Token()
+		if err == io.EOF {
+			r.done = true
+			r.sheet.Close()
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+
+		var row struct {
+			Cells []xlsxCell `xml:"c"`
+		}
+		if err := r.decoder.DecodeElement(&row, &start); err != nil {
+			return nil, err
+		}
+		var record []string
+		for _, cell := range row.Cells {
+			column := len(record)
+			if cell.Ref != "" {
+				if column, err = xlsxColumnIndex(cell.Ref); err != nil {
+					return nil, err
+				}
+			}
+			for len(record) <= column {
+				record = append(record, "")
+			}
+			if record[column], err = r.cellValue(cell); err != nil {
+				return nil, err
+			}
+		}
+		// rows of blank cells are skipped like blank lines of a CSV file
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		return record, nil
+	}
+}
+
+func (r *xlsxSheetReader) cellValue(cell xlsxCell) (string, error) {
+	switch cell.Type {
+	case "s":
+		index, err := strconv.Atoi(strings.TrimSpace(cell.Value))
+		if err != nil || index < 0 || index >= len(r.strings) {
+			return "", errNotXLSX
+		}
+		return r.strings[index], nil
+	case "inlineStr":
+		return cell.Inline.String(), nil
+	case "b":
+		if cell.Value == "1" {
+			return "true", nil
+		}
+		return "false", nil
+	case "", "n":
+		if r.dateStyles[cell.Style] {
+			if serial, err := strconv.ParseFloat(cell.Value, 64); err == nil {
+				return xlsxDate(serial, r.date1904), nil
+			}
+		}
+	}
+	return cell.Value, nil
+}
+
+// xlsxDateStyles returns the cell styles showing dates, which hold the number of days since 1900
+func xlsxDateStyles(parts map[string]*zip.File) map[int]bool {
+	var styles struct {
+		NumberFormats []struct {
+			ID   int    `xml:"numFmtId,attr"`
+			Code string `xml:"formatCode,attr"`
+		} `xml:"numFmts>numFmt"`
+		CellFormats []struct {
+			NumberFormatID int `xml:"numFmtId,attr"`
+		} `xml:"cellXfs>xf"`
+	}
+	if err := decodeXLSXPart(parts, "xl/styles.xml", &styles); err != nil {
+		return nil
+	}
+
+	dateFormats := make(map[int]bool)
+	for id := 14; id <= 22; id++ {
+		dateFormats[id] = true
+	}
+	for _, id := range []int{45, 46, 47} {
+		dateFormats[id] = true
+	}
+	for _, format := range styles.NumberFormats {
+		dateFormats[format.ID] = isXLSXDateFormat(format.Code)
+	}
+
+	dateStyles := make(map[int]bool)
+	for i, format := range styles.CellFormats {
+		if dateFormats[format.NumberFormatID] {
+			dateStyles[i] = true
+		}
+	}
+	return dateStyles
+}
+
+// isXLSXDateFormat reports whether a custom number format shows a date or a time, ignoring quoted text
+// and colors such as [Red]
+func isXLSXDateFormat(code string) bool {
+	quoted, bracketed := false, false
+	for _, r := range strings.ToLower(code) {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '[':
+			bracketed = true
+		case r == ']':
+			bracketed = false
+		case bracketed:
+		case strings.ContainsRune("ymdhs", r):
+			return true
+		}
+	}
+	return false
+}
+
+// xlsxDate renders a date serial as YYYY-MM-DD, with the time when it has one, and a time of day below 1
+// as HH:MM:SS
+func xlsxDate(serial float64, date1904 bool) string {
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if date1904 {
+		epoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	days := math.Floor(serial)
+	seconds := math.Round((serial - days) * 86400)
+	date := epoch.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second)
+	switch {
+	case days == 0 && !date1904:
+		return date.Format(time.TimeOnly)
+	case seconds == 0:
+		return date.Format(time.DateOnly)
+	}
+	return date.Format(time.DateTime)
+}
+
+// xlsxColumnIndex is the zero based column of a cell reference, A1 is 0 and AA7 is 26
+func xlsxColumnIndex(ref string) (int, error) {
+	index := 0
+	letters := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A') + 1
+		letters++
+	}
+	if letters == 0 || letters > 3 {
+		return 0, errNotXLSX
+	}
+	return index - 1, nil
+}
+
+// decodeXLSXPart decodes a part of the workbook, errNotXLSX when it is missing
+func decodeXLSXPart(parts map[string]*zip.File, name string, value any) error {
+	part, ok := parts[name]
+	if !ok {
+		return errNotXLSX
+	}
+	content, err := part.Open()
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+	return xml.NewDecoder(content).Decode(value)
+}
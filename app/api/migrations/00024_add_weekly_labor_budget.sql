@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE organizations_rules ADD COLUMN weekly_labor_budget DECIMAL(12,2) CHECK (weekly_labor_budget IS NULL OR weekly_labor_budget > 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE organizations_rules DROP COLUMN weekly_labor_budget;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE schedules ADD COLUMN published_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE schedules ADD COLUMN acknowledged_at TIMESTAMPTZ;
+ALTER TABLE schedules ADD COLUMN last_reminded_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_schedules_unacknowledged ON schedules (schedule_date) WHERE acknowledged_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_schedules_unacknowledged;
+ALTER TABLE schedules DROP COLUMN IF EXISTS last_reminded_at;
+ALTER TABLE schedules DROP COLUMN IF EXISTS acknowledged_at;
+ALTER TABLE schedules DROP COLUMN IF EXISTS published_at;
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS organization_memberships (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_role VARCHAR(50) NOT NULL CHECK (user_role IN ('admin','manager','employee')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, organization_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_memberships_organization ON organization_memberships (organization_id);
+
+-- every user is a member of their home organization
+INSERT INTO organization_memberships (user_id, organization_id, user_role)
+SELECT id, organization_id, user_role FROM users
+WHERE organization_id IS NOT NULL AND user_role IS NOT NULL
+ON CONFLICT (user_id, organization_id) DO NOTHING;
+
+CREATE OR REPLACE FUNCTION sync_home_membership()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.organization_id IS NOT NULL AND NEW.user_role IS NOT NULL THEN
+        INSERT INTO organization_memberships (user_id, organization_id, user_role)
+        VALUES (NEW.id, NEW.organization_id, NEW.user_role)
+        ON CONFLICT (user_id, organization_id) DO UPDATE SET user_role = EXCLUDED.user_role;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_sync_home_membership
+AFTER INSERT OR UPDATE OF organization_id, user_role ON users
+FOR EACH ROW
+EXECUTE FUNCTION sync_home_membership();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trigger_sync_home_membership ON users;
+DROP FUNCTION IF EXISTS sync_home_membership();
+DROP TABLE IF EXISTS organization_memberships;
+-- +goose StatementEnd
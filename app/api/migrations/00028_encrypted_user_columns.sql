@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Sensitive columns are encrypted by the application, so they are stored as text
+ALTER TABLE users ADD COLUMN IF NOT EXISTS salary_per_hour_encrypted TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS emergency_contact TEXT;
+
+-- Encrypted salaries leave salary_per_hour empty, the salary rule now accepts either column
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_salary_per_hour_check;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_check;
+ALTER TABLE users ADD CONSTRAINT users_salary_required CHECK (
+    (user_role = 'admin' AND salary_per_hour IS NULL AND salary_per_hour_encrypted IS NULL)
+    OR (user_role != 'admin' AND (salary_per_hour IS NOT NULL OR salary_per_hour_encrypted IS NOT NULL))
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Run the encrypt-columns command with -decrypt first, encrypted salaries cannot be restored here
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_salary_required;
+ALTER TABLE users ADD CONSTRAINT users_salary_per_hour_check CHECK (
+    (user_role = 'admin' AND salary_per_hour IS NULL) OR (user_role != 'admin' AND salary_per_hour IS NOT NULL)
+);
+ALTER TABLE users DROP COLUMN IF EXISTS emergency_contact;
+ALTER TABLE users DROP COLUMN IF EXISTS phone;
+ALTER TABLE users DROP COLUMN IF EXISTS salary_per_hour_encrypted;
+-- +goose StatementEnd
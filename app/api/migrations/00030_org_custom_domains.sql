@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- host name an organization serves its dashboard from, allowed as a CORS origin
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS custom_domain VARCHAR(255);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organizations_custom_domain ON organizations (LOWER(custom_domain));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_organizations_custom_domain;
+ALTER TABLE organizations DROP COLUMN IF EXISTS custom_domain;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- tuning of the wait time estimate quoted to customers
+ALTER TABLE organizations_rules ADD COLUMN prep_buffer_minutes INTEGER NOT NULL DEFAULT 5 CHECK (prep_buffer_minutes >= 0);
+ALTER TABLE organizations_rules ADD COLUMN delivery_minutes INTEGER NOT NULL DEFAULT 25 CHECK (delivery_minutes >= 0);
+ALTER TABLE organizations_rules ADD COLUMN wait_time_factor DECIMAL(4,2) NOT NULL DEFAULT 1.0 CHECK (wait_time_factor > 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE organizations_rules DROP COLUMN wait_time_factor;
+ALTER TABLE organizations_rules DROP COLUMN delivery_minutes;
+ALTER TABLE organizations_rules DROP COLUMN prep_buffer_minutes;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- windows in which an item can be ordered, items without windows are always available
+CREATE TABLE IF NOT EXISTS item_availability (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    item_id UUID NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    start_date DATE,
+    end_date DATE,
+    start_time TIME,
+    end_time TIME,
+    CHECK (start_date IS NULL OR end_date IS NULL OR start_date <= end_date),
+    CHECK ((start_time IS NULL) = (end_time IS NULL)),
+    CHECK (start_time IS NULL OR start_time < end_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_item_availability_org ON item_availability(organization_id, item_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS item_availability;
+-- +goose StatementEnd
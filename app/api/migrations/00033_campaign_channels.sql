@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- where a campaign runs and the code customers use to redeem it
+ALTER TABLE marketing_campaigns ADD COLUMN channel VARCHAR(20) NOT NULL DEFAULT 'in_store' CHECK (channel IN ('in_store','app','delivery_platform'));
+ALTER TABLE marketing_campaigns ADD COLUMN redemption_code VARCHAR(32);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_marketing_campaigns_redemption_code ON marketing_campaigns(organization_id, UPPER(redemption_code)) WHERE redemption_code IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS campaign_redemptions (
+    campaign_id UUID NOT NULL REFERENCES marketing_campaigns(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    redeemed_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (campaign_id, order_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS campaign_redemptions;
+DROP INDEX IF EXISTS idx_marketing_campaigns_redemption_code;
+ALTER TABLE marketing_campaigns DROP COLUMN redemption_code;
+ALTER TABLE marketing_campaigns DROP COLUMN channel;
+-- +goose StatementEnd
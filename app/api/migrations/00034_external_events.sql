@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- events around the organization that move demand (concerts, matches, road closures, competitor openings)
+CREATE TABLE IF NOT EXISTS external_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    category VARCHAR(32) NOT NULL DEFAULT 'other'
+        CHECK (category IN ('concert', 'sports', 'festival', 'road_closure', 'competitor', 'other')),
+    description TEXT,
+    location VARCHAR(255),
+    start_time TIMESTAMP NOT NULL,
+    end_time TIMESTAMP NOT NULL,
+    expected_attendance INTEGER CHECK (expected_attendance IS NULL OR expected_attendance >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (start_time < end_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_external_events_org_time ON external_events(organization_id, start_time, end_time);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS external_events;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+-- open positions, optionally created from a hiring recommendation of the schedule insights
+CREATE TABLE IF NOT EXISTS job_postings (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    role VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    openings INTEGER NOT NULL DEFAULT 1 CHECK (openings > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+    source VARCHAR(20) NOT NULL DEFAULT 'manual' CHECK (source IN ('manual', 'recommendation')),
+    recommendation_reason TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_postings_org ON job_postings(organization_id, status);
+
+-- people who applied to a posting through the public intake, moved through the hiring pipeline
+CREATE TABLE IF NOT EXISTS job_applicants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    posting_id UUID NOT NULL REFERENCES job_postings(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    full_name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    phone VARCHAR(50),
+    message TEXT,
+    stage VARCHAR(20) NOT NULL DEFAULT 'applied'
+        CHECK (stage IN ('applied', 'screening', 'interview', 'trial', 'offer', 'hired', 'rejected')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_job_applicants_posting_email ON job_applicants(posting_id, LOWER(email));
+CREATE INDEX IF NOT EXISTS idx_job_applicants_org ON job_applicants(organization_id, stage);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS job_applicants;
+DROP TABLE IF EXISTS job_postings;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- interviews and trial shifts of applicants, shown on the schedule grid as non-productive time
+CREATE TABLE IF NOT EXISTS applicant_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    applicant_id UUID NOT NULL REFERENCES job_applicants(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('interview', 'trial')),
+    manager_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    session_date DATE NOT NULL,
+    start_hour TIME NOT NULL,
+    end_hour TIME NOT NULL,
+    location VARCHAR(255),
+    notes TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_hour > start_hour)
+);
+
+CREATE INDEX IF NOT EXISTS idx_applicant_sessions_org_date ON applicant_sessions(organization_id, session_date);
+CREATE INDEX IF NOT EXISTS idx_applicant_sessions_manager_date ON applicant_sessions(manager_id, session_date);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS applicant_sessions;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- confidential notes and disciplinary actions about an employee, only visible to admins and the manager who wrote them.
+-- Records are purged once retain_until has passed.
+CREATE TABLE IF NOT EXISTS employee_records (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    employee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('note', 'warning', 'write_up', 'commendation')),
+    title VARCHAR(255) NOT NULL,
+    details TEXT,
+    retain_until DATE NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_employee_records_employee ON employee_records(organization_id, employee_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_employee_records_retain_until ON employee_records(retain_until);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS employee_records;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- days the organization is closed despite its weekly operating hours (public holidays, renovations)
+CREATE TABLE IF NOT EXISTS organizations_operating_hours_exceptions (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    exception_date DATE NOT NULL,
+    reason VARCHAR(255),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, exception_date)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS organizations_operating_hours_exceptions;
+-- +goose StatementEnd
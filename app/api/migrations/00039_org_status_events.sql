@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- health signals of the API per organization (ingestion lag, schedule generations, email failures, ML latency)
+CREATE TABLE IF NOT EXISTS org_status_events (
+    id UUID PRIMARY KEY,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    kind VARCHAR(30) NOT NULL CHECK (kind IN ('ingestion', 'schedule_generation', 'email_failure', 'ml_request')),
+    source VARCHAR(100) NOT NULL,
+    duration_ms BIGINT,
+    failed BOOLEAN NOT NULL DEFAULT FALSE,
+    detail TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_org_status_events_org_kind ON org_status_events(organization_id, kind, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS org_status_events;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- sanity checks an organization applies to the rows it imports, e.g. orders over 2000 or ratings outside 1-5
+CREATE TABLE IF NOT EXISTS ingestion_rules (
+    id UUID PRIMARY KEY,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    dataset VARCHAR(20) NOT NULL CHECK (dataset IN ('orders', 'order_items', 'deliveries', 'items')),
+    field VARCHAR(100) NOT NULL,
+    operator VARCHAR(20) NOT NULL CHECK (operator IN ('required', 'min', 'max', 'one_of')),
+    value VARCHAR(255) NOT NULL DEFAULT '',
+    message VARCHAR(255),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingestion_rules_org_dataset ON ingestion_rules(organization_id, dataset);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS ingestion_rules;
+-- +goose StatementEnd
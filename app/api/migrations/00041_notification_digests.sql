@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- how each user wants to receive notifications, immediately or grouped in an hourly or daily digest email
+CREATE TABLE IF NOT EXISTS users_notification_settings (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    digest_mode VARCHAR(10) NOT NULL DEFAULT 'immediate' CHECK (digest_mode IN ('immediate', 'hourly', 'daily')),
+    last_digest_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- notifications waiting for the next digest, the same event repeated is kept once with a count
+CREATE TABLE IF NOT EXISTS pending_notifications (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    dedup_key VARCHAR(255) NOT NULL,
+    summary TEXT NOT NULL,
+    occurrences INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, dedup_key)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS pending_notifications;
+DROP TABLE IF EXISTS users_notification_settings;
+-- +goose StatementEnd
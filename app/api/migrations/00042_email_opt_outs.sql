@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- categories of non-critical emails an address no longer wants, set from the preferences or an unsubscribe link
+CREATE TABLE IF NOT EXISTS email_opt_outs (
+    email VARCHAR(255) NOT NULL,
+    category VARCHAR(20) NOT NULL CHECK (category IN ('reminders', 'digests', 'marketing')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (email, category)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS email_opt_outs;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- logo of each organization for its dashboard, emails and PDF exports, the brand colors are the hex codes of organizations
+CREATE TABLE IF NOT EXISTS organization_logos (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    content_type VARCHAR(50) NOT NULL,
+    data BYTEA NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS organization_logos;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- where an order was placed, orders imported from CSV or the API are direct
+ALTER TABLE orders ADD COLUMN channel VARCHAR(20) NOT NULL DEFAULT 'direct'
+    CHECK (channel IN ('direct', 'uber_eats', 'deliveroo', 'talabat'));
+
+-- connections of an organization to the delivery platforms it sells on. Webhooks are verified with
+-- webhook_secret, platforms without webhooks are polled at api_url with api_token.
+CREATE TABLE IF NOT EXISTS delivery_platform_integrations (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    platform VARCHAR(20) NOT NULL CHECK (platform IN ('uber_eats', 'deliveroo', 'talabat')),
+    store_id VARCHAR(100) NOT NULL,
+    webhook_secret TEXT NOT NULL,
+    api_url TEXT,
+    api_token TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    last_polled_at TIMESTAMP,
+    last_event_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, platform)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS delivery_platform_integrations;
+ALTER TABLE orders DROP COLUMN IF EXISTS channel;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- orders taken at the till, on the phone or in the organization's own app are told apart from the
+-- other direct orders, so channel breakdowns cover every source
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_channel_check;
+ALTER TABLE orders ADD CONSTRAINT orders_channel_check
+    CHECK (channel IN ('direct', 'pos', 'phone', 'app', 'uber_eats', 'deliveroo', 'talabat'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE orders SET channel = 'direct' WHERE channel IN ('pos', 'phone', 'app');
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_channel_check;
+ALTER TABLE orders ADD CONSTRAINT orders_channel_check
+    CHECK (channel IN ('direct', 'uber_eats', 'deliveroo', 'talabat'));
+-- +goose StatementEnd
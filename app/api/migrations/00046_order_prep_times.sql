@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- when the kitchen finished an order and each of its items, bumped on the kitchen display or sent by the
+-- POS. An order is prepared once all its items are.
+ALTER TABLE orders ADD COLUMN prepared_at TIMESTAMP;
+ALTER TABLE order_items ADD COLUMN prepared_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_orders_org_prepared_at ON orders(organization_id, prepared_at) WHERE prepared_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_orders_org_prepared_at;
+ALTER TABLE order_items DROP COLUMN IF EXISTS prepared_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS prepared_at;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- alerts raised during the day when the orders so far drift from the demand forecast, kept to measure
+-- later how often the forecast and the alerts were right
+CREATE TABLE IF NOT EXISTS forecast_variance_alerts (
+    id UUID PRIMARY KEY,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    alert_date DATE NOT NULL,
+    through_hour INTEGER NOT NULL CHECK (through_hour BETWEEN 1 AND 24),
+    forecast_orders INTEGER NOT NULL,
+    actual_orders INTEGER NOT NULL,
+    variance_percent NUMERIC(7, 2) NOT NULL,
+    direction VARCHAR(10) NOT NULL CHECK (direction IN ('above', 'below')),
+    suggestion VARCHAR(10) NOT NULL CHECK (suggestion IN ('call_in', 'send_home')),
+    staff_on_shift INTEGER NOT NULL DEFAULT 0,
+    suggested_staff_change INTEGER NOT NULL DEFAULT 0,
+    notified_managers INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_forecast_variance_alerts_org_date ON forecast_variance_alerts(organization_id, alert_date);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS forecast_variance_alerts;
+-- +goose StatementEnd
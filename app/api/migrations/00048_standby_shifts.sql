@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- on-call shifts: standby shifts are paid standby_pay_percent of the hourly salary until a manager
+-- activates them, which turns them into working shifts
+ALTER TABLE schedules ADD COLUMN id UUID NOT NULL DEFAULT uuid_generate_v4();
+ALTER TABLE schedules ADD COLUMN shift_type VARCHAR(10) NOT NULL DEFAULT 'working' CHECK (shift_type IN ('working', 'standby'));
+ALTER TABLE schedules ADD COLUMN activated_at TIMESTAMPTZ;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_schedules_id ON schedules (id);
+ALTER TABLE organizations_rules ADD COLUMN standby_pay_percent INTEGER NOT NULL DEFAULT 25 CHECK (standby_pay_percent BETWEEN 0 AND 100);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE organizations_rules DROP COLUMN standby_pay_percent;
+DROP INDEX IF EXISTS idx_schedules_id;
+ALTER TABLE schedules DROP COLUMN IF EXISTS activated_at;
+ALTER TABLE schedules DROP COLUMN IF EXISTS shift_type;
+ALTER TABLE schedules DROP COLUMN IF EXISTS id;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- peak periods when holiday requests are refused, or flagged so that only an admin can approve them
+CREATE TABLE IF NOT EXISTS time_off_blackouts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    action VARCHAR(10) NOT NULL DEFAULT 'reject' CHECK (action IN ('reject', 'flag')),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_date >= start_date)
+);
+CREATE INDEX IF NOT EXISTS idx_time_off_blackouts_org_dates ON time_off_blackouts (organization_id, start_date, end_date);
+
+-- the days a holiday request is for, and the blackout it was flagged for
+ALTER TABLE requests ADD COLUMN start_date DATE;
+ALTER TABLE requests ADD COLUMN end_date DATE;
+ALTER TABLE requests ADD COLUMN blackout_id UUID REFERENCES time_off_blackouts(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE requests DROP COLUMN IF EXISTS blackout_id;
+ALTER TABLE requests DROP COLUMN IF EXISTS end_date;
+ALTER TABLE requests DROP COLUMN IF EXISTS start_date;
+DROP TABLE IF EXISTS time_off_blackouts;
+-- +goose StatementEnd
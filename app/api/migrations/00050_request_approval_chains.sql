@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the roles that must approve each request type in order, request types without a chain use the
+-- default one (a manager, then an admin for resignations)
+CREATE TABLE IF NOT EXISTS request_approval_chains (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    request_type VARCHAR(20) NOT NULL CHECK (request_type IN ('calloff', 'holiday', 'resign')),
+    steps VARCHAR(10)[] NOT NULL CHECK (CARDINALITY(steps) > 0 AND steps <@ ARRAY['manager', 'admin']::VARCHAR(10)[]),
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, request_type)
+);
+
+-- the steps of the chain of each request, snapshotted when the request is submitted, with their decisions
+CREATE TABLE IF NOT EXISTS request_approvals (
+    request_id UUID NOT NULL REFERENCES requests(request_id) ON DELETE CASCADE,
+    step INTEGER NOT NULL CHECK (step > 0),
+    approver_role VARCHAR(10) NOT NULL CHECK (approver_role IN ('manager', 'admin')),
+    decision VARCHAR(10) CHECK (decision IN ('approved', 'declined')),
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ,
+    PRIMARY KEY (request_id, step)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS request_approvals;
+DROP TABLE IF EXISTS request_approval_chains;
+-- +goose StatementEnd
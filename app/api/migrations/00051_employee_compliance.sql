@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- emergency contact, identity and payroll details of an employee. Phone, document and bank numbers
+-- are sealed by the API when column encryption is enabled.
+CREATE TABLE IF NOT EXISTS employee_compliance (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    emergency_contact_name VARCHAR(100),
+    emergency_contact_relationship VARCHAR(50),
+    emergency_contact_phone TEXT,
+    national_id TEXT,
+    national_id_expiry DATE,
+    work_permit_number TEXT,
+    work_permit_expiry DATE,
+    bank_account_holder TEXT,
+    bank_name VARCHAR(100),
+    bank_iban TEXT,
+    -- smallest number of days before expiry the employee and admins were alerted at, reset when
+    -- the expiry date changes
+    national_id_alerted_days INT,
+    work_permit_alerted_days INT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_employee_compliance_national_id_expiry ON employee_compliance (national_id_expiry);
+CREATE INDEX IF NOT EXISTS idx_employee_compliance_work_permit_expiry ON employee_compliance (work_permit_expiry);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS employee_compliance;
+-- +goose StatementEnd
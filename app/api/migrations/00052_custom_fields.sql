@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- attributes an organization adds to its employees, items or orders
+CREATE TABLE IF NOT EXISTS custom_field_definitions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    entity_type VARCHAR(10) NOT NULL CHECK (entity_type IN ('employee', 'item', 'order')),
+    key VARCHAR(50) NOT NULL,
+    label VARCHAR(100) NOT NULL,
+    field_type VARCHAR(10) NOT NULL CHECK (field_type IN ('text', 'number', 'boolean', 'date', 'select')),
+    options TEXT[] NOT NULL DEFAULT '{}',
+    required BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (organization_id, entity_type, key)
+);
+
+-- values of the custom fields of an employee, item or order, keyed by field key
+CREATE TABLE IF NOT EXISTS custom_field_values (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    entity_type VARCHAR(10) NOT NULL CHECK (entity_type IN ('employee', 'item', 'order')),
+    entity_id UUID NOT NULL,
+    "values" JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, entity_type, entity_id)
+);
+CREATE INDEX IF NOT EXISTS idx_custom_field_values_values ON custom_field_values USING GIN ("values");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS custom_field_values;
+DROP TABLE IF EXISTS custom_field_definitions;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- named filter sets of a list endpoint, private to their creator or shared with the organization
+CREATE TABLE IF NOT EXISTS saved_views (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    created_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    resource VARCHAR(30) NOT NULL,
+    -- query parameters applied to the list endpoint of the resource
+    filters JSONB NOT NULL DEFAULT '{}',
+    shared BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (organization_id, created_by, resource, name)
+);
+CREATE INDEX IF NOT EXISTS idx_saved_views_org_resource ON saved_views (organization_id, resource);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS saved_views;
+-- +goose StatementEnd
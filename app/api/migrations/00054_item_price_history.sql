@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- every price change of an item, with who made it and why, e.g. a VAT change
+CREATE TABLE IF NOT EXISTS item_price_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    item_id UUID NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    old_price DECIMAL(10,2) NOT NULL,
+    new_price DECIMAL(10,2) NOT NULL CHECK (new_price >= 0.0),
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reason VARCHAR(200),
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_item_price_history_item ON item_price_history (item_id, changed_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS item_price_history;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- append-only history of the shifts: every change to the schedules table adds an event holding the shift
+-- as it is after the change (as it was, for cancelled shifts). Events are never updated or deleted.
+-- swapped is for shifts handed over to another employee.
+CREATE TABLE IF NOT EXISTS schedule_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    shift_id UUID NOT NULL,
+    event_type VARCHAR(20) NOT NULL CHECK (event_type IN ('generated', 'created', 'edited', 'swapped', 'cancelled')),
+    employee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    schedule_date DATE NOT NULL,
+    start_hour TIME NOT NULL,
+    end_hour TIME NOT NULL,
+    shift_type VARCHAR(10) NOT NULL,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedule_events_shift ON schedule_events(shift_id, occurred_at);
+
+-- shifts published before the history existed start with their generation
+INSERT INTO schedule_events (organization_id, shift_id, event_type, employee_id, schedule_date, start_hour, end_hour, shift_type, occurred_at)
+SELECT u.organization_id, s.id, 'generated', s.employee_id, s.schedule_date, s.start_hour, s.end_hour, s.shift_type, s.published_at
+FROM schedules s
+INNER JOIN users u ON s.employee_id = u.id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS schedule_events;
+-- +goose StatementEnd
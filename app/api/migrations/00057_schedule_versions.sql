@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- one row per generated schedule, with how well it matched the preferences of the employees:
+-- preferred_shifts are the shifts inside the preferred hours of their day. The percent only counts
+-- employees who submitted preferences and is NULL when none did.
+CREATE TABLE IF NOT EXISTS schedule_versions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL,
+    generated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    week_start DATE NOT NULL,
+    shifts INT NOT NULL,
+    preferred_shifts INT NOT NULL,
+    preference_satisfaction_percent NUMERIC(5, 2),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedule_versions_org ON schedule_versions(organization_id, created_at);
+
+CREATE TABLE IF NOT EXISTS schedule_version_employees (
+    version_id UUID NOT NULL REFERENCES schedule_versions(id) ON DELETE CASCADE,
+    employee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    shifts INT NOT NULL,
+    preferred_shifts INT NOT NULL,
+    satisfaction_percent NUMERIC(5, 2),
+    PRIMARY KEY (version_id, employee_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS schedule_version_employees;
+DROP TABLE IF EXISTS schedule_versions;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- published shifts cancelled by a manager: cancellations with less than cancellation_notice_hours before
+-- the shift starts are late and kept for compliance reporting. The shift itself is deleted, so its
+-- times are copied here.
+ALTER TABLE organizations_rules ADD COLUMN cancellation_notice_hours INTEGER NOT NULL DEFAULT 24 CHECK (cancellation_notice_hours >= 0);
+
+CREATE TABLE IF NOT EXISTS shift_cancellations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    shift_id UUID NOT NULL,
+    employee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    schedule_date DATE NOT NULL,
+    start_hour TIME NOT NULL,
+    end_hour TIME NOT NULL,
+    shift_type VARCHAR(10) NOT NULL,
+    reason TEXT NOT NULL,
+    cancelled_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    notice_hours NUMERIC(8, 2) NOT NULL,
+    required_notice_hours INTEGER NOT NULL,
+    late BOOLEAN NOT NULL,
+    offers_created INTEGER NOT NULL DEFAULT 0,
+    cancelled_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_shift_cancellations_org ON shift_cancellations(organization_id, cancelled_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS shift_cancellations;
+ALTER TABLE organizations_rules DROP COLUMN IF EXISTS cancellation_notice_hours;
+-- +goose StatementEnd
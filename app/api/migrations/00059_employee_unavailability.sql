@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- days an employee cannot be scheduled, recorded when their holiday or call-off request is approved.
+-- Unlike preferences, which repeat every week, they cover dates.
+CREATE TABLE IF NOT EXISTS employee_unavailability (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    employee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    request_id UUID NOT NULL UNIQUE REFERENCES requests(request_id) ON DELETE CASCADE,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('holiday', 'calloff')),
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_employee_unavailability_employee ON employee_unavailability(employee_id, end_date);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS employee_unavailability;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- organization ratings recomputed from the ratings of recent orders, the latest one is also copied to
+-- organizations.rating, which is what the ML service sees
+CREATE TABLE IF NOT EXISTS organization_rating_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    rating DECIMAL(10,2) NOT NULL,
+    rated_orders INTEGER NOT NULL CHECK (rated_orders > 0),
+    window_days INTEGER NOT NULL,
+    half_life_days INTEGER NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_rating_history_org ON organization_rating_history(organization_id, computed_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS organization_rating_history;
+-- +goose StatementEnd
@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+-- shared tablets at the restaurant, authenticated by a device token bound to one organization. Only the
+-- SHA-256 of the token is kept, the token itself is shown once when the device is registered.
+CREATE TABLE IF NOT EXISTS kiosk_devices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_kiosk_devices_org ON kiosk_devices(organization_id);
+
+-- the PIN an employee clocks in with on the kiosks of an organization, gone with the membership
+CREATE TABLE IF NOT EXISTS kiosk_pins (
+    user_id UUID NOT NULL,
+    organization_id UUID NOT NULL,
+    pin_hash TEXT NOT NULL,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, organization_id),
+    FOREIGN KEY (user_id, organization_id) REFERENCES organization_memberships(user_id, organization_id) ON DELETE CASCADE
+);
+
+-- worked time clocked on a kiosk, an employee has at most one open entry per organization
+CREATE TABLE IF NOT EXISTS time_clock_entries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    employee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_id UUID REFERENCES kiosk_devices(id) ON DELETE SET NULL,
+    clock_in_at TIMESTAMPTZ NOT NULL,
+    clock_out_at TIMESTAMPTZ,
+    CHECK (clock_out_at IS NULL OR clock_out_at >= clock_in_at)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_time_clock_entries_open ON time_clock_entries(organization_id, employee_id) WHERE clock_out_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_time_clock_entries_org ON time_clock_entries(organization_id, clock_in_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS time_clock_entries;
+DROP TABLE IF EXISTS kiosk_pins;
+DROP TABLE IF EXISTS kiosk_devices;
+-- +goose StatementEnd
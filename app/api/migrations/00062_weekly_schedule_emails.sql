@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- weeks an employee was already emailed their shifts for, so the Sunday email goes out once per week
+CREATE TABLE IF NOT EXISTS weekly_schedule_emails (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    week_start DATE NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, week_start)
+);
+
+-- the week-at-a-glance email can be unsubscribed from like the other non-critical emails
+ALTER TABLE email_opt_outs DROP CONSTRAINT IF EXISTS email_opt_outs_category_check;
+ALTER TABLE email_opt_outs ADD CONSTRAINT email_opt_outs_category_check
+    CHECK (category IN ('reminders', 'digests', 'marketing', 'weekly_summary'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM email_opt_outs WHERE category = 'weekly_summary';
+ALTER TABLE email_opt_outs DROP CONSTRAINT IF EXISTS email_opt_outs_category_check;
+ALTER TABLE email_opt_outs ADD CONSTRAINT email_opt_outs_category_check
+    CHECK (category IN ('reminders', 'digests', 'marketing'));
+DROP TABLE IF EXISTS weekly_schedule_emails;
+-- +goose StatementEnd
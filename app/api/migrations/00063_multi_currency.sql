@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the currency the organization's prices, revenue and salaries are in, as an ISO 4217 code
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD'
+    CHECK (currency ~ '^[A-Z]{3}$');
+
+-- daily exchange rates fetched from the rate provider, units of quote_currency per unit of base_currency
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    rate_date DATE NOT NULL,
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    fetched_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (rate_date, base_currency, quote_currency)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS exchange_rates;
+ALTER TABLE organizations DROP COLUMN IF EXISTS currency;
+-- +goose StatementEnd
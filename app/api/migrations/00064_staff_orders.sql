@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- orders eaten by employees, free staff meals or orders with an employee discount. They are left out of the
+-- revenue insights and reported per employee for payroll deductions.
+CREATE TABLE IF NOT EXISTS staff_orders (
+    order_id UUID PRIMARY KEY REFERENCES orders(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    employee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('staff_meal', 'employee_discount')),
+    tagged_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    tagged_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_staff_orders_employee ON staff_orders(organization_id, employee_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS staff_orders;
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the offer and termination letter templates of an organization, the built-in templates are used for the
+-- kinds without one
+CREATE TABLE IF NOT EXISTS document_templates (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    kind VARCHAR(30) NOT NULL CHECK (kind IN ('offer_letter', 'termination_letter')),
+    title VARCHAR(200) NOT NULL,
+    body TEXT NOT NULL,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, kind)
+);
+
+-- letters generated for an employee and their electronic signature. Documents outlive the employee's
+-- account, a layoff deletes it, so the name and email of the employee are kept with the document. Only the
+-- SHA-256 of the signing token is kept, the token itself is emailed to the employee.
+CREATE TABLE IF NOT EXISTS employee_documents (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    employee_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    employee_name VARCHAR(255) NOT NULL,
+    employee_email VARCHAR(255) NOT NULL,
+    kind VARCHAR(30) NOT NULL CHECK (kind IN ('offer_letter', 'termination_letter')),
+    title VARCHAR(200) NOT NULL,
+    body TEXT NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sign_token_hash CHAR(64) NOT NULL UNIQUE,
+    sign_expires_at TIMESTAMPTZ NOT NULL,
+    signed_at TIMESTAMPTZ,
+    signer_name VARCHAR(255),
+    signer_ip VARCHAR(45),
+    signed_pdf BYTEA,
+    signed_sha256 CHAR(64),
+    CHECK (signed_at IS NULL OR (signer_name IS NOT NULL AND signed_pdf IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_employee_documents_employee ON employee_documents(organization_id, employee_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS employee_documents;
+DROP TABLE IF EXISTS document_templates;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- notes managers leave for the next shift, open until someone resolves them. Low stock notes name the item
+-- running out and are listed as low stock alerts in the shift handover report.
+CREATE TABLE IF NOT EXISTS shift_notes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('note', 'low_stock')),
+    item_id UUID REFERENCES items(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_shift_notes_unresolved ON shift_notes(organization_id) WHERE resolved_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS shift_notes;
+-- +goose StatementEnd
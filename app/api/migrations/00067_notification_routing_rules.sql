@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- where the notifications of an organization go, e.g. calloffs less than 4 hours before the shift are texted
+-- to the manager on duty. Rules are tried by position and the first match wins, without a match managers
+-- and admins are emailed.
+CREATE TABLE IF NOT EXISTS notification_routing_rules (
+    id UUID PRIMARY KEY,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    event VARCHAR(20) NOT NULL CHECK (event IN ('calloff', 'holiday', 'resign')),
+    within_hours INT CHECK (within_hours > 0),
+    channel VARCHAR(10) NOT NULL CHECK (channel IN ('email', 'sms')),
+    recipients VARCHAR(30) NOT NULL CHECK (recipients IN ('on_duty_manager', 'managers', 'admins', 'managers_and_admins')),
+    position INT NOT NULL DEFAULT 0,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_routing_rules_org ON notification_routing_rules(organization_id, event);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notification_routing_rules;
+-- +goose StatementEnd
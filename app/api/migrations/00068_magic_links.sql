@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- organizations opt in to passwordless login with links emailed to their members
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS magic_link_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- single-use login links, only the SHA-256 of the token is stored. The links issued in the last hour are
+-- counted to rate limit the requests per account and per IP address.
+CREATE TABLE IF NOT EXISTS magic_links (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    ip_address VARCHAR(45) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_magic_links_user_created ON magic_links(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_magic_links_ip_created ON magic_links(ip_address, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS magic_links;
+ALTER TABLE organizations DROP COLUMN IF EXISTS magic_link_enabled;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- wall displays showing the schedule of the day and the live order counts, each authenticated by a token
+-- bound to one organization. Only the SHA-256 of the token is kept, the token is shown once.
+CREATE TABLE IF NOT EXISTS status_boards (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_status_boards_org ON status_boards(organization_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS status_boards;
+-- +goose StatementEnd
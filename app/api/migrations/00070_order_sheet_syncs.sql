@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Google Sheets an organization keeps its order log in, pulled periodically and imported like an orders
+-- CSV upload. column_mapping maps the columns of an orders CSV to the headers of the sheet, the columns it
+-- leaves out are read from headers of the same name. credentials is a service account key for sheets
+-- that are not shared by link, sealed like the other secrets. rows_imported counts the rows already read.
+CREATE TABLE IF NOT EXISTS order_sheet_syncs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    sheet_url TEXT NOT NULL,
+    credentials TEXT,
+    column_mapping JSONB NOT NULL DEFAULT '{}',
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    rows_imported INTEGER NOT NULL DEFAULT 0,
+    last_synced_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_sheet_syncs_org ON order_sheet_syncs(organization_id);
+
+-- the result of each sync of a sheet
+CREATE TABLE IF NOT EXISTS order_sheet_sync_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    sync_id UUID NOT NULL REFERENCES order_sheet_syncs(id) ON DELETE CASCADE,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('success', 'failed')),
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    success_count INTEGER NOT NULL DEFAULT 0,
+    error_count INTEGER NOT NULL DEFAULT 0,
+    duplicate_count INTEGER NOT NULL DEFAULT 0,
+    rejected_count INTEGER NOT NULL DEFAULT 0,
+    error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_sheet_sync_runs_sync ON order_sheet_sync_runs(sync_id, started_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS order_sheet_sync_runs;
+DROP TABLE IF EXISTS order_sheet_syncs;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- predictable scheduling laws: changes made by the organization to a shift less than
+-- predictability_notice_days before it starts owe the employee predictability pay, left NULL for
+-- organizations not subject to such a law. Added or moved shifts owe predictability_pay_hours of pay,
+-- cut hours owe predictability_lost_hours_percent of the hours lost.
+ALTER TABLE organizations_rules ADD COLUMN predictability_notice_days INTEGER CHECK (predictability_notice_days BETWEEN 1 AND 60);
+ALTER TABLE organizations_rules ADD COLUMN predictability_pay_hours NUMERIC(4, 2) NOT NULL DEFAULT 1 CHECK (predictability_pay_hours >= 0);
+ALTER TABLE organizations_rules ADD COLUMN predictability_lost_hours_percent INTEGER NOT NULL DEFAULT 50 CHECK (predictability_lost_hours_percent BETWEEN 0 AND 100);
+
+-- weeks the admins of an organization were already sent the compliance report for
+CREATE TABLE IF NOT EXISTS compliance_report_emails (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    week_start DATE NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, week_start)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS compliance_report_emails;
+ALTER TABLE organizations_rules DROP COLUMN IF EXISTS predictability_lost_hours_percent;
+ALTER TABLE organizations_rules DROP COLUMN IF EXISTS predictability_pay_hours;
+ALTER TABLE organizations_rules DROP COLUMN IF EXISTS predictability_notice_days;
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+-- skills and certifications declared by employees, which only count towards scheduling once a manager
+-- verified them. Proposing a skill again after a review sends it back for verification.
+CREATE TABLE IF NOT EXISTS employee_skills (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    employee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL DEFAULT 'skill' CHECK (kind IN ('skill', 'certification')),
+    expires_on DATE,
+    note TEXT,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'verified', 'rejected')),
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ,
+    review_note TEXT,
+    -- set once the employee and the managers were reminded the verification is about to expire
+    expiry_reminded_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_employee_skills_name ON employee_skills (employee_id, (LOWER(name)));
+CREATE INDEX IF NOT EXISTS idx_employee_skills_org_status ON employee_skills (organization_id, status);
+CREATE INDEX IF NOT EXISTS idx_employee_skills_expiry ON employee_skills (expires_on) WHERE status = 'verified' AND expiry_reminded_at IS NULL;
+
+-- skills an employee needs verified to be scheduled in a role
+CREATE TABLE IF NOT EXISTS role_skill_requirements (
+    organization_id UUID NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    skills TEXT[] NOT NULL,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, role),
+    FOREIGN KEY (organization_id, role) REFERENCES organizations_roles(organization_id, role) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS role_skill_requirements;
+DROP TABLE IF EXISTS employee_skills;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- messages sent by admins to a filtered group of the staff. filters keeps the targeting the recipients
+-- were picked with, as they were when the broadcast was sent.
+CREATE TABLE IF NOT EXISTS notification_broadcasts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    sent_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    title VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL,
+    channels TEXT[] NOT NULL,
+    filters JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_broadcasts_org ON notification_broadcasts(organization_id, created_at);
+
+-- one row per recipient and channel, updated as the broadcast is sent
+CREATE TABLE IF NOT EXISTS notification_broadcast_deliveries (
+    broadcast_id UUID NOT NULL REFERENCES notification_broadcasts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel VARCHAR(10) NOT NULL CHECK (channel IN ('email', 'sms')),
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    error TEXT,
+    sent_at TIMESTAMPTZ,
+    PRIMARY KEY (broadcast_id, user_id, channel)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notification_broadcast_deliveries;
+DROP TABLE IF EXISTS notification_broadcasts;
+-- +goose StatementEnd
@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+-- large order history imports, staged before they reach the orders table. The rows of an import are
+-- checked and deduplicated in bulk, then promoted together or discarded.
+CREATE TABLE IF NOT EXISTS order_imports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    file_name VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(10) NOT NULL DEFAULT 'staged' CHECK (status IN ('staged', 'promoted', 'discarded')),
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    valid_rows INTEGER NOT NULL DEFAULT 0,
+    invalid_rows INTEGER NOT NULL DEFAULT 0,
+    duplicate_rows INTEGER NOT NULL DEFAULT 0,
+    promoted_rows INTEGER NOT NULL DEFAULT 0,
+    redemption_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    promoted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_imports_org ON order_imports(organization_id, created_at);
+
+-- the staging area of the imports of every organization, one row per line of the file. Typed columns
+-- are NULL when the value could not be parsed, error telling why. Values are kept loose so that no row
+-- fails to stage, the constraints of orders are checked before promotion.
+CREATE TABLE IF NOT EXISTS order_import_rows (
+    import_id UUID NOT NULL REFERENCES order_imports(id) ON DELETE CASCADE,
+    line INTEGER NOT NULL,
+    order_id UUID,
+    user_id UUID,
+    create_time TIMESTAMP,
+    order_type TEXT,
+    order_status TEXT,
+    total_amount DOUBLE PRECISION,
+    discount_amount DOUBLE PRECISION,
+    rating DOUBLE PRECISION,
+    channel TEXT,
+    redemption_code TEXT,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'valid', 'invalid', 'duplicate')),
+    error TEXT,
+    PRIMARY KEY (import_id, line)
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_import_rows_order ON order_import_rows(import_id, order_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS order_import_rows;
+DROP TABLE IF EXISTS order_imports;
+-- +goose StatementEnd
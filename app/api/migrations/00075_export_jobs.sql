@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- exports of the history of an organization, produced to object storage in the background because they
+-- take too long to answer over HTTP. A job is claimed by one instance of the API at a time, a job left
+-- running by an instance that stopped is claimed again once its lease has passed. The file is deleted
+-- once the job expires.
+CREATE TABLE IF NOT EXISTS export_jobs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    entity VARCHAR(20) NOT NULL CHECK (entity IN ('orders', 'deliveries')),
+    from_date DATE NOT NULL,
+    to_date DATE NOT NULL,
+    format VARCHAR(10) NOT NULL CHECK (format IN ('csv', 'xlsx')),
+    status VARCHAR(10) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'completed', 'failed', 'expired')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    file_key TEXT,
+    row_count INTEGER,
+    size_bytes BIGINT,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ,
+    CHECK (from_date <= to_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_export_jobs_org ON export_jobs(organization_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_export_jobs_pending ON export_jobs(created_at) WHERE status IN ('queued', 'running');
+CREATE INDEX IF NOT EXISTS idx_export_jobs_expiry ON export_jobs(expires_at) WHERE status = 'completed';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS export_jobs;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- orders taken live move from pending through preparing and ready to completed, or are cancelled.
+-- incompleted stays the status of the open orders of imports and delivery platforms. The column was too
+-- short for 'incompleted'.
+ALTER TABLE orders ALTER COLUMN order_status TYPE VARCHAR(20);
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_order_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_order_status_check
+    CHECK (order_status IN ('pending', 'preparing', 'ready', 'completed', 'cancelled', 'incompleted'));
+
+-- every status change made through the lifecycle, for auditing. changed_by is NULL once the user is
+-- deleted.
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    note TEXT,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_history_order ON order_status_history(order_id, changed_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS order_status_history;
+UPDATE orders SET order_status = 'incompleted' WHERE order_status IN ('pending', 'preparing', 'ready', 'cancelled');
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_order_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_order_status_check
+    CHECK (order_status IN ('completed', 'incompleted'));
+-- +goose StatementEnd
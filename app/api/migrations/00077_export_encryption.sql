@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- how the exports and emailed reports of an organization are encrypted, with the OpenPGP public key of
+-- the organization or a shared passphrase. The passphrase is sealed when column encryption is enabled.
+CREATE TABLE IF NOT EXISTS organization_export_encryption (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    mode VARCHAR(20) NOT NULL CHECK (mode IN ('pgp', 'passphrase')),
+    public_key TEXT,
+    key_fingerprint VARCHAR(64),
+    passphrase TEXT,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((mode = 'pgp' AND public_key IS NOT NULL) OR (mode = 'passphrase' AND passphrase IS NOT NULL))
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS organization_export_encryption;
+-- +goose StatementEnd
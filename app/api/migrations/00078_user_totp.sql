@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- authenticator app secrets of users, used to step up before sensitive actions. The secret is sealed when
+-- column encryption is enabled. A secret is pending until the user confirms a first code, which sets
+-- enabled_at. last_used_counter is the period of the last accepted code, so codes cannot be replayed.
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    enabled_at TIMESTAMPTZ,
+    last_used_counter BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_totp;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- employees removed after an accepted resignation are recorded as a resignation rather than a layoff, and
+-- every exit keeps the hire date of the deleted user for the tenure of the turnover report
+ALTER TABLE layoffs_hirings DROP CONSTRAINT IF EXISTS layoffs_hirings_action_check;
+ALTER TABLE layoffs_hirings ADD CONSTRAINT layoffs_hirings_action_check CHECK (action IN ('layoff', 'hiring', 'resignation'));
+ALTER TABLE layoffs_hirings ADD COLUMN IF NOT EXISTS hired_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_layoffs_hirings_org_action_date ON layoffs_hirings(organization_id, action_date);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_layoffs_hirings_org_action_date;
+ALTER TABLE layoffs_hirings DROP COLUMN IF EXISTS hired_at;
+UPDATE layoffs_hirings SET action = 'layoff' WHERE action = 'resignation';
+ALTER TABLE layoffs_hirings DROP CONSTRAINT IF EXISTS layoffs_hirings_action_check;
+ALTER TABLE layoffs_hirings ADD CONSTRAINT layoffs_hirings_action_check CHECK (action IN ('layoff', 'hiring'));
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- organizations can let order acceptance close by itself when the demand of the current hour is far above
+-- what the staff on shift can handle, each employee handling orders_per_staff_hour orders an hour. Orders
+-- close above auto_close_load_percent of that capacity and reopen at auto_reopen_load_percent or below.
+-- orders_auto_closed_at is set while orders are closed by the controller, it only reopens what it closed.
+ALTER TABLE organizations_rules ADD COLUMN auto_close_orders BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE organizations_rules ADD COLUMN orders_per_staff_hour INTEGER NOT NULL DEFAULT 10 CHECK (orders_per_staff_hour > 0);
+ALTER TABLE organizations_rules ADD COLUMN auto_close_load_percent INTEGER NOT NULL DEFAULT 150 CHECK (auto_close_load_percent > 0);
+ALTER TABLE organizations_rules ADD COLUMN auto_reopen_load_percent INTEGER NOT NULL DEFAULT 100 CHECK (auto_reopen_load_percent > 0);
+ALTER TABLE organizations_rules ADD COLUMN orders_auto_closed_at TIMESTAMPTZ;
+
+-- every change of accepting_orders made by the controller, with the load it was made on
+CREATE TABLE IF NOT EXISTS order_acceptance_changes (
+    id UUID PRIMARY KEY,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    accepting_orders BOOLEAN NOT NULL,
+    forecast_orders INTEGER NOT NULL DEFAULT 0,
+    recent_orders INTEGER NOT NULL DEFAULT 0,
+    staff_on_shift INTEGER NOT NULL DEFAULT 0,
+    capacity INTEGER NOT NULL DEFAULT 0,
+    load_percent NUMERIC(9, 2),
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_order_acceptance_changes_org_changed ON order_acceptance_changes(organization_id, changed_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS order_acceptance_changes;
+ALTER TABLE organizations_rules DROP COLUMN IF EXISTS orders_auto_closed_at;
+ALTER TABLE organizations_rules DROP COLUMN IF EXISTS auto_reopen_load_percent;
+ALTER TABLE organizations_rules DROP COLUMN IF EXISTS auto_close_load_percent;
+ALTER TABLE organizations_rules DROP COLUMN IF EXISTS orders_per_staff_hour;
+ALTER TABLE organizations_rules DROP COLUMN IF EXISTS auto_close_orders;
+-- +goose StatementEnd
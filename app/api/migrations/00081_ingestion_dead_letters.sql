@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- payloads of delivery platform webhooks and polls that could not be read, kept for review instead of only
+-- being logged. The payload is kept as received, not as JSON, since it may not be valid JSON. Admins can
+-- edit a pending payload and replay it, a replayed one is kept for the failure rates.
+CREATE TABLE IF NOT EXISTS ingestion_dead_letters (
+    id UUID PRIMARY KEY,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    source VARCHAR(50) NOT NULL,
+    via VARCHAR(10) NOT NULL CHECK (via IN ('webhook', 'poll')),
+    payload TEXT NOT NULL,
+    error TEXT NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'replayed')),
+    replays INTEGER NOT NULL DEFAULT 0,
+    edited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    edited_at TIMESTAMPTZ,
+    replayed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    replayed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_ingestion_dead_letters_org_created ON ingestion_dead_letters(organization_id, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS ingestion_dead_letters;
+-- +goose StatementEnd
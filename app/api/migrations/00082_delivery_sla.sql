@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a delivery breaches the SLA of its organization when delivered more than delivery_sla_minutes after it
+-- went out. Managers are emailed once a day when more than sla_breach_alert_percent of the deliveries of
+-- the day so far breached it.
+ALTER TABLE organizations_rules ADD COLUMN delivery_sla_minutes INTEGER NOT NULL DEFAULT 45 CHECK (delivery_sla_minutes > 0);
+ALTER TABLE organizations_rules ADD COLUMN sla_breach_alert_percent INTEGER NOT NULL DEFAULT 20 CHECK (sla_breach_alert_percent BETWEEN 1 AND 100);
+
+-- the breach alerts sent, at most one per organization and day
+CREATE TABLE IF NOT EXISTS delivery_sla_alerts (
+    id UUID PRIMARY KEY,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    alert_date DATE NOT NULL,
+    sla_minutes INTEGER NOT NULL,
+    delivered INTEGER NOT NULL DEFAULT 0,
+    breaches INTEGER NOT NULL DEFAULT 0,
+    breach_rate NUMERIC(5, 2) NOT NULL DEFAULT 0,
+    threshold_percent INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (organization_id, alert_date)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS delivery_sla_alerts;
+ALTER TABLE organizations_rules DROP COLUMN IF EXISTS sla_breach_alert_percent;
+ALTER TABLE organizations_rules DROP COLUMN IF EXISTS delivery_sla_minutes;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- solo coverage roles are worked alone for stretches of the shift (e.g. drivers, the only cook of a quiet
+-- night), so employees in training are not scheduled in them
+ALTER TABLE organizations_roles ADD COLUMN solo_coverage BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- the training period of new hires. Until ends_on they are scheduled on top of the staff the demand needs,
+-- and a background job graduates them on ends_on and tells their managers.
+CREATE TABLE IF NOT EXISTS employee_trainings (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    starts_on DATE NOT NULL,
+    ends_on DATE NOT NULL CHECK (ends_on > starts_on),
+    started_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    graduated_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_employee_trainings_due ON employee_trainings(ends_on) WHERE graduated_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS employee_trainings;
+ALTER TABLE organizations_roles DROP COLUMN IF EXISTS solo_coverage;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the KPI targets an organization tracks its weeks against, a target left NULL is not tracked. Revenue is
+-- after discounts and in the currency of the organization, labor cost is a percent of that revenue and
+-- the rating is the average rating of the orders.
+CREATE TABLE IF NOT EXISTS kpi_targets (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    weekly_revenue NUMERIC(12, 2) CHECK (weekly_revenue > 0),
+    labor_cost_percent NUMERIC(5, 2) CHECK (labor_cost_percent > 0 AND labor_cost_percent <= 100),
+    average_rating NUMERIC(3, 2) CHECK (average_rating BETWEEN 1 AND 5),
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- weeks the admins of an organization were already sent the KPI report for
+CREATE TABLE IF NOT EXISTS kpi_report_emails (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    week_start DATE NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, week_start)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS kpi_report_emails;
+DROP TABLE IF EXISTS kpi_targets;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- discontinued items are deactivated instead of deleted, so the orders they were sold in keep them. They
+-- are left out of campaign recommendations, prep lists and orders placed from now on.
+ALTER TABLE items ADD COLUMN is_active BOOLEAN NOT NULL DEFAULT TRUE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE items DROP COLUMN IF EXISTS is_active;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a custom domain is pending until the organization publishes its token in a TXT record of the domain,
+-- only verified domains are allowed as CORS origins. Domains set before verification existed have to
+-- be verified like new ones.
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS custom_domain_token VARCHAR(100);
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS custom_domain_verified_at TIMESTAMPTZ;
+UPDATE organizations SET custom_domain_token = md5(random()::text || id::text) WHERE custom_domain IS NOT NULL;
+
+-- any number of organizations may claim a domain, only one can verify it
+DROP INDEX IF EXISTS idx_organizations_custom_domain;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organizations_custom_domain ON organizations (LOWER(custom_domain))
+    WHERE custom_domain_verified_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_organizations_custom_domain;
+UPDATE organizations SET custom_domain = NULL WHERE custom_domain_verified_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organizations_custom_domain ON organizations (LOWER(custom_domain));
+ALTER TABLE organizations DROP COLUMN IF EXISTS custom_domain_verified_at;
+ALTER TABLE organizations DROP COLUMN IF EXISTS custom_domain_token;
+-- +goose StatementEnd
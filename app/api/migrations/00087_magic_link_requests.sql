@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- every login link request per IP address, whether or not a link was sent, so an address is limited the
+-- same way for emails with and without an account. Requests older than the hour counted are deleted.
+CREATE TABLE IF NOT EXISTS magic_link_requests (
+    ip_address VARCHAR(45) NOT NULL,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_magic_link_requests_ip_requested ON magic_link_requests(ip_address, requested_at);
+CREATE INDEX IF NOT EXISTS idx_magic_link_requests_requested ON magic_link_requests(requested_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS magic_link_requests;
+-- +goose StatementEnd
@@ -0,0 +1,126 @@
+"""
+ML Service Contract Tests
+=========================
+Validates the golden fixtures shared with the Go API (app/api/internal/mlcontract/fixtures)
+against the request and response models of the ML service. The Go side checks the same
+fixtures against its own types with `go run ./cmd/mlcontract`.
+"""
+
+import json
+import sys
+import typing
+from pathlib import Path
+sys.path.insert(0, str(Path(__file__).parent.parent))
+
+import pytest
+from pydantic import BaseModel
+
+from api.main import (
+    CampaignRecommendationRequest,
+    CampaignRecommendationResponse,
+    DemandOutput,
+    DemandPredictionRequest,
+    SchedulingRequest,
+    SchedulingResponse,
+)
+
+FIXTURES = Path(__file__).parent.parent.parent / "api" / "internal" / "mlcontract" / "fixtures"
+
+MODELS = {
+    "/predict/schedule": (SchedulingRequest, SchedulingResponse),
+    "/predict/demand": (DemandPredictionRequest, DemandOutput),
+    "/recommend/campaigns": (CampaignRecommendationRequest, CampaignRecommendationResponse),
+}
+
+
+def load_contracts():
+    with open(FIXTURES / "contracts.json") as f:
+        return json.load(f)
+
+
+def load_fixture(name):
+    with open(FIXTURES / name) as f:
+        return json.load(f)
+
+
+def candidates(annotation):
+    """Models a field can hold as (model, keyed), keyed when the field maps data keys to them.
+    Fields that also take a plain dict have none, anything goes in them."""
+    origin, args = typing.get_origin(annotation), typing.get_args(annotation)
+    if origin is None:
+        if isinstance(annotation, type) and issubclass(annotation, BaseModel):
+            return [(annotation, False)]
+        return []
+    if origin is dict:
+        return [(model, True) for model, _ in candidates(args[1])] if len(args) == 2 else []
+    if origin is typing.Union and any(
+        typing.get_origin(arg) is dict and not candidates(arg) for arg in args
+    ):
+        return []
+    return [candidate for arg in args for candidate in candidates(arg)]
+
+
+def unknown_fields(model, data, prefix=""):
+    """Paths of the fixture fields the model does not declare, arrays collapsed to []"""
+    if isinstance(data, list):
+        return [p for item in data for p in unknown_fields(model, item, prefix + "[]")]
+    if not isinstance(data, dict):
+        return []
+
+    fields = {}
+    for name, field in model.model_fields.items():
+        fields[field.alias or name] = field
+        fields[name] = field
+
+    unknown = []
+    for key, value in data.items():
+        path = f"{prefix}.{key}" if prefix else key
+        if key not in fields:
+            unknown.append(path)
+            continue
+        found = candidates(fields[key].annotation)
+        keyed = [m for m, k in found if k and isinstance(value, dict)]
+        plain = [m for m, k in found if not k]
+        if keyed:
+            for k, v in value.items():
+                unknown.extend(unknown_fields(keyed[0], v, f"{path}.{k}"))
+        elif plain:
+            unknown.extend(unknown_fields(plain[0], value, path))
+    return unknown
+
+
+@pytest.mark.parametrize("contract", load_contracts(), ids=lambda c: c["endpoint"])
+class TestMLContract:
+    """Fixtures shared with the Go API"""
+
+    def test_endpoint_has_models(self, contract):
+        assert contract["endpoint"] in MODELS
+
+    def test_request_validates(self, contract):
+        request_model, _ = MODELS[contract["endpoint"]]
+        request_model.model_validate(load_fixture(contract["request"]))
+
+    def test_request_fields_are_known(self, contract):
+        request_model, _ = MODELS[contract["endpoint"]]
+        ignored = set(contract.get("ignored_by_service") or [])
+        unknown = [
+            p for p in unknown_fields(request_model, load_fixture(contract["request"]))
+            if not any(p == i or p.startswith(i + ".") or p.startswith(i + "[]") for i in ignored)
+        ]
+        assert unknown == [], (
+            "the API sends fields the ML service does not declare, add them to the models "
+            "or to ignored_by_service in contracts.json"
+        )
+
+    def test_response_validates(self, contract):
+        _, response_model = MODELS[contract["endpoint"]]
+        response_model.model_validate(load_fixture(contract["response"]))
+
+    def test_response_fields_are_known(self, contract):
+        _, response_model = MODELS[contract["endpoint"]]
+        unknown = unknown_fields(response_model, load_fixture(contract["response"]))
+        assert unknown == [], "the fixture has response fields the ML service does not return"
+
+
+def test_every_endpoint_has_a_contract():
+    assert {c["endpoint"] for c in load_contracts()} == set(MODELS)